@@ -4,8 +4,13 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/container"
+	"github.com/glkt/vyb-code/internal/handlers"
+	_ "github.com/glkt/vyb-code/internal/langpacks" // 組み込みの言語パック（Ruby, PHP, Kotlin）
+	"github.com/glkt/vyb-code/internal/remote"
 	"github.com/glkt/vyb-code/internal/version"
+	"github.com/glkt/vyb-code/internal/workspace"
 	"github.com/spf13/cobra"
 )
 
@@ -21,9 +26,25 @@ var rootCmd = &cobra.Command{
 	Long:    `vyb - Feel the rhythm of perfect code. A local LLM-based coding assistant with AI-powered interactive vibe coding mode as default experience.`,
 	Version: version.GetVersion(),
 	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-		// コンテナー初期化
-		appContainer = container.NewContainer()
-		return appContainer.Initialize()
+		// --module 指定時はモジュールへ移動してから設定を読み込む（分析・ビルド・コンテキストをモジュールに限定）
+		if module, _ := cmd.Flags().GetString("module"); module != "" {
+			if _, err := workspace.Enter(".", module); err != nil {
+				return err
+			}
+		}
+
+		// コンテナー初期化（--profile または VYB_PROFILE のプロファイルを適用）
+		profile, _ := cmd.Flags().GetString("profile")
+		appContainer = container.NewContainer().WithProfile(config.SelectProfile(profile))
+		if err := appContainer.Initialize(); err != nil {
+			return err
+		}
+
+		// --remote 指定時はファイル操作・コマンド実行を ssh 越しのエージェントで行う
+		if target, _ := cmd.Flags().GetString("remote"); target != "" {
+			return remote.ApplyTarget(&appContainer.GetConfig().Remote, target)
+		}
+		return nil
 	},
 	PersistentPostRunE: func(cmd *cobra.Command, args []string) error {
 		// コンテナークリーンアップ
@@ -39,8 +60,22 @@ var rootCmd = &cobra.Command{
 		}
 
 		config := appContainer.GetConfig()
+		applyTUIFlag(cmd, config)
+		if err := applyTemplateFlag(cmd, chatHandler); err != nil {
+			return err
+		}
+
+		// 画像を最初のメッセージに添付
+		images, _ := cmd.Flags().GetStringSlice("image")
+		if err := chatHandler.AttachImages(images); err != nil {
+			return err
+		}
 
 		if len(args) == 0 {
+			// 記録済みセッションの再開
+			if resumeID := resumeSessionID(cmd); resumeID != "" {
+				return chatHandler.ContinueSession(resumeID, config, false, false)
+			}
 			// 引数なし：バイブコーディングモードをデフォルトで開始
 			return chatHandler.StartVibeChat(config)
 		} else {
@@ -55,13 +90,24 @@ var rootCmd = &cobra.Command{
 var chatCmd = &cobra.Command{
 	Use:   "chat",
 	Short: "Start legacy terminal mode (traditional chat interface)",
+	Long:  `Start legacy terminal mode. Usage: vyb chat [file...]. Files given as arguments are pinned to the session: their current content is re-read into the context every turn, edits target them by default and the status line shows them (/pin and /unpin change the pins during the session).`,
 	RunE: func(cmd *cobra.Command, args []string) error {
 		chatHandler, err := appContainer.GetChatHandler()
 		if err != nil {
 			return fmt.Errorf("チャットハンドラー取得エラー: %w", err)
 		}
+		if err := chatHandler.PinAtStart(args); err != nil {
+			return err
+		}
 
 		config := appContainer.GetConfig()
+		applyTUIFlag(cmd, config)
+		if err := applyTemplateFlag(cmd, chatHandler); err != nil {
+			return err
+		}
+		if resumeID := resumeSessionID(cmd); resumeID != "" {
+			return chatHandler.ContinueSession(resumeID, config, false, false)
+		}
 		return chatHandler.StartChatSession(config)
 	},
 }
@@ -78,30 +124,135 @@ var vibeCmd = &cobra.Command{
 		}
 
 		config := appContainer.GetConfig()
+		applyTUIFlag(cmd, config)
+		if err := applyTemplateFlag(cmd, chatHandler); err != nil {
+			return err
+		}
 		return chatHandler.StartVibeChat(config)
 	},
 }
 
+// ヘッドレス実行コマンド：TUIなしで1ターン実行し機械可読な結果を出力
+var runCmd = &cobra.Command{
+	Use:   "run [query]",
+	Short: "Run a single agentic turn non-interactively (for CI and editor integrations)",
+	Long:  `Run one agentic turn (including tool calls) without the interactive UI. Use --output json or stream-json to emit machine-readable events for each tool invocation and the final answer.`,
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		chatHandler, err := appContainer.GetChatHandler()
+		if err != nil {
+			return fmt.Errorf("チャットハンドラー取得エラー: %w", err)
+		}
+
+		output, _ := cmd.Flags().GetString("output")
+		resumeID, _ := cmd.Flags().GetString("resume")
+		images, _ := cmd.Flags().GetStringSlice("image")
+		if err := chatHandler.AttachImages(images); err != nil {
+			return err
+		}
+
+		// 実行エラー時は結果出力済みのため使い方表示を抑制
+		cmd.SilenceUsage = true
+
+		config := appContainer.GetConfig()
+		return chatHandler.RunHeadless(args[0], resumeID, output, config)
+	},
+}
+
+// エディタ連携サーバーコマンド：JSON-RPC over stdio
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Run editor integration server (JSON-RPC over stdio, or a REST/WebSocket API with --http)",
+	Long: `Run a long-lived JSON-RPC server for editor plugins (Neovim, VSCode). Sessions, prompts, streaming tool events and edit/apply notifications with unified diffs are exchanged as newline-delimited JSON. See docs/editor-protocol.md.
+
+With --http :8080 the same sessions are served as a REST API with a WebSocket event stream, so one workstation hosting the model can serve several team members' editors or CI jobs. Clients authenticate with "Authorization: Bearer <token>" using the tokens in serve.tokens (or VYB_SERVE_TOKEN).`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		stdio, _ := cmd.Flags().GetBool("stdio")
+		address, _ := cmd.Flags().GetString("http")
+		if stdio == (address != "") {
+			return fmt.Errorf("--stdio か --http <address> のどちらか一方を指定してください")
+		}
+
+		chatHandler, err := appContainer.GetChatHandler()
+		if err != nil {
+			return fmt.Errorf("チャットハンドラー取得エラー: %w", err)
+		}
+
+		cmd.SilenceUsage = true
+		config := appContainer.GetConfig()
+		if address != "" {
+			certFile, _ := cmd.Flags().GetString("tls-cert")
+			keyFile, _ := cmd.Flags().GetString("tls-key")
+			return chatHandler.ServeHTTPAPI(config, address, certFile, keyFile)
+		}
+		return chatHandler.ServeStdio(config)
+	},
+}
+
+// applyTUIFlag は --no-tui 指定時にペイン構成のTUIを使わず逐次表示にする
+func applyTUIFlag(cmd *cobra.Command, cfg *config.Config) {
+	if noTUI, _ := cmd.Flags().GetBool("no-tui"); noTUI {
+		cfg.TUI.Enabled = false
+	}
+}
+
+// applyTemplateFlag は --template で指定したセッションテンプレートを読み込む
+func applyTemplateFlag(cmd *cobra.Command, chatHandler *handlers.ChatHandler) error {
+	name, _ := cmd.Flags().GetString("template")
+	if name == "" {
+		return nil
+	}
+	return chatHandler.SetTemplate(name)
+}
+
+// resumeSessionID は --resume <id> または --continue（直近のセッション）で再開するセッション
+func resumeSessionID(cmd *cobra.Command) string {
+	if resumeID, _ := cmd.Flags().GetString("resume"); resumeID != "" {
+		return resumeID
+	}
+	if continueLast, _ := cmd.Flags().GetBool("continue"); continueLast {
+		return "latest"
+	}
+	return ""
+}
+
 func init() {
 	// ルートコマンドにフラグを追加
-	rootCmd.PersistentFlags().Bool("no-tui", false, "Disable TUI mode")
+	rootCmd.PersistentFlags().Bool("no-tui", false, "Disable the pane UI and use plain line-by-line output")
 	rootCmd.PersistentFlags().Bool("terminal-mode", false, "Enable Claude Code-style terminal mode")
 	rootCmd.PersistentFlags().Bool("no-terminal-mode", false, "Disable terminal mode")
 	rootCmd.PersistentFlags().Bool("plan-mode", false, "Enable plan mode")
 	rootCmd.PersistentFlags().Bool("continue", false, "Continue previous session")
 	rootCmd.PersistentFlags().String("resume", "", "Resume specific session ID")
+	rootCmd.PersistentFlags().String("template", "", "Start the session from a template (bugfix, feature, spike or .vyb/templates/<name>.yaml)")
+	rootCmd.PersistentFlags().String("profile", "", "Apply a named configuration profile (default: $VYB_PROFILE)")
+	rootCmd.PersistentFlags().String("remote", "", "Edit a workspace on a remote host over SSH ([user@]host[:dir]); tools run there, the LLM and UI stay local")
+	rootCmd.PersistentFlags().String("module", "", "Scope the session to a module of a monorepo (e.g. services/api)")
+	rootCmd.Flags().StringSlice("image", nil, "Attach an image to the prompt (multimodal models such as llava, qwen2.5vl)")
 
 	// チャットコマンドにフラグを追加
-	chatCmd.Flags().Bool("no-tui", false, "Disable TUI mode")
+	chatCmd.Flags().Bool("no-tui", false, "Disable the pane UI and use plain line-by-line output")
 	chatCmd.Flags().Bool("terminal-mode", false, "Enable Claude Code-style terminal mode")
 	chatCmd.Flags().Bool("no-terminal-mode", false, "Disable terminal mode")
 	chatCmd.Flags().Bool("plan-mode", false, "Enable plan mode")
 	chatCmd.Flags().Bool("continue", false, "Continue previous session")
 	chatCmd.Flags().String("resume", "", "Resume specific session ID")
 
+	// ヘッドレス実行コマンドにフラグを追加
+	runCmd.Flags().StringP("output", "o", "text", "Output format (text, json, stream-json)")
+	runCmd.Flags().StringSlice("image", nil, "Attach an image to the prompt (multimodal models such as llava, qwen2.5vl)")
+
+	// エディタ連携サーバーにフラグを追加
+	serveCmd.Flags().Bool("stdio", false, "Communicate over stdin/stdout")
+	serveCmd.Flags().String("http", "", "Serve the REST/WebSocket API on this address (e.g. :8080)")
+	serveCmd.Flags().String("tls-cert", "", "TLS certificate file for --http")
+	serveCmd.Flags().String("tls-key", "", "TLS private key file for --http")
+
 	// サブコマンドを追加（これらは初期化時に動的に追加される）
 	rootCmd.AddCommand(chatCmd)
 	rootCmd.AddCommand(vibeCmd)
+	rootCmd.AddCommand(runCmd)
+	rootCmd.AddCommand(serveCmd)
 }
 
 func main() {
@@ -153,5 +304,137 @@ func buildCommands() error {
 	gitCmd := gitHandler.CreateGitCommands()
 	rootCmd.AddCommand(gitCmd)
 
+	// プロンプトテンプレートコマンド
+	promptsHandler := handlers.NewPromptsHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(promptsHandler.CreatePromptsCommands())
+
+	// 監査ログ閲覧コマンド
+	auditHandler := handlers.NewAuditHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Audit.Dir)
+	rootCmd.AddCommand(auditHandler.CreateAuditCommands())
+
+	// 記録したセッションの再実行コマンド
+	replayHandler := handlers.NewReplayHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Audit.Dir)
+	rootCmd.AddCommand(replayHandler.CreateReplayCommands())
+
+	// セッションエクスポートコマンド
+	exportHandler := handlers.NewExportHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Audit.Dir)
+	rootCmd.AddCommand(exportHandler.CreateExportCommands())
+
+	// 過去セッション検索コマンド
+	historyHandler := handlers.NewHistoryHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Audit.Dir)
+	rootCmd.AddCommand(historyHandler.CreateHistoryCommands())
+
+	// 初回セットアップコマンド
+	initHandler := handlers.NewInitHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(initHandler.CreateInitCommand())
+
+	// プロジェクトの初回調査（VYB.md のオンボーディング）コマンド
+	learnHandler := handlers.NewLearnHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(learnHandler.CreateLearnCommand())
+
+	// モデル管理コマンド
+	modelsHandler := handlers.NewModelsHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(modelsHandler.CreateModelsCommands())
+
+	// セッションテンプレートコマンド
+	templatesHandler := handlers.NewTemplatesHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(templatesHandler.CreateTemplatesCommands())
+
+	// 言語・フレームワーク別の指針コマンド
+	knowledgeHandler := handlers.NewKnowledgeHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(knowledgeHandler.CreateKnowledgeCommand())
+
+	// モデルのベンチマークコマンド
+	benchHandler := handlers.NewBenchHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(benchHandler.CreateBenchCommands())
+
+	// 埋め込みインデックスコマンド
+	indexHandler := handlers.NewIndexHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(indexHandler.CreateIndexCommands())
+
+	// gitフックコマンド
+	hooksHandler := handlers.NewHooksHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(hooksHandler.CreateHooksCommands())
+
+	// 応答処理の回帰テストコマンド
+	evalHandler := handlers.NewEvalHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(evalHandler.CreateEvalCommands())
+
+	// コードレビューコマンド
+	reviewHandler := handlers.NewReviewHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(reviewHandler.CreateReviewCommands())
+
+	// コード生成コマンド
+	genHandler := handlers.NewGenHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(genHandler.CreateGenCommands())
+
+	// 単発のコード提案コマンド
+	suggestHandler := handlers.NewSuggestHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(suggestHandler.CreateSuggestCommand())
+
+	// 実行環境の診断コマンド
+	doctorHandler := handlers.NewDoctorHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(doctorHandler.CreateDoctorCommand())
+
+	// コミット履歴からの変更履歴・リリースノート生成コマンド
+	releaseHandler := handlers.NewReleaseHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(releaseHandler.CreateReleaseCommand())
+
+	// CI設定・Makefile生成コマンド
+	scaffoldHandler := handlers.NewScaffoldHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(scaffoldHandler.CreateScaffoldCommands())
+
+	// リファクタリングコマンド
+	refactorHandler := handlers.NewRefactorHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(refactorHandler.CreateRefactorCommands())
+	rootCmd.AddCommand(refactorHandler.CreateRenameCommand())
+
+	// 定期メンテナンス（デーモン）コマンド
+	daemonHandler := handlers.NewDaemonHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(daemonHandler.CreateDaemonCommands())
+
+	// プロンプトの一括実行コマンド
+	batchHandler := handlers.NewBatchHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(batchHandler.CreateBatchCommand())
+
+	// CI の失敗の修正コマンド
+	ciFixHandler := handlers.NewCIFixHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(ciFixHandler.CreateCIFixCommand())
+
+	// ワークフローコマンド
+	workflowHandler := handlers.NewWorkflowHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(workflowHandler.CreateWorkflowCommands())
+
+	// 拡張コマンド
+	extensionsHandler := handlers.NewExtensionsHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(extensionsHandler.CreateExtensionsCommands())
+
+	// リモート開発用エージェントコマンド
+	remoteHandler := handlers.NewRemoteHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(remoteHandler.CreateAgentCommand())
+
+	// 使用量・コストコマンド
+	usageHandler := handlers.NewUsageHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Usage)
+	rootCmd.AddCommand(usageHandler.CreateUsageCommands())
+
+	// ローカル専用の利用統計コマンド
+	statsHandler := handlers.NewStatsHandler(tempContainer.GetLogger())
+	rootCmd.AddCommand(statsHandler.CreateStatsCommand())
+
+	// 保存したセッション・会話の一覧・検索コマンド
+	storageHandler := handlers.NewStorageHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Storage)
+	rootCmd.AddCommand(storageHandler.CreateStorageCommands())
+	rootCmd.AddCommand(storageHandler.CreateSessionsCommands())
+
+	// プロジェクト・ユーザーの許可規則の表示コマンド
+	permissionsHandler := handlers.NewPermissionsHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Permissions)
+	rootCmd.AddCommand(permissionsHandler.CreatePermissionsCommands())
+
+	// シェル補完・マニュアル生成コマンド（補完候補は全コマンドを追加した後に登録）
+	completionHandler := handlers.NewCompletionHandler(tempContainer.GetLogger(), tempContainer.GetConfig().Audit.Dir)
+	rootCmd.AddCommand(completionHandler.CreateCompletionCommand())
+	rootCmd.AddCommand(completionHandler.CreateDocsCommands())
+	completionHandler.RegisterCompletions(rootCmd)
+
 	return nil
 }
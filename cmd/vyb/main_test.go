@@ -45,3 +45,15 @@ func TestChatCommandStructure(t *testing.T) {
 		t.Errorf("chatCmd.Use = %s, want %s", chatCmd.Use, "chat")
 	}
 }
+
+// TestRunCommandStructure はrunCmdの出力形式フラグを確認
+func TestRunCommandStructure(t *testing.T) {
+	flag := runCmd.Flags().Lookup("output")
+	if flag == nil {
+		t.Fatal("runCmdに--outputフラグがありません")
+	}
+
+	if flag.DefValue != "text" {
+		t.Errorf("--output default = %s, want text", flag.DefValue)
+	}
+}
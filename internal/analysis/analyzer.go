@@ -14,6 +14,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // プロジェクト分析器の実装
@@ -224,7 +226,7 @@ func (pa *projectAnalyzer) analyzeLanguageAndFramework(analysis *ProjectAnalysis
 func (pa *projectAnalyzer) detectLanguageFromFiles(projectPath string) string {
 	extensionCounts := make(map[string]int)
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -294,7 +296,7 @@ func (pa *projectAnalyzer) analyzeFileStructure(analysis *ProjectAnalysis) error
 
 	allFiles := make([]FileInfo, 0)
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -381,7 +383,7 @@ func (pa *projectAnalyzer) analyzeDirectoryInfo(dirPath, projectPath string) *Di
 	}
 
 	// ディレクトリ内のファイルを数える
-	filepath.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
+	ignore.Walk(dirPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() || path == dirPath {
 			return nil
 		}
@@ -726,7 +728,7 @@ func (pa *projectAnalyzer) hasLayeredStructure(directories []DirectoryInfo) bool
 func (pa *projectAnalyzer) findMVCFiles(projectPath string) []string {
 	files := make([]string, 0)
 
-	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -750,7 +752,7 @@ func (pa *projectAnalyzer) findMVCFiles(projectPath string) []string {
 func (pa *projectAnalyzer) findMicroservicesFiles(projectPath string) []string {
 	files := make([]string, 0)
 
-	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -775,7 +777,7 @@ func (pa *projectAnalyzer) findLayeredFiles(projectPath string) []string {
 	files := make([]string, 0)
 	layers := []string{"presentation", "business", "data", "domain", "infrastructure"}
 
-	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -1040,7 +1042,7 @@ func (pa *projectAnalyzer) analyzeTestingFramework(analysis *ProjectAnalysis) er
 func (pa *projectAnalyzer) findTestFiles(projectPath string, framework *TestingFramework) ([]string, error) {
 	testFiles := make([]string, 0)
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -9,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // 非同期分析エンジン
@@ -403,7 +405,7 @@ func (aa *AsyncAnalyzer) analyzeBasicFileStructure(analysis *ProjectAnalysis) er
 	}
 
 	// ファイル数と行数の基本カウント
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
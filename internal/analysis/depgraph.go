@@ -0,0 +1,381 @@
+package analysis
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
+)
+
+// 依存グラフ（インポートの逆引き）の構築
+
+// 依存グラフのエコシステム
+const (
+	EcosystemGo     = "go"
+	EcosystemNode   = "node"
+	EcosystemPython = "python"
+)
+
+const (
+	graphCacheTTL   = 30 * time.Second // 同じルートのグラフを再利用する期間
+	graphFileLimit  = 5000             // JS/TS・Python で読むファイル数の上限
+	graphBuildLimit = 20 * time.Second // go list の実行時間の上限
+)
+
+// グラフの構築で読み飛ばすディレクトリ
+var graphSkippedDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "dist": true, "build": true, "target": true,
+	"coverage": true, ".next": true, "__pycache__": true, ".venv": true, "venv": true, ".tox": true,
+}
+
+// 拡張子毎のエコシステム
+var ecosystemExtensions = map[string]string{
+	".go": EcosystemGo,
+	".js": EcosystemNode, ".jsx": EcosystemNode, ".mjs": EcosystemNode, ".cjs": EcosystemNode,
+	".ts": EcosystemNode, ".tsx": EcosystemNode,
+	".py": EcosystemPython,
+}
+
+// JS/TS の相対インポートで補う拡張子
+var nodeResolveExtensions = []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}
+
+var (
+	nodeImportPattern       = regexp.MustCompile(`(?:import|export)\s[^'"]*?from\s*['"]([^'"]+)['"]|import\s*\(?\s*['"]([^'"]+)['"]|require\(\s*['"]([^'"]+)['"]\s*\)`)
+	pythonFromImportPattern = regexp.MustCompile(`(?m)^\s*from\s+(\.*[\w.]*)\s+import\s+\(?([\w\s,*]+)`)
+	pythonImportPattern     = regexp.MustCompile(`(?m)^\s*import\s+([\w.]+(?:\s*,\s*[\w.]+)*)`)
+)
+
+// dependencyGraph はプロジェクト内のインポート関係
+// Go はパッケージ（ディレクトリ）、JS/TS・Python はファイルを単位とする
+type dependencyGraph struct {
+	root      string
+	ecosystem string
+	units     map[string]*graphUnit
+	fileUnit  map[string]string   // ファイルの相対パス → 単位
+	reverse   map[string][]string // 単位 → それをインポートする単位
+}
+
+// graphUnit はグラフの単位
+type graphUnit struct {
+	name       string   // ルートからの相対パス（Go はディレクトリ）
+	files      []string // 含まれるファイルの相対パス（テストを含む）
+	imports    []string // インポートしているプロジェクト内の単位
+	importPath string   // Go のインポートパス
+	pkgName    string   // Go のパッケージ名
+}
+
+// errGraphFileLimit はファイル数の上限に達したことを表す（読んだ範囲でグラフを作る）
+var errGraphFileLimit = errors.New("file limit reached")
+
+type cachedGraph struct {
+	graph   *dependencyGraph
+	builtAt time.Time
+}
+
+var (
+	graphCacheMu sync.Mutex
+	graphCache   = make(map[string]*cachedGraph)
+)
+
+// loadDependencyGraph はルートの依存グラフを構築する（一定時間は前回の結果を使う）
+func loadDependencyGraph(ctx context.Context, root, ecosystem string) (*dependencyGraph, error) {
+	key := ecosystem + "|" + root
+	graphCacheMu.Lock()
+	defer graphCacheMu.Unlock()
+	if cached, ok := graphCache[key]; ok && time.Since(cached.builtAt) < graphCacheTTL {
+		return cached.graph, nil
+	}
+
+	var graph *dependencyGraph
+	var err error
+	switch ecosystem {
+	case EcosystemGo:
+		graph, err = buildGoGraph(ctx, root)
+	case EcosystemNode:
+		graph, err = buildFileGraph(root, EcosystemNode, resolveNodeImports)
+	case EcosystemPython:
+		graph, err = buildFileGraph(root, EcosystemPython, resolvePythonImports)
+	default:
+		err = fmt.Errorf("未対応のエコシステムです: %s", ecosystem)
+	}
+	if err != nil {
+		return nil, err
+	}
+	graphCache[key] = &cachedGraph{graph: graph, builtAt: time.Now()}
+	return graph, nil
+}
+
+func newDependencyGraph(root, ecosystem string) *dependencyGraph {
+	return &dependencyGraph{
+		root:      root,
+		ecosystem: ecosystem,
+		units:     make(map[string]*graphUnit),
+		fileUnit:  make(map[string]string),
+		reverse:   make(map[string][]string),
+	}
+}
+
+// link は逆引きを作成する
+func (g *dependencyGraph) link() {
+	for name, unit := range g.units {
+		for _, imported := range unit.imports {
+			g.reverse[imported] = append(g.reverse[imported], name)
+		}
+	}
+	for name := range g.reverse {
+		sort.Strings(g.reverse[name])
+	}
+}
+
+// goListPackage は go list -json の出力のうち使う項目
+type goListPackage struct {
+	Dir          string
+	ImportPath   string
+	Name         string
+	GoFiles      []string
+	CgoFiles     []string
+	TestGoFiles  []string
+	XTestGoFiles []string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+	Module       *struct{ Main bool }
+}
+
+// buildGoGraph は go list -deps でメインモジュールのパッケージのインポート関係を取得する
+func buildGoGraph(ctx context.Context, root string) (*dependencyGraph, error) {
+	ctx, cancel := context.WithTimeout(ctx, graphBuildLimit)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "list", "-e", "-deps", "-json", "./...")
+	cmd.Dir = root
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("go list 実行エラー: %v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var packages []goListPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg goListPackage
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("go list の出力を解析できません: %w", err)
+		}
+		if pkg.Module != nil && pkg.Module.Main && pkg.Dir != "" {
+			packages = append(packages, pkg)
+		}
+	}
+
+	graph := newDependencyGraph(root, EcosystemGo)
+	unitByImportPath := make(map[string]string)
+	for _, pkg := range packages {
+		rel, err := filepath.Rel(root, pkg.Dir)
+		if err != nil || strings.HasPrefix(rel, "..") {
+			continue
+		}
+		unitByImportPath[pkg.ImportPath] = filepath.ToSlash(rel)
+	}
+	for _, pkg := range packages {
+		name, ok := unitByImportPath[pkg.ImportPath]
+		if !ok {
+			continue
+		}
+		unit := &graphUnit{name: name, importPath: pkg.ImportPath, pkgName: pkg.Name}
+		for _, group := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.TestGoFiles, pkg.XTestGoFiles} {
+			for _, file := range group {
+				rel := path.Join(name, file)
+				unit.files = append(unit.files, rel)
+				graph.fileUnit[rel] = name
+			}
+		}
+		seen := make(map[string]bool)
+		for _, group := range [][]string{pkg.Imports, pkg.TestImports, pkg.XTestImports} {
+			for _, imported := range group {
+				if target, ok := unitByImportPath[imported]; ok && target != name && !seen[target] {
+					seen[target] = true
+					unit.imports = append(unit.imports, target)
+				}
+			}
+		}
+		graph.units[name] = unit
+	}
+	graph.link()
+	return graph, nil
+}
+
+// buildFileGraph はファイル単位のインポート関係を、ソースの import 文から構築する
+func buildFileGraph(root, ecosystem string, resolve func(root, file, content string, exists func(string) bool) []string) (*dependencyGraph, error) {
+	graph := newDependencyGraph(root, ecosystem)
+	contents := make(map[string]string)
+	err := ignore.Walk(root, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if filePath != root && (graphSkippedDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ecosystemExtensions[filepath.Ext(filePath)] != ecosystem || strings.HasSuffix(filePath, ".d.ts") {
+			return nil
+		}
+		if len(contents) >= graphFileLimit {
+			return errGraphFileLimit
+		}
+		rel, err := filepath.Rel(root, filePath)
+		if err != nil {
+			return nil
+		}
+		data, err := os.ReadFile(filePath)
+		if err != nil {
+			return nil
+		}
+		contents[filepath.ToSlash(rel)] = string(data)
+		return nil
+	})
+	if err != nil && err != errGraphFileLimit {
+		return nil, err
+	}
+
+	exists := func(rel string) bool {
+		_, ok := contents[rel]
+		return ok
+	}
+	for rel, content := range contents {
+		unit := &graphUnit{name: rel, files: []string{rel}}
+		seen := make(map[string]bool)
+		for _, target := range resolve(root, rel, content, exists) {
+			if target != rel && !seen[target] {
+				seen[target] = true
+				unit.imports = append(unit.imports, target)
+			}
+		}
+		graph.units[rel] = unit
+		graph.fileUnit[rel] = rel
+	}
+	graph.link()
+	return graph, nil
+}
+
+// resolveNodeImports は相対インポート（./ ../）を解決したファイルの一覧
+func resolveNodeImports(root, file, content string, exists func(string) bool) []string {
+	var resolved []string
+	for _, match := range nodeImportPattern.FindAllStringSubmatch(content, -1) {
+		spec := match[1] + match[2] + match[3]
+		if !strings.HasPrefix(spec, "./") && !strings.HasPrefix(spec, "../") {
+			continue
+		}
+		base := path.Join(path.Dir(file), spec)
+		candidates := []string{base}
+		// TypeScript は .js の指定で .ts を読む
+		trimmed := strings.TrimSuffix(base, path.Ext(base))
+		for _, ext := range nodeResolveExtensions {
+			candidates = append(candidates, base+ext, trimmed+ext, base+"/index"+ext)
+		}
+		for _, candidate := range candidates {
+			if exists(candidate) {
+				resolved = append(resolved, candidate)
+				break
+			}
+		}
+	}
+	return resolved
+}
+
+// resolvePythonImports は import・from ... import をプロジェクト内のファイルに解決した一覧
+func resolvePythonImports(root, file, content string, exists func(string) bool) []string {
+	module := func(name string) string {
+		base := strings.ReplaceAll(name, ".", "/")
+		for _, prefix := range []string{"", "src/"} {
+			for _, candidate := range []string{prefix + base + ".py", prefix + base + "/__init__.py"} {
+				if exists(candidate) {
+					return candidate
+				}
+			}
+		}
+		return ""
+	}
+
+	var resolved []string
+	for _, match := range pythonImportPattern.FindAllStringSubmatch(content, -1) {
+		for _, name := range strings.Split(match[1], ",") {
+			if target := module(strings.TrimSpace(name)); target != "" {
+				resolved = append(resolved, target)
+			}
+		}
+	}
+	for _, match := range pythonFromImportPattern.FindAllStringSubmatch(content, -1) {
+		from := match[1]
+		dots := len(from) - len(strings.TrimLeft(from, "."))
+		name := from[dots:]
+		if dots > 0 {
+			// 相対インポートはファイルのパッケージからたどる
+			dir := path.Dir(file)
+			for i := 1; i < dots; i++ {
+				dir = path.Dir(dir)
+			}
+			if dir == "." {
+				dir = ""
+			}
+			name = strings.Trim(strings.ReplaceAll(dir, "/", ".")+"."+name, ".")
+		}
+		// from pkg import module の形式ならサブモジュールも候補にする
+		found := false
+		for _, imported := range strings.Split(match[2], ",") {
+			imported = strings.TrimSpace(imported)
+			if imported == "" || imported == "*" {
+				continue
+			}
+			if target := module(strings.Trim(name+"."+imported, ".")); target != "" {
+				resolved = append(resolved, target)
+				found = true
+			}
+		}
+		if !found && name != "" {
+			if target := module(name); target != "" {
+				resolved = append(resolved, target)
+			}
+		}
+	}
+	return resolved
+}
+
+// goImportName はファイルがインポートパスを参照する名前（インポートしていなければ空）
+func goImportName(source []byte, importPath, pkgName string) string {
+	file, err := parser.ParseFile(token.NewFileSet(), "", source, parser.ImportsOnly)
+	if err != nil {
+		return ""
+	}
+	for _, spec := range file.Imports {
+		value, err := strconv.Unquote(spec.Path.Value)
+		if err != nil || value != importPath {
+			continue
+		}
+		if spec.Name != nil {
+			return spec.Name.Name
+		}
+		return pkgName
+	}
+	return ""
+}
@@ -0,0 +1,274 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// アーキテクチャ図（パッケージの依存関係・呼び出し関係）の出力
+
+// 図の形式
+const (
+	DiagramMermaid = "mermaid"
+	DiagramDOT     = "dot"
+)
+
+// diagramCallLabels は辺のラベルに並べる呼び出し先の数（残りは +N）
+const diagramCallLabels = 3
+
+// ValidDiagramFormats は対応する図の形式
+func ValidDiagramFormats() []string {
+	return []string{DiagramMermaid, DiagramDOT}
+}
+
+// DiagramOptions は図の作り方
+type DiagramOptions struct {
+	Calls bool // Go のパッケージ間で呼び出している関数を辺のラベルにする
+	Depth int  // ディレクトリをルートからこの階層で束ねる（0ならパッケージ・ディレクトリ毎）
+}
+
+// ArchitectureDiagram はパッケージ（JS/TS・Python はディレクトリ）間の依存関係
+type ArchitectureDiagram struct {
+	Ecosystem string        `json:"ecosystem"`
+	Nodes     []string      `json:"nodes"` // ルートからの相対パス（ルートは "."）
+	Edges     []DiagramEdge `json:"edges"`
+}
+
+// DiagramEdge は From が To をインポートしていること（Calls は呼び出している関数）
+type DiagramEdge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Calls []string `json:"calls,omitempty"`
+}
+
+// BuildArchitectureDiagram はプロジェクトの依存グラフから図を作る
+func BuildArchitectureDiagram(ctx context.Context, root string, opts DiagramOptions) (*ArchitectureDiagram, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	ecosystem := detectDiagramEcosystem(root)
+	if ecosystem == "" {
+		return nil, fmt.Errorf("図にできるプロジェクトが見つかりません（go.mod・package.json・Python のプロジェクトに対応）: %s", root)
+	}
+	graph, err := loadDependencyGraph(ctx, root, ecosystem)
+	if err != nil {
+		return nil, err
+	}
+
+	group := func(unit string) string {
+		dir := unit
+		if ecosystem != EcosystemGo {
+			dir = path.Dir(unit)
+		}
+		if opts.Depth > 0 && dir != "." {
+			if parts := strings.Split(dir, "/"); len(parts) > opts.Depth {
+				dir = strings.Join(parts[:opts.Depth], "/")
+			}
+		}
+		return dir
+	}
+
+	nodes := make(map[string]bool)
+	edges := make(map[[2]string]map[string]bool)
+	addEdge := func(from, to string, calls []string) {
+		from, to = group(from), group(to)
+		if from == to {
+			return
+		}
+		key := [2]string{from, to}
+		if edges[key] == nil {
+			edges[key] = make(map[string]bool)
+		}
+		for _, call := range calls {
+			edges[key][call] = true
+		}
+	}
+
+	byImportPath := make(map[string]*graphUnit)
+	for _, unit := range graph.units {
+		byImportPath[unit.importPath] = unit
+	}
+	for name, unit := range graph.units {
+		nodes[group(name)] = true
+		if ecosystem != EcosystemGo {
+			for _, imported := range unit.imports {
+				addEdge(name, imported, nil)
+			}
+			continue
+		}
+		// テストだけのインポートを除くため、Go はテスト以外のファイルから辺を作る
+		for imported, calls := range graph.goReferences(unit, byImportPath, opts.Calls) {
+			addEdge(name, imported, calls)
+		}
+	}
+
+	diagram := &ArchitectureDiagram{Ecosystem: ecosystem, Nodes: []string{}, Edges: []DiagramEdge{}}
+	for node := range nodes {
+		diagram.Nodes = append(diagram.Nodes, node)
+	}
+	sort.Strings(diagram.Nodes)
+	for key, calls := range edges {
+		edge := DiagramEdge{From: key[0], To: key[1]}
+		for call := range calls {
+			edge.Calls = append(edge.Calls, call)
+		}
+		sort.Strings(edge.Calls)
+		diagram.Edges = append(diagram.Edges, edge)
+	}
+	sort.Slice(diagram.Edges, func(i, j int) bool {
+		if diagram.Edges[i].From != diagram.Edges[j].From {
+			return diagram.Edges[i].From < diagram.Edges[j].From
+		}
+		return diagram.Edges[i].To < diagram.Edges[j].To
+	})
+	return diagram, nil
+}
+
+// detectDiagramEcosystem はルートから親方向に構成ファイルを探してエコシステムを判定する（判定できなければ空）
+// サブディレクトリを指定した場合はその配下だけを図にする
+func detectDiagramEcosystem(root string) string {
+	for dir := root; ; dir = filepath.Dir(dir) {
+		for _, marker := range []struct{ file, ecosystem string }{
+			{"go.mod", EcosystemGo},
+			{"package.json", EcosystemNode},
+			{"pyproject.toml", EcosystemPython},
+			{"setup.py", EcosystemPython},
+			{"requirements.txt", EcosystemPython},
+		} {
+			if _, err := os.Stat(filepath.Join(dir, marker.file)); err == nil {
+				return marker.ecosystem
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return ""
+		}
+	}
+}
+
+// goReferences はパッケージのテスト以外のファイルがインポートしているプロジェクト内のパッケージと、呼び出している関数
+func (g *dependencyGraph) goReferences(unit *graphUnit, byImportPath map[string]*graphUnit, withCalls bool) map[string][]string {
+	mode := parser.ImportsOnly
+	if withCalls {
+		mode = parser.SkipObjectResolution
+	}
+	references := make(map[string][]string)
+	for _, file := range unit.files {
+		if isTestFile(file) {
+			continue
+		}
+		parsed, err := parser.ParseFile(token.NewFileSet(), filepath.Join(g.root, filepath.FromSlash(file)), nil, mode)
+		if err != nil {
+			continue
+		}
+		// ファイル内でパッケージを参照する名前 → パッケージ
+		names := make(map[string]string)
+		for _, spec := range parsed.Imports {
+			importPath, err := strconv.Unquote(spec.Path.Value)
+			if err != nil {
+				continue
+			}
+			target, ok := byImportPath[importPath]
+			if !ok || target.name == unit.name {
+				continue
+			}
+			if _, seen := references[target.name]; !seen {
+				references[target.name] = nil
+			}
+			name := target.pkgName
+			if spec.Name != nil {
+				name = spec.Name.Name
+			}
+			names[name] = target.name
+		}
+		if !withCalls {
+			continue
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			call, ok := node.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+			if selector, ok := call.Fun.(*ast.SelectorExpr); ok {
+				if ident, ok := selector.X.(*ast.Ident); ok {
+					if target, ok := names[ident.Name]; ok {
+						references[target] = append(references[target], selector.Sel.Name)
+					}
+				}
+			}
+			return true
+		})
+	}
+	return references
+}
+
+// Render は図を指定の形式（mermaid, dot）で出力する
+func (d *ArchitectureDiagram) Render(format string) (string, error) {
+	switch format {
+	case DiagramMermaid, "":
+		return d.Mermaid(), nil
+	case DiagramDOT:
+		return d.DOT(), nil
+	default:
+		return "", fmt.Errorf("無効な図の形式です: %s（%s のいずれかを指定してください）", format, strings.Join(ValidDiagramFormats(), ", "))
+	}
+}
+
+// Mermaid は Mermaid の flowchart として出力する（Markdown の ```mermaid に埋め込める）
+func (d *ArchitectureDiagram) Mermaid() string {
+	var sb strings.Builder
+	sb.WriteString("graph LR\n")
+	ids := make(map[string]string)
+	for i, node := range d.Nodes {
+		ids[node] = fmt.Sprintf("n%d", i)
+		sb.WriteString(fmt.Sprintf("  %s[\"%s\"]\n", ids[node], mermaidText(node)))
+	}
+	for _, edge := range d.Edges {
+		if label := edge.callLabel(); label != "" {
+			sb.WriteString(fmt.Sprintf("  %s -->|\"%s\"| %s\n", ids[edge.From], mermaidText(label), ids[edge.To]))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s --> %s\n", ids[edge.From], ids[edge.To]))
+		}
+	}
+	return sb.String()
+}
+
+// DOT は Graphviz の DOT として出力する（dot -Tsvg で描画できる）
+func (d *ArchitectureDiagram) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("digraph architecture {\n  rankdir=LR;\n  node [shape=box];\n")
+	for _, node := range d.Nodes {
+		sb.WriteString(fmt.Sprintf("  %s;\n", strconv.Quote(node)))
+	}
+	for _, edge := range d.Edges {
+		if label := edge.callLabel(); label != "" {
+			sb.WriteString(fmt.Sprintf("  %s -> %s [label=%s];\n", strconv.Quote(edge.From), strconv.Quote(edge.To), strconv.Quote(label)))
+		} else {
+			sb.WriteString(fmt.Sprintf("  %s -> %s;\n", strconv.Quote(edge.From), strconv.Quote(edge.To)))
+		}
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// callLabel は辺のラベル（呼び出している関数、多ければ残りの数）
+func (e DiagramEdge) callLabel() string {
+	if len(e.Calls) <= diagramCallLabels {
+		return strings.Join(e.Calls, ", ")
+	}
+	return fmt.Sprintf("%s +%d", strings.Join(e.Calls[:diagramCallLabels], ", "), len(e.Calls)-diagramCallLabels)
+}
+
+// mermaidText は Mermaid のラベルで使えない引用符を文字参照にする
+func mermaidText(text string) string {
+	return strings.ReplaceAll(text, "\"", "#quot;")
+}
@@ -0,0 +1,506 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// 変更の影響範囲（blast radius）の分析
+
+// 影響範囲の表示で各項目に並べる件数
+const impactListLimit = 8
+
+// ImpactUnit は変更したコードに直接依存するパッケージ・ファイル
+type ImpactUnit struct {
+	Name    string   `json:"name"`              // Go はパッケージのディレクトリ、それ以外はファイル
+	Files   []string `json:"files"`             // 変更したシンボルを参照するファイル
+	Symbols []string `json:"symbols,omitempty"` // 参照している変更シンボル
+}
+
+// ImpactReport は変更の影響範囲
+type ImpactReport struct {
+	Changed    []string     `json:"changed"`           // 変更したパッケージ・ファイル
+	Symbols    []string     `json:"symbols,omitempty"` // 変更したシンボル（Type.Method 形式を含む）
+	Direct     []ImpactUnit `json:"direct"`            // 変更したシンボルを参照する単位
+	Transitive []string     `json:"transitive"`        // 直接依存する単位をさらにインポートする単位
+	Tests      []string     `json:"tests"`             // 影響を受けるテストファイル
+}
+
+var (
+	hunkHeaderPattern = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@(.*)`)
+
+	// 言語毎の宣言（最初のグループがレシーバ・クラス、2番目が名前）
+	declarationPatterns = map[string][]*regexp.Regexp{
+		EcosystemGo: {
+			regexp.MustCompile(`^func\s+\(\s*(?:\w+\s+)?\*?(\w+)(?:\[[^\]]*\])?\s*\)\s*(\w+)`),
+			regexp.MustCompile(`^func\s+()(\w+)`),
+			regexp.MustCompile(`^type\s+()(\w+)`),
+			regexp.MustCompile(`^(?:var|const)\s+()(\w+)`),
+		},
+		EcosystemNode: {
+			regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\s*\*?\s*()(\w+)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+()(\w+)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:const|let|var)\s+()(\w+)`),
+			regexp.MustCompile(`^(?:export\s+)?(?:declare\s+)?(?:interface|type|enum)\s+()(\w+)`),
+		},
+		EcosystemPython: {
+			regexp.MustCompile(`^\s*(?:async\s+)?def\s+()(\w+)`),
+			regexp.MustCompile(`^\s*class\s+()(\w+)`),
+		},
+	}
+)
+
+// AnalyzeImpact は変更したファイルとシンボルから影響範囲を求める
+// changes のキーはルートからの相対パス（または絶対パス）、値は変更したシンボル（空ならファイル全体の変更）
+func AnalyzeImpact(ctx context.Context, root string, changes map[string][]string) (*ImpactReport, error) {
+	root, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[string]map[string][]string)
+	for file, symbols := range changes {
+		rel := file
+		if filepath.IsAbs(file) {
+			if rel, err = filepath.Rel(root, file); err != nil {
+				continue
+			}
+		}
+		rel = filepath.ToSlash(filepath.Clean(rel))
+		if strings.HasPrefix(rel, "../") {
+			continue
+		}
+		ecosystem := ecosystemExtensions[path.Ext(rel)]
+		if groups[ecosystem] == nil {
+			groups[ecosystem] = make(map[string][]string)
+		}
+		groups[ecosystem][rel] = append(groups[ecosystem][rel], symbols...)
+	}
+
+	report := &ImpactReport{}
+	for ecosystem, files := range groups {
+		var graph *dependencyGraph
+		if ecosystem != "" {
+			// グラフを作れない場合（go が無い等）は変更したファイルのみ報告する
+			graph, _ = loadDependencyGraph(ctx, root, ecosystem)
+		}
+		if graph == nil {
+			for file, symbols := range files {
+				report.Changed = append(report.Changed, file)
+				report.Symbols = append(report.Symbols, symbols...)
+			}
+			continue
+		}
+		graph.blastRadius(files, report)
+	}
+
+	report.Changed = uniqueSorted(report.Changed)
+	report.Symbols = uniqueSorted(report.Symbols)
+	report.Transitive = uniqueSorted(report.Transitive)
+	report.Tests = uniqueSorted(report.Tests)
+	sort.Slice(report.Direct, func(i, j int) bool { return report.Direct[i].Name < report.Direct[j].Name })
+	return report, nil
+}
+
+// AnalyzeDiffImpact は作業ツリーに対する unified diff（git diff 等）の影響範囲を求める
+func AnalyzeDiffImpact(ctx context.Context, root, diff string) (*ImpactReport, error) {
+	source := func(rel string) (string, bool) {
+		data, err := os.ReadFile(filepath.Join(root, filepath.FromSlash(rel)))
+		return string(data), err == nil
+	}
+	return AnalyzeImpact(ctx, root, ChangedSymbols(diff, source))
+}
+
+// blastRadius は変更した単位に依存する単位を report に追加する
+func (g *dependencyGraph) blastRadius(files map[string][]string, report *ImpactReport) {
+	changedFiles := make(map[string]bool)
+	changedUnits := make(map[string][]string)
+	wholeUnits := make(map[string]bool)
+	for file, symbols := range files {
+		changedFiles[file] = true
+		name, ok := g.fileUnit[file]
+		if !ok && g.ecosystem == EcosystemGo {
+			// パッケージに追加したファイル
+			name = path.Dir(file)
+			_, ok = g.units[name]
+		}
+		if !ok {
+			report.Changed = append(report.Changed, file)
+			report.Symbols = append(report.Symbols, symbols...)
+			continue
+		}
+		if len(symbols) == 0 {
+			wholeUnits[name] = true
+		}
+		changedUnits[name] = append(changedUnits[name], symbols...)
+	}
+
+	direct := make(map[string]*ImpactUnit)
+	addDirect := func(name, file string, symbols []string) {
+		unit, ok := direct[name]
+		if !ok {
+			unit = &ImpactUnit{Name: name}
+			direct[name] = unit
+		}
+		unit.Files = append(unit.Files, file)
+		unit.Symbols = uniqueSorted(append(unit.Symbols, symbols...))
+	}
+
+	for name, symbols := range changedUnits {
+		report.Changed = append(report.Changed, name)
+		report.Symbols = append(report.Symbols, symbols...)
+
+		// 同じパッケージの他のファイル（Go）
+		if g.ecosystem == EcosystemGo && len(symbols) > 0 {
+			for _, file := range g.units[name].files {
+				if changedFiles[file] {
+					continue
+				}
+				if used, _ := g.references(file, name, symbols, true); len(used) > 0 {
+					addDirect(name, file, used)
+				}
+			}
+		}
+
+		for _, importer := range g.reverse[name] {
+			for _, file := range g.units[importer].files {
+				if changedFiles[file] {
+					continue
+				}
+				used, imports := g.references(file, name, symbols, false)
+				if imports && (wholeUnits[name] || len(used) > 0) {
+					addDirect(importer, file, used)
+				}
+			}
+		}
+	}
+
+	// 直接依存する単位をインポートする単位を幅優先でたどる
+	visited := make(map[string]bool)
+	var queue []string
+	for name := range changedUnits {
+		visited[name] = true
+	}
+	for name, unit := range direct {
+		visited[name] = true
+		if _, changed := changedUnits[name]; !changed {
+			queue = append(queue, name)
+		}
+		for _, file := range unit.Files {
+			if isTestFile(file) {
+				report.Tests = append(report.Tests, file)
+			}
+		}
+		report.Direct = append(report.Direct, *unit)
+	}
+	sort.Strings(queue)
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, importer := range g.reverse[name] {
+			if !visited[importer] {
+				visited[importer] = true
+				report.Transitive = append(report.Transitive, importer)
+				queue = append(queue, importer)
+			}
+		}
+	}
+}
+
+// references はファイルが参照している変更シンボルと、ファイルが変更した単位をインポートしているか
+func (g *dependencyGraph) references(file, target string, symbols []string, samePackage bool) ([]string, bool) {
+	data, err := os.ReadFile(filepath.Join(g.root, filepath.FromSlash(file)))
+	if err != nil {
+		return nil, false
+	}
+	content := string(data)
+
+	qualifier := ""
+	if g.ecosystem == EcosystemGo && !samePackage {
+		unit := g.units[target]
+		qualifier = goImportName(data, unit.importPath, unit.pkgName)
+		if qualifier == "" {
+			return nil, false
+		}
+	}
+
+	var used []string
+	for _, symbol := range symbols {
+		receiver, name := splitSymbol(symbol)
+		var pattern string
+		switch {
+		case g.ecosystem == EcosystemGo && !samePackage && !isExported(name):
+			// 他のパッケージからは参照できない
+			continue
+		case receiver != "":
+			pattern = `\.` + regexp.QuoteMeta(name) + `\b`
+		case qualifier != "" && qualifier != "." && qualifier != "_":
+			pattern = `\b` + regexp.QuoteMeta(qualifier) + `\.` + regexp.QuoteMeta(name) + `\b`
+		default:
+			pattern = `\b` + regexp.QuoteMeta(name) + `\b`
+		}
+		if regexp.MustCompile(pattern).MatchString(content) {
+			used = append(used, symbol)
+		}
+	}
+	return used, true
+}
+
+// ChangedSymbols は unified diff から変更したファイルと宣言（関数・型等）を取り出す
+// source でファイルの新しい内容が得られれば、変更行を囲む宣言も変更したシンボルとする
+func ChangedSymbols(diff string, source func(path string) (string, bool)) map[string][]string {
+	changes := make(map[string][]string)
+	seen := make(map[string]bool)
+	var file, ecosystem string
+	var lines []string
+	newLine := 0
+
+	add := func(symbol string) {
+		if symbol != "" && file != "" && !seen[file+"\x00"+symbol] {
+			seen[file+"\x00"+symbol] = true
+			changes[file] = append(changes[file], symbol)
+		}
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "--- "):
+			file, lines = diffPath(line[4:]), nil
+		case strings.HasPrefix(line, "+++ "):
+			if name := diffPath(line[4:]); name != "" {
+				file = name
+			}
+			if file == "" {
+				continue
+			}
+			ecosystem = ecosystemExtensions[path.Ext(file)]
+			if _, ok := changes[file]; !ok {
+				changes[file] = nil
+			}
+			if source != nil {
+				if content, ok := source(file); ok {
+					lines = strings.Split(content, "\n")
+				}
+			}
+		case strings.HasPrefix(line, "@@"):
+			match := hunkHeaderPattern.FindStringSubmatch(line)
+			if match == nil {
+				continue
+			}
+			newLine, _ = strconv.Atoi(match[1])
+			if lines == nil {
+				// 内容が無ければ git が付ける見出し（直前の宣言）で代用する
+				add(declaredSymbol(ecosystem, strings.TrimSpace(match[2])))
+			}
+		case strings.HasPrefix(line, "+"):
+			if symbol := declaredSymbol(ecosystem, line[1:]); symbol != "" {
+				add(symbol)
+			} else {
+				add(enclosingSymbol(ecosystem, lines, newLine-1))
+			}
+			newLine++
+		case strings.HasPrefix(line, "-"):
+			// 削除・名前変更した宣言
+			if symbol := declaredSymbol(ecosystem, line[1:]); symbol != "" {
+				add(symbol)
+			} else {
+				add(enclosingSymbol(ecosystem, lines, newLine-2))
+			}
+		case strings.HasPrefix(line, " "):
+			newLine++
+		}
+	}
+	return changes
+}
+
+// DeclaredSymbols はコード片で宣言している関数・型等
+func DeclaredSymbols(filePath, code string) []string {
+	ecosystem := ecosystemExtensions[filepath.Ext(filePath)]
+	var symbols []string
+	for _, line := range strings.Split(code, "\n") {
+		if symbol := declaredSymbol(ecosystem, line); symbol != "" {
+			symbols = append(symbols, symbol)
+		}
+	}
+	return uniqueSorted(symbols)
+}
+
+// declaredSymbol は行が宣言ならそのシンボル（メソッドは Type.Method）
+func declaredSymbol(ecosystem, line string) string {
+	for _, pattern := range declarationPatterns[ecosystem] {
+		if match := pattern.FindStringSubmatch(line); match != nil {
+			if match[1] != "" {
+				return match[1] + "." + match[2]
+			}
+			return match[2]
+		}
+	}
+	return ""
+}
+
+// enclosingSymbol は index 行目（0始まり）を囲む宣言
+func enclosingSymbol(ecosystem string, lines []string, index int) string {
+	if index >= len(lines) {
+		index = len(lines) - 1
+	}
+	for i := index; i >= 0; i-- {
+		line := lines[i]
+		if symbol := declaredSymbol(ecosystem, line); symbol != "" {
+			return symbol
+		}
+		if i == index || line == "" {
+			continue
+		}
+		// 前の宣言の終わり（Go・JS/TS）や、インデントの無い文（Python）を越えたら宣言の外
+		switch ecosystem {
+		case EcosystemPython:
+			if !unicode.IsSpace(rune(line[0])) && line[0] != '@' && line[0] != '#' {
+				return ""
+			}
+		default:
+			if line == "}" || line == ")" || strings.HasPrefix(line, "};") || strings.HasPrefix(line, "})") {
+				return ""
+			}
+		}
+	}
+	return ""
+}
+
+// diffPath は diff のファイル名（a/ b/ を除く、/dev/null は空）
+func diffPath(name string) string {
+	if i := strings.IndexByte(name, '\t'); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.TrimSpace(name)
+	if name == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(name, "a/") || strings.HasPrefix(name, "b/") {
+		name = name[2:]
+	}
+	return name
+}
+
+// splitSymbol は Type.Method をレシーバと名前に分ける
+func splitSymbol(symbol string) (string, string) {
+	if i := strings.LastIndexByte(symbol, '.'); i >= 0 {
+		return symbol[:i], symbol[i+1:]
+	}
+	return "", symbol
+}
+
+func isExported(name string) bool {
+	for _, r := range name {
+		return unicode.IsUpper(r)
+	}
+	return false
+}
+
+// isTestFile はテストファイルか（Go・JS/TS・Python の命名規則）
+func isTestFile(file string) bool {
+	base := path.Base(file)
+	return strings.HasSuffix(base, "_test.go") ||
+		strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+		(strings.HasSuffix(base, ".py") && (strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py"))) ||
+		strings.Contains(file, "__tests__/")
+}
+
+func uniqueSorted(items []string) []string {
+	seen := make(map[string]bool, len(items))
+	var unique []string
+	for _, item := range items {
+		if item != "" && !seen[item] {
+			seen[item] = true
+			unique = append(unique, item)
+		}
+	}
+	sort.Strings(unique)
+	return unique
+}
+
+// Areas は変更・直接依存するパッケージ・ファイル
+func (r *ImpactReport) Areas() []string {
+	areas := append([]string{}, r.Changed...)
+	for _, unit := range r.Direct {
+		areas = append(areas, unit.Name)
+	}
+	return uniqueSorted(areas)
+}
+
+// Summary は影響範囲の1行の要約
+func (r *ImpactReport) Summary() string {
+	direct := 0
+	changed := make(map[string]bool)
+	for _, name := range r.Changed {
+		changed[name] = true
+	}
+	for _, unit := range r.Direct {
+		if !changed[unit.Name] {
+			direct++
+		}
+	}
+	if len(r.Direct) == 0 && len(r.Transitive) == 0 {
+		return i18n.T("impact.none")
+	}
+	return i18n.T("impact.summary", direct, len(r.Transitive))
+}
+
+// Format は確認プロンプトに表示する影響範囲
+func (r *ImpactReport) Format() string {
+	var b strings.Builder
+	b.WriteString(r.Summary())
+	b.WriteString("\n")
+
+	changed := formatImpactList(r.Changed)
+	if len(r.Symbols) > 0 {
+		changed += " — " + formatImpactList(r.Symbols)
+	}
+	b.WriteString(i18n.T("impact.changed", changed))
+	b.WriteString("\n")
+
+	if len(r.Direct) > 0 {
+		b.WriteString(i18n.T("impact.direct"))
+		b.WriteString("\n")
+		for i, unit := range r.Direct {
+			if i == impactListLimit {
+				b.WriteString("    " + i18n.T("impact.more", len(r.Direct)-i) + "\n")
+				break
+			}
+			var files []string
+			for _, file := range unit.Files {
+				files = append(files, path.Base(file))
+			}
+			line := "    " + unit.Name + " — " + formatImpactList(uniqueSorted(files))
+			if len(unit.Symbols) > 0 {
+				line += " (" + strings.Join(unit.Symbols, ", ") + ")"
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if len(r.Transitive) > 0 {
+		b.WriteString(i18n.T("impact.transitive", formatImpactList(r.Transitive)))
+		b.WriteString("\n")
+	}
+	if len(r.Tests) > 0 {
+		b.WriteString(i18n.T("impact.tests", formatImpactList(r.Tests)))
+		b.WriteString("\n")
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// formatImpactList は一覧を上限まで並べる
+func formatImpactList(items []string) string {
+	if len(items) <= impactListLimit {
+		return strings.Join(items, ", ")
+	}
+	return strings.Join(items[:impactListLimit], ", ") + " " + i18n.T("impact.more", len(items)-impactListLimit)
+}
@@ -0,0 +1,202 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeProject はファイルの相対パスと内容から一時プロジェクトを作成
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func impactNames(units []ImpactUnit) []string {
+	var names []string
+	for _, unit := range units {
+		names = append(names, unit.Name)
+	}
+	return names
+}
+
+func TestChangedSymbols(t *testing.T) {
+	source := "package lib\n\nfunc Load() error {\n\treturn nil\n}\n\nfunc (c *Config) Save() {\n\tc.n++\n}\n"
+	diff := `--- a/lib/lib.go
++++ b/lib/lib.go
+@@ -3,3 +3,3 @@
+ func Load() error {
+-	return errors.New("x")
++	return nil
+ }
+@@ -6,2 +6,4 @@
+
++func (c *Config) Save() {
++	c.n++
++}
+--- a/web/app.ts
++++ b/web/app.ts
+@@ -10,2 +10,2 @@ export function render(props) {
+-  return a
++  return b
+`
+	got := ChangedSymbols(diff, func(path string) (string, bool) {
+		return source, path == "lib/lib.go"
+	})
+	want := map[string][]string{
+		"lib/lib.go": {"Load", "Config.Save"},
+		"web/app.ts": {"render"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ChangedSymbols = %v, want %v", got, want)
+	}
+
+	if got := DeclaredSymbols("app.py", "class Cart:\n    def total(self):\n        pass\n"); !reflect.DeepEqual(got, []string{"Cart", "total"}) {
+		t.Errorf("DeclaredSymbols = %v", got)
+	}
+}
+
+func TestAnalyzeImpact_Go(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	root := writeProject(t, map[string]string{
+		"go.mod":           "module example.com/shop\n\ngo 1.20\n",
+		"lib/lib.go":       "package lib\n\nfunc Load() error { return nil }\n\nfunc Save() error { return nil }\n",
+		"lib/lib_test.go":  "package lib\n\nimport \"testing\"\n\nfunc TestLoad(t *testing.T) { Load() }\n",
+		"app/app.go":       "package app\n\nimport store \"example.com/shop/lib\"\n\nfunc Run() error { return store.Load() }\n",
+		"app/other.go":     "package app\n\nfunc helper() {}\n",
+		"cli/cli.go":       "package cli\n\nimport \"example.com/shop/app\"\n\nfunc Main() { app.Run() }\n",
+		"report/report.go": "package report\n\nimport \"example.com/shop/lib\"\n\nfunc Write() error { return lib.Save() }\n",
+	})
+
+	report, err := AnalyzeImpact(context.Background(), root, map[string][]string{"lib/lib.go": {"Load"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report.Changed, []string{"lib"}) {
+		t.Errorf("Changed = %v", report.Changed)
+	}
+	if got := impactNames(report.Direct); !reflect.DeepEqual(got, []string{"app", "lib"}) {
+		t.Fatalf("Direct = %v (Load を参照する app と同じパッケージのテストのみのはず)", got)
+	}
+	if files := report.Direct[0].Files; !reflect.DeepEqual(files, []string{"app/app.go"}) {
+		t.Errorf("参照しているファイルのみ挙げるはず: %v", files)
+	}
+	if !reflect.DeepEqual(report.Transitive, []string{"cli"}) {
+		t.Errorf("Transitive = %v", report.Transitive)
+	}
+	if !reflect.DeepEqual(report.Tests, []string{"lib/lib_test.go"}) {
+		t.Errorf("Tests = %v", report.Tests)
+	}
+
+	// シンボルが分からなければインポートしている全てのパッケージ
+	report, err = AnalyzeImpact(context.Background(), root, map[string][]string{filepath.Join(root, "lib", "lib.go"): nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := impactNames(report.Direct); !reflect.DeepEqual(got, []string{"app", "report"}) {
+		t.Errorf("Direct = %v", got)
+	}
+	if !strings.Contains(report.Format(), "report — report.go") {
+		t.Errorf("Format に直接依存するファイルを含むはず:\n%s", report.Format())
+	}
+}
+
+func TestAnalyzeImpact_NodeAndPython(t *testing.T) {
+	root := writeProject(t, map[string]string{
+		"src/util.ts":          "export function formatPrice(n: number) { return n }\nexport const TAX = 0.1\n",
+		"src/cart.ts":          "import { formatPrice } from './util'\nexport function total() { return formatPrice(1) }\n",
+		"src/tax.ts":           "import { TAX } from './util.js'\nexport const rate = TAX\n",
+		"src/page.js":          "const { total } = require('./cart')\n",
+		"src/cart.test.ts":     "import { formatPrice } from './util'\n",
+		"shop/__init__.py":     "",
+		"shop/pricing.py":      "def discount(price):\n    return price\n",
+		"shop/checkout.py":     "from .pricing import discount\n\ndef pay():\n    return discount(1)\n",
+		"shop/api.py":          "from shop import checkout\n",
+		"node_modules/x/a.js":  "require('../../src/util')\n",
+		"tests/test_prices.py": "import shop.pricing\n",
+	})
+
+	report, err := AnalyzeImpact(context.Background(), root, map[string][]string{
+		"src/util.ts":     {"formatPrice"},
+		"shop/pricing.py": {"discount"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := impactNames(report.Direct); !reflect.DeepEqual(got, []string{"shop/checkout.py", "src/cart.test.ts", "src/cart.ts"}) {
+		t.Errorf("Direct = %v", got)
+	}
+	if !reflect.DeepEqual(report.Transitive, []string{"shop/api.py", "src/page.js"}) {
+		t.Errorf("Transitive = %v", report.Transitive)
+	}
+	if !reflect.DeepEqual(report.Tests, []string{"src/cart.test.ts"}) {
+		t.Errorf("Tests = %v", report.Tests)
+	}
+}
+
+func TestArchitectureDiagram_Go(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go not found")
+	}
+	root := writeProject(t, map[string]string{
+		"go.mod":                  "module example.com/shop\n\ngo 1.20\n",
+		"internal/lib/lib.go":     "package lib\n\nfunc Load() error { return nil }\n\nfunc Save() error { return nil }\n",
+		"internal/store/store.go": "package store\n\nimport l \"example.com/shop/internal/lib\"\n\nfunc Open() { l.Load(); l.Save(); l.Load() }\n",
+		"internal/util/util.go":   "package util\n\nvar X = 1\n",
+		// テストだけのインポートは依存として描かない
+		"internal/lib/lib_test.go": "package lib\n\nimport (\n\t\"testing\"\n\n\t\"example.com/shop/internal/util\"\n)\n\nfunc TestLoad(t *testing.T) { _ = util.X }\n",
+		"cmd/app/main.go":          "package main\n\nimport \"example.com/shop/internal/store\"\n\nfunc main() { store.Open() }\n",
+	})
+
+	diagram, err := BuildArchitectureDiagram(context.Background(), root, DiagramOptions{Calls: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"cmd/app", "internal/lib", "internal/store", "internal/util"}; !reflect.DeepEqual(diagram.Nodes, want) {
+		t.Errorf("nodes = %v, want %v", diagram.Nodes, want)
+	}
+	wantEdges := []DiagramEdge{
+		{From: "cmd/app", To: "internal/store", Calls: []string{"Open"}},
+		{From: "internal/store", To: "internal/lib", Calls: []string{"Load", "Save"}},
+	}
+	if !reflect.DeepEqual(diagram.Edges, wantEdges) {
+		t.Errorf("edges = %+v, want %+v", diagram.Edges, wantEdges)
+	}
+
+	mermaid := diagram.Mermaid()
+	if !strings.HasPrefix(mermaid, "graph LR\n") || !strings.Contains(mermaid, `n2 -->|"Load, Save"| n1`) {
+		t.Errorf("unexpected mermaid:\n%s", mermaid)
+	}
+	dot, err := diagram.Render(DiagramDOT)
+	if err != nil || !strings.Contains(dot, `"cmd/app" -> "internal/store" [label="Open"];`) {
+		t.Errorf("unexpected dot (%v):\n%s", err, dot)
+	}
+	if _, err := diagram.Render("svg"); err == nil {
+		t.Error("unknown format should be rejected")
+	}
+
+	// 階層で束ねると internal 内の依存は1つの単位にまとまる
+	grouped, err := BuildArchitectureDiagram(context.Background(), root, DiagramOptions{Depth: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(grouped.Nodes, []string{"cmd", "internal"}) || len(grouped.Edges) != 1 || grouped.Edges[0].Calls != nil {
+		t.Errorf("unexpected grouped diagram: %+v", grouped)
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // 軽量分析器 - 基本的な分析のみを高速で実行
@@ -115,7 +117,7 @@ func (la *LightweightAnalyzer) detectLanguageFromExtensions(analysis *ProjectAna
 	maxFiles := 50 // 最大50ファイルまでしか見ない（高速化）
 
 	fileCount := 0
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -192,7 +194,7 @@ func (la *LightweightAnalyzer) analyzeBasicStructure(analysis *ProjectAnalysis)
 	maxFiles := 100 // ファイル数制限
 	fileCount := 0
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
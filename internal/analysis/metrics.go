@@ -0,0 +1,332 @@
+package analysis
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/ignore"
+)
+
+// 実測メトリクスの収集（カバレッジプロファイル・AST複雑度・コミット単位キャッシュ）
+
+// カバレッジの取得元
+const (
+	CoverageSourceGoTest    = "go test"
+	CoverageSourceJest      = "jest"
+	CoverageSourcePytest    = "pytest"
+	CoverageSourceEstimated = "estimated" // テストファイル比率からの推定
+)
+
+// CoverageReport はカバレッジプロファイルの集計結果
+type CoverageReport struct {
+	Mode       string             `json:"mode"`
+	Statements int                `json:"statements"`
+	Covered    int                `json:"covered"`
+	Total      float64            `json:"total"`    // 全体カバレッジ（%）
+	Packages   map[string]float64 `json:"packages"` // パッケージ別カバレッジ（%）
+}
+
+// FunctionComplexity は関数単位の循環的複雑度
+type FunctionComplexity struct {
+	Name       string `json:"name"`
+	File       string `json:"file"`
+	Line       int    `json:"line"`
+	Complexity int    `json:"complexity"`
+}
+
+// ComplexitySummary はプロジェクト全体の複雑度集計
+type ComplexitySummary struct {
+	Functions []FunctionComplexity `json:"functions"` // 複雑度の高い順
+	Average   float64              `json:"average"`
+	Max       int                  `json:"max"`
+}
+
+// coverProfileLine はプロファイルの1ブロック（file:start.col,end.col statements count）
+var coverProfileLine = regexp.MustCompile(`^(.+):(\d+)\.(\d+),(\d+)\.(\d+) (\d+) (\d+)$`)
+
+// ParseCoverProfile は go test -coverprofile の出力を集計
+func ParseCoverProfile(r io.Reader) (*CoverageReport, error) {
+	type block struct {
+		file       string
+		statements int
+		count      int
+	}
+	blocks := make(map[string]*block)
+	report := &CoverageReport{Packages: make(map[string]float64)}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if strings.HasPrefix(line, "mode:") {
+			report.Mode = strings.TrimSpace(strings.TrimPrefix(line, "mode:"))
+			continue
+		}
+
+		m := coverProfileLine.FindStringSubmatch(line)
+		if m == nil {
+			return nil, fmt.Errorf("不正なカバレッジプロファイル行: %s", line)
+		}
+		statements, _ := strconv.Atoi(m[6])
+		count, _ := strconv.Atoi(m[7])
+
+		// 複数パッケージから同じブロックが出力される場合は実行回数を合算
+		key := strings.Join(m[1:6], ":")
+		if b, ok := blocks[key]; ok {
+			b.count += count
+			continue
+		}
+		blocks[key] = &block{file: m[1], statements: statements, count: count}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if report.Mode == "" {
+		return nil, fmt.Errorf("カバレッジプロファイルのmode行がありません")
+	}
+
+	pkgStatements := make(map[string]int)
+	pkgCovered := make(map[string]int)
+	for _, b := range blocks {
+		pkg := path.Dir(b.file)
+		report.Statements += b.statements
+		pkgStatements[pkg] += b.statements
+		if b.count > 0 {
+			report.Covered += b.statements
+			pkgCovered[pkg] += b.statements
+		}
+	}
+
+	if report.Statements > 0 {
+		report.Total = float64(report.Covered) / float64(report.Statements) * 100.0
+	}
+	for pkg, statements := range pkgStatements {
+		if statements > 0 {
+			report.Packages[pkg] = float64(pkgCovered[pkg]) / float64(statements) * 100.0
+		}
+	}
+
+	return report, nil
+}
+
+// runGoCoverage は go test -coverprofile を実行してカバレッジを集計
+func (pa *projectAnalyzer) runGoCoverage(projectPath string) (*CoverageReport, error) {
+	profile, err := os.CreateTemp("", "vyb-coverage-*.out")
+	if err != nil {
+		return nil, err
+	}
+	profilePath := profile.Name()
+	profile.Close()
+	defer os.Remove(profilePath)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pa.config.Timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "go", "test", "-count=1", "-coverprofile="+profilePath, "./...")
+	cmd.Dir = projectPath
+	// 一部パッケージのテスト失敗でもプロファイルは出力されるため、実行エラーは集計後に判断
+	runErr := cmd.Run()
+
+	f, err := os.Open(profilePath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	report, err := ParseCoverProfile(f)
+	if err != nil {
+		if runErr != nil {
+			return nil, fmt.Errorf("go test 実行エラー: %w", runErr)
+		}
+		return nil, err
+	}
+	return report, nil
+}
+
+// CyclomaticComplexity は関数本体の循環的複雑度を計算（分岐数 + 1）
+func CyclomaticComplexity(fn ast.Node) int {
+	complexity := 1
+	ast.Inspect(fn, func(n ast.Node) bool {
+		switch node := n.(type) {
+		case *ast.FuncLit:
+			// 無名関数は外側の関数に含めて数える
+			return true
+		case *ast.IfStmt, *ast.ForStmt, *ast.RangeStmt:
+			complexity++
+		case *ast.CaseClause:
+			if node.List != nil { // default 節は数えない
+				complexity++
+			}
+		case *ast.CommClause:
+			if node.Comm != nil {
+				complexity++
+			}
+		case *ast.BinaryExpr:
+			if node.Op == token.LAND || node.Op == token.LOR {
+				complexity++
+			}
+		}
+		return true
+	})
+	return complexity
+}
+
+// analyzeGoComplexity はGoソースをASTで解析して関数毎の複雑度を集計
+func (pa *projectAnalyzer) analyzeGoComplexity(projectPath string) (*ComplexitySummary, error) {
+	summary := &ComplexitySummary{}
+	fset := token.NewFileSet()
+	total := 0
+
+	err := ignore.Walk(projectPath, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		relPath, _ := filepath.Rel(projectPath, filePath)
+		if info.IsDir() {
+			if relPath != "." && (strings.HasPrefix(info.Name(), ".") || info.Name() == "vendor" || info.Name() == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(info.Name(), ".go") || strings.HasSuffix(info.Name(), "_test.go") {
+			return nil
+		}
+
+		file, err := parser.ParseFile(fset, filePath, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil // 構文エラーのファイルは集計対象外
+		}
+
+		for _, decl := range file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Body == nil {
+				continue
+			}
+			complexity := CyclomaticComplexity(fn.Body)
+			summary.Functions = append(summary.Functions, FunctionComplexity{
+				Name:       goFuncName(fn),
+				File:       filepath.ToSlash(relPath),
+				Line:       fset.Position(fn.Pos()).Line,
+				Complexity: complexity,
+			})
+			total += complexity
+			if complexity > summary.Max {
+				summary.Max = complexity
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(summary.Functions) > 0 {
+		summary.Average = float64(total) / float64(len(summary.Functions))
+	}
+	sort.SliceStable(summary.Functions, func(i, j int) bool {
+		return summary.Functions[i].Complexity > summary.Functions[j].Complexity
+	})
+
+	return summary, nil
+}
+
+// goFuncName はレシーバー型を含む関数名を返す
+func goFuncName(fn *ast.FuncDecl) string {
+	if fn.Recv == nil || len(fn.Recv.List) == 0 {
+		return fn.Name.Name
+	}
+	recv := fn.Recv.List[0].Type
+	if star, ok := recv.(*ast.StarExpr); ok {
+		recv = star.X
+	}
+	if index, ok := recv.(*ast.IndexExpr); ok {
+		recv = index.X
+	}
+	if ident, ok := recv.(*ast.Ident); ok {
+		return ident.Name + "." + fn.Name.Name
+	}
+	return fn.Name.Name
+}
+
+// コミット単位のメトリクスキャッシュ
+
+// metricsStateKey はHEADコミットと未コミット差分から作業ツリーの状態キーを生成
+func (pa *projectAnalyzer) metricsStateKey(projectPath string) string {
+	head, err := pa.execGitCommand(projectPath, "rev-parse", "HEAD")
+	if err != nil {
+		return "" // Gitリポジトリでなければキャッシュしない
+	}
+
+	hasher := sha256.New()
+	absPath, _ := filepath.Abs(projectPath)
+	hasher.Write([]byte(absPath + "\x00" + strings.TrimSpace(head) + "\x00"))
+
+	// 未コミットの変更があれば差分内容もキーに含める
+	if diff, err := pa.execGitCommand(projectPath, "diff", "HEAD"); err == nil {
+		hasher.Write([]byte(diff))
+	}
+	if untracked, err := pa.execGitCommand(projectPath, "ls-files", "--others", "--exclude-standard"); err == nil {
+		hasher.Write([]byte(untracked))
+	}
+
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// metricsCacheDir はメトリクスキャッシュの保存先
+func metricsCacheDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "vyb-analysis-cache", "metrics")
+	}
+	return filepath.Join(homeDir, ".vyb", "cache", "metrics")
+}
+
+// loadMetricsCache は状態キーに対応する品質メトリクスを読み込み
+func (pa *projectAnalyzer) loadMetricsCache(stateKey string) (*QualityMetrics, bool) {
+	data, err := os.ReadFile(filepath.Join(metricsCacheDir(), stateKey+".json"))
+	if err != nil {
+		return nil, false
+	}
+
+	var metrics QualityMetrics
+	if err := json.Unmarshal(data, &metrics); err != nil {
+		return nil, false
+	}
+	if metrics.Details == nil {
+		metrics.Details = make(map[string]float64)
+	}
+	return &metrics, true
+}
+
+// saveMetricsCache は品質メトリクスを状態キーで保存
+func (pa *projectAnalyzer) saveMetricsCache(stateKey string, metrics *QualityMetrics) error {
+	dir := metricsCacheDir()
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(metrics)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, stateKey+".json"), data, 0644)
+}
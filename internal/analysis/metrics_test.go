@@ -0,0 +1,94 @@
+package analysis
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseCoverProfile(t *testing.T) {
+	profile := `mode: set
+example.com/app/pkg/a.go:3.10,5.2 2 1
+example.com/app/pkg/a.go:7.10,9.2 2 0
+example.com/app/cmd/main.go:5.13,7.2 4 1
+example.com/app/pkg/a.go:7.10,9.2 2 1
+`
+	report, err := ParseCoverProfile(strings.NewReader(profile))
+	if err != nil {
+		t.Fatalf("ParseCoverProfile failed: %v", err)
+	}
+
+	// 重複ブロックは1回だけ数え、いずれかで実行されていればカバー済み
+	if report.Statements != 8 || report.Covered != 8 {
+		t.Errorf("Unexpected totals: statements=%d covered=%d", report.Statements, report.Covered)
+	}
+	if report.Mode != "set" || report.Total != 100 {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+	if _, ok := report.Packages["example.com/app/pkg"]; !ok {
+		t.Errorf("Missing package coverage: %v", report.Packages)
+	}
+
+	partial, err := ParseCoverProfile(strings.NewReader("mode: count\na.go:1.1,2.2 3 0\na.go:3.1,4.2 1 5\n"))
+	if err != nil {
+		t.Fatalf("ParseCoverProfile failed: %v", err)
+	}
+	if math.Abs(partial.Total-25.0) > 0.001 {
+		t.Errorf("Expected 25%% coverage, got %.2f", partial.Total)
+	}
+
+	if _, err := ParseCoverProfile(strings.NewReader("garbage")); err == nil {
+		t.Error("Expected error for invalid profile")
+	}
+}
+
+func TestAnalyzeGoComplexity(t *testing.T) {
+	src := `package p
+
+func simple() int { return 1 }
+
+func branchy(xs []int, ok bool) int {
+	total := 0
+	for _, x := range xs {
+		if x > 0 && ok {
+			total += x
+		}
+	}
+	switch total {
+	case 0:
+		return 0
+	case 1, 2:
+		return 1
+	default:
+		return total
+	}
+}
+`
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "p.go"), []byte(src), 0644); err != nil {
+		t.Fatal(err)
+	}
+	// テストファイルは集計対象外
+	if err := os.WriteFile(filepath.Join(tempDir, "p_test.go"), []byte("package p\n\nfunc helper() { if true {} }\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	pa := NewProjectAnalyzer(nil).(*projectAnalyzer)
+	summary, err := pa.analyzeGoComplexity(tempDir)
+	if err != nil {
+		t.Fatalf("analyzeGoComplexity failed: %v", err)
+	}
+
+	if len(summary.Functions) != 2 {
+		t.Fatalf("Expected 2 functions, got %+v", summary.Functions)
+	}
+	// range(1) + if(1) + &&(1) + case(2) + 基本(1) = 6
+	if top := summary.Functions[0]; top.Name != "branchy" || top.Complexity != 6 || top.Line != 5 {
+		t.Errorf("Unexpected top function: %+v", top)
+	}
+	if summary.Max != 6 || summary.Average != 3.5 {
+		t.Errorf("Unexpected summary: max=%d average=%.2f", summary.Max, summary.Average)
+	}
+}
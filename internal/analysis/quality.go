@@ -9,12 +9,22 @@ import (
 	"strconv"
 	"strings"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // 品質メトリクスの分析実装
 
 // プロジェクトの品質メトリクスを分析
 func (pa *projectAnalyzer) AnalyzeQuality(projectPath string) (*QualityMetrics, error) {
+	// テスト実行を伴うため、同一コミット・同一差分の結果はキャッシュを再利用
+	stateKey := pa.metricsStateKey(projectPath)
+	if stateKey != "" {
+		if cached, ok := pa.loadMetricsCache(stateKey); ok {
+			return cached, nil
+		}
+	}
+
 	metrics := &QualityMetrics{
 		Details: make(map[string]float64),
 	}
@@ -23,10 +33,22 @@ func (pa *projectAnalyzer) AnalyzeQuality(projectPath string) (*QualityMetrics,
 	var err error
 
 	// テストカバレッジ
-	metrics.TestCoverage, _ = pa.calculateTestCoverage(projectPath)
+	metrics.TestCoverage, metrics.CoverageSource, _ = pa.calculateTestCoverage(projectPath, metrics)
 
-	// コード複雑度
-	metrics.CodeComplexity, _ = pa.calculateCodeComplexity(projectPath)
+	// コード複雑度（GoはASTによる関数単位の循環的複雑度）
+	if pa.hasGoMod(projectPath) {
+		if summary, cerr := pa.analyzeGoComplexity(projectPath); cerr == nil {
+			metrics.CodeComplexity = summary.Average
+			metrics.Details["max_complexity"] = float64(summary.Max)
+			metrics.Details["function_count"] = float64(len(summary.Functions))
+			metrics.Hotspots = summary.Functions
+			if len(metrics.Hotspots) > 5 {
+				metrics.Hotspots = metrics.Hotspots[:5]
+			}
+		}
+	} else {
+		metrics.CodeComplexity, _ = pa.calculateCodeComplexity(projectPath)
+	}
 
 	// 保守性スコア
 	metrics.Maintainability, _ = pa.calculateMaintainability(projectPath)
@@ -49,70 +71,51 @@ func (pa *projectAnalyzer) AnalyzeQuality(projectPath string) (*QualityMetrics,
 	// 詳細メトリクス
 	pa.calculateDetailedMetrics(projectPath, metrics)
 
+	if stateKey != "" {
+		pa.saveMetricsCache(stateKey, metrics)
+	}
+
 	return metrics, err
 }
 
-// テストカバレッジを計算
-func (pa *projectAnalyzer) calculateTestCoverage(projectPath string) (float64, error) {
-	// 言語別にテストカバレッジを計算
+// テストカバレッジを計算（カバレッジ値と取得元を返す）
+func (pa *projectAnalyzer) calculateTestCoverage(projectPath string, metrics *QualityMetrics) (float64, string, error) {
+	// 言語別にテストカバレッジを計算（実測できなければファイル比率から推定）
 	if pa.hasGoMod(projectPath) {
-		return pa.calculateGoCoverage(projectPath)
+		if coverage, err := pa.calculateGoCoverage(projectPath, metrics); err == nil {
+			return coverage, CoverageSourceGoTest, nil
+		}
 	}
 
 	if pa.hasPackageJson(projectPath) {
-		return pa.calculateJSCoverage(projectPath)
+		if coverage, err := pa.calculateJSCoverage(projectPath); err == nil && coverage > 0 {
+			return coverage, CoverageSourceJest, nil
+		}
 	}
 
 	if pa.hasPythonProject(projectPath) {
-		return pa.calculatePythonCoverage(projectPath)
+		if coverage, err := pa.calculatePythonCoverage(projectPath); err == nil && coverage > 0 {
+			return coverage, CoverageSourcePytest, nil
+		}
 	}
 
 	// デフォルトはファイルベースの推定
-	return pa.estimateCoverageFromFiles(projectPath)
+	coverage, err := pa.estimateCoverageFromFiles(projectPath)
+	return coverage, CoverageSourceEstimated, err
 }
 
-// Go プロジェクトのテストカバレッジ
-func (pa *projectAnalyzer) calculateGoCoverage(projectPath string) (float64, error) {
-	// go test -coverprofile を実行してカバレッジを取得
-	cmd := exec.Command("go", "test", "-coverprofile=coverage.out", "./...")
-	cmd.Dir = projectPath
-
-	if err := cmd.Run(); err != nil {
-		return 0.0, err
-	}
-
-	// カバレッジファイルを読み込み
-	coverageFile := filepath.Join(projectPath, "coverage.out")
-	defer os.Remove(coverageFile) // 一時ファイルを削除
-
-	if _, err := os.Stat(coverageFile); os.IsNotExist(err) {
-		return 0.0, nil
-	}
-
-	// go tool cover で総カバレッジを計算
-	cmd = exec.Command("go", "tool", "cover", "-func=coverage.out")
-	cmd.Dir = projectPath
-
-	output, err := cmd.Output()
+// Go プロジェクトのテストカバレッジ（カバレッジプロファイルを集計）
+func (pa *projectAnalyzer) calculateGoCoverage(projectPath string, metrics *QualityMetrics) (float64, error) {
+	report, err := pa.runGoCoverage(projectPath)
 	if err != nil {
 		return 0.0, err
 	}
 
-	// 出力から総カバレッジを抽出
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "total:") {
-			parts := strings.Fields(line)
-			if len(parts) >= 3 {
-				coverageStr := strings.TrimSuffix(parts[2], "%")
-				if coverage, err := strconv.ParseFloat(coverageStr, 64); err == nil {
-					return coverage, nil
-				}
-			}
-		}
-	}
+	metrics.Details["coverage_statements"] = float64(report.Statements)
+	metrics.Details["coverage_covered"] = float64(report.Covered)
+	metrics.Details["coverage_packages"] = float64(len(report.Packages))
 
-	return 0.0, nil
+	return report.Total, nil
 }
 
 // JavaScript プロジェクトのテストカバレッジ
@@ -157,7 +160,7 @@ func (pa *projectAnalyzer) estimateCoverageFromFiles(projectPath string) (float6
 	sourceFiles := 0
 	testFiles := 0
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -200,7 +203,7 @@ func (pa *projectAnalyzer) calculateCodeComplexity(projectPath string) (float64,
 	totalComplexity := 0
 	fileCount := 0
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -278,7 +281,7 @@ func (pa *projectAnalyzer) calculateDuplication(projectPath string) (float64, er
 	// ファイル内容をハッシュ化して重複を検出
 	lineHashes := make(map[string]int)
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -322,7 +325,7 @@ func (pa *projectAnalyzer) calculateTechnicalDebt(projectPath string) (time.Dura
 		techDebtRegex[i] = regexp.MustCompile(`(?i)(//|#|<!--).*` + pattern)
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -449,9 +452,11 @@ func (pa *projectAnalyzer) calculateDetailedMetrics(projectPath string, metrics
 		metrics.Details["total_lines"] = float64(lineCount)
 	}
 
-	// 関数数
-	if functionCount, err := pa.countFunctions(projectPath); err == nil {
-		metrics.Details["function_count"] = float64(functionCount)
+	// 関数数（Goは複雑度解析で集計済み）
+	if _, exists := metrics.Details["function_count"]; !exists {
+		if functionCount, err := pa.countFunctions(projectPath); err == nil {
+			metrics.Details["function_count"] = float64(functionCount)
+		}
 	}
 
 	// クラス数
@@ -544,7 +549,7 @@ func (pa *projectAnalyzer) calculateCommentRatio(projectPath string) (float64, e
 	totalLines := 0
 	commentLines := 0
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -709,7 +714,7 @@ func (pa *projectAnalyzer) countCommentIssues(projectPath string) (int, error) {
 		regexp.MustCompile(`(?i)XXX`),
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -752,7 +757,7 @@ func (pa *projectAnalyzer) findLargeFiles(projectPath string) ([]string, error)
 	const maxSize = 10 * 1024 * 1024 // 10MB
 	largeFiles := make([]string, 0)
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -779,7 +784,7 @@ func (pa *projectAnalyzer) countPerformanceIssues(projectPath string) (int, erro
 
 func (pa *projectAnalyzer) countFiles(projectPath string) (int, error) {
 	count := 0
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -791,7 +796,7 @@ func (pa *projectAnalyzer) countFiles(projectPath string) (int, error) {
 
 func (pa *projectAnalyzer) countTotalLines(projectPath string) (int, error) {
 	totalLines := 0
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
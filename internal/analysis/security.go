@@ -8,6 +8,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // セキュリティ分析の実装
@@ -57,7 +59,7 @@ func (pa *projectAnalyzer) scanSecrets(projectPath string) ([]SecurityIssue, err
 		"OAuth Token":       regexp.MustCompile(`(?i)(access[_-]?token|oauth[_-]?token)\s*[:=]\s*["']?([a-zA-Z0-9_-]{20,})`),
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -171,7 +173,7 @@ func (pa *projectAnalyzer) scanVulnerablePatterns(projectPath string) ([]Securit
 		},
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -289,7 +291,7 @@ func (pa *projectAnalyzer) scanDependencyVulns(projectPath string) ([]SecurityIs
 func (pa *projectAnalyzer) scanFilePermissions(projectPath string) ([]SecurityIssue, error) {
 	issues := make([]SecurityIssue, 0)
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
@@ -400,7 +402,7 @@ func (pa *projectAnalyzer) scanSQLInjection(projectPath string) ([]SecurityIssue
 		regexp.MustCompile(`sql\s*=\s*.*\+.*`),
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -465,7 +467,7 @@ func (pa *projectAnalyzer) scanXSS(projectPath string) ([]SecurityIssue, error)
 		regexp.MustCompile(`dangerouslySetInnerHTML`),
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -525,7 +527,7 @@ func (pa *projectAnalyzer) scanPathTraversal(projectPath string) ([]SecurityIssu
 		regexp.MustCompile(`filepath\.Join\s*\(\s*.*\+`),
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -583,7 +585,7 @@ func (pa *projectAnalyzer) scanHardcodedCredentials(projectPath string) ([]Secur
 		"Secret":   regexp.MustCompile(`(?i)(secret|key)\s*[:=]\s*["']([a-zA-Z0-9_-]{10,})["']`),
 	}
 
-	err := filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -96,6 +96,9 @@ type QualityMetrics struct {
 	SecurityScore    float64            `json:"security_score"`
 	PerformanceScore float64            `json:"performance_score"`
 	Details          map[string]float64 `json:"details"`
+
+	CoverageSource string               `json:"coverage_source"`    // カバレッジの取得元（go test, jest, pytest, estimated）
+	Hotspots       []FunctionComplexity `json:"hotspots,omitempty"` // 複雑度の高い関数（上位）
 }
 
 // 技術スタック
@@ -183,14 +183,16 @@ func (ua *UnifiedAnalyzer) AnalyzeProject(ctx context.Context, projectPath strin
 
 	startTime := time.Now()
 
-	// キャッシュチェック
+	// キャッシュチェック（HEAD と未コミットの変更が同じなら前回の結果を使う）
+	key := projectPath
 	if ua.config.EnableCaching {
-		if cached := ua.getCachedResult(projectPath, AnalysisTypeBasic); cached != nil {
-			if result, ok := cached.(*ProjectAnalysis); ok {
-				ua.updateCacheMetrics(true)
-				return result, nil
-			}
+		key = projectCacheKey(ctx, projectPath)
+		if result := ua.cachedProjectLocked(key); result != nil {
+			ua.updateCacheMetrics(true)
+			return result, nil
 		}
+		// 状態が変わったので、サブ分析器の期限付きキャッシュも使わない
+		ua.projectAnalyzer.InvalidateCache(projectPath)
 	}
 
 	// プロジェクト分析を実行
@@ -200,9 +202,10 @@ func (ua *UnifiedAnalyzer) AnalyzeProject(ctx context.Context, projectPath strin
 		return nil, err
 	}
 
-	// キャッシュに保存
+	// キャッシュに保存（同じプロジェクトの古い状態の結果は削除）
 	if ua.config.EnableCaching {
-		ua.cacheResult(projectPath, AnalysisTypeBasic, result)
+		ua.removeProjectEntriesLocked(projectPath)
+		ua.cacheResult(key, AnalysisTypeBasic, result)
 	}
 
 	// メトリクス更新
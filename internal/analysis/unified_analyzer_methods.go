@@ -3,6 +3,7 @@ package analysis
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
@@ -55,6 +56,63 @@ func (ua *UnifiedAnalyzer) InvalidateCache(key string, analysisType AnalysisType
 	return nil
 }
 
+// CachedProject - 現在の状態（HEAD・未コミットの変更）のプロジェクト分析結果があれば返す
+// 分析を待たずにキャッシュだけを確認する用途（無ければ nil）
+func (ua *UnifiedAnalyzer) CachedProject(ctx context.Context, projectPath string) *ProjectAnalysis {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+
+	if !ua.config.EnableCaching {
+		return nil
+	}
+	result := ua.cachedProjectLocked(projectCacheKey(ctx, projectPath))
+	if result != nil {
+		ua.updateCacheMetrics(true)
+	}
+	return result
+}
+
+// InvalidateProjects - 全プロジェクトの分析結果のキャッシュを無効化（ファイル変更の通知時）
+func (ua *UnifiedAnalyzer) InvalidateProjects() {
+	ua.mu.Lock()
+	defer ua.mu.Unlock()
+
+	for key, entry := range ua.cache {
+		if result, ok := entry.Result.(*ProjectAnalysis); ok {
+			delete(ua.cache, key)
+			ua.projectAnalyzer.InvalidateCache(result.ProjectPath)
+		}
+	}
+}
+
+// projectCacheKey はプロジェクト分析のキャッシュキー（git 管理外ならパスのみで、期限まで有効）
+func projectCacheKey(ctx context.Context, projectPath string) string {
+	if workspace := WorkspaceKey(ctx, projectPath); workspace != "" {
+		return projectPath + "@" + workspace
+	}
+	return projectPath
+}
+
+func (ua *UnifiedAnalyzer) cachedProjectLocked(key string) *ProjectAnalysis {
+	if cached := ua.getCachedResult(key, AnalysisTypeBasic); cached != nil {
+		if result, ok := cached.(*ProjectAnalysis); ok {
+			return result
+		}
+	}
+	return nil
+}
+
+// removeProjectEntriesLocked は projectPath の（古い状態のキーを含む）分析結果を削除
+func (ua *UnifiedAnalyzer) removeProjectEntriesLocked(projectPath string) {
+	suffix := fmt.Sprintf("_%d", AnalysisTypeBasic)
+	for key := range ua.cache {
+		trimmed := strings.TrimSuffix(key, suffix)
+		if trimmed == projectPath || strings.HasPrefix(trimmed, projectPath+"@") {
+			delete(ua.cache, key)
+		}
+	}
+}
+
 // GetAnalysisMetrics - 分析メトリクスを取得
 func (ua *UnifiedAnalyzer) GetAnalysisMetrics() *UnifiedAnalysisMetrics {
 	ua.mu.RLock()
@@ -0,0 +1,92 @@
+package analysis
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// workspaceHashMaxBytes を超える変更ファイルは内容ではなくサイズと更新時刻で区別する
+const workspaceHashMaxBytes = 1 << 20
+
+// WorkspaceKey はプロジェクトの状態を表すキー（HEAD のコミット + 未コミットの変更内容のハッシュ）を返す
+// キーが同じならファイルは変わっておらず、プロジェクト分析の結果を再利用できる
+// git 管理外・コミットが無い場合は空文字を返す
+func WorkspaceKey(ctx context.Context, projectPath string) string {
+	revParse, err := workspaceGit(ctx, projectPath, "rev-parse", "--show-toplevel", "HEAD")
+	if err != nil {
+		return ""
+	}
+	lines := strings.Split(strings.TrimSpace(revParse), "\n")
+	if len(lines) != 2 {
+		return ""
+	}
+	top, head := lines[0], lines[1]
+	// porcelain 形式のパスはリポジトリのルートからの相対パス
+	status, err := workspaceGit(ctx, projectPath, "status", "--porcelain", "-z", "--untracked-files=all")
+	if err != nil {
+		return ""
+	}
+
+	hash := sha256.New()
+	for _, path := range dirtyPaths(status) {
+		fmt.Fprintf(hash, "%s\x00", path)
+		hashWorkspaceFile(hash, filepath.Join(top, path))
+	}
+	return head + "+" + hex.EncodeToString(hash.Sum(nil))[:16]
+}
+
+// dirtyPaths は git status --porcelain -z の出力から変更されたファイルのパスを取り出す
+// 名前変更・コピーは変更後のパスのみ返す（続く変更前のパスは読み飛ばす）
+func dirtyPaths(status string) []string {
+	var paths []string
+	fields := strings.Split(status, "\x00")
+	for i := 0; i < len(fields); i++ {
+		entry := fields[i]
+		if len(entry) < 4 {
+			continue
+		}
+		paths = append(paths, entry[3:])
+		if entry[0] == 'R' || entry[0] == 'C' {
+			i++
+		}
+	}
+	return paths
+}
+
+// hashWorkspaceFile はファイルの内容（大きなファイルはサイズと更新時刻）をハッシュに加える
+func hashWorkspaceFile(hash io.Writer, path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		fmt.Fprint(hash, "deleted\x00")
+		return
+	}
+	if !info.Mode().IsRegular() || info.Size() > workspaceHashMaxBytes {
+		fmt.Fprintf(hash, "%d %d\x00", info.Size(), info.ModTime().UnixNano())
+		return
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(hash, "%d %d\x00", info.Size(), info.ModTime().UnixNano())
+		return
+	}
+	defer file.Close()
+	io.Copy(hash, file)
+	fmt.Fprint(hash, "\x00")
+}
+
+func workspaceGit(ctx context.Context, projectPath string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = projectPath
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+	return string(output), nil
+}
@@ -0,0 +1,125 @@
+package analysis
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// gitProject は1コミットを持つ一時リポジトリを作成
+func gitProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git が見つかりません")
+	}
+	root := writeProject(t, files)
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "init"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = root
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	return root
+}
+
+func TestWorkspaceKey(t *testing.T) {
+	root := gitProject(t, map[string]string{"main.go": "package main\n"})
+	ctx := context.Background()
+
+	clean := WorkspaceKey(ctx, root)
+	if clean == "" || WorkspaceKey(ctx, root) != clean {
+		t.Fatalf("変更が無ければ同じキーのはず: %q", clean)
+	}
+
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("main.go", "package main\n\nfunc main() {}\n")
+	dirty := WorkspaceKey(ctx, root)
+	if dirty == clean {
+		t.Error("未コミットの変更でキーが変わるはず")
+	}
+	// 既に変更済みのファイルをさらに編集しても変わる
+	write("main.go", "package main\n\nfunc main() { println() }\n")
+	if WorkspaceKey(ctx, root) == dirty {
+		t.Error("変更済みファイルの再編集でキーが変わるはず")
+	}
+	write("main.go", "package main\n")
+	if WorkspaceKey(ctx, root) != clean {
+		t.Error("元に戻せば同じキーのはず")
+	}
+	write("new.go", "package main\n")
+	if WorkspaceKey(ctx, root) == clean {
+		t.Error("未追跡ファイルの追加でキーが変わるはず")
+	}
+
+	// サブディレクトリからでもリポジトリ全体の状態を見る
+	if err := os.Mkdir(filepath.Join(root, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if WorkspaceKey(ctx, filepath.Join(root, "sub")) != WorkspaceKey(ctx, root) {
+		t.Error("サブディレクトリでも同じキーのはず")
+	}
+
+	if key := WorkspaceKey(ctx, t.TempDir()); key != "" {
+		t.Errorf("git 管理外は空のはず: %q", key)
+	}
+}
+
+func TestDirtyPaths(t *testing.T) {
+	status := " M a.go\x00R  new.go\x00old.go\x00?? dir/b.go\x00"
+	got := dirtyPaths(status)
+	if len(got) != 3 || got[0] != "a.go" || got[1] != "new.go" || got[2] != "dir/b.go" {
+		t.Errorf("dirtyPaths = %q", got)
+	}
+}
+
+func TestUnifiedAnalyzer_ProjectCacheFollowsWorkspace(t *testing.T) {
+	root := gitProject(t, map[string]string{"main.go": "package main\n"})
+	ctx := context.Background()
+	analyzer := NewUnifiedAnalyzer(&config.Config{}, &MockLLMClient{})
+
+	if analyzer.CachedProject(ctx, root) != nil {
+		t.Fatal("分析前はキャッシュが無いはず")
+	}
+	first, err := analyzer.AnalyzeProject(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if analyzer.CachedProject(ctx, root) != first {
+		t.Error("状態が同じならキャッシュを返すはず")
+	}
+
+	if err := os.WriteFile(filepath.Join(root, "util.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if analyzer.CachedProject(ctx, root) != nil {
+		t.Error("ファイルが変わったらキャッシュは使わないはず")
+	}
+	second, err := analyzer.AnalyzeProject(ctx, root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if second == first {
+		t.Error("状態が変わったら再分析するはず")
+	}
+	if len(analyzer.cache) != 1 {
+		t.Errorf("古い状態の結果は削除されるはず: %d 件", len(analyzer.cache))
+	}
+
+	analyzer.InvalidateProjects()
+	if analyzer.CachedProject(ctx, root) != nil {
+		t.Error("無効化後はキャッシュが無いはず")
+	}
+}
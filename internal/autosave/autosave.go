@@ -0,0 +1,267 @@
+package autosave
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/branch"
+	"github.com/glkt/vyb-code/internal/process"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// Snapshot は実行中のセッションの自動保存内容
+type Snapshot struct {
+	SessionID  string    `json:"session_id"`
+	ProjectDir string    `json:"project_dir"`
+	PID        int       `json:"pid"`
+	Model      string    `json:"model,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	SavedAt    time.Time `json:"saved_at"`
+
+	Transcript        *transcript.Transcript `json:"transcript"`
+	PendingSuggestion json.RawMessage        `json:"pending_suggestion,omitempty"` // 未適用の提案（interactive.CodeSuggestion）
+	Branches          *branch.Tree           `json:"branches,omitempty"`           // /branch で分岐した会話（現在の分岐は Transcript）
+}
+
+// Turns は記録されているユーザー入力の数
+func (s *Snapshot) Turns() int {
+	if s.Transcript == nil {
+		return 0
+	}
+	return s.Transcript.Turns()
+}
+
+// Empty は復元する内容がないか
+func (s *Snapshot) Empty() bool {
+	return s.Turns() == 0 && len(s.PendingSuggestion) == 0
+}
+
+// UnansweredInput は応答を得る前に中断された最後の入力（なければ空）
+func (s *Snapshot) UnansweredInput() string {
+	if s.Transcript == nil {
+		return ""
+	}
+	for i := len(s.Transcript.Entries) - 1; i >= 0; i-- {
+		switch entry := s.Transcript.Entries[i]; entry.Kind {
+		case transcript.KindAssistant:
+			return ""
+		case transcript.KindUser:
+			return entry.Content
+		}
+	}
+	return ""
+}
+
+// DefaultDir は自動保存の既定の保存先（~/.vyb/autosave）
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".vyb", "autosave")
+	}
+	return filepath.Join(home, ".vyb", "autosave")
+}
+
+// Store はセッション毎の自動保存ファイル（<dir>/<session>.json）
+// 正常終了したセッションのファイルは削除されるため、残っているものは中断されたセッション
+// 実行中のセッションとの区別には PID と更新時刻（ハートビート）を使う
+type Store struct {
+	dir string
+}
+
+// NewStore は保存先を指定してストアを作成
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(sessionID string) string {
+	return filepath.Join(s.dir, sessionID+".json")
+}
+
+// Save はスナップショットを書き込む（書き込み途中で落ちても壊れないよう一時ファイルから置き換える）
+func (s *Store) Save(snapshot *Snapshot) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("自動保存ディレクトリ作成エラー: %w", err)
+	}
+	data, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("自動保存のJSON変換エラー: %w", err)
+	}
+	tmp, err := os.CreateTemp(s.dir, snapshot.SessionID+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("自動保存エラー: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("自動保存エラー: %w", err)
+	}
+	tmp.Close()
+	if err := os.Rename(tmp.Name(), s.path(snapshot.SessionID)); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("自動保存エラー: %w", err)
+	}
+	return nil
+}
+
+// Touch は更新時刻を進め、セッションが動作中であることを示す
+func (s *Store) Touch(sessionID string) error {
+	now := time.Now()
+	return os.Chtimes(s.path(sessionID), now, now)
+}
+
+// Discard はセッションの自動保存を削除する（正常終了・復元済み・破棄時）
+func (s *Store) Discard(sessionID string) error {
+	err := os.Remove(s.path(sessionID))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+// Interrupted はプロジェクトの中断されたセッションを新しい順に返す
+// 保存したプロセスが動作中で、staleAfter 以内に更新されているものは実行中として除く
+func (s *Store) Interrupted(projectDir string, staleAfter time.Duration) ([]*Snapshot, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshots []*Snapshot
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			continue
+		}
+		if filepath.Clean(snapshot.ProjectDir) != filepath.Clean(projectDir) || snapshot.Empty() {
+			continue
+		}
+		if snapshot.PID != os.Getpid() && process.Alive(snapshot.PID) && time.Since(info.ModTime()) < staleAfter {
+			continue
+		}
+		snapshots = append(snapshots, &snapshot)
+	}
+	sort.Slice(snapshots, func(i, j int) bool { return snapshots[i].SavedAt.After(snapshots[j].SavedAt) })
+	return snapshots, nil
+}
+
+// Prune は maxAge より古い自動保存と書き込み途中の一時ファイルを削除する
+func (s *Store) Prune(maxAge time.Duration) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if time.Since(info.ModTime()) > maxAge || (strings.HasSuffix(entry.Name(), ".tmp") && time.Since(info.ModTime()) > time.Hour) {
+			os.Remove(filepath.Join(s.dir, entry.Name()))
+		}
+	}
+}
+
+// Saver はセッションの状態を定期的に保存する
+// 変更がなければ書き込まず、更新時刻のみ進める
+type Saver struct {
+	store    *Store
+	interval time.Duration
+	snapshot func() (*Snapshot, error)
+
+	mu        sync.Mutex
+	sessionID string
+	last      []byte
+	stop      chan struct{}
+	done      chan struct{}
+}
+
+// NewSaver は snapshot で取得した状態を interval 毎に保存する自動保存を作成
+func NewSaver(store *Store, interval time.Duration, snapshot func() (*Snapshot, error)) *Saver {
+	return &Saver{store: store, interval: interval, snapshot: snapshot}
+}
+
+// Start は定期保存を開始する
+func (a *Saver) Start() {
+	a.stop = make(chan struct{})
+	a.done = make(chan struct{})
+	go func() {
+		defer close(a.done)
+		ticker := time.NewTicker(a.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-a.stop:
+				return
+			case <-ticker.C:
+				a.Save()
+			}
+		}
+	}()
+}
+
+// Save は状態を取得し、前回から変わっていれば保存する
+func (a *Saver) Save() error {
+	snapshot, err := a.snapshot()
+	if err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sessionID != snapshot.SessionID {
+		// セッションが切り替わったら前のセッションの保存は不要
+		if a.sessionID != "" {
+			a.store.Discard(a.sessionID)
+		}
+		a.sessionID = snapshot.SessionID
+		a.last = nil
+	}
+
+	// 保存時刻を除いた内容で変更を判定
+	snapshot.SavedAt = time.Time{}
+	content, err := json.Marshal(snapshot)
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(content, a.last) {
+		return a.store.Touch(snapshot.SessionID)
+	}
+	if snapshot.Empty() {
+		return nil
+	}
+	snapshot.SavedAt = time.Now()
+	if err := a.store.Save(snapshot); err != nil {
+		return err
+	}
+	a.last = content
+	return nil
+}
+
+// Stop は定期保存を止め、正常終了として自動保存を削除する
+func (a *Saver) Stop() {
+	if a.stop != nil {
+		close(a.stop)
+		<-a.done
+		a.stop = nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.sessionID != "" {
+		a.store.Discard(a.sessionID)
+	}
+}
@@ -0,0 +1,112 @@
+package autosave
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// 存在しないプロセスID（中断されたセッションの再現用）
+const deadPID = 99999999
+
+func newSnapshot(sessionID, projectDir string, pid int, inputs ...string) *Snapshot {
+	record := transcript.New(sessionID, "test-model")
+	for _, input := range inputs {
+		record.Add(transcript.Entry{Kind: transcript.KindUser, Content: input, Success: true})
+		record.Add(transcript.Entry{Kind: transcript.KindAssistant, Content: "answer: " + input, Success: true})
+	}
+	return &Snapshot{SessionID: sessionID, ProjectDir: projectDir, PID: pid, SavedAt: time.Now(), Transcript: record}
+}
+
+func TestInterrupted(t *testing.T) {
+	store := NewStore(t.TempDir())
+	project := "/work/project"
+
+	older := newSnapshot("older", project, deadPID, "first")
+	older.SavedAt = time.Now().Add(-time.Hour)
+	for _, snapshot := range []*Snapshot{
+		older,
+		newSnapshot("crashed", project, deadPID, "hello", "fix the bug"),
+		newSnapshot("running", project, os.Getppid(), "still here"),
+		newSnapshot("other", "/work/other", deadPID, "elsewhere"),
+		newSnapshot("empty", project, deadPID),
+	} {
+		if err := store.Save(snapshot); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snapshots, err := store.Interrupted(project, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 || snapshots[0].SessionID != "crashed" || snapshots[1].SessionID != "older" {
+		t.Fatalf("同じプロジェクトの中断されたセッションを新しい順に返すはず: %+v", snapshots)
+	}
+	if snapshots[0].Turns() != 2 {
+		t.Errorf("会話記録を復元するはず: %d", snapshots[0].Turns())
+	}
+
+	// 実行中のプロセスでも更新が途絶えていれば中断とみなす
+	stale := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(store.dir, "running.json"), stale, stale)
+	snapshots, _ = store.Interrupted(project, time.Minute)
+	if len(snapshots) != 3 {
+		t.Errorf("更新が途絶えたセッションも返すはず: %d", len(snapshots))
+	}
+}
+
+func TestUnansweredInput(t *testing.T) {
+	snapshot := newSnapshot("s", "/p", deadPID, "done")
+	if input := snapshot.UnansweredInput(); input != "" {
+		t.Errorf("応答済みなら空のはず: %q", input)
+	}
+	snapshot.Transcript.Add(transcript.Entry{Kind: transcript.KindUser, Content: "in flight"})
+	snapshot.Transcript.Add(transcript.Entry{Kind: transcript.KindTool, Tool: "bash"})
+	if input := snapshot.UnansweredInput(); input != "in flight" {
+		t.Errorf("応答前の入力を返すはず: %q", input)
+	}
+}
+
+func TestSaver(t *testing.T) {
+	store := NewStore(t.TempDir())
+	live := newSnapshot("live", "/p", os.Getpid())
+	saver := NewSaver(store, time.Hour, func() (*Snapshot, error) {
+		snapshot := *live
+		return &snapshot, nil
+	})
+	path := filepath.Join(store.dir, "live.json")
+
+	if err := saver.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("会話がなければ保存しないはず")
+	}
+
+	live.Transcript.Add(transcript.Entry{Kind: transcript.KindUser, Content: "hello", Success: true})
+	if err := saver.Save(); err != nil {
+		t.Fatal(err)
+	}
+	first, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("会話があれば保存するはず: %v", err)
+	}
+
+	// 変更がなければ内容は書き換えない
+	if err := saver.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if second, _ := os.ReadFile(path); string(second) != string(first) {
+		t.Error("変更がなければ書き換えないはず")
+	}
+
+	saver.Start()
+	saver.Stop()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("正常終了時は自動保存を削除するはず")
+	}
+}
@@ -0,0 +1,183 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+)
+
+func TestParse(t *testing.T) {
+	def, err := Parse([]byte(`
+prompt: "Fix this: {{.item}}"
+parallel: 2
+items:
+  - Flaky test in pkg/foo
+  - Flaky test in pkg/foo
+  - id: docs
+    prompt: Update the README
+`), "cleanup")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "cleanup" || def.Base != "HEAD" || def.BranchPrefix != "vyb-batch/cleanup" || def.Parallel != 2 {
+		t.Errorf("既定値を補うはず: %+v", def)
+	}
+	ids := []string{def.Items[0].ID, def.Items[1].ID, def.Items[2].ID}
+	if strings.Join(ids, ",") != "flaky-test-in-pkg-foo,flaky-test-in-pkg-foo-2,docs" {
+		t.Errorf("文字列の項目からIDを作り、重複を避けるはず: %v", ids)
+	}
+	if prompt, _ := def.PromptFor(def.Items[0]); prompt != "Fix this: Flaky test in pkg/foo" {
+		t.Errorf("共通のプロンプトを展開するはず: %q", prompt)
+	}
+	if prompt, _ := def.PromptFor(def.Items[2]); prompt != "Update the README" {
+		t.Errorf("項目のプロンプトを優先するはず: %q", prompt)
+	}
+	log := "template: x.tmpl:3: {{.Values.name}} undefined"
+	if prompt, err := def.PromptFor(Item{ID: "log", Prompt: Literal(log)}); err != nil || prompt != log {
+		t.Errorf("Literal のプロンプトは展開しないはず: %q, %v", prompt, err)
+	}
+	if _, err := def.Select([]string{"docs", "missing"}); err == nil {
+		t.Error("存在しない項目の指定はエラーになるはず")
+	}
+
+	invalid := map[string]string{
+		"項目なし":    "items: []",
+		"ID重複":    "items:\n  - {id: a, item: x}\n  - {id: a, item: y}",
+		"不正なID":   "items:\n  - {id: 'A B', item: x}",
+		"未定義の値":   "prompt: '{{.missing}}'\nitems: [x]",
+		"不正な制限時間": "timeout: soon\nitems: [x]",
+		"未知のキー":   "itemz: [x]",
+	}
+	for name, data := range invalid {
+		if _, err := Parse([]byte(data), "batch"); err == nil {
+			t.Errorf("%s: エラーになるはず", name)
+		}
+	}
+}
+
+// initRepo はコミットが1つある git リポジトリを作る
+func initRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git が見つかりません")
+	}
+	dir := t.TempDir()
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "main"},
+		{"config", "user.name", "test"},
+		{"config", "user.email", "test@example.com"},
+		{"commit", "-q", "--allow-empty", "-m", "initial"},
+	} {
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v エラー: %v %s", args, err, output)
+		}
+	}
+	return dir
+}
+
+func TestRunner(t *testing.T) {
+	repo := initRepo(t)
+	def, err := Parse([]byte(`
+name: demo
+check: test
+items:
+  - {id: add, item: add.txt}
+  - {id: broken, item: broken.txt}
+  - {id: noop, prompt: do nothing}
+  - {id: crash, item: crash.txt}
+`), "demo")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var running, peak int32
+	runner := &Runner{
+		ProjectDir: repo,
+		OutputDir:  filepath.Join(t.TempDir(), "out"),
+		Parallel:   2,
+		Agent: func(ctx context.Context, dir, prompt string, log io.Writer) (*AgentResult, error) {
+			n := atomic.AddInt32(&running, 1)
+			defer atomic.AddInt32(&running, -1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(50 * time.Millisecond)
+			fmt.Fprintln(log, "working on", prompt)
+			if prompt == "do nothing" {
+				return &AgentResult{Message: "nothing to do"}, nil
+			}
+			if err := os.WriteFile(filepath.Join(dir, prompt), []byte(prompt+"\n"), 0644); err != nil {
+				return nil, err
+			}
+			if prompt == "crash.txt" {
+				return &AgentResult{ToolCalls: 1}, errors.New("model error")
+			}
+			return &AgentResult{Message: "done", ToolCalls: 1}, nil
+		},
+		Check: func(ctx context.Context, dir, check string) (bool, string, error) {
+			if _, err := os.Stat(filepath.Join(dir, "broken.txt")); err == nil {
+				return false, "FAIL broken", nil
+			}
+			return true, "", nil
+		},
+	}
+	report, err := runner.Run(context.Background(), def, def.Items)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses := map[string]*ItemResult{}
+	for _, item := range report.Items {
+		statuses[item.ID] = item
+	}
+	if got := statuses["add"]; got.Status != StatusChanged || got.Branch != "vyb-batch/demo/add" || got.Additions != 1 || len(got.Files) != 1 {
+		t.Errorf("変更をブランチに保存するはず: %+v", got)
+	}
+	if got := statuses["broken"]; got.Status != StatusCheckFailed || got.CheckOutput != "FAIL broken" || got.Branch == "" {
+		t.Errorf("チェックに失敗しても変更は残すはず: %+v", got)
+	}
+	if got := statuses["noop"]; got.Status != StatusNoChanges || got.Branch != "" {
+		t.Errorf("変更がなければブランチを作らないはず: %+v", got)
+	}
+	if got := statuses["crash"]; got.Status != StatusFailed || got.Error != "model error" {
+		t.Errorf("エージェントの失敗を記録するはず: %+v", got)
+	}
+	if peak > 2 {
+		t.Errorf("同時実行数を制限するはず: %d", peak)
+	}
+
+	branches, _ := gitexec.Run(context.Background(), repo, "branch", "--format=%(refname:short)")
+	if strings.Join(strings.Fields(branches), ",") != "main,vyb-batch/demo/add,vyb-batch/demo/broken" {
+		t.Errorf("変更のある項目のブランチだけ残すはず: %q", branches)
+	}
+	if worktrees, _ := gitexec.Run(context.Background(), repo, "worktree", "list"); strings.Count(worktrees, "\n") != 1 {
+		t.Errorf("作業ツリーは片付けるはず: %q", worktrees)
+	}
+	patch, err := os.ReadFile(statuses["add"].Patch)
+	if err != nil || !strings.Contains(string(patch), "Subject: [PATCH] demo: add.txt") || !strings.Contains(string(patch), "+add.txt") {
+		t.Errorf("git am で適用できるパッチを書き出すはず: %v %s", err, patch)
+	}
+	if _, err := os.Stat(filepath.Join(runner.OutputDir, "report.json")); err != nil {
+		t.Errorf("レポートを保存するはず: %v", err)
+	}
+
+	// 同じブランチが残っていれば実行前に止める
+	if _, err := runner.Run(context.Background(), def, def.Items[:1]); err == nil {
+		t.Error("既存のブランチがあればエラーになるはず")
+	}
+}
@@ -0,0 +1,218 @@
+package batch
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// 1項目あたりの既定の制限時間
+const defaultTimeout = 15 * time.Minute
+
+// Definition は prompts.yaml のバッチ定義
+type Definition struct {
+	Name         string `yaml:"name" json:"name"`
+	Description  string `yaml:"description,omitempty" json:"description,omitempty"`
+	Prompt       string `yaml:"prompt,omitempty" json:"prompt,omitempty"`               // 項目共通のプロンプト（{{.item}}・{{.id}} を展開）
+	Items        []Item `yaml:"items" json:"items"`                                     // 互いに独立した作業項目
+	Base         string `yaml:"base,omitempty" json:"base,omitempty"`                   // ブランチの起点（既定 HEAD）
+	BranchPrefix string `yaml:"branch_prefix,omitempty" json:"branch_prefix,omitempty"` // 既定 vyb-batch/<name>
+	Parallel     int    `yaml:"parallel,omitempty" json:"parallel,omitempty"`           // 同時に実行する項目数（既定 1）
+	Check        string `yaml:"check,omitempty" json:"check,omitempty"`                 // build・test・lint または任意のコマンド
+	Timeout      string `yaml:"timeout,omitempty" json:"timeout,omitempty"`             // 1項目の制限時間（既定 15m）
+
+	Path string `yaml:"-" json:"path"`
+}
+
+// Item はバッチの1項目（文字列だけなら item として扱う）
+type Item struct {
+	ID     string `yaml:"id,omitempty" json:"id"`
+	Item   string `yaml:"item,omitempty" json:"item,omitempty"`
+	Prompt string `yaml:"prompt,omitempty" json:"prompt,omitempty"` // 指定すれば共通のプロンプトの代わりに使う
+}
+
+// UnmarshalYAML は `- fix the flaky test in foo` のような文字列の項目も受け付ける
+func (i *Item) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		i.Item = node.Value
+		return nil
+	}
+	type plain Item
+	return node.Decode((*plain)(i))
+}
+
+var slugPattern = regexp.MustCompile(`[^a-z0-9]+`)
+
+// slug は項目をブランチ名・ファイル名に使える識別子にする
+func slug(text string) string {
+	s := strings.Trim(slugPattern.ReplaceAllString(strings.ToLower(text), "-"), "-")
+	if len(s) > 40 {
+		s = strings.TrimRight(s[:40], "-")
+	}
+	return s
+}
+
+// Parse はYAMLのバッチ定義を解析・検証する（name がなければ defaultName）
+func Parse(data []byte, defaultName string) (*Definition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var def Definition
+	if err := decoder.Decode(&def); err != nil {
+		return nil, fmt.Errorf("バッチ定義の解析エラー: %w", err)
+	}
+	if def.Name == "" {
+		def.Name = defaultName
+	}
+	if def.Base == "" {
+		def.Base = "HEAD"
+	}
+	if def.BranchPrefix == "" {
+		def.BranchPrefix = "vyb-batch/" + def.Slug()
+	}
+	def.BranchPrefix = strings.TrimSuffix(def.BranchPrefix, "/")
+	if def.Parallel <= 0 {
+		def.Parallel = 1
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Load はYAMLファイルからバッチ定義を読み込む
+func Load(path string) (*Definition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("バッチ定義読み込みエラー: %w", err)
+	}
+	def, err := Parse(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	def.Path = path
+	return def, nil
+}
+
+// Validate は定義を検証し、項目のIDを補う
+func (d *Definition) Validate() error {
+	if d.Slug() == "" {
+		return fmt.Errorf("バッチ名 %q が不正です", d.Name)
+	}
+	if len(d.Items) == 0 {
+		return fmt.Errorf("バッチ %s に項目がありません", d.Name)
+	}
+	if _, err := d.ItemTimeout(); err != nil {
+		return err
+	}
+
+	ids := make(map[string]bool)
+	for i := range d.Items {
+		item := &d.Items[i]
+		where := fmt.Sprintf("items[%d]", i)
+		if item.Item == "" && item.Prompt == "" {
+			return fmt.Errorf("%s: item か prompt を指定してください", where)
+		}
+		if item.Prompt == "" && d.Prompt == "" && strings.TrimSpace(item.Item) == "" {
+			return fmt.Errorf("%s: プロンプトが空です", where)
+		}
+		if item.ID == "" {
+			item.ID = slug(item.Item)
+			if item.ID == "" {
+				item.ID = fmt.Sprintf("item-%d", i+1)
+			}
+			// 自動で付けたIDは連番で重複を避ける
+			base := item.ID
+			for n := 2; ids[item.ID]; n++ {
+				item.ID = fmt.Sprintf("%s-%d", base, n)
+			}
+		} else if item.ID != slug(item.ID) {
+			return fmt.Errorf("%s: ID %q は英小文字・数字・ハイフンのみ使えます", where, item.ID)
+		}
+		if ids[item.ID] {
+			return fmt.Errorf("%s: ID %q が重複しています", where, item.ID)
+		}
+		ids[item.ID] = true
+		if _, err := d.PromptFor(*item); err != nil {
+			return fmt.Errorf("%s: %w", where, err)
+		}
+	}
+	return nil
+}
+
+// ItemTimeout は1項目の制限時間
+func (d *Definition) ItemTimeout() (time.Duration, error) {
+	if d.Timeout == "" {
+		return defaultTimeout, nil
+	}
+	timeout, err := time.ParseDuration(d.Timeout)
+	if err != nil || timeout <= 0 {
+		return 0, fmt.Errorf("timeout %q が不正です（例: 10m）", d.Timeout)
+	}
+	return timeout, nil
+}
+
+// Slug はバッチ名をディレクトリ名に使える形にしたもの
+func (d *Definition) Slug() string {
+	return slug(d.Name)
+}
+
+// Branch は項目の変更を載せるブランチ名
+func (d *Definition) Branch(item Item) string {
+	return d.BranchPrefix + "/" + item.ID
+}
+
+// PromptFor は項目に送るプロンプトを返す（共通のプロンプトがなければ項目そのもの）
+func (d *Definition) PromptFor(item Item) (string, error) {
+	text := item.Prompt
+	if text == "" {
+		text = d.Prompt
+	}
+	if text == "" {
+		return item.Item, nil
+	}
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("テンプレート解析エラー: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, map[string]string{"item": item.Item, "id": item.ID}); err != nil {
+		return "", fmt.Errorf("テンプレート展開エラー: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// Literal はログ等を含むプロンプトがテンプレートとして展開されないよう {{ をエスケープする
+func Literal(text string) string {
+	return strings.ReplaceAll(text, "{{", `{{"{{"}}`)
+}
+
+// Select は指定したIDの項目だけに絞り込む（空なら全項目）
+func (d *Definition) Select(ids []string) ([]Item, error) {
+	if len(ids) == 0 {
+		return d.Items, nil
+	}
+	byID := make(map[string]Item, len(d.Items))
+	for _, item := range d.Items {
+		byID[item.ID] = item
+	}
+	var selected []Item
+	for _, id := range ids {
+		item, ok := byID[id]
+		if !ok {
+			return nil, fmt.Errorf("項目 %s が見つかりません", id)
+		}
+		selected = append(selected, item)
+	}
+	return selected, nil
+}
@@ -0,0 +1,327 @@
+package batch
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+)
+
+// 項目の結果
+const (
+	StatusChanged     = "changed"      // 変更をブランチとパッチに保存した
+	StatusNoChanges   = "no_changes"   // 変更がなかった（ブランチは作らない）
+	StatusCheckFailed = "check_failed" // 変更は保存したがチェックに失敗した
+	StatusFailed      = "failed"       // エージェントの実行に失敗した（変更は捨てる）
+)
+
+// AgentResult はエージェントの1回の実行結果
+type AgentResult struct {
+	Message   string
+	ToolCalls int
+}
+
+// AgentFunc は作業ツリー dir でプロンプトを実行する（進捗ログは log に書く）
+type AgentFunc func(ctx context.Context, dir, prompt string, log io.Writer) (*AgentResult, error)
+
+// CheckFunc は作業ツリー dir でチェックを実行し、失敗なら出力の要約を返す
+type CheckFunc func(ctx context.Context, dir, check string) (passed bool, output string, err error)
+
+// ItemResult は1項目の実行結果
+type ItemResult struct {
+	ID          string    `json:"id"`
+	Prompt      string    `json:"prompt"`
+	Status      string    `json:"status"`
+	Branch      string    `json:"branch,omitempty"`
+	Commit      string    `json:"commit,omitempty"`
+	Patch       string    `json:"patch,omitempty"`
+	Log         string    `json:"log,omitempty"`
+	Files       []string  `json:"files,omitempty"`
+	Additions   int       `json:"additions"`
+	Deletions   int       `json:"deletions"`
+	ToolCalls   int       `json:"tool_calls"`
+	Message     string    `json:"message,omitempty"`
+	Error       string    `json:"error,omitempty"`
+	CheckOutput string    `json:"check_output,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	DurationMs  int64     `json:"duration_ms"`
+}
+
+// Report はバッチ実行の結果（report.json）
+type Report struct {
+	Name       string        `json:"name"`
+	Definition string        `json:"definition,omitempty"`
+	BaseCommit string        `json:"base_commit"`
+	Parallel   int           `json:"parallel"`
+	Check      string        `json:"check,omitempty"`
+	StartedAt  time.Time     `json:"started_at"`
+	DurationMs int64         `json:"duration_ms"`
+	Items      []*ItemResult `json:"items"`
+}
+
+// Count は指定した状態の項目数
+func (r *Report) Count(status string) int {
+	n := 0
+	for _, item := range r.Items {
+		if item.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// Runner はバッチの各項目を専用の git worktree で実行し、項目ごとにブランチとパッチを残す
+type Runner struct {
+	ProjectDir string // 対象の git リポジトリ
+	OutputDir  string // パッチ・ログ・report.json の保存先
+	Agent      AgentFunc
+	Check      CheckFunc       // nil ならチェックしない
+	Parallel   int             // 0 なら定義の parallel
+	OnStart    func(item Item) // 項目の開始時（並列実行では複数のゴルーチンから呼ばれる）
+	OnDone     func(result *ItemResult)
+	// CommitMessage は項目のコミットメッセージ（nil なら件名 + プロンプト）
+	CommitMessage func(item Item, prompt string) string
+
+	worktreeMu sync.Mutex // git worktree add/remove は同じリポジトリで同時に実行できない
+}
+
+// Run は items を実行し、全項目の結果を定義順に返す（ctx の取り消しで残りの項目は失敗になる）
+func (r *Runner) Run(ctx context.Context, def *Definition, items []Item) (*Report, error) {
+	base, err := gitexec.Run(ctx, r.ProjectDir, "rev-parse", "--verify", def.Base+"^{commit}")
+	if err != nil {
+		return nil, fmt.Errorf("起点 %s が見つかりません: %w", def.Base, err)
+	}
+	for _, item := range items {
+		if _, err := gitexec.Run(ctx, r.ProjectDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+def.Branch(item)); err == nil {
+			return nil, fmt.Errorf("ブランチ %s が既に存在します（削除するか branch_prefix を変更してください）", def.Branch(item))
+		}
+	}
+	timeout, err := def.ItemTimeout()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(r.OutputDir, 0755); err != nil {
+		return nil, fmt.Errorf("出力ディレクトリ作成エラー: %w", err)
+	}
+	worktrees, err := os.MkdirTemp("", "vyb-batch-")
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリ作成エラー: %w", err)
+	}
+	defer os.RemoveAll(worktrees)
+
+	parallel := r.Parallel
+	if parallel <= 0 {
+		parallel = def.Parallel
+	}
+	report := &Report{
+		Name:       def.Name,
+		Definition: def.Path,
+		BaseCommit: strings.TrimSpace(base),
+		Parallel:   parallel,
+		Check:      def.Check,
+		StartedAt:  time.Now(),
+		Items:      make([]*ItemResult, len(items)),
+	}
+
+	slots := make(chan struct{}, parallel)
+	var wg sync.WaitGroup
+	for i, item := range items {
+		wg.Add(1)
+		go func(i int, item Item) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			if r.OnStart != nil {
+				r.OnStart(item)
+			}
+			itemCtx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			result := r.runItem(itemCtx, def, item, report.BaseCommit, filepath.Join(worktrees, item.ID))
+			report.Items[i] = result
+			if r.OnDone != nil {
+				r.OnDone(result)
+			}
+		}(i, item)
+	}
+	wg.Wait()
+
+	report.DurationMs = time.Since(report.StartedAt).Milliseconds()
+	if err := r.saveReport(report); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// runItem は1項目を worktree で実行し、変更をコミットしてパッチを書き出す
+func (r *Runner) runItem(ctx context.Context, def *Definition, item Item, base, worktree string) *ItemResult {
+	result := &ItemResult{ID: item.ID, StartedAt: time.Now()}
+	defer func() { result.DurationMs = time.Since(result.StartedAt).Milliseconds() }()
+	fail := func(status string, err error) *ItemResult {
+		result.Status = status
+		result.Error = err.Error()
+		return result
+	}
+
+	prompt, err := def.PromptFor(item)
+	if err != nil {
+		return fail(StatusFailed, err)
+	}
+	result.Prompt = prompt
+	if err := ctx.Err(); err != nil {
+		return fail(StatusFailed, err)
+	}
+
+	branch := def.Branch(item)
+	r.worktreeMu.Lock()
+	_, err = gitexec.Run(ctx, r.ProjectDir, "worktree", "add", "-q", "-b", branch, worktree, base)
+	r.worktreeMu.Unlock()
+	if err != nil {
+		return fail(StatusFailed, err)
+	}
+	keepBranch := false
+	defer func() {
+		// 取り消し後も片付けられるよう ctx とは独立に実行する
+		cleanup := context.Background()
+		r.worktreeMu.Lock()
+		gitexec.Run(cleanup, r.ProjectDir, "worktree", "remove", "--force", worktree)
+		r.worktreeMu.Unlock()
+		if !keepBranch {
+			gitexec.Run(cleanup, r.ProjectDir, "branch", "-D", branch)
+		}
+	}()
+
+	logPath := filepath.Join(r.OutputDir, item.ID+".log")
+	logFile, err := os.Create(logPath)
+	if err != nil {
+		return fail(StatusFailed, fmt.Errorf("ログ作成エラー: %w", err))
+	}
+	defer logFile.Close()
+	result.Log = logPath
+
+	agentResult, err := r.Agent(ctx, worktree, prompt, logFile)
+	if agentResult != nil {
+		result.Message = agentResult.Message
+		result.ToolCalls = agentResult.ToolCalls
+	}
+	if err != nil {
+		return fail(StatusFailed, err)
+	}
+
+	if _, err := gitexec.Run(ctx, worktree, "add", "-A"); err != nil {
+		return fail(StatusFailed, err)
+	}
+	if err := r.diffStat(ctx, worktree, result); err != nil {
+		return fail(StatusFailed, err)
+	}
+	if len(result.Files) == 0 {
+		result.Status = StatusNoChanges
+		return result
+	}
+
+	result.Status = StatusChanged
+	if def.Check != "" && r.Check != nil {
+		passed, output, err := r.Check(ctx, worktree, def.Check)
+		if err != nil {
+			return fail(StatusFailed, fmt.Errorf("チェック実行エラー: %w", err))
+		}
+		if !passed {
+			result.Status = StatusCheckFailed
+			result.CheckOutput = output
+		}
+	}
+
+	message := commitMessage(def, item, prompt)
+	if r.CommitMessage != nil {
+		message = r.CommitMessage(item, prompt)
+	}
+	if err := commit(ctx, worktree, message); err != nil {
+		return fail(StatusFailed, err)
+	}
+	commitHash, err := gitexec.Run(ctx, worktree, "rev-parse", "HEAD")
+	if err != nil {
+		return fail(StatusFailed, err)
+	}
+	patch, err := gitexec.Run(ctx, worktree, "format-patch", "-1", "--stdout", "HEAD")
+	if err != nil {
+		return fail(StatusFailed, err)
+	}
+	patchPath := filepath.Join(r.OutputDir, item.ID+".patch")
+	if err := os.WriteFile(patchPath, []byte(patch), 0644); err != nil {
+		return fail(StatusFailed, fmt.Errorf("パッチ保存エラー: %w", err))
+	}
+
+	keepBranch = true
+	result.Branch = branch
+	result.Commit = strings.TrimSpace(commitHash)
+	result.Patch = patchPath
+	return result
+}
+
+// diffStat はステージした変更のファイルと追加・削除行数を記録する
+func (r *Runner) diffStat(ctx context.Context, worktree string, result *ItemResult) error {
+	out, err := gitexec.Run(ctx, worktree, "diff", "--cached", "--numstat")
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) != 3 {
+			continue
+		}
+		// バイナリファイルは "-" になる
+		added, _ := strconv.Atoi(fields[0])
+		deleted, _ := strconv.Atoi(fields[1])
+		result.Additions += added
+		result.Deletions += deleted
+		result.Files = append(result.Files, fields[2])
+	}
+	return nil
+}
+
+// commitMessage は項目のコミットメッセージ（件名 + プロンプト）
+func commitMessage(def *Definition, item Item, prompt string) string {
+	subject := item.Item
+	if subject == "" {
+		subject = item.ID
+	}
+	if i := strings.IndexByte(subject, '\n'); i >= 0 {
+		subject = subject[:i]
+	}
+	return fmt.Sprintf("%s: %s\n\n%s\n", def.Name, subject, strings.TrimSpace(prompt))
+}
+
+// commit はステージした変更をコミットする（ユーザー名が未設定の環境では vyb の名前を使う）
+func commit(ctx context.Context, dir, message string) error {
+	args := []string{"commit", "-q", "--no-verify", "-F", "-"}
+	if email, _ := gitexec.Run(ctx, dir, "config", "user.email"); strings.TrimSpace(email) == "" {
+		args = append([]string{"-c", "user.name=vyb", "-c", "user.email=vyb@localhost"}, args...)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = strings.NewReader(message)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("git commit: %s", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+func (r *Runner) saveReport(report *Report) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(r.OutputDir, "report.json"), data, 0644); err != nil {
+		return fmt.Errorf("レポート保存エラー: %w", err)
+	}
+	return nil
+}
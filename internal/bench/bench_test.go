@@ -0,0 +1,173 @@
+package bench
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// fakeStreamer は課題毎に決まった回答をチャンクに分けて返すテスト用プロバイダー
+type fakeStreamer struct {
+	answers map[string]string // プロンプトの先頭 → 回答
+	missing string            // 読み込めないモデル
+	delay   time.Duration     // 最初のチャンクまでの遅延
+}
+
+func (f *fakeStreamer) answer(req llm.ChatRequest) (string, error) {
+	if req.Model == f.missing {
+		return "", errors.New("model not found")
+	}
+	prompt := req.Messages[0].Content
+	for prefix, answer := range f.answers {
+		if strings.HasPrefix(prompt, prefix) {
+			return answer, nil
+		}
+	}
+	return "OK", nil
+}
+
+func (f *fakeStreamer) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	answer, err := f.answer(req)
+	if err != nil {
+		return nil, err
+	}
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: answer}}, nil
+}
+
+func (f *fakeStreamer) ChatStream(ctx context.Context, req llm.ChatRequest, onChunk func(chunk *llm.ChatResponse)) (*llm.ChatResponse, error) {
+	answer, err := f.answer(req)
+	if err != nil {
+		return nil, err
+	}
+	time.Sleep(f.delay)
+	for _, word := range strings.SplitAfter(answer, " ") {
+		onChunk(&llm.ChatResponse{Message: llm.ChatMessage{Content: word}})
+	}
+	time.Sleep(f.delay)
+	return &llm.ChatResponse{
+		Message:         llm.ChatMessage{Role: "assistant", Content: answer},
+		EvalCount:       40,
+		PromptEvalCount: 10,
+	}, nil
+}
+
+func (f *fakeStreamer) SupportsFunctionCalling() bool { return false }
+func (f *fakeStreamer) GetModelInfo(model string) (*llm.ModelInfo, error) {
+	return &llm.ModelInfo{Name: model}, nil
+}
+func (f *fakeStreamer) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+// TestEvaluate は回答の品質ヒューリスティックをテストする
+func TestEvaluate(t *testing.T) {
+	task := DefaultTasks()[0] // write-function
+	good := "```go\nfunc Reverse(s string) string {\n\tr := []rune(s)\n\tfor i, j := 0, len(r)-1; i < j; i, j = i+1, j-1 {\n\t\tr[i], r[j] = r[j], r[i]\n\t}\n\treturn string(r)\n}\n```"
+	if score := Score(task.Evaluate(good)); score != 1.0 {
+		t.Errorf("score = %v, checks = %+v", score, task.Evaluate(good))
+	}
+
+	// コードブロックが無く構文も壊れている回答
+	bad := "func Reverse(s string) string { return s"
+	checks := task.Evaluate(bad)
+	for _, check := range checks {
+		if (check.Name == "code_block" || check.Name == "go_syntax") && check.Passed {
+			t.Errorf("%s は不合格のはず", check.Name)
+		}
+	}
+	if score := Score(checks); score >= 1.0 || score <= 0 {
+		t.Errorf("score = %v", score)
+	}
+
+	// "a|b" はいずれかを含めば合格、長すぎる回答は不合格
+	shell := Task{Expect: []string{"-mtime|-mmin"}, MaxChars: 20}
+	checks = shell.Evaluate("find . -mmin -1440 -name '*.go'")
+	if !checks[0].Passed || checks[1].Passed {
+		t.Errorf("checks = %+v", checks)
+	}
+
+	if _, err := SelectTasks(DefaultTasks(), []string{"nope"}); err == nil {
+		t.Error("不明な課題はエラーのはず")
+	}
+}
+
+// TestRunner は計測（TTFT・トークン/秒・品質）と読み込めないモデルの扱いをテストする
+func TestRunner(t *testing.T) {
+	tasks, err := SelectTasks(DefaultTasks(), []string{"explain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &fakeStreamer{
+		answers: map[string]string{"In at most": "Add sets the counter, Done decrements it and Wait blocks until it reaches zero."},
+		missing: "missing:7b",
+		delay:   20 * time.Millisecond,
+	}
+	var seen int
+	runner := &Runner{Provider: provider, Tasks: tasks, Repeat: 2, Warmup: true, OnResult: func(Result) { seen++ }}
+
+	run, err := runner.Run(context.Background(), []string{"qwen2.5-coder:7b", "missing:7b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if seen != 3 || len(run.Results) != 3 {
+		t.Fatalf("results = %+v", run.Results)
+	}
+	result := run.Results[0]
+	if !result.Streamed || result.TTFTMs < 15 || result.DurationMs < result.TTFTMs+15 {
+		t.Errorf("ttft = %d, duration = %d", result.TTFTMs, result.DurationMs)
+	}
+	if result.CompletionTokens != 40 || result.TokensEstimated || result.TokensPerSecond <= 0 {
+		t.Errorf("result = %+v", result)
+	}
+	if result.Score != 1.0 {
+		t.Errorf("checks = %+v", result.Checks)
+	}
+
+	summary := run.Summary("qwen2.5-coder:7b")
+	if summary == nil || summary.Results != 2 || summary.AvgScore != 1.0 {
+		t.Errorf("summary = %+v", summary)
+	}
+	// 読み込めないモデルは課題を実行せずウォームアップの失敗として記録
+	if run.Summary("missing:7b") != nil || run.Results[2].Task != "warmup" || run.Results[2].Error == "" {
+		t.Errorf("results = %+v", run.Results)
+	}
+}
+
+// TestStoreCompare は保存・一覧と、モデル毎の直近の集計の比較をテストする
+func TestStoreCompare(t *testing.T) {
+	store := NewStore(t.TempDir())
+	older := &Run{ID: "older", StartedAt: time.Now().Add(-time.Hour), Models: []ModelSummary{
+		{Model: "a", Results: 6, AvgScore: 0.5},
+		{Model: "b", Results: 6, AvgScore: 0.9},
+	}}
+	newer := &Run{ID: "newer", StartedAt: time.Now(), Models: []ModelSummary{
+		{Model: "a", Results: 6, AvgScore: 1.0},
+		{Model: "c", Failures: 6},
+	}}
+	for _, run := range []*Run{older, newer} {
+		if _, err := store.Save(run); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	runs, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs) != 2 || runs[0].ID != "newer" {
+		t.Fatalf("runs = %+v", runs)
+	}
+
+	comparisons := Compare(runs)
+	if len(comparisons) != 2 {
+		t.Fatalf("comparisons = %+v", comparisons)
+	}
+	if comparisons[0].Model != "a" || comparisons[0].RunID != "newer" || comparisons[0].Runs != 2 {
+		t.Errorf("comparisons[0] = %+v", comparisons[0])
+	}
+	if comparisons[1].Model != "b" || comparisons[1].RunID != "older" {
+		t.Errorf("comparisons[1] = %+v", comparisons[1])
+	}
+}
@@ -0,0 +1,243 @@
+package bench
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/usage"
+)
+
+// warmupPrompt はモデルの読み込みを済ませるための短いリクエスト（計測から除く）
+const warmupPrompt = "Reply with OK."
+
+// Streamer はストリーミングで応答できるプロバイダー（最初のトークンまでの時間を測れる）
+type Streamer interface {
+	ChatStream(ctx context.Context, req llm.ChatRequest, onChunk func(chunk *llm.ChatResponse)) (*llm.ChatResponse, error)
+}
+
+// Result は1つの課題の1回の計測結果
+type Result struct {
+	Model            string  `json:"model"`
+	Task             string  `json:"task"`
+	Category         string  `json:"category,omitempty"`
+	Attempt          int     `json:"attempt"`
+	TTFTMs           int64   `json:"ttft_ms"` // 最初のトークンまでの時間（ストリーミングできなければ応答全体の時間）
+	DurationMs       int64   `json:"duration_ms"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TokensEstimated  bool    `json:"tokens_estimated,omitempty"` // プロバイダーがトークン数を返さず推定した
+	TokensPerSecond  float64 `json:"tokens_per_second"`
+	Streamed         bool    `json:"streamed"`
+	Score            float64 `json:"score"`
+	Checks           []Check `json:"checks,omitempty"`
+	Error            string  `json:"error,omitempty"`
+}
+
+// ModelSummary はモデル毎の集計（失敗した計測は平均に含めない）
+type ModelSummary struct {
+	Model           string  `json:"model"`
+	Results         int     `json:"results"`
+	Failures        int     `json:"failures"`
+	LoadMs          int64   `json:"load_ms"` // ウォームアップ（モデルの読み込みを含む）の所要時間
+	AvgTTFTMs       int64   `json:"avg_ttft_ms"`
+	AvgTokensPerSec float64 `json:"avg_tokens_per_second"`
+	AvgScore        float64 `json:"avg_score"`
+}
+
+// Run は1回のベンチマークの実行記録
+type Run struct {
+	ID        string         `json:"id"`
+	StartedAt time.Time      `json:"started_at"`
+	Host      string         `json:"host,omitempty"`
+	Provider  string         `json:"provider,omitempty"`
+	BaseURL   string         `json:"base_url,omitempty"`
+	Tasks     []string       `json:"tasks"`
+	Repeat    int            `json:"repeat"`
+	Models    []ModelSummary `json:"models"`
+	Results   []Result       `json:"results"`
+}
+
+// Summary はモデルの集計を返す（無ければ nil）
+func (r *Run) Summary(model string) *ModelSummary {
+	for i := range r.Models {
+		if r.Models[i].Model == model {
+			return &r.Models[i]
+		}
+	}
+	return nil
+}
+
+// Runner は課題をモデル毎に実行して計測する
+type Runner struct {
+	Provider llm.Provider
+	Tasks    []Task
+	Repeat   int          // 課題毎の実行回数（1未満なら1）
+	Warmup   bool         // 計測前にモデルを読み込ませる
+	OnResult func(Result) // 計測の度に呼ばれる（進捗表示）
+}
+
+// Run は各モデルで全ての課題を実行する（中断された場合は途中までの記録とエラーを返す）
+func (r *Runner) Run(ctx context.Context, models []string) (*Run, error) {
+	repeat := r.Repeat
+	if repeat < 1 {
+		repeat = 1
+	}
+	run := &Run{
+		ID:        time.Now().Format("20060102-150405"),
+		StartedAt: time.Now(),
+		Repeat:    repeat,
+	}
+	for _, task := range r.Tasks {
+		run.Tasks = append(run.Tasks, task.Name)
+	}
+
+	for _, model := range models {
+		summary := ModelSummary{Model: model}
+		if r.Warmup {
+			start := time.Now()
+			if _, err := r.Provider.Chat(ctx, r.request(model, warmupPrompt)); err != nil {
+				if ctx.Err() != nil {
+					return r.finish(run), ctx.Err()
+				}
+				// 読み込めないモデル（未取得等）は課題を実行せずに失敗として記録
+				result := Result{Model: model, Task: "warmup", Error: err.Error()}
+				run.Results = append(run.Results, result)
+				r.notify(result)
+				continue
+			}
+			summary.LoadMs = time.Since(start).Milliseconds()
+		}
+		run.Models = append(run.Models, summary)
+
+		for _, task := range r.Tasks {
+			for attempt := 1; attempt <= repeat; attempt++ {
+				result := r.measure(ctx, model, task)
+				result.Attempt = attempt
+				if ctx.Err() != nil {
+					return r.finish(run), ctx.Err()
+				}
+				run.Results = append(run.Results, result)
+				r.notify(result)
+			}
+		}
+	}
+	return r.finish(run), nil
+}
+
+// measure は課題を1回実行し、時間・トークン数・品質を計測する
+func (r *Runner) measure(ctx context.Context, model string, task Task) Result {
+	result := Result{Model: model, Task: task.Name, Category: task.Category}
+	req := r.request(model, task.Prompt)
+
+	start := time.Now()
+	var firstToken time.Duration
+	var resp *llm.ChatResponse
+	var err error
+	if streamer, ok := r.Provider.(Streamer); ok {
+		result.Streamed = true
+		resp, err = streamer.ChatStream(ctx, req, func(chunk *llm.ChatResponse) {
+			if firstToken == 0 && chunk.Message.Content != "" {
+				firstToken = time.Since(start)
+			}
+		})
+	} else {
+		resp, err = r.Provider.Chat(ctx, req)
+	}
+	duration := time.Since(start)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if firstToken == 0 {
+		firstToken = duration
+	}
+
+	answer := resp.Message.Content
+	result.TTFTMs = firstToken.Milliseconds()
+	result.DurationMs = duration.Milliseconds()
+	result.PromptTokens = resp.PromptTokens()
+	result.CompletionTokens = resp.CompletionTokens()
+	if result.CompletionTokens == 0 {
+		result.CompletionTokens = usage.EstimateTokens(answer)
+		result.TokensEstimated = true
+	}
+	// 生成速度は最初のトークン以降の時間で測る（ストリーミングできなければ応答全体）
+	generation := duration - firstToken
+	if generation <= 0 {
+		generation = duration
+	}
+	if generation > 0 {
+		result.TokensPerSecond = float64(result.CompletionTokens) / generation.Seconds()
+	}
+	result.Checks = task.Evaluate(answer)
+	result.Score = Score(result.Checks)
+	return result
+}
+
+// request は計測用のリクエスト（結果を比較できるよう温度は0）
+func (r *Runner) request(model, prompt string) llm.ChatRequest {
+	temperature := 0.0
+	return llm.ChatRequest{
+		Model:       model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	}
+}
+
+func (r *Runner) notify(result Result) {
+	if r.OnResult != nil {
+		r.OnResult(result)
+	}
+}
+
+// finish はモデル毎の集計を計算する
+func (r *Runner) finish(run *Run) *Run {
+	for i := range run.Models {
+		summary := &run.Models[i]
+		var ttft int64
+		var tps, score float64
+		for _, result := range run.Results {
+			if result.Model != summary.Model {
+				continue
+			}
+			if result.Error != "" {
+				summary.Failures++
+				continue
+			}
+			summary.Results++
+			ttft += result.TTFTMs
+			tps += result.TokensPerSecond
+			score += result.Score
+		}
+		if summary.Results > 0 {
+			summary.AvgTTFTMs = ttft / int64(summary.Results)
+			summary.AvgTokensPerSec = tps / float64(summary.Results)
+			summary.AvgScore = score / float64(summary.Results)
+		}
+	}
+	return run
+}
+
+// FormatSummary はモデル毎の集計の表
+func FormatSummary(models []ModelSummary) string {
+	out := fmt.Sprintf("  %-32s %8s %8s %9s %7s %s\n", "MODEL", "LOAD", "TTFT", "TOK/S", "SCORE", "FAILED")
+	for _, summary := range models {
+		out += fmt.Sprintf("  %-32s %8s %8s %9.1f %6.0f%% %d/%d\n", summary.Model,
+			formatMs(summary.LoadMs), formatMs(summary.AvgTTFTMs), summary.AvgTokensPerSec,
+			summary.AvgScore*100, summary.Failures, summary.Results+summary.Failures)
+	}
+	return out
+}
+
+// formatMs はミリ秒を 850ms / 1.2s 形式で表示
+func formatMs(ms int64) string {
+	if ms == 0 {
+		return "-"
+	}
+	if ms < 1000 {
+		return fmt.Sprintf("%dms", ms)
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
@@ -0,0 +1,114 @@
+package bench
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store はベンチマークの実行記録の保存先（1回の実行を1つのJSONファイルに保存）
+type Store struct {
+	dir string
+}
+
+// NewStore は保存先ディレクトリを指定してストアを作成
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+// DefaultDir はベンチマーク結果の保存先（~/.vyb/bench）
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".vyb", "bench")
+	}
+	return filepath.Join(home, ".vyb", "bench")
+}
+
+// Save は実行記録を保存し、保存先のパスを返す
+func (s *Store) Save(run *Run) (string, error) {
+	if err := os.MkdirAll(s.dir, 0755); err != nil {
+		return "", fmt.Errorf("ベンチマーク保存先の作成エラー: %w", err)
+	}
+	data, err := json.MarshalIndent(run, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("ベンチマーク結果のJSON変換エラー: %w", err)
+	}
+	path := filepath.Join(s.dir, run.ID+".json")
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return "", fmt.Errorf("ベンチマーク結果の保存エラー: %w", err)
+	}
+	return path, nil
+}
+
+// List は保存済みの実行記録を新しい順に返す（読めないファイルは飛ばす）
+func (s *Store) List() ([]*Run, error) {
+	entries, err := os.ReadDir(s.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ベンチマーク結果の読み込みエラー: %w", err)
+	}
+	var runs []*Run
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(s.dir, entry.Name()))
+		if err != nil {
+			continue
+		}
+		var run Run
+		if json.Unmarshal(data, &run) != nil {
+			continue
+		}
+		runs = append(runs, &run)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartedAt.After(runs[j].StartedAt) })
+	return runs, nil
+}
+
+// Comparison はモデル毎の直近の計測（vyb bench compare）
+type Comparison struct {
+	ModelSummary
+	RunID string    `json:"run_id"`
+	RunAt time.Time `json:"run_at"`
+	Runs  int       `json:"runs"` // このモデルを計測した実行の数
+}
+
+// Compare はモデル毎に直近の実行の集計を返す（平均スコアの高い順）
+func Compare(runs []*Run) []Comparison {
+	latest := make(map[string]*Comparison)
+	for _, run := range runs {
+		for _, summary := range run.Models {
+			if summary.Results == 0 {
+				continue
+			}
+			current, ok := latest[summary.Model]
+			if !ok {
+				latest[summary.Model] = &Comparison{ModelSummary: summary, RunID: run.ID, RunAt: run.StartedAt, Runs: 1}
+				continue
+			}
+			current.Runs++
+			if run.StartedAt.After(current.RunAt) {
+				current.ModelSummary, current.RunID, current.RunAt = summary, run.ID, run.StartedAt
+			}
+		}
+	}
+	comparisons := make([]Comparison, 0, len(latest))
+	for _, comparison := range latest {
+		comparisons = append(comparisons, *comparison)
+	}
+	sort.Slice(comparisons, func(i, j int) bool {
+		if comparisons[i].AvgScore != comparisons[j].AvgScore {
+			return comparisons[i].AvgScore > comparisons[j].AvgScore
+		}
+		return comparisons[i].AvgTokensPerSec > comparisons[j].AvgTokensPerSec
+	})
+	return comparisons
+}
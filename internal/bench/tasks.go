@@ -0,0 +1,157 @@
+package bench
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"regexp"
+	"strings"
+)
+
+// Task はベンチマークの1つのコーディング課題
+type Task struct {
+	Name     string   `json:"name"`
+	Category string   `json:"category"`
+	Prompt   string   `json:"prompt"`
+	Language string   `json:"language,omitempty"`  // コードで答える課題の言語（go なら構文も確認）
+	Expect   []string `json:"expect,omitempty"`    // 回答に含むべき語（"a|b" はいずれか、大文字小文字を区別しない）
+	MaxChars int      `json:"max_chars,omitempty"` // 簡潔さの上限（0 なら確認しない）
+}
+
+// Check は回答の品質の確認項目の結果
+type Check struct {
+	Name   string `json:"name"`
+	Passed bool   `json:"passed"`
+}
+
+// DefaultTasks は標準の課題（マシン・モデル間で比較できるよう固定）
+func DefaultTasks() []Task {
+	return []Task{
+		{
+			Name:     "write-function",
+			Category: "generation",
+			Prompt:   "Write a Go function `Reverse(s string) string` that reverses a string, handling multi-byte UTF-8 characters correctly. Reply with the code only.",
+			Language: "go",
+			Expect:   []string{"func Reverse", "rune"},
+		},
+		{
+			Name:     "fix-bug",
+			Category: "debugging",
+			Prompt: "This Go function panics with an index out of range error. Fix it and reply with the corrected code.\n\n" +
+				"```go\nfunc Sum(xs []int) int {\n\ttotal := 0\n\tfor i := 0; i <= len(xs); i++ {\n\t\ttotal += xs[i]\n\t}\n\treturn total\n}\n```",
+			Language: "go",
+			Expect:   []string{"func Sum", "< len(xs)|range xs"},
+		},
+		{
+			Name:     "write-test",
+			Category: "testing",
+			Prompt:   "Write a table-driven Go test for `func Add(a, b int) int` in package calc, covering negative numbers and zero. Reply with the code only.",
+			Language: "go",
+			Expect:   []string{"func TestAdd", "testing.T", "-"},
+		},
+		{
+			Name:     "refactor",
+			Category: "refactoring",
+			Prompt: "Refactor this Go function to use early returns instead of nested ifs, keeping the behaviour. Reply with the code only.\n\n" +
+				"```go\nfunc Discount(age int, member bool) float64 {\n\tif age >= 0 {\n\t\tif member {\n\t\t\tif age >= 65 {\n\t\t\t\treturn 0.3\n\t\t\t} else {\n\t\t\t\treturn 0.1\n\t\t\t}\n\t\t} else {\n\t\t\treturn 0\n\t\t}\n\t} else {\n\t\treturn 0\n\t}\n}\n```",
+			Language: "go",
+			Expect:   []string{"func Discount", "!member|member =="},
+		},
+		{
+			Name:     "explain",
+			Category: "explanation",
+			Prompt:   "In at most three sentences, explain what Go's sync.WaitGroup is for and how Add, Done and Wait are used.",
+			Expect:   []string{"Add", "Done", "Wait"},
+			MaxChars: 800,
+		},
+		{
+			Name:     "shell",
+			Category: "commands",
+			Prompt:   "Give a single shell command that lists all .go files under the current directory modified in the last 24 hours. Reply with the command only.",
+			Expect:   []string{"find", "-mtime|-mmin|-newermt", ".go"},
+			MaxChars: 300,
+		},
+	}
+}
+
+// SelectTasks は名前で課題を絞り込む（names が空なら全て）
+func SelectTasks(tasks []Task, names []string) ([]Task, error) {
+	if len(names) == 0 {
+		return tasks, nil
+	}
+	byName := make(map[string]Task, len(tasks))
+	for _, task := range tasks {
+		byName[task.Name] = task
+	}
+	selected := make([]Task, 0, len(names))
+	for _, name := range names {
+		task, ok := byName[name]
+		if !ok {
+			return nil, fmt.Errorf("不明な課題です: %s", name)
+		}
+		selected = append(selected, task)
+	}
+	return selected, nil
+}
+
+// codeBlockPattern は回答中のコードブロック
+var codeBlockPattern = regexp.MustCompile("(?s)```([A-Za-z0-9_+-]*)\\n(.*?)```")
+
+// Evaluate は回答を課題の品質ヒューリスティック（コードブロック・構文・期待する語・簡潔さ）で確認する
+func (t Task) Evaluate(answer string) []Check {
+	var checks []Check
+	if t.Language != "" {
+		code, found := extractCode(answer)
+		checks = append(checks, Check{Name: "code_block", Passed: found})
+		if t.Language == "go" {
+			checks = append(checks, Check{Name: "go_syntax", Passed: parsesAsGo(code)})
+		}
+	}
+	lower := strings.ToLower(answer)
+	for _, expect := range t.Expect {
+		passed := false
+		for _, alternative := range strings.Split(expect, "|") {
+			if strings.Contains(lower, strings.ToLower(alternative)) {
+				passed = true
+				break
+			}
+		}
+		checks = append(checks, Check{Name: "mentions " + expect, Passed: passed})
+	}
+	if t.MaxChars > 0 {
+		checks = append(checks, Check{Name: fmt.Sprintf("under %d chars", t.MaxChars), Passed: len([]rune(strings.TrimSpace(answer))) <= t.MaxChars})
+	}
+	return checks
+}
+
+// Score は確認項目の合格率（0.0-1.0、項目が無ければ 1.0）
+func Score(checks []Check) float64 {
+	if len(checks) == 0 {
+		return 1.0
+	}
+	passed := 0
+	for _, check := range checks {
+		if check.Passed {
+			passed++
+		}
+	}
+	return float64(passed) / float64(len(checks))
+}
+
+// extractCode は最初のコードブロックの中身を返す（無ければ回答全体と false）
+func extractCode(answer string) (string, bool) {
+	if match := codeBlockPattern.FindStringSubmatch(answer); match != nil {
+		return match[2], true
+	}
+	return answer, false
+}
+
+// parsesAsGo はコードがGoとして構文解析できるか（package 宣言が無ければ補って確認）
+func parsesAsGo(code string) bool {
+	source := code
+	if !strings.HasPrefix(strings.TrimSpace(source), "package ") {
+		source = "package bench\n\n" + source
+	}
+	_, err := parser.ParseFile(token.NewFileSet(), "answer.go", source, parser.AllErrors)
+	return err == nil
+}
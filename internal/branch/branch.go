@@ -0,0 +1,222 @@
+package branch
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/history"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// MainBranch は最初の会話の分岐名
+const MainBranch = "main"
+
+// Branch は会話の分岐
+// 親の ForkTurn ターン目までを引き継ぎ、それ以降は独自のやり取りを持つ
+type Branch struct {
+	Name      string             `json:"name"`
+	Parent    string             `json:"parent,omitempty"`
+	ForkTurn  int                `json:"fork_turn"` // 親から引き継いだターン数
+	CreatedAt time.Time          `json:"created_at"`
+	Entries   []transcript.Entry `json:"entries"`          // 引き継いだ部分を含む会話記録
+	Merged    []string           `json:"merged,omitempty"` // 結論を取り込んだ分岐
+}
+
+// Turns は分岐のユーザー入力の数
+func (b *Branch) Turns() int {
+	return countTurns(b.Entries)
+}
+
+// Tree はセッションの会話の分岐（作成順）と現在の分岐
+type Tree struct {
+	Current  string    `json:"current"`
+	Branches []*Branch `json:"branches"`
+}
+
+// NewTree は entries を main 分岐とする分岐ツリーを作成
+func NewTree(entries []transcript.Entry) *Tree {
+	return &Tree{
+		Current: MainBranch,
+		Branches: []*Branch{{
+			Name:      MainBranch,
+			CreatedAt: time.Now(),
+			Entries:   append([]transcript.Entry{}, entries...),
+		}},
+	}
+}
+
+// Get は名前で分岐を探す（なければ nil）
+func (t *Tree) Get(name string) *Branch {
+	for _, b := range t.Branches {
+		if b.Name == name {
+			return b
+		}
+	}
+	return nil
+}
+
+// CurrentBranch は現在の分岐
+func (t *Tree) CurrentBranch() *Branch {
+	return t.Get(t.Current)
+}
+
+// Children は親が name の分岐（作成順）
+func (t *Tree) Children(name string) []*Branch {
+	var children []*Branch
+	for _, b := range t.Branches {
+		if b.Parent == name {
+			children = append(children, b)
+		}
+	}
+	return children
+}
+
+// Fork は現在の分岐の turn ターン目までを引き継いだ分岐を作り、現在の分岐にする
+// name が空なら連番の名前（branch-2 等）を付ける
+func (t *Tree) Fork(turn int, name string) (*Branch, error) {
+	current := t.CurrentBranch()
+	if current == nil {
+		return nil, fmt.Errorf("現在の分岐が見つかりません: %s", t.Current)
+	}
+	if turns := current.Turns(); turn < 0 || turn > turns {
+		return nil, fmt.Errorf("ターン %d からは分岐できません（0〜%d）", turn, turns)
+	}
+	if name == "" {
+		name = t.nextName()
+	}
+	if err := validName(name); err != nil {
+		return nil, err
+	}
+	if t.Get(name) != nil {
+		return nil, fmt.Errorf("分岐 %s は既にあります", name)
+	}
+
+	forked := &Branch{
+		Name:      name,
+		Parent:    current.Name,
+		ForkTurn:  turn,
+		CreatedAt: time.Now(),
+		Entries:   Prefix(current.Entries, turn),
+	}
+	t.Branches = append(t.Branches, forked)
+	t.Current = name
+	return forked, nil
+}
+
+// Switch は現在の分岐を切り替える
+func (t *Tree) Switch(name string) (*Branch, error) {
+	target := t.Get(name)
+	if target == nil {
+		return nil, fmt.Errorf("分岐が見つかりません: %s", name)
+	}
+	t.Current = name
+	return target, nil
+}
+
+// Clone は分岐の会話記録を共有しないコピーを返す
+func (t *Tree) Clone() *Tree {
+	clone := &Tree{Current: t.Current, Branches: make([]*Branch, 0, len(t.Branches))}
+	for _, b := range t.Branches {
+		copied := *b
+		copied.Entries = append([]transcript.Entry{}, b.Entries...)
+		copied.Merged = append([]string(nil), b.Merged...)
+		clone.Branches = append(clone.Branches, &copied)
+	}
+	return clone
+}
+
+func (t *Tree) nextName() string {
+	for i := len(t.Branches) + 1; ; i++ {
+		name := fmt.Sprintf("branch-%d", i)
+		if t.Get(name) == nil {
+			return name
+		}
+	}
+}
+
+// validName はコマンド引数として扱える分岐名か確認する
+func validName(name string) error {
+	if strings.ContainsAny(name, " \t\n/") {
+		return fmt.Errorf("分岐名に空白や / は使えません: %q", name)
+	}
+	if _, err := strconv.Atoi(name); err == nil {
+		return fmt.Errorf("分岐名に数字だけの名前は使えません（ターン番号と区別するため）: %s", name)
+	}
+	return nil
+}
+
+// Prefix は先頭から turns ターン目の終わりまでの項目（turns+1 ターン目の入力の直前まで）
+func Prefix(entries []transcript.Entry, turns int) []transcript.Entry {
+	seen := 0
+	for i, entry := range entries {
+		if entry.Kind != transcript.KindUser {
+			continue
+		}
+		if seen == turns {
+			return append([]transcript.Entry{}, entries[:i]...)
+		}
+		seen++
+	}
+	return append([]transcript.Entry{}, entries...)
+}
+
+// SharedLength は2つの分岐が共有している先頭の項目数
+// 分岐時に会話記録をコピーするため、共通の祖先から引き継いだ項目は一致する
+func SharedLength(a, b *Branch) int {
+	n := 0
+	for n < len(a.Entries) && n < len(b.Entries) && sameEntry(a.Entries[n], b.Entries[n]) {
+		n++
+	}
+	return n
+}
+
+// SharedTurns は2つの分岐が共有しているターン数
+func SharedTurns(a, b *Branch) int {
+	return countTurns(a.Entries[:SharedLength(a, b)])
+}
+
+func sameEntry(a, b transcript.Entry) bool {
+	return a.Kind == b.Kind && a.Timestamp.Equal(b.Timestamp) && a.Content == b.Content && a.Command == b.Command && a.Tool == b.Tool
+}
+
+func countTurns(entries []transcript.Entry) int {
+	turns := 0
+	for _, entry := range entries {
+		if entry.Kind == transcript.KindUser {
+			turns++
+		}
+	}
+	return turns
+}
+
+// 引用時に1つの応答から取り込む最大文字数
+const quoteMaxRunes = 4000
+
+// Exchanges は分岐の after ターン目より後のやり取り（ターン番号は分岐内の通し番号）
+func Exchanges(b *Branch, after int) []history.Exchange {
+	exchanges := history.Exchanges(&transcript.Transcript{Entries: b.Entries})
+	if after >= len(exchanges) {
+		return nil
+	}
+	if after < 0 {
+		after = 0
+	}
+	return exchanges[after:]
+}
+
+// Quote は分岐のやり取りを現在の分岐のコンテキストに取り込むための引用文
+func Quote(name string, exchange history.Exchange) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("会話の分岐 %s のターン %d:\n", name, exchange.Turn))
+	sb.WriteString("ユーザー: " + strings.TrimSpace(exchange.User) + "\n")
+	if exchange.Assistant != "" {
+		answer := []rune(strings.TrimSpace(exchange.Assistant))
+		if len(answer) > quoteMaxRunes {
+			answer = append(answer[:quoteMaxRunes], []rune("...(truncated)")...)
+		}
+		sb.WriteString("アシスタント: " + string(answer) + "\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,98 @@
+package branch
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// turns は n ターン分の入力・応答を作成する
+func turns(n int) []transcript.Entry {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var entries []transcript.Entry
+	for i := 1; i <= n; i++ {
+		at := base.Add(time.Duration(i) * time.Minute)
+		entries = append(entries,
+			transcript.Entry{Kind: transcript.KindUser, Timestamp: at, Content: "question " + string(rune('0'+i))},
+			transcript.Entry{Kind: transcript.KindCommand, Timestamp: at.Add(time.Second), Command: "go test"},
+			transcript.Entry{Kind: transcript.KindAssistant, Timestamp: at.Add(2 * time.Second), Content: "answer " + string(rune('0'+i))},
+		)
+	}
+	return entries
+}
+
+func TestTree_ForkAndSwitch(t *testing.T) {
+	tree := NewTree(turns(3))
+
+	forked, err := tree.Fork(1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forked.Name != "branch-2" || forked.Parent != MainBranch || forked.Turns() != 1 || tree.Current != "branch-2" {
+		t.Fatalf("forked = %+v (current %s)", forked, tree.Current)
+	}
+	if len(forked.Entries) != 3 {
+		t.Errorf("ターン1の入力・コマンド・応答を引き継ぐはず: %d", len(forked.Entries))
+	}
+
+	// 分岐は独立しており、元の分岐には影響しない
+	forked.Entries = append(forked.Entries, transcript.Entry{Kind: transcript.KindUser, Timestamp: time.Now(), Content: "another approach"})
+	if main := tree.Get(MainBranch); main.Turns() != 3 {
+		t.Errorf("main turns = %d", main.Turns())
+	}
+	if SharedTurns(tree.Get(MainBranch), forked) != 1 {
+		t.Errorf("SharedTurns = %d", SharedTurns(tree.Get(MainBranch), forked))
+	}
+	if exchanges := Exchanges(forked, 1); len(exchanges) != 1 || exchanges[0].Turn != 2 || exchanges[0].User != "another approach" {
+		t.Errorf("Exchanges = %+v", exchanges)
+	}
+
+	// 分岐からさらに分岐でき、ツリーとして辿れる
+	if _, err := tree.Fork(0, "scratch"); err != nil {
+		t.Fatal(err)
+	}
+	if children := tree.Children("branch-2"); len(children) != 1 || children[0].Name != "scratch" {
+		t.Errorf("children = %+v", children)
+	}
+
+	if _, err := tree.Switch(MainBranch); err != nil || tree.Current != MainBranch {
+		t.Errorf("Switch: %v (current %s)", err, tree.Current)
+	}
+	if _, err := tree.Switch("missing"); err == nil {
+		t.Error("存在しない分岐への切替はエラーのはず")
+	}
+}
+
+func TestTree_ForkErrors(t *testing.T) {
+	tree := NewTree(turns(2))
+	for _, tt := range []struct {
+		turn int
+		name string
+	}{
+		{3, "too-far"},
+		{-1, "negative"},
+		{1, "main"},
+		{1, "two words"},
+		{1, "42"},
+	} {
+		if _, err := tree.Fork(tt.turn, tt.name); err == nil {
+			t.Errorf("Fork(%d, %q) はエラーのはず", tt.turn, tt.name)
+		}
+	}
+	if len(tree.Branches) != 1 || tree.Current != MainBranch {
+		t.Errorf("失敗した分岐は作られないはず: %+v", tree)
+	}
+}
+
+func TestQuote(t *testing.T) {
+	tree := NewTree(turns(1))
+	exchange := Exchanges(tree.CurrentBranch(), 0)[0]
+	exchange.Assistant = strings.Repeat("あ", quoteMaxRunes+10)
+
+	quote := Quote("main", exchange)
+	if !strings.Contains(quote, "会話の分岐 main のターン 1") || !strings.Contains(quote, "question 1") || !strings.HasSuffix(quote, "...(truncated)\n") {
+		t.Errorf("Quote = %q", quote[:80])
+	}
+}
@@ -0,0 +1,224 @@
+// Package budget は1回のユーザー入力（ターン）で使うツール実行回数・トークン数・経過時間を数え、
+// 上限を超えたら以降の処理を止める
+package budget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// Kind は超過した上限の種類
+type Kind string
+
+const (
+	KindToolCalls Kind = "tool_calls"
+	KindTokens    Kind = "tokens"
+	KindWallTime  Kind = "wall_time"
+)
+
+// Limits は1ターンの上限（0 以下はその上限なし）
+type Limits struct {
+	MaxToolCalls int
+	MaxTokens    int
+	MaxWallTime  time.Duration
+}
+
+// LimitsFromConfig は設定からターンの上限を作成
+func LimitsFromConfig(cfg config.TurnLimitsConfig) Limits {
+	return Limits{
+		MaxToolCalls: cfg.MaxToolCalls,
+		MaxTokens:    cfg.MaxTokens,
+		MaxWallTime:  time.Duration(cfg.MaxSeconds) * time.Second,
+	}
+}
+
+// Usage はターン内で使った資源
+type Usage struct {
+	ToolCalls int
+	Tokens    int
+	Elapsed   time.Duration
+}
+
+// ExceededError は上限を超えたことを表すエラー
+type ExceededError struct {
+	Kind  Kind
+	Limit string
+}
+
+func (e *ExceededError) Error() string {
+	return i18n.T("budget.exceeded", i18n.T("budget.kind."+string(e.Kind)), e.Limit)
+}
+
+// IsExceeded は err が上限超過によるものか
+func IsExceeded(err error) bool {
+	var exceeded *ExceededError
+	return errors.As(err, &exceeded)
+}
+
+// Tracker は1ターン分の使用量と実行した操作を記録する
+type Tracker struct {
+	limits Limits
+	start  time.Time
+	now    func() time.Time
+
+	mu       sync.Mutex
+	usage    Usage
+	actions  []string
+	exceeded *ExceededError
+}
+
+// New はターン開始時点から数える Tracker を作成
+func New(limits Limits) *Tracker {
+	return &Tracker{limits: limits, start: time.Now(), now: time.Now}
+}
+
+// Limits は上限の設定を返す
+func (t *Tracker) Limits() Limits {
+	return t.limits
+}
+
+// UseTool はツール実行を1回数える（上限を超える実行は行わず超過エラーを返す）
+func (t *Tracker) UseTool(action string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err := t.checkLocked(); err != nil {
+		return err
+	}
+	if t.limits.MaxToolCalls > 0 && t.usage.ToolCalls >= t.limits.MaxToolCalls {
+		t.exceeded = &ExceededError{Kind: KindToolCalls, Limit: fmt.Sprintf("%d", t.limits.MaxToolCalls)}
+		return t.exceeded
+	}
+	t.usage.ToolCalls++
+	if action != "" {
+		t.actions = append(t.actions, action)
+	}
+	return nil
+}
+
+// AddTokens はLLMリクエストで使ったトークン数を加算（超えた時点で以降のリクエストを止める）
+func (t *Tracker) AddTokens(tokens int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.usage.Tokens += tokens
+	if t.exceeded == nil && t.limits.MaxTokens > 0 && t.usage.Tokens >= t.limits.MaxTokens {
+		t.exceeded = &ExceededError{Kind: KindTokens, Limit: fmt.Sprintf("%d", t.limits.MaxTokens)}
+	}
+}
+
+// Check は上限を超えていれば超過エラーを返す
+func (t *Tracker) Check() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.checkLocked()
+}
+
+func (t *Tracker) checkLocked() error {
+	if t.exceeded != nil {
+		return t.exceeded
+	}
+	if t.limits.MaxWallTime > 0 && t.now().Sub(t.start) >= t.limits.MaxWallTime {
+		t.exceeded = &ExceededError{Kind: KindWallTime, Limit: t.limits.MaxWallTime.String()}
+		return t.exceeded
+	}
+	return nil
+}
+
+// Exceeded は超過した上限（超えていなければ nil）
+func (t *Tracker) Exceeded() *ExceededError {
+	if t.Check() == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.exceeded
+}
+
+// Usage は現在までの使用量
+func (t *Tracker) Usage() Usage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	usage := t.usage
+	usage.Elapsed = t.now().Sub(t.start)
+	return usage
+}
+
+// Actions は実行したツール・コマンドの一覧
+func (t *Tracker) Actions() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.actions...)
+}
+
+// Summary は上限に達した旨とそれまでに行った操作の要約
+func (t *Tracker) Summary() string {
+	exceeded := t.Exceeded()
+	if exceeded == nil {
+		return ""
+	}
+	usage := t.Usage()
+
+	var b strings.Builder
+	b.WriteString("⛔ **" + exceeded.Error() + "**\n\n")
+	b.WriteString(i18n.T("budget.usage", usage.ToolCalls, usage.Tokens, usage.Elapsed.Round(time.Second)))
+	b.WriteString("\n")
+	actions := t.Actions()
+	if len(actions) == 0 {
+		b.WriteString(i18n.T("budget.no_actions"))
+		b.WriteString("\n")
+	} else {
+		b.WriteString(i18n.T("budget.done_so_far"))
+		b.WriteString("\n")
+		for _, action := range actions {
+			b.WriteString("- " + action + "\n")
+		}
+	}
+	b.WriteString("\n" + i18n.T("budget.continue_hint"))
+	return b.String()
+}
+
+// trackerKey はコンテキストに Tracker を保持するキー
+type trackerKey struct{}
+
+// WithTracker は ctx で行う処理を tracker で数える
+func WithTracker(ctx context.Context, tracker *Tracker) context.Context {
+	return context.WithValue(ctx, trackerKey{}, tracker)
+}
+
+// FromContext は ctx の Tracker（なければ nil）
+func FromContext(ctx context.Context) *Tracker {
+	if ctx == nil {
+		return nil
+	}
+	tracker, _ := ctx.Value(trackerKey{}).(*Tracker)
+	return tracker
+}
+
+// UseTool は ctx の Tracker でツール実行を数える（Tracker がなければ何もしない）
+func UseTool(ctx context.Context, action string) error {
+	if tracker := FromContext(ctx); tracker != nil {
+		return tracker.UseTool(action)
+	}
+	return nil
+}
+
+// AddTokens は ctx の Tracker にトークン数を加算
+func AddTokens(ctx context.Context, tokens int) {
+	if tracker := FromContext(ctx); tracker != nil {
+		tracker.AddTokens(tokens)
+	}
+}
+
+// Check は ctx の Tracker が上限を超えていれば超過エラーを返す
+func Check(ctx context.Context) error {
+	if tracker := FromContext(ctx); tracker != nil {
+		return tracker.Check()
+	}
+	return nil
+}
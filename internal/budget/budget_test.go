@@ -0,0 +1,87 @@
+package budget
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrackerToolCalls(t *testing.T) {
+	tracker := New(Limits{MaxToolCalls: 2})
+	ctx := WithTracker(context.Background(), tracker)
+
+	for _, action := range []string{"read: main.go", "bash: go test ./..."} {
+		if err := UseTool(ctx, action); err != nil {
+			t.Fatalf("UseTool(%q) = %v", action, err)
+		}
+	}
+	err := UseTool(ctx, "write: main.go")
+	if !IsExceeded(err) {
+		t.Fatalf("third tool call should exceed the budget, got %v", err)
+	}
+	if exceeded := tracker.Exceeded(); exceeded == nil || exceeded.Kind != KindToolCalls {
+		t.Fatalf("Exceeded = %+v", exceeded)
+	}
+	if usage := tracker.Usage(); usage.ToolCalls != 2 {
+		t.Fatalf("refused calls should not be counted, got %d", usage.ToolCalls)
+	}
+
+	summary := tracker.Summary()
+	for _, want := range []string{"read: main.go", "bash: go test ./..."} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary should list %q:\n%s", want, summary)
+		}
+	}
+	if strings.Contains(summary, "write: main.go") {
+		t.Errorf("summary should not list the refused call:\n%s", summary)
+	}
+}
+
+func TestTrackerTokens(t *testing.T) {
+	tracker := New(Limits{MaxTokens: 100})
+	ctx := WithTracker(context.Background(), tracker)
+
+	AddTokens(ctx, 60)
+	if err := Check(ctx); err != nil {
+		t.Fatalf("under the limit: %v", err)
+	}
+	AddTokens(ctx, 60)
+	if err := Check(ctx); !IsExceeded(err) {
+		t.Fatalf("over the limit should be exceeded, got %v", err)
+	}
+	if err := UseTool(ctx, "read: a.go"); !IsExceeded(err) {
+		t.Fatalf("tools should stop once the token budget is spent, got %v", err)
+	}
+}
+
+func TestTrackerWallTime(t *testing.T) {
+	tracker := New(Limits{MaxWallTime: time.Minute})
+	now := tracker.start
+	tracker.now = func() time.Time { return now }
+
+	if tracker.Exceeded() != nil {
+		t.Fatal("should not be exceeded at the start")
+	}
+	now = now.Add(2 * time.Minute)
+	if exceeded := tracker.Exceeded(); exceeded == nil || exceeded.Kind != KindWallTime {
+		t.Fatalf("Exceeded = %+v", exceeded)
+	}
+}
+
+func TestNoTracker(t *testing.T) {
+	ctx := context.Background()
+	for i := 0; i < 1000; i++ {
+		if err := UseTool(ctx, fmt.Sprintf("read: %d", i)); err != nil {
+			t.Fatalf("without a tracker nothing should be limited: %v", err)
+		}
+	}
+	AddTokens(ctx, 1<<30)
+	if err := Check(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if New(Limits{}).Summary() != "" {
+		t.Fatal("summary should be empty when nothing was exceeded")
+	}
+}
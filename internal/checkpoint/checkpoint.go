@@ -0,0 +1,260 @@
+package checkpoint
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+)
+
+// チェックポイントを保持するref（gcで消えないように参照を残す）
+const refPrefix = "refs/vyb/checkpoints/"
+
+// Snapshot はワークスペース（追跡ファイル＋未追跡ファイル）のある時点の状態
+type Snapshot struct {
+	Commit string `json:"commit"`
+	Tree   string `json:"tree"`
+}
+
+// Checkpoint はエージェントのターン開始前のワークスペースと会話の状態
+type Checkpoint struct {
+	ID        string    `json:"id"`
+	SessionID string    `json:"session_id"`
+	Turn      int       `json:"turn"`  // このチェックポイントの直後に実行したターン番号（1始まり）
+	Input     string    `json:"input"` // そのターンのユーザー入力
+	Snapshot  Snapshot  `json:"snapshot"`
+	Files     []string  `json:"files"` // そのターンで変更されたファイル
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RestoreResult は復元で書き戻した・削除したファイル
+type RestoreResult struct {
+	Restored []string `json:"restored"`
+	Removed  []string `json:"removed"`
+}
+
+// Store はgitオブジェクトとしてスナップショットを保存・復元する
+// 作業ツリーとユーザーのインデックスには触れず、一時インデックスで処理する
+type Store struct {
+	root string
+}
+
+// NewStore はディレクトリを含むgitリポジトリのストアを作成
+func NewStore(dir string) (*Store, error) {
+	output, err := runGit(dir, nil, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, fmt.Errorf("gitリポジトリではありません: %s", dir)
+	}
+	return &Store{root: strings.TrimSpace(output)}, nil
+}
+
+// Root はリポジトリのルートを返す
+func (s *Store) Root() string {
+	return s.root
+}
+
+// Snapshot は現在のワークスペースを.gitignoreを尊重してコミットオブジェクトとして保存
+func (s *Store) Snapshot(message string) (Snapshot, error) {
+	indexFile, cleanup, err := s.tempIndex()
+	if err != nil {
+		return Snapshot{}, err
+	}
+	defer cleanup()
+	env := []string{"GIT_INDEX_FILE=" + indexFile}
+
+	if _, err := runGit(s.root, env, "add", "-A", "--", "."); err != nil {
+		return Snapshot{}, fmt.Errorf("スナップショット作成エラー: %w", err)
+	}
+	tree, err := runGit(s.root, env, "write-tree")
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("スナップショット作成エラー: %w", err)
+	}
+	tree = strings.TrimSpace(tree)
+
+	args := []string{"commit-tree", tree, "-m", message}
+	// 初回コミット前のリポジトリでは親なしで作成
+	if head, err := runGit(s.root, nil, "rev-parse", "--verify", "--quiet", "HEAD"); err == nil {
+		args = append(args, "-p", strings.TrimSpace(head))
+	}
+	commit, err := runGit(s.root, checkpointIdentity(), args...)
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("スナップショット作成エラー: %w", err)
+	}
+
+	return Snapshot{Commit: strings.TrimSpace(commit), Tree: tree}, nil
+}
+
+// Keep はチェックポイントのコミットをrefで保持
+func (s *Store) Keep(cp *Checkpoint) error {
+	if _, err := runGit(s.root, nil, "update-ref", refName(cp), cp.Snapshot.Commit); err != nil {
+		return fmt.Errorf("チェックポイント保存エラー: %w", err)
+	}
+	return nil
+}
+
+// Drop はチェックポイントのrefを削除
+func (s *Store) Drop(cp *Checkpoint) error {
+	if _, err := runGit(s.root, nil, "update-ref", "-d", refName(cp)); err != nil {
+		return fmt.Errorf("チェックポイント削除エラー: %w", err)
+	}
+	return nil
+}
+
+// Prune は指定期間より古いチェックポイントのrefを削除し、削除数を返す
+// 終了処理を経なかったセッションのrefが残り続けないよう起動時に呼び出す
+func (s *Store) Prune(maxAge time.Duration) (int, error) {
+	output, err := runGit(s.root, nil, "for-each-ref", "--format=%(refname) %(committerdate:unix)", refPrefix)
+	if err != nil {
+		return 0, fmt.Errorf("チェックポイント一覧取得エラー: %w", err)
+	}
+
+	cutoff := time.Now().Add(-maxAge).Unix()
+	pruned := 0
+	for _, line := range strings.Split(output, "\n") {
+		ref, timestamp, found := strings.Cut(strings.TrimSpace(line), " ")
+		if !found {
+			continue
+		}
+		created, err := strconv.ParseInt(timestamp, 10, 64)
+		if err != nil || created >= cutoff {
+			continue
+		}
+		if _, err := runGit(s.root, nil, "update-ref", "-d", ref); err != nil {
+			return pruned, fmt.Errorf("チェックポイント削除エラー: %w", err)
+		}
+		pruned++
+	}
+	return pruned, nil
+}
+
+// ChangedFiles は2つのスナップショット間で変更されたファイル
+func (s *Store) ChangedFiles(from, to Snapshot) ([]string, error) {
+	output, err := runGit(s.root, nil, "diff-tree", "-r", "-z", "--name-only", "--no-commit-id", from.Tree, to.Tree)
+	if err != nil {
+		return nil, fmt.Errorf("差分取得エラー: %w", err)
+	}
+	return splitNUL(output), nil
+}
+
+// Diff は2つのスナップショット間の変更をunified diff形式で返す
+func (s *Store) Diff(from, to Snapshot) (string, error) {
+	output, err := runGit(s.root, nil, "diff-tree", "-p", "-r", "--no-commit-id", "--no-color", from.Tree, to.Tree)
+	if err != nil {
+		return "", fmt.Errorf("差分取得エラー: %w", err)
+	}
+	return output, nil
+}
+
+// Restore はワークスペースをスナップショットの状態に戻す
+// スナップショット以降に追加されたファイルは削除し、変更・削除されたファイルは書き戻す
+func (s *Store) Restore(target Snapshot) (*RestoreResult, error) {
+	current, err := s.Snapshot("vyb: pre-restore")
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := runGit(s.root, nil, "diff-tree", "-r", "-z", "--name-status", "--no-renames", "--no-commit-id", target.Tree, current.Tree)
+	if err != nil {
+		return nil, fmt.Errorf("差分取得エラー: %w", err)
+	}
+
+	// -z 出力は「状態\0パス\0」の繰り返し
+	result := &RestoreResult{}
+	fields := splitNUL(output)
+	for i := 0; i+1 < len(fields); i += 2 {
+		status, path := fields[i], fields[i+1]
+		if status == "A" {
+			// スナップショット後に作成されたファイル
+			if err := os.Remove(filepath.Join(s.root, path)); err != nil && !os.IsNotExist(err) {
+				return result, fmt.Errorf("ファイル削除エラー: %w", err)
+			}
+			result.Removed = append(result.Removed, path)
+		} else {
+			result.Restored = append(result.Restored, path)
+		}
+	}
+	if len(result.Restored) == 0 {
+		return result, nil
+	}
+
+	indexFile, cleanup, err := s.tempIndex()
+	if err != nil {
+		return result, err
+	}
+	defer cleanup()
+	env := []string{"GIT_INDEX_FILE=" + indexFile}
+
+	if _, err := runGit(s.root, env, "read-tree", target.Tree); err != nil {
+		return result, fmt.Errorf("復元エラー: %w", err)
+	}
+	paths := strings.Join(result.Restored, "\x00") + "\x00"
+	if _, err := gitexec.RunInput(context.Background(), s.root, env, paths, "checkout-index", "-f", "-z", "--stdin"); err != nil {
+		return result, fmt.Errorf("復元エラー: %w", err)
+	}
+	return result, nil
+}
+
+// tempIndex は一時インデックスファイルのパスと後始末関数を返す
+// 実インデックスをコピーしておくことで、未変更ファイルの再ハッシュを避ける
+func (s *Store) tempIndex() (string, func(), error) {
+	dir, err := os.MkdirTemp("", "vyb-checkpoint-")
+	if err != nil {
+		return "", nil, fmt.Errorf("一時ディレクトリ作成エラー: %w", err)
+	}
+	indexFile := filepath.Join(dir, "index")
+	cleanup := func() { os.RemoveAll(dir) }
+
+	if realIndex, err := runGit(s.root, nil, "rev-parse", "--path-format=absolute", "--git-path", "index"); err == nil {
+		if data, err := os.ReadFile(strings.TrimSpace(realIndex)); err == nil {
+			if err := os.WriteFile(indexFile, data, 0600); err != nil {
+				cleanup()
+				return "", nil, fmt.Errorf("一時インデックス作成エラー: %w", err)
+			}
+		}
+	}
+	return indexFile, cleanup, nil
+}
+
+// refName はチェックポイントのref名
+func refName(cp *Checkpoint) string {
+	return refPrefix + sanitizeRef(cp.SessionID) + "/" + cp.ID
+}
+
+// sanitizeRef はref名に使えない文字を置換
+func sanitizeRef(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == '_' || (r >= '0' && r <= '9') || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// checkpointIdentity はユーザーのgit設定がなくてもコミットできるよう作成者を指定
+func checkpointIdentity() []string {
+	return []string{
+		"GIT_AUTHOR_NAME=vyb", "GIT_AUTHOR_EMAIL=vyb@localhost",
+		"GIT_COMMITTER_NAME=vyb", "GIT_COMMITTER_EMAIL=vyb@localhost",
+	}
+}
+
+// runGit はgitコマンドを実行して標準出力を返す
+func runGit(dir string, env []string, args ...string) (string, error) {
+	return gitexec.RunInput(context.Background(), dir, env, "", args...)
+}
+
+// splitNUL はNUL区切りの出力を分割
+func splitNUL(output string) []string {
+	var fields []string
+	for _, field := range strings.Split(output, "\x00") {
+		if field != "" {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
@@ -0,0 +1,216 @@
+package checkpoint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newTestRepo は1コミットを持つ一時gitリポジトリを作成
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+
+	dir := t.TempDir()
+	writeFile(t, dir, "main.go", "package main\n")
+	writeFile(t, dir, ".gitignore", "build/\n")
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"add", "-A"},
+		{"commit", "-q", "-m", "initial"},
+	} {
+		if _, err := runGit(dir, checkpointIdentity(), args...); err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+	}
+	return dir
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readFile(t *testing.T, dir, name string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestNewStoreOutsideRepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	if _, err := NewStore(t.TempDir()); err == nil {
+		t.Fatal("expected error outside a git repository")
+	}
+}
+
+func TestSnapshotAndRestore(t *testing.T) {
+	dir := newTestRepo(t)
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 未コミットの変更と未追跡ファイルもスナップショットに含まれる
+	writeFile(t, dir, "main.go", "package main\n\n// edited\n")
+	writeFile(t, dir, "notes.txt", "draft\n")
+	before, err := store.Snapshot("before")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// ターン中の変更: 編集・削除・新規作成・無視対象の生成物
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	if err := os.Remove(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "pkg/util.go", "package pkg\n")
+	writeFile(t, dir, "build/out.bin", "binary")
+
+	after, err := store.Snapshot("after")
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed, err := store.ChangedFiles(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(changed)
+	if want := []string{"main.go", "notes.txt", "pkg/util.go"}; !equalStrings(changed, want) {
+		t.Fatalf("changed files = %v, want %v", changed, want)
+	}
+
+	result, err := store.Restore(before)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(result.Restored)
+	if want := []string{"main.go", "notes.txt"}; !equalStrings(result.Restored, want) {
+		t.Errorf("restored = %v, want %v", result.Restored, want)
+	}
+	if want := []string{"pkg/util.go"}; !equalStrings(result.Removed, want) {
+		t.Errorf("removed = %v, want %v", result.Removed, want)
+	}
+
+	if got := readFile(t, dir, "main.go"); got != "package main\n\n// edited\n" {
+		t.Errorf("main.go = %q", got)
+	}
+	if got := readFile(t, dir, "notes.txt"); got != "draft\n" {
+		t.Errorf("notes.txt = %q", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "pkg/util.go")); !os.IsNotExist(err) {
+		t.Error("pkg/util.go should be removed")
+	}
+	// .gitignore対象は触らない
+	if got := readFile(t, dir, "build/out.bin"); got != "binary" {
+		t.Errorf("ignored file changed: %q", got)
+	}
+
+	// ユーザーのインデックス（ステージ状態）は変更しない
+	status, err := runGit(dir, nil, "diff", "--cached", "--name-only")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status != "" {
+		t.Errorf("index was modified: %q", status)
+	}
+}
+
+func TestKeepDropAndPrune(t *testing.T) {
+	dir := newTestRepo(t)
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snapshot, err := store.Snapshot("turn")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cp := &Checkpoint{ID: "turn-1", SessionID: "session/1", Snapshot: snapshot}
+	if err := store.Keep(cp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(dir, nil, "rev-parse", "--verify", refName(cp)); err != nil {
+		t.Fatalf("ref not created: %v", err)
+	}
+
+	// 作成直後のものは期限内なので残る
+	if pruned, err := store.Prune(time.Hour); err != nil || pruned != 0 {
+		t.Fatalf("Prune(1h) = %d, %v", pruned, err)
+	}
+	if pruned, err := store.Prune(-time.Hour); err != nil || pruned != 1 {
+		t.Fatalf("Prune(-1h) = %d, %v", pruned, err)
+	}
+
+	if err := store.Keep(cp); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Drop(cp); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := runGit(dir, nil, "rev-parse", "--verify", "--quiet", refName(cp)); err == nil {
+		t.Fatal("ref should be deleted")
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDiff(t *testing.T) {
+	dir := newTestRepo(t)
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	before, err := store.Snapshot("before")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, dir, "main.go", "package main\n\nfunc main() {}\n")
+	after, err := store.Snapshot("after")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	patch, err := store.Diff(before, after)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"--- a/main.go", "+++ b/main.go", "+func main() {}"} {
+		if !strings.Contains(patch, want) {
+			t.Errorf("diff missing %q:\n%s", want, patch)
+		}
+	}
+
+	if patch, err := store.Diff(after, after); err != nil || patch != "" {
+		t.Errorf("diff of identical snapshots = %q, %v", patch, err)
+	}
+}
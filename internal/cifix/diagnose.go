@@ -0,0 +1,367 @@
+// Package cifix は CI の失敗ログ（GitHub Actions のジョブログ等）から失敗したステップとエラーを取り出し、
+// 関係するコードを特定して修正を依頼するプロンプトを組み立てる
+package cifix
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+const (
+	// プロンプトに含めるログの最大行数（失敗したステップの末尾）
+	excerptMaxLines = 150
+	// コードを添付する最大ファイル数
+	maxLocations = 5
+	// 失敗した行の前後に添付する行数
+	snippetContext = 12
+)
+
+var (
+	// GitHub Actions のログの各行の時刻（2024-05-01T12:00:00.1234567Z）
+	timestampPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(?:\.\d+)?Z ?`)
+	ansiPattern      = regexp.MustCompile(`\x1b\[[0-9;]*[A-Za-z]`)
+	// 失敗の集計行（エラーの一覧には含めない）
+	exitCodePattern = regexp.MustCompile(`^Process completed with exit code (\d+)`)
+)
+
+// Step はログの1ステップ（GitHub Actions の ##[group]Run ... から次のステップまで、区切りがなければログ全体）
+type Step struct {
+	Command string   `json:"command,omitempty"`
+	Errors  []string `json:"errors,omitempty"` // ##[error] の注記
+	Output  []string `json:"-"`
+}
+
+// Location は失敗に関係するコードの箇所
+type Location struct {
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Test    string `json:"test,omitempty"`
+	Snippet string `json:"snippet"` // 行番号付きのコード
+}
+
+// Diagnosis は失敗ログの解析結果
+type Diagnosis struct {
+	Source    string          `json:"source"`         // ログファイル・ジョブ名
+	Step      string          `json:"step,omitempty"` // 失敗したステップのコマンド
+	ExitCode  string          `json:"exit_code,omitempty"`
+	Errors    []string        `json:"errors,omitempty"`
+	Failures  []tasks.Failure `json:"failures"`
+	Locations []Location      `json:"locations"`
+	Excerpt   string          `json:"excerpt"`
+}
+
+// CleanLine は行から時刻と ANSI エスケープを除く
+func CleanLine(line string) string {
+	line = strings.TrimSuffix(line, "\r")
+	line = timestampPattern.ReplaceAllString(line, "")
+	return ansiPattern.ReplaceAllString(line, "")
+}
+
+// Steps はログをステップに分ける（コマンドの echo・環境変数の ##[group] ブロックは出力に含めない）
+func Steps(log string) []*Step {
+	current := &Step{}
+	steps := []*Step{current}
+	inGroup := false
+	scanner := bufio.NewScanner(strings.NewReader(log))
+	scanner.Buffer(make([]byte, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := CleanLine(scanner.Text())
+		switch {
+		case strings.HasPrefix(line, "##[group]"):
+			title := strings.TrimPrefix(line, "##[group]")
+			if strings.HasPrefix(title, "Run ") {
+				current = &Step{Command: strings.TrimPrefix(title, "Run ")}
+				steps = append(steps, current)
+			}
+			inGroup = true
+		case strings.HasPrefix(line, "##[endgroup]"):
+			inGroup = false
+		case strings.HasPrefix(line, "##[error]"):
+			current.Errors = append(current.Errors, strings.TrimSpace(strings.TrimPrefix(line, "##[error]")))
+		case strings.HasPrefix(line, "##["):
+			// ##[warning] 等の他の注記・命令
+		case !inGroup:
+			current.Output = append(current.Output, line)
+		}
+	}
+	return steps
+}
+
+// Diagnose はログから失敗したステップのエラーを取り出し、projectDir で関係するコードを探す
+func Diagnose(projectDir, source, log string) *Diagnosis {
+	steps := Steps(log)
+	failed := steps[len(steps)-1]
+	for _, step := range steps {
+		if len(step.Errors) > 0 {
+			failed = step
+			break
+		}
+	}
+
+	d := &Diagnosis{Source: source, Step: failed.Command}
+	for _, message := range failed.Errors {
+		if m := exitCodePattern.FindStringSubmatch(message); m != nil {
+			d.ExitCode = m[1]
+			continue
+		}
+		d.Errors = append(d.Errors, message)
+	}
+	output := strings.Join(failed.Output, "\n")
+	d.Failures = tasks.ParseFailures(output)
+	if d.Failures == nil {
+		d.Failures = []tasks.Failure{}
+	}
+
+	lines := failed.Output
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > excerptMaxLines {
+		lines = append([]string{fmt.Sprintf("... (%d lines omitted)", len(lines)-excerptMaxLines)}, lines[len(lines)-excerptMaxLines:]...)
+	}
+	d.Excerpt = strings.Join(lines, "\n")
+	d.Locations = locate(projectDir, d.Failures)
+	return d
+}
+
+// locate は失敗のファイル・テストをプロジェクト内で探し、前後のコードを添付する（ファイル毎に最初の失敗のみ）
+func locate(projectDir string, failures []tasks.Failure) []Location {
+	locations := []Location{}
+	seen := make(map[string]bool)
+	for _, failure := range failures {
+		if len(locations) >= maxLocations {
+			break
+		}
+		file, line := "", failure.Line
+		if failure.File != "" {
+			file = resolvePath(projectDir, failure.File, failure.Test)
+		} else if failure.Test != "" {
+			file, line = findGoTest(projectDir, failure.Test)
+		}
+		if file == "" || seen[file] {
+			continue
+		}
+		snippet, err := readSnippet(filepath.Join(projectDir, file), line)
+		if err != nil {
+			continue
+		}
+		seen[file] = true
+		locations = append(locations, Location{File: file, Line: line, Test: failure.Test, Snippet: snippet})
+	}
+	return locations
+}
+
+// resolvePath はログのパス（CI の作業ディレクトリの絶対パス・テストのファイル名のみ等）をプロジェクトの相対パスにする
+func resolvePath(projectDir, path, test string) string {
+	path = filepath.ToSlash(filepath.Clean(path))
+	parts := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	// 先頭のディレクトリを順に外して一致するファイルを探す（/home/runner/work/repo/repo/pkg/a.go → pkg/a.go）
+	for i := range parts {
+		candidate := strings.Join(parts[i:], "/")
+		if info, err := os.Stat(filepath.Join(projectDir, candidate)); err == nil && !info.IsDir() {
+			return candidate
+		}
+	}
+	if len(parts) > 1 {
+		return ""
+	}
+	// go test の出力はファイル名のみのため、同名のファイルが複数あればテストを定義しているものを選ぶ
+	first, declared, _ := findFile(projectDir, func(name string) bool { return name == parts[0] }, testDeclaration(test))
+	if declared != "" {
+		return declared
+	}
+	return first
+}
+
+// findGoTest は位置の分からない Go のテスト（サブテストは親）の定義を探す
+func findGoTest(projectDir, test string) (string, int) {
+	_, file, line := findFile(projectDir, func(name string) bool { return strings.HasSuffix(name, "_test.go") }, testDeclaration(test))
+	return file, line
+}
+
+// testDeclaration は Go のテスト関数の宣言の先頭（テスト名がなければ空）
+func testDeclaration(test string) string {
+	if test == "" {
+		return ""
+	}
+	if i := strings.IndexByte(test, '/'); i >= 0 {
+		test = test[:i]
+	}
+	return "func " + test + "("
+}
+
+// findFile は名前が match に一致する最初のファイルと、declaration で始まる行を含む最初のファイル・その行番号を探す
+func findFile(projectDir string, match func(name string) bool, declaration string) (first, declared string, line int) {
+	filepath.WalkDir(projectDir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			if skipDir(entry.Name()) && path != projectDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !match(entry.Name()) {
+			return nil
+		}
+		rel, _ := filepath.Rel(projectDir, path)
+		rel = filepath.ToSlash(rel)
+		if first == "" {
+			first = rel
+		}
+		if declaration == "" {
+			return filepath.SkipAll
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		for i, text := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(text, declaration) {
+				declared, line = rel, i+1
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+	return first, declared, line
+}
+
+func skipDir(name string) bool {
+	return strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor" || name == "target" || name == "dist"
+}
+
+// readSnippet は line の前後（line が0ならファイルの先頭）を行番号付きで返す
+func readSnippet(path string, line int) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	start, end := line-snippetContext, line+snippetContext
+	if line <= 0 {
+		start, end = 1, 2*snippetContext
+	}
+	if start < 1 {
+		start = 1
+	}
+	if end > len(lines) {
+		end = len(lines)
+	}
+	var sb strings.Builder
+	for i := start; i <= end; i++ {
+		marker := "  "
+		if i == line {
+			marker = "> "
+		}
+		sb.WriteString(fmt.Sprintf("%s%4d | %s\n", marker, i, lines[i-1]))
+	}
+	return strings.TrimRight(sb.String(), "\n"), nil
+}
+
+// Tests は失敗したテスト名（重複なし）
+func (d *Diagnosis) Tests() []string {
+	var names []string
+	seen := make(map[string]bool)
+	for _, failure := range d.Failures {
+		if failure.Test != "" && !seen[failure.Test] {
+			seen[failure.Test] = true
+			names = append(names, failure.Test)
+		}
+	}
+	return names
+}
+
+// Title は修正のコミット・PRの件名
+func (d *Diagnosis) Title() string {
+	tests := d.Tests()
+	switch {
+	case len(tests) == 1:
+		return "Fix failing test " + tests[0]
+	case len(tests) > 1:
+		return fmt.Sprintf("Fix %d failing tests", len(tests))
+	case len(d.Locations) > 0:
+		return "Fix CI error in " + d.Locations[0].File
+	case len(d.Failures) > 0 && d.Failures[0].File != "":
+		return "Fix CI error in " + d.Failures[0].File
+	case d.Step != "":
+		command := d.Step
+		if i := strings.IndexByte(command, '\n'); i >= 0 {
+			command = command[:i]
+		}
+		return "Fix CI step: " + command
+	default:
+		return "Fix CI failure"
+	}
+}
+
+// Check は修正後に実行するチェック（テストの失敗なら test、それ以外は build）
+func (d *Diagnosis) Check() string {
+	if len(d.Tests()) > 0 {
+		return string(tasks.KindTest)
+	}
+	return string(tasks.KindBuild)
+}
+
+// FailureList はエラーの注記と構造化した失敗の一覧（Markdown の箇条書き）
+func (d *Diagnosis) FailureList() string {
+	var sb strings.Builder
+	for _, message := range d.Errors {
+		sb.WriteString("- " + message + "\n")
+	}
+	for _, failure := range d.Failures {
+		sb.WriteString("- ")
+		if loc := failure.Location(); loc != "" {
+			sb.WriteString(loc + ": ")
+		}
+		if failure.Test != "" {
+			sb.WriteString("[" + failure.Test + "] ")
+		}
+		sb.WriteString(failure.Message + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// CodeContext は特定したコードの箇所（Markdown）
+func (d *Diagnosis) CodeContext() string {
+	var sb strings.Builder
+	for i, location := range d.Locations {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString("### " + location.File)
+		if location.Line > 0 {
+			sb.WriteString(fmt.Sprintf(":%d", location.Line))
+		}
+		if location.Test != "" {
+			sb.WriteString(" (" + location.Test + ")")
+		}
+		sb.WriteString("\n```\n" + location.Snippet + "\n```")
+	}
+	return sb.String()
+}
+
+// Prompt は修正を依頼するプロンプト（TemplateCIFix）を描画する
+func (d *Diagnosis) Prompt(registry *prompts.Registry, language, model, memory string) (string, error) {
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	return registry.Render(prompts.TemplateCIFix, prompts.Data{
+		Language:    language,
+		ModelFamily: prompts.ModelFamily(model),
+		Memory:      memory,
+		Intent:      d.Title(),
+		CurrentFile: d.Step,
+		LastOutput:  d.FailureList(),
+		Context:     d.CodeContext(),
+		Input:       d.Excerpt,
+	})
+}
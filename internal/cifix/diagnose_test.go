@@ -0,0 +1,99 @@
+package cifix
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// GitHub Actions のジョブログ（時刻・ANSI・ステップの区切り付き）
+const actionsLog = "2024-05-01T12:00:00.0000000Z ##[group]Run actions/checkout@v4\n" +
+	"2024-05-01T12:00:00.1000000Z with:\n" +
+	"2024-05-01T12:00:00.2000000Z ##[endgroup]\n" +
+	"2024-05-01T12:00:01.0000000Z Syncing repository: o/r\n" +
+	"2024-05-01T12:00:02.0000000Z ##[group]Run go test ./...\n" +
+	"2024-05-01T12:00:02.1000000Z \x1b[36;1mgo test ./...\x1b[0m\n" +
+	"2024-05-01T12:00:02.2000000Z shell: /usr/bin/bash -e {0}\n" +
+	"2024-05-01T12:00:02.3000000Z ##[endgroup]\n" +
+	"2024-05-01T12:00:05.0000000Z --- FAIL: TestLogin (0.00s)\n" +
+	"2024-05-01T12:00:05.1000000Z     auth_test.go:9: expected 200, got 500\n" +
+	"2024-05-01T12:00:05.2000000Z --- FAIL: TestLogout (0.00s)\n" +
+	"2024-05-01T12:00:05.3000000Z FAIL\texample.com/app/auth\t0.01s\n" +
+	"2024-05-01T12:00:05.4000000Z ##[error]Process completed with exit code 1.\n" +
+	"2024-05-01T12:00:06.0000000Z ##[group]Run actions/upload-artifact@v4\n" +
+	"2024-05-01T12:00:06.1000000Z ##[endgroup]\n" +
+	"2024-05-01T12:00:06.2000000Z Skipped\n"
+
+func TestDiagnoseActionsLog(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "auth"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	test := "package auth\n\nimport \"testing\"\n\nfunc TestLogin(t *testing.T) {\n\tstatus := login()\n\tif status != 200 {\n\t\t// ...\n\t\tt.Errorf(\"expected 200, got %d\", status)\n\t}\n}\n\nfunc TestLogout(t *testing.T) {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "auth", "auth_test.go"), []byte(test), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 同名で先に見つかるファイルはテストを定義していないので選ばない
+	if err := os.MkdirAll(filepath.Join(dir, "a"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "a", "auth_test.go"), []byte("package a\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	d := Diagnose(dir, "CI / test", actionsLog)
+	if d.Step != "go test ./..." || d.ExitCode != "1" || len(d.Errors) != 0 {
+		t.Fatalf("失敗したステップを選ぶはず: %+v", d)
+	}
+	if strings.Contains(d.Excerpt, "shell:") || strings.Contains(d.Excerpt, "2024-05-01") || !strings.Contains(d.Excerpt, "expected 200, got 500") {
+		t.Errorf("抜粋はステップの出力のみ（時刻・コマンドの echo を除く）のはず:\n%s", d.Excerpt)
+	}
+	if tests := d.Tests(); len(tests) != 2 || d.Title() != "Fix 2 failing tests" || d.Check() != "test" {
+		t.Errorf("tests = %v, title = %q, check = %q", tests, d.Title(), d.Check())
+	}
+	// ファイル名だけのパスもプロジェクト内で見つけ、同じファイルは1回だけ添付する
+	if len(d.Locations) != 1 || d.Locations[0].File != "auth/auth_test.go" || d.Locations[0].Line != 9 || !strings.Contains(d.Locations[0].Snippet, ">    9 | \t\tt.Errorf") {
+		t.Fatalf("locations = %+v", d.Locations)
+	}
+
+	prompt, err := d.Prompt(prompts.NewRegistry(""), "en", "qwen2.5-coder", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"Fix 2 failing tests", "go test ./...", "auth_test.go:9: [TestLogin] expected 200, got 500", "### auth/auth_test.go:9 (TestLogin)"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("プロンプトに %q がありません:\n%s", want, prompt)
+		}
+	}
+}
+
+func TestDiagnosePlainLog(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "src", "app.ts"), []byte("const a: number = 'x'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 区切りのないログは全体を1ステップとし、CI の絶対パスはプロジェクトの相対パスにする
+	log := "> tsc --noEmit\n/home/runner/work/r/r/src/app.ts(1,7): error TS2322: Type 'string' is not assignable to type 'number'.\n"
+	d := Diagnose(dir, "build.log", log)
+	if d.Step != "" || len(d.Failures) != 1 || len(d.Locations) != 1 || d.Locations[0].File != "src/app.ts" {
+		t.Fatalf("diagnosis = %+v", d)
+	}
+	if d.Title() != "Fix CI error in src/app.ts" {
+		t.Errorf("title = %q", d.Title())
+	}
+	if d.Check() != "build" {
+		t.Errorf("テストの失敗でなければ build で確認するはず: %q", d.Check())
+	}
+
+	if d := Diagnose(dir, "empty.log", "\n"); d.Title() != "Fix CI failure" || len(d.Locations) != 0 {
+		t.Errorf("解析できないログ: %+v", d)
+	}
+}
@@ -0,0 +1,128 @@
+// Package codecheck は応答中の生成コード（Markdown のコードブロック）を表示・適用する前に検証する。
+// Go は gofmt 相当の構文解析と go vet、TypeScript は tsc --noEmit、Python は構文解析で確かめ、
+// 見つかった問題はモデルに修正を求める文面にまとめる。検証に使うツールがない言語は検証しない。
+package codecheck
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/markdown"
+)
+
+// 検証できる言語
+const (
+	LanguageGo         = "go"
+	LanguageTypeScript = "typescript"
+	LanguagePython     = "python"
+)
+
+// ValidLanguages は検証できる言語
+func ValidLanguages() []string {
+	return []string{LanguageGo, LanguageTypeScript, LanguagePython}
+}
+
+// languageAliases はコードブロックの言語名と検証する言語の対応
+var languageAliases = map[string]string{
+	"go":         LanguageGo,
+	"golang":     LanguageGo,
+	"ts":         LanguageTypeScript,
+	"tsx":        LanguageTypeScript,
+	"typescript": LanguageTypeScript,
+	"py":         LanguagePython,
+	"python":     LanguagePython,
+	"python3":    LanguagePython,
+}
+
+// LanguageOf はコードブロックの言語名（```go title="x" の go 等）から検証する言語を返す（対象外なら空）
+func LanguageOf(tag string) string {
+	fields := strings.Fields(strings.ToLower(tag))
+	if len(fields) == 0 {
+		return ""
+	}
+	return languageAliases[fields[0]]
+}
+
+// Issue は検証に失敗したコードブロック1つ
+type Issue struct {
+	Block    int    `json:"block"` // 応答中のコードブロックの番号（1始まり）
+	Language string `json:"language"`
+	Tool     string `json:"tool"`    // 検証したツール（gofmt, go vet, tsc, python）
+	Message  string `json:"message"` // ツールの出力（行番号はコードブロックの先頭からの行）
+}
+
+// String は「block 1 (go, gofmt): メッセージ」の形式
+func (i Issue) String() string {
+	return fmt.Sprintf("block %d (%s, %s): %s", i.Block, i.Language, i.Tool, i.Message)
+}
+
+// checkFunc は1つのコードブロックを検証し、問題があればツール名と出力を返す（ツールがなければ空）
+type checkFunc func(ctx context.Context, code string) (tool, message string)
+
+// checkers は言語毎の検証
+var checkers = map[string]checkFunc{
+	LanguageGo:         checkGo,
+	LanguageTypeScript: checkTypeScript,
+	LanguagePython:     checkPython,
+}
+
+// Checker は有効な言語のコードブロックを検証する（nil は何も検証しない）
+type Checker struct {
+	languages map[string]bool
+	timeout   time.Duration
+}
+
+// New は languages（空なら全て）のコードブロックを、1ブロックあたり timeout（0以下なら30秒）で検証する Checker を作成
+func New(languages []string, timeout time.Duration) (*Checker, error) {
+	if len(languages) == 0 {
+		languages = ValidLanguages()
+	}
+	if timeout <= 0 {
+		timeout = 30 * time.Second
+	}
+	c := &Checker{languages: make(map[string]bool), timeout: timeout}
+	for _, language := range languages {
+		if _, ok := checkers[language]; !ok {
+			return nil, fmt.Errorf("unknown code check language %q (valid: %s)", language, strings.Join(ValidLanguages(), ", "))
+		}
+		c.languages[language] = true
+	}
+	return c, nil
+}
+
+// Check は content 中のコードブロックを出現順に検証し、失敗したものを返す
+func (c *Checker) Check(ctx context.Context, content string) []Issue {
+	if c == nil {
+		return nil
+	}
+	var issues []Issue
+	for i, block := range markdown.CodeBlocks(content) {
+		language := LanguageOf(block.Language)
+		if !c.languages[language] || strings.TrimSpace(block.Code) == "" {
+			continue
+		}
+		blockCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		tool, message := checkers[language](blockCtx, block.Code)
+		cancel()
+		if message != "" {
+			issues = append(issues, Issue{Block: i + 1, Language: language, Tool: tool, Message: message})
+		}
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return issues
+}
+
+// RepairPrompt は検証の失敗を伝え、応答全体を直して返すようモデルに求める文面
+func RepairPrompt(issues []Issue) string {
+	var b strings.Builder
+	b.WriteString("The code blocks in your previous answer failed automatic validation:\n\n")
+	for _, issue := range issues {
+		fmt.Fprintf(&b, "- %s\n", strings.ReplaceAll(issue.String(), "\n", "\n  "))
+	}
+	b.WriteString("\nFix these errors and reply with the complete corrected answer. Keep everything else unchanged and do not mention this validation step.")
+	return b.String()
+}
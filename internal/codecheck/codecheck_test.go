@@ -0,0 +1,77 @@
+package codecheck
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCheckGo(t *testing.T) {
+	checker, err := New([]string{LanguageGo}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content := strings.Join([]string{
+		"Fragments only need to parse:",
+		"```go",
+		"x := compute()\nfmt.Println(x)",
+		"```",
+		"```golang",
+		"func broken( {",
+		"```",
+		"```python",
+		"def skipped(:",
+		"```",
+	}, "\n")
+	issues := checker.Check(context.Background(), content)
+	if len(issues) != 1 || issues[0].Block != 2 || issues[0].Tool != "gofmt" || !strings.HasPrefix(issues[0].Message, "line 1:") {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not installed")
+	}
+	vet := "```go\npackage main\n\nimport \"fmt\"\n\nfunc main() {\n\tfmt.Printf(\"%d\\n\", \"text\")\n}\n```"
+	issues = checker.Check(context.Background(), vet)
+	if len(issues) != 1 || issues[0].Tool != "go vet" || !strings.Contains(issues[0].Message, "line 6:") {
+		t.Fatalf("expected a go vet issue, got %+v", issues)
+	}
+	// 外部モジュールを使うファイルは構文だけを確かめる
+	external := "```go\npackage main\n\nimport \"github.com/example/missing\"\n\nfunc main() { missing.Run() }\n```"
+	if issues := checker.Check(context.Background(), external); len(issues) != 0 {
+		t.Errorf("external imports should not be vetted: %+v", issues)
+	}
+}
+
+func TestCheckPython(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skip("python3 is not installed")
+	}
+	checker, _ := New([]string{LanguagePython}, 10*time.Second)
+	content := "```py\nimport os\nprint(os.getcwd())\n```\n\n```python\ndef f(:\n    pass\n```"
+	issues := checker.Check(context.Background(), content)
+	if len(issues) != 1 || issues[0].Block != 2 || !strings.HasPrefix(issues[0].Message, "line 1:") {
+		t.Fatalf("unexpected issues: %+v", issues)
+	}
+	prompt := RepairPrompt(issues)
+	if !strings.Contains(prompt, "block 2 (python, python): line 1:") {
+		t.Errorf("repair prompt does not list the issue:\n%s", prompt)
+	}
+}
+
+func TestNewAndLanguageOf(t *testing.T) {
+	if _, err := New([]string{"cobol"}, 0); err == nil {
+		t.Error("expected an error for an unknown language")
+	}
+	for tag, want := range map[string]string{"Go": LanguageGo, "tsx": LanguageTypeScript, "python title=\"x.py\"": LanguagePython, "bash": "", "": ""} {
+		if got := LanguageOf(tag); got != want {
+			t.Errorf("LanguageOf(%q) = %q, want %q", tag, got, want)
+		}
+	}
+	var checker *Checker
+	if issues := checker.Check(context.Background(), "```go\nfunc (\n```"); issues != nil {
+		t.Errorf("nil checker should not check: %+v", issues)
+	}
+}
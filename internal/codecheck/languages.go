@@ -0,0 +1,160 @@
+package codecheck
+
+import (
+	"context"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// maxMessageLines は1つのコードブロックについて返すツールの出力の最大行数
+const maxMessageLines = 10
+
+// checkGo は gofmt と同じ構文解析（宣言・文だけの断片も可）を行い、
+// package 節のある完全なファイルで標準ライブラリだけを使う場合は go vet も実行する
+func checkGo(ctx context.Context, code string) (string, string) {
+	if _, err := format.Source([]byte(code)); err != nil {
+		return "gofmt", "line " + err.Error()
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.ImportsOnly)
+	if err != nil {
+		return "", "" // package 節のない断片は型検査できない
+	}
+	for _, spec := range file.Imports {
+		path, _ := strconv.Unquote(spec.Path.Value)
+		// 外部モジュール・cgo は単独では解決できない
+		if path == "C" || strings.Contains(strings.Split(path, "/")[0], ".") {
+			return "", ""
+		}
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return "", ""
+	}
+
+	dir, err := os.MkdirTemp("", "vyb-codecheck-")
+	if err != nil {
+		return "", ""
+	}
+	defer os.RemoveAll(dir)
+	if os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module snippet\n\ngo 1.20\n"), 0644) != nil ||
+		os.WriteFile(filepath.Join(dir, "snippet.go"), []byte(code), 0644) != nil {
+		return "", ""
+	}
+	cmd := exec.CommandContext(ctx, goBin, "vet", ".")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod", "GOTOOLCHAIN=local")
+	output, err := cmd.CombinedOutput()
+	if err == nil || ctx.Err() != nil {
+		return "", ""
+	}
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "vet: ")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "./")
+		lines = append(lines, strings.Replace(line, "snippet.go:", "line ", 1))
+	}
+	return "go vet", joinMessage(lines)
+}
+
+// tsDiagnostic は tsc --pretty false の1件の診断（ファイル名(行,列): error TSコード: メッセージ）
+var tsDiagnostic = regexp.MustCompile(`^.*\((\d+),(\d+)\): error TS(\d+): (.*)$`)
+
+// ignoredTSErrors は断片では解決できない参照の診断（モジュール・名前・型定義が見つからない）
+var ignoredTSErrors = map[string]bool{
+	"2304": true, // Cannot find name
+	"2307": true, // Cannot find module
+	"2503": true, // Cannot find namespace
+	"2580": true, // Cannot find name 'require' 等（@types/node がない）
+	"2582": true, // Cannot find name 'describe' 等（テストの型定義がない）
+	"2792": true, // Cannot find module（moduleResolution の違い）
+	"7016": true, // Could not find a declaration file for module
+}
+
+// checkTypeScript は tsc --noEmit で検査する（ワークスペースの node_modules/.bin/tsc を優先し、なければ PATH の tsc）
+func checkTypeScript(ctx context.Context, code string) (string, string) {
+	tsc := filepath.Join("node_modules", ".bin", "tsc")
+	if _, err := os.Stat(tsc); err != nil {
+		if tsc, err = exec.LookPath("tsc"); err != nil {
+			return "", ""
+		}
+	}
+	tsc, _ = filepath.Abs(tsc)
+
+	dir, err := os.MkdirTemp("", "vyb-codecheck-")
+	if err != nil {
+		return "", ""
+	}
+	defer os.RemoveAll(dir)
+	// JSX を含む断片も通るよう .tsx として検査する
+	file := filepath.Join(dir, "snippet.tsx")
+	if os.WriteFile(file, []byte(code), 0644) != nil {
+		return "", ""
+	}
+	cmd := exec.CommandContext(ctx, tsc, "--noEmit", "--pretty", "false", "--skipLibCheck", "--target", "es2020",
+		"--module", "esnext", "--moduleResolution", "node", "--jsx", "preserve", "--strict", "false", file)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err == nil || ctx.Err() != nil {
+		return "", ""
+	}
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		match := tsDiagnostic.FindStringSubmatch(strings.TrimSpace(line))
+		if match == nil || ignoredTSErrors[match[3]] {
+			continue
+		}
+		lines = append(lines, "line "+match[1]+":"+match[2]+": TS"+match[3]+": "+match[4])
+	}
+	return "tsc", joinMessage(lines)
+}
+
+// pythonSyntaxCheck は標準入力のコードを構文解析し、エラーを「line 行:列: メッセージ」で出力するスクリプト
+const pythonSyntaxCheck = `import ast, sys
+try:
+    ast.parse(sys.stdin.read())
+except SyntaxError as e:
+    print("line %s:%s: %s" % (e.lineno, e.offset, e.msg))
+    sys.exit(1)
+`
+
+// checkPython は python3（なければ python）で構文だけを検査する（ファイルを書き出さずバイトコードも作らない）
+func checkPython(ctx context.Context, code string) (string, string) {
+	python, err := exec.LookPath("python3")
+	if err != nil {
+		if python, err = exec.LookPath("python"); err != nil {
+			return "", ""
+		}
+	}
+	cmd := exec.CommandContext(ctx, python, "-c", pythonSyntaxCheck)
+	cmd.Stdin = strings.NewReader(code)
+	output, err := cmd.Output()
+	if err == nil || ctx.Err() != nil {
+		return "", ""
+	}
+	return "python", joinMessage(strings.Split(string(output), "\n"))
+}
+
+// joinMessage は空行を除いた出力を先頭 maxMessageLines 行まで連結する
+func joinMessage(lines []string) string {
+	var kept []string
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); line != "" {
+			kept = append(kept, line)
+		}
+	}
+	if len(kept) > maxMessageLines {
+		omitted := len(kept) - maxMessageLines
+		kept = append(kept[:maxMessageLines], "... ("+strconv.Itoa(omitted)+" more)")
+	}
+	return strings.Join(kept, "\n")
+}
@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/glkt/vyb-code/internal/redact"
 )
 
 // MCPサーバー設定
@@ -34,23 +36,357 @@ type LogConfig struct {
 
 // TUI設定（非推奨 - Claude Code風インターフェースに移行済み）
 type TUIConfig struct {
-	Enabled      bool   `json:"enabled"`       // TUI有効/無効（非推奨）
+	Enabled      bool   `json:"enabled"`       // ペイン構成のTUI有効/無効（無効・非対話端末では逐次表示）
 	Theme        string `json:"theme"`         // カラーテーマ（非推奨）
 	ShowSpinner  bool   `json:"show_spinner"`  // スピナー表示（非推奨）
 	ShowProgress bool   `json:"show_progress"` // プログレスバー表示（非推奨）
 	Animation    bool   `json:"animation"`     // アニメーション有効（非推奨）
+	FoldLines    int    `json:"fold_lines"`    // これを超える行数のコマンド出力を折り畳む（負の値で折り畳まない）
+	PageLines    int    `json:"page_lines"`    // 折り畳んだ出力を展開するときの1ページの行数
 }
 
 // ターミナルモード専用設定
 type TerminalModeConfig struct {
-	TypingSpeed     int  `json:"typing_speed"`      // タイピング速度（ミリ秒）
-	ShowGitInPrompt bool `json:"show_git_prompt"`   // プロンプトにGit情報表示
-	ShowProjectInfo bool `json:"show_project_info"` // プロジェクト情報表示
-	HistorySize     int  `json:"history_size"`      // 入力履歴サイズ
-	EnableSlashCmd  bool `json:"enable_slash_cmd"`  // スラッシュコマンド有効
-	AutoSaveSession bool `json:"auto_save_session"` // セッション自動保存
+	TypingSpeed     int    `json:"typing_speed"`      // タイピング速度（ミリ秒）
+	ShowGitInPrompt bool   `json:"show_git_prompt"`   // プロンプトにGit情報表示
+	ShowProjectInfo bool   `json:"show_project_info"` // プロジェクト情報表示
+	HistorySize     int    `json:"history_size"`      // 入力履歴サイズ
+	EnableSlashCmd  bool   `json:"enable_slash_cmd"`  // スラッシュコマンド有効
+	AutoSaveSession bool   `json:"auto_save_session"` // セッション自動保存
+	EditMode        string `json:"edit_mode"`         // 入力行の編集キー配置（emacs, vi）
+}
+
+// サンドボックス実行設定
+type SandboxConfig struct {
+	Mode              string   `json:"mode"`                // 実行バックエンド（host, docker, podman）
+	Image             string   `json:"image"`               // コンテナイメージ
+	AllowNetwork      bool     `json:"allow_network"`       // ネットワークアクセス許可
+	ReadOnlyWorkspace bool     `json:"read_only_workspace"` // ワークスペースを読み取り専用でマウント
+	MemoryLimit       string   `json:"memory_limit"`        // メモリ上限（例: 512m）
+	CPULimit          string   `json:"cpu_limit"`           // CPU上限（例: 1.0）
+	ExtraArgs         []string `json:"extra_args"`          // コンテナランタイムへの追加引数
+}
+
+// ネットワークポリシー設定（ツール・MCPからの外部通信制御）
+type NetworkPolicyConfig struct {
+	Mode           string   `json:"mode"`            // ポリシーモード（allowlist, deny_all, allow_all）
+	AllowedDomains []string `json:"allowed_domains"` // 通信許可ドメイン
+	BlockedDomains []string `json:"blocked_domains"` // 通信禁止ドメイン
+	AuditRequests  bool     `json:"audit_requests"`  // 外部通信を監査ログに記録
+	AllowLoopback  bool     `json:"allow_loopback"`  // ローカルホストへの通信をモードに関わらず許可（ローカルのSearxNG等）
+}
+
+// Webツール設定（Web取得・Web検索）
+type WebToolsConfig struct {
+	SearchBackend   string `json:"search_backend"`    // 検索バックエンド（duckduckgo, searxng, brave）
+	SearxNGURL      string `json:"searxng_url"`       // SearxNGインスタンスのURL
+	BraveAPIKey     string `json:"brave_api_key"`     // Brave Search APIキー（未設定時はBRAVE_API_KEY環境変数）
+	FetchMaxBytes   int64  `json:"fetch_max_bytes"`   // Web取得の最大サイズ（バイト）
+	CacheTTLMinutes int    `json:"cache_ttl_minutes"` // Web取得キャッシュの有効期間（分、0で無効）
+}
+
+// LLM応答キャッシュ設定（同一・類似プロンプトの再問い合わせ削減）
+type LLMCacheConfig struct {
+	Enabled             bool    `json:"enabled"`              // キャッシュ有効/無効
+	TTLMinutes          int     `json:"ttl_minutes"`          // キャッシュの有効期間（分）
+	MaxEntries          int     `json:"max_entries"`          // 最大保持件数
+	SemanticMatching    bool    `json:"semantic_matching"`    // 埋め込みによる類似プロンプト照合
+	EmbeddingModel      string  `json:"embedding_model"`      // 埋め込み生成に使うモデル（空なら embeddings.model）
+	SimilarityThreshold float64 `json:"similarity_threshold"` // 類似とみなすコサイン類似度の下限
+}
+
+// LLMエンドポイントのリトライ・フェイルオーバー設定
+type ResilienceConfig struct {
+	MaxRetries       int                `json:"max_retries"`        // 一時的なエラーの再試行回数（エンドポイント毎）
+	InitialBackoffMs int                `json:"initial_backoff_ms"` // 最初の再試行までの待機時間（ミリ秒、以降は倍増）
+	MaxBackoffMs     int                `json:"max_backoff_ms"`     // 再試行間隔の上限（ミリ秒）
+	FailureThreshold int                `json:"failure_threshold"`  // サーキットブレーカーを開く連続失敗数
+	CooldownSeconds  int                `json:"cooldown_seconds"`   // 開いたブレーカーで再び試行するまでの秒数
+	Fallbacks        []FallbackEndpoint `json:"fallbacks"`          // プライマリが停止中に順に試す代替エンドポイント
+}
+
+// LLMリクエスト・ツール実行・分析の同時実行数の設定（超えた分は待機）
+type ConcurrencyConfig struct {
+	MaxLLMRequests    int `json:"max_llm_requests"`    // LLMへの同時リクエスト数
+	MaxToolExecutions int `json:"max_tool_executions"` // ツールの同時実行数
+	MaxAnalyses       int `json:"max_analyses"`        // プロジェクト全体の分析の同時実行数
+	MaxBackground     int `json:"max_background"`      // プロアクティブ分析等のバックグラウンド処理の同時実行数（種類の合計）
+}
+
+// 代替のLLMエンドポイント（空の項目はプライマリの設定を使用）
+type FallbackEndpoint struct {
+	Provider string `json:"provider"` // プロバイダー（ollama等）
+	BaseURL  string `json:"base_url"` // サーバーのURL
+	Model    string `json:"model"`    // 使用するモデル名
+}
+
+// コンテキスト圧縮の検証設定（要約で失われた事実の検出・補完）
+type ContextCompressionConfig struct {
+	Validation     string  `json:"validation"`      // 検証方式（key_facts, embedding, llm）
+	MinFidelity    float64 `json:"min_fidelity"`    // これを下回ると失われた事実をキーポイントに補う（0.0-1.0）
+	EmbeddingModel string  `json:"embedding_model"` // embedding 方式で使う埋め込みモデル（空なら embeddings.model）
+}
+
+// ビルド・テスト失敗時の自動修正ループ設定
+type FixLoopConfig struct {
+	Enabled        bool `json:"enabled"`         // 編集適用後の自動検証・修正の有効/無効
+	MaxIterations  int  `json:"max_iterations"`  // 修正を試みる最大回数
+	RunTests       bool `json:"run_tests"`       // ビルド成功後にテストも実行するか
+	TimeoutSeconds int  `json:"timeout_seconds"` // ビルド・テスト1回あたりのタイムアウト（秒）
+}
+
+// 生成コードを表示・適用する前に検証し、失敗したらモデルに修正させる設定
+type CodeCheckConfig struct {
+	Enabled        bool     `json:"enabled"`         // 応答中のコードブロックの検証の有効/無効
+	Languages      []string `json:"languages"`       // 検証する言語（go, typescript, python。空なら全て）
+	MaxRepairs     int      `json:"max_repairs"`     // 検証の失敗を伝えて応答を作り直させる最大回数
+	TimeoutSeconds int      `json:"timeout_seconds"` // コードブロック1つの検証のタイムアウト（秒）
+}
+
+// 1つの入力内でツール実行結果をモデルに返して次の行動を求めるエージェントループの設定
+type AgentLoopConfig struct {
+	Enabled     bool `json:"enabled"`      // 無効ならツールを1回実行した時点で応答する
+	MaxSteps    int  `json:"max_steps"`    // 1つの入力でモデルに問い合わせる最大回数（最初の応答を含む）
+	StreamTools bool `json:"stream_tools"` // 応答の生成中に完成した <COMMAND> から実行を始める
+}
+
+// セッション中のコマンド・ツールの実行結果の記憶（<RECALL> で検索）の設定
+type RecallConfig struct {
+	Enabled        bool   `json:"enabled"`         // 無効なら実行結果を記憶せず <RECALL> も案内しない
+	MaxBytes       int    `json:"max_bytes"`       // 記憶する出力の合計サイズ（超えたら古い結果から要約する）
+	MaxResults     int    `json:"max_results"`     // 1回の検索で返す結果の最大数
+	Semantic       bool   `json:"semantic"`        // キーワードに加えて埋め込みの類似度で検索する
+	EmbeddingModel string `json:"embedding_model"` // semantic で使う埋め込みモデル（空なら embeddings.model）
+}
+
+// 検出した言語・フレームワークの指針（.vyb/knowledge）をプロンプトに加える設定
+type KnowledgeConfig struct {
+	Enabled   bool     `json:"enabled"`    // 無効なら指針を加えない
+	MaxGuides int      `json:"max_guides"` // プロンプトに加える指針の最大数（フレームワークを言語より優先）
+	Disabled  []string `json:"disabled"`   // 検出しても加えない指針の名前
+}
+
+// 最初のやり取りからセッションの題名を付ける設定
+type SessionTitleConfig struct {
+	Enabled   bool   `json:"enabled"`    // 無効なら /title で付けるまで題名なし
+	Model     string `json:"model"`      // 題名の生成に使う小さいモデル（空なら会話のモデル）
+	MaxLength int    `json:"max_length"` // 題名の最大文字数
+}
+
+// 応答生成パイプラインの段（intent, retrieval, generation, execution, render）毎に使う実装の設定
+// 指定のない段・"default" は組み込みの実装を使う
+type PipelineConfig struct {
+	Stages map[string]string `json:"stages"` // 段の種類 → 登録された実装名
+}
+
+// ValidPipelineStages は応答生成パイプラインの段の種類（実行順）
+func ValidPipelineStages() []string {
+	return []string{"intent", "retrieval", "generation", "execution", "render"}
+}
+
+// 確認ダイアログの前に評価する提案の自動承認ポリシー（上から順に評価し、最初に一致したルールの判定を使う）
+// どのルールにも一致しない提案は従来どおり確認する
+type ApprovalConfig struct {
+	Rules []ApprovalRule `json:"rules"`
+}
+
+// ApprovalRule は提案の自動承認ルール（未指定の条件は全ての提案に一致）
+type ApprovalRule struct {
+	Name          string   `json:"name"`                     // ルール名（remove-approval-rule で指定）
+	Decision      string   `json:"decision"`                 // auto: 確認せず適用, prompt: 確認する, deny: 適用しない
+	Kinds         []string `json:"kinds,omitempty"`          // 操作の種類（create, edit, command）
+	Changes       []string `json:"changes,omitempty"`        // 変更の内容（docs: コメント・ドキュメントのみ, test: テストファイル, source: それ以外）
+	Paths         []string `json:"paths,omitempty"`          // 対象ファイルのglob（"/" を含まなければファイル名と照合、dir/** で配下全て）
+	Branches      []string `json:"branches,omitempty"`       // 現在のgitブランチのglob（main, release/* 等）
+	MinConfidence float64  `json:"min_confidence,omitempty"` // 信頼度がこの値以上の場合のみ一致
+	MaxImpact     string   `json:"max_impact,omitempty"`     // 影響レベルがこれ以下の場合のみ一致（low, medium, high, critical）
+}
+
+// ValidApprovalDecisions は承認ルールの判定
+func ValidApprovalDecisions() []string {
+	return []string{"auto", "prompt", "deny"}
+}
+
+// ValidApprovalKinds は承認ルールで指定できる操作の種類
+func ValidApprovalKinds() []string {
+	return []string{"create", "edit", "command"}
+}
+
+// ValidApprovalChanges は承認ルールで指定できる変更の内容
+func ValidApprovalChanges() []string {
+	return []string{"docs", "test", "source"}
+}
+
+// ValidImpactLevels は承認ルールで指定できる影響レベル（低い順）
+func ValidImpactLevels() []string {
+	return []string{"low", "medium", "high", "critical"}
+}
+
+// ファイル走査（分析・監視・インデックス・ファイルツール）の除外設定
+// 組み込みの除外パターン・.gitignore・.vybignore に加えて適用する
+type IgnoreConfig struct {
+	Patterns []string `json:"patterns"` // 追加の除外パターン（.gitignore と同じ書式、プロジェクトのルートからの相対）
+}
+
+// 埋め込みモデルの設定（チャットのモデルとは別に指定し、各機能で共有する）
+type EmbeddingsConfig struct {
+	Model    string `json:"model"`     // 埋め込みモデル
+	BaseURL  string `json:"base_url"`  // 埋め込みAPIのURL（空ならチャットと同じ base_url）
+	API      string `json:"api"`       // Ollama の埋め込みAPI（auto, embed, embeddings）
+	CacheDir string `json:"cache_dir"` // ファイルのベクトルキャッシュの保存先（空なら ~/.vyb/embeddings）
+}
+
+// ValidEmbeddingAPIs は設定できる Ollama の埋め込みAPI
+// auto は /api/embed を使い、未対応の古いサーバーでは /api/embeddings に切り替える
+func ValidEmbeddingAPIs() []string {
+	return []string{"auto", "embed", "embeddings"}
+}
+
+// vyb hooks install で入れるgitフックの設定
+type HooksConfig struct {
+	Checks      []string `json:"checks"`        // 実行するチェック（lint, secrets, commit-message）
+	BlockOnLint bool     `json:"block_on_lint"` // リントの失敗でコミットを止めるか（既定は要約の表示のみ）
+}
+
+// ValidHookChecks はgitフックで実行できるチェック
+// lint と secrets は pre-commit、commit-message は commit-msg フックで実行する
+func ValidHookChecks() []string {
+	return []string{"lint", "secrets", "commit-message"}
+}
+
+// 1回のユーザー入力（ターン）で使える資源の上限（負の値でその上限を無効化）
+type TurnLimitsConfig struct {
+	MaxToolCalls int `json:"max_tool_calls"` // ツール・コマンド実行の回数
+	MaxTokens    int `json:"max_tokens"`     // LLMリクエストの累計トークン数（プロンプト＋生成）
+	MaxSeconds   int `json:"max_seconds"`    // 経過時間（秒）
+}
+
+// LLMに送る内容・監査ログから機密情報を伏せる設定
+type RedactionConfig struct {
+	Level    string   `json:"level"`    // 伏せ字化の強さ（off, low, standard, strict）
+	Patterns []string `json:"patterns"` // 追加で伏せる値の正規表現（サブマッチがあれば1つ目だけを伏せる）
+}
+
+// ツール呼び出し・LLMリクエストの監査ログ設定
+type AuditConfig struct {
+	Enabled         bool   `json:"enabled"`           // 監査ログ記録の有効/無効
+	Dir             string `json:"dir"`               // 保存先ディレクトリ（空なら ~/.vyb/logs）
+	MaxContentBytes int    `json:"max_content_bytes"` // 1イベントに記録する本文の最大バイト数
+}
+
+// モデル毎のトークン単価（1,000トークンあたり）
+type ModelPrice struct {
+	PromptPer1K     float64 `json:"prompt_per_1k"`     // プロンプト1,000トークンあたりの料金
+	CompletionPer1K float64 `json:"completion_per_1k"` // 生成1,000トークンあたりの料金
+}
+
+// トークン使用量・コスト集計の設定
+type UsageConfig struct {
+	Enabled  bool                  `json:"enabled"`  // 使用量記録の有効/無効
+	Currency string                `json:"currency"` // 料金表示の通貨
+	Pricing  map[string]ModelPrice `json:"pricing"`  // モデル名（または "qwen2.5-coder" 等のプレフィックス、"*"）毎の単価
+}
+
+// サードパーティ拡張（~/.vyb/plugins/<name>/plugin.yaml）の設定
+type ExtensionsConfig struct {
+	Enabled        bool     `json:"enabled"`         // 拡張の読み込みの有効/無効
+	Project        bool     `json:"project"`         // プロジェクトの .vyb/plugins も読み込むか（任意のコードを実行するため既定で無効）
+	Disabled       []string `json:"disabled"`        // 読み込まない拡張名
+	TimeoutSeconds int      `json:"timeout_seconds"` // 拡張ツール1回あたりのタイムアウト（秒）
+}
+
+// 実行中のセッションの自動保存（クラッシュ後の復元）設定
+type AutosaveConfig struct {
+	Enabled         bool `json:"enabled"`          // 自動保存と次回起動時の復元確認の有効/無効
+	IntervalSeconds int  `json:"interval_seconds"` // 自動保存の間隔（秒）
+}
+
+// HTTP API（vyb serve --http）の設定
+type ServeConfig struct {
+	Tokens map[string]string `json:"tokens"` // 利用者名 → APIトークン（Authorization: Bearer で送る）
+}
+
+// 定期メンテナンス（vyb daemon）の設定
+type DaemonConfig struct {
+	Tasks           []string `json:"tasks"`             // 実行するタスク（vulnerabilities, index, branches）
+	At              string   `json:"at"`                // 毎日の実行時刻（HH:MM、ローカル時刻）
+	StaleBranchDays int      `json:"stale_branch_days"` // この日数コミットのないブランチを古いとみなす
+	Summary         bool     `json:"summary"`           // 次の対話セッションの開始時に前回以降の結果を要約するか
+}
+
+// ValidDaemonTasks は定期メンテナンスで実行できるタスク
+func ValidDaemonTasks() []string {
+	return []string{"vulnerabilities", "index", "branches"}
 }
 
+// 長いターンの完了通知設定
+type NotificationConfig struct {
+	Enabled          bool     `json:"enabled"`           // 通知の有効/無効
+	Method           string   `json:"method"`            // 通知方法（auto, bell, osc777, desktop）
+	ThresholdSeconds int      `json:"threshold_seconds"` // この秒数以上かかったターンのみ通知
+	Events           []string `json:"events"`            // 通知するイベント（complete: 完了, input: 確認待ち, error: 失敗）
+}
+
+// ValidNotificationMethods は設定できる通知方法
+func ValidNotificationMethods() []string {
+	return []string{"auto", "bell", "osc777", "desktop"}
+}
+
+// ValidNotificationEvents は通知できるイベント
+func ValidNotificationEvents() []string {
+	return []string{"complete", "input", "error"}
+}
+
+// ローカル専用の利用統計（vyb stats）の記録設定（オプトイン、外部には送らない）
+type TelemetryConfig struct {
+	Enabled bool     `json:"enabled"` // 記録の有効/無効（既定は無効）
+	Path    string   `json:"path"`    // 保存先（空なら ~/.vyb/telemetry.jsonl）
+	Events  []string `json:"events"`  // 記録する種類（session, turn, suggestion, edit）
+}
+
+// ValidTelemetryEvents は記録できる利用統計の種類
+func ValidTelemetryEvents() []string {
+	return []string{"session", "turn", "suggestion", "edit"}
+}
+
+// SSH 越しのリモート開発設定（Host が空なら無効）
+// ファイル操作・コマンド実行はリモートの `vyb agent` が行い、LLM・UI はローカルで動かす
+type RemoteConfig struct {
+	Host                  string   `json:"host"`                    // 接続先（[user@]host、~/.ssh/config の Host 名も可）
+	Dir                   string   `json:"dir"`                     // リモートの作業ディレクトリ（空ならログイン時のディレクトリ）
+	AgentCommand          string   `json:"agent_command"`           // リモートで起動するエージェントのコマンド
+	SSHArgs               []string `json:"ssh_args,omitempty"`      // ssh に渡す追加の引数（-p 2222、-i ~/.ssh/key 等）
+	ConnectTimeoutSeconds int      `json:"connect_timeout_seconds"` // 接続のタイムアウト（秒）
+}
+
+// メトリクス・トレースの外部出力設定（既定ではどちらも無効）
+type ObservabilityConfig struct {
+	MetricsAddress string            `json:"metrics_address"`        // Prometheus の /metrics を公開するアドレス（例: 127.0.0.1:9464、空なら無効）
+	OTLPEndpoint   string            `json:"otlp_endpoint"`          // ターン毎のトレースを送る OTLP/HTTP の送信先（例: http://localhost:4318、空なら無効）
+	OTLPHeaders    map[string]string `json:"otlp_headers,omitempty"` // 送信時に付けるヘッダー（認証トークン等）
+	ServiceName    string            `json:"service_name"`           // トレースの service.name
+}
+
+// ターン毎のワークスペースチェックポイント設定
+type CheckpointConfig struct {
+	Enabled       bool `json:"enabled"`         // ファイル変更を伴うターンの前にスナップショットを保存
+	MaxPerSession int  `json:"max_per_session"` // セッション毎に保持するチェックポイントの上限
+}
+
+// セッション・メトリクス・会話・キャッシュの保存先の設定
+type StorageConfig struct {
+	Backend string `json:"backend"` // sqlite（ファイルに永続化）または memory（プロセス終了で破棄）
+	Path    string `json:"path"`    // SQLite データベースファイル（空なら ~/.vyb/vyb.db）
+}
+
+// ValidStorageBackends は設定できる保存先の種類
+func ValidStorageBackends() []string {
+	return []string{"sqlite", "memory"}
+}
+
+// DefaultModel はモデルが設定されていない場合に使うモデル
+const DefaultModel = "qwen2.5-coder:14b"
+
 // vybの設定情報を管理する構造体
 type Config struct {
 	// LLM設定
@@ -63,6 +399,8 @@ type Config struct {
 	MaxTokens   int     `json:"max_tokens"`  // 最大トークン数
 	Stream      bool    `json:"stream"`      // ストリーミング応答
 
+	ContextWindow int `json:"context_window"` // モデルのコンテキスト長（トークン）
+
 	// システム設定
 	MaxFileSize    int64  `json:"max_file_size"`    // 読み込み可能な最大ファイルサイズ
 	FileMaxSizeMB  int    `json:"file_max_size_mb"` // ファイル最大サイズ（MB）
@@ -70,18 +408,55 @@ type Config struct {
 	WorkspacePath  string `json:"workspace_path"`   // 作業ディレクトリパス
 	CommandTimeout int    `json:"command_timeout"`  // コマンド実行タイムアウト（秒）
 	MaxHistory     int    `json:"max_history"`      // 履歴保持数
+	Language       string `json:"language"`         // 表示・応答言語（ja, en, auto）
 
 	// サブ設定
-	MCPServers   map[string]MCPServerConfig `json:"mcp_servers"`   // MCPサーバー設定
-	Log          LogConfig                  `json:"log"`           // ログ設定
-	Logging      LogConfig                  `json:"logging"`       // ログ設定（互換性）
-	TUI          TUIConfig                  `json:"tui"`           // TUI設定
-	TerminalMode TerminalModeConfig         `json:"terminal_mode"` // ターミナルモード設定
-	Markdown     MarkdownConfig             `json:"markdown"`      // Markdown設定
-	Features     *Features                  `json:"features"`      // 機能設定
-	Proactive    ProactiveConfig            `json:"proactive"`     // プロアクティブ設定
-	Migration    GradualMigrationConfig     `json:"migration"`     // 段階的移行設定
-	Prompts      *PromptConfig              `json:"prompts"`       // プロンプト設定
+	MCPServers    map[string]MCPServerConfig `json:"mcp_servers"`         // MCPサーバー設定
+	Log           LogConfig                  `json:"log"`                 // ログ設定
+	Logging       LogConfig                  `json:"logging"`             // ログ設定（互換性）
+	TUI           TUIConfig                  `json:"tui"`                 // TUI設定
+	TerminalMode  TerminalModeConfig         `json:"terminal_mode"`       // ターミナルモード設定
+	Markdown      MarkdownConfig             `json:"markdown"`            // Markdown設定
+	Features      *Features                  `json:"features"`            // 機能設定
+	Proactive     ProactiveConfig            `json:"proactive"`           // プロアクティブ設定
+	Migration     GradualMigrationConfig     `json:"migration"`           // 段階的移行設定
+	Prompts       *PromptConfig              `json:"prompts"`             // プロンプト設定
+	Sandbox       SandboxConfig              `json:"sandbox"`             // サンドボックス実行設定
+	Network       NetworkPolicyConfig        `json:"network"`             // ネットワークポリシー設定
+	WebTools      WebToolsConfig             `json:"web_tools"`           // Webツール設定
+	LLMCache      LLMCacheConfig             `json:"llm_cache"`           // LLM応答キャッシュ設定
+	Resilience    ResilienceConfig           `json:"resilience"`          // リトライ・フェイルオーバー設定
+	Concurrency   ConcurrencyConfig          `json:"concurrency"`         // LLM・ツールの同時実行数の制限
+	Compression   ContextCompressionConfig   `json:"context_compression"` // コンテキスト圧縮の検証設定
+	FixLoop       FixLoopConfig              `json:"fix_loop"`            // 自動修正ループ設定
+	CodeCheck     CodeCheckConfig            `json:"code_check"`          // 生成コードの検証設定
+	AgentLoop     AgentLoopConfig            `json:"agent_loop"`          // ツール実行結果を返して続けるエージェントループ設定
+	Recall        RecallConfig               `json:"recall"`              // コマンド・ツールの実行結果の記憶と検索
+	Knowledge     KnowledgeConfig            `json:"knowledge"`           // 言語・フレームワーク別の指針
+	Pipeline      PipelineConfig             `json:"pipeline"`            // 応答生成パイプラインの段の実装
+	Approval      ApprovalConfig             `json:"approval"`            // 提案の自動承認ポリシー
+	SessionTitles SessionTitleConfig         `json:"session_titles"`      // セッションの題名の自動生成
+	Permissions   PermissionsConfig          `json:"permissions"`         // コマンド・パスの許可規則（.vyb/permissions.yaml と合算）
+	TurnLimits    TurnLimitsConfig           `json:"turn_limits"`         // 1ターンの資源上限
+	Embeddings    EmbeddingsConfig           `json:"embeddings"`          // 埋め込みモデル・ベクトルキャッシュ設定
+	Ignore        IgnoreConfig               `json:"ignore"`              // ファイル走査の除外設定
+	Hooks         HooksConfig                `json:"hooks"`               // gitフックのチェック設定
+	Audit         AuditConfig                `json:"audit"`               // 監査ログ設定
+	Redaction     RedactionConfig            `json:"redaction"`           // 機密情報の伏せ字化設定
+	Usage         UsageConfig                `json:"usage"`               // 使用量・コスト集計設定
+	Checkpoints   CheckpointConfig           `json:"checkpoints"`         // ワークスペースチェックポイント設定
+	Storage       StorageConfig              `json:"storage"`             // セッション・メトリクス・会話の保存先
+	Extensions    ExtensionsConfig           `json:"extensions"`          // サードパーティ拡張設定
+	Observability ObservabilityConfig        `json:"observability"`       // メトリクス・トレースの外部出力設定
+	Autosave      AutosaveConfig             `json:"autosave"`            // 自動保存・クラッシュ復元設定
+	Notifications NotificationConfig         `json:"notifications"`       // 長いターンの完了通知設定
+	Telemetry     TelemetryConfig            `json:"telemetry"`           // ローカル専用の利用統計の記録設定
+	Daemon        DaemonConfig               `json:"daemon"`              // 定期メンテナンス（vyb daemon）設定
+	Serve         ServeConfig                `json:"serve"`               // HTTP API（vyb serve --http）設定
+	Remote        RemoteConfig               `json:"remote"`              // SSH 越しのリモート開発設定
+
+	// 名前付きプロファイル（--profile で選択、部分的な設定を上書き）
+	Profiles map[string]map[string]interface{} `json:"profiles,omitempty"`
 
 	// 内部管理用（JSONには含まれない）
 	featureManager *FeatureManager `json:"-"` // 機能フラグマネージャー
@@ -89,8 +464,14 @@ type Config struct {
 
 // Markdown設定
 type MarkdownConfig struct {
-	Enabled         bool `json:"enabled"`          // Markdown有効/無効
-	SyntaxHighlight bool `json:"syntax_highlight"` // シンタックスハイライト
+	Enabled         bool   `json:"enabled"`          // Markdown有効/無効
+	SyntaxHighlight bool   `json:"syntax_highlight"` // シンタックスハイライト
+	SyntaxTheme     string `json:"syntax_theme"`     // コードブロック・差分の配色（dark, light, none）
+}
+
+// ValidSyntaxThemes は設定できるシンタックスハイライトの配色
+func ValidSyntaxThemes() []string {
+	return []string{"dark", "light", "none"}
 }
 
 // 機能設定
@@ -189,14 +570,16 @@ func DefaultConfig() *Config {
 	return &Config{
 		// LLM設定
 		Provider:    "ollama",
-		Model:       "qwen2.5-coder:14b",
-		ModelName:   "qwen2.5-coder:14b",
+		Model:       DefaultModel,
+		ModelName:   DefaultModel,
 		BaseURL:     "http://localhost:11434",
 		Timeout:     120, // 2分に延長
 		Temperature: 0.7,
 		MaxTokens:   4096,
 		Stream:      true,
 
+		ContextWindow: 32768,
+
 		// システム設定
 		MaxFileSize:    10 * 1024 * 1024, // 10MB
 		FileMaxSizeMB:  10,
@@ -204,6 +587,7 @@ func DefaultConfig() *Config {
 		WorkspacePath:  ".",
 		CommandTimeout: 60, // 1分に延長
 		MaxHistory:     100,
+		Language:       "ja",
 
 		// サブ設定
 		MCPServers: make(map[string]MCPServerConfig),
@@ -230,11 +614,13 @@ func DefaultConfig() *Config {
 			Context:       make(map[string]string),
 		},
 		TUI: TUIConfig{
-			Enabled:      false, // レガシーTUI無効（Claude Code風UIに完全移行）
+			Enabled:      true,  // ペイン構成のTUI（--no-tui で逐次表示）
 			Theme:        "",    // 使用しない
 			ShowSpinner:  false, // Claude Code風UIで代替
 			ShowProgress: false, // Claude Code風UIで代替
 			Animation:    false, // Claude Code風UIで代替
+			FoldLines:    30,    // 30行を超える出力は先頭・末尾だけ表示
+			PageLines:    200,   // 展開は200行ずつ
 		},
 		TerminalMode: TerminalModeConfig{
 			TypingSpeed:     15, // 15ms per character
@@ -243,10 +629,12 @@ func DefaultConfig() *Config {
 			HistorySize:     100,
 			EnableSlashCmd:  true,
 			AutoSaveSession: false,
+			EditMode:        "emacs",
 		},
 		Markdown: MarkdownConfig{
 			Enabled:         true,
 			SyntaxHighlight: true,
+			SyntaxTheme:     "dark",
 		},
 		Features: &Features{
 			VibeMode:      true, // バイブコーディングモード有効
@@ -280,7 +668,309 @@ func DefaultConfig() *Config {
 			MetricsInterval:  60,    // 1分間隔
 			LogMigrationInfo: false, // 移行完了により不要
 		},
-		Prompts: DefaultPromptConfig(), // プロンプト設定のデフォルト
+		Prompts:       DefaultPromptConfig(), // プロンプト設定のデフォルト
+		Sandbox:       DefaultSandboxConfig(),
+		Network:       DefaultNetworkPolicyConfig(),
+		WebTools:      DefaultWebToolsConfig(),
+		LLMCache:      DefaultLLMCacheConfig(),
+		Resilience:    DefaultResilienceConfig(),
+		Concurrency:   DefaultConcurrencyConfig(),
+		Compression:   DefaultContextCompressionConfig(),
+		FixLoop:       DefaultFixLoopConfig(),
+		CodeCheck:     DefaultCodeCheckConfig(),
+		AgentLoop:     DefaultAgentLoopConfig(),
+		Recall:        DefaultRecallConfig(),
+		Knowledge:     DefaultKnowledgeConfig(),
+		Pipeline:      PipelineConfig{Stages: map[string]string{}},
+		Approval:      ApprovalConfig{Rules: []ApprovalRule{}},
+		SessionTitles: DefaultSessionTitleConfig(),
+		Permissions:   DefaultPermissionsConfig(),
+		TurnLimits:    DefaultTurnLimitsConfig(),
+		Embeddings:    DefaultEmbeddingsConfig(),
+		Ignore:        IgnoreConfig{Patterns: []string{}},
+		Hooks:         DefaultHooksConfig(),
+		Audit:         DefaultAuditConfig(),
+		Redaction:     DefaultRedactionConfig(),
+		Usage:         DefaultUsageConfig(),
+		Checkpoints:   DefaultCheckpointConfig(),
+		Storage:       DefaultStorageConfig(),
+		Extensions:    DefaultExtensionsConfig(),
+		Observability: DefaultObservabilityConfig(),
+		Autosave:      DefaultAutosaveConfig(),
+		Notifications: DefaultNotificationConfig(),
+		Telemetry:     DefaultTelemetryConfig(),
+		Daemon:        DefaultDaemonConfig(),
+		Remote:        DefaultRemoteConfig(),
+	}
+}
+
+// デフォルトの許可規則を返す（規則なし）
+func DefaultPermissionsConfig() PermissionsConfig {
+	return PermissionsConfig{
+		Commands: CommandPermissions{Allow: []string{}, Deny: []string{}},
+		Paths:    PathPermissions{Deny: []string{}, ReadOnly: []string{}},
+	}
+}
+
+// デフォルトの同時実行数の設定を返す
+// ローカルモデルの応答が遅くならないよう、バックグラウンドの処理は1件ずつにして対話用の枠を残す
+func DefaultConcurrencyConfig() ConcurrencyConfig {
+	return ConcurrencyConfig{
+		MaxLLMRequests:    2,
+		MaxToolExecutions: 4,
+		MaxAnalyses:       1,
+		MaxBackground:     1,
+	}
+}
+
+// デフォルトのリモート開発設定を返す（接続先は未設定）
+func DefaultRemoteConfig() RemoteConfig {
+	return RemoteConfig{
+		AgentCommand:          "vyb agent --stdio",
+		ConnectTimeoutSeconds: 15,
+	}
+}
+
+// デフォルトの通知設定を返す（30秒以上かかったターンの完了・確認待ち・失敗を通知）
+func DefaultNotificationConfig() NotificationConfig {
+	return NotificationConfig{
+		Enabled:          true,
+		Method:           "auto",
+		ThresholdSeconds: 30,
+		Events:           ValidNotificationEvents(),
+	}
+}
+
+// デフォルトの利用統計設定を返す（無効、有効にすればすべての種類を記録）
+func DefaultTelemetryConfig() TelemetryConfig {
+	return TelemetryConfig{
+		Enabled: false,
+		Path:    "",
+		Events:  ValidTelemetryEvents(),
+	}
+}
+
+// デフォルトの自動保存設定を返す
+func DefaultAutosaveConfig() AutosaveConfig {
+	return AutosaveConfig{
+		Enabled:         true,
+		IntervalSeconds: 15,
+	}
+}
+
+// デフォルトの定期メンテナンス設定を返す
+func DefaultDaemonConfig() DaemonConfig {
+	return DaemonConfig{
+		Tasks:           ValidDaemonTasks(),
+		At:              "03:00",
+		StaleBranchDays: 30,
+		Summary:         true,
+	}
+}
+
+// デフォルトのメトリクス・トレース出力設定を返す（出力先は未設定）
+func DefaultObservabilityConfig() ObservabilityConfig {
+	return ObservabilityConfig{
+		ServiceName: "vyb",
+	}
+}
+
+// デフォルトの拡張設定を返す（ユーザーディレクトリの拡張のみ読み込む）
+func DefaultExtensionsConfig() ExtensionsConfig {
+	return ExtensionsConfig{
+		Enabled:        true,
+		Project:        false,
+		Disabled:       []string{},
+		TimeoutSeconds: 60,
+	}
+}
+
+// デフォルトのチェックポイント設定を返す
+func DefaultCheckpointConfig() CheckpointConfig {
+	return CheckpointConfig{
+		Enabled:       true,
+		MaxPerSession: 20,
+	}
+}
+
+// デフォルトの保存先設定を返す（~/.vyb/vyb.db に永続化）
+func DefaultStorageConfig() StorageConfig {
+	return StorageConfig{Backend: "sqlite"}
+}
+
+// デフォルトの使用量集計設定を返す（ローカルモデルは無料のため単価は未設定）
+func DefaultUsageConfig() UsageConfig {
+	return UsageConfig{
+		Enabled:  true,
+		Currency: "USD",
+		Pricing:  make(map[string]ModelPrice),
+	}
+}
+
+// デフォルトの監査ログ設定を返す
+func DefaultAuditConfig() AuditConfig {
+	return AuditConfig{
+		Enabled:         true,
+		Dir:             "",
+		MaxContentBytes: 8192,
+	}
+}
+
+// デフォルトの伏せ字化設定を返す（形式の決まったトークンに加え、秘密らしい名前への代入・.env の値を伏せる）
+func DefaultRedactionConfig() RedactionConfig {
+	return RedactionConfig{
+		Level:    redact.LevelStandard,
+		Patterns: []string{},
+	}
+}
+
+// デフォルトの自動修正ループ設定を返す
+func DefaultFixLoopConfig() FixLoopConfig {
+	return FixLoopConfig{
+		Enabled:        true,
+		MaxIterations:  3,
+		RunTests:       true,
+		TimeoutSeconds: 300,
+	}
+}
+
+// デフォルトの生成コード検証設定を返す（修正の往復でリクエストが増えるため既定は無効）
+func DefaultCodeCheckConfig() CodeCheckConfig {
+	return CodeCheckConfig{
+		Enabled:        false,
+		Languages:      []string{},
+		MaxRepairs:     2,
+		TimeoutSeconds: 30,
+	}
+}
+
+// デフォルトのエージェントループ設定を返す
+func DefaultAgentLoopConfig() AgentLoopConfig {
+	return AgentLoopConfig{
+		Enabled:     true,
+		MaxSteps:    10,
+		StreamTools: true,
+	}
+}
+
+// デフォルトの実行結果の記憶の設定を返す（1セッションあたり 1MB、埋め込みは使わない）
+func DefaultRecallConfig() RecallConfig {
+	return RecallConfig{
+		Enabled:    true,
+		MaxBytes:   1 << 20,
+		MaxResults: 3,
+	}
+}
+
+// デフォルトの言語・フレームワーク別の指針の設定を返す
+func DefaultKnowledgeConfig() KnowledgeConfig {
+	return KnowledgeConfig{
+		Enabled:   true,
+		MaxGuides: 3,
+		Disabled:  []string{},
+	}
+}
+
+// デフォルトのセッションの題名の設定を返す
+func DefaultSessionTitleConfig() SessionTitleConfig {
+	return SessionTitleConfig{
+		Enabled:   true,
+		MaxLength: 60,
+	}
+}
+
+// デフォルトのターン上限を返す（通常の作業では届かず、ループした場合に止まる値）
+func DefaultTurnLimitsConfig() TurnLimitsConfig {
+	return TurnLimitsConfig{
+		MaxToolCalls: 50,
+		MaxTokens:    200000,
+		MaxSeconds:   900,
+	}
+}
+
+// デフォルトの埋め込み設定を返す（チャットと同じ Ollama で nomic-embed-text を使う）
+func DefaultEmbeddingsConfig() EmbeddingsConfig {
+	return EmbeddingsConfig{
+		Model: "nomic-embed-text",
+		API:   "auto",
+	}
+}
+
+// デフォルトのgitフック設定を返す（全てのチェックを実行し、止めるのはシークレット検出時のみ）
+func DefaultHooksConfig() HooksConfig {
+	return HooksConfig{
+		Checks: ValidHookChecks(),
+	}
+}
+
+// デフォルトのコンテキスト圧縮検証設定を返す（モデルを呼ばない事実照合）
+func DefaultContextCompressionConfig() ContextCompressionConfig {
+	return ContextCompressionConfig{
+		Validation:  "key_facts",
+		MinFidelity: 0.8,
+	}
+}
+
+// デフォルトのリトライ・フェイルオーバー設定を返す（代替エンドポイントなし）
+func DefaultResilienceConfig() ResilienceConfig {
+	return ResilienceConfig{
+		MaxRetries:       2,
+		InitialBackoffMs: 500,
+		MaxBackoffMs:     8000,
+		FailureThreshold: 3,
+		CooldownSeconds:  30,
+		Fallbacks:        []FallbackEndpoint{},
+	}
+}
+
+// デフォルトのLLM応答キャッシュ設定を返す（完全一致のみ、類似照合は無効）
+func DefaultLLMCacheConfig() LLMCacheConfig {
+	return LLMCacheConfig{
+		Enabled:             true,
+		TTLMinutes:          30,
+		MaxEntries:          200,
+		SemanticMatching:    false,
+		SimilarityThreshold: 0.97,
+	}
+}
+
+// デフォルトのWebツール設定を返す
+func DefaultWebToolsConfig() WebToolsConfig {
+	return WebToolsConfig{
+		SearchBackend:   "duckduckgo",
+		FetchMaxBytes:   2 * 1024 * 1024, // 2MB
+		CacheTTLMinutes: 15,
+	}
+}
+
+// デフォルトのネットワークポリシー設定を返す（主要なドキュメント・パッケージレジストリのみ許可）
+func DefaultNetworkPolicyConfig() NetworkPolicyConfig {
+	return NetworkPolicyConfig{
+		Mode: "allowlist",
+		AllowedDomains: []string{
+			"go.dev", "golang.org", "pkg.go.dev", "proxy.golang.org", "sum.golang.org",
+			"github.com", "raw.githubusercontent.com",
+			"docs.python.org", "pypi.org", "files.pythonhosted.org",
+			"registry.npmjs.org", "developer.mozilla.org",
+			"crates.io", "docs.rs",
+			"duckduckgo.com",
+		},
+		BlockedDomains: []string{},
+		AuditRequests:  true,
+		AllowLoopback:  false,
+	}
+}
+
+// デフォルトのサンドボックス設定を返す（ホスト直接実行）
+func DefaultSandboxConfig() SandboxConfig {
+	return SandboxConfig{
+		Mode:              "host",
+		Image:             "golang:1.22-bookworm",
+		AllowNetwork:      false,
+		ReadOnlyWorkspace: false,
+		MemoryLimit:       "1g",
+		CPULimit:          "2",
+		ExtraArgs:         []string{},
 	}
 }
 
@@ -328,33 +1018,281 @@ func Load() (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config file: %w", err)
 	}
 
+	applyDefaults(&config)
+
+	return &config, nil
+}
+
+// applyDefaults は未設定（ゼロ値）の項目にデフォルト値を補う
+func applyDefaults(cfg *Config) {
 	// 後方互換性のためのフィールド初期化
-	if config.Features == nil {
-		config.Features = &Features{
+	if cfg.Features == nil {
+		cfg.Features = &Features{
 			VibeMode:      true, // バイブコーディングモード有効
 			ProactiveMode: true, // Phase 2: プロアクティブモード有効化
 		}
 	}
 
 	// プロンプト設定の初期化
-	if config.Prompts == nil {
-		config.Prompts = DefaultPromptConfig()
+	if cfg.Prompts == nil {
+		cfg.Prompts = DefaultPromptConfig()
+	}
+
+	// サンドボックス設定の初期化
+	if cfg.Sandbox.Mode == "" {
+		cfg.Sandbox = DefaultSandboxConfig()
+	}
+
+	// ネットワークポリシー設定の初期化
+	if cfg.Network.Mode == "" {
+		cfg.Network = DefaultNetworkPolicyConfig()
+	}
+
+	// Webツール設定の初期化
+	if cfg.WebTools.SearchBackend == "" {
+		cfg.WebTools = DefaultWebToolsConfig()
+	}
+
+	// LLM応答キャッシュ設定の初期化
+	if cfg.LLMCache.MaxEntries == 0 {
+		cfg.LLMCache = DefaultLLMCacheConfig()
+	}
+
+	// リトライ・フェイルオーバー設定の初期化
+	if cfg.Resilience.FailureThreshold == 0 {
+		fallbacks := cfg.Resilience.Fallbacks
+		cfg.Resilience = DefaultResilienceConfig()
+		if fallbacks != nil {
+			cfg.Resilience.Fallbacks = fallbacks
+		}
+	}
+
+	// 同時実行数設定の初期化（未設定の項目のみ既定値）
+	concurrencyDefaults := DefaultConcurrencyConfig()
+	for _, field := range []struct {
+		value    *int
+		fallback int
+	}{
+		{&cfg.Concurrency.MaxLLMRequests, concurrencyDefaults.MaxLLMRequests},
+		{&cfg.Concurrency.MaxToolExecutions, concurrencyDefaults.MaxToolExecutions},
+		{&cfg.Concurrency.MaxAnalyses, concurrencyDefaults.MaxAnalyses},
+		{&cfg.Concurrency.MaxBackground, concurrencyDefaults.MaxBackground},
+	} {
+		if *field.value == 0 {
+			*field.value = field.fallback
+		}
+	}
+
+	// コンテキスト圧縮検証設定の初期化
+	if cfg.Compression.Validation == "" {
+		cfg.Compression = DefaultContextCompressionConfig()
+	}
+
+	// 入力行の編集キー配置の初期化
+	if cfg.TerminalMode.EditMode == "" {
+		cfg.TerminalMode.EditMode = "emacs"
+	}
+
+	// 出力の折り畳み設定の初期化
+	if cfg.TUI.FoldLines == 0 {
+		cfg.TUI.FoldLines = 30
+	}
+	if cfg.TUI.PageLines <= 0 {
+		cfg.TUI.PageLines = 200
+	}
+
+	// シンタックスハイライト配色の初期化
+	if cfg.Markdown.SyntaxTheme == "" {
+		cfg.Markdown.SyntaxTheme = "dark"
+	}
+
+	// 言語設定の初期化
+	if cfg.Language == "" {
+		cfg.Language = "ja"
+	}
+
+	// 自動修正ループ設定の初期化
+	if cfg.FixLoop.MaxIterations == 0 {
+		cfg.FixLoop = DefaultFixLoopConfig()
+	}
+
+	// 生成コード検証設定の初期化（有効時の max_repairs の 0 は修正させずに検証だけ行う）
+	if !cfg.CodeCheck.Enabled && cfg.CodeCheck.MaxRepairs == 0 {
+		cfg.CodeCheck.MaxRepairs = DefaultCodeCheckConfig().MaxRepairs
+	}
+	if cfg.CodeCheck.TimeoutSeconds == 0 {
+		cfg.CodeCheck.TimeoutSeconds = DefaultCodeCheckConfig().TimeoutSeconds
+	}
+	if cfg.CodeCheck.Languages == nil {
+		cfg.CodeCheck.Languages = []string{}
+	}
+
+	// エージェントループ設定の初期化
+	if cfg.AgentLoop.MaxSteps == 0 {
+		cfg.AgentLoop = DefaultAgentLoopConfig()
+	}
+
+	// 自動承認ポリシーの初期化（ルールなしは全ての提案を確認する）
+	if cfg.Approval.Rules == nil {
+		cfg.Approval.Rules = []ApprovalRule{}
+	}
+
+	// セッションの題名の設定の初期化
+	if cfg.SessionTitles.MaxLength <= 0 {
+		cfg.SessionTitles = DefaultSessionTitleConfig()
+	}
+
+	// 許可規則の初期化（規則なしは既定の制約のみ）
+	if cfg.Permissions.Commands.Allow == nil {
+		cfg.Permissions.Commands.Allow = []string{}
+	}
+	if cfg.Permissions.Commands.Deny == nil {
+		cfg.Permissions.Commands.Deny = []string{}
+	}
+	if cfg.Permissions.Paths.Deny == nil {
+		cfg.Permissions.Paths.Deny = []string{}
+	}
+	if cfg.Permissions.Paths.ReadOnly == nil {
+		cfg.Permissions.Paths.ReadOnly = []string{}
+	}
+
+	// ターン上限の初期化（負の値は無効化として残す）
+	defaultLimits := DefaultTurnLimitsConfig()
+	if cfg.TurnLimits.MaxToolCalls == 0 {
+		cfg.TurnLimits.MaxToolCalls = defaultLimits.MaxToolCalls
+	}
+	if cfg.TurnLimits.MaxTokens == 0 {
+		cfg.TurnLimits.MaxTokens = defaultLimits.MaxTokens
+	}
+	if cfg.TurnLimits.MaxSeconds == 0 {
+		cfg.TurnLimits.MaxSeconds = defaultLimits.MaxSeconds
+	}
+
+	// 埋め込み設定の初期化（URL・保存先は空ならチャットの設定・既定の場所を使う）
+	if cfg.Embeddings.Model == "" {
+		cfg.Embeddings.Model = DefaultEmbeddingsConfig().Model
+	}
+	if cfg.Embeddings.API == "" {
+		cfg.Embeddings.API = DefaultEmbeddingsConfig().API
+	}
+
+	// 実行結果の記憶の設定の初期化
+	if cfg.Recall.MaxBytes == 0 {
+		cfg.Recall = DefaultRecallConfig()
+	}
+	if cfg.Recall.MaxResults == 0 {
+		cfg.Recall.MaxResults = DefaultRecallConfig().MaxResults
+	}
+
+	// 言語・フレームワーク別の指針の設定の初期化
+	if cfg.Knowledge.MaxGuides == 0 {
+		cfg.Knowledge = DefaultKnowledgeConfig()
+	}
+	if cfg.Knowledge.Disabled == nil {
+		cfg.Knowledge.Disabled = []string{}
+	}
+
+	// パイプライン設定の初期化
+	if cfg.Pipeline.Stages == nil {
+		cfg.Pipeline.Stages = map[string]string{}
+	}
+
+	// 除外設定の初期化
+	if cfg.Ignore.Patterns == nil {
+		cfg.Ignore.Patterns = []string{}
+	}
+
+	// 伏せ字化設定の初期化
+	if cfg.Redaction.Level == "" {
+		cfg.Redaction.Level = redact.LevelStandard
+	}
+	if cfg.Redaction.Patterns == nil {
+		cfg.Redaction.Patterns = []string{}
+	}
+
+	// gitフック設定の初期化（空の配列はチェックなしとして残す）
+	if cfg.Hooks.Checks == nil {
+		cfg.Hooks.Checks = ValidHookChecks()
+	}
+
+	// 拡張設定の初期化
+	if cfg.Extensions.TimeoutSeconds == 0 {
+		cfg.Extensions = DefaultExtensionsConfig()
+	}
+
+	// 自動保存設定の初期化
+	if cfg.Autosave.IntervalSeconds == 0 {
+		cfg.Autosave = DefaultAutosaveConfig()
+	}
+
+	// 通知設定の初期化
+	if cfg.Notifications.ThresholdSeconds == 0 {
+		cfg.Notifications = DefaultNotificationConfig()
+	}
+
+	// 利用統計設定の初期化（有効/無効・保存先の設定は残す）
+	if cfg.Telemetry.Events == nil {
+		cfg.Telemetry.Events = ValidTelemetryEvents()
+	}
+
+	// 定期メンテナンス設定の初期化
+	if cfg.Daemon.At == "" {
+		cfg.Daemon = DefaultDaemonConfig()
+	}
+
+	// リモート開発設定の初期化（接続先の設定は残す）
+	if cfg.Remote.AgentCommand == "" {
+		cfg.Remote.AgentCommand = DefaultRemoteConfig().AgentCommand
+	}
+	if cfg.Remote.ConnectTimeoutSeconds == 0 {
+		cfg.Remote.ConnectTimeoutSeconds = DefaultRemoteConfig().ConnectTimeoutSeconds
+	}
+
+	// メトリクス・トレース出力設定の初期化（出力先の設定は残す）
+	if cfg.Observability.ServiceName == "" {
+		cfg.Observability.ServiceName = DefaultObservabilityConfig().ServiceName
+	}
+
+	// 監査ログ設定の初期化
+	if cfg.Audit.MaxContentBytes == 0 {
+		cfg.Audit = DefaultAuditConfig()
+	}
+
+	// 使用量集計設定の初期化
+	if cfg.Usage.Currency == "" {
+		cfg.Usage = DefaultUsageConfig()
+	}
+	if cfg.Usage.Pricing == nil {
+		cfg.Usage.Pricing = make(map[string]ModelPrice)
+	}
+
+	// チェックポイント設定の初期化
+	if cfg.Checkpoints.MaxPerSession == 0 {
+		cfg.Checkpoints = DefaultCheckpointConfig()
+	}
+
+	// 保存先設定の初期化（パスは空なら既定の場所を使う）
+	if cfg.Storage.Backend == "" {
+		cfg.Storage.Backend = DefaultStorageConfig().Backend
 	}
 
 	// デフォルト値の修正（0値の場合）
-	if config.Temperature == 0 {
-		config.Temperature = 0.7
+	if cfg.Temperature == 0 {
+		cfg.Temperature = 0.7
 	}
-	if config.MaxTokens == 0 {
-		config.MaxTokens = 4096
+	if cfg.MaxTokens == 0 {
+		cfg.MaxTokens = 4096
 	}
-	if config.CommandTimeout == 0 {
-		config.CommandTimeout = 60
+	if cfg.ContextWindow == 0 {
+		cfg.ContextWindow = 32768
+	}
+	if cfg.CommandTimeout == 0 {
+		cfg.CommandTimeout = 60
 	}
 
 	// プロアクティブ設定の初期化
-	if config.Proactive.Level == 0 && !config.Proactive.Enabled {
-		config.Proactive = ProactiveConfig{
+	if cfg.Proactive.Level == 0 && !cfg.Proactive.Enabled {
+		cfg.Proactive = ProactiveConfig{
 			Enabled:            true, // Phase 2: 有効化
 			Level:              ProactiveLevelMinimal,
 			AnalysisTimeout:    8, // 短縮
@@ -364,8 +1302,6 @@ func Load() (*Config, error) {
 			ProjectMonitoring:  false,
 		}
 	}
-
-	return &config, nil
 }
 
 // Save は設定をファイルに保存
@@ -410,6 +1346,44 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// ResolvedModel は実際に使うモデル名（model → model_name → DefaultModel の順）
+func (c *Config) ResolvedModel() string {
+	if c.Model != "" {
+		return c.Model
+	}
+	if c.ModelName != "" {
+		return c.ModelName
+	}
+	return DefaultModel
+}
+
+// ResolvedEmbeddingModel は機能毎の指定（override）がなければ共通の埋め込みモデルを返す
+func (c *Config) ResolvedEmbeddingModel(override string) string {
+	if override != "" {
+		return override
+	}
+	if c.Embeddings.Model != "" {
+		return c.Embeddings.Model
+	}
+	return DefaultEmbeddingsConfig().Model
+}
+
+// ResolvedEmbeddingURL は埋め込みAPIのURL（未指定ならチャットと同じ）
+func (c *Config) ResolvedEmbeddingURL() string {
+	if c.Embeddings.BaseURL != "" {
+		return c.Embeddings.BaseURL
+	}
+	return c.BaseURL
+}
+
+// ResolvedSyntaxTheme はコードブロック・差分の配色（シンタックスハイライト無効なら none）
+func (c *Config) ResolvedSyntaxTheme() string {
+	if !c.Markdown.SyntaxHighlight {
+		return "none"
+	}
+	return c.Markdown.SyntaxTheme
+}
+
 // モデルを設定して保存する
 func (c *Config) SetModel(model string) error {
 	c.Model = model // モデル名を更新
@@ -463,6 +1437,12 @@ func (c *Config) GetMCPServer(name string) (MCPServerConfig, error) {
 	return server, nil
 }
 
+// サンドボックスモードを設定して保存する
+func (c *Config) SetSandboxMode(mode string) error {
+	c.Sandbox.Mode = mode
+	return c.Save()
+}
+
 // ログレベルを設定して保存する
 func (c *Config) SetLogLevel(level string) error {
 	c.Logging.Level = level
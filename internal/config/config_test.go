@@ -85,6 +85,24 @@ func TestConfigDefault(t *testing.T) {
 	}
 }
 
+// TestResolvedSyntaxTheme はシンタックスハイライト配色の解決をテストする
+func TestResolvedSyntaxTheme(t *testing.T) {
+	config := DefaultConfig()
+	if theme := config.ResolvedSyntaxTheme(); theme != "dark" {
+		t.Errorf("デフォルトの配色は dark のはず: %s", theme)
+	}
+
+	config.Markdown.SyntaxTheme = "light"
+	if theme := config.ResolvedSyntaxTheme(); theme != "light" {
+		t.Errorf("設定した配色を使うはず: %s", theme)
+	}
+
+	config.Markdown.SyntaxHighlight = false
+	if theme := config.ResolvedSyntaxTheme(); theme != "none" {
+		t.Errorf("シンタックスハイライト無効なら none のはず: %s", theme)
+	}
+}
+
 // TestMCPServerConfig はMCPサーバー設定のテストする
 func TestMCPServerConfig(t *testing.T) {
 	tempDir := t.TempDir()
@@ -0,0 +1,643 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/codecheck"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/redact"
+	"gopkg.in/yaml.v3"
+)
+
+// 設定レイヤー名（後のレイヤーほど優先）
+const (
+	LayerDefault        = "default"         // 組み込みのデフォルト値
+	LayerGlobal         = "global"          // ~/.vyb/config.json
+	LayerGlobalProfile  = "global-profile"  // ~/.vyb/config.json の profiles.<name>
+	LayerProject        = "project"         // <project>/.vyb/config.yaml
+	LayerProjectProfile = "project-profile" // <project>/.vyb/config.yaml の profiles.<name>
+)
+
+// ProfileEnv は --profile 省略時にプロファイル名を読む環境変数
+const ProfileEnv = "VYB_PROFILE"
+
+// ProjectConfigDir はプロジェクト設定を置くディレクトリ名
+const ProjectConfigDir = ".vyb"
+
+// プロジェクト設定のファイル名（先に見つかったものを使用）
+var projectConfigNames = []string{"config.yaml", "config.yml"}
+
+// 検証結果の重大度
+const (
+	SeverityError   = "error"   // 値は無視される
+	SeverityWarning = "warning" // 動作には影響しない
+)
+
+// プロジェクト設定（リポジトリに含まれ、clone しただけで読み込まれる）からは変更できない信頼に関わるキー
+// 任意のコードの実行・伏せ字化の無効化・サンドボックスの緩和・LLM への送信先や ssh の引数の差し替えにつながるため、
+// ユーザーのグローバル設定（~/.vyb/config.json とそのプロファイル）でだけ指定できる
+var userOnlyKeys = []string{
+	"extensions", "redaction", "sandbox",
+	"provider", "base_url", "resilience.fallbacks", "embeddings.base_url",
+	"remote.host", "remote.agent_command", "remote.ssh_args",
+}
+
+// ErrProfileNotFound は指定されたプロファイルがどのレイヤーにも定義されていない
+var ErrProfileNotFound = errors.New("profile not found")
+
+// ResolveOptions は階層設定の解決条件
+type ResolveOptions struct {
+	Profile    string // 適用するプロファイル名（空なら適用しない）
+	ProjectDir string // プロジェクト設定の探索開始ディレクトリ（空ならカレントディレクトリ）
+}
+
+// Layer は設定の1レイヤー
+type Layer struct {
+	Name   string                 `json:"name"`
+	Path   string                 `json:"path,omitempty"`
+	Found  bool                   `json:"found"`
+	values map[string]interface{} // 検証済みの値
+}
+
+// Issue は設定の検証で見つかった問題
+type Issue struct {
+	Layer    string `json:"layer,omitempty"` // 空なら解決後の設定に対する問題
+	Key      string `json:"key"`
+	Message  string `json:"message"`
+	Severity string `json:"severity"`
+}
+
+// String は "layer: key: message" 形式の表現
+func (i Issue) String() string {
+	if i.Layer == "" {
+		return fmt.Sprintf("%s: %s", i.Key, i.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Layer, i.Key, i.Message)
+}
+
+// Resolved は全レイヤーをマージした実効設定
+type Resolved struct {
+	Config   *Config           `json:"config"`
+	Profile  string            `json:"profile,omitempty"`
+	Profiles []string          `json:"profiles"` // 定義済みのプロファイル名
+	Layers   []Layer           `json:"layers"`
+	Sources  map[string]Source `json:"sources"` // デフォルト以外から設定されたキー -> 設定元
+	Issues   []Issue           `json:"issues"`
+}
+
+// Source は設定値とその設定元のレイヤー
+type Source struct {
+	Layer string      `json:"layer"`
+	Value interface{} `json:"value"`
+}
+
+// HasErrors はエラーの問題があるか
+func (r *Resolved) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectProfile はフラグ指定（なければ環境変数 VYB_PROFILE）のプロファイル名
+func SelectProfile(flag string) string {
+	if flag != "" {
+		return flag
+	}
+	return strings.TrimSpace(os.Getenv(ProfileEnv))
+}
+
+// LoadResolved はデフォルト・グローバル設定・プロジェクト設定・プロファイルを順にマージする
+// マップは再帰的にマージし、スカラーと配列は後のレイヤーの値で置き換える
+// 型の合わない値はエラーとして報告し無視する（未知のキーは警告のみ）
+func LoadResolved(opts ResolveOptions) (*Resolved, error) {
+	resolved := &Resolved{Profile: opts.Profile, Sources: make(map[string]Source)}
+
+	defaults, err := toValueMap(DefaultConfig())
+	if err != nil {
+		return nil, err
+	}
+
+	// グローバル設定
+	global := Layer{Name: LayerGlobal}
+	if global.Path, err = GetConfigPath(); err != nil {
+		return nil, err
+	}
+	if data, err := os.ReadFile(global.Path); err == nil {
+		if err := json.Unmarshal(data, &global.values); err != nil {
+			return nil, fmt.Errorf("failed to parse config file: %w", err)
+		}
+		global.Found = true
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	// プロジェクト設定
+	project := Layer{Name: LayerProject}
+	if project.Path = FindProjectConfig(opts.ProjectDir); project.Path != "" {
+		data, err := os.ReadFile(project.Path)
+		if err != nil {
+			return nil, fmt.Errorf("プロジェクト設定読み込みエラー: %w", err)
+		}
+		if err := yaml.Unmarshal(data, &project.values); err != nil {
+			return nil, fmt.Errorf("プロジェクト設定解析エラー (%s): %w", project.Path, err)
+		}
+		project.Found = true
+	}
+
+	globalProfiles := extractProfiles(&global, &resolved.Issues)
+	projectProfiles := extractProfiles(&project, &resolved.Issues)
+	resolved.Profiles = profileNames(globalProfiles, projectProfiles)
+
+	// プロファイル（グローバル・プロジェクトのどちらかに定義されていれば有効）
+	globalProfile := Layer{Name: LayerGlobalProfile}
+	projectProfile := Layer{Name: LayerProjectProfile}
+	if opts.Profile != "" {
+		globalProfile.values, globalProfile.Found = globalProfiles[opts.Profile]
+		projectProfile.values, projectProfile.Found = projectProfiles[opts.Profile]
+		if !globalProfile.Found && !projectProfile.Found {
+			return nil, fmt.Errorf("%w: %s (defined: %s)", ErrProfileNotFound, opts.Profile, strings.Join(resolved.Profiles, ", "))
+		}
+		if globalProfile.Found {
+			globalProfile.Path = global.Path
+		}
+		if projectProfile.Found {
+			projectProfile.Path = project.Path
+		}
+	}
+
+	// レイヤーの検証とマージ
+	merged := make(map[string]interface{})
+	mergeValues(merged, defaults, "", "", nil)
+	resolved.Layers = []Layer{{Name: LayerDefault, Found: true, values: defaults}}
+	for _, layer := range []Layer{global, globalProfile, project, projectProfile} {
+		if layer.Found && (layer.Name == LayerProject || layer.Name == LayerProjectProfile) {
+			dropUserOnlyKeys(layer.values, layer.Name, &resolved.Issues)
+		}
+		if layer.Found {
+			checkSchema(layer.values, reflect.TypeOf(Config{}), "", layer.Name, &resolved.Issues)
+			mergeValues(merged, layer.values, "", layer.Name, resolved.Sources)
+		}
+		resolved.Layers = append(resolved.Layers, layer)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config file: %w", err)
+	}
+	applyDefaults(&cfg)
+	cfg.Profiles = nil
+
+	resolved.Config = &cfg
+	resolved.Issues = append(resolved.Issues, cfg.Validate()...)
+	return resolved, nil
+}
+
+// dropUserOnlyKeys はプロジェクトのレイヤーから userOnlyKeys を取り除き、無視したことを警告する
+func dropUserOnlyKeys(values map[string]interface{}, layer string, issues *[]Issue) {
+	for _, key := range userOnlyKeys {
+		parts := strings.Split(key, ".")
+		parent := values
+		for _, part := range parts[:len(parts)-1] {
+			child, ok := parent[part].(map[string]interface{})
+			if !ok {
+				parent = nil
+				break
+			}
+			parent = child
+		}
+		if parent == nil {
+			continue
+		}
+		if _, ok := parent[parts[len(parts)-1]]; !ok {
+			continue
+		}
+		delete(parent, parts[len(parts)-1])
+		*issues = append(*issues, Issue{Layer: layer, Key: key, Message: "cannot be set in project configuration (ignored, set it in ~/.vyb/config.json)", Severity: SeverityWarning})
+	}
+}
+
+// FindProjectConfig はディレクトリから親方向に .vyb/config.yaml を探す
+// Gitリポジトリのルートで探索を止め、ホームディレクトリの ~/.vyb は対象外
+func FindProjectConfig(startDir string) string {
+	names := make([]string, len(projectConfigNames))
+	for i, name := range projectConfigNames {
+		names[i] = filepath.Join(ProjectConfigDir, name)
+	}
+	return findInProject(startDir, names...)
+}
+
+// findInProject はディレクトリから親方向にファイルを探す（見つからなければ空文字）
+// Gitリポジトリのルートで探索を止め、ホームディレクトリはプロジェクトとして扱わない
+func findInProject(startDir string, names ...string) string {
+	dir := startDir
+	if dir == "" {
+		var err error
+		if dir, err = os.Getwd(); err != nil {
+			return ""
+		}
+	}
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	homeDir, _ := os.UserHomeDir()
+
+	for {
+		if dir != homeDir {
+			for _, name := range names {
+				path := filepath.Join(dir, name)
+				if info, err := os.Stat(path); err == nil && !info.IsDir() {
+					return path
+				}
+			}
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return ""
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// Validate は値の範囲・列挙値を検証する（型はレイヤー読み込み時に検証済み）
+func (c *Config) Validate() []Issue {
+	var issues []Issue
+	add := func(key, format string, args ...interface{}) {
+		issues = append(issues, Issue{Key: key, Message: fmt.Sprintf(format, args...), Severity: SeverityError})
+	}
+
+	if !containsString(ValidProviders(), c.Provider) {
+		add("provider", "unknown provider %q (valid: %s)", c.Provider, strings.Join(ValidProviders(), ", "))
+	}
+	if parsed, err := url.Parse(c.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		add("base_url", "must be an http(s) URL: %q", c.BaseURL)
+	}
+	if c.Temperature < 0 || c.Temperature > 2 {
+		add("temperature", "must be between 0 and 2: %g", c.Temperature)
+	}
+	for key, value := range map[string]int{
+		"max_tokens":                      c.MaxTokens,
+		"context_window":                  c.ContextWindow,
+		"command_timeout":                 c.CommandTimeout,
+		"concurrency.max_llm_requests":    c.Concurrency.MaxLLMRequests,
+		"concurrency.max_tool_executions": c.Concurrency.MaxToolExecutions,
+		"concurrency.max_analyses":        c.Concurrency.MaxAnalyses,
+		"concurrency.max_background":      c.Concurrency.MaxBackground,
+	} {
+		if value <= 0 {
+			add(key, "must be positive: %d", value)
+		}
+	}
+	if c.Timeout < 0 {
+		add("timeout", "must not be negative: %d", c.Timeout)
+	}
+	if c.Resilience.MaxRetries < 0 {
+		add("resilience.max_retries", "must not be negative: %d", c.Resilience.MaxRetries)
+	}
+	for i, fallback := range c.Resilience.Fallbacks {
+		key := fmt.Sprintf("resilience.fallbacks[%d]", i)
+		if fallback.Provider != "" && !containsString(ValidProviders(), fallback.Provider) {
+			add(key+".provider", "unknown provider %q (valid: %s)", fallback.Provider, strings.Join(ValidProviders(), ", "))
+		}
+		if fallback.BaseURL == "" && fallback.Model == "" {
+			add(key, "needs a base_url or a model")
+		} else if fallback.BaseURL != "" {
+			if parsed, err := url.Parse(fallback.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+				add(key+".base_url", "must be an http(s) URL: %q", fallback.BaseURL)
+			}
+		}
+	}
+	if !containsString(ValidCompressionValidations(), c.Compression.Validation) {
+		add("context_compression.validation", "unknown validation %q (valid: %s)", c.Compression.Validation, strings.Join(ValidCompressionValidations(), ", "))
+	}
+	if c.Compression.MinFidelity < 0 || c.Compression.MinFidelity > 1 {
+		add("context_compression.min_fidelity", "must be between 0 and 1: %g", c.Compression.MinFidelity)
+	}
+	if !containsString(i18n.ValidLanguages(), c.Language) {
+		add("language", "unknown language %q (valid: %s)", c.Language, strings.Join(i18n.ValidLanguages(), ", "))
+	}
+	if !containsString(ValidLogLevels(), c.Log.Level) {
+		add("log.level", "unknown log level %q (valid: %s)", c.Log.Level, strings.Join(ValidLogLevels(), ", "))
+	}
+	if _, err := redact.New(c.Redaction.Level, c.Redaction.Patterns); err != nil {
+		add("redaction", "%v", err)
+	}
+	for _, language := range c.CodeCheck.Languages {
+		if !containsString(codecheck.ValidLanguages(), language) {
+			add("code_check.languages", "unknown language %q (valid: %s)", language, strings.Join(codecheck.ValidLanguages(), ", "))
+		}
+	}
+	if c.CodeCheck.MaxRepairs < 0 {
+		add("code_check.max_repairs", "must not be negative: %d", c.CodeCheck.MaxRepairs)
+	}
+	if c.Recall.MaxBytes < 0 {
+		add("recall.max_bytes", "must not be negative: %d", c.Recall.MaxBytes)
+	}
+	if c.Recall.MaxResults < 0 {
+		add("recall.max_results", "must not be negative: %d", c.Recall.MaxResults)
+	}
+	if c.Knowledge.MaxGuides < 0 {
+		add("knowledge.max_guides", "must not be negative: %d", c.Knowledge.MaxGuides)
+	}
+	for _, event := range c.Telemetry.Events {
+		if !containsString(ValidTelemetryEvents(), event) {
+			add("telemetry.events", "unknown event %q (valid: %s)", event, strings.Join(ValidTelemetryEvents(), ", "))
+		}
+	}
+	for _, task := range c.Daemon.Tasks {
+		if !containsString(ValidDaemonTasks(), task) {
+			add("daemon.tasks", "unknown task %q (valid: %s)", task, strings.Join(ValidDaemonTasks(), ", "))
+		}
+	}
+	if _, err := time.Parse("15:04", c.Daemon.At); err != nil {
+		add("daemon.at", "must be a time of day (HH:MM): %q", c.Daemon.At)
+	}
+	if c.Daemon.StaleBranchDays <= 0 {
+		add("daemon.stale_branch_days", "must be positive: %d", c.Daemon.StaleBranchDays)
+	}
+	for name, token := range c.Serve.Tokens {
+		if len(token) < 16 {
+			add("serve.tokens."+name, "must be at least 16 characters")
+		}
+	}
+	_, permissionIssues := validatePermissions(c.Permissions, "")
+	for _, issue := range permissionIssues {
+		add("permissions."+issue.Key, "%s", issue.Message)
+	}
+
+	sort.Slice(issues, func(i, j int) bool { return issues[i].Key < issues[j].Key })
+	return issues
+}
+
+// ValidProviders は対応しているLLMプロバイダー
+func ValidProviders() []string {
+	return []string{"ollama", "lmstudio", "vllm"}
+}
+
+// ValidCompressionValidations はコンテキスト圧縮の検証方式
+func ValidCompressionValidations() []string {
+	return []string{"key_facts", "embedding", "llm"}
+}
+
+// ValidLogLevels は有効なログレベル
+func ValidLogLevels() []string {
+	return []string{"debug", "info", "warn", "error"}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// toValueMap は設定をJSONのキー構造を持つマップに変換
+func toValueMap(cfg *Config) (map[string]interface{}, error) {
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal config: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse config: %w", err)
+	}
+	return values, nil
+}
+
+// extractProfiles はレイヤーから profiles を取り出す（レイヤー自体の値からは除く）
+func extractProfiles(layer *Layer, issues *[]Issue) map[string]map[string]interface{} {
+	profiles := make(map[string]map[string]interface{})
+	raw, exists := layer.values["profiles"]
+	if !exists {
+		return profiles
+	}
+	delete(layer.values, "profiles")
+
+	entries, ok := raw.(map[string]interface{})
+	if !ok {
+		*issues = append(*issues, Issue{Layer: layer.Name, Key: "profiles", Message: "must be a mapping of profile names", Severity: SeverityError})
+		return profiles
+	}
+	for name, body := range entries {
+		values, ok := body.(map[string]interface{})
+		if body == nil {
+			values, ok = map[string]interface{}{}, true
+		}
+		if !ok {
+			*issues = append(*issues, Issue{Layer: layer.Name, Key: "profiles." + name, Message: "must be a mapping", Severity: SeverityError})
+			continue
+		}
+		if _, nested := values["profiles"]; nested {
+			delete(values, "profiles")
+			*issues = append(*issues, Issue{Layer: layer.Name, Key: "profiles." + name + ".profiles", Message: "profiles cannot be nested", Severity: SeverityWarning})
+		}
+		profiles[name] = values
+	}
+	return profiles
+}
+
+// profileNames は定義済みのプロファイル名（重複なし、昇順）
+func profileNames(sets ...map[string]map[string]interface{}) []string {
+	seen := make(map[string]bool)
+	names := []string{}
+	for _, set := range sets {
+		for name := range set {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// mergeValues はsrcをdstにマージする（マップは再帰、それ以外は置き換え）
+// sourcesが指定されていれば設定された末端のキーと値・レイヤー名を記録
+func mergeValues(dst, src map[string]interface{}, prefix, layer string, sources map[string]Source) {
+	for key, value := range src {
+		path := joinKey(prefix, key)
+		if srcMap, ok := value.(map[string]interface{}); ok {
+			dstMap, ok := dst[key].(map[string]interface{})
+			if !ok {
+				dstMap = make(map[string]interface{})
+				dst[key] = dstMap
+			}
+			if len(srcMap) == 0 && sources != nil {
+				sources[path] = Source{Layer: layer, Value: srcMap}
+			}
+			mergeValues(dstMap, srcMap, path, layer, sources)
+			continue
+		}
+		dst[key] = value
+		if sources != nil {
+			sources[path] = Source{Layer: layer, Value: value}
+		}
+	}
+}
+
+// checkSchema はレイヤーの値をConfigのJSONタグと照合する
+// 未知のキーは警告、型の合わない値はエラーとして報告し、どちらもレイヤーから取り除く
+func checkSchema(values map[string]interface{}, t reflect.Type, prefix, layer string, issues *[]Issue) {
+	fields := jsonFields(t)
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := joinKey(prefix, key)
+		fieldType, known := fields[key]
+		if !known {
+			*issues = append(*issues, Issue{Layer: layer, Key: path, Message: "unknown key (ignored)", Severity: SeverityWarning})
+			delete(values, key)
+			continue
+		}
+		if expected := checkValue(values[key], fieldType, path, layer, issues); expected != "" {
+			*issues = append(*issues, Issue{Layer: layer, Key: path, Message: fmt.Sprintf("expected %s, got %s (ignored)", expected, describeValue(values[key])), Severity: SeverityError})
+			delete(values, key)
+		}
+	}
+}
+
+// checkValue は値が型に合うか検証し、合わなければ期待する型の名前を返す
+func checkValue(value interface{}, t reflect.Type, path, layer string, issues *[]Issue) string {
+	if value == nil {
+		return ""
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		if _, ok := value.(string); !ok {
+			return "string"
+		}
+	case reflect.Bool:
+		if _, ok := value.(bool); !ok {
+			return "boolean"
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if number, ok := toFloat(value); !ok || number != math.Trunc(number) {
+			return "integer"
+		}
+	case reflect.Float32, reflect.Float64:
+		if _, ok := toFloat(value); !ok {
+			return "number"
+		}
+	case reflect.Slice, reflect.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			return "list"
+		}
+		for i, item := range items {
+			if expected := checkValue(item, t.Elem(), fmt.Sprintf("%s[%d]", path, i), layer, issues); expected != "" {
+				return "list of " + expected
+			}
+		}
+	case reflect.Map:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return "mapping"
+		}
+		for key, entry := range entries {
+			if expected := checkValue(entry, t.Elem(), joinKey(path, key), layer, issues); expected != "" {
+				*issues = append(*issues, Issue{Layer: layer, Key: joinKey(path, key), Message: fmt.Sprintf("expected %s, got %s (ignored)", expected, describeValue(entry)), Severity: SeverityError})
+				delete(entries, key)
+			}
+		}
+	case reflect.Struct:
+		entries, ok := value.(map[string]interface{})
+		if !ok {
+			return "mapping"
+		}
+		checkSchema(entries, t, path, layer, issues)
+	}
+	return ""
+}
+
+// jsonFields は構造体のJSONキーとフィールド型の対応
+func jsonFields(t reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" {
+			continue
+		}
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// toFloat はJSON（float64）・YAML（int等）の数値を共通に扱う
+func toFloat(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case uint64:
+		return float64(v), true
+	default:
+		return 0, false
+	}
+}
+
+// describeValue はエラーメッセージ用の値の種類
+func describeValue(value interface{}) string {
+	switch value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case []interface{}:
+		return "list"
+	case map[string]interface{}:
+		return "mapping"
+	}
+	if _, ok := toFloat(value); ok {
+		return "number"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
@@ -0,0 +1,35 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+// ProjectMemoryFile はプロジェクトの前提知識（構成・コマンド・規約）を書くファイル
+// 見つかった場合は毎ターンのプロンプトに含める
+const ProjectMemoryFile = "VYB.md"
+
+// プロンプトに含めるプロジェクトメモリの上限
+const maxProjectMemoryBytes = 16 * 1024
+
+// FindProjectMemory はディレクトリから親方向に VYB.md を探す（リポジトリのルートまで）
+func FindProjectMemory(startDir string) string {
+	return findInProject(startDir, ProjectMemoryFile)
+}
+
+// LoadProjectMemory はプロジェクトメモリの内容とパスを返す（なければ空文字）
+func LoadProjectMemory(startDir string) (string, string) {
+	path := FindProjectMemory(startDir)
+	if path == "" {
+		return "", ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", ""
+	}
+	content := string(data)
+	if len(content) > maxProjectMemoryBytes {
+		content = strings.ToValidUTF8(content[:maxProjectMemoryBytes], "") + "\n...(truncated)"
+	}
+	return strings.TrimSpace(content), path
+}
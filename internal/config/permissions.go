@@ -0,0 +1,186 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PermissionsFileName はリポジトリにコミットするツール許可規則のファイル名（.vyb/ 内）
+const PermissionsFileName = "permissions.yaml"
+
+// 許可規則の設定元
+const (
+	PermissionSourceUser    = "user"    // ~/.vyb/config.json（プロファイル・プロジェクト設定を含む）の permissions
+	PermissionSourceProject = "project" // <project>/.vyb/permissions.yaml
+)
+
+// PermissionsConfig はエージェントが実行できるコマンド・触れられるパスの規則
+// ユーザー設定と .vyb/permissions.yaml の規則は合算され、deny はどちらの allow よりも優先する
+type PermissionsConfig struct {
+	Commands CommandPermissions `json:"commands"`
+	Paths    PathPermissions    `json:"paths"`
+}
+
+// CommandPermissions はコマンドの規則（"make test" は引数付きの実行も含む、* は任意の文字列）
+type CommandPermissions struct {
+	Allow []string `json:"allow"` // 既定の許可リストにないが実行を許可するコマンド（既定の禁止コマンドは許可できない）
+	Deny  []string `json:"deny"`  // 実行を禁止するコマンド（&& や | で繋いだ各コマンドも照合）
+}
+
+// PathPermissions はファイルツールが扱うパスの規則（プロジェクトのルートからの相対、"/" を含まなければ任意の階層の名前と照合）
+type PathPermissions struct {
+	Deny     []string `json:"deny"`      // 読み書きを禁止するパス
+	ReadOnly []string `json:"read_only"` // 作成・編集・削除を禁止するパス
+}
+
+// PermissionRule は規則1件と設定元
+type PermissionRule struct {
+	Kind    string `json:"kind"` // allow_command, deny_command, deny_path, read_only_path
+	Pattern string `json:"pattern"`
+	Source  string `json:"source"`
+}
+
+// ResolvedPermissions はユーザー設定とプロジェクトのファイルを合算した規則
+type ResolvedPermissions struct {
+	Root   string            `json:"root"`           // パスの規則の基準ディレクトリ
+	Path   string            `json:"path,omitempty"` // 読み込んだ .vyb/permissions.yaml（なければ空）
+	Config PermissionsConfig `json:"config"`
+	Rules  []PermissionRule  `json:"rules"`
+	Issues []Issue           `json:"issues"` // 無効な規則は無視される
+}
+
+// HasErrors はエラーの問題があるか
+func (r *ResolvedPermissions) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// FindPermissionsFile はディレクトリから親方向に .vyb/permissions.yaml を探す
+func FindPermissionsFile(startDir string) string {
+	return findInProject(startDir, filepath.Join(ProjectConfigDir, PermissionsFileName))
+}
+
+// LoadPermissions はユーザー設定の permissions とプロジェクトの .vyb/permissions.yaml を合算する
+// ファイルの未知のキー・型の合わない値・無効なパターンは問題として報告し、その規則だけを無視する
+func LoadPermissions(user PermissionsConfig, startDir string) (*ResolvedPermissions, error) {
+	resolved := &ResolvedPermissions{}
+	if startDir == "" {
+		var err error
+		if startDir, err = os.Getwd(); err != nil {
+			return nil, fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+		}
+	}
+	root, err := filepath.Abs(startDir)
+	if err != nil {
+		return nil, err
+	}
+	resolved.Root = root
+
+	user, resolved.Issues = validatePermissions(user, PermissionSourceUser)
+	var project PermissionsConfig
+	if resolved.Path = FindPermissionsFile(startDir); resolved.Path != "" {
+		// パスの規則はファイルを置いたプロジェクトのルートを基準にする
+		resolved.Root = filepath.Dir(filepath.Dir(resolved.Path))
+
+		data, err := os.ReadFile(resolved.Path)
+		if err != nil {
+			return nil, fmt.Errorf("許可規則の読み込みエラー: %w", err)
+		}
+		var values map[string]interface{}
+		if err := yaml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("許可規則の解析エラー (%s): %w", resolved.Path, err)
+		}
+		if values != nil {
+			checkSchema(values, reflect.TypeOf(PermissionsConfig{}), "", PermissionSourceProject, &resolved.Issues)
+			encoded, err := json.Marshal(values)
+			if err != nil {
+				return nil, fmt.Errorf("許可規則の変換エラー: %w", err)
+			}
+			if err := json.Unmarshal(encoded, &project); err != nil {
+				return nil, fmt.Errorf("許可規則の解析エラー (%s): %w", resolved.Path, err)
+			}
+		}
+		var issues []Issue
+		project, issues = validatePermissions(project, PermissionSourceProject)
+		resolved.Issues = append(resolved.Issues, issues...)
+	}
+
+	merged := PermissionsConfig{
+		Commands: CommandPermissions{Allow: []string{}, Deny: []string{}},
+		Paths:    PathPermissions{Deny: []string{}, ReadOnly: []string{}},
+	}
+	for _, layer := range []struct {
+		source string
+		config PermissionsConfig
+	}{{PermissionSourceUser, user}, {PermissionSourceProject, project}} {
+		for _, set := range []struct {
+			kind     string
+			patterns []string
+			merged   *[]string
+		}{
+			{"allow_command", layer.config.Commands.Allow, &merged.Commands.Allow},
+			{"deny_command", layer.config.Commands.Deny, &merged.Commands.Deny},
+			{"deny_path", layer.config.Paths.Deny, &merged.Paths.Deny},
+			{"read_only_path", layer.config.Paths.ReadOnly, &merged.Paths.ReadOnly},
+		} {
+			for _, pattern := range set.patterns {
+				if !containsString(*set.merged, pattern) {
+					*set.merged = append(*set.merged, pattern)
+				}
+				resolved.Rules = append(resolved.Rules, PermissionRule{Kind: set.kind, Pattern: pattern, Source: layer.source})
+			}
+		}
+	}
+	resolved.Config = merged
+	return resolved, nil
+}
+
+// validatePermissions は空・無効なパターンを取り除き、問題として報告する
+func validatePermissions(permissions PermissionsConfig, layer string) (PermissionsConfig, []Issue) {
+	var issues []Issue
+	clean := func(key string, patterns []string, isPath bool) []string {
+		valid := []string{}
+		for i, pattern := range patterns {
+			pattern = strings.TrimSpace(pattern)
+			itemKey := fmt.Sprintf("%s[%d]", key, i)
+			var problem string
+			switch {
+			case pattern == "":
+				problem = "empty pattern (ignored)"
+			case isPath && strings.Contains("/"+filepath.ToSlash(pattern)+"/", "/../"):
+				problem = fmt.Sprintf("must stay inside the project: %q (ignored)", pattern)
+			default:
+				if _, err := filepath.Match(pattern, ""); err != nil {
+					problem = fmt.Sprintf("invalid pattern %q: %v (ignored)", pattern, err)
+				}
+			}
+			if problem != "" {
+				issues = append(issues, Issue{Layer: layer, Key: itemKey, Message: problem, Severity: SeverityError})
+				continue
+			}
+			valid = append(valid, pattern)
+		}
+		return valid
+	}
+
+	return PermissionsConfig{
+		Commands: CommandPermissions{
+			Allow: clean("commands.allow", permissions.Commands.Allow, false),
+			Deny:  clean("commands.deny", permissions.Commands.Deny, false),
+		},
+		Paths: PathPermissions{
+			Deny:     clean("paths.deny", permissions.Paths.Deny, true),
+			ReadOnly: clean("paths.read_only", permissions.Paths.ReadOnly, true),
+		},
+	}, issues
+}
@@ -0,0 +1,190 @@
+package conflicts
+
+import (
+	"fmt"
+	"strings"
+)
+
+// 競合マーカー（git は7文字のマーカーの後に空白とラベルを付ける）
+const (
+	markerOurs   = "<<<<<<<"
+	markerBase   = "|||||||"
+	markerSplit  = "======="
+	markerTheirs = ">>>>>>>"
+)
+
+// ContextLines は提示・プロンプトに含める競合の前後の行数
+const ContextLines = 8
+
+// Hunk はファイル内の競合箇所1つ
+type Hunk struct {
+	Index       int    `json:"index"`      // ファイル内の番号（0始まり）
+	StartLine   int    `json:"start_line"` // <<<<<<< の行（1始まり）
+	EndLine     int    `json:"end_line"`   // >>>>>>> の行
+	OursLabel   string `json:"ours_label"`
+	BaseLabel   string `json:"base_label,omitempty"`
+	TheirsLabel string `json:"theirs_label"`
+	Ours        string `json:"ours"`
+	Base        string `json:"base,omitempty"` // merge.conflictStyle=diff3/zdiff3 の共通祖先
+	HasBase     bool   `json:"has_base"`
+	Theirs      string `json:"theirs"`
+	Before      string `json:"before,omitempty"` // 競合の直前の行（ContextLines まで）
+	After       string `json:"after,omitempty"`  // 競合の直後の行
+
+	ours, base, theirs []string
+}
+
+// Raw は競合マーカーを含む競合箇所の元のテキストを返す
+func (h *Hunk) Raw() string {
+	lines := []string{joinMarker(markerOurs, h.OursLabel)}
+	lines = append(lines, h.ours...)
+	if h.HasBase {
+		lines = append(lines, joinMarker(markerBase, h.BaseLabel))
+		lines = append(lines, h.base...)
+	}
+	lines = append(lines, markerSplit)
+	lines = append(lines, h.theirs...)
+	lines = append(lines, joinMarker(markerTheirs, h.TheirsLabel))
+	return strings.Join(lines, "\n")
+}
+
+// File は競合マーカーを含むファイルの解析結果
+type File struct {
+	Path  string  `json:"path"`
+	Hunks []*Hunk `json:"hunks"`
+
+	lines           []string
+	trailingNewline bool
+}
+
+// Parse はファイルの内容から競合箇所を取り出す（閉じていないマーカーはエラー）
+func Parse(path, content string) (*File, error) {
+	file := &File{
+		Path:            path,
+		trailingNewline: strings.HasSuffix(content, "\n"),
+	}
+	if content != "" {
+		file.lines = strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	}
+
+	var hunk *Hunk
+	section := ""
+	for i, line := range file.lines {
+		if hunk == nil {
+			if label, ok := marker(line, markerOurs); ok {
+				hunk = &Hunk{Index: len(file.Hunks), StartLine: i + 1, OursLabel: label}
+				section = markerOurs
+			}
+			continue
+		}
+		if label, ok := marker(line, markerBase); ok && section == markerOurs {
+			hunk.HasBase, hunk.BaseLabel = true, label
+			section = markerBase
+			continue
+		}
+		if line == markerSplit && section != markerSplit {
+			section = markerSplit
+			continue
+		}
+		if label, ok := marker(line, markerTheirs); ok && section == markerSplit {
+			hunk.EndLine, hunk.TheirsLabel = i+1, label
+			file.finish(hunk)
+			hunk = nil
+			continue
+		}
+		switch section {
+		case markerOurs:
+			hunk.ours = append(hunk.ours, line)
+		case markerBase:
+			hunk.base = append(hunk.base, line)
+		case markerSplit:
+			hunk.theirs = append(hunk.theirs, line)
+		}
+	}
+	if hunk != nil {
+		return nil, fmt.Errorf("%s:%d の競合マーカーが閉じていません", path, hunk.StartLine)
+	}
+	return file, nil
+}
+
+// finish は競合箇所の各側と前後の行を確定して追加する
+func (f *File) finish(hunk *Hunk) {
+	hunk.Ours = strings.Join(hunk.ours, "\n")
+	hunk.Base = strings.Join(hunk.base, "\n")
+	hunk.Theirs = strings.Join(hunk.theirs, "\n")
+
+	start := hunk.StartLine - 1 - ContextLines
+	if start < 0 {
+		start = 0
+	}
+	hunk.Before = strings.Join(f.lines[start:hunk.StartLine-1], "\n")
+	end := hunk.EndLine + ContextLines
+	if end > len(f.lines) {
+		end = len(f.lines)
+	}
+	hunk.After = strings.Join(f.lines[hunk.EndLine:end], "\n")
+	f.Hunks = append(f.Hunks, hunk)
+}
+
+// Resolve は解決済みの競合箇所（番号→置き換える内容）を反映したファイルの内容を返す
+// 解決していない競合箇所はマーカーごとそのまま残す
+func (f *File) Resolve(resolutions map[int]string) string {
+	var out []string
+	next := 0
+	for _, hunk := range f.Hunks {
+		out = append(out, f.lines[next:hunk.StartLine-1]...)
+		if resolution, ok := resolutions[hunk.Index]; ok {
+			if resolution != "" {
+				out = append(out, strings.Split(resolution, "\n")...)
+			}
+		} else {
+			out = append(out, f.lines[hunk.StartLine-1:hunk.EndLine]...)
+		}
+		next = hunk.EndLine
+	}
+	out = append(out, f.lines[next:]...)
+
+	content := strings.Join(out, "\n")
+	if f.trailingNewline && len(out) > 0 {
+		content += "\n"
+	}
+	return content
+}
+
+// HasMarkers は内容に競合マーカーが残っているか
+func HasMarkers(content string) bool {
+	for _, line := range strings.Split(content, "\n") {
+		for _, prefix := range []string{markerOurs, markerBase, markerTheirs} {
+			if _, ok := marker(line, prefix); ok {
+				return true
+			}
+		}
+		if line == markerSplit {
+			return true
+		}
+	}
+	return false
+}
+
+// marker は行が指定のマーカー（7文字、後は空白とラベルのみ）かを判定してラベルを返す
+func marker(line, prefix string) (string, bool) {
+	if !strings.HasPrefix(line, prefix) {
+		return "", false
+	}
+	rest := line[len(prefix):]
+	if rest == "" {
+		return "", true
+	}
+	if rest[0] != ' ' {
+		return "", false
+	}
+	return strings.TrimSpace(rest), true
+}
+
+// joinMarker はマーカーとラベルの行を作る
+func joinMarker(prefix, label string) string {
+	if label == "" {
+		return prefix
+	}
+	return prefix + " " + label
+}
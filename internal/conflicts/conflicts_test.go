@@ -0,0 +1,225 @@
+package conflicts
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+const conflicted = `package calc
+
+<<<<<<< HEAD
+func Add(a, b int) int { return a + b }
+||||||| base
+func Add(a, b int) int { return a - b }
+=======
+func Add(a, b int) int {
+	return a - b
+}
+>>>>>>> feature
+=======
+<<<<<<< HEAD
+const Version = "1.1"
+=======
+const Version = "2.0"
+>>>>>>> feature
+`
+
+// TestParseAndResolve は diff3 形式を含む競合箇所の解析と、解決した箇所だけを置き換えることをテストする
+func TestParseAndResolve(t *testing.T) {
+	file, err := Parse("calc.go", conflicted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(file.Hunks) != 2 {
+		t.Fatalf("競合箇所は2つのはず: %d", len(file.Hunks))
+	}
+	first, second := file.Hunks[0], file.Hunks[1]
+	if first.StartLine != 3 || first.EndLine != 11 || first.OursLabel != "HEAD" || first.TheirsLabel != "feature" {
+		t.Errorf("1つ目の位置とラベル = %+v", first)
+	}
+	if !first.HasBase || first.Base != "func Add(a, b int) int { return a - b }" || first.Theirs != "func Add(a, b int) int {\n\treturn a - b\n}" {
+		t.Errorf("1つ目の各側 = %q / %q / %q", first.Ours, first.Base, first.Theirs)
+	}
+	if second.HasBase || second.Ours != `const Version = "1.1"` || !strings.HasSuffix(second.Before, ">>>>>>> feature\n=======") || second.After != "" {
+		t.Errorf("2つ目 = %+v", second)
+	}
+	if first.Raw() != strings.Join(strings.Split(conflicted, "\n")[2:11], "\n") {
+		t.Errorf("Raw はマーカーを含む元のテキストのはず:\n%s", first.Raw())
+	}
+
+	resolved := file.Resolve(map[int]string{1: `const Version = "2.0"`})
+	if !strings.HasSuffix(resolved, "=======\nconst Version = \"2.0\"\n") || !strings.Contains(resolved, "||||||| base") {
+		t.Errorf("解決した箇所だけを置き換えるはず:\n%s", resolved)
+	}
+	if !HasMarkers(resolved) || HasMarkers("a := b // <<<<<<< not a marker") {
+		t.Error("HasMarkers の判定が想定外")
+	}
+	if all := file.Resolve(map[int]string{0: first.Ours, 1: "x"}); all != "package calc\n\nfunc Add(a, b int) int { return a + b }\n=======\nx\n" {
+		t.Errorf("全て解決した内容 = %q", all)
+	}
+
+	if _, err := Parse("broken.go", "<<<<<<< HEAD\na\n=======\nb\n"); err == nil {
+		t.Error("閉じていない競合マーカーはエラーになるはず")
+	}
+	if file, _ := Parse("plain.go", "<<<<<<<< not a marker\n"); len(file.Hunks) != 0 {
+		t.Error("8文字のマーカーは競合として扱わないはず")
+	}
+}
+
+// TestParseProposal はモデルの応答から解決の内容・理由を取り出し、どちらの側かを判定することをテストする
+func TestParseProposal(t *testing.T) {
+	file, _ := Parse("calc.go", conflicted)
+	hunk := file.Hunks[1]
+
+	proposal, err := ParseProposal(hunk, "```go\nconst Version = \"2.0\"\n```\n\n**Reason:** feature bumps the major version.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proposal.Resolution != `const Version = "2.0"` || proposal.Strategy != StrategyTheirs || proposal.Reason != "feature bumps the major version." {
+		t.Errorf("提案 = %+v", proposal)
+	}
+	proposal, _ = ParseProposal(hunk, "```\nconst Version = \"1.1\"\nconst Version = \"2.0\"\n```\n理由：両方残す")
+	if proposal.Strategy != StrategyBoth || proposal.Reason != "両方残す" {
+		t.Errorf("両側を並べた提案 = %+v", proposal)
+	}
+	if StrategyOf(hunk, "const Version = \"1.1\"  \n") != StrategyOurs || StrategyOf(hunk, "const Version = \"3.0\"") != StrategyCustom {
+		t.Error("StrategyOf の判定が想定外")
+	}
+	if _, err := ParseProposal(hunk, "Take theirs."); err == nil {
+		t.Error("コードブロックのない応答はエラーになるはず")
+	}
+	if _, err := ParseProposal(hunk, "```\n<<<<<<< HEAD\nx\n```"); err == nil {
+		t.Error("マーカーが残った提案はエラーになるはず")
+	}
+}
+
+// TestUpdateMessage はコミットメッセージの解決の一覧を git のコメントの前に入れ、再実行で差し替えることをテストする
+func TestUpdateMessage(t *testing.T) {
+	journal := &Journal{}
+	journal.Add(Resolution{File: "calc.go", Line: 3, Strategy: StrategyCustom, Auto: true, Reason: "keep the fix and the format"})
+	journal.Add(Resolution{File: "calc.go", Line: 13, Strategy: StrategyOurs})
+	if journal.AutoResolved() != 1 {
+		t.Errorf("自動解決の数 = %d", journal.AutoResolved())
+	}
+
+	message := "Merge branch 'feature'\n\n# Conflicts:\n#\tcalc.go\n"
+	updated := UpdateMessage(message, journal.Summary())
+	want := "Merge branch 'feature'\n\n" + SummaryHeader + "\n" +
+		"- calc.go:3: combined both sides, auto-resolved (keep the fix and the format)\n" +
+		"- calc.go:13: kept ours\n\n# Conflicts:\n#\tcalc.go\n"
+	if updated != want {
+		t.Errorf("更新後のメッセージ:\n%s\nwant:\n%s", updated, want)
+	}
+	if again := UpdateMessage(updated, journal.Summary()); again != want {
+		t.Errorf("再実行では一覧を差し替えるはず:\n%s", again)
+	}
+	if cleared := UpdateMessage(updated, ""); cleared != message {
+		t.Errorf("空の一覧では取り除くはず:\n%q", cleared)
+	}
+}
+
+// answerProvider は固定の回答を返すテスト用プロバイダー
+type answerProvider struct {
+	llm.Provider
+	answer string
+	prompt string
+}
+
+func (p *answerProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.prompt = req.Messages[0].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}}, nil
+}
+
+// run はテスト用リポジトリで git を実行
+func run(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+	if output, err := cmd.CombinedOutput(); err != nil && args[0] != "merge" {
+		t.Fatalf("git %v エラー: %v %s", args, err, output)
+	}
+}
+
+// TestDetectAndJournal は実際のマージ競合の検出・解決依頼・記録の引き継ぎをテストする
+func TestDetectAndJournal(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git が見つかりません")
+	}
+	ctx := context.Background()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "version.go")
+	run(t, dir, "init", "-q", "-b", "main")
+	os.WriteFile(path, []byte("package app\n\nconst Version = \"1.0\"\n"), 0644)
+	run(t, dir, "add", ".")
+	run(t, dir, "commit", "-q", "-m", "init")
+	run(t, dir, "checkout", "-q", "-b", "feature")
+	os.WriteFile(path, []byte("package app\n\nconst Version = \"2.0\"\n"), 0644)
+	run(t, dir, "commit", "-q", "-am", "Bump to 2.0")
+	run(t, dir, "checkout", "-q", "main")
+	os.WriteFile(path, []byte("package app\n\nconst Version = \"1.1\"\n"), 0644)
+	run(t, dir, "commit", "-q", "-am", "Release 1.1")
+	run(t, dir, "merge", "-q", "feature")
+
+	state, err := Detect(ctx, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Operation != OperationMerge || state.Incoming != "MERGE_HEAD" || state.Commit == "" || len(state.Files) != 1 || state.Files[0] != "version.go" {
+		t.Fatalf("状態 = %+v", state)
+	}
+	if state.ContinueCommand() != "git merge --continue" {
+		t.Errorf("続けるコマンド = %s", state.ContinueCommand())
+	}
+
+	data, _ := os.ReadFile(path)
+	file, err := Parse(state.Files[0], string(data))
+	if err != nil || len(file.Hunks) != 1 {
+		t.Fatalf("競合箇所 = %v, %v", file, err)
+	}
+	provider := &answerProvider{answer: "```go\nconst Version = \"2.0\"\n```\nReason: feature supersedes the release."}
+	resolver := &Resolver{Provider: provider, Model: "test-model", Language: "en"}
+	proposal, err := resolver.Propose(ctx, state, file, file.Hunks[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"version.go", "Bump to 2.0", "Release 1.1", "the branch being merged", "<<<<<<< HEAD"} {
+		if !strings.Contains(provider.prompt, want) {
+			t.Errorf("プロンプトに %q が含まれるはず:\n%s", want, provider.prompt)
+		}
+	}
+	if proposal.Strategy != StrategyTheirs {
+		t.Errorf("提案 = %+v", proposal)
+	}
+
+	journal, err := LoadJournal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	journal.Add(Resolution{File: file.Path, Line: file.Hunks[0].StartLine, Strategy: proposal.Strategy, Auto: true, Reason: proposal.Reason})
+	if err := journal.Save(state); err != nil {
+		t.Fatal(err)
+	}
+	if reloaded, _ := LoadJournal(state); len(reloaded.Resolutions) != 1 {
+		t.Errorf("同じ操作の記録は引き継ぐはず: %+v", reloaded)
+	}
+	state.Commit = "other"
+	if reloaded, _ := LoadJournal(state); len(reloaded.Resolutions) != 0 {
+		t.Errorf("別の操作の記録は引き継がないはず: %+v", reloaded)
+	}
+
+	updated, err := state.UpdateMergeMessage(journal.Summary())
+	if err != nil || !updated {
+		t.Fatalf("MERGE_MSG を更新するはず: %v", err)
+	}
+	message, _ := os.ReadFile(filepath.Join(state.GitDir, "MERGE_MSG"))
+	if !strings.Contains(string(message), "- version.go:3: took theirs, auto-resolved") {
+		t.Errorf("MERGE_MSG:\n%s", message)
+	}
+}
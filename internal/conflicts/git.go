@@ -0,0 +1,132 @@
+package conflicts
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+)
+
+// 競合が起きた操作の種類
+const (
+	OperationMerge      = "merge"
+	OperationRebase     = "rebase"
+	OperationCherryPick = "cherry-pick"
+	OperationRevert     = "revert"
+)
+
+// State は作業ツリーの競合の状態
+type State struct {
+	Root      string   `json:"root"`      // リポジトリのルート
+	GitDir    string   `json:"git_dir"`   // .git ディレクトリ（ワークツリーでは固有のもの）
+	Operation string   `json:"operation"` // merge, rebase, cherry-pick, revert（不明なら空）
+	Incoming  string   `json:"incoming"`  // 取り込む側（theirs）の参照: MERGE_HEAD, REBASE_HEAD 等
+	Commit    string   `json:"commit"`    // 取り込む側のコミット
+	Files     []string `json:"files"`     // 競合中のファイル（ルートからの相対パス）
+}
+
+// 操作の種類と取り込む側の参照（判定する順）
+var operations = []struct {
+	name string
+	ref  string
+}{
+	{OperationRebase, "REBASE_HEAD"},
+	{OperationCherryPick, "CHERRY_PICK_HEAD"},
+	{OperationRevert, "REVERT_HEAD"},
+	{OperationMerge, "MERGE_HEAD"},
+}
+
+// Detect はリポジトリで進行中の操作と競合中のファイルを調べる
+func Detect(ctx context.Context, dir string) (*State, error) {
+	root, err := gitexec.Run(ctx, dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	gitDir, err := gitexec.Run(ctx, dir, "rev-parse", "--absolute-git-dir")
+	if err != nil {
+		return nil, err
+	}
+	state := &State{Root: strings.TrimSpace(root), GitDir: strings.TrimSpace(gitDir)}
+
+	for _, operation := range operations {
+		data, err := os.ReadFile(filepath.Join(state.GitDir, operation.ref))
+		if err != nil {
+			continue
+		}
+		state.Operation, state.Incoming = operation.name, operation.ref
+		state.Commit = strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0])
+		break
+	}
+	if state.Operation == "" && isRebasing(state.GitDir) {
+		state.Operation = OperationRebase
+	}
+
+	output, err := gitexec.Run(ctx, state.Root, "diff", "--name-only", "--diff-filter=U", "-z")
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range strings.Split(output, "\x00") {
+		if path != "" {
+			state.Files = append(state.Files, path)
+		}
+	}
+	return state, nil
+}
+
+// isRebasing はリベースの途中か（REBASE_HEAD のない古い git・apply バックエンド向け）
+func isRebasing(gitDir string) bool {
+	for _, name := range []string{"rebase-merge", "rebase-apply"} {
+		if info, err := os.Stat(filepath.Join(gitDir, name)); err == nil && info.IsDir() {
+			return true
+		}
+	}
+	return false
+}
+
+// Sides は ours・theirs がそれぞれ何を指すかの説明を返す（リベースでは逆になる点に注意）
+func (s *State) Sides() (ours, theirs string) {
+	switch s.Operation {
+	case OperationRebase:
+		return "the branch being rebased onto (upstream)", "your commit being replayed"
+	case OperationCherryPick:
+		return "the current branch (HEAD)", "the cherry-picked commit"
+	case OperationRevert:
+		return "the current branch (HEAD)", "the reverse of the reverted commit"
+	default:
+		return "the current branch (HEAD)", "the branch being merged"
+	}
+}
+
+// ContinueCommand は競合の解決後に操作を続けるコマンドを返す
+func (s *State) ContinueCommand() string {
+	if s.Operation == "" {
+		return "git commit"
+	}
+	return "git " + s.Operation + " --continue"
+}
+
+// History は競合したファイルを変更した両側の直近のコミットを返す
+func (s *State) History(ctx context.Context, path string, count int) string {
+	var sections []string
+	refs := []string{"HEAD"}
+	if s.Incoming != "" {
+		refs = append(refs, s.Incoming)
+	}
+	for _, ref := range refs {
+		output, err := gitexec.Run(ctx, s.Root, "log", fmt.Sprintf("-%d", count), "--format=%h %s", ref, "--", path)
+		if err != nil || strings.TrimSpace(output) == "" {
+			continue
+		}
+		sections = append(sections, ref+":\n"+strings.TrimRight(output, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
+
+// Stage は解決したファイルを解決済みとしてステージする
+func (s *State) Stage(ctx context.Context, path string) error {
+	_, err := gitexec.Run(ctx, s.Root, "add", "--", path)
+	return err
+}
@@ -0,0 +1,178 @@
+package conflicts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// 競合の解決方法
+const (
+	StrategyOurs   = "ours"   // ours をそのまま採用
+	StrategyTheirs = "theirs" // theirs をそのまま採用
+	StrategyBoth   = "both"   // 両側を順に並べた
+	StrategyCustom = "custom" // 両側を組み合わせて書き直した
+)
+
+// JournalFile は解決の記録を置くファイル名（.git 配下、コミットには含まれない）
+const JournalFile = "vyb-resolve.json"
+
+// SummaryHeader はコミットメッセージに加える解決の記録の見出し
+const SummaryHeader = "Conflicts resolved with vyb:"
+
+// Resolution は解決した競合1つの記録
+type Resolution struct {
+	File     string    `json:"file"`
+	Line     int       `json:"line"` // 解決前の <<<<<<< の行
+	Strategy string    `json:"strategy"`
+	Auto     bool      `json:"auto"` // モデルの提案を採用した（false なら ours・theirs を手で選んだ）
+	Reason   string    `json:"reason,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// Journal は進行中の操作で解決した競合の記録（vyb git resolve を繰り返し実行しても引き継ぐ）
+type Journal struct {
+	Operation   string       `json:"operation"`
+	Commit      string       `json:"commit"` // 取り込む側のコミット（別の操作の記録は引き継がない）
+	Resolutions []Resolution `json:"resolutions"`
+}
+
+// LoadJournal は進行中の操作の解決の記録を読み込む（なければ空の記録を返す）
+func LoadJournal(state *State) (*Journal, error) {
+	fresh := &Journal{Operation: state.Operation, Commit: state.Commit}
+	data, err := os.ReadFile(filepath.Join(state.GitDir, JournalFile))
+	if os.IsNotExist(err) {
+		return fresh, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解決の記録の読み込みエラー: %w", err)
+	}
+	var journal Journal
+	if err := json.Unmarshal(data, &journal); err != nil || journal.Operation != state.Operation || journal.Commit != state.Commit {
+		return fresh, nil
+	}
+	return &journal, nil
+}
+
+// Add は解決を記録する
+func (j *Journal) Add(resolution Resolution) {
+	if resolution.Time.IsZero() {
+		resolution.Time = time.Now()
+	}
+	j.Resolutions = append(j.Resolutions, resolution)
+}
+
+// Save は解決の記録を .git 配下に保存する
+func (j *Journal) Save(state *State) error {
+	data, err := json.MarshalIndent(j, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(state.GitDir, JournalFile), data, 0644); err != nil {
+		return fmt.Errorf("解決の記録の保存エラー: %w", err)
+	}
+	return nil
+}
+
+// AutoResolved はモデルの提案で解決した競合の数を返す
+func (j *Journal) AutoResolved() int {
+	count := 0
+	for _, resolution := range j.Resolutions {
+		if resolution.Auto {
+			count++
+		}
+	}
+	return count
+}
+
+// Summary はコミットメッセージに加える解決の一覧を返す（記録がなければ空）
+func (j *Journal) Summary() string {
+	if len(j.Resolutions) == 0 {
+		return ""
+	}
+	lines := []string{SummaryHeader}
+	for _, resolution := range j.Resolutions {
+		how := describeStrategy(resolution.Strategy)
+		if resolution.Auto {
+			how += ", auto-resolved"
+		}
+		line := fmt.Sprintf("- %s:%d: %s", resolution.File, resolution.Line, how)
+		if resolution.Reason != "" {
+			line += " (" + resolution.Reason + ")"
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeStrategy は解決方法の表示名を返す
+func describeStrategy(strategy string) string {
+	switch strategy {
+	case StrategyOurs:
+		return "kept ours"
+	case StrategyTheirs:
+		return "took theirs"
+	case StrategyBoth:
+		return "kept both sides"
+	default:
+		return "combined both sides"
+	}
+}
+
+// UpdateMessage はコミットメッセージの解決の一覧を差し替える
+// 以前の一覧は取り除き、新しい一覧は git のコメント行（# Conflicts: 等）の前に入れる
+func UpdateMessage(message, summary string) string {
+	lines := strings.Split(strings.TrimRight(message, "\n"), "\n")
+	var kept []string
+	for i := 0; i < len(lines); i++ {
+		if lines[i] != SummaryHeader {
+			kept = append(kept, lines[i])
+			continue
+		}
+		for i+1 < len(lines) && strings.HasPrefix(lines[i+1], "- ") {
+			i++
+		}
+	}
+
+	insert := len(kept)
+	for i, line := range kept {
+		if strings.HasPrefix(line, "#") {
+			insert = i
+			break
+		}
+	}
+	body := strings.TrimRight(strings.Join(kept[:insert], "\n"), "\n")
+	comments := strings.Join(kept[insert:], "\n")
+
+	var parts []string
+	if body != "" {
+		parts = append(parts, body)
+	}
+	if summary != "" {
+		parts = append(parts, summary)
+	}
+	if comments != "" {
+		parts = append(parts, comments)
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// UpdateMergeMessage は git が用意したコミットメッセージ（MERGE_MSG）に解決の一覧を書き込む
+// MERGE_MSG がなければ何もせず false を返す
+func (s *State) UpdateMergeMessage(summary string) (bool, error) {
+	path := filepath.Join(s.GitDir, "MERGE_MSG")
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	if err := os.WriteFile(path, []byte(UpdateMessage(string(data), summary)), 0644); err != nil {
+		return false, fmt.Errorf("MERGE_MSG の書き込みエラー: %w", err)
+	}
+	return true, nil
+}
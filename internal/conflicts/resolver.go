@@ -0,0 +1,149 @@
+package conflicts
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// historyCommits はプロンプトに含める両側の直近のコミット数
+const historyCommits = 3
+
+// 応答の最初のコードブロック
+var codeBlockPattern = regexp.MustCompile("(?s)```[^\\n]*\\n(.*?)```")
+
+// 理由の行の見出し
+var reasonPattern = regexp.MustCompile(`(?i)^\**\s*(reason|理由)\s*\**\s*[:：]\s*\**\s*`)
+
+// Proposal はモデルが提案した競合の解決
+type Proposal struct {
+	Resolution string `json:"resolution"` // 競合箇所（マーカーを含む）を置き換える内容
+	Strategy   string `json:"strategy"`
+	Reason     string `json:"reason"`
+}
+
+// Resolver は競合箇所ごとにモデルへ解決を依頼する
+type Resolver struct {
+	Provider llm.Provider
+	Model    string
+	Language string
+	Memory   string // プロジェクトメモリ（VYB.md）
+	Registry *prompts.Registry
+}
+
+// Prompt は競合箇所の解決を依頼するプロンプトを作る
+func (r *Resolver) Prompt(ctx context.Context, state *State, file *File, hunk *Hunk) (string, error) {
+	registry := r.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	ours, theirs := state.Sides()
+	intent := fmt.Sprintf("ours (%s) = %s; theirs (%s) = %s", labelOr(hunk.OursLabel, "HEAD"), ours, labelOr(hunk.TheirsLabel, state.Incoming), theirs)
+	if state.Operation != "" {
+		intent = state.Operation + ": " + intent
+	}
+	return registry.Render(prompts.TemplateResolve, prompts.Data{
+		Language:    r.Language,
+		ModelFamily: prompts.ModelFamily(r.Model),
+		Memory:      r.Memory,
+		CurrentFile: file.Path,
+		Intent:      intent,
+		History:     state.History(ctx, file.Path, historyCommits),
+		Context:     hunkContext(hunk),
+		Input:       hunk.Raw(),
+	})
+}
+
+// Propose はモデルに競合箇所の解決を提案させる
+func (r *Resolver) Propose(ctx context.Context, state *State, file *File, hunk *Hunk) (*Proposal, error) {
+	prompt, err := r.Prompt(ctx, state, file, hunk)
+	if err != nil {
+		return nil, err
+	}
+	temperature := 0.1
+	resp, err := r.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       r.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseProposal(hunk, resp.Message.Content)
+}
+
+// ParseProposal はモデルの応答から解決の内容（最初のコードブロック）と理由を取り出す
+func ParseProposal(hunk *Hunk, content string) (*Proposal, error) {
+	match := codeBlockPattern.FindStringSubmatchIndex(content)
+	if match == nil {
+		return nil, fmt.Errorf("モデルの応答に解決のコードブロックがありません")
+	}
+	resolution := strings.TrimSuffix(content[match[2]:match[3]], "\n")
+	if HasMarkers(resolution) {
+		return nil, fmt.Errorf("モデルの提案に競合マーカーが残っています")
+	}
+
+	reason := ""
+	for _, line := range strings.Split(content[match[1]:], "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		reason = strings.TrimSpace(strings.Trim(reasonPattern.ReplaceAllString(line, ""), "*"))
+		break
+	}
+	return &Proposal{
+		Resolution: resolution,
+		Strategy:   StrategyOf(hunk, resolution),
+		Reason:     reason,
+	}, nil
+}
+
+// StrategyOf は解決の内容が競合のどちらの側に当たるかを判定する
+func StrategyOf(hunk *Hunk, resolution string) string {
+	normalized := normalize(resolution)
+	ours, theirs := normalize(hunk.Ours), normalize(hunk.Theirs)
+	switch normalized {
+	case ours:
+		return StrategyOurs
+	case theirs:
+		return StrategyTheirs
+	case normalize(hunk.Ours + "\n" + hunk.Theirs), normalize(hunk.Theirs + "\n" + hunk.Ours):
+		return StrategyBoth
+	}
+	return StrategyCustom
+}
+
+// normalize は比較のために行末の空白と前後の空行を除く
+func normalize(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t\r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+// hunkContext は競合箇所の前後の行を、競合の位置を示して返す
+func hunkContext(hunk *Hunk) string {
+	var parts []string
+	if hunk.Before != "" {
+		parts = append(parts, hunk.Before)
+	}
+	parts = append(parts, fmt.Sprintf("[... conflict at line %d ...]", hunk.StartLine))
+	if hunk.After != "" {
+		parts = append(parts, hunk.After)
+	}
+	return strings.Join(parts, "\n")
+}
+
+// labelOr はマーカーのラベル（なければ既定の名前）を返す
+func labelOr(label, fallback string) string {
+	if label == "" {
+		return fallback
+	}
+	return label
+}
@@ -2,13 +2,17 @@ package container
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"sync"
 
 	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/core"
 	"github.com/glkt/vyb-code/internal/handlers"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/ignore"
 	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/redact"
 )
 
 // Container は依存性注入コンテナー
@@ -19,6 +23,7 @@ type Container struct {
 	logger        logger.Logger
 	factory       *handlers.HandlerFactory // ハンドラーファクトリー
 	moduleManager core.ModuleManager       // モジュールマネージャー
+	profile       string                   // 適用する設定プロファイル
 }
 
 // NewContainer は新しいコンテナーを作成
@@ -28,23 +33,53 @@ func NewContainer() *Container {
 	}
 }
 
+// WithProfile は初期化時に適用する設定プロファイルを指定
+func (c *Container) WithProfile(profile string) *Container {
+	c.profile = profile
+	return c
+}
+
 // Initialize はコンテナーを初期化
 func (c *Container) Initialize() error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	// Config を初期化
-	cfg, err := config.Load()
+	// Config を初期化（グローバル・プロジェクト設定とプロファイルをマージ）
+	var cfg *config.Config
+	var issues []config.Issue
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: c.profile})
+	if errors.Is(err, config.ErrProfileNotFound) {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
 	if err != nil {
 		// デフォルト設定を作成
 		cfg = config.DefaultConfig()
-		err = nil
-	}
-	if err != nil {
-		return fmt.Errorf("設定読み込みエラー: %w", err)
+	} else {
+		cfg = resolved.Config
+		issues = resolved.Issues
 	}
 	c.config = cfg
 
+	// 表示・応答言語を適用
+	i18n.SetLanguage(cfg.Language)
+
+	// ファイル走査の追加の除外パターンを適用
+	ignore.SetPatterns(cfg.Ignore.Patterns)
+
+	// LLMへの送信・監査ログで伏せる機密情報の範囲を適用（設定が不正なら既定の強さで伏せる）
+	if redactor, err := redact.New(cfg.Redaction.Level, cfg.Redaction.Patterns); err == nil {
+		redact.SetDefault(redactor)
+	}
+
+	// 監査ログを有効化（セッション毎に ~/.vyb/logs/<session>.jsonl へ記録）
+	if cfg.Audit.Enabled {
+		auditDir := cfg.Audit.Dir
+		if auditDir == "" {
+			auditDir = logger.DefaultAuditDir()
+		}
+		logger.SetAuditRecorder(logger.NewAuditRecorder(auditDir, cfg.Audit.MaxContentBytes))
+	}
+
 	// Logger を初期化
 	loggerConfig := logger.Config{
 		Level:     logger.ParseLevel(cfg.Log.Level),
@@ -60,8 +95,16 @@ func (c *Container) Initialize() error {
 	c.logger.Info("Container 初期化開始", map[string]interface{}{
 		"log_level":  cfg.Log.Level,
 		"log_format": cfg.Log.Format,
+		"profile":    c.profile,
 	})
 
+	// 無視された設定値を警告（詳細は vyb config doctor）
+	for _, issue := range issues {
+		if issue.Severity == config.SeverityError {
+			c.logger.Warn("設定の問題", map[string]interface{}{"issue": issue.String()})
+		}
+	}
+
 	// ハンドラーファクトリーを初期化
 	c.factory = handlers.NewHandlerFactory(c.logger, c.config)
 
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // コンテキスト監視器
@@ -225,14 +227,15 @@ func (w *Watcher) detectProjectLanguage() string {
 func (w *Watcher) countProjectFiles() int {
 	count := 0
 
-	filepath.Walk(w.workDir, func(path string, info os.FileInfo, err error) error {
+	// node_modules等や .gitignore・.vybignore で除外されたパスは ignore.Walk がスキップする
+	ignore.Walk(w.workDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil
 		}
 
-		// 隠しディレクトリやnode_modules等をスキップ
+		// 隠しディレクトリをスキップ
 		relativePath := strings.TrimPrefix(path, w.workDir)
-		if strings.Contains(relativePath, "/.") || strings.Contains(relativePath, "node_modules") {
+		if strings.Contains(relativePath, "/.") {
 			if info.IsDir() {
 				return filepath.SkipDir
 			}
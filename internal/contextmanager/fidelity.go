@@ -0,0 +1,271 @@
+package contextmanager
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// 圧縮の検証方式
+const (
+	FidelityMethodKeyFacts  = "key_facts" // 抽出した事実が要約に含まれるか照合
+	FidelityMethodEmbedding = "embedding" // 圧縮前後の埋め込みベクトルの類似度
+	FidelityMethodLLM       = "llm"       // モデルに事実が要約から読み取れるか確認させる
+)
+
+// DefaultMinFidelity はこれを下回ると失われた事実をキーポイントに補う忠実度
+const DefaultMinFidelity = 0.8
+
+// 検証で照合する事実の上限と、検証1回あたりのタイムアウト
+const (
+	maxKeyFacts     = 30
+	validateTimeout = 30 * time.Second
+)
+
+// 事実として抽出するパターン（ファイルパス・定義名・コード片・エラー行）
+var (
+	filePathPattern   = regexp.MustCompile(`[\w./-]+\.(?:go|py|js|ts|tsx|jsx|rs|java|rb|c|h|cpp|md|json|ya?ml|toml|sql|sh|mod)\b`)
+	definitionPattern = regexp.MustCompile(`\b(?:func|def|class|function|type|struct|interface)\s+(?:\([^)]*\)\s*)?([A-Za-z_]\w{2,})`)
+	backtickPattern   = regexp.MustCompile("`([^`\n]{2,60})`")
+	errorLinePattern  = regexp.MustCompile(`(?i)\b(?:error|panic|fail(?:ed)?)\b|エラー|失敗`)
+	factNumberPattern = regexp.MustCompile(`\d+`)
+)
+
+// FidelityValidator は圧縮結果が元の内容の事実を保持しているかを検証する
+type FidelityValidator interface {
+	// Method は検証方式の名前
+	Method() string
+	// Validate は忠実度（0.0-1.0）と、要約から失われた事実を返す
+	Validate(ctx context.Context, original, compressed string, facts []string) (float64, []string, error)
+}
+
+// FidelityReport は1回の圧縮の検証結果
+type FidelityReport struct {
+	SessionID    string    `json:"session_id,omitempty"`
+	Method       string    `json:"method"`
+	Ratio        float64   `json:"ratio"`    // 削減率（1 - 圧縮後/圧縮前）
+	Fidelity     float64   `json:"fidelity"` // 補う前の忠実度（0.0-1.0）
+	Facts        int       `json:"facts"`    // 照合した事実の数
+	MissingFacts []string  `json:"missing_facts,omitempty"`
+	Repaired     bool      `json:"repaired"`        // 失われた事実をキーポイントに補った
+	Error        string    `json:"error,omitempty"` // 検証器のエラー（事実照合で代替）
+	CheckedAt    time.Time `json:"checked_at"`
+}
+
+// CompressionMetrics は圧縮率・忠実度の累計
+type CompressionMetrics struct {
+	Compressions    int             `json:"compressions"`
+	OriginalBytes   int64           `json:"original_bytes"`
+	CompressedBytes int64           `json:"compressed_bytes"`
+	AverageFidelity float64         `json:"average_fidelity"`
+	MinFidelity     float64         `json:"min_fidelity"`
+	Repaired        int             `json:"repaired"`
+	Last            *FidelityReport `json:"last,omitempty"`
+}
+
+// Ratio は累計の削減率（1 - 圧縮後/圧縮前）
+func (m CompressionMetrics) Ratio() float64 {
+	if m.OriginalBytes == 0 {
+		return 0
+	}
+	return 1 - float64(m.CompressedBytes)/float64(m.OriginalBytes)
+}
+
+// record は検証結果を累計に加える
+func (m *CompressionMetrics) record(report FidelityReport, originalSize, compressedSize int) {
+	if m.Compressions == 0 || report.Fidelity < m.MinFidelity {
+		m.MinFidelity = report.Fidelity
+	}
+	m.AverageFidelity = (m.AverageFidelity*float64(m.Compressions) + report.Fidelity) / float64(m.Compressions+1)
+	m.Compressions++
+	m.OriginalBytes += int64(originalSize)
+	m.CompressedBytes += int64(compressedSize)
+	if report.Repaired {
+		m.Repaired++
+	}
+	last := report
+	m.Last = &last
+}
+
+// Text は要約とキーポイントを合わせた圧縮後の内容
+func (cc *CompressedContext) Text() string {
+	if len(cc.KeyPoints) == 0 {
+		return cc.Summary
+	}
+	return cc.Summary + "\n" + strings.Join(cc.KeyPoints, "\n")
+}
+
+// ExtractKeyFacts は圧縮後も残るべき事実（ファイルパス・定義名・コード片・エラー行）を抽出
+func ExtractKeyFacts(content string) []string {
+	var facts []string
+	seen := make(map[string]bool)
+	add := func(fact string) {
+		fact = strings.TrimSpace(fact)
+		if fact == "" || seen[strings.ToLower(fact)] || len(facts) >= maxKeyFacts {
+			return
+		}
+		seen[strings.ToLower(fact)] = true
+		facts = append(facts, fact)
+	}
+
+	for _, match := range filePathPattern.FindAllString(content, -1) {
+		add(match)
+	}
+	for _, match := range definitionPattern.FindAllStringSubmatch(content, -1) {
+		add(match[1])
+	}
+	for _, match := range backtickPattern.FindAllStringSubmatch(content, -1) {
+		add(match[1])
+	}
+	for _, line := range strings.Split(content, "\n") {
+		if errorLinePattern.MatchString(line) {
+			if runes := []rune(strings.TrimSpace(line)); len(runes) > 120 {
+				line = string(runes[:120])
+			}
+			add(line)
+		}
+	}
+	return facts
+}
+
+// missingFacts は要約に（大文字小文字を区別せず）含まれない事実
+func missingFacts(compressed string, facts []string) []string {
+	lower := strings.ToLower(compressed)
+	var missing []string
+	for _, fact := range facts {
+		if !strings.Contains(lower, strings.ToLower(fact)) {
+			missing = append(missing, fact)
+		}
+	}
+	return missing
+}
+
+// factFidelity は含まれている事実の割合（事実がなければ1.0）
+func factFidelity(facts, missing []string) float64 {
+	if len(facts) == 0 {
+		return 1.0
+	}
+	return 1 - float64(len(missing))/float64(len(facts))
+}
+
+// KeyFactValidator は抽出した事実の文字列照合で検証する（モデル呼び出しなし）
+type KeyFactValidator struct{}
+
+func (KeyFactValidator) Method() string { return FidelityMethodKeyFacts }
+
+func (KeyFactValidator) Validate(ctx context.Context, original, compressed string, facts []string) (float64, []string, error) {
+	missing := missingFacts(compressed, facts)
+	return factFidelity(facts, missing), missing, nil
+}
+
+// EmbeddingValidator は圧縮前後の埋め込みの類似度で検証する（補う事実は文字列照合で特定）
+type EmbeddingValidator struct {
+	Embedder llm.Embedder
+	Model    string
+}
+
+func (v *EmbeddingValidator) Method() string { return FidelityMethodEmbedding }
+
+func (v *EmbeddingValidator) Validate(ctx context.Context, original, compressed string, facts []string) (float64, []string, error) {
+	originalVec, err := v.Embedder.Embed(ctx, v.Model, original)
+	if err != nil {
+		return 0, nil, fmt.Errorf("埋め込み取得エラー: %w", err)
+	}
+	compressedVec, err := v.Embedder.Embed(ctx, v.Model, compressed)
+	if err != nil {
+		return 0, nil, fmt.Errorf("埋め込み取得エラー: %w", err)
+	}
+	return llm.CosineSimilarity(originalVec, compressedVec), missingFacts(compressed, facts), nil
+}
+
+// LLMValidator はモデルに各事実が要約から読み取れるかを判定させる
+type LLMValidator struct {
+	Provider llm.Provider
+	Model    string
+}
+
+func (v *LLMValidator) Method() string { return FidelityMethodLLM }
+
+func (v *LLMValidator) Validate(ctx context.Context, original, compressed string, facts []string) (float64, []string, error) {
+	if len(facts) == 0 {
+		return 1.0, nil, nil
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("Below is a summary of an earlier conversation and a numbered list of facts from the original.\n")
+	prompt.WriteString("Reply with only the numbers of the facts that can NOT be recovered from the summary, comma separated, or NONE.\n\n")
+	prompt.WriteString("Summary:\n")
+	prompt.WriteString(compressed)
+	prompt.WriteString("\n\nFacts:\n")
+	for i, fact := range facts {
+		fmt.Fprintf(&prompt, "%d. %s\n", i+1, fact)
+	}
+
+	temperature := 0.0
+	resp, err := v.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       v.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt.String()}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return 0, nil, fmt.Errorf("忠実度の確認エラー: %w", err)
+	}
+
+	missing := parseMissingFacts(resp.Message.Content, facts)
+	return factFidelity(facts, missing), missing, nil
+}
+
+// parseMissingFacts はモデルの回答から失われた事実の番号を読み取る（NONE や範囲外の番号は無視）
+func parseMissingFacts(answer string, facts []string) []string {
+	var missing []string
+	seen := make(map[int]bool)
+	for _, field := range factNumberPattern.FindAllString(answer, -1) {
+		index, err := strconv.Atoi(field)
+		if err != nil || index < 1 || index > len(facts) || seen[index] {
+			continue
+		}
+		seen[index] = true
+		missing = append(missing, facts[index-1])
+	}
+	return missing
+}
+
+// validateCompression は圧縮結果を検証し、忠実度が下限を下回れば失われた事実をキーポイントに補う
+func (scm *smartContextManager) validateCompression(original string, compressed *CompressedContext) FidelityReport {
+	facts := ExtractKeyFacts(original)
+	report := FidelityReport{
+		Method:    scm.validator.Method(),
+		Facts:     len(facts),
+		CheckedAt: time.Now(),
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+	fidelity, missing, err := scm.validator.Validate(ctx, original, compressed.Text(), facts)
+	if err != nil {
+		// 検証器が使えない場合は事実照合で代替
+		report.Error = err.Error()
+		report.Method = FidelityMethodKeyFacts
+		fidelity, missing, _ = KeyFactValidator{}.Validate(ctx, original, compressed.Text(), facts)
+	}
+	report.Fidelity = fidelity
+	report.MissingFacts = missing
+
+	if fidelity < scm.minFidelity && len(missing) > 0 {
+		compressed.KeyPoints = append(compressed.KeyPoints, missing...)
+		compressed.CompressedSize = len(compressed.Summary) + len(strings.Join(compressed.KeyPoints, ""))
+		compressed.Metadata["fidelity_repaired"] = strconv.Itoa(len(missing))
+		report.Repaired = true
+	}
+	compressed.Metadata["fidelity"] = strconv.FormatFloat(fidelity, 'f', 2, 64)
+
+	if compressed.OriginalSize > 0 {
+		report.Ratio = 1 - float64(compressed.CompressedSize)/float64(compressed.OriginalSize)
+	}
+	return report
+}
@@ -0,0 +1,165 @@
+package contextmanager
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+func TestExtractKeyFacts(t *testing.T) {
+	content := "internal/handlers/chat.go の `runInteractiveLoop` を修正\nfunc (h *ChatHandler) showInfo() {\nerror: undefined: llmEndpoints\n通常の行"
+	facts := ExtractKeyFacts(content)
+
+	for _, want := range []string{"internal/handlers/chat.go", "runInteractiveLoop", "showInfo", "error: undefined: llmEndpoints"} {
+		found := false
+		for _, fact := range facts {
+			if fact == want {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("事実 %q が抽出されていない: %v", want, facts)
+		}
+	}
+	for _, fact := range facts {
+		if fact == "通常の行" {
+			t.Errorf("事実でない行が抽出された: %v", facts)
+		}
+	}
+}
+
+func TestKeyFactValidator(t *testing.T) {
+	facts := []string{"main.go", "parseConfig", "error: timeout"}
+	fidelity, missing, err := KeyFactValidator{}.Validate(context.Background(), "", "main.go の parseConfig を変更", facts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "error: timeout" {
+		t.Errorf("失われた事実: %v", missing)
+	}
+	if fidelity < 0.66 || fidelity > 0.67 {
+		t.Errorf("忠実度: %f", fidelity)
+	}
+
+	if fidelity, _, _ := (KeyFactValidator{}).Validate(context.Background(), "", "anything", nil); fidelity != 1.0 {
+		t.Errorf("事実がない場合は1.0: %f", fidelity)
+	}
+}
+
+// answerProvider は固定の回答を返すテスト用プロバイダー
+type answerProvider struct {
+	answer string
+	prompt string
+	err    error
+}
+
+func (p *answerProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	p.prompt = req.Messages[0].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}, Done: true}, nil
+}
+
+func (p *answerProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *answerProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *answerProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+func TestLLMValidator(t *testing.T) {
+	provider := &answerProvider{answer: "2, 7"}
+	validator := &LLMValidator{Provider: provider, Model: "m"}
+	facts := []string{"a.go", "b.go", "c.go", "d.go"}
+
+	fidelity, missing, err := validator.Validate(context.Background(), "", "summary", facts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 1 || missing[0] != "b.go" || fidelity != 0.75 {
+		t.Errorf("範囲外の番号は無視するはず: fidelity=%f missing=%v", fidelity, missing)
+	}
+	if !strings.Contains(provider.prompt, "4. d.go") {
+		t.Errorf("プロンプトに番号付きの事実が含まれていない: %s", provider.prompt)
+	}
+
+	provider.answer = "NONE"
+	if fidelity, missing, _ := validator.Validate(context.Background(), "", "summary", facts); fidelity != 1.0 || len(missing) != 0 {
+		t.Errorf("NONE: fidelity=%f missing=%v", fidelity, missing)
+	}
+}
+
+// compressWithFacts は事実を含む古い短期コンテキストを追加して強制圧縮する
+func compressWithFacts(t *testing.T, manager ContextManager, sessionID string) *CompressedContext {
+	t.Helper()
+	for i := 0; i < 20; i++ {
+		content := fmt.Sprintf("step %d\nupdated pkg/file%d.go\nnotes", i, i)
+		err := manager.AddContext(&ContextItem{
+			Type:       ContextTypeShortTerm,
+			Content:    content,
+			Importance: 0.5,
+			Metadata:   map[string]string{"session_id": sessionID},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	compressed, err := manager.CompressContext(true)
+	if err != nil || compressed == nil {
+		t.Fatalf("圧縮結果: %v, %v", compressed, err)
+	}
+	return compressed
+}
+
+func TestCompressionRepairsMissingFacts(t *testing.T) {
+	manager := NewSmartContextManager()
+	compressed := compressWithFacts(t, manager, "s1")
+
+	// 要約は先頭5行のみのため、多くのファイル名が失われ、キーポイントに補われる
+	if compressed.Metadata["fidelity_repaired"] == "" {
+		t.Fatalf("失われた事実が補われていない: %+v", compressed.Metadata)
+	}
+	if !strings.Contains(compressed.Text(), "pkg/file9.go") {
+		t.Errorf("補われた事実が圧縮結果に含まれていない:\n%s", compressed.Text())
+	}
+
+	metrics := manager.CompressionMetrics("s1")
+	if metrics.Compressions != 1 || metrics.Repaired != 1 || metrics.Last == nil {
+		t.Fatalf("メトリクス: %+v", metrics)
+	}
+	if metrics.Last.Fidelity >= DefaultMinFidelity || len(metrics.Last.MissingFacts) == 0 {
+		t.Errorf("補う前の忠実度と失われた事実が記録されるはず: %+v", metrics.Last)
+	}
+	if metrics.Ratio() <= 0 || metrics.Ratio() >= 1 {
+		t.Errorf("削減率: %f", metrics.Ratio())
+	}
+	if other := manager.CompressionMetrics("s2"); other.Compressions != 0 {
+		t.Errorf("別セッションのメトリクス: %+v", other)
+	}
+	if total := manager.CompressionMetrics(""); total.Compressions != 1 {
+		t.Errorf("全体のメトリクス: %+v", total)
+	}
+
+	stats, _ := manager.GetStats()
+	if stats.AverageFidelity != metrics.AverageFidelity || stats.AchievedReduction <= 0 {
+		t.Errorf("統計に圧縮の忠実度が含まれていない: %+v", stats)
+	}
+}
+
+func TestCompressionValidatorFallback(t *testing.T) {
+	manager := NewSmartContextManager()
+	manager.SetFidelityValidator(&LLMValidator{Provider: &answerProvider{err: fmt.Errorf("connection refused")}}, 0)
+
+	compressWithFacts(t, manager, "s1")
+
+	last := manager.CompressionMetrics("s1").Last
+	if last.Method != FidelityMethodKeyFacts || !strings.Contains(last.Error, "connection refused") {
+		t.Errorf("検証器のエラー時は事実照合で代替するはず: %+v", last)
+	}
+	if last.Repaired {
+		t.Error("下限0では補わないはず")
+	}
+}
@@ -28,10 +28,16 @@ type smartContextManager struct {
 	compressionRatio   float64
 	relevanceThreshold float64
 
+	// 圧縮の検証
+	validator   FidelityValidator
+	minFidelity float64
+
 	// メトリクス
 	totalCompressed   int64
 	totalMemorySaved  int64
 	lastCompressionAt time.Time
+	compression       CompressionMetrics             // 全セッションの圧縮率・忠実度
+	sessionMetrics    map[string]*CompressionMetrics // セッション毎の圧縮率・忠実度
 }
 
 // NewSmartContextManager は新しいスマートコンテキストマネージャーを作成する
@@ -49,6 +55,10 @@ func NewSmartContextManager() ContextManager {
 		compressionRatio:   0.3, // 30%に圧縮
 		relevanceThreshold: 0.1, // 関連度10%以下は除外
 
+		validator:      KeyFactValidator{},
+		minFidelity:    DefaultMinFidelity,
+		sessionMetrics: make(map[string]*CompressionMetrics),
+
 		totalCompressed:   0,
 		totalMemorySaved:  0,
 		lastCompressionAt: time.Now(),
@@ -78,12 +88,17 @@ func (scm *smartContextManager) AddContext(item *ContextItem) error {
 	switch item.Type {
 	case ContextTypeImmediate:
 		scm.immediateContext = append(scm.immediateContext, item)
-		// 最大数を超えた場合は古いものを短期コンテキストに移動
+		// 最大数を超えた場合は固定していない最も古いものを短期コンテキストに移動
 		if len(scm.immediateContext) > scm.maxImmediateItems {
-			oldest := scm.immediateContext[0]
-			oldest.Type = ContextTypeShortTerm
-			scm.shortTermContext = append(scm.shortTermContext, oldest)
-			scm.immediateContext = scm.immediateContext[1:]
+			for i, oldest := range scm.immediateContext {
+				if oldest.Pinned {
+					continue
+				}
+				oldest.Type = ContextTypeShortTerm
+				scm.shortTermContext = append(scm.shortTermContext, oldest)
+				scm.immediateContext = append(scm.immediateContext[:i], scm.immediateContext[i+1:]...)
+				break
+			}
 		}
 
 	case ContextTypeShortTerm:
@@ -135,13 +150,18 @@ func (scm *smartContextManager) GetRelevantContext(query string, maxItems int) (
 		return allItems[i].Relevance > allItems[j].Relevance
 	})
 
-	// 閾値以上の関連度を持つ項目のみ返す
+	// 固定した項目は常に先頭に含め、残りは閾値以上の関連度を持つ項目のみ返す
 	relevantItems := make([]*ContextItem, 0)
+	for _, item := range allItems {
+		if item.Pinned {
+			relevantItems = append(relevantItems, item)
+		}
+	}
 	for _, item := range allItems {
 		if len(relevantItems) >= maxItems {
 			break
 		}
-		if item.Relevance >= scm.relevanceThreshold {
+		if !item.Pinned && item.Relevance >= scm.relevanceThreshold {
 			relevantItems = append(relevantItems, item)
 		}
 	}
@@ -180,7 +200,9 @@ func (scm *smartContextManager) compressContextInternal(forceCompress bool) (*Co
 	cutoffTime := now.Add(-2 * time.Hour) // 2時間より古いものを圧縮対象
 
 	for _, item := range scm.shortTermContext {
-		if item.Timestamp.Before(cutoffTime) {
+		if item.Pinned {
+			keepItems = append(keepItems, item) // 固定した項目は要約に置き換えない
+		} else if item.Timestamp.Before(cutoffTime) {
 			compressTargets = append(compressTargets, item)
 		} else if forceCompress && len(compressTargets) < len(scm.shortTermContext)/2 {
 			// forceCompress時でも、少なくとも半分は短期コンテキストに残す
@@ -200,11 +222,27 @@ func (scm *smartContextManager) compressContextInternal(forceCompress bool) (*Co
 		return nil, fmt.Errorf("圧縮実行エラー: %w", err)
 	}
 
+	// 要約が元の事実を保持しているか検証（不足分はキーポイントに補う）
+	var original strings.Builder
+	for _, item := range compressTargets {
+		original.WriteString(item.Content)
+		original.WriteString("\n")
+	}
+	report := scm.validateCompression(original.String(), compressed)
+	report.SessionID = compressTargets[0].Metadata["session_id"]
+	scm.compression.record(report, compressed.OriginalSize, compressed.CompressedSize)
+	sessionMetrics, ok := scm.sessionMetrics[report.SessionID]
+	if !ok {
+		sessionMetrics = &CompressionMetrics{}
+		scm.sessionMetrics[report.SessionID] = sessionMetrics
+	}
+	sessionMetrics.record(report, compressed.OriginalSize, compressed.CompressedSize)
+
 	// 圧縮結果を中期コンテキストに保存
 	compressedItem := &ContextItem{
 		ID:          fmt.Sprintf("compressed_%d", now.UnixNano()),
 		Type:        ContextTypeMediumTerm,
-		Content:     compressed.Summary,
+		Content:     compressed.Text(),
 		Metadata:    map[string]string{"type": "compressed_context", "original_items": fmt.Sprintf("%d", len(compressTargets))},
 		Timestamp:   now,
 		Relevance:   0.0, // 後で計算
@@ -295,6 +333,32 @@ func (scm *smartContextManager) performCompression(items []*ContextItem) (*Compr
 	return compressed, nil
 }
 
+// SetFidelityValidator は圧縮結果の検証方式と、失われた事実を補う忠実度の下限を設定（nilで事実照合）
+func (scm *smartContextManager) SetFidelityValidator(validator FidelityValidator, minFidelity float64) {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+
+	if validator == nil {
+		validator = KeyFactValidator{}
+	}
+	scm.validator = validator
+	scm.minFidelity = minFidelity
+}
+
+// CompressionMetrics はセッションの圧縮率・忠実度の累計（空文字列なら全セッション）
+func (scm *smartContextManager) CompressionMetrics(sessionID string) CompressionMetrics {
+	scm.mu.RLock()
+	defer scm.mu.RUnlock()
+
+	if sessionID == "" {
+		return scm.compression
+	}
+	if metrics, ok := scm.sessionMetrics[sessionID]; ok {
+		return *metrics
+	}
+	return CompressionMetrics{}
+}
+
 // CalculateRelevance は関連度を計算する
 func (scm *smartContextManager) CalculateRelevance(item *ContextItem, query string) float64 {
 	return scm.calculateRelevance(item, query)
@@ -553,9 +617,69 @@ func (scm *smartContextManager) GetStats() (*ContextStats, error) {
 		LastCompressionAt:  scm.lastCompressionAt,
 		AverageRelevance:   averageRelevance,
 		CompressionHistory: len(scm.compressionHistory),
+		AchievedReduction:  scm.compression.Ratio(),
+		AverageFidelity:    scm.compression.AverageFidelity,
 	}, nil
 }
 
+// Items は保持している全項目のコピーを重要度の高い順に返す（アクセス回数は更新しない）
+func (scm *smartContextManager) Items() []ContextItem {
+	scm.mu.RLock()
+	defer scm.mu.RUnlock()
+
+	items := make([]ContextItem, 0, len(scm.immediateContext)+len(scm.shortTermContext)+len(scm.mediumTermContext)+len(scm.longTermContext))
+	for _, context := range [][]*ContextItem{scm.immediateContext, scm.shortTermContext, scm.mediumTermContext, scm.longTermContext} {
+		for _, item := range context {
+			items = append(items, *item)
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].Importance > items[j].Importance
+	})
+	return items
+}
+
+// RemoveSince は指定時刻以降に追加された項目を全階層から削除し、削除数を返す
+func (scm *smartContextManager) RemoveSince(since time.Time) int {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+
+	removed := 0
+	filter := func(items []*ContextItem) []*ContextItem {
+		kept := items[:0]
+		for _, item := range items {
+			if item.Timestamp.Before(since) {
+				kept = append(kept, item)
+			} else {
+				removed++
+			}
+		}
+		return kept
+	}
+	scm.immediateContext = filter(scm.immediateContext)
+	scm.shortTermContext = filter(scm.shortTermContext)
+	scm.mediumTermContext = filter(scm.mediumTermContext)
+	scm.longTermContext = filter(scm.longTermContext)
+	return removed
+}
+
+// SetPinned は項目を固定・解除する（固定した項目は圧縮・階層の移動で除かず、常に取得結果に含める）
+func (scm *smartContextManager) SetPinned(id string, pinned bool) error {
+	scm.mu.Lock()
+	defer scm.mu.Unlock()
+
+	for _, context := range [][]*ContextItem{scm.immediateContext, scm.shortTermContext, scm.mediumTermContext, scm.longTermContext} {
+		for _, item := range context {
+			if item.ID == id {
+				item.Pinned = pinned
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("コンテキスト項目 %s が見つかりません", id)
+}
+
 // ClearContext は指定したタイプのコンテキストをクリアする
 func (scm *smartContextManager) ClearContext(contextType ContextType) error {
 	scm.mu.Lock()
@@ -253,6 +253,75 @@ func TestTimeBasedCompression(t *testing.T) {
 	}
 }
 
+// TestPinnedContextIsNeverEvicted は固定した項目が圧縮・階層の移動で除かれず、常に取得されることをテスト
+func TestPinnedContextIsNeverEvicted(t *testing.T) {
+	manager := NewSmartContextManager()
+
+	decision := &ContextItem{ID: "decision", Type: ContextTypeShortTerm, Content: "設計判断: 永続化は SQLite のみを使う"}
+	if err := manager.AddContext(decision); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetPinned("decision", true); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.SetPinned("missing", true); err == nil {
+		t.Error("存在しない項目の固定はエラーになるべきです")
+	}
+	for _, item := range createTestContextItems(10) {
+		if err := manager.AddContext(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// 強制圧縮は短期コンテキストの先頭から要約するが、固定した項目は残る
+	if _, err := manager.CompressContext(true); err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, item := range manager.Items() {
+		if item.ID == "decision" {
+			found = item.Pinned && item.Type == ContextTypeShortTerm
+		}
+	}
+	if !found {
+		t.Fatal("固定した項目が圧縮で除かれました")
+	}
+
+	// 即座のコンテキストの上限を超えても、固定した項目は短期コンテキストに移らない
+	constraint := &ContextItem{ID: "constraint", Type: ContextTypeImmediate, Content: "制約: 公開APIの互換性を保つ", Pinned: true}
+	if err := manager.AddContext(constraint); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 60; i++ {
+		if err := manager.AddContext(&ContextItem{Type: ContextTypeImmediate, Content: fmt.Sprintf("編集中の関数 %d", i)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if constraint.Type != ContextTypeImmediate {
+		t.Errorf("固定した項目が移動しました: %v", constraint.Type)
+	}
+
+	// 関連度が低くても、件数の上限を超えても固定した項目は取得される
+	relevant, err := manager.GetRelevantContext("無関係な問い合わせ", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, item := range relevant {
+		ids = append(ids, item.ID)
+	}
+	if len(relevant) != 2 || !contains(ids, "decision") || !contains(ids, "constraint") {
+		t.Errorf("固定した項目が取得されませんでした: %v", ids)
+	}
+
+	if err := manager.SetPinned("decision", false); err != nil {
+		t.Fatal(err)
+	}
+	if relevant, _ := manager.GetRelevantContext("無関係な問い合わせ", 1); len(relevant) != 1 || relevant[0].ID != "constraint" {
+		t.Errorf("固定を解除した項目は通常の取得に戻るべきです: %d items", len(relevant))
+	}
+}
+
 // TestConcurrentAccess は並行アクセスをテスト
 func TestConcurrentAccess(t *testing.T) {
 	manager := NewSmartContextManager()
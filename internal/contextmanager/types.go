@@ -31,6 +31,7 @@ type ContextItem struct {
 	Importance  float64           `json:"importance"`   // 0.0-1.0の重要度スコア
 	AccessCount int               `json:"access_count"` // アクセス回数
 	LastAccess  time.Time         `json:"last_access"`
+	Pinned      bool              `json:"pinned,omitempty"` // 固定した項目は圧縮・階層の移動で除かず、常に取得結果に含める
 }
 
 // 圧縮されたコンテキスト
@@ -88,8 +89,23 @@ type ContextManager interface {
 	// 統計情報の取得
 	GetStats() (*ContextStats, error)
 
+	// 保持している全項目のスナップショット（重要度順）
+	Items() []ContextItem
+
+	// 指定時刻以降に追加された項目の削除（巻き戻し用）
+	RemoveSince(since time.Time) int
+
+	// 項目の固定・解除（固定した項目は圧縮・退避の対象にしない）
+	SetPinned(id string, pinned bool) error
+
 	// コンテキストのクリア
 	ClearContext(contextType ContextType) error
+
+	// 圧縮結果の検証方式の設定
+	SetFidelityValidator(validator FidelityValidator, minFidelity float64)
+
+	// 圧縮率・忠実度の累計（セッション毎、空文字列なら全体）
+	CompressionMetrics(sessionID string) CompressionMetrics
 }
 
 // コンテキスト統計
@@ -104,6 +120,8 @@ type ContextStats struct {
 	LastCompressionAt  time.Time `json:"last_compression_at"`
 	AverageRelevance   float64   `json:"average_relevance"`
 	CompressionHistory int       `json:"compression_history"`
+	AchievedReduction  float64   `json:"achieved_reduction"` // 実際の削減率（1 - 圧縮後/圧縮前）
+	AverageFidelity    float64   `json:"average_fidelity"`   // 検証した忠実度の平均
 }
 
 // 会話メッセージの拡張（コンテキスト対応）
@@ -12,6 +12,7 @@ import (
 	"github.com/glkt/vyb-code/internal/analysis"
 	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/reasoning"
+	"github.com/glkt/vyb-code/internal/security"
 )
 
 // CognitiveExecutionEngine は真のClaude Codeレベル思考を実現する実行エンジン
@@ -299,7 +300,7 @@ func (cee *CognitiveExecutionEngine) executeDirectCommand(
 	}
 
 	// コンテキスト認識実行
-	result, err := cee.runCommandWithContext(command, strategy)
+	result, err := cee.runCommandWithContext(ctx, command, strategy)
 	if err != nil {
 		return nil, fmt.Errorf("コマンド実行エラー: %w", err)
 	}
@@ -795,21 +796,19 @@ func (cee *CognitiveExecutionEngine) extractOptimalCommand(result *reasoning.Rea
 }
 
 func (cee *CognitiveExecutionEngine) isCommandSafe(command string) bool {
-	dangerousCommands := []string{"rm -rf", "dd if=", "mkfs", "> /dev"}
-	for _, dangerous := range dangerousCommands {
-		if strings.Contains(command, dangerous) {
-			return false
-		}
-	}
-	return true
+	return security.NewCommandPolicy(cee.projectPath, nil).Evaluate(command) == nil
 }
 
-func (cee *CognitiveExecutionEngine) runCommandWithContext(command string, strategy *DynamicExecutionStrategy) (*ExecutionResult, error) {
+func (cee *CognitiveExecutionEngine) runCommandWithContext(ctx context.Context, command string, strategy *DynamicExecutionStrategy) (*ExecutionResult, error) {
 	start := time.Now()
-	cmd := exec.Command("sh", "-c", command)
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
 	cmd.Dir = cee.projectPath
+	cmd.WaitDelay = time.Second
 
 	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("コマンド実行中断: %w", ctx.Err())
+	}
 
 	result := &ExecutionResult{
 		Command:   command,
@@ -844,8 +843,12 @@ func (cee *CognitiveExecutionEngine) groupParallelizableSteps(steps []*WorkflowS
 func (cee *CognitiveExecutionEngine) executeStepGroupInParallel(ctx context.Context, group []*WorkflowStep, strategy *DynamicExecutionStrategy) ([]*ExecutionResult, error) {
 	var results []*ExecutionResult
 	for _, step := range group {
-		result, err := cee.runCommandWithContext(step.Command, strategy)
+		result, err := cee.runCommandWithContext(ctx, step.Command, strategy)
 		if err != nil {
+			// 中断時は残りのステップを実行しない
+			if ctx.Err() != nil {
+				return results, err
+			}
 			continue
 		}
 		results = append(results, result)
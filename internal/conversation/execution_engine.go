@@ -1,12 +1,16 @@
 package conversation
 
 import (
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
 	"time"
 
 	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
 )
 
 // コマンド実行結果のキャッシュエントリ
@@ -18,13 +22,14 @@ type CacheEntry struct {
 
 // 実行型応答エンジン - コマンド実行とマルチツール連携
 type ExecutionEngine struct {
-	config          *config.Config
-	projectPath     string
-	enabled         bool
-	allowedCommands map[string]bool
-	safetyLimits    *SafetyLimits
-	cache           map[string]*CacheEntry // パフォーマンス最適化用キャッシュ
-	lastUserInput   string                 // マルチツールワークフロー用ユーザー入力保持
+	config        *config.Config
+	projectPath   string
+	enabled       bool
+	policy        *security.CommandPolicy // コマンド実行ポリシー
+	safetyLimits  *SafetyLimits
+	cache         map[string]*CacheEntry // パフォーマンス最適化用キャッシュ
+	lastUserInput string                 // マルチツールワークフロー用ユーザー入力保持
+	backend       sandbox.Backend        // コマンド実行バックエンド
 }
 
 // 安全性制限
@@ -60,27 +65,15 @@ type ContextAnalysis struct {
 
 // 新しい実行エンジンを作成
 func NewExecutionEngine(cfg *config.Config, projectPath string) *ExecutionEngine {
+	// 設定に応じた実行バックエンド（不正なモード指定時はnilとなり実行を拒否）
+	backend, _ := sandbox.New(cfg.Sandbox)
+
 	return &ExecutionEngine{
 		config:      cfg,
 		projectPath: projectPath,
 		enabled:     cfg.IsProactiveEnabled(),
-		allowedCommands: map[string]bool{
-			"git":    true,
-			"ls":     true,
-			"pwd":    true,
-			"find":   true,
-			"grep":   true,
-			"cat":    true,
-			"head":   true,
-			"tail":   true,
-			"wc":     true,
-			"stat":   true,
-			"file":   true,
-			"go":     true,
-			"npm":    true,
-			"python": true,
-			"node":   true,
-		},
+		policy:      security.NewCommandPolicy(projectPath, nil),
+
 		safetyLimits: &SafetyLimits{
 			MaxExecutionTime: 30 * time.Second,
 			AllowedPaths:     []string{projectPath},
@@ -88,7 +81,8 @@ func NewExecutionEngine(cfg *config.Config, projectPath string) *ExecutionEngine
 			MaxOutputSize:    10 * 1024, // 10KB
 			ReadOnlyMode:     true,      // デフォルトは読み取り専用
 		},
-		cache: make(map[string]*CacheEntry), // キャッシュ初期化
+		cache:   make(map[string]*CacheEntry), // キャッシュ初期化
+		backend: backend,
 	}
 }
 
@@ -369,16 +363,16 @@ func (ee *ExecutionEngine) isLearningAssistance(input string) bool {
 }
 
 // 複数ツール連携実行システム
-func (ee *ExecutionEngine) executeMultiToolWorkflow(intent string, inputLower, originalInput string) (*MultiToolResult, error) {
+func (ee *ExecutionEngine) executeMultiToolWorkflow(ctx context.Context, intent string, inputLower, originalInput string) (*MultiToolResult, error) {
 	switch intent {
 	case "project_understanding":
-		return ee.projectUnderstandingWorkflow(inputLower, originalInput)
+		return ee.projectUnderstandingWorkflow(ctx, inputLower, originalInput)
 	case "multi_file_investigation":
-		return ee.multiFileInvestigationWorkflow(inputLower, originalInput)
+		return ee.multiFileInvestigationWorkflow(ctx, inputLower, originalInput)
 	case "problem_solving":
-		return ee.problemSolvingWorkflow(inputLower, originalInput)
+		return ee.problemSolvingWorkflow(ctx, inputLower, originalInput)
 	case "learning_assistance":
-		return ee.learningAssistanceWorkflow(inputLower, originalInput)
+		return ee.learningAssistanceWorkflow(ctx, inputLower, originalInput)
 	}
 
 	return nil, fmt.Errorf("未知のワークフロー: %s", intent)
@@ -400,26 +394,26 @@ type ToolStep struct {
 }
 
 // プロジェクト理解ワークフロー
-func (ee *ExecutionEngine) projectUnderstandingWorkflow(inputLower, originalInput string) (*MultiToolResult, error) {
+func (ee *ExecutionEngine) projectUnderstandingWorkflow(ctx context.Context, inputLower, originalInput string) (*MultiToolResult, error) {
 	start := time.Now()
 	result := &MultiToolResult{
 		Steps: make([]ToolStep, 0),
 	}
 
 	// Step 1: プロジェクト構造の把握
-	step1 := ee.executeToolStep("ls", "ls -la")
+	step1 := ee.executeTreeStep(ctx, 2)
 	result.Steps = append(result.Steps, step1)
 
 	// Step 2: 重要ファイルの確認
-	step2 := ee.executeToolStep("cat", "cat README.md")
+	step2 := ee.executeToolStep(ctx, "cat", "cat README.md")
 	result.Steps = append(result.Steps, step2)
 
 	// Step 3: 依存関係の確認 (Go プロジェクトの場合)
-	step3 := ee.executeToolStep("cat", "cat go.mod")
+	step3 := ee.executeToolStep(ctx, "cat", "cat go.mod")
 	result.Steps = append(result.Steps, step3)
 
 	// Step 4: コードファイルの概要
-	step4 := ee.executeToolStep("find", "find . -name '*.go' | head -10")
+	step4 := ee.executeToolStep(ctx, "find", "find . -name '*.go' | head -10")
 	result.Steps = append(result.Steps, step4)
 
 	result.Duration = time.Since(start)
@@ -429,7 +423,7 @@ func (ee *ExecutionEngine) projectUnderstandingWorkflow(inputLower, originalInpu
 }
 
 // 複数ファイル調査ワークフロー
-func (ee *ExecutionEngine) multiFileInvestigationWorkflow(inputLower, originalInput string) (*MultiToolResult, error) {
+func (ee *ExecutionEngine) multiFileInvestigationWorkflow(ctx context.Context, inputLower, originalInput string) (*MultiToolResult, error) {
 	start := time.Now()
 	result := &MultiToolResult{
 		Steps: make([]ToolStep, 0),
@@ -440,11 +434,11 @@ func (ee *ExecutionEngine) multiFileInvestigationWorkflow(inputLower, originalIn
 
 	if searchTarget != "" {
 		// Step 1: 検索実行
-		step1 := ee.executeToolStep("grep", fmt.Sprintf("grep -r %s . --exclude-dir=.git", searchTarget))
+		step1 := ee.executeToolStep(ctx, "grep", fmt.Sprintf("grep -r %s . --exclude-dir=.git", searchTarget))
 		result.Steps = append(result.Steps, step1)
 
 		// Step 2: ファイル一覧取得
-		step2 := ee.executeToolStep("grep", fmt.Sprintf("grep -l %s $(find . -name '*.go' -not -path './.git/*')", searchTarget))
+		step2 := ee.executeToolStep(ctx, "grep", fmt.Sprintf("grep -rl %s . --include='*.go' --exclude-dir=.git", searchTarget))
 		result.Steps = append(result.Steps, step2)
 	}
 
@@ -455,22 +449,22 @@ func (ee *ExecutionEngine) multiFileInvestigationWorkflow(inputLower, originalIn
 }
 
 // 問題解決ワークフロー
-func (ee *ExecutionEngine) problemSolvingWorkflow(inputLower, originalInput string) (*MultiToolResult, error) {
+func (ee *ExecutionEngine) problemSolvingWorkflow(ctx context.Context, inputLower, originalInput string) (*MultiToolResult, error) {
 	start := time.Now()
 	result := &MultiToolResult{
 		Steps: make([]ToolStep, 0),
 	}
 
 	// Step 1: 現在の状況確認
-	step1 := ee.executeToolStep("git", "git status")
+	step1 := ee.executeToolStep(ctx, "git", "git status")
 	result.Steps = append(result.Steps, step1)
 
 	// Step 2: 最近の変更確認
-	step2 := ee.executeToolStep("git", "git log --oneline -5")
+	step2 := ee.executeToolStep(ctx, "git", "git log --oneline -5")
 	result.Steps = append(result.Steps, step2)
 
 	// Step 3: ビルド状況確認（Go プロジェクトの場合）
-	step3 := ee.executeToolStep("go", "go build -n ./...")
+	step3 := ee.executeToolStep(ctx, "go", "go build -n ./...")
 	result.Steps = append(result.Steps, step3)
 
 	result.Duration = time.Since(start)
@@ -480,7 +474,7 @@ func (ee *ExecutionEngine) problemSolvingWorkflow(inputLower, originalInput stri
 }
 
 // 学習支援ワークフロー
-func (ee *ExecutionEngine) learningAssistanceWorkflow(inputLower, originalInput string) (*MultiToolResult, error) {
+func (ee *ExecutionEngine) learningAssistanceWorkflow(ctx context.Context, inputLower, originalInput string) (*MultiToolResult, error) {
 	start := time.Now()
 	result := &MultiToolResult{
 		Steps: make([]ToolStep, 0),
@@ -501,57 +495,57 @@ func (ee *ExecutionEngine) learningAssistanceWorkflow(inputLower, originalInput
 	case "file_specific":
 		// 特定ファイルについての説明
 		if strings.HasSuffix(topic, ".md") || strings.HasSuffix(topic, ".go") {
-			step1 := ee.executeToolStep("cat", fmt.Sprintf("cat %s", topic))
+			step1 := ee.executeToolStep(ctx, "cat", fmt.Sprintf("cat %s", topic))
 			result.Steps = append(result.Steps, step1)
 		}
 
 	case "concept_explanation":
 		// 概念説明：関連ファイル検索 + 定義検索
-		step1 := ee.executeToolStep("grep", ee.buildSafeGrepCommand(topic, "definition"))
+		step1 := ee.executeToolStep(ctx, "grep", ee.buildSafeGrepCommand(topic, "definition"))
 		result.Steps = append(result.Steps, step1)
 
-		step2 := ee.executeToolStep("find", fmt.Sprintf("find . -name '*.go' -type f | head -10"))
+		step2 := ee.executeToolStep(ctx, "find", fmt.Sprintf("find . -name '*.go' -type f | head -10"))
 		result.Steps = append(result.Steps, step2)
 
-		step3 := ee.executeToolStep("grep", ee.buildSafeGrepCommand(topic, "usage"))
+		step3 := ee.executeToolStep(ctx, "grep", ee.buildSafeGrepCommand(topic, "usage"))
 		result.Steps = append(result.Steps, step3)
 
 	case "code_analysis":
 		// コード分析：構造体/関数/型の検索
-		step1 := ee.executeToolStep("grep", ee.buildSafeGrepCommand(topic, "struct_func"))
+		step1 := ee.executeToolStep(ctx, "grep", ee.buildSafeGrepCommand(topic, "struct_func"))
 		result.Steps = append(result.Steps, step1)
 
-		step2 := ee.executeToolStep("grep", ee.buildSafeGrepCommand(topic, "type_def"))
+		step2 := ee.executeToolStep(ctx, "grep", ee.buildSafeGrepCommand(topic, "type_def"))
 		result.Steps = append(result.Steps, step2)
 
-		step3 := ee.executeToolStep("find", "find . -name '*.go' -exec grep -l 'func.*' {} \\; | head -5")
+		step3 := ee.executeToolStep(ctx, "find", "find . -name '*.go' -exec grep -l 'func.*' {} \\; | head -5")
 		result.Steps = append(result.Steps, step3)
 
 	case "architecture_understanding":
 		// アーキテクチャ理解：プロジェクト構造分析
-		step1 := ee.executeToolStep("find", "find . -type d -name 'internal' -o -name 'cmd' -o -name 'pkg'")
+		step1 := ee.executeTreeStep(ctx, 2)
 		result.Steps = append(result.Steps, step1)
 
-		step2 := ee.executeToolStep("cat", "cat go.mod")
+		step2 := ee.executeToolStep(ctx, "cat", "cat go.mod")
 		result.Steps = append(result.Steps, step2)
 
-		step3 := ee.executeToolStep("find", "find . -name '*.go' | head -10")
+		step3 := ee.executeToolStep(ctx, "find", "find . -name '*.go' | head -10")
 		result.Steps = append(result.Steps, step3)
 
-		step4 := ee.executeToolStep("cat", "cat CLAUDE.md")
+		step4 := ee.executeToolStep(ctx, "cat", "cat CLAUDE.md")
 		result.Steps = append(result.Steps, step4)
 
 	default:
 		// 汎用学習支援
-		step1 := ee.executeToolStep("find", "find . -name 'README.md' -o -name 'CLAUDE.md'")
+		step1 := ee.executeToolStep(ctx, "find", "find . -name 'README.md' -o -name 'CLAUDE.md'")
 		result.Steps = append(result.Steps, step1)
 
 		if topic != "general" {
-			step2 := ee.executeToolStep("grep", ee.buildSafeGrepCommand(topic, "general"))
+			step2 := ee.executeToolStep(ctx, "grep", ee.buildSafeGrepCommand(topic, "general"))
 			result.Steps = append(result.Steps, step2)
 		}
 
-		step3 := ee.executeToolStep("ls", "ls -la")
+		step3 := ee.executeTreeStep(ctx, 1)
 		result.Steps = append(result.Steps, step3)
 	}
 
@@ -562,9 +556,9 @@ func (ee *ExecutionEngine) learningAssistanceWorkflow(inputLower, originalInput
 }
 
 // ツール実行ステップ
-func (ee *ExecutionEngine) executeToolStep(tool, command string) ToolStep {
+func (ee *ExecutionEngine) executeToolStep(ctx context.Context, tool, command string) ToolStep {
 	start := time.Now()
-	result, err := ee.ExecuteCommand(command)
+	result, err := ee.ExecuteCommandContext(ctx, command)
 
 	step := ToolStep{
 		Tool:     tool,
@@ -582,6 +576,27 @@ func (ee *ExecutionEngine) executeToolStep(tool, command string) ToolStep {
 	return step
 }
 
+// executeTreeStep はプロジェクトのディレクトリツリー（除外パターン適用済み）をステップとして取得
+func (ee *ExecutionEngine) executeTreeStep(ctx context.Context, depth int) ToolStep {
+	start := time.Now()
+	step := ToolStep{Tool: "tree", Command: fmt.Sprintf("tree depth=%d", depth)}
+	if err := ctx.Err(); err != nil {
+		step.Output = err.Error()
+		step.Duration = time.Since(start)
+		return step
+	}
+
+	tree, err := tools.BuildTree(ee.projectPath, tools.TreeOptions{Depth: depth})
+	step.Duration = time.Since(start)
+	if err != nil {
+		step.Output = err.Error()
+		return step
+	}
+	step.Success = true
+	step.Output = tree.Compact()
+	return step
+}
+
 // ヘルパー関数群
 func (ee *ExecutionEngine) extractSearchTarget(input string) string {
 	// まず全文から技術的なキーワードを直接検索
@@ -1161,6 +1176,11 @@ func (ee *ExecutionEngine) inferAnalysisCommand(input string) string {
 
 // コマンドを安全に実行
 func (ee *ExecutionEngine) ExecuteCommand(command string) (*ExecutionResult, error) {
+	return ee.ExecuteCommandContext(context.Background(), command)
+}
+
+// ExecuteCommandContext はコマンドを安全に実行（ctxのキャンセルで実行中のプロセスを停止）
+func (ee *ExecutionEngine) ExecuteCommandContext(ctx context.Context, command string) (*ExecutionResult, error) {
 	if !ee.enabled {
 		return nil, fmt.Errorf("実行エンジンが無効です")
 	}
@@ -1168,7 +1188,7 @@ func (ee *ExecutionEngine) ExecuteCommand(command string) (*ExecutionResult, err
 	// マルチツールワークフロー処理
 	if strings.HasPrefix(command, "multi-tool:") {
 		workflowType := strings.TrimPrefix(command, "multi-tool:")
-		return ee.executeMultiToolWorkflowAndFormat(workflowType, command)
+		return ee.executeMultiToolWorkflowAndFormat(ctx, workflowType, command)
 	}
 
 	// キャッシュチェック（パフォーマンス最適化）
@@ -1177,16 +1197,16 @@ func (ee *ExecutionEngine) ExecuteCommand(command string) (*ExecutionResult, err
 	}
 
 	// コマンドの安全性をチェック
-	if !ee.isCommandSafe(command) {
+	if err := ee.policy.Evaluate(command); err != nil {
 		return &ExecutionResult{
 			Command:   command,
-			Error:     "コマンドが安全性チェックを通過しませんでした",
+			Error:     fmt.Sprintf("コマンドが安全性チェックを通過しませんでした: %v", err),
 			ExitCode:  -1,
 			Timestamp: time.Now(),
-		}, fmt.Errorf("unsafe command: %s", command)
+		}, fmt.Errorf("unsafe command: %s: %w", command, err)
 	}
 
-	result, err := ee.runCommand(command)
+	result, err := ee.runCommand(ctx, command)
 
 	// 成功した場合はキャッシュに保存
 	if err == nil && result != nil {
@@ -1196,38 +1216,8 @@ func (ee *ExecutionEngine) ExecuteCommand(command string) (*ExecutionResult, err
 	return result, err
 }
 
-// コマンドの安全性をチェック
-func (ee *ExecutionEngine) isCommandSafe(command string) bool {
-	parts := strings.Fields(command)
-	if len(parts) == 0 {
-		return false
-	}
-
-	baseCmd := parts[0]
-
-	// 許可されたコマンドかチェック
-	if !ee.allowedCommands[baseCmd] {
-		return false
-	}
-
-	// 危険なオプションをチェック
-	dangerousPatterns := []string{
-		"rm ", "mv ", "cp /", "> /", ">> /",
-		"sudo", "su ", "chmod", "chown",
-		"--delete", "--force", "-f",
-	}
-
-	for _, pattern := range dangerousPatterns {
-		if strings.Contains(command, pattern) {
-			return false
-		}
-	}
-
-	return true
-}
-
-// 実際にコマンドを実行
-func (ee *ExecutionEngine) runCommand(command string) (*ExecutionResult, error) {
+// 実際にコマンドを実行（MaxExecutionTimeまたはctxのキャンセルでプロセスを停止）
+func (ee *ExecutionEngine) runCommand(ctx context.Context, command string) (*ExecutionResult, error) {
 	result := &ExecutionResult{
 		Command:   command,
 		Timestamp: time.Now(),
@@ -1243,40 +1233,52 @@ func (ee *ExecutionEngine) runCommand(command string) (*ExecutionResult, error)
 		return result, fmt.Errorf("empty command")
 	}
 
+	if ee.backend == nil {
+		result.Error = "実行バックエンドが設定されていません（サンドボックス設定を確認してください）"
+		result.ExitCode = -1
+		return result, fmt.Errorf("execution backend not configured")
+	}
+
+	// タイムアウトは呼び出し元のキャンセルと合わせてコンテキストで管理
+	ctx, cancel := context.WithTimeout(ctx, ee.safetyLimits.MaxExecutionTime)
+	defer cancel()
+
 	// 実行ディレクトリを設定
 	var cmd *exec.Cmd
 
-	// パイプが含まれている場合はシェルを経由して実行
-	if strings.Contains(command, "|") || strings.Contains(command, ">") || strings.Contains(command, "<") {
-		cmd = exec.Command("sh", "-c", command)
+	if ee.backend.IsSandboxed() {
+		// サンドボックス実行はバックエンドにコマンド構築を委譲
+		sandboxed, err := ee.backend.Command(ctx, command, ee.projectPath)
+		if err != nil {
+			result.Error = fmt.Sprintf("サンドボックス実行エラー: %v", err)
+			result.ExitCode = -1
+			return result, err
+		}
+		cmd = sandboxed
+	} else if strings.Contains(command, "|") || strings.Contains(command, ">") || strings.Contains(command, "<") {
+		// パイプが含まれている場合はシェルを経由して実行
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
 	} else {
-		cmd = exec.Command(parts[0], parts[1:]...)
+		cmd = exec.CommandContext(ctx, parts[0], parts[1:]...)
 	}
 	cmd.Dir = ee.projectPath
+	// 停止したプロセスの子がパイプを保持していても待ち続けない
+	cmd.WaitDelay = time.Second
 
-	// タイムアウト処理の改善
-	done := make(chan struct{})
-	var output []byte
-	var err error
-
-	go func() {
-		defer close(done)
-		output, err = cmd.CombinedOutput()
-	}()
-
-	// タイムアウトチェック
-	select {
-	case <-done:
-		// 正常終了
-	case <-time.After(ee.safetyLimits.MaxExecutionTime):
-		// タイムアウト時はプロセスを強制終了
-		if cmd.Process != nil {
-			cmd.Process.Kill()
-		}
+	output, err := cmd.CombinedOutput()
+
+	// タイムアウト・キャンセルの判定
+	switch ctx.Err() {
+	case context.DeadlineExceeded:
 		result.Error = fmt.Sprintf("コマンドタイムアウト (%v)", ee.safetyLimits.MaxExecutionTime)
 		result.ExitCode = -1
 		result.Duration = time.Since(start)
 		return result, fmt.Errorf("command timeout after %v", ee.safetyLimits.MaxExecutionTime)
+	case context.Canceled:
+		result.Error = "コマンドがキャンセルされました"
+		result.ExitCode = -1
+		result.Duration = time.Since(start)
+		return result, fmt.Errorf("command canceled: %w", context.Canceled)
 	}
 
 	result.Duration = time.Since(start)
@@ -1998,7 +2000,7 @@ func (ee *ExecutionEngine) GetCacheStats() map[string]interface{} {
 }
 
 // マルチツールワークフローの実行と結果フォーマット
-func (ee *ExecutionEngine) executeMultiToolWorkflowAndFormat(workflowType, originalCommand string) (*ExecutionResult, error) {
+func (ee *ExecutionEngine) executeMultiToolWorkflowAndFormat(ctx context.Context, workflowType, originalCommand string) (*ExecutionResult, error) {
 	start := time.Now()
 
 	// 保存されたユーザー入力を使用
@@ -2006,7 +2008,7 @@ func (ee *ExecutionEngine) executeMultiToolWorkflowAndFormat(workflowType, origi
 	userInput := ee.lastUserInput
 
 	// ワークフローを実行
-	multiResult, err := ee.executeMultiToolWorkflow(workflowType, inputLower, userInput)
+	multiResult, err := ee.executeMultiToolWorkflow(ctx, workflowType, inputLower, userInput)
 	if err != nil {
 		return &ExecutionResult{
 			Command:   originalCommand,
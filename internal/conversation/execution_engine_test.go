@@ -1,8 +1,11 @@
 package conversation
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/glkt/vyb-code/internal/config"
 )
@@ -216,3 +219,114 @@ func TestAnalyzeUserIntent(t *testing.T) {
 		})
 	}
 }
+
+// newCancellationTestEngine はキャンセル検証用に実行エンジンを作成
+func newCancellationTestEngine(t *testing.T) *ExecutionEngine {
+	cfg := &config.Config{
+		Features: &config.Features{ProactiveMode: true},
+		Proactive: config.ProactiveConfig{
+			Enabled: true,
+			Level:   3,
+		},
+	}
+	ee := NewExecutionEngine(cfg, t.TempDir())
+	if ee.backend == nil {
+		t.Fatal("実行バックエンドが作成されていない")
+	}
+	return ee
+}
+
+// キャンセルで実行中のコマンドが停止することのテスト
+func TestRunCommandCancellation(t *testing.T) {
+	ee := newCancellationTestEngine(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := ee.runCommand(ctx, "sleep 10")
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("context.Canceled を期待: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("キャンセル後もコマンドが継続: %v", elapsed)
+	}
+	if result == nil || result.ExitCode != -1 {
+		t.Errorf("キャンセル時の終了コードは-1であるべき: %+v", result)
+	}
+
+	// パイプを含むシェル経由のコマンドも停止する
+	ctx, cancel = context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start = time.Now()
+	if _, err := ee.runCommand(ctx, "sleep 10 | cat"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("context.Canceled を期待: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("キャンセル後もシェルコマンドが継続: %v", elapsed)
+	}
+}
+
+// MaxExecutionTime 超過でタイムアウトすることのテスト
+func TestRunCommandTimeout(t *testing.T) {
+	ee := newCancellationTestEngine(t)
+	ee.safetyLimits.MaxExecutionTime = 100 * time.Millisecond
+
+	start := time.Now()
+	result, err := ee.runCommand(context.Background(), "sleep 10")
+	if err == nil || !strings.Contains(err.Error(), "timeout") {
+		t.Fatalf("タイムアウトエラーを期待: %v", err)
+	}
+	if errors.Is(err, context.Canceled) {
+		t.Error("タイムアウトはキャンセルと区別されるべき")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("タイムアウト後もコマンドが継続: %v", elapsed)
+	}
+	if !strings.Contains(result.Error, "タイムアウト") {
+		t.Errorf("タイムアウトのエラーメッセージを期待: %q", result.Error)
+	}
+}
+
+// キャンセル済みのコンテキストではワークフローの各ステップが実行されないことのテスト
+func TestExecuteCommandContextCanceled(t *testing.T) {
+	ee := newCancellationTestEngine(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := ee.ExecuteCommandContext(ctx, "ls -la"); !errors.Is(err, context.Canceled) {
+		t.Fatalf("context.Canceled を期待: %v", err)
+	}
+	// キャンセルされた結果はキャッシュしない
+	if cached := ee.getCachedResult("ls -la"); cached != nil {
+		t.Error("キャンセルされた結果がキャッシュされている")
+	}
+
+	step := ee.executeToolStep(ctx, "ls", "ls -la")
+	if step.Success {
+		t.Error("キャンセル済みのステップが成功扱いになっている")
+	}
+}
+
+// コマンドポリシーによる安全性チェックのテスト
+func TestExecuteCommandPolicy(t *testing.T) {
+	ee := newCancellationTestEngine(t)
+
+	for _, command := range []string{"rm -rf .", "ls; rm -rf .", "cat /etc/passwd", "find . -delete"} {
+		result, err := ee.ExecuteCommand(command)
+		if err == nil || !strings.Contains(err.Error(), "unsafe command") {
+			t.Errorf("%q: 安全性チェックで拒否されるべき: %v", command, err)
+		}
+		if result == nil || !strings.Contains(result.Error, "安全性チェック") {
+			t.Errorf("%q: 拒否理由が結果に含まれていない: %+v", command, result)
+		}
+	}
+
+	// 以前は "-f" を含むだけで拒否されていたコマンド
+	for _, command := range []string{"grep -f patterns.txt main.go", "git log -n 1 --format=%H"} {
+		if err := ee.policy.Evaluate(command); err != nil {
+			t.Errorf("%q: 安全なコマンドが拒否された: %v", command, err)
+		}
+	}
+}
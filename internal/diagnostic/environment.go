@@ -0,0 +1,349 @@
+package diagnostic
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/textutil"
+	"github.com/glkt/vyb-code/internal/version"
+)
+
+// toolVersionTimeout はツール1つのバージョン取得の待ち時間
+const toolVersionTimeout = 5 * time.Second
+
+// DefaultTools は環境レポートでバージョンを調べるツール（名前 → バージョン表示の引数）
+var DefaultTools = []ToolCommand{
+	{Name: "go", Args: []string{"version"}},
+	{Name: "node", Args: []string{"--version"}},
+	{Name: "npm", Args: []string{"--version"}},
+	{Name: "python3", Args: []string{"--version"}},
+	{Name: "docker", Args: []string{"--version"}},
+	{Name: "git", Args: []string{"--version"}},
+	{Name: "ollama", Args: []string{"--version"}},
+}
+
+// environmentVariables は環境レポートに含める環境変数（VYB_ で始まるものも含める）
+var environmentVariables = []string{
+	"SHELL", "TERM", "LANG", "LC_ALL", "PATH",
+	"GOPATH", "GOROOT", "GOFLAGS", "GOPROXY", "GOPRIVATE", "GO111MODULE", "CGO_ENABLED", "GOOS", "GOARCH",
+	"NODE_ENV", "NODE_OPTIONS", "NPM_CONFIG_REGISTRY",
+	"VIRTUAL_ENV", "PYTHONPATH",
+	"DOCKER_HOST", "DOCKER_CONTEXT",
+	"OLLAMA_HOST", "HTTP_PROXY", "HTTPS_PROXY", "NO_PROXY",
+	"CI", "GITHUB_ACTIONS",
+}
+
+// secretNameParts は値を全て伏せる環境変数名の一部
+var secretNameParts = []string{"TOKEN", "SECRET", "PASSWORD", "PASSWD", "KEY", "CREDENTIAL", "AUTH"}
+
+// toolNamePattern は追加でバージョンを調べられるツール名（パスや引数は受け付けない）
+var toolNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._+-]*$`)
+
+// ValidToolName は追加で調べるツールの名前として使えるか
+func ValidToolName(name string) bool {
+	return toolNamePattern.MatchString(name)
+}
+
+// ToolCommand はバージョンを調べるツール
+type ToolCommand struct {
+	Name string
+	Args []string
+}
+
+// ToolVersion はツールのバージョンの調査結果
+type ToolVersion struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	Path    string `json:"path,omitempty"`
+	Error   string `json:"error,omitempty"` // 見つからない・実行できない場合
+}
+
+// Setting は環境変数・設定の名前と値
+type Setting struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// OSInfo は OS・実行環境の情報
+type OSInfo struct {
+	GOOS    string `json:"goos"`
+	GOARCH  string `json:"goarch"`
+	Release string `json:"release,omitempty"` // ディストリビューション・OS のバージョン
+	Kernel  string `json:"kernel,omitempty"`
+	NumCPU  int    `json:"num_cpu"`
+	WorkDir string `json:"work_dir"` // ホームディレクトリは ~ に置き換える
+}
+
+// EnvironmentReport は再現可能な不具合報告のための環境の情報（機密情報は伏せる）
+type EnvironmentReport struct {
+	GeneratedAt time.Time     `json:"generated_at"`
+	Vyb         string        `json:"vyb"`
+	GoRuntime   string        `json:"go_runtime"` // vyb をビルドした Go のバージョン
+	OS          OSInfo        `json:"os"`
+	Tools       []ToolVersion `json:"tools"`
+	Env         []Setting     `json:"env"`
+	Config      []Setting     `json:"config,omitempty"`
+}
+
+// EnvironmentOptions は環境レポートの取得内容
+type EnvironmentOptions struct {
+	Config  *config.Config // nil なら設定を含めない
+	Profile string
+	Tools   []string // 既定に加えてバージョンを調べるツール（<name> --version）
+}
+
+// CaptureEnvironment はツールのバージョン・OS・関係する環境変数・vyb の設定を集める
+func CaptureEnvironment(ctx context.Context, opts EnvironmentOptions) *EnvironmentReport {
+	report := &EnvironmentReport{
+		GeneratedAt: time.Now(),
+		Vyb:         version.GetVersion(),
+		GoRuntime:   runtime.Version(),
+		OS:          captureOS(ctx),
+	}
+
+	commands := append([]ToolCommand(nil), DefaultTools...)
+	for _, name := range opts.Tools {
+		if ValidToolName(name) && !hasTool(commands, name) {
+			commands = append(commands, ToolCommand{Name: name, Args: []string{"--version"}})
+		}
+	}
+	for _, command := range commands {
+		report.Tools = append(report.Tools, toolVersion(ctx, command))
+	}
+
+	report.Env = captureEnv()
+	if opts.Config != nil {
+		report.Config = configSummary(opts.Config, opts.Profile)
+	}
+	return report
+}
+
+// hasTool は同じ名前のツールがあるか
+func hasTool(commands []ToolCommand, name string) bool {
+	for _, command := range commands {
+		if command.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// toolVersion はツールのバージョン表示の最初の行を取得する
+func toolVersion(ctx context.Context, command ToolCommand) ToolVersion {
+	result := ToolVersion{Name: command.Name}
+	path, err := exec.LookPath(command.Name)
+	if err != nil {
+		result.Error = "not found"
+		return result
+	}
+	result.Path = homeRelative(path)
+
+	ctx, cancel := context.WithTimeout(ctx, toolVersionTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, path, command.Args...).CombinedOutput()
+	line := textutil.FirstLine(string(output))
+	if err != nil {
+		result.Error = err.Error()
+		if line != "" {
+			result.Error += ": " + line
+		}
+		return result
+	}
+	result.Version = line
+	return result
+}
+
+// captureOS は OS の種類・バージョン・カーネルを取得する
+func captureOS(ctx context.Context) OSInfo {
+	info := OSInfo{GOOS: runtime.GOOS, GOARCH: runtime.GOARCH, NumCPU: runtime.NumCPU()}
+	if dir, err := os.Getwd(); err == nil {
+		info.WorkDir = homeRelative(dir)
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		info.Release = osRelease("/etc/os-release")
+	case "darwin":
+		info.Release = commandLine(ctx, "sw_vers", "-productVersion")
+		if info.Release != "" {
+			info.Release = "macOS " + info.Release
+		}
+	case "windows":
+		info.Release = commandLine(ctx, "cmd", "/c", "ver")
+	}
+	if runtime.GOOS != "windows" {
+		info.Kernel = commandLine(ctx, "uname", "-sr")
+	}
+	return info
+}
+
+// osRelease は os-release の PRETTY_NAME を返す
+func osRelease(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if value, ok := strings.CutPrefix(line, "PRETTY_NAME="); ok {
+			if unquoted, err := strconv.Unquote(value); err == nil {
+				return unquoted
+			}
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return ""
+}
+
+// commandLine はコマンドの出力の最初の行を返す（失敗したら空）
+func commandLine(ctx context.Context, name string, args ...string) string {
+	ctx, cancel := context.WithTimeout(ctx, toolVersionTimeout)
+	defer cancel()
+	output, err := exec.CommandContext(ctx, name, args...).Output()
+	if err != nil {
+		return ""
+	}
+	return textutil.FirstLine(string(output))
+}
+
+// captureEnv は関係する環境変数を伏せ字にして集める
+func captureEnv() []Setting {
+	var settings []Setting
+	seen := make(map[string]bool)
+	add := func(name string) {
+		value, ok := os.LookupEnv(name)
+		if !ok || seen[name] {
+			return
+		}
+		seen[name] = true
+		settings = append(settings, Setting{Name: name, Value: RedactEnv(name, value)})
+	}
+	for _, name := range environmentVariables {
+		add(name)
+	}
+
+	var vybVariables []string
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		if strings.HasPrefix(name, "VYB_") {
+			vybVariables = append(vybVariables, name)
+		}
+	}
+	sort.Strings(vybVariables)
+	for _, name := range vybVariables {
+		add(name)
+	}
+	return settings
+}
+
+// RedactEnv は環境変数の値を報告用に伏せる
+// 名前が機密情報を示すものは値を全て伏せ、それ以外は既定の Redactor とホームディレクトリの置き換えを通す
+func RedactEnv(name, value string) string {
+	upper := strings.ToUpper(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(upper, part) && value != "" {
+			return redact.Placeholder("env")
+		}
+	}
+	return homeRelative(redact.String(value))
+}
+
+// configSummary は不具合の調査に関係する vyb の設定（URL の資格情報は伏せる）
+func configSummary(cfg *config.Config, profile string) []Setting {
+	settings := []Setting{
+		{Name: "provider", Value: cfg.Provider},
+		{Name: "model", Value: cfg.ResolvedModel()},
+		{Name: "base_url", Value: redact.String(cfg.BaseURL)},
+		{Name: "language", Value: cfg.Language},
+		{Name: "workspace_mode", Value: cfg.WorkspaceMode},
+		{Name: "timeout", Value: strconv.Itoa(cfg.Timeout)},
+		{Name: "command_timeout", Value: strconv.Itoa(cfg.CommandTimeout)},
+		{Name: "context_window", Value: strconv.Itoa(cfg.ContextWindow)},
+		{Name: "sandbox.mode", Value: cfg.Sandbox.Mode},
+		{Name: "network.mode", Value: cfg.Network.Mode},
+		{Name: "redaction.level", Value: cfg.Redaction.Level},
+	}
+	if profile != "" {
+		settings = append([]Setting{{Name: "profile", Value: profile}}, settings...)
+	}
+	for _, fallback := range cfg.Resilience.Fallbacks {
+		settings = append(settings, Setting{Name: "resilience.fallback", Value: redact.String(fallback.BaseURL)})
+	}
+	return settings
+}
+
+// homeRelative はホームディレクトリを ~ に置き換える（ユーザー名を報告に含めない）
+func homeRelative(text string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" || home == string(filepath.Separator) {
+		return text
+	}
+	return strings.ReplaceAll(text, home, "~")
+}
+
+// MissingTools は見つからなかったツールの名前を返す
+func (r *EnvironmentReport) MissingTools() []string {
+	var missing []string
+	for _, tool := range r.Tools {
+		if tool.Error == "not found" {
+			missing = append(missing, tool.Name)
+		}
+	}
+	return missing
+}
+
+// Markdown は不具合報告に貼り付けられる形式の環境レポートを返す
+func (r *EnvironmentReport) Markdown() string {
+	var b strings.Builder
+	b.WriteString("### Environment\n\n")
+	fmt.Fprintf(&b, "- vyb: %s (%s)\n", r.Vyb, r.GoRuntime)
+	osLine := r.OS.GOOS + "/" + r.OS.GOARCH
+	if r.OS.Release != "" {
+		osLine += ", " + r.OS.Release
+	}
+	if r.OS.Kernel != "" {
+		osLine += ", " + r.OS.Kernel
+	}
+	fmt.Fprintf(&b, "- OS: %s, %d CPU(s)\n", osLine, r.OS.NumCPU)
+	fmt.Fprintf(&b, "- Working directory: %s\n", r.OS.WorkDir)
+
+	b.WriteString("\n#### Tools\n\n| Tool | Version | Path |\n|------|---------|------|\n")
+	for _, tool := range r.Tools {
+		status := tool.Version
+		if tool.Error != "" {
+			status = tool.Error
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s |\n", tool.Name, escapeCell(status), escapeCell(tool.Path))
+	}
+
+	if len(r.Env) > 0 {
+		b.WriteString("\n#### Environment variables\n\n```\n")
+		for _, setting := range r.Env {
+			fmt.Fprintf(&b, "%s=%s\n", setting.Name, setting.Value)
+		}
+		b.WriteString("```\n")
+	}
+	if len(r.Config) > 0 {
+		b.WriteString("\n#### vyb config\n\n```\n")
+		for _, setting := range r.Config {
+			fmt.Fprintf(&b, "%s: %s\n", setting.Name, setting.Value)
+		}
+		b.WriteString("```\n")
+	}
+	return b.String()
+}
+
+// escapeCell は Markdown の表のセルに入れられるようにする
+func escapeCell(text string) string {
+	return strings.ReplaceAll(text, "|", `\|`)
+}
@@ -0,0 +1,263 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LCS計算の上限（行数の積）。超える場合はファイル全体の置換として扱う
+const maxLCSCells = 4_000_000
+
+// OpKind は行単位の差分操作の種類
+type OpKind int
+
+const (
+	OpEqual  OpKind = iota // 変更なし
+	OpDelete               // 削除行
+	OpInsert               // 追加行
+)
+
+// Op は行単位の差分操作
+type Op struct {
+	Kind OpKind
+	Line string
+}
+
+// Lines は2つのテキストの行単位差分を計算
+func Lines(oldText, newText string) []Op {
+	return compute(splitLines(oldText), splitLines(newText))
+}
+
+// Unified は unified diff 形式の差分文字列を生成（差分なしの場合は空文字）
+func Unified(oldName, newName, oldText, newText string, contextLines int) string {
+	if oldText == newText {
+		return ""
+	}
+
+	ops := Lines(oldText, newText)
+	hunks := buildHunks(ops, contextLines)
+	if len(hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n", oldName)
+	fmt.Fprintf(&b, "+++ %s\n", newName)
+	for _, h := range hunks {
+		fmt.Fprintf(&b, "@@ -%s +%s @@\n", hunkRange(h.oldStart, h.oldCount), hunkRange(h.newStart, h.newCount))
+		for _, op := range h.ops {
+			switch op.Kind {
+			case OpEqual:
+				b.WriteString(" ")
+			case OpDelete:
+				b.WriteString("-")
+			case OpInsert:
+				b.WriteString("+")
+			}
+			b.WriteString(op.Line)
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// Stats は追加行数と削除行数を返す
+func Stats(oldText, newText string) (added, removed int) {
+	for _, op := range Lines(oldText, newText) {
+		switch op.Kind {
+		case OpInsert:
+			added++
+		case OpDelete:
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// splitLines はテキストを行に分割（末尾の改行は無視）
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
+
+// compute はLCSに基づいて差分操作列を生成
+func compute(a, b []string) []Op {
+	// 共通の先頭・末尾を除外して計算量を削減
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]Op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, Op{Kind: OpEqual, Line: line})
+	}
+	ops = append(ops, computeMiddle(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, Op{Kind: OpEqual, Line: line})
+	}
+	return ops
+}
+
+// computeMiddle は先頭・末尾を除いた区間のLCS差分を計算
+func computeMiddle(a, b []string) []Op {
+	n, m := len(a), len(b)
+	ops := make([]Op, 0, n+m)
+
+	// 大きすぎる場合は全削除・全追加として扱う
+	if n*m > maxLCSCells {
+		for _, line := range a {
+			ops = append(ops, Op{Kind: OpDelete, Line: line})
+		}
+		for _, line := range b {
+			ops = append(ops, Op{Kind: OpInsert, Line: line})
+		}
+		return ops
+	}
+
+	// lcs[i][j] は a[i:] と b[j:] のLCS長
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, Op{Kind: OpEqual, Line: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, Op{Kind: OpDelete, Line: a[i]})
+			i++
+		default:
+			ops = append(ops, Op{Kind: OpInsert, Line: b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, Op{Kind: OpDelete, Line: a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, Op{Kind: OpInsert, Line: b[j]})
+	}
+	return ops
+}
+
+// hunk は unified diff のハンク
+type hunk struct {
+	oldStart, oldCount int
+	newStart, newCount int
+	ops                []Op
+}
+
+// buildHunks は差分操作列を前後のコンテキスト付きハンクにまとめる
+func buildHunks(ops []Op, contextLines int) []hunk {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+
+	// 変更行のインデックスを収集
+	var changes []int
+	for i, op := range ops {
+		if op.Kind != OpEqual {
+			changes = append(changes, i)
+		}
+	}
+	if len(changes) == 0 {
+		return nil
+	}
+
+	// 各操作位置での旧・新の行番号（1始まり）を計算
+	oldLine := make([]int, len(ops)+1)
+	newLine := make([]int, len(ops)+1)
+	oldLine[0], newLine[0] = 1, 1
+	for i, op := range ops {
+		oldLine[i+1], newLine[i+1] = oldLine[i], newLine[i]
+		if op.Kind != OpInsert {
+			oldLine[i+1]++
+		}
+		if op.Kind != OpDelete {
+			newLine[i+1]++
+		}
+	}
+
+	var hunks []hunk
+	start := max(changes[0]-contextLines, 0)
+	end := min(changes[0]+contextLines+1, len(ops))
+	for _, c := range changes[1:] {
+		if c-contextLines <= end {
+			end = min(c+contextLines+1, len(ops))
+			continue
+		}
+		hunks = append(hunks, newHunk(ops, start, end, oldLine, newLine))
+		start = max(c-contextLines, 0)
+		end = min(c+contextLines+1, len(ops))
+	}
+	hunks = append(hunks, newHunk(ops, start, end, oldLine, newLine))
+	return hunks
+}
+
+// newHunk は指定範囲からハンクを作成
+func newHunk(ops []Op, start, end int, oldLine, newLine []int) hunk {
+	h := hunk{
+		oldStart: oldLine[start],
+		newStart: newLine[start],
+		ops:      ops[start:end],
+	}
+	for _, op := range h.ops {
+		if op.Kind != OpInsert {
+			h.oldCount++
+		}
+		if op.Kind != OpDelete {
+			h.newCount++
+		}
+	}
+	return h
+}
+
+// hunkRange はハンクヘッダーの範囲表記を返す
+func hunkRange(start, count int) string {
+	// 行数0のハンクは直前の行番号を示す（GNU diff互換）
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// max は大きい方を返す
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+// min は小さい方を返す
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,53 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnified_SingleChange(t *testing.T) {
+	oldText := "a\nb\nc\nd\ne\n"
+	newText := "a\nb\nC\nd\ne\n"
+
+	got := Unified("a/file.txt", "b/file.txt", oldText, newText, 1)
+	expected := "--- a/file.txt\n+++ b/file.txt\n@@ -2,3 +2,3 @@\n b\n-c\n+C\n d\n"
+	if got != expected {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, expected)
+	}
+}
+
+func TestUnified_NoChange(t *testing.T) {
+	if got := Unified("a", "b", "same\n", "same\n", 3); got != "" {
+		t.Errorf("Expected empty diff, got %q", got)
+	}
+}
+
+func TestUnified_NewFile(t *testing.T) {
+	got := Unified("/dev/null", "b/new.go", "", "package main\n", 3)
+	if !strings.Contains(got, "@@ -0,0 +1 @@\n+package main\n") {
+		t.Errorf("Unexpected diff for new file:\n%s", got)
+	}
+}
+
+func TestUnified_SeparateHunks(t *testing.T) {
+	lines := make([]string, 20)
+	for i := range lines {
+		lines[i] = "line"
+	}
+	oldText := strings.Join(lines, "\n")
+	lines[1] = "first"
+	lines[18] = "second"
+	newText := strings.Join(lines, "\n")
+
+	got := Unified("a", "b", oldText, newText, 2)
+	if strings.Count(got, "@@ -") != 2 {
+		t.Errorf("Expected 2 hunks, got:\n%s", got)
+	}
+}
+
+func TestStats(t *testing.T) {
+	added, removed := Stats("a\nb\nc\n", "a\nx\ny\nc\n")
+	if added != 2 || removed != 1 {
+		t.Errorf("Stats = (+%d, -%d), want (+2, -1)", added, removed)
+	}
+}
@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"strconv"
+	"strings"
+)
+
+// FileDiff は unified diff（git diff 形式）のファイル1つ分の差分
+type FileDiff struct {
+	Path     string   // 変更後のパス（削除の場合は変更前のパス）
+	OldPath  string   // 変更前のパス（新規作成の場合は空）
+	Hunks    []string // "@@" 行から始まるハンク毎の差分
+	Added    int
+	Deleted  int
+	Binary   bool
+	IsNew    bool
+	IsDelete bool
+
+	newLines map[int]bool // 差分に含まれる変更後の行番号（追加行・文脈行）
+}
+
+// Patch はファイルの差分全体
+func (f *FileDiff) Patch() string {
+	return strings.Join(f.Hunks, "")
+}
+
+// HasLine は変更後の行番号が差分に含まれるか（レビューコメントを付けられる行）
+func (f *FileDiff) HasLine(line int) bool {
+	return f.newLines[line]
+}
+
+// ParseFiles は unified diff をファイル毎に分割する
+func ParseFiles(patch string) []*FileDiff {
+	var files []*FileDiff
+	var current *FileDiff
+	var hunk strings.Builder
+	newLine := 0
+
+	flushHunk := func() {
+		if current != nil && hunk.Len() > 0 {
+			current.Hunks = append(current.Hunks, hunk.String())
+			hunk.Reset()
+		}
+	}
+
+	for _, line := range strings.SplitAfter(patch, "\n") {
+		text := strings.TrimRight(line, "\n")
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			flushHunk()
+			current = &FileDiff{newLines: make(map[int]bool)}
+			files = append(files, current)
+			// 後続の ---/+++ 行がない場合（バイナリ・リネームのみ）に備えて a/ b/ から推定
+			if a, b, ok := splitGitPaths(strings.TrimPrefix(text, "diff --git ")); ok {
+				current.OldPath, current.Path = a, b
+			}
+		case current == nil:
+			continue
+		case hunk.Len() == 0 && strings.HasPrefix(text, "--- "):
+			if path := trimDiffPath(strings.TrimPrefix(text, "--- ")); path != "" {
+				current.OldPath = path
+			} else {
+				current.OldPath = ""
+				current.IsNew = true
+			}
+		case hunk.Len() == 0 && strings.HasPrefix(text, "+++ "):
+			if path := trimDiffPath(strings.TrimPrefix(text, "+++ ")); path != "" {
+				current.Path = path
+			} else {
+				current.Path = current.OldPath
+				current.IsDelete = true
+			}
+		case strings.HasPrefix(text, "new file mode"):
+			current.IsNew = true
+		case strings.HasPrefix(text, "deleted file mode"):
+			current.IsDelete = true
+		case strings.HasPrefix(text, "Binary files "):
+			current.Binary = true
+		case strings.HasPrefix(text, "@@"):
+			flushHunk()
+			newLine = hunkNewStart(text)
+			hunk.WriteString(line)
+		case hunk.Len() > 0:
+			hunk.WriteString(line)
+			switch {
+			case strings.HasPrefix(text, "+"):
+				current.Added++
+				current.newLines[newLine] = true
+				newLine++
+			case strings.HasPrefix(text, "-"):
+				current.Deleted++
+			case strings.HasPrefix(text, " "):
+				current.newLines[newLine] = true
+				newLine++
+			}
+		}
+	}
+	flushHunk()
+	return files
+}
+
+// splitGitPaths は "a/x b/y" 形式のパスを分割（空白を含むパスは ---/+++ 行に任せる）
+func splitGitPaths(paths string) (string, string, bool) {
+	fields := strings.Fields(paths)
+	if len(fields) != 2 || !strings.HasPrefix(fields[0], "a/") || !strings.HasPrefix(fields[1], "b/") {
+		return "", "", false
+	}
+	return fields[0][2:], fields[1][2:], true
+}
+
+// trimDiffPath は ---/+++ 行のパスから a/ b/ 接頭辞を除く（/dev/null は空）
+func trimDiffPath(path string) string {
+	if i := strings.Index(path, "\t"); i >= 0 {
+		path = path[:i]
+	}
+	if path == "/dev/null" {
+		return ""
+	}
+	if strings.HasPrefix(path, "a/") || strings.HasPrefix(path, "b/") {
+		return path[2:]
+	}
+	return path
+}
+
+// hunkNewStart は "@@ -a,b +c,d @@" から変更後の開始行 c を取得
+func hunkNewStart(header string) int {
+	fields := strings.Fields(header)
+	for _, field := range fields {
+		if strings.HasPrefix(field, "+") {
+			start := strings.TrimPrefix(field, "+")
+			if i := strings.Index(start, ","); i >= 0 {
+				start = start[:i]
+			}
+			if n, err := strconv.Atoi(start); err == nil {
+				return n
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,76 @@
+package diff
+
+import "testing"
+
+const samplePatch = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,4 +1,5 @@
+ package main
+ 
+-func old() {}
++func newer() {}
++func extra() {}
+ // end
+@@ -20,2 +21,2 @@ func tail() {
+-	return 1
++	return 2
+ }
+diff --git a/docs/new.md b/docs/new.md
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/docs/new.md
+@@ -0,0 +1,2 @@
++# New
++-- not a header
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+index 4444444..0000000
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-gone
+diff --git a/logo.png b/logo.png
+index 5555555..6666666 100644
+Binary files a/logo.png and b/logo.png differ
+`
+
+func TestParseFiles(t *testing.T) {
+	files := ParseFiles(samplePatch)
+	if len(files) != 4 {
+		t.Fatalf("ファイル数: %d", len(files))
+	}
+
+	main := files[0]
+	if main.Path != "main.go" || len(main.Hunks) != 2 || main.Added != 3 || main.Deleted != 2 {
+		t.Errorf("main.go: path=%s hunks=%d +%d -%d", main.Path, len(main.Hunks), main.Added, main.Deleted)
+	}
+	// 追加行（3, 4）と文脈行（1, 2, 5, 22）はコメント可能、差分外の行は不可
+	for _, line := range []int{1, 3, 4, 5, 21, 22} {
+		if !main.HasLine(line) {
+			t.Errorf("行 %d は差分に含まれるはず", line)
+		}
+	}
+	for _, line := range []int{6, 20, 23} {
+		if main.HasLine(line) {
+			t.Errorf("行 %d は差分に含まれないはず", line)
+		}
+	}
+
+	added := files[1]
+	if added.Path != "docs/new.md" || !added.IsNew || added.OldPath != "" || added.Added != 2 {
+		t.Errorf("新規ファイル: %+v", added)
+	}
+
+	deleted := files[2]
+	if deleted.Path != "old.txt" || !deleted.IsDelete || deleted.Deleted != 1 {
+		t.Errorf("削除ファイル: %+v", deleted)
+	}
+
+	binary := files[3]
+	if binary.Path != "logo.png" || !binary.Binary || len(binary.Hunks) != 0 {
+		t.Errorf("バイナリ: %+v", binary)
+	}
+}
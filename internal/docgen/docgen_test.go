@@ -0,0 +1,194 @@
+package docgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+const sampleSource = `package shapes
+
+import "math"
+
+// 形状の種類
+const (
+	KindCircle = "circle"
+	KindSquare = "square" // 正方形
+	kindHidden = "hidden"
+)
+
+var DefaultScale = 1.0
+
+type Circle struct {
+	Radius float64
+}
+
+// NewCircle は円を作成
+func NewCircle(r float64) *Circle {
+	return &Circle{Radius: r}
+}
+
+func (c *Circle) Area() float64 {
+	return math.Pi * c.Radius * c.Radius
+}
+
+type shape struct{}
+
+func (s shape) Exported() {}
+
+func helper() {}
+`
+
+func writePackage(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"shapes.go":      sampleSource,
+		"shapes_test.go": "package shapes\n\nfunc TestX() {}\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestInspect(t *testing.T) {
+	pkg, err := Inspect(writePackage(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pkg.Name != "shapes" || len(pkg.Files) != 1 {
+		t.Fatalf("パッケージ: %+v", pkg)
+	}
+
+	names := make(map[string]Symbol)
+	for _, symbol := range pkg.Symbols {
+		names[symbol.Name] = symbol
+	}
+	for _, hidden := range []string{"kindHidden", "shape.Exported", "helper"} {
+		if _, ok := names[hidden]; ok {
+			t.Errorf("%s は公開シンボルではない", hidden)
+		}
+	}
+	if s := names["Circle.Area"]; s.Kind != KindMethod || s.Signature != "func (c *Circle) Area() float64" {
+		t.Errorf("メソッド: %+v", s)
+	}
+
+	var missing []string
+	for _, symbol := range pkg.Missing() {
+		missing = append(missing, symbol.Name)
+	}
+	// グループ・行末のコメントで説明済みの定数は対象外
+	if strings.Join(missing, ",") != "shapes,DefaultScale,Circle,Circle.Area" {
+		t.Errorf("ドキュメントのないシンボル: %v", missing)
+	}
+	if len(pkg.Targets(true)) != 5 {
+		t.Errorf("update では宣言自身のコメントのみ書き直すはず: %d", len(pkg.Targets(true)))
+	}
+}
+
+// answerProvider は固定の回答を返すテスト用プロバイダー
+type answerProvider struct {
+	answer string
+	prompt string
+}
+
+func (p *answerProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.prompt = req.Messages[0].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}, Done: true}, nil
+}
+
+func (p *answerProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *answerProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *answerProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+func TestDocumentPackage(t *testing.T) {
+	dir := writePackage(t)
+	pkg, err := Inspect(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &answerProvider{answer: "```json\n" + `{"shapes": "Package shapes は図形を扱う", "Circle": "Circle は円", "Circle.Area": "// Area は面積を返す", "NewCircle": "NewCircle は半径から円を作成する"}` + "\n```"}
+	generator := &Generator{Provider: provider, Model: "m", Language: "ja", Registry: prompts.NewRegistry("")}
+
+	changes, err := generator.DocumentPackage(context.Background(), pkg, false, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || strings.Join(changes[0].Documented, ",") != "Circle,Circle.Area,shapes" {
+		t.Fatalf("変更: %+v", changes)
+	}
+	if !strings.Contains(provider.prompt, "- Circle.Area (method): func (c *Circle) Area() float64") {
+		t.Errorf("プロンプトに対象シンボルが含まれていない:\n%s", provider.prompt)
+	}
+
+	after := changes[0].After
+	for _, want := range []string{"// Package shapes は図形を扱う\npackage shapes", "// Circle は円\ntype Circle struct", "// Area は面積を返す\nfunc (c *Circle) Area()", "// NewCircle は円を作成\n"} {
+		if !strings.Contains(after, want) {
+			t.Errorf("%q が含まれていない:\n%s", want, after)
+		}
+	}
+	if diff := changes[0].Diff(); !strings.Contains(diff, "+// Circle は円") {
+		t.Errorf("差分:\n%s", diff)
+	}
+
+	// 適用前はファイルを変更しない
+	if data, _ := os.ReadFile(filepath.Join(dir, "shapes.go")); string(data) != sampleSource {
+		t.Error("適用前にファイルが変更された")
+	}
+	if err := changes[0].Apply(); err != nil {
+		t.Fatal(err)
+	}
+	pkg, _ = Inspect(dir)
+	if len(pkg.Missing()) != 1 || pkg.Missing()[0].Name != "DefaultScale" {
+		t.Errorf("適用後のドキュメントのないシンボル: %+v", pkg.Missing())
+	}
+}
+
+func TestApplyCommentsReplacesExisting(t *testing.T) {
+	dir := writePackage(t)
+	pkg, _ := Inspect(dir)
+	src, _ := os.ReadFile(filepath.Join(dir, "shapes.go"))
+
+	var targets []Symbol
+	for _, symbol := range pkg.Targets(true) {
+		if symbol.Name == "NewCircle" {
+			targets = append(targets, symbol)
+		}
+	}
+	after, documented, err := ApplyComments(src, targets, map[string]string{"NewCircle": "NewCircle は半径 r の円を作成する\n\nr は正の値"})
+	if err != nil || len(documented) != 1 {
+		t.Fatalf("%v %v", documented, err)
+	}
+	if !strings.Contains(string(after), "// NewCircle は半径 r の円を作成する\n//\n// r は正の値\nfunc NewCircle") || strings.Contains(string(after), "円を作成\n") {
+		t.Errorf("既存コメントを置き換えるはず:\n%s", after)
+	}
+}
+
+func TestExpandDirs(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"a", "a/b", "a/testdata", "a/.hidden", "c"} {
+		os.MkdirAll(filepath.Join(root, dir), 0755)
+	}
+	for _, file := range []string{"a/x.go", "a/b/y.go", "a/testdata/z.go", "a/.hidden/h.go", "c/only_test.go"} {
+		os.WriteFile(filepath.Join(root, file), []byte("package p\n"), 0644)
+	}
+
+	dirs, err := ExpandDirs(root + "/...")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dirs) != 2 || dirs[0] != filepath.Join(root, "a") || dirs[1] != filepath.Join(root, "a", "b") {
+		t.Errorf("展開結果: %v", dirs)
+	}
+}
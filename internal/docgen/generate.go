@@ -0,0 +1,264 @@
+package docgen
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/textutil"
+)
+
+// プロンプトに添付するソースの最大サイズ
+const maxSourceBytes = 32 * 1024
+
+// FileChange は1ファイル分のドキュメント変更（適用前に差分を確認できる）
+type FileChange struct {
+	Path       string   `json:"path"`
+	Before     string   `json:"-"`
+	After      string   `json:"-"`
+	Documented []string `json:"documented"` // コメントを書いたシンボル
+}
+
+// Diff は変更の unified diff
+func (c *FileChange) Diff() string {
+	return diff.Unified("a/"+filepath.ToSlash(c.Path), "b/"+filepath.ToSlash(c.Path), c.Before, c.After, 3)
+}
+
+// Apply は変更をファイルに書き込む
+func (c *FileChange) Apply() error {
+	if err := os.WriteFile(c.Path, []byte(c.After), 0644); err != nil {
+		return fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// Generator はLLMでドキュメントコメント・READMEを生成する
+type Generator struct {
+	Provider llm.Provider
+	Model    string
+	Language string
+	Memory   string // プロジェクトメモリ（VYB.md）
+	Registry *prompts.Registry
+}
+
+// DocumentPackage は対象シンボルのコメントをファイル毎に生成し、変更（未適用）を返す
+func (g *Generator) DocumentPackage(ctx context.Context, pkg *Package, update bool, progress func(file string)) ([]*FileChange, error) {
+	byFile := make(map[string][]Symbol)
+	for _, symbol := range pkg.Targets(update) {
+		byFile[symbol.File] = append(byFile[symbol.File], symbol)
+	}
+	files := make([]string, 0, len(byFile))
+	for file := range byFile {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+
+	var changes []*FileChange
+	for _, file := range files {
+		if progress != nil {
+			progress(file)
+		}
+		change, err := g.DocumentFile(ctx, pkg, file, byFile[file])
+		if err != nil {
+			return changes, fmt.Errorf("%s: %w", file, err)
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+	return changes, nil
+}
+
+// DocumentFile は1ファイルのシンボルのコメントを生成して適用後の内容を作る（変更なしなら nil）
+func (g *Generator) DocumentFile(ctx context.Context, pkg *Package, file string, symbols []Symbol) (*FileChange, error) {
+	path := filepath.Join(pkg.Dir, file)
+	src, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ソース読み込みエラー: %w", err)
+	}
+
+	var list strings.Builder
+	for _, symbol := range symbols {
+		fmt.Fprintf(&list, "- %s (%s): %s\n", symbol.Name, symbol.Kind, textutil.FirstLineMore(symbol.Signature, " ..."))
+	}
+	source := string(src)
+	if len(source) > maxSourceBytes {
+		source = source[:maxSourceBytes]
+	}
+
+	answer, err := g.ask(ctx, prompts.TemplateDocGen, prompts.Data{
+		SessionType: "docgen",
+		Language:    g.Language,
+		ModelFamily: prompts.ModelFamily(g.Model),
+		Memory:      g.Memory,
+		CurrentFile: filepath.ToSlash(path),
+		Context:     list.String(),
+		Input:       source,
+	})
+	if err != nil {
+		return nil, err
+	}
+	comments, err := ParseComments(answer)
+	if err != nil {
+		return nil, err
+	}
+
+	after, documented, err := ApplyComments(src, symbols, comments)
+	if err != nil {
+		return nil, err
+	}
+	if len(documented) == 0 || string(after) == string(src) {
+		return nil, nil
+	}
+	return &FileChange{Path: path, Before: string(src), After: string(after), Documented: documented}, nil
+}
+
+// Readme はパッケージの README.md を生成（既存の内容があれば更新）し、変更（未適用）を返す
+func (g *Generator) Readme(ctx context.Context, pkg *Package) (*FileChange, error) {
+	path := filepath.Join(pkg.Dir, "README.md")
+	existing, _ := os.ReadFile(path)
+
+	var api strings.Builder
+	for _, symbol := range pkg.Symbols {
+		if symbol.Kind == KindPackage {
+			continue
+		}
+		fmt.Fprintf(&api, "%s\n", textutil.FirstLineMore(symbol.Signature, " ..."))
+		if symbol.Documented() {
+			fmt.Fprintf(&api, "    %s\n", textutil.FirstLineMore(symbol.Doc, " ..."))
+		}
+	}
+
+	answer, err := g.ask(ctx, prompts.TemplateReadme, prompts.Data{
+		SessionType: "docgen",
+		Language:    g.Language,
+		ModelFamily: prompts.ModelFamily(g.Model),
+		Memory:      g.Memory,
+		CurrentFile: filepath.ToSlash(pkg.Dir),
+		Context:     pkg.Symbols[0].Doc,
+		Input:       api.String(),
+		LastOutput:  string(existing),
+		TargetFile:  filepath.ToSlash(path),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	content := unwrapMarkdown(answer)
+	if content == "" || content == string(existing) {
+		return nil, nil
+	}
+	return &FileChange{Path: path, Before: string(existing), After: content, Documented: []string{"README.md"}}, nil
+}
+
+// ask はモデルに問い合わせて回答本文を返す
+func (g *Generator) ask(ctx context.Context, template string, data prompts.Data) (string, error) {
+	registry := g.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	prompt, err := registry.Render(template, data)
+	if err != nil {
+		return "", err
+	}
+
+	temperature := 0.2
+	resp, err := g.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       g.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("ドキュメント生成エラー: %w", err)
+	}
+	return resp.Message.Content, nil
+}
+
+// ParseComments はモデルの回答から {"シンボル名": "コメント"} を取り出す
+func ParseComments(answer string) (map[string]string, error) {
+	start := strings.Index(answer, "{")
+	end := strings.LastIndex(answer, "}")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("応答にコメントのJSONがありません")
+	}
+	var comments map[string]string
+	if err := json.Unmarshal([]byte(answer[start:end+1]), &comments); err != nil {
+		return nil, fmt.Errorf("コメントのJSON解析エラー: %w", err)
+	}
+	return comments, nil
+}
+
+// ApplyComments はシンボルの宣言の直前にコメントを挿入（既存コメントは置換）し、gofmt 済みの内容を返す
+func ApplyComments(src []byte, symbols []Symbol, comments map[string]string) ([]byte, []string, error) {
+	lines := strings.Split(string(src), "\n")
+
+	// 後ろから適用して行番号のずれを防ぐ
+	ordered := append([]Symbol(nil), symbols...)
+	sort.Slice(ordered, func(i, j int) bool { return ordered[i].Line > ordered[j].Line })
+
+	var documented []string
+	for _, symbol := range ordered {
+		text := strings.TrimSpace(comments[symbol.Name])
+		if text == "" || symbol.Line < 1 || symbol.Line > len(lines) {
+			continue
+		}
+		indent := leadingWhitespace(lines[symbol.Line-1])
+		commentLines := formatComment(text, indent)
+
+		start, end := symbol.Line-1, symbol.Line-1
+		if symbol.docStart > 0 {
+			start, end = symbol.docStart-1, symbol.docEnd
+		}
+		replaced := append([]string{}, lines[:start]...)
+		replaced = append(replaced, commentLines...)
+		lines = append(replaced, lines[end:]...)
+		documented = append(documented, symbol.Name)
+	}
+	sort.Strings(documented)
+
+	formatted, err := format.Source([]byte(strings.Join(lines, "\n")))
+	if err != nil {
+		return nil, nil, fmt.Errorf("コメント適用後のソースが不正です: %w", err)
+	}
+	return formatted, documented, nil
+}
+
+// formatComment はコメント本文を // 形式の行にする（既に // が付いていれば除く）
+func formatComment(text, indent string) []string {
+	var lines []string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "//"))
+		if line == "" {
+			lines = append(lines, indent+"//")
+			continue
+		}
+		lines = append(lines, indent+"// "+line)
+	}
+	return lines
+}
+
+// unwrapMarkdown は回答全体がコードブロックで囲まれていれば外す
+func unwrapMarkdown(answer string) string {
+	content := strings.TrimSpace(answer)
+	if strings.HasPrefix(content, "```") && strings.HasSuffix(content, "```") {
+		if i := strings.Index(content, "\n"); i >= 0 {
+			content = strings.TrimSpace(strings.TrimSuffix(content[i+1:], "```"))
+		}
+	}
+	if content == "" {
+		return ""
+	}
+	return content + "\n"
+}
+
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
@@ -0,0 +1,315 @@
+package docgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// シンボルの種類
+const (
+	KindPackage = "package"
+	KindFunc    = "func"
+	KindMethod  = "method"
+	KindType    = "type"
+	KindConst   = "const"
+	KindVar     = "var"
+)
+
+// プロンプトに渡すシグネチャの最大長
+const maxSignatureLen = 400
+
+// Symbol はドキュメントコメントの対象となる公開シンボル
+type Symbol struct {
+	Name      string `json:"name"` // メソッドは Type.Method
+	Kind      string `json:"kind"`
+	File      string `json:"file"` // パッケージディレクトリからの相対パス
+	Line      int    `json:"line"` // 宣言の行（コメントはこの直前に置く）
+	Signature string `json:"signature"`
+	Doc       string `json:"doc,omitempty"`
+
+	docStart int // 既存コメントの行範囲（なければ0）
+	docEnd   int
+}
+
+// Documented はドキュメントコメントがあるか
+func (s Symbol) Documented() bool {
+	return strings.TrimSpace(s.Doc) != ""
+}
+
+// Location は "file:line" 形式の位置
+func (s Symbol) Location() string {
+	return fmt.Sprintf("%s:%d", s.File, s.Line)
+}
+
+// Package はディレクトリ内のGoパッケージ（テストファイルを除く）
+type Package struct {
+	Dir     string   `json:"dir"`
+	Name    string   `json:"name"`
+	Files   []string `json:"files"`
+	Symbols []Symbol `json:"symbols"` // パッケージコメントは Kind=package のシンボル
+}
+
+// Missing はドキュメントコメントのないシンボル
+func (p *Package) Missing() []Symbol {
+	var missing []Symbol
+	for _, symbol := range p.Symbols {
+		if !symbol.Documented() {
+			missing = append(missing, symbol)
+		}
+	}
+	return missing
+}
+
+// Targets は生成対象のシンボル（update なら宣言自身の既存コメントも書き直す）
+func (p *Package) Targets(update bool) []Symbol {
+	if !update {
+		return p.Missing()
+	}
+	var targets []Symbol
+	for _, symbol := range p.Symbols {
+		if !symbol.Documented() || symbol.docStart > 0 {
+			targets = append(targets, symbol)
+		}
+	}
+	return targets
+}
+
+// Inspect はディレクトリのGoソースを解析し、公開シンボルとドキュメントコメントの有無を抽出
+func Inspect(dir string) (*Package, error) {
+	fset := token.NewFileSet()
+	filter := func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}
+	pkgs, err := parser.ParseDir(fset, dir, filter, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("Goソース解析エラー: %w", err)
+	}
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("Goのパッケージがありません: %s", dir)
+	}
+
+	// 複数ある場合（ビルドタグ違い等）はファイル数の多いパッケージ
+	var astPkg *ast.Package
+	for _, candidate := range pkgs {
+		if astPkg == nil || len(candidate.Files) > len(astPkg.Files) {
+			astPkg = candidate
+		}
+	}
+
+	pkg := &Package{Dir: dir, Name: astPkg.Name}
+	for path := range astPkg.Files {
+		pkg.Files = append(pkg.Files, filepath.Base(path))
+	}
+	sort.Strings(pkg.Files)
+
+	// 公開型（メソッドの対象を絞るため）
+	exportedTypes := make(map[string]bool)
+	for _, file := range astPkg.Files {
+		for _, decl := range file.Decls {
+			if gen, ok := decl.(*ast.GenDecl); ok && gen.Tok == token.TYPE {
+				for _, spec := range gen.Specs {
+					if ts := spec.(*ast.TypeSpec); ts.Name.IsExported() {
+						exportedTypes[ts.Name.Name] = true
+					}
+				}
+			}
+		}
+	}
+
+	var packageDoc *Symbol
+	for _, name := range pkg.Files {
+		file := astPkg.Files[filepath.Join(dir, name)]
+		if file.Doc != nil && packageDoc == nil {
+			packageDoc = &Symbol{Name: pkg.Name, Kind: KindPackage, File: name, Line: fset.Position(file.Package).Line, Doc: file.Doc.Text()}
+		}
+		pkg.Symbols = append(pkg.Symbols, fileSymbols(fset, file, name, exportedTypes)...)
+	}
+	if packageDoc == nil {
+		// doc.go があればそこに、なければ最初のファイルにパッケージコメントを置く
+		name := pkg.Files[0]
+		for _, candidate := range pkg.Files {
+			if candidate == "doc.go" {
+				name = candidate
+			}
+		}
+		file := astPkg.Files[filepath.Join(dir, name)]
+		packageDoc = &Symbol{Name: pkg.Name, Kind: KindPackage, File: name, Line: fset.Position(file.Package).Line}
+	}
+	packageDoc.Signature = "package " + pkg.Name
+	pkg.Symbols = append([]Symbol{*packageDoc}, pkg.Symbols...)
+	return pkg, nil
+}
+
+// fileSymbols は1ファイルの公開シンボルを宣言順に抽出
+func fileSymbols(fset *token.FileSet, file *ast.File, name string, exportedTypes map[string]bool) []Symbol {
+	var symbols []Symbol
+	add := func(symbolName, kind string, pos token.Pos, doc *ast.CommentGroup, node interface{}) {
+		symbol := Symbol{
+			Name:      symbolName,
+			Kind:      kind,
+			File:      name,
+			Line:      fset.Position(pos).Line,
+			Signature: signature(fset, node),
+		}
+		if doc != nil {
+			symbol.Doc = doc.Text()
+			symbol.docStart = fset.Position(doc.Pos()).Line
+			symbol.docEnd = fset.Position(doc.End()).Line
+		}
+		symbols = append(symbols, symbol)
+	}
+
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if !d.Name.IsExported() {
+				continue
+			}
+			if d.Recv == nil {
+				add(d.Name.Name, KindFunc, d.Pos(), d.Doc, d)
+				continue
+			}
+			receiver := receiverType(d.Recv.List[0].Type)
+			if exportedTypes[receiver] {
+				add(receiver+"."+d.Name.Name, KindMethod, d.Pos(), d.Doc, d)
+			}
+
+		case *ast.GenDecl:
+			if d.Tok == token.IMPORT {
+				continue
+			}
+			grouped := d.Lparen.IsValid()
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if !s.Name.IsExported() {
+						continue
+					}
+					if grouped {
+						add(s.Name.Name, KindType, s.Pos(), s.Doc, &ast.GenDecl{Tok: token.TYPE, Specs: []ast.Spec{s}})
+						inheritDoc(&symbols[len(symbols)-1], s.Comment, d.Doc)
+					} else {
+						add(s.Name.Name, KindType, d.Pos(), d.Doc, d)
+					}
+				case *ast.ValueSpec:
+					kind := KindVar
+					if d.Tok == token.CONST {
+						kind = KindConst
+					}
+					for _, ident := range s.Names {
+						if !ident.IsExported() {
+							continue
+						}
+						if grouped {
+							add(ident.Name, kind, s.Pos(), s.Doc, &ast.GenDecl{Tok: d.Tok, Specs: []ast.Spec{s}})
+							inheritDoc(&symbols[len(symbols)-1], s.Comment, d.Doc)
+						} else {
+							add(ident.Name, kind, d.Pos(), d.Doc, d)
+						}
+						break // 同じ行の複数名は1つのコメントで説明する
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// inheritDoc はグループ内の宣言に自身のコメントがなければ行末・グループのコメントで説明済みとする
+// （これらは宣言のコメントではないため書き換え対象にしない）
+func inheritDoc(symbol *Symbol, comment, group *ast.CommentGroup) {
+	if symbol.Documented() {
+		return
+	}
+	if comment != nil {
+		symbol.Doc = comment.Text()
+	} else if group != nil {
+		symbol.Doc = group.Text()
+	}
+}
+
+// receiverType はレシーバーの型名（ポインタ・型パラメーターを除く）
+func receiverType(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverType(t.X)
+	case *ast.IndexExpr:
+		return receiverType(t.X)
+	case *ast.IndexListExpr:
+		return receiverType(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// signature は本体を除いた宣言のソース（長い型定義は切り詰める）
+func signature(fset *token.FileSet, node interface{}) string {
+	if fn, ok := node.(*ast.FuncDecl); ok {
+		copied := *fn
+		copied.Body = nil
+		copied.Doc = nil
+		node = &copied
+	}
+	if gen, ok := node.(*ast.GenDecl); ok {
+		copied := *gen
+		copied.Doc = nil
+		node = &copied
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+	text := buf.String()
+	if len(text) > maxSignatureLen {
+		text = text[:maxSignatureLen] + " ..."
+	}
+	return text
+}
+
+// ExpandDirs は "dir/..." をGoソースを含むサブディレクトリに展開する（testdata・vendor・隠しディレクトリを除く）
+func ExpandDirs(pattern string) ([]string, error) {
+	root := strings.TrimSuffix(pattern, "...")
+	if root == pattern {
+		return []string{filepath.Clean(pattern)}, nil
+	}
+	root = filepath.Clean(strings.TrimSuffix(root, "/"))
+	if root == "" {
+		root = "."
+	}
+
+	var dirs []string
+	err := filepath.WalkDir(root, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != root && (name == "testdata" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		sources, _ := filepath.Glob(filepath.Join(path, "*.go"))
+		for _, source := range sources {
+			if !strings.HasSuffix(source, "_test.go") {
+				dirs = append(dirs, path)
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ディレクトリ走査エラー: %w", err)
+	}
+	return dirs, nil
+}
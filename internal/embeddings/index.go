@@ -0,0 +1,314 @@
+// Package embeddings はプロジェクトのファイルを行単位のチャンクに分けて埋め込みベクトルを生成し、
+// ディスク上のキャッシュに保存する（ファイルのハッシュが変わったものだけ再生成する）
+package embeddings
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+const (
+	// ChunkLines は1チャンクの行数
+	ChunkLines = 60
+	// maxFileSize はインデックス対象にするファイルサイズの上限
+	maxFileSize = 512 * 1024
+	// batchSize は1回の埋め込みリクエストで送るチャンク数
+	batchSize = 32
+	// indexVersion はキャッシュ形式のバージョン（変わったら全て作り直す）
+	indexVersion = 1
+)
+
+// Embedder は複数テキストの埋め込みベクトルをまとめて生成できるプロバイダー
+type Embedder interface {
+	EmbedBatch(ctx context.Context, model string, texts []string) ([][]float64, error)
+}
+
+// Chunk はファイルの一部（StartLine〜EndLine、1始まり）とその埋め込み
+type Chunk struct {
+	StartLine int       `json:"start_line"`
+	EndLine   int       `json:"end_line"`
+	Vector    []float64 `json:"vector"`
+}
+
+// FileEntry は1ファイル分のキャッシュ
+type FileEntry struct {
+	Hash   string  `json:"hash"`
+	Chunks []Chunk `json:"chunks"`
+}
+
+// Index はプロジェクト1つ・埋め込みモデル1つ分のベクトルキャッシュ
+type Index struct {
+	Version   int                   `json:"version"`
+	Root      string                `json:"root"`
+	Model     string                `json:"model"`
+	UpdatedAt time.Time             `json:"updated_at"`
+	Files     map[string]*FileEntry `json:"files"` // キーはルートからの相対パス
+
+	path string
+}
+
+// Stats は Update の結果
+type Stats struct {
+	Embedded int // 新規・変更のため埋め込みを生成したファイル数
+	Reused   int // ハッシュが同じためキャッシュを使ったファイル数
+	Removed  int // 削除されたためキャッシュから除いたファイル数
+	Chunks   int // 生成したチャンク数
+}
+
+// Result は検索結果の1件
+type Result struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Score     float64
+}
+
+// DefaultDir は埋め込みキャッシュの保存先（~/.vyb/embeddings）
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".vyb", "embeddings")
+	}
+	return filepath.Join(home, ".vyb", "embeddings")
+}
+
+// ResolveCacheDir は設定の保存先（空なら既定の保存先）
+func ResolveCacheDir(dir string) string {
+	if dir == "" {
+		return DefaultDir()
+	}
+	return dir
+}
+
+// IndexPath はプロジェクトとモデルに対応するキャッシュファイルのパス
+func IndexPath(cacheDir, root, model string) string {
+	sum := sha256.Sum256([]byte(root))
+	name := strings.NewReplacer("/", "_", ":", "_", "\\", "_").Replace(model)
+	return filepath.Join(cacheDir, fmt.Sprintf("%s-%s.json", hex.EncodeToString(sum[:8]), name))
+}
+
+// Load はキャッシュを読み込む（存在しない・形式やモデルが異なる場合は空のインデックス）
+func Load(cacheDir, root, model string) (*Index, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("プロジェクトパスの解決エラー: %w", err)
+	}
+	path := IndexPath(cacheDir, absRoot, model)
+	empty := &Index{Version: indexVersion, Root: absRoot, Model: model, Files: map[string]*FileEntry{}, path: path}
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return empty, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("埋め込みキャッシュの読み込みエラー: %w", err)
+	}
+	var idx Index
+	if err := json.Unmarshal(data, &idx); err != nil || idx.Version != indexVersion || idx.Model != model || idx.Files == nil {
+		// 壊れた・古い形式のキャッシュは作り直す
+		return empty, nil
+	}
+	idx.Root = absRoot
+	idx.path = path
+	return &idx, nil
+}
+
+// Save はキャッシュを書き込む（一時ファイルに書いてから置き換える）
+func (idx *Index) Save() error {
+	if err := os.MkdirAll(filepath.Dir(idx.path), 0755); err != nil {
+		return fmt.Errorf("埋め込みキャッシュ保存先の作成エラー: %w", err)
+	}
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("埋め込みキャッシュのJSON変換エラー: %w", err)
+	}
+	tmp := idx.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("埋め込みキャッシュの保存エラー: %w", err)
+	}
+	if err := os.Rename(tmp, idx.path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("埋め込みキャッシュの保存エラー: %w", err)
+	}
+	return nil
+}
+
+// Path はキャッシュファイルのパス
+func (idx *Index) Path() string {
+	return idx.path
+}
+
+// Update は files（ルートからの相対パス）の埋め込みを最新にする
+// 内容のハッシュが変わっていないファイルはキャッシュを使い、files にないファイルは除く
+func (idx *Index) Update(ctx context.Context, embedder Embedder, files []string) (Stats, error) {
+	var stats Stats
+	seen := make(map[string]bool, len(files))
+
+	for _, rel := range files {
+		seen[rel] = true
+		content, err := os.ReadFile(filepath.Join(idx.Root, rel))
+		if err != nil {
+			continue
+		}
+		hash := hashContent(content)
+		if entry, ok := idx.Files[rel]; ok && entry.Hash == hash {
+			stats.Reused++
+			continue
+		}
+
+		chunks, texts := splitChunks(rel, string(content))
+		for start := 0; start < len(texts); start += batchSize {
+			end := start + batchSize
+			if end > len(texts) {
+				end = len(texts)
+			}
+			vectors, err := embedder.EmbedBatch(ctx, idx.Model, texts[start:end])
+			if err != nil {
+				return stats, fmt.Errorf("%s の埋め込み生成エラー: %w", rel, err)
+			}
+			for i, vector := range vectors {
+				chunks[start+i].Vector = vector
+			}
+		}
+		idx.Files[rel] = &FileEntry{Hash: hash, Chunks: chunks}
+		stats.Embedded++
+		stats.Chunks += len(chunks)
+	}
+
+	for rel := range idx.Files {
+		if !seen[rel] {
+			delete(idx.Files, rel)
+			stats.Removed++
+		}
+	}
+	idx.UpdatedAt = time.Now()
+	return stats, nil
+}
+
+// Search は query に近いチャンクを類似度の高い順に最大 k 件返す
+func (idx *Index) Search(ctx context.Context, embedder Embedder, query string, k int) ([]Result, error) {
+	vectors, err := embedder.EmbedBatch(ctx, idx.Model, []string{query})
+	if err != nil {
+		return nil, fmt.Errorf("検索クエリの埋め込み生成エラー: %w", err)
+	}
+	queryVector := vectors[0]
+
+	var results []Result
+	for rel, entry := range idx.Files {
+		for _, chunk := range entry.Chunks {
+			results = append(results, Result{
+				Path:      rel,
+				StartLine: chunk.StartLine,
+				EndLine:   chunk.EndLine,
+				Score:     llm.CosineSimilarity(queryVector, chunk.Vector),
+			})
+		}
+	}
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Path != results[j].Path {
+			return results[i].Path < results[j].Path
+		}
+		return results[i].StartLine < results[j].StartLine
+	})
+	if k > 0 && len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// ChunkCount はキャッシュ済みのチャンク数
+func (idx *Index) ChunkCount() int {
+	count := 0
+	for _, entry := range idx.Files {
+		count += len(entry.Chunks)
+	}
+	return count
+}
+
+// CollectFiles は root 以下のインデックス対象ファイルを相対パスで返す
+// 隠しディレクトリ、除外パターン（ignore パッケージ）に一致するパス、大きなファイル、バイナリファイルは除く
+func CollectFiles(root string) ([]string, error) {
+	var files []string
+	err := ignore.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || strings.HasPrefix(name, ".") || info.Size() == 0 || info.Size() > maxFileSize {
+			return nil
+		}
+		if isBinary(path) {
+			return nil
+		}
+		rel, err := filepath.Rel(root, path)
+		if err != nil {
+			return nil
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("インデックス対象ファイルの収集エラー: %w", err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// splitChunks はファイルを ChunkLines 行毎に分割し、埋め込み用のテキスト（パスを先頭に付ける）と共に返す
+func splitChunks(rel, content string) ([]Chunk, []string) {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	var chunks []Chunk
+	var texts []string
+	for start := 0; start < len(lines); start += ChunkLines {
+		end := start + ChunkLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+		text := strings.Join(lines[start:end], "\n")
+		if strings.TrimSpace(text) == "" {
+			continue
+		}
+		chunks = append(chunks, Chunk{StartLine: start + 1, EndLine: end})
+		texts = append(texts, rel+"\n"+text)
+	}
+	return chunks, texts
+}
+
+// hashContent はファイル内容のハッシュ
+func hashContent(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// isBinary は先頭にNULバイトを含むファイルをバイナリとみなす
+func isBinary(path string) bool {
+	f, err := os.Open(path)
+	if err != nil {
+		return true
+	}
+	defer f.Close()
+	buf := make([]byte, 8000)
+	n, _ := f.Read(buf)
+	return bytes.IndexByte(buf[:n], 0) >= 0
+}
@@ -0,0 +1,132 @@
+package embeddings
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// countingEmbedder はキーワードの出現有無をベクトルにし、埋め込んだテキスト数を数える
+type countingEmbedder struct {
+	texts int
+}
+
+func (e *countingEmbedder) EmbedBatch(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	e.texts += len(texts)
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		for _, keyword := range []string{"alpha", "beta", "gamma"} {
+			if strings.Contains(text, keyword) {
+				vectors[i] = append(vectors[i], 1)
+			} else {
+				vectors[i] = append(vectors[i], 0)
+			}
+		}
+	}
+	return vectors, nil
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestIndexIncrementalUpdate(t *testing.T) {
+	root := t.TempDir()
+	cacheDir := t.TempDir()
+	writeFile(t, filepath.Join(root, "a.go"), "package a // alpha\n")
+	writeFile(t, filepath.Join(root, "b.go"), "package b // beta\n")
+	writeFile(t, filepath.Join(root, ".git", "HEAD"), "ref: main\n")
+	writeFile(t, filepath.Join(root, "node_modules", "x.js"), "gamma\n")
+	writeFile(t, filepath.Join(root, "bin.dat"), "gamma\x00\x01")
+
+	files, err := CollectFiles(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(files, ",") != "a.go,b.go" {
+		t.Fatalf("収集対象が想定外: %v", files)
+	}
+
+	embedder := &countingEmbedder{}
+	idx, err := Load(cacheDir, root, "test-model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stats, err := idx.Update(context.Background(), embedder, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Embedded != 2 || stats.Reused != 0 || embedder.texts != 2 {
+		t.Fatalf("初回は全ファイルを埋め込むべき: %+v (texts=%d)", stats, embedder.texts)
+	}
+	if err := idx.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 保存したキャッシュを読み直し、b.go だけ変更・a.go を削除
+	writeFile(t, filepath.Join(root, "b.go"), "package b // gamma\n")
+	os.Remove(filepath.Join(root, "a.go"))
+	writeFile(t, filepath.Join(root, "c.go"), "package c // alpha\n")
+	files, _ = CollectFiles(root)
+
+	idx, err = Load(cacheDir, root, "test-model")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(idx.Files) != 2 {
+		t.Fatalf("保存したキャッシュが読み込まれていない: %d files", len(idx.Files))
+	}
+	embedder.texts = 0
+	stats, err = idx.Update(context.Background(), embedder, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Embedded != 2 || stats.Removed != 1 || embedder.texts != 2 {
+		t.Fatalf("変更・追加分だけ埋め込むべき: %+v (texts=%d)", stats, embedder.texts)
+	}
+
+	embedder.texts = 0
+	stats, _ = idx.Update(context.Background(), embedder, files)
+	if stats.Reused != 2 || stats.Embedded != 0 || embedder.texts != 0 {
+		t.Fatalf("変更がなければ埋め込みを再利用すべき: %+v", stats)
+	}
+
+	results, err := idx.Search(context.Background(), embedder, "gamma", 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Path != "b.go" {
+		t.Fatalf("検索結果が想定外: %+v", results)
+	}
+
+	// モデルが異なるキャッシュは共有しない
+	other, _ := Load(cacheDir, root, "other-model")
+	if len(other.Files) != 0 {
+		t.Fatal("別モデルのキャッシュを読み込むべきではない")
+	}
+}
+
+func TestSplitChunks(t *testing.T) {
+	var lines []string
+	for i := 0; i < ChunkLines*2+5; i++ {
+		lines = append(lines, "line")
+	}
+	chunks, texts := splitChunks("x.go", strings.Join(lines, "\n")+"\n")
+	if len(chunks) != 3 || len(texts) != 3 {
+		t.Fatalf("チャンク数が想定外: %d", len(chunks))
+	}
+	if chunks[2].StartLine != ChunkLines*2+1 || chunks[2].EndLine != ChunkLines*2+5 {
+		t.Fatalf("最後のチャンクの行範囲が想定外: %+v", chunks[2])
+	}
+	if !strings.HasPrefix(texts[0], "x.go\n") {
+		t.Fatal("埋め込みテキストにパスを含めるべき")
+	}
+}
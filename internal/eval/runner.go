@@ -0,0 +1,184 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// Replayer はプロンプトの描画と、モデル応答の解析・実行を行う（interactive.Replayer が実装）
+type Replayer interface {
+	Prompt(ctx context.Context, input string) string
+	Replay(ctx context.Context, input, response string) (*interactive.ReplayResult, error)
+}
+
+// Result はシナリオ1つの評価結果
+type Result struct {
+	Name      string        `json:"name"`
+	Path      string        `json:"path,omitempty"`
+	Passed    bool          `json:"passed"`
+	Failures  []string      `json:"failures,omitempty"`
+	Error     string        `json:"error,omitempty"`      // 評価自体ができなかった理由
+	Response  string        `json:"response,omitempty"`   // live 時のモデル応答
+	ToolCalls []string      `json:"tool_calls,omitempty"` // 実行したツール呼び出し
+	Duration  time.Duration `json:"duration"`
+}
+
+// Runner はシナリオ毎に一時的な作業ディレクトリを作り、その中で応答を再生する
+type Runner struct {
+	// NewReplayer はシナリオ毎に作業ディレクトリへ移動した後で呼ばれる
+	NewReplayer func() (Replayer, error)
+	// WorkRoot は作業ディレクトリを作る場所（空なら一時ディレクトリ）
+	WorkRoot string
+
+	// Live なら記録した応答ではなく Provider の応答を再生する
+	Live     bool
+	Provider llm.Provider
+	Model    string
+}
+
+// Run はシナリオを評価する
+func (r *Runner) Run(ctx context.Context, scenario *Scenario) *Result {
+	start := time.Now()
+	result := &Result{Name: scenario.Name, Path: scenario.Path}
+	defer func() { result.Duration = time.Since(start) }()
+
+	if err := scenario.Validate(r.Live); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	if err := r.run(ctx, scenario, result); err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	result.Passed = len(result.Failures) == 0
+	return result
+}
+
+func (r *Runner) run(ctx context.Context, scenario *Scenario, result *Result) error {
+	restore, workDir, err := r.enterWorkDir(scenario)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	replayer, err := r.NewReplayer()
+	if err != nil {
+		return err
+	}
+
+	prompt := replayer.Prompt(ctx, scenario.Input)
+	for _, want := range scenario.Expect.PromptContains {
+		if !strings.Contains(prompt, want) {
+			result.fail("prompt does not contain %q", want)
+		}
+	}
+
+	response := scenario.Response
+	if r.Live {
+		chat, err := r.Provider.Chat(ctx, llm.ChatRequest{
+			Model:    r.Model,
+			Messages: []llm.ChatMessage{{Role: "user", Content: prompt}},
+		})
+		if err != nil {
+			return fmt.Errorf("モデル問い合わせエラー: %w", err)
+		}
+		response = chat.Message.Content
+		result.Response = response
+	}
+
+	replay, err := replayer.Replay(ctx, scenario.Input, response)
+	if err != nil {
+		return fmt.Errorf("応答の再生エラー: %w", err)
+	}
+	result.ToolCalls = replay.ToolCalls
+
+	if want := scenario.Expect.ToolCalls; want != nil && !equalCalls(want, replay.ToolCalls) {
+		result.fail("tool calls = %s, want %s", formatCalls(replay.ToolCalls), formatCalls(want))
+	}
+	for path, want := range scenario.Expect.Files {
+		data, err := os.ReadFile(filepath.Join(workDir, path))
+		if err != nil {
+			result.fail("file %s was not created", path)
+			continue
+		}
+		if !strings.Contains(string(data), want) {
+			result.fail("file %s does not contain %q", path, want)
+		}
+	}
+	for _, want := range scenario.Expect.ResponseContains {
+		if !strings.Contains(replay.Message, want) {
+			result.fail("response does not contain %q", want)
+		}
+	}
+	for _, unwanted := range scenario.Expect.ResponseNotContains {
+		if strings.Contains(replay.Message, unwanted) {
+			result.fail("response contains %q", unwanted)
+		}
+	}
+	return nil
+}
+
+// enterWorkDir はシナリオのファイルを置いた作業ディレクトリに移動し、元に戻して削除する関数を返す
+func (r *Runner) enterWorkDir(scenario *Scenario) (func(), string, error) {
+	workDir, err := os.MkdirTemp(r.WorkRoot, "vyb-eval-")
+	if err != nil {
+		return nil, "", fmt.Errorf("作業ディレクトリの作成エラー: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(workDir) }
+
+	for path, content := range scenario.Files {
+		target := filepath.Join(workDir, path)
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			cleanup()
+			return nil, "", fmt.Errorf("シナリオのファイル作成エラー: %w", err)
+		}
+		if err := os.WriteFile(target, []byte(content), 0644); err != nil {
+			cleanup()
+			return nil, "", fmt.Errorf("シナリオのファイル作成エラー: %w", err)
+		}
+	}
+
+	previous, err := os.Getwd()
+	if err != nil {
+		cleanup()
+		return nil, "", fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		cleanup()
+		return nil, "", fmt.Errorf("作業ディレクトリへの移動エラー: %w", err)
+	}
+	return func() {
+		os.Chdir(previous)
+		cleanup()
+	}, workDir, nil
+}
+
+func (r *Result) fail(format string, args ...interface{}) {
+	r.Failures = append(r.Failures, fmt.Sprintf(format, args...))
+}
+
+func equalCalls(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if strings.TrimSpace(a[i]) != strings.TrimSpace(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+func formatCalls(calls []string) string {
+	if len(calls) == 0 {
+		return "[]"
+	}
+	return "[" + strings.Join(calls, ", ") + "]"
+}
@@ -0,0 +1,164 @@
+// Package eval は記録したモデル応答を構造化応答の解析・実行処理に通し、
+// 期待するツール呼び出し・ファイル・応答と比べる回帰テスト（vyb eval）を提供する
+package eval
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// Scenario は .vyb/evals/<name>.yaml の評価シナリオ
+type Scenario struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Input       string            `yaml:"input" json:"input"`                     // ユーザー入力
+	Response    string            `yaml:"response,omitempty" json:"response"`     // 記録・作成したモデルの応答
+	Files       map[string]string `yaml:"files,omitempty" json:"files,omitempty"` // 実行前に作業ディレクトリに置くファイル
+	Expect      Expectation       `yaml:"expect" json:"expect"`
+
+	Path string `yaml:"-" json:"path"`
+}
+
+// Expectation はシナリオの期待結果（指定した項目だけを確認する）
+type Expectation struct {
+	// ToolCalls は実行されるツール呼び出し（"bash: go test ./..."、"write: main.go"、"read: go.mod"、"job: npm run dev"）
+	// 実行順に完全一致で比べる。空の配列はツールを実行しないことを表す
+	ToolCalls           []string          `yaml:"tool_calls,omitempty" json:"tool_calls,omitempty"`
+	Files               map[string]string `yaml:"files,omitempty" json:"files,omitempty"` // 実行後のファイルに含まれる文字列
+	ResponseContains    []string          `yaml:"response_contains,omitempty" json:"response_contains,omitempty"`
+	ResponseNotContains []string          `yaml:"response_not_contains,omitempty" json:"response_not_contains,omitempty"`
+	PromptContains      []string          `yaml:"prompt_contains,omitempty" json:"prompt_contains,omitempty"` // 描画したプロンプトに含まれる文字列
+}
+
+// Validate はシナリオの内容を検証する（live が偽なら記録した応答が必要）
+func (s *Scenario) Validate(live bool) error {
+	if strings.TrimSpace(s.Input) == "" {
+		return fmt.Errorf("シナリオ %s に input がありません", s.Name)
+	}
+	if !live && strings.TrimSpace(s.Response) == "" {
+		return fmt.Errorf("シナリオ %s に response がありません（--live でモデルに問い合わせるか --record で記録してください）", s.Name)
+	}
+	for path := range s.Files {
+		if filepath.IsAbs(path) || strings.HasPrefix(filepath.Clean(path), "..") {
+			return fmt.Errorf("シナリオ %s: files には作業ディレクトリ内の相対パスを指定してください: %s", s.Name, path)
+		}
+	}
+	return nil
+}
+
+// Dir はプロジェクトの評価シナリオのディレクトリ（.vyb/evals）
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, config.ProjectConfigDir, "evals")
+}
+
+// Parse はYAMLのシナリオを解析する（name がなければ defaultName）
+func Parse(data []byte, defaultName string) (*Scenario, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var scenario Scenario
+	if err := decoder.Decode(&scenario); err != nil {
+		return nil, fmt.Errorf("シナリオの解析エラー: %w", err)
+	}
+	if scenario.Name == "" {
+		scenario.Name = defaultName
+	}
+	return &scenario, nil
+}
+
+// Load は名前（またはYAMLファイルのパス）からシナリオを読み込む
+func Load(dir, name string) (*Scenario, error) {
+	var candidates []string
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		candidates = []string{name}
+	} else {
+		candidates = []string{filepath.Join(dir, name+".yaml"), filepath.Join(dir, name+".yml")}
+	}
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("シナリオ読み込みエラー: %w", err)
+		}
+		scenario, err := Parse(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		scenario.Path = path
+		return scenario, nil
+	}
+	return nil, fmt.Errorf("シナリオ %s が見つかりません（%s）", name, dir)
+}
+
+// List はディレクトリのシナリオをファイル名順に返す（解析できないファイルはエラーとして返す）
+func List(dir string) ([]*Scenario, map[string]error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var scenarios []*Scenario
+	invalid := make(map[string]error)
+	for _, path := range paths {
+		scenario, err := Load(dir, path)
+		if err != nil {
+			invalid[path] = err
+			continue
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, invalid
+}
+
+// SaveResponse はシナリオファイルの response だけを書き換える（他の項目・コメントは保つ）
+func SaveResponse(path, response string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("シナリオ読み込みエラー: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("シナリオの解析エラー: %w", err)
+	}
+	if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return fmt.Errorf("シナリオの形式が不正です: %s", path)
+	}
+
+	root := doc.Content[0]
+	value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: response, Style: yaml.LiteralStyle}
+	replaced := false
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == "response" {
+			root.Content[i+1] = value
+			replaced = true
+			break
+		}
+	}
+	if !replaced {
+		root.Content = append(root.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: "response"}, value)
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return fmt.Errorf("シナリオのYAML変換エラー: %w", err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		return fmt.Errorf("シナリオの保存エラー: %w", err)
+	}
+	return nil
+}
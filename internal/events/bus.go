@@ -0,0 +1,134 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// イベントの種類
+const (
+	SessionStarted    = "session.started"    // セッション開始（data: focus）
+	ToolExecuted      = "tool.executed"      // ツール実行（data: tool, success, duration_ms, error）
+	EditApplied       = "edit.applied"       // ファイル変更の適用（data: path, source）
+	ResponseGenerated = "response.generated" // 応答の生成（data: input, response）
+	TurnCompleted     = "turn.completed"     // 1回の入力の処理完了（data: duration_ms, success, needs_input, error）
+	LLMCompleted      = "llm.completed"      // LLM呼び出し（data: model, duration_ms, success, prompt_tokens, completion_tokens, ttft_ms, tokens_per_second, error）
+	SuggestionDecided = "suggestion.decided" // ユーザーによる提案の承認・拒否（data: accepted）
+)
+
+// Types は購読できるイベントの種類一覧を返す
+func Types() []string {
+	return []string{SessionStarted, ToolExecuted, EditApplied, ResponseGenerated, TurnCompleted, LLMCompleted, SuggestionDecided}
+}
+
+// Event はバスに流れる1件のイベント
+type Event struct {
+	Type      string                 `json:"type"`
+	Time      time.Time              `json:"time"`
+	SessionID string                 `json:"session_id,omitempty"`
+	Data      map[string]interface{} `json:"data,omitempty"`
+}
+
+// Handler はイベントの購読者（同期的に呼ばれるため、重い処理は自前でキューに積む）
+type Handler func(Event)
+
+type subscription struct {
+	id      int
+	pattern string
+	handler Handler
+}
+
+// Bus はイベントを購読者に配信する
+type Bus struct {
+	mu            sync.RWMutex
+	subscriptions []subscription
+	nextID        int
+}
+
+// NewBus は空のイベントバスを作成
+func NewBus() *Bus {
+	return &Bus{}
+}
+
+var defaultBus = NewBus()
+
+// Default はプロセス全体で共有するイベントバスを返す
+func Default() *Bus {
+	return defaultBus
+}
+
+// Publish は共有バスにイベントを発行する
+func Publish(eventType, sessionID string, data map[string]interface{}) {
+	defaultBus.Publish(Event{Type: eventType, SessionID: sessionID, Data: data})
+}
+
+// Subscribe はパターンに一致するイベントの購読を登録し、解除する関数を返す
+// パターンは種類そのもの、"*"（すべて）、"tool.*"（前方一致）のいずれか
+func (b *Bus) Subscribe(pattern string, handler Handler) func() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.nextID++
+	id := b.nextID
+	b.subscriptions = append(b.subscriptions, subscription{id: id, pattern: pattern, handler: handler})
+
+	return func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for i, sub := range b.subscriptions {
+			if sub.id == id {
+				b.subscriptions = append(b.subscriptions[:i:i], b.subscriptions[i+1:]...)
+				return
+			}
+		}
+	}
+}
+
+// Publish は一致する購読者に登録順で配信する（購読者のパニックは他の購読者に影響させない）
+func (b *Bus) Publish(event Event) {
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+
+	b.mu.RLock()
+	var handlers []Handler
+	for _, sub := range b.subscriptions {
+		if Match(sub.pattern, event.Type) {
+			handlers = append(handlers, sub.handler)
+		}
+	}
+	b.mu.RUnlock()
+
+	for _, handler := range handlers {
+		deliver(handler, event)
+	}
+}
+
+// HasSubscribers は購読者がいるか（イベント作成のコストを省く判定用）
+func (b *Bus) HasSubscribers() bool {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return len(b.subscriptions) > 0
+}
+
+// Match はパターンがイベントの種類に一致するか
+func Match(pattern, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+	if prefix := strings.TrimSuffix(pattern, "*"); prefix != pattern {
+		return strings.HasPrefix(eventType, prefix)
+	}
+	return false
+}
+
+func deliver(handler Handler, event Event) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "イベント購読者のエラー (%s): %v\n", event.Type, r)
+		}
+	}()
+	handler(event)
+}
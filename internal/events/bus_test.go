@@ -0,0 +1,53 @@
+package events
+
+import (
+	"testing"
+)
+
+func TestPublishAndSubscribe(t *testing.T) {
+	bus := NewBus()
+	var all, tools, edits []string
+	bus.Subscribe("*", func(e Event) { all = append(all, e.Type) })
+	unsubscribe := bus.Subscribe("tool.*", func(e Event) { tools = append(tools, e.Data["tool"].(string)) })
+	bus.Subscribe(EditApplied, func(e Event) {
+		if e.Time.IsZero() || e.SessionID != "s1" {
+			t.Errorf("時刻とセッションを設定するはず: %+v", e)
+		}
+		edits = append(edits, e.Data["path"].(string))
+	})
+	bus.Subscribe(ResponseGenerated, func(e Event) { panic("broken subscriber") })
+
+	bus.Publish(Event{Type: ToolExecuted, Data: map[string]interface{}{"tool": "bash"}})
+	bus.Publish(Event{Type: EditApplied, SessionID: "s1", Data: map[string]interface{}{"path": "main.go"}})
+	bus.Publish(Event{Type: ResponseGenerated})
+	unsubscribe()
+	bus.Publish(Event{Type: ToolExecuted, Data: map[string]interface{}{"tool": "read"}})
+
+	if len(all) != 4 {
+		t.Errorf("パニックした購読者がいても配信を続けるはず: %v", all)
+	}
+	if len(tools) != 1 || tools[0] != "bash" {
+		t.Errorf("解除後は配信しないはず: %v", tools)
+	}
+	if len(edits) != 1 || edits[0] != "main.go" {
+		t.Errorf("種類が一致するイベントのみ: %v", edits)
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		pattern, eventType string
+		want               bool
+	}{
+		{"*", SessionStarted, true},
+		{SessionStarted, SessionStarted, true},
+		{"session.*", SessionStarted, true},
+		{"session.*", ToolExecuted, false},
+		{ToolExecuted, EditApplied, false},
+	}
+	for _, tt := range tests {
+		if got := Match(tt.pattern, tt.eventType); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v", tt.pattern, tt.eventType, got)
+		}
+	}
+}
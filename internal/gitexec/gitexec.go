@@ -0,0 +1,39 @@
+// Package gitexec はリポジトリで git コマンドを実行する共通の処理
+package gitexec
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Run は dir で git コマンドを実行して標準出力を返す
+// 失敗した場合は git の標準エラー出力（なければ実行時のエラー）をエラーにする
+func Run(ctx context.Context, dir string, args ...string) (string, error) {
+	return RunInput(ctx, dir, nil, "", args...)
+}
+
+// RunInput は環境変数（現在の環境に追加）と標準入力を指定して Run と同様に実行する
+func RunInput(ctx context.Context, dir string, env []string, input string, args ...string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	if len(env) > 0 {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if input != "" {
+		cmd.Stdin = strings.NewReader(input)
+	}
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git %s エラー: %s", args[0], message)
+		}
+		return "", fmt.Errorf("git %s エラー: %w", args[0], err)
+	}
+	return string(output), nil
+}
@@ -0,0 +1,39 @@
+package gitexec
+
+import (
+	"context"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	if _, err := Run(context.Background(), dir, "init", "-q"); err != nil {
+		t.Fatalf("git init failed: %v", err)
+	}
+	out, err := Run(context.Background(), dir, "rev-parse", "--is-inside-work-tree")
+	if err != nil || strings.TrimSpace(out) != "true" {
+		t.Errorf("unexpected output %q: %v", out, err)
+	}
+
+	// 失敗時は git の標準エラー出力をエラーに含める
+	_, err = Run(context.Background(), dir, "rev-parse", "--verify", "no-such-ref")
+	if err == nil || !strings.Contains(err.Error(), "git rev-parse エラー: fatal:") {
+		t.Errorf("expected stderr in error, got: %v", err)
+	}
+}
+
+func TestRunInput(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	out, err := RunInput(context.Background(), dir, []string{"GIT_DIR=" + dir + "/objects-only"}, "hello\n", "hash-object", "--stdin")
+	if err != nil || strings.TrimSpace(out) != "ce013625030ba8dba906f756967f9e9ca394464a" {
+		t.Errorf("unexpected output %q: %v", out, err)
+	}
+}
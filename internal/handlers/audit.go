@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// AuditHandler は監査ログ閲覧のハンドラー
+type AuditHandler struct {
+	log logger.Logger
+	dir string
+}
+
+// NewAuditHandler は監査ハンドラーの新しいインスタンスを作成（dirが空なら ~/.vyb/logs）
+func NewAuditHandler(log logger.Logger, dir string) *AuditHandler {
+	if dir == "" {
+		dir = logger.DefaultAuditDir()
+	}
+	return &AuditHandler{log: log, dir: dir}
+}
+
+// ListSessions は監査ログのあるセッション一覧を表示
+func (h *AuditHandler) ListSessions() error {
+	sessions, err := logger.ListAuditSessions(h.dir)
+	if err != nil {
+		return fmt.Errorf("監査ログ一覧取得エラー: %w", err)
+	}
+
+	fmt.Printf("Audit log directory: %s\n", h.dir)
+	if len(sessions) == 0 {
+		fmt.Println("  (no sessions recorded)")
+		return nil
+	}
+	for _, session := range sessions {
+		fmt.Printf("  %-36s %s  %s\n", session.SessionID, session.Modified.Format("2006-01-02 15:04:05"), formatBytes(session.Size))
+	}
+	return nil
+}
+
+// ShowSession はセッションの操作履歴をタイムラインとして再構成して表示
+func (h *AuditHandler) ShowSession(sessionID string, asJSON, full bool) error {
+	if sessionID == "latest" {
+		sessions, err := logger.ListAuditSessions(h.dir)
+		if err != nil {
+			return fmt.Errorf("監査ログ一覧取得エラー: %w", err)
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("監査ログがありません: %s", h.dir)
+		}
+		sessionID = sessions[0].SessionID
+	}
+
+	events, err := logger.ReadAuditLog(h.dir, sessionID)
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(events)
+	}
+
+	fmt.Printf("Session: %s (%d events)\n\n", sessionID, len(events))
+	for _, event := range events {
+		fmt.Println(formatAuditEvent(event, full))
+	}
+
+	fmt.Println()
+	fmt.Println(summarizeAuditEvents(events))
+	return nil
+}
+
+// formatAuditEvent はイベントを1行（--fullなら本文付き）に整形
+func formatAuditEvent(event logger.AuditEvent, full bool) string {
+	status := "✓"
+	if !event.Success {
+		status = "✗"
+	}
+
+	var detail string
+	switch event.Type {
+	case logger.AuditLLMRequest:
+		detail = fmt.Sprintf("💬 LLM request  %s (%s)", event.Model, formatBytes(int64(event.Bytes)))
+	case logger.AuditLLMResponse:
+		detail = fmt.Sprintf("🤖 LLM response %s %dms tokens=%d/%d", event.Model, event.LatencyMs, event.PromptTokens, event.CompletionTokens)
+	case logger.AuditCommand:
+		detail = fmt.Sprintf("⚡ command      %s (%dms)", event.Command, event.LatencyMs)
+	case logger.AuditFileWrite:
+		detail = fmt.Sprintf("📝 %-12s %s", event.Tool, event.Path)
+		if event.Bytes > 0 {
+			detail += fmt.Sprintf(" (%s)", formatBytes(int64(event.Bytes)))
+		}
+	case logger.AuditToolCall:
+		detail = fmt.Sprintf("🔧 tool         %s (%dms)", event.Tool, event.LatencyMs)
+		if event.Path != "" {
+			detail += " " + event.Path
+		}
+	case logger.AuditUserInput:
+		detail = fmt.Sprintf("👤 user         %s", truncateRunes(event.Content, 60))
+	case logger.AuditAssistant:
+		detail = fmt.Sprintf("🤖 assistant    %s", formatBytes(int64(len(event.Content))))
+	case logger.AuditDiff:
+		added, removed := countDiffLines(event.Content)
+		detail = fmt.Sprintf("📝 diff         +%d -%d", added, removed)
+	case logger.AuditBranch:
+		detail = fmt.Sprintf("🌿 branch       %s", event.Content)
+	case logger.AuditTask:
+		detail = fmt.Sprintf("⚙ %-13s %s exit=%d (%dms)", event.Tool, event.Command, event.ExitCode, event.LatencyMs)
+	case logger.AuditRedaction:
+		detail = fmt.Sprintf("🔒 redaction    %s secrets hidden before sending to %s", event.Metadata["kinds"], event.Model)
+	case logger.AuditCodeCheck:
+		status := "passed"
+		if !event.Success {
+			status = "failed: " + event.Error
+		}
+		detail = fmt.Sprintf("🧪 code check   round %s %s", event.Metadata["round"], status)
+	default:
+		detail = event.Type
+	}
+
+	line := fmt.Sprintf("%s %s %s", event.Timestamp.Format("15:04:05"), status, detail)
+	if event.Error != "" {
+		line += "\n           error: " + event.Error
+	}
+	if full && event.Content != "" {
+		line += "\n" + indentLines(event.Content, "           ")
+	}
+	return line
+}
+
+// summarizeAuditEvents はセッション全体の集計を作成
+func summarizeAuditEvents(events []logger.AuditEvent) string {
+	var llmCalls, promptTokens, completionTokens, commands, failedCommands int
+	var llmLatency int64
+	files := make(map[string]bool)
+	var first, last time.Time
+
+	for _, event := range events {
+		if first.IsZero() || event.Timestamp.Before(first) {
+			first = event.Timestamp
+		}
+		if event.Timestamp.After(last) {
+			last = event.Timestamp
+		}
+
+		switch event.Type {
+		case logger.AuditLLMResponse:
+			llmCalls++
+			promptTokens += event.PromptTokens
+			completionTokens += event.CompletionTokens
+			llmLatency += event.LatencyMs
+		case logger.AuditCommand, logger.AuditTask:
+			commands++
+			if !event.Success {
+				failedCommands++
+			}
+		case logger.AuditFileWrite:
+			if event.Success {
+				files[event.Path] = true
+			}
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("Summary:\n")
+	sb.WriteString(fmt.Sprintf("  Duration:      %s\n", last.Sub(first).Round(time.Second)))
+	sb.WriteString(fmt.Sprintf("  LLM calls:     %d (%dms total)\n", llmCalls, llmLatency))
+	sb.WriteString(fmt.Sprintf("  Tokens:        %d prompt / %d completion\n", promptTokens, completionTokens))
+	sb.WriteString(fmt.Sprintf("  Commands:      %d (%d failed)\n", commands, failedCommands))
+	sb.WriteString(fmt.Sprintf("  Files written: %d", len(files)))
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		sb.WriteString("\n    - " + path)
+	}
+	return sb.String()
+}
+
+// countDiffLines はunified diffの追加・削除行数
+func countDiffLines(patch string) (added, removed int) {
+	for _, line := range strings.Split(patch, "\n") {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+		case strings.HasPrefix(line, "+"):
+			added++
+		case strings.HasPrefix(line, "-"):
+			removed++
+		}
+	}
+	return added, removed
+}
+
+// indentLines は各行にインデントを付与
+func indentLines(text, indent string) string {
+	lines := strings.Split(strings.TrimRight(text, "\n"), "\n")
+	for i, line := range lines {
+		lines[i] = indent + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// formatBytes はバイト数を読みやすい単位で表示
+func formatBytes(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
+
+// CreateAuditCommands は監査ログ関連のコマンドを作成
+func (h *AuditHandler) CreateAuditCommands() *cobra.Command {
+	auditCmd := &cobra.Command{
+		Use:   "audit [session|latest]",
+		Short: "Show what the agent did in a session",
+		Long:  `Reconstruct a session from its audit trail in ~/.vyb/logs/<session>.jsonl: LLM requests and responses (with token counts and latency), executed commands, file writes and build/test runs. Without arguments, lists recorded sessions.`,
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				return h.ListSessions()
+			}
+			asJSON, _ := cmd.Flags().GetBool("json")
+			full, _ := cmd.Flags().GetBool("full")
+			return h.ShowSession(args[0], asJSON, full)
+		},
+	}
+	auditCmd.Flags().Bool("json", false, "Print raw events as JSON")
+	auditCmd.Flags().Bool("full", false, "Include prompts, responses and command output")
+
+	return auditCmd
+}
@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/batch"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/spf13/cobra"
+)
+
+// BatchHandler はプロンプトの一括実行（vyb batch）のハンドラー
+type BatchHandler struct {
+	log logger.Logger
+}
+
+// NewBatchHandler はバッチハンドラーを作成
+func NewBatchHandler(log logger.Logger) *BatchHandler {
+	return &BatchHandler{log: log}
+}
+
+// BatchOptions は batch の指定内容
+type BatchOptions struct {
+	Profile   string
+	Parallel  int
+	Only      []string
+	OutputDir string
+	DryRun    bool
+	JSON      bool
+}
+
+// Run はバッチ定義の各項目を専用の worktree で `vyb run` し、項目ごとのブランチ・パッチと要約を残す
+func (h *BatchHandler) Run(path string, opts BatchOptions) error {
+	def, err := batch.Load(path)
+	if err != nil {
+		return err
+	}
+	items, err := def.Select(opts.Only)
+	if err != nil {
+		return err
+	}
+	if opts.DryRun {
+		return printBatchPlan(def, items)
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	if status, err := exec.Command("git", "-C", projectDir, "status", "--porcelain", "--untracked-files=no").Output(); err != nil {
+		return fmt.Errorf("git リポジトリではありません: %w", err)
+	} else if len(bytes.TrimSpace(status)) > 0 && !opts.JSON {
+		fmt.Fprintf(os.Stderr, "\033[38;5;214m⚠️  未コミットの変更は各項目に含まれません（起点: %s）\033[0m\n", def.Base)
+	}
+	vybPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("vyb の実行ファイルが見つかりません: %w", err)
+	}
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return fmt.Errorf("実行環境の初期化エラー: %w", err)
+	}
+
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(projectDir, config.ProjectConfigDir, "batch", def.Slug()+"-"+time.Now().Format("20060102-150405"))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var printMu sync.Mutex
+	runner := &batch.Runner{
+		ProjectDir: projectDir,
+		OutputDir:  outputDir,
+		Parallel:   opts.Parallel,
+		Agent:      batchAgent(vybPath, opts.Profile),
+		Check:      batchCheck(backend),
+	}
+	if !opts.JSON {
+		parallel := opts.Parallel
+		if parallel <= 0 {
+			parallel = def.Parallel
+		}
+		fmt.Printf("▶️  %s: %d 件（同時実行 %d）\n", def.Name, len(items), parallel)
+		runner.OnStart = func(item batch.Item) {
+			printMu.Lock()
+			defer printMu.Unlock()
+			fmt.Printf("  … %s\n", item.ID)
+		}
+		runner.OnDone = func(result *batch.ItemResult) {
+			printMu.Lock()
+			defer printMu.Unlock()
+			printBatchItem(result)
+		}
+	}
+
+	report, err := runner.Run(ctx, def, items)
+	if report == nil {
+		return err
+	}
+	h.log.Info("Batch completed", map[string]interface{}{
+		"batch":   def.Name,
+		"items":   len(report.Items),
+		"changed": report.Count(batch.StatusChanged),
+		"failed":  report.Count(batch.StatusFailed) + report.Count(batch.StatusCheckFailed),
+	})
+
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printBatchSummary(report, outputDir)
+	}
+	if err != nil {
+		return err
+	}
+	if failed := report.Count(batch.StatusFailed) + report.Count(batch.StatusCheckFailed); failed > 0 {
+		return fmt.Errorf("%d 件の項目が失敗しました", failed)
+	}
+	return nil
+}
+
+// batchAgent は worktree で `vyb run --output json` を実行するエージェント
+// 作業ディレクトリはプロセス全体で共有されるため、並列実行できるよう項目ごとに別プロセスで動かす
+func batchAgent(vybPath, profile string) batch.AgentFunc {
+	return func(ctx context.Context, dir, prompt string, log io.Writer) (*batch.AgentResult, error) {
+		args := []string{"run", "--output", HeadlessOutputJSON}
+		if profile != "" {
+			args = append(args, "--profile", profile)
+		}
+		cmd := exec.CommandContext(ctx, vybPath, append(args, prompt)...)
+		cmd.Dir = dir
+		cmd.Stderr = log
+		var stdout bytes.Buffer
+		cmd.Stdout = &stdout
+		runErr := cmd.Run()
+		io.Copy(log, bytes.NewReader(stdout.Bytes()))
+
+		var result HeadlessResult
+		if err := json.Unmarshal(stdout.Bytes(), &result); err != nil {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("制限時間を超えたか中断されました: %w", ctx.Err())
+			}
+			if runErr != nil {
+				return nil, fmt.Errorf("vyb run エラー: %w", runErr)
+			}
+			return nil, fmt.Errorf("vyb run の結果を解析できません: %w", err)
+		}
+		agentResult := &batch.AgentResult{Message: result.Result, ToolCalls: len(result.ToolCalls)}
+		if result.IsError {
+			return agentResult, fmt.Errorf("%s", result.Error)
+		}
+		return agentResult, nil
+	}
+}
+
+// batchCheck は build・test・lint なら検出したビルドシステムのコマンド、それ以外はそのままコマンドとして実行する
+func batchCheck(backend sandbox.Backend) batch.CheckFunc {
+	return func(ctx context.Context, dir, check string) (bool, string, error) {
+		var result *tasks.Result
+		var err error
+		switch kind := tasks.Kind(check); kind {
+		case tasks.KindBuild, tasks.KindTest, tasks.KindLint:
+			runner, runnerErr := tasks.NewRunner(dir, backend)
+			if runnerErr != nil {
+				return false, "", runnerErr
+			}
+			result, err = runner.Run(ctx, kind)
+		default:
+			runner := tasks.NewRunnerWithSystem(dir, backend, &tasks.BuildSystem{Name: "batch"})
+			result, err = runner.RunCommand(ctx, tasks.KindTest, check)
+		}
+		if err != nil {
+			return false, "", err
+		}
+		if result.Success {
+			return true, "", nil
+		}
+		return false, tasks.FormatFailures(result), nil
+	}
+}
+
+// printBatchPlan は --dry-run で各項目のブランチとプロンプトを表示する
+func printBatchPlan(def *batch.Definition, items []batch.Item) error {
+	fmt.Printf("%s: %d 件（起点 %s、同時実行 %d）\n", def.Name, len(items), def.Base, def.Parallel)
+	if def.Check != "" {
+		fmt.Printf("チェック: %s\n", def.Check)
+	}
+	for _, item := range items {
+		prompt, err := def.PromptFor(item)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n\033[1m%s\033[0m → %s\n  %s\n", item.ID, def.Branch(item), strings.ReplaceAll(strings.TrimSpace(prompt), "\n", "\n  "))
+	}
+	return nil
+}
+
+// printBatchItem は完了した項目を1行で表示する
+func printBatchItem(result *batch.ItemResult) {
+	elapsed := (time.Duration(result.DurationMs) * time.Millisecond).Round(time.Second)
+	switch result.Status {
+	case batch.StatusChanged:
+		fmt.Printf("  \033[38;5;46m✓\033[0m %s: %d ファイル +%d -%d (%s)\n", result.ID, len(result.Files), result.Additions, result.Deletions, elapsed)
+	case batch.StatusCheckFailed:
+		fmt.Printf("  \033[38;5;214m!\033[0m %s: チェック失敗、変更は %s に保存 (%s)\n", result.ID, result.Branch, elapsed)
+	case batch.StatusNoChanges:
+		fmt.Printf("  \033[38;5;245m-\033[0m %s: 変更なし (%s)\n", result.ID, elapsed)
+	default:
+		fmt.Printf("  \033[38;5;196m✗\033[0m %s: %s (%s)\n", result.ID, truncateLine(result.Error, 100), elapsed)
+	}
+}
+
+// printBatchSummary は全項目の結果の表と保存先を表示する
+func printBatchSummary(report *batch.Report, outputDir string) {
+	fmt.Printf("\n%-32s %-13s %-40s %s\n", "ITEM", "STATUS", "BRANCH", "CHANGES")
+	for _, item := range report.Items {
+		changes := ""
+		if len(item.Files) > 0 {
+			changes = fmt.Sprintf("%d files +%d -%d", len(item.Files), item.Additions, item.Deletions)
+		}
+		fmt.Printf("%-32s %-13s %-40s %s\n", item.ID, item.Status, item.Branch, changes)
+	}
+	fmt.Printf("\n変更 %d・変更なし %d・チェック失敗 %d・失敗 %d（%s）\n",
+		report.Count(batch.StatusChanged), report.Count(batch.StatusNoChanges),
+		report.Count(batch.StatusCheckFailed), report.Count(batch.StatusFailed),
+		(time.Duration(report.DurationMs) * time.Millisecond).Round(time.Second))
+	fmt.Printf("パッチ・ログ・レポート: %s\n", outputDir)
+}
+
+// CreateBatchCommand はbatchコマンドを作成
+func (h *BatchHandler) CreateBatchCommand() *cobra.Command {
+	batchCmd := &cobra.Command{
+		Use:   "batch <prompts.yaml>",
+		Short: "Run a list of independent prompts, one branch and patch per item",
+		Long: `Run each item of a batch file as its own agent turn in an isolated git worktree, sequentially or with bounded parallelism.
+Every item starts from the same base commit; items with changes are committed to <branch_prefix>/<id> and exported as <id>.patch,
+items without changes leave no branch, and a summary table plus report.json are written to .vyb/batch/<name>-<timestamp>.
+
+  name: flaky-tests           # default: file name
+  prompt: "Fix the flaky test: {{.item}}"
+  parallel: 2                 # items run at the same time
+  check: test                 # build, test, lint or any shell command, run after each item
+  timeout: 15m                # per item
+  base: HEAD                  # branches start here
+  items:
+    - TestUpload in pkg/storage
+    - id: docs
+      prompt: Update the README for the new flags`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts BatchOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Parallel, _ = cmd.Flags().GetInt("parallel")
+			opts.Only, _ = cmd.Flags().GetStringSlice("only")
+			opts.OutputDir, _ = cmd.Flags().GetString("output-dir")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Run(args[0], opts)
+		},
+	}
+	batchCmd.Flags().IntP("parallel", "p", 0, "Number of items to run at the same time (default: parallel in the file, or 1)")
+	batchCmd.Flags().StringSlice("only", nil, "Run only the items with these IDs")
+	batchCmd.Flags().String("output-dir", "", "Directory for patches, logs and report.json (default: .vyb/batch/<name>-<timestamp>)")
+	batchCmd.Flags().Bool("dry-run", false, "Show the branch and prompt of each item without running them")
+	batchCmd.Flags().Bool("json", false, "Output the report as JSON")
+	return batchCmd
+}
@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/bench"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// BenchHandler はモデルの速度・応答品質のベンチマークのハンドラー
+type BenchHandler struct {
+	log logger.Logger
+}
+
+// NewBenchHandler はベンチマークハンドラーを作成
+func NewBenchHandler(log logger.Logger) *BenchHandler {
+	return &BenchHandler{log: log}
+}
+
+// BenchOptions は vyb bench の指定内容
+type BenchOptions struct {
+	Profile  string
+	Models   []string // 空なら設定中のモデル
+	Tasks    []string // 空なら全ての課題
+	Repeat   int
+	NoWarmup bool
+	NoSave   bool
+	JSON     bool
+}
+
+// Run は課題を各モデルで実行し、結果を表示・保存する
+func (h *BenchHandler) Run(ctx context.Context, opts BenchOptions) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	tasks, err := bench.SelectTasks(bench.DefaultTasks(), opts.Tasks)
+	if err != nil {
+		return err
+	}
+	models := opts.Models
+	if len(models) == 0 {
+		models = []string{cfg.ResolvedModel()}
+	}
+
+	runner := &bench.Runner{
+		Provider: llm.NewOllamaClient(cfg.BaseURL),
+		Tasks:    tasks,
+		Repeat:   opts.Repeat,
+		Warmup:   !opts.NoWarmup,
+	}
+	if !opts.JSON {
+		fmt.Printf("Benchmarking %d model(s) with %d task(s) on %s (%s)\n\n", len(models), len(tasks), cfg.Provider, cfg.BaseURL)
+		runner.OnResult = printBenchResult
+	}
+
+	h.log.Info("ベンチマーク開始", map[string]interface{}{"models": models, "tasks": len(tasks)})
+	run, runErr := runner.Run(ctx, models)
+	run.Host, _ = os.Hostname()
+	run.Provider = cfg.Provider
+	run.BaseURL = cfg.BaseURL
+	if runErr == nil && len(run.Models) == 0 {
+		runErr = fmt.Errorf("どのモデルも読み込めませんでした（vyb models list で確認してください）")
+	}
+
+	// 中断された場合も途中までの結果は保存する
+	savedPath := ""
+	if !opts.NoSave && len(run.Models) > 0 {
+		if savedPath, err = bench.NewStore(bench.DefaultDir()).Save(run); err != nil {
+			h.log.Warn("ベンチマーク結果の保存に失敗", map[string]interface{}{"error": err.Error()})
+		}
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(run); err != nil {
+			return err
+		}
+		return runErr
+	}
+
+	if len(run.Models) > 0 {
+		fmt.Printf("\n%s", bench.FormatSummary(run.Models))
+	}
+	if savedPath != "" {
+		fmt.Printf("\n\033[38;5;244mSaved to %s (compare with: vyb bench compare)\033[0m\n", savedPath)
+	}
+	return runErr
+}
+
+// printBenchResult は計測の度に1行で結果を表示
+func printBenchResult(result bench.Result) {
+	if result.Error != "" {
+		fmt.Printf("  \033[38;5;196m✗\033[0m %-24s %-16s %s\n", result.Model, result.Task, result.Error)
+		return
+	}
+	estimated := ""
+	if result.TokensEstimated {
+		estimated = "~"
+	}
+	fmt.Printf("  \033[38;5;46m✓\033[0m %-24s %-16s ttft %6dms  %s%6.1f tok/s  score %3.0f%%%s\n",
+		result.Model, result.Task, result.TTFTMs, estimated, result.TokensPerSecond, result.Score*100, failedChecks(result.Checks))
+}
+
+// failedChecks は不合格の確認項目の一覧（全て合格なら空）
+func failedChecks(checks []bench.Check) string {
+	var failed []string
+	for _, check := range checks {
+		if !check.Passed {
+			failed = append(failed, check.Name)
+		}
+	}
+	if len(failed) == 0 {
+		return ""
+	}
+	return "  \033[38;5;244m(failed: " + strings.Join(failed, ", ") + ")\033[0m"
+}
+
+// History は保存済みの実行を新しい順に表示
+func (h *BenchHandler) History(asJSON bool) error {
+	runs, err := bench.NewStore(bench.DefaultDir()).List()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if runs == nil {
+			runs = []*bench.Run{}
+		}
+		return encoder.Encode(runs)
+	}
+	if len(runs) == 0 {
+		fmt.Println("No benchmark runs yet. Run: vyb bench")
+		return nil
+	}
+	for _, run := range runs {
+		fmt.Printf("\033[38;5;27m%s\033[0m  %s  %s (%s)  %d task(s) x%d\n",
+			run.ID, run.StartedAt.Format("2006-01-02 15:04"), run.Provider, run.Host, len(run.Tasks), run.Repeat)
+		fmt.Print(bench.FormatSummary(run.Models))
+		fmt.Println()
+	}
+	return nil
+}
+
+// Compare は保存済みの実行からモデル毎に直近の結果を並べて表示
+func (h *BenchHandler) Compare(asJSON bool) error {
+	runs, err := bench.NewStore(bench.DefaultDir()).List()
+	if err != nil {
+		return err
+	}
+	comparisons := bench.Compare(runs)
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(comparisons)
+	}
+	if len(comparisons) == 0 {
+		fmt.Println("No benchmark results to compare. Run: vyb bench --model <a> --model <b>")
+		return nil
+	}
+	summaries := make([]bench.ModelSummary, 0, len(comparisons))
+	for _, comparison := range comparisons {
+		summaries = append(summaries, comparison.ModelSummary)
+	}
+	fmt.Print(bench.FormatSummary(summaries))
+	fmt.Printf("\n\033[38;5;244mLatest run per model, best score first.\033[0m\n")
+	return nil
+}
+
+// CreateBenchCommands は bench コマンドを作成
+func (h *BenchHandler) CreateBenchCommands() *cobra.Command {
+	benchCmd := &cobra.Command{
+		Use:   "bench",
+		Short: "Benchmark models for latency, throughput and answer quality",
+		Long: `Run a fixed set of coding prompts (write a function, fix a bug, write a test, refactor, explain, shell) against one or more models and measure time-to-first-token, tokens/sec and simple quality checks (code block present, Go code parses, expected terms mentioned, answer length).
+A warm-up request loads each model first and is reported as LOAD. Results are saved under ~/.vyb/bench so models can be compared with "vyb bench compare" before choosing one for vibe sessions.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts BenchOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Models, _ = cmd.Flags().GetStringArray("model")
+			opts.Tasks, _ = cmd.Flags().GetStringArray("task")
+			opts.Repeat, _ = cmd.Flags().GetInt("repeat")
+			opts.NoWarmup, _ = cmd.Flags().GetBool("no-warmup")
+			opts.NoSave, _ = cmd.Flags().GetBool("no-save")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			if listTasks, _ := cmd.Flags().GetBool("list-tasks"); listTasks {
+				for _, task := range bench.DefaultTasks() {
+					fmt.Printf("  %-16s %-12s %s\n", task.Name, task.Category, truncateRunes(task.Prompt, 70))
+				}
+				return nil
+			}
+			cmd.SilenceUsage = true
+			return h.Run(cmd.Context(), opts)
+		},
+	}
+	benchCmd.Flags().StringArray("model", nil, "Model to benchmark (repeatable, default: the configured model)")
+	benchCmd.Flags().StringArray("task", nil, "Run only this task (repeatable, see --list-tasks)")
+	benchCmd.Flags().Int("repeat", 1, "Runs per task")
+	benchCmd.Flags().Bool("no-warmup", false, "Skip the warm-up request (LOAD then includes nothing)")
+	benchCmd.Flags().Bool("no-save", false, "Do not save the results")
+	benchCmd.Flags().Bool("json", false, "Output as JSON")
+	benchCmd.Flags().Bool("list-tasks", false, "List the benchmark tasks")
+
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Show saved benchmark runs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.History(asJSON)
+		},
+	}
+	historyCmd.Flags().Bool("json", false, "Output as JSON")
+
+	compareCmd := &cobra.Command{
+		Use:   "compare",
+		Short: "Compare the latest saved result of each model",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.Compare(asJSON)
+		},
+	}
+	compareCmd.Flags().Bool("json", false, "Output as JSON")
+
+	benchCmd.AddCommand(historyCmd, compareCmd)
+	return benchCmd
+}
@@ -6,21 +6,37 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/glkt/vyb-code/internal/ai"
+	"github.com/glkt/vyb-code/internal/checkpoint"
 	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/history"
+	"github.com/glkt/vyb-code/internal/i18n"
 	"github.com/glkt/vyb-code/internal/input"
 	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/jobs"
 	"github.com/glkt/vyb-code/internal/llm"
 	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/notify"
 	"github.com/glkt/vyb-code/internal/performance"
+	"github.com/glkt/vyb-code/internal/remote"
+	"github.com/glkt/vyb-code/internal/scheduler"
 	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/storage"
 	"github.com/glkt/vyb-code/internal/streaming"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/telemetry"
+	"github.com/glkt/vyb-code/internal/templates"
 	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/transcript"
+	"github.com/glkt/vyb-code/internal/usage"
 )
 
 // ChatHandler はチャット機能のハンドラー（統合システム）
@@ -31,6 +47,26 @@ type ChatHandler struct {
 	streamingManager   *streaming.Manager           // ストリーミング表示管理
 	completer          *input.AdvancedCompleter     // 高度な補完機能
 	perfMonitor        *performance.RealtimeMonitor // パフォーマンス監視
+	usageTracker       *usage.Tracker               // トークン使用量・コスト集計
+	usageCurrency      string                       // コスト表示の通貨
+	pendingImages      []string                     // 次のメッセージに添付する画像（base64）
+	pendingPastes      []input.Paste                // 入力行に貼り付けた添付（目印が残っていれば次のメッセージに添付）
+	folded             *foldedOutput                // 最後に折り畳んだコマンド出力（/expand で展開）
+	inTUI              bool                         // ペイン構成のTUIで実行中（出力の折り畳みはTUIが行う）
+	historyDir         string                       // 過去セッションの検索・再開に使う監査ログの場所
+	resilientProvider  *llm.ResilientProvider       // エンドポイントの再試行・フェイルオーバー（/info で状態表示）
+	cfg                *config.Config               // /info で表示する解決済みの設定
+	exporters          *performance.Exporters       // メトリクス・トレースの外部出力（無効なら nil）
+	notifier           *notify.Notifier             // 長いターンの完了通知（無効なら nil）
+	telemetry          *telemetry.Recorder          // ローカル専用の利用統計の記録（無効なら nil）
+	workDirFollowers   []func(dir string)           // /workspace で作業ディレクトリが変わった時の通知先
+	remote             *remote.Client               // ツール層を置き換えたリモートエージェント（--remote、無ければ nil）
+	scheduler          *scheduler.Scheduler         // LLM・ツール・分析の同時実行数の制限（/info で状態表示）
+	offline            bool                         // LLMに到達できず、ローカルの操作のみで動作中
+	queuedPrompts      []string                     // オフライン中に保留した入力（/queue run で送る）
+	template           *templates.Template          // 開始時に適用するセッションテンプレート（--template、無ければ nil）
+	startPins          []string                     // 開始時に固定するファイル（vyb chat <file...>）
+	store              storage.Store                // セッション・メトリクス・会話・応答キャッシュの保存先（終了時に閉じる）
 }
 
 // NewChatHandler はチャットハンドラーを作成
@@ -40,6 +76,11 @@ func NewChatHandler(log logger.Logger, cfg *config.Config) *ChatHandler {
 	streamConfig.TokenDelay = 25 * time.Millisecond     // 少し遅めで読みやすく
 	streamConfig.SentenceDelay = 150 * time.Millisecond // 文末でより長い間隔
 	streamConfig.EnableStreaming = true                 // 必ず有効
+	if cfg != nil {
+		streamConfig.SyntaxTheme = cfg.ResolvedSyntaxTheme()
+		streamConfig.Fold.Threshold = cfg.TUI.FoldLines
+		streamConfig.Fold.PageLines = cfg.TUI.PageLines
+	}
 
 	// 作業ディレクトリを取得
 	workDir, _ := os.Getwd()
@@ -64,19 +105,139 @@ func NewChatHandlerWithMigration(log logger.Logger, migrationConfig *config.Grad
 	return NewChatHandler(log, nil)
 }
 
+// llmEndpoints はプライマリと設定された代替エンドポイントを順に並べる（空の項目はプライマリの値を使用）
+func llmEndpoints(cfg *config.Config) []llm.Endpoint {
+	endpoints := []llm.Endpoint{{
+		Name:     fmt.Sprintf("%s@%s", cfg.Provider, cfg.BaseURL),
+		Provider: llm.NewOllamaClient(cfg.BaseURL),
+	}}
+	for _, fallback := range cfg.Resilience.Fallbacks {
+		provider, baseURL := fallback.Provider, fallback.BaseURL
+		if provider == "" {
+			provider = cfg.Provider
+		}
+		if baseURL == "" {
+			baseURL = cfg.BaseURL
+		}
+		endpoints = append(endpoints, llm.Endpoint{
+			Name:     fmt.Sprintf("%s@%s", provider, baseURL),
+			Provider: llm.NewOllamaClient(baseURL),
+			Model:    fallback.Model,
+		})
+	}
+	return endpoints
+}
+
+// newLLMProvider は単発のコマンドで使うプロバイダー（再試行・代替エンドポイント付きで、送信前に機密情報を伏せ、
+// 設定で有効なら生成コードを検証する）
+func newLLMProvider(cfg *config.Config) llm.Provider {
+	provider := llm.NewRedactingProvider(llm.NewResilientProvider(llmEndpoints(cfg), cfg.Resilience))
+	return llm.NewCodeCheckProvider(provider, cfg.CodeCheck)
+}
+
+// fidelityValidator は設定に応じたコンテキスト圧縮の検証器（キャッシュ・監査を通さず直接問い合わせる）
+func (h *ChatHandler) fidelityValidator(cfg *config.Config) contextmanager.FidelityValidator {
+	switch cfg.Compression.Validation {
+	case contextmanager.FidelityMethodEmbedding:
+		return &contextmanager.EmbeddingValidator{Embedder: llm.NewEmbeddingClient(cfg), Model: cfg.ResolvedEmbeddingModel(cfg.Compression.EmbeddingModel)}
+	case contextmanager.FidelityMethodLLM:
+		// 圧縮の検証は裏の処理のため、ユーザーが待っているリクエストを優先する
+		provider := llm.NewScheduledProvider(h.resilientProvider, h.scheduler).WithPriority(scheduler.PriorityBackground)
+		return &contextmanager.LLMValidator{Provider: llm.NewRedactingProvider(provider), Model: cfg.ResolvedModel()}
+	default:
+		return contextmanager.KeyFactValidator{}
+	}
+}
+
+// scheduledManager はツール実行・分析の同時実行数を制限できるセッション管理
+type scheduledManager interface {
+	SetScheduler(s *scheduler.Scheduler)
+}
+
+// storedManager はセッション・メトリクス・会話の保存先を差し替えられるセッション管理
+type storedManager interface {
+	SetStore(store storage.Store)
+}
+
+// openStore は設定の保存先を開く（開けなければ警告してメモリに保存する）
+func openStore(cfg *config.Config) storage.Store {
+	store, err := storage.Open(cfg.Storage)
+	if err != nil {
+		fmt.Printf("⚠️ Storage unavailable, keeping history in memory: %v\n", err)
+		return storage.NewMemoryStore()
+	}
+	return store
+}
+
 // initializeInteractiveManager はInteractiveSessionManagerを初期化
 func (h *ChatHandler) initializeInteractiveManager(cfg *config.Config) error {
 	if h.interactiveManager != nil {
 		return nil // 既に初期化済み
 	}
 
-	// LLMプロバイダーを作成
-	baseProvider := llm.NewOllamaClient(cfg.BaseURL)
+	h.historyDir = cfg.Audit.Dir
+	if h.historyDir == "" {
+		h.historyDir = logger.DefaultAuditDir()
+	}
+
+	// LLMプロバイダーを作成（一時的なエラーの再試行と代替エンドポイントへの切り替え付き）
+	h.cfg = cfg
+	h.scheduler = scheduler.New(cfg.Concurrency)
+	h.resilientProvider = llm.NewResilientProvider(llmEndpoints(cfg), cfg.Resilience)
+	// セッション・メトリクス・会話・応答キャッシュを保存してプロセスを跨いで参照する
+	h.store = openStore(cfg)
+	var baseProvider llm.Provider = h.resilientProvider
+	// 実際の呼び出しの所要時間・トークン数をイベントとして通知（メトリクス・トレース・拡張が購読）
+	baseProvider = llm.NewEventProvider(baseProvider, events.Default())
+	// 同時リクエスト数を制限（キャッシュヒットは待たずに返す）
+	baseProvider = llm.NewScheduledProvider(baseProvider, h.scheduler)
+	// 実際にプロバイダーへ送ったリクエストのみトークン使用量を記録
+	if cfg.Usage.Enabled {
+		h.usageTracker = usage.NewTracker(usage.DefaultPath(), cfg.Usage)
+		h.usageCurrency = cfg.Usage.Currency
+		baseProvider = llm.NewUsageProvider(baseProvider, h.usageTracker)
+	}
+	// 1ターンの累計トークン数を数え、上限を超えたら以降のリクエストを止める
+	baseProvider = llm.NewBudgetProvider(baseProvider)
+	// 同一・類似プロンプトの再問い合わせを抑えるため応答キャッシュでラップ
+	if cfg.LLMCache.Enabled {
+		cacheCfg := cfg.LLMCache
+		cacheCfg.EmbeddingModel = cfg.ResolvedEmbeddingModel(cacheCfg.EmbeddingModel)
+		cachingProvider := llm.NewCachingProvider(baseProvider, cacheCfg)
+		cachingProvider.SetStore(h.store)
+		// 類似照合の埋め込みはチャットとは別の埋め込みモデル・APIで生成する
+		if cacheCfg.SemanticMatching {
+			cachingProvider.SetEmbedder(llm.NewEmbeddingClient(cfg))
+		}
+		baseProvider = cachingProvider
+	}
+	// キャッシュヒットも含め全リクエストを監査ログに記録
+	if cfg.Audit.Enabled {
+		baseProvider = llm.NewAuditingProvider(baseProvider)
+	}
+	// ファイル内容・コマンド出力に含まれる機密情報を伏せてから送る（キャッシュ・監査ログにも伏せた内容が残る）
+	baseProvider = llm.NewRedactingProvider(baseProvider)
+	// 設定で有効なら応答中のコードを表示前に検証し、失敗したらモデルに直させる
+	baseProvider = llm.NewCodeCheckProvider(baseProvider, cfg.CodeCheck)
 	// プロンプトアダプターでラップして自動システムプロンプト統合
 	llmProvider := llm.NewPromptAdapter(baseProvider, cfg)
 
-	// ContextManagerを作成
+	// 設定で有効な場合は /metrics の公開とターン毎のトレース送信を開始
+	exporters, err := performance.StartExporters(cfg.Observability, events.Default(), h.perfMonitor)
+	if err != nil {
+		fmt.Printf("⚠️ Metrics export failed to start: %v\n", err)
+	}
+	h.exporters = exporters
+
+	// 長いターンの完了・確認待ち・失敗をデスクトップ・端末に通知
+	h.notifier = notify.Start(cfg.Notifications, events.Default())
+
+	// 設定で有効なら vyb stats で集計する利用統計をローカルに記録
+	h.telemetry = telemetry.Start(cfg.Telemetry, events.Default())
+
+	// ContextManagerを作成（圧縮結果は設定した方式で忠実度を検証）
 	contextManager := contextmanager.NewSmartContextManager()
+	contextManager.SetFidelityValidator(h.fidelityValidator(cfg), cfg.Compression.MinFidelity)
 
 	// AIServiceを作成 (インターフェース互換性のため簡単なアダプター作成)
 	// 現在はInteractiveSessionManagerでLLMプロバイダーを直接使用するため、nilでも動作する
@@ -99,36 +260,783 @@ func (h *ChatHandler) initializeInteractiveManager(cfg *config.Config) error {
 		aiService,
 		editTool,
 		vibeConfig,
-		cfg.Model,
+		cfg.ResolvedModel(),
 		cfg,
 	)
+	if scheduled, ok := h.interactiveManager.(scheduledManager); ok {
+		scheduled.SetScheduler(h.scheduler)
+	}
+	if stored, ok := h.interactiveManager.(storedManager); ok {
+		stored.SetStore(h.store)
+	}
+
+	// リモート開発: ファイル操作・コマンド実行を ssh 越しのエージェントで行う
+	if cfg.Remote.Host != "" {
+		if err := h.connectRemote(cfg); err != nil {
+			return err
+		}
+	}
 
 	h.log.Info("Interactive session manager initialized", nil)
 	return nil
 }
 
+// projectTaskRunner はビルド・テスト・リントを実行できるセッション管理
+type projectTaskRunner interface {
+	RunProjectTask(ctx context.Context, sessionID string, kind tasks.Kind) (*tasks.Result, error)
+}
+
+// projectTaskCommands はスラッシュコマンドとタスク種類の対応
+var projectTaskCommands = map[string]tasks.Kind{
+	"/build": tasks.KindBuild,
+	"/test":  tasks.KindTest,
+	"/lint":  tasks.KindLint,
+}
+
+// runProjectTask はタスクを実行して結果を表示
+func (h *ChatHandler) runProjectTask(sessionID string, kind tasks.Kind) {
+	runner, ok := h.interactiveManager.(projectTaskRunner)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("task.unavailable", kind))
+		return
+	}
+
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("task.running", kind))
+	ctx, stop := interruptContext()
+	defer stop()
+	result, err := runner.RunProjectTask(ctx, sessionID, kind)
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("chat.interrupted"))
+			return
+		}
+		fmt.Printf("\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	fmt.Printf("%s", tasks.FormatFailures(result))
+	if !result.Success {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("task.context_added"))
+	}
+	fmt.Println()
+}
+
+// shellRunner はユーザーのコマンドを直接実行できるセッション管理
+type shellRunner interface {
+	RunShellCommand(ctx context.Context, sessionID string, command string) (*tools.ToolExecutionResult, error)
+}
+
+// runShellCommand は !command をモデルを介さずに実行して出力を表示
+func (h *ChatHandler) runShellCommand(sessionID, command string) {
+	runner, ok := h.interactiveManager.(shellRunner)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("shell.unavailable"))
+		return
+	}
+	if command == "" {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("shell.usage"))
+		return
+	}
+
+	fmt.Printf("\n\033[38;5;27m$ %s\033[0m\n", command)
+	ctx, stop := interruptContext()
+	defer stop()
+	result, err := runner.RunShellCommand(ctx, sessionID, command)
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("chat.interrupted"))
+			return
+		}
+		fmt.Printf("\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	h.printOutput(result.Content)
+	switch {
+	case result.TimedOut:
+		fmt.Printf("\033[38;5;196m%s\033[0m\n", i18n.T("shell.timed_out", result.Duration))
+	case result.ExitCode != 0:
+		fmt.Printf("\033[38;5;196m%s\033[0m\n", i18n.T("shell.exit_code", result.ExitCode, result.Duration))
+	default:
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("shell.done", result.Duration))
+	}
+	fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("shell.context_added"))
+}
+
+// jobController はバックグラウンドジョブを管理できるセッション管理
+type jobController interface {
+	StartJob(ctx context.Context, sessionID string, command string) (jobs.Info, error)
+	Jobs() []jobs.Info
+	JobOutput(id int, maxBytes int) (jobs.Info, string, error)
+	KillJob(id int) (jobs.Info, error)
+	StopJobs()
+}
+
+// fileConfirmer はファイル削除の確認方法を設定できるセッション管理
+type fileConfirmer interface {
+	SetFileConfirmation(confirmation security.UserConfirmation)
+}
+
+// jobTailBytes は /jobs <id> で表示する出力の上限
+const jobTailBytes = 4 * 1024
+
+// jobCommand は /bg <command>（起動）、/jobs [id]（一覧・出力）、/kill <id>（停止）を処理
+func (h *ChatHandler) jobCommand(sessionID, command string) {
+	controller, ok := h.interactiveManager.(jobController)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("jobs.unavailable"))
+		return
+	}
+
+	name, arg, _ := strings.Cut(command, " ")
+	arg = strings.TrimSpace(arg)
+
+	switch name {
+	case "/bg":
+		if arg == "" {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("jobs.usage"))
+			return
+		}
+		info, err := controller.StartJob(context.Background(), sessionID, arg)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		fmt.Printf("\n\033[38;5;34m%s\033[0m\n\n", i18n.T("jobs.started", info.ID, info.PID, info.Command))
+
+	case "/jobs":
+		if arg == "" {
+			h.listJobs(controller.Jobs())
+			return
+		}
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("jobs.invalid_id", arg))
+			return
+		}
+		info, output, err := controller.JobOutput(id, jobTailBytes)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("jobs.output_title", info.ID, info.Command, jobStatusLabel(info)))
+		h.printOutput(output)
+		fmt.Println()
+
+	case "/kill":
+		id, err := strconv.Atoi(arg)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("jobs.invalid_id", arg))
+			return
+		}
+		info, err := controller.KillJob(id)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("jobs.killed", info.ID, jobStatusLabel(info)))
+	}
+}
+
+// listJobs はジョブ一覧を表示
+func (h *ChatHandler) listJobs(list []jobs.Info) {
+	if len(list) == 0 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("jobs.empty"))
+		return
+	}
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("jobs.title"))
+	for _, info := range list {
+		elapsed := time.Since(info.StartedAt)
+		if !info.EndedAt.IsZero() {
+			elapsed = info.EndedAt.Sub(info.StartedAt)
+		}
+		fmt.Printf("  [%d] %-18s %8s  %8s  %s\n", info.ID, jobStatusLabel(info), elapsed.Round(time.Second).String(), formatBytes(info.OutputBytes), truncateRunes(info.Command, 60))
+	}
+	fmt.Println()
+}
+
+// jobStatusLabel はジョブ状態の表示名
+func jobStatusLabel(info jobs.Info) string {
+	if info.Status == jobs.StatusExited {
+		return i18n.T("jobs.status_exited", info.ExitCode)
+	}
+	return i18n.T("jobs.status_" + string(info.Status))
+}
+
+// stopJobs はチャット終了時に実行中のジョブを停止し、送信待ちのトレースを送り切る（完了通知・利用統計・リモート接続・保存先も閉じる）
+func (h *ChatHandler) stopJobs() {
+	if controller, ok := h.interactiveManager.(jobController); ok {
+		controller.StopJobs()
+	}
+	h.exporters.Close()
+	h.exporters = nil
+	h.notifier.Close()
+	h.notifier = nil
+	h.telemetry.Close()
+	h.telemetry = nil
+	h.remote.Close()
+	h.remote = nil
+	if h.store != nil {
+		h.store.Close()
+		h.store = nil
+	}
+}
+
+// AttachImages は画像ファイルを読み込み、次のメッセージに添付する
+func (h *ChatHandler) AttachImages(paths []string) error {
+	for _, path := range paths {
+		encoded, err := llm.LoadImage(path)
+		if err != nil {
+			return err
+		}
+		h.pendingImages = append(h.pendingImages, encoded)
+	}
+	return nil
+}
+
+// attachImageCommand は /image <path> と /paste を処理
+func (h *ChatHandler) attachImageCommand(command string) {
+	var err error
+	if command == "/paste" {
+		var data []byte
+		if data, err = input.ReadClipboardImage(); err == nil {
+			var encoded string
+			if encoded, err = llm.EncodeImage(data); err == nil {
+				h.pendingImages = append(h.pendingImages, encoded)
+			}
+		}
+	} else {
+		err = h.AttachImages([]string{strings.TrimSpace(strings.TrimPrefix(command, "/image "))})
+	}
+
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("vision.attached", len(h.pendingImages)))
+}
+
+// interruptContext は処理中のCtrl+Cで実行中のコマンドやLLM呼び出しをキャンセルするコンテキストを作成
+// 入力待ちの間はリーダーがCtrl+Cを扱うため、シグナルを受けるのはstopを呼ぶまでの処理中のみ
+func interruptContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt)
+}
+
+// turnContext は保留中の添付画像と @メンションしたファイルを載せたコンテキストを作成（1ターンで消費）
+func (h *ChatHandler) turnContext(parent context.Context, model, query string) context.Context {
+	ctx := h.mentionContext(parent, query)
+	if len(h.pendingImages) == 0 {
+		return ctx
+	}
+
+	// 非対応モデルでも処理は継続し、画像を送らない旨のみ通知（ヘッドレス出力を汚さないようstderr）
+	if !llm.SupportsVision(model) {
+		fmt.Fprintf(os.Stderr, "⚠ %s\n", i18n.T("vision.unsupported_warning", model))
+	}
+	ctx = llm.WithImages(ctx, h.pendingImages)
+	h.pendingImages = nil
+	return ctx
+}
+
+// mentionContext は入力中の @path のファイル内容と貼り付けた内容を次のリクエストに添付
+// 添付結果はヘッドレス出力を汚さないようstderrに表示
+func (h *ChatHandler) mentionContext(ctx context.Context, query string) context.Context {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return ctx
+	}
+	mentions := input.ResolveMentions(query, workDir)
+	for _, mention := range mentions {
+		switch {
+		case mention.Err != nil:
+			fmt.Fprintf(os.Stderr, "⚠ %s\n", i18n.T("mention.skipped", mention.Path, mention.Err))
+		case mention.Truncated:
+			fmt.Fprintf(os.Stderr, "📎 %s\n", i18n.T("mention.truncated", mention.Path, len(mention.Content), mention.Size))
+		default:
+			fmt.Fprintf(os.Stderr, "📎 %s\n", i18n.T("mention.attached", mention.Path, mention.Size))
+		}
+	}
+	attachments := input.FormatMentions(mentions)
+	if pasted := h.pasteAttachments(query); pasted != "" {
+		if attachments != "" {
+			attachments += "\n"
+		}
+		attachments += pasted
+	}
+	return llm.WithFileAttachments(ctx, attachments)
+}
+
+// contextInspector はプロンプト構成の内訳を取得できるセッション管理
+type contextInspector interface {
+	ContextUsage(sessionID string) (*interactive.ContextUsage, error)
+}
+
+// compressionInspector はコンテキスト圧縮の削減率・忠実度を取得できるセッション管理
+type compressionInspector interface {
+	CompressionMetrics(sessionID string) contextmanager.CompressionMetrics
+}
+
+// contextSegmentColors はコンテキスト内訳の要素毎の表示色（ANSI 256色）
+var contextSegmentColors = map[string]int{
+	interactive.ContextSegmentSystem:     99,
+	interactive.ContextSegmentTemplate:   141,
+	interactive.ContextSegmentMemory:     37,
+	interactive.ContextSegmentContext:    34,
+	interactive.ContextSegmentHistory:    214,
+	interactive.ContextSegmentLastOutput: 203,
+}
+
+// contextBarWidth はコンテキスト使用量バーの幅（文字数）
+const contextBarWidth = 50
+
+// contextMaxListedItems は内訳に表示するコンテキスト項目の最大数
+const contextMaxListedItems = 10
+
+// showContextUsage はプロンプトを占める内容をバーグラフで表示
+func (h *ChatHandler) showContextUsage(sessionID string) {
+	inspector, ok := h.interactiveManager.(contextInspector)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("context.unavailable"))
+		return
+	}
+
+	contextUsage, err := inspector.ContextUsage(sessionID)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	window := contextUsage.ContextWindow
+	used := contextUsage.UsedTokens()
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("context.title", contextUsage.Model, used, window, percentOf(used, window)))
+
+	// 全体バー: 要素毎に色分けし、応答用の確保分と残りを続ける
+	var bar strings.Builder
+	drawn := 0
+	for _, segment := range contextUsage.Segments {
+		cells := segment.Tokens * contextBarWidth / window
+		if cells == 0 && segment.Tokens > 0 {
+			cells = 1
+		}
+		if drawn+cells > contextBarWidth {
+			cells = contextBarWidth - drawn
+		}
+		bar.WriteString(fmt.Sprintf("\033[38;5;%dm%s", contextSegmentColors[segment.Name], strings.Repeat("█", cells)))
+		drawn += cells
+	}
+	reserved := contextUsage.ReservedOutput * contextBarWidth / window
+	if drawn+reserved > contextBarWidth {
+		reserved = contextBarWidth - drawn
+	}
+	bar.WriteString("\033[38;5;240m" + strings.Repeat("▒", reserved))
+	bar.WriteString(strings.Repeat("░", contextBarWidth-drawn-reserved) + "\033[0m")
+	fmt.Printf("  %s\n\n", bar.String())
+
+	for _, segment := range contextUsage.Segments {
+		label := i18n.T("context.segment." + segment.Name)
+		if segment.Items > 0 {
+			label = i18n.T("context.segment_items", label, segment.Items)
+		}
+		cells := segment.Tokens * contextBarWidth / window
+		if cells == 0 && segment.Tokens > 0 {
+			cells = 1
+		}
+		if cells > contextBarWidth {
+			cells = contextBarWidth
+		}
+		fmt.Printf("  \033[38;5;%dm■\033[0m %-28s %7d  %5.1f%%  \033[38;5;%dm%s\033[0m\n",
+			contextSegmentColors[segment.Name], label, segment.Tokens, percentOf(segment.Tokens, window),
+			contextSegmentColors[segment.Name], strings.Repeat("▇", cells))
+	}
+	fmt.Printf("  \033[38;5;240m▒\033[0m %-28s %7d  %5.1f%%\n", i18n.T("context.reserved"), contextUsage.ReservedOutput, percentOf(contextUsage.ReservedOutput, window))
+	fmt.Printf("  \033[38;5;240m░\033[0m %-28s %7d  %5.1f%%\n", i18n.T("context.remaining"), contextUsage.RemainingTokens(), percentOf(contextUsage.RemainingTokens(), window))
+
+	if len(contextUsage.Items) > 0 {
+		fmt.Printf("\n  \033[1m%s\033[0m\n", i18n.T("context.items_title"))
+		for i, item := range contextUsage.Items {
+			if i >= contextMaxListedItems {
+				fmt.Printf("  \033[38;5;244m%s\033[0m\n", i18n.T("context.more_items", len(contextUsage.Items)-contextMaxListedItems))
+				break
+			}
+			kind := item.Tier
+			if item.ContentType != "" {
+				kind += "/" + item.ContentType
+			}
+			marker := "  "
+			if item.Pinned {
+				marker = "📌"
+			}
+			fmt.Printf("  %s %-24s imp %.2f rel %.2f %6d  \033[38;5;244m%s\033[0m  \033[38;5;240m%s\033[0m\n", marker, kind, item.Importance, item.Relevance, item.Tokens, item.Preview, item.ID)
+		}
+	}
+	fmt.Println()
+}
+
+// percentOf は全体に対する割合（%）
+func percentOf(part, whole int) float64 {
+	if whole <= 0 {
+		return 0
+	}
+	return float64(part) * 100 / float64(whole)
+}
+
+// truncateRunes は1行目を指定文字数で切り詰める
+func truncateRunes(text string, maxRunes int) string {
+	text, _, _ = strings.Cut(strings.TrimSpace(text), "\n")
+	runes := []rune(text)
+	if len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "…"
+	}
+	return text
+}
+
+// showCompressionStats は /context stats でコンテキスト圧縮の削減率と忠実度を表示
+func (h *ChatHandler) showCompressionStats(sessionID string) {
+	inspector, ok := h.interactiveManager.(compressionInspector)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("context.unavailable"))
+		return
+	}
+
+	metrics := inspector.CompressionMetrics(sessionID)
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("context.stats_title"))
+	if metrics.Compressions == 0 {
+		fmt.Printf("  \033[38;5;244m%s\033[0m\n\n", i18n.T("context.stats_none"))
+		return
+	}
+
+	fmt.Printf("  %s\n", i18n.T("context.stats_count", metrics.Compressions, metrics.Repaired))
+	fmt.Printf("  %s\n", i18n.T("context.stats_size", metrics.OriginalBytes, metrics.CompressedBytes, metrics.Ratio()*100))
+	fmt.Printf("  %s\n", i18n.T("context.stats_fidelity", metrics.AverageFidelity*100, metrics.MinFidelity*100))
+
+	if last := metrics.Last; last != nil {
+		fmt.Printf("  %s\n", i18n.T("context.stats_last", last.Method, last.Facts, last.Fidelity*100))
+		if last.Error != "" {
+			fmt.Printf("  \033[38;5;214m%s\033[0m\n", i18n.T("context.stats_fallback", last.Error))
+		}
+		if len(last.MissingFacts) > 0 {
+			title := i18n.T("context.stats_missing")
+			if last.Repaired {
+				title = i18n.T("context.stats_restored")
+			}
+			fmt.Printf("\n  \033[1m%s\033[0m\n", title)
+			for i, fact := range last.MissingFacts {
+				if i >= contextMaxListedItems {
+					fmt.Printf("  \033[38;5;244m%s\033[0m\n", i18n.T("context.more_items", len(last.MissingFacts)-contextMaxListedItems))
+					break
+				}
+				fmt.Printf("    • %s\n", truncateRunes(fact, 80))
+			}
+		}
+	}
+	fmt.Println()
+}
+
+// showSessionCost はセッションのトークン使用量とコストをモデル別に表示
+func (h *ChatHandler) showSessionCost(sessionID string) {
+	if h.usageTracker == nil {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("usage.disabled"))
+		return
+	}
+
+	records, err := usage.Load(h.usageTracker.Path(), time.Time{})
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	records = usage.FilterSession(records, sessionID)
+	if len(records) == 0 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("usage.no_records"))
+		return
+	}
+
+	total := usage.Total(records)
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("usage.session_title"))
+	for _, summary := range usage.Aggregate(records, usage.ByModel) {
+		fmt.Printf("  %-28s %s\n", summary.Key, i18n.T("usage.line", summary.Requests, summary.PromptTokens, summary.CompletionTokens, usage.FormatCost(summary.Cost, h.usageCurrency)))
+	}
+	fmt.Printf("  %-28s %s\n", i18n.T("usage.total"), i18n.T("usage.line", total.Requests, total.PromptTokens, total.CompletionTokens, usage.FormatCost(total.Cost, h.usageCurrency)))
+	if total.Estimated > 0 {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("usage.estimated_note", total.Estimated))
+	}
+	fmt.Println()
+}
+
+// showInfo は /info でモデルとLLMエンドポイントの状態（サーキットブレーカー）を表示
+func (h *ChatHandler) showInfo(sessionID string) {
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("info.title"))
+	if h.cfg != nil {
+		fmt.Printf("  %s\n", i18n.T("info.model", h.cfg.ResolvedModel()))
+		fmt.Printf("  %s\n", i18n.T("info.provider", h.cfg.Provider, h.cfg.BaseURL))
+	}
+	if h.offline {
+		fmt.Printf("  \033[38;5;214m%s\033[0m\n", i18n.T("info.offline", len(h.queuedPrompts)))
+	}
+	if h.template != nil {
+		fmt.Printf("  %s\n", i18n.T("info.template", h.template.Name, h.template.Source()))
+	}
+	if manager, ok := h.interactiveManager.(pinManager); ok {
+		if pinned := manager.PinnedFiles(sessionID); len(pinned) > 0 {
+			fmt.Printf("  %s\n", i18n.T("info.pinned", strings.Join(pinned, ", ")))
+		}
+	}
+	if h.scheduler != nil {
+		fmt.Printf("  %s\n", i18n.T("info.concurrency"))
+		stats := h.scheduler.Stats()
+		for _, class := range h.scheduler.Classes() {
+			s := stats[class]
+			fmt.Printf("    %-10s %s\n", class, i18n.T("info.concurrency_line", s.Running, s.Limit, s.Queued, s.Waited, s.TotalWait.Round(time.Millisecond)))
+		}
+	}
+	if h.resilientProvider == nil {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("info.no_resilience"))
+		return
+	}
+
+	fmt.Printf("  %s\n", i18n.T("info.endpoints"))
+	for _, endpoint := range h.resilientProvider.Health() {
+		name := endpoint.Name
+		if endpoint.Model != "" {
+			name += " (" + endpoint.Model + ")"
+		}
+		var state string
+		switch endpoint.State {
+		case llm.CircuitOpen:
+			state = "\033[38;5;196m✗ " + i18n.T("info.state_open", endpoint.RetryAt.Format("15:04:05")) + "\033[0m"
+		case llm.CircuitHalfOpen:
+			state = "\033[38;5;214m◐ " + i18n.T("info.state_halfopen") + "\033[0m"
+		default:
+			state = "\033[38;5;46m✓ " + i18n.T("info.state_closed") + "\033[0m"
+		}
+		if endpoint.Active {
+			state += " · " + i18n.T("info.active")
+		}
+		fmt.Printf("    %-44s %s\n", name, state)
+		if endpoint.ConsecutiveFailures > 0 {
+			fmt.Printf("      \033[38;5;244m%s\033[0m\n", i18n.T("info.failures", endpoint.ConsecutiveFailures, truncateRunes(endpoint.LastError, 80)))
+		}
+	}
+	fmt.Println()
+}
+
+// checkpointManager はワークスペースのチェックポイントと巻き戻しに対応したセッション管理
+type checkpointManager interface {
+	CheckpointsEnabled() bool
+	Checkpoints(sessionID string) []checkpoint.Checkpoint
+	Rewind(sessionID string, turn int) (*interactive.RewindResult, error)
+}
+
+// rewindCommand は /rewind（一覧）と /rewind <ターン>（巻き戻し）を処理
+func (h *ChatHandler) rewindCommand(sessionID, command string) {
+	manager, ok := h.interactiveManager.(checkpointManager)
+	if !ok || !manager.CheckpointsEnabled() {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("rewind.unavailable"))
+		return
+	}
+
+	arg := strings.TrimSpace(strings.TrimPrefix(command, "/rewind"))
+	if arg == "" {
+		checkpoints := manager.Checkpoints(sessionID)
+		if len(checkpoints) == 0 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("rewind.empty"))
+			return
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("rewind.title"))
+		for i := len(checkpoints) - 1; i >= 0; i-- {
+			cp := checkpoints[i]
+			fmt.Printf("  %s\n", i18n.T("rewind.entry", cp.Turn, cp.CreatedAt.Format("15:04:05"), len(cp.Files), truncateRunes(cp.Input, 50)))
+		}
+		fmt.Println()
+		return
+	}
+
+	turn, err := strconv.Atoi(arg)
+	if err != nil || turn < 1 {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("rewind.invalid_turn", arg))
+		return
+	}
+	result, err := manager.Rewind(sessionID, turn)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	// 取り消したターンの応答は show で展開できないようにする
+	undone := result.TurnsUndone
+	if undone > len(h.responseHistory) {
+		undone = len(h.responseHistory)
+	}
+	h.responseHistory = h.responseHistory[:len(h.responseHistory)-undone]
+
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("rewind.done", result.Checkpoint.Turn, result.TurnsUndone))
+	fmt.Printf("  %s\n", i18n.T("rewind.files", len(result.Files.Restored), len(result.Files.Removed)))
+	for _, path := range result.Files.Restored {
+		fmt.Printf("  \033[38;5;34m↺ %s\033[0m\n", path)
+	}
+	for _, path := range result.Files.Removed {
+		fmt.Printf("  \033[38;5;196m✗ %s\033[0m\n", path)
+	}
+	fmt.Println()
+}
+
+// transcriptExporter は会話記録をエクスポートできるセッション管理
+type transcriptExporter interface {
+	Transcript(sessionID string) (*transcript.Transcript, error)
+}
+
+// saveConversation は /save [file] で会話全体をMarkdown（.html ならHTML）に書き出す
+func (h *ChatHandler) saveConversation(sessionID, command string) {
+	exporter, ok := h.interactiveManager.(transcriptExporter)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("save.unavailable"))
+		return
+	}
+
+	record, err := exporter.Transcript(sessionID)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	if len(record.Entries) == 0 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("save.empty"))
+		return
+	}
+
+	path := strings.TrimSpace(strings.TrimPrefix(command, "/save"))
+	if path == "" {
+		path = transcript.DefaultFileName(sessionID, transcript.FormatMarkdown)
+	}
+	format, err := transcript.WriteFile(record, path)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n\n", i18n.T("save.done", record.Turns(), path, format))
+}
+
+// historyQuoter は過去のやり取りをコンテキストに取り込めるセッション管理
+type historyQuoter interface {
+	QuoteHistory(sessionID string, exchanges ...history.Exchange) error
+}
+
+// 再開時にコンテキストへ取り込む直近のターン数
+const resumeMaxTurns = 10
+
+// searchHistory は /history <query> で過去のセッションを検索
+func (h *ChatHandler) searchHistory(command string) {
+	query := strings.TrimSpace(strings.TrimPrefix(command, "/history"))
+	if query == "" {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("history.usage"))
+		return
+	}
+
+	index, err := history.Load(h.historyDir)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	hits := index.Search(query, history.SearchOptions{Limit: 10})
+	if len(hits) == 0 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("history.no_match", query))
+		return
+	}
+
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("history.title", query, len(hits)))
+	fmt.Print(formatHistoryHits(hits))
+	fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("history.quote_hint"))
+}
+
+// quoteHistory は /quote <session> <turn> で過去のやり取りを次の応答のコンテキストに取り込む
+func (h *ChatHandler) quoteHistory(sessionID, command string) {
+	quoter, ok := h.interactiveManager.(historyQuoter)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("history.unavailable"))
+		return
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(command, "/quote"))
+	if len(fields) != 2 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("history.quote_usage"))
+		return
+	}
+	turn, err := strconv.Atoi(fields[1])
+	if err != nil || turn < 1 {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("rewind.invalid_turn", fields[1]))
+		return
+	}
+
+	exchange, err := history.LoadExchange(h.historyDir, fields[0], turn)
+	if err == nil {
+		err = quoter.QuoteHistory(sessionID, *exchange)
+	}
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("history.quoted", exchange.SessionID, exchange.Turn))
+	fmt.Printf("  %s\n\n", truncateRunes(exchange.User, 60))
+}
+
 // runInteractiveLoop はインタラクティブな対話ループを実行
 func (h *ChatHandler) runInteractiveLoop(sessionID string, cfg *config.Config) error {
+	// 終了時に残ったバックグラウンドジョブを停止
+	defer h.stopJobs()
+
+	// 異常終了に備えて会話を定期的に自動保存（正常終了時は削除、パニック時は保存して残す）
+	if saver := h.startAutosave(sessionID); saver != nil {
+		defer func() {
+			if r := recover(); r != nil {
+				saver.Save()
+				panic(r)
+			}
+			saver.Stop()
+		}()
+	}
+
+	// --template のテンプレートをセッションに適用（作業計画の表示・開始時のタスクは画面の準備後）
+	templated := h.applyTemplate(sessionID)
+	// vyb chat <file...> のファイルを固定
+	h.applyPins(sessionID)
+
+	// ペイン構成のTUI（--no-tui・非対話端末では以下の逐次表示）
+	if h.tuiEnabled(cfg) {
+		return h.runTUI(sessionID, cfg, templated)
+	}
+
+	// 逐次表示ではファイル削除をターン中に端末で確認する（TUI・単発実行では削除しない）
+	if confirmer, ok := h.interactiveManager.(fileConfirmer); ok {
+		confirmer.SetFileConfirmation(security.NewConsoleConfirmation())
+	}
+
 	// 高度な入力システムを使用（Backspace対応）
-	reader := h.createAdvancedInputReader()
+	reader := h.createAdvancedInputReader(cfg)
 
 	// ClaudeCode風のウェルカムメッセージ
 	h.showWelcomeMessage()
+	if templated {
+		h.startTemplate(sessionID)
+	}
 
 	for {
+		// 固定中のファイルをプロンプトの上に表示
+		if status := h.pinnedStatus(sessionID); status != "" {
+			fmt.Printf("\033[38;5;244m%s\033[0m\n", status)
+		}
+
 		// ClaudeCode風のプロンプト表示（高度な入力システムが処理）
 		input, err := reader.ReadLine()
+		h.pendingPastes = reader.TakePastes()
 		if err != nil {
 			if err == io.EOF {
-				fmt.Printf("\n👋 Goodbye!\n")
+				fmt.Printf("\n%s\n", i18n.T("chat.goodbye"))
 				break
 			}
 			// Ctrl+C (interrupted) の場合も正常終了として扱う
 			if strings.Contains(err.Error(), "interrupted") {
-				fmt.Printf("\n👋 Goodbye!\n")
+				fmt.Printf("\n%s\n", i18n.T("chat.goodbye"))
 				break
 			}
-			fmt.Printf("入力エラー: %v\n", err)
+			fmt.Printf("%s\n", i18n.T("chat.input_error", err))
 			continue
 		}
 
@@ -139,103 +1047,264 @@ func (h *ChatHandler) runInteractiveLoop(sessionID string, cfg *config.Config) e
 		}
 
 		if input == "exit" || input == "quit" {
-			fmt.Printf("\n👋 Goodbye!\n")
+			fmt.Printf("\n%s\n", i18n.T("chat.goodbye"))
 			break
 		}
 
-		// 展開コマンドの処理（ストリーミング対応）
-		if input == "show" || input == "more" || input == "full" {
-			if len(h.responseHistory) > 0 {
-				// 最新の応答を展開
-				latestResponse := h.responseHistory[len(h.responseHistory)-1]
-				fmt.Printf("\n\033[38;5;27m🤖 Assistant (Full Content)\033[0m\n")
-
-				// 完全なコンテンツをストリーミング表示
-				streamOptions := &streaming.StreamOptions{
-					Type:            streaming.StreamTypeUIDisplay,
-					EnableInterrupt: false, // 展開時は中断無効
-				}
+		// スラッシュコマンド・!command 等のモデルを介さない入力
+		if h.handleLocalCommand(sessionID, input) {
+			continue
+		}
 
-				err := h.streamingManager.ProcessString(context.Background(), latestResponse, os.Stdout, streamOptions)
-				if err != nil {
-					fmt.Printf("%s", latestResponse)
-				}
-				fmt.Println()
-				continue
-			} else {
-				fmt.Printf("\n\033[38;5;196m✗ Error\033[0m\nNo previous response to expand.\n\n")
-				continue
-			}
+		// LLMに到達できない間は入力を保留し、復帰後に送る
+		if h.queueWhileOffline(input) {
+			continue
 		}
 
-		// ユーザー入力を表示（ClaudeCode風）
-		fmt.Printf("\n\033[38;5;34m▶ You\033[0m\n%s\n\n", h.formatForDisplay(input))
+		h.runTurn(sessionID, cfg, input)
+	}
+
+	// 高度な入力システムでは scanner.Err() は不要
+
+	return nil
+}
+
+// runTurn は1ターン分の入力をモデルで処理して応答を表示
+func (h *ChatHandler) runTurn(sessionID string, cfg *config.Config, input string) {
+	// ユーザー入力を表示（ClaudeCode風）
+	fmt.Printf("\n\033[38;5;34m%s\033[0m\n%s\n\n", i18n.T("chat.you"), h.formatForDisplay(input))
+
+	// パフォーマンス測定開始
+	startTime := time.Now()
+	if h.perfMonitor != nil {
+		h.perfMonitor.RecordProactiveUsage("chat_request")
+	}
+
+	// インタラクティブセッションで処理（独自のプログレス表示を使用）
+	// Ctrl+Cでこのターンのコマンド実行とLLM呼び出しを中断（セッションは継続）
+	ctx, stop := interruptContext()
+	response, err := h.interactiveManager.ProcessUserInput(h.turnContext(ctx, cfg.ResolvedModel(), input), sessionID, input)
+	interrupted := ctx.Err() != nil
+	stop()
 
-		// パフォーマンス測定開始
-		startTime := time.Now()
-		if h.perfMonitor != nil {
-			h.perfMonitor.RecordProactiveUsage("chat_request")
+	// パフォーマンス測定記録
+	duration := time.Since(startTime)
+	if h.perfMonitor != nil {
+		h.perfMonitor.RecordResponseTime(duration)
+		h.perfMonitor.RecordLLMLatency(duration) // 簡略化
+	}
+
+	if interrupted {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("chat.interrupted"))
+		return
+	}
+	if err != nil {
+		// LLMに到達できなくなった場合はオフラインに切り替えて入力を保留
+		if h.queueAfterFailure(input) {
+			return
 		}
+		fmt.Printf("\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	// 完全な応答を履歴に追加
+	h.rememberResponse(response.Message)
 
-		// インタラクティブセッションで処理（独自のプログレス表示を使用）
-		response, err := h.interactiveManager.ProcessUserInput(context.Background(), sessionID, input)
+	// AI応答をストリーミング表示（ClaudeCode風）
+	fmt.Printf("\033[38;5;27m🤖 Assistant\033[0m\n")
 
-		// パフォーマンス測定記録
-		duration := time.Since(startTime)
-		if h.perfMonitor != nil {
-			h.perfMonitor.RecordResponseTime(duration)
-			h.perfMonitor.RecordLLMLatency(duration) // 簡略化
+	// 折り畳み処理のため、まず表示用コンテンツを取得
+	displayContent := h.formatForDisplay(response.Message)
+
+	// Claude Codeライクなストリーミング表示（より積極的に）
+	if len(displayContent) > 30 {
+		// ほとんどのコンテンツでストリーミング表示を使用
+		streamOptions := &streaming.StreamOptions{
+			Type:            streaming.StreamTypeUIDisplay,
+			EnableInterrupt: false, // 通常応答では中断無効
 		}
 
+		err = h.streamingManager.ProcessString(context.Background(), displayContent, os.Stdout, streamOptions)
 		if err != nil {
-			fmt.Printf("\033[38;5;196m✗ Error\033[0m\n%s\n\n", err.Error())
-			continue
+			// ストリーミングエラー時は通常表示にフォールバック
+			fmt.Printf("%s", displayContent)
 		}
+	} else {
+		// 非常に短いコンテンツは直接表示
+		fmt.Printf("%s", displayContent)
+	}
 
-		// 完全な応答を履歴に追加
-		h.responseHistory = append(h.responseHistory, response.Message)
+	// Claude Code風メタデータ表示
+	h.showResponseMetadata(duration, len(response.Message))
 
-		// 履歴を最大10件に制限
-		if len(h.responseHistory) > 10 {
-			h.responseHistory = h.responseHistory[1:]
-		}
+	// プロアクティブな機能提案
+	h.showProactiveSuggestions(input, response.Message)
 
-		// AI応答をストリーミング表示（ClaudeCode風）
-		fmt.Printf("\033[38;5;27m🤖 Assistant\033[0m\n")
+	fmt.Println() // 改行
+}
 
-		// 折り畳み処理のため、まず表示用コンテンツを取得
-		displayContent := h.formatForDisplay(response.Message)
+// rememberResponse は show/more で展開する応答を履歴に追加（最大10件）
+func (h *ChatHandler) rememberResponse(message string) {
+	h.responseHistory = append(h.responseHistory, message)
+	if len(h.responseHistory) > 10 {
+		h.responseHistory = h.responseHistory[1:]
+	}
+}
 
-		// Claude Codeライクなストリーミング表示（より積極的に）
-		if len(displayContent) > 30 {
-			// ほとんどのコンテンツでストリーミング表示を使用
-			streamOptions := &streaming.StreamOptions{
-				Type:            streaming.StreamTypeUIDisplay,
-				EnableInterrupt: false, // 通常応答では中断無効
-			}
+// handleLocalCommand はモデルに送らずに処理する入力（スラッシュコマンド・!command・show 等）を実行
+// 処理した場合は true
+func (h *ChatHandler) handleLocalCommand(sessionID, input string) bool {
+	// 展開コマンドの処理（ストリーミング対応）
+	if input == "show" || input == "more" || input == "full" {
+		if len(h.responseHistory) == 0 {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("chat.no_previous_response"))
+			return true
+		}
+		// 最新の応答を展開
+		latestResponse := h.responseHistory[len(h.responseHistory)-1]
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("chat.full_content"))
+
+		// 完全なコンテンツをストリーミング表示
+		streamOptions := &streaming.StreamOptions{
+			Type:            streaming.StreamTypeUIDisplay,
+			EnableInterrupt: false, // 展開時は中断無効
+		}
 
-			err = h.streamingManager.ProcessString(context.Background(), displayContent, os.Stdout, streamOptions)
-			if err != nil {
-				// ストリーミングエラー時は通常表示にフォールバック
-				fmt.Printf("%s", displayContent)
-			}
-		} else {
-			// 非常に短いコンテンツは直接表示
-			fmt.Printf("%s", displayContent)
+		err := h.streamingManager.ProcessString(context.Background(), latestResponse, os.Stdout, streamOptions)
+		if err != nil {
+			fmt.Printf("%s", latestResponse)
 		}
+		fmt.Println()
+		return true
+	}
+
+	// 折り畳んだコマンド出力の展開（ページ単位）
+	if input == "/expand" || strings.HasPrefix(input, "/expand ") {
+		h.expandCommand(input)
+		return true
+	}
 
-		// Claude Code風メタデータ表示
-		h.showResponseMetadata(duration, len(response.Message))
+	// 直前の応答のコードブロックをクリップボードにコピー
+	if input == "/copy" || strings.HasPrefix(input, "/copy ") {
+		h.copyCommand(input)
+		return true
+	}
 
-		// プロアクティブな機能提案
-		h.showProactiveSuggestions(input, response.Message)
+	// 画像の添付（ファイル指定またはクリップボードから貼り付け）
+	if strings.HasPrefix(input, "/image ") || input == "/paste" {
+		h.attachImageCommand(input)
+		return true
+	}
 
-		fmt.Println() // 改行
+	// プロンプトを占める内容の内訳表示
+	if input == "/context" {
+		h.showContextUsage(sessionID)
+		return true
+	}
+	if input == "/context stats" {
+		h.showCompressionStats(sessionID)
+		return true
 	}
 
-	// 高度な入力システムでは scanner.Err() は不要
+	// チェックポイント一覧・巻き戻し
+	if input == "/rewind" || strings.HasPrefix(input, "/rewind ") {
+		h.rewindCommand(sessionID, input)
+		return true
+	}
 
-	return nil
+	// 会話の分岐・切替・比較・取り込み
+	if input == "/branch" || strings.HasPrefix(input, "/branch ") {
+		h.branchCommand(sessionID, input)
+		return true
+	}
+
+	// 提案の根拠（取得したコンテキスト・分析結果・信頼度の内訳）
+	if input == "/why" || strings.HasPrefix(input, "/why ") {
+		h.whyCommand(sessionID, input)
+		return true
+	}
+
+	// 過去のセッションの検索・引用
+	if input == "/history" || strings.HasPrefix(input, "/history ") {
+		h.searchHistory(input)
+		return true
+	}
+	if input == "/quote" || strings.HasPrefix(input, "/quote ") {
+		h.quoteHistory(sessionID, input)
+		return true
+	}
+
+	// 会話をMarkdown/HTMLに保存
+	if input == "/save" || strings.HasPrefix(input, "/save ") {
+		h.saveConversation(sessionID, input)
+		return true
+	}
+
+	// コンテキストの固定・一覧・解除（圧縮で除かない設計判断・制約・ファイル）
+	if input == "/pin-context" || strings.HasPrefix(input, "/pin-context ") || input == "/unpin-context" || strings.HasPrefix(input, "/unpin-context ") {
+		h.pinContextCommand(sessionID, input)
+		return true
+	}
+
+	// ファイルの固定・一覧・解除
+	if input == "/pin" || strings.HasPrefix(input, "/pin ") || input == "/unpin" || strings.HasPrefix(input, "/unpin ") {
+		h.pinCommand(sessionID, input)
+		return true
+	}
+
+	// セッションの題名の表示・変更
+	if input == "/title" || strings.HasPrefix(input, "/title ") {
+		h.titleCommand(sessionID, input)
+		return true
+	}
+
+	// モデル・エンドポイントの状態表示
+	if input == "/info" {
+		h.showInfo(sessionID)
+		return true
+	}
+
+	// オフラインの状態表示・再接続と保留中の入力
+	if input == "/offline" {
+		h.offlineCommand()
+		return true
+	}
+	if input == "/queue" || strings.HasPrefix(input, "/queue ") {
+		h.queueCommand(sessionID, input)
+		return true
+	}
+
+	// セッションのトークン使用量・コスト表示
+	if input == "/cost" {
+		h.showSessionCost(sessionID)
+		return true
+	}
+
+	// バックグラウンドジョブの起動・一覧・停止
+	if strings.HasPrefix(input, "/bg ") || input == "/bg" || input == "/jobs" || strings.HasPrefix(input, "/jobs ") || strings.HasPrefix(input, "/kill ") {
+		h.jobCommand(sessionID, input)
+		return true
+	}
+
+	// モノレポのモジュール一覧・切替
+	if input == "/workspace" || strings.HasPrefix(input, "/workspace ") {
+		h.workspaceCommand(sessionID, input)
+		return true
+	}
+
+	// !command はモデルを介さずに直接実行（出力は次の応答のコンテキストになる）
+	if strings.HasPrefix(input, "!") {
+		h.runShellCommand(sessionID, strings.TrimSpace(strings.TrimPrefix(input, "!")))
+		return true
+	}
+
+	// ビルド・テスト・リントの実行（失敗内容は次の応答のコンテキストになる）
+	if kind, ok := projectTaskCommands[input]; ok {
+		h.runProjectTask(sessionID, kind)
+		return true
+	}
+
+	return false
 }
 
 // showWelcomeMessage はClaudeCode風のウェルカムメッセージを表示
@@ -247,19 +1316,19 @@ func (h *ChatHandler) showWelcomeMessage() {
 	fmt.Println("\033[1m🤖 vyb-code · AI Coding Assistant\033[0m")
 	fmt.Println(strings.Repeat("─", 50))
 	fmt.Println()
-	fmt.Println("🎯 \033[32mWelcome to intelligent coding!\033[0m")
-	fmt.Println("💡 \033[90mIntelligent suggestions, streaming responses, and smart completion\033[0m")
+	fmt.Printf("🎯 \033[32m%s\033[0m\n", i18n.T("welcome.greeting"))
+	fmt.Printf("💡 \033[90m%s\033[0m\n", i18n.T("welcome.features"))
 	fmt.Println()
-	fmt.Println("🔧 \033[90mCommands: '\033[36mhelp\033[90m' for help\033[0m")
-	fmt.Println("🚪 \033[90mExit: '\033[36mexit\033[90m' or '\033[36mquit\033[90m' or \033[36mCtrl+C\033[90m\033[0m")
+	fmt.Printf("🔧 \033[90m%s\033[0m\n", i18n.T("welcome.commands", "\033[36mhelp\033[90m"))
+	fmt.Printf("🚪 \033[90m%s\033[0m\n", i18n.T("welcome.exit", "\033[36mexit\033[90m", "\033[36mquit\033[90m", "\033[36mCtrl+C\033[90m"))
 
 	// プロジェクト情報を表示
 	workDir, _ := os.Getwd()
-	fmt.Printf("📂 \033[90mProject: \033[36m%s\033[0m\n", filepath.Base(workDir))
+	fmt.Printf("📂 \033[90m%s\033[36m%s\033[0m\n", i18n.T("welcome.project"), filepath.Base(workDir))
 
 	// パフォーマンス情報があれば表示
 	if h.perfMonitor != nil {
-		fmt.Printf("⚡ \033[90mPerformance monitoring: \033[32menabled\033[0m\n")
+		fmt.Printf("⚡ \033[90m%s\033[32m%s\033[0m\n", i18n.T("welcome.performance"), i18n.T("welcome.enabled"))
 	}
 
 	fmt.Println()
@@ -302,14 +1371,35 @@ func (h *ChatHandler) readInputWithCompletion() (string, error) {
 	return input, scanner.Err()
 }
 
+// toolLister は入力補完用のツール名を一覧できるセッション管理
+type toolLister interface {
+	ToolNames() map[string]string
+}
+
 // createAdvancedInputReader は高度な入力リーダーを作成（Backspace対応）
-func (h *ChatHandler) createAdvancedInputReader() *input.Reader {
+func (h *ChatHandler) createAdvancedInputReader(cfg *config.Config) *input.Reader {
 	// 高度な入力リーダーを作成
 	reader := input.NewReader()
 
 	// ClaudeCode風のプロンプトを設定
 	reader.SetPrompt("💬 You: ")
 
+	// 編集キー配置（emacs / vi）
+	if cfg != nil {
+		if mode, err := input.ParseEditMode(cfg.TerminalMode.EditMode); err == nil {
+			reader.SetEditMode(mode)
+		}
+	}
+
+	// Tab でツール名（MCPツールを含む）も補完
+	if lister, ok := h.interactiveManager.(toolLister); ok {
+		reader.SetToolNames(lister.ToolNames)
+	}
+	h.followWorkDir(reader.SetWorkDir)
+
+	// Ctrl+X y / Ctrl+X <1-9> で直前の応答のコードブロックをコピー
+	reader.SetCopyHandler(h.copyKey)
+
 	// セキュリティとパフォーマンス最適化を有効化
 	reader.EnableSecurity()
 	reader.EnableOptimization()
@@ -471,18 +1561,26 @@ func (h *ChatHandler) StartVibeChat(cfg *config.Config) error {
 	if err := h.initializeInteractiveManager(cfg); err != nil {
 		return fmt.Errorf("interactive manager initialization failed: %w", err)
 	}
+	// LLMに到達できなければオフラインで続けるか確認
+	if err := h.checkProviderAvailability(); err != nil {
+		return err
+	}
 
 	// 新しいインタラクティブセッションを開始
-	session, err := h.interactiveManager.CreateSession(interactive.CodingSessionTypeGeneral)
-	if err != nil {
-		return fmt.Errorf("vibe coding session creation failed: %w", err)
+	// 前回中断されたセッションがあれば再開を確認
+	session := h.recoverInterruptedSession()
+	if session == nil {
+		var err error
+		session, err = h.interactiveManager.CreateSession(interactive.CodingSessionTypeGeneral)
+		if err != nil {
+			return fmt.Errorf("vibe coding session creation failed: %w", err)
+		}
 	}
 	sessionID := session.ID
-	if err != nil {
-		return fmt.Errorf("vibe coding session start failed: %w", err)
-	}
 
 	fmt.Printf("🎵 Vibe coding session started: %s\n", sessionID)
+	// vyb daemon の前回のセッション以降の実行結果を要約
+	h.showMaintenanceSummary()
 
 	// パフォーマンス監視を開始
 	if h.perfMonitor != nil {
@@ -503,18 +1601,26 @@ func (h *ChatHandler) StartChatSession(cfg *config.Config) error {
 	if err := h.initializeInteractiveManager(cfg); err != nil {
 		return fmt.Errorf("interactive manager initialization failed: %w", err)
 	}
+	// LLMに到達できなければオフラインで続けるか確認
+	if err := h.checkProviderAvailability(); err != nil {
+		return err
+	}
 
 	// 新しいチャットセッションを開始
-	session, err := h.interactiveManager.CreateSession(interactive.CodingSessionTypeGeneral)
-	if err != nil {
-		return fmt.Errorf("chat session creation failed: %w", err)
+	// 前回中断されたセッションがあれば再開を確認
+	session := h.recoverInterruptedSession()
+	if session == nil {
+		var err error
+		session, err = h.interactiveManager.CreateSession(interactive.CodingSessionTypeGeneral)
+		if err != nil {
+			return fmt.Errorf("chat session creation failed: %w", err)
+		}
 	}
 	sessionID := session.ID
-	if err != nil {
-		return fmt.Errorf("chat session start failed: %w", err)
-	}
 
 	fmt.Printf("💬 Chat session started: %s\n", sessionID)
+	// vyb daemon の前回のセッション以降の実行結果を要約
+	h.showMaintenanceSummary()
 
 	// パフォーマンス監視を開始
 	if h.perfMonitor != nil {
@@ -528,19 +1634,48 @@ func (h *ChatHandler) StartChatSession(cfg *config.Config) error {
 	return h.runInteractiveLoop(sessionID, cfg)
 }
 
+// ContinueSession は記録済みのセッション（"latest" で直近）の会話をコンテキストに取り込んで新しいセッションを開始
 func (h *ChatHandler) ContinueSession(resumeID string, cfg *config.Config, terminalMode bool, planMode bool) error {
-	fmt.Printf("🔄 Continuing session: %s\n", resumeID)
-
 	// InteractiveSessionManagerを初期化
 	if err := h.initializeInteractiveManager(cfg); err != nil {
 		return fmt.Errorf("interactive manager initialization failed: %w", err)
 	}
+	// LLMに到達できなければオフラインで続けるか確認
+	if err := h.checkProviderAvailability(); err != nil {
+		return err
+	}
+
+	if resumeID == "latest" {
+		sessions, err := logger.ListAuditSessions(h.historyDir)
+		if err != nil || len(sessions) == 0 {
+			return fmt.Errorf("再開できるセッションがありません: %s", h.historyDir)
+		}
+		resumeID = sessions[0].SessionID
+	}
+	fmt.Printf("🔄 Continuing session: %s\n", resumeID)
 
-	// セッションを再開
-	fmt.Printf("🎯 Session resumed: %s\n", resumeID)
+	exchanges, err := history.LoadExchanges(h.historyDir, resumeID)
+	if err != nil {
+		return fmt.Errorf("session resume failed: %w", err)
+	}
+	if len(exchanges) > resumeMaxTurns {
+		exchanges = exchanges[len(exchanges)-resumeMaxTurns:]
+	}
+
+	session, err := h.interactiveManager.CreateSession(interactive.CodingSessionTypeGeneral)
+	if err != nil {
+		return fmt.Errorf("session creation failed: %w", err)
+	}
+	if quoter, ok := h.interactiveManager.(historyQuoter); ok {
+		if err := quoter.QuoteHistory(session.ID, exchanges...); err != nil {
+			return fmt.Errorf("session resume failed: %w", err)
+		}
+	}
+
+	fmt.Printf("🎯 Session resumed: %s (%d turns restored as context, new session %s)\n", resumeID, len(exchanges), session.ID)
 
 	// インタラクティブループを開始
-	return h.runInteractiveLoop(resumeID, cfg)
+	return h.runInteractiveLoop(session.ID, cfg)
 }
 
 func (h *ChatHandler) RunSingleQuery(query string, resumeID string, cfg *config.Config) error {
@@ -566,8 +1701,13 @@ func (h *ChatHandler) RunSingleQuery(query string, resumeID string, cfg *config.
 		fmt.Printf("📝 Created temporary session: %s\n", sessionID)
 	}
 
+	// 終了時にジョブ・拡張・トレース送信を片付ける
+	defer h.stopJobs()
+
 	// クエリを処理
-	response, err := h.interactiveManager.ProcessUserInput(context.Background(), sessionID, query)
+	ctx, stop := interruptContext()
+	defer stop()
+	response, err := h.interactiveManager.ProcessUserInput(h.turnContext(ctx, cfg.ResolvedModel(), query), sessionID, query)
 	if err != nil {
 		return fmt.Errorf("query processing failed: %w", err)
 	}
@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/glkt/vyb-code/internal/autosave"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/interactive"
+)
+
+// 中断されたセッションの自動保存を残しておく期間
+const autosaveMaxAge = 7 * 24 * time.Hour
+
+// sessionRecoverer はセッションの自動保存と復元ができるセッション管理
+type sessionRecoverer interface {
+	Snapshot(sessionID string) (*autosave.Snapshot, error)
+	RestoreSnapshot(snapshot *autosave.Snapshot, maxTurns int) (*interactive.InteractiveSession, error)
+}
+
+// startAutosave は設定で有効な場合にセッションの定期的な自動保存を開始（無効なら nil）
+func (h *ChatHandler) startAutosave(sessionID string) *autosave.Saver {
+	recoverer, ok := h.interactiveManager.(sessionRecoverer)
+	if !ok || h.cfg == nil || !h.cfg.Autosave.Enabled {
+		return nil
+	}
+	saver := autosave.NewSaver(autosave.NewStore(autosave.DefaultDir()), h.autosaveInterval(), func() (*autosave.Snapshot, error) {
+		return recoverer.Snapshot(sessionID)
+	})
+	saver.Start()
+	return saver
+}
+
+func (h *ChatHandler) autosaveInterval() time.Duration {
+	return time.Duration(h.cfg.Autosave.IntervalSeconds) * time.Second
+}
+
+// recoverInterruptedSession は前回中断されたセッションがあれば再開を確認し、復元したセッションを返す
+// 再開しない場合・対話端末でない場合は nil
+func (h *ChatHandler) recoverInterruptedSession() *interactive.InteractiveSession {
+	recoverer, ok := h.interactiveManager.(sessionRecoverer)
+	if !ok || h.cfg == nil || !h.cfg.Autosave.Enabled || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil
+	}
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	store := autosave.NewStore(autosave.DefaultDir())
+	store.Prune(autosaveMaxAge)
+	// 実行中の別のvybは自動保存の度に更新時刻を進めるため、数回分更新がなければ中断とみなす
+	snapshots, err := store.Interrupted(projectDir, 3*h.autosaveInterval())
+	if err != nil || len(snapshots) == 0 {
+		return nil
+	}
+	snapshot := snapshots[0]
+
+	fmt.Printf("\n\033[38;5;214m%s\033[0m\n", i18n.T("autosave.found", formatAge(time.Since(snapshot.SavedAt)), snapshot.Turns()))
+	if input := snapshot.UnansweredInput(); input != "" {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("autosave.unanswered", truncateLine(input, 80)))
+	}
+	var pending interactive.CodeSuggestion
+	if len(snapshot.PendingSuggestion) > 0 && json.Unmarshal(snapshot.PendingSuggestion, &pending) == nil {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("autosave.pending", pending.FilePath))
+	}
+	fmt.Print(i18n.T("autosave.prompt"))
+
+	answer := strings.ToLower(strings.TrimSpace(readLine()))
+	if answer != "" && answer != "y" && answer != "yes" && answer != "はい" {
+		store.Discard(snapshot.SessionID)
+		fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("autosave.discarded"))
+		return nil
+	}
+
+	session, err := recoverer.RestoreSnapshot(snapshot, resumeMaxTurns)
+	if err != nil {
+		fmt.Printf("\033[38;5;196m%s\033[0m\n\n", i18n.T("autosave.failed", err))
+		return nil
+	}
+	// 復元したセッションは新しいIDで自動保存される
+	store.Discard(snapshot.SessionID)
+
+	turns := snapshot.Turns()
+	if turns > resumeMaxTurns {
+		turns = resumeMaxTurns
+	}
+	fmt.Printf("\033[38;5;46m%s\033[0m\n", i18n.T("autosave.resumed", turns, session.ID))
+	if session.PendingSuggestion != nil {
+		fmt.Printf("%s\n", i18n.T("autosave.pending_restored", session.PendingSuggestion.FilePath))
+	}
+	fmt.Println()
+	return session
+}
+
+// readLine は標準入力から1行読む（後続の入力処理のためにバッファーに先読みしない）
+func readLine() string {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buf)
+		if n == 0 || err != nil || buf[0] == '\n' {
+			return string(line)
+		}
+		line = append(line, buf[0])
+	}
+}
+
+// formatAge は経過時間を "45s"・"10m"・"3h"・"2d" の形式で返す
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// truncateLine は1行目を最大 limit 文字にする
+func truncateLine(text string, limit int) string {
+	text = strings.TrimSpace(text)
+	if i := strings.Index(text, "\n"); i >= 0 {
+		text = text[:i] + " …"
+	}
+	if runes := []rune(text); len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+	return text
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/branch"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/interactive"
+)
+
+// branchManager は会話の分岐に対応したセッション管理
+type branchManager interface {
+	Branches(sessionID string) (*branch.Tree, error)
+	ForkBranch(sessionID string, turn int, name string) (*branch.Branch, error)
+	SwitchBranch(sessionID, name string) (*branch.Branch, error)
+	CompareBranches(sessionID, name string) (*interactive.BranchComparison, error)
+	MergeBranch(sessionID, name string) (*interactive.BranchComparison, error)
+}
+
+// branchCommand は /branch（一覧）、/branch <ターン> [名前]（分岐）、
+// /branch switch|compare|merge <名前> を処理
+func (h *ChatHandler) branchCommand(sessionID, input string) {
+	manager, ok := h.interactiveManager.(branchManager)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("branch.unavailable"))
+		return
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(input, "/branch"))
+	if len(fields) == 0 {
+		tree, err := manager.Branches(sessionID)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		h.showBranches(tree)
+		return
+	}
+
+	var err error
+	switch fields[0] {
+	case "switch", "compare", "merge":
+		if len(fields) != 2 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("branch.usage"))
+			return
+		}
+		err = h.branchAction(manager, sessionID, fields[0], fields[1])
+	default:
+		turn, convErr := strconv.Atoi(fields[0])
+		if convErr != nil || turn < 0 || len(fields) > 2 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("branch.usage"))
+			return
+		}
+		name := ""
+		if len(fields) == 2 {
+			name = fields[1]
+		}
+		var forked *branch.Branch
+		forked, err = manager.ForkBranch(sessionID, turn, name)
+		if err == nil {
+			h.restoreResponseHistory(forked)
+			fmt.Printf("\n\033[38;5;27m%s\033[0m\n\n", i18n.T("branch.forked", forked.Name, forked.Parent, forked.ForkTurn))
+		}
+	}
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+	}
+}
+
+// branchAction は分岐の切替・比較・取り込みを行う
+func (h *ChatHandler) branchAction(manager branchManager, sessionID, action, name string) error {
+	switch action {
+	case "switch":
+		target, err := manager.SwitchBranch(sessionID, name)
+		if err != nil {
+			return err
+		}
+		h.restoreResponseHistory(target)
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n\n", i18n.T("branch.switched", target.Name, target.Turns()))
+	case "compare":
+		comparison, err := manager.CompareBranches(sessionID, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("branch.compare_title", comparison.Current.Name, comparison.Other.Name, comparison.SharedTurns))
+		printBranchSide(comparison.Current)
+		printBranchSide(comparison.Other)
+		fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("branch.merge_hint", comparison.Other.Name))
+	case "merge":
+		comparison, err := manager.MergeBranch(sessionID, name)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n\n", i18n.T("branch.merged", len(comparison.Other.Exchanges), comparison.Other.Name, comparison.Current.Name))
+	}
+	return nil
+}
+
+// showBranches は分岐ツリーを表示（現在の分岐に * を付ける）
+func (h *ChatHandler) showBranches(tree *branch.Tree) {
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("branch.title"))
+	var walk func(b *branch.Branch, depth int)
+	walk = func(b *branch.Branch, depth int) {
+		marker := " "
+		if b.Name == tree.Current {
+			marker = "*"
+		}
+		indent := strings.Repeat("  ", depth)
+		if depth > 0 {
+			indent = strings.Repeat("  ", depth-1) + "└ "
+		}
+		line := fmt.Sprintf("  %s %s%-16s %s", marker, indent, b.Name, i18n.T("branch.turns", b.Turns()))
+		if b.Parent != "" {
+			line += "  \033[38;5;244m" + i18n.T("branch.forked_from", b.Parent, b.ForkTurn) + "\033[0m"
+		}
+		fmt.Println(line)
+		for _, child := range tree.Children(b.Name) {
+			walk(child, depth+1)
+		}
+	}
+	for _, b := range tree.Branches {
+		if b.Parent == "" || tree.Get(b.Parent) == nil {
+			walk(b, 0)
+		}
+	}
+	fmt.Println()
+}
+
+// printBranchSide は分岐が分かれた後のやり取り（入力と応答の冒頭）を表示
+func printBranchSide(side interactive.BranchSide) {
+	fmt.Printf("── %s\n", side.Name)
+	if len(side.Exchanges) == 0 {
+		fmt.Printf("  \033[38;5;244m%s\033[0m\n", i18n.T("branch.no_exchanges"))
+		return
+	}
+	for _, exchange := range side.Exchanges {
+		fmt.Printf("  %d › %s\n", exchange.Turn, truncateRunes(strings.TrimSpace(exchange.User), 60))
+		if answer := strings.TrimSpace(exchange.Assistant); answer != "" {
+			fmt.Printf("    \033[38;5;244m%s\033[0m\n", truncateRunes(strings.Join(strings.Fields(answer), " "), 160))
+		}
+	}
+}
+
+// restoreResponseHistory は show で展開する応答を切り替えた分岐のものにする
+func (h *ChatHandler) restoreResponseHistory(b *branch.Branch) {
+	h.responseHistory = nil
+	for _, exchange := range branch.Exchanges(b, 0) {
+		if exchange.Assistant != "" {
+			h.rememberResponse(exchange.Assistant)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/input"
+	"github.com/glkt/vyb-code/internal/markdown"
+)
+
+// copyCommand は /copy [n] を処理（直前の応答の n 番目のコードブロック、省略時は最後をコピー）
+func (h *ChatHandler) copyCommand(command string) {
+	arg := strings.TrimSpace(strings.TrimPrefix(command, "/copy"))
+	n := 0
+	if arg != "" {
+		var err error
+		if n, err = strconv.Atoi(arg); err != nil || n < 1 {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("copy.usage"))
+			return
+		}
+	}
+
+	message, err := h.copyCodeBlock(n)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;46m%s\033[0m\n\n", message)
+}
+
+// copyKey は入力中の Ctrl+X y / Ctrl+X <1-9> で呼ばれ、結果を1行で返す
+func (h *ChatHandler) copyKey(n int) string {
+	message, err := h.copyCodeBlock(n)
+	if err != nil {
+		return err.Error()
+	}
+	return message
+}
+
+// copyCodeBlock は直前の応答の n 番目（1始まり、0 は最後）のコードブロックをクリップボードにコピーする
+func (h *ChatHandler) copyCodeBlock(n int) (string, error) {
+	if len(h.responseHistory) == 0 {
+		return "", fmt.Errorf("%s", i18n.T("chat.no_previous_response"))
+	}
+	blocks := markdown.CodeBlocks(h.responseHistory[len(h.responseHistory)-1])
+	if len(blocks) == 0 {
+		return "", fmt.Errorf("%s", i18n.T("copy.no_blocks"))
+	}
+	if n == 0 {
+		n = len(blocks)
+	}
+	if n > len(blocks) {
+		return "", fmt.Errorf("%s", i18n.T("copy.out_of_range", n, len(blocks)))
+	}
+
+	block := blocks[n-1]
+	methods, err := input.WriteClipboard(block.Code + "\n")
+	if err != nil {
+		return "", err
+	}
+	language := block.Language
+	if language == "" {
+		language = "text"
+	}
+	lines := strings.Count(block.Code, "\n") + 1
+	return i18n.T("copy.copied", n, len(blocks), language, lines, strings.Join(methods, ", ")), nil
+}
+
+// pasteAttachments は入力行に目印が残っている貼り付けを添付用に整形する（1ターンで消費）
+// 添付結果はヘッドレス出力を汚さないようstderrに表示
+func (h *ChatHandler) pasteAttachments(query string) string {
+	pastes := h.pendingPastes
+	h.pendingPastes = nil
+	for _, paste := range pastes {
+		if strings.Contains(query, paste.Placeholder()) {
+			fmt.Fprintf(os.Stderr, "📎 %s\n", i18n.T("paste.attached", paste.Placeholder(), len(paste.Text)))
+		}
+	}
+	return input.FormatPastes(query, pastes)
+}
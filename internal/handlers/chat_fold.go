@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// foldedOutput は折り畳んで表示したコマンド出力（/expand でページ単位に展開）
+type foldedOutput struct {
+	lines []string
+	page  int // 次に表示するページ（0始まり）
+}
+
+// printOutput はコマンドの出力を表示する（長い出力は先頭・末尾だけ表示して折り畳む）
+// ペイン構成のTUIでは折り畳みをTUIが行うため、そのまま出力する
+func (h *ChatHandler) printOutput(content string) {
+	if content == "" {
+		return
+	}
+	fold := h.streamingManager.FoldConfig()
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+	if h.inTUI || !fold.Folds(len(lines)) {
+		fmt.Print(content)
+		if !strings.HasSuffix(content, "\n") {
+			fmt.Println()
+		}
+		return
+	}
+
+	head, hidden, tail := fold.Fold(lines)
+	fmt.Println(strings.Join(head, "\n"))
+	fmt.Printf("\033[38;5;214m  ⋯ %s\033[0m\n", i18n.T("fold.hidden", hidden, len(lines)))
+	fmt.Println(strings.Join(tail, "\n"))
+	h.folded = &foldedOutput{lines: lines}
+}
+
+// expandCommand は /expand（次のページ）と /expand all（全て）で最後に折り畳んだ出力を表示する
+func (h *ChatHandler) expandCommand(input string) {
+	if h.folded == nil {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("fold.none"))
+		return
+	}
+	lines := h.folded.lines
+	if strings.TrimSpace(strings.TrimPrefix(input, "/expand")) == "all" {
+		fmt.Printf("\n%s\n\n", strings.Join(lines, "\n"))
+		h.folded.page = 0
+		return
+	}
+
+	fold := h.streamingManager.FoldConfig()
+	pages := fold.Pages(len(lines))
+	start, end := fold.Page(len(lines), h.folded.page)
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("fold.page", h.folded.page+1, pages, start+1, end, len(lines)))
+	fmt.Println(strings.Join(lines[start:end], "\n"))
+	h.folded.page++
+	if h.folded.page < pages {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("fold.next_page"))
+		return
+	}
+	h.folded.page = 0
+	fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("fold.end"))
+}
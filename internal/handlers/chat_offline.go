@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"golang.org/x/term"
+)
+
+// providerProbeTimeout はLLMへの到達確認の待ち時間
+const providerProbeTimeout = 3 * time.Second
+
+// probeProvider はLLMのエンドポイントのいずれかに到達できるか確認する
+func (h *ChatHandler) probeProvider() error {
+	if h.resilientProvider == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), providerProbeTimeout)
+	defer cancel()
+	return h.resilientProvider.Probe(ctx)
+}
+
+// checkProviderAvailability は起動時にLLMへの到達を確認し、到達できなければオフラインで続けるか確認する
+// オフライン中もスラッシュコマンド・!command 等のローカルの操作は使え、モデルへの入力は保留される
+func (h *ChatHandler) checkProviderAvailability() error {
+	err := h.probeProvider()
+	if err == nil {
+		return nil
+	}
+
+	fmt.Printf("\n\033[38;5;214m%s\033[0m\n", i18n.T("offline.unreachable", err))
+	fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("offline.capabilities"))
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Print(i18n.T("offline.prompt"))
+		answer := strings.ToLower(strings.TrimSpace(readLine()))
+		if answer != "" && answer != "y" && answer != "yes" && answer != "はい" {
+			return fmt.Errorf("LLMに接続できません: %w", err)
+		}
+	}
+
+	h.offline = true
+	h.log.Warn("LLM unreachable, starting in offline mode", map[string]interface{}{"error": err.Error()})
+	fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.started"))
+	return nil
+}
+
+// queueWhileOffline はオフライン中の入力を保留する（保留した場合は true）
+// モデルが復帰していればオンラインに戻してそのまま処理させる
+func (h *ChatHandler) queueWhileOffline(input string) bool {
+	if !h.offline {
+		return false
+	}
+	if h.probeProvider() == nil {
+		h.goOnline()
+		return false
+	}
+	h.queuePrompt(input)
+	return true
+}
+
+// queueAfterFailure はターンの失敗がLLMに到達できないためなら、オフラインに切り替えて入力を保留する
+func (h *ChatHandler) queueAfterFailure(input string) bool {
+	err := h.probeProvider()
+	if err == nil {
+		return false
+	}
+	h.offline = true
+	h.log.Warn("LLM became unreachable, switching to offline mode", map[string]interface{}{"error": err.Error()})
+	fmt.Printf("\033[38;5;214m%s\033[0m\n", i18n.T("offline.lost", err))
+	h.queuePrompt(input)
+	return true
+}
+
+// queuePrompt は入力をモデルの復帰後に送るよう保留する
+func (h *ChatHandler) queuePrompt(input string) {
+	h.queuedPrompts = append(h.queuedPrompts, input)
+	fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.queued", len(h.queuedPrompts)))
+}
+
+// goOnline はモデルの復帰を知らせ、保留中の入力があれば送り方を案内する
+func (h *ChatHandler) goOnline() {
+	h.offline = false
+	h.log.Info("LLM reachable again, leaving offline mode", map[string]interface{}{"queued": len(h.queuedPrompts)})
+	fmt.Printf("\n\033[38;5;46m%s\033[0m\n", i18n.T("offline.restored"))
+	if len(h.queuedPrompts) > 0 {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("offline.queue_hint", len(h.queuedPrompts)))
+	}
+}
+
+// offlineCommand は /offline（オフラインの状態表示とモデルへの再接続）を処理
+func (h *ChatHandler) offlineCommand() {
+	if !h.offline {
+		fmt.Printf("\n\033[38;5;46m%s\033[0m\n\n", i18n.T("offline.online"))
+		return
+	}
+	if err := h.probeProvider(); err != nil {
+		fmt.Printf("\n\033[38;5;214m%s\033[0m\n", i18n.T("offline.unreachable", err))
+		fmt.Printf("\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.capabilities"))
+		return
+	}
+	h.goOnline()
+	fmt.Println()
+}
+
+// queueCommand は /queue（保留中の入力の一覧）、/queue run（順に送る）、/queue clear を処理
+func (h *ChatHandler) queueCommand(sessionID, input string) {
+	switch strings.TrimSpace(strings.TrimPrefix(input, "/queue")) {
+	case "":
+		if len(h.queuedPrompts) == 0 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.queue_empty"))
+			return
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("offline.queue_title", len(h.queuedPrompts)))
+		for i, prompt := range h.queuedPrompts {
+			fmt.Printf("  %d › %s\n", i+1, truncateRunes(prompt, 70))
+		}
+		fmt.Println()
+	case "clear":
+		count := len(h.queuedPrompts)
+		h.queuedPrompts = nil
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.queue_cleared", count))
+	case "run":
+		if len(h.queuedPrompts) == 0 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.queue_empty"))
+			return
+		}
+		if err := h.probeProvider(); err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("offline.still_unreachable", err))
+			return
+		}
+		h.offline = false
+		pending := h.queuedPrompts
+		h.queuedPrompts = nil
+		for i, prompt := range pending {
+			h.runTurn(sessionID, h.cfg, prompt)
+			// 途中で再び到達できなくなったら、失敗した入力（runTurn で保留済み）の後に残りを戻す
+			if h.offline {
+				h.queuedPrompts = append(h.queuedPrompts, pending[i+1:]...)
+				return
+			}
+		}
+	default:
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("offline.queue_usage"))
+	}
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+func newOfflineTestHandler(t *testing.T, baseURL string) *ChatHandler {
+	t.Helper()
+	logConfig := logger.DefaultConfig()
+	logConfig.Level = logger.ErrorLevel
+	log, err := logger.NewLogger(logConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoints := []llm.Endpoint{{Name: "ollama", Provider: llm.NewOllamaClient(baseURL)}}
+	return &ChatHandler{log: log, resilientProvider: llm.NewResilientProvider(endpoints, config.DefaultResilienceConfig())}
+}
+
+// TestOfflineQueue はモデルに到達できない間は入力が保留され、復帰すると通常の処理に戻ることをテストする
+func TestOfflineQueue(t *testing.T) {
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+
+	h := newOfflineTestHandler(t, down.URL)
+	h.offline = true
+	if !h.queueWhileOffline("explain main.go") {
+		t.Fatal("オフライン中は保留するはず")
+	}
+	// オンラインのつもりで失敗したターンも、到達できなければ保留してオフラインになる
+	h.offline = false
+	if !h.queueAfterFailure("add tests") || !h.offline {
+		t.Fatal("到達できなければオフラインに切り替えるはず")
+	}
+	if len(h.queuedPrompts) != 2 || h.queuedPrompts[0] != "explain main.go" {
+		t.Errorf("queued = %v", h.queuedPrompts)
+	}
+	// 到達できない間は /queue run で送らない
+	h.queueCommand("session", "/queue run")
+	if len(h.queuedPrompts) != 2 || !h.offline {
+		t.Errorf("queued = %v, offline = %t", h.queuedPrompts, h.offline)
+	}
+
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"version":"0.5.0"}`))
+	}))
+	defer up.Close()
+	h.resilientProvider = newOfflineTestHandler(t, up.URL).resilientProvider
+	if h.queueWhileOffline("next prompt") || h.offline {
+		t.Error("復帰したら保留せずにオンラインに戻るはず")
+	}
+	if h.queueAfterFailure("bad request") {
+		t.Error("到達できる場合の失敗は保留しないはず")
+	}
+
+	h.queueCommand("session", "/queue clear")
+	if len(h.queuedPrompts) != 0 {
+		t.Errorf("queued = %v", h.queuedPrompts)
+	}
+}
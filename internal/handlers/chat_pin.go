@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// pinManager はファイルを固定できるセッション管理
+type pinManager interface {
+	PinFiles(sessionID string, paths ...string) ([]string, error)
+	UnpinFiles(sessionID string, paths ...string) ([]string, error)
+	PinnedFiles(sessionID string) []string
+}
+
+// PinAtStart は開始するセッションに固定するファイルを指定する（vyb chat <file...>）
+func (h *ChatHandler) PinAtStart(paths []string) error {
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return fmt.Errorf("ファイルが見つかりません: %s", path)
+		}
+		if info.IsDir() {
+			return fmt.Errorf("ディレクトリは固定できません: %s", path)
+		}
+	}
+	h.startPins = paths
+	return nil
+}
+
+// applyPins は開始したセッションに vyb chat <file...> のファイルを固定する
+func (h *ChatHandler) applyPins(sessionID string) {
+	if len(h.startPins) == 0 {
+		return
+	}
+	manager, ok := h.interactiveManager.(pinManager)
+	if !ok {
+		fmt.Printf("\033[38;5;214m%s\033[0m\n", i18n.T("pin.unavailable"))
+		return
+	}
+	if _, err := manager.PinFiles(sessionID, h.startPins...); err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	h.log.Info("Files pinned", map[string]interface{}{"files": h.startPins})
+}
+
+// pinCommand は /pin [file...]（引数なしで一覧）と /unpin [file...]（引数なしで全て解除）を処理する
+func (h *ChatHandler) pinCommand(sessionID, input string) {
+	manager, ok := h.interactiveManager.(pinManager)
+	if !ok {
+		fmt.Printf("\n\033[38;5;214m%s\033[0m\n\n", i18n.T("pin.unavailable"))
+		return
+	}
+	fields := strings.Fields(input)
+	command, paths := fields[0], fields[1:]
+
+	if command == "/unpin" {
+		if _, err := manager.UnpinFiles(sessionID, paths...); err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		if len(paths) == 0 {
+			fmt.Printf("\n%s\n\n", i18n.T("pin.all_removed"))
+		} else {
+			fmt.Printf("\n%s\n\n", i18n.T("pin.unpinned", strings.Join(paths, ", ")))
+		}
+		return
+	}
+
+	if len(paths) == 0 {
+		pinned := manager.PinnedFiles(sessionID)
+		if len(pinned) == 0 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("pin.none"))
+			return
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n\n", i18n.T("pin.status", strings.Join(pinned, ", ")))
+		return
+	}
+	if _, err := manager.PinFiles(sessionID, paths...); err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;46m%s\033[0m\n\n", i18n.T("pin.pinned", strings.Join(paths, ", ")))
+}
+
+// pinnedStatus は固定中のファイルを示すステータス行（固定がなければ空）
+func (h *ChatHandler) pinnedStatus(sessionID string) string {
+	manager, ok := h.interactiveManager.(pinManager)
+	if !ok {
+		return ""
+	}
+	pinned := manager.PinnedFiles(sessionID)
+	if len(pinned) == 0 {
+		return ""
+	}
+	return i18n.T("pin.status", strings.Join(pinned, ", "))
+}
+
+// contextPinManager はコンテキスト項目を固定できるセッション管理
+type contextPinManager interface {
+	PinContext(sessionID, target string) (string, error)
+	UnpinContext(sessionID, target string) error
+	PinnedContext(sessionID string) []contextmanager.ContextItem
+	PinnedFiles(sessionID string) []string
+}
+
+// pinContextCommand は /pin-context [ID|ファイル|メモ]（引数なしで一覧）と /unpin-context <ID|ファイル> を処理する
+func (h *ChatHandler) pinContextCommand(sessionID, input string) {
+	manager, ok := h.interactiveManager.(contextPinManager)
+	if !ok {
+		fmt.Printf("\n\033[38;5;214m%s\033[0m\n\n", i18n.T("pin_context.unavailable"))
+		return
+	}
+	command, target, _ := strings.Cut(input, " ")
+	target = strings.TrimSpace(target)
+
+	if command == "/unpin-context" {
+		if target == "" {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("pin_context.usage"))
+			return
+		}
+		if err := manager.UnpinContext(sessionID, target); err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		fmt.Printf("\n%s\n\n", i18n.T("pin_context.unpinned", target))
+		return
+	}
+
+	if target == "" {
+		items, files := manager.PinnedContext(sessionID), manager.PinnedFiles(sessionID)
+		if len(items) == 0 && len(files) == 0 {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("pin_context.none"))
+			return
+		}
+		fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("pin_context.title"))
+		for _, item := range items {
+			fmt.Printf("  %-28s %s\n", item.ID, truncateRunes(item.Content, 80))
+		}
+		for _, file := range files {
+			fmt.Printf("  %-28s \033[38;5;244m%s\033[0m\n", file, i18n.T("pin_context.file"))
+		}
+		fmt.Println()
+		return
+	}
+
+	id, err := manager.PinContext(sessionID, target)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;46m%s\033[0m\n\n", i18n.T("pin_context.pinned", id))
+}
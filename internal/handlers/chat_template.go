@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/templates"
+)
+
+// templateManager はセッションテンプレートを適用できるセッション管理
+type templateManager interface {
+	SetSessionTemplate(sessionID string, tmpl *templates.Template) error
+}
+
+// SetTemplate は開始するセッションに適用するテンプレートを読み込む（vyb --template、.vyb/templates を組み込みより優先）
+func (h *ChatHandler) SetTemplate(name string) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	tmpl, err := templates.Load(templates.Dir(projectDir), name)
+	if err != nil {
+		return err
+	}
+	h.template = tmpl
+	return nil
+}
+
+// applyTemplate は開始したセッションにテンプレートを適用する（適用した場合は true）
+func (h *ChatHandler) applyTemplate(sessionID string) bool {
+	if h.template == nil {
+		return false
+	}
+	manager, ok := h.interactiveManager.(templateManager)
+	if !ok {
+		fmt.Printf("\033[38;5;214m%s\033[0m\n", i18n.T("template.unavailable"))
+		return false
+	}
+	if err := manager.SetSessionTemplate(sessionID, h.template); err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return false
+	}
+	h.log.Info("Session template applied", map[string]interface{}{"template": h.template.Name, "source": h.template.Source()})
+	return true
+}
+
+// startTemplate は作業計画を表示し、開始時のタスク（bugfix ならテスト）を実行する
+// タスクの結果はセッションのコンテキストに入るため、最初のターンから前提として使われる
+func (h *ChatHandler) startTemplate(sessionID string) {
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("template.applied", h.template.Name, h.template.Description))
+	if len(h.template.Plan) > 0 {
+		fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("template.plan"))
+		for i, step := range h.template.Plan {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+	for _, kind := range h.template.Tasks() {
+		fmt.Printf("\033[38;5;244m%s\033[0m", i18n.T("template.run_first", kind))
+		h.runProjectTask(sessionID, kind)
+	}
+	if len(h.template.Tasks()) == 0 {
+		fmt.Println()
+	}
+}
@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// titledManager はセッションの題名を扱えるセッション管理
+type titledManager interface {
+	SessionTitle(sessionID string) string
+	SetSessionTitle(sessionID, title string) error
+}
+
+// titleCommand は /title（現在の題名を表示）と /title <題名>（題名を変更）を処理する
+func (h *ChatHandler) titleCommand(sessionID, input string) {
+	manager, ok := h.interactiveManager.(titledManager)
+	if !ok {
+		fmt.Printf("\n\033[38;5;214m%s\033[0m\n\n", i18n.T("title.unavailable"))
+		return
+	}
+
+	title := strings.TrimSpace(strings.TrimPrefix(input, "/title"))
+	if title == "" {
+		if current := manager.SessionTitle(sessionID); current != "" {
+			fmt.Printf("\n\033[38;5;27m%s\033[0m\n\n", i18n.T("title.current", current))
+		} else {
+			fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("title.none"))
+		}
+		return
+	}
+	if err := manager.SetSessionTitle(sessionID, title); err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n\033[38;5;46m%s\033[0m\n\n", i18n.T("title.renamed", manager.SessionTitle(sessionID)))
+}
+
+// sessionTitle はセッションの題名（TUIのヘッダーに表示、まだなければ空）
+func (h *ChatHandler) sessionTitle(sessionID string) string {
+	if manager, ok := h.interactiveManager.(titledManager); ok {
+		return manager.SessionTitle(sessionID)
+	}
+	return ""
+}
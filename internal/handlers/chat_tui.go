@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/term"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/input"
+	"github.com/glkt/vyb-code/internal/jobs"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/transcript"
+	"github.com/glkt/vyb-code/internal/tui"
+)
+
+// tuiEnabled はペイン構成のTUIを使うか（設定・--no-tui で無効、非対話端末では常に逐次表示）
+func (h *ChatHandler) tuiEnabled(cfg *config.Config) bool {
+	return cfg != nil && cfg.TUI.Enabled && term.IsTerminal(int(os.Stdin.Fd())) && term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// runTUI はペイン構成のTUIでセッションを進める
+func (h *ChatHandler) runTUI(sessionID string, cfg *config.Config, templated bool) error {
+	backend := &tuiBackend{handler: h, sessionID: sessionID, model: cfg.ResolvedModel(), completer: input.NewAdvancedCompleter("."), templated: templated}
+	if lister, ok := h.interactiveManager.(toolLister); ok {
+		backend.completer.SetToolNames(lister.ToolNames)
+	}
+	h.followWorkDir(backend.completer.SetWorkDir)
+	h.inTUI = true
+	defer func() { h.inTUI = false }()
+	if err := tui.Run(backend, "vyb · "+backend.model, h.streamingManager.FoldConfig()); err != nil {
+		return fmt.Errorf("TUI実行エラー: %w", err)
+	}
+	fmt.Printf("%s\n", i18n.T("chat.goodbye"))
+	return nil
+}
+
+// tuiBackend はチャットハンドラーのセッションをTUIから操作する
+type tuiBackend struct {
+	handler   *ChatHandler
+	sessionID string
+	model     string
+	completer *input.AdvancedCompleter
+	templated bool // 起動後にテンプレートの作業計画・開始時のタスクを表示・実行する
+}
+
+// Start はテンプレートの作業計画を表示し、開始時のタスクを実行する
+func (b *tuiBackend) Start() {
+	if b.templated {
+		b.handler.startTemplate(b.sessionID)
+	}
+}
+
+// CopyCodeBlock は直前の応答のコードブロックをコピーする（Ctrl+Y）
+func (b *tuiBackend) CopyCodeBlock(n int) string {
+	return b.handler.copyKey(n)
+}
+
+// Status は固定中のファイル（タブの横に表示）
+func (b *tuiBackend) Status() string {
+	return b.handler.pinnedStatus(b.sessionID)
+}
+
+// Title はセッションの題名（ヘッダーに表示）
+func (b *tuiBackend) Title() string {
+	return b.handler.sessionTitle(b.sessionID)
+}
+
+// Tree は作業ディレクトリのディレクトリツリー（ファイルペインで t）
+func (b *tuiBackend) Tree() []string {
+	tree, err := tools.BuildTree(".", tools.TreeOptions{})
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return tree.FullLines()
+}
+
+// Submit はスラッシュコマンドを実行、それ以外は1ターン処理する（出力はTUIが取り込む）
+func (b *tuiBackend) Submit(ctx context.Context, input string) error {
+	h := b.handler
+	if h.handleLocalCommand(b.sessionID, input) || h.queueWhileOffline(input) {
+		return nil
+	}
+
+	startTime := time.Now()
+	if h.perfMonitor != nil {
+		h.perfMonitor.RecordProactiveUsage("chat_request")
+	}
+	response, err := h.interactiveManager.ProcessUserInput(h.turnContext(ctx, b.model, input), b.sessionID, input)
+	if h.perfMonitor != nil {
+		duration := time.Since(startTime)
+		h.perfMonitor.RecordResponseTime(duration)
+		h.perfMonitor.RecordLLMLatency(duration)
+	}
+	if err != nil {
+		if h.queueAfterFailure(input) {
+			return nil
+		}
+		return err
+	}
+	h.rememberResponse(response.Message)
+	return nil
+}
+
+func (b *tuiBackend) Transcript() *transcript.Transcript {
+	exporter, ok := b.handler.interactiveManager.(transcriptExporter)
+	if !ok {
+		return nil
+	}
+	record, err := exporter.Transcript(b.sessionID)
+	if err != nil {
+		return nil
+	}
+	return record
+}
+
+func (b *tuiBackend) Jobs() []jobs.Info {
+	if controller, ok := b.handler.interactiveManager.(jobController); ok {
+		return controller.Jobs()
+	}
+	return nil
+}
+
+func (b *tuiBackend) JobOutput(id int) string {
+	controller, ok := b.handler.interactiveManager.(jobController)
+	if !ok {
+		return ""
+	}
+	_, output, err := controller.JobOutput(id, jobTailBytes)
+	if err != nil {
+		return ""
+	}
+	return output
+}
+
+func (b *tuiBackend) KillJob(id int) error {
+	controller, ok := b.handler.interactiveManager.(jobController)
+	if !ok {
+		return fmt.Errorf("バックグラウンドジョブは利用できません")
+	}
+	_, err := controller.KillJob(id)
+	return err
+}
+
+// Complete はカーソル位置の単語（スラッシュコマンド・ファイルパス・ブランチ・ツール名）を補完
+func (b *tuiBackend) Complete(line []rune, cursor int) ([]rune, int, []string) {
+	line, cursor, candidates := b.completer.CompleteLine(line, cursor)
+	var texts []string
+	for _, candidate := range candidates {
+		texts = append(texts, candidate.Text)
+	}
+	return line, cursor, texts
+}
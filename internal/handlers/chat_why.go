@@ -0,0 +1,64 @@
+package handlers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/interactive"
+)
+
+// provenanceManager は提案の根拠を記録するセッション管理
+type provenanceManager interface {
+	SuggestionProvenance(sessionID, suggestionID string) (*interactive.SuggestionProvenance, error)
+	ProvenanceHistory(sessionID string) []*interactive.SuggestionProvenance
+}
+
+// whyCommand は /why（最新の提案の根拠）、/why list（提案の一覧）、/why <ID> を処理
+func (h *ChatHandler) whyCommand(sessionID, input string) {
+	manager, ok := h.interactiveManager.(provenanceManager)
+	if !ok {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), i18n.T("why.unavailable"))
+		return
+	}
+
+	fields := strings.Fields(strings.TrimPrefix(input, "/why"))
+	if len(fields) > 1 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("why.usage"))
+		return
+	}
+	if len(fields) == 1 && fields[0] == "list" {
+		h.showProvenanceHistory(manager.ProvenanceHistory(sessionID))
+		return
+	}
+
+	suggestionID := ""
+	if len(fields) == 1 {
+		suggestionID = fields[0]
+	}
+	provenance, err := manager.SuggestionProvenance(sessionID, suggestionID)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	fmt.Printf("\n%s\n", provenance.Format())
+}
+
+// showProvenanceHistory は根拠を記録した提案を新しい順に表示
+func (h *ChatHandler) showProvenanceHistory(history []*interactive.SuggestionProvenance) {
+	if len(history) == 0 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("why.none"))
+		return
+	}
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("why.list_title"))
+	for i := len(history) - 1; i >= 0; i-- {
+		record := history[i]
+		status := i18n.T("why.not_applied")
+		if record.AppliedAt != nil {
+			status = i18n.T("why.applied", record.AppliedAt.Format("15:04:05"))
+		}
+		fmt.Printf("  %s  %.2f  %s  \033[38;5;244m%s\033[0m\n", record.SuggestionID, record.Confidence.Score,
+			truncateRunes(strings.TrimSpace(record.Input), 50), status)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/workspace"
+)
+
+// workspaceEnterer はモジュール切替をセッションに反映できるセッション管理
+type workspaceEnterer interface {
+	EnterWorkspace(sessionID, module, dir string) error
+}
+
+// followWorkDir は /workspace で作業ディレクトリが変わった時の通知先（入力補完等）を登録
+func (h *ChatHandler) followWorkDir(follower func(dir string)) {
+	h.workDirFollowers = append(h.workDirFollowers, follower)
+}
+
+// workspaceCommand は /workspace（モジュール一覧）、/workspace <module>（切替）を処理
+// 切替は作業ディレクトリの移動で行い、分析・ビルド・ファイル操作・入力補完をモジュールに限定する
+func (h *ChatHandler) workspaceCommand(sessionID, input string) {
+	arg := strings.TrimSpace(strings.TrimPrefix(input, "/workspace"))
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+	ws, err := workspace.Detect(workDir)
+	if err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	if arg == "" {
+		h.listModules(ws, workDir)
+		return
+	}
+
+	label, dir := ".", ws.Root
+	if arg != "/" {
+		module, err := ws.Find(arg)
+		if err != nil {
+			fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+			return
+		}
+		label, dir = module.Path, module.Dir
+	}
+	if err := os.Chdir(dir); err != nil {
+		fmt.Printf("\n\033[38;5;196m%s\033[0m\n%s\n\n", i18n.T("chat.error"), err.Error())
+		return
+	}
+
+	h.completer.SetWorkDir(dir)
+	for _, follower := range h.workDirFollowers {
+		follower(dir)
+	}
+	if enterer, ok := h.interactiveManager.(workspaceEnterer); ok {
+		if err := enterer.EnterWorkspace(sessionID, label, dir); err != nil {
+			h.log.Warn("モジュール切替をセッションに反映できません", map[string]interface{}{"error": err.Error()})
+		}
+	}
+	fmt.Printf("\n\033[38;5;34m%s\033[0m\n\n", i18n.T("workspace.switched", label, dir))
+}
+
+// listModules はワークスペースのモジュール一覧を表示（現在のモジュールに * を付ける）
+func (h *ChatHandler) listModules(ws *workspace.Workspace, workDir string) {
+	if len(ws.Modules) == 0 {
+		fmt.Printf("\n\033[38;5;244m%s\033[0m\n\n", i18n.T("workspace.none", ws.Root))
+		return
+	}
+	active := ws.Active(workDir)
+	fmt.Printf("\n\033[38;5;27m%s\033[0m\n", i18n.T("workspace.title", ws.Root))
+	for _, module := range ws.Modules {
+		marker := " "
+		if active != nil && active.Dir == module.Dir {
+			marker = "*"
+		}
+		fmt.Printf("  %s %-28s %-4s %s\n", marker, module.Path, module.Kind, module.Name)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,457 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/batch"
+	"github.com/glkt/vyb-code/internal/cifix"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/review"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/spf13/cobra"
+)
+
+// CIFixHandler は CI の失敗ログから修正を作る（vyb ci-fix）ハンドラー
+type CIFixHandler struct {
+	log logger.Logger
+}
+
+// NewCIFixHandler は ci-fix ハンドラーを作成
+func NewCIFixHandler(log logger.Logger) *CIFixHandler {
+	return &CIFixHandler{log: log}
+}
+
+// CIFixOptions は ci-fix の指定内容
+type CIFixOptions struct {
+	Profile   string
+	Log       string // ログファイル（"-" なら標準入力）
+	Run       int64  // GitHub Actions のワークフロー実行ID
+	Job       string // 失敗したジョブのうち修正するもの（名前）
+	Repo      string // owner/repo（既定は GITHUB_REPOSITORY・origin）
+	Check     string // auto, none, build, test, lint または任意のコマンド
+	Timeout   string
+	PR        bool   // ブランチを push してPRを作成する
+	Draft     bool   // PRを下書きで作成する
+	Base      string // PRのマージ先（既定は失敗した実行のブランチ・現在のブランチ）
+	OutputDir string
+	DryRun    bool
+	JSON      bool
+}
+
+// CIFixReport は ci-fix の結果（--json の出力）
+type CIFixReport struct {
+	Diagnosis   *cifix.Diagnosis  `json:"diagnosis"`
+	Prompt      string            `json:"prompt,omitempty"`
+	Result      *batch.ItemResult `json:"result,omitempty"`
+	PullRequest string            `json:"pull_request,omitempty"`
+}
+
+// ciLog は取得した失敗ログ
+type ciLog struct {
+	source string
+	text   string
+	repo   string
+	run    *review.WorkflowRun
+}
+
+// Run は失敗ログを解析し、専用の worktree でエージェントに修正させてブランチ（--pr ならPR）を作る
+func (h *CIFixHandler) Run(opts CIFixOptions) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	if err := exec.Command("git", "-C", projectDir, "rev-parse", "--git-dir").Run(); err != nil {
+		return fmt.Errorf("git リポジトリではありません: %w", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	github := review.NewGitHubClient()
+	log, err := h.fetchLog(ctx, github, projectDir, opts)
+	if err != nil {
+		return err
+	}
+	diagnosis := cifix.Diagnose(projectDir, log.source, log.text)
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	memory, _ := config.LoadProjectMemory("")
+	prompt, err := diagnosis.Prompt(prompts.DefaultRegistry(), cfg.Language, cfg.ResolvedModel(), memory)
+	if err != nil {
+		return err
+	}
+
+	report := &CIFixReport{Diagnosis: diagnosis}
+	if opts.DryRun {
+		report.Prompt = prompt
+		if opts.JSON {
+			return writeCIFixJSON(report)
+		}
+		printDiagnosis(diagnosis)
+		fmt.Printf("\n\033[38;5;244m--- prompt ---\033[0m\n%s\n", prompt)
+		return nil
+	}
+
+	check := opts.Check
+	switch check {
+	case "", "auto":
+		check = diagnosis.Check()
+	case "none":
+		check = ""
+	}
+	id := "log"
+	if log.run != nil {
+		id = fmt.Sprintf("run-%d", log.run.ID)
+	}
+	id += "-" + time.Now().Format("0102-150405")
+	def := &batch.Definition{
+		Name:         "ci-fix",
+		Base:         "HEAD",
+		BranchPrefix: "vyb/ci-fix",
+		Check:        check,
+		Timeout:      opts.Timeout,
+		Items:        []batch.Item{{ID: id, Item: diagnosis.Title(), Prompt: batch.Literal(prompt)}},
+	}
+	if err := def.Validate(); err != nil {
+		return err
+	}
+
+	vybPath, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("vyb の実行ファイルが見つかりません: %w", err)
+	}
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return fmt.Errorf("実行環境の初期化エラー: %w", err)
+	}
+	outputDir := opts.OutputDir
+	if outputDir == "" {
+		outputDir = filepath.Join(projectDir, config.ProjectConfigDir, "ci-fix", id)
+	}
+	runner := &batch.Runner{
+		ProjectDir: projectDir,
+		OutputDir:  outputDir,
+		Agent:      batchAgent(vybPath, opts.Profile),
+		Check:      batchCheck(backend),
+		CommitMessage: func(item batch.Item, prompt string) string {
+			return ciFixCommitMessage(diagnosis)
+		},
+	}
+	if !opts.JSON {
+		printDiagnosis(diagnosis)
+		fmt.Printf("\n▶️  修正中（%s、チェック: %s）…\n", def.Branch(def.Items[0]), valueOr(check, "なし"))
+	}
+	batchReport, err := runner.Run(ctx, def, def.Items)
+	if batchReport == nil {
+		return err
+	}
+	result := batchReport.Items[0]
+	report.Result = result
+	h.log.Info("CI fix completed", map[string]interface{}{
+		"source": diagnosis.Source,
+		"status": result.Status,
+		"branch": result.Branch,
+	})
+
+	if opts.PR && result.Branch != "" {
+		url, prErr := h.openPullRequest(ctx, github, projectDir, log, diagnosis, result, opts)
+		if prErr != nil {
+			err = prErr
+		}
+		report.PullRequest = url
+	}
+
+	if opts.JSON {
+		if jsonErr := writeCIFixJSON(report); jsonErr != nil {
+			return jsonErr
+		}
+	} else {
+		printCIFixResult(result, report.PullRequest, outputDir)
+	}
+	if err != nil {
+		return err
+	}
+	if result.Status == batch.StatusFailed {
+		return fmt.Errorf("修正に失敗しました: %s", result.Error)
+	}
+	if result.Status == batch.StatusCheckFailed {
+		return fmt.Errorf("修正後のチェック（%s）に失敗しました", check)
+	}
+	return nil
+}
+
+// fetchLog は --log のファイル・標準入力、または --run の失敗したジョブのログを読む
+func (h *CIFixHandler) fetchLog(ctx context.Context, github *review.GitHubClient, projectDir string, opts CIFixOptions) (*ciLog, error) {
+	switch {
+	case opts.Log == "-":
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return nil, fmt.Errorf("ログ読み込みエラー: %w", err)
+		}
+		return &ciLog{source: "stdin", text: string(data)}, nil
+	case opts.Log != "":
+		data, err := os.ReadFile(opts.Log)
+		if err != nil {
+			return nil, fmt.Errorf("ログ読み込みエラー: %w", err)
+		}
+		return &ciLog{source: opts.Log, text: string(data)}, nil
+	case opts.Run == 0:
+		return nil, fmt.Errorf("--log（ファイル・- で標準入力）か --run（GitHub Actions の実行ID）を指定してください")
+	}
+
+	repo, err := ciRepo(ctx, projectDir, opts.Repo)
+	if err != nil {
+		return nil, err
+	}
+	run, err := github.WorkflowRun(ctx, repo, opts.Run)
+	if err != nil {
+		return nil, err
+	}
+	jobs, err := github.FailedJobs(ctx, repo, opts.Run)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, job := range jobs {
+		names = append(names, job.Name)
+		if opts.Job != "" && job.Name != opts.Job {
+			continue
+		}
+		text, err := github.JobLog(ctx, repo, job.ID)
+		if err != nil {
+			return nil, err
+		}
+		return &ciLog{source: fmt.Sprintf("%s / %s (%s)", run.Name, job.Name, job.HTMLURL), text: text, repo: repo, run: run}, nil
+	}
+	if opts.Job != "" {
+		return nil, fmt.Errorf("失敗したジョブ %s がありません（失敗したジョブ: %s）", opts.Job, valueOr(strings.Join(names, ", "), "なし"))
+	}
+	return nil, fmt.Errorf("実行 %d（%s）に失敗したジョブがありません", opts.Run, run.HTMLURL)
+}
+
+// ciRepo は owner/repo を --repo・GITHUB_REPOSITORY・origin の順に決める
+func ciRepo(ctx context.Context, projectDir, repo string) (string, error) {
+	if repo != "" {
+		return repo, nil
+	}
+	if repo = os.Getenv("GITHUB_REPOSITORY"); repo != "" {
+		return repo, nil
+	}
+	return review.RemoteRepo(ctx, projectDir)
+}
+
+// openPullRequest は修正のブランチを origin に push してPRを作成し、URLを返す（チェックに失敗したら下書き）
+func (h *CIFixHandler) openPullRequest(ctx context.Context, github *review.GitHubClient, projectDir string, log *ciLog, diagnosis *cifix.Diagnosis, result *batch.ItemResult, opts CIFixOptions) (string, error) {
+	repo := log.repo
+	if repo == "" {
+		var err error
+		if repo, err = ciRepo(ctx, projectDir, opts.Repo); err != nil {
+			return "", err
+		}
+	}
+	base := opts.Base
+	if base == "" && log.run != nil {
+		base = log.run.HeadBranch
+	}
+	if base == "" {
+		base = currentBranch(projectDir)
+	}
+	if base == "" {
+		return "", fmt.Errorf("PRのマージ先が分かりません（--base を指定してください）")
+	}
+
+	push := exec.CommandContext(ctx, "git", "push", "origin", result.Branch)
+	push.Dir = projectDir
+	if output, err := push.CombinedOutput(); err != nil {
+		return "", fmt.Errorf("git push エラー: %s", strings.TrimSpace(string(output)))
+	}
+	pr, err := github.CreatePullRequest(ctx, repo, review.NewPullRequest{
+		Title: diagnosis.Title(),
+		Head:  result.Branch,
+		Base:  base,
+		Body:  ciFixPullRequestBody(diagnosis, log.run, result),
+		Draft: opts.Draft || result.Status == batch.StatusCheckFailed,
+	})
+	if err != nil {
+		return "", fmt.Errorf("PR作成エラー: %w", err)
+	}
+	return pr.HTMLURL, nil
+}
+
+// currentBranch は現在のブランチ（detached HEAD の CI では GITHUB_HEAD_REF・GITHUB_REF_NAME）
+func currentBranch(projectDir string) string {
+	output, err := exec.Command("git", "-C", projectDir, "symbolic-ref", "--short", "-q", "HEAD").Output()
+	if branch := strings.TrimSpace(string(output)); err == nil && branch != "" {
+		return branch
+	}
+	if branch := os.Getenv("GITHUB_HEAD_REF"); branch != "" {
+		return branch
+	}
+	return os.Getenv("GITHUB_REF_NAME")
+}
+
+// ciFixCommitMessage は修正のコミットメッセージ（件名 + 失敗の一覧）
+func ciFixCommitMessage(diagnosis *cifix.Diagnosis) string {
+	var sb strings.Builder
+	sb.WriteString(diagnosis.Title() + "\n\n")
+	sb.WriteString("CI failure: " + diagnosis.Source + "\n")
+	if failures := diagnosis.FailureList(); failures != "" {
+		lines := strings.Split(failures, "\n")
+		if len(lines) > 10 {
+			lines = append(lines[:10], fmt.Sprintf("- ... and %d more", len(lines)-10))
+		}
+		sb.WriteString("\n" + strings.Join(lines, "\n") + "\n")
+	}
+	return sb.String()
+}
+
+// ciFixPullRequestBody はPRの本文（失敗・エージェントの説明・変更・チェック結果）
+func ciFixPullRequestBody(diagnosis *cifix.Diagnosis, run *review.WorkflowRun, result *batch.ItemResult) string {
+	var sb strings.Builder
+	sb.WriteString("Automated fix for a failing CI job, generated by `vyb ci-fix`. Review it before merging.\n\n")
+	sb.WriteString("## Failure\n")
+	if run != nil {
+		fmt.Fprintf(&sb, "- Run: [%s #%d](%s) on `%s` (%s)\n", run.Name, run.ID, run.HTMLURL, run.HeadBranch, shortSHA(run.HeadSHA))
+	}
+	fmt.Fprintf(&sb, "- Job: %s\n", diagnosis.Source)
+	if diagnosis.Step != "" {
+		fmt.Fprintf(&sb, "- Step: `%s`\n", strings.SplitN(diagnosis.Step, "\n", 2)[0])
+	}
+	if failures := diagnosis.FailureList(); failures != "" {
+		sb.WriteString("\n" + failures + "\n")
+	}
+	if message := strings.TrimSpace(result.Message); message != "" {
+		sb.WriteString("\n## Fix\n" + message + "\n")
+	}
+	sb.WriteString("\n## Changes\n")
+	for _, file := range result.Files {
+		fmt.Fprintf(&sb, "- `%s`\n", file)
+	}
+	fmt.Fprintf(&sb, "\n+%d -%d\n", result.Additions, result.Deletions)
+	if result.Status == batch.StatusCheckFailed {
+		sb.WriteString("\n## ⚠️ Local check failed\n```\n" + strings.TrimSpace(result.CheckOutput) + "\n```\n")
+	}
+	return sb.String()
+}
+
+func shortSHA(sha string) string {
+	if len(sha) > 7 {
+		return sha[:7]
+	}
+	return sha
+}
+
+func valueOr(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
+
+// printDiagnosis は失敗したステップ・エラー・特定したコードの箇所を表示する
+func printDiagnosis(diagnosis *cifix.Diagnosis) {
+	fmt.Printf("\033[1m%s\033[0m\n", diagnosis.Title())
+	fmt.Printf("\033[38;5;244mlog: %s\033[0m\n", diagnosis.Source)
+	if diagnosis.Step != "" {
+		fmt.Printf("step: %s\n", strings.SplitN(diagnosis.Step, "\n", 2)[0])
+	}
+	if failures := diagnosis.FailureList(); failures != "" {
+		fmt.Printf("\n%s\n", failures)
+	} else {
+		fmt.Println("\n構造化できるエラーはありません（ログの末尾を渡します）")
+	}
+	for _, location := range diagnosis.Locations {
+		line := ""
+		if location.Line > 0 {
+			line = fmt.Sprintf(":%d", location.Line)
+		}
+		fmt.Printf("  📍 %s%s\n", location.File, line)
+	}
+}
+
+// printCIFixResult は修正の結果とブランチ・PR・保存先を表示する
+func printCIFixResult(result *batch.ItemResult, pullRequest, outputDir string) {
+	fmt.Println()
+	printBatchItem(result)
+	if message := strings.TrimSpace(result.Message); message != "" && result.Status == batch.StatusNoChanges {
+		fmt.Printf("\n%s\n", message)
+	}
+	if result.Branch != "" {
+		fmt.Printf("ブランチ: %s\n", result.Branch)
+	}
+	if pullRequest != "" {
+		fmt.Printf("PR: %s\n", pullRequest)
+	}
+	fmt.Printf("パッチ・ログ・レポート: %s\n", outputDir)
+}
+
+func writeCIFixJSON(report *CIFixReport) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(report)
+}
+
+// CreateCIFixCommand は ci-fix コマンドを作成
+func (h *CIFixHandler) CreateCIFixCommand() *cobra.Command {
+	ciFixCmd := &cobra.Command{
+		Use:   "ci-fix",
+		Short: "Diagnose a failed CI job and fix it on a new branch (optionally opening a PR)",
+		Long: `Read a failed CI log, find the failed step, its errors and the code they point to, and let the agent fix the cause in an isolated git worktree.
+The fix is committed to vyb/ci-fix/<id>, checked with the build or tests (--check) and exported as a patch under .vyb/ci-fix/<id>.
+With --pr the branch is pushed to origin and a pull request is opened (a draft if the check still fails), which makes the command usable as a CI failure triage bot:
+
+  vyb ci-fix --log build.log                 # a saved log, or --log - for stdin
+  vyb ci-fix --run 123456789 --pr            # fetch the failed job of a GitHub Actions run (GITHUB_TOKEN)
+  vyb ci-fix --run 123456789 --dry-run       # only show the diagnosis and the prompt
+
+If the failure is not caused by the code (missing secret, network, flaky runner), the agent explains it and no branch is created.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts CIFixOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Log, _ = cmd.Flags().GetString("log")
+			opts.Run, _ = cmd.Flags().GetInt64("run")
+			opts.Job, _ = cmd.Flags().GetString("job")
+			opts.Repo, _ = cmd.Flags().GetString("repo")
+			opts.Check, _ = cmd.Flags().GetString("check")
+			opts.Timeout, _ = cmd.Flags().GetString("timeout")
+			opts.PR, _ = cmd.Flags().GetBool("pr")
+			opts.Draft, _ = cmd.Flags().GetBool("draft")
+			opts.Base, _ = cmd.Flags().GetString("base")
+			opts.OutputDir, _ = cmd.Flags().GetString("output-dir")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Run(opts)
+		},
+	}
+	ciFixCmd.Flags().String("log", "", "CI log file to diagnose (- reads stdin)")
+	ciFixCmd.Flags().Int64("run", 0, "GitHub Actions run ID whose failed job log is fetched")
+	ciFixCmd.Flags().String("job", "", "Name of the failed job to fix (default: the first failed job)")
+	ciFixCmd.Flags().String("repo", "", "GitHub repository owner/repo (default: GITHUB_REPOSITORY or the origin remote)")
+	ciFixCmd.Flags().String("check", "auto", "Check after the fix: auto (test for test failures, otherwise build), none, build, test, lint or a shell command")
+	ciFixCmd.Flags().String("timeout", "", "Time limit for the fix (default 15m)")
+	ciFixCmd.Flags().Bool("pr", false, "Push the branch to origin and open a pull request (GITHUB_TOKEN)")
+	ciFixCmd.Flags().Bool("draft", false, "Open the pull request as a draft")
+	ciFixCmd.Flags().String("base", "", "Base branch of the pull request (default: the branch of the failed run, or the current branch)")
+	ciFixCmd.Flags().String("output-dir", "", "Directory for the patch, agent log and report.json (default: .vyb/ci-fix/<id>)")
+	ciFixCmd.Flags().Bool("dry-run", false, "Only show the diagnosis and the prompt, without running the agent")
+	ciFixCmd.Flags().Bool("json", false, "Output the diagnosis and result as JSON")
+	return ciFixCmd
+}
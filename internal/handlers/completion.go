@@ -0,0 +1,473 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/eval"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/input"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/templates"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/version"
+	"github.com/glkt/vyb-code/internal/workflow"
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+)
+
+// completionTimeout は補完候補のためにLLMサーバーへ問い合わせる時間の上限（応答がなければ候補なし）
+const completionTimeout = 2 * time.Second
+
+// CompletionHandler はシェル補完スクリプトとマニュアルページの生成、動的な補完候補のハンドラー
+type CompletionHandler struct {
+	log      logger.Logger
+	auditDir string
+}
+
+// NewCompletionHandler は補完ハンドラーを作成（auditDirが空なら ~/.vyb/logs）
+func NewCompletionHandler(log logger.Logger, auditDir string) *CompletionHandler {
+	if auditDir == "" {
+		auditDir = logger.DefaultAuditDir()
+	}
+	return &CompletionHandler{log: log, auditDir: auditDir}
+}
+
+// GenerateCompletion はシェル用の補完スクリプトを出力
+func (h *CompletionHandler) GenerateCompletion(root *cobra.Command, shell string, descriptions bool) error {
+	out := os.Stdout
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(out, descriptions)
+	case "zsh":
+		if descriptions {
+			return root.GenZshCompletion(out)
+		}
+		return root.GenZshCompletionNoDesc(out)
+	case "fish":
+		return root.GenFishCompletion(out, descriptions)
+	case "powershell":
+		if descriptions {
+			return root.GenPowerShellCompletionWithDesc(out)
+		}
+		return root.GenPowerShellCompletion(out)
+	}
+	return fmt.Errorf("未対応のシェルです: %s（bash, zsh, fish, powershell のいずれかを指定してください）", shell)
+}
+
+// GenerateMan は全コマンドのマニュアルページ（vyb.1, vyb-config.1, ...）をディレクトリに出力
+func (h *CompletionHandler) GenerateMan(root *cobra.Command, dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("出力ディレクトリ作成エラー: %w", err)
+	}
+
+	// 生成日時の行を入れない（SOURCE_DATE_EPOCH があれば .TH の日付にも使われる）
+	root.DisableAutoGenTag = true
+	header := &doc.GenManHeader{
+		Section: "1",
+		Source:  "vyb " + version.GetVersion(),
+		Manual:  "vyb Manual",
+	}
+	if err := doc.GenManTree(root, header, dir); err != nil {
+		return fmt.Errorf("マニュアル生成エラー: %w", err)
+	}
+
+	pages, _ := filepath.Glob(filepath.Join(dir, "*.1"))
+	h.log.Info("マニュアルページを生成しました", map[string]interface{}{
+		"dir":   dir,
+		"pages": len(pages),
+	})
+	fmt.Printf("Wrote %d man pages to %s (view with: man -l %s)\n", len(pages), dir, filepath.Join(dir, "vyb.1"))
+	return nil
+}
+
+// RegisterCompletions はコマンドツリーの引数・フラグに動的な補完候補を登録する
+// （セッションID、モデル名、プロファイル、設定値、テンプレート等）。見つからないコマンドは無視する
+func (h *CompletionHandler) RegisterCompletions(root *cobra.Command) {
+	sessions := h.completeSessions
+	models := completeModels
+	boolean := cobra.FixedCompletions([]string{"true", "false"}, cobra.ShellCompDirectiveNoFileComp)
+
+	args := map[string]cobra.CompletionFunc{
+		"audit":        firstArgOnly(sessions),
+		"export":       firstArgOnly(sessions),
+		"replay":       firstArgOnly(sessions),
+		"history show": firstArgOnly(sessions),
+
+		"models pull":                firstArgOnly(models),
+		"models info":                firstArgOnly(models),
+		"config set-model":           firstArgOnly(models),
+		"config set-embedding-model": firstArgOnly(models),
+		"config set-model-price":     firstArgOnly(models),
+
+		"config set-provider":             firstArgOnly(fixedChoices(config.ValidProviders())),
+		"config set-log-level":            firstArgOnly(fixedChoices(config.ValidLogLevels())),
+		"config set-log-format":           firstArgOnly(fixedChoices([]string{"console", "json"})),
+		"config set-syntax-theme":         firstArgOnly(fixedChoices(config.ValidSyntaxThemes())),
+		"config set-edit-mode":            firstArgOnly(fixedChoices(input.ValidEditModes())),
+		"config set-migration-mode":       firstArgOnly(fixedChoices([]string{"gradual", "compatibility", "unified"})),
+		"config set-sandbox-mode":         firstArgOnly(fixedChoices(sandbox.ValidModes())),
+		"config set-network-policy":       firstArgOnly(fixedChoices(security.ValidNetworkModes())),
+		"config set-search-backend":       firstArgOnly(fixedChoices([]string{tools.SearchBackendDuckDuckGo, tools.SearchBackendSearxNG, tools.SearchBackendBrave})),
+		"config set-language":             firstArgOnly(fixedChoices(i18n.ValidLanguages())),
+		"config set-storage":              firstArgOnly(fixedChoices(config.ValidStorageBackends())),
+		"config set-notifications":        completeNotifications,
+		"config set-telemetry":            completeTelemetry,
+		"config set-hook-checks":          firstArgOnly(completeHookChecks),
+		"config set-redaction":            firstArgOnly(fixedChoices(redact.ValidLevels())),
+		"config remove-approval-rule":     firstArgOnly(completeApprovalRules),
+		"config set-pipeline-stage":       completePipelineStage,
+		"config set-tui":                  firstArgOnly(boolean),
+		"config enable-llm-cache":         firstArgOnly(boolean),
+		"config enable-fix-loop":          firstArgOnly(boolean),
+		"config enable-code-check":        firstArgOnly(boolean),
+		"config enable-agent-loop":        firstArgOnly(boolean),
+		"config enable-tool-streaming":    firstArgOnly(boolean),
+		"config enable-session-titles":    firstArgOnly(boolean),
+		"config enable-usage":             firstArgOnly(boolean),
+		"config enable-checkpoints":       firstArgOnly(boolean),
+		"config enable-validation":        firstArgOnly(boolean),
+		"config enable-unified-tools":     firstArgOnly(boolean),
+		"config enable-unified-streaming": firstArgOnly(boolean),
+		"config enable-unified-session":   firstArgOnly(boolean),
+		"config enable-unified-analysis":  firstArgOnly(boolean),
+
+		"templates show": firstArgOnly(completeTemplates),
+		"prompts show":   firstArgOnly(completePrompts),
+		"prompts edit":   firstArgOnly(completePrompts),
+		"workflow run":   firstArgOnly(completeWorkflows),
+		"eval":           completeEvalScenarios,
+	}
+	for path, fn := range args {
+		if cmd := findCommand(root, path); cmd != nil && cmd.ValidArgsFunction == nil {
+			cmd.ValidArgsFunction = fn
+		}
+	}
+
+	flags := []struct {
+		path string
+		flag string
+		fn   cobra.CompletionFunc
+	}{
+		{"", "resume", sessions},
+		{"chat", "resume", sessions},
+		{"history search", "session", sessions},
+		{"storage search", "session", sessions},
+		{"", "profile", completeProfiles},
+		{"", "template", completeTemplates},
+		{"", "module", directoriesOnly},
+		{"bench", "model", models},
+		{"config enable-session-titles", "model", models},
+		{"run", "output", fixedChoices([]string{"text", "json", "stream-json"})},
+		{"export", "format", fixedChoices([]string{"markdown", "html"})},
+		{"analyze", "diagram", fixedChoices(analysis.ValidDiagramFormats())},
+		{"eval", "dir", directoriesOnly},
+		{"replay", "dir", directoriesOnly},
+		{"config add-approval-rule", "decision", fixedChoices(config.ValidApprovalDecisions())},
+		{"config add-approval-rule", "kind", fixedChoices(config.ValidApprovalKinds())},
+		{"config add-approval-rule", "change", fixedChoices(config.ValidApprovalChanges())},
+		{"config add-approval-rule", "max-impact", fixedChoices(config.ValidImpactLevels())},
+		{"docs man", "dir", directoriesOnly},
+		{"suggest", "type", fixedChoices(interactive.SuggestionTypeNames())},
+	}
+	for _, entry := range flags {
+		cmd := root
+		if entry.path != "" {
+			cmd = findCommand(root, entry.path)
+		}
+		if cmd == nil {
+			continue
+		}
+		if err := cmd.RegisterFlagCompletionFunc(entry.flag, entry.fn); err != nil {
+			h.log.Debug("補完候補を登録できません", map[string]interface{}{
+				"command": entry.path,
+				"flag":    entry.flag,
+				"error":   err.Error(),
+			})
+		}
+	}
+}
+
+// findCommand は "config set-model" のような空白区切りのパスのコマンドを探す
+func findCommand(root *cobra.Command, path string) *cobra.Command {
+	cmd, _, err := root.Find(strings.Fields(path))
+	if err != nil || cmd.CommandPath() != strings.TrimSpace(root.Name()+" "+path) {
+		return nil
+	}
+	return cmd
+}
+
+// firstArgOnly は最初の位置引数だけを補完する
+func firstArgOnly(fn cobra.CompletionFunc) cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+		if len(args) > 0 {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+		return fn(cmd, args, toComplete)
+	}
+}
+
+// fixedChoices はファイル名を補完しない固定の候補
+func fixedChoices(choices []string) cobra.CompletionFunc {
+	return cobra.FixedCompletions(choices, cobra.ShellCompDirectiveNoFileComp)
+}
+
+func directoriesOnly(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveFilterDirs
+}
+
+// completeSessions は監査ログに記録されたセッションID（新しい順、"latest" を含む）
+func (h *CompletionHandler) completeSessions(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	completions := []cobra.Completion{cobra.CompletionWithDesc("latest", "most recent session")}
+	sessions, err := logger.ListAuditSessions(h.auditDir)
+	if err != nil {
+		return completions, cobra.ShellCompDirectiveNoFileComp
+	}
+	for _, session := range sessions {
+		completions = append(completions, cobra.CompletionWithDesc(session.SessionID, session.Modified.Format("2006-01-02 15:04")))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeModels は設定中のプロバイダーにあるモデル名（--profile を反映、サーバーに届かなければ設定中のモデルのみ）
+func completeModels(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	profile, _ := cmd.Flags().GetString("profile")
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(profile)})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg := resolved.Config
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), completionTimeout)
+	defer cancel()
+
+	var models []llm.ModelDetails
+	if cfg.Provider == "ollama" {
+		models, err = llm.NewOllamaClient(cfg.BaseURL).ListLocalModels(ctx)
+	} else {
+		models, err = llm.ListOpenAICompatibleModels(ctx, &http.Client{}, cfg.BaseURL)
+	}
+	if err != nil || len(models) == 0 {
+		return []cobra.Completion{cobra.CompletionWithDesc(cfg.ResolvedModel(), "configured model")}, cobra.ShellCompDirectiveNoFileComp
+	}
+
+	completions := make([]cobra.Completion, 0, len(models))
+	for _, model := range models {
+		description := orDash(model.ParameterSize)
+		if model.SizeBytes > 0 {
+			description = formatModelSize(model.SizeBytes) + " " + description
+		}
+		completions = append(completions, cobra.CompletionWithDesc(model.Name, description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeProfiles はグローバル・プロジェクト設定で定義されたプロファイル名
+func completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	resolved, err := config.LoadResolved(config.ResolveOptions{})
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return resolved.Profiles, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeApprovalRules は設定済みの自動承認ルール名
+func completeApprovalRules(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions := make([]cobra.Completion, 0, len(cfg.Approval.Rules))
+	for _, rule := range cfg.Approval.Rules {
+		completions = append(completions, cobra.CompletionWithDesc(rule.Name, rule.Decision))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+}
+
+// completeNotifications は通知方法（1つ目）と通知イベント（2つ目以降、指定済みを除く）
+func completeNotifications(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return append(config.ValidNotificationMethods(), "off"), cobra.ShellCompDirectiveNoFileComp
+	}
+	return remainingChoices(config.ValidNotificationEvents(), args[1:]), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeTelemetry は on/off（1つ目）と記録する種類（2つ目以降、指定済みを除く）
+func completeTelemetry(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	if len(args) == 0 {
+		return []string{"on", "off"}, cobra.ShellCompDirectiveNoFileComp
+	}
+	return remainingChoices(config.ValidTelemetryEvents(), args[1:]), cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePipelineStage は段の種類（1つ目）と登録された実装名（2つ目）
+func completePipelineStage(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	switch len(args) {
+	case 0:
+		return config.ValidPipelineStages(), cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveKeepOrder
+	case 1:
+		return interactive.RegisteredStages(), cobra.ShellCompDirectiveNoFileComp
+	}
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeHookChecks はカンマ区切りのチェック名（入力済みのものに続けて未指定のものを補完）
+func completeHookChecks(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	prefix := ""
+	var selected []string
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+		selected = strings.Split(toComplete[:i], ",")
+	}
+
+	var completions []cobra.Completion
+	for _, check := range remainingChoices(config.ValidHookChecks(), selected) {
+		completions = append(completions, prefix+check)
+	}
+	if prefix == "" {
+		completions = append(completions, "none")
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+// remainingChoices は choices のうち selected に含まれないもの
+func remainingChoices(choices, selected []string) []string {
+	var remaining []string
+	for _, choice := range choices {
+		used := false
+		for _, s := range selected {
+			if strings.TrimSpace(s) == choice {
+				used = true
+				break
+			}
+		}
+		if !used {
+			remaining = append(remaining, choice)
+		}
+	}
+	return remaining
+}
+
+// completeTemplates は組み込みと .vyb/templates のセッションテンプレート名
+func completeTemplates(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectDir, _ := os.Getwd()
+	list, _ := templates.List(templates.Dir(projectDir))
+	completions := make([]cobra.Completion, 0, len(list))
+	for _, tmpl := range list {
+		completions = append(completions, cobra.CompletionWithDesc(tmpl.Name, tmpl.Description))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completePrompts はプロンプトテンプレート名
+func completePrompts(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	infos, err := prompts.DefaultRegistry().List()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	completions := make([]cobra.Completion, 0, len(infos))
+	for _, info := range infos {
+		completions = append(completions, cobra.CompletionWithDesc(info.Name, info.Source))
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp
+}
+
+// completeWorkflows は .vyb/workflows のワークフロー名（YAMLファイルのパスも指定できる）
+func completeWorkflows(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	projectDir, _ := os.Getwd()
+	definitions, _ := workflow.List(workflow.Dir(projectDir))
+	completions := make([]cobra.Completion, 0, len(definitions))
+	for _, definition := range definitions {
+		completions = append(completions, cobra.CompletionWithDesc(definition.Name, definition.Description))
+	}
+	return completions, cobra.ShellCompDirectiveDefault
+}
+
+// completeEvalScenarios は --dir（なければ .vyb/evals）の評価シナリオ名（指定済みを除く）
+func completeEvalScenarios(cmd *cobra.Command, args []string, toComplete string) ([]cobra.Completion, cobra.ShellCompDirective) {
+	dir, _ := cmd.Flags().GetString("dir")
+	if dir == "" {
+		projectDir, _ := os.Getwd()
+		dir = eval.Dir(projectDir)
+	}
+	scenarios, _ := eval.List(dir)
+	var completions []cobra.Completion
+	for _, scenario := range scenarios {
+		if len(remainingChoices([]string{scenario.Name}, args)) == 0 {
+			continue
+		}
+		completions = append(completions, cobra.CompletionWithDesc(scenario.Name, scenario.Description))
+	}
+	return completions, cobra.ShellCompDirectiveDefault
+}
+
+// CreateCompletionCommand はシェル補完スクリプトを出力するコマンドを作成
+func (h *CompletionHandler) CreateCompletionCommand() *cobra.Command {
+	completionCmd := &cobra.Command{
+		Use:   "completion [bash|zsh|fish|powershell]",
+		Short: "Generate the shell completion script",
+		Long: `Print a completion script for the given shell. Besides commands and flags, it completes
+session IDs (audit, export, history show, --resume), model names from the configured provider
+(config set-model, models pull/info, bench --model), profiles, templates, workflows, eval scenarios
+and the values accepted by the config set-* commands.
+
+  bash:        source <(vyb completion bash)
+               # permanently: vyb completion bash > /etc/bash_completion.d/vyb
+               # (requires the bash-completion package)
+  zsh:         vyb completion zsh > "${fpath[1]}/_vyb"
+               # completion must be enabled: autoload -U compinit; compinit
+  fish:        vyb completion fish > ~/.config/fish/completions/vyb.fish
+  powershell:  vyb completion powershell | Out-String | Invoke-Expression
+               # permanently: add the line above to your $PROFILE`,
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			noDescriptions, _ := cmd.Flags().GetBool("no-descriptions")
+			cmd.SilenceUsage = true
+			return h.GenerateCompletion(cmd.Root(), args[0], !noDescriptions)
+		},
+	}
+	completionCmd.Flags().Bool("no-descriptions", false, "Do not include descriptions next to the candidates")
+
+	return completionCmd
+}
+
+// CreateDocsCommands はドキュメント生成コマンドを作成
+func (h *CompletionHandler) CreateDocsCommands() *cobra.Command {
+	docsCmd := &cobra.Command{
+		Use:   "docs",
+		Short: "Generate documentation for the vyb command line",
+	}
+
+	manCmd := &cobra.Command{
+		Use:   "man",
+		Short: "Generate man pages for vyb and all of its subcommands",
+		Long: `Write one man page per command (vyb.1, vyb-config.1, vyb-config-set-model.1, ...) into a directory.
+Install them by copying the directory's contents into a man1 directory on your MANPATH, e.g.
+  vyb docs man --dir ~/.local/share/man/man1`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, _ := cmd.Flags().GetString("dir")
+			cmd.SilenceUsage = true
+			return h.GenerateMan(cmd.Root(), dir)
+		},
+	}
+	manCmd.Flags().String("dir", "man", "Output directory")
+
+	docsCmd.AddCommand(manCmd)
+	return docsCmd
+}
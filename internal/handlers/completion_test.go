@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// completeWith はコマンドツリーに補完候補を登録し、__complete の出力から候補だけを返す
+func completeWith(t *testing.T, auditDir string, args ...string) []string {
+	t.Helper()
+	logConfig := logger.DefaultConfig()
+	logConfig.Level = logger.ErrorLevel
+	log, err := logger.NewLogger(logConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noop := func(cmd *cobra.Command, args []string) {}
+	root := &cobra.Command{Use: "vyb", Run: noop}
+	root.PersistentFlags().String("resume", "", "")
+	configCmd := &cobra.Command{Use: "config"}
+	configCmd.AddCommand(
+		&cobra.Command{Use: "set-provider [provider]", Run: noop},
+		&cobra.Command{Use: "set-hook-checks [checks]", Run: noop},
+	)
+	root.AddCommand(configCmd, &cobra.Command{Use: "audit [session|latest]", Run: noop})
+
+	h := NewCompletionHandler(log, auditDir)
+	root.AddCommand(h.CreateCompletionCommand(), h.CreateDocsCommands())
+	h.RegisterCompletions(root)
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs(append([]string{cobra.ShellCompRequestCmd}, args...))
+	if err := root.Execute(); err != nil {
+		t.Fatal(err)
+	}
+
+	var candidates []string
+	for _, line := range strings.Split(out.String(), "\n") {
+		if line == "" || strings.HasPrefix(line, ":") || strings.HasPrefix(line, "Completion ended") {
+			continue
+		}
+		candidates = append(candidates, strings.SplitN(line, "\t", 2)[0])
+	}
+	return candidates
+}
+
+func TestRegisterCompletions(t *testing.T) {
+	auditDir := t.TempDir()
+	for _, id := range []string{"session_1", "session_2"} {
+		if err := os.WriteFile(filepath.Join(auditDir, id+".jsonl"), []byte("{}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{[]string{"config", "set-provider", ""}, []string{"ollama", "lmstudio", "vllm"}},
+		{[]string{"config", "set-provider", "ollama", ""}, nil},
+		{[]string{"config", "set-hook-checks", "lint,"}, []string{"lint,secrets", "lint,commit-message"}},
+		{[]string{"audit", ""}, []string{"latest", "session_1", "session_2"}},
+		{[]string{"--resume", ""}, []string{"latest", "session_1", "session_2"}},
+		{[]string{"completion", ""}, []string{"bash", "zsh", "fish", "powershell"}},
+	}
+	for _, tt := range tests {
+		got := completeWith(t, auditDir, tt.args...)
+		// セッションは更新日時順なので集合として比べる
+		if strings.Join(sortedCopy(got), ",") != strings.Join(sortedCopy(tt.want), ",") {
+			t.Errorf("%v: got %v, want %v", tt.args, got, tt.want)
+		}
+	}
+}
+
+func TestGenerateMan(t *testing.T) {
+	logConfig := logger.DefaultConfig()
+	logConfig.Level = logger.ErrorLevel
+	log, err := logger.NewLogger(logConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := &cobra.Command{Use: "vyb", Short: "test"}
+	root.AddCommand(&cobra.Command{Use: "audit", Short: "Show the audit log", Run: func(cmd *cobra.Command, args []string) {}})
+	dir := filepath.Join(t.TempDir(), "man")
+	if err := NewCompletionHandler(log, t.TempDir()).GenerateMan(root, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "vyb-audit.1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "Show the audit log") {
+		t.Fatalf("サブコマンドの説明がマニュアルにない:\n%s", data)
+	}
+}
+
+func sortedCopy(values []string) []string {
+	copied := append([]string(nil), values...)
+	sort.Strings(copied)
+	return copied
+}
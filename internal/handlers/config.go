@@ -3,10 +3,25 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
 
+	"github.com/glkt/vyb-code/internal/codecheck"
 	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/embeddings"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/input"
+	"github.com/glkt/vyb-code/internal/interactive"
 	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/storage"
+	"github.com/glkt/vyb-code/internal/tools"
 	"github.com/spf13/cobra"
 )
 
@@ -53,7 +68,7 @@ func (h *ConfigHandler) SetProvider(provider string) error {
 	}
 
 	// プロバイダーの検証
-	validProviders := []string{"ollama", "lmstudio", "vllm"}
+	validProviders := config.ValidProviders()
 	isValid := false
 	for _, valid := range validProviders {
 		if provider == valid {
@@ -88,17 +103,30 @@ func (h *ConfigHandler) ListConfig() error {
 	// コンソールに直接出力（ログシステムを使わない）
 	fmt.Println("現在の設定:")
 	fmt.Printf("  Provider: %s\n", cfg.Provider)
-	fmt.Printf("  Model: %s\n", cfg.ModelName)
+	fmt.Printf("  Model: %s\n", cfg.ResolvedModel())
 	fmt.Printf("  Base URL: %s\n", cfg.BaseURL)
 	fmt.Printf("  Max Tokens: %d\n", cfg.MaxTokens)
 	fmt.Printf("  Temperature: %g\n", cfg.Temperature)
 	fmt.Printf("  Stream: %t\n", cfg.Stream)
 	fmt.Printf("  Log Level: %s\n", cfg.Log.Level)
 	fmt.Printf("  Log Format: %s\n", cfg.Log.Format)
-	fmt.Printf("  TUI Enabled: %t (deprecated - Claude Code風インターフェースが標準)\n", cfg.TUI.Enabled)
+	fmt.Printf("  TUI Enabled: %t\n", cfg.TUI.Enabled)
 	fmt.Printf("  TUI Theme: %s (deprecated - Claude Code風インターフェースが標準)\n", cfg.TUI.Theme)
+	fmt.Printf("  Syntax Theme: %s\n", cfg.ResolvedSyntaxTheme())
+	fmt.Printf("  Edit Mode: %s\n", cfg.TerminalMode.EditMode)
+	if cfg.Notifications.Enabled {
+		fmt.Printf("  Notifications: %s (%v, %ds以上)\n", cfg.Notifications.Method, cfg.Notifications.Events, cfg.Notifications.ThresholdSeconds)
+	} else {
+		fmt.Printf("  Notifications: off\n")
+	}
+	if cfg.Telemetry.Enabled {
+		fmt.Printf("  Telemetry: local only (%v)\n", cfg.Telemetry.Events)
+	} else {
+		fmt.Printf("  Telemetry: off\n")
+	}
 	fmt.Printf("  File Max Size (MB): %d\n", cfg.FileMaxSizeMB)
 	fmt.Printf("  Command Timeout: %d\n", cfg.CommandTimeout)
+	fmt.Printf("  Language: %s\n", cfg.Language)
 
 	// プロンプト設定表示
 	if cfg.Prompts != nil {
@@ -113,100 +141,1194 @@ func (h *ConfigHandler) ListConfig() error {
 		fmt.Printf("  Explanation Tendency: %.1f\n", cfg.Prompts.ExplanationTendency)
 	}
 
-	// 段階的移行設定
-	fmt.Println("  Migration Settings:")
-	fmt.Printf("    Migration Mode: %s\n", cfg.Migration.MigrationMode)
-	fmt.Printf("    Unified Streaming: %t\n", cfg.Migration.UseUnifiedStreaming)
-	fmt.Printf("    Unified Session: %t\n", cfg.Migration.UseUnifiedSession)
-	fmt.Printf("    Unified Tools: %t\n", cfg.Migration.UseUnifiedTools)
-	fmt.Printf("    Unified Analysis: %t\n", cfg.Migration.UseUnifiedAnalysis)
-	fmt.Printf("    Validation Enabled: %t\n", cfg.Migration.EnableValidation)
-	fmt.Printf("    Fallback Enabled: %t\n", cfg.Migration.EnableFallback)
-	fmt.Printf("    Metrics Enabled: %t\n", cfg.Migration.EnableMetrics)
+	// 段階的移行設定
+	fmt.Println("  Migration Settings:")
+	fmt.Printf("    Migration Mode: %s\n", cfg.Migration.MigrationMode)
+	fmt.Printf("    Unified Streaming: %t\n", cfg.Migration.UseUnifiedStreaming)
+	fmt.Printf("    Unified Session: %t\n", cfg.Migration.UseUnifiedSession)
+	fmt.Printf("    Unified Tools: %t\n", cfg.Migration.UseUnifiedTools)
+	fmt.Printf("    Unified Analysis: %t\n", cfg.Migration.UseUnifiedAnalysis)
+	fmt.Printf("    Validation Enabled: %t\n", cfg.Migration.EnableValidation)
+	fmt.Printf("    Fallback Enabled: %t\n", cfg.Migration.EnableFallback)
+	fmt.Printf("    Metrics Enabled: %t\n", cfg.Migration.EnableMetrics)
+
+	// サンドボックス設定
+	fmt.Println("  Sandbox Settings:")
+	fmt.Printf("    Mode: %s\n", cfg.Sandbox.Mode)
+	fmt.Printf("    Image: %s\n", cfg.Sandbox.Image)
+	fmt.Printf("    Allow Network: %t\n", cfg.Sandbox.AllowNetwork)
+	fmt.Printf("    Read-only Workspace: %t\n", cfg.Sandbox.ReadOnlyWorkspace)
+	fmt.Println("  Network Policy:")
+	fmt.Printf("    Mode: %s\n", cfg.Network.Mode)
+	fmt.Printf("    Allowed Domains: %v\n", cfg.Network.AllowedDomains)
+	fmt.Printf("    Blocked Domains: %v\n", cfg.Network.BlockedDomains)
+	fmt.Printf("    Audit Requests: %t\n", cfg.Network.AuditRequests)
+	fmt.Printf("    Allow Loopback: %t\n", cfg.Network.AllowLoopback)
+	fmt.Println("  Redaction:")
+	fmt.Printf("    Level: %s\n", cfg.Redaction.Level)
+	if len(cfg.Redaction.Patterns) > 0 {
+		fmt.Printf("    Extra Patterns: %v\n", cfg.Redaction.Patterns)
+	}
+	fmt.Println("  Web Tools:")
+	fmt.Printf("    Search Backend: %s\n", cfg.WebTools.SearchBackend)
+	if cfg.WebTools.SearxNGURL != "" {
+		fmt.Printf("    SearxNG URL: %s\n", cfg.WebTools.SearxNGURL)
+	}
+	fmt.Printf("    Fetch Max Bytes: %d\n", cfg.WebTools.FetchMaxBytes)
+	fmt.Printf("    Cache TTL: %d min\n", cfg.WebTools.CacheTTLMinutes)
+	fmt.Println("  Git Hooks:")
+	fmt.Printf("    Checks: %s\n", formatHookChecks(cfg.Hooks.Checks))
+	fmt.Printf("    Block On Lint: %t\n", cfg.Hooks.BlockOnLint)
+	fmt.Println("  Embeddings:")
+	fmt.Printf("    Model: %s\n", cfg.ResolvedEmbeddingModel(""))
+	fmt.Printf("    Base URL: %s\n", cfg.ResolvedEmbeddingURL())
+	fmt.Printf("    API: %s\n", cfg.Embeddings.API)
+	fmt.Printf("    Cache Dir: %s\n", embeddings.ResolveCacheDir(cfg.Embeddings.CacheDir))
+	fmt.Println("  LLM Cache:")
+	fmt.Printf("    Enabled: %t\n", cfg.LLMCache.Enabled)
+	fmt.Printf("    TTL: %d min\n", cfg.LLMCache.TTLMinutes)
+	fmt.Printf("    Max Entries: %d\n", cfg.LLMCache.MaxEntries)
+	fmt.Printf("    Semantic Matching: %t\n", cfg.LLMCache.SemanticMatching)
+	if cfg.LLMCache.SemanticMatching {
+		fmt.Printf("    Embedding Model: %s\n", cfg.ResolvedEmbeddingModel(cfg.LLMCache.EmbeddingModel))
+		fmt.Printf("    Similarity Threshold: %.2f\n", cfg.LLMCache.SimilarityThreshold)
+	}
+	fmt.Println("  Fix Loop:")
+	fmt.Printf("    Enabled: %t\n", cfg.FixLoop.Enabled)
+	fmt.Printf("    Max Iterations: %d\n", cfg.FixLoop.MaxIterations)
+	fmt.Printf("    Run Tests: %t\n", cfg.FixLoop.RunTests)
+	fmt.Printf("    Timeout: %ds\n", cfg.FixLoop.TimeoutSeconds)
+	fmt.Println("  Code Check:")
+	fmt.Printf("    Enabled: %t\n", cfg.CodeCheck.Enabled)
+	if len(cfg.CodeCheck.Languages) > 0 {
+		fmt.Printf("    Languages: %v\n", cfg.CodeCheck.Languages)
+	} else {
+		fmt.Printf("    Languages: all (%s)\n", strings.Join(codecheck.ValidLanguages(), ", "))
+	}
+	fmt.Printf("    Max Repairs: %d\n", cfg.CodeCheck.MaxRepairs)
+	fmt.Printf("    Timeout: %ds\n", cfg.CodeCheck.TimeoutSeconds)
+	fmt.Println("  Agent Loop:")
+	fmt.Printf("    Enabled: %t\n", cfg.AgentLoop.Enabled)
+	fmt.Printf("    Max Steps: %d\n", cfg.AgentLoop.MaxSteps)
+	fmt.Printf("    Stream Tools: %t\n", cfg.AgentLoop.StreamTools)
+	fmt.Println("  Session Titles:")
+	fmt.Printf("    Enabled: %t\n", cfg.SessionTitles.Enabled)
+	titleModel := cfg.SessionTitles.Model
+	if titleModel == "" {
+		titleModel = "(chat model)"
+	}
+	fmt.Printf("    Model: %s\n", titleModel)
+	fmt.Printf("    Max Length: %d\n", cfg.SessionTitles.MaxLength)
+	fmt.Println("  Pipeline:")
+	for _, stage := range config.ValidPipelineStages() {
+		name := cfg.Pipeline.Stages[stage]
+		if name == "" {
+			name = interactive.DefaultStageName
+		}
+		fmt.Printf("    %s: %s\n", stage, name)
+	}
+	fmt.Println("  Approval Rules:")
+	if len(cfg.Approval.Rules) == 0 {
+		fmt.Println("    (none: every suggestion asks for confirmation)")
+	}
+	for i, rule := range cfg.Approval.Rules {
+		fmt.Printf("    %d. %s\n", i+1, formatApprovalRule(rule))
+	}
+	fmt.Println("  Permissions (user; see vyb permissions show for project rules):")
+	fmt.Printf("    Allowed Commands: %s\n", formatPermissionList(cfg.Permissions.Commands.Allow))
+	fmt.Printf("    Denied Commands: %s\n", formatPermissionList(cfg.Permissions.Commands.Deny))
+	fmt.Printf("    Denied Paths: %s\n", formatPermissionList(cfg.Permissions.Paths.Deny))
+	fmt.Printf("    Read-only Paths: %s\n", formatPermissionList(cfg.Permissions.Paths.ReadOnly))
+	fmt.Println("  Turn Limits:")
+	fmt.Printf("    Max Tool Calls: %s\n", formatTurnLimit(cfg.TurnLimits.MaxToolCalls, ""))
+	fmt.Printf("    Max Tokens: %s\n", formatTurnLimit(cfg.TurnLimits.MaxTokens, ""))
+	fmt.Printf("    Max Wall Time: %s\n", formatTurnLimit(cfg.TurnLimits.MaxSeconds, "s"))
+	fmt.Println("  Usage:")
+	fmt.Printf("    Enabled: %t\n", cfg.Usage.Enabled)
+	fmt.Printf("    Currency: %s\n", cfg.Usage.Currency)
+	models := make([]string, 0, len(cfg.Usage.Pricing))
+	for model := range cfg.Usage.Pricing {
+		models = append(models, model)
+	}
+	sort.Strings(models)
+	for _, model := range models {
+		price := cfg.Usage.Pricing[model]
+		fmt.Printf("    Price %s: %g / %g per 1K tokens\n", model, price.PromptPer1K, price.CompletionPer1K)
+	}
+	fmt.Println("  Checkpoints:")
+	fmt.Printf("    Enabled: %t\n", cfg.Checkpoints.Enabled)
+	fmt.Printf("    Max Per Session: %d\n", cfg.Checkpoints.MaxPerSession)
+	fmt.Println("  Storage:")
+	fmt.Printf("    Backend: %s\n", cfg.Storage.Backend)
+	if cfg.Storage.Backend == storage.BackendSQLite {
+		path := cfg.Storage.Path
+		if path == "" {
+			path = storage.DefaultPath()
+		}
+		fmt.Printf("    Path: %s\n", path)
+	}
+	fmt.Println("  Ignore:")
+	fmt.Printf("    Built-in: %s\n", strings.Join(ignore.DefaultPatterns(), " "))
+	if len(cfg.Ignore.Patterns) == 0 {
+		fmt.Println("    Patterns: (none)")
+	} else {
+		fmt.Printf("    Patterns: %s\n", strings.Join(cfg.Ignore.Patterns, " "))
+	}
+
+	return nil
+}
+
+// SetLogLevel はログレベルを設定
+func (h *ConfigHandler) SetLogLevel(level string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	// ログレベルの検証
+	validLevels := config.ValidLogLevels()
+	isValid := false
+	for _, valid := range validLevels {
+		if level == valid {
+			isValid = true
+			break
+		}
+	}
+
+	if !isValid {
+		return fmt.Errorf("無効なログレベルです。有効な値: %v", validLevels)
+	}
+
+	cfg.Log.Level = level
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("ログレベルを更新しました", map[string]interface{}{
+		"level": level,
+	})
+	return nil
+}
+
+// SetLogFormat はログフォーマットを設定
+func (h *ConfigHandler) SetLogFormat(format string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	// ログフォーマットの検証
+	validFormats := []string{"console", "json"}
+	isValid := false
+	for _, valid := range validFormats {
+		if format == valid {
+			isValid = true
+			break
+		}
+	}
+
+	if !isValid {
+		return fmt.Errorf("無効なログフォーマットです。有効な値: %v", validFormats)
+	}
+
+	cfg.Log.Format = format
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("ログフォーマットを更新しました", map[string]interface{}{
+		"format": format,
+	})
+	return nil
+}
+
+// SetTUIEnabled はペイン構成のTUIの有効/無効を設定（無効なら従来の逐次表示）
+func (h *ConfigHandler) SetTUIEnabled(enabled bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.TUI.Enabled = enabled
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("TUI設定を更新しました", map[string]interface{}{
+		"enabled": enabled,
+	})
+	return nil
+}
+
+// SetSyntaxTheme はコードブロック・差分の配色を設定（none で色付けしない）
+func (h *ConfigHandler) SetSyntaxTheme(theme string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	// 配色の検証
+	validThemes := config.ValidSyntaxThemes()
+	isValid := false
+	for _, valid := range validThemes {
+		if theme == valid {
+			isValid = true
+			break
+		}
+	}
+
+	if !isValid {
+		return fmt.Errorf("無効な配色です。有効な値: %v", validThemes)
+	}
+
+	cfg.Markdown.SyntaxTheme = theme
+	cfg.Markdown.SyntaxHighlight = theme != "none"
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("シンタックスハイライトの配色を更新しました", map[string]interface{}{
+		"theme": theme,
+	})
+	return nil
+}
+
+// SetEditMode は入力行の編集キー配置を設定
+func (h *ConfigHandler) SetEditMode(mode string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	editMode, err := input.ParseEditMode(mode)
+	if err != nil {
+		return err
+	}
+
+	cfg.TerminalMode.EditMode = string(editMode)
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("編集モードを更新しました", map[string]interface{}{
+		"mode": editMode,
+	})
+	return nil
+}
+
+// SetNotifications は長いターンの完了通知を設定（off で無効、イベント・秒数は指定時のみ置き換え）
+func (h *ConfigHandler) SetNotifications(method string, notifyEvents []string, thresholdSeconds int) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	if method == "off" {
+		cfg.Notifications.Enabled = false
+	} else {
+		// 通知方法の検証
+		validMethods := config.ValidNotificationMethods()
+		isValid := false
+		for _, valid := range validMethods {
+			if method == valid {
+				isValid = true
+				break
+			}
+		}
+		if !isValid {
+			return fmt.Errorf("無効な通知方法です。有効な値: %v (off で無効)", validMethods)
+		}
+		cfg.Notifications.Enabled = true
+		cfg.Notifications.Method = method
+	}
+
+	// 通知するイベントの検証
+	if len(notifyEvents) > 0 {
+		validEvents := config.ValidNotificationEvents()
+		for _, event := range notifyEvents {
+			isValid := false
+			for _, valid := range validEvents {
+				if event == valid {
+					isValid = true
+					break
+				}
+			}
+			if !isValid {
+				return fmt.Errorf("無効な通知イベントです: %s (有効な値: %v)", event, validEvents)
+			}
+		}
+		cfg.Notifications.Events = notifyEvents
+	}
+
+	if thresholdSeconds < 0 {
+		return fmt.Errorf("通知のしきい値は1秒以上を指定してください")
+	}
+	if thresholdSeconds > 0 {
+		cfg.Notifications.ThresholdSeconds = thresholdSeconds
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("通知設定を更新しました", map[string]interface{}{
+		"enabled":           cfg.Notifications.Enabled,
+		"method":            cfg.Notifications.Method,
+		"events":            cfg.Notifications.Events,
+		"threshold_seconds": cfg.Notifications.ThresholdSeconds,
+	})
+	return nil
+}
+
+// SetTelemetry はローカル専用の利用統計の記録を設定（off で無効、種類は指定時のみ置き換え）
+func (h *ConfigHandler) SetTelemetry(mode string, recordEvents []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	switch mode {
+	case "on":
+		cfg.Telemetry.Enabled = true
+	case "off":
+		cfg.Telemetry.Enabled = false
+	default:
+		return fmt.Errorf("無効な値です。on または off を指定してください")
+	}
+
+	// 記録する種類の検証
+	if len(recordEvents) > 0 {
+		validEvents := config.ValidTelemetryEvents()
+		for _, event := range recordEvents {
+			isValid := false
+			for _, valid := range validEvents {
+				if event == valid {
+					isValid = true
+					break
+				}
+			}
+			if !isValid {
+				return fmt.Errorf("無効な記録の種類です: %s (有効な値: %v)", event, validEvents)
+			}
+		}
+		cfg.Telemetry.Events = recordEvents
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("利用統計の記録設定を更新しました", map[string]interface{}{
+		"enabled": cfg.Telemetry.Enabled,
+		"events":  cfg.Telemetry.Events,
+	})
+	return nil
+}
+
+// SetTUITheme はTUIテーマを設定（非推奨 - Claude Code風インターフェースに移行済み）
+func (h *ConfigHandler) SetTUITheme(theme string) error {
+	h.log.Warn("TUIテーマ設定は非推奨です。Claude Code風インターフェースが常に使用されます。", nil)
+	fmt.Println("⚠️  TUIテーマ設定は非推奨です。Claude Code風インターフェースが標準となりました。")
+	return nil
+}
+
+// SetSandboxMode はコマンド実行バックエンドを設定
+func (h *ConfigHandler) SetSandboxMode(mode string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	if !sandbox.IsValidMode(mode) {
+		return fmt.Errorf("無効なサンドボックスモードです。有効な値: %v", sandbox.ValidModes())
+	}
+
+	cfg.Sandbox.Mode = mode
+
+	// コンテナモードではランタイムの存在を事前確認
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return err
+	}
+	if err := backend.Available(); err != nil {
+		h.log.Warn("サンドボックスバックエンドが現在利用できません", map[string]interface{}{
+			"mode":  mode,
+			"error": err.Error(),
+		})
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("サンドボックスモードを更新しました", map[string]interface{}{
+		"mode": mode,
+	})
+	return nil
+}
+
+// SetSandboxImage はサンドボックスのコンテナイメージを設定
+func (h *ConfigHandler) SetSandboxImage(image string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	if image == "" {
+		return fmt.Errorf("イメージ名が空です")
+	}
+
+	cfg.Sandbox.Image = image
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("サンドボックスイメージを更新しました", map[string]interface{}{
+		"image": image,
+	})
+	return nil
+}
+
+// SetNetworkPolicy はネットワークポリシー（モード・許可/ブロックドメイン）を設定
+func (h *ConfigHandler) SetNetworkPolicy(mode string, allowedDomains, blockedDomains []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	if !security.IsValidNetworkMode(mode) {
+		return fmt.Errorf("無効なネットワークポリシーモード: %s (有効な値: %v)", mode, security.ValidNetworkModes())
+	}
+
+	cfg.Network.Mode = mode
+	// ドメイン指定がある場合のみ許可リストを置き換え
+	if len(allowedDomains) > 0 {
+		cfg.Network.AllowedDomains = allowedDomains
+	}
+	if blockedDomains != nil {
+		cfg.Network.BlockedDomains = blockedDomains
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	security.NewAuditLogger().LogConfigChange("network.mode", mode)
+
+	h.log.Info("ネットワークポリシーを更新しました", map[string]interface{}{
+		"mode":            mode,
+		"allowed_domains": cfg.Network.AllowedDomains,
+		"blocked_domains": cfg.Network.BlockedDomains,
+	})
+	return nil
+}
+
+// SetSearchBackend はWeb検索バックエンドを設定（searxngはURL、braveはAPIキーを指定）
+func (h *ConfigHandler) SetSearchBackend(backend, value string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.WebTools.SearchBackend = backend
+	switch backend {
+	case tools.SearchBackendSearxNG:
+		if value != "" {
+			cfg.WebTools.SearxNGURL = value
+		}
+	case tools.SearchBackendBrave:
+		if value != "" {
+			cfg.WebTools.BraveAPIKey = value
+		}
+	}
+
+	// バックエンドを構築できるか事前に検証
+	if _, err := tools.NewSearchBackend(cfg.WebTools); err != nil {
+		return err
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("Web検索バックエンドを更新しました", map[string]interface{}{
+		"backend": backend,
+	})
+	return nil
+}
+
+// EnableLLMCache はLLM応答キャッシュを有効化（semanticがnilなら類似照合の設定は変更しない）
+func (h *ConfigHandler) EnableLLMCache(enable bool, semantic *bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.LLMCache.Enabled = enable
+	if semantic != nil {
+		cfg.LLMCache.SemanticMatching = *semantic
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("LLM応答キャッシュ設定を更新しました", map[string]interface{}{
+		"enabled":  enable,
+		"semantic": cfg.LLMCache.SemanticMatching,
+	})
+	return nil
+}
+
+// SetLanguage は表示・応答言語を設定
+func (h *ConfigHandler) SetLanguage(language string) error {
+	valid := false
+	for _, lang := range i18n.ValidLanguages() {
+		if language == lang {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return i18n.Errorf("error.invalid_language")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.Language = language
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	i18n.SetLanguage(language)
+	h.log.Info("言語設定を更新しました", map[string]interface{}{
+		"language": language,
+		"resolved": string(i18n.Current()),
+	})
+	return nil
+}
+
+// EnableUsage はトークン使用量・コストの記録を設定
+func (h *ConfigHandler) EnableUsage(enable bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.Usage.Enabled = enable
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("使用量記録設定を更新しました", map[string]interface{}{
+		"enabled": enable,
+	})
+	return nil
+}
+
+// SetModelPrice はモデルの1,000トークンあたりの単価を設定（currencyが空なら通貨は変更しない）
+func (h *ConfigHandler) SetModelPrice(model string, promptPer1K, completionPer1K float64, currency string) error {
+	if promptPer1K < 0 || completionPer1K < 0 {
+		return fmt.Errorf("単価は0以上を指定してください")
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.Usage.Pricing[model] = config.ModelPrice{
+		PromptPer1K:     promptPer1K,
+		CompletionPer1K: completionPer1K,
+	}
+	if currency != "" {
+		cfg.Usage.Currency = strings.ToUpper(currency)
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("モデル単価を更新しました", map[string]interface{}{
+		"model":             model,
+		"prompt_per_1k":     promptPer1K,
+		"completion_per_1k": completionPer1K,
+		"currency":          cfg.Usage.Currency,
+	})
+	return nil
+}
+
+// EnableFixLoop は編集後の自動修正ループを設定（maxIterationsが0以下なら回数は変更しない）
+func (h *ConfigHandler) EnableFixLoop(enable bool, maxIterations int, runTests *bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.FixLoop.Enabled = enable
+	if maxIterations > 0 {
+		cfg.FixLoop.MaxIterations = maxIterations
+	}
+	if runTests != nil {
+		cfg.FixLoop.RunTests = *runTests
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("自動修正ループ設定を更新しました", map[string]interface{}{
+		"enabled":        enable,
+		"max_iterations": cfg.FixLoop.MaxIterations,
+		"run_tests":      cfg.FixLoop.RunTests,
+	})
+	return nil
+}
+
+// EnableCodeCheck は応答中の生成コードの検証を設定（maxRepairsが負なら回数、languagesがnilなら言語は変更しない）
+func (h *ConfigHandler) EnableCodeCheck(enable bool, maxRepairs int, languages []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.CodeCheck.Enabled = enable
+	if maxRepairs >= 0 {
+		cfg.CodeCheck.MaxRepairs = maxRepairs
+	}
+	if languages != nil {
+		if _, err := codecheck.New(languages, 0); err != nil {
+			return err
+		}
+		cfg.CodeCheck.Languages = languages
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("生成コード検証設定を更新しました", map[string]interface{}{
+		"enabled":     enable,
+		"max_repairs": cfg.CodeCheck.MaxRepairs,
+		"languages":   cfg.CodeCheck.Languages,
+	})
+	return nil
+}
+
+// EnableAgentLoop はツール実行結果をモデルに返して続けるエージェントループを設定（maxStepsが0以下なら回数は変更しない）
+func (h *ConfigHandler) EnableAgentLoop(enable bool, maxSteps int) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.AgentLoop.Enabled = enable
+	if maxSteps > 0 {
+		cfg.AgentLoop.MaxSteps = maxSteps
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("エージェントループ設定を更新しました", map[string]interface{}{
+		"enabled":   enable,
+		"max_steps": cfg.AgentLoop.MaxSteps,
+	})
+	return nil
+}
+
+// EnableToolStreaming は応答の生成中に完成したコマンドから実行を始めるかを設定
+func (h *ConfigHandler) EnableToolStreaming(enable bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.AgentLoop.StreamTools = enable
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("ツールのストリーミング実行設定を更新しました", map[string]interface{}{
+		"enabled": enable,
+	})
+	return nil
+}
+
+// EnableSessionTitles は最初のやり取りからセッションの題名を付けるかを設定（modelがnilならモデルは変更しない）
+func (h *ConfigHandler) EnableSessionTitles(enable bool, model *string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.SessionTitles.Enabled = enable
+	if model != nil {
+		cfg.SessionTitles.Model = *model
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("セッションの題名の設定を更新しました", map[string]interface{}{
+		"enabled": enable,
+		"model":   cfg.SessionTitles.Model,
+	})
+	return nil
+}
+
+// SetPipelineStage は応答生成パイプラインの段に使う実装を設定（default なら組み込みに戻す）
+func (h *ConfigHandler) SetPipelineStage(stage, name string) error {
+	if !containsValue(config.ValidPipelineStages(), stage) {
+		return fmt.Errorf("無効な段です: %s（%s のいずれかを指定してください）", stage, strings.Join(config.ValidPipelineStages(), ", "))
+	}
+	if !containsValue(interactive.RegisteredStages(), name) {
+		return fmt.Errorf("登録されていない実装です: %s（%s のいずれかを指定してください）", name, strings.Join(interactive.RegisteredStages(), ", "))
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	if name == interactive.DefaultStageName {
+		delete(cfg.Pipeline.Stages, stage)
+	} else {
+		cfg.Pipeline.Stages[stage] = name
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("パイプラインの段を更新しました", map[string]interface{}{
+		"stage":          stage,
+		"implementation": name,
+	})
+	return nil
+}
+
+// AddApprovalRule は提案の自動承認ルールを追加（同名のルールは置き換え、first なら最初に評価する）
+func (h *ConfigHandler) AddApprovalRule(rule config.ApprovalRule, first bool) error {
+	if err := validateApprovalRule(rule); err != nil {
+		return err
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	rules := make([]config.ApprovalRule, 0, len(cfg.Approval.Rules)+1)
+	replaced := false
+	for _, existing := range cfg.Approval.Rules {
+		if existing.Name != rule.Name {
+			rules = append(rules, existing)
+		} else if !first {
+			rules = append(rules, rule)
+			replaced = true
+		}
+	}
+	switch {
+	case first:
+		rules = append([]config.ApprovalRule{rule}, rules...)
+	case !replaced:
+		rules = append(rules, rule)
+	}
+	cfg.Approval.Rules = rules
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("自動承認ルールを更新しました", map[string]interface{}{
+		"rule":  formatApprovalRule(rule),
+		"rules": len(cfg.Approval.Rules),
+	})
+	return nil
+}
+
+// RemoveApprovalRule は名前で指定した自動承認ルールを削除
+func (h *ConfigHandler) RemoveApprovalRule(name string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	rules := make([]config.ApprovalRule, 0, len(cfg.Approval.Rules))
+	for _, rule := range cfg.Approval.Rules {
+		if rule.Name != name {
+			rules = append(rules, rule)
+		}
+	}
+	if len(rules) == len(cfg.Approval.Rules) {
+		return fmt.Errorf("自動承認ルールが見つかりません: %s", name)
+	}
+	cfg.Approval.Rules = rules
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("自動承認ルールを削除しました", map[string]interface{}{
+		"name":  name,
+		"rules": len(cfg.Approval.Rules),
+	})
+	return nil
+}
+
+// validateApprovalRule は自動承認ルールの値を検証
+func validateApprovalRule(rule config.ApprovalRule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("ルール名を指定してください")
+	}
+	if !containsValue(config.ValidApprovalDecisions(), rule.Decision) {
+		return fmt.Errorf("無効な判定です: %s（%s のいずれかを指定してください）", rule.Decision, strings.Join(config.ValidApprovalDecisions(), ", "))
+	}
+	for _, kind := range rule.Kinds {
+		if !containsValue(config.ValidApprovalKinds(), kind) {
+			return fmt.Errorf("無効な操作の種類です: %s（%s のいずれかを指定してください）", kind, strings.Join(config.ValidApprovalKinds(), ", "))
+		}
+	}
+	for _, change := range rule.Changes {
+		if !containsValue(config.ValidApprovalChanges(), change) {
+			return fmt.Errorf("無効な変更の内容です: %s（%s のいずれかを指定してください）", change, strings.Join(config.ValidApprovalChanges(), ", "))
+		}
+	}
+	for _, pattern := range append(append([]string{}, rule.Paths...), rule.Branches...) {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("無効なパターンです: %s", pattern)
+		}
+	}
+	if rule.MinConfidence < 0 || rule.MinConfidence > 1 {
+		return fmt.Errorf("信頼度は0.0から1.0の範囲で指定してください")
+	}
+	if rule.MaxImpact != "" && !containsValue(config.ValidImpactLevels(), rule.MaxImpact) {
+		return fmt.Errorf("無効な影響レベルです: %s（%s のいずれかを指定してください）", rule.MaxImpact, strings.Join(config.ValidImpactLevels(), ", "))
+	}
+	return nil
+}
+
+// containsValue は values に value が含まれるか判定
+func containsValue(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// formatApprovalRule は自動承認ルールの表示（name: decision when 条件...）
+func formatApprovalRule(rule config.ApprovalRule) string {
+	var conditions []string
+	for _, condition := range []struct {
+		label  string
+		values []string
+	}{
+		{"kind", rule.Kinds},
+		{"change", rule.Changes},
+		{"path", rule.Paths},
+		{"branch", rule.Branches},
+	} {
+		if len(condition.values) > 0 {
+			conditions = append(conditions, fmt.Sprintf("%s=%s", condition.label, strings.Join(condition.values, ",")))
+		}
+	}
+	if rule.MinConfidence > 0 {
+		conditions = append(conditions, fmt.Sprintf("confidence>=%.2f", rule.MinConfidence))
+	}
+	if rule.MaxImpact != "" {
+		conditions = append(conditions, fmt.Sprintf("impact<=%s", rule.MaxImpact))
+	}
+	if len(conditions) == 0 {
+		conditions = append(conditions, "any suggestion")
+	}
+	return fmt.Sprintf("%s: %s when %s", rule.Name, rule.Decision, strings.Join(conditions, " "))
+}
+
+// SetTurnLimits は1ターンの資源上限を設定（nil の項目は変更しない、負の値で無効化）
+func (h *ConfigHandler) SetTurnLimits(maxToolCalls, maxTokens, maxSeconds *int) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	for _, value := range []*int{maxToolCalls, maxTokens, maxSeconds} {
+		if value != nil && *value == 0 {
+			return fmt.Errorf("上限は1以上を指定してください（無効にする場合は -1）")
+		}
+	}
+	if maxToolCalls != nil {
+		cfg.TurnLimits.MaxToolCalls = *maxToolCalls
+	}
+	if maxTokens != nil {
+		cfg.TurnLimits.MaxTokens = *maxTokens
+	}
+	if maxSeconds != nil {
+		cfg.TurnLimits.MaxSeconds = *maxSeconds
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("ターンの上限を更新しました", map[string]interface{}{
+		"max_tool_calls": cfg.TurnLimits.MaxToolCalls,
+		"max_tokens":     cfg.TurnLimits.MaxTokens,
+		"max_seconds":    cfg.TurnLimits.MaxSeconds,
+	})
+	return nil
+}
+
+// SetEmbeddings は共通の埋め込みモデルと埋め込みAPIの接続先を設定（空の項目は変更しない）
+func (h *ConfigHandler) SetEmbeddings(model, baseURL, api string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	if api != "" {
+		valid := false
+		for _, candidate := range config.ValidEmbeddingAPIs() {
+			if api == candidate {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("無効な埋め込みAPIです: %s（%s のいずれかを指定してください）", api, strings.Join(config.ValidEmbeddingAPIs(), ", "))
+		}
+		cfg.Embeddings.API = api
+	}
+	if model != "" {
+		cfg.Embeddings.Model = model
+	}
+	if baseURL != "" {
+		cfg.Embeddings.BaseURL = baseURL
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("埋め込みモデルの設定を更新しました", map[string]interface{}{
+		"model":    cfg.Embeddings.Model,
+		"base_url": cfg.ResolvedEmbeddingURL(),
+		"api":      cfg.Embeddings.API,
+	})
+	return nil
+}
+
+// SetHookChecks はgitフックで実行するチェックを設定（blockOnLint が nil なら変更しない）
+func (h *ConfigHandler) SetHookChecks(checks []string, blockOnLint *bool) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	selected := []string{}
+	for _, check := range checks {
+		valid := false
+		for _, candidate := range config.ValidHookChecks() {
+			if check == candidate {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("無効なチェックです: %s（%s のいずれかを指定してください）", check, strings.Join(config.ValidHookChecks(), ", "))
+		}
+		selected = append(selected, check)
+	}
+	cfg.Hooks.Checks = selected
+	if blockOnLint != nil {
+		cfg.Hooks.BlockOnLint = *blockOnLint
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	h.log.Info("gitフックのチェックを更新しました", map[string]interface{}{
+		"checks":        cfg.Hooks.Checks,
+		"block_on_lint": cfg.Hooks.BlockOnLint,
+	})
+	return nil
+}
+
+// SetRedaction は LLM への送信・監査ログで機密情報を伏せる強さを設定（patterns が nil なら追加パターンは変更しない）
+func (h *ConfigHandler) SetRedaction(level string, patterns []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	cfg.Redaction.Level = level
+	if patterns != nil {
+		cfg.Redaction.Patterns = patterns
+	}
+	// 強さ・パターンを検証してから保存する
+	redactor, err := redact.New(cfg.Redaction.Level, cfg.Redaction.Patterns)
+	if err != nil {
+		return err
+	}
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+	redact.SetDefault(redactor)
+	security.NewAuditLogger().LogConfigChange("redaction.level", level)
+
+	h.log.Info("機密情報の伏せ字化設定を更新しました", map[string]interface{}{
+		"level":    cfg.Redaction.Level,
+		"patterns": cfg.Redaction.Patterns,
+	})
+	return nil
+}
+
+// SetIgnorePatterns はプロジェクトの走査で除外するパターン（.gitignore と同じ書式）を設定（空なら全て削除）
+func (h *ConfigHandler) SetIgnorePatterns(patterns []string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+
+	selected := []string{}
+	for _, pattern := range patterns {
+		if pattern = strings.TrimSpace(pattern); pattern == "" || strings.HasPrefix(pattern, "#") {
+			return fmt.Errorf("無効な除外パターンです: %q", pattern)
+		}
+		selected = append(selected, pattern)
+	}
+	cfg.Ignore.Patterns = selected
+
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+	ignore.SetPatterns(cfg.Ignore.Patterns)
+
+	h.log.Info("除外パターンを更新しました", map[string]interface{}{
+		"patterns": cfg.Ignore.Patterns,
+	})
+	return nil
+}
+
+// CheckIgnore はパスが除外されるかと、除外を決めたパターン・その出所を表示する
+func (h *ConfigHandler) CheckIgnore(paths []string) error {
+	matcher := ignore.New(".")
+	for _, path := range paths {
+		isDir := false
+		if info, err := os.Stat(path); err == nil {
+			isDir = info.IsDir()
+		}
+		rule := matcher.Match(path, isDir)
+		switch {
+		case rule == nil:
+			fmt.Printf("%s: not ignored\n", path)
+		case rule.Negate:
+			fmt.Printf("%s: not ignored (%s: %s)\n", path, rule.Source, rule.Pattern)
+		default:
+			fmt.Printf("%s: ignored (%s: %s)\n", path, rule.Source, rule.Pattern)
+		}
+	}
+	return nil
+}
+
+// formatTurnLimit は上限の表示（負の値は無制限）
+func formatTurnLimit(value int, unit string) string {
+	if value < 0 {
+		return "unlimited"
+	}
+	return fmt.Sprintf("%d%s", value, unit)
+}
 
-	return nil
+// formatPermissionList は許可規則のパターンの表示（なければ none）
+func formatPermissionList(patterns []string) string {
+	if len(patterns) == 0 {
+		return "none"
+	}
+	return strings.Join(patterns, ", ")
 }
 
-// SetLogLevel はログレベルを設定
-func (h *ConfigHandler) SetLogLevel(level string) error {
+// EnableCheckpoints はターン毎のワークスペースチェックポイントを設定（maxPerSessionが0以下なら上限は変更しない）
+func (h *ConfigHandler) EnableCheckpoints(enable bool, maxPerSession int) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("設定読み込みエラー: %w", err)
 	}
 
-	// ログレベルの検証
-	validLevels := []string{"debug", "info", "warn", "error"}
-	isValid := false
-	for _, valid := range validLevels {
-		if level == valid {
-			isValid = true
-			break
-		}
-	}
-
-	if !isValid {
-		return fmt.Errorf("無効なログレベルです。有効な値: %v", validLevels)
+	cfg.Checkpoints.Enabled = enable
+	if maxPerSession > 0 {
+		cfg.Checkpoints.MaxPerSession = maxPerSession
 	}
 
-	cfg.Log.Level = level
-
 	if err := config.Save(cfg); err != nil {
 		return fmt.Errorf("設定保存エラー: %w", err)
 	}
 
-	h.log.Info("ログレベルを更新しました", map[string]interface{}{
-		"level": level,
+	h.log.Info("チェックポイント設定を更新しました", map[string]interface{}{
+		"enabled":         enable,
+		"max_per_session": cfg.Checkpoints.MaxPerSession,
 	})
 	return nil
 }
 
-// SetLogFormat はログフォーマットを設定
-func (h *ConfigHandler) SetLogFormat(format string) error {
+// SetStorage はセッション・メトリクス・会話・応答キャッシュの保存先を設定（pathが空なら ~/.vyb/vyb.db）
+func (h *ConfigHandler) SetStorage(backend, path string) error {
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("設定読み込みエラー: %w", err)
 	}
 
-	// ログフォーマットの検証
-	validFormats := []string{"console", "json"}
+	validBackends := config.ValidStorageBackends()
 	isValid := false
-	for _, valid := range validFormats {
-		if format == valid {
+	for _, valid := range validBackends {
+		if backend == valid {
 			isValid = true
 			break
 		}
 	}
-
 	if !isValid {
-		return fmt.Errorf("無効なログフォーマットです。有効な値: %v", validFormats)
+		return fmt.Errorf("無効な保存先です。有効な値: %v", validBackends)
+	}
+	if path != "" && backend != storage.BackendSQLite {
+		return fmt.Errorf("パスは sqlite の場合のみ指定できます")
+	}
+	if path != "" {
+		if path, err = filepath.Abs(path); err != nil {
+			return fmt.Errorf("パス解決エラー: %w", err)
+		}
 	}
 
-	cfg.Log.Format = format
+	cfg.Storage.Backend = backend
+	cfg.Storage.Path = path
 
 	if err := config.Save(cfg); err != nil {
 		return fmt.Errorf("設定保存エラー: %w", err)
 	}
 
-	h.log.Info("ログフォーマットを更新しました", map[string]interface{}{
-		"format": format,
+	h.log.Info("保存先を更新しました", map[string]interface{}{
+		"backend": backend,
+		"path":    path,
 	})
 	return nil
 }
 
-// SetTUIEnabled はTUIの有効/無効を設定（非推奨 - Claude Code風インターフェースに移行済み）
-func (h *ConfigHandler) SetTUIEnabled(enabled bool) error {
-	h.log.Warn("TUI設定は非推奨です。Claude Code風インターフェースが常に使用されます。", nil)
-	fmt.Println("⚠️  TUI設定は非推奨です。Claude Code風インターフェースが標準となりました。")
-	return nil
-}
-
-// SetTUITheme はTUIテーマを設定（非推奨 - Claude Code風インターフェースに移行済み）
-func (h *ConfigHandler) SetTUITheme(theme string) error {
-	h.log.Warn("TUIテーマ設定は非推奨です。Claude Code風インターフェースが常に使用されます。", nil)
-	fmt.Println("⚠️  TUIテーマ設定は非推奨です。Claude Code風インターフェースが標準となりました。")
-	return nil
-}
-
 // 段階的移行設定のメソッド
 
 // SetMigrationMode は移行モードを設定
@@ -417,10 +1539,10 @@ func (h *ConfigHandler) CreateConfigCommands() *cobra.Command {
 		},
 	}
 
-	// set-tui コマンド（非推奨）
+	// set-tui コマンド
 	setTUICmd := &cobra.Command{
 		Use:   "set-tui [true|false]",
-		Short: "Enable or disable TUI mode (deprecated - Claude Code風インターフェースが標準)",
+		Short: "Enable or disable the keyboard-driven pane UI (false: plain output)",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			enabled, err := strconv.ParseBool(args[0])
@@ -441,6 +1563,48 @@ func (h *ConfigHandler) CreateConfigCommands() *cobra.Command {
 		},
 	}
 
+	// set-syntax-theme コマンド
+	setSyntaxThemeCmd := &cobra.Command{
+		Use:   "set-syntax-theme [theme]",
+		Short: "Set the color theme for code blocks and diffs (dark, light, none)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetSyntaxTheme(args[0])
+		},
+	}
+
+	// set-edit-mode コマンド
+	setEditModeCmd := &cobra.Command{
+		Use:   "set-edit-mode [mode]",
+		Short: "Set the input line key bindings (emacs, vi)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetEditMode(args[0])
+		},
+	}
+
+	// set-notifications コマンド
+	setNotificationsCmd := &cobra.Command{
+		Use:   "set-notifications [method] [events...]",
+		Short: "Notify when long turns finish (auto, bell, osc777, desktop, off; events: complete, input, error)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			threshold, _ := cmd.Flags().GetInt("threshold")
+			return h.SetNotifications(args[0], args[1:], threshold)
+		},
+	}
+	setNotificationsCmd.Flags().Int("threshold", 0, "Only notify for turns taking at least this many seconds")
+
+	// set-telemetry コマンド
+	setTelemetryCmd := &cobra.Command{
+		Use:   "set-telemetry [on|off] [events...]",
+		Short: "Record local-only usage statistics for vyb stats (events: session, turn, suggestion, edit)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetTelemetry(args[0], args[1:])
+		},
+	}
+
 	// 段階的移行設定コマンド
 	setMigrationModeCmd := &cobra.Command{
 		Use:   "set-migration-mode [mode]",
@@ -516,10 +1680,420 @@ func (h *ConfigHandler) CreateConfigCommands() *cobra.Command {
 		},
 	}
 
+	// サンドボックス設定コマンド
+	setSandboxModeCmd := &cobra.Command{
+		Use:   "set-sandbox-mode [mode]",
+		Short: "Set command execution backend (host, docker, podman)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetSandboxMode(args[0])
+		},
+	}
+
+	setSandboxImageCmd := &cobra.Command{
+		Use:   "set-sandbox-image [image]",
+		Short: "Set container image used by the sandbox backend",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetSandboxImage(args[0])
+		},
+	}
+
+	setNetworkPolicyCmd := &cobra.Command{
+		Use:   "set-network-policy [mode] [domains...]",
+		Short: "Set network policy for tool HTTP requests (allowlist, deny_all, allow_all)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var blocked []string
+			if cmd.Flags().Changed("block") {
+				blocked, _ = cmd.Flags().GetStringSlice("block")
+			}
+			return h.SetNetworkPolicy(args[0], args[1:], blocked)
+		},
+	}
+	setNetworkPolicyCmd.Flags().StringSlice("block", nil, "Domains to always block")
+
+	setSearchBackendCmd := &cobra.Command{
+		Use:   "set-search-backend [backend] [url-or-api-key]",
+		Short: "Set web search backend (duckduckgo, searxng <url>, brave <api-key>)",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			value := ""
+			if len(args) > 1 {
+				value = args[1]
+			}
+			return h.SetSearchBackend(args[0], value)
+		},
+	}
+
+	enableLLMCacheCmd := &cobra.Command{
+		Use:   "enable-llm-cache [true|false]",
+		Short: "Enable or disable the LLM response cache",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			var semantic *bool
+			if cmd.Flags().Changed("semantic") {
+				value, _ := cmd.Flags().GetBool("semantic")
+				semantic = &value
+			}
+			return h.EnableLLMCache(enable, semantic)
+		},
+	}
+	enableLLMCacheCmd.Flags().Bool("semantic", false, "Match near-duplicate prompts using embeddings")
+
+	setLanguageCmd := &cobra.Command{
+		Use:   "set-language [ja|en|auto]",
+		Short: "Set the UI and response language",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetLanguage(args[0])
+		},
+	}
+
+	enableFixLoopCmd := &cobra.Command{
+		Use:   "enable-fix-loop [true|false]",
+		Short: "Automatically fix build/test failures after applying edits",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			maxIterations, _ := cmd.Flags().GetInt("max-iterations")
+			if cmd.Flags().Changed("max-iterations") && maxIterations < 1 {
+				return fmt.Errorf("最大反復回数は1以上を指定してください")
+			}
+			var runTests *bool
+			if cmd.Flags().Changed("tests") {
+				value, _ := cmd.Flags().GetBool("tests")
+				runTests = &value
+			}
+			return h.EnableFixLoop(enable, maxIterations, runTests)
+		},
+	}
+	enableFixLoopCmd.Flags().Int("max-iterations", 0, "Maximum number of fix attempts")
+	enableFixLoopCmd.Flags().Bool("tests", true, "Run tests after a successful build")
+
+	enableCodeCheckCmd := &cobra.Command{
+		Use:   "enable-code-check [true|false]",
+		Short: "Validate generated code blocks (gofmt/go vet, tsc --noEmit, Python syntax) and ask the model to fix failures before showing them",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			maxRepairs := -1
+			if cmd.Flags().Changed("max-repairs") {
+				maxRepairs, _ = cmd.Flags().GetInt("max-repairs")
+				if maxRepairs < 0 {
+					return fmt.Errorf("修正の最大回数は0以上を指定してください")
+				}
+			}
+			var languages []string
+			if cmd.Flags().Changed("language") {
+				languages, _ = cmd.Flags().GetStringSlice("language")
+				if len(languages) == 1 && languages[0] == "all" {
+					languages = []string{}
+				}
+			}
+			return h.EnableCodeCheck(enable, maxRepairs, languages)
+		},
+	}
+	enableCodeCheckCmd.Flags().Int("max-repairs", 0, "Maximum repair round-trips with the model (0 only reports failures in the audit log)")
+	enableCodeCheckCmd.Flags().StringSlice("language", nil, "Languages to validate: go, typescript, python (\"all\" for every language)")
+
+	enableAgentLoopCmd := &cobra.Command{
+		Use:   "enable-agent-loop [true|false]",
+		Short: "Feed tool results back to the model until it gives a final answer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			maxSteps, _ := cmd.Flags().GetInt("max-steps")
+			if cmd.Flags().Changed("max-steps") && maxSteps < 1 {
+				return fmt.Errorf("最大ステップ数は1以上を指定してください")
+			}
+			return h.EnableAgentLoop(enable, maxSteps)
+		},
+	}
+	enableAgentLoopCmd.Flags().Int("max-steps", 0, "Maximum model responses per prompt, including the first")
+
+	enableToolStreamingCmd := &cobra.Command{
+		Use:   "enable-tool-streaming [true|false]",
+		Short: "Start running a command as soon as its tag is complete, while the model is still generating",
+		Long: `Stream model responses and run each <COMMAND> as soon as its closing tag arrives instead of waiting
+for the whole response. Commands still run one at a time in the order they appear, and the rest of the
+response (file writes, reads, analysis) is handled once it is complete, so results are the same as
+without streaming; only the wait is shorter. Applies to every step of the agent loop.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			return h.EnableToolStreaming(enable)
+		},
+	}
+
+	enableSessionTitlesCmd := &cobra.Command{
+		Use:   "enable-session-titles [true|false]",
+		Short: "Name each session with a short title generated after the first exchange",
+		Long: `After the first prompt is answered, ask a model for a short title describing the session. The title
+is shown in the TUI header and in "vyb sessions list", and can be changed at any time with /title.
+Use --model to generate titles with a small, fast model instead of the chat model ("" restores the
+chat model). If the model cannot be reached, the first prompt is used as the title.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			var model *string
+			if cmd.Flags().Changed("model") {
+				value, _ := cmd.Flags().GetString("model")
+				model = &value
+			}
+			return h.EnableSessionTitles(enable, model)
+		},
+	}
+	enableSessionTitlesCmd.Flags().String("model", "", "Model used to generate titles (empty for the chat model)")
+
+	setPipelineStageCmd := &cobra.Command{
+		Use:   "set-pipeline-stage [stage] [implementation]",
+		Short: "Choose the implementation of a response pipeline stage (intent, retrieval, generation, execution, render; \"default\" restores the built-in one)",
+		Args:  cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.SetPipelineStage(args[0], args[1])
+		},
+	}
+
+	addApprovalRuleCmd := &cobra.Command{
+		Use:   "add-approval-rule [name]",
+		Short: "Add a rule that auto-applies, prompts for or denies matching suggestions",
+		Long: `Approval rules are checked in order before the confirmation prompt; the first matching rule decides.
+Suggestions that match no rule ask for confirmation as before. Unset conditions match every suggestion.
+Adding a rule with an existing name replaces it in place (or moves it to the top with --first).
+
+Examples:
+  vyb config add-approval-rule main-branch --decision prompt --branch main --branch master --first
+  vyb config add-approval-rule doc-comments --decision auto --kind edit --change docs --min-confidence 0.9
+  vyb config add-approval-rule new-tests --decision auto --kind create --path '*_test.go' --min-confidence 0.9
+  vyb config add-approval-rule source --decision prompt --change source`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			rule := config.ApprovalRule{Name: args[0]}
+			rule.Decision, _ = cmd.Flags().GetString("decision")
+			rule.Kinds, _ = cmd.Flags().GetStringSlice("kind")
+			rule.Changes, _ = cmd.Flags().GetStringSlice("change")
+			rule.Paths, _ = cmd.Flags().GetStringArray("path")
+			rule.Branches, _ = cmd.Flags().GetStringArray("branch")
+			rule.MinConfidence, _ = cmd.Flags().GetFloat64("min-confidence")
+			rule.MaxImpact, _ = cmd.Flags().GetString("max-impact")
+			first, _ := cmd.Flags().GetBool("first")
+			return h.AddApprovalRule(rule, first)
+		},
+	}
+	addApprovalRuleCmd.Flags().String("decision", "prompt", "What to do with matching suggestions (auto, prompt, deny)")
+	addApprovalRuleCmd.Flags().StringSlice("kind", nil, "Match only these operations (create, edit, command)")
+	addApprovalRuleCmd.Flags().StringSlice("change", nil, "Match only these changes (docs: comments/documentation only, test: test files, source)")
+	addApprovalRuleCmd.Flags().StringArray("path", nil, "Match only target files matching this glob (repeatable; dir/** matches a whole directory)")
+	addApprovalRuleCmd.Flags().StringArray("branch", nil, "Match only on git branches matching this glob (repeatable)")
+	addApprovalRuleCmd.Flags().Float64("min-confidence", 0, "Match only suggestions with at least this confidence (0.0-1.0)")
+	addApprovalRuleCmd.Flags().String("max-impact", "", "Match only suggestions with at most this impact (low, medium, high, critical)")
+	addApprovalRuleCmd.Flags().Bool("first", false, "Check this rule before all other rules")
+
+	removeApprovalRuleCmd := &cobra.Command{
+		Use:   "remove-approval-rule [name]",
+		Short: "Remove an approval rule",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.RemoveApprovalRule(args[0])
+		},
+	}
+
+	setTurnLimitsCmd := &cobra.Command{
+		Use:   "set-turn-limits",
+		Short: "Limit tool calls, tokens and wall time per prompt (-1 disables a limit)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var limits [3]*int
+			for i, name := range []string{"tool-calls", "tokens", "seconds"} {
+				if cmd.Flags().Changed(name) {
+					value, _ := cmd.Flags().GetInt(name)
+					limits[i] = &value
+				}
+			}
+			if limits[0] == nil && limits[1] == nil && limits[2] == nil {
+				return fmt.Errorf("--tool-calls、--tokens、--seconds のいずれかを指定してください")
+			}
+			return h.SetTurnLimits(limits[0], limits[1], limits[2])
+		},
+	}
+	setTurnLimitsCmd.Flags().Int("tool-calls", 0, "Maximum tool and command executions per prompt")
+	setTurnLimitsCmd.Flags().Int("tokens", 0, "Maximum LLM tokens (prompt + completion) per prompt")
+	setTurnLimitsCmd.Flags().Int("seconds", 0, "Maximum wall-clock seconds per prompt")
+
+	setHookChecksCmd := &cobra.Command{
+		Use:   "set-hook-checks [checks]",
+		Short: "Choose the checks run by git hooks (comma-separated: lint, secrets, commit-message; \"none\" disables all)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var checks []string
+			if args[0] != "none" {
+				for _, check := range strings.Split(args[0], ",") {
+					if check = strings.TrimSpace(check); check != "" {
+						checks = append(checks, check)
+					}
+				}
+			}
+			var blockOnLint *bool
+			if cmd.Flags().Changed("block-on-lint") {
+				value, _ := cmd.Flags().GetBool("block-on-lint")
+				blockOnLint = &value
+			}
+			return h.SetHookChecks(checks, blockOnLint)
+		},
+	}
+	setHookChecksCmd.Flags().Bool("block-on-lint", false, "Abort the commit when lint fails (default: only show the summary)")
+
+	setRedactionCmd := &cobra.Command{
+		Use:   "set-redaction [off|low|standard|strict]",
+		Short: "Choose how aggressively secrets are redacted from file contents and command output before they reach the LLM or the audit log",
+		Long: `Choose how aggressively secrets are replaced with [REDACTED:<kind>] placeholders before
+prompts are sent to the LLM and before events are written to the audit log.
+
+  off       nothing is redacted
+  low       well-known token formats (AWS, GitHub, Slack, API keys) and private key blocks
+  standard  also quoted values assigned to password/secret/token/api_key names, .env values
+            and credentials in URLs (default)
+  strict    also unquoted assignments, JWTs and long random-looking strings`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var patterns []string
+			if cmd.Flags().Changed("pattern") {
+				patterns, _ = cmd.Flags().GetStringArray("pattern")
+				if len(patterns) == 1 && patterns[0] == "none" {
+					patterns = []string{}
+				}
+			}
+			return h.SetRedaction(args[0], patterns)
+		},
+	}
+	setRedactionCmd.Flags().StringArray("pattern", nil, "Extra regular expression to redact (repeatable; only the first group is hidden when it has one; \"none\" clears them)")
+
+	setIgnorePatternsCmd := &cobra.Command{
+		Use:   "set-ignore-patterns [patterns...]",
+		Short: "Set extra .gitignore-style patterns excluded from analysis, indexing and file tools (\"none\" clears them)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 1 && args[0] == "none" {
+				args = nil
+			}
+			return h.SetIgnorePatterns(args)
+		},
+	}
+
+	checkIgnoreCmd := &cobra.Command{
+		Use:   "check-ignore [paths...]",
+		Short: "Show whether paths are excluded and which pattern (built-in, config, .gitignore or .vybignore) decided it",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.CheckIgnore(args)
+		},
+	}
+
+	setEmbeddingModelCmd := &cobra.Command{
+		Use:   "set-embedding-model [model]",
+		Short: "Set the embedding model used for indexing, semantic cache and compression checks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			baseURL, _ := cmd.Flags().GetString("base-url")
+			api, _ := cmd.Flags().GetString("api")
+			return h.SetEmbeddings(args[0], baseURL, api)
+		},
+	}
+	setEmbeddingModelCmd.Flags().String("base-url", "", "Embedding server URL (defaults to the chat server)")
+	setEmbeddingModelCmd.Flags().String("api", "", "Embedding endpoint: auto, embed (/api/embed) or embeddings (/api/embeddings)")
+
+	enableUsageCmd := &cobra.Command{
+		Use:   "enable-usage [true|false]",
+		Short: "Enable or disable token usage and cost tracking",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			return h.EnableUsage(enable)
+		},
+	}
+
+	enableCheckpointsCmd := &cobra.Command{
+		Use:   "enable-checkpoints [true|false]",
+		Short: "Snapshot the workspace before each turn so /rewind can restore it",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			enable, err := strconv.ParseBool(args[0])
+			if err != nil {
+				return fmt.Errorf("無効な値です。true または false を指定してください")
+			}
+			maxPerSession, _ := cmd.Flags().GetInt("max")
+			if cmd.Flags().Changed("max") && maxPerSession < 1 {
+				return fmt.Errorf("保持数は1以上を指定してください")
+			}
+			return h.EnableCheckpoints(enable, maxPerSession)
+		},
+	}
+	enableCheckpointsCmd.Flags().Int("max", 0, "Maximum number of checkpoints kept per session")
+
+	setStorageCmd := &cobra.Command{
+		Use:   "set-storage <sqlite|memory> [path]",
+		Short: "Store sessions, metrics, conversations and the response cache in SQLite or in memory",
+		Long: `Choose where sessions, per-session metrics, conversation flow, messages and the LLM response
+cache are kept. sqlite (default) persists them in a single database file (~/.vyb/vyb.db unless a path
+is given) so they survive restarts and can be listed and searched with "vyb storage". memory keeps
+them only for the lifetime of the process.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := ""
+			if len(args) == 2 {
+				path = args[1]
+			}
+			return h.SetStorage(args[0], path)
+		},
+	}
+
+	setModelPriceCmd := &cobra.Command{
+		Use:   "set-model-price [model] [prompt-per-1k] [completion-per-1k]",
+		Short: "Set per-1K-token prices for a model (name, prefix or \"*\")",
+		Args:  cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			promptPrice, err := strconv.ParseFloat(args[1], 64)
+			if err != nil {
+				return fmt.Errorf("無効な単価です: %s", args[1])
+			}
+			completionPrice, err := strconv.ParseFloat(args[2], 64)
+			if err != nil {
+				return fmt.Errorf("無効な単価です: %s", args[2])
+			}
+			currency, _ := cmd.Flags().GetString("currency")
+			return h.SetModelPrice(args[0], promptPrice, completionPrice, currency)
+		},
+	}
+	setModelPriceCmd.Flags().String("currency", "", "Currency used for cost display (e.g. USD, JPY)")
+
 	// サブコマンドを追加
 	configCmd.AddCommand(setModelCmd, setProviderCmd, listCmd)
+	configCmd.AddCommand(setLanguageCmd)
 	configCmd.AddCommand(setLogLevelCmd, setLogFormatCmd)
-	configCmd.AddCommand(setTUICmd, setTUIThemeCmd)
+	configCmd.AddCommand(setTUICmd, setTUIThemeCmd, setSyntaxThemeCmd, setEditModeCmd, setNotificationsCmd, setTelemetryCmd)
 
 	// 段階的移行コマンドを追加
 	configCmd.AddCommand(setMigrationModeCmd)
@@ -527,6 +2101,54 @@ func (h *ConfigHandler) CreateConfigCommands() *cobra.Command {
 	configCmd.AddCommand(enableUnifiedToolsCmd, enableUnifiedAnalysisCmd)
 	configCmd.AddCommand(enableValidationCmd)
 
+	// サンドボックスコマンドを追加
+	configCmd.AddCommand(setSandboxModeCmd, setSandboxImageCmd)
+
+	// ネットワークポリシーコマンドを追加
+	configCmd.AddCommand(setNetworkPolicyCmd, setSearchBackendCmd)
+
+	// LLMキャッシュコマンドを追加
+	configCmd.AddCommand(enableLLMCacheCmd)
+
+	// 自動修正ループコマンドを追加
+	configCmd.AddCommand(enableFixLoopCmd, enableCodeCheckCmd)
+
+	// エージェントループコマンドを追加
+	configCmd.AddCommand(enableAgentLoopCmd, enableToolStreamingCmd, enableSessionTitlesCmd)
+
+	// 応答生成パイプラインコマンドを追加
+	configCmd.AddCommand(setPipelineStageCmd)
+
+	// 自動承認ルールコマンドを追加
+	configCmd.AddCommand(addApprovalRuleCmd, removeApprovalRuleCmd)
+
+	// ターン上限コマンドを追加
+	configCmd.AddCommand(setTurnLimitsCmd)
+
+	// 除外パターンコマンドを追加
+	configCmd.AddCommand(setIgnorePatternsCmd, checkIgnoreCmd)
+
+	// 埋め込みモデルコマンドを追加
+	configCmd.AddCommand(setEmbeddingModelCmd)
+
+	// gitフックコマンドを追加
+	configCmd.AddCommand(setHookChecksCmd)
+
+	// 機密情報の伏せ字化コマンドを追加
+	configCmd.AddCommand(setRedactionCmd)
+
+	// 使用量・コストコマンドを追加
+	configCmd.AddCommand(enableUsageCmd, setModelPriceCmd)
+
+	// チェックポイントコマンドを追加
+	configCmd.AddCommand(enableCheckpointsCmd)
+
+	// 保存先コマンドを追加
+	configCmd.AddCommand(setStorageCmd)
+
+	// 階層設定の診断コマンドを追加
+	configCmd.AddCommand(h.createDoctorCommand())
+
 	return configCmd
 }
 
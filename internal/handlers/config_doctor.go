@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/spf13/cobra"
+)
+
+// Doctor は階層設定を解決し、レイヤー・上書きされた値・検証結果・実効設定を表示
+// エラーの問題がある場合は終了コードで分かるようエラーを返す
+func (h *ConfigHandler) Doctor(profile string, asJSON bool) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(profile)})
+	if err != nil {
+		return fmt.Errorf("設定解決エラー: %w", err)
+	}
+	resolved.Issues = append(resolved.Issues, runtimeConfigIssues(resolved.Config)...)
+
+	if asJSON {
+		data, err := json.MarshalIndent(resolved, "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON変換エラー: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		output, err := formatDoctorReport(resolved)
+		if err != nil {
+			return err
+		}
+		fmt.Print(output)
+	}
+
+	if resolved.HasErrors() {
+		return fmt.Errorf("設定にエラーがあります")
+	}
+	return nil
+}
+
+// runtimeConfigIssues はconfigパッケージ外で定義された列挙値（サンドボックス・ネットワーク）を検証
+func runtimeConfigIssues(cfg *config.Config) []config.Issue {
+	var issues []config.Issue
+	if !sandbox.IsValidMode(cfg.Sandbox.Mode) {
+		issues = append(issues, config.Issue{
+			Key:      "sandbox.mode",
+			Message:  fmt.Sprintf("unknown sandbox mode %q (valid: %s)", cfg.Sandbox.Mode, strings.Join(sandbox.ValidModes(), ", ")),
+			Severity: config.SeverityError,
+		})
+	}
+	if !security.IsValidNetworkMode(cfg.Network.Mode) {
+		issues = append(issues, config.Issue{
+			Key:      "network.mode",
+			Message:  fmt.Sprintf("unknown network mode %q (valid: %s)", cfg.Network.Mode, strings.Join(security.ValidNetworkModes(), ", ")),
+			Severity: config.SeverityError,
+		})
+	}
+	return issues
+}
+
+// formatDoctorReport は config doctor のテキスト出力
+func formatDoctorReport(resolved *config.Resolved) (string, error) {
+	var b strings.Builder
+
+	b.WriteString("Configuration layers (later layers win):\n")
+	for _, layer := range resolved.Layers {
+		mark := "-"
+		if layer.Found {
+			mark = "✓"
+		}
+		fmt.Fprintf(&b, "  %s %-16s %s\n", mark, layer.Name, layer.Path)
+	}
+
+	profile := resolved.Profile
+	if profile == "" {
+		profile = "(none)"
+	}
+	fmt.Fprintf(&b, "\nProfile: %s", profile)
+	if len(resolved.Profiles) > 0 {
+		fmt.Fprintf(&b, " (defined: %s)", strings.Join(resolved.Profiles, ", "))
+	}
+	b.WriteString("\n")
+
+	if len(resolved.Sources) > 0 {
+		keys := make([]string, 0, len(resolved.Sources))
+		for key := range resolved.Sources {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		b.WriteString("\nOverrides:\n")
+		for _, key := range keys {
+			source := resolved.Sources[key]
+			value, _ := json.Marshal(source.Value)
+			fmt.Fprintf(&b, "  %s = %s  (%s)\n", key, value, source.Layer)
+		}
+	}
+
+	b.WriteString("\nValidation:\n")
+	if len(resolved.Issues) == 0 {
+		b.WriteString("  ✓ no problems found\n")
+	}
+	for _, issue := range resolved.Issues {
+		mark := "!"
+		if issue.Severity == config.SeverityError {
+			mark = "✗"
+		}
+		fmt.Fprintf(&b, "  %s %s\n", mark, issue)
+	}
+
+	data, err := json.MarshalIndent(resolved.Config, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("JSON変換エラー: %w", err)
+	}
+	fmt.Fprintf(&b, "\nEffective configuration:\n%s\n", data)
+	return b.String(), nil
+}
+
+// createDoctorCommand は config doctor コマンドを作成
+func (h *ConfigHandler) createDoctorCommand() *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Validate layered configuration and show the effective settings",
+		Long: `Resolve the configuration layers in order - built-in defaults, the global ~/.vyb/config.json, its profiles.<name>, the project .vyb/config.yaml (searched upward to the repository root) and its profiles.<name> - and report where each overridden value comes from, schema and value errors, and the effective merged configuration.
+
+Mappings are merged key by key; scalars and lists are replaced by the later layer. Select a profile with --profile or the VYB_PROFILE environment variable.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Doctor(profile, asJSON)
+		},
+	}
+	doctorCmd.Flags().Bool("json", false, "Print the resolved layers, sources, issues and configuration as JSON")
+	return doctorCmd
+}
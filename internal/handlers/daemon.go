@@ -0,0 +1,312 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/embeddings"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/maintenance"
+	"github.com/spf13/cobra"
+)
+
+// 開始時の要約に表示する新しい脆弱性の最大数
+const maintenanceSummaryMaxVulns = 5
+
+// DaemonHandler は定期メンテナンス（vyb daemon）のハンドラー
+type DaemonHandler struct {
+	log logger.Logger
+}
+
+// NewDaemonHandler は定期メンテナンスハンドラーを作成
+func NewDaemonHandler(log logger.Logger) *DaemonHandler {
+	return &DaemonHandler{log: log}
+}
+
+// Run はカレントディレクトリのプロジェクトで毎日の実行時刻にメンテナンスのタスクを実行する
+// once なら今すぐ1回だけ実行して終了する。結果は次の対話セッションの開始時に要約される
+func (h *DaemonHandler) Run(profile string, once bool) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	daemon := &maintenance.Daemon{
+		Runner: &maintenance.Runner{
+			ProjectDir:   projectDir,
+			Config:       cfg.Daemon,
+			RefreshIndex: refreshIndexTask(cfg, projectDir),
+		},
+		Store: maintenance.NewStore(maintenance.DefaultDir()),
+		OnReport: func(report *maintenance.Report, err error) {
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "⚠️  %v\n", err)
+			}
+			printMaintenanceReport(report)
+			h.log.Info("定期メンテナンスを実行しました", map[string]interface{}{
+				"project":         projectDir,
+				"vulnerabilities": len(report.Vulnerabilities),
+				"stale_branches":  len(report.Branches),
+				"errors":          len(report.Errors),
+				"duration_ms":     report.FinishedAt.Sub(report.StartedAt).Milliseconds(),
+			})
+		},
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	if !once {
+		fmt.Printf("🛠  Maintenance daemon for %s: %s daily at %s (Ctrl+C to stop)\n", projectDir, strings.Join(cfg.Daemon.Tasks, ", "), cfg.Daemon.At)
+	}
+	return daemon.Run(ctx, once)
+}
+
+// refreshIndexTask は埋め込みインデックスを更新するタスク（vyb index と同じく変更のあったファイルのみ再生成）
+func refreshIndexTask(cfg *config.Config, root string) func(ctx context.Context) (*maintenance.IndexResult, error) {
+	return func(ctx context.Context) (*maintenance.IndexResult, error) {
+		idx, err := embeddings.Load(embeddings.ResolveCacheDir(cfg.Embeddings.CacheDir), root, cfg.ResolvedEmbeddingModel(""))
+		if err != nil {
+			return nil, err
+		}
+		files, err := embeddings.CollectFiles(idx.Root)
+		if err != nil {
+			return nil, err
+		}
+		stats, updateErr := idx.Update(ctx, llm.NewEmbeddingClient(cfg), files)
+		// 途中で失敗しても生成済みの埋め込みは次回に使えるよう保存する
+		if err := idx.Save(); err != nil {
+			return nil, err
+		}
+		return &maintenance.IndexResult{Files: len(files), Embedded: stats.Embedded, Removed: stats.Removed, Model: idx.Model}, updateErr
+	}
+}
+
+// Status はデーモンの動作状況・次回の実行予定と直近の実行結果を表示する
+func (h *DaemonHandler) Status(profile string, jsonOutput bool) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	store := maintenance.NewStore(maintenance.DefaultDir())
+	state, err := store.Load(projectDir)
+	if err != nil {
+		return err
+	}
+	pid := store.RunningPID(projectDir)
+
+	if jsonOutput {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"project":     projectDir,
+			"running_pid": pid,
+			"latest":      state.Latest(),
+		})
+	}
+
+	if pid != 0 {
+		fmt.Printf("🛠  Daemon running (PID %d): %s daily at %s\n", pid, strings.Join(cfg.Daemon.Tasks, ", "), cfg.Daemon.At)
+	} else {
+		fmt.Println("🛠  Daemon not running (start it with: vyb daemon)")
+	}
+	latest := state.Latest()
+	if latest == nil {
+		fmt.Println("No maintenance run recorded for this project yet (run one now with: vyb daemon --once)")
+		return nil
+	}
+	if pid != 0 {
+		if next, err := maintenance.NextRun(cfg.Daemon.At, latest.StartedAt, time.Now()); err == nil {
+			fmt.Printf("Next run: %s\n", next.Format("2006-01-02 15:04"))
+		}
+	}
+	fmt.Printf("Last run: %s (%s ago)\n\n", latest.FinishedAt.Format("2006-01-02 15:04"), formatAge(time.Since(latest.FinishedAt)))
+	printMaintenanceReport(latest)
+	return nil
+}
+
+// printMaintenanceReport は1回の実行結果を表示
+func printMaintenanceReport(report *maintenance.Report) {
+	for _, task := range report.Tasks {
+		switch task {
+		case "vulnerabilities":
+			scanned := strings.Join(report.Scanners, ", ")
+			if scanned == "" {
+				scanned = "no scanner"
+			}
+			fmt.Printf("🔐 Vulnerabilities: %d (%s)\n", len(report.Vulnerabilities), scanned)
+			for _, vuln := range report.Vulnerabilities {
+				fmt.Printf("   %s\n", formatVulnerability(vuln))
+			}
+		case "index":
+			if report.Index != nil {
+				fmt.Printf("🧭 Index: %d file(s), %d re-embedded, %d removed (%s)\n", report.Index.Files, report.Index.Embedded, report.Index.Removed, report.Index.Model)
+			}
+		case "branches":
+			fmt.Printf("🌿 Stale branches: %d\n", len(report.Branches))
+			for _, branch := range report.Branches {
+				state := formatAge(time.Since(branch.LastCommit)) + " old"
+				if branch.Merged {
+					state += ", merged"
+				}
+				fmt.Printf("   %s \033[38;5;244m(%s, %s)\033[0m\n", branch.Name, state, branch.Author)
+			}
+		}
+	}
+	printMaintenanceErrors(report.Errors)
+}
+
+func printMaintenanceErrors(errors map[string]string) {
+	tasks := make([]string, 0, len(errors))
+	for task := range errors {
+		tasks = append(tasks, task)
+	}
+	sort.Strings(tasks)
+	for _, task := range tasks {
+		fmt.Printf("\033[38;5;214m   ⚠ %s: %s\033[0m\n", task, errors[task])
+	}
+}
+
+// formatVulnerability は脆弱性を1行で表す（例: CVE-2024-1234 example.com/lib@v0.1.0 (fixed in v0.2.0): 概要）
+func formatVulnerability(vuln maintenance.Vulnerability) string {
+	line := vuln.DisplayID() + " " + vuln.Package
+	if vuln.Version != "" {
+		line += "@" + vuln.Version
+	}
+	if vuln.FixedIn != "" {
+		line += i18n.T("maintenance.fixed_in", vuln.FixedIn)
+	}
+	if vuln.Summary != "" {
+		line += ": " + truncateLine(vuln.Summary, 80)
+	}
+	return line
+}
+
+// showMaintenanceSummary は前回の対話セッション以降に vyb daemon が実行した結果を要約して表示し、表示済みにする
+func (h *ChatHandler) showMaintenanceSummary() {
+	if h.cfg == nil || !h.cfg.Daemon.Summary {
+		return
+	}
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return
+	}
+	store := maintenance.NewStore(maintenance.DefaultDir())
+	state, err := store.Load(projectDir)
+	if err != nil {
+		return
+	}
+	summary := state.Unseen()
+	if summary == nil {
+		return
+	}
+
+	var parts []string
+	if len(summary.NewVulnerabilities) > 0 {
+		parts = append(parts, i18n.T("maintenance.new_vulns", len(summary.NewVulnerabilities)))
+	} else {
+		parts = append(parts, i18n.T("maintenance.no_new_vulns"))
+	}
+	if summary.Resolved > 0 {
+		parts = append(parts, i18n.T("maintenance.resolved", summary.Resolved))
+	}
+	if len(summary.StaleBranches) > 0 {
+		parts = append(parts, i18n.T("maintenance.stale_branches", len(summary.StaleBranches)))
+	}
+	if summary.Index != nil && summary.Index.Embedded+summary.Index.Removed > 0 {
+		parts = append(parts, i18n.T("maintenance.index", summary.Index.Embedded+summary.Index.Removed))
+	}
+	since := summary.Since
+	if since.IsZero() {
+		since = summary.LastRun
+	}
+
+	color := "38;5;244"
+	if len(summary.NewVulnerabilities) > 0 {
+		color = "38;5;214"
+	}
+	fmt.Printf("\033[%sm%s\033[0m\n", color, i18n.T("maintenance.summary", formatSince(since, time.Now()), strings.Join(parts, ", ")))
+	for i, vuln := range summary.NewVulnerabilities {
+		if i == maintenanceSummaryMaxVulns {
+			fmt.Printf("   … +%d\n", len(summary.NewVulnerabilities)-i)
+			break
+		}
+		fmt.Printf("   %s\n", formatVulnerability(vuln))
+	}
+	printMaintenanceErrors(summary.Errors)
+	fmt.Printf("\033[38;5;244m%s\033[0m\n", i18n.T("maintenance.details"))
+
+	state.MarkSeen(time.Now())
+	if err := store.Save(state); err != nil {
+		h.log.Warn("定期メンテナンスの要約を表示済みにできませんでした", map[string]interface{}{"error": err.Error()})
+	}
+}
+
+// formatSince は要約の起点を「今日」「昨日」「3d 前」のように表す
+func formatSince(since, now time.Time) string {
+	y, m, d := now.Date()
+	today := time.Date(y, m, d, 0, 0, 0, 0, now.Location())
+	switch {
+	case !since.Before(today):
+		return i18n.T("maintenance.since_today")
+	case !since.Before(today.AddDate(0, 0, -1)):
+		return i18n.T("maintenance.since_yesterday")
+	}
+	return i18n.T("maintenance.since_ago", formatAge(now.Sub(since)))
+}
+
+// CreateDaemonCommands は定期メンテナンスコマンドを作成
+func (h *DaemonHandler) CreateDaemonCommands() *cobra.Command {
+	daemonCmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Run scheduled maintenance for this project (vulnerability scan, index refresh, stale-branch report)",
+		Long: `Run the maintenance tasks configured in "daemon" every day at daemon.at (default 03:00) for the
+current project: a dependency vulnerability scan (govulncheck, npm audit, pip-audit when installed),
+an embedding index refresh and a report of stale or merged branches. A run missed while the daemon
+was stopped starts right away. Results are stored in ~/.vyb/maintenance and summarized at the start
+of the next interactive session. Run it under nohup, systemd or launchd to keep it in the background.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			profile, _ := cmd.Flags().GetString("profile")
+			once, _ := cmd.Flags().GetBool("once")
+			return h.Run(profile, once)
+		},
+	}
+	daemonCmd.Flags().Bool("once", false, "Run the tasks now once and exit")
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show whether the daemon is running and the result of the last run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			profile, _ := cmd.Flags().GetString("profile")
+			jsonOutput, _ := cmd.Flags().GetBool("json")
+			return h.Status(profile, jsonOutput)
+		},
+	}
+	statusCmd.Flags().Bool("json", false, "Output the last run as JSON")
+
+	daemonCmd.AddCommand(statusCmd)
+	return daemonCmd
+}
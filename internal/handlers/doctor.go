@@ -0,0 +1,186 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/diagnostic"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// DoctorHandler は実行環境の診断（ツールのバージョン・OS・環境変数・設定・LLM への到達）のハンドラー
+type DoctorHandler struct {
+	log logger.Logger
+}
+
+// NewDoctorHandler は診断ハンドラーを作成
+func NewDoctorHandler(log logger.Logger) *DoctorHandler {
+	return &DoctorHandler{log: log}
+}
+
+// DoctorOptions は vyb doctor の指定内容
+type DoctorOptions struct {
+	Profile  string
+	Tools    []string // 既定に加えてバージョンを調べるツール
+	JSON     bool
+	Markdown bool // 不具合報告に貼り付ける Markdown の環境レポートだけを出す
+}
+
+// doctorCheck は診断の項目の結果
+type doctorCheck struct {
+	Name     string `json:"name"`
+	Severity string `json:"severity"` // ok, warning, error
+	Message  string `json:"message"`
+}
+
+// Run は環境レポートを作成し、設定の検証と LLM のエンドポイントへの到達を確認する
+// エラーの項目がある場合は終了コードで分かるようエラーを返す
+func (h *DoctorHandler) Run(ctx context.Context, opts DoctorOptions) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定解決エラー: %w", err)
+	}
+	cfg := resolved.Config
+	report := diagnostic.CaptureEnvironment(ctx, diagnostic.EnvironmentOptions{
+		Config:  cfg,
+		Profile: resolved.Profile,
+		Tools:   opts.Tools,
+	})
+	if opts.Markdown {
+		fmt.Print(report.Markdown())
+		return nil
+	}
+
+	checks := doctorChecks(ctx, cfg, resolved.Issues, report)
+	if opts.JSON {
+		data, err := json.MarshalIndent(struct {
+			Environment *diagnostic.EnvironmentReport `json:"environment"`
+			Checks      []doctorCheck                 `json:"checks"`
+		}{report, checks}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("JSON変換エラー: %w", err)
+		}
+		fmt.Println(string(data))
+	} else {
+		fmt.Print(formatDoctor(report, checks))
+	}
+
+	h.log.Info("環境を診断しました", map[string]interface{}{
+		"checks":  len(checks),
+		"missing": report.MissingTools(),
+	})
+	for _, check := range checks {
+		if check.Severity == config.SeverityError {
+			return fmt.Errorf("診断でエラーが見つかりました")
+		}
+	}
+	return nil
+}
+
+// doctorChecks は設定の検証・LLM への到達・見つからないツールを診断の項目にする
+func doctorChecks(ctx context.Context, cfg *config.Config, issues []config.Issue, report *diagnostic.EnvironmentReport) []doctorCheck {
+	var checks []doctorCheck
+	issues = append(issues, runtimeConfigIssues(cfg)...)
+	if len(issues) == 0 {
+		checks = append(checks, doctorCheck{Name: "config", Severity: "ok", Message: "no problems found"})
+	}
+	for _, issue := range issues {
+		checks = append(checks, doctorCheck{Name: "config", Severity: issue.Severity, Message: issue.String()})
+	}
+
+	probeCtx, cancel := context.WithTimeout(ctx, providerProbeTimeout)
+	defer cancel()
+	if err := llm.NewResilientProvider(llmEndpoints(cfg), cfg.Resilience).Probe(probeCtx); err != nil {
+		checks = append(checks, doctorCheck{Name: "llm", Severity: config.SeverityError, Message: fmt.Sprintf("%s is unreachable: %v", cfg.BaseURL, err)})
+	} else {
+		checks = append(checks, doctorCheck{Name: "llm", Severity: "ok", Message: fmt.Sprintf("%s is reachable", cfg.BaseURL)})
+	}
+
+	if missing := report.MissingTools(); len(missing) > 0 {
+		checks = append(checks, doctorCheck{Name: "tools", Severity: config.SeverityWarning, Message: "not found in PATH: " + strings.Join(missing, ", ")})
+	}
+	return checks
+}
+
+// formatDoctor は vyb doctor のテキスト出力
+func formatDoctor(report *diagnostic.EnvironmentReport, checks []doctorCheck) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "vyb %s (%s)\n", report.Vyb, report.GoRuntime)
+	fmt.Fprintf(&b, "OS: %s/%s", report.OS.GOOS, report.OS.GOARCH)
+	for _, detail := range []string{report.OS.Release, report.OS.Kernel} {
+		if detail != "" {
+			fmt.Fprintf(&b, ", %s", detail)
+		}
+	}
+	fmt.Fprintf(&b, ", %d CPU(s)\n", report.OS.NumCPU)
+
+	b.WriteString("\nTools:\n")
+	for _, tool := range report.Tools {
+		if tool.Error != "" {
+			fmt.Fprintf(&b, "  - %-8s %s\n", tool.Name, tool.Error)
+			continue
+		}
+		fmt.Fprintf(&b, "  ✓ %-8s %s (%s)\n", tool.Name, tool.Version, tool.Path)
+	}
+
+	if len(report.Env) > 0 {
+		b.WriteString("\nEnvironment:\n")
+		for _, setting := range report.Env {
+			fmt.Fprintf(&b, "  %s=%s\n", setting.Name, setting.Value)
+		}
+	}
+	if len(report.Config) > 0 {
+		b.WriteString("\nConfiguration:\n")
+		for _, setting := range report.Config {
+			fmt.Fprintf(&b, "  %s: %s\n", setting.Name, setting.Value)
+		}
+	}
+
+	b.WriteString("\nChecks:\n")
+	for _, check := range checks {
+		mark := "✓"
+		switch check.Severity {
+		case config.SeverityError:
+			mark = "✗"
+		case config.SeverityWarning:
+			mark = "!"
+		}
+		fmt.Fprintf(&b, "  %s %-6s %s\n", mark, check.Name, check.Message)
+	}
+	b.WriteString("\nAttach the report to a bug report with: vyb doctor --markdown\n")
+	return b.String()
+}
+
+// CreateDoctorCommand は doctor コマンドを作成
+func (h *DoctorHandler) CreateDoctorCommand() *cobra.Command {
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check the environment and print a report for debugging environment-specific failures",
+		Long: `Gather the versions of go, node, npm, python3, docker, git and ollama (add more with --tools), OS and kernel,
+relevant environment variables (GOPATH, NODE_OPTIONS, DOCKER_HOST, proxies, VYB_* ...) and the effective vyb
+configuration, then check the configuration and whether the LLM endpoint is reachable.
+
+Values of variables whose names look like secrets (TOKEN, KEY, PASSWORD ...) are replaced, other values go through
+the redactor and the home directory is shown as ~, so the report can be shared. Use --markdown to print a report
+to paste into a bug report. The assistant can gather the same report with the diagnostics tool.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts DoctorOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Tools, _ = cmd.Flags().GetStringSlice("tools")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			opts.Markdown, _ = cmd.Flags().GetBool("markdown")
+			cmd.SilenceUsage = true
+			return h.Run(cmd.Context(), opts)
+		},
+	}
+	doctorCmd.Flags().StringSlice("tools", nil, "Additional tools to check with <name> --version (e.g. cargo,rustc)")
+	doctorCmd.Flags().Bool("json", false, "Print the report and checks as JSON")
+	doctorCmd.Flags().Bool("markdown", false, "Print only the environment report as Markdown for a bug report")
+	return doctorCmd
+}
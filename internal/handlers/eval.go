@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/eval"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// EvalHandler は記録したモデル応答による回帰テスト（.vyb/evals）のハンドラー
+type EvalHandler struct {
+	log logger.Logger
+}
+
+// NewEvalHandler は評価ハンドラーを作成
+func NewEvalHandler(log logger.Logger) *EvalHandler {
+	return &EvalHandler{log: log}
+}
+
+// EvalOptions は vyb eval の指定内容
+type EvalOptions struct {
+	Profile string
+	Dir     string // シナリオのディレクトリ（空なら .vyb/evals）
+	Live    bool   // 記録した応答ではなくモデルに問い合わせる
+	Record  bool   // live の応答をシナリオに記録する
+	JSON    bool
+}
+
+// Run はシナリオ（names が空ならディレクトリの全て）を評価し、1件でも失敗すればエラーを返す
+func (h *EvalHandler) Run(ctx context.Context, names []string, opts EvalOptions) error {
+	dir, err := h.scenarioDir(opts.Dir)
+	if err != nil {
+		return err
+	}
+	scenarios, invalid, err := loadScenarios(dir, names)
+	if err != nil {
+		return err
+	}
+	if len(scenarios) == 0 && len(invalid) == 0 {
+		fmt.Printf("No scenarios found in %s\n", dir)
+		return nil
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	runner := &eval.Runner{
+		NewReplayer: func() (eval.Replayer, error) { return interactive.NewReplayer(cfg) },
+		Live:        opts.Live || opts.Record,
+	}
+	if runner.Live {
+		runner.Provider = newLLMProvider(cfg)
+		runner.Model = cfg.ResolvedModel()
+	}
+
+	// ツールの実行中に表示される進捗がJSONに混ざらないよう stderr に退避
+	out := os.Stdout
+	if opts.JSON {
+		os.Stdout = os.Stderr
+		defer func() { os.Stdout = out }()
+	}
+
+	start := time.Now()
+	results := make([]*eval.Result, 0, len(scenarios)+len(invalid))
+	for _, scenario := range scenarios {
+		result := runner.Run(ctx, scenario)
+		if opts.Record && result.Error == "" && scenario.Path != "" {
+			if err := eval.SaveResponse(scenario.Path, result.Response); err != nil {
+				result.Error = err.Error()
+				result.Passed = false
+			}
+		}
+		results = append(results, result)
+		if !opts.JSON {
+			printEvalResult(out, result, opts.Record)
+		}
+	}
+	for _, path := range sortedErrorPaths(invalid) {
+		result := &eval.Result{Name: path, Path: path, Error: invalid[path].Error()}
+		results = append(results, result)
+		if !opts.JSON {
+			printEvalResult(out, result, false)
+		}
+	}
+
+	failed := 0
+	for _, result := range results {
+		if !result.Passed {
+			failed++
+		}
+	}
+	h.log.Info("評価を実行しました", map[string]interface{}{
+		"scenarios": len(results),
+		"failed":    failed,
+		"live":      runner.Live,
+	})
+
+	if opts.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	} else {
+		fmt.Fprintf(out, "\n%d passed, %d failed (%s)\n", len(results)-failed, failed, time.Since(start).Round(time.Millisecond))
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 件のシナリオが失敗しました", failed)
+	}
+	return nil
+}
+
+// List はシナリオの一覧を表示
+func (h *EvalHandler) List(dirFlag string) error {
+	dir, err := h.scenarioDir(dirFlag)
+	if err != nil {
+		return err
+	}
+	scenarios, invalid := eval.List(dir)
+	if len(scenarios) == 0 && len(invalid) == 0 {
+		fmt.Printf("No scenarios found in %s\n", dir)
+		return nil
+	}
+	for _, scenario := range scenarios {
+		fmt.Printf("%-24s", scenario.Name)
+		if scenario.Description != "" {
+			fmt.Printf("  %s", scenario.Description)
+		}
+		if scenario.Response == "" {
+			fmt.Print("  \033[38;5;214m(no recorded response)\033[0m")
+		}
+		fmt.Println()
+	}
+	for _, path := range sortedErrorPaths(invalid) {
+		fmt.Printf("\033[38;5;196m✗ %v\033[0m\n", invalid[path])
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d 件のシナリオが不正です", len(invalid))
+	}
+	return nil
+}
+
+// scenarioDir はシナリオのディレクトリ（指定がなければ .vyb/evals）
+func (h *EvalHandler) scenarioDir(dir string) (string, error) {
+	if dir != "" {
+		return dir, nil
+	}
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	return eval.Dir(projectDir), nil
+}
+
+// loadScenarios は指定したシナリオ（空ならディレクトリの全て）を読み込む
+func loadScenarios(dir string, names []string) ([]*eval.Scenario, map[string]error, error) {
+	if len(names) == 0 {
+		scenarios, invalid := eval.List(dir)
+		return scenarios, invalid, nil
+	}
+	scenarios := make([]*eval.Scenario, 0, len(names))
+	for _, name := range names {
+		scenario, err := eval.Load(dir, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		scenarios = append(scenarios, scenario)
+	}
+	return scenarios, nil, nil
+}
+
+// printEvalResult はシナリオの結果を表示（失敗時は理由も表示）
+func printEvalResult(out *os.File, result *eval.Result, recorded bool) {
+	duration := result.Duration.Round(time.Millisecond)
+	switch {
+	case result.Error != "":
+		fmt.Fprintf(out, "\033[38;5;196m✗ %s: %s\033[0m\n", result.Name, result.Error)
+	case result.Passed:
+		note := ""
+		if recorded {
+			note = ", response recorded"
+		}
+		fmt.Fprintf(out, "\033[38;5;46m✓\033[0m %s \033[38;5;244m(%s%s)\033[0m\n", result.Name, duration, note)
+	default:
+		fmt.Fprintf(out, "\033[38;5;196m✗\033[0m %s \033[38;5;244m(%s)\033[0m\n", result.Name, duration)
+		for _, failure := range result.Failures {
+			fmt.Fprintf(out, "    %s\n", failure)
+		}
+	}
+}
+
+// sortedErrorPaths は読み込めなかったシナリオのパスを名前順に返す
+func sortedErrorPaths(m map[string]error) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CreateEvalCommands はevalコマンドを作成
+func (h *EvalHandler) CreateEvalCommands() *cobra.Command {
+	evalCmd := &cobra.Command{
+		Use:   "eval [scenario|file.yaml]...",
+		Short: "Replay recorded model responses through the response pipeline and check the results",
+		Long: `Run the regression scenarios in .vyb/evals/<name>.yaml. Each scenario gives a user input, a
+recorded or hand-written model response and the expected results:
+  tool_calls:            tools run, in order ("bash: go test ./...", "write: main.go", "read: go.mod", "job: ...");
+                         [] means no tool may run
+  files:                 path -> text the file must contain afterwards
+  response_contains:     text the reply shown to the user must (or, with response_not_contains, must not) contain
+  prompt_contains:       text the rendered prompt must contain (checks customized prompt templates)
+"files" at the top level are written to a scratch directory before the response is replayed there.
+--live sends the rendered prompt to the configured model instead of using the recorded response,
+--record does the same and saves the model's response into the scenario file.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts EvalOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Dir, _ = cmd.Flags().GetString("dir")
+			opts.Live, _ = cmd.Flags().GetBool("live")
+			opts.Record, _ = cmd.Flags().GetBool("record")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Run(cmd.Context(), args, opts)
+		},
+	}
+	evalCmd.PersistentFlags().String("dir", "", "Scenario directory (default: .vyb/evals)")
+	evalCmd.Flags().Bool("live", false, "Ask the configured model instead of replaying the recorded response")
+	evalCmd.Flags().Bool("record", false, "Ask the model and save its response into the scenario file")
+	evalCmd.Flags().Bool("json", false, "Output the results as JSON")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List evaluation scenarios",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			dir, _ := cmd.Flags().GetString("dir")
+			return h.List(dir)
+		},
+	}
+
+	evalCmd.AddCommand(listCmd)
+	return evalCmd
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"fmt"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/transcript"
+	"github.com/spf13/cobra"
+)
+
+// ExportHandler は記録済みセッションのエクスポートのハンドラー
+type ExportHandler struct {
+	log logger.Logger
+	dir string
+}
+
+// NewExportHandler はエクスポートハンドラーを作成（dirが空なら ~/.vyb/logs）
+func NewExportHandler(log logger.Logger, dir string) *ExportHandler {
+	if dir == "" {
+		dir = logger.DefaultAuditDir()
+	}
+	return &ExportHandler{log: log, dir: dir}
+}
+
+// Export は監査ログから会話を再構成し、ファイル（省略時は標準出力）に書き出す
+func (h *ExportHandler) Export(sessionID, output, format string) error {
+	if sessionID == "latest" {
+		sessions, err := logger.ListAuditSessions(h.dir)
+		if err != nil {
+			return fmt.Errorf("監査ログ一覧取得エラー: %w", err)
+		}
+		if len(sessions) == 0 {
+			return fmt.Errorf("監査ログがありません: %s", h.dir)
+		}
+		sessionID = sessions[0].SessionID
+	}
+
+	events, err := logger.ReadAuditLog(h.dir, sessionID)
+	if err != nil {
+		return err
+	}
+	record := transcript.FromAuditEvents(sessionID, events)
+
+	// 形式の指定がなければ出力ファイルの拡張子から判定
+	parsed := transcript.FormatMarkdown
+	if format != "" {
+		if parsed, err = transcript.ParseFormat(format); err != nil {
+			return err
+		}
+	} else if output != "" {
+		parsed = transcript.FormatFromPath(output)
+	}
+
+	if output == "" {
+		fmt.Print(transcript.Render(record, parsed))
+		return nil
+	}
+	if err := transcript.WriteFileFormat(record, output, parsed); err != nil {
+		return err
+	}
+
+	h.log.Info("Session exported", map[string]interface{}{"session": sessionID, "path": output})
+	fmt.Printf("Exported %s (%d turns) to %s\n", sessionID, record.Turns(), output)
+	return nil
+}
+
+// CreateExportCommands はエクスポートコマンドを作成
+func (h *ExportHandler) CreateExportCommands() *cobra.Command {
+	exportCmd := &cobra.Command{
+		Use:   "export <session|latest>",
+		Short: "Export a recorded session as Markdown or HTML",
+		Long:  `Rebuild a conversation from its audit trail in ~/.vyb/logs/<session>.jsonl and export it as a shareable transcript: user prompts, assistant answers with code blocks, tool calls, command output and the diffs of each turn. HTML output is self-contained and syntax highlighted. Use 'vyb audit' to list recorded sessions.`,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			format, _ := cmd.Flags().GetString("format")
+			cmd.SilenceUsage = true
+			return h.Export(args[0], output, format)
+		},
+	}
+	exportCmd.Flags().StringP("output", "o", "", "Write to a file (.html exports HTML) instead of stdout")
+	exportCmd.Flags().String("format", "", "Output format: markdown or html (default: from the file extension)")
+
+	return exportCmd
+}
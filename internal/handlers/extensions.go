@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/plugins"
+	"github.com/spf13/cobra"
+)
+
+// ExtensionsHandler はサードパーティ拡張（~/.vyb/plugins）のハンドラー
+type ExtensionsHandler struct {
+	log logger.Logger
+}
+
+// NewExtensionsHandler は拡張ハンドラーを作成
+func NewExtensionsHandler(log logger.Logger) *ExtensionsHandler {
+	return &ExtensionsHandler{log: log}
+}
+
+// List は探索ディレクトリと見つかった拡張（ツール・購読イベント・無効化・エラー）を表示
+func (h *ExtensionsHandler) List(profile string) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config.Extensions
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	dirs := plugins.ExtensionDirs(projectDir, cfg)
+	fmt.Printf("\033[38;5;244mSearch paths: %s\033[0m\n", strings.Join(dirs, ", "))
+	if !cfg.Enabled {
+		fmt.Println("Extensions are disabled (extensions.enabled = false)")
+		return nil
+	}
+
+	manifests, invalid := plugins.DiscoverExtensions(dirs...)
+	if len(manifests) == 0 && len(invalid) == 0 {
+		fmt.Println("No extensions found")
+		return nil
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range cfg.Disabled {
+		disabled[name] = true
+	}
+	for _, manifest := range manifests {
+		fmt.Printf("%-20s", manifest.Name)
+		if manifest.Version != "" {
+			fmt.Printf(" %s", manifest.Version)
+		}
+		if disabled[manifest.Name] {
+			fmt.Print(" \033[38;5;244m(disabled)\033[0m")
+		}
+		if manifest.Description != "" {
+			fmt.Printf("  %s", manifest.Description)
+		}
+		fmt.Println()
+
+		var details []string
+		if len(manifest.Tools) > 0 {
+			names := make([]string, 0, len(manifest.Tools))
+			for _, tool := range manifest.Tools {
+				names = append(names, tool.Name)
+			}
+			details = append(details, "tools: "+strings.Join(names, ", "))
+		}
+		if len(manifest.Events) > 0 {
+			details = append(details, "events: "+strings.Join(manifest.Events, ", "))
+		}
+		if manifest.GoPlugin != "" {
+			details = append(details, "go plugin: "+manifest.GoPlugin)
+		}
+		if len(details) > 0 {
+			fmt.Printf("\033[38;5;244m%-20s %s\033[0m\n", "", strings.Join(details, "; "))
+		}
+	}
+
+	paths := make([]string, 0, len(invalid))
+	for path := range invalid {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("\033[38;5;196m✗ %v\033[0m\n", invalid[path])
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d 件の拡張マニフェストが不正です", len(invalid))
+	}
+	return nil
+}
+
+// CreateExtensionsCommands はextensionsコマンドを作成
+func (h *ExtensionsHandler) CreateExtensionsCommands() *cobra.Command {
+	extensionsCmd := &cobra.Command{
+		Use:   "extensions",
+		Short: "Inspect third-party extensions (tools and event sinks)",
+		Long: `Extensions live in ~/.vyb/plugins/<name>/plugin.yaml (and .vyb/plugins when extensions.project is true).
+An extension runs an external command or loads a Go plugin (.so exporting VybExtension):
+  "<command> tool <name>"  runs a tool; parameters arrive as JSON on stdin, stdout is the result
+  "<command> events"       receives subscribed events as JSON Lines on stdin
+Events: ` + strings.Join(events.Types(), ", "),
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List discovered extensions",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			cmd.SilenceUsage = true
+			return h.List(profile)
+		},
+	}
+
+	extensionsCmd.AddCommand(listCmd)
+	return extensionsCmd
+}
@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/docgen"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/testgen"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// 生成ファイル1件あたりの最大サイズ
+const genMaxFileSize = 10 * 1024 * 1024
+
+// GenHandler はテスト等のコード生成ワークフローのハンドラー
+type GenHandler struct {
+	log logger.Logger
+}
+
+// NewGenHandler は生成ハンドラーを作成
+func NewGenHandler(log logger.Logger) *GenHandler {
+	return &GenHandler{log: log}
+}
+
+// GenTestsOptions は gen tests の指定内容
+type GenTestsOptions struct {
+	Profile       string
+	MaxIterations int // 0以下なら fix_loop.max_iterations
+	MaxFiles      int // パッケージ指定時に生成する最大ファイル数
+	KeepFailing   bool
+	JSON          bool
+}
+
+// GenerateTests は対象のテストを生成・実行し、失敗時は修正を繰り返してカバレッジの増分を表示
+func (h *GenHandler) GenerateTests(ctx context.Context, target string, opts GenTestsOptions) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	plans, err := testgen.PlanTarget(projectDir, target, opts.MaxFiles)
+	if err != nil {
+		return err
+	}
+
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return fmt.Errorf("実行環境の初期化エラー: %w", err)
+	}
+	runner := tasks.NewRunnerWithSystem(projectDir, backend, &tasks.BuildSystem{Name: plans[0].Framework})
+	runner.SetTimeout(time.Duration(cfg.FixLoop.TimeoutSeconds) * time.Second)
+
+	maxIterations := opts.MaxIterations
+	if maxIterations <= 0 {
+		maxIterations = cfg.FixLoop.MaxIterations
+	}
+	memory, _ := config.LoadProjectMemory("")
+	generator := &testgen.Generator{
+		Provider:      newLLMProvider(cfg),
+		Model:         cfg.ResolvedModel(),
+		Language:      cfg.Language,
+		Memory:        memory,
+		Registry:      prompts.DefaultRegistry(),
+		Writer:        tools.NewWriteTool(security.NewDefaultConstraints(projectDir), projectDir, genMaxFileSize),
+		Runner:        runner,
+		ProjectDir:    projectDir,
+		MaxIterations: maxIterations,
+		KeepFailing:   opts.KeepFailing,
+		Progress: func(message string) {
+			if !opts.JSON {
+				fmt.Fprintf(os.Stderr, "\033[38;5;244m  %s\033[0m\n", message)
+			}
+		},
+	}
+
+	var results []*testgen.Result
+	failed := 0
+	for _, plan := range plans {
+		if !opts.JSON {
+			fmt.Printf("🧪 %s → %s (%s)\n", plan.Source, plan.TestPath, plan.Framework)
+		}
+		result, err := generator.Generate(ctx, plan)
+		if err != nil {
+			return fmt.Errorf("%s のテスト生成エラー: %w", plan.Source, err)
+		}
+		results = append(results, result)
+		if !result.Passed {
+			failed++
+		}
+		if !opts.JSON {
+			printGenTestsResult(result)
+		}
+		h.log.Info("Test generation completed", map[string]interface{}{
+			"source":     result.Source,
+			"test_path":  result.TestPath,
+			"passed":     result.Passed,
+			"iterations": result.Iterations,
+		})
+	}
+
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			return err
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d 件のテストが修正後も通りませんでした", failed)
+	}
+	return nil
+}
+
+// printGenTestsResult は1ファイル分の生成結果とカバレッジの増分を表示
+func printGenTestsResult(result *testgen.Result) {
+	status := "\033[38;5;46m✓ passed\033[0m"
+	if !result.Passed {
+		status = "\033[38;5;196m✗ failing\033[0m"
+	}
+	fixes := ""
+	if result.Iterations > 0 {
+		fixes = fmt.Sprintf(" after %d fix(es)", result.Iterations)
+	}
+	fmt.Printf("  %s%s: %s\n", status, fixes, result.TestPath)
+
+	if !result.Passed {
+		if result.Final != nil {
+			fmt.Println(indentLines(tasks.FormatFailures(result.Final), "    "))
+		}
+		if !result.Kept {
+			fmt.Println("    Removed the failing test file (use --keep-failing to keep it)")
+		}
+	}
+
+	if delta, ok := result.CoverageDelta(); ok {
+		fmt.Printf("  Coverage: %.1f%% → %.1f%% (%+.1f)\n", result.CoverageBefore, result.CoverageAfter, delta)
+	} else {
+		fmt.Println("  Coverage: n/a (coverage tool not available)")
+	}
+}
+
+// GenDocsOptions は gen docs の指定内容
+type GenDocsOptions struct {
+	Profile string
+	Check   bool // 生成せず、ドキュメントのないシンボルを報告する
+	Update  bool // 既存のコメントも書き直す
+	Readme  bool // パッケージの README.md も生成する
+	Yes     bool // 確認せずに適用する
+	DryRun  bool // 差分の表示のみ
+	JSON    bool
+}
+
+// GenerateDocs はパッケージの公開シンボルのドキュメントコメント（と README）を生成し、差分を確認して適用する
+func (h *GenHandler) GenerateDocs(ctx context.Context, patterns []string, opts GenDocsOptions) error {
+	var packages []*docgen.Package
+	for _, pattern := range patterns {
+		dirs, err := docgen.ExpandDirs(pattern)
+		if err != nil {
+			return err
+		}
+		for _, dir := range dirs {
+			pkg, err := docgen.Inspect(dir)
+			if err != nil {
+				return err
+			}
+			packages = append(packages, pkg)
+		}
+	}
+	if len(packages) == 0 {
+		return fmt.Errorf("Goのパッケージが見つかりません: %s", strings.Join(patterns, " "))
+	}
+
+	if opts.Check {
+		return h.checkDocs(packages, opts.JSON)
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	memory, _ := config.LoadProjectMemory("")
+	generator := &docgen.Generator{
+		Provider: newLLMProvider(cfg),
+		Model:    cfg.ResolvedModel(),
+		Language: cfg.Language,
+		Memory:   memory,
+		Registry: prompts.DefaultRegistry(),
+	}
+
+	var changes []*docgen.FileChange
+	for _, pkg := range packages {
+		pkgChanges, err := generator.DocumentPackage(ctx, pkg, opts.Update, func(file string) {
+			fmt.Fprintf(os.Stderr, "\033[38;5;244m  Documenting %s\033[0m\n", filepath.Join(pkg.Dir, file))
+		})
+		changes = append(changes, pkgChanges...)
+		if err != nil {
+			return err
+		}
+		if opts.Readme {
+			fmt.Fprintf(os.Stderr, "\033[38;5;244m  Writing %s\033[0m\n", filepath.Join(pkg.Dir, "README.md"))
+			readme, err := generator.Readme(ctx, pkg)
+			if err != nil {
+				return err
+			}
+			if readme != nil {
+				changes = append(changes, readme)
+			}
+		}
+	}
+
+	if opts.JSON {
+		return h.encodeDocChanges(changes, opts)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No documentation changes")
+		return nil
+	}
+
+	input := bufio.NewReader(os.Stdin)
+	applied := 0
+	for _, change := range changes {
+		fmt.Printf("\n📝 %s (%s)\n", change.Path, strings.Join(change.Documented, ", "))
+		fmt.Print(colorizeDiff(change.Diff()))
+		if opts.DryRun {
+			continue
+		}
+		if !opts.Yes {
+			fmt.Print("Apply this change? [y/N] ")
+			answer, _ := input.ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+		if err := change.Apply(); err != nil {
+			return err
+		}
+		applied++
+	}
+
+	if opts.DryRun {
+		fmt.Printf("\n%d file(s) would change (dry run)\n", len(changes))
+	} else {
+		fmt.Printf("\nApplied %d of %d change(s)\n", applied, len(changes))
+	}
+	h.log.Info("Documentation generation completed", map[string]interface{}{
+		"packages": len(packages),
+		"changes":  len(changes),
+		"applied":  applied,
+	})
+	return nil
+}
+
+// checkDocs はドキュメントのない公開シンボルを報告し、あればエラーにする（CI向け）
+func (h *GenHandler) checkDocs(packages []*docgen.Package, asJSON bool) error {
+	total, missingCount := 0, 0
+	report := make(map[string][]docgen.Symbol)
+	for _, pkg := range packages {
+		missing := pkg.Missing()
+		total += len(pkg.Symbols)
+		missingCount += len(missing)
+		if len(missing) > 0 {
+			report[pkg.Dir] = missing
+		}
+		if asJSON {
+			continue
+		}
+		for _, symbol := range missing {
+			fmt.Printf("%s:%d\t%-7s %s\n", filepath.Join(pkg.Dir, symbol.File), symbol.Line, symbol.Kind, symbol.Name)
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(map[string]interface{}{
+			"symbols": total,
+			"missing": report,
+		}); err != nil {
+			return err
+		}
+	} else {
+		fmt.Printf("%d of %d exported symbols lack doc comments\n", missingCount, total)
+	}
+	if missingCount > 0 {
+		return fmt.Errorf("ドキュメントのない公開シンボルが %d 件あります", missingCount)
+	}
+	return nil
+}
+
+// encodeDocChanges は変更をJSONで出力（--yes なら適用する）
+func (h *GenHandler) encodeDocChanges(changes []*docgen.FileChange, opts GenDocsOptions) error {
+	type changeJSON struct {
+		*docgen.FileChange
+		Diff    string `json:"diff"`
+		Applied bool   `json:"applied"`
+	}
+	output := make([]changeJSON, 0, len(changes))
+	for _, change := range changes {
+		entry := changeJSON{FileChange: change, Diff: change.Diff()}
+		if opts.Yes && !opts.DryRun {
+			if err := change.Apply(); err != nil {
+				return err
+			}
+			entry.Applied = true
+		}
+		output = append(output, entry)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// colorizeDiff はunified diffの追加・削除・ハンク行を色分け
+func colorizeDiff(patch string) string {
+	lines := strings.Split(strings.TrimRight(patch, "\n"), "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+			lines[i] = "\033[1m" + line + "\033[0m"
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = "\033[38;5;27m" + line + "\033[0m"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = "\033[38;5;46m" + line + "\033[0m"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = "\033[38;5;196m" + line + "\033[0m"
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// CreateGenCommands はgenコマンドを作成
+func (h *GenHandler) CreateGenCommands() *cobra.Command {
+	genCmd := &cobra.Command{
+		Use:   "gen",
+		Short: "Generate tests and documentation",
+	}
+
+	testsCmd := &cobra.Command{
+		Use:   "tests <file|package>",
+		Short: "Generate tests, run them and fix failures",
+		Long: `Generate tests for a source file, or for the files of a package directory that have no tests yet.
+The test framework is detected from the file and project (Go table-driven tests, pytest, jest or vitest),
+the tests are written next to the code (or to tests/ for Python), run, and failures are sent back to the
+model until they pass or --max-iterations is reached. Existing test files are never overwritten.
+The coverage before and after generation is shown at the end.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts GenTestsOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.MaxIterations, _ = cmd.Flags().GetInt("max-iterations")
+			opts.MaxFiles, _ = cmd.Flags().GetInt("max-files")
+			opts.KeepFailing, _ = cmd.Flags().GetBool("keep-failing")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.GenerateTests(cmd.Context(), args[0], opts)
+		},
+	}
+	testsCmd.Flags().Int("max-iterations", 0, "Maximum fix attempts per file (default: fix_loop.max_iterations)")
+	testsCmd.Flags().Int("max-files", 5, "Maximum number of files to generate tests for in a package")
+	testsCmd.Flags().Bool("keep-failing", false, "Keep test files that still fail after the last fix attempt")
+	testsCmd.Flags().Bool("json", false, "Output results as JSON")
+
+	docsCmd := &cobra.Command{
+		Use:   "docs <package>...",
+		Short: "Write or update doc comments and package READMEs",
+		Long: `Extract the exported symbols of Go packages (dir, or dir/... for subpackages) and have the model
+write doc comments for the undocumented ones (--update rewrites existing comments too, --readme also
+writes the package README.md). Each change is shown as a diff and applied after confirmation.
+--check only reports missing doc comments and exits non-zero when there are any.`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts GenDocsOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Check, _ = cmd.Flags().GetBool("check")
+			opts.Update, _ = cmd.Flags().GetBool("update")
+			opts.Readme, _ = cmd.Flags().GetBool("readme")
+			opts.Yes, _ = cmd.Flags().GetBool("yes")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.GenerateDocs(cmd.Context(), args, opts)
+		},
+	}
+	docsCmd.Flags().Bool("check", false, "Only report exported symbols without doc comments")
+	docsCmd.Flags().Bool("update", false, "Rewrite existing doc comments as well")
+	docsCmd.Flags().Bool("readme", false, "Also write or update the package README.md")
+	docsCmd.Flags().BoolP("yes", "y", false, "Apply changes without confirmation")
+	docsCmd.Flags().Bool("dry-run", false, "Show the diffs without applying them")
+	docsCmd.Flags().Bool("json", false, "Output as JSON")
+
+	genCmd.AddCommand(testsCmd, docsCmd)
+	return genCmd
+}
@@ -3,13 +3,16 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
 	"github.com/spf13/cobra"
 )
 
-// GitHandler はGit操作のハンドラー（簡略版）
+// GitHandler はGit操作のハンドラー（競合の解決以外はLLMを使わずに動作する）
 type GitHandler struct {
 	log logger.Logger
 }
@@ -19,70 +22,145 @@ func NewGitHandler(log logger.Logger) *GitHandler {
 	return &GitHandler{log: log}
 }
 
-// GetStatus はGitステータスを取得
+// gitOperations は作業ディレクトリのGit操作器を作成
+func (h *GitHandler) gitOperations() (*tools.GitOperations, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	constraints := &security.Constraints{
+		AllowedCommands: []string{"git"},
+		MaxTimeout:      cfg.CommandTimeout,
+	}
+	return tools.NewGitOperations(constraints, cfg.WorkspacePath), nil
+}
+
+// runGit はGit操作を実行して出力を表示（失敗時は標準エラーの内容をエラーにする）
+func (h *GitHandler) runGit(operation func(git *tools.GitOperations) (*tools.ExecutionResult, error)) error {
+	git, err := h.gitOperations()
+	if err != nil {
+		return err
+	}
+	result, err := operation(git)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("%s が失敗しました: %s", result.Command, strings.TrimSpace(result.Stderr))
+	}
+	if output := strings.TrimRight(result.Stdout, "\n"); output != "" {
+		fmt.Println(output)
+	}
+	return nil
+}
+
+// GetStatus は現在のブランチと変更されたファイルを表示
 func (h *GitHandler) GetStatus() error {
-	h.log.Info("Gitステータス機能（開発中）", nil)
-	return fmt.Errorf("Gitステータス機能は現在開発中です")
+	git, err := h.gitOperations()
+	if err != nil {
+		return err
+	}
+	if branch, err := git.GetCurrentBranch(); err == nil {
+		fmt.Printf("📍 %s\n", branch)
+	}
+	status, err := git.GetStatus()
+	if err != nil {
+		return err
+	}
+	if status.ExitCode != 0 {
+		return fmt.Errorf("gitステータス取得エラー: %s", strings.TrimSpace(status.Stderr))
+	}
+	if strings.TrimSpace(status.Stdout) == "" {
+		fmt.Println("Working tree clean")
+		return nil
+	}
+	fmt.Print(status.Stdout)
+	return nil
 }
 
-// CreateBranch は新しいブランチを作成
+// CreateBranch は新しいブランチを作成して切り替える
 func (h *GitHandler) CreateBranch(branchName string) error {
-	h.log.Info("ブランチ作成機能（開発中）", map[string]interface{}{
-		"branch": branchName,
+	h.log.Info("ブランチ作成", map[string]interface{}{"branch": branchName})
+	return h.runGit(func(git *tools.GitOperations) (*tools.ExecutionResult, error) {
+		return git.CreateAndCheckoutBranch(branchName)
 	})
-	return fmt.Errorf("ブランチ作成機能は現在開発中です")
 }
 
 // ListBranches はブランチ一覧を表示
 func (h *GitHandler) ListBranches() error {
-	h.log.Info("ブランチ一覧機能（開発中）", nil)
-	return fmt.Errorf("ブランチ一覧機能は現在開発中です")
+	return h.runGit(func(git *tools.GitOperations) (*tools.ExecutionResult, error) {
+		return git.GetBranches()
+	})
 }
 
 // SwitchBranch はブランチを切り替え
 func (h *GitHandler) SwitchBranch(branchName string) error {
-	h.log.Info("ブランチ切り替え機能（開発中）", map[string]interface{}{
-		"branch": branchName,
+	h.log.Info("ブランチ切り替え", map[string]interface{}{"branch": branchName})
+	return h.runGit(func(git *tools.GitOperations) (*tools.ExecutionResult, error) {
+		return git.CheckoutBranch(branchName)
 	})
-	return fmt.Errorf("ブランチ切り替え機能は現在開発中です")
 }
 
-// Commit はコミットを作成
+// Commit はコミットを作成（addAll なら全ての変更をステージしてから）
 func (h *GitHandler) Commit(message string, addAll bool) error {
-	h.log.Info("コミット作成機能（開発中）", map[string]interface{}{
+	h.log.Info("コミット作成", map[string]interface{}{
 		"message": message,
 		"add_all": addAll,
 	})
-	return fmt.Errorf("コミット作成機能は現在開発中です")
+	if addAll {
+		if err := h.runGit(func(git *tools.GitOperations) (*tools.ExecutionResult, error) {
+			return git.AddFiles(nil)
+		}); err != nil {
+			return err
+		}
+	}
+	return h.runGit(func(git *tools.GitOperations) (*tools.ExecutionResult, error) {
+		return git.Commit(message)
+	})
 }
 
-// ShowDiff は差分を表示
+// ShowDiff は差分を表示（staged ならステージ済みの変更）
 func (h *GitHandler) ShowDiff(staged bool) error {
-	h.log.Info("差分表示機能（開発中）", map[string]interface{}{
-		"staged": staged,
-	})
-	return fmt.Errorf("差分表示機能は現在開発中です")
+	git, err := h.gitOperations()
+	if err != nil {
+		return err
+	}
+	options := ""
+	if staged {
+		options = "--cached"
+	}
+	result, err := git.GetDiff(options)
+	if err != nil {
+		return err
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("差分取得エラー: %s", strings.TrimSpace(result.Stderr))
+	}
+	fmt.Print(colorizeDiff(result.Stdout))
+	return nil
 }
 
 // ShowLog はコミットログを表示
 func (h *GitHandler) ShowLog(count int) error {
-	h.log.Info("コミットログ機能（開発中）", map[string]interface{}{
-		"count": count,
+	if count <= 0 {
+		count = 10
+	}
+	return h.runGit(func(git *tools.GitOperations) (*tools.ExecutionResult, error) {
+		return git.GetLog(fmt.Sprintf("-n %d", count))
 	})
-	return fmt.Errorf("コミットログ機能は現在開発中です")
 }
 
 // CreateGitCommands はGit関連のcobraコマンドを作成
 func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	gitCmd := &cobra.Command{
 		Use:   "git",
-		Short: "Git operations (development version)",
+		Short: "Git operations that work without a model, plus model-assisted conflict resolution",
 	}
 
 	// status サブコマンド
 	statusCmd := &cobra.Command{
 		Use:   "status",
-		Short: "Show git status (dev)",
+		Short: "Show the current branch and changed files",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return h.GetStatus()
 		},
@@ -91,7 +169,7 @@ func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	// branch サブコマンド
 	branchCmd := &cobra.Command{
 		Use:   "branch [name]",
-		Short: "Create or list branches (dev)",
+		Short: "List branches, or create and switch to a new one",
 		Args:  cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			if len(args) == 0 {
@@ -104,7 +182,7 @@ func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	// switch サブコマンド
 	switchCmd := &cobra.Command{
 		Use:   "switch [branch]",
-		Short: "Switch to branch (dev)",
+		Short: "Switch to a branch",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			return h.SwitchBranch(args[0])
@@ -114,7 +192,7 @@ func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	// commit サブコマンド
 	commitCmd := &cobra.Command{
 		Use:   "commit [message]",
-		Short: "Create commit (dev)",
+		Short: "Create a commit",
 		Args:  cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			addAll, _ := cmd.Flags().GetBool("add")
@@ -126,7 +204,7 @@ func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	// diff サブコマンド
 	diffCmd := &cobra.Command{
 		Use:   "diff",
-		Short: "Show differences (dev)",
+		Short: "Show unstaged (or --staged) changes",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			staged, _ := cmd.Flags().GetBool("staged")
 			return h.ShowDiff(staged)
@@ -137,7 +215,7 @@ func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	// log サブコマンド
 	logCmd := &cobra.Command{
 		Use:   "log",
-		Short: "Show commit history (dev)",
+		Short: "Show commit history",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			count, _ := cmd.Flags().GetInt("count")
 			return h.ShowLog(count)
@@ -145,8 +223,31 @@ func (h *GitHandler) CreateGitCommands() *cobra.Command {
 	}
 	logCmd.Flags().IntP("count", "n", 10, "Number of commits to show")
 
+	// resolve サブコマンド
+	resolveCmd := &cobra.Command{
+		Use:   "resolve [files...]",
+		Short: "Resolve merge/rebase conflicts hunk by hunk with proposals from the model",
+		Long: `Walk through the conflicts of an in-progress merge, rebase, cherry-pick or revert.
+Each conflicted hunk is shown with both sides (and the common ancestor with merge.conflictStyle=diff3),
+the surrounding lines and the recent commits on each side; the model proposes a resolution that you can
+apply, replace with ours or theirs, or skip. Files with every conflict resolved are staged, and the
+resolutions (which ones were auto-resolved by the model) are added to the commit message (MERGE_MSG).
+Note that during a rebase "ours" is the upstream and "theirs" is your commit being replayed.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts ResolveOptions
+			opts.Files = args
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Yes, _ = cmd.Flags().GetBool("yes")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			cmd.SilenceUsage = true
+			return h.ResolveConflicts(cmd.Context(), opts)
+		},
+	}
+	resolveCmd.Flags().BoolP("yes", "y", false, "Apply the model's proposals without confirmation")
+	resolveCmd.Flags().Bool("dry-run", false, "Show the proposals without changing any file")
+
 	// サブコマンドを追加
-	gitCmd.AddCommand(statusCmd, branchCmd, switchCmd, commitCmd, diffCmd, logCmd)
+	gitCmd.AddCommand(statusCmd, branchCmd, switchCmd, commitCmd, diffCmd, logCmd, resolveCmd)
 
 	return gitCmd
 }
@@ -164,20 +265,20 @@ func (h *GitHandler) GetMetadata() HandlerMetadata {
 	return HandlerMetadata{
 		Name:        "git",
 		Version:     "1.0.0",
-		Description: "Git操作ハンドラー（開発版）",
+		Description: "Git操作ハンドラー",
 		Capabilities: []string{
 			"git_status",
 			"branch_management",
 			"commit_creation",
 			"diff_viewing",
 			"log_viewing",
+			"conflict_resolution",
 		},
 		Dependencies: []string{
 			"git",
 		},
 		Config: map[string]string{
-			"status": "development",
-			"mode":   "simplified",
+			"mode": "direct",
 		},
 	}
 }
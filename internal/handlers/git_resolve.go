@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/conflicts"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// ResolveOptions は vyb git resolve の指定内容
+type ResolveOptions struct {
+	Profile string
+	Files   []string // 対象のファイル（空なら競合中の全てのファイル）
+	Yes     bool     // 確認せずにモデルの提案を適用
+	DryRun  bool     // 提案を表示するだけで適用しない
+}
+
+// 競合箇所ごとの選択
+const (
+	resolveAccept = "accept"
+	resolveOurs   = "ours"
+	resolveTheirs = "theirs"
+	resolveSkip   = "skip"
+	resolveQuit   = "quit"
+)
+
+// ResolveConflicts はマージ・リベース等の競合箇所ごとにモデルの解決案を示し、確認の上で適用する
+// 全ての競合を解決したファイルはステージし、解決の記録をコミットメッセージ（MERGE_MSG）に加える
+func (h *GitHandler) ResolveConflicts(ctx context.Context, opts ResolveOptions) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	state, err := conflicts.Detect(ctx, workDir)
+	if err != nil {
+		return err
+	}
+	files, err := resolveTargets(state, workDir, opts.Files)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		fmt.Println("No merge conflicts")
+		return nil
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	memory, _ := config.LoadProjectMemory("")
+	resolver := &conflicts.Resolver{
+		Provider: newLLMProvider(cfg),
+		Model:    cfg.ResolvedModel(),
+		Language: cfg.Language,
+		Memory:   memory,
+		Registry: prompts.DefaultRegistry(),
+	}
+	journal, err := conflicts.LoadJournal(state)
+	if err != nil {
+		return err
+	}
+
+	if state.Operation != "" {
+		fmt.Printf("🔀 %s in progress: %d conflicted file(s)\n", state.Operation, len(files))
+	}
+	ours, theirs := state.Sides()
+	fmt.Printf("\033[38;5;244m   ours = %s, theirs = %s\033[0m\n", ours, theirs)
+
+	input := bufio.NewReader(os.Stdin)
+	staged, quit := 0, false
+	for _, path := range files {
+		if quit {
+			break
+		}
+		var done bool
+		done, quit, err = h.resolveFile(ctx, state, resolver, journal, input, path, opts)
+		if err != nil {
+			return err
+		}
+		if done {
+			staged++
+		}
+	}
+
+	if opts.DryRun {
+		return nil
+	}
+	if err := journal.Save(state); err != nil {
+		return err
+	}
+	if summary := journal.Summary(); summary != "" {
+		if updated, err := state.UpdateMergeMessage(summary); err != nil {
+			h.log.Warn("コミットメッセージへの解決の記録に失敗", map[string]interface{}{"error": err.Error()})
+		} else if updated {
+			fmt.Println("\n📝 Added the resolutions to the commit message (MERGE_MSG)")
+		} else {
+			fmt.Printf("\n📝 Add to the commit message:\n%s\n", summary)
+		}
+	}
+
+	remaining, err := conflicts.Detect(ctx, workDir)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\nStaged %d file(s); %d conflict(s) auto-resolved so far\n", staged, journal.AutoResolved())
+	if len(remaining.Files) == 0 {
+		fmt.Printf("All conflicts resolved. Review the result, then run \"%s\"\n", state.ContinueCommand())
+	} else {
+		fmt.Printf("%d file(s) still conflicted: %s\n", len(remaining.Files), strings.Join(remaining.Files, ", "))
+	}
+	h.log.Info("競合の解決", map[string]interface{}{
+		"operation":     state.Operation,
+		"files":         len(files),
+		"staged":        staged,
+		"auto_resolved": journal.AutoResolved(),
+	})
+	return nil
+}
+
+// resolveTargets は対象のファイルをリポジトリのルートからの相対パスで返す（競合していないファイルはエラー）
+func resolveTargets(state *conflicts.State, workDir string, args []string) ([]string, error) {
+	if len(args) == 0 {
+		return state.Files, nil
+	}
+	conflicted := make(map[string]bool, len(state.Files))
+	for _, path := range state.Files {
+		conflicted[path] = true
+	}
+	var files []string
+	for _, arg := range args {
+		path := arg
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(workDir, path)
+		}
+		rel, err := filepath.Rel(state.Root, path)
+		if err != nil {
+			return nil, err
+		}
+		rel = filepath.ToSlash(rel)
+		if !conflicted[rel] {
+			return nil, fmt.Errorf("競合していないファイルです: %s", arg)
+		}
+		files = append(files, rel)
+	}
+	return files, nil
+}
+
+// resolveFile はファイルの競合箇所を1つずつ解決し、全て解決したらステージする
+func (h *GitHandler) resolveFile(ctx context.Context, state *conflicts.State, resolver *conflicts.Resolver, journal *conflicts.Journal, input *bufio.Reader, path string, opts ResolveOptions) (done, quit bool, err error) {
+	fullPath := filepath.Join(state.Root, path)
+	info, err := os.Stat(fullPath)
+	if err != nil {
+		fmt.Printf("\n⚠️  %s: %v (resolve it manually)\n", path, err)
+		return false, false, nil
+	}
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return false, false, err
+	}
+	file, err := conflicts.Parse(path, string(data))
+	if err != nil {
+		fmt.Printf("\n⚠️  %v\n", err)
+		return false, false, nil
+	}
+	if len(file.Hunks) == 0 {
+		fmt.Printf("\n⚠️  %s has no conflict markers (binary, deleted or renamed on one side); resolve it manually\n", path)
+		return false, false, nil
+	}
+
+	resolutions := make(map[int]string)
+	for _, hunk := range file.Hunks {
+		fmt.Printf("\n⚔️  %s:%d (%d/%d)\n", path, hunk.StartLine, hunk.Index+1, len(file.Hunks))
+		printHunk(hunk)
+
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m  Asking %s for a resolution...\033[0m\n", resolver.Model)
+		proposal, proposeErr := resolver.Propose(ctx, state, file, hunk)
+		if proposeErr != nil {
+			fmt.Printf("⚠️  No proposal: %v\n", proposeErr)
+		} else {
+			fmt.Printf("💡 Proposed (%s):\n", proposal.Strategy)
+			printSide(proposal.Resolution, "\033[32m")
+			if proposal.Reason != "" {
+				fmt.Printf("   %s\n", proposal.Reason)
+			}
+		}
+		if opts.DryRun {
+			continue
+		}
+
+		choice := resolveSkip
+		if opts.Yes {
+			if proposal != nil {
+				choice = resolveAccept
+			}
+		} else {
+			choice = askResolution(input, proposal != nil)
+		}
+
+		resolution := conflicts.Resolution{File: path, Line: hunk.StartLine}
+		switch choice {
+		case resolveAccept:
+			resolutions[hunk.Index] = proposal.Resolution
+			resolution.Strategy, resolution.Auto, resolution.Reason = proposal.Strategy, true, proposal.Reason
+		case resolveOurs:
+			resolutions[hunk.Index] = hunk.Ours
+			resolution.Strategy = conflicts.StrategyOurs
+		case resolveTheirs:
+			resolutions[hunk.Index] = hunk.Theirs
+			resolution.Strategy = conflicts.StrategyTheirs
+		case resolveQuit:
+			quit = true
+		}
+		if quit {
+			break
+		}
+		if choice != resolveSkip {
+			journal.Add(resolution)
+		}
+	}
+
+	if len(resolutions) == 0 {
+		return false, quit, nil
+	}
+	if err := os.WriteFile(fullPath, []byte(file.Resolve(resolutions)), info.Mode().Perm()); err != nil {
+		return false, quit, fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+	if len(resolutions) < len(file.Hunks) {
+		fmt.Printf("✏️  %s: %d of %d conflict(s) resolved\n", path, len(resolutions), len(file.Hunks))
+		return false, quit, nil
+	}
+	if err := state.Stage(ctx, path); err != nil {
+		return false, quit, err
+	}
+	fmt.Printf("✅ %s resolved and staged\n", path)
+	return true, quit, nil
+}
+
+// printHunk は競合箇所の両側（共通祖先があればそれも）を前後の行とともに表示
+func printHunk(hunk *conflicts.Hunk) {
+	printSide(lastLines(hunk.Before, 3), "\033[38;5;244m")
+	fmt.Printf("\033[31m<<<<<<< ours %s\033[0m\n", hunk.OursLabel)
+	printSide(hunk.Ours, "\033[31m")
+	if hunk.HasBase {
+		fmt.Printf("\033[38;5;244m||||||| base %s\033[0m\n", hunk.BaseLabel)
+		printSide(hunk.Base, "\033[38;5;244m")
+	}
+	fmt.Println("\033[36m=======\033[0m")
+	printSide(hunk.Theirs, "\033[36m")
+	fmt.Printf("\033[36m>>>>>>> theirs %s\033[0m\n", hunk.TheirsLabel)
+	printSide(firstLines(hunk.After, 3), "\033[38;5;244m")
+}
+
+// printSide は行を指定の色で字下げして表示（空なら何もしない）
+func printSide(text, color string) {
+	if text == "" {
+		return
+	}
+	for _, line := range strings.Split(text, "\n") {
+		fmt.Printf("%s   %s\033[0m\n", color, line)
+	}
+}
+
+// firstLines は先頭の n 行を返す
+func firstLines(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > n {
+		lines = lines[:n]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// lastLines は末尾の n 行を返す
+func lastLines(text string, n int) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// askResolution は競合箇所の扱いを尋ねる（提案がなければ ours・theirs・スキップのみ）
+func askResolution(input *bufio.Reader, hasProposal bool) string {
+	question := "Apply? [y]es / [o]urs / [t]heirs / [s]kip / [q]uit: "
+	if !hasProposal {
+		question = "Keep [o]urs / [t]heirs / [s]kip / [q]uit: "
+	}
+	for {
+		fmt.Print(question)
+		answer, err := input.ReadString('\n')
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "y", "yes":
+			if hasProposal {
+				return resolveAccept
+			}
+		case "o", "ours":
+			return resolveOurs
+		case "t", "theirs":
+			return resolveTheirs
+		case "s", "skip", "n", "no":
+			return resolveSkip
+		case "q", "quit":
+			return resolveQuit
+		}
+		if err != nil {
+			return resolveQuit
+		}
+	}
+}
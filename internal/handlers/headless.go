@@ -0,0 +1,321 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/server"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/version"
+)
+
+// ヘッドレス実行の出力形式
+const (
+	HeadlessOutputText       = "text"        // 最終回答のみをテキスト出力
+	HeadlessOutputJSON       = "json"        // 実行完了後に結果全体を単一JSONで出力
+	HeadlessOutputStreamJSON = "stream-json" // イベント毎に1行のJSONを逐次出力
+)
+
+// ヘッドレス実行のイベント種別
+const (
+	HeadlessEventStart      = "session_start"
+	HeadlessEventToolUse    = "tool_use"
+	HeadlessEventToolResult = "tool_result"
+	HeadlessEventResult     = "result"
+	HeadlessEventError      = "error"
+)
+
+// HeadlessEvent はヘッドレス実行中に発生するイベント
+type HeadlessEvent struct {
+	Type       string                      `json:"type"`
+	SessionID  string                      `json:"session_id,omitempty"`
+	StepID     string                      `json:"step_id,omitempty"`
+	Tool       string                      `json:"tool,omitempty"`
+	Parameters map[string]interface{}      `json:"parameters,omitempty"`
+	Success    *bool                       `json:"success,omitempty"`
+	Output     string                      `json:"output,omitempty"`
+	Message    string                      `json:"message,omitempty"`
+	DurationMs int64                       `json:"duration_ms,omitempty"`
+	Analysis   *interactive.AnalysisReport `json:"analysis,omitempty"` // result イベントのみ
+	Timestamp  time.Time                   `json:"timestamp"`
+}
+
+// HeadlessResult は json 出力形式での最終結果
+type HeadlessResult struct {
+	SessionID  string                      `json:"session_id"`
+	Result     string                      `json:"result"`
+	IsError    bool                        `json:"is_error"`
+	Error      string                      `json:"error,omitempty"`
+	ToolCalls  []HeadlessEvent             `json:"tool_calls"`
+	Analysis   *interactive.AnalysisReport `json:"analysis,omitempty"` // ターン中に行ったプロジェクト分析
+	DurationMs int64                       `json:"duration_ms"`
+}
+
+// ValidHeadlessOutputs は有効な出力形式一覧を返す
+func ValidHeadlessOutputs() []string {
+	return []string{HeadlessOutputText, HeadlessOutputJSON, HeadlessOutputStreamJSON}
+}
+
+// executionObservable はツール実行イベントを通知できるセッション管理
+type executionObservable interface {
+	SetExecutionObserver(observer tools.ExecutionObserver)
+}
+
+// headlessEmitter はイベントを出力形式に応じて書き出す
+type headlessEmitter struct {
+	mu        sync.Mutex
+	out       io.Writer
+	format    string
+	sessionID string
+	toolCalls []HeadlessEvent
+}
+
+// emit はイベントを記録し、stream-json形式なら即座に出力
+func (e *headlessEmitter) emit(event HeadlessEvent) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	event.Timestamp = time.Now()
+	if event.SessionID == "" {
+		event.SessionID = e.sessionID
+	}
+
+	if event.Type == HeadlessEventToolResult {
+		e.toolCalls = append(e.toolCalls, event)
+	}
+
+	if e.format == HeadlessOutputStreamJSON {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return
+		}
+		fmt.Fprintln(e.out, string(data))
+	}
+}
+
+// OnStepStart はツール実行開始を通知
+func (e *headlessEmitter) OnStepStart(step tools.ExecutionStep) {
+	e.emit(HeadlessEvent{
+		Type:       HeadlessEventToolUse,
+		StepID:     step.StepID,
+		Tool:       step.Tool,
+		Parameters: step.Parameters,
+	})
+}
+
+// OnStepComplete はツール実行完了を通知
+func (e *headlessEmitter) OnStepComplete(step tools.ExecutionStep) {
+	success := step.Success
+	event := HeadlessEvent{
+		Type:       HeadlessEventToolResult,
+		StepID:     step.StepID,
+		Tool:       step.Tool,
+		Parameters: step.Parameters,
+		Success:    &success,
+		DurationMs: step.EndTime.Sub(step.StartTime).Milliseconds(),
+	}
+	if step.Result != nil {
+		event.Output = step.Result.Content
+		if step.Result.Error != "" {
+			event.Message = step.Result.Error
+		}
+	}
+	e.emit(event)
+}
+
+// RunHeadless はTUIを使わずに1ターンのエージェント処理を実行し、結果を機械可読形式で出力
+func (h *ChatHandler) RunHeadless(query string, resumeID string, format string, cfg *config.Config) error {
+	switch format {
+	case HeadlessOutputText, HeadlessOutputJSON, HeadlessOutputStreamJSON:
+	default:
+		return fmt.Errorf("無効な出力形式: %s (有効な値: %v)", format, ValidHeadlessOutputs())
+	}
+
+	// 内部の進捗表示が標準出力を汚さないよう、処理中はstdoutをstderrへ退避
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	emitter := &headlessEmitter{out: out, format: format}
+	startTime := time.Now()
+
+	response, sessionID, err := h.runHeadlessTurn(query, resumeID, cfg, emitter)
+	duration := time.Since(startTime)
+	message := ""
+	var report *interactive.AnalysisReport
+	if response != nil {
+		message = response.Message
+		report = response.Analysis
+	}
+
+	switch format {
+	case HeadlessOutputStreamJSON:
+		if err != nil {
+			emitter.emit(HeadlessEvent{Type: HeadlessEventError, Message: err.Error(), DurationMs: duration.Milliseconds()})
+		} else {
+			emitter.emit(HeadlessEvent{Type: HeadlessEventResult, Message: message, Analysis: report, DurationMs: duration.Milliseconds()})
+		}
+
+	case HeadlessOutputJSON:
+		result := HeadlessResult{
+			SessionID:  sessionID,
+			Result:     message,
+			IsError:    err != nil,
+			ToolCalls:  emitter.toolCalls,
+			Analysis:   report,
+			DurationMs: duration.Milliseconds(),
+		}
+		if result.ToolCalls == nil {
+			result.ToolCalls = []HeadlessEvent{}
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		data, marshalErr := json.MarshalIndent(result, "", "  ")
+		if marshalErr != nil {
+			return fmt.Errorf("結果のJSON変換エラー: %w", marshalErr)
+		}
+		fmt.Fprintln(out, string(data))
+
+	default:
+		if err == nil {
+			fmt.Fprintln(out, message)
+		}
+	}
+
+	return err
+}
+
+// runHeadlessTurn はセッションを準備してユーザー入力を1回処理
+func (h *ChatHandler) runHeadlessTurn(query string, resumeID string, cfg *config.Config, emitter *headlessEmitter) (*interactive.InteractionResponse, string, error) {
+	if err := h.initializeInteractiveManager(cfg); err != nil {
+		return nil, "", err
+	}
+
+	sessionID := resumeID
+	if sessionID == "" {
+		session, err := h.interactiveManager.CreateSession(interactive.CodingSessionTypeGeneral)
+		if err != nil {
+			return nil, "", fmt.Errorf("セッション作成エラー: %w", err)
+		}
+		sessionID = session.ID
+	}
+	emitter.sessionID = sessionID
+	emitter.emit(HeadlessEvent{Type: HeadlessEventStart, Message: query})
+
+	// 終了時にジョブ・拡張・保存先を片付ける
+	defer h.stopJobs()
+
+	// ツール実行イベントを購読
+	if observable, ok := h.interactiveManager.(executionObservable); ok {
+		observable.SetExecutionObserver(emitter)
+		defer observable.SetExecutionObserver(nil)
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+	response, err := h.interactiveManager.ProcessUserInput(h.turnContext(ctx, cfg.ResolvedModel(), query), sessionID, query)
+	if err != nil {
+		return nil, sessionID, fmt.Errorf("クエリ処理エラー: %w", err)
+	}
+
+	return response, sessionID, nil
+}
+
+// ServeStdio はエディタ連携用のJSON-RPCサーバーを標準入出力で起動
+func (h *ChatHandler) ServeStdio(cfg *config.Config) error {
+	// プロトコル以外の出力が標準出力に混ざらないようstderrへ退避
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	if err := h.initializeInteractiveManager(cfg); err != nil {
+		return err
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	srv := server.NewServer(h.interactiveManager, version.GetVersion())
+	return srv.Serve(ctx, os.Stdin, out)
+}
+
+// ServeHTTPAPI はチームで共有するための REST/WebSocket API を address で起動
+// トークンは serve.tokens（利用者名 → トークン）と VYB_SERVE_TOKEN から読み、どちらもなければ起動時に生成して表示する
+func (h *ChatHandler) ServeHTTPAPI(cfg *config.Config, address, certFile, keyFile string) error {
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("--tls-cert と --tls-key は両方指定してください")
+	}
+	tokens := make(map[string]string, len(cfg.Serve.Tokens)+1)
+	for name, token := range cfg.Serve.Tokens {
+		tokens[name] = token
+	}
+	if token := os.Getenv("VYB_SERVE_TOKEN"); token != "" {
+		tokens["default"] = token
+	}
+	if len(tokens) == 0 {
+		token, err := generateServeToken()
+		if err != nil {
+			return err
+		}
+		tokens["default"] = token
+		fmt.Fprintf(os.Stderr, "🔑 No serve.tokens configured; generated a token for this run:\n   %s\n", token)
+	}
+
+	if err := h.initializeInteractiveManager(cfg); err != nil {
+		return err
+	}
+	defer h.stopJobs()
+
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("HTTP API の待ち受けエラー (%s): %w", address, err)
+	}
+	scheme := "http"
+	if certFile != "" {
+		scheme = "https"
+	}
+	names := make([]string, 0, len(tokens))
+	for name := range tokens {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fmt.Fprintf(os.Stderr, "🌐 vyb HTTP API listening on %s://%s/v1 (users: %s, Ctrl+C to stop)\n", scheme, listener.Addr(), strings.Join(names, ", "))
+	if scheme == "http" && !isLoopback(listener.Addr()) {
+		fmt.Fprintf(os.Stderr, "⚠️  Tokens are sent in clear text; use --tls-cert/--tls-key or a TLS proxy on shared networks\n")
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+	srv := server.NewHTTPServer(h.interactiveManager, version.GetVersion(), tokens, h.log)
+	return srv.Serve(ctx, listener, certFile, keyFile)
+}
+
+// generateServeToken は推測できないAPIトークンを生成
+func generateServeToken() (string, error) {
+	buf := make([]byte, 24)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("トークン生成エラー: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// isLoopback は待ち受けアドレスがループバックのみか
+func isLoopback(addr net.Addr) bool {
+	tcp, ok := addr.(*net.TCPAddr)
+	return ok && tcp.IP.IsLoopback()
+}
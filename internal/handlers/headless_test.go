@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// stream-json形式ではイベント毎に1行のJSONが出力されることを確認
+func TestHeadlessEmitter_StreamJSON(t *testing.T) {
+	var buf bytes.Buffer
+	emitter := &headlessEmitter{out: &buf, format: HeadlessOutputStreamJSON, sessionID: "s1"}
+
+	start := time.Now()
+	step := tools.ExecutionStep{
+		StepID:     "step_1",
+		Tool:       "read",
+		Parameters: map[string]interface{}{"file_path": "main.go"},
+		StartTime:  start,
+		EndTime:    start.Add(5 * time.Millisecond),
+		Success:    true,
+		Result:     &tools.ToolResponse{Success: true, Content: "package main"},
+	}
+	emitter.OnStepStart(step)
+	emitter.OnStepComplete(step)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("Expected 2 events, got %d: %s", len(lines), buf.String())
+	}
+
+	var result HeadlessEvent
+	if err := json.Unmarshal([]byte(lines[1]), &result); err != nil {
+		t.Fatalf("Invalid JSON event: %v", err)
+	}
+	if result.Type != HeadlessEventToolResult || result.Tool != "read" || result.SessionID != "s1" {
+		t.Errorf("Unexpected event: %+v", result)
+	}
+	if result.Success == nil || !*result.Success || result.Output != "package main" {
+		t.Errorf("Unexpected tool result: %+v", result)
+	}
+	if len(emitter.toolCalls) != 1 {
+		t.Errorf("Expected 1 recorded tool call, got %d", len(emitter.toolCalls))
+	}
+}
+
+// json形式では逐次出力しないことを確認
+func TestHeadlessEmitter_JSONBuffersEvents(t *testing.T) {
+	var buf bytes.Buffer
+	emitter := &headlessEmitter{out: &buf, format: HeadlessOutputJSON}
+	emitter.emit(HeadlessEvent{Type: HeadlessEventStart})
+
+	if buf.Len() != 0 {
+		t.Errorf("Expected no streaming output in json mode, got %q", buf.String())
+	}
+}
+
+// 無効な出力形式はエラーになることを確認
+func TestRunHeadless_InvalidFormat(t *testing.T) {
+	handler := &ChatHandler{}
+	if err := handler.RunHeadless("hello", "", "yaml", nil); err == nil {
+		t.Error("Expected error for invalid output format")
+	}
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/history"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// HistoryHandler は過去のセッション検索のハンドラー
+type HistoryHandler struct {
+	log logger.Logger
+	dir string
+}
+
+// NewHistoryHandler は履歴ハンドラーを作成（dirが空なら ~/.vyb/logs）
+func NewHistoryHandler(log logger.Logger, dir string) *HistoryHandler {
+	if dir == "" {
+		dir = logger.DefaultAuditDir()
+	}
+	return &HistoryHandler{log: log, dir: dir}
+}
+
+// Search は過去の会話とツール出力を全文検索してマッチしたターンを表示
+func (h *HistoryHandler) Search(query string, opts history.SearchOptions, asJSON bool) error {
+	index, err := history.Load(h.dir)
+	if err != nil {
+		return err
+	}
+	hits := index.Search(query, opts)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(hits)
+	}
+
+	if len(hits) == 0 {
+		fmt.Printf("No matches for %q in %d recorded entries (%s)\n", query, index.Len(), h.dir)
+		return nil
+	}
+	fmt.Print(formatHistoryHits(hits))
+	fmt.Println()
+	fmt.Println("Show a turn:   vyb history show <session> <turn>")
+	fmt.Println("Resume:        vyb --resume <session>")
+	fmt.Println("Quote in chat: /quote <session> <turn>")
+	return nil
+}
+
+// Show は過去のセッションの1ターン（省略時は全ターン）を表示
+func (h *HistoryHandler) Show(sessionID string, turn int) error {
+	exchanges, err := history.LoadExchanges(h.dir, sessionID)
+	if err != nil {
+		return err
+	}
+	if turn > 0 {
+		if turn > len(exchanges) {
+			return fmt.Errorf("ターン %d はありません（1〜%d）: %s", turn, len(exchanges), sessionID)
+		}
+		exchanges = exchanges[turn-1 : turn]
+	}
+
+	for _, exchange := range exchanges {
+		fmt.Printf("── %s turn %d · %s ──\n", exchange.SessionID, exchange.Turn, exchange.Timestamp.Format("2006-01-02 15:04:05"))
+		fmt.Printf("👤 %s\n\n", strings.TrimSpace(exchange.User))
+		if exchange.Assistant != "" {
+			fmt.Printf("🤖 %s\n\n", strings.TrimSpace(exchange.Assistant))
+		}
+	}
+	return nil
+}
+
+// formatHistoryHits は検索結果をターン毎に1ブロックで整形
+func formatHistoryHits(hits []history.Hit) string {
+	var sb strings.Builder
+	for _, hit := range hits {
+		sb.WriteString(fmt.Sprintf("%s  turn %-3d %s  [%s", hit.SessionID, hit.Turn, hit.Timestamp.Format("2006-01-02 15:04"), hit.Kind))
+		if hit.Label != "" {
+			sb.WriteString(" " + truncateRunes(hit.Label, 40))
+		}
+		sb.WriteString("]\n")
+		sb.WriteString("    " + hit.Snippet + "\n")
+	}
+	return sb.String()
+}
+
+// CreateHistoryCommands は履歴関連のコマンドを作成
+func (h *HistoryHandler) CreateHistoryCommands() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: "Search and show past sessions",
+		Long:  `Search past conversations and tool output recorded in the audit trail (~/.vyb/logs). Matching turns can be shown, resumed with 'vyb --resume <session>', or quoted into a running session with /quote.`,
+	}
+
+	searchCmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Full-text search over past conversations and tool output",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			limit, _ := cmd.Flags().GetInt("limit")
+			sessionID, _ := cmd.Flags().GetString("session")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.Search(strings.Join(args, " "), history.SearchOptions{Limit: limit, SessionID: sessionID}, asJSON)
+		},
+	}
+	searchCmd.Flags().Int("limit", 20, "Maximum number of matching turns")
+	searchCmd.Flags().String("session", "", "Only search this session")
+	searchCmd.Flags().Bool("json", false, "Print matches as JSON")
+
+	showCmd := &cobra.Command{
+		Use:   "show <session> [turn]",
+		Short: "Show a past session or a single turn",
+		Args:  cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			turn := 0
+			if len(args) == 2 {
+				parsed, err := strconv.Atoi(args[1])
+				if err != nil || parsed < 1 {
+					return fmt.Errorf("無効なターン番号です: %s", args[1])
+				}
+				turn = parsed
+			}
+			return h.Show(args[0], turn)
+		},
+	}
+
+	historyCmd.AddCommand(searchCmd, showCmd)
+	return historyCmd
+}
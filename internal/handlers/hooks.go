@@ -0,0 +1,294 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/hooks"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/spf13/cobra"
+)
+
+// commitMessageTimeout はコミットメッセージ提案を待つ時間（超えたらコミットを止めずに諦める）
+const commitMessageTimeout = 60 * time.Second
+
+// HooksHandler はgitフックの導入とフックから呼ばれるチェックのハンドラー
+type HooksHandler struct {
+	log logger.Logger
+}
+
+// NewHooksHandler はgitフックハンドラーを作成
+func NewHooksHandler(log logger.Logger) *HooksHandler {
+	return &HooksHandler{log: log}
+}
+
+// Install は現在のリポジトリに pre-commit・commit-msg フックを導入する
+func (h *HooksHandler) Install(ctx context.Context, force bool) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	vybPath, err := os.Executable()
+	if err != nil {
+		vybPath = "vyb"
+	} else if resolved, err := filepath.EvalSymlinks(vybPath); err == nil {
+		vybPath = resolved
+	}
+
+	written, err := hooks.Install(ctx, workDir, vybPath, force)
+	if err != nil {
+		return err
+	}
+	for _, path := range written {
+		fmt.Printf("Installed %s\n", path)
+	}
+	cfg, err := config.Load()
+	if err == nil {
+		fmt.Printf("Checks: %s\n", formatHookChecks(cfg.Hooks.Checks))
+	}
+	fmt.Printf("Skip once with %s=1 git commit ... or git commit --no-verify\n", hooks.SkipEnv)
+
+	h.log.Info("gitフックを導入しました", map[string]interface{}{"hooks": written, "vyb": vybPath})
+	return nil
+}
+
+// Uninstall は vyb が導入したフックを削除する
+func (h *HooksHandler) Uninstall(ctx context.Context) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	removed, err := hooks.Uninstall(ctx, workDir)
+	if err != nil {
+		return err
+	}
+	if len(removed) == 0 {
+		fmt.Println("No vyb hooks installed")
+		return nil
+	}
+	for _, path := range removed {
+		fmt.Printf("Removed %s\n", path)
+	}
+	h.log.Info("gitフックを削除しました", map[string]interface{}{"hooks": removed})
+	return nil
+}
+
+// Status はフックの導入状況と有効なチェックを表示する
+func (h *HooksHandler) Status(ctx context.Context) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	statuses, err := hooks.Inspect(ctx, workDir)
+	if err != nil {
+		return err
+	}
+	for _, status := range statuses {
+		state := "not installed"
+		switch {
+		case status.Managed:
+			state = "installed"
+		case status.Exists:
+			state = "other hook (not managed by vyb)"
+		}
+		fmt.Printf("%-11s %s (%s)\n", status.Name, state, status.Path)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	fmt.Printf("Checks: %s\n", formatHookChecks(cfg.Hooks.Checks))
+	fmt.Printf("Block on lint failure: %t\n", cfg.Hooks.BlockOnLint)
+	return nil
+}
+
+// RunPreCommit はステージ済みの変更のシークレット検出とリントを行う（pre-commit フックから呼ばれる）
+func (h *HooksHandler) RunPreCommit(ctx context.Context) error {
+	if os.Getenv(hooks.SkipEnv) != "" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	if hookCheckEnabled(cfg, "secrets") {
+		findings, err := hooks.ScanStaged(ctx, workDir)
+		if err != nil {
+			return err
+		}
+		if len(findings) > 0 {
+			fmt.Fprintln(os.Stderr, "\033[31mvyb: possible secrets in staged changes:\033[0m")
+			for _, finding := range findings {
+				fmt.Fprintf(os.Stderr, "  %s:%d  %s\n", finding.File, finding.Line, finding.Kind)
+			}
+			fmt.Fprintf(os.Stderr, "Remove them, or mark false positives with a %q comment on the line.\n", hooks.AllowSecretComment)
+			h.log.Warn("ステージ済みの変更にシークレットを検出", map[string]interface{}{"findings": len(findings)})
+			return fmt.Errorf("シークレットの可能性がある値が %d 件あるためコミットを中止しました（%s=1 で一度だけ飛ばせます）", len(findings), hooks.SkipEnv)
+		}
+	}
+
+	if hookCheckEnabled(cfg, "lint") {
+		runner, err := tasks.NewRunner(workDir, nil)
+		if err != nil || runner.System().Command(tasks.KindLint) == "" {
+			// リントの方法がわからないプロジェクトでは何もしない
+			return nil
+		}
+		result, err := runner.RunLint(ctx)
+		if err != nil {
+			return err
+		}
+		fmt.Fprint(os.Stderr, "vyb: "+tasks.FormatFailures(result))
+		if !result.Success && cfg.Hooks.BlockOnLint {
+			return fmt.Errorf("リントが失敗したためコミットを中止しました（%s=1 で一度だけ飛ばせます）", hooks.SkipEnv)
+		}
+	}
+	return nil
+}
+
+// RunCommitMsg は変更内容を表していないコミットメッセージに対して、ステージ済みの差分から提案を表示する
+// （commit-msg フックから呼ばれる。モデルに接続できない場合もコミットは止めない）
+func (h *HooksHandler) RunCommitMsg(ctx context.Context, messageFile string) error {
+	if os.Getenv(hooks.SkipEnv) != "" {
+		return nil
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	if !hookCheckEnabled(cfg, "commit-message") {
+		return nil
+	}
+	raw, err := os.ReadFile(messageFile)
+	if err != nil {
+		return fmt.Errorf("コミットメッセージの読み込みエラー: %w", err)
+	}
+	message := hooks.CleanMessage(string(raw))
+	if !hooks.IsWeakMessage(message) {
+		return nil
+	}
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	diff, err := hooks.StagedDiff(ctx, workDir)
+	if err != nil || strings.TrimSpace(diff) == "" {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, commitMessageTimeout)
+	defer cancel()
+	memory, _ := config.LoadProjectMemory("")
+	suggester := &hooks.Suggester{
+		Provider: newLLMProvider(cfg),
+		Model:    cfg.ResolvedModel(),
+		Language: cfg.Language,
+		Memory:   memory,
+		Registry: prompts.DefaultRegistry(),
+	}
+	suggestion, err := suggester.Suggest(ctx, diff, message)
+	if err != nil {
+		h.log.Warn("コミットメッセージの提案に失敗", map[string]interface{}{"error": err.Error()})
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "\033[38;5;214mvyb: %q does not describe the change. Suggested message:\033[0m\n\n", message)
+	fmt.Fprintln(os.Stderr, suggestion)
+	fmt.Fprintln(os.Stderr, "\nUse it with: git commit --amend")
+	return nil
+}
+
+// hookCheckEnabled は設定でチェックが有効か
+func hookCheckEnabled(cfg *config.Config, check string) bool {
+	for _, enabled := range cfg.Hooks.Checks {
+		if enabled == check {
+			return true
+		}
+	}
+	return false
+}
+
+// formatHookChecks は有効なチェックの表示
+func formatHookChecks(checks []string) string {
+	if len(checks) == 0 {
+		return "none"
+	}
+	return strings.Join(checks, ", ")
+}
+
+// CreateHooksCommands はgitフックコマンドを作成
+func (h *HooksHandler) CreateHooksCommands() *cobra.Command {
+	hooksCmd := &cobra.Command{
+		Use:   "hooks",
+		Short: "Run vyb checks from git pre-commit and commit-msg hooks",
+	}
+
+	installCmd := &cobra.Command{
+		Use:   "install",
+		Short: "Install pre-commit (lint summary, secret scan) and commit-msg (message suggestion) hooks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			force, _ := cmd.Flags().GetBool("force")
+			return h.Install(cmd.Context(), force)
+		},
+	}
+	installCmd.Flags().Bool("force", false, "Replace existing hooks (they are kept as .vyb-backup and restored on uninstall)")
+
+	uninstallCmd := &cobra.Command{
+		Use:   "uninstall",
+		Short: "Remove the hooks installed by vyb",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.Uninstall(cmd.Context())
+		},
+	}
+
+	statusCmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show installed hooks and enabled checks",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.Status(cmd.Context())
+		},
+	}
+
+	runCmd := &cobra.Command{
+		Use:       "run [pre-commit|commit-msg] [message-file]",
+		Short:     "Run the checks of a hook (called by the installed hooks)",
+		Args:      cobra.RangeArgs(1, 2),
+		ValidArgs: hooks.Names(),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			switch args[0] {
+			case hooks.HookPreCommit:
+				return h.RunPreCommit(cmd.Context())
+			case hooks.HookCommitMsg:
+				if len(args) < 2 {
+					return fmt.Errorf("commit-msg にはコミットメッセージのファイルを指定してください")
+				}
+				return h.RunCommitMsg(cmd.Context(), args[1])
+			default:
+				return fmt.Errorf("不明なフックです: %s（%s のいずれかを指定してください）", args[0], strings.Join(hooks.Names(), ", "))
+			}
+		},
+	}
+
+	hooksCmd.AddCommand(installCmd, uninstallCmd, statusCmd, runCmd)
+	return hooksCmd
+}
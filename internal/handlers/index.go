@@ -0,0 +1,127 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/embeddings"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// IndexHandler はプロジェクトの埋め込みインデックスのハンドラー
+type IndexHandler struct {
+	log logger.Logger
+}
+
+// NewIndexHandler は埋め込みインデックスハンドラーを作成
+func NewIndexHandler(log logger.Logger) *IndexHandler {
+	return &IndexHandler{log: log}
+}
+
+// loadIndex は設定の埋め込みモデル・保存先でプロジェクトのインデックスを読み込む
+func (h *IndexHandler) loadIndex(root string) (*embeddings.Index, *llm.OllamaClient, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, nil, fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	idx, err := embeddings.Load(embeddings.ResolveCacheDir(cfg.Embeddings.CacheDir), root, cfg.ResolvedEmbeddingModel(""))
+	if err != nil {
+		return nil, nil, err
+	}
+	return idx, llm.NewEmbeddingClient(cfg), nil
+}
+
+// Build はプロジェクトのインデックスを作成・更新する（変更のないファイルはキャッシュを使う）
+func (h *IndexHandler) Build(ctx context.Context, root string) error {
+	idx, embedder, err := h.loadIndex(root)
+	if err != nil {
+		return err
+	}
+	files, err := embeddings.CollectFiles(idx.Root)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Indexing %d file(s) in %s with %s\n", len(files), idx.Root, idx.Model)
+	start := time.Now()
+	stats, updateErr := idx.Update(ctx, embedder, files)
+	// 途中で失敗しても生成済みの埋め込みは次回に使えるよう保存する
+	if err := idx.Save(); err != nil {
+		return err
+	}
+	if updateErr != nil {
+		return updateErr
+	}
+
+	fmt.Printf("Embedded: %d file(s), %d chunk(s)\n", stats.Embedded, stats.Chunks)
+	fmt.Printf("Reused:   %d file(s)\n", stats.Reused)
+	fmt.Printf("Removed:  %d file(s)\n", stats.Removed)
+	fmt.Printf("Total:    %d chunk(s) in %s (%s)\n", idx.ChunkCount(), idx.Path(), time.Since(start).Round(time.Millisecond))
+
+	h.log.Info("埋め込みインデックスを更新しました", map[string]interface{}{
+		"root":     idx.Root,
+		"model":    idx.Model,
+		"embedded": stats.Embedded,
+		"reused":   stats.Reused,
+		"removed":  stats.Removed,
+	})
+	return nil
+}
+
+// Search はインデックスから query に近い箇所を表示する
+func (h *IndexHandler) Search(ctx context.Context, root, query string, limit int) error {
+	idx, embedder, err := h.loadIndex(root)
+	if err != nil {
+		return err
+	}
+	if len(idx.Files) == 0 {
+		return fmt.Errorf("インデックスがありません（先に vyb index を実行してください）")
+	}
+	results, err := idx.Search(ctx, embedder, query, limit)
+	if err != nil {
+		return err
+	}
+	for _, result := range results {
+		fmt.Printf("%.3f  %s:%d-%d\n", result.Score, result.Path, result.StartLine, result.EndLine)
+	}
+	return nil
+}
+
+// CreateIndexCommands は埋め込みインデックスコマンドを作成
+func (h *IndexHandler) CreateIndexCommands() *cobra.Command {
+	indexCmd := &cobra.Command{
+		Use:   "index [path]",
+		Short: "Build or refresh the embedding index of a project (only changed files are re-embedded)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			root := "."
+			if len(args) > 0 {
+				root = args[0]
+			}
+			return h.Build(cmd.Context(), filepath.Clean(root))
+		},
+	}
+
+	searchCmd := &cobra.Command{
+		Use:   "search [query]",
+		Short: "Search the embedding index for code related to a query",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			root, _ := cmd.Flags().GetString("path")
+			limit, _ := cmd.Flags().GetInt("limit")
+			return h.Search(cmd.Context(), root, args[0], limit)
+		},
+	}
+	searchCmd.Flags().String("path", ".", "Project path")
+	searchCmd.Flags().IntP("limit", "n", 10, "Maximum number of results")
+
+	indexCmd.AddCommand(searchCmd)
+	return indexCmd
+}
@@ -0,0 +1,247 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/setup"
+	"github.com/spf13/cobra"
+)
+
+// ベンチマークのタイムアウト（初回はモデルの読み込みを含む）
+const initBenchmarkTimeout = 3 * time.Minute
+
+// InitOptions は vyb init の動作指定
+type InitOptions struct {
+	Model         string // 推奨を使わずに指定するモデル
+	AssumeYes     bool   // 確認せずにデフォルトの回答で進める
+	SkipBenchmark bool
+	SkipMemory    bool // VYB.md を作成しない
+}
+
+// InitHandler は初回セットアップウィザードのハンドラー
+type InitHandler struct {
+	log    logger.Logger
+	input  *bufio.Reader
+	output io.Writer
+}
+
+// NewInitHandler はセットアップハンドラーを作成
+func NewInitHandler(log logger.Logger) *InitHandler {
+	return &InitHandler{log: log, input: bufio.NewReader(os.Stdin), output: os.Stdout}
+}
+
+// Run はプロバイダー検出・モデル推奨・ベンチマーク・設定書き込み・VYB.md 作成を順に行う
+func (h *InitHandler) Run(ctx context.Context, projectDir string, opts InitOptions) error {
+	// 1. マシンとプロバイダーの検出
+	hw := setup.DetectHardware(ctx)
+	h.printf("🖥  %s/%s, %d CPUs, RAM %s", hw.OS, hw.Arch, hw.CPUs, setup.FormatBytes(hw.RAMBytes))
+	if hw.GPU != "" {
+		h.printf(", GPU %s (%s VRAM)", hw.GPU, setup.FormatBytes(hw.VRAMBytes))
+	}
+	h.printf("\n\nProviders:\n")
+
+	probes := setup.DetectProviders(ctx)
+	var ollama setup.Probe
+	for _, probe := range probes {
+		h.printProbe(probe)
+		if probe.Provider == "ollama" {
+			ollama = probe
+		}
+	}
+	if !ollama.Running {
+		h.printf("\n\033[38;5;214m⚠ Ollama is not reachable at %s.\033[0m vyb talks to the Ollama API;", ollama.BaseURL)
+		if ollama.Installed {
+			h.printf(" start it with `ollama serve`.\n")
+		} else {
+			h.printf(" install it from https://ollama.com/download.\n")
+		}
+	}
+
+	// 2. モデルの推奨
+	model := opts.Model
+	if model == "" {
+		recommendation := setup.Recommend(hw, ollama)
+		h.printf("\nRecommended model: \033[1m%s\033[0m — %s\n", recommendation.Model, recommendation.Reason)
+		model = recommendation.Model
+		if !h.confirm(fmt.Sprintf("Use %s?", model), true, opts.AssumeYes) {
+			model = h.ask("Model to use", installedOrDefault(ollama, model), opts.AssumeYes)
+		}
+	}
+
+	// 3. 未取得ならpull
+	if ollama.Running && !ollama.HasModel(model) {
+		if h.confirm(fmt.Sprintf("%s is not pulled yet. Pull it now?", model), true, opts.AssumeYes) {
+			if err := pullModel(ctx, llm.NewOllamaClient(ollama.BaseURL), model, h.output); err != nil {
+				h.printf("\033[38;5;196m✗ %v\033[0m\n", err)
+			} else {
+				ollama.Models = append(ollama.Models, llm.ModelDetails{Name: model})
+			}
+		} else {
+			h.printf("Pull it later with: vyb models pull %s\n", model)
+		}
+	}
+
+	// 4. ベンチマーク
+	if !opts.SkipBenchmark && ollama.Running && ollama.HasModel(model) {
+		h.printf("\nBenchmarking %s with a short prompt...\n", model)
+		benchCtx, cancel := context.WithTimeout(ctx, initBenchmarkTimeout)
+		result, err := setup.Benchmark(benchCtx, llm.NewOllamaClient(ollama.BaseURL), model)
+		cancel()
+		switch {
+		case err != nil:
+			h.printf("\033[38;5;196m✗ %v\033[0m\n", err)
+		case result.Slow():
+			h.printf("⚠ %s — this is slow for interactive use; consider a smaller model\n", result)
+		default:
+			h.printf("✓ %s\n", result)
+		}
+	}
+
+	// 5. 設定の書き込み
+	if err := h.writeConfig(ollama.BaseURL, model); err != nil {
+		return err
+	}
+
+	// 6. プロジェクトメモリ
+	if !opts.SkipMemory && h.confirm(fmt.Sprintf("Create %s with project notes in %s?", config.ProjectMemoryFile, projectDir), true, opts.AssumeYes) {
+		path, created, err := setup.WriteMemoryFile(projectDir)
+		if err != nil {
+			return err
+		}
+		if created {
+			h.printf("✓ Created %s — edit it to describe your build commands and conventions\n", path)
+		} else {
+			h.printf("• %s already exists, left unchanged\n", path)
+		}
+	}
+
+	h.printf("\nSetup complete. Run `vyb` to start, or `vyb config doctor` to review the configuration.\n")
+	return nil
+}
+
+// writeConfig はグローバル設定にプロバイダーとモデルを保存
+func (h *InitHandler) writeConfig(baseURL, model string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg.Provider = "ollama"
+	cfg.BaseURL = baseURL
+	cfg.Model = model
+	cfg.ModelName = model
+	if err := config.Save(cfg); err != nil {
+		return fmt.Errorf("設定保存エラー: %w", err)
+	}
+
+	path, _ := config.GetConfigPath()
+	h.log.Info("Initial configuration written", map[string]interface{}{"model": model, "path": path})
+	h.printf("\n✓ Saved provider=ollama model=%s to %s\n", model, path)
+	return nil
+}
+
+// printProbe はプロバイダーの検出結果を1行で表示
+func (h *InitHandler) printProbe(probe setup.Probe) {
+	switch {
+	case probe.Running:
+		names := make([]string, 0, len(probe.Models))
+		for _, model := range probe.Models {
+			names = append(names, model.Name)
+		}
+		pulled := "no models pulled"
+		if len(names) > 0 {
+			pulled = strings.Join(names, ", ")
+		}
+		h.printf("  ✓ %-9s running at %s — %s\n", probe.Provider, probe.BaseURL, pulled)
+		if probe.Provider != "ollama" {
+			h.printf("    (OpenAI-compatible servers are detected only; vyb currently uses the Ollama API)\n")
+		}
+	case probe.Installed:
+		h.printf("  • %-9s installed, not running\n", probe.Provider)
+	default:
+		h.printf("  - %-9s not found\n", probe.Provider)
+	}
+}
+
+// confirm は Y/n の確認（assumeYesなら既定値）
+func (h *InitHandler) confirm(question string, defaultYes, assumeYes bool) bool {
+	if assumeYes {
+		return defaultYes
+	}
+	hint := "[Y/n]"
+	if !defaultYes {
+		hint = "[y/N]"
+	}
+	answer := strings.ToLower(h.ask(question+" "+hint, "", false))
+	if answer == "" {
+		return defaultYes
+	}
+	return answer == "y" || answer == "yes"
+}
+
+// ask は1行の入力を受け付ける（空なら既定値）
+func (h *InitHandler) ask(question, defaultValue string, assumeYes bool) string {
+	if assumeYes {
+		return defaultValue
+	}
+	if defaultValue != "" {
+		h.printf("%s [%s]: ", question, defaultValue)
+	} else {
+		h.printf("%s ", question)
+	}
+	line, _ := h.input.ReadString('\n')
+	if answer := strings.TrimSpace(line); answer != "" {
+		return answer
+	}
+	return defaultValue
+}
+
+func (h *InitHandler) printf(format string, args ...interface{}) {
+	fmt.Fprintf(h.output, format, args...)
+}
+
+// installedOrDefault はpull済みの最初のモデル（なければ推奨モデル）
+func installedOrDefault(ollama setup.Probe, fallback string) string {
+	if len(ollama.Models) > 0 {
+		return ollama.Models[0].Name
+	}
+	return fallback
+}
+
+// CreateInitCommand は init コマンドを作成
+func (h *InitHandler) CreateInitCommand() *cobra.Command {
+	initCmd := &cobra.Command{
+		Use:   "init",
+		Short: "First-run setup: detect providers, pick a model and write the initial config",
+		Long:  `Interactive first-run setup. Detects local LLM servers (Ollama, LM Studio, vLLM) and the models already pulled, recommends a coding model that fits this machine's RAM/VRAM, optionally pulls it and benchmarks a short prompt, writes provider and model to ~/.vyb/config.json, and creates the project memory file VYB.md, which is included in every prompt.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts InitOptions
+			opts.Model, _ = cmd.Flags().GetString("model")
+			opts.AssumeYes, _ = cmd.Flags().GetBool("yes")
+			opts.SkipBenchmark, _ = cmd.Flags().GetBool("skip-benchmark")
+			opts.SkipMemory, _ = cmd.Flags().GetBool("no-memory")
+
+			projectDir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+			}
+			cmd.SilenceUsage = true
+			return h.Run(cmd.Context(), projectDir, opts)
+		},
+	}
+	initCmd.Flags().String("model", "", "Use this model instead of the recommendation")
+	initCmd.Flags().BoolP("yes", "y", false, "Accept the defaults without prompting")
+	initCmd.Flags().Bool("skip-benchmark", false, "Do not benchmark the selected model")
+	initCmd.Flags().Bool("no-memory", false, "Do not create VYB.md in the current project")
+
+	return initCmd
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/knowledge"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// KnowledgeHandler は言語・フレームワーク別の指針（.vyb/knowledge）のハンドラー
+type KnowledgeHandler struct {
+	log logger.Logger
+}
+
+// NewKnowledgeHandler は指針ハンドラーを作成
+func NewKnowledgeHandler(log logger.Logger) *KnowledgeHandler {
+	return &KnowledgeHandler{log: log}
+}
+
+// List はプロジェクトと組み込みの指針を表示し、このプロジェクトでプロンプトに加わるものに印を付ける
+func (h *KnowledgeHandler) List() error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	cfg := config.DefaultKnowledgeConfig()
+	if resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile("")}); err == nil {
+		cfg = resolved.Config.Knowledge
+	}
+
+	list, invalid := knowledge.List(knowledge.Dir(projectDir))
+	active := make(map[string]bool)
+	if cfg.Enabled {
+		for i, guide := range knowledge.Match(list, knowledge.Scan(projectDir), cfg.Disabled) {
+			if cfg.MaxGuides > 0 && i >= cfg.MaxGuides {
+				break
+			}
+			active[guide.Name] = true
+		}
+	}
+
+	for _, guide := range list {
+		mark := " "
+		if active[guide.Name] {
+			mark = "\033[38;5;46m●\033[0m"
+		}
+		fmt.Printf("%s %-18s %s\n", mark, guide.Name, guide.Description)
+		details := []string{"source: " + guide.Source()}
+		if len(guide.Detect.Files) > 0 {
+			details = append(details, "files: "+strings.Join(guide.Detect.Files, ", "))
+		}
+		if len(guide.Detect.Dependencies) > 0 {
+			details = append(details, "dependencies: "+strings.Join(guide.Detect.Dependencies, ", "))
+		}
+		fmt.Printf("\033[38;5;244m  %-18s %s\033[0m\n", "", strings.Join(details, " · "))
+	}
+	if !cfg.Enabled {
+		fmt.Println("\nFramework guidance is disabled (knowledge.enabled)")
+	} else if len(active) > 0 {
+		fmt.Println("\n● added to the prompt for this project")
+	}
+
+	paths := make([]string, 0, len(invalid))
+	for path := range invalid {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("\033[38;5;196m✗ %v\033[0m\n", invalid[path])
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d 件の指針が不正です", len(invalid))
+	}
+	return nil
+}
+
+// Show は指針の検出条件と内容を表示
+func (h *KnowledgeHandler) Show(name string) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	list, _ := knowledge.List(knowledge.Dir(projectDir))
+	for _, guide := range list {
+		if guide.Name != name {
+			continue
+		}
+		fmt.Printf("\033[38;5;27m%s\033[0m  %s\n", guide.Name, guide.Description)
+		fmt.Printf("\033[38;5;244msource: %s\033[0m\n", guide.Source())
+		if len(guide.Detect.Files) > 0 {
+			fmt.Printf("files:        %s\n", strings.Join(guide.Detect.Files, ", "))
+		}
+		if len(guide.Detect.Dependencies) > 0 {
+			fmt.Printf("dependencies: %s\n", strings.Join(guide.Detect.Dependencies, ", "))
+		}
+		fmt.Printf("\n%s\n", guide.Guidance)
+		return nil
+	}
+	return fmt.Errorf("指針 %s が見つかりません（vyb knowledge list で一覧を表示）", name)
+}
+
+// Init は組み込みの指針を .vyb/knowledge に書き出して編集できるようにする
+func (h *KnowledgeHandler) Init(names []string, force bool) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	written, skipped, err := knowledge.WriteBuiltin(knowledge.Dir(projectDir), names, force)
+	for _, path := range written {
+		fmt.Printf("✓ Wrote %s\n", path)
+	}
+	for _, path := range skipped {
+		fmt.Printf("• %s already exists, left unchanged (use --force to overwrite)\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	if len(written) > 0 {
+		fmt.Printf("\nEdit the guidance and detection rules; new sessions pick up the changes\n")
+	}
+	return nil
+}
+
+// CreateKnowledgeCommand は knowledge コマンドを作成
+func (h *KnowledgeHandler) CreateKnowledgeCommand() *cobra.Command {
+	knowledgeCmd := &cobra.Command{
+		Use:   "knowledge",
+		Short: "List, show and customize framework-specific prompt guidance",
+		Long: `vyb detects the languages and frameworks a project uses (from go.mod, package.json, requirements.txt, Cargo.toml and other manifests) and adds matching guidance - idioms, test frameworks, directory conventions - to the interactive prompt. Framework guides (for example go-cobra or react-typescript) come before plain language guides.
+Built-in guides: django, fastapi, go, go-cobra, nextjs, react-typescript, rust. Project guides live in .vyb/knowledge/<name>.yaml and take precedence over built-ins with the same name; "vyb knowledge init" writes the built-ins there for editing. A guide applies when any of its detect.files exists and the project depends on any of its detect.dependencies.
+Settings: knowledge.enabled, knowledge.max_guides, knowledge.disabled.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List guides and mark the ones detected for this project",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.List()
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a guide's detection rules and guidance",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.Show(args[0])
+		},
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init [name...]",
+		Short: "Copy built-in guides to .vyb/knowledge for editing (default: all)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			cmd.SilenceUsage = true
+			return h.Init(args, force)
+		},
+	}
+	initCmd.Flags().Bool("force", false, "Overwrite existing guide files")
+
+	knowledgeCmd.AddCommand(listCmd, showCmd, initCmd)
+	return knowledgeCmd
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/setup"
+	"github.com/spf13/cobra"
+)
+
+// LearnOptions は vyb learn の指定内容
+type LearnOptions struct {
+	Profile   string
+	DryRun    bool // VYB.md に書かずに節を表示する
+	NoSummary bool // モデルに概要を書かせず、調査結果だけを書く
+	Force     bool // 既存の節を作り直す
+	JSON      bool // 調査結果を JSON で表示する（VYB.md には書かない）
+}
+
+// LearnHandler はプロジェクトの初回調査（vyb learn）のハンドラー
+type LearnHandler struct {
+	log logger.Logger
+}
+
+// NewLearnHandler は初回調査ハンドラーを作成
+func NewLearnHandler(log logger.Logger) *LearnHandler {
+	return &LearnHandler{log: log}
+}
+
+// Learn はプロジェクトを調べ、モデルが書いた概要と合わせてオンボーディングの節を VYB.md に書く
+func (h *LearnHandler) Learn(ctx context.Context, projectDir string, opts LearnOptions) error {
+	memoryPath := filepath.Join(projectDir, config.ProjectMemoryFile)
+	existing, _ := os.ReadFile(memoryPath)
+	section, notes := setup.SplitOnboarding(string(existing))
+	if section != "" && !opts.Force && !opts.DryRun && !opts.JSON {
+		fmt.Printf("• %s already has an onboarding section; run `vyb learn --force` to regenerate it\n", memoryPath)
+		return nil
+	}
+
+	fmt.Fprintf(os.Stderr, "\033[38;5;244m🔍 Scanning %s...\033[0m\n", projectDir)
+	survey, err := setup.ScanProject(ctx, projectDir)
+	if err != nil {
+		return fmt.Errorf("プロジェクト調査エラー: %w", err)
+	}
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(survey)
+	}
+
+	overview := ""
+	if !opts.NoSummary {
+		resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+		if err != nil {
+			return fmt.Errorf("設定読み込みエラー: %w", err)
+		}
+		cfg := resolved.Config
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m✍️  Writing the overview with %s...\033[0m\n", cfg.ResolvedModel())
+		summarizer := &setup.Summarizer{
+			Provider: newLLMProvider(cfg),
+			Model:    cfg.ResolvedModel(),
+			Language: cfg.Language,
+			Registry: prompts.DefaultRegistry(),
+		}
+		overview, err = summarizer.Summarize(ctx, survey, strings.TrimSpace(notes))
+		if err != nil {
+			return fmt.Errorf("%w（--no-summary で調査結果だけを書けます）", err)
+		}
+	}
+
+	rendered := survey.Markdown(overview)
+	if opts.DryRun {
+		fmt.Print(rendered)
+		return nil
+	}
+	path, created, err := setup.WriteOnboarding(projectDir, rendered)
+	if err != nil {
+		return err
+	}
+	h.log.Info("Onboarding summary written", map[string]interface{}{
+		"path":         path,
+		"languages":    len(survey.Languages),
+		"entry_points": len(survey.EntryPoints),
+		"summary":      overview != "",
+	})
+	if created {
+		fmt.Printf("✓ Created %s with the onboarding summary\n", path)
+	} else {
+		fmt.Printf("✓ Updated the onboarding section of %s (your other notes are unchanged)\n", path)
+	}
+	fmt.Println("  It is included in every prompt from now on; edit it freely or rerun `vyb learn --force` after large changes.")
+	return nil
+}
+
+// CreateLearnCommand は learn コマンドを作成
+func (h *LearnHandler) CreateLearnCommand() *cobra.Command {
+	learnCmd := &cobra.Command{
+		Use:   "learn",
+		Short: "Scan the project once and write an onboarding summary to VYB.md",
+		Long: `Scan the current project once - directory layout, languages, entry points, build/test/lint
+commands and conventions (test layout, linters, CI, commit style) - and ask the model for an
+overview of its purpose, architecture and key flows. The result is written as a marked section of
+the project memory file VYB.md, which is included in every prompt, so later sessions start with
+this context instead of analysing the project again. Notes outside the section are kept.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts LearnOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.NoSummary, _ = cmd.Flags().GetBool("no-summary")
+			opts.Force, _ = cmd.Flags().GetBool("force")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+
+			projectDir, err := os.Getwd()
+			if err != nil {
+				return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+			}
+			cmd.SilenceUsage = true
+			return h.Learn(cmd.Context(), projectDir, opts)
+		},
+	}
+	learnCmd.Flags().Bool("dry-run", false, "Print the section instead of writing VYB.md")
+	learnCmd.Flags().Bool("no-summary", false, "Do not ask the model for an overview; write the scan results only")
+	learnCmd.Flags().Bool("force", false, "Regenerate the section when VYB.md already has one")
+	learnCmd.Flags().Bool("json", false, "Print the scan results as JSON")
+
+	return learnCmd
+}
@@ -0,0 +1,391 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// モデル一覧・詳細取得のタイムアウト
+const modelsRequestTimeout = 15 * time.Second
+
+// ModelsHandler はローカルモデルの一覧・取得・詳細表示のハンドラー
+type ModelsHandler struct {
+	log logger.Logger
+}
+
+// NewModelsHandler はモデル管理ハンドラーを作成
+func NewModelsHandler(log logger.Logger) *ModelsHandler {
+	return &ModelsHandler{log: log}
+}
+
+// ModelInfoReport は models info の出力内容
+type ModelInfoReport struct {
+	Provider      string            `json:"provider"`
+	BaseURL       string            `json:"base_url"`
+	Model         string            `json:"model"`
+	Source        string            `json:"source"` // 設定元のレイヤー（引数で指定した場合は "argument"）
+	Profile       string            `json:"profile,omitempty"`
+	Pulled        bool              `json:"pulled"`
+	Details       *llm.ModelDetails `json:"details,omitempty"`
+	ContextWindow int               `json:"context_window"` // 設定の context_window
+	Error         string            `json:"error,omitempty"`
+}
+
+// resolveConfig はプロファイル・プロジェクト設定を反映した設定を読み込む
+func (h *ModelsHandler) resolveConfig(profile string) (*config.Resolved, error) {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(profile)})
+	if err != nil {
+		return nil, fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	return resolved, nil
+}
+
+// List はプロバイダーにあるモデルをサイズ・コンテキスト長付きで表示（設定中のモデルに * を付ける）
+func (h *ModelsHandler) List(ctx context.Context, profile string, asJSON bool) error {
+	resolved, err := h.resolveConfig(profile)
+	if err != nil {
+		return err
+	}
+	cfg := resolved.Config
+
+	models, err := h.listModels(ctx, cfg)
+	if err != nil {
+		return fmt.Errorf("%s (%s) からモデル一覧を取得できません: %w", cfg.Provider, cfg.BaseURL, err)
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"provider": cfg.Provider,
+			"base_url": cfg.BaseURL,
+			"current":  cfg.ResolvedModel(),
+			"models":   models,
+		})
+	}
+
+	if len(models) == 0 {
+		fmt.Printf("No models available on %s (%s). Pull one with: vyb models pull %s\n", cfg.Provider, cfg.BaseURL, config.DefaultModel)
+		return nil
+	}
+
+	current := cfg.ResolvedModel()
+	fmt.Printf("  %-36s %10s %8s %10s %9s  %s\n", "NAME", "SIZE", "PARAMS", "QUANT", "CONTEXT", "MODIFIED")
+	found := false
+	for _, model := range models {
+		mark := " "
+		if llm.SameModel(model.Name, current) {
+			mark = "*"
+			found = true
+		}
+		fmt.Printf("%s %-36s %10s %8s %10s %9s  %s\n", mark, model.Name, formatModelSize(model.SizeBytes),
+			orDash(model.ParameterSize), orDash(model.QuantizationLevel), formatContextLength(effectiveContext(model)), formatModified(model.ModifiedAt))
+	}
+	if !found {
+		fmt.Printf("\n\033[38;5;214m⚠ The configured model %s is not available. Run: vyb models pull %s\033[0m\n", current, current)
+	}
+	return nil
+}
+
+// listModels はプロバイダーに応じてモデル一覧を取得（Ollamaはコンテキスト長も取得）
+func (h *ModelsHandler) listModels(ctx context.Context, cfg *config.Config) ([]llm.ModelDetails, error) {
+	ctx, cancel := context.WithTimeout(ctx, modelsRequestTimeout)
+	defer cancel()
+
+	if cfg.Provider != "ollama" {
+		return llm.ListOpenAICompatibleModels(ctx, &http.Client{}, cfg.BaseURL)
+	}
+
+	client := llm.NewOllamaClient(cfg.BaseURL)
+	models, err := client.ListLocalModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := range models {
+		if details, err := client.ShowModel(ctx, models[i].Name); err == nil {
+			models[i].ContextLength = details.ContextLength
+			models[i].NumCtx = details.NumCtx
+		}
+	}
+	return models, nil
+}
+
+// Pull はOllamaにモデルを取得させ、進捗バーを表示
+func (h *ModelsHandler) Pull(ctx context.Context, profile, name string) error {
+	resolved, err := h.resolveConfig(profile)
+	if err != nil {
+		return err
+	}
+	cfg := resolved.Config
+	if cfg.Provider != "ollama" {
+		return fmt.Errorf("モデルの取得はOllamaのみ対応しています（現在のプロバイダー: %s）", cfg.Provider)
+	}
+	if name == "" {
+		name = cfg.ResolvedModel()
+	}
+
+	if err := pullModel(ctx, llm.NewOllamaClient(cfg.BaseURL), name, os.Stdout); err != nil {
+		return err
+	}
+	h.log.Info("Model pulled", map[string]interface{}{"model": name})
+	if !llm.SameModel(name, cfg.ResolvedModel()) {
+		fmt.Printf("Use it with: vyb config set-model %s\n", name)
+	}
+	return nil
+}
+
+// pullModel はモデルを取得し、レイヤー毎の進捗を1行ずつ上書き表示
+func pullModel(ctx context.Context, client *llm.OllamaClient, name string, out io.Writer) error {
+	fmt.Fprintf(out, "Pulling %s from %s\n", name, client.BaseURL)
+	printer := &pullProgressPrinter{out: out}
+	err := client.Pull(ctx, name, printer.update)
+	printer.finish()
+	if err != nil {
+		return fmt.Errorf("モデル取得エラー: %w", err)
+	}
+	fmt.Fprintf(out, "\033[38;5;46m✓\033[0m %s is ready\n", name)
+	return nil
+}
+
+// pullProgressPrinter は pull の進捗をプログレスバーで表示
+type pullProgressPrinter struct {
+	out    io.Writer
+	digest string // 表示中のレイヤー
+	inLine bool   // 改行せずに上書き中
+}
+
+func (p *pullProgressPrinter) update(progress llm.PullProgress) {
+	if progress.Total <= 0 {
+		// マニフェスト取得・検証等の段階は1行で表示
+		p.finish()
+		if progress.Status != "success" {
+			fmt.Fprintf(p.out, "  %s\n", progress.Status)
+		}
+		return
+	}
+
+	if progress.Digest != p.digest {
+		p.finish()
+		p.digest = progress.Digest
+	}
+	fmt.Fprintf(p.out, "\r  %s %s", shortDigest(progress.Digest), progressBar(progress.Completed, progress.Total, 30))
+	p.inLine = true
+}
+
+func (p *pullProgressPrinter) finish() {
+	if p.inLine {
+		fmt.Fprintln(p.out)
+		p.inLine = false
+	}
+}
+
+// progressBar は "[#####.....]  50% 1.2/2.4 GB" 形式のバー
+func progressBar(completed, total int64, width int) string {
+	if completed > total {
+		completed = total
+	}
+	filled := int(float64(width) * float64(completed) / float64(total))
+	return fmt.Sprintf("[%s%s] %3d%% %s/%s", strings.Repeat("#", filled), strings.Repeat(".", width-filled),
+		completed*100/total, formatModelSize(completed), formatModelSize(total))
+}
+
+// Info はモデル（省略時は設定が解決するモデル）の詳細と設定元を表示
+func (h *ModelsHandler) Info(ctx context.Context, profile, name string, asJSON bool) error {
+	resolved, err := h.resolveConfig(profile)
+	if err != nil {
+		return err
+	}
+	cfg := resolved.Config
+
+	report := ModelInfoReport{
+		Provider:      cfg.Provider,
+		BaseURL:       cfg.BaseURL,
+		Model:         name,
+		Source:        "argument",
+		Profile:       resolved.Profile,
+		ContextWindow: cfg.ContextWindow,
+	}
+	if name == "" {
+		report.Model = cfg.ResolvedModel()
+		report.Source = config.LayerDefault
+		if source, ok := resolved.Sources["model"]; ok {
+			report.Source = source.Layer
+		}
+	}
+
+	models, err := h.listModels(ctx, cfg)
+	if err != nil {
+		report.Error = err.Error()
+	}
+	for i := range models {
+		if llm.SameModel(models[i].Name, report.Model) {
+			report.Pulled = true
+			report.Details = &models[i]
+		}
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	}
+
+	fmt.Printf("Model:     %s\n", report.Model)
+	if name == "" {
+		profileNote := ""
+		if report.Profile != "" {
+			profileNote = fmt.Sprintf(", profile %s", report.Profile)
+		}
+		fmt.Printf("Source:    %s%s\n", report.Source, profileNote)
+	}
+	fmt.Printf("Provider:  %s (%s)\n", report.Provider, report.BaseURL)
+
+	switch {
+	case report.Error != "":
+		fmt.Printf("Status:    \033[38;5;196munreachable\033[0m (%s)\n", report.Error)
+		return nil
+	case !report.Pulled:
+		fmt.Printf("Status:    \033[38;5;214mnot available\033[0m — run: vyb models pull %s\n", report.Model)
+		return nil
+	}
+
+	details := report.Details
+	fmt.Printf("Status:    \033[38;5;46mavailable\033[0m\n")
+	if details.Family != "" {
+		fmt.Printf("Family:    %s, %s parameters, %s (%s)\n", details.Family, orDash(details.ParameterSize), orDash(details.QuantizationLevel), orDash(details.Format))
+	}
+	if details.SizeBytes > 0 {
+		fmt.Printf("Size:      %s\n", formatModelSize(details.SizeBytes))
+	}
+	if details.ContextLength > 0 || details.NumCtx > 0 {
+		fmt.Printf("Context:   %s trained", formatContextLength(details.ContextLength))
+		if details.NumCtx > 0 {
+			fmt.Printf(", num_ctx %d", details.NumCtx)
+		}
+		fmt.Printf(" (config context_window: %d)\n", report.ContextWindow)
+		if limit := effectiveContext(*details); limit > 0 && report.ContextWindow > limit {
+			fmt.Printf("\033[38;5;214m⚠ context_window exceeds what the model supports; long conversations will be truncated by the server\033[0m\n")
+		}
+	}
+	return nil
+}
+
+// effectiveContext は実行時に使われるコンテキスト長（num_ctx指定があれば優先）
+func effectiveContext(model llm.ModelDetails) int {
+	if model.NumCtx > 0 {
+		return model.NumCtx
+	}
+	return model.ContextLength
+}
+
+func formatModelSize(bytes int64) string {
+	switch {
+	case bytes <= 0:
+		return "-"
+	case bytes >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(bytes)/(1<<30))
+	default:
+		return fmt.Sprintf("%.0f MB", float64(bytes)/(1<<20))
+	}
+}
+
+func formatContextLength(length int) string {
+	switch {
+	case length <= 0:
+		return "-"
+	case length%1024 == 0:
+		return fmt.Sprintf("%dK", length/1024)
+	default:
+		return fmt.Sprintf("%d", length)
+	}
+}
+
+func formatModified(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02")
+}
+
+func shortDigest(digest string) string {
+	digest = strings.TrimPrefix(digest, "sha256:")
+	if len(digest) > 12 {
+		return digest[:12]
+	}
+	return digest
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// CreateModelsCommands はモデル管理コマンドを作成
+func (h *ModelsHandler) CreateModelsCommands() *cobra.Command {
+	modelsCmd := &cobra.Command{
+		Use:   "models",
+		Short: "List, pull and inspect local models",
+		Long:  `Manage the models of the configured provider. Ollama is queried for sizes, quantization and context lengths and can pull models; LM Studio and vLLM servers are listed through their OpenAI-compatible /v1/models endpoint. The configured model honours --profile and project .vyb/config.yaml overrides.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List locally available models (* marks the configured model)",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.List(cmd.Context(), profile, asJSON)
+		},
+	}
+	listCmd.Flags().Bool("json", false, "Output as JSON")
+
+	pullCmd := &cobra.Command{
+		Use:   "pull [model]",
+		Short: "Download a model with Ollama (default: the configured model)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			cmd.SilenceUsage = true
+			return h.Pull(cmd.Context(), profile, name)
+		},
+	}
+
+	infoCmd := &cobra.Command{
+		Use:   "info [model]",
+		Short: "Show model details and where the configured model comes from",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profile, _ := cmd.Flags().GetString("profile")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			name := ""
+			if len(args) > 0 {
+				name = args[0]
+			}
+			cmd.SilenceUsage = true
+			return h.Info(cmd.Context(), profile, name, asJSON)
+		},
+	}
+	infoCmd.Flags().Bool("json", false, "Output as JSON")
+
+	modelsCmd.AddCommand(listCmd, pullCmd, infoCmd)
+	return modelsCmd
+}
@@ -0,0 +1,111 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// PermissionsHandler はプロジェクト・ユーザーの許可規則の表示のハンドラー
+type PermissionsHandler struct {
+	log logger.Logger
+	cfg config.PermissionsConfig
+}
+
+// NewPermissionsHandler は許可規則ハンドラーの新しいインスタンスを作成
+func NewPermissionsHandler(log logger.Logger, cfg config.PermissionsConfig) *PermissionsHandler {
+	return &PermissionsHandler{log: log, cfg: cfg}
+}
+
+// Show はユーザー設定と .vyb/permissions.yaml を合算した規則と設定元を表示（無効な規則があればエラー）
+func (h *PermissionsHandler) Show(asJSON bool) error {
+	resolved, err := config.LoadPermissions(h.cfg, "")
+	if err != nil {
+		return err
+	}
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(resolved); err != nil {
+			return err
+		}
+	} else {
+		projectFile := resolved.Path
+		if projectFile == "" {
+			projectFile = "(none)"
+		}
+		fmt.Printf("Project file: %s\n", projectFile)
+		fmt.Printf("Path root:    %s\n", resolved.Root)
+
+		for _, section := range []struct {
+			title string
+			kind  string
+		}{
+			{"Allowed commands", "allow_command"},
+			{"Denied commands", "deny_command"},
+			{"Denied paths", "deny_path"},
+			{"Read-only paths", "read_only_path"},
+		} {
+			fmt.Printf("\n%s:\n", section.title)
+			count := 0
+			for _, rule := range resolved.Rules {
+				if rule.Kind == section.kind {
+					fmt.Printf("  %-40s (%s)\n", rule.Pattern, rule.Source)
+					count++
+				}
+			}
+			if count == 0 {
+				fmt.Println("  (none)")
+			}
+		}
+
+		if len(resolved.Issues) > 0 {
+			fmt.Println("\nIssues:")
+			for _, issue := range resolved.Issues {
+				fmt.Printf("  [%s] %s\n", issue.Severity, issue)
+			}
+		}
+	}
+
+	if resolved.HasErrors() {
+		return fmt.Errorf("許可規則に無効な項目があります（無視されます）")
+	}
+	return nil
+}
+
+// CreatePermissionsCommands は許可規則関連のコマンドを作成
+func (h *PermissionsHandler) CreatePermissionsCommands() *cobra.Command {
+	permissionsCmd := &cobra.Command{
+		Use:   "permissions",
+		Short: "Show which commands and paths the agent may touch in this project",
+		Long: `Rules come from "permissions" in the user config and from .vyb/permissions.yaml committed to the
+repository. Both are merged and a deny always wins over an allow:
+
+  commands:
+    allow: ["make test"]     # run even if not in the built-in allowlist (built-in blocked commands stay blocked)
+    deny: ["git push"]       # also matches "git push origin main" and commands joined with && or |
+  paths:
+    read_only: ["migrations/"]  # relative to the project root; names without "/" match at any depth
+    deny: ["secrets/**"]`,
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show",
+		Short: "Show the merged rules, where each comes from and any invalid entries",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Show(asJSON)
+		},
+	}
+	showCmd.Flags().Bool("json", false, "Print as JSON")
+
+	permissionsCmd.AddCommand(showCmd)
+	return permissionsCmd
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/spf13/cobra"
+)
+
+// PromptsHandler はプロンプトテンプレート管理のハンドラー
+type PromptsHandler struct {
+	log      logger.Logger
+	registry *prompts.Registry
+}
+
+// NewPromptsHandler はプロンプトハンドラーの新しいインスタンスを作成
+func NewPromptsHandler(log logger.Logger) *PromptsHandler {
+	return &PromptsHandler{log: log, registry: prompts.DefaultRegistry()}
+}
+
+// ListTemplates はテンプレート一覧を表示
+func (h *PromptsHandler) ListTemplates() error {
+	infos, err := h.registry.List()
+	if err != nil {
+		return fmt.Errorf("テンプレート一覧取得エラー: %w", err)
+	}
+
+	fmt.Printf("Override directory: %s\n", h.registry.OverrideDir())
+	for _, info := range infos {
+		if info.Path != "" {
+			fmt.Printf("  %-28s %s (%s)\n", info.Name, info.Source, info.Path)
+		} else {
+			fmt.Printf("  %-28s %s\n", info.Name, info.Source)
+		}
+	}
+	return nil
+}
+
+// ShowTemplate はセッションタイプ・言語に応じて解決されたテンプレートを表示
+func (h *PromptsHandler) ShowTemplate(name, sessionType, language string) error {
+	source, info, err := h.registry.Resolve(name, prompts.Data{SessionType: sessionType, Language: language})
+	if err != nil {
+		return err
+	}
+
+	if info.Path != "" {
+		fmt.Printf("# %s (%s: %s)\n\n", info.Name, info.Source, info.Path)
+	} else {
+		fmt.Printf("# %s (%s)\n\n", info.Name, info.Source)
+	}
+	fmt.Print(source)
+	return nil
+}
+
+// EditTemplate は上書きテンプレートを作成してエディタで開く
+func (h *PromptsHandler) EditTemplate(name string) error {
+	path, err := h.registry.EnsureOverride(name)
+	if err != nil {
+		return err
+	}
+
+	editor := os.Getenv("VISUAL")
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = "vi"
+	}
+
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("エディタ実行エラー: %w", err)
+	}
+
+	h.log.Info("プロンプトテンプレートを更新しました", map[string]interface{}{
+		"template": name,
+		"path":     path,
+	})
+	return nil
+}
+
+// CreatePromptsCommands はプロンプト関連のコマンドを作成
+func (h *PromptsHandler) CreatePromptsCommands() *cobra.Command {
+	promptsCmd := &cobra.Command{
+		Use:   "prompts",
+		Short: "Manage prompt templates",
+		Long:  `Show and customize the prompt templates used by vyb. Templates are Go text/template files embedded in the binary and can be overridden per name in ~/.vyb/prompts (e.g. interactive.tmpl, interactive.debugging.tmpl, interactive.en.tmpl).`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List available prompt templates",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return h.ListTemplates()
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show [name]",
+		Short: "Show the resolved prompt template",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := prompts.TemplateInteractive
+			if len(args) > 0 {
+				name = args[0]
+			}
+			sessionType, _ := cmd.Flags().GetString("session-type")
+			language, _ := cmd.Flags().GetString("language")
+			return h.ShowTemplate(name, sessionType, language)
+		},
+	}
+	showCmd.Flags().String("session-type", "", "Session type (general, debugging, refactor, review, learning)")
+	showCmd.Flags().String("language", "", "Response language (ja, en)")
+
+	editCmd := &cobra.Command{
+		Use:   "edit [name]",
+		Short: "Edit a prompt template override in $EDITOR",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := prompts.TemplateInteractive
+			if len(args) > 0 {
+				name = args[0]
+			}
+			return h.EditTemplate(name)
+		},
+	}
+
+	promptsCmd.AddCommand(listCmd, showCmd, editCmd)
+	return promptsCmd
+}
@@ -0,0 +1,284 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/journal"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/refactor"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/spf13/cobra"
+)
+
+// RefactorHandler は複数ファイルのリファクタリングのハンドラー
+type RefactorHandler struct {
+	log logger.Logger
+}
+
+// NewRefactorHandler はリファクタリングハンドラーを作成
+func NewRefactorHandler(log logger.Logger) *RefactorHandler {
+	return &RefactorHandler{log: log}
+}
+
+// RefactorOptions は refactor の指定内容
+type RefactorOptions struct {
+	Profile  string
+	Files    []string // 指定がなければ指示から候補を探す
+	MaxFiles int
+	Yes      bool
+	DryRun   bool
+	Test     bool // ビルドに加えてテストでも検証する
+	JSON     bool
+}
+
+// Refactor は指示から書き換えを計画し、1つのトランザクションとして適用・検証する
+func (h *RefactorHandler) Refactor(ctx context.Context, instruction string, opts RefactorOptions) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	memory, _ := config.LoadProjectMemory("")
+	refactorer := &refactor.Refactorer{
+		Provider:   newLLMProvider(cfg),
+		Model:      cfg.ResolvedModel(),
+		Language:   cfg.Language,
+		Memory:     memory,
+		Registry:   prompts.DefaultRegistry(),
+		ProjectDir: projectDir,
+		MaxFiles:   opts.MaxFiles,
+	}
+
+	files := opts.Files
+	if len(files) == 0 {
+		if files, err = refactorer.Candidates(instruction); err != nil {
+			return err
+		}
+	}
+	if !opts.JSON {
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m  Planning edits across %d file(s)\033[0m\n", len(files))
+	}
+	plan, err := refactorer.Plan(ctx, instruction, files)
+	if err != nil {
+		return err
+	}
+
+	if !opts.JSON {
+		if plan.Summary != "" {
+			fmt.Printf("🔧 %s\n", plan.Summary)
+		}
+		for _, edit := range plan.Edits {
+			fmt.Printf("\n📝 %s\n", edit.Path)
+			fmt.Print(colorizeDiff(edit.Diff()))
+		}
+		if report := planImpact(ctx, projectDir, plan); report != nil {
+			fmt.Printf("\n%s\n", report.Format())
+		}
+	}
+	if opts.DryRun {
+		if opts.JSON {
+			return encodeRefactorResult(plan, nil)
+		}
+		fmt.Printf("\n%d file(s) would change (dry run)\n", len(plan.Edits))
+		return nil
+	}
+	if !opts.Yes {
+		if opts.JSON {
+			return fmt.Errorf("--json で適用するには --yes を指定してください")
+		}
+		fmt.Printf("\nApply %d file(s) as one transaction? [y/N] ", len(plan.Edits))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	runner, err := validationRunner(cfg, projectDir, opts.JSON)
+	if err != nil {
+		return err
+	}
+
+	outcome, err := refactorer.Apply(ctx, plan, journal.DefaultDir(), runner, opts.Test)
+	if err != nil {
+		return err
+	}
+	h.log.Info("Refactoring completed", map[string]interface{}{
+		"files":       len(plan.Edits),
+		"applied":     outcome.Applied,
+		"rolled_back": outcome.RolledBack,
+	})
+
+	if opts.JSON {
+		if err := encodeRefactorResult(plan, outcome); err != nil {
+			return err
+		}
+	} else if outcome.Applied {
+		fmt.Printf("\n\033[38;5;46m✅ Applied %d file(s)\033[0m (undo with: vyb refactor undo %s)\n", len(plan.Edits), outcome.TransactionID)
+	} else if outcome.Validation != nil {
+		fmt.Printf("\n%s", tasks.FormatFailures(outcome.Validation))
+	}
+	if outcome.RolledBack {
+		return fmt.Errorf("検証に失敗したため、すべての変更をロールバックしました")
+	}
+	return nil
+}
+
+// validationRunner は適用後の検証に使うビルド・テストの実行環境（ビルドシステムが検出できなければ nil で検証しない）
+func validationRunner(cfg *config.Config, projectDir string, quiet bool) (*tasks.Runner, error) {
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return nil, fmt.Errorf("実行環境の初期化エラー: %w", err)
+	}
+	runner, err := tasks.NewRunner(projectDir, backend)
+	if err != nil {
+		if !quiet {
+			fmt.Fprintf(os.Stderr, "\033[38;5;214m⚠️  %v; applying without build validation\033[0m\n", err)
+		}
+		return nil, nil
+	}
+	runner.SetTimeout(time.Duration(cfg.FixLoop.TimeoutSeconds) * time.Second)
+	if !quiet {
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m  Validating with %s\033[0m\n", runner.System().Command(tasks.KindBuild))
+	}
+	return runner, nil
+}
+
+// planImpact は計画した書き換えが変更するシンボルに依存する箇所を求める（求められなければ nil）
+func planImpact(ctx context.Context, projectDir string, plan *refactor.Plan) *analysis.ImpactReport {
+	changes := make(map[string][]string)
+	for _, edit := range plan.Edits {
+		// 適用前なので、変更行を囲む宣言は書き換え後の内容から探す
+		content := edit.Content
+		source := func(string) (string, bool) { return content, true }
+		for file, symbols := range analysis.ChangedSymbols(edit.Diff(), source) {
+			changes[file] = append(changes[file], symbols...)
+		}
+	}
+	report, err := analysis.AnalyzeImpact(ctx, projectDir, changes)
+	if err != nil {
+		return nil
+	}
+	return report
+}
+
+// encodeRefactorResult は計画と適用結果をJSONで出力
+func encodeRefactorResult(plan *refactor.Plan, outcome *refactor.Outcome) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"plan":    plan,
+		"outcome": outcome,
+	})
+}
+
+// Undo は確定済みのリファクタリングを取り消す
+func (h *RefactorHandler) Undo(id string, force bool) error {
+	tx, err := journal.Load(journal.DefaultDir(), id)
+	if err != nil {
+		return err
+	}
+	if err := tx.Undo(force); err != nil {
+		return err
+	}
+	h.log.Info("Refactoring undone", map[string]interface{}{"id": tx.ID, "files": len(tx.Entries)})
+	fmt.Printf("↩️  Undid %s: %s (%d file(s))\n", tx.ID, tx.Description, len(tx.Entries))
+	return nil
+}
+
+// List はジャーナルのリファクタリング履歴を表示
+func (h *RefactorHandler) List() error {
+	transactions, err := journal.List(journal.DefaultDir())
+	if err != nil {
+		return err
+	}
+	if len(transactions) == 0 {
+		fmt.Println("No refactorings recorded")
+		return nil
+	}
+	for _, tx := range transactions {
+		state := tx.State
+		if state == journal.StateUndone {
+			state = "\033[38;5;244m" + state + "\033[0m"
+		}
+		fmt.Printf("%s  %-10s  %d file(s)  %s\n", tx.ID, state, len(tx.Entries), tx.Description)
+	}
+	return nil
+}
+
+// CreateRefactorCommands はrefactorコマンドを作成
+func (h *RefactorHandler) CreateRefactorCommands() *cobra.Command {
+	refactorCmd := &cobra.Command{
+		Use:   "refactor <instruction>",
+		Short: "Apply a multi-file refactoring as one transaction",
+		Long: `Plan edits across multiple files from an instruction such as "rename Hello to Greet".
+The files referencing the identifiers in the instruction are sent to the model (or only --files),
+the resulting edits are shown as diffs and, after confirmation, applied all-or-nothing.
+The project is then built (and tested with --test); on failure every file is rolled back.
+Applied refactorings are recorded in ~/.vyb/journal and can be undone with "vyb refactor undo".`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts RefactorOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Files, _ = cmd.Flags().GetStringSlice("files")
+			opts.MaxFiles, _ = cmd.Flags().GetInt("max-files")
+			opts.Yes, _ = cmd.Flags().GetBool("yes")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.Test, _ = cmd.Flags().GetBool("test")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Refactor(cmd.Context(), strings.Join(args, " "), opts)
+		},
+	}
+	refactorCmd.Flags().StringSlice("files", nil, "Files to refactor (default: files referencing the identifiers in the instruction)")
+	refactorCmd.Flags().Int("max-files", refactor.DefaultMaxFiles, "Maximum number of candidate files sent to the model")
+	refactorCmd.Flags().BoolP("yes", "y", false, "Apply without confirmation")
+	refactorCmd.Flags().Bool("dry-run", false, "Show the planned diffs without applying them")
+	refactorCmd.Flags().Bool("test", false, "Also run the tests before committing the transaction")
+	refactorCmd.Flags().Bool("json", false, "Output the plan and result as JSON")
+
+	undoCmd := &cobra.Command{
+		Use:   "undo [id]",
+		Short: "Undo an applied refactoring or file move/delete (default: the latest)",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			id := "latest"
+			if len(args) > 0 {
+				id = args[0]
+			}
+			force, _ := cmd.Flags().GetBool("force")
+			cmd.SilenceUsage = true
+			return h.Undo(id, force)
+		},
+	}
+	undoCmd.Flags().Bool("force", false, "Undo even if the files were modified afterwards")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List recorded refactorings and file moves/deletes",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.List()
+		},
+	}
+
+	refactorCmd.AddCommand(undoCmd, listCmd)
+	return refactorCmd
+}
@@ -0,0 +1,179 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/release"
+	"github.com/glkt/vyb-code/internal/review"
+	"github.com/spf13/cobra"
+)
+
+// ReleaseHandler はコミット履歴からの変更履歴・リリースノート生成のハンドラー
+type ReleaseHandler struct {
+	log logger.Logger
+}
+
+// NewReleaseHandler はリリースハンドラーを作成
+func NewReleaseHandler(log logger.Logger) *ReleaseHandler {
+	return &ReleaseHandler{log: log}
+}
+
+// ReleaseNotesOptions は vyb release notes の指定内容
+type ReleaseNotesOptions struct {
+	Profile   string
+	Range     string // v1.2.0..HEAD 等（空なら直近のタグから）
+	Version   string // 見出しのバージョン（空なら範囲の終わりのタグ、HEAD なら Unreleased）
+	Output    string // 書き込む変更履歴のファイル
+	NoSummary bool   // モデルによる要約（Highlights）を付けない
+	Print     bool   // ファイルを変更せず、リリースの節を標準出力に出す
+	Yes       bool
+	DryRun    bool
+}
+
+// Notes は範囲のコミットを種類毎にまとめ、利用者向けの要約を付けて変更履歴に書き込む
+func (h *ReleaseHandler) Notes(ctx context.Context, opts ReleaseNotesOptions) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	revisionRange := release.ResolveRange(ctx, workDir, opts.Range)
+	commits, err := release.Log(ctx, workDir, revisionRange)
+	if err != nil {
+		return err
+	}
+
+	version, date := opts.Version, ""
+	end := release.RangeEnd(revisionRange)
+	if version == "" && end != "HEAD" {
+		version = end
+	}
+	if version == "" {
+		version = release.Unreleased
+	} else {
+		date = release.CommitDate(ctx, workDir, end)
+	}
+	repo, _ := review.RemoteRepo(ctx, workDir)
+	notes := release.NewNotes(version, date, revisionRange, repo, commits)
+	if notes.Empty() {
+		fmt.Printf("No commits to list in %s\n", revisionRange)
+		return nil
+	}
+
+	if !opts.NoSummary {
+		resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+		if err != nil {
+			return fmt.Errorf("設定読み込みエラー: %w", err)
+		}
+		cfg := resolved.Config
+		memory, _ := config.LoadProjectMemory("")
+		summarizer := &release.Summarizer{
+			Provider: newLLMProvider(cfg),
+			Model:    cfg.ResolvedModel(),
+			Language: cfg.Language,
+			Memory:   memory,
+			Registry: prompts.DefaultRegistry(),
+		}
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m  Summarizing %d commit(s) in %s...\033[0m\n", len(commits), revisionRange)
+		highlights, err := summarizer.Summarize(ctx, notes)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "⚠️  Summary skipped: %v\n", err)
+		}
+		notes.Highlights = highlights
+	}
+
+	if opts.Print {
+		fmt.Print(notes.Markdown())
+		return nil
+	}
+
+	existing, err := os.ReadFile(opts.Output)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("変更履歴の読み込みエラー: %w", err)
+	}
+	updated := release.UpdateChangelog(string(existing), notes)
+	if updated == string(existing) {
+		fmt.Printf("%s is up to date\n", opts.Output)
+		return nil
+	}
+
+	name := filepath.ToSlash(opts.Output)
+	fmt.Printf("📝 %s (%s, %d commit(s))\n", opts.Output, notes.Version, len(commits))
+	fmt.Print(colorizeDiff(diff.Unified("a/"+name, "b/"+name, string(existing), updated, 3)))
+	if opts.DryRun {
+		return nil
+	}
+	if !opts.Yes {
+		fmt.Print("Apply this change? [y/N] ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+	if err := os.WriteFile(opts.Output, []byte(updated), 0644); err != nil {
+		return fmt.Errorf("変更履歴の書き込みエラー: %w", err)
+	}
+	fmt.Printf("✅ Updated %s\n", opts.Output)
+
+	h.log.Info("変更履歴を更新しました", map[string]interface{}{
+		"range":   revisionRange,
+		"version": notes.Version,
+		"commits": len(commits),
+		"output":  opts.Output,
+	})
+	return nil
+}
+
+// CreateReleaseCommand はリリース関連のコマンドを作成
+func (h *ReleaseHandler) CreateReleaseCommand() *cobra.Command {
+	releaseCmd := &cobra.Command{
+		Use:   "release",
+		Short: "Release helpers: changelog and release notes from the commit history",
+	}
+
+	notesCmd := &cobra.Command{
+		Use:   "notes [range]",
+		Short: "Write release notes for a commit range into CHANGELOG.md",
+		Long: `Collect the commits in a range (e.g. v1.2.0..HEAD; a single ref means ref..HEAD; default: the latest tag
+to HEAD) and group them by Conventional Commits type: Features (feat), Bug Fixes (fix), Performance (perf),
+Documentation (docs), Chores (chore, refactor, test, build, ci, style, revert) and Other Changes.
+Breaking changes ("!" or BREAKING CHANGE:) are also listed on their own. PR numbers are taken from squash-merge
+subjects ("(#123)") and from "Merge pull request #123" commits, and linked when origin is on GitHub.
+The model writes a short Highlights section of user-facing changes (--no-summary to skip it).
+The section is inserted into CHANGELOG.md, or replaces the section for the same version, after showing the diff.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts ReleaseNotesOptions
+			if len(args) > 0 {
+				opts.Range = args[0]
+			}
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Version, _ = cmd.Flags().GetString("version")
+			opts.Output, _ = cmd.Flags().GetString("output")
+			opts.NoSummary, _ = cmd.Flags().GetBool("no-summary")
+			opts.Print, _ = cmd.Flags().GetBool("print")
+			opts.Yes, _ = cmd.Flags().GetBool("yes")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			cmd.SilenceUsage = true
+			return h.Notes(cmd.Context(), opts)
+		},
+	}
+	notesCmd.Flags().String("version", "", "Version for the heading (default: the tag at the end of the range, or Unreleased)")
+	notesCmd.Flags().StringP("output", "o", "CHANGELOG.md", "Changelog file to write")
+	notesCmd.Flags().Bool("no-summary", false, "Do not ask the model for a Highlights summary")
+	notesCmd.Flags().Bool("print", false, "Print the release section to stdout instead of updating the changelog")
+	notesCmd.Flags().BoolP("yes", "y", false, "Write the changelog without confirmation")
+	notesCmd.Flags().Bool("dry-run", false, "Show the diff without writing")
+
+	releaseCmd.AddCommand(notesCmd)
+	return releaseCmd
+}
@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/plugins"
+	"github.com/glkt/vyb-code/internal/remote"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/version"
+	"github.com/spf13/cobra"
+)
+
+// RemoteHandler はリモート開発用エージェント（vyb agent）のハンドラー
+type RemoteHandler struct {
+	log logger.Logger
+}
+
+// NewRemoteHandler はリモートハンドラーの新しいインスタンスを作成
+func NewRemoteHandler(log logger.Logger) *RemoteHandler {
+	return &RemoteHandler{log: log}
+}
+
+// ServeAgent は dir を作業ディレクトリとしてツール層を標準入出力で公開する
+// LLM を使わないため、リモートホストにモデルやLLMサーバーは不要
+func (h *RemoteHandler) ServeAgent(dir string) error {
+	// プロトコル以外の出力が標準出力に混ざらないようstderrへ退避
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	defer func() { os.Stdout = out }()
+
+	if dir != "" {
+		if strings.HasPrefix(dir, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				dir = filepath.Join(home, dir[2:])
+			}
+		}
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("作業ディレクトリに移動できません: %w", err)
+		}
+	}
+	workDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile("")})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return fmt.Errorf("実行環境の初期化エラー: %w", err)
+	}
+	registry := tools.NewUnifiedToolRegistry(security.NewDefaultConstraints(workDir), nil)
+	registry.SetExecutionBackend(backend)
+	networkPolicy := security.NewNetworkPolicy(cfg.Network.Mode, cfg.Network.AllowedDomains, cfg.Network.BlockedDomains)
+	networkPolicy.SetAllowLoopback(cfg.Network.AllowLoopback)
+	registry.SetNetworkPolicy(networkPolicy)
+	if err := registry.ConfigureWebTools(cfg.WebTools); err != nil {
+		fmt.Fprintf(os.Stderr, "⚠️  Webツール設定エラー: %v\n", err)
+	}
+	extensions := plugins.LoadExtensions(&plugins.Host{Bus: events.Default(), Tools: registry}, cfg.Extensions, workDir)
+	defer extensions.Close()
+	for name, err := range extensions.Errors {
+		fmt.Fprintf(os.Stderr, "⚠️  拡張 %s の読み込みエラー: %v\n", name, err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	h.log.Info("Remote agent started", map[string]interface{}{"work_dir": workDir})
+	return remote.NewAgent(registry, workDir, version.GetVersion()).Serve(ctx, os.Stdin, out)
+}
+
+// CreateAgentCommand は vyb agent コマンドを作成
+func (h *RemoteHandler) CreateAgentCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "agent",
+		Short: "Expose the tool layer on this host for remote development (used by vyb --remote over SSH)",
+		Long:  `Serve file read/write/edit, search, git and command execution tools as newline-delimited JSON-RPC on stdin/stdout. A local vyb started with --remote user@host:/path launches this over SSH and keeps the LLM and UI on the local machine, so no model is needed on the remote host.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			stdio, _ := cmd.Flags().GetBool("stdio")
+			if !stdio {
+				return fmt.Errorf("現在は --stdio トランスポートのみ対応しています")
+			}
+			dir, _ := cmd.Flags().GetString("dir")
+			cmd.SilenceUsage = true
+			return h.ServeAgent(dir)
+		},
+	}
+	cmd.Flags().Bool("stdio", false, "Communicate over stdin/stdout")
+	cmd.Flags().String("dir", "", "Working directory on this host (default: current directory)")
+	return cmd
+}
+
+// remoteToolUser はツール層をリモートエージェントに置き換えられるセッション管理
+type remoteToolUser interface {
+	UseRemoteTools(client *remote.Client) ([]string, error)
+}
+
+// connectRemote は設定されたリモートホストのエージェントに接続し、ツール層を置き換える
+// 接続できない場合はローカルを誤って操作しないようエラーにする
+func (h *ChatHandler) connectRemote(cfg *config.Config) error {
+	user, ok := h.interactiveManager.(remoteToolUser)
+	if !ok {
+		return fmt.Errorf("このセッションはリモート開発に対応していません")
+	}
+
+	fmt.Fprintf(os.Stderr, "\033[38;5;244m%s\033[0m\n", i18n.T("remote.connecting", cfg.Remote.Host))
+	client, err := remote.Dial(context.Background(), cfg.Remote)
+	if err != nil {
+		return err
+	}
+	mounted, err := user.UseRemoteTools(client)
+	if err != nil {
+		client.Close()
+		return fmt.Errorf("リモートのツールを登録できません: %w", err)
+	}
+	h.remote = client
+	fmt.Fprintf(os.Stderr, "\033[38;5;34m%s\033[0m\n", i18n.T("remote.connected", client.Info.Hostname, client.Info.WorkDir, len(mounted)))
+	return nil
+}
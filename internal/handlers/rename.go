@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/journal"
+	"github.com/glkt/vyb-code/internal/refactor"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/spf13/cobra"
+)
+
+// RenameOptions は rename の指定内容
+type RenameOptions struct {
+	Profile string
+	File    string // 同名の宣言が複数あるときの宣言のあるファイル
+	Yes     bool
+	DryRun  bool
+	Test    bool // ビルドに加えてテストでも検証する
+	JSON    bool
+}
+
+// Rename は型検査で求めたシンボルの全ての参照を1つのトランザクションとして書き換え、ビルドで検証する
+// 書き換えなかった旧名の出現（コメント・文字列・設定等）は手作業で確認するよう一覧にする
+func (h *RefactorHandler) Rename(ctx context.Context, symbol, newName string, opts RenameOptions) error {
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	refactorer := &refactor.Refactorer{ProjectDir: projectDir}
+
+	if !opts.JSON {
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m  Type-checking the module to find references to %s\033[0m\n", symbol)
+	}
+	rename, err := refactorer.PlanRename(symbol, newName, opts.File)
+	if err != nil {
+		return err
+	}
+
+	if !opts.JSON {
+		fmt.Printf("🔤 %s → %s (declared at %s): %s\n", symbol, newName, rename.Declared, rename.Plan.Summary)
+		for _, warning := range rename.Warnings {
+			fmt.Printf("\033[38;5;214m⚠️  %s\033[0m\n", warning)
+		}
+		for _, edit := range rename.Plan.Edits {
+			fmt.Printf("\n📝 %s\n", edit.Path)
+			fmt.Print(colorizeDiff(edit.Diff()))
+		}
+	}
+	if opts.DryRun {
+		if opts.JSON {
+			return encodeRenameResult(rename, nil)
+		}
+		printRenameMentions(rename)
+		fmt.Printf("\n%d file(s) would change (dry run)\n", len(rename.Plan.Edits))
+		return nil
+	}
+	if !opts.Yes {
+		if opts.JSON {
+			return fmt.Errorf("--json で適用するには --yes を指定してください")
+		}
+		fmt.Printf("\nRename in %d file(s) as one transaction? [y/N] ", len(rename.Plan.Edits))
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			fmt.Println("Cancelled")
+			return nil
+		}
+	}
+
+	runner, err := validationRunner(cfg, projectDir, opts.JSON)
+	if err != nil {
+		return err
+	}
+	outcome, err := refactorer.Apply(ctx, rename.Plan, journal.DefaultDir(), runner, opts.Test)
+	if err != nil {
+		return err
+	}
+	h.log.Info("Rename completed", map[string]interface{}{
+		"symbol":      symbol,
+		"new_name":    newName,
+		"references":  rename.References,
+		"files":       len(rename.Plan.Edits),
+		"mentions":    len(rename.Mentions),
+		"applied":     outcome.Applied,
+		"rolled_back": outcome.RolledBack,
+	})
+
+	if opts.JSON {
+		if err := encodeRenameResult(rename, outcome); err != nil {
+			return err
+		}
+	} else if outcome.Applied {
+		fmt.Printf("\n\033[38;5;46m✅ Renamed %d reference(s) in %d file(s)\033[0m (undo with: vyb refactor undo %s)\n", rename.References, len(rename.Plan.Edits), outcome.TransactionID)
+		printRenameMentions(rename)
+	} else if outcome.Validation != nil {
+		fmt.Printf("\n%s", tasks.FormatFailures(outcome.Validation))
+	}
+	if outcome.RolledBack {
+		return fmt.Errorf("検証に失敗したため、すべての変更をロールバックしました")
+	}
+	return nil
+}
+
+// printRenameMentions は書き換えなかった旧名の出現を手作業での確認用に表示
+func printRenameMentions(rename *refactor.Rename) {
+	if len(rename.Skipped) > 0 {
+		fmt.Printf("\n\033[38;5;214m⚠️  Not type-checked (build constraints): %s\033[0m\n", strings.Join(rename.Skipped, ", "))
+	}
+	if len(rename.Mentions) == 0 {
+		return
+	}
+	fmt.Printf("\n🔎 %d remaining mention(s) of %s to review (comments, strings, docs, configs):\n", len(rename.Mentions), rename.OldName)
+	for _, mention := range rename.Mentions {
+		fmt.Printf("  %s:%d  \033[38;5;244m%s\033[0m\n", mention.Path, mention.Line, truncateRunes(mention.Text, 100))
+	}
+	if rename.Truncated {
+		fmt.Println("  …")
+	}
+}
+
+// encodeRenameResult は名前変更の計画と適用結果をJSONで出力
+func encodeRenameResult(rename *refactor.Rename, outcome *refactor.Outcome) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(map[string]interface{}{
+		"rename":  rename,
+		"outcome": outcome,
+	})
+}
+
+// CreateRenameCommand はrenameコマンドを作成
+func (h *RefactorHandler) CreateRenameCommand() *cobra.Command {
+	renameCmd := &cobra.Command{
+		Use:   "rename <symbol> <new-name>",
+		Short: "Rename a Go symbol across the module and verify the build",
+		Long: `Rename a package-level declaration (Name) or a method or field (Type.Member) everywhere it is used.
+The module is type-checked from source, so only identifiers that refer to the symbol are changed,
+including other packages, tests and embedded fields; unrelated symbols with the same name are left alone.
+The edits are applied as one transaction and the project is built (and tested with --test);
+on failure every file is rolled back. Remaining mentions of the old name in comments, strings,
+docs and configuration files are listed for manual review. Undo with "vyb refactor undo".`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts RenameOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.File, _ = cmd.Flags().GetString("file")
+			opts.Yes, _ = cmd.Flags().GetBool("yes")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.Test, _ = cmd.Flags().GetBool("test")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Rename(cmd.Context(), args[0], args[1], opts)
+		},
+	}
+	renameCmd.Flags().String("file", "", "File declaring the symbol (when several packages declare the same name)")
+	renameCmd.Flags().BoolP("yes", "y", false, "Apply without confirmation")
+	renameCmd.Flags().Bool("dry-run", false, "Show the diffs and remaining mentions without applying them")
+	renameCmd.Flags().Bool("test", false, "Also run the tests before committing the transaction")
+	renameCmd.Flags().Bool("json", false, "Output the rename plan and result as JSON")
+	return renameCmd
+}
@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/replay"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/spf13/cobra"
+)
+
+// ReplayHandler は監査ログに記録したセッションの決定的な再実行（vyb replay）のハンドラー
+type ReplayHandler struct {
+	log logger.Logger
+	dir string
+}
+
+// NewReplayHandler は再実行ハンドラーを作成（dirが空なら ~/.vyb/logs）
+func NewReplayHandler(log logger.Logger, dir string) *ReplayHandler {
+	if dir == "" {
+		dir = logger.DefaultAuditDir()
+	}
+	return &ReplayHandler{log: log, dir: dir}
+}
+
+// ReplayOptions は vyb replay の指定内容
+type ReplayOptions struct {
+	Profile    string
+	ProjectDir string // 複製して再実行するプロジェクト（空ならカレントディレクトリ）
+	Keep       bool   // 作業ディレクトリと再実行の監査ログを残す
+	JSON       bool
+}
+
+// Replay はセッションを再実行して記録との違いを表示し、違いがあればエラーを返す
+// session はセッションID、"latest"、または監査ログのファイル（.jsonl）
+func (h *ReplayHandler) Replay(ctx context.Context, session string, opts ReplayOptions) error {
+	sessionID, events, err := h.loadEvents(session)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	// 題名の生成は記録されたモデルの応答を消費してしまうため再実行では行わない
+	cfg.SessionTitles.Enabled = false
+
+	projectDir := opts.ProjectDir
+	if projectDir == "" {
+		if projectDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+		}
+	}
+	runner := &replay.Runner{
+		NewManager: func(provider llm.Provider) (interactive.SessionManager, error) {
+			editTool := tools.NewEditTool(security.NewDefaultConstraints("."), ".", 10*1024*1024)
+			return interactive.NewInteractiveSessionManager(
+				contextmanager.NewSmartContextManager(),
+				provider,
+				nil,
+				editTool,
+				nil,
+				cfg.ResolvedModel(),
+				cfg,
+			), nil
+		},
+		ProjectDir: projectDir,
+		Keep:       opts.Keep,
+	}
+
+	// 再実行中の進捗・ツール出力が結果に混ざらないよう stderr に退避
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	report, err := runner.Run(ctx, sessionID, events)
+	os.Stdout = out
+	if err != nil {
+		return err
+	}
+
+	h.log.Info("セッションを再実行しました", map[string]interface{}{
+		"session":  sessionID,
+		"turns":    len(report.Turns),
+		"diverged": report.Diverged(),
+	})
+
+	if opts.JSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		printReplayReport(report)
+	}
+	if report.Diverged() {
+		return fmt.Errorf("再実行の結果が記録と一致しません: %s", sessionID)
+	}
+	return nil
+}
+
+// loadEvents はセッションID・"latest"・監査ログのファイルから記録を読み込む
+func (h *ReplayHandler) loadEvents(session string) (string, []logger.AuditEvent, error) {
+	if strings.HasSuffix(session, ".jsonl") {
+		if _, err := os.Stat(session); err == nil {
+			sessionID := strings.TrimSuffix(filepath.Base(session), ".jsonl")
+			events, err := logger.ReadAuditLog(filepath.Dir(session), sessionID)
+			return sessionID, events, err
+		}
+	}
+	if session == "latest" {
+		sessions, err := logger.ListAuditSessions(h.dir)
+		if err != nil {
+			return "", nil, fmt.Errorf("監査ログ一覧取得エラー: %w", err)
+		}
+		if len(sessions) == 0 {
+			return "", nil, fmt.Errorf("監査ログがありません: %s", h.dir)
+		}
+		session = sessions[0].SessionID
+	}
+	events, err := logger.ReadAuditLog(h.dir, session)
+	return session, events, err
+}
+
+// printReplayReport はターン毎の操作と記録との違いを表示
+func printReplayReport(report *replay.Report) {
+	fmt.Printf("Replay of %s (%d turns)\n", report.SessionID, len(report.Turns))
+	for _, turn := range report.Turns {
+		mark := "\033[38;5;46m✓\033[0m"
+		if len(turn.Divergences) > 0 {
+			mark = "\033[38;5;196m✗\033[0m"
+		}
+		fmt.Printf("\n%s Turn %d: %s\n", mark, turn.Index, truncateRunes(turn.Input, 60))
+		for _, action := range turn.Replayed {
+			fmt.Printf("    %s\n", action)
+		}
+		if turn.Applied {
+			fmt.Println("    \033[38;5;244m(pending suggestion applied as recorded)\033[0m")
+		}
+		if turn.Error != "" {
+			fmt.Printf("    \033[38;5;196merror: %s\033[0m\n", turn.Error)
+		}
+		for _, divergence := range turn.Divergences {
+			fmt.Printf("    \033[38;5;214m≠ %s\033[0m\n", divergence)
+		}
+	}
+
+	fmt.Println()
+	if report.Diverged() {
+		fmt.Println("Replay diverged from the recording")
+	} else {
+		fmt.Println("Replay matches the recording")
+	}
+	if report.WorkDir != "" {
+		fmt.Printf("Work directory: %s\n", filepath.Join(report.WorkDir, "project"))
+		fmt.Printf("Replay audit log: %s\n", logger.AuditLogPath(filepath.Join(report.WorkDir, "logs"), report.ReplayID))
+	}
+}
+
+// CreateReplayCommands は replay コマンドを作成
+func (h *ReplayHandler) CreateReplayCommands() *cobra.Command {
+	replayCmd := &cobra.Command{
+		Use:   "replay <session|latest|file.jsonl>",
+		Short: "Re-run a recorded session against its recorded model responses and command output",
+		Long: `Replay the user inputs of a session from its audit log (~/.vyb/logs/<session>.jsonl) in a scratch
+copy of the project. Model requests are answered with the recorded responses and shell commands, tasks
+and bash tool calls return the recorded output and exit code instead of running, so the replay is
+deterministic and never touches the original files. Pending suggestions are applied when the recording
+shows they were applied.
+
+Each turn lists what the replay did and where it diverged from the recording (different or missing
+tool calls, unrecorded commands, a different final response). The command fails when the replay
+diverges, so a saved audit log can serve as a regression test for prompt and pipeline changes.
+Replay with the same configuration the session was recorded with.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts ReplayOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.ProjectDir, _ = cmd.Flags().GetString("dir")
+			opts.Keep, _ = cmd.Flags().GetBool("keep")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Replay(cmd.Context(), args[0], opts)
+		},
+	}
+	replayCmd.Flags().String("dir", "", "Project to copy into the scratch directory (default: current directory)")
+	replayCmd.Flags().Bool("keep", false, "Keep the scratch directory and the replay's audit log")
+	replayCmd.Flags().Bool("json", false, "Output the report as JSON")
+
+	return replayCmd
+}
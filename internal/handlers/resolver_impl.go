@@ -40,7 +40,7 @@ func (r *ReflectiveDependencyResolver) ResolveLLMProvider(cfg *config.Config) (L
 	provider := &LLMProviderAdapter{
 		providerName: cfg.Provider,
 		baseURL:      cfg.BaseURL,
-		model:        cfg.Model,
+		model:        cfg.ResolvedModel(),
 		logger:       r.logger,
 	}
 
@@ -0,0 +1,233 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/review"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// レビュー結果の出力形式
+const (
+	reviewFormatText     = "text"
+	reviewFormatMarkdown = "markdown"
+	reviewFormatJSON     = "json"
+	reviewFormatGitHub   = "github"
+)
+
+// ReviewHandler は差分・PRのコードレビューのハンドラー
+type ReviewHandler struct {
+	log logger.Logger
+}
+
+// NewReviewHandler はレビューハンドラーを作成
+func NewReviewHandler(log logger.Logger) *ReviewHandler {
+	return &ReviewHandler{log: log}
+}
+
+// ReviewOptions は vyb review の指定内容
+type ReviewOptions struct {
+	Profile     string
+	Base        string // 比較元ブランチ（空なら main、なければ master）
+	PR          int    // GitHubのPR番号（0ならローカルの差分）
+	Format      string // text, markdown, json, github
+	Output      string // 出力先ファイル（空なら標準出力）
+	MinSeverity string // これ未満の指摘は出力しない
+	FailOn      string // この重要度以上の指摘があれば失敗終了
+}
+
+// Review は差分をファイル毎にレビューし、指定の形式で出力する
+func (h *ReviewHandler) Review(ctx context.Context, opts ReviewOptions) error {
+	if err := validateReviewOptions(&opts); err != nil {
+		return err
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	workDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	// 差分の取得（PR指定時はGitHubから）
+	var patch, source, repo string
+	var pr *review.PullRequest
+	github := review.NewGitHubClient()
+	if opts.PR > 0 {
+		if repo, err = review.RemoteRepo(ctx, workDir); err != nil {
+			return err
+		}
+		if pr, err = github.PullRequest(ctx, repo, opts.PR); err != nil {
+			return err
+		}
+		if patch, err = github.PullRequestDiff(ctx, repo, opts.PR); err != nil {
+			return err
+		}
+		source = fmt.Sprintf("%s#%d", repo, opts.PR)
+	} else {
+		if opts.Base == "" {
+			opts.Base = review.DefaultBase(ctx, workDir)
+		}
+		if patch, err = review.GitDiff(ctx, workDir, opts.Base); err != nil {
+			return err
+		}
+		source = opts.Base + "...HEAD"
+	}
+
+	files := diff.ParseFiles(patch)
+	if len(files) == 0 {
+		fmt.Printf("No changes to review in %s\n", source)
+		return nil
+	}
+
+	memory, _ := config.LoadProjectMemory("")
+	reviewer := &review.Reviewer{
+		Provider: newLLMProvider(cfg),
+		Model:    cfg.ResolvedModel(),
+		Language: cfg.Language,
+		Memory:   memory,
+		Registry: prompts.DefaultRegistry(),
+		Progress: func(chunk review.Chunk, index, total int) {
+			name := chunk.File.Path
+			if chunk.Parts > 1 {
+				name = fmt.Sprintf("%s (%d/%d)", name, chunk.Part, chunk.Parts)
+			}
+			fmt.Fprintf(os.Stderr, "\033[38;5;244m[%d/%d] Reviewing %s\033[0m\n", index+1, total, name)
+		},
+	}
+
+	report, err := reviewer.Review(ctx, source, files)
+	if err != nil {
+		return fmt.Errorf("レビューエラー: %w", err)
+	}
+	if opts.MinSeverity != "" {
+		report.Filter(opts.MinSeverity)
+	}
+	h.log.Info("Code review completed", map[string]interface{}{
+		"source":   source,
+		"files":    report.Files,
+		"findings": len(report.Findings),
+		"errors":   len(report.Errors),
+	})
+
+	if err := h.writeReport(ctx, opts, report, github, repo, pr); err != nil {
+		return err
+	}
+
+	if opts.FailOn != "" {
+		if count := report.CountAtLeast(opts.FailOn); count > 0 {
+			return fmt.Errorf("%s 以上の指摘が %d 件あります", opts.FailOn, count)
+		}
+	}
+	return nil
+}
+
+// writeReport はレビュー結果を指定の形式で出力（github はPRへ投稿）
+func (h *ReviewHandler) writeReport(ctx context.Context, opts ReviewOptions, report *review.Report, github *review.GitHubClient, repo string, pr *review.PullRequest) error {
+	if opts.Format == reviewFormatGitHub {
+		url, err := github.PostReview(ctx, repo, pr, report)
+		if err != nil {
+			return fmt.Errorf("レビュー投稿エラー: %w", err)
+		}
+		fmt.Printf("Posted review to %s#%d: %s\n", repo, pr.Number, report.Summary())
+		if url != "" {
+			fmt.Printf("  %s\n", url)
+		}
+		return nil
+	}
+
+	out := os.Stdout
+	if opts.Output != "" {
+		file, err := os.Create(opts.Output)
+		if err != nil {
+			return fmt.Errorf("出力ファイル作成エラー: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	switch opts.Format {
+	case reviewFormatJSON:
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(report)
+	case reviewFormatMarkdown:
+		_, err := fmt.Fprint(out, review.FormatMarkdown(report))
+		return err
+	default:
+		color := out == os.Stdout && term.IsTerminal(int(os.Stdout.Fd()))
+		review.WriteText(out, report, color)
+		return nil
+	}
+}
+
+// validateReviewOptions は形式・重要度の指定を検証
+func validateReviewOptions(opts *ReviewOptions) error {
+	switch opts.Format {
+	case "", reviewFormatText:
+		opts.Format = reviewFormatText
+	case "md":
+		opts.Format = reviewFormatMarkdown
+	case reviewFormatMarkdown, reviewFormatJSON:
+	case reviewFormatGitHub:
+		if opts.PR == 0 {
+			return fmt.Errorf("--format github には --pr の指定が必要です")
+		}
+	default:
+		return fmt.Errorf("不明な出力形式: %s (text, markdown, json, github)", opts.Format)
+	}
+
+	for _, severity := range []string{opts.MinSeverity, opts.FailOn} {
+		if severity != "" && review.SeverityRank(severity) == 0 {
+			return fmt.Errorf("不明な重要度: %s (%s)", severity, strings.Join(review.ValidSeverities(), ", "))
+		}
+	}
+	return nil
+}
+
+// CreateReviewCommands はreviewコマンドを作成
+func (h *ReviewHandler) CreateReviewCommands() *cobra.Command {
+	reviewCmd := &cobra.Command{
+		Use:   "review",
+		Short: "Review the current branch diff or a GitHub pull request",
+		Long: `Review the changes of the current branch (git diff <base>...HEAD) or of a GitHub pull request.
+The diff is split per file (large files per hunk) and each chunk is reviewed with the review prompt,
+producing findings with severity (critical, major, minor, nit), file, line and a suggestion.
+Results are printed to the terminal, as Markdown or JSON, or posted as a GitHub review with
+line comments (--format github, requires --pr and GITHUB_TOKEN).`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts ReviewOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Base, _ = cmd.Flags().GetString("base")
+			opts.PR, _ = cmd.Flags().GetInt("pr")
+			opts.Format, _ = cmd.Flags().GetString("format")
+			opts.Output, _ = cmd.Flags().GetString("output")
+			opts.MinSeverity, _ = cmd.Flags().GetString("min-severity")
+			opts.FailOn, _ = cmd.Flags().GetString("fail-on")
+			cmd.SilenceUsage = true
+			return h.Review(cmd.Context(), opts)
+		},
+	}
+	reviewCmd.Flags().String("base", "", "Base branch to diff against (default: main, or master)")
+	reviewCmd.Flags().Int("pr", 0, "GitHub pull request number to review instead of the local diff")
+	reviewCmd.Flags().StringP("format", "f", reviewFormatText, "Output format: text, markdown, json, github")
+	reviewCmd.Flags().StringP("output", "o", "", "Write the review to a file")
+	reviewCmd.Flags().String("min-severity", "", "Hide findings below this severity")
+	reviewCmd.Flags().String("fail-on", "", "Exit with an error when findings at or above this severity exist")
+
+	return reviewCmd
+}
@@ -0,0 +1,194 @@
+package handlers
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/scaffold"
+	"github.com/spf13/cobra"
+)
+
+// ScaffoldHandler はCI設定・Makefile等のひな形生成のハンドラー
+type ScaffoldHandler struct {
+	log logger.Logger
+}
+
+// NewScaffoldHandler はひな形生成ハンドラーを作成
+func NewScaffoldHandler(log logger.Logger) *ScaffoldHandler {
+	return &ScaffoldHandler{log: log}
+}
+
+// ScaffoldCIOptions は scaffold ci の指定内容
+type ScaffoldCIOptions struct {
+	Profile string
+	Targets []string // 空なら既存の設定から選ぶ
+	Inspect bool     // 生成せず、検出したプロジェクトの情報を表示する
+	Yes     bool     // 確認せずに適用する
+	DryRun  bool     // 差分の表示のみ
+	JSON    bool
+}
+
+// ScaffoldCI はプロジェクトを調べてCI設定・Makefileを生成し、差分を確認して適用する
+func (h *ScaffoldHandler) ScaffoldCI(ctx context.Context, opts ScaffoldCIOptions) error {
+	project, err := scaffold.Inspect(".")
+	if err != nil {
+		return err
+	}
+
+	var targets []scaffold.Target
+	for _, name := range opts.Targets {
+		target, err := scaffold.ParseTarget(name)
+		if err != nil {
+			return err
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 0 {
+		targets = []scaffold.Target{project.DefaultTarget()}
+	}
+
+	if opts.Inspect {
+		if opts.JSON {
+			encoder := json.NewEncoder(os.Stdout)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(project)
+		}
+		fmt.Print(project.Summary())
+		return nil
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	memory, _ := config.LoadProjectMemory("")
+	generator := &scaffold.Generator{
+		Provider: newLLMProvider(cfg),
+		Model:    cfg.ResolvedModel(),
+		Language: cfg.Language,
+		Memory:   memory,
+		Registry: prompts.DefaultRegistry(),
+	}
+
+	var changes []*scaffold.FileChange
+	for _, target := range targets {
+		fmt.Fprintf(os.Stderr, "\033[38;5;244m  Generating %s (%s)\033[0m\n", target.Path(), target.Label())
+		change, err := generator.Generate(ctx, project, target)
+		if err != nil {
+			return err
+		}
+		if change != nil {
+			changes = append(changes, change)
+		}
+	}
+
+	if opts.JSON {
+		return h.encodeScaffoldChanges(changes, opts)
+	}
+	if len(changes) == 0 {
+		fmt.Println("No configuration changes")
+		return nil
+	}
+
+	input := bufio.NewReader(os.Stdin)
+	applied := 0
+	for _, change := range changes {
+		fmt.Printf("\n🛠  %s (%s)\n", change.Path, change.Target.Label())
+		fmt.Print(colorizeDiff(change.Diff()))
+		if opts.DryRun {
+			continue
+		}
+		if !opts.Yes {
+			fmt.Print("Apply this change? [y/N] ")
+			answer, _ := input.ReadString('\n')
+			if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+				continue
+			}
+		}
+		if err := change.Apply(); err != nil {
+			return err
+		}
+		applied++
+	}
+
+	if opts.DryRun {
+		fmt.Printf("\n%d file(s) would change (dry run)\n", len(changes))
+	} else {
+		fmt.Printf("\nApplied %d of %d change(s)\n", applied, len(changes))
+	}
+	h.log.Info("CI scaffolding completed", map[string]interface{}{
+		"targets": len(targets),
+		"changes": len(changes),
+		"applied": applied,
+	})
+	return nil
+}
+
+// encodeScaffoldChanges は変更をJSONで出力（--yes なら適用する）
+func (h *ScaffoldHandler) encodeScaffoldChanges(changes []*scaffold.FileChange, opts ScaffoldCIOptions) error {
+	type changeJSON struct {
+		*scaffold.FileChange
+		Diff    string `json:"diff"`
+		Applied bool   `json:"applied"`
+	}
+	output := make([]changeJSON, 0, len(changes))
+	for _, change := range changes {
+		entry := changeJSON{FileChange: change, Diff: change.Diff()}
+		if opts.Yes && !opts.DryRun {
+			if err := change.Apply(); err != nil {
+				return err
+			}
+			entry.Applied = true
+		}
+		output = append(output, entry)
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// CreateScaffoldCommands はscaffoldコマンドを作成
+func (h *ScaffoldHandler) CreateScaffoldCommands() *cobra.Command {
+	scaffoldCmd := &cobra.Command{
+		Use:   "scaffold",
+		Short: "Generate project configuration such as CI pipelines",
+	}
+
+	ciCmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Generate a GitHub Actions workflow, GitLab CI pipeline or Makefile",
+		Long: `Inspect the project (languages, required versions, build/test/lint commands, existing Makefile and
+CI configuration, OS-specific sources) and have the model write a CI configuration or Makefile for it.
+--target selects github (.github/workflows/ci.yml), gitlab (.gitlab-ci.yml) or makefile (Makefile) and can
+be repeated; by default the CI system already in use is chosen. Existing files are updated rather than
+replaced. Each change is shown as a diff and applied after confirmation. --inspect only prints what was detected.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts ScaffoldCIOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Targets, _ = cmd.Flags().GetStringSlice("target")
+			opts.Inspect, _ = cmd.Flags().GetBool("inspect")
+			opts.Yes, _ = cmd.Flags().GetBool("yes")
+			opts.DryRun, _ = cmd.Flags().GetBool("dry-run")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.ScaffoldCI(cmd.Context(), opts)
+		},
+	}
+	ciCmd.Flags().StringSlice("target", nil, "What to generate: github, gitlab or makefile (repeatable)")
+	ciCmd.Flags().Bool("inspect", false, "Only show the detected project information")
+	ciCmd.Flags().BoolP("yes", "y", false, "Apply changes without confirmation")
+	ciCmd.Flags().Bool("dry-run", false, "Show the diffs without applying them")
+	ciCmd.Flags().Bool("json", false, "Output as JSON")
+
+	scaffoldCmd.AddCommand(ciCmd)
+	return scaffoldCmd
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/telemetry"
+	"github.com/spf13/cobra"
+)
+
+// StatsHandler はローカルに記録した利用統計の集計表示のハンドラー
+type StatsHandler struct {
+	log logger.Logger
+}
+
+// NewStatsHandler は利用統計ハンドラーの新しいインスタンスを作成
+func NewStatsHandler(log logger.Logger) *StatsHandler {
+	return &StatsHandler{log: log}
+}
+
+// ShowStats は直近の週毎の利用状況を集計して表示（ローカルの記録のみを読み、外部には送らない）
+func (h *StatsHandler) ShowStats(weeks, top int, asJSON bool) error {
+	if weeks < 1 {
+		return fmt.Errorf("週数は1以上を指定してください")
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	path := cfg.Telemetry.Path
+	if path == "" {
+		path = telemetry.DefaultPath()
+	}
+
+	now := time.Now()
+	records, err := telemetry.Load(path, telemetry.WeekStart(now).AddDate(0, 0, -7*(weeks-1)))
+	if err != nil {
+		return err
+	}
+	stats := telemetry.Summarize(records, weeks, now, top)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("No statistics recorded since %s (%s)\n", stats.Since.Format("2006-01-02"), path)
+		if !cfg.Telemetry.Enabled {
+			fmt.Println("Recording is off. Enable it with \"vyb config set-telemetry on\" (stored locally only).")
+		}
+		return nil
+	}
+
+	fmt.Printf("%-12s %8s %8s %12s %10s %8s\n", "Week", "Sessions", "Turns", "Avg latency", "Accepted", "Edits")
+	for _, week := range stats.Weeks {
+		accepted := "-"
+		if week.Accepted+week.Rejected > 0 {
+			accepted = fmt.Sprintf("%.0f%%", week.AcceptanceRate()*100)
+		}
+		fmt.Printf("%-12s %8d %8d %12s %10s %8d\n", week.Start.Format("2006-01-02"), week.Sessions, week.Turns, formatLatency(week.AverageLatencyMs, week.Turns), accepted, week.Edits)
+	}
+
+	fmt.Printf("\nSessions: %d, turns: %d (%d failed)\n", stats.Sessions, stats.Turns, stats.FailedTurns)
+	if stats.Turns > 0 {
+		fmt.Printf("Turn latency: %s average, %s median\n", formatLatency(stats.AverageLatencyMs, stats.Turns), formatLatency(stats.MedianLatencyMs, stats.Turns))
+	}
+	if decided := stats.Accepted + stats.Rejected; decided > 0 {
+		fmt.Printf("Suggestions: %.0f%% accepted (%d of %d)\n", stats.AcceptanceRate*100, stats.Accepted, decided)
+	}
+	if len(stats.Packages) > 0 {
+		fmt.Println("\nMost edited packages:")
+		for _, pkg := range stats.Packages {
+			fmt.Printf("  %5d  %s\n", pkg.Edits, pkg.Package)
+		}
+	}
+	if !cfg.Telemetry.Enabled {
+		fmt.Println("\nRecording is currently off (\"vyb config set-telemetry on\" to resume).")
+	} else if len(cfg.Telemetry.Events) < len(config.ValidTelemetryEvents()) {
+		fmt.Printf("\nOnly recording: %s\n", strings.Join(cfg.Telemetry.Events, ", "))
+	}
+	return nil
+}
+
+// formatLatency はターンの所要時間を秒単位で表示（ターンがなければ "-"）
+func formatLatency(ms int64, turns int) string {
+	if turns == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1fs", float64(ms)/1000)
+}
+
+// CreateStatsCommand は利用統計の表示コマンドを作成
+func (h *StatsHandler) CreateStatsCommand() *cobra.Command {
+	statsCmd := &cobra.Command{
+		Use:   "stats",
+		Short: "Show local usage statistics: sessions per week, suggestion acceptance, most-edited packages, turn latency",
+		Long:  `Summarize the usage statistics recorded on this machine. Recording is opt-in ("vyb config set-telemetry on [session turn suggestion edit]"); records are appended to ~/.vyb/telemetry.jsonl (telemetry.path), contain no prompts, answers or file contents, and are never sent over the network.`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			weeks, _ := cmd.Flags().GetInt("weeks")
+			top, _ := cmd.Flags().GetInt("top")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.ShowStats(weeks, top, asJSON)
+		},
+	}
+	statsCmd.Flags().Int("weeks", 8, "Number of weeks to include, ending with the current week")
+	statsCmd.Flags().Int("top", 10, "Number of most-edited packages to show (0 for all)")
+	statsCmd.Flags().Bool("json", false, "Print the statistics as JSON")
+
+	return statsCmd
+}
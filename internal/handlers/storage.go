@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/storage"
+	"github.com/spf13/cobra"
+)
+
+// StorageHandler は保存したセッション・会話の一覧・検索のハンドラー
+type StorageHandler struct {
+	log logger.Logger
+	cfg config.StorageConfig
+}
+
+// NewStorageHandler は保存先ハンドラーの新しいインスタンスを作成
+func NewStorageHandler(log logger.Logger, cfg config.StorageConfig) *StorageHandler {
+	return &StorageHandler{log: log, cfg: cfg}
+}
+
+// open は設定の保存先を開く（memory では以前のプロセスの記録は残らない）
+func (h *StorageHandler) open() (storage.Store, error) {
+	store, err := storage.Open(h.cfg)
+	if err != nil {
+		return nil, fmt.Errorf("保存先を開けません: %w", err)
+	}
+	return store, nil
+}
+
+// ShowInfo は保存先と保存している件数を表示
+func (h *StorageHandler) ShowInfo(asJSON bool) error {
+	store, err := h.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	stats, err := store.Stats()
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(stats)
+	}
+	fmt.Printf("Backend:  %s\n", stats.Backend)
+	if stats.Path != "" {
+		fmt.Printf("Path:     %s\n", stats.Path)
+	}
+	fmt.Printf("Sessions: %d\n", stats.Sessions)
+	fmt.Printf("Messages: %d\n", stats.Messages)
+	fmt.Printf("Cached:   %d\n", stats.Entries)
+	return nil
+}
+
+// ListSessions は保存したセッションを新しい順に表示（projectOnly ならカレントディレクトリのみ）
+func (h *StorageHandler) ListSessions(projectOnly bool, days, limit int, asJSON bool) error {
+	store, err := h.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	query := storage.SessionQuery{Limit: limit}
+	if projectOnly {
+		if query.ProjectDir, err = os.Getwd(); err != nil {
+			return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+		}
+	}
+	if days > 0 {
+		query.Since = time.Now().AddDate(0, 0, -days)
+	}
+	sessions, err := store.ListSessions(query)
+	if err != nil {
+		return err
+	}
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(sessions)
+	}
+	if len(sessions) == 0 {
+		fmt.Println("No sessions stored")
+		return nil
+	}
+
+	fmt.Printf("%-30s %-36s %-16s %-12s %-20s %s\n", "Title", "Session", "Last activity", "Type", "Model", "Project")
+	for _, session := range sessions {
+		title := session.Title
+		if title == "" {
+			title = "-"
+		}
+		fmt.Printf("%-30s %-36s %-16s %-12s %-20s %s\n",
+			truncateRunes(title, 30),
+			session.ID,
+			session.LastActivity.Format("2006-01-02 15:04"),
+			session.Type,
+			truncateRunes(session.Model, 20),
+			session.ProjectDir,
+		)
+	}
+	return nil
+}
+
+// Search は保存した会話から文字列を含む発言を新しい順に表示
+func (h *StorageHandler) Search(text, sessionID string, limit int, asJSON bool) error {
+	store, err := h.open()
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+
+	messages, err := store.SearchMessages(storage.MessageQuery{Text: text, SessionID: sessionID, Limit: limit})
+	if err != nil {
+		return err
+	}
+	h.log.Info("会話を検索しました", map[string]interface{}{
+		"query":   text,
+		"matches": len(messages),
+	})
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(messages)
+	}
+	if len(messages) == 0 {
+		fmt.Printf("No messages match %q\n", text)
+		return nil
+	}
+
+	for _, message := range messages {
+		fmt.Printf("\033[38;5;244m%s  %s  %s\033[0m\n", message.Timestamp.Format("2006-01-02 15:04"), message.SessionID, message.Role)
+		fmt.Printf("  %s\n", truncateRunes(strings.Join(strings.Fields(message.Content), " "), 120))
+	}
+	return nil
+}
+
+// CreateSessionsCommands はセッション関連のコマンド（vyb sessions list）を作成
+func (h *StorageHandler) CreateSessionsCommands() *cobra.Command {
+	sessionsCmd := &cobra.Command{
+		Use:   "sessions",
+		Short: "List past sessions by title",
+		Long: `Each session is named with a short title generated after its first exchange (see
+"vyb config enable-session-titles"); rename the current one with /title in chat.`,
+	}
+	sessionsCmd.AddCommand(h.newSessionListCommand("list"))
+	return sessionsCmd
+}
+
+// newSessionListCommand は保存したセッションの一覧コマンド（vyb sessions list、vyb storage sessions）を作成
+func (h *StorageHandler) newSessionListCommand(use string) *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   use,
+		Short: "List stored sessions with their titles, most recent first",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			projectOnly, _ := cmd.Flags().GetBool("project")
+			days, _ := cmd.Flags().GetInt("days")
+			limit, _ := cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.ListSessions(projectOnly, days, limit, asJSON)
+		},
+	}
+	listCmd.Flags().Bool("project", false, "Only list sessions started in the current directory")
+	listCmd.Flags().Int("days", 0, "Only list sessions active in the last N days (0 for all)")
+	listCmd.Flags().Int("limit", 20, "Maximum number of sessions (0 for all)")
+	listCmd.Flags().Bool("json", false, "Print as JSON")
+	return listCmd
+}
+
+// CreateStorageCommands は保存先関連のコマンドを作成
+func (h *StorageHandler) CreateStorageCommands() *cobra.Command {
+	storageCmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Inspect stored sessions and search past conversations",
+		Long: `Sessions, per-session metrics, conversation flow, messages and the LLM response cache are kept in
+the store configured with "vyb config set-storage" (SQLite at ~/.vyb/vyb.db by default).`,
+	}
+
+	infoCmd := &cobra.Command{
+		Use:   "info",
+		Short: "Show the store location and how much it holds",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.ShowInfo(asJSON)
+		},
+	}
+	infoCmd.Flags().Bool("json", false, "Print as JSON")
+
+	sessionsCmd := h.newSessionListCommand("sessions")
+
+	searchCmd := &cobra.Command{
+		Use:   "search <text>",
+		Short: "Search past user inputs and responses (case-insensitive)",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			sessionID, _ := cmd.Flags().GetString("session")
+			limit, _ := cmd.Flags().GetInt("limit")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.Search(strings.Join(args, " "), sessionID, limit, asJSON)
+		},
+	}
+	searchCmd.Flags().String("session", "", "Only search the given session")
+	searchCmd.Flags().Int("limit", 20, "Maximum number of messages")
+	searchCmd.Flags().Bool("json", false, "Print as JSON")
+
+	storageCmd.AddCommand(infoCmd, sessionsCmd, searchCmd)
+	return storageCmd
+}
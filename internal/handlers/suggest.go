@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/spf13/cobra"
+)
+
+// SuggestHandler はセッションを開かずに単発のコード提案を返すハンドラー（vyb suggest）
+type SuggestHandler struct {
+	log logger.Logger
+}
+
+// NewSuggestHandler は提案ハンドラーを作成
+func NewSuggestHandler(log logger.Logger) *SuggestHandler {
+	return &SuggestHandler{log: log}
+}
+
+// SuggestOptions は vyb suggest の指定内容
+type SuggestOptions struct {
+	Profile     string
+	File        string
+	Range       string // "10:40"、"10"（1行）、空ならファイル全体
+	Type        string
+	Description string
+}
+
+// Suggest はファイルの行範囲への提案を1つ生成し、結果をJSONで出力する（ファイルは変更しない）
+func (h *SuggestHandler) Suggest(ctx context.Context, opts SuggestOptions) error {
+	if opts.File == "" {
+		return fmt.Errorf("--file を指定してください")
+	}
+	suggestionType, err := interactive.ParseSuggestionType(opts.Type)
+	if err != nil {
+		return err
+	}
+	startLine, endLine, err := parseLineRange(opts.Range)
+	if err != nil {
+		return err
+	}
+	request, err := interactive.NewFileSuggestionRequest(opts.File, startLine, endLine, suggestionType, opts.Description)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+	manager := interactive.NewInteractiveSessionManager(
+		contextmanager.NewSmartContextManager(),
+		newLLMProvider(cfg),
+		nil,
+		nil, // ファイルは変更しない
+		nil,
+		cfg.ResolvedModel(),
+		cfg,
+	)
+	suggester, ok := manager.(interactive.Suggester)
+	if !ok {
+		return fmt.Errorf("単発の提案に対応していません")
+	}
+
+	// 処理中の表示が結果のJSONに混ざらないよう stderr に退避
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	result, err := suggester.Suggest(ctx, request)
+	os.Stdout = out
+	if err != nil {
+		return err
+	}
+
+	h.log.Info("コード提案を生成しました", map[string]interface{}{
+		"file":       result.FilePath,
+		"type":       result.Type,
+		"start_line": result.StartLine,
+		"end_line":   result.EndLine,
+		"confidence": result.Confidence,
+	})
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(result)
+}
+
+// parseLineRange は "10:40"・"10"・空（ファイル全体）の行範囲を解析する
+func parseLineRange(value string) (int, int, error) {
+	if value == "" {
+		return 0, 0, nil
+	}
+	startText, endText, found := strings.Cut(value, ":")
+	if !found {
+		endText = startText
+	}
+	start, err := strconv.Atoi(strings.TrimSpace(startText))
+	if err != nil || start < 1 {
+		return 0, 0, fmt.Errorf("無効な行範囲です（例: 10:40）: %s", value)
+	}
+	end, err := strconv.Atoi(strings.TrimSpace(endText))
+	if err != nil || end < start {
+		return 0, 0, fmt.Errorf("無効な行範囲です（例: 10:40）: %s", value)
+	}
+	return start, end, nil
+}
+
+// CreateSuggestCommand は単発のコード提案コマンドを作成
+func (h *SuggestHandler) CreateSuggestCommand() *cobra.Command {
+	suggestCmd := &cobra.Command{
+		Use:   "suggest --file <path> [--range start:end] [--type kind]",
+		Short: "Get a one-off code suggestion for a file range as JSON",
+		Long: `Ask the model for a single suggestion on a file or a line range (1-based, inclusive) without starting a session.
+The result is printed as JSON: {type, file_path, start_line, end_line, original_code, suggested_code,
+explanation, confidence, impact, model, metadata}. Nothing is written to the file; editors can replace
+start_line..end_line with suggested_code. Editor plugins can request the same over "vyb serve" (code/suggest).
+Types: ` + strings.Join(interactive.SuggestionTypeNames(), ", "),
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts SuggestOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.File, _ = cmd.Flags().GetString("file")
+			opts.Range, _ = cmd.Flags().GetString("range")
+			opts.Type, _ = cmd.Flags().GetString("type")
+			opts.Description, _ = cmd.Flags().GetString("description")
+			cmd.SilenceUsage = true
+			return h.Suggest(cmd.Context(), opts)
+		},
+	}
+	suggestCmd.Flags().String("file", "", "File to suggest changes for")
+	suggestCmd.Flags().String("range", "", "Line range, e.g. 10:40 (default: the whole file)")
+	suggestCmd.Flags().String("type", "improvement", "Kind of suggestion: "+strings.Join(interactive.SuggestionTypeNames(), ", "))
+	suggestCmd.Flags().StringP("description", "m", "", "What you want changed")
+
+	return suggestCmd
+}
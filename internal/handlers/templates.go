@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/templates"
+	"github.com/spf13/cobra"
+)
+
+// TemplatesHandler はセッションテンプレート（.vyb/templates）のハンドラー
+type TemplatesHandler struct {
+	log logger.Logger
+}
+
+// NewTemplatesHandler はテンプレートハンドラーを作成
+func NewTemplatesHandler(log logger.Logger) *TemplatesHandler {
+	return &TemplatesHandler{log: log}
+}
+
+// templatesDir はプロジェクトのテンプレートディレクトリ
+func (h *TemplatesHandler) templatesDir() (string, error) {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return "", fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	return templates.Dir(projectDir), nil
+}
+
+// List はプロジェクトと組み込みのテンプレートを表示
+func (h *TemplatesHandler) List() error {
+	dir, err := h.templatesDir()
+	if err != nil {
+		return err
+	}
+	list, invalid := templates.List(dir)
+	for _, tmpl := range list {
+		fmt.Printf("%-12s %s\n", tmpl.Name, tmpl.Description)
+		details := []string{"source: " + tmpl.Source()}
+		if tmpl.SessionType != "" {
+			details = append(details, "type: "+tmpl.SessionType)
+		}
+		if len(tmpl.RunFirst) > 0 {
+			details = append(details, "runs first: "+strings.Join(tmpl.RunFirst, ", "))
+		}
+		fmt.Printf("\033[38;5;244m%-12s %s\033[0m\n", "", strings.Join(details, " · "))
+	}
+
+	paths := make([]string, 0, len(invalid))
+	for path := range invalid {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("\033[38;5;196m✗ %v\033[0m\n", invalid[path])
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d 件のテンプレート定義が不正です", len(invalid))
+	}
+	return nil
+}
+
+// Show はテンプレートの内容（指示・作業計画・重視するツール・開始時のタスク）を表示
+func (h *TemplatesHandler) Show(name string) error {
+	dir, err := h.templatesDir()
+	if err != nil {
+		return err
+	}
+	tmpl, err := templates.Load(dir, name)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("\033[38;5;27m%s\033[0m  %s\n", tmpl.Name, tmpl.Description)
+	fmt.Printf("\033[38;5;244msource: %s\033[0m\n", tmpl.Source())
+	if tmpl.SessionType != "" {
+		fmt.Printf("session type: %s\n", tmpl.SessionType)
+	}
+	if len(tmpl.RunFirst) > 0 {
+		fmt.Printf("runs first:   %s\n", strings.Join(tmpl.RunFirst, ", "))
+	}
+	if len(tmpl.Tools) > 0 {
+		fmt.Printf("tools:        %s\n", strings.Join(tmpl.Tools, ", "))
+	}
+	if prompt := strings.TrimSpace(tmpl.SystemPrompt); prompt != "" {
+		fmt.Printf("\n%s\n", prompt)
+	}
+	if len(tmpl.Plan) > 0 {
+		fmt.Println("\nPlan:")
+		for i, step := range tmpl.Plan {
+			fmt.Printf("  %d. %s\n", i+1, step)
+		}
+	}
+	return nil
+}
+
+// Init は組み込みテンプレートを .vyb/templates に書き出して編集できるようにする
+func (h *TemplatesHandler) Init(names []string, force bool) error {
+	dir, err := h.templatesDir()
+	if err != nil {
+		return err
+	}
+	written, skipped, err := templates.WriteBuiltin(dir, names, force)
+	for _, path := range written {
+		fmt.Printf("✓ Wrote %s\n", path)
+	}
+	for _, path := range skipped {
+		fmt.Printf("• %s already exists, left unchanged (use --force to overwrite)\n", path)
+	}
+	if err != nil {
+		return err
+	}
+	if len(written) > 0 {
+		fmt.Printf("\nEdit the files and start a session with: vyb --template <name>\n")
+	}
+	return nil
+}
+
+// CreateTemplatesCommands は templates コマンドを作成
+func (h *TemplatesHandler) CreateTemplatesCommands() *cobra.Command {
+	templatesCmd := &cobra.Command{
+		Use:   "templates",
+		Short: "List, show and customize session templates (vyb --template <name>)",
+		Long: `Session templates tailor a session to a kind of task. A template adds instructions and a plan skeleton to every prompt, marks the tools to favour, can switch the session type and can run build, test or lint when the session starts (the bugfix template runs the tests first).
+Built-in templates: bugfix, feature, spike. Project templates live in .vyb/templates/<name>.yaml and take precedence over built-ins with the same name; "vyb templates init" writes the built-ins there for editing.`,
+	}
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List project and built-in templates",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.List()
+		},
+	}
+
+	showCmd := &cobra.Command{
+		Use:   "show <name>",
+		Short: "Show a template's instructions, plan and start-up tasks",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.Show(args[0])
+		},
+	}
+
+	initCmd := &cobra.Command{
+		Use:   "init [name...]",
+		Short: "Copy built-in templates to .vyb/templates for editing (default: all)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			force, _ := cmd.Flags().GetBool("force")
+			cmd.SilenceUsage = true
+			return h.Init(args, force)
+		},
+	}
+	initCmd.Flags().Bool("force", false, "Overwrite existing template files")
+
+	templatesCmd.AddCommand(listCmd, showCmd, initCmd)
+	return templatesCmd
+}
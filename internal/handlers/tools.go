@@ -2,12 +2,15 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/glkt/vyb-code/internal/analysis"
 	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/interactive"
 	"github.com/glkt/vyb-code/internal/logger"
 	"github.com/glkt/vyb-code/internal/security"
 	"github.com/glkt/vyb-code/internal/tools"
@@ -189,14 +192,7 @@ func (h *ToolsHandler) AnalyzeProject(path string) error {
 	}
 
 	// 分析対象パスの決定
-	analyzePath := cfg.WorkspacePath
-	if path != "" {
-		if filepath.IsAbs(path) {
-			analyzePath = path
-		} else {
-			analyzePath = filepath.Join(cfg.WorkspacePath, path)
-		}
-	}
+	analyzePath := resolveAnalyzePath(cfg, path)
 
 	// セキュリティ制約設定
 	constraints := &security.Constraints{
@@ -233,6 +229,75 @@ func (h *ToolsHandler) AnalyzeProject(path string) error {
 	return nil
 }
 
+// AnalyzeProjectJSON はプロジェクト分析と未コミットの変更の分析をJSONで出力（外部ツール連携用）
+func (h *ToolsHandler) AnalyzeProjectJSON(path string) error {
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	analyzePath, err := filepath.Abs(resolveAnalyzePath(cfg, path))
+	if err != nil {
+		return fmt.Errorf("パス解決エラー: %w", err)
+	}
+
+	// 分析中の出力がJSONに混ざらないよう、処理中はstdoutをstderrへ退避
+	out := os.Stdout
+	os.Stdout = os.Stderr
+	// プロジェクト分析はLLMを使わない
+	report := interactive.BuildAnalysisReport(context.Background(), analysis.NewUnifiedAnalyzer(cfg, nil), analyzePath, "")
+	os.Stdout = out
+
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("分析結果のJSON変換エラー: %w", err)
+	}
+	fmt.Fprintln(out, string(data))
+	return nil
+}
+
+// AnalyzeDiagram はパッケージの依存関係（calls なら呼び出している関数も）を Mermaid・DOT の図として出力
+func (h *ToolsHandler) AnalyzeDiagram(path, format string, opts analysis.DiagramOptions) error {
+	if !containsValue(analysis.ValidDiagramFormats(), format) {
+		return fmt.Errorf("無効な図の形式です: %s（%s のいずれかを指定してください）", format, strings.Join(analysis.ValidDiagramFormats(), ", "))
+	}
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	analyzePath, err := filepath.Abs(resolveAnalyzePath(cfg, path))
+	if err != nil {
+		return fmt.Errorf("パス解決エラー: %w", err)
+	}
+
+	diagram, err := analysis.BuildArchitectureDiagram(context.Background(), analyzePath, opts)
+	if err != nil {
+		return err
+	}
+	rendered, err := diagram.Render(format)
+	if err != nil {
+		return err
+	}
+	h.log.Debug("アーキテクチャ図を作成しました", map[string]interface{}{
+		"path":   analyzePath,
+		"format": format,
+		"nodes":  len(diagram.Nodes),
+		"edges":  len(diagram.Edges),
+	})
+	fmt.Print(rendered)
+	return nil
+}
+
+// resolveAnalyzePath は分析対象のパス（相対パスはワークスペース基準、省略時はワークスペース）
+func resolveAnalyzePath(cfg *config.Config, path string) string {
+	if path == "" {
+		return cfg.WorkspacePath
+	}
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(cfg.WorkspacePath, path)
+}
+
 // QuickGitStatus はGitステータスの簡易表示
 func (h *ToolsHandler) QuickGitStatus() error {
 	h.log.Info("Gitステータス機能実行", nil)
@@ -491,9 +556,24 @@ func (h *ToolsHandler) CreateToolCommands() []*cobra.Command {
 			if len(args) > 0 {
 				path = args[0]
 			}
+			if cmd.Flags().Changed("diagram") {
+				format, _ := cmd.Flags().GetString("diagram")
+				calls, _ := cmd.Flags().GetBool("calls")
+				depth, _ := cmd.Flags().GetInt("depth")
+				cmd.SilenceUsage = true
+				return h.AnalyzeDiagram(path, format, analysis.DiagramOptions{Calls: calls, Depth: depth})
+			}
+			if asJSON, _ := cmd.Flags().GetBool("json"); asJSON {
+				return h.AnalyzeProjectJSON(path)
+			}
 			return h.AnalyzeProject(path)
 		},
 	}
+	analyzeCmd.Flags().Bool("json", false, "Print the structured analysis report as JSON")
+	analyzeCmd.Flags().String("diagram", "", "Print the package dependency graph as a diagram (mermaid or dot), e.g. --diagram > arch.mmd")
+	analyzeCmd.Flags().Lookup("diagram").NoOptDefVal = analysis.DiagramMermaid
+	analyzeCmd.Flags().Bool("calls", false, "With --diagram, label edges with the functions called across packages (Go)")
+	analyzeCmd.Flags().Int("depth", 0, "With --diagram, group directories at this depth from the root (0 for one node per package)")
 
 	// s コマンド (git status shortcut)
 	statusCmd := &cobra.Command{
@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/usage"
+	"github.com/spf13/cobra"
+)
+
+// UsageHandler はトークン使用量・コスト表示のハンドラー
+type UsageHandler struct {
+	log      logger.Logger
+	path     string
+	currency string
+}
+
+// NewUsageHandler は使用量ハンドラーの新しいインスタンスを作成
+func NewUsageHandler(log logger.Logger, cfg config.UsageConfig) *UsageHandler {
+	return &UsageHandler{log: log, path: usage.DefaultPath(), currency: cfg.Currency}
+}
+
+// ShowUsage は期間内の使用量を指定単位で集計して表示
+func (h *UsageHandler) ShowUsage(by usage.GroupBy, days int, sessionID string, asJSON bool) error {
+	valid := false
+	for _, groupBy := range usage.ValidGroupBy() {
+		if by == groupBy {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return fmt.Errorf("無効な集計単位です。session, day, model のいずれかを指定してください")
+	}
+
+	var since time.Time
+	if days > 0 {
+		now := time.Now()
+		since = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).AddDate(0, 0, -(days - 1))
+	}
+
+	records, err := usage.Load(h.path, since)
+	if err != nil {
+		return err
+	}
+	if sessionID != "" {
+		records = usage.FilterSession(records, sessionID)
+	}
+
+	summaries := usage.Aggregate(records, by)
+	total := usage.Total(records)
+
+	if asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(map[string]interface{}{
+			"group_by": by,
+			"currency": h.currency,
+			"groups":   summaries,
+			"total":    total,
+		})
+	}
+
+	if len(records) == 0 {
+		fmt.Printf("No usage recorded (%s)\n", h.path)
+		return nil
+	}
+
+	fmt.Printf("%-36s %8s %12s %12s %16s\n", by, "Requests", "Prompt", "Completion", "Cost")
+	for _, summary := range summaries {
+		fmt.Printf("%-36s %8d %12d %12d %16s\n", summary.Key, summary.Requests, summary.PromptTokens, summary.CompletionTokens, usage.FormatCost(summary.Cost, h.currency))
+	}
+	fmt.Printf("%-36s %8d %12d %12d %16s\n", "Total", total.Requests, total.PromptTokens, total.CompletionTokens, usage.FormatCost(total.Cost, h.currency))
+	if total.Estimated > 0 {
+		fmt.Printf("\n* %d request(s) have token counts estimated from text length\n", total.Estimated)
+	}
+	return nil
+}
+
+// CreateUsageCommands は使用量関連のコマンドを作成
+func (h *UsageHandler) CreateUsageCommands() *cobra.Command {
+	usageCmd := &cobra.Command{
+		Use:   "usage",
+		Short: "Show token usage and cost per day, model or session",
+		Long:  `Aggregate prompt/completion tokens recorded for every LLM request. Counts reported by the provider are used when available; otherwise they are estimated from text length. Costs use the per-model prices set with "vyb config set-model-price".`,
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			by, _ := cmd.Flags().GetString("by")
+			days, _ := cmd.Flags().GetInt("days")
+			sessionID, _ := cmd.Flags().GetString("session")
+			asJSON, _ := cmd.Flags().GetBool("json")
+			return h.ShowUsage(usage.GroupBy(by), days, sessionID, asJSON)
+		},
+	}
+	usageCmd.Flags().String("by", string(usage.ByDay), "Group by: day, model or session")
+	usageCmd.Flags().Int("days", 30, "Only include the last N days (0 for all)")
+	usageCmd.Flags().String("session", "", "Only include the given session")
+	usageCmd.Flags().Bool("json", false, "Print the summary as JSON")
+
+	return usageCmd
+}
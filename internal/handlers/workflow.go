@@ -0,0 +1,256 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/plugins"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/workflow"
+	"github.com/spf13/cobra"
+)
+
+// WorkflowHandler は .vyb/workflows のワークフロー実行のハンドラー
+type WorkflowHandler struct {
+	log logger.Logger
+}
+
+// NewWorkflowHandler はワークフローハンドラーを作成
+func NewWorkflowHandler(log logger.Logger) *WorkflowHandler {
+	return &WorkflowHandler{log: log}
+}
+
+// WorkflowRunOptions は workflow run の指定内容
+type WorkflowRunOptions struct {
+	Profile string
+	Inputs  []string // name=value
+	JSON    bool
+}
+
+// Run はワークフローを読み込んで実行し、ステップ毎の結果を表示
+func (h *WorkflowHandler) Run(ctx context.Context, name string, opts WorkflowRunOptions) error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	def, err := workflow.Load(workflow.Dir(projectDir), name)
+	if err != nil {
+		return err
+	}
+	inputs, err := parseWorkflowInputs(opts.Inputs)
+	if err != nil {
+		return err
+	}
+
+	resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile(opts.Profile)})
+	if err != nil {
+		return fmt.Errorf("設定読み込みエラー: %w", err)
+	}
+	cfg := resolved.Config
+
+	backend, err := sandbox.New(cfg.Sandbox)
+	if err != nil {
+		return fmt.Errorf("実行環境の初期化エラー: %w", err)
+	}
+	registry := tools.NewUnifiedToolRegistry(security.NewDefaultConstraints(projectDir), nil)
+	registry.SetExecutionBackend(backend)
+	networkPolicy := security.NewNetworkPolicy(cfg.Network.Mode, cfg.Network.AllowedDomains, cfg.Network.BlockedDomains)
+	networkPolicy.SetAllowLoopback(cfg.Network.AllowLoopback)
+	registry.SetNetworkPolicy(networkPolicy)
+	if err := registry.ConfigureWebTools(cfg.WebTools); err != nil {
+		fmt.Fprintf(os.Stderr, "\033[38;5;214m⚠️  Webツール設定エラー: %v\033[0m\n", err)
+	}
+	// 拡張のツールもステップから呼び出せるようにする
+	extensions := plugins.LoadExtensions(&plugins.Host{Bus: events.Default(), Tools: registry}, cfg.Extensions, projectDir)
+	defer extensions.Close()
+	for name, err := range extensions.Errors {
+		fmt.Fprintf(os.Stderr, "\033[38;5;214m⚠️  拡張 %s の読み込みエラー: %v\033[0m\n", name, err)
+	}
+
+	memory, _ := config.LoadProjectMemory("")
+	engine := &workflow.Engine{
+		Provider: newLLMProvider(cfg),
+		Model:    cfg.ResolvedModel(),
+		Memory:   memory,
+		Tools:    registry,
+	}
+	if !opts.JSON {
+		fmt.Printf("▶️  %s", def.Name)
+		if def.Description != "" {
+			fmt.Printf(" — %s", def.Description)
+		}
+		fmt.Println()
+		engine.Progress = printWorkflowStep
+	}
+
+	result, runErr := engine.Run(ctx, def, inputs)
+	if result == nil {
+		return runErr
+	}
+	h.log.Info("Workflow completed", map[string]interface{}{
+		"workflow": def.Name,
+		"success":  result.Success,
+		"steps":    len(result.Steps),
+	})
+
+	if opts.JSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(result); err != nil {
+			return err
+		}
+		return runErr
+	}
+
+	// 最後に成功した prompt ステップの回答を結果として表示
+	for i := len(result.Steps) - 1; i >= 0; i-- {
+		step := result.Steps[i]
+		if step.Kind == workflow.StepPrompt && step.Status == workflow.StatusSucceeded {
+			fmt.Printf("\n%s\n", step.Output)
+			break
+		}
+	}
+	if result.Success {
+		fmt.Printf("\n\033[38;5;46m✅ %s completed\033[0m (%s)\n", def.Name, result.Duration.Round(time.Millisecond))
+	}
+	return runErr
+}
+
+// printWorkflowStep はステップの終了を1行で表示（失敗時は出力の末尾も表示）
+func printWorkflowStep(result *workflow.StepResult) {
+	indent := strings.Repeat("  ", result.Depth+1)
+	switch result.Status {
+	case workflow.StatusRunning:
+		if result.Kind == workflow.StepCondition || result.Kind == workflow.StepLoop {
+			fmt.Printf("%s\033[38;5;27m%s\033[0m\n", indent, result.Label)
+		}
+	case workflow.StatusSucceeded:
+		fmt.Printf("%s\033[38;5;46m✓\033[0m %s \033[38;5;244m(%s)\033[0m\n", indent, result.Label, result.Duration.Round(time.Millisecond))
+	case workflow.StatusSkipped:
+		fmt.Printf("%s\033[38;5;244m- %s (skipped)\033[0m\n", indent, result.Label)
+	case workflow.StatusFailed:
+		fmt.Printf("%s\033[38;5;196m✗ %s: %s\033[0m\n", indent, result.Label, result.Error)
+		if output := strings.TrimSpace(result.Output); output != "" {
+			lines := strings.Split(output, "\n")
+			if len(lines) > 10 {
+				lines = lines[len(lines)-10:]
+			}
+			fmt.Println(indentLines(strings.Join(lines, "\n"), indent+"  "))
+		}
+	}
+}
+
+// parseWorkflowInputs は name=value 形式の入力を解析
+func parseWorkflowInputs(values []string) (map[string]string, error) {
+	inputs := make(map[string]string)
+	for _, value := range values {
+		name, val, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("入力は name=value 形式で指定してください: %s", value)
+		}
+		inputs[strings.TrimSpace(name)] = val
+	}
+	return inputs, nil
+}
+
+// List はプロジェクトのワークフロー一覧を表示
+func (h *WorkflowHandler) List() error {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	dir := workflow.Dir(projectDir)
+	defs, invalid := workflow.List(dir)
+	if len(defs) == 0 && len(invalid) == 0 {
+		fmt.Printf("No workflows found in %s\n", dir)
+		return nil
+	}
+
+	for _, def := range defs {
+		fmt.Printf("%-20s %d step(s)", def.Name, len(def.Steps))
+		if def.Description != "" {
+			fmt.Printf("  %s", def.Description)
+		}
+		fmt.Println()
+		if len(def.Inputs) > 0 {
+			names := make([]string, 0, len(def.Inputs))
+			for name, fallback := range def.Inputs {
+				if fallback == "" {
+					name += " (required)"
+				} else {
+					name += "=" + fallback
+				}
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			fmt.Printf("\033[38;5;244m%-20s inputs: %s\033[0m\n", "", strings.Join(names, ", "))
+		}
+	}
+
+	paths := make([]string, 0, len(invalid))
+	for path := range invalid {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Printf("\033[38;5;196m✗ %v\033[0m\n", invalid[path])
+	}
+	if len(invalid) > 0 {
+		return fmt.Errorf("%d 件のワークフロー定義が不正です", len(invalid))
+	}
+	return nil
+}
+
+// CreateWorkflowCommands はworkflowコマンドを作成
+func (h *WorkflowHandler) CreateWorkflowCommands() *cobra.Command {
+	workflowCmd := &cobra.Command{
+		Use:   "workflow",
+		Short: "Run multi-step workflows defined in .vyb/workflows",
+	}
+
+	runCmd := &cobra.Command{
+		Use:   "run <name|file.yaml>",
+		Short: "Run a workflow",
+		Long: `Run a workflow defined in .vyb/workflows/<name>.yaml. Steps run in order and are one of:
+  prompt:    ask the model (the conversation continues across prompt steps)
+  tool:      run a tool (bash, read, write, edit, grep, glob, ...) with parameters in "with"
+  condition: run "then" or "else" steps depending on an expression
+  loop:      repeat "steps" over "items" or until an expression is true (at most "max" times)
+Strings are Go templates: {{.inputs.name}}, {{.steps.<id>.output}}, {{.steps.<id>.success}}, {{.item}}.
+A failing step stops the workflow unless it sets continue_on_error.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var opts WorkflowRunOptions
+			opts.Profile, _ = cmd.Flags().GetString("profile")
+			opts.Inputs, _ = cmd.Flags().GetStringArray("input")
+			opts.JSON, _ = cmd.Flags().GetBool("json")
+			cmd.SilenceUsage = true
+			return h.Run(cmd.Context(), args[0], opts)
+		},
+	}
+	runCmd.Flags().StringArrayP("input", "i", nil, "Workflow input as name=value (repeatable)")
+	runCmd.Flags().Bool("json", false, "Output the step results as JSON")
+
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List workflows and their inputs",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cmd.SilenceUsage = true
+			return h.List()
+		},
+	}
+
+	workflowCmd.AddCommand(runCmd, listCmd)
+	return workflowCmd
+}
@@ -0,0 +1,93 @@
+package history
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// 引用時に1つの応答から取り込む最大文字数
+const quoteMaxRunes = 4000
+
+// Exchange は過去のセッションの1ターン（ユーザー入力と応答）
+type Exchange struct {
+	SessionID string    `json:"session_id"`
+	Turn      int       `json:"turn"`
+	Timestamp time.Time `json:"timestamp"`
+	User      string    `json:"user"`
+	Assistant string    `json:"assistant"`
+}
+
+// Exchanges は会話記録をターン毎の入力・応答に分ける
+func Exchanges(record *transcript.Transcript) []Exchange {
+	var exchanges []Exchange
+	for _, entry := range record.Entries {
+		switch entry.Kind {
+		case transcript.KindUser:
+			exchanges = append(exchanges, Exchange{
+				SessionID: record.SessionID,
+				Turn:      len(exchanges) + 1,
+				Timestamp: entry.Timestamp,
+				User:      entry.Content,
+			})
+		case transcript.KindAssistant:
+			if len(exchanges) == 0 {
+				continue
+			}
+			last := &exchanges[len(exchanges)-1]
+			if last.Assistant != "" {
+				last.Assistant += "\n\n"
+			}
+			last.Assistant += entry.Content
+		}
+	}
+	return exchanges
+}
+
+// LoadExchanges は監査ログからセッションの全ターンを読み込む
+func LoadExchanges(dir, sessionID string) ([]Exchange, error) {
+	events, err := logger.ReadAuditLog(dir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	exchanges := Exchanges(transcript.FromAuditEvents(sessionID, events))
+	if len(exchanges) == 0 {
+		return nil, fmt.Errorf("セッションに会話の記録がありません: %s", sessionID)
+	}
+	return exchanges, nil
+}
+
+// LoadExchange は監査ログから指定ターンを読み込む
+func LoadExchange(dir, sessionID string, turn int) (*Exchange, error) {
+	exchanges, err := LoadExchanges(dir, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if turn < 1 || turn > len(exchanges) {
+		return nil, fmt.Errorf("ターン %d はありません（1〜%d）: %s", turn, len(exchanges), sessionID)
+	}
+	return &exchanges[turn-1], nil
+}
+
+// Quote は現在のセッションのコンテキストに取り込むための引用文
+func (e Exchange) Quote() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("過去のセッション %s のターン %d（%s）からの引用:\n", e.SessionID, e.Turn, e.Timestamp.Format("2006-01-02 15:04")))
+	sb.WriteString("ユーザー: " + strings.TrimSpace(e.User) + "\n")
+	if e.Assistant != "" {
+		sb.WriteString("アシスタント: " + truncateRunes(strings.TrimSpace(e.Assistant), quoteMaxRunes) + "\n")
+	}
+	return sb.String()
+}
+
+// truncateRunes は文字数で切り詰める
+func truncateRunes(text string, maxRunes int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "...(truncated)"
+}
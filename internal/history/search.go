@@ -0,0 +1,332 @@
+package history
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// BM25のパラメータ
+const (
+	bm25K1 = 1.2
+	bm25B  = 0.75
+)
+
+// 検索結果の抜粋の前後文字数
+const snippetRadius = 60
+
+// Document は検索対象の1単位（セッション内の1項目）
+type Document struct {
+	SessionID string          `json:"session_id"`
+	Turn      int             `json:"turn"` // 何番目のユーザー入力に属するか（1始まり、入力前の項目は0）
+	Kind      transcript.Kind `json:"kind"`
+	Timestamp time.Time       `json:"timestamp"`
+	Label     string          `json:"label,omitempty"` // ツール名・コマンド等
+	Text      string          `json:"text"`
+}
+
+// Hit は検索にマッチしたターン
+type Hit struct {
+	Document
+	Score   float64 `json:"score"`
+	Snippet string  `json:"snippet"`
+}
+
+// SearchOptions は検索条件
+type SearchOptions struct {
+	Limit     int    // 最大件数（0なら20）
+	SessionID string // 特定セッションに限定
+}
+
+// Index は過去の会話の転置インデックス（メモリ上）
+type Index struct {
+	docs      []Document
+	lengths   []int
+	postings  map[string]map[int]int // term -> doc -> 出現回数
+	avgLength float64
+}
+
+// NewIndex は文書から転置インデックスを作成
+func NewIndex(docs []Document) *Index {
+	index := &Index{
+		docs:     docs,
+		lengths:  make([]int, len(docs)),
+		postings: make(map[string]map[int]int),
+	}
+	total := 0
+	for i, doc := range docs {
+		terms := Tokenize(doc.Text + " " + doc.Label)
+		index.lengths[i] = len(terms)
+		total += len(terms)
+		for _, term := range terms {
+			if index.postings[term] == nil {
+				index.postings[term] = make(map[int]int)
+			}
+			index.postings[term][i]++
+		}
+	}
+	if len(docs) > 0 {
+		index.avgLength = float64(total) / float64(len(docs))
+	}
+	return index
+}
+
+// Load は監査ログディレクトリの全セッションからインデックスを作成
+func Load(dir string) (*Index, error) {
+	sessions, err := logger.ListAuditSessions(dir)
+	if err != nil {
+		return nil, fmt.Errorf("監査ログ一覧取得エラー: %w", err)
+	}
+	var docs []Document
+	for _, session := range sessions {
+		events, err := logger.ReadAuditLog(dir, session.SessionID)
+		if err != nil {
+			continue
+		}
+		docs = append(docs, Documents(transcript.FromAuditEvents(session.SessionID, events))...)
+	}
+	return NewIndex(docs), nil
+}
+
+// Documents は会話記録を検索対象の文書に分割
+func Documents(record *transcript.Transcript) []Document {
+	docs := make([]Document, 0, len(record.Entries))
+	turn := 0
+	for _, entry := range record.Entries {
+		if entry.Kind == transcript.KindUser {
+			turn++
+		}
+		text := entry.Content
+		if entry.Error != "" {
+			text += "\n" + entry.Error
+		}
+		label := entry.Tool
+		if entry.Command != "" {
+			label = strings.TrimSpace(label + " " + entry.Command)
+		} else if entry.Path != "" {
+			label = strings.TrimSpace(label + " " + entry.Path)
+		}
+		if strings.TrimSpace(text) == "" && label == "" {
+			continue
+		}
+		docs = append(docs, Document{
+			SessionID: record.SessionID,
+			Turn:      turn,
+			Kind:      entry.Kind,
+			Timestamp: entry.Timestamp,
+			Label:     label,
+			Text:      text,
+		})
+	}
+	return docs
+}
+
+// Len はインデックス済みの文書数
+func (idx *Index) Len() int {
+	return len(idx.docs)
+}
+
+// Search はBM25でスコア付けし、全ての語を含む文書をターン単位で返す（新しい順の同点解決）
+// クエリ全体がそのまま出現する文書はスコアを上げる
+func (idx *Index) Search(query string, opts SearchOptions) []Hit {
+	terms := uniqueTerms(Tokenize(query))
+	if len(terms) == 0 {
+		return nil
+	}
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = 20
+	}
+
+	// 全ての語を含む文書のみ（AND検索）
+	var candidates map[int]bool
+	for _, term := range terms {
+		matched := make(map[int]bool)
+		for doc := range idx.postings[term] {
+			if candidates == nil || candidates[doc] {
+				matched[doc] = true
+			}
+		}
+		candidates = matched
+		if len(candidates) == 0 {
+			return nil
+		}
+	}
+
+	phrase := strings.ToLower(strings.TrimSpace(query))
+	best := make(map[string]*Hit)
+	for doc := range candidates {
+		document := idx.docs[doc]
+		if opts.SessionID != "" && document.SessionID != opts.SessionID {
+			continue
+		}
+
+		score := 0.0
+		for _, term := range terms {
+			score += idx.termScore(term, doc)
+		}
+		lower := strings.ToLower(document.Text)
+		if strings.Contains(lower, phrase) {
+			score *= 1.5
+		}
+
+		key := fmt.Sprintf("%s#%d", document.SessionID, document.Turn)
+		if current, exists := best[key]; exists && current.Score >= score {
+			continue
+		}
+		best[key] = &Hit{Document: document, Score: score, Snippet: Snippet(document.Text, query)}
+	}
+
+	hits := make([]Hit, 0, len(best))
+	for _, hit := range best {
+		hits = append(hits, *hit)
+	}
+	sort.Slice(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		return hits[i].Timestamp.After(hits[j].Timestamp)
+	})
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits
+}
+
+// termScore は1語のBM25スコア
+func (idx *Index) termScore(term string, doc int) float64 {
+	postings := idx.postings[term]
+	frequency := float64(postings[doc])
+	if frequency == 0 {
+		return 0
+	}
+	n := float64(len(idx.docs))
+	df := float64(len(postings))
+	idf := math.Log(1 + (n-df+0.5)/(df+0.5))
+	norm := 1 - bm25B + bm25B*float64(idx.lengths[doc])/math.Max(idx.avgLength, 1)
+	return idf * frequency * (bm25K1 + 1) / (frequency + bm25K1*norm)
+}
+
+// Tokenize は検索語に分割（英数字は小文字の単語、日本語等は2文字ずつのN-gram）
+func Tokenize(text string) []string {
+	var terms []string
+	var word []rune
+	var cjk []rune
+
+	flushWord := func() {
+		if len(word) > 0 {
+			terms = append(terms, string(word))
+			word = word[:0]
+		}
+	}
+	flushCJK := func() {
+		switch {
+		case len(cjk) == 1:
+			terms = append(terms, string(cjk))
+		case len(cjk) > 1:
+			for i := 0; i+1 < len(cjk); i++ {
+				terms = append(terms, string(cjk[i:i+2]))
+			}
+		}
+		cjk = cjk[:0]
+	}
+
+	for _, r := range text {
+		switch {
+		case isCJK(r):
+			flushWord()
+			cjk = append(cjk, r)
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_':
+			flushCJK()
+			word = append(word, unicode.ToLower(r))
+		default:
+			flushWord()
+			flushCJK()
+		}
+	}
+	flushWord()
+	flushCJK()
+	return terms
+}
+
+// isCJK は単語区切りのない文字（漢字・かな・ハングル）か
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul) || r == 'ー'
+}
+
+// uniqueTerms は重複を除いた語
+func uniqueTerms(terms []string) []string {
+	seen := make(map[string]bool, len(terms))
+	unique := make([]string, 0, len(terms))
+	for _, term := range terms {
+		if !seen[term] {
+			seen[term] = true
+			unique = append(unique, term)
+		}
+	}
+	return unique
+}
+
+// Snippet はクエリ（なければ最初の語）の出現箇所周辺を1行に整形
+func Snippet(text, query string) string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		lower = runes // 小文字化で文字数が変わる場合は位置がずれないよう元の文字列で探す
+	}
+
+	position := indexRunes(lower, []rune(strings.ToLower(strings.TrimSpace(query))))
+	if position < 0 {
+		for _, term := range Tokenize(query) {
+			if position = indexRunes(lower, []rune(term)); position >= 0 {
+				break
+			}
+		}
+	}
+	if position < 0 {
+		position = 0
+	}
+
+	start := position - snippetRadius
+	if start < 0 {
+		start = 0
+	}
+	end := position + snippetRadius
+	if end > len(runes) {
+		end = len(runes)
+	}
+	snippet := strings.Join(strings.Fields(string(runes[start:end])), " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(runes) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// indexRunes はルーン単位の部分列検索（見つからなければ-1）
+func indexRunes(text, pattern []rune) int {
+	if len(pattern) == 0 {
+		return -1
+	}
+	for i := 0; i+len(pattern) <= len(text); i++ {
+		match := true
+		for j := range pattern {
+			if text[i+j] != pattern[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,117 @@
+package history
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// recordSession は監査ログにセッションの会話を記録
+func recordSession(t *testing.T, dir, sessionID string, start time.Time, turns [][2]string) {
+	t.Helper()
+	recorder := logger.NewAuditRecorder(dir, 0)
+	defer recorder.Close()
+	for i, turn := range turns {
+		at := start.Add(time.Duration(i) * time.Minute)
+		for _, event := range []logger.AuditEvent{
+			{SessionID: sessionID, Timestamp: at, Type: logger.AuditUserInput, Content: turn[0], Success: true},
+			{SessionID: sessionID, Timestamp: at.Add(time.Second), Type: logger.AuditAssistant, Content: turn[1], Success: true},
+		} {
+			if err := recorder.Record(event); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+}
+
+func TestTokenize(t *testing.T) {
+	got := strings.Join(Tokenize("Fix the Race_Condition in 競合状態!"), ",")
+	if want := "fix,the,race_condition,in,競合,合状,状態"; got != want {
+		t.Errorf("Tokenize = %s, want %s", got, want)
+	}
+}
+
+func TestSearchAcrossSessions(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+	recordSession(t, dir, "old", base, [][2]string{
+		{"why does the test hang?", "There is a race condition in the worker pool: the channel is closed twice."},
+		{"add a README", "Done."},
+	})
+	recordSession(t, dir, "new", base.Add(24*time.Hour), [][2]string{
+		{"データ競合を調べて", "sync.Mutex で保護してください。race condition ではありません。"},
+	})
+
+	index, err := Load(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if index.Len() != 6 {
+		t.Fatalf("indexed %d documents, want 6", index.Len())
+	}
+
+	hits := index.Search("race condition", SearchOptions{})
+	if len(hits) != 2 {
+		t.Fatalf("hits = %+v", hits)
+	}
+	for _, hit := range hits {
+		if !strings.Contains(strings.ToLower(hit.Snippet), "race condition") {
+			t.Errorf("snippet should contain the query: %q", hit.Snippet)
+		}
+	}
+	if hits[0].Turn != 1 || hits[0].Kind != transcript.KindAssistant {
+		t.Errorf("unexpected top hit: %+v", hits[0])
+	}
+
+	// 日本語はN-gramで部分一致
+	if hits := index.Search("競合", SearchOptions{}); len(hits) != 1 || hits[0].SessionID != "new" {
+		t.Errorf("japanese search: %+v", hits)
+	}
+	// セッションで絞り込み・全語を含まない文書は対象外
+	if hits := index.Search("race", SearchOptions{SessionID: "new"}); len(hits) != 1 {
+		t.Errorf("session filter: %+v", hits)
+	}
+	if hits := index.Search("race README", SearchOptions{}); len(hits) != 0 {
+		t.Errorf("all terms must match in one entry: %+v", hits)
+	}
+	if hits := index.Search("  ", SearchOptions{}); hits != nil {
+		t.Errorf("empty query: %+v", hits)
+	}
+}
+
+func TestLoadExchangeQuote(t *testing.T) {
+	dir := t.TempDir()
+	recordSession(t, dir, "s1", time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC), [][2]string{
+		{"first question", "first answer"},
+		{"second question", "second answer"},
+	})
+
+	exchange, err := LoadExchange(dir, "s1", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	quote := exchange.Quote()
+	for _, want := range []string{"s1", "ターン 2", "second question", "second answer"} {
+		if !strings.Contains(quote, want) {
+			t.Errorf("quote missing %q:\n%s", want, quote)
+		}
+	}
+
+	if _, err := LoadExchange(dir, "s1", 3); err == nil {
+		t.Error("out of range turn should fail")
+	}
+	if _, err := LoadExchange(dir, "missing", 1); err == nil {
+		t.Error("unknown session should fail")
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	text := strings.Repeat("a ", 100) + "needle here " + strings.Repeat("b ", 100)
+	snippet := Snippet(text, "Needle")
+	if !strings.HasPrefix(snippet, "…") || !strings.HasSuffix(snippet, "…") || !strings.Contains(snippet, "needle here") {
+		t.Errorf("unexpected snippet: %q", snippet)
+	}
+}
@@ -0,0 +1,177 @@
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/markdown"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+const (
+	// AllowSecretComment を含む行はシークレット検出の対象外（テスト用のダミー値等）
+	AllowSecretComment = "vyb:allow-secret"
+	// maxScanBytes はシークレット検出で読むファイルサイズの上限
+	maxScanBytes = 1024 * 1024
+	// maxDiffBytes はコミットメッセージ提案でモデルに渡す差分の上限
+	maxDiffBytes = 24 * 1024
+)
+
+// secretPatterns はコミットを止めるシークレットのパターン（誤検出の多い汎用パターンは含めない）
+var secretPatterns = []struct {
+	kind    string
+	pattern *regexp.Regexp
+}{
+	{"AWS Access Key", regexp.MustCompile(`AKIA[0-9A-Z]{16}`)},
+	{"GitHub Token", regexp.MustCompile(`gh[pousr]_[A-Za-z0-9]{36,}`)},
+	{"Slack Token", regexp.MustCompile(`xox[baprs]-[A-Za-z0-9-]{10,}`)},
+	{"Private Key", regexp.MustCompile(`-----BEGIN ((RSA|EC|DSA|OPENSSH|PGP) )?PRIVATE KEY( BLOCK)?-----`)},
+	{"API Key", regexp.MustCompile(`(?i)(api[_-]?key|secret[_-]?key|access[_-]?token)\s*[:=]\s*["'][A-Za-z0-9_\-/+=]{20,}["']`)},
+	{"Password", regexp.MustCompile(`(?i)(password|passwd)\s*[:=]\s*["'][^"'\s]{8,}["']`)},
+}
+
+// Finding はステージ済みファイルで見つかったシークレット
+type Finding struct {
+	File string `json:"file"`
+	Line int    `json:"line"`
+	Kind string `json:"kind"`
+}
+
+// StagedFiles はコミットされる（追加・変更・名前変更された）ファイル
+func StagedFiles(ctx context.Context, repoDir string) ([]string, error) {
+	output, err := gitexec.Run(ctx, repoDir, "diff", "--cached", "--name-only", "--diff-filter=ACMR", "-z")
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, name := range strings.Split(output, "\x00") {
+		if name != "" {
+			files = append(files, name)
+		}
+	}
+	return files, nil
+}
+
+// StagedDiff はステージ済みの変更の差分
+func StagedDiff(ctx context.Context, repoDir string) (string, error) {
+	return gitexec.Run(ctx, repoDir, "diff", "--cached", "--no-color", "--no-ext-diff")
+}
+
+// ScanStaged はステージ済みの内容（作業ツリーではなくインデックス）からシークレットを探す
+func ScanStaged(ctx context.Context, repoDir string) ([]Finding, error) {
+	files, err := StagedFiles(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	var findings []Finding
+	for _, file := range files {
+		content, err := gitexec.Run(ctx, repoDir, "show", ":"+file)
+		if err != nil || len(content) > maxScanBytes || strings.IndexByte(content, 0) >= 0 {
+			continue
+		}
+		findings = append(findings, ScanSecrets(file, content)...)
+	}
+	return findings, nil
+}
+
+// ScanSecrets は content からシークレットらしい値を探す（1行につき1件）
+func ScanSecrets(file, content string) []Finding {
+	var findings []Finding
+	for i, line := range strings.Split(content, "\n") {
+		if strings.Contains(line, AllowSecretComment) {
+			continue
+		}
+		for _, secret := range secretPatterns {
+			if secret.pattern.MatchString(line) {
+				findings = append(findings, Finding{File: file, Line: i + 1, Kind: secret.kind})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// CleanMessage はコミットメッセージファイルからコメント行・末尾の空行を除く
+// git commit -v の差分（scissors 行以降）も除く
+func CleanMessage(raw string) string {
+	var lines []string
+	for _, line := range strings.Split(raw, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, strings.TrimRight(line, " \t\r"))
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// weakSubjects は内容を表さないコミットメッセージ
+var weakSubjects = map[string]bool{
+	"wip": true, "fix": true, "fixes": true, "update": true, "updates": true, "changes": true,
+	"change": true, "tmp": true, "temp": true, "test": true, "misc": true, "stuff": true, ".": true,
+	"修正": true, "更新": true, "変更": true,
+}
+
+// IsWeakMessage はコミットメッセージが変更内容を表していないか（空、短すぎる、定型句のみ）
+// マージ・fixup・squash 等 git が生成するメッセージは対象外
+func IsWeakMessage(message string) bool {
+	subject := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	for _, prefix := range []string{"Merge ", "Revert ", "fixup! ", "squash! ", "amend! "} {
+		if strings.HasPrefix(subject, prefix) {
+			return false
+		}
+	}
+	normalized := strings.ToLower(strings.TrimRight(subject, ".!"))
+	return len([]rune(subject)) < 8 || weakSubjects[normalized]
+}
+
+// Suggester はステージ済みの差分からコミットメッセージを提案する
+type Suggester struct {
+	Provider llm.Provider
+	Model    string
+	Language string
+	Memory   string // プロジェクトメモリ（VYB.md）
+	Registry *prompts.Registry
+}
+
+// Suggest は差分と作者のメッセージ（空でもよい）からコミットメッセージを生成
+func (s *Suggester) Suggest(ctx context.Context, diff, message string) (string, error) {
+	registry := s.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	if len(diff) > maxDiffBytes {
+		diff = diff[:maxDiffBytes] + "\n... (truncated)"
+	}
+	prompt, err := registry.Render(prompts.TemplateCommitMsg, prompts.Data{
+		Language:    s.Language,
+		ModelFamily: prompts.ModelFamily(s.Model),
+		Memory:      s.Memory,
+		LastOutput:  message,
+		Input:       diff,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	temperature := 0.2
+	resp, err := s.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       s.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+	suggestion := strings.TrimSpace(markdown.StripCodeFence(resp.Message.Content))
+	if suggestion == "" {
+		return "", fmt.Errorf("モデルがコミットメッセージを返しませんでした")
+	}
+	return suggestion, nil
+}
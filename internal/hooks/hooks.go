@@ -0,0 +1,157 @@
+// Package hooks は vyb のチェックを実行するgitフック（pre-commit, commit-msg）の導入・削除と、
+// フックから呼ばれるチェック（リントの要約、シークレット検出、コミットメッセージ提案）を提供する
+package hooks
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// Marker は vyb が書いたフックであることを示す行
+	Marker = "# vyb-managed hook"
+	// SkipEnv はフックのチェックを飛ばす環境変数（VYB_SKIP_HOOKS=1 git commit ...）
+	SkipEnv = "VYB_SKIP_HOOKS"
+	// backupSuffix は置き換えた既存フックの退避先の拡張子
+	backupSuffix = ".vyb-backup"
+)
+
+// フック名
+const (
+	HookPreCommit = "pre-commit"
+	HookCommitMsg = "commit-msg"
+)
+
+// Names は vyb が導入するフック
+func Names() []string {
+	return []string{HookPreCommit, HookCommitMsg}
+}
+
+// Status はフック1つの導入状況
+type Status struct {
+	Name    string `json:"name"`
+	Path    string `json:"path"`
+	Exists  bool   `json:"exists"`  // フックファイルがあるか
+	Managed bool   `json:"managed"` // vyb が書いたフックか
+	Backup  bool   `json:"backup"`  // 置き換えた既存フックの退避があるか
+}
+
+// Dir はリポジトリのフックの保存先（core.hooksPath を考慮）
+func Dir(ctx context.Context, repoDir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "rev-parse", "--git-path", "hooks")
+	cmd.Dir = repoDir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("gitリポジトリではありません: %s", repoDir)
+	}
+	dir := strings.TrimSpace(string(output))
+	if !filepath.IsAbs(dir) {
+		dir = filepath.Join(repoDir, dir)
+	}
+	return dir, nil
+}
+
+// Script はフックのシェルスクリプト（vybPath が実行できなければ PATH の vyb を使う）
+func Script(hook, vybPath string) string {
+	args := "hooks run " + hook
+	if hook == HookCommitMsg {
+		args += ` "$1"`
+	}
+	return fmt.Sprintf(`#!/bin/sh
+%s (vyb hooks install)
+# Skip once with %s=1 git commit ... or git commit --no-verify.
+[ -n "$%s" ] && exit 0
+VYB=%s
+[ -x "$VYB" ] || VYB=$(command -v vyb) || exit 0
+exec "$VYB" %s
+`, Marker, SkipEnv, SkipEnv, shellQuote(vybPath), args)
+}
+
+// Install はフックを書き込み、書き込んだパスを返す
+// vyb 以外が書いた既存フックがある場合、force なら退避してから置き換え、そうでなければエラー
+func Install(ctx context.Context, repoDir, vybPath string, force bool) ([]string, error) {
+	dir, err := Dir(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	statuses, err := Inspect(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	for _, status := range statuses {
+		if status.Exists && !status.Managed && !force {
+			return nil, fmt.Errorf("既存の %s フックがあります（--force で %s に退避して置き換えます）", status.Name, status.Path+backupSuffix)
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("フック保存先の作成エラー: %w", err)
+	}
+	var written []string
+	for _, status := range statuses {
+		if status.Exists && !status.Managed {
+			if err := os.Rename(status.Path, status.Path+backupSuffix); err != nil {
+				return written, fmt.Errorf("既存の %s フックの退避エラー: %w", status.Name, err)
+			}
+		}
+		if err := os.WriteFile(status.Path, []byte(Script(status.Name, vybPath)), 0755); err != nil {
+			return written, fmt.Errorf("%s フックの書き込みエラー: %w", status.Name, err)
+		}
+		written = append(written, status.Path)
+	}
+	return written, nil
+}
+
+// Uninstall は vyb が書いたフックを削除し、退避した既存フックがあれば戻す（削除したパスを返す）
+func Uninstall(ctx context.Context, repoDir string) ([]string, error) {
+	statuses, err := Inspect(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	var removed []string
+	for _, status := range statuses {
+		if !status.Managed {
+			continue
+		}
+		if err := os.Remove(status.Path); err != nil {
+			return removed, fmt.Errorf("%s フックの削除エラー: %w", status.Name, err)
+		}
+		removed = append(removed, status.Path)
+		if status.Backup {
+			if err := os.Rename(status.Path+backupSuffix, status.Path); err != nil {
+				return removed, fmt.Errorf("退避した %s フックの復元エラー: %w", status.Name, err)
+			}
+		}
+	}
+	return removed, nil
+}
+
+// Inspect は各フックの導入状況を返す
+func Inspect(ctx context.Context, repoDir string) ([]Status, error) {
+	dir, err := Dir(ctx, repoDir)
+	if err != nil {
+		return nil, err
+	}
+	statuses := make([]Status, 0, len(Names()))
+	for _, name := range Names() {
+		status := Status{Name: name, Path: filepath.Join(dir, name)}
+		if data, err := os.ReadFile(status.Path); err == nil {
+			status.Exists = true
+			status.Managed = strings.Contains(string(data), Marker)
+		}
+		if _, err := os.Stat(status.Path + backupSuffix); err == nil {
+			status.Backup = true
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// shellQuote はシェルの単一引用符で囲む
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
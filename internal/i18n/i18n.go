@@ -0,0 +1,104 @@
+package i18n
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Lang は表示・応答言語
+type Lang string
+
+const (
+	LangJa   Lang = "ja"
+	LangEn   Lang = "en"
+	LangAuto Lang = "auto" // 環境変数（LC_ALL, LC_MESSAGES, LANG）から判定
+)
+
+// ValidLanguages は設定可能な言語一覧を返す
+func ValidLanguages() []string {
+	return []string{string(LangJa), string(LangEn), string(LangAuto)}
+}
+
+// catalogs は言語別のメッセージカタログ
+var catalogs = map[Lang]map[string]string{
+	LangJa: messagesJa,
+	LangEn: messagesEn,
+}
+
+var (
+	mu      sync.RWMutex
+	current = LangJa
+)
+
+// Resolve は設定値を実際の言語に解決（不明な値は日本語）
+func Resolve(setting string) Lang {
+	switch Lang(strings.ToLower(strings.TrimSpace(setting))) {
+	case LangEn:
+		return LangEn
+	case LangAuto:
+		return detectFromEnv()
+	default:
+		return LangJa
+	}
+}
+
+// detectFromEnv はロケール環境変数から言語を判定
+func detectFromEnv() Lang {
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		value := os.Getenv(name)
+		if value == "" || value == "C" || value == "POSIX" {
+			continue
+		}
+		if strings.HasPrefix(strings.ToLower(value), "ja") {
+			return LangJa
+		}
+		return LangEn
+	}
+	return LangEn
+}
+
+// SetLanguage は現在の言語を設定（ja, en, auto）
+func SetLanguage(setting string) {
+	mu.Lock()
+	defer mu.Unlock()
+	current = Resolve(setting)
+}
+
+// Current は現在の言語を返す
+func Current() Lang {
+	mu.RLock()
+	defer mu.RUnlock()
+	return current
+}
+
+// T は現在の言語でメッセージを取得（未定義なら日本語、それもなければキーを返す）
+func T(key string, args ...interface{}) string {
+	return TL(Current(), key, args...)
+}
+
+// TL は指定言語でメッセージを取得
+func TL(lang Lang, key string, args ...interface{}) string {
+	format, ok := catalogs[lang][key]
+	if !ok {
+		if format, ok = catalogs[LangJa][key]; !ok {
+			return key
+		}
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Errorf は現在の言語でエラーを作成（%w によるラップに対応）
+func Errorf(key string, args ...interface{}) error {
+	format, ok := catalogs[Current()][key]
+	if !ok {
+		if format, ok = catalogs[LangJa][key]; !ok {
+			format = key
+		}
+	}
+	return fmt.Errorf(format, args...)
+}
@@ -0,0 +1,203 @@
+package i18n
+
+import (
+	"errors"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// formatVerb はメッセージ内の書式指定子
+var formatVerb = regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z%]`)
+
+func verbs(format string) []string {
+	matches := formatVerb.FindAllString(format, -1)
+	// 語順が異なる言語があるため順序は比較しない
+	sort.Strings(matches)
+	return matches
+}
+
+func TestCatalogsAreConsistent(t *testing.T) {
+	for key, ja := range messagesJa {
+		en, ok := messagesEn[key]
+		if !ok {
+			t.Errorf("key %q missing from en catalog", key)
+			continue
+		}
+		jaVerbs, enVerbs := verbs(ja), verbs(en)
+		if len(jaVerbs) != len(enVerbs) {
+			t.Errorf("key %q: format verbs differ (ja %v, en %v)", key, jaVerbs, enVerbs)
+			continue
+		}
+		for i := range jaVerbs {
+			if jaVerbs[i] != enVerbs[i] {
+				t.Errorf("key %q: format verbs differ (ja %v, en %v)", key, jaVerbs, enVerbs)
+				break
+			}
+		}
+	}
+	for key := range messagesEn {
+		if _, ok := messagesJa[key]; !ok {
+			t.Errorf("key %q missing from ja catalog", key)
+		}
+	}
+}
+
+// リポジトリ内で i18n.T・TL・Errorf に文字列リテラルで渡しているキーが全ての言語のカタログにあることのテスト
+func TestUsedKeysExistInCatalogs(t *testing.T) {
+	root := filepath.Join("..", "..")
+	used := make(map[string]string) // キー → 最初に使っている場所
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if name := entry.Name(); path != root && (strings.HasPrefix(name, ".") || name == "vendor" || name == "testdata") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, path, nil, 0)
+		if err != nil {
+			return err
+		}
+		ast.Inspect(file, func(node ast.Node) bool {
+			if key, ok := catalogKeyArg(node); ok {
+				if _, seen := used[key]; !seen {
+					used[key] = fset.Position(node.Pos()).String()
+				}
+			}
+			return true
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(used) == 0 {
+		t.Fatal("no i18n keys found")
+	}
+
+	for key, position := range used {
+		for lang, catalog := range catalogs {
+			if _, ok := catalog[key]; !ok {
+				t.Errorf("%s: key %q missing from %s catalog", position, key, lang)
+			}
+		}
+	}
+}
+
+// catalogKeyArg は i18n.T・TL・Errorf の呼び出しで文字列リテラルのキーを渡していればそれを返す
+func catalogKeyArg(node ast.Node) (string, bool) {
+	call, ok := node.(*ast.CallExpr)
+	if !ok {
+		return "", false
+	}
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != "i18n" {
+		return "", false
+	}
+	index := 0
+	switch selector.Sel.Name {
+	case "T", "Errorf":
+	case "TL":
+		index = 1
+	default:
+		return "", false
+	}
+	if len(call.Args) <= index {
+		return "", false
+	}
+	literal, ok := call.Args[index].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	key, err := strconv.Unquote(literal.Value)
+	return key, err == nil
+}
+
+func TestResolve(t *testing.T) {
+	tests := []struct {
+		setting string
+		lang    string
+		want    Lang
+	}{
+		{"ja", "", LangJa},
+		{"en", "", LangEn},
+		{"EN", "", LangEn},
+		{"unknown", "", LangJa},
+		{"auto", "ja_JP.UTF-8", LangJa},
+		{"auto", "en_US.UTF-8", LangEn},
+		{"auto", "C", LangEn},
+	}
+
+	for _, tt := range tests {
+		t.Setenv("LC_ALL", "")
+		t.Setenv("LC_MESSAGES", "")
+		t.Setenv("LANG", tt.lang)
+		if got := Resolve(tt.setting); got != tt.want {
+			t.Errorf("Resolve(%q) with LANG=%q = %q, want %q", tt.setting, tt.lang, got, tt.want)
+		}
+	}
+}
+
+func TestTranslate(t *testing.T) {
+	defer SetLanguage(string(Current()))
+
+	SetLanguage("ja")
+	if got := T("fixloop.succeeded", 2); got != "🔧 **自動修正:** 2 回の修正でビルド・テストに成功しました" {
+		t.Errorf("unexpected ja message: %s", got)
+	}
+	if got := T("session.type.review"); got != "コードレビュー" {
+		t.Errorf("unexpected ja label: %s", got)
+	}
+
+	SetLanguage("en")
+	if got := T("fixloop.succeeded", 2); got != "🔧 **Auto-fix:** build and tests passed after 2 fix(es)" {
+		t.Errorf("unexpected en message: %s", got)
+	}
+	if got := T("session.type.review"); got != "Code review" {
+		t.Errorf("unexpected en label: %s", got)
+	}
+
+	// 未定義キーはキーそのものを返す
+	if got := T("no.such.key"); got != "no.such.key" {
+		t.Errorf("expected key fallback, got %s", got)
+	}
+}
+
+func TestErrorf(t *testing.T) {
+	defer SetLanguage(string(Current()))
+	cause := errors.New("exit status 1")
+
+	for _, tt := range []struct {
+		lang string
+		want string
+	}{
+		{"ja", "make の実行エラー: exit status 1"},
+		{"en", "failed to run make: exit status 1"},
+	} {
+		SetLanguage(tt.lang)
+		err := Errorf("error.task_exec", "make", cause)
+		if err.Error() != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.lang, err.Error(), tt.want)
+		}
+		if !errors.Is(err, cause) {
+			t.Errorf("%s: error should wrap the cause", tt.lang)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+package i18n
+
+// messagesEn は英語メッセージカタログ
+var messagesEn = map[string]string{
+	// チャットUI
+	"chat.goodbye":              "👋 Goodbye!",
+	"chat.you":                  "▶ You",
+	"chat.error":                "✗ Error",
+	"chat.input_error":          "Input error: %v",
+	"chat.full_content":         "🤖 Assistant (Full Content)",
+	"chat.no_previous_response": "No previous response to expand.",
+	"chat.interrupted":          "⏹ Interrupted",
+
+	// ウェルカムメッセージ
+	"welcome.greeting":    "Welcome to intelligent coding!",
+	"welcome.features":    "Intelligent suggestions, streaming responses, and smart completion",
+	"welcome.commands":    "Commands: '%s' for help",
+	"welcome.exit":        "Exit: '%s' or '%s' or %s",
+	"welcome.project":     "Project: ",
+	"welcome.performance": "Performance monitoring: ",
+	"welcome.enabled":     "enabled",
+
+	// ビルド・テスト・リント
+	"task.unavailable":   "%s is not available in this session",
+	"task.running":       "⚙ Running %s...",
+	"task.context_added": "Failures were added to the context. You can ask for a fix.",
+
+	// 自動修正ループ
+	"fixloop.verified":       "🔧 **Verified:** build and tests passed",
+	"fixloop.succeeded":      "🔧 **Auto-fix:** build and tests passed after %d fix(es)",
+	"fixloop.failed":         "🔧 **Auto-fix:** failures remain after %d attempt(s)",
+	"fixloop.iteration":      "- #%d: %s failed (%d issues) → patched %s",
+	"fixloop.max_iterations": "reached the maximum of %d iterations",
+	"fixloop.no_patch":       "no fix patch was produced",
+
+	// エージェントループ
+	"agentloop.step":      "🔁 **Step %d**",
+	"agentloop.max_steps": "⏹ Stopped after %d steps without a final answer",
+	"agentloop.repeated":  "⏹ Stopped because the same actions were repeated",
+	"agentloop.error":     "⏹ Could not generate the next step: %v",
+	"agentloop.results":   "🔄 **Results:**",
+	"agentloop.observation": "Results of the tools you used:\n\n%s\n\n" +
+		"Continue with the user's request based on these results. " +
+		"If you need more information or further actions, use the structured tags again. " +
+		"When the task is complete, reply with the final answer and no tags.",
+	"agentloop.last_step": " This is your last response: give the final answer now.",
+	"agentloop.remaining": " You have at most %d more responses.",
+
+	// セッション種別
+	"session.type.general":   "General coding",
+	"session.type.debugging": "Debugging",
+	"session.type.refactor":  "Refactoring",
+	"session.type.review":    "Code review",
+	"session.type.learning":  "Learning",
+
+	// プロンプトに列挙する構造化タグ
+	"tool.analysis.description":    "Project/code analysis (mandatory for analysis questions)",
+	"tool.analysis.purpose":        "Analysis / status check (highest priority)",
+	"tool.command.description":     "Run a Bash command",
+	"tool.command.purpose":         "Command execution",
+	"tool.filecreate.description":  "Create a file",
+	"tool.filecreate.purpose":      "File creation",
+	"tool.patch.description":       "Edit existing files with a unified diff (line-number and whitespace drift is tolerated; all hunks apply or none)",
+	"tool.patch.purpose":           "File editing",
+	"tool.filemove.description":    "Move or rename a file or directory (git mv when tracked; undo with vyb refactor undo)",
+	"tool.filemove.purpose":        "File move / rename",
+	"tool.filedelete.description":  "Delete a file or directory after the user confirms (git rm when tracked; undo with vyb refactor undo)",
+	"tool.filedelete.purpose":      "File deletion",
+	"tool.tree.description":        "Show the directory tree under a path (respects .gitignore/.vybignore; use instead of ls -la or find)",
+	"tool.tree.purpose":            "Directory structure",
+	"tool.fileread.description":    "Read a file",
+	"tool.fileread.purpose":        "File reading",
+	"tool.suggestion.description":  "Suggest the next action",
+	"tool.suggestion.purpose":      "Next-step suggestion",
+	"tool.diagram.description":     "Draw the package dependency graph (calls: label edges with called functions) to show in the answer",
+	"tool.diagram.purpose":         "Architecture diagram",
+	"tool.jobstart.description":    "Start a long-running command (dev server, watcher) in the background",
+	"tool.jobstart.purpose":        "Background execution",
+	"tool.joboutput.description":   "Check a background job's status and recent output",
+	"tool.joboutput.purpose":       "Job output",
+	"tool.jobkill.description":     "Stop a background job",
+	"tool.jobkill.purpose":         "Job termination",
+	"tool.pin.description":         "Pin a decision, constraint or file so it is never dropped from the context",
+	"tool.pin.purpose":             "Keeping agreed decisions",
+	"tool.diagnostics.description": "Collect tool versions (go, node, docker...), OS, relevant environment variables (secrets redacted) and vyb config when a failure may depend on the environment",
+	"tool.diagnostics.purpose":     "Environment report",
+	"tool.logtail.description":     "Show the end of a log file or journalctl unit and follow it for N seconds (e.g. while the user hits an endpoint); lines get error/warn levels",
+	"tool.logtail.purpose":         "Watching logs",
+	"tool.recall.description":      "Search the output of commands and tools run earlier in this session (e.g. what the test failure said) instead of running them again",
+	"tool.recall.purpose":          "Recalling earlier results",
+
+	// 応答
+	"suggestion.applied": "✅ Suggestion applied!",
+
+	// 提案の適用時の変更の衝突
+	"conflict.detected":              "⚠️ %s changed on disk after this suggestion was generated; it was not applied",
+	"conflict.model_edit":            "── Suggested edit (base → suggestion)",
+	"conflict.concurrent_change":     "── Concurrent change (base → current)",
+	"conflict.current_vs_suggestion": "── Current content → suggestion (the base content is no longer available)",
+	"conflict.deleted":               "── The file has since been deleted",
+	"conflict.options":               "Choose: [r] regenerate from the current content  [f] force apply  [a] abort",
+	"conflict.aborted":               "❌ Discarded the suggestion for %s",
+	"conflict.forced":                "✅ Applied the suggestion to %s despite the concurrent change",
+	"conflict.force_unavailable":     "cannot force the suggestion: the code it replaces is no longer in %s and the base content is unknown (regenerate instead)",
+
+	// 自動承認ポリシー
+	"approval.auto_applied": "🤖 Applied without confirmation by approval rule %s (confidence %.2f)",
+	"approval.auto_failed":  "⚠️ Approval rule %s allows this suggestion, but applying it failed: %v (answer y to retry)",
+	"approval.denied":       "🚫 Approval rule %s does not allow this suggestion; it was not applied",
+
+	// 使用量・コスト
+	"usage.disabled":       "Usage tracking is disabled (enable with: vyb config enable-usage true)",
+	"usage.no_records":     "No usage recorded for this session yet",
+	"usage.session_title":  "💰 Usage for this session",
+	"usage.line":           "%d req  in %d / out %d tokens  %s",
+	"usage.total":          "Total",
+	"usage.estimated_note": "* %d request(s) have token counts estimated from text length",
+
+	// コンテキスト内訳
+	"context.unavailable":         "Context breakdown is not available in this session",
+	"context.title":               "📊 Context usage (%s): %d / %d tokens (%.1f%%)",
+	"context.segment.system":      "System prompt",
+	"context.segment.template":    "Response template",
+	"context.segment.memory":      "Memory (long-term)",
+	"context.segment.context":     "Context items",
+	"context.segment.history":     "Conversation history",
+	"context.segment.last_output": "Last output",
+	"context.segment_items":       "%s (%d items)",
+	"context.reserved":            "Reserved for response",
+	"context.remaining":           "Remaining",
+	"context.items_title":         "Context items (by importance)",
+	"context.more_items":          "… %d more",
+	"context.stats_title":         "🗜 Context compression (this session)",
+	"context.stats_none":          "No context has been compressed in this session yet",
+	"context.stats_count":         "Compressions: %d (%d repaired)",
+	"context.stats_size":          "Size:         %d → %d bytes (%.1f%% reduction)",
+	"context.stats_fidelity":      "Fidelity:     avg %.0f%%, min %.0f%%",
+	"context.stats_last":          "Last:         %s, %d facts checked, fidelity %.0f%%",
+	"context.stats_fallback":      "validator failed, fell back to key facts: %s",
+	"context.stats_missing":       "Facts missing from the last summary:",
+	"context.stats_restored":      "Facts restored as key points:",
+
+	// 画像添付
+	"vision.attached":            "📎 %d image(s) will be attached to your next message",
+	"vision.unsupported_warning": "model %s does not accept images, so they will not be sent (use llava, qwen2.5vl, etc.)",
+	"vision.unsupported_note":    "(The user attached %d image(s), but model %s does not accept image input so they are not included. Say so if the image content is needed.)",
+
+	// チェックポイント・巻き戻し
+	"rewind.unavailable":  "checkpoints are disabled (not a git repository, or vyb config enable-checkpoints false)",
+	"history.usage":       "usage: /history <query> (search past conversations and tool output)",
+	"history.no_match":    "no past conversations match \"%s\"",
+	"history.title":       "🔎 Results for \"%s\" (%d)",
+	"history.quote_hint":  "/quote <session> <turn> adds a match to this session's context",
+	"history.quote_usage": "usage: /quote <session> <turn>",
+	"history.unavailable": "quoting past conversations is not available in this session",
+	"history.quoted":      "📎 Added %s turn %d to the context for the next response",
+	"save.done":           "💾 Saved %d turn(s) to %s (%s)",
+	"save.unavailable":    "saving the conversation is not available in this session",
+	"save.empty":          "nothing to save yet",
+	"rewind.empty":        "no checkpoints yet (they are taken for turns that change files)",
+	"rewind.title":        "⏪ Checkpoints (/rewind <turn> restores the state before that turn)",
+	"rewind.entry":        "turn %d  %s  %d file(s)  %s",
+	"rewind.invalid_turn": "specify a turn number: %s",
+	"rewind.done":         "⏪ Rewound to before turn %d (%d conversation turn(s) undone)",
+	"rewind.files":        "files: %d restored / %d removed",
+
+	// @メンション
+	"mention.attached":  "attached @%s (%d bytes)",
+	"mention.truncated": "attached @%s (truncated to the first %d of %d bytes)",
+	"mention.skipped":   "cannot attach @%s: %v",
+
+	// シェルコマンド（!command）
+	"shell.unavailable":   "commands cannot be run directly in this session",
+	"shell.usage":         "use !<command> to run a command directly (e.g. !go test ./...)",
+	"shell.done":          "✓ done (%s)",
+	"shell.exit_code":     "✗ exit code %d (%s)",
+	"shell.timed_out":     "✗ timed out (%s)",
+	"shell.context_added": "output added to the context for the next response",
+
+	// バックグラウンドジョブ
+	"jobs.unavailable":    "background jobs are not available in this session",
+	"jobs.usage":          "use /bg <command> to run it in the background (e.g. /bg npm run dev)",
+	"jobs.started":        "🚀 started job %d (pid %d): %s",
+	"jobs.empty":          "no background jobs",
+	"jobs.title":          "⚙ Background jobs (/jobs <id> for output, /kill <id> to stop)",
+	"jobs.invalid_id":     "specify a job id: %s",
+	"jobs.output_title":   "📋 Job %d: %s (%s)",
+	"jobs.killed":         "🛑 stopped job %d (%s)",
+	"jobs.status_running": "running",
+	"jobs.status_killed":  "killed",
+	"jobs.status_exited":  "exited (code %d)",
+
+	// /info
+	"info.title":            "ℹ Session info",
+	"info.model":            "Model:    %s",
+	"info.provider":         "Provider: %s (%s)",
+	"info.endpoints":        "LLM endpoints (retry + failover):",
+	"info.no_resilience":    "LLM endpoint health is not available",
+	"info.state_closed":     "healthy",
+	"info.state_open":       "down, retry at %s",
+	"info.state_halfopen":   "recovering",
+	"info.active":           "active",
+	"info.failures":         "%d consecutive failure(s): %s",
+	"info.concurrency":      "Concurrency:",
+	"info.concurrency_line": "%d/%d running, %d queued · waited %d time(s), %s in total",
+	"info.offline":          "Offline: model unreachable, %d prompt(s) queued",
+	"info.template":         "Template: %s (%s)",
+	"info.pinned":           "Pinned: %s",
+
+	// クラッシュ復元
+	"autosave.found":            "⏪ An interrupted session from %s ago was found (%d turn(s))",
+	"autosave.unanswered":       "   Last input without a response: %s",
+	"autosave.pending":          "   Pending suggestion: %s",
+	"autosave.prompt":           "Resume it? [Y/n] ",
+	"autosave.resumed":          "🎯 Interrupted session restored: %d turn(s) added as context (new session %s)",
+	"autosave.pending_restored": "💡 The pending suggestion for %s was restored — reply \"y\" to apply it",
+	"autosave.discarded":        "Interrupted session discarded",
+	"autosave.failed":           "⚠ Could not restore the interrupted session: %v",
+
+	// 定期メンテナンス（vyb daemon）の要約
+	"maintenance.since_today":     "earlier today",
+	"maintenance.since_yesterday": "yesterday",
+	"maintenance.since_ago":       "%s ago",
+	"maintenance.summary":         "🛠  Since %s: %s",
+	"maintenance.new_vulns":       "%d new vulnerability(ies) in deps",
+	"maintenance.no_new_vulns":    "no new vulnerabilities in deps",
+	"maintenance.resolved":        "%d resolved",
+	"maintenance.stale_branches":  "%d stale branch(es)",
+	"maintenance.index":           "index refreshed (%d file(s) re-embedded)",
+	"maintenance.fixed_in":        " (fixed in %s)",
+	"maintenance.details":         "   Details: vyb daemon status",
+
+	// ペイン構成のTUI
+	"tui.pane_conversation": "Conversation",
+	"tui.pane_plan":         "Plan",
+	"tui.pane_files":        "Files",
+	"tui.pane_jobs":         "Jobs",
+	"tui.working":           "working",
+	"tui.busy":              "Still working on the previous input (Ctrl+C to interrupt)",
+	"tui.diff_summary":      "%d file(s) changed (+%d -%d) — see the Files pane",
+	"tui.plan_empty":        "No plan yet. Tool steps and todo lists of the current turn appear here.",
+	"tui.plan_request":      "Request",
+	"tui.plan_steps":        "Steps",
+	"tui.plan_no_steps":     "no tool steps",
+	"tui.plan_todos":        "Todo",
+	"tui.files_empty":       "No files changed in this session yet",
+	"tui.files_no_diff":     "No diff recorded for this file (checkpoints disabled?)",
+	"tui.jobs_no_output":    "no output yet",
+	"tui.help_conversation": "Enter send · Tab complete · ↑↓ history · PgUp/PgDn scroll · Ctrl+O expand output · Ctrl+Y copy code · Tab (empty)/Alt+1-4 panes · Ctrl+C interrupt/quit",
+	"tui.fold_hidden":       "%d of %d lines folded · Ctrl+O expands",
+	"tui.fold_page":         "page %d/%d · lines %d-%d of %d · Ctrl+O next page",
+	"tui.fold_collapse":     "end of output · Ctrl+O folds it again",
+	"tui.fold_dropped":      "… %d earlier lines were dropped",
+	"tui.fold_none":         "no folded output to expand",
+	"tui.help_plan":         "j/k scroll · 1-4 panes · Esc back · Ctrl+C quit",
+	"tui.help_files":        "j/k select file · PgUp/PgDn scroll diff · t directory tree · 1-4 panes · Esc back · Ctrl+C quit",
+	"tui.help_tree":         "j/k or PgUp/PgDn scroll · t changed files · 1-4 panes · Esc back · Ctrl+C quit",
+	"tui.help_jobs":         "j/k select job · x kill · PgUp/PgDn scroll output · 1-4 panes · Esc back · Ctrl+C quit",
+
+	// 完了通知
+	"notify.complete": "Finished after %s",
+	"notify.input":    "Waiting for your confirmation (%s)",
+	"notify.error":    "Failed after %s",
+
+	// 影響範囲
+	"impact.summary":    "💥 Blast radius: %d direct and %d transitive dependent(s)",
+	"impact.none":       "💥 Blast radius: nothing else in the project depends on the changed code",
+	"impact.changed":    "  Changed: %s",
+	"impact.direct":     "  Direct dependents:",
+	"impact.transitive": "  Transitive: %s",
+	"impact.tests":      "  Affected tests: %s",
+	"impact.more":       "… and %d more",
+
+	// 会話の分岐
+	"branch.title":         "🌿 Conversation branches (* = current)",
+	"branch.turns":         "%d turn(s)",
+	"branch.forked_from":   "forked from %s after turn %d",
+	"branch.usage":         "usage: /branch (list) · /branch <turn> [name] (fork after a turn, 0 = from the start) · /branch switch|compare|merge <name>",
+	"branch.unavailable":   "conversation branching is not available in this session",
+	"branch.forked":        "🌿 Created branch %s from %s after turn %d (later turns are out of the context; files are unchanged, use /rewind for those)",
+	"branch.switched":      "🌿 Switched to branch %s (%d turn(s))",
+	"branch.compare_title": "🌿 %s vs %s (%d shared turn(s))",
+	"branch.no_exchanges":  "no turns since the branches diverged",
+	"branch.merge_hint":    "/branch merge %s brings that branch's conclusions into the current one",
+	"branch.merged":        "🌿 Merged %d turn(s) from %s into %s as context",
+
+	// 提案の根拠（/why）
+	"why.title":               "🔎 Why suggestion %s",
+	"why.applied":             "applied at %s",
+	"why.not_applied":         "not applied",
+	"why.input":               "Request: %s",
+	"why.model":               "Model: %s",
+	"why.context":             "Retrieved context (%d item(s)):",
+	"why.context_none":        "no context items were retrieved",
+	"why.files":               "Files: %s",
+	"why.analysis":            "Analysis:",
+	"why.confidence":          "Confidence %.2f:",
+	"why.factor.base":         "base confidence",
+	"why.factor.context":      "rich context (%d items, more than 5)",
+	"why.factor.bugfix":       "bug fixes are comparatively reliable",
+	"why.factor.optimization": "performance optimizations need care",
+	"why.factor.security":     "security fixes are weighted up",
+	"why.factor.history":      "more suggestions accepted than rejected this session (%d/%d)",
+	"why.factor.extracted":    "fixed default for suggestions extracted from the reply (0.85 with a code block, 0.70 without)",
+	"why.none":                "no suggestions have been made in this session yet",
+	"why.not_found":           "no suggestion matches %s (/why list shows them)",
+	"why.unavailable":         "suggestion provenance is not available in this session",
+	"why.list_title":          "🔎 Suggestions in this session (/why <id> for details)",
+	"why.usage":               "usage: /why (latest suggestion) · /why list · /why <id>",
+
+	// オフライン（LLMに到達できない場合）
+	"offline.unreachable":       "⚠ The model is not reachable: %v",
+	"offline.capabilities":      "Offline mode still works without a model: !<command> (git, grep, ...), /build /test /lint, /bg /jobs, /rewind, /context, /history, /save,\nand the CLI commands vyb git status|diff|log, vyb search, vyb grep, vyb find, vyb analyze. Prompts are queued until the model is back.",
+	"offline.prompt":            "Continue in offline mode? [Y/n] ",
+	"offline.started":           "📴 Offline mode: prompts are queued (/queue), /offline retries the connection",
+	"offline.lost":              "⚠ Lost the connection to the model (%v) — switched to offline mode",
+	"offline.queued":            "⏸ Queued until the model is back (%d pending · /queue lists them · /offline retries)",
+	"offline.restored":          "🔌 The model is reachable again",
+	"offline.queue_hint":        "%d queued prompt(s): /queue run sends them in order",
+	"offline.online":            "🔌 Online: the model is reachable",
+	"offline.queue_empty":       "no queued prompts",
+	"offline.queue_title":       "⏸ Queued prompts (%d)",
+	"offline.queue_cleared":     "Discarded %d queued prompt(s)",
+	"offline.still_unreachable": "the model is still unreachable: %v",
+	"offline.queue_usage":       "usage: /queue (list) · /queue run (send in order) · /queue clear",
+
+	// セッションテンプレート（vyb --template）
+	"template.applied":     "🧭 Template %s: %s",
+	"template.plan":        "Plan:",
+	"template.run_first":   "Running %s first as the template asks",
+	"template.unavailable": "this session does not support templates",
+
+	// ファイルの固定（vyb chat <file>、/pin）
+	"pin.status":      "📌 pinned: %s",
+	"pin.none":        "no pinned files (/pin <file> pins one; pinned files are re-read every turn and are the default edit target)",
+	"pin.pinned":      "📌 Pinned %s — re-read every turn and used as the default edit target",
+	"pin.unpinned":    "Unpinned %s",
+	"pin.all_removed": "Unpinned all files",
+	"pin.unavailable": "this session does not support pinned files",
+
+	// コンテキストの固定（/pin-context）
+	"pin_context.title":       "📌 Pinned context (never dropped by compression)",
+	"pin_context.none":        "no pinned context (/pin-context <decision or constraint> pins a note; an ID from /context or a file path pins that item)",
+	"pin_context.pinned":      "📌 Pinned %s — it stays in the prompt every turn and is never compressed away",
+	"pin_context.unpinned":    "Unpinned %s",
+	"pin_context.usage":       "usage: /unpin-context <id|file> (/pin-context lists pinned items)",
+	"pin_context.file":        "file (re-read every turn)",
+	"pin_context.unavailable": "this session does not support pinned context",
+
+	// セッションの題名（/title）
+	"title.current":     "Session title: %s",
+	"title.none":        "this session has no title yet (one is generated after the first answer; /title <text> sets one)",
+	"title.renamed":     "Renamed the session to \"%s\"",
+	"title.unavailable": "this session does not support titles",
+
+	// クリップボード（/copy、貼り付けの添付）
+	"copy.copied":       "📋 Copied code block %d of %d (%s, %d lines) via %s",
+	"copy.no_blocks":    "the last response has no code blocks",
+	"copy.out_of_range": "there is no code block %d (the last response has %d)",
+	"copy.usage":        "usage: /copy [n] (n-th code block of the last response, default: the last one)",
+	"paste.attached":    "Attached pasted text %s (%d bytes) as context",
+
+	// 長い出力の折り畳み
+	"fold.hidden":    "%d of %d lines folded · /expand pages through them, /expand all prints everything",
+	"fold.none":      "no folded output (in the pane UI, Ctrl+O expands folded output)",
+	"fold.page":      "Output page %d/%d (lines %d-%d of %d)",
+	"fold.next_page": "/expand for the next page · /expand all for everything",
+	"fold.end":       "end of output",
+
+	// ターンの資源上限
+	"budget.exceeded":        "Turn budget exceeded: %s limit (%s) reached",
+	"budget.kind.tool_calls": "tool call",
+	"budget.kind.tokens":     "token",
+	"budget.kind.wall_time":  "wall time",
+	"budget.usage":           "Used this turn: %d tool calls, %d tokens, %s",
+	"budget.done_so_far":     "Here is what I did so far:",
+	"budget.no_actions":      "No tools were run before the limit was reached.",
+	"budget.continue_hint":   "Send another message to continue, or raise the limit with `vyb config set-turn-limits`.",
+
+	// ワークスペース（モノレポ）
+	"workspace.title":    "📦 Modules in %s (/workspace <module> to switch, /workspace / for the repository root)",
+	"workspace.none":     "no Go modules or npm workspaces found under %s",
+	"workspace.switched": "📂 Scope switched to %s (%s)",
+
+	// リモート開発
+	"remote.connecting": "🔌 Connecting to %s over SSH...",
+	"remote.connected":  "🔌 Remote workspace %s:%s (%d tools run on the remote host)",
+
+	// エラー
+	"error.session_not_found":      "session %s not found",
+	"error.prompt_template":        "prompt template error: %v",
+	"error.build_system_not_found": "no build system detected in %s",
+	"error.task_command_missing":   "%s has no %s command",
+	"error.task_command_build":     "failed to build command: %w",
+	"error.task_timeout":           "%s timed out or was cancelled: %w",
+	"error.task_exec":              "failed to run %s: %w",
+	"error.invalid_language":       "invalid language; use ja, en or auto",
+}
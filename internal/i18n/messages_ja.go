@@ -0,0 +1,398 @@
+package i18n
+
+// messagesJa は日本語メッセージカタログ
+var messagesJa = map[string]string{
+	// チャットUI
+	"chat.goodbye":              "👋 終了します",
+	"chat.you":                  "▶ あなた",
+	"chat.error":                "✗ エラー",
+	"chat.input_error":          "入力エラー: %v",
+	"chat.full_content":         "🤖 Assistant（全文）",
+	"chat.no_previous_response": "展開できる直前の応答がありません。",
+	"chat.interrupted":          "⏹ 中断しました",
+
+	// ウェルカムメッセージ
+	"welcome.greeting":    "インテリジェントなコーディングへようこそ！",
+	"welcome.features":    "インテリジェントな提案・ストリーミング応答・スマート補完",
+	"welcome.commands":    "コマンド: '%s' でヘルプ",
+	"welcome.exit":        "終了: '%s'、'%s' または %s",
+	"welcome.project":     "プロジェクト: ",
+	"welcome.performance": "パフォーマンス監視: ",
+	"welcome.enabled":     "有効",
+
+	// ビルド・テスト・リント
+	"task.unavailable":   "このセッションでは %s を実行できません",
+	"task.running":       "⚙ %s を実行中...",
+	"task.context_added": "失敗内容をコンテキストに追加しました。修正を依頼できます。",
+
+	// 自動修正ループ
+	"fixloop.verified":       "🔧 **検証:** ビルド・テストに成功しました",
+	"fixloop.succeeded":      "🔧 **自動修正:** %d 回の修正でビルド・テストに成功しました",
+	"fixloop.failed":         "🔧 **自動修正:** %d 回試行しましたが失敗が残っています",
+	"fixloop.iteration":      "- #%d: %s の失敗 %d 件 → %s を修正",
+	"fixloop.max_iterations": "最大反復回数 %d に到達",
+	"fixloop.no_patch":       "修正パッチが生成されませんでした",
+
+	// エージェントループ
+	"agentloop.step":      "🔁 **ステップ %d**",
+	"agentloop.max_steps": "⏹ %d ステップで最終回答に至らなかったため停止しました",
+	"agentloop.repeated":  "⏹ 同じ操作が繰り返されたため停止しました",
+	"agentloop.error":     "⏹ 次のステップを生成できませんでした: %v",
+	"agentloop.results":   "🔄 **実行結果:**",
+	"agentloop.observation": "使用したツールの実行結果:\n\n%s\n\n" +
+		"これらの結果を踏まえてユーザーの依頼を続けてください。" +
+		"さらに情報や操作が必要な場合は、再び構造化タグを使ってください。" +
+		"作業が完了したら、タグを付けずに最終回答を返してください。",
+	"agentloop.last_step": "これが最後の応答です。今すぐ最終回答を返してください。",
+	"agentloop.remaining": "残りの応答は最大 %d 回です。",
+
+	// セッション種別
+	"session.type.general":   "一般的なコーディング",
+	"session.type.debugging": "デバッグ作業",
+	"session.type.refactor":  "リファクタリング",
+	"session.type.review":    "コードレビュー",
+	"session.type.learning":  "学習・説明",
+
+	// プロンプトに列挙する構造化タグ
+	"tool.analysis.description":    "プロジェクト/コード分析 (分析系質問では絶対必須)",
+	"tool.analysis.purpose":        "分析・状況確認（最優先）",
+	"tool.command.description":     "Bashコマンド実行",
+	"tool.command.purpose":         "コマンド実行",
+	"tool.filecreate.description":  "ファイル作成",
+	"tool.filecreate.purpose":      "ファイル作成",
+	"tool.patch.description":       "既存ファイルを unified diff で編集（行番号・空白のずれは許容、全ハンクが当たる場合のみ適用）",
+	"tool.patch.purpose":           "ファイル編集",
+	"tool.filemove.description":    "ファイル・ディレクトリの移動・リネーム（追跡中は git mv、vyb refactor undo で取り消し可）",
+	"tool.filemove.purpose":        "ファイル移動",
+	"tool.filedelete.description":  "ユーザーの確認後にファイル・ディレクトリを削除（追跡中は git rm、vyb refactor undo で取り消し可）",
+	"tool.filedelete.purpose":      "ファイル削除",
+	"tool.tree.description":        "パス以下のディレクトリツリーを表示（.gitignore・.vybignore を適用。ls -la・find の代わりに使う）",
+	"tool.tree.purpose":            "ディレクトリ構造",
+	"tool.fileread.description":    "ファイル読み取り",
+	"tool.fileread.purpose":        "ファイル読み取り",
+	"tool.suggestion.description":  "次の作業提案",
+	"tool.suggestion.purpose":      "次の提案",
+	"tool.diagram.description":     "パッケージの依存関係の図を描いて応答に載せる（calls で呼び出している関数も表示）",
+	"tool.diagram.purpose":         "アーキテクチャ図",
+	"tool.jobstart.description":    "開発サーバー等の長時間コマンドをバックグラウンドで起動",
+	"tool.jobstart.purpose":        "バックグラウンド実行",
+	"tool.joboutput.description":   "バックグラウンドジョブの状態と直近の出力を確認",
+	"tool.joboutput.purpose":       "ジョブ出力確認",
+	"tool.jobkill.description":     "バックグラウンドジョブを停止",
+	"tool.jobkill.purpose":         "ジョブ停止",
+	"tool.pin.description":         "設計判断・制約・ファイルをコンテキストに固定（圧縮で除かない）",
+	"tool.pin.purpose":             "合意した判断の保持",
+	"tool.diagnostics.description": "環境に依存しそうな失敗の調査のため、ツールのバージョン（go・node・docker 等）・OS・関係する環境変数（機密情報は伏せ字）・vyb の設定を集める",
+	"tool.diagnostics.purpose":     "環境レポート",
+	"tool.logtail.description":     "ログファイル・journalctl のユニットの末尾を表示し、N 秒間追記を追う（ユーザーがエンドポイントにリクエストを送る間等）。行には error・warn 等の重大度が付く",
+	"tool.logtail.purpose":         "ログの監視",
+	"tool.recall.description":      "このセッションで以前実行したコマンド・ツールの出力を検索（テスト失敗の内容等。再実行の代わりに使う）",
+	"tool.recall.purpose":          "以前の実行結果の参照",
+
+	// 応答
+	"suggestion.applied": "✅ 提案を適用しました！",
+
+	// 提案の適用時の変更の衝突
+	"conflict.detected":              "⚠️ 提案の作成後に %s が変更されたため、適用していません",
+	"conflict.model_edit":            "── 提案の変更（作成時 → 提案）",
+	"conflict.concurrent_change":     "── 並行した変更（作成時 → 現在）",
+	"conflict.current_vs_suggestion": "── 現在の内容 → 提案（作成時の内容は残っていません）",
+	"conflict.deleted":               "── ファイルはその後削除されています",
+	"conflict.options":               "選択してください: [r] 現在の内容から再生成  [f] 強制的に適用  [a] 中止",
+	"conflict.aborted":               "❌ %s への提案を破棄しました",
+	"conflict.forced":                "✅ 並行した変更を承知で %s に提案を適用しました",
+	"conflict.force_unavailable":     "強制適用できません: 置換元のコードが %s になく、作成時の内容も分かりません（再生成してください）",
+
+	// 自動承認ポリシー
+	"approval.auto_applied": "🤖 承認ルール %s により確認なしで適用しました（信頼度 %.2f）",
+	"approval.auto_failed":  "⚠️ 承認ルール %s で自動適用しようとしましたが失敗しました: %v（y で再試行）",
+	"approval.denied":       "🚫 承認ルール %s により、この提案は適用しません",
+
+	// 使用量・コスト
+	"usage.disabled":       "使用量の記録は無効です（vyb config enable-usage true で有効化）",
+	"usage.no_records":     "このセッションの使用量はまだありません",
+	"usage.session_title":  "💰 このセッションの使用量",
+	"usage.line":           "%d 回  入力 %d / 出力 %d トークン  %s",
+	"usage.total":          "合計",
+	"usage.estimated_note": "※ %d 件はトークン数を文字数から推定しています",
+
+	// コンテキスト内訳
+	"context.unavailable":         "このセッションではコンテキスト内訳を表示できません",
+	"context.title":               "📊 コンテキスト使用量 (%s): %d / %d トークン (%.1f%%)",
+	"context.segment.system":      "システムプロンプト",
+	"context.segment.template":    "応答テンプレート",
+	"context.segment.memory":      "メモリ（長期）",
+	"context.segment.context":     "コンテキスト項目",
+	"context.segment.history":     "会話履歴",
+	"context.segment.last_output": "直前の出力",
+	"context.segment_items":       "%s (%d 件)",
+	"context.reserved":            "応答用に確保",
+	"context.remaining":           "残り",
+	"context.items_title":         "コンテキスト項目（重要度順）",
+	"context.more_items":          "… 他 %d 件",
+	"context.stats_title":         "🗜 コンテキスト圧縮（このセッション）",
+	"context.stats_none":          "このセッションではまだ圧縮されていません",
+	"context.stats_count":         "圧縮回数: %d（補完 %d 回）",
+	"context.stats_size":          "サイズ:   %d → %d バイト（%.1f%% 削減）",
+	"context.stats_fidelity":      "忠実度:   平均 %.0f%%、最低 %.0f%%",
+	"context.stats_last":          "直近:     %s、事実 %d 件を照合、忠実度 %.0f%%",
+	"context.stats_fallback":      "検証に失敗したため事実照合で代替: %s",
+	"context.stats_missing":       "直近の要約で失われた事実:",
+	"context.stats_restored":      "キーポイントに補った事実:",
+
+	// 画像添付
+	"vision.attached":            "📎 画像 %d 枚を次のメッセージに添付します",
+	"vision.unsupported_warning": "モデル %s は画像入力に対応していないため、画像は送信されません（llava, qwen2.5vl 等を使用してください）",
+	"vision.unsupported_note":    "（ユーザーは画像を %d 枚添付しましたが、モデル %s は画像入力に対応していないため画像は含まれていません。画像の内容が必要な場合はその旨を伝えてください）",
+
+	// チェックポイント・巻き戻し
+	"rewind.unavailable":  "チェックポイントは無効です（gitリポジトリ外、または vyb config enable-checkpoints false）",
+	"history.usage":       "使い方: /history <検索語>（過去のセッションの会話とツール出力を検索）",
+	"history.no_match":    "「%s」に一致する過去の会話はありません",
+	"history.title":       "🔎 「%s」の検索結果（%d件）",
+	"history.quote_hint":  "/quote <セッション> <ターン> でこのセッションのコンテキストに取り込めます",
+	"history.quote_usage": "使い方: /quote <セッション> <ターン>",
+	"history.unavailable": "このセッションでは過去の会話の引用は利用できません",
+	"history.quoted":      "📎 %s のターン %d を次の応答のコンテキストに追加しました",
+	"save.done":           "💾 %d ターンの会話を %s に保存しました（%s）",
+	"save.unavailable":    "このセッションでは会話の保存は利用できません",
+	"save.empty":          "保存する会話がまだありません",
+	"rewind.empty":        "ファイルを変更したターンのチェックポイントはまだありません",
+	"rewind.title":        "⏪ チェックポイント（/rewind <ターン> でそのターンの開始前に戻します）",
+	"rewind.entry":        "ターン %d  %s  %d ファイル  %s",
+	"rewind.invalid_turn": "ターン番号を指定してください: %s",
+	"rewind.done":         "⏪ ターン %d の開始前に戻しました（会話 %d ターン分を取り消し）",
+	"rewind.files":        "ファイル: 復元 %d / 削除 %d",
+
+	// @メンション
+	"mention.attached":  "@%s を添付しました (%d bytes)",
+	"mention.truncated": "@%s を添付しました（先頭 %d / %d bytes に切り詰め）",
+	"mention.skipped":   "@%s は添付できません: %v",
+
+	// シェルコマンド（!command）
+	"shell.unavailable":   "このセッションではコマンドを直接実行できません",
+	"shell.usage":         "!<コマンド> で直接実行できます（例: !go test ./...）",
+	"shell.done":          "✓ 完了 (%s)",
+	"shell.exit_code":     "✗ 終了コード %d (%s)",
+	"shell.timed_out":     "✗ タイムアウトしました (%s)",
+	"shell.context_added": "出力を次の応答のコンテキストに追加しました",
+
+	// バックグラウンドジョブ
+	"jobs.unavailable":    "このセッションではバックグラウンドジョブを使用できません",
+	"jobs.usage":          "/bg <コマンド> でバックグラウンド実行します（例: /bg npm run dev）",
+	"jobs.started":        "🚀 ジョブ %d を起動しました (pid %d): %s",
+	"jobs.empty":          "バックグラウンドジョブはありません",
+	"jobs.title":          "⚙ バックグラウンドジョブ（/jobs <ID> で出力、/kill <ID> で停止）",
+	"jobs.invalid_id":     "ジョブIDを指定してください: %s",
+	"jobs.output_title":   "📋 ジョブ %d: %s (%s)",
+	"jobs.killed":         "🛑 ジョブ %d を停止しました (%s)",
+	"jobs.status_running": "実行中",
+	"jobs.status_killed":  "停止済み",
+	"jobs.status_exited":  "終了 (コード %d)",
+
+	// /info
+	"info.title":            "ℹ セッション情報",
+	"info.model":            "モデル:       %s",
+	"info.provider":         "プロバイダー: %s (%s)",
+	"info.endpoints":        "LLMエンドポイント（再試行・フェイルオーバー）:",
+	"info.no_resilience":    "LLMエンドポイントの状態は取得できません",
+	"info.state_closed":     "正常",
+	"info.state_open":       "停止中、%s に再試行",
+	"info.state_halfopen":   "回復確認中",
+	"info.active":           "使用中",
+	"info.failures":         "連続 %d 回失敗: %s",
+	"info.concurrency":      "同時実行数:",
+	"info.concurrency_line": "実行中 %d/%d、待機中 %d · 待機 %d 回（合計 %s）",
+	"info.offline":          "オフライン: モデルに接続できず、%d 件の入力を保留中",
+	"info.template":         "テンプレート: %s（%s）",
+	"info.pinned":           "固定ファイル: %s",
+
+	// クラッシュ復元
+	"autosave.found":            "⏪ %s 前に中断されたセッションが見つかりました（%d ターン）",
+	"autosave.unanswered":       "   応答前に中断された入力: %s",
+	"autosave.pending":          "   保留中の提案: %s",
+	"autosave.prompt":           "再開しますか？ [Y/n] ",
+	"autosave.resumed":          "🎯 中断されたセッションを復元しました: %d ターンをコンテキストに追加（新しいセッション %s）",
+	"autosave.pending_restored": "💡 %s への保留中の提案を復元しました — 「y」で適用します",
+	"autosave.discarded":        "中断されたセッションを破棄しました",
+	"autosave.failed":           "⚠ 中断されたセッションを復元できませんでした: %v",
+
+	// 定期メンテナンス（vyb daemon）の要約
+	"maintenance.since_today":     "今日",
+	"maintenance.since_yesterday": "昨日",
+	"maintenance.since_ago":       "%s 前",
+	"maintenance.summary":         "🛠  %sからの定期メンテナンス: %s",
+	"maintenance.new_vulns":       "依存関係に新しい脆弱性 %d 件",
+	"maintenance.no_new_vulns":    "依存関係に新しい脆弱性なし",
+	"maintenance.resolved":        "%d 件解消",
+	"maintenance.stale_branches":  "古いブランチ %d 件",
+	"maintenance.index":           "インデックス更新（%d ファイルを再生成）",
+	"maintenance.fixed_in":        "（%s で修正）",
+	"maintenance.details":         "   詳細: vyb daemon status",
+
+	// ペイン構成のTUI
+	"tui.pane_conversation": "会話",
+	"tui.pane_plan":         "計画",
+	"tui.pane_files":        "ファイル",
+	"tui.pane_jobs":         "ジョブ",
+	"tui.working":           "処理中",
+	"tui.busy":              "前の入力を処理中です（Ctrl+C で中断）",
+	"tui.diff_summary":      "%d ファイル変更 (+%d -%d) — ファイルペインで確認できます",
+	"tui.plan_empty":        "まだ計画はありません。現在のターンのツール実行とTODOリストがここに表示されます。",
+	"tui.plan_request":      "依頼",
+	"tui.plan_steps":        "ステップ",
+	"tui.plan_no_steps":     "ツール実行なし",
+	"tui.plan_todos":        "TODO",
+	"tui.files_empty":       "このセッションで変更したファイルはまだありません",
+	"tui.files_no_diff":     "このファイルの差分は記録されていません（チェックポイント無効？）",
+	"tui.jobs_no_output":    "出力はまだありません",
+	"tui.help_conversation": "Enter 送信 · Tab 補完 · ↑↓ 履歴 · PgUp/PgDn スクロール · Ctrl+O 出力を展開 · Ctrl+Y コードをコピー · Tab（空行）/Alt+1-4 ペイン切替 · Ctrl+C 中断/終了",
+	"tui.fold_hidden":       "%d/%d 行を折り畳み · Ctrl+O で展開",
+	"tui.fold_page":         "%d/%d ページ · %d-%d 行目 / 全 %d 行 · Ctrl+O で次のページ",
+	"tui.fold_collapse":     "出力の終わり · Ctrl+O で折り畳み",
+	"tui.fold_dropped":      "… 先頭の %d 行は破棄しました",
+	"tui.fold_none":         "展開できる折り畳んだ出力はありません",
+	"tui.help_plan":         "j/k スクロール · 1-4 ペイン切替 · Esc 戻る · Ctrl+C 終了",
+	"tui.help_files":        "j/k ファイル選択 · PgUp/PgDn 差分スクロール · t ディレクトリツリー · 1-4 ペイン切替 · Esc 戻る · Ctrl+C 終了",
+	"tui.help_tree":         "j/k・PgUp/PgDn スクロール · t 変更したファイル · 1-4 ペイン切替 · Esc 戻る · Ctrl+C 終了",
+	"tui.help_jobs":         "j/k ジョブ選択 · x 停止 · PgUp/PgDn 出力スクロール · 1-4 ペイン切替 · Esc 戻る · Ctrl+C 終了",
+
+	// 完了通知
+	"notify.complete": "処理が完了しました（%s）",
+	"notify.input":    "確認待ちです（%s）",
+	"notify.error":    "処理が失敗しました（%s）",
+
+	// 影響範囲
+	"impact.summary":    "💥 影響範囲: 直接依存 %d 件、間接依存 %d 件",
+	"impact.none":       "💥 影響範囲: 変更したコードに依存する箇所はありません",
+	"impact.changed":    "  変更: %s",
+	"impact.direct":     "  直接依存:",
+	"impact.transitive": "  間接依存: %s",
+	"impact.tests":      "  影響するテスト: %s",
+	"impact.more":       "… 他 %d 件",
+
+	// 会話の分岐
+	"branch.title":         "🌿 会話の分岐（* = 現在の分岐）",
+	"branch.turns":         "%d ターン",
+	"branch.forked_from":   "%s のターン %d から分岐",
+	"branch.usage":         "使い方: /branch（一覧）・/branch <ターン> [名前]（そのターンの後から分岐、0 = 最初から）・/branch switch|compare|merge <名前>",
+	"branch.unavailable":   "このセッションでは会話の分岐を使えません",
+	"branch.forked":        "🌿 分岐 %s を %s のターン %d の後から作成しました（以降のターンはコンテキストから外れます。ファイルは変更しないため、必要なら /rewind で戻してください）",
+	"branch.switched":      "🌿 分岐 %s に切り替えました（%d ターン）",
+	"branch.compare_title": "🌿 %s と %s の比較（共有 %d ターン）",
+	"branch.no_exchanges":  "分かれた後のやり取りはありません",
+	"branch.merge_hint":    "/branch merge %s でその分岐の結論を現在の分岐に取り込めます",
+	"branch.merged":        "🌿 %d ターン分を %s から %s のコンテキストに取り込みました",
+
+	// 提案の根拠（/why）
+	"why.title":               "🔎 提案 %s の根拠",
+	"why.applied":             "%s に適用済み",
+	"why.not_applied":         "未適用",
+	"why.input":               "依頼: %s",
+	"why.model":               "モデル: %s",
+	"why.context":             "取得したコンテキスト（%d 件）:",
+	"why.context_none":        "取得したコンテキストはありません",
+	"why.files":               "ファイル: %s",
+	"why.analysis":            "分析結果:",
+	"why.confidence":          "信頼度 %.2f:",
+	"why.factor.base":         "ベース信頼度",
+	"why.factor.context":      "コンテキストが豊富（%d 件、5件超）",
+	"why.factor.bugfix":       "バグ修正は比較的信頼度が高い",
+	"why.factor.optimization": "パフォーマンス最適化は慎重に扱う",
+	"why.factor.security":     "セキュリティ修正は重視する",
+	"why.factor.history":      "このセッションでは採用が却下より多い（%d/%d）",
+	"why.factor.extracted":    "応答から抽出した提案の既定値（コードブロックありは 0.85、なしは 0.70）",
+	"why.none":                "このセッションではまだ提案がありません",
+	"why.not_found":           "%s に一致する提案がありません（/why list で一覧）",
+	"why.unavailable":         "このセッションでは提案の根拠を表示できません",
+	"why.list_title":          "🔎 このセッションの提案（/why <ID> で詳細）",
+	"why.usage":               "使い方: /why（最新の提案）· /why list · /why <ID>",
+
+	// オフライン（LLMに到達できない場合）
+	"offline.unreachable":       "⚠ モデルに接続できません: %v",
+	"offline.capabilities":      "オフラインでも使える操作: !<コマンド>（git、grep 等）、/build /test /lint、/bg /jobs、/rewind、/context、/history、/save、\nCLI の vyb git status|diff|log、vyb search、vyb grep、vyb find、vyb analyze。入力はモデルが復帰するまで保留します。",
+	"offline.prompt":            "オフラインモードで続けますか？ [Y/n] ",
+	"offline.started":           "📴 オフラインモード: 入力は保留されます（/queue）、/offline で再接続を試します",
+	"offline.lost":              "⚠ モデルとの接続が切れました（%v）— オフラインモードに切り替えました",
+	"offline.queued":            "⏸ モデルが復帰するまで保留しました（%d 件 · /queue で一覧 · /offline で再接続）",
+	"offline.restored":          "🔌 モデルに再び接続できました",
+	"offline.queue_hint":        "保留中の入力が %d 件あります: /queue run で順に送ります",
+	"offline.online":            "🔌 オンライン: モデルに接続できます",
+	"offline.queue_empty":       "保留中の入力はありません",
+	"offline.queue_title":       "⏸ 保留中の入力（%d 件）",
+	"offline.queue_cleared":     "保留中の入力 %d 件を破棄しました",
+	"offline.still_unreachable": "まだモデルに接続できません: %v",
+	"offline.queue_usage":       "使い方: /queue（一覧）· /queue run（順に送る）· /queue clear",
+
+	// セッションテンプレート（vyb --template）
+	"template.applied":     "🧭 テンプレート %s: %s",
+	"template.plan":        "作業計画:",
+	"template.run_first":   "テンプレートに従い最初に %s を実行します",
+	"template.unavailable": "このセッションはテンプレートに対応していません",
+
+	// ファイルの固定（vyb chat <file>、/pin）
+	"pin.status":      "📌 固定中: %s",
+	"pin.none":        "固定したファイルはありません（/pin <ファイル> で固定すると毎ターン読み直し、編集の既定の対象になります）",
+	"pin.pinned":      "📌 %s を固定しました（毎ターン読み直し、編集の既定の対象にします）",
+	"pin.unpinned":    "%s の固定を解除しました",
+	"pin.all_removed": "全てのファイルの固定を解除しました",
+	"pin.unavailable": "このセッションはファイルの固定に対応していません",
+
+	// コンテキストの固定（/pin-context）
+	"pin_context.title":       "📌 固定したコンテキスト（圧縮で除かれません）",
+	"pin_context.none":        "固定したコンテキストはありません（/pin-context <設計判断・制約> でメモを固定、/context のIDやファイルのパスでその項目を固定）",
+	"pin_context.pinned":      "📌 %s を固定しました（毎ターンのプロンプトに含め、圧縮で除きません）",
+	"pin_context.unpinned":    "%s の固定を解除しました",
+	"pin_context.usage":       "使い方: /unpin-context <ID|ファイル>（/pin-context で一覧）",
+	"pin_context.file":        "ファイル（毎ターン読み直し）",
+	"pin_context.unavailable": "このセッションはコンテキストの固定に対応していません",
+
+	// セッションの題名（/title）
+	"title.current":     "セッションの題名: %s",
+	"title.none":        "まだ題名はありません（最初の応答の後に生成します。/title <題名> で設定できます）",
+	"title.renamed":     "セッションの題名を「%s」に変更しました",
+	"title.unavailable": "このセッションは題名に対応していません",
+
+	// クリップボード（/copy、貼り付けの添付）
+	"copy.copied":       "📋 コードブロック %d/%d（%s、%d 行）をコピーしました（%s）",
+	"copy.no_blocks":    "直前の応答にコードブロックがありません",
+	"copy.out_of_range": "コードブロック %d はありません（直前の応答には %d 個）",
+	"copy.usage":        "使い方: /copy [n]（直前の応答の n 番目のコードブロック、省略時は最後）",
+	"paste.attached":    "貼り付けたテキスト %s（%d バイト）をコンテキストとして添付しました",
+
+	// 長い出力の折り畳み
+	"fold.hidden":    "%d/%d 行を折り畳みました · /expand でページ単位、/expand all で全て表示",
+	"fold.none":      "折り畳んだ出力はありません（ペイン構成のTUIでは Ctrl+O で展開します）",
+	"fold.page":      "出力 %d/%d ページ（%d-%d 行目 / 全 %d 行）",
+	"fold.next_page": "/expand で次のページ · /expand all で全て表示",
+	"fold.end":       "出力の終わり",
+
+	// ターンの資源上限
+	"budget.exceeded":        "ターンの上限を超えました: %sの上限（%s）に達しました",
+	"budget.kind.tool_calls": "ツール実行回数",
+	"budget.kind.tokens":     "トークン数",
+	"budget.kind.wall_time":  "経過時間",
+	"budget.usage":           "このターンの使用量: ツール実行 %d 回、%d トークン、%s",
+	"budget.done_so_far":     "ここまでに行った操作:",
+	"budget.no_actions":      "上限に達するまでにツールは実行していません。",
+	"budget.continue_hint":   "続けるにはもう一度メッセージを送ってください。上限は `vyb config set-turn-limits` で変更できます。",
+
+	// ワークスペース（モノレポ）
+	"workspace.title":    "📦 %s のモジュール（/workspace <モジュール> で切替、/workspace / でリポジトリのルート）",
+	"workspace.none":     "%s に Go モジュール・npm ワークスペースが見つかりません",
+	"workspace.switched": "📂 作業範囲を %s (%s) に切り替えました",
+
+	// リモート開発
+	"remote.connecting": "🔌 %s に SSH で接続しています...",
+	"remote.connected":  "🔌 リモートの作業ディレクトリ %s:%s（%d 個のツールをリモートで実行）",
+
+	// エラー
+	"error.session_not_found":      "セッション %s が見つかりません",
+	"error.prompt_template":        "プロンプトテンプレートエラー: %v",
+	"error.build_system_not_found": "ビルドシステムを検出できません: %s",
+	"error.task_command_missing":   "%s に %s コマンドがありません",
+	"error.task_command_build":     "コマンド構築エラー: %w",
+	"error.task_timeout":           "%s がタイムアウトまたはキャンセルされました: %w",
+	"error.task_exec":              "%s の実行エラー: %w",
+	"error.invalid_language":       "無効な言語です。ja, en, auto のいずれかを指定してください",
+}
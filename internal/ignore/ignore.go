@@ -0,0 +1,362 @@
+// Package ignore はプロジェクトのファイル走査で除外するパスを判定する
+// （組み込みの除外パターン、設定の ignore.patterns、.gitignore・.vybignore・.git/info/exclude）。
+// パターンの書式は .gitignore と同じで、後に書かれたパターンが優先される（!pattern で除外を取り消す）。
+package ignore
+
+import (
+	"bufio"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// FileName はプロジェクト毎の除外パターンを書くファイル（.gitignore と同じ書式）
+const FileName = ".vybignore"
+
+// 除外パターンの出所（Rule.Source）
+const (
+	SourceBuiltin = "built-in"
+	SourceConfig  = "config"
+)
+
+// DefaultPatterns は常に除外するパターン（.vybignore に !vendor/ のように書けば取り消せる）
+// build・target はソースのパッケージ名にも使われるため、ルート直下のビルド出力だけを除外する
+func DefaultPatterns() []string {
+	return []string{
+		".git/", ".hg/", ".svn/",
+		"node_modules/", "vendor/", "dist/", "/build/", "/target/",
+		"__pycache__/", ".venv/", ".idea/", ".DS_Store",
+	}
+}
+
+var (
+	mu             sync.RWMutex
+	configPatterns []string
+)
+
+// SetPatterns は設定（ignore.patterns）で追加した除外パターンを登録する（起動時に設定を読み込んだ後に呼ぶ）
+func SetPatterns(patterns []string) {
+	mu.Lock()
+	defer mu.Unlock()
+	configPatterns = append([]string(nil), patterns...)
+}
+
+// Patterns は設定で追加された除外パターン
+func Patterns() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+	return append([]string(nil), configPatterns...)
+}
+
+// Rule は除外パターン1つ
+type Rule struct {
+	Pattern string // 書かれたとおりのパターン
+	Source  string // built-in、config、またはパターンを書いたファイル（ルートからの相対パス:行番号）
+	Negate  bool   // !pattern（除外の取り消し）
+
+	dir     string // パターンを書いたファイルのディレクトリ（ルートからの相対、ルートは ""）
+	dirOnly bool   // 末尾の / （ディレクトリのみに一致）
+	re      *regexp.Regexp
+}
+
+// Matcher はディレクトリ毎の ignore ファイルを必要になった時に読み込んで除外を判定する
+type Matcher struct {
+	root   string // gitリポジトリのルート（リポジトリ外なら走査のルート）
+	global []*Rule
+
+	mu   sync.Mutex
+	dirs map[string][]*Rule // ディレクトリ（ルートからの相対）毎の .gitignore・.vybignore のルール
+}
+
+// New は dir を含むプロジェクトの Matcher を作成する
+// dir を含む gitリポジトリがあればそのルートを基準にし、上位ディレクトリの .gitignore も適用する
+func New(dir string) *Matcher {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		root = dir
+	}
+	for current := root; ; current = filepath.Dir(current) {
+		if _, err := os.Stat(filepath.Join(current, ".git")); err == nil {
+			root = current
+			break
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+
+	m := &Matcher{root: root, dirs: make(map[string][]*Rule)}
+	for _, pattern := range DefaultPatterns() {
+		if rule := parseRule(pattern, "", SourceBuiltin); rule != nil {
+			m.global = append(m.global, rule)
+		}
+	}
+	for _, pattern := range Patterns() {
+		if rule := parseRule(pattern, "", SourceConfig); rule != nil {
+			m.global = append(m.global, rule)
+		}
+	}
+	return m
+}
+
+// Root は除外パターンの基準ディレクトリ
+func (m *Matcher) Root() string {
+	return m.root
+}
+
+// Ignored は path（絶対パスまたは作業ディレクトリからの相対パス）を除外するか判定する
+// 除外されたディレクトリの中のパスも除外する
+func (m *Matcher) Ignored(path string, isDir bool) bool {
+	rule := m.Match(path, isDir)
+	return rule != nil && !rule.Negate
+}
+
+// Match は path の除外を決めたルールを返す（どのルールにも一致しなければ nil、Negate なら除外しない）
+// 除外されたディレクトリの中のパスはそのディレクトリを除外したルールを返す
+func (m *Matcher) Match(path string, isDir bool) *Rule {
+	rel, ok := m.relative(path)
+	if !ok {
+		return nil
+	}
+	parts := strings.Split(rel, "/")
+	for i := 1; i < len(parts); i++ {
+		if rule := m.match(strings.Join(parts[:i], "/"), true); rule != nil && !rule.Negate {
+			return rule
+		}
+	}
+	return m.match(rel, isDir)
+}
+
+// relative は path をルートからのスラッシュ区切りの相対パスにする（ルート外・ルート自身なら false）
+func (m *Matcher) relative(path string) (string, bool) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(m.root, abs)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// match は rel 自身に一致する最後のルールを返す（親ディレクトリは見ない）
+func (m *Matcher) match(rel string, isDir bool) *Rule {
+	var matched *Rule
+	for _, rule := range m.global {
+		if rule.matches(rel, isDir) {
+			matched = rule
+		}
+	}
+
+	// ルートから rel の親ディレクトリまでの ignore ファイルを浅い順に適用
+	dirs := []string{""}
+	for i := 0; i < len(rel); i++ {
+		if rel[i] == '/' {
+			dirs = append(dirs, rel[:i])
+		}
+	}
+	for _, dir := range dirs {
+		for _, rule := range m.dirRules(dir) {
+			if rule.matches(rel, isDir) {
+				matched = rule
+			}
+		}
+	}
+	return matched
+}
+
+// dirRules はディレクトリの .gitignore・.vybignore（ルートでは .git/info/exclude も）のルールを読み込んで返す
+func (m *Matcher) dirRules(dir string) []*Rule {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if rules, ok := m.dirs[dir]; ok {
+		return rules
+	}
+
+	files := []string{".gitignore", FileName}
+	if dir == "" {
+		files = append([]string{filepath.Join(".git", "info", "exclude")}, files...)
+	}
+	var rules []*Rule
+	for _, name := range files {
+		rules = append(rules, readRules(m.root, dir, name)...)
+	}
+	m.dirs[dir] = rules
+	return rules
+}
+
+// readRules は ignore ファイル1つのルールを読み込む（存在しなければ空）
+func readRules(root, dir, name string) []*Rule {
+	rel := filepath.ToSlash(filepath.Join(dir, name))
+	file, err := os.Open(filepath.Join(root, filepath.FromSlash(rel)))
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var rules []*Rule
+	scanner := bufio.NewScanner(file)
+	for line := 1; scanner.Scan(); line++ {
+		if rule := parseRule(scanner.Text(), dir, rel+":"+strconv.Itoa(line)); rule != nil {
+			rules = append(rules, rule)
+		}
+	}
+	return rules
+}
+
+// parseRule は .gitignore の1行をルールにする（空行・コメント・不正なパターンは nil）
+func parseRule(line, dir, source string) *Rule {
+	line = strings.TrimRight(line, "\r")
+	// 末尾の空白はエスケープされていなければ無視
+	for strings.HasSuffix(line, " ") && !strings.HasSuffix(line, `\ `) {
+		line = line[:len(line)-1]
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return nil
+	}
+
+	rule := &Rule{Pattern: line, Source: source, dir: dir}
+	pattern := line
+	if strings.HasPrefix(pattern, "!") {
+		rule.Negate = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "/") {
+		rule.dirOnly = true
+		pattern = strings.TrimRight(pattern, "/")
+	}
+	// 途中に / を含むパターンはファイルのあるディレクトリからの相対パス、含まなければ任意の階層の名前に一致
+	anchored := strings.Contains(pattern, "/")
+	pattern = strings.TrimPrefix(pattern, "/")
+	if pattern == "" {
+		return nil
+	}
+
+	re, err := regexp.Compile(globToRegexp(pattern, anchored))
+	if err != nil {
+		return nil
+	}
+	rule.re = re
+	return rule
+}
+
+// globToRegexp は .gitignore のパターンを正規表現にする
+func globToRegexp(pattern string, anchored bool) string {
+	var sb strings.Builder
+	sb.WriteString("^")
+	if !anchored {
+		sb.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' &&
+				(i == 0 || pattern[i-1] == '/') && (i+2 == len(pattern) || pattern[i+2] == '/') {
+				if i+2 == len(pattern) {
+					// 末尾の ** は中身全て
+					sb.WriteString(".*")
+					i++
+				} else {
+					// **/ は0個以上のディレクトリ
+					sb.WriteString("(?:.*/)?")
+					i += 2
+				}
+				continue
+			}
+			for i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+			}
+			sb.WriteString("[^/]*")
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			// 先頭の ! と ] は文字クラスの一部
+			start := i + 1
+			if start < len(pattern) && pattern[start] == '!' {
+				start++
+			}
+			if start < len(pattern) && pattern[start] == ']' {
+				start++
+			}
+			end := strings.IndexByte(pattern[start:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := pattern[i+1 : start+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i = start + end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+				sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	sb.WriteString("$")
+	return sb.String()
+}
+
+// matches は rel（ルートからの相対パス）自身がルールに一致するか判定
+func (r *Rule) matches(rel string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.dir != "" {
+		if !strings.HasPrefix(rel, r.dir+"/") {
+			return false
+		}
+		rel = rel[len(r.dir)+1:]
+	}
+	return r.re.MatchString(rel)
+}
+
+// Walk は filepath.Walk と同じだが、除外されたファイルを fn に渡さず、除外されたディレクトリの中に入らない
+// root 自身は除外の対象にしない（明示的に指定されたディレクトリは走査する）
+func Walk(root string, fn filepath.WalkFunc) error {
+	m := New(root)
+	return filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && path != root && m.skip(path, info.IsDir()) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path, info, err)
+	})
+}
+
+// WalkDir は filepath.WalkDir と同じだが、除外されたファイルを fn に渡さず、除外されたディレクトリの中に入らない
+func WalkDir(root string, fn fs.WalkDirFunc) error {
+	m := New(root)
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && path != root && m.skip(path, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		return fn(path, d, err)
+	})
+}
+
+// skip は走査中のパスを除外するか判定する（親ディレクトリは走査済みのため見ない）
+func (m *Matcher) skip(path string, isDir bool) bool {
+	rel, ok := m.relative(path)
+	if !ok {
+		return false
+	}
+	rule := m.match(rel, isDir)
+	return rule != nil && !rule.Negate
+}
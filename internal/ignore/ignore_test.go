@@ -0,0 +1,166 @@
+package ignore
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPatternSyntax(t *testing.T) {
+	tests := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		want    bool
+	}{
+		{"*.log", "a.log", false, true},
+		{"*.log", "deep/dir/a.log", false, true},
+		{"*.log", "a.log.txt", false, false},
+		{"/TODO", "TODO", false, true},
+		{"/TODO", "sub/TODO", false, false},
+		{"doc/*.txt", "doc/notes.txt", false, true},
+		{"doc/*.txt", "doc/server/arch.txt", false, false},
+		{"tmp/", "tmp", true, true},
+		{"tmp/", "tmp", false, false},
+		{"tmp/", "src/tmp", true, true},
+		{"**/foo", "a/b/foo", false, true},
+		{"**/foo", "foo", false, true},
+		{"abc/**", "abc/x/y", false, true},
+		{"abc/**", "abc", true, false},
+		{"a/**/b", "a/b", false, true},
+		{"a/**/b", "a/x/y/b", false, true},
+		{"file[0-9].go", "file3.go", false, true},
+		{"file[!0-9].go", "file3.go", false, false},
+		{"file?.go", "fileA.go", false, true},
+		{`\#notes`, "#notes", false, true},
+		{"generated.go   ", "generated.go", false, true},
+	}
+	for _, tt := range tests {
+		rule := parseRule(tt.pattern, "", SourceConfig)
+		if rule == nil {
+			t.Errorf("%q: not parsed", tt.pattern)
+			continue
+		}
+		if got := rule.matches(tt.path, tt.isDir); got != tt.want {
+			t.Errorf("%q vs %q (dir=%t): got %t, want %t", tt.pattern, tt.path, tt.isDir, got, tt.want)
+		}
+	}
+	for _, line := range []string{"", "# comment", "   ", "/"} {
+		if parseRule(line, "", SourceConfig) != nil {
+			t.Errorf("%q should not be a rule", line)
+		}
+	}
+}
+
+func TestMatcher(t *testing.T) {
+	root := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	writeFiles(t, root, map[string]string{
+		".gitignore":               "*.log\n/bin/\n",
+		".vybignore":               "fixtures/\n!vendor/\n",
+		"src/.gitignore":           "local.go\n!keep.log\n",
+		"src/main.go":              "",
+		"src/local.go":             "",
+		"src/keep.log":             "",
+		"src/other.log":            "",
+		"bin/tool":                 "",
+		"node_modules/x.js":        "",
+		"vendor/dep/dep.go":        "",
+		"testdata/fixtures/a.json": "",
+	})
+	SetPatterns([]string{"*.gen.go"})
+	defer SetPatterns(nil)
+
+	m := New(filepath.Join(root, "src"))
+	if m.Root() != root {
+		t.Fatalf("root should be the git repository: %s", m.Root())
+	}
+	tests := []struct {
+		path  string
+		isDir bool
+		want  bool
+	}{
+		{"src/main.go", false, false},
+		{"src/local.go", false, true},             // src/.gitignore
+		{"src/other.log", false, true},            // .gitignore
+		{"src/keep.log", false, false},            // src/.gitignore の ! が優先
+		{"bin/tool", false, true},                 // 除外されたディレクトリの中
+		{"node_modules/x.js", false, true},        // 組み込み
+		{"vendor/dep/dep.go", false, false},       // .vybignore で組み込みを取り消し
+		{"testdata/fixtures/a.json", false, true}, // .vybignore のディレクトリ
+		{"api/types.gen.go", false, true},         // 設定
+	}
+	for _, tt := range tests {
+		if got := m.Ignored(filepath.Join(root, filepath.FromSlash(tt.path)), tt.isDir); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.path, got, tt.want)
+		}
+	}
+
+	rule := m.Match(filepath.Join(root, "src", "local.go"), false)
+	if rule == nil || rule.Source != "src/.gitignore:1" || rule.Pattern != "local.go" {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+	if rule := m.Match(filepath.Join(root, "node_modules", "x.js"), false); rule == nil || rule.Source != SourceBuiltin {
+		t.Errorf("unexpected rule: %+v", rule)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	root := t.TempDir()
+	writeFiles(t, root, map[string]string{
+		".vybignore":          "*.tmp\n",
+		"main.go":             "",
+		"scratch.tmp":         "",
+		"node_modules/a/b.js": "",
+		"dist/app.js":         "",
+		"pkg/util.go":         "",
+		"build/out.o":         "",
+		"target/debug/app":    "",
+		"internal/build/x.go": "",
+	})
+
+	var files []string
+	err := Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			rel, _ := filepath.Rel(root, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(files)
+	if got := strings.Join(files, ","); got != ".vybignore,internal/build/x.go,main.go,pkg/util.go" {
+		t.Errorf("unexpected files: %s", got)
+	}
+
+	// 明示的に指定した除外ディレクトリは走査する
+	files = nil
+	WalkDir(filepath.Join(root, "dist"), func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			files = append(files, filepath.Base(path))
+		}
+		return err
+	})
+	if strings.Join(files, ",") != "app.js" {
+		t.Errorf("explicit root should be walked: %v", files)
+	}
+}
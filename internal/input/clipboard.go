@@ -0,0 +1,123 @@
+package input
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// clipboardCommand はクリップボードを読み書きする外部コマンド
+type clipboardCommand struct {
+	name string
+	args []string
+}
+
+// clipboardImageCommands は画像をPNGで標準出力に書き出すコマンド（先頭から順に利用可能なものを使う）
+func clipboardImageCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{
+			{"pngpaste", []string{"-"}},
+		}
+	case "windows":
+		return []clipboardCommand{
+			{"powershell", []string{"-NoProfile", "-Command",
+				"Add-Type -AssemblyName System.Windows.Forms; $img = [Windows.Forms.Clipboard]::GetImage(); if ($img) { $ms = New-Object IO.MemoryStream; $img.Save($ms, [Drawing.Imaging.ImageFormat]::Png); [Console]::OpenStandardOutput().Write($ms.ToArray(), 0, $ms.Length) }"}},
+		}
+	default:
+		return []clipboardCommand{
+			{"wl-paste", []string{"--no-newline", "--type", "image/png"}},
+			{"xclip", []string{"-selection", "clipboard", "-t", "image/png", "-o"}},
+		}
+	}
+}
+
+// ReadClipboardImage はクリップボードの画像をPNGデータとして取得
+func ReadClipboardImage() ([]byte, error) {
+	var tried []string
+	for _, command := range clipboardImageCommands() {
+		if _, err := exec.LookPath(command.name); err != nil {
+			tried = append(tried, command.name)
+			continue
+		}
+
+		var stdout bytes.Buffer
+		cmd := exec.Command(command.name, command.args...)
+		cmd.Stdout = &stdout
+		if err := cmd.Run(); err != nil {
+			return nil, fmt.Errorf("クリップボード読み取りエラー (%s): %w", command.name, err)
+		}
+		if stdout.Len() == 0 {
+			return nil, fmt.Errorf("クリップボードに画像がありません")
+		}
+		return stdout.Bytes(), nil
+	}
+	return nil, fmt.Errorf("クリップボードから画像を取得するコマンドが見つかりません: %v", tried)
+}
+
+// clipboardWriteCommands はテキストを標準入力からクリップボードに書き込むコマンド（OS毎、先頭から順に試行）
+func clipboardWriteCommands() []clipboardCommand {
+	switch runtime.GOOS {
+	case "darwin":
+		return []clipboardCommand{
+			{"pbcopy", nil},
+		}
+	case "windows":
+		return []clipboardCommand{
+			{"clip", nil},
+		}
+	default:
+		return []clipboardCommand{
+			{"wl-copy", nil},
+			{"xclip", []string{"-selection", "clipboard"}},
+			{"xsel", []string{"--clipboard", "--input"}},
+		}
+	}
+}
+
+// WriteClipboard はテキストをクリップボードにコピーし、使った方法を返す
+// 端末へは OSC 52 を送り（SSH 越しでも手元の端末のクリップボードに届く）、OSのクリップボードコマンドがあれば併用する
+func WriteClipboard(text string) ([]string, error) {
+	var methods []string
+	if writeOSC52(text) {
+		methods = append(methods, "OSC 52")
+	}
+
+	var lastErr error
+	for _, command := range clipboardWriteCommands() {
+		if _, err := exec.LookPath(command.name); err != nil {
+			continue
+		}
+		cmd := exec.Command(command.name, command.args...)
+		cmd.Stdin = strings.NewReader(text)
+		if err := cmd.Run(); err != nil {
+			lastErr = fmt.Errorf("クリップボード書き込みエラー (%s): %w", command.name, err)
+			continue
+		}
+		methods = append(methods, command.name)
+		break
+	}
+
+	if len(methods) == 0 {
+		if lastErr != nil {
+			return nil, lastErr
+		}
+		return nil, fmt.Errorf("クリップボードに書き込む方法がありません（端末が OSC 52 に非対応で、pbcopy・wl-copy・xclip・xsel・clip も見つかりません）")
+	}
+	return methods, nil
+}
+
+// writeOSC52 は制御端末に OSC 52 のシーケンスを送る（端末がなければ false）
+func writeOSC52(text string) bool {
+	tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0)
+	if err != nil {
+		return false
+	}
+	defer tty.Close()
+	_, err = fmt.Fprintf(tty, "\033]52;c;%s\a", base64.StdEncoding.EncodeToString([]byte(text)))
+	return err == nil
+}
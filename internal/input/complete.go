@@ -0,0 +1,290 @@
+package input
+
+import (
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 補完に使うファイル・ブランチ一覧の保持期間と件数の上限
+const (
+	completionListTTL     = 10 * time.Second
+	completionFileLimit   = 20000
+	completionMaxListSize = 50
+)
+
+// slashCommand はスラッシュコマンドと引数の補完方法
+type slashCommand struct {
+	name        string
+	description string
+	args        []string // 固定の引数候補
+	fileArg     bool     // 引数にファイルパスを取る
+}
+
+// slashCommands はチャットで使えるスラッシュコマンド
+var slashCommands = []slashCommand{
+	{name: "/help", description: "ヘルプ表示"},
+	{name: "/clear", description: "画面クリア"},
+	{name: "/history", description: "過去のセッションを検索"},
+	{name: "/status", description: "ステータス表示"},
+	{name: "/info", description: "情報表示"},
+	{name: "/save", description: "会話をMarkdown/HTMLで保存", fileArg: true},
+	{name: "/quote", description: "過去のやり取りをコンテキストに引用"},
+	{name: "/retry", description: "再実行"},
+	{name: "/edit", description: "編集モード"},
+	{name: "/build", description: "ビルド実行"},
+	{name: "/test", description: "テスト実行"},
+	{name: "/lint", description: "リント実行"},
+	{name: "/cost", description: "使用量・コスト表示"},
+	{name: "/context", description: "コンテキスト内訳表示", args: []string{"stats"}},
+	{name: "/image", description: "画像を添付", fileArg: true},
+	{name: "/paste", description: "クリップボードの画像を添付"},
+	{name: "/copy", description: "コードブロックをコピー"},
+	{name: "/expand", description: "折り畳んだ出力を展開"},
+	{name: "/rewind", description: "チェックポイントへ巻き戻し"},
+	{name: "/branch", description: "会話の分岐・切替・比較", args: []string{"switch", "compare", "merge"}},
+	{name: "/why", description: "提案の根拠表示", args: []string{"list"}},
+	{name: "/offline", description: "オフライン状態表示・再接続"},
+	{name: "/queue", description: "保留中の入力", args: []string{"run", "clear"}},
+	{name: "/pin", description: "ファイルを固定", fileArg: true},
+	{name: "/unpin", description: "ファイルの固定を解除", fileArg: true},
+	{name: "/pin-context", description: "コンテキストを固定（圧縮で除かない）", fileArg: true},
+	{name: "/unpin-context", description: "コンテキストの固定を解除", fileArg: true},
+	{name: "/title", description: "セッションの題名を表示・変更"},
+	{name: "/bg", description: "バックグラウンド実行"},
+	{name: "/jobs", description: "バックグラウンドジョブ一覧"},
+	{name: "/kill", description: "バックグラウンドジョブ停止"},
+	{name: "/workspace", description: "モノレポのモジュール一覧・切替"},
+	{name: "/exit", description: "終了"},
+	{name: "/quit", description: "終了"},
+}
+
+// gitPromptPattern はブランチ名を補完する git 関連の入力
+var gitPromptPattern = regexp.MustCompile(`(?i)(^|\s)(git|branch|checkout|switch|merge|rebase|cherry-pick|diff|log)(\s|$)|ブランチ`)
+
+// Complete は入力のカーソル位置までの最後の単語を補完する
+// 置き換える単語の開始位置（ルーン単位）と候補を返す。候補の Text は単語全体の置き換え後の文字列
+func (ac *AdvancedCompleter) Complete(line string) (int, []CompletionCandidate) {
+	runes := []rune(line)
+	start := len(runes)
+	for start > 0 && runes[start-1] != ' ' && runes[start-1] != '\t' {
+		start--
+	}
+	word := string(runes[start:])
+	before := strings.TrimSpace(string(runes[:start]))
+
+	var candidates []CompletionCandidate
+	switch {
+	case before == "" && strings.HasPrefix(word, "/"):
+		candidates = ac.completeSlashCommand(word)
+	case strings.HasPrefix(before, "/"):
+		candidates = ac.completeSlashArgument(strings.Fields(before)[0], word)
+	default:
+		candidates = ac.completeWord(before, word)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return candidates[i].Text < candidates[j].Text
+	})
+	candidates = ac.removeDuplicates(candidates)
+	if len(candidates) > completionMaxListSize {
+		candidates = candidates[:completionMaxListSize]
+	}
+	return start, candidates
+}
+
+// CompleteLine はカーソル位置の単語を補完した行とカーソル位置を返す
+// 候補が1つなら確定（ディレクトリ以外は空白を付ける）、複数なら共通部分まで補完する。
+// 複数の候補があって補完が進まない場合は行を変えずに候補を返す
+func (ac *AdvancedCompleter) CompleteLine(line []rune, cursor int) ([]rune, int, []CompletionCandidate) {
+	cursor = clampInt(cursor, 0, len(line))
+	start, candidates := ac.Complete(string(line[:cursor]))
+	if len(candidates) == 0 {
+		return line, cursor, nil
+	}
+
+	replacement := []rune(CommonPrefix(candidates))
+	if len(candidates) > 1 && len(replacement) <= cursor-start {
+		return line, cursor, candidates
+	}
+	if len(candidates) == 1 && !strings.HasSuffix(candidates[0].Text, "/") {
+		replacement = append(replacement, ' ')
+	}
+
+	completed := append(append(append([]rune{}, line[:start]...), replacement...), line[cursor:]...)
+	return completed, start + len(replacement), nil
+}
+
+// SetToolNames はツール名（MCPツールは server.tool）と説明の取得元を設定
+func (ac *AdvancedCompleter) SetToolNames(source func() map[string]string) {
+	ac.toolNames = source
+}
+
+// SetWorkDir は補完の基準ディレクトリを切り替える（/workspace でのモジュール切替）
+func (ac *AdvancedCompleter) SetWorkDir(workDir string) {
+	ac.listMu.Lock()
+	defer ac.listMu.Unlock()
+	ac.workDir = workDir
+	ac.cache = NewCompletionCache()
+	ac.gitCompleter = NewGitCompleter(workDir)
+	ac.projectAnalyzer = NewProjectAnalyzer(workDir)
+	ac.projectFiles = nil
+	ac.branches = nil
+}
+
+// completeSlashCommand はスラッシュコマンド名を補完
+func (ac *AdvancedCompleter) completeSlashCommand(word string) []CompletionCandidate {
+	var candidates []CompletionCandidate
+	for _, command := range slashCommands {
+		if strings.HasPrefix(command.name, word) {
+			candidates = append(candidates, CompletionCandidate{
+				Text:        command.name,
+				Description: command.description,
+				Type:        CompletionCommand,
+				Score:       0.9,
+			})
+		}
+	}
+	return candidates
+}
+
+// completeSlashArgument はスラッシュコマンドの引数を補完
+func (ac *AdvancedCompleter) completeSlashArgument(name, word string) []CompletionCandidate {
+	var candidates []CompletionCandidate
+	for _, command := range slashCommands {
+		if command.name != name {
+			continue
+		}
+		for _, arg := range command.args {
+			if strings.HasPrefix(arg, word) {
+				candidates = append(candidates, CompletionCandidate{
+					Text:        arg,
+					Description: command.description,
+					Type:        CompletionCommand,
+					Score:       0.9,
+				})
+			}
+		}
+		if command.fileArg {
+			candidates = append(candidates, ac.completePath(word)...)
+		}
+	}
+	return candidates
+}
+
+// completeWord は通常の入力中の単語を補完（ファイルパス・git ブランチ・ツール名）
+func (ac *AdvancedCompleter) completeWord(before, word string) []CompletionCandidate {
+	if word == "" {
+		return nil
+	}
+
+	var candidates []CompletionCandidate
+	if gitPromptPattern.MatchString(before) {
+		for _, branch := range ac.listBranches() {
+			if strings.HasPrefix(branch, word) {
+				candidates = append(candidates, CompletionCandidate{
+					Text:        branch,
+					Description: "ブランチ",
+					Type:        CompletionGitBranch,
+					Score:       0.95,
+				})
+			}
+		}
+	}
+
+	if ac.toolNames != nil {
+		for name, description := range ac.toolNames() {
+			if strings.HasPrefix(name, word) {
+				candidates = append(candidates, CompletionCandidate{
+					Text:        name,
+					Description: description,
+					Type:        CompletionTool,
+					Score:       0.8,
+				})
+			}
+		}
+	}
+
+	return append(candidates, ac.completePath(word)...)
+}
+
+// completePath はワークスペースからの相対パスを次の区切りまで補完（.gitignore の対象は除く）
+// @path の形式にも対応する
+func (ac *AdvancedCompleter) completePath(word string) []CompletionCandidate {
+	mention := ""
+	if strings.HasPrefix(word, "@") {
+		mention, word = "@", word[1:]
+	}
+	prefix := strings.TrimPrefix(word, "./")
+	dot := word[:len(word)-len(prefix)]
+
+	seen := make(map[string]bool)
+	var candidates []CompletionCandidate
+	for _, file := range ac.listProjectFiles() {
+		if !strings.HasPrefix(file, prefix) {
+			continue
+		}
+		text, isDir := file, false
+		if i := strings.Index(file[len(prefix):], "/"); i >= 0 {
+			text, isDir = file[:len(prefix)+i+1], true
+		}
+		if seen[text] {
+			continue
+		}
+		seen[text] = true
+
+		candidate := CompletionCandidate{
+			Text:        mention + dot + text,
+			Description: getFileTypeDescription(filepath.Ext(text)),
+			Type:        CompletionFile,
+			Score:       0.7,
+		}
+		if isDir {
+			candidate.Description = "ディレクトリ"
+		}
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
+
+// listProjectFiles はプロジェクトのファイル一覧（一定時間保持）
+func (ac *AdvancedCompleter) listProjectFiles() []string {
+	ac.listMu.Lock()
+	defer ac.listMu.Unlock()
+	if ac.projectFiles == nil || time.Since(ac.projectFiles.timestamp) > completionListTTL {
+		ac.projectFiles = &CacheEntry{data: ListProjectFiles(ac.workDir, completionFileLimit), timestamp: time.Now()}
+	}
+	return ac.projectFiles.data
+}
+
+// listBranches はローカル・リモートのブランチ一覧（一定時間保持）
+func (ac *AdvancedCompleter) listBranches() []string {
+	ac.listMu.Lock()
+	defer ac.listMu.Unlock()
+	if ac.branches == nil || time.Since(ac.branches.timestamp) > completionListTTL {
+		ac.branches = &CacheEntry{data: ac.gitCompleter.getBranches(), timestamp: time.Now()}
+	}
+	return ac.branches.data
+}
+
+// CommonPrefix は候補に共通する先頭部分
+func CommonPrefix(candidates []CompletionCandidate) string {
+	if len(candidates) == 0 {
+		return ""
+	}
+	prefix := []rune(candidates[0].Text)
+	for _, candidate := range candidates[1:] {
+		text := []rune(candidate.Text)
+		n := 0
+		for n < len(prefix) && n < len(text) && prefix[n] == text[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	return string(prefix)
+}
@@ -0,0 +1,152 @@
+package input
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newTestRepo は main・develop・feature/login ブランチと .gitignore を持つ一時リポジトリを作成
+func newTestRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git が見つかりません")
+	}
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":                   "module example.com/app\n",
+		"main.go":                  "package main\n",
+		"internal/app/app.go":      "package app\n",
+		"internal/app/app_test.go": "package app\n",
+		"internal/api/api.go":      "package api\n",
+		".gitignore":               "dist/\n",
+		"dist/bundle.js":           "ignored\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(path), 0755)
+		os.WriteFile(path, []byte(content), 0644)
+	}
+	run := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Dir = dir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	run("add", ".")
+	run("commit", "-q", "-m", "init")
+	run("branch", "develop")
+	run("branch", "feature/login")
+	os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0644)
+	return dir
+}
+
+func candidateTexts(candidates []CompletionCandidate) []string {
+	var texts []string
+	for _, candidate := range candidates {
+		texts = append(texts, candidate.Text)
+	}
+	return texts
+}
+
+func TestComplete_SlashCommands(t *testing.T) {
+	completer := NewAdvancedCompleter(t.TempDir())
+
+	start, candidates := completer.Complete("/he")
+	if start != 0 || strings.Join(candidateTexts(candidates), ",") != "/help" {
+		t.Errorf("スラッシュコマンド名を補完するはず: start=%d %v", start, candidateTexts(candidates))
+	}
+
+	start, candidates = completer.Complete("/context st")
+	if start != 9 || strings.Join(candidateTexts(candidates), ",") != "stats" {
+		t.Errorf("スラッシュコマンドの引数を補完するはず: start=%d %v", start, candidateTexts(candidates))
+	}
+
+	if _, candidates := completer.Complete("please /he"); len(candidates) != 0 {
+		t.Errorf("行頭以外の / はコマンドとして補完しないはず: %v", candidateTexts(candidates))
+	}
+}
+
+func TestComplete_FilePaths(t *testing.T) {
+	completer := NewAdvancedCompleter(newTestRepo(t))
+
+	start, candidates := completer.Complete("look at inter")
+	if start != 8 || strings.Join(candidateTexts(candidates), ",") != "internal/" {
+		t.Errorf("ディレクトリを区切りまで補完するはず: start=%d %v", start, candidateTexts(candidates))
+	}
+
+	_, candidates = completer.Complete("look at internal/a")
+	if got := strings.Join(candidateTexts(candidates), ","); got != "internal/api/,internal/app/" {
+		t.Errorf("同じ階層の候補を重複なく返すはず: %s", got)
+	}
+
+	_, candidates = completer.Complete("/image ./internal/app/app_")
+	if got := strings.Join(candidateTexts(candidates), ","); got != "./internal/app/app_test.go" {
+		t.Errorf("ファイル引数のコマンドは ./ 付きのパスも補完するはず: %s", got)
+	}
+
+	_, candidates = completer.Complete("explain @ma")
+	if got := strings.Join(candidateTexts(candidates), ","); got != "@main.go" {
+		t.Errorf("@メンションのパスを補完するはず: %s", got)
+	}
+
+	if _, candidates := completer.Complete("open dis"); len(candidates) != 0 {
+		t.Errorf(".gitignore の対象は補完しないはず: %v", candidateTexts(candidates))
+	}
+}
+
+func TestComplete_GitBranchesAndTools(t *testing.T) {
+	completer := NewAdvancedCompleter(newTestRepo(t))
+	completer.SetToolNames(func() map[string]string {
+		return map[string]string{"read": "ファイルを読む", "github.create_issue": "Issue を作成"}
+	})
+
+	_, candidates := completer.Complete("git checkout fe")
+	if len(candidates) != 1 || candidates[0].Text != "feature/login" || candidates[0].Type != CompletionGitBranch {
+		t.Errorf("git 関連の入力ではブランチ名を補完するはず: %+v", candidates)
+	}
+
+	_, candidates = completer.Complete("develop ブランチにマージして d")
+	if len(candidates) == 0 || candidates[0].Text != "develop" {
+		t.Errorf("ブランチに言及する入力でもブランチ名を優先するはず: %v", candidateTexts(candidates))
+	}
+
+	if _, candidates := completer.Complete("fix fe"); len(candidates) != 0 {
+		t.Errorf("git 関連でない入力ではブランチ名を補完しないはず: %v", candidateTexts(candidates))
+	}
+
+	_, candidates = completer.Complete("use github.cr")
+	if len(candidates) != 1 || candidates[0].Text != "github.create_issue" || candidates[0].Type != CompletionTool {
+		t.Errorf("MCP ツール名を補完するはず: %+v", candidates)
+	}
+}
+
+func TestCommonPrefix(t *testing.T) {
+	candidates := []CompletionCandidate{{Text: "internal/api/"}, {Text: "internal/app/"}}
+	if got := CommonPrefix(candidates); got != "internal/ap" {
+		t.Errorf("CommonPrefix = %q", got)
+	}
+	if got := CommonPrefix(nil); got != "" {
+		t.Errorf("候補がなければ空のはず: %q", got)
+	}
+}
+
+func TestReaderTabCompletion(t *testing.T) {
+	reader := NewReader()
+	defer reader.Close()
+	reader.completer.advancedCompleter = NewAdvancedCompleter(newTestRepo(t))
+
+	typeKeys(reader, "read int\tapp\t")
+	if reader.currentLine != "read internal/app/" {
+		t.Errorf("共通部分まで補完し、ディレクトリには空白を付けないはず: %q", reader.currentLine)
+	}
+
+	typeKeys(reader, "app.\t")
+	if reader.currentLine != "read internal/app/app.go " {
+		t.Errorf("候補が1つなら確定して空白を付けるはず: %q", reader.currentLine)
+	}
+}
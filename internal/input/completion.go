@@ -3,9 +3,11 @@ package input
 import (
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"sort"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -16,6 +18,12 @@ type AdvancedCompleter struct {
 	gitCompleter    *GitCompleter
 	projectAnalyzer *ProjectAnalyzer
 	fuzzyMatcher    *FuzzyMatcher
+
+	// 単語単位の補完（Complete）で使う一覧
+	toolNames    func() map[string]string
+	listMu       sync.Mutex
+	projectFiles *CacheEntry
+	branches     *CacheEntry
 }
 
 // 補完キャッシュシステム
@@ -72,6 +80,7 @@ const (
 	CompletionGitFile
 	CompletionProjectCommand
 	CompletionDependency
+	CompletionTool
 )
 
 // 高度なコンプリーターを作成
@@ -196,27 +205,13 @@ func (ac *AdvancedCompleter) getDefaultSuggestions() []CompletionCandidate {
 func (ac *AdvancedCompleter) getSlashCommandCompletions(input string) []CompletionCandidate {
 	var candidates []CompletionCandidate
 
-	slashCommands := map[string]string{
-		"/help":    "ヘルプ表示",
-		"/clear":   "画面クリア",
-		"/history": "履歴表示",
-		"/status":  "ステータス表示",
-		"/info":    "情報表示",
-		"/save":    "セッション保存",
-		"/retry":   "再実行",
-		"/edit":    "編集モード",
-		"/exit":    "終了",
-		"/quit":    "終了",
-	}
-
-	for cmd, desc := range slashCommands {
-		if strings.HasPrefix(cmd, input) {
-			score := ac.calculateScore(input, cmd)
+	for _, command := range slashCommands {
+		if strings.HasPrefix(command.name, input) {
 			candidates = append(candidates, CompletionCandidate{
-				Text:        cmd,
-				Description: desc,
+				Text:        command.name,
+				Description: command.description,
 				Type:        CompletionCommand,
-				Score:       score,
+				Score:       ac.calculateScore(input, command.name),
 			})
 		}
 	}
@@ -715,25 +710,51 @@ func (cc *CompletionCache) setGitCache(candidates []CompletionCandidate) {
 
 // Git補完の具体的実装
 func (gc *GitCompleter) getBranches() []string {
-	// 簡易実装：実際のgitコマンドは呼ばずサンプルデータを返す
-	// 本番環境では exec.Command("git", "branch") を使用
-	return []string{"main", "develop", "feature/new-feature", "bugfix/issue-123"}
+	// ローカルブランチの後にリモート追跡ブランチ（origin/HEAD 等のシンボリック参照は除く）
+	var branches []string
+	for _, ref := range gc.git("for-each-ref", "--format=%(refname:short)", "refs/heads", "refs/remotes") {
+		if !strings.HasSuffix(ref, "/HEAD") {
+			branches = append(branches, ref)
+		}
+	}
+	return branches
 }
 
 func (gc *GitCompleter) getModifiedFiles() []string {
-	// 簡易実装：実際のgitコマンドは呼ばずサンプルデータを返す
-	// 本番環境では exec.Command("git", "status", "--porcelain") を使用
-	return []string{"internal/input/reader.go", "internal/input/security.go", "README.md"}
+	var files []string
+	for _, entry := range gc.git("status", "--porcelain", "--untracked-files=all") {
+		if len(entry) < 4 {
+			continue
+		}
+		file := entry[3:]
+		// リネームは移動先のパス
+		if i := strings.Index(file, " -> "); i >= 0 {
+			file = file[i+4:]
+		}
+		files = append(files, strings.Trim(file, `"`))
+	}
+	return files
 }
 
 func (gc *GitCompleter) getTrackedFiles() []string {
-	// 簡易実装：実際のgitコマンドは呼ばずサンプルデータを返す
-	// 本番環境では exec.Command("git", "ls-files") を使用
-	return []string{
-		"go.mod", "go.sum", "main.go", "README.md",
-		"internal/input/reader.go", "internal/input/security.go",
-		"cmd/vyb/main.go", "pkg/types/types.go",
+	return gc.git("ls-files")
+}
+
+// git は作業ディレクトリで git を実行して出力の空でない行を返す（リポジトリ外・失敗時は nil）
+func (gc *GitCompleter) git(args ...string) []string {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = gc.workDir
+	output, err := cmd.Output()
+	if err != nil {
+		return nil
+	}
+	var lines []string
+	for _, line := range strings.Split(string(output), "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
 	}
+	return lines
 }
 
 // プロジェクト解析の具体的実装
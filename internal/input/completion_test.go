@@ -39,7 +39,7 @@ func TestAdvancedCompleter_Creation(t *testing.T) {
 }
 
 func TestAdvancedCompleter_GetAdvancedSuggestions(t *testing.T) {
-	completer := NewAdvancedCompleter("/test")
+	completer := NewAdvancedCompleter(newTestRepo(t))
 
 	tests := []struct {
 		name           string
@@ -153,7 +153,7 @@ func TestAdvancedCompleter_SlashCommands(t *testing.T) {
 }
 
 func TestGitCompleter_Methods(t *testing.T) {
-	gitCompleter := NewGitCompleter("/test")
+	gitCompleter := NewGitCompleter(newTestRepo(t))
 
 	t.Run("getBranches", func(t *testing.T) {
 		branches := gitCompleter.getBranches()
@@ -162,8 +162,8 @@ func TestGitCompleter_Methods(t *testing.T) {
 			t.Error("Expected at least one branch")
 		}
 
-		// サンプルブランチが含まれているかチェック
-		expectedBranches := []string{"main", "develop"}
+		// リポジトリのブランチが含まれているかチェック
+		expectedBranches := []string{"main", "develop", "feature/login"}
 		for _, expected := range expectedBranches {
 			found := false
 			for _, branch := range branches {
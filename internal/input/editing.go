@@ -0,0 +1,307 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// EditMode は入力行の編集キー配置
+type EditMode string
+
+const (
+	EditModeEmacs EditMode = "emacs" // Ctrl+A/E/K/U/W/Y 等（既定）
+	EditModeVi    EditMode = "vi"    // Esc でノーマルモード、h/l/w/b/x/dd/p 等
+)
+
+// 編集用の制御キー
+const (
+	CtrlA = 1  // 行頭
+	CtrlB = 2  // 1文字左
+	CtrlE = 5  // 行末（Ctrl+X に続けると外部エディタ）
+	CtrlF = 6  // 1文字右
+	CtrlK = 11 // カーソルから行末まで削除
+	CtrlN = 14 // 次の履歴
+	CtrlP = 16 // 前の履歴
+	CtrlU = 21 // 行頭からカーソルまで削除
+	CtrlW = 23 // 直前の単語を削除
+	CtrlX = 24 // Ctrl+X Ctrl+E の前置キー
+	CtrlY = 25 // 削除した文字列を貼り付け
+)
+
+// ValidEditModes は設定できる編集キー配置
+func ValidEditModes() []string {
+	return []string{string(EditModeEmacs), string(EditModeVi)}
+}
+
+// ParseEditMode は設定値を編集キー配置に変換（空は emacs）
+func ParseEditMode(mode string) (EditMode, error) {
+	switch EditMode(strings.ToLower(strings.TrimSpace(mode))) {
+	case "", EditModeEmacs:
+		return EditModeEmacs, nil
+	case EditModeVi:
+		return EditModeVi, nil
+	}
+	return "", fmt.Errorf("無効な編集モードです。有効な値: %v", ValidEditModes())
+}
+
+// SetEditMode は編集キー配置を設定
+func (r *Reader) SetEditMode(mode EditMode) {
+	r.editMode = mode
+	r.viNormal = false
+	r.viOperator = 0
+}
+
+// EditMode は現在の編集キー配置
+func (r *Reader) EditMode() EditMode {
+	if r.editMode == "" {
+		return EditModeEmacs
+	}
+	return r.editMode
+}
+
+// handleEditingKey は Emacs 風の編集キーを処理（vi の挿入モードでは Ctrl+U/Ctrl+W のみ）
+func (r *Reader) handleEditingKey(b byte) bool {
+	runes := []rune(r.currentLine)
+	switch b {
+	case CtrlU:
+		r.killRange(0, r.cursorPos)
+	case CtrlW:
+		r.killRange(wordBackward(runes, r.cursorPos), r.cursorPos)
+	default:
+		if r.EditMode() != EditModeEmacs {
+			return false
+		}
+		switch b {
+		case CtrlA:
+			r.cursorPos = 0
+		case CtrlE:
+			r.cursorPos = len(runes)
+		case CtrlB:
+			if r.cursorPos > 0 {
+				r.cursorPos--
+			}
+		case CtrlF:
+			if r.cursorPos < len(runes) {
+				r.cursorPos++
+			}
+		case CtrlK:
+			r.killRange(r.cursorPos, len(runes))
+		case CtrlY:
+			r.yank(r.cursorPos)
+		case CtrlP:
+			r.recallHistory(r.history.Previous())
+		case CtrlN:
+			r.recallHistory(r.history.Next())
+		default:
+			return false
+		}
+	}
+	r.redrawLine()
+	return true
+}
+
+// handleAltKey は ESC に続くキーを処理
+// vi では挿入モードからノーマルモードへ切り替え、続くキーはノーマルモードのコマンドとして扱う
+func (r *Reader) handleAltKey(key byte) {
+	if r.EditMode() == EditModeVi {
+		if !r.viNormal {
+			r.viNormal = true
+			if r.cursorPos > 0 {
+				r.cursorPos--
+			}
+			r.redrawLine()
+		}
+		r.unreadByte(key)
+		return
+	}
+
+	runes := []rune(r.currentLine)
+	switch key {
+	case 'b', 'B':
+		r.cursorPos = wordBackward(runes, r.cursorPos)
+	case 'f', 'F':
+		r.cursorPos = wordForward(runes, r.cursorPos)
+	case 'd', 'D':
+		r.killRange(r.cursorPos, wordForward(runes, r.cursorPos))
+	case KeyBS, 8:
+		r.killRange(wordBackward(runes, r.cursorPos), r.cursorPos)
+	default:
+		// Alt との組み合わせでなければ通常のキーとして扱う
+		r.unreadByte(key)
+		return
+	}
+	r.redrawLine()
+}
+
+// handleViNormalKey は vi のノーマルモードのキーを処理。外部エディタを開く場合は true
+func (r *Reader) handleViNormalKey(b byte) bool {
+	runes := []rune(r.currentLine)
+
+	// d/c/y に続く移動コマンド
+	if operator := r.viOperator; operator != 0 {
+		r.viOperator = 0
+		start, end := r.cursorPos, r.cursorPos
+		switch b {
+		case operator:
+			start, end = 0, len(runes)
+		case 'w':
+			end = wordForward(runes, r.cursorPos)
+		case 'b':
+			start = wordBackward(runes, r.cursorPos)
+		case '$':
+			end = len(runes)
+		case '0', '^':
+			start = 0
+		default:
+			return false
+		}
+		if operator == 'y' {
+			r.killRing = string(runes[start:end])
+		} else {
+			r.killRange(start, end)
+		}
+		if operator == 'c' {
+			r.viNormal = false
+		}
+		r.finishViCommand()
+		return false
+	}
+
+	switch b {
+	case 'h', KeyBS, 8:
+		if r.cursorPos > 0 {
+			r.cursorPos--
+		}
+	case 'l', ' ':
+		r.cursorPos++
+	case '0', '^':
+		r.cursorPos = 0
+	case '$':
+		r.cursorPos = len(runes)
+	case 'w':
+		r.cursorPos = wordForward(runes, r.cursorPos)
+	case 'b':
+		r.cursorPos = wordBackward(runes, r.cursorPos)
+	case 'x':
+		r.killRange(r.cursorPos, r.cursorPos+1)
+	case 'X':
+		r.killRange(r.cursorPos-1, r.cursorPos)
+	case 'D':
+		r.killRange(r.cursorPos, len(runes))
+	case 'C':
+		r.killRange(r.cursorPos, len(runes))
+		r.viNormal = false
+	case 'S':
+		r.killRange(0, len(runes))
+		r.viNormal = false
+	case 'd', 'c', 'y':
+		r.viOperator = b
+		return false
+	case 'p':
+		if len(runes) > 0 {
+			r.cursorPos++
+		}
+		r.yank(r.cursorPos)
+		r.cursorPos--
+	case 'P':
+		r.yank(r.cursorPos)
+		r.cursorPos--
+	case 'i':
+		r.viNormal = false
+	case 'a':
+		r.viNormal = false
+		r.cursorPos++
+	case 'I':
+		r.viNormal = false
+		r.cursorPos = 0
+	case 'A':
+		r.viNormal = false
+		r.cursorPos = len(runes)
+	case 'k', '-':
+		r.recallHistory(r.history.Previous())
+	case 'j', '+':
+		r.recallHistory(r.history.Next())
+	case 'v':
+		return true
+	default:
+		return false
+	}
+	r.finishViCommand()
+	return false
+}
+
+// finishViCommand はカーソルを行内に収めて再描画（ノーマルモードでは最後の文字の上まで）
+func (r *Reader) finishViCommand() {
+	limit := len([]rune(r.currentLine))
+	if r.viNormal && limit > 0 {
+		limit--
+	}
+	r.cursorPos = clampInt(r.cursorPos, 0, limit)
+	r.redrawLine()
+}
+
+// killRange は [start, end) の文字を削除して貼り付け用に保持
+func (r *Reader) killRange(start, end int) {
+	runes := []rune(r.currentLine)
+	start = clampInt(start, 0, len(runes))
+	end = clampInt(end, start, len(runes))
+	if start == end {
+		return
+	}
+	r.killRing = string(runes[start:end])
+	r.currentLine = string(append(runes[:start:start], runes[end:]...))
+	r.cursorPos = start
+}
+
+// yank は削除した文字列を pos に挿入し、カーソルを挿入した末尾に置く
+func (r *Reader) yank(pos int) {
+	runes := []rune(r.currentLine)
+	pos = clampInt(pos, 0, len(runes))
+	inserted := []rune(r.killRing)
+	newRunes := append(append(append([]rune{}, runes[:pos]...), inserted...), runes[pos:]...)
+	if len(string(newRunes)) > MaxLineLength {
+		return
+	}
+	r.currentLine = string(newRunes)
+	r.cursorPos = pos + len(inserted)
+}
+
+// recallHistory は履歴の入力で行を置き換える
+func (r *Reader) recallHistory(line string) {
+	r.currentLine = line
+	r.cursorPos = len([]rune(line))
+}
+
+// wordForward は次の単語の先頭（空白区切り）
+func wordForward(runes []rune, pos int) int {
+	for pos < len(runes) && runes[pos] != ' ' {
+		pos++
+	}
+	for pos < len(runes) && runes[pos] == ' ' {
+		pos++
+	}
+	return pos
+}
+
+// wordBackward は現在・直前の単語の先頭（空白区切り）
+func wordBackward(runes []rune, pos int) int {
+	pos = clampInt(pos, 0, len(runes))
+	for pos > 0 && runes[pos-1] == ' ' {
+		pos--
+	}
+	for pos > 0 && runes[pos-1] != ' ' {
+		pos--
+	}
+	return pos
+}
+
+// clampInt は value を [low, high] に収める
+func clampInt(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
@@ -0,0 +1,185 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// typeKeys は readLineRaw と同じ順序で編集キー・文字入力を処理する（確定・補完等は対象外）
+func typeKeys(r *Reader, keys string) {
+	// 再描画の出力は捨てる
+	if devNull, err := os.OpenFile(os.DevNull, os.O_WRONLY, 0); err == nil {
+		stdout := os.Stdout
+		os.Stdout = devNull
+		defer func() {
+			os.Stdout = stdout
+			devNull.Close()
+		}()
+	}
+
+	for i := len(keys) - 1; i >= 0; i-- {
+		r.unreadByte(keys[i])
+	}
+	for len(r.unread) > 0 {
+		b, _ := r.readByte()
+		switch {
+		case b == KeyESC:
+			r.handleEscapeSequence()
+		case b == KeyTab:
+			r.completeAtCursor()
+		case r.viNormal:
+			r.handleViNormalKey(b)
+		case r.handleEditingKey(b):
+		default:
+			r.insertRunes([]rune{rune(b)})
+		}
+	}
+}
+
+func newEditingReader(mode EditMode, line string) *Reader {
+	r := NewReader()
+	r.SetEditMode(mode)
+	r.currentLine = line
+	r.cursorPos = len([]rune(line))
+	return r
+}
+
+func TestEmacsBindings(t *testing.T) {
+	r := newEditingReader(EditModeEmacs, "git commit -m message")
+
+	typeKeys(r, string([]byte{CtrlW}))
+	if r.currentLine != "git commit -m " {
+		t.Errorf("Ctrl+W で直前の単語を削除するはず: %q", r.currentLine)
+	}
+	typeKeys(r, string([]byte{CtrlA, CtrlK}))
+	if r.currentLine != "" || r.killRing != "git commit -m " {
+		t.Errorf("Ctrl+A Ctrl+K で行全体を削除して保持するはず: %q / %q", r.currentLine, r.killRing)
+	}
+	typeKeys(r, string([]byte{CtrlY}))
+	if r.currentLine != "git commit -m " || r.cursorPos != len(r.currentLine) {
+		t.Errorf("Ctrl+Y で貼り付けるはず: %q (%d)", r.currentLine, r.cursorPos)
+	}
+
+	// Alt+b で単語の先頭へ戻って挿入
+	typeKeys(r, "\x1bbx")
+	if r.currentLine != "git commit x-m " {
+		t.Errorf("Alt+b で直前の単語の先頭へ移動するはず: %q", r.currentLine)
+	}
+	// ESC に続く Alt の組み合わせでないキーは通常入力
+	typeKeys(r, string([]byte{CtrlE})+"\x1bz")
+	if r.currentLine != "git commit x-m z" {
+		t.Errorf("ESC に続く通常のキーは入力として扱うはず: %q", r.currentLine)
+	}
+}
+
+func TestViBindings(t *testing.T) {
+	r := newEditingReader(EditModeVi, "")
+
+	typeKeys(r, "hello big world")
+	if r.viNormal || r.currentLine != "hello big world" {
+		t.Fatalf("挿入モードで入力するはず: %q", r.currentLine)
+	}
+
+	// Esc でノーマルモード、b で単語を戻り dw で削除
+	typeKeys(r, "\x1bbbdw")
+	if !r.viNormal || r.currentLine != "hello world" {
+		t.Errorf("dw で単語を削除するはず: %q", r.currentLine)
+	}
+	typeKeys(r, "0x$p")
+	if r.currentLine != "ello worldh" || r.cursorPos != len(r.currentLine)-1 {
+		t.Errorf("x で削除した文字を p で貼り付けるはず: %q (%d)", r.currentLine, r.cursorPos)
+	}
+	typeKeys(r, "ccnew")
+	if r.viNormal || r.currentLine != "new" {
+		t.Errorf("cc で行を置き換えて挿入モードに戻るはず: %q", r.currentLine)
+	}
+	typeKeys(r, "\x1bIsay ")
+	if r.currentLine != "say new" {
+		t.Errorf("I で行頭に挿入するはず: %q", r.currentLine)
+	}
+	// 挿入モードでも Ctrl+U は使える
+	typeKeys(r, string([]byte{CtrlU}))
+	if r.currentLine != "new" {
+		t.Errorf("Ctrl+U でカーソルから行頭まで削除するはず: %q", r.currentLine)
+	}
+}
+
+func TestViHistory(t *testing.T) {
+	r := newEditingReader(EditModeVi, "")
+	r.history.Add("first")
+	r.history.Add("second")
+
+	typeKeys(r, "\x1bkk")
+	if r.currentLine != "first" {
+		t.Errorf("k で履歴を遡るはず: %q", r.currentLine)
+	}
+	if r.cursorPos != len("first")-1 {
+		t.Errorf("ノーマルモードのカーソルは最後の文字の上のはず: %d", r.cursorPos)
+	}
+}
+
+func TestParseEditMode(t *testing.T) {
+	for input, want := range map[string]EditMode{"": EditModeEmacs, "emacs": EditModeEmacs, "VI": EditModeVi} {
+		if got, err := ParseEditMode(input); err != nil || got != want {
+			t.Errorf("ParseEditMode(%q) = %q, %v", input, got, err)
+		}
+	}
+	if _, err := ParseEditMode("nano"); err == nil {
+		t.Error("未知の編集モードはエラーになるはず")
+	}
+}
+
+func TestEditText(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("シェルスクリプトのエディタを使う")
+	}
+	// 既存の内容に1行追記するエディタ
+	editor := filepath.Join(t.TempDir(), "editor.sh")
+	if err := os.WriteFile(editor, []byte("#!/bin/sh\necho 'second line' >> \"$1\"\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", editor)
+
+	text, err := EditText("first line\n")
+	if err != nil {
+		t.Fatalf("EditText: %v", err)
+	}
+	if text != "first line\nsecond line" {
+		t.Errorf("編集後の内容を末尾の改行を除いて返すはず: %q", text)
+	}
+
+	t.Setenv("EDITOR", filepath.Join(t.TempDir(), "missing"))
+	if _, err := EditText(""); err == nil {
+		t.Error("エディタを起動できなければエラーになるはず")
+	}
+}
+
+// TestReader_BracketedPaste は複数行の貼り付けが目印に置き換わり、添付として取り出せることをテストする
+func TestReader_BracketedPaste(t *testing.T) {
+	r := NewReader()
+	typeKeys(r, "fix \033[200~short\033[201~ and \033[200~line one\r\nline two\rline three\033[201~")
+
+	want := "fix short and [paste #1: 3 lines]"
+	if r.currentLine != want {
+		t.Fatalf("currentLine = %q, want %q", r.currentLine, want)
+	}
+	pastes := r.TakePastes()
+	if len(pastes) != 1 || pastes[0].Text != "line one\nline two\nline three" {
+		t.Fatalf("pastes = %+v", pastes)
+	}
+	if len(r.TakePastes()) != 0 {
+		t.Error("取り出した貼り付けは残らないはず")
+	}
+
+	attached := FormatPastes(r.currentLine, pastes)
+	if !strings.Contains(attached, "### [paste #1: 3 lines]\n```\nline one\nline two\nline three\n```") {
+		t.Errorf("attached = %q", attached)
+	}
+	if FormatPastes("fix short", pastes) != "" {
+		t.Error("目印を消した貼り付けは添付しないはず")
+	}
+}
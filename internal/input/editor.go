@@ -0,0 +1,53 @@
+package input
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// EditorCommand は外部エディタのコマンド（$VISUAL → $EDITOR、未設定なら vi、Windows は notepad）
+func EditorCommand() []string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if fields := strings.Fields(os.Getenv(name)); len(fields) > 0 {
+			return fields
+		}
+	}
+	if runtime.GOOS == "windows" {
+		return []string{"notepad"}
+	}
+	return []string{"vi"}
+}
+
+// EditText は text を一時ファイルに書き出して外部エディタで開き、保存された内容を返す（末尾の空白・改行は除く）
+func EditText(text string) (string, error) {
+	file, err := os.CreateTemp("", "vyb-prompt-*.md")
+	if err != nil {
+		return "", fmt.Errorf("一時ファイル作成エラー: %w", err)
+	}
+	path := file.Name()
+	defer os.Remove(path)
+
+	_, err = file.WriteString(text)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", fmt.Errorf("一時ファイル書き込みエラー: %w", err)
+	}
+
+	command := EditorCommand()
+	cmd := exec.Command(command[0], append(command[1:], path)...)
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("エディタ実行エラー (%s): %w", command[0], err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("編集結果の読み込みエラー: %w", err)
+	}
+	return strings.TrimRight(string(data), " \t\r\n"), nil
+}
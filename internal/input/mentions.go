@@ -0,0 +1,311 @@
+package input
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/redact"
+)
+
+// @メンションで添付するファイルの上限
+const (
+	MentionMaxFileBytes  = 64 * 1024  // 1ファイルあたり（超過分は切り詰め）
+	MentionMaxTotalBytes = 256 * 1024 // 1メッセージあたりの合計
+)
+
+// mentionPattern は行頭または空白直後の @path（メールアドレス等は除外）
+var mentionPattern = regexp.MustCompile(`(^|\s)@([^\s@]+)`)
+
+// FileMention は @メンションで添付したファイル
+type FileMention struct {
+	Path      string // 入力されたパス（プロジェクトルートからの相対）
+	Content   string
+	Size      int64 // 元のファイルサイズ
+	Truncated bool  // 上限を超えたため切り詰めた
+	Err       error // 添付できなかった理由
+}
+
+// ParseMentions は入力から @path のパスを重複なく抽出
+func ParseMentions(line string) []string {
+	var paths []string
+	seen := make(map[string]bool)
+	for _, match := range mentionPattern.FindAllStringSubmatch(line, -1) {
+		// 文末の句読点は含めない
+		path := strings.TrimRight(match[2], ",;:!?)'\"。、")
+		path = strings.TrimSuffix(path, ".")
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// ResolveMentions は入力中の @path をプロジェクト内のファイルとして読み込む
+// 存在しないパスは通常の @ 表記とみなして無視し、プロジェクト外・バイナリ・上限超過はErrに理由を設定
+func ResolveMentions(line, root string) []FileMention {
+	return ResolveFiles(ParseMentions(line), root)
+}
+
+// ResolveFiles はパスをプロジェクト内のファイルとして @メンションと同じ上限で読み込む（存在しないパスは含めない）
+func ResolveFiles(paths []string, root string) []FileMention {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil
+	}
+
+	var mentions []FileMention
+	remaining := MentionMaxTotalBytes
+	for _, path := range paths {
+		fullPath := path
+		if !filepath.IsAbs(fullPath) {
+			fullPath = filepath.Join(absRoot, path)
+		}
+		info, err := os.Stat(fullPath)
+		if err != nil || info.IsDir() {
+			continue
+		}
+
+		mention := FileMention{Path: path, Size: info.Size()}
+		if rel, err := filepath.Rel(absRoot, fullPath); err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			mention.Err = fmt.Errorf("プロジェクト外のファイルは添付できません")
+			mentions = append(mentions, mention)
+			continue
+		}
+		if remaining <= 0 {
+			mention.Err = fmt.Errorf("添付ファイルの合計サイズ上限（%d bytes）に達しました", MentionMaxTotalBytes)
+			mentions = append(mentions, mention)
+			continue
+		}
+
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			mention.Err = err
+			mentions = append(mentions, mention)
+			continue
+		}
+		if isBinary(data) {
+			mention.Err = fmt.Errorf("バイナリファイルは添付できません")
+			mentions = append(mentions, mention)
+			continue
+		}
+
+		limit := MentionMaxFileBytes
+		if remaining < limit {
+			limit = remaining
+		}
+		if len(data) > limit {
+			data = truncateUTF8(data, limit)
+			mention.Truncated = true
+		}
+		// .env 等の値・機密情報はプロンプトに入れる前に伏せる
+		mention.Content = redact.File(path, string(data))
+		remaining -= len(data)
+		mentions = append(mentions, mention)
+	}
+	return mentions
+}
+
+// FormatMentions は添付できたファイルをプロンプトに追記する形式に整形
+func FormatMentions(mentions []FileMention) string {
+	var builder strings.Builder
+	for _, mention := range mentions {
+		if mention.Err != nil {
+			continue
+		}
+		if builder.Len() == 0 {
+			builder.WriteString("## Attached files\n")
+		}
+		builder.WriteString(fmt.Sprintf("\n### @%s\n```%s\n%s", mention.Path, strings.TrimPrefix(filepath.Ext(mention.Path), "."), mention.Content))
+		if !strings.HasSuffix(mention.Content, "\n") {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("```\n")
+		if mention.Truncated {
+			builder.WriteString(fmt.Sprintf("(truncated: showing %d of %d bytes)\n", len(mention.Content), mention.Size))
+		}
+	}
+	return builder.String()
+}
+
+// isBinary は先頭部分にNULバイトを含むかでバイナリを判定
+func isBinary(data []byte) bool {
+	head := data
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) >= 0
+}
+
+// truncateUTF8 は文字の途中で切らないよう指定バイト数以下に切り詰め
+func truncateUTF8(data []byte, limit int) []byte {
+	data = data[:limit]
+	for len(data) > 0 && !utf8.Valid(data) {
+		data = data[:len(data)-1]
+	}
+	return data
+}
+
+// ListProjectFiles はプロジェクトのファイル一覧を返す
+// gitリポジトリでは.gitignoreを尊重し、それ以外は除外パターン（ignore パッケージ）に一致するパスを除いて走査
+func ListProjectFiles(root string, limit int) []string {
+	cmd := exec.Command("git", "ls-files", "--cached", "--others", "--exclude-standard", "-z")
+	cmd.Dir = root
+	if output, err := cmd.Output(); err == nil {
+		var files []string
+		for _, file := range strings.Split(string(output), "\x00") {
+			if file == "" {
+				continue
+			}
+			files = append(files, file)
+			if limit > 0 && len(files) >= limit {
+				break
+			}
+		}
+		return files
+	}
+
+	var files []string
+	ignore.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		name := info.Name()
+		if info.IsDir() {
+			if path != root && strings.HasPrefix(name, ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if rel, err := filepath.Rel(root, path); err == nil {
+			files = append(files, filepath.ToSlash(rel))
+		}
+		if limit > 0 && len(files) >= limit {
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return files
+}
+
+// FuzzyScore はfzf風のあいまい一致でスコアを計算（一致しなければfalse）
+// 連続一致・区切り文字直後・ファイル名部分での一致を高く評価し、短いパスを優先
+func FuzzyScore(candidate, query string) (int, bool) {
+	if query == "" {
+		return 0, true
+	}
+
+	lower := strings.ToLower(candidate)
+	base := strings.LastIndex(lower, "/") + 1
+	score, offset, previous := 0, 0, -2
+	for _, r := range strings.ToLower(query) {
+		index := strings.IndexRune(lower[offset:], r)
+		if index < 0 {
+			return 0, false
+		}
+		position := offset + index
+		score++
+		if position == previous+1 {
+			score += 5
+		}
+		if position == 0 || strings.ContainsRune("/_-.", rune(lower[position-1])) {
+			score += 3
+		}
+		if position >= base {
+			score += 2
+		}
+		previous = position
+		offset = position + utf8.RuneLen(r)
+	}
+	return score - len(candidate)/10, true
+}
+
+// FuzzyFilter はクエリに一致するファイルをスコア順に最大limit件返す
+func FuzzyFilter(files []string, query string, limit int) []string {
+	type scored struct {
+		path  string
+		score int
+	}
+	var matches []scored
+	for _, file := range files {
+		if score, ok := FuzzyScore(file, query); ok {
+			matches = append(matches, scored{file, score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		return matches[i].path < matches[j].path
+	})
+
+	result := make([]string, 0, len(matches))
+	for _, match := range matches {
+		result = append(result, match.path)
+		if limit > 0 && len(result) >= limit {
+			break
+		}
+	}
+	return result
+}
+
+// FilePicker は @ 入力時のファイル選択（fzf風の絞り込み）
+type FilePicker struct {
+	root     string
+	files    []string
+	matches  []string
+	selected int
+}
+
+// ピッカーの表示件数と読み込むファイル数の上限
+const (
+	pickerVisibleItems = 8
+	pickerMaxFiles     = 20000
+)
+
+// NewFilePicker はプロジェクトルートを起点にしたファイルピッカーを作成
+func NewFilePicker(root string) *FilePicker {
+	return &FilePicker{root: root}
+}
+
+// Reset はファイル一覧を読み直して絞り込みを解除
+func (p *FilePicker) Reset() {
+	p.files = ListProjectFiles(p.root, pickerMaxFiles)
+	p.SetQuery("")
+}
+
+// SetQuery は絞り込み文字列を更新（選択は先頭に戻る）
+func (p *FilePicker) SetQuery(query string) {
+	p.matches = FuzzyFilter(p.files, query, pickerVisibleItems)
+	p.selected = 0
+}
+
+// Move は選択を上下に移動（端で折り返す）
+func (p *FilePicker) Move(delta int) {
+	if len(p.matches) == 0 {
+		return
+	}
+	p.selected = (p.selected + delta + len(p.matches)) % len(p.matches)
+}
+
+// Matches は表示中の候補
+func (p *FilePicker) Matches() []string {
+	return p.matches
+}
+
+// Selected は選択中の候補
+func (p *FilePicker) Selected() (string, bool) {
+	if len(p.matches) == 0 {
+		return "", false
+	}
+	return p.matches[p.selected], true
+}
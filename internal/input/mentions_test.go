@@ -0,0 +1,102 @@
+package input
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseMentions(t *testing.T) {
+	got := ParseMentions("@main.go と @internal/llm/vision.go, を比較して。mail@example.com と @main.go は無視")
+	want := []string{"main.go", "internal/llm/vision.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseMentions = %v, want %v", got, want)
+	}
+}
+
+func TestResolveMentions(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "main.go"), []byte("package main\n"), 0644)
+	os.WriteFile(filepath.Join(root, "big.txt"), []byte(strings.Repeat("a", MentionMaxFileBytes+10)), 0644)
+	os.WriteFile(filepath.Join(root, "image.bin"), []byte{0x89, 0x00, 0x01}, 0644)
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	os.WriteFile(outside, []byte("secret"), 0644)
+
+	mentions := ResolveMentions("@main.go @big.txt @image.bin @missing.go @"+outside+" @team", root)
+	if len(mentions) != 4 {
+		t.Fatalf("expected 4 mentions (missing paths ignored), got %+v", mentions)
+	}
+	if mentions[0].Err != nil || mentions[0].Content != "package main\n" {
+		t.Errorf("main.go = %+v", mentions[0])
+	}
+	if !mentions[1].Truncated || len(mentions[1].Content) != MentionMaxFileBytes {
+		t.Errorf("big.txt should be truncated: truncated=%t len=%d", mentions[1].Truncated, len(mentions[1].Content))
+	}
+	if mentions[2].Err == nil {
+		t.Error("binary file should be rejected")
+	}
+	if mentions[3].Err == nil {
+		t.Error("file outside the project should be rejected")
+	}
+
+	formatted := FormatMentions(mentions)
+	if !strings.Contains(formatted, "### @main.go\n```go\npackage main\n```") {
+		t.Errorf("unexpected format:\n%s", formatted)
+	}
+	if strings.Contains(formatted, "secret") {
+		t.Error("rejected files must not be included")
+	}
+}
+
+func TestFuzzyFilter(t *testing.T) {
+	files := []string{
+		"internal/handlers/chat.go",
+		"internal/handlers/chat_decoupled.go",
+		"internal/llm/cache.go",
+		"cmd/vyb/main.go",
+	}
+
+	got := FuzzyFilter(files, "chat", 0)
+	if len(got) != 2 || got[0] != "internal/handlers/chat.go" {
+		t.Errorf("FuzzyFilter(chat) = %v", got)
+	}
+	if got := FuzzyFilter(files, "vmain", 0); len(got) != 1 || got[0] != "cmd/vyb/main.go" {
+		t.Errorf("FuzzyFilter(vmain) = %v", got)
+	}
+	if got := FuzzyFilter(files, "xyz", 0); len(got) != 0 {
+		t.Errorf("FuzzyFilter(xyz) = %v", got)
+	}
+	if got := FuzzyFilter(files, "", 2); len(got) != 2 {
+		t.Errorf("empty query should list files up to the limit: %v", got)
+	}
+}
+
+func TestFilePicker(t *testing.T) {
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "node_modules", "lib"), 0755)
+	os.WriteFile(filepath.Join(root, "node_modules", "lib", "index.js"), []byte(""), 0644)
+	os.WriteFile(filepath.Join(root, "app.go"), []byte(""), 0644)
+	os.WriteFile(filepath.Join(root, "app_test.go"), []byte(""), 0644)
+
+	picker := NewFilePicker(root)
+	picker.Reset()
+	picker.SetQuery("index")
+	if _, ok := picker.Selected(); ok {
+		t.Error("node_modules should be skipped")
+	}
+
+	picker.SetQuery("app")
+	if len(picker.Matches()) != 2 {
+		t.Fatalf("matches = %v", picker.Matches())
+	}
+	first, _ := picker.Selected()
+	picker.Move(1)
+	second, _ := picker.Selected()
+	picker.Move(1)
+	wrapped, _ := picker.Selected()
+	if first == second || wrapped != first {
+		t.Errorf("selection should move and wrap: %s %s %s", first, second, wrapped)
+	}
+}
@@ -0,0 +1,50 @@
+package input
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LargePasteBytes はこれより長い1行の貼り付けも添付として扱うバイト数
+const LargePasteBytes = 512
+
+// Paste は入力行に貼り付けられ、プロンプトではなく添付として送る内容
+type Paste struct {
+	ID   int
+	Text string
+}
+
+// Placeholder は入力行に残す貼り付けの目印（消すと添付も送らない）
+func (p Paste) Placeholder() string {
+	return fmt.Sprintf("[paste #%d: %d lines]", p.ID, strings.Count(strings.TrimSuffix(p.Text, "\n"), "\n")+1)
+}
+
+// IsLargePaste は貼り付けを添付として扱うか（複数行または LargePasteBytes を超える長さ）
+func IsLargePaste(text string) bool {
+	return strings.Contains(strings.TrimSuffix(text, "\n"), "\n") || len(text) > LargePasteBytes
+}
+
+// normalizePaste は貼り付けの改行を \n にそろえる（端末からは \r で届く）
+func normalizePaste(text string) string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	return strings.ReplaceAll(text, "\r", "\n")
+}
+
+// FormatPastes は入力行に目印が残っている貼り付けを添付用のMarkdownに整形
+func FormatPastes(line string, pastes []Paste) string {
+	var builder strings.Builder
+	for _, paste := range pastes {
+		if !strings.Contains(line, paste.Placeholder()) {
+			continue
+		}
+		if builder.Len() == 0 {
+			builder.WriteString("## Pasted content\n")
+		}
+		builder.WriteString(fmt.Sprintf("\n### %s\n```\n%s", paste.Placeholder(), paste.Text))
+		if !strings.HasSuffix(paste.Text, "\n") {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("```\n")
+	}
+	return builder.String()
+}
@@ -23,10 +23,23 @@ type Reader struct {
 	currentLine        string
 	cursorPos          int
 	prompt             string
-	clientID           string // セキュリティ用のクライアントID
-	enableOptimization bool   // パフォーマンス最適化の有効/無効
+	clientID           string      // セキュリティ用のクライアントID
+	enableOptimization bool        // パフォーマンス最適化の有効/無効
+	filePicker         *FilePicker // @ 入力時のファイル選択
+	editMode           EditMode    // 編集キー配置（emacs / vi）
+	viNormal           bool        // vi のノーマルモード中
+	viOperator         byte        // 移動コマンド待ちの d/c/y
+	killRing           string      // 削除した文字列（Ctrl+Y・p で貼り付け）
+	pendingCtrlX       bool        // Ctrl+X の次のキー待ち
+	unread             []byte      // 読み戻したキー入力
+	pastes             []Paste     // 入力中の行に貼り付けた添付（TakePastes で取り出す）
+	pasteSeq           int         // 貼り付けの通し番号
+	copyHandler        CopyHandler // Ctrl+X y / Ctrl+X <数字> でコードブロックをコピー
 }
 
+// CopyHandler は直前の応答の n 番目（0 は最後）のコードブロックをコピーし、結果の表示を返す
+type CopyHandler func(n int) string
+
 // 入力履歴管理（既存のInputHistoryを拡張）
 type History struct {
 	entries  []string
@@ -75,6 +88,8 @@ func NewReader() *Reader {
 		isRawMode:          false,
 		clientID:           "local", // ローカル実行用のデフォルトID
 		enableOptimization: true,    // デフォルトで有効
+		filePicker:         NewFilePicker(currentDir),
+		editMode:           EditModeEmacs,
 	}
 }
 
@@ -92,6 +107,7 @@ func NewCompleter(workDir string) *Completer {
 	return &Completer{
 		commands: []string{
 			"/help", "/clear", "/history", "/status", "/info", "/save", "/retry", "/edit",
+			"/build", "/test", "/lint", "/cost", "/context", "/image", "/paste", "/copy", "/expand", "/rewind", "/branch", "/why", "/offline", "/queue", "/pin", "/unpin", "/pin-context", "/unpin-context", "/title", "/bg", "/jobs", "/kill", "/quote", "/workspace",
 			"exit", "quit",
 		},
 		currentDir:        workDir,
@@ -172,6 +188,8 @@ func (r *Reader) enableRawMode() error {
 
 	r.oldState = oldState
 	r.isRawMode = true
+	// 貼り付けを括弧付きで受け取る（大きな貼り付けを添付として扱うため）
+	fmt.Print("\033[?2004h")
 	return nil
 }
 
@@ -181,6 +199,7 @@ func (r *Reader) disableRawMode() error {
 		return nil
 	}
 
+	fmt.Print("\033[?2004l")
 	if r.oldState != nil {
 		err := term.Restore(int(os.Stdin.Fd()), r.oldState)
 		if err != nil {
@@ -215,43 +234,75 @@ func (r *Reader) readLineRaw() (string, error) {
 
 	r.currentLine = ""
 	r.cursorPos = 0
-
-	buffer := make([]byte, 1)
+	r.viNormal = false
+	r.viOperator = 0
+	r.pendingCtrlX = false
+	r.pastes = nil
 
 	for {
-		n, err := os.Stdin.Read(buffer)
+		b, err := r.readByte()
 		if err == io.EOF {
 			return r.currentLine, err
 		}
 		if err != nil {
 			return "", err
 		}
-		if n == 0 {
+
+		// Ctrl+X Ctrl+E: 入力中のプロンプトを外部エディタで編集して送信
+		if r.pendingCtrlX {
+			r.pendingCtrlX = false
+			if b == CtrlE {
+				if line, ok := r.composeInEditor(); ok {
+					return line, nil
+				}
+				continue
+			}
+			// Ctrl+X y: 直前の応答の最後のコードブロック、Ctrl+X <1-9>: n 番目をコピー
+			if r.copyHandler != nil && (b == 'y' || (b >= '1' && b <= '9')) {
+				n := 0
+				if b != 'y' {
+					n = int(b - '0')
+				}
+				r.clearCurrentLine()
+				fmt.Printf("\033[90m%s\033[0m\r\n", r.copyHandler(n))
+				r.redrawLine()
+				continue
+			}
+		}
+		if b == CtrlX {
+			r.pendingCtrlX = true
 			continue
 		}
 
-		b := buffer[0]
+		// vi のノーマルモード（Enter・Ctrl+C・Ctrl+D・矢印キーは共通の処理）
+		if r.viNormal && b != KeyEnter && b != CtrlC && b != CtrlD && b != KeyESC {
+			if r.handleViNormalKey(b) {
+				if line, ok := r.composeInEditor(); ok {
+					return line, nil
+				}
+			}
+			continue
+		}
+
+		// Emacs 風の編集キー
+		if r.handleEditingKey(b) {
+			continue
+		}
 
 		switch b {
 		case KeyEnter:
 			// 入力確定
 			r.clearCurrentLine()
 			fmt.Printf("%s\n", r.currentLine)
-			line := r.currentLine
 
-			// セキュリティ検証を実行
-			sanitizedLine, err := r.securityValidator.ValidateInput(line, r.clientID)
-			if err != nil {
-				// セキュリティエラーの場合は警告表示して再入力
-				fmt.Printf("\033[31m警告: %s\033[0m\n", err.Error())
-				r.currentLine = ""
-				r.cursorPos = 0
-				r.redrawLine()
-				continue
+			// セキュリティ検証を実行（エラーの場合は警告表示して再入力）
+			if line, ok := r.accept(r.currentLine); ok {
+				return line, nil
 			}
-
-			r.history.Add(sanitizedLine)
-			return sanitizedLine, nil
+			r.currentLine = ""
+			r.cursorPos = 0
+			r.redrawLine()
+			continue
 
 		case CtrlC:
 			// Ctrl+C: キャンセル
@@ -272,25 +323,8 @@ func (r *Reader) readLineRaw() (string, error) {
 			r.redrawLine()
 
 		case KeyTab:
-			// Tab: 高度なオートコンプリート（パフォーマンス最適化付き）
-			var candidates []CompletionCandidate
-
-			if r.enableOptimization && r.perfOptimizer != nil {
-				// パフォーマンス最適化版を使用
-				candidates = r.perfOptimizer.OptimizedCompletion(r.currentLine, r.completer.advancedCompleter)
-			} else {
-				// 通常版を使用
-				candidates = r.completer.advancedCompleter.GetAdvancedSuggestions(r.currentLine)
-			}
-
-			if len(candidates) == 1 {
-				r.currentLine = candidates[0].Text
-				r.cursorPos = len(r.currentLine)
-				r.redrawLine()
-			} else if len(candidates) > 1 {
-				r.showAdvancedSuggestions(candidates)
-				r.redrawLine()
-			}
+			// Tab: カーソル位置の単語を補完
+			r.completeAtCursor()
 
 		case KeyBS, 8: // Backspace (127 or 8)
 			// Backspace: カーソルの左の文字を削除（UTF-8対応）
@@ -325,6 +359,11 @@ func (r *Reader) readLineRaw() (string, error) {
 				r.currentLine = newLine
 				r.cursorPos++
 				r.redrawLine()
+
+				// 行頭・空白直後の @ でファイルピッカーを開く
+				if b == '@' && r.filePicker != nil && (r.cursorPos == 1 || newRunes[r.cursorPos-2] == ' ') {
+					r.runFilePicker()
+				}
 			} else if b >= 128 {
 				// UTF-8マルチバイト文字の開始
 				if err := r.handleUTF8Input(b); err != nil {
@@ -335,68 +374,320 @@ func (r *Reader) readLineRaw() (string, error) {
 	}
 }
 
+// readByte は読み戻したキー入力、なければ標準入力から1バイト読み取る
+func (r *Reader) readByte() (byte, error) {
+	if n := len(r.unread); n > 0 {
+		b := r.unread[n-1]
+		r.unread = r.unread[:n-1]
+		return b, nil
+	}
+	buffer := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buffer)
+		if err != nil {
+			return 0, err
+		}
+		if n > 0 {
+			return buffer[0], nil
+		}
+	}
+}
+
+// unreadByte はキー入力を読み戻し、次の readByte で返す
+func (r *Reader) unreadByte(b byte) {
+	r.unread = append(r.unread, b)
+}
+
+// accept は入力を検証して履歴に追加（不正な入力は警告を表示して false）
+func (r *Reader) accept(line string) (string, bool) {
+	if r.securityValidator != nil {
+		sanitizedLine, err := r.securityValidator.ValidateInput(line, r.clientID)
+		if err != nil {
+			fmt.Printf("\033[31m警告: %s\033[0m\n", err.Error())
+			return "", false
+		}
+		line = sanitizedLine
+	}
+
+	// 複数行の入力は1行の編集で扱えないため履歴に残さない
+	if !strings.Contains(line, "\n") {
+		r.history.Add(line)
+	}
+	return line, true
+}
+
+// composeInEditor は入力中のプロンプトを外部エディタで編集し、保存された内容を送信する
+// 空で保存した場合・エディタが失敗した場合は元の入力に戻る
+func (r *Reader) composeInEditor() (string, bool) {
+	r.clearCurrentLine()
+	wasRaw := r.isRawMode
+	r.disableRawMode()
+	text, err := EditText(r.currentLine)
+	if wasRaw {
+		r.enableRawMode()
+	}
+
+	if err != nil {
+		fmt.Printf("\033[31m警告: %s\033[0m\r\n", err.Error())
+		r.redrawLine()
+		return "", false
+	}
+	if strings.TrimSpace(text) == "" {
+		r.redrawLine()
+		return "", false
+	}
+
+	// 送信する内容を表示（raw モードでは改行で行頭に戻らない）
+	fmt.Printf("%s\r\n", strings.ReplaceAll(text, "\n", "\r\n"))
+	line, ok := r.accept(text)
+	if !ok {
+		r.redrawLine()
+	}
+	return line, ok
+}
+
+// runFilePicker は @ に続けて入力した文字でファイルを絞り込み、選択したパスを挿入
+// Enter/Tabで確定、↑↓で選択、空白で通常入力に戻り、Ctrl+Cで取り消し
+func (r *Reader) runFilePicker() {
+	r.filePicker.Reset()
+	start := r.cursorPos // @ の直後
+	r.renderFilePicker()
+
+	buffer := make([]byte, 1)
+	for {
+		n, err := os.Stdin.Read(buffer)
+		if err != nil || n == 0 {
+			r.closeFilePicker()
+			return
+		}
+
+		b := buffer[0]
+		switch {
+		case b == KeyEnter || b == KeyTab:
+			if path, ok := r.filePicker.Selected(); ok {
+				runes := []rune(r.currentLine)
+				inserted := []rune(path + " ")
+				newRunes := append(append(append([]rune{}, runes[:start]...), inserted...), runes[r.cursorPos:]...)
+				r.currentLine = string(newRunes)
+				r.cursorPos = start + len(inserted)
+			}
+			r.closeFilePicker()
+			return
+
+		case b == CtrlC:
+			r.closeFilePicker()
+			return
+
+		case b == KeyBS || b == 8:
+			runes := []rune(r.currentLine)
+			r.currentLine = string(append(runes[:r.cursorPos-1], runes[r.cursorPos:]...))
+			r.cursorPos--
+			// @ 自体を消したらピッカーを閉じる
+			if r.cursorPos < start {
+				r.closeFilePicker()
+				return
+			}
+
+		case b == KeyESC:
+			sequence := make([]byte, 2)
+			if n, err := os.Stdin.Read(sequence); err != nil || n < 2 || sequence[0] != '[' {
+				r.closeFilePicker()
+				return
+			}
+			switch sequence[1] {
+			case KeyUp:
+				r.filePicker.Move(-1)
+			case KeyDown:
+				r.filePicker.Move(1)
+			}
+			r.renderFilePicker()
+			continue
+
+		case b == ' ':
+			r.closeFilePicker()
+			r.insertRunes([]rune{' '})
+			return
+
+		case b > 32 && b <= 126:
+			r.insertRunes([]rune{rune(b)})
+
+		case b >= 128:
+			if err := r.handleUTF8Input(b); err != nil {
+				continue
+			}
+
+		default:
+			continue
+		}
+
+		r.filePicker.SetQuery(string([]rune(r.currentLine)[start:r.cursorPos]))
+		r.renderFilePicker()
+	}
+}
+
+// insertRunes はカーソル位置に文字を挿入
+func (r *Reader) insertRunes(chars []rune) {
+	runes := []rune(r.currentLine)
+	newRunes := append(append(append([]rune{}, runes[:r.cursorPos]...), chars...), runes[r.cursorPos:]...)
+	if len(string(newRunes)) > MaxLineLength {
+		return
+	}
+	r.currentLine = string(newRunes)
+	r.cursorPos += len(chars)
+	r.redrawLine()
+}
+
+// renderFilePicker は入力行の下に候補を表示し、カーソルを入力行に戻す
+func (r *Reader) renderFilePicker() {
+	matches := r.filePicker.Matches()
+	selected, _ := r.filePicker.Selected()
+
+	lines := 0
+	if len(matches) == 0 {
+		fmt.Print("\r\n\033[K\033[90m  （一致するファイルなし）\033[0m")
+		lines++
+	}
+	for _, match := range matches {
+		if match == selected {
+			fmt.Printf("\r\n\033[K\033[36m❯ %s\033[0m", match)
+		} else {
+			fmt.Printf("\r\n\033[K  %s", match)
+		}
+		lines++
+	}
+	fmt.Printf("\033[J\033[%dA", lines)
+	r.redrawLine()
+}
+
+// closeFilePicker は候補表示を消して入力行を再描画
+func (r *Reader) closeFilePicker() {
+	fmt.Print("\r\n\033[J\033[1A")
+	r.redrawLine()
+}
+
 // エスケープシーケンスを処理（矢印キーなど）
 func (r *Reader) handleEscapeSequence() error {
 	// エスケープシーケンスの続きを読み取り
-	buffer := make([]byte, 2)
-	n, err := os.Stdin.Read(buffer)
-	if err != nil || n < 2 {
+	next, err := r.readByte()
+	if err != nil {
 		return err
 	}
+	if next != '[' {
+		// Alt+キー、vi ではノーマルモードへの切り替え
+		r.handleAltKey(next)
+		return nil
+	}
 
 	// [ + キーコード のパターン
-	if buffer[0] == '[' {
-		switch buffer[1] {
-		case KeyUp:
-			// 上矢印: 履歴を戻る
-			if prev := r.history.Previous(); prev != r.currentLine {
-				r.currentLine = prev
-				r.cursorPos = len([]rune(r.currentLine)) // ルーン単位でカーソル位置設定
-				r.redrawLine()
-			}
+	code, err := r.readByte()
+	if err != nil {
+		return err
+	}
+	switch code {
+	case KeyUp:
+		// 上矢印: 履歴を戻る
+		if prev := r.history.Previous(); prev != r.currentLine {
+			r.currentLine = prev
+			r.cursorPos = len([]rune(r.currentLine)) // ルーン単位でカーソル位置設定
+			r.redrawLine()
+		}
 
-		case KeyDown:
-			// 下矢印: 履歴を進む
-			if next := r.history.Next(); next != r.currentLine {
-				r.currentLine = next
-				r.cursorPos = len([]rune(r.currentLine)) // ルーン単位でカーソル位置設定
-				r.redrawLine()
-			}
+	case KeyDown:
+		// 下矢印: 履歴を進む
+		if next := r.history.Next(); next != r.currentLine {
+			r.currentLine = next
+			r.cursorPos = len([]rune(r.currentLine)) // ルーン単位でカーソル位置設定
+			r.redrawLine()
+		}
 
-		case KeyLeft:
-			// 左矢印: カーソルを左に移動（UTF-8対応）
-			if r.cursorPos > 0 {
-				r.cursorPos--
-				r.redrawLine() // 完全な再描画でカーソル位置を同期
-			}
+	case KeyLeft:
+		// 左矢印: カーソルを左に移動（UTF-8対応）
+		if r.cursorPos > 0 {
+			r.cursorPos--
+			r.redrawLine() // 完全な再描画でカーソル位置を同期
+		}
 
-		case KeyRight:
-			// 右矢印: カーソルを右に移動（UTF-8対応）
-			runes := []rune(r.currentLine)
-			if r.cursorPos < len(runes) {
-				r.cursorPos++
-				r.redrawLine() // 完全な再描画でカーソル位置を同期
-			}
+	case KeyRight:
+		// 右矢印: カーソルを右に移動（UTF-8対応）
+		runes := []rune(r.currentLine)
+		if r.cursorPos < len(runes) {
+			r.cursorPos++
+			r.redrawLine() // 完全な再描画でカーソル位置を同期
+		}
 
-		case '3':
-			// Delete key: [3~ シーケンス - より安全な処理
-			buffer2 := make([]byte, 1)
-			n2, err2 := os.Stdin.Read(buffer2)
-			if err2 != nil {
-				// 読み取りエラーの場合は静かに無視
+	case '2':
+		// [200~ で始まる括弧付き貼り付け（[2~ の Insert キーは無視）
+		rest := make([]byte, 0, 3)
+		for len(rest) < 3 {
+			b, err := r.readByte()
+			if err != nil {
 				return nil
 			}
-			if n2 == 1 && buffer2[0] == '~' {
-				// Delete: カーソル位置の文字を削除（UTF-8対応）
-				r.performDelete()
+			rest = append(rest, b)
+			if b == '~' {
+				break
 			}
-			// それ以外の場合は無視（Deleteキーでない）
 		}
+		if string(rest) == "00~" {
+			r.readPaste()
+		}
+
+	case '3':
+		// Delete key: [3~ シーケンス - より安全な処理
+		tilde, err := r.readByte()
+		if err != nil {
+			// 読み取りエラーの場合は静かに無視
+			return nil
+		}
+		if tilde == '~' {
+			// Delete: カーソル位置の文字を削除（UTF-8対応）
+			r.performDelete()
+		}
+		// それ以外の場合は無視（Deleteキーでない）
 	}
 
 	return nil
 }
 
+// readPaste は括弧付き貼り付けの終わり（ESC [201~）まで読み取り、入力行に挿入する
+// 複数行・長い貼り付けはプロンプトに展開せず、目印を挿入して添付として扱う
+func (r *Reader) readPaste() {
+	const end = "\033[201~"
+	var data []byte
+	for !strings.HasSuffix(string(data), end) {
+		b, err := r.readByte()
+		if err != nil {
+			return
+		}
+		data = append(data, b)
+	}
+	text := normalizePaste(strings.TrimSuffix(string(data), end))
+	if text == "" {
+		return
+	}
+
+	if IsLargePaste(text) {
+		r.pasteSeq++
+		paste := Paste{ID: r.pasteSeq, Text: text}
+		r.pastes = append(r.pastes, paste)
+		r.insertRunes([]rune(paste.Placeholder()))
+		return
+	}
+	r.insertRunes([]rune(text))
+}
+
+// TakePastes は直前に読み取った行に貼り付けた添付を取り出す
+func (r *Reader) TakePastes() []Paste {
+	pastes := r.pastes
+	r.pastes = nil
+	return pastes
+}
+
+// SetCopyHandler はコードブロックをコピーするキー（Ctrl+X y、Ctrl+X <1-9>）の処理を設定
+func (r *Reader) SetCopyHandler(handler CopyHandler) {
+	r.copyHandler = handler
+}
+
 // Delete操作を実行（共通化）
 func (r *Reader) performDelete() {
 	runes := []rune(r.currentLine)
@@ -515,14 +806,8 @@ func (r *Reader) showSuggestions(suggestions []string) {
 	fmt.Print("\033[u")
 }
 
-// 高度な補完候補を表示（詳細情報付き）
+// 高度な補完候補を入力行の下に表示し、プロンプトを次の行に描き直す
 func (r *Reader) showAdvancedSuggestions(candidates []CompletionCandidate) {
-	// 現在の行を保存
-	fmt.Print("\033[s")
-
-	// 新しい行に移動
-	fmt.Print("\n")
-
 	// カラーコード
 	gray := "\033[90m"
 	green := "\033[32m"
@@ -531,14 +816,15 @@ func (r *Reader) showAdvancedSuggestions(candidates []CompletionCandidate) {
 	cyan := "\033[36m"
 	reset := "\033[0m"
 
-	fmt.Printf("%s候補:%s\n", gray, reset)
-
-	maxDisplay := 6
-	if len(candidates) > maxDisplay {
-		candidates = candidates[:maxDisplay]
-	}
+	fmt.Printf("\r\n%s候補:%s\r\n", gray, reset)
 
+	maxDisplay := 10
 	for i, candidate := range candidates {
+		if i >= maxDisplay {
+			fmt.Printf("  %s... (%d more)%s\r\n", gray, len(candidates)-maxDisplay, reset)
+			break
+		}
+
 		// 補完タイプによる色分け
 		var typeColor string
 		var typeIcon string
@@ -559,31 +845,47 @@ func (r *Reader) showAdvancedSuggestions(candidates []CompletionCandidate) {
 		case CompletionProjectCommand:
 			typeColor = "\033[35m" // マゼンタ
 			typeIcon = "🔧"
+		case CompletionTool:
+			typeColor = cyan
+			typeIcon = "🛠"
 		default:
 			typeColor = gray
 			typeIcon = "💡"
 		}
 
-		// スコア表示（デバッグ用、実際は非表示推奨）
-		scoreStr := ""
-		if candidate.Score > 0 {
-			scoreStr = fmt.Sprintf(" %s(%.2f)%s", gray, candidate.Score, reset)
-		}
-
-		fmt.Printf("  %s%s %s%s%s %s%s%s%s\n",
+		fmt.Printf("  %s%s %s%s %s%s%s\r\n",
 			typeColor, typeIcon, candidate.Text, reset,
-			scoreStr,
-			gray, candidate.Description, reset, gray)
+			gray, candidate.Description, reset)
+	}
 
-		if i >= maxDisplay-1 && len(candidates) > maxDisplay {
-			fmt.Printf("  %s... (%d more)%s\n", gray, len(candidates)-maxDisplay, reset)
-			break
-		}
+	r.redrawLine()
+}
+
+// completeAtCursor はカーソル位置の単語を補完し、補完が進まなければ候補を一覧表示
+func (r *Reader) completeAtCursor() {
+	line, cursor, candidates := r.completer.advancedCompleter.CompleteLine([]rune(r.currentLine), r.cursorPos)
+	if len(candidates) > 0 {
+		r.showAdvancedSuggestions(candidates)
+		return
 	}
+	if len(string(line)) > MaxLineLength {
+		return
+	}
+	r.currentLine = string(line)
+	r.cursorPos = cursor
+	r.redrawLine()
+}
 
-	fmt.Print("\n")
-	// 元の位置に戻る
-	fmt.Print("\033[u")
+// SetToolNames はツール名の補完に使う一覧（名前と説明）の取得元を設定
+func (r *Reader) SetToolNames(source func() map[string]string) {
+	r.completer.advancedCompleter.SetToolNames(source)
+}
+
+// SetWorkDir は補完・@ファイルピッカーの基準ディレクトリを切り替える
+func (r *Reader) SetWorkDir(workDir string) {
+	r.completer.currentDir = workDir
+	r.completer.advancedCompleter.SetWorkDir(workDir)
+	r.filePicker = NewFilePicker(workDir)
 }
 
 // フォールバック：通常の入力処理
@@ -674,8 +976,23 @@ func (c *Completer) getFilePathSuggestions(input string) []string {
 	return suggestions
 }
 
-// マルチライン入力の読み取り
+// マルチライン入力の読み取り（端末では外部エディタで作成、それ以外は空行で送信）
 func (r *Reader) ReadMultiLine() (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		text, err := EditText("")
+		if err != nil {
+			return "", err
+		}
+		if strings.TrimSpace(text) == "" {
+			return "", fmt.Errorf("interrupted")
+		}
+		line, ok := r.accept(text)
+		if !ok {
+			return "", fmt.Errorf("入力が検証を通りませんでした")
+		}
+		return line, nil
+	}
+
 	var lines []string
 
 	fmt.Printf("マルチライン入力モード (空行で送信, Ctrl+C でキャンセル):\n")
@@ -282,8 +282,11 @@ func (s *SecurityValidator) ValidateInput(input string, clientID string) (string
 	}
 
 	// コマンドインジェクション検証
-	if err := s.ValidateCommand(input); err != nil {
-		return "", err
+	// !command はシェルへの直接実行指示のため、実行時にBashToolのセキュリティ制約で検証する
+	if !strings.HasPrefix(input, "!") {
+		if err := s.ValidateCommand(input); err != nil {
+			return "", err
+		}
 	}
 
 	// パスインジェクション検証（パスらしき文字列が含まれる場合）
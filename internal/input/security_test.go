@@ -394,6 +394,11 @@ func TestSecurityValidator_ValidateInput(t *testing.T) {
 			input:       "cat ../../../etc/passwd",
 			shouldError: true,
 		},
+		{
+			name:        "Shell passthrough is validated by BashTool instead",
+			input:       "!go test ./... | grep FAIL",
+			shouldError: false,
+		},
 		{
 			name:        "Command injection attempt",
 			input:       "ls && rm file",
@@ -0,0 +1,160 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/ui"
+)
+
+// モデルに返す実行結果1件あたりの上限（ファイル内容など）
+const agentLoopMaxObservationBytes = 16 * 1024
+
+// agentLoopConfig はエージェントループ設定を返す
+func (ism *interactiveSessionManager) agentLoopConfig() config.AgentLoopConfig {
+	if ism.config == nil {
+		return config.DefaultAgentLoopConfig()
+	}
+	return ism.config.AgentLoop
+}
+
+// truncateObservation はモデルに返す実行結果を上限の長さに切り詰める
+func truncateObservation(content string) string {
+	if len(content) <= agentLoopMaxObservationBytes {
+		return content
+	}
+	return content[:agentLoopMaxObservationBytes] + "\n...(truncated)"
+}
+
+// agentObservationPrompt はツールの実行結果をモデルに返し、続きを求めるメッセージ
+func agentObservationPrompt(execution *structuredExecution, remaining int) string {
+	prompt := i18n.T("agentloop.observation", strings.Join(execution.observations, "\n\n"))
+	if remaining == 1 {
+		return prompt + i18n.T("agentloop.last_step")
+	}
+	return prompt + i18n.T("agentloop.remaining", remaining)
+}
+
+// runAgentLoop は構造化タグを実行した応答について、実行結果をモデルに返して次の応答を求めることを
+// ツールを使わない最終回答・最大ステップ数・ターンの上限（ツール実行回数・トークン数・時間）のいずれかまで繰り返し、
+// 各ステップの本文と実行結果をまとめた応答を返す（無効時・提案のみの応答は1回の実行結果をそのまま返す）
+func (ism *interactiveSessionManager) runAgentLoop(
+	ctx context.Context,
+	session *InteractiveSession,
+	request llm.ChatRequest,
+	content string,
+	execution *structuredExecution,
+	progress *ui.ProgressIndicator,
+) *InteractionResponse {
+	cfg := ism.agentLoopConfig()
+	if !cfg.Enabled || cfg.MaxSteps < 2 || ism.llmProvider == nil || execution.toolCalls == 0 {
+		return ism.buildStructuredResponse(session, content, execution)
+	}
+
+	var message strings.Builder
+	writeStep := func(step int, content string, execution *structuredExecution) {
+		if step > 1 {
+			message.WriteString("\n\n" + i18n.T("agentloop.step", step) + "\n")
+		}
+		message.WriteString(ism.extractCleanMessage(content))
+		if len(execution.results) > 0 {
+			message.WriteString("\n\n" + i18n.T("agentloop.results") + "\n")
+			message.WriteString(strings.Join(execution.results, "\n\n"))
+		}
+	}
+	writeStep(1, content, execution)
+
+	actions := append([]string(nil), execution.actions...)
+	suggestions := append([]string(nil), execution.suggestions...)
+	analysis := execution.analysis
+	messages := append([]llm.ChatMessage(nil), request.Messages...)
+	previous := strings.Join(execution.actions, "\n")
+	received := len(content) / 4
+	step := 1
+	final := false
+	stopReason := ""
+
+	for {
+		if step >= cfg.MaxSteps {
+			stopReason = i18n.T("agentloop.max_steps", step)
+			break
+		}
+		// ターンの上限・中断で止まった場合の要約は ProcessUserInput が追記する
+		if budget.Check(ctx) != nil || ctx.Err() != nil {
+			break
+		}
+
+		messages = append(messages,
+			llm.ChatMessage{Role: "assistant", Content: content},
+			llm.ChatMessage{Role: "user", Content: agentObservationPrompt(execution, cfg.MaxSteps-step)},
+		)
+		request.Messages = messages
+		chat, streamed, err := ism.chatWithToolStream(ctx, session, request)
+		if err != nil {
+			if !budget.IsExceeded(err) && ctx.Err() == nil {
+				stopReason = i18n.T("agentloop.error", err)
+			}
+			break
+		}
+		step++
+		if progress != nil {
+			received += len(chat.Message.Content) / 4
+			progress.UpdateTokens(received)
+		}
+
+		content = ism.normalizeLanguage(chat.Message.Content)
+		ism.addToSmartContext(session.ID, content, "llm_response")
+		execution = ism.executeStructuredTags(ctx, session, content, streamed)
+		actions = append(actions, execution.actions...)
+		suggestions = append(suggestions, execution.suggestions...)
+		if execution.analysis != nil {
+			analysis = execution.analysis
+		}
+
+		// ツールを使わない応答が最終回答
+		if execution.toolCalls == 0 {
+			message.WriteString("\n\n")
+			message.WriteString(ism.extractCleanMessage(content))
+			final = true
+			break
+		}
+		writeStep(step, content, execution)
+
+		current := strings.Join(execution.actions, "\n")
+		if current == previous {
+			stopReason = i18n.T("agentloop.repeated")
+			break
+		}
+		previous = current
+	}
+
+	if len(suggestions) > 0 {
+		message.WriteString("\n\n💡 **次のステップの提案:**\n• ")
+		message.WriteString(strings.Join(suggestions, "\n• "))
+	}
+	if stopReason != "" {
+		message.WriteString("\n\n" + stopReason)
+	}
+
+	return &InteractionResponse{
+		SessionID:            session.ID,
+		ResponseType:         ResponseTypeMessage,
+		Message:              message.String(),
+		RequiresConfirmation: false,
+		Analysis:             analysis,
+		GeneratedAt:          time.Now(),
+		Metadata: map[string]string{
+			"actions_count":    fmt.Sprintf("%d", len(actions)),
+			"has_executions":   "true",
+			"executed_actions": strings.Join(actions, ", "),
+			"agent_steps":      fmt.Sprintf("%d", step),
+			"agent_final":      fmt.Sprintf("%t", final),
+		},
+	}
+}
@@ -0,0 +1,290 @@
+package interactive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// scriptedProvider は用意した応答を順に返し、受け取った要求を記録するテスト用プロバイダー
+type scriptedProvider struct {
+	llm.Provider
+	responses []string
+	requests  []llm.ChatRequest
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	content := p.responses[len(p.responses)-1]
+	if len(p.requests) <= len(p.responses) {
+		content = p.responses[len(p.requests)-1]
+	}
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: content}, Done: true}, nil
+}
+
+func newAgentLoopManager(t *testing.T, provider llm.Provider, loop config.AgentLoopConfig) (*interactiveSessionManager, *InteractiveSession) {
+	t.Helper()
+	cfg := config.DefaultConfig()
+	cfg.AgentLoop = loop
+	manager := NewInteractiveSessionManager(nil, provider, nil, nil, nil, "test-model", cfg).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+	return manager, session
+}
+
+// runFirstStep は最初の応答のタグを実行してからループを回す
+func runFirstStep(manager *interactiveSessionManager, session *InteractiveSession, content string) *InteractionResponse {
+	ctx := context.Background()
+	request := llm.ChatRequest{Model: "test-model", Messages: []llm.ChatMessage{{Role: "user", Content: "find the bug and fix it"}}}
+	execution := manager.executeStructuredTags(ctx, session, content, nil)
+	return manager.runAgentLoop(ctx, session, request, content, execution, nil)
+}
+
+func TestAgentLoopUntilFinalAnswer(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{
+		"Looking closer. <COMMAND>echo step-two</COMMAND>",
+		"The bug was an off-by-one error; it is fixed now.",
+	}}
+	manager, session := newAgentLoopManager(t, provider, config.DefaultAgentLoopConfig())
+
+	response := runFirstStep(manager, session, "Let me check. <COMMAND>echo step-one</COMMAND>")
+
+	if len(provider.requests) != 2 {
+		t.Fatalf("expected 2 follow-up requests, got %d", len(provider.requests))
+	}
+	// 2回目の要求には1回目の応答と実行結果が会話として含まれる
+	messages := provider.requests[0].Messages
+	if len(messages) != 3 || messages[1].Role != "assistant" || !strings.Contains(messages[2].Content, "step-one") {
+		t.Errorf("tool results were not fed back: %+v", messages)
+	}
+	if len(provider.requests[1].Messages) != 5 || !strings.Contains(provider.requests[1].Messages[4].Content, "step-two") {
+		t.Errorf("second step results were not fed back: %+v", provider.requests[1].Messages)
+	}
+
+	for _, want := range []string{"step-one", "step-two", "off-by-one"} {
+		if !strings.Contains(response.Message, want) {
+			t.Errorf("response does not contain %q:\n%s", want, response.Message)
+		}
+	}
+	if response.Metadata["agent_steps"] != "3" || response.Metadata["agent_final"] != "true" {
+		t.Errorf("unexpected metadata: %+v", response.Metadata)
+	}
+}
+
+func TestAgentLoopStops(t *testing.T) {
+	t.Run("repeated actions", func(t *testing.T) {
+		provider := &scriptedProvider{responses: []string{"<COMMAND>echo again</COMMAND>"}}
+		manager, session := newAgentLoopManager(t, provider, config.DefaultAgentLoopConfig())
+
+		response := runFirstStep(manager, session, "<COMMAND>echo first</COMMAND>")
+		if len(provider.requests) != 2 || response.Metadata["agent_final"] != "false" {
+			t.Errorf("expected to stop after the repeated step, got %d requests: %+v", len(provider.requests), response.Metadata)
+		}
+	})
+
+	t.Run("max steps", func(t *testing.T) {
+		provider := &scriptedProvider{responses: []string{"<COMMAND>echo a</COMMAND>", "<COMMAND>echo b</COMMAND>", "<COMMAND>echo c</COMMAND>"}}
+		manager, session := newAgentLoopManager(t, provider, config.AgentLoopConfig{Enabled: true, MaxSteps: 3})
+
+		response := runFirstStep(manager, session, "<COMMAND>echo start</COMMAND>")
+		if len(provider.requests) != 2 || response.Metadata["agent_steps"] != "3" {
+			t.Errorf("expected 2 follow-up requests within 3 steps, got %d: %+v", len(provider.requests), response.Metadata)
+		}
+	})
+
+	t.Run("disabled", func(t *testing.T) {
+		provider := &scriptedProvider{responses: []string{"done"}}
+		manager, session := newAgentLoopManager(t, provider, config.AgentLoopConfig{Enabled: false, MaxSteps: 10})
+
+		response := runFirstStep(manager, session, "<COMMAND>echo once</COMMAND>")
+		if len(provider.requests) != 0 || !strings.Contains(response.Message, "once") {
+			t.Errorf("disabled loop should only run the first step: %d requests", len(provider.requests))
+		}
+	})
+}
+
+func TestToolStreamRunsCommandsWhileGenerating(t *testing.T) {
+	manager, session := newAgentLoopManager(t, &scriptedProvider{responses: []string{"unused"}}, config.DefaultAgentLoopConfig())
+
+	stream := newToolStream(context.Background(), manager, session)
+	stream.update("Checking. <COMMAND>echo stream-one</COM")
+	stream.update("Checking. <COMMAND>echo stream-one</COMMAND> then <COMMAND>echo stream-two</COMMAND>")
+	stream.close()
+
+	if len(stream.commands) != 2 || stream.executed != 2 {
+		t.Fatalf("expected 2 executed commands, got %d (executed %d)", len(stream.commands), stream.executed)
+	}
+	for i, want := range []string{"stream-one", "stream-two"} {
+		if !strings.Contains(stream.commands[i].output, want) || stream.commands[i].err != nil {
+			t.Errorf("command %d: output %q, err %v", i, stream.commands[i].output, stream.commands[i].err)
+		}
+	}
+	if _, ok := stream.take("echo stream-two#1"); !ok {
+		t.Error("the second command should be reused")
+	}
+	if _, ok := stream.take("echo other#1"); ok {
+		t.Error("a command that was not streamed must run")
+	}
+	if _, ok := (*toolStream)(nil).take("echo stream-one#1"); ok {
+		t.Error("a nil stream has no results")
+	}
+}
+
+func TestToolStreamDoesNotRerunCommandsOnRetry(t *testing.T) {
+	manager, session := newAgentLoopManager(t, &scriptedProvider{responses: []string{"unused"}}, config.DefaultAgentLoopConfig())
+
+	stream := newToolStream(context.Background(), manager, session)
+	stream.update("Let me look. <COMMAND>echo first-try</COMMAND> and <COMMAND>echo fir")
+	// 再試行で本文が最初から渡し直された（実行済みのブロックは同じIDになる）
+	stream.update("Looking. <COMMAND>echo first-try</COMMAND>")
+	stream.update("Looking. <COMMAND>echo first-try</COMMAND> <COMMAND>echo second-try</COMMAND> <COMMAND>echo first-try</COMMAND>")
+	stream.close()
+
+	var ids []string
+	for _, command := range stream.commands {
+		ids = append(ids, command.id)
+	}
+	if want := "echo first-try#1,echo second-try#1,echo first-try#2"; strings.Join(ids, ",") != want || stream.executed != 3 {
+		t.Fatalf("each block should run once: %v (executed %d), want %s", ids, stream.executed, want)
+	}
+
+	// 完成した応答のコマンドは全て生成中の結果を使う
+	stream.byID["echo first-try#1"].output = "result of the first attempt"
+	execution := manager.executeStructuredTags(context.Background(), session,
+		"Looking. <COMMAND>echo first-try</COMMAND> <COMMAND>echo second-try</COMMAND> <COMMAND>echo first-try</COMMAND>", stream)
+	if len(execution.results) != 3 || !strings.Contains(execution.results[0], "result of the first attempt") ||
+		!strings.Contains(execution.results[1], "second-try") {
+		t.Errorf("unexpected results: %+v", execution.results)
+	}
+}
+
+func TestExecuteStructuredTagsReusesStreamedResults(t *testing.T) {
+	manager, session := newAgentLoopManager(t, &scriptedProvider{responses: []string{"unused"}}, config.DefaultAgentLoopConfig())
+
+	stream := newToolStream(context.Background(), manager, session)
+	stream.update("<COMMAND>echo streamed</COMMAND>")
+	stream.close()
+	stream.commands[0].output = "result recorded while generating"
+
+	execution := manager.executeStructuredTags(context.Background(), session, "<COMMAND>echo streamed</COMMAND>", stream)
+	if len(execution.results) != 1 || !strings.Contains(execution.results[0], "result recorded while generating") {
+		t.Errorf("the streamed result was not reused: %+v", execution.results)
+	}
+	if execution.toolCalls != 1 {
+		t.Errorf("toolCalls = %d, want 1", execution.toolCalls)
+	}
+}
+
+func TestExecuteStructuredTagsAppliesPatch(t *testing.T) {
+	manager, session := newAgentLoopManager(t, &scriptedProvider{responses: []string{"unused"}}, config.DefaultAgentLoopConfig())
+	dir := t.TempDir()
+	manager.patchTool = tools.NewApplyPatchTool(security.NewDefaultConstraints(dir), dir, 1024*1024)
+	target := filepath.Join(dir, "greet.go")
+	if err := os.WriteFile(target, []byte("package greet\n\nfunc Hello() string {\n\treturn \"hi\"\n}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	content := "Fixing the greeting.\n<PATCH>\n--- a/greet.go\n+++ b/greet.go\n@@ -3,3 +3,3 @@\n func Hello() string {\n-\treturn \"hi\"\n+\treturn \"hello\"\n }\n</PATCH>"
+	execution := manager.executeStructuredTags(context.Background(), session, content, nil)
+	if len(execution.results) != 1 || !strings.Contains(execution.results[0], "greet.go") {
+		t.Fatalf("unexpected results: %+v", execution.results)
+	}
+	data, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `return "hello"`) {
+		t.Errorf("patch was not applied:\n%s", data)
+	}
+	if modified := manager.takeModifiedFiles(session.ID); len(modified) != 1 || modified[0] != "greet.go" {
+		t.Errorf("modified files = %v", modified)
+	}
+	if clean := manager.extractCleanMessage(content); strings.Contains(clean, "PATCH") {
+		t.Errorf("patch tag was not removed: %q", clean)
+	}
+}
+
+func TestExecuteStructuredTagsMovesAndDeletesFiles(t *testing.T) {
+	manager, session := newAgentLoopManager(t, &scriptedProvider{responses: []string{"unused"}}, config.DefaultAgentLoopConfig())
+	dir := t.TempDir()
+	manager.fileOpsTool = tools.NewFileOpsTool(security.NewDefaultConstraints(dir), dir)
+	manager.fileOpsTool.SetJournalDir(t.TempDir())
+	for _, name := range []string{"old.go", "unused.go"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("package a\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	content := "<FILEMOVE>old.go|new.go</FILEMOVE>\n<FILEDELETE>unused.go</FILEDELETE>"
+	execution := manager.executeStructuredTags(context.Background(), session, content, nil)
+	if len(execution.results) != 2 || !strings.HasPrefix(execution.results[0], "✅") || !strings.HasPrefix(execution.results[1], "⚠️") {
+		t.Fatalf("unexpected results: %+v", execution.results)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.go")); err != nil {
+		t.Errorf("file was not moved: %v", err)
+	}
+	// 確認方法がなければ削除しない
+	if _, err := os.Stat(filepath.Join(dir, "unused.go")); err != nil {
+		t.Errorf("file must not be deleted without confirmation: %v", err)
+	}
+	if clean := manager.extractCleanMessage(content); clean != "実行しました。" {
+		t.Errorf("file operation tags were not removed: %q", clean)
+	}
+}
+
+func TestExecuteStructuredTagsShowsTree(t *testing.T) {
+	manager, session := newAgentLoopManager(t, &scriptedProvider{responses: []string{"unused"}}, config.DefaultAgentLoopConfig())
+
+	content := "Let me look around.\n<TREE>. depth=1</TREE>\n<TREE>../../.. max=5</TREE>"
+	execution := manager.executeStructuredTags(context.Background(), session, content, nil)
+	if len(execution.results) != 2 || execution.toolCalls != 2 {
+		t.Fatalf("unexpected results: %+v", execution.results)
+	}
+	if !strings.HasPrefix(execution.results[0], "🌳 .:") || !strings.Contains(execution.results[0], "  tree.go\n") || !strings.Contains(execution.results[0], "(depth 1)") {
+		t.Errorf("unexpected tree: %s", execution.results[0])
+	}
+	// ワークスペースの外は辿らない
+	if !strings.HasPrefix(execution.results[1], "⚠️") {
+		t.Errorf("path outside the workspace should be rejected: %s", execution.results[1])
+	}
+	if clean := manager.extractCleanMessage(content); strings.Contains(clean, "TREE") {
+		t.Errorf("tree tag was not removed: %q", clean)
+	}
+
+	path, opts := parseTreeSpec(" internal/tools depth=2 max=50 hidden ")
+	if path != "internal/tools" || opts.Depth != 2 || opts.MaxEntries != 50 || !opts.ShowHidden {
+		t.Errorf("parseTreeSpec = %q %+v", path, opts)
+	}
+}
+
+// TestAgentObservationPrompt は実行結果を返すメッセージが表示言語のカタログから作られることをテストする
+func TestAgentObservationPrompt(t *testing.T) {
+	defer i18n.SetLanguage(string(i18n.Current()))
+	execution := &structuredExecution{observations: []string{"$ echo one\none", "$ echo two\ntwo"}}
+
+	i18n.SetLanguage("en")
+	prompt := agentObservationPrompt(execution, 3)
+	if !strings.HasPrefix(prompt, "Results of the tools you used:\n\n$ echo one\none\n\n$ echo two\ntwo\n\n") ||
+		!strings.HasSuffix(prompt, "You have at most 3 more responses.") {
+		t.Errorf("unexpected en prompt:\n%s", prompt)
+	}
+	if prompt := agentObservationPrompt(execution, 1); !strings.HasSuffix(prompt, "give the final answer now.") {
+		t.Errorf("last step should ask for the final answer:\n%s", prompt)
+	}
+
+	i18n.SetLanguage("ja")
+	prompt = agentObservationPrompt(execution, 2)
+	if !strings.HasPrefix(prompt, "使用したツールの実行結果:\n\n$ echo one") || !strings.HasSuffix(prompt, "残りの応答は最大 2 回です。") {
+		t.Errorf("unexpected ja prompt:\n%s", prompt)
+	}
+}
@@ -0,0 +1,73 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+)
+
+// AnalysisReport はプロジェクト分析の構造化された結果（vyb analyze --json・ヘッドレス出力・エディタ連携用）
+type AnalysisReport struct {
+	Query       string                    `json:"query,omitempty"`
+	ProjectPath string                    `json:"project_path"`
+	Project     *analysis.ProjectAnalysis `json:"project,omitempty"`
+	Changes     *DetailedDiffAnalysis     `json:"changes,omitempty"` // 未コミットの変更（git 管理外なら省略）
+	Errors      []string                  `json:"errors,omitempty"`  // 失敗した分析（他の分析結果は返す）
+	GeneratedAt time.Time                 `json:"generated_at"`
+}
+
+// BuildAnalysisReport は analyzer でプロジェクトを分析し、未コミットの変更と合わせて構造化する
+func BuildAnalysisReport(ctx context.Context, analyzer *analysis.UnifiedAnalyzer, projectPath string, query string) *AnalysisReport {
+	report := &AnalysisReport{Query: query, ProjectPath: projectPath, GeneratedAt: time.Now()}
+
+	project, err := analyzer.AnalyzeProject(ctx, projectPath)
+	if err != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("project: %v", err))
+	} else {
+		report.Project = project
+	}
+
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-color", "--no-ext-diff", "HEAD")
+	cmd.Dir = projectPath
+	if output, err := cmd.Output(); err == nil {
+		report.Changes = AnalyzeDiff(string(output))
+	}
+	return report
+}
+
+// AnalyzeDiff は git diff の出力を構造化して分析（summarizeGitDiff の表示と同じ内容）
+func AnalyzeDiff(diffOutput string) *DetailedDiffAnalysis {
+	// 差分の分析はセッションの状態を使わない
+	var ism interactiveSessionManager
+	return ism.performDetailedDiffAnalysis(diffOutput)
+}
+
+// AnalysisReport は作業ディレクトリのプロジェクト分析を構造化して返す
+// 前回から HEAD と未コミットの変更が変わっていなければキャッシュした分析を使う
+func (ism *interactiveSessionManager) AnalysisReport(ctx context.Context, query string) (*AnalysisReport, error) {
+	if ism.config == nil {
+		return nil, fmt.Errorf("設定が読み込まれていないため分析できません")
+	}
+	analyzer := ism.sharedUnifiedAnalyzer()
+	if analyzer == nil {
+		return nil, fmt.Errorf("分析器を利用できません")
+	}
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	if analyzer.CachedProject(ctx, projectPath) == nil {
+		var release func()
+		ctx, release, err = ism.acquireAnalysis(ctx)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+	return BuildAnalysisReport(ctx, analyzer, projectPath, query), nil
+}
@@ -0,0 +1,364 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// 提案の自動承認ポリシー（config.ApprovalConfig）の判定
+const (
+	approvalAuto   = "auto"
+	approvalPrompt = "prompt"
+	approvalDeny   = "deny"
+)
+
+// approvalFacts はルールと照合する提案の属性
+type approvalFacts struct {
+	kind       string // create, edit, command
+	change     string // docs, test, source
+	path       string // 作業ディレクトリからの相対パス（スラッシュ区切り、コマンドは空）
+	branch     string // 現在のgitブランチ（取得できなければ空）
+	confidence float64
+	impact     ImpactLevel
+}
+
+// approvalDecision はポリシーの評価結果（一致するルールがなければ prompt）
+type approvalDecision struct {
+	decision string
+	rule     string // 一致したルール名
+}
+
+// matchApprovalRule は上から順に評価して最初に一致したルールを返す
+func matchApprovalRule(rules []config.ApprovalRule, facts approvalFacts) (config.ApprovalRule, int, bool) {
+	for i, rule := range rules {
+		if approvalRuleMatches(rule, facts) {
+			return rule, i, true
+		}
+	}
+	return config.ApprovalRule{}, -1, false
+}
+
+// approvalRuleMatches はルールの全ての条件に提案が一致するか判定
+func approvalRuleMatches(rule config.ApprovalRule, facts approvalFacts) bool {
+	if len(rule.Kinds) > 0 && !containsString(rule.Kinds, facts.kind) {
+		return false
+	}
+	if len(rule.Changes) > 0 && !containsString(rule.Changes, facts.change) {
+		return false
+	}
+	if len(rule.Paths) > 0 && !matchAnyPathGlob(rule.Paths, facts.path) {
+		return false
+	}
+	if len(rule.Branches) > 0 {
+		if facts.branch == "" {
+			return false
+		}
+		matched := false
+		for _, pattern := range rule.Branches {
+			if ok, _ := filepath.Match(pattern, facts.branch); ok {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if rule.MinConfidence > 0 && facts.confidence < rule.MinConfidence {
+		return false
+	}
+	if rule.MaxImpact != "" {
+		maxImpact, ok := parseImpactLevel(rule.MaxImpact)
+		if !ok || facts.impact > maxImpact {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAnyPathGlob はパスがいずれかのglobに一致するか判定
+// "/" を含まないパターンはファイル名と、dir/** はそのディレクトリ配下の全てと照合する
+func matchAnyPathGlob(patterns []string, path string) bool {
+	if path == "" {
+		return false
+	}
+	for _, pattern := range patterns {
+		pattern = filepath.ToSlash(pattern)
+		switch {
+		case strings.HasSuffix(pattern, "/**"):
+			if strings.HasPrefix(path, strings.TrimSuffix(pattern, "**")) {
+				return true
+			}
+		case !strings.Contains(pattern, "/"):
+			if ok, _ := filepath.Match(pattern, filepath.Base(path)); ok {
+				return true
+			}
+		default:
+			if ok, _ := filepath.Match(pattern, path); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// parseImpactLevel は影響レベルの名前（low, medium, high, critical）を変換
+func parseImpactLevel(name string) (ImpactLevel, bool) {
+	for i, level := range config.ValidImpactLevels() {
+		if strings.EqualFold(name, level) {
+			return ImpactLevel(i), true
+		}
+	}
+	return ImpactLevelLow, false
+}
+
+// evaluateApproval は確認ダイアログの前に提案を自動承認ポリシーで評価し、結果を提案のメタデータに残す（/why で表示する）
+// 危険なファイル操作・対象ファイルが特定できない提案、kinds に command がないルールに一致したコマンドは auto でも確認する
+func (ism *interactiveSessionManager) evaluateApproval(ctx context.Context, suggestion *CodeSuggestion) approvalDecision {
+	decision := approvalDecision{decision: approvalPrompt}
+	if ism.config == nil || len(ism.config.Approval.Rules) == 0 || suggestion == nil {
+		return decision
+	}
+
+	rules := ism.config.Approval.Rules
+	facts := ism.approvalFacts(ctx, suggestion, rulesNeedBranch(rules))
+	rule, index, ok := matchApprovalRule(rules, facts)
+	if !ok {
+		return decision
+	}
+	decision.decision = rule.Decision
+	decision.rule = rule.Name
+	if decision.rule == "" {
+		decision.rule = fmt.Sprintf("#%d", index+1)
+	}
+	if decision.decision == approvalAuto {
+		if facts.kind == "command" {
+			// コマンドは種類に command を明示したルールでのみ自動実行する
+			if !containsString(rule.Kinds, "command") {
+				decision.decision = approvalPrompt
+			}
+		} else if facts.path == "" || ism.isDangerousFileOperation(suggestion) {
+			decision.decision = approvalPrompt
+		}
+	}
+
+	if suggestion.Metadata == nil {
+		suggestion.Metadata = make(map[string]string)
+	}
+	suggestion.Metadata["approval"] = fmt.Sprintf("%s (rule %s: %s, %s, confidence %.2f)",
+		decision.decision, decision.rule, facts.kind, facts.change, facts.confidence)
+	return decision
+}
+
+// enforceApproval は評価結果に従って保留中の提案を確認なしで適用（auto）または破棄（deny）する
+// prompt の場合は何もせず、従来どおり確認ダイアログを表示する
+func (ism *interactiveSessionManager) enforceApproval(ctx context.Context, session *InteractiveSession, suggestion *CodeSuggestion, decision approvalDecision) error {
+	if session.PendingSuggestion != suggestion {
+		return nil
+	}
+	switch decision.decision {
+	case approvalAuto:
+		// ユーザーの承認ではないため満足度・承認数の学習には含めない
+		suggestion.UserConfirmed = true
+		session.State = SessionStateExecuting
+		if err := ism.ApplySuggestion(ctx, session.ID, suggestion.ID); err != nil {
+			suggestion.UserConfirmed = false
+			session.PendingSuggestion = suggestion
+			session.State = SessionStateWaitingForConfirmation
+			return err
+		}
+		session.State = SessionStateWaitingForInput
+	case approvalDeny:
+		session.PendingSuggestion = nil
+		session.State = SessionStateIdle
+	}
+	return nil
+}
+
+// applyApprovalToResponse は評価結果を実行し、適用・破棄した場合は確認ダイアログの代わりに結果を応答に含める
+func (ism *interactiveSessionManager) applyApprovalToResponse(
+	ctx context.Context,
+	session *InteractiveSession,
+	response *InteractionResponse,
+	suggestion *CodeSuggestion,
+	decision approvalDecision,
+) {
+	if decision.rule == "" {
+		return
+	}
+	renderApproval(response, suggestion, decision, ism.enforceApproval(ctx, session, suggestion, decision))
+}
+
+// renderApproval は自動承認の結果（enforceApproval のエラーを含む）を応答に含める
+func renderApproval(response *InteractionResponse, suggestion *CodeSuggestion, decision approvalDecision, err error) {
+	if decision.rule == "" {
+		return
+	}
+	response.Metadata["approval"] = decision.decision
+	response.Metadata["approval_rule"] = decision.rule
+	if err != nil {
+		response.Message += "\n\n" + i18n.T("approval.auto_failed", decision.rule, err)
+		return
+	}
+	switch decision.decision {
+	case approvalAuto:
+		response.ResponseType = ResponseTypeCompletion
+		response.RequiresConfirmation = false
+		response.Message += "\n\n" + i18n.T("approval.auto_applied", decision.rule, suggestion.Confidence)
+		response.Metadata["action"] = "suggestion_applied"
+		response.Metadata["suggestion_id"] = suggestion.ID
+		response.Metadata["file_path"] = suggestion.FilePath
+	case approvalDeny:
+		response.RequiresConfirmation = false
+		response.Message += "\n\n" + i18n.T("approval.denied", decision.rule)
+	}
+}
+
+// approvalFacts はルールと照合する提案の属性を集める（ブランチは必要な場合のみ取得）
+func (ism *interactiveSessionManager) approvalFacts(ctx context.Context, suggestion *CodeSuggestion, withBranch bool) approvalFacts {
+	facts := approvalFacts{
+		kind:       "edit",
+		change:     "source",
+		confidence: suggestion.Confidence,
+		impact:     suggestion.ImpactLevel,
+	}
+	if withBranch {
+		facts.branch = currentGitBranch(ctx)
+	}
+
+	if ism.isCommandSuggestion(suggestion.SuggestedCode) {
+		facts.kind = "command"
+		facts.change = ""
+		return facts
+	}
+
+	path := suggestion.FilePath
+	if path == "" {
+		return facts
+	}
+	facts.path = filepath.ToSlash(path)
+	if wd, err := os.Getwd(); err == nil {
+		absPath := path
+		if !filepath.IsAbs(absPath) {
+			absPath = filepath.Join(wd, absPath)
+		}
+		if rel, err := filepath.Rel(wd, absPath); err == nil && !strings.HasPrefix(rel, "..") {
+			facts.path = filepath.ToSlash(rel)
+		}
+	}
+
+	// 元のコードがない提案はファイル全体を書き込む（既存ファイルなら内容と比べる）
+	original := suggestion.OriginalCode
+	if original == "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			facts.kind = "create"
+		} else {
+			original = string(data)
+		}
+	}
+
+	switch {
+	case suggestion.Type == SuggestionTypeTestGeneration || isTestFilePath(facts.path):
+		facts.change = "test"
+	case suggestion.Type == SuggestionTypeDocumentation || isDocumentationPath(facts.path) ||
+		(original != "" && changesOnlyComments(facts.path, original, suggestion.SuggestedCode)):
+		facts.change = "docs"
+	}
+	return facts
+}
+
+// rulesNeedBranch はブランチを条件にするルールがあるか判定
+func rulesNeedBranch(rules []config.ApprovalRule) bool {
+	for _, rule := range rules {
+		if len(rule.Branches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// currentGitBranch は作業ディレクトリの現在のgitブランチ（gitリポジトリ外・detached HEAD なら空）
+func currentGitBranch(ctx context.Context) string {
+	output, err := exec.CommandContext(ctx, "git", "rev-parse", "--abbrev-ref", "HEAD").Output()
+	if err != nil {
+		return ""
+	}
+	branch := strings.TrimSpace(string(output))
+	if branch == "HEAD" {
+		return ""
+	}
+	return branch
+}
+
+// isTestFilePath はテストファイルのパスか判定
+func isTestFilePath(path string) bool {
+	base := filepath.Base(path)
+	ext := filepath.Ext(base)
+	name := strings.TrimSuffix(base, ext)
+	switch {
+	case strings.HasSuffix(name, "_test"), strings.HasPrefix(name, "test_"):
+		return true
+	case strings.HasSuffix(name, ".test"), strings.HasSuffix(name, ".spec"):
+		return true
+	case ext == ".java" && strings.HasSuffix(name, "Test"):
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(path)), "/") {
+		if dir == "test" || dir == "tests" || dir == "__tests__" {
+			return true
+		}
+	}
+	return false
+}
+
+// isDocumentationPath はドキュメントファイルのパスか判定
+func isDocumentationPath(path string) bool {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".md", ".rst", ".txt", ".adoc":
+		return true
+	}
+	return false
+}
+
+// changesOnlyComments はコメント行・空行を除いたコードが変わらない（コメントのみの変更）か判定
+func changesOnlyComments(path, original, suggested string) bool {
+	if original == suggested {
+		return false
+	}
+	hashComments := false
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".py", ".sh", ".bash", ".rb", ".yaml", ".yml", ".toml":
+		hashComments = true
+	}
+	stripComments := func(code string) []string {
+		var lines []string
+		inBlock := false
+		for _, line := range strings.Split(code, "\n") {
+			trimmed := strings.TrimSpace(line)
+			switch {
+			case trimmed == "":
+			case hashComments:
+				if !strings.HasPrefix(trimmed, "#") {
+					lines = append(lines, trimmed)
+				}
+			case inBlock:
+				inBlock = !strings.Contains(trimmed, "*/")
+			case strings.HasPrefix(trimmed, "//"):
+			case strings.HasPrefix(trimmed, "/*"):
+				inBlock = !strings.Contains(trimmed, "*/")
+			default:
+				lines = append(lines, trimmed)
+			}
+		}
+		return lines
+	}
+	return strings.Join(stripComments(original), "\n") == strings.Join(stripComments(suggested), "\n")
+}
@@ -0,0 +1,164 @@
+package interactive
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// examplePolicy はmainブランチでは常に確認し、確度の高いコメントのみの編集とテストファイルの作成は自動で適用し、
+// その他のソースの編集は確認するポリシー
+func examplePolicy() []config.ApprovalRule {
+	return []config.ApprovalRule{
+		{Name: "main-branch", Decision: "prompt", Branches: []string{"main", "master"}},
+		{Name: "doc-comments", Decision: "auto", Kinds: []string{"edit"}, Changes: []string{"docs"}, MinConfidence: 0.9},
+		{Name: "new-tests", Decision: "auto", Kinds: []string{"create"}, Paths: []string{"*_test.go"}, MinConfidence: 0.9},
+		{Name: "generated", Decision: "deny", Paths: []string{"gen/**"}},
+		{Name: "source", Decision: "prompt", Changes: []string{"source"}},
+	}
+}
+
+func TestMatchApprovalRule(t *testing.T) {
+	tests := []struct {
+		name  string
+		facts approvalFacts
+		want  string
+	}{
+		{"doc comment edit", approvalFacts{kind: "edit", change: "docs", path: "pkg/a.go", branch: "feature", confidence: 0.95}, "doc-comments"},
+		{"low confidence doc edit", approvalFacts{kind: "edit", change: "docs", path: "pkg/a.go", branch: "feature", confidence: 0.85}, ""},
+		{"new test file", approvalFacts{kind: "create", change: "test", path: "pkg/a_test.go", branch: "feature", confidence: 0.92}, "new-tests"},
+		{"main branch", approvalFacts{kind: "edit", change: "docs", path: "pkg/a.go", branch: "main", confidence: 0.99}, "main-branch"},
+		{"generated file", approvalFacts{kind: "edit", change: "source", path: "gen/api/types.go", branch: "feature", confidence: 0.99}, "generated"},
+		{"source edit", approvalFacts{kind: "edit", change: "source", path: "pkg/a.go", branch: "feature", confidence: 0.99}, "source"},
+	}
+	for _, tt := range tests {
+		// 一致しなければ空のルールが返る
+		rule, _, _ := matchApprovalRule(examplePolicy(), tt.facts)
+		if rule.Name != tt.want {
+			t.Errorf("%s: matched %q, want %q", tt.name, rule.Name, tt.want)
+		}
+	}
+
+	impactRule := []config.ApprovalRule{{Name: "low-impact", Decision: "auto", MaxImpact: "medium"}}
+	if _, _, ok := matchApprovalRule(impactRule, approvalFacts{impact: ImpactLevelHigh}); ok {
+		t.Error("high impact suggestion should not match max_impact medium")
+	}
+	if _, _, ok := matchApprovalRule(impactRule, approvalFacts{impact: ImpactLevelMedium}); !ok {
+		t.Error("medium impact suggestion should match max_impact medium")
+	}
+}
+
+func TestChangesOnlyComments(t *testing.T) {
+	original := "package a\n\nfunc A() int {\n\treturn *p\n}\n"
+	tests := []struct {
+		name      string
+		path      string
+		suggested string
+		want      bool
+	}{
+		{"doc comment", "a.go", "package a\n\n// A は値を返す\nfunc A() int {\n\treturn *p\n}\n", true},
+		{"block comment", "a.go", "package a\n\n/*\n * A は値を返す\n */\nfunc A() int {\n\treturn *p\n}\n", true},
+		{"code change", "a.go", "package a\n\n// A は値を返す\nfunc A() int {\n\treturn *p + 1\n}\n", false},
+		{"unchanged", "a.go", original, false},
+	}
+	for _, tt := range tests {
+		if got := changesOnlyComments(tt.path, original, tt.suggested); got != tt.want {
+			t.Errorf("%s: got %t, want %t", tt.name, got, tt.want)
+		}
+	}
+	if !changesOnlyComments("a.py", "x = 1\n", "# x を設定\nx = 1\n") {
+		t.Error("python comment should be a comment-only change")
+	}
+}
+
+func TestApprovalPolicyBeforeConfirmation(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	original := "package a\n\nfunc A() {}\n"
+	if err := os.WriteFile("a.go", []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	// 作業ディレクトリはgitリポジトリではないためブランチの条件には一致しない
+	cfg.Approval.Rules = examplePolicy()
+	editTool := tools.NewEditTool(security.NewDefaultConstraints("."), ".", 10*1024*1024)
+	manager := NewInteractiveSessionManager(nil, nil, nil, editTool, nil, "test-model", cfg).(*interactiveSessionManager)
+	ctx := context.Background()
+
+	propose := func(path, code string, confidence float64) (*InteractiveSession, *CodeSuggestion, *InteractionResponse) {
+		session, err := manager.CreateSession(CodingSessionTypeGeneral)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suggestion := &CodeSuggestion{ID: "s1", SuggestedCode: code, FilePath: path, Confidence: confidence, Metadata: map[string]string{}}
+		session.PendingSuggestion = suggestion
+		session.State = SessionStateWaitingForConfirmation
+		response := &InteractionResponse{Message: "reply", RequiresConfirmation: true, Metadata: map[string]string{}}
+		manager.applyApprovalToResponse(ctx, session, response, suggestion, manager.evaluateApproval(ctx, suggestion))
+		return session, suggestion, response
+	}
+
+	t.Run("auto-applies a new test file", func(t *testing.T) {
+		session, suggestion, response := propose("a_test.go", "package a\n", 0.95)
+		if !suggestion.Applied || session.PendingSuggestion != nil || response.RequiresConfirmation {
+			t.Fatalf("suggestion was not applied: %+v", response.Metadata)
+		}
+		if data, err := os.ReadFile("a_test.go"); err != nil || string(data) != "package a\n" {
+			t.Errorf("test file was not written: %q, %v", data, err)
+		}
+		if response.Metadata["approval_rule"] != "new-tests" || !strings.Contains(suggestion.Metadata["approval"], "create") {
+			t.Errorf("unexpected approval metadata: %+v / %+v", response.Metadata, suggestion.Metadata)
+		}
+	})
+
+	t.Run("prompts for a source edit", func(t *testing.T) {
+		session, suggestion, response := propose("a.go", "package a\n\nfunc A() { panic(1) }\n", 0.99)
+		if suggestion.Applied || session.PendingSuggestion != suggestion || !response.RequiresConfirmation {
+			t.Fatal("source edit should wait for confirmation")
+		}
+		if response.Metadata["approval"] != "prompt" || response.Metadata["approval_rule"] != "source" {
+			t.Errorf("unexpected approval metadata: %+v", response.Metadata)
+		}
+	})
+
+	t.Run("prompts when confidence is too low", func(t *testing.T) {
+		session, suggestion, _ := propose("b_test.go", "package a\n", 0.85)
+		if suggestion.Applied || session.PendingSuggestion != suggestion {
+			t.Fatal("low confidence suggestion should wait for confirmation")
+		}
+		if _, err := os.Stat("b_test.go"); err == nil {
+			t.Error("low confidence suggestion was written")
+		}
+	})
+
+	t.Run("denies generated files", func(t *testing.T) {
+		session, suggestion, response := propose("gen/types.go", "package gen\n", 0.99)
+		if suggestion.Applied || session.PendingSuggestion != nil || response.RequiresConfirmation {
+			t.Fatal("denied suggestion should be discarded")
+		}
+	})
+
+	t.Run("no rules keeps the confirmation", func(t *testing.T) {
+		rules := cfg.Approval.Rules
+		cfg.Approval.Rules = nil
+		defer func() { cfg.Approval.Rules = rules }()
+		_, suggestion, response := propose("c_test.go", "package a\n", 1.0)
+		if suggestion.Applied || response.Metadata["approval"] != "" {
+			t.Fatalf("suggestion should not be evaluated without rules: %+v", response.Metadata)
+		}
+	})
+}
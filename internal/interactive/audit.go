@@ -0,0 +1,90 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// auditToolSteps は自動実行したツールの各ステップを監査ログに記録
+func auditToolSteps(ctx context.Context, steps []tools.ExecutionStep) {
+	for _, step := range steps {
+		event := logger.AuditEvent{
+			Type:      logger.AuditToolCall,
+			Tool:      step.Tool,
+			LatencyMs: step.EndTime.Sub(step.StartTime).Milliseconds(),
+			Success:   step.Success,
+			Metadata:  make(map[string]string),
+		}
+		for key, value := range step.Parameters {
+			event.Metadata[key] = fmt.Sprintf("%v", value)
+		}
+		if filePath, ok := step.Parameters["file_path"].(string); ok {
+			event.Path = filePath
+		}
+		if command, ok := step.Parameters["command"].(string); ok {
+			event.Command = command
+		}
+		if step.Result != nil {
+			event.Content = step.Result.Content
+			event.Error = step.Result.Error
+		}
+		logger.AuditContext(ctx, event)
+
+		// ファイル変更はタイムラインで追えるよう書き込みイベントとしても残す
+		if step.Success && (step.Tool == "write" || step.Tool == "edit" || step.Tool == "multiedit") && event.Path != "" {
+			auditFileWrite(ctx, event.Path, step.Tool, 0, nil)
+		}
+	}
+}
+
+// auditCommand はコマンド実行結果を監査ログに記録
+func auditCommand(ctx context.Context, command, output string, startTime time.Time, err error) {
+	event := logger.AuditEvent{
+		Type:      logger.AuditCommand,
+		Command:   command,
+		Content:   output,
+		LatencyMs: time.Since(startTime).Milliseconds(),
+		Success:   err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	logger.AuditContext(ctx, event)
+}
+
+// auditFileWrite はファイル書き込みを監査ログに記録
+func auditFileWrite(ctx context.Context, filePath, tool string, size int, err error) {
+	event := logger.AuditEvent{
+		Type:    logger.AuditFileWrite,
+		Tool:    tool,
+		Path:    filePath,
+		Bytes:   size,
+		Success: err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	logger.AuditContext(ctx, event)
+}
+
+// auditTaskResult はビルド・テスト・リントの実行結果を監査ログに記録
+func auditTaskResult(ctx context.Context, result *tasks.Result) {
+	logger.AuditContext(ctx, logger.AuditEvent{
+		Type:      logger.AuditTask,
+		Tool:      string(result.Kind),
+		Command:   result.Command,
+		Content:   tasks.FormatFailures(result),
+		LatencyMs: result.Duration.Milliseconds(),
+		ExitCode:  result.ExitCode,
+		Success:   result.Success,
+		Metadata: map[string]string{
+			"system":   result.System,
+			"failures": fmt.Sprintf("%d", len(result.Failures)),
+		},
+	})
+}
@@ -0,0 +1,95 @@
+package interactive
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/glkt/vyb-code/internal/autosave"
+	"github.com/glkt/vyb-code/internal/branch"
+	"github.com/glkt/vyb-code/internal/history"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// Snapshot はクラッシュ時の復元用にセッションの会話記録と保留中の提案を取り出す
+func (ism *interactiveSessionManager) Snapshot(sessionID string) (*autosave.Snapshot, error) {
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+
+	session, exists := ism.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	snapshot := &autosave.Snapshot{
+		SessionID:  sessionID,
+		ProjectDir: projectDir,
+		PID:        os.Getpid(),
+		Model:      ism.modelName,
+		StartedAt:  session.StartTime,
+		Transcript: transcript.New(sessionID, ism.modelName),
+	}
+	if record, exists := ism.transcripts[sessionID]; exists {
+		snapshot.Transcript = record.Clone()
+	}
+	if tree, exists := ism.branches[sessionID]; exists {
+		snapshot.Branches = tree.Clone()
+		snapshot.Branches.CurrentBranch().Entries = append([]transcript.Entry{}, snapshot.Transcript.Entries...)
+	}
+	if session.PendingSuggestion != nil && !session.PendingSuggestion.Applied {
+		pending, err := json.Marshal(session.PendingSuggestion)
+		if err != nil {
+			return nil, fmt.Errorf("保留中の提案のJSON変換エラー: %w", err)
+		}
+		snapshot.PendingSuggestion = pending
+	}
+	return snapshot, nil
+}
+
+// RestoreSnapshot は中断されたセッションを新しいセッションとして復元する
+// 直近 maxTurns ターンを次の応答のコンテキストに取り込み、会話記録と保留中の提案を引き継ぐ
+func (ism *interactiveSessionManager) RestoreSnapshot(snapshot *autosave.Snapshot, maxTurns int) (*InteractiveSession, error) {
+	var pending *CodeSuggestion
+	if len(snapshot.PendingSuggestion) > 0 {
+		pending = &CodeSuggestion{}
+		if err := json.Unmarshal(snapshot.PendingSuggestion, pending); err != nil {
+			return nil, fmt.Errorf("保留中の提案の読み込みエラー: %w", err)
+		}
+	}
+
+	session, err := ism.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		return nil, err
+	}
+
+	if snapshot.Transcript != nil {
+		exchanges := history.Exchanges(snapshot.Transcript)
+		if maxTurns > 0 && len(exchanges) > maxTurns {
+			exchanges = exchanges[len(exchanges)-maxTurns:]
+		}
+		if err := ism.QuoteHistory(session.ID, exchanges...); err != nil {
+			return nil, err
+		}
+		// /save で中断前の会話も書き出せるよう記録を引き継ぐ
+		ism.appendTranscript(session.ID, snapshot.Transcript.Entries...)
+	}
+	if snapshot.Branches != nil && snapshot.Branches.CurrentBranch() != nil {
+		ism.mu.Lock()
+		if ism.branches == nil {
+			ism.branches = make(map[string]*branch.Tree)
+		}
+		ism.branches[session.ID] = snapshot.Branches.Clone()
+		ism.mu.Unlock()
+	}
+
+	if pending != nil {
+		ism.mu.Lock()
+		session.PendingSuggestion = pending
+		ism.mu.Unlock()
+	}
+	return session, nil
+}
@@ -0,0 +1,225 @@
+package interactive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/branch"
+	"github.com/glkt/vyb-code/internal/history"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// BranchComparison は2つの会話の分岐の比較（共有しているターン以降のやり取り）
+type BranchComparison struct {
+	SharedTurns int        `json:"shared_turns"`
+	Current     BranchSide `json:"current"`
+	Other       BranchSide `json:"other"`
+}
+
+// BranchSide は比較する分岐の独自のやり取り
+type BranchSide struct {
+	Name      string             `json:"name"`
+	Exchanges []history.Exchange `json:"exchanges"`
+}
+
+// Branches はセッションの会話の分岐ツリーのコピーを返す
+func (ism *interactiveSessionManager) Branches(sessionID string) (*branch.Tree, error) {
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+
+	if _, exists := ism.sessions[sessionID]; !exists {
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	return ism.syncBranchLocked(sessionID).Clone(), nil
+}
+
+// ForkBranch は現在の分岐の turn ターン目までを引き継いだ分岐を作って切り替える
+// 以降のターンのコンテキストは外れ、別のアプローチを試せる（ファイルは変更しない）
+func (ism *interactiveSessionManager) ForkBranch(sessionID string, turn int, name string) (*branch.Branch, error) {
+	ism.mu.Lock()
+	if _, exists := ism.sessions[sessionID]; !exists {
+		ism.mu.Unlock()
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	tree := ism.syncBranchLocked(sessionID)
+	from := tree.CurrentBranch()
+	forked, err := tree.Fork(turn, name)
+	if err != nil {
+		ism.mu.Unlock()
+		return nil, err
+	}
+	ism.enterBranchLocked(sessionID, tree)
+	ism.mu.Unlock()
+
+	ism.replaceBranchContext(sessionID, tree, from, forked)
+	logger.Audit(logger.AuditEvent{SessionID: sessionID, Type: logger.AuditBranch, Content: fmt.Sprintf("fork %s from %s at turn %d", forked.Name, from.Name, turn), Success: true})
+	return forked, nil
+}
+
+// SwitchBranch は別の分岐に切り替え、その分岐の会話をコンテキストに戻す
+func (ism *interactiveSessionManager) SwitchBranch(sessionID, name string) (*branch.Branch, error) {
+	ism.mu.Lock()
+	if _, exists := ism.sessions[sessionID]; !exists {
+		ism.mu.Unlock()
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	tree := ism.syncBranchLocked(sessionID)
+	from := tree.CurrentBranch()
+	if from.Name == name {
+		ism.mu.Unlock()
+		return from, nil
+	}
+	target, err := tree.Switch(name)
+	if err != nil {
+		ism.mu.Unlock()
+		return nil, err
+	}
+	ism.enterBranchLocked(sessionID, tree)
+	ism.mu.Unlock()
+
+	ism.replaceBranchContext(sessionID, tree, from, target)
+	logger.Audit(logger.AuditEvent{SessionID: sessionID, Type: logger.AuditBranch, Content: fmt.Sprintf("switch %s -> %s", from.Name, target.Name), Success: true})
+	return target, nil
+}
+
+// CompareBranches は現在の分岐と name の分岐の、分かれた後のやり取りを返す
+func (ism *interactiveSessionManager) CompareBranches(sessionID, name string) (*BranchComparison, error) {
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+
+	if _, exists := ism.sessions[sessionID]; !exists {
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	tree := ism.syncBranchLocked(sessionID)
+	other := tree.Get(name)
+	if other == nil {
+		return nil, fmt.Errorf("分岐が見つかりません: %s", name)
+	}
+	return compareBranches(tree.CurrentBranch(), other), nil
+}
+
+// MergeBranch は name の分岐で得た結論（分かれた後のやり取り）を現在の分岐のコンテキストに取り込む
+func (ism *interactiveSessionManager) MergeBranch(sessionID, name string) (*BranchComparison, error) {
+	ism.mu.Lock()
+	if _, exists := ism.sessions[sessionID]; !exists {
+		ism.mu.Unlock()
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	tree := ism.syncBranchLocked(sessionID)
+	current, other := tree.CurrentBranch(), tree.Get(name)
+	if other == nil {
+		ism.mu.Unlock()
+		return nil, fmt.Errorf("分岐が見つかりません: %s", name)
+	}
+	if other == current {
+		ism.mu.Unlock()
+		return nil, fmt.Errorf("現在の分岐には取り込めません: %s", name)
+	}
+	comparison := compareBranches(current, other)
+	if len(comparison.Other.Exchanges) == 0 {
+		ism.mu.Unlock()
+		return nil, fmt.Errorf("分岐 %s には取り込むやり取りがありません", name)
+	}
+	if !containsString(current.Merged, name) {
+		current.Merged = append(current.Merged, name)
+	}
+	ism.mu.Unlock()
+
+	for _, exchange := range comparison.Other.Exchanges {
+		ism.addToSmartContext(sessionID, branch.Quote(name, exchange), "branch_merge")
+	}
+	logger.Audit(logger.AuditEvent{SessionID: sessionID, Type: logger.AuditBranch, Content: fmt.Sprintf("merge %s into %s", name, current.Name), Success: true})
+	return comparison, nil
+}
+
+// syncBranchLocked は分岐ツリーを返す（未作成なら現在の会話記録を main 分岐として作成）
+// 現在の分岐には最新の会話記録を反映する。ism.mu のロック中に呼ぶ
+func (ism *interactiveSessionManager) syncBranchLocked(sessionID string) *branch.Tree {
+	if ism.branches == nil {
+		ism.branches = make(map[string]*branch.Tree)
+	}
+	var entries []transcript.Entry
+	if record, exists := ism.transcripts[sessionID]; exists {
+		entries = record.Entries
+	}
+	tree, exists := ism.branches[sessionID]
+	if !exists {
+		tree = branch.NewTree(entries)
+		ism.branches[sessionID] = tree
+		return tree
+	}
+	tree.CurrentBranch().Entries = append([]transcript.Entry{}, entries...)
+	return tree
+}
+
+// enterBranchLocked は会話記録を現在の分岐のものに置き換える。ism.mu のロック中に呼ぶ
+func (ism *interactiveSessionManager) enterBranchLocked(sessionID string, tree *branch.Tree) {
+	record := transcript.New(sessionID, ism.modelName)
+	if previous, exists := ism.transcripts[sessionID]; exists {
+		record.CreatedAt = previous.CreatedAt
+	}
+	record.Entries = append([]transcript.Entry{}, tree.CurrentBranch().Entries...)
+	if ism.transcripts == nil {
+		ism.transcripts = make(map[string]*transcript.Transcript)
+	}
+	ism.transcripts[sessionID] = record
+
+	if session, exists := ism.sessions[sessionID]; exists {
+		session.PendingSuggestion = nil
+		session.LastActivity = time.Now()
+	}
+}
+
+// replaceBranchContext は from の分岐で追加したコンテキストを外し、to の分岐で分かれた後のやり取りと
+// 取り込んだ結論をコンテキストに戻す
+func (ism *interactiveSessionManager) replaceBranchContext(sessionID string, tree *branch.Tree, from, to *branch.Branch) {
+	ism.mu.Lock()
+	if ism.branchEnteredAt == nil {
+		ism.branchEnteredAt = make(map[string]time.Time)
+	}
+	since := ism.branchEnteredAt[sessionID]
+	ism.branchEnteredAt[sessionID] = time.Now()
+	shared := branch.SharedLength(from, to)
+	if shared < len(from.Entries) && (since.IsZero() || from.Entries[shared].Timestamp.Before(since)) {
+		since = from.Entries[shared].Timestamp
+	}
+	var merged []*branch.Branch
+	for _, name := range to.Merged {
+		if b := tree.Get(name); b != nil {
+			merged = append(merged, b)
+		}
+	}
+	ism.mu.Unlock()
+
+	if ism.contextManager != nil && !since.IsZero() {
+		ism.contextManager.RemoveSince(since)
+	}
+	for _, exchange := range branch.Exchanges(to, branch.SharedTurns(from, to)) {
+		ism.addToSmartContext(sessionID, branch.Quote(to.Name, exchange), "branch")
+	}
+	for _, b := range merged {
+		for _, exchange := range compareBranches(to, b).Other.Exchanges {
+			ism.addToSmartContext(sessionID, branch.Quote(b.Name, exchange), "branch_merge")
+		}
+	}
+}
+
+// compareBranches は2つの分岐の分かれた後のやり取りを取り出す
+func compareBranches(current, other *branch.Branch) *BranchComparison {
+	shared := branch.SharedTurns(current, other)
+	return &BranchComparison{
+		SharedTurns: shared,
+		Current:     BranchSide{Name: current.Name, Exchanges: branch.Exchanges(current, shared)},
+		Other:       BranchSide{Name: other.Name, Exchanges: branch.Exchanges(other, shared)},
+	}
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package interactive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// addTurn はユーザー入力・ターン中のコンテキスト・応答を記録する
+func addTurn(manager *interactiveSessionManager, sessionID, input, answer string) {
+	manager.appendTranscript(sessionID, transcript.Entry{Kind: transcript.KindUser, Content: input, Success: true})
+	manager.addToSmartContext(sessionID, "context of "+input, "tool_result")
+	manager.appendTranscript(sessionID, transcript.Entry{Kind: transcript.KindAssistant, Content: answer, Success: true})
+}
+
+func contextContents(contextManager contextmanager.ContextManager) string {
+	var contents []string
+	for _, item := range contextManager.Items() {
+		contents = append(contents, item.Content)
+	}
+	return strings.Join(contents, "\n")
+}
+
+// TestBranches は分岐で会話記録とコンテキストが切り替わることをテストする
+func TestBranches(t *testing.T) {
+	contextManager := contextmanager.NewSmartContextManager()
+	manager := NewInteractiveSessionManager(contextManager, nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addTurn(manager, session.ID, "use a mutex", "added a mutex")
+	addTurn(manager, session.ID, "benchmark it", "mutex is slow")
+
+	// ターン1の後から分岐すると、ターン2は会話記録とコンテキストから外れる
+	forked, err := manager.ForkBranch(session.ID, 1, "channels")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if forked.Turns() != 1 {
+		t.Errorf("forked turns = %d", forked.Turns())
+	}
+	if record, _ := manager.Transcript(session.ID); record.Turns() != 1 {
+		t.Errorf("transcript turns = %d", record.Turns())
+	}
+	if contents := contextContents(contextManager); strings.Contains(contents, "benchmark it") || !strings.Contains(contents, "use a mutex") {
+		t.Errorf("context = %q", contents)
+	}
+
+	addTurn(manager, session.ID, "use channels instead", "channels are faster")
+
+	// 比較では共有ターン以降のやり取りを返す
+	comparison, err := manager.CompareBranches(session.ID, "main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if comparison.SharedTurns != 1 || len(comparison.Current.Exchanges) != 1 || len(comparison.Other.Exchanges) != 1 ||
+		comparison.Other.Exchanges[0].Assistant != "mutex is slow" {
+		t.Errorf("comparison = %+v", comparison)
+	}
+
+	// main に戻ると main のターン2がコンテキストに戻り、分岐のターンは外れる
+	if _, err := manager.SwitchBranch(session.ID, "main"); err != nil {
+		t.Fatal(err)
+	}
+	if record, _ := manager.Transcript(session.ID); record.Turns() != 2 {
+		t.Errorf("main transcript turns = %d", record.Turns())
+	}
+	contents := contextContents(contextManager)
+	if strings.Contains(contents, "use channels instead") || !strings.Contains(contents, "mutex is slow") {
+		t.Errorf("context after switch = %q", contents)
+	}
+
+	// 分岐の結論を取り込む
+	if _, err := manager.MergeBranch(session.ID, "channels"); err != nil {
+		t.Fatal(err)
+	}
+	if contents := contextContents(contextManager); !strings.Contains(contents, "会話の分岐 channels のターン 2") {
+		t.Errorf("merged context = %q", contents)
+	}
+
+	tree, err := manager.Branches(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree.Current != "main" || len(tree.Branches) != 2 || tree.Get("main").Merged[0] != "channels" {
+		t.Errorf("tree = %+v", tree)
+	}
+
+	// 自動保存に分岐ツリーが含まれ、復元で引き継がれる
+	snapshot, err := manager.Snapshot(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	restored, err := manager.RestoreSnapshot(snapshot, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tree, _ := manager.Branches(restored.ID); len(tree.Branches) != 2 || tree.Get("channels").Turns() != 2 {
+		t.Errorf("restored tree = %+v", tree)
+	}
+}
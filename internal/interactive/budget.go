@@ -0,0 +1,43 @@
+package interactive
+
+import (
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// turnLimits は1ターンの資源上限（設定がなければデフォルト）
+func (ism *interactiveSessionManager) turnLimits() budget.Limits {
+	if ism.config == nil {
+		return budget.LimitsFromConfig(config.DefaultTurnLimitsConfig())
+	}
+	return budget.LimitsFromConfig(ism.config.TurnLimits)
+}
+
+// budgetExceededResponse は上限で打ち切ったため応答を生成できなかったターンの応答
+func budgetExceededResponse(sessionID string) *InteractionResponse {
+	return &InteractionResponse{
+		SessionID:    sessionID,
+		ResponseType: ResponseTypeMessage,
+		GeneratedAt:  time.Now(),
+	}
+}
+
+// appendBudgetSummary は上限を超えたターンの応答に、それまでに行った操作の要約を追記
+func appendBudgetSummary(response *InteractionResponse, tracker *budget.Tracker) {
+	exceeded := tracker.Exceeded()
+	if exceeded == nil {
+		return
+	}
+	message := strings.TrimRight(response.Message, "\n")
+	if message != "" {
+		message += "\n\n"
+	}
+	response.Message = message + tracker.Summary()
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]string)
+	}
+	response.Metadata["budget_exceeded"] = string(exceeded.Kind)
+}
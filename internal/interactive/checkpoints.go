@@ -0,0 +1,259 @@
+package interactive
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/checkpoint"
+)
+
+// 起動時に削除する古いチェックポイントの経過時間
+const checkpointMaxAge = 7 * 24 * time.Hour
+
+// sessionState はチェックポイント時点の会話状態（巻き戻しで復元）
+type sessionState struct {
+	userIntent        string
+	lastCommandOutput string
+	metadata          map[string]string
+	metrics           SessionMetrics
+	modifiedFiles     []string
+}
+
+// turnCheckpoint はターン開始前のワークスペースと会話状態
+type turnCheckpoint struct {
+	checkpoint.Checkpoint
+	state sessionState
+}
+
+// sessionCheckpoints はセッション毎のターン数と保持中のチェックポイント（古い順）
+type sessionCheckpoints struct {
+	turns       int
+	checkpoints []*turnCheckpoint
+}
+
+// RewindResult は巻き戻しの結果
+type RewindResult struct {
+	Checkpoint          checkpoint.Checkpoint     `json:"checkpoint"`
+	Files               *checkpoint.RestoreResult `json:"files"`
+	TurnsUndone         int                       `json:"turns_undone"`          // 取り消した会話ターン数
+	ContextItemsRemoved int                       `json:"context_items_removed"` // 削除したコンテキスト項目数
+}
+
+// newCheckpointStore は設定で有効な場合にカレントディレクトリのチェックポイントストアを作成
+// gitリポジトリ外では無効（nil）
+func newCheckpointStore(enabled bool) *checkpoint.Store {
+	if !enabled {
+		return nil
+	}
+	store, err := checkpoint.NewStore(".")
+	if err != nil {
+		return nil
+	}
+	store.Prune(checkpointMaxAge)
+	return store
+}
+
+// beginTurn はターン番号を進め、ワークスペースと会話状態を記録する
+// スナップショットに失敗してもターンの処理は継続する
+func (ism *interactiveSessionManager) beginTurn(sessionID, input string) *turnCheckpoint {
+	ism.mu.Lock()
+	tracker, exists := ism.checkpoints[sessionID]
+	if !exists {
+		tracker = &sessionCheckpoints{}
+		ism.checkpoints[sessionID] = tracker
+	}
+	tracker.turns++
+	turn := tracker.turns
+	session := ism.sessions[sessionID]
+	ism.mu.Unlock()
+
+	if ism.checkpointStore == nil || session == nil {
+		return nil
+	}
+
+	createdAt := time.Now()
+	snapshot, err := ism.checkpointStore.Snapshot(fmt.Sprintf("vyb checkpoint: %s turn %d", sessionID, turn))
+	if err != nil {
+		return nil
+	}
+
+	return &turnCheckpoint{
+		Checkpoint: checkpoint.Checkpoint{
+			ID:        fmt.Sprintf("turn-%d", turn),
+			SessionID: sessionID,
+			Turn:      turn,
+			Input:     input,
+			Snapshot:  snapshot,
+			CreatedAt: createdAt,
+		},
+		state: ism.captureSessionState(session),
+	}
+}
+
+// finishTurn はターン中にワークスペースが変更された場合のみチェックポイントを保持
+func (ism *interactiveSessionManager) finishTurn(pending *turnCheckpoint) {
+	if pending == nil {
+		return
+	}
+
+	after, err := ism.checkpointStore.Snapshot(fmt.Sprintf("vyb checkpoint: %s turn %d (after)", pending.SessionID, pending.Turn))
+	if err != nil || after.Tree == pending.Snapshot.Tree {
+		return
+	}
+	files, err := ism.checkpointStore.ChangedFiles(pending.Snapshot, after)
+	if err != nil {
+		return
+	}
+	pending.Files = files
+	if patch, err := ism.checkpointStore.Diff(pending.Snapshot, after); err == nil {
+		ism.recordTurnDiff(pending.SessionID, patch)
+	}
+	if err := ism.checkpointStore.Keep(&pending.Checkpoint); err != nil {
+		return
+	}
+
+	limit := 0
+	if ism.config != nil {
+		limit = ism.config.Checkpoints.MaxPerSession
+	}
+
+	ism.mu.Lock()
+	tracker := ism.checkpoints[pending.SessionID]
+	tracker.checkpoints = append(tracker.checkpoints, pending)
+	var dropped []*turnCheckpoint
+	if limit > 0 && len(tracker.checkpoints) > limit {
+		excess := len(tracker.checkpoints) - limit
+		dropped = tracker.checkpoints[:excess]
+		tracker.checkpoints = append([]*turnCheckpoint{}, tracker.checkpoints[excess:]...)
+	}
+	ism.mu.Unlock()
+
+	for _, cp := range dropped {
+		ism.checkpointStore.Drop(&cp.Checkpoint)
+	}
+}
+
+// captureSessionState は巻き戻し用に会話状態をコピー
+func (ism *interactiveSessionManager) captureSessionState(session *InteractiveSession) sessionState {
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+
+	state := sessionState{
+		userIntent:        session.UserIntent,
+		lastCommandOutput: session.LastCommandOutput,
+		metadata:          make(map[string]string, len(session.SessionMetadata)),
+		modifiedFiles:     append([]string{}, ism.modifiedFiles[session.ID]...),
+	}
+	for key, value := range session.SessionMetadata {
+		state.metadata[key] = value
+	}
+	if session.Metrics != nil {
+		state.metrics = *session.Metrics
+	}
+	return state
+}
+
+// CheckpointsEnabled はワークスペースのチェックポイントが利用可能かを返す
+func (ism *interactiveSessionManager) CheckpointsEnabled() bool {
+	return ism.checkpointStore != nil
+}
+
+// Checkpoints はセッションで保持しているチェックポイントを古い順に返す
+func (ism *interactiveSessionManager) Checkpoints(sessionID string) []checkpoint.Checkpoint {
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+
+	tracker, exists := ism.checkpoints[sessionID]
+	if !exists {
+		return nil
+	}
+	result := make([]checkpoint.Checkpoint, 0, len(tracker.checkpoints))
+	for _, cp := range tracker.checkpoints {
+		result = append(result, cp.Checkpoint)
+	}
+	return result
+}
+
+// Rewind は指定ターンの開始前にファイルと会話を巻き戻す
+// そのターン以降のチェックポイントは破棄される
+func (ism *interactiveSessionManager) Rewind(sessionID string, turn int) (*RewindResult, error) {
+	if ism.checkpointStore == nil {
+		return nil, fmt.Errorf("チェックポイントが無効です（gitリポジトリ外、または設定で無効）")
+	}
+	session, err := ism.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	ism.mu.RLock()
+	tracker := ism.checkpoints[sessionID]
+	var target *turnCheckpoint
+	if tracker != nil {
+		for _, cp := range tracker.checkpoints {
+			if cp.Turn == turn {
+				target = cp
+				break
+			}
+		}
+	}
+	ism.mu.RUnlock()
+	if target == nil {
+		return nil, fmt.Errorf("ターン %d のチェックポイントが見つかりません", turn)
+	}
+
+	files, err := ism.checkpointStore.Restore(target.Snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &RewindResult{Checkpoint: target.Checkpoint, Files: files}
+
+	ism.mu.Lock()
+	session.UserIntent = target.state.userIntent
+	session.LastCommandOutput = target.state.lastCommandOutput
+	session.SessionMetadata = target.state.metadata
+	if session.Metrics != nil {
+		*session.Metrics = target.state.metrics
+	}
+	session.PendingSuggestion = nil
+	session.LastActivity = time.Now()
+	ism.modifiedFiles[sessionID] = target.state.modifiedFiles
+
+	result.TurnsUndone = tracker.turns - turn + 1
+	tracker.turns = turn - 1
+	var dropped []*turnCheckpoint
+	kept := make([]*turnCheckpoint, 0, len(tracker.checkpoints))
+	for _, cp := range tracker.checkpoints {
+		if cp.Turn >= turn {
+			dropped = append(dropped, cp)
+		} else {
+			kept = append(kept, cp)
+		}
+	}
+	tracker.checkpoints = kept
+	ism.mu.Unlock()
+
+	// 巻き戻したターン以降に追加されたコンテキストも取り除く
+	if ism.contextManager != nil {
+		result.ContextItemsRemoved = ism.contextManager.RemoveSince(target.CreatedAt)
+	}
+	for _, cp := range dropped {
+		ism.checkpointStore.Drop(&cp.Checkpoint)
+	}
+	return result, nil
+}
+
+// discardCheckpoints はセッションのチェックポイントをすべて破棄
+func (ism *interactiveSessionManager) discardCheckpoints(sessionID string) {
+	ism.mu.Lock()
+	tracker := ism.checkpoints[sessionID]
+	delete(ism.checkpoints, sessionID)
+	ism.mu.Unlock()
+
+	if tracker == nil || ism.checkpointStore == nil {
+		return
+	}
+	for _, cp := range tracker.checkpoints {
+		ism.checkpointStore.Drop(&cp.Checkpoint)
+	}
+}
@@ -0,0 +1,247 @@
+package interactive
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// 提案の作成時に記録する対象ファイルの状態（CodeSuggestion.Metadata のキー）
+const (
+	metaBaseHash = "base_hash"     // 作成時の内容の tools.ContentHash（ファイルがなければ baseAbsent）
+	metaConflict = "edit_conflict" // 適用時に変更を検出した（"true" の間は再生成・強制・中止を選ぶ）
+	baseAbsent   = "absent"
+)
+
+// maxRegenerateContextBytes は再生成の依頼に含める現在の内容の上限
+const maxRegenerateContextBytes = 32 * 1024
+
+// EditConflict は提案の作成後に対象ファイルが変更されていたため、適用を止めたエラー
+type EditConflict struct {
+	FilePath  string
+	Base      string // 提案の作成時の内容（BaseKnown が false なら不明）
+	BaseKnown bool
+	Proposed  string // 提案を作成時の内容に適用した結果（求められなければ空）
+	Current   string // 現在の内容
+	Exists    bool   // 現在ファイルがあるか（false なら削除された）
+}
+
+func (c *EditConflict) Error() string {
+	return fmt.Sprintf("%s changed on disk since the suggestion was generated", c.FilePath)
+}
+
+// Comparison は作成時の内容・提案・現在の内容の3者比較（作成時→提案、作成時→現在の差分）
+// 作成時の内容が分からない場合（セッションの復元後等）は現在→提案の差分だけを示す
+func (c *EditConflict) Comparison() string {
+	var b strings.Builder
+	current := c.Current
+	if !c.Exists {
+		current = ""
+	}
+	if !c.BaseKnown {
+		fmt.Fprintf(&b, "%s\n%s", i18n.T("conflict.current_vs_suggestion"), diff.Unified("current/"+c.FilePath, "suggestion/"+c.FilePath, current, c.Proposed, 3))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s\n%s\n", i18n.T("conflict.model_edit"), diff.Unified("base/"+c.FilePath, "suggestion/"+c.FilePath, c.Base, c.Proposed, 3))
+	if !c.Exists {
+		fmt.Fprintf(&b, "%s\n", i18n.T("conflict.deleted"))
+		return b.String()
+	}
+	fmt.Fprintf(&b, "%s\n%s", i18n.T("conflict.concurrent_change"), diff.Unified("base/"+c.FilePath, "current/"+c.FilePath, c.Base, current, 3))
+	return b.String()
+}
+
+// snapshotSuggestionBase は提案の作成時に対象ファイルの内容を記録し、適用時に変更を検出できるようにする
+// コマンドの提案・対象が特定できない提案・リモート実行では記録しない
+func (ism *interactiveSessionManager) snapshotSuggestionBase(suggestion *CodeSuggestion) {
+	if suggestion == nil || suggestion.FilePath == "" || ism.remote != nil || ism.isCommandSuggestion(suggestion.SuggestedCode) {
+		return
+	}
+	content, err := os.ReadFile(suggestion.FilePath)
+	if err != nil && !os.IsNotExist(err) {
+		return
+	}
+	if suggestion.Metadata == nil {
+		suggestion.Metadata = make(map[string]string)
+	}
+	if err != nil {
+		suggestion.Metadata[metaBaseHash] = baseAbsent
+		return
+	}
+	suggestion.base = string(content)
+	suggestion.Metadata[metaBaseHash] = tools.ContentHash(suggestion.base)
+}
+
+// expectedHash は EditTool に渡す作成時の内容のハッシュ（記録がなければ空）
+func expectedHash(suggestion *CodeSuggestion) string {
+	if hash := suggestion.Metadata[metaBaseHash]; hash != baseAbsent {
+		return hash
+	}
+	return ""
+}
+
+// checkSuggestionConflict は提案の作成後に filePath が変更・作成・削除されていれば3者比較の材料を返す
+func (ism *interactiveSessionManager) checkSuggestionConflict(filePath string, suggestion *CodeSuggestion) *EditConflict {
+	hash, recorded := suggestion.Metadata[metaBaseHash]
+	if !recorded {
+		return nil
+	}
+	data, err := os.ReadFile(filePath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil // 読めない場合は適用するツールのエラーに任せる
+	}
+	exists := err == nil
+	current := string(data)
+	if (hash == baseAbsent && !exists) || (exists && tools.ContentHash(current) == hash) {
+		return nil
+	}
+
+	conflict := &EditConflict{FilePath: filePath, Current: current, Exists: exists}
+	switch {
+	case hash == baseAbsent:
+		// 新規作成の提案の後に同じファイルが作られた
+		conflict.BaseKnown = true
+		conflict.Proposed = suggestion.SuggestedCode
+	case suggestion.base != "" || tools.ContentHash("") == hash:
+		conflict.Base, conflict.BaseKnown = suggestion.base, true
+		conflict.Proposed = applySuggestionTo(suggestion, suggestion.base)
+	default:
+		conflict.Proposed = applySuggestionTo(suggestion, current)
+	}
+	return conflict
+}
+
+// applySuggestionTo は content に提案を適用した結果（置換元が一意に見つからなければ空）
+func applySuggestionTo(suggestion *CodeSuggestion, content string) string {
+	if suggestion.OriginalCode == "" {
+		return suggestion.SuggestedCode
+	}
+	if strings.Count(content, suggestion.OriginalCode) != 1 {
+		return ""
+	}
+	return strings.Replace(content, suggestion.OriginalCode, suggestion.SuggestedCode, 1)
+}
+
+// staleEditConflict は EditTool が書き込み直前に変更を検出したエラーを3者比較の材料に変換する（該当しなければnil）
+func (ism *interactiveSessionManager) staleEditConflict(err error, filePath string, suggestion *CodeSuggestion) *EditConflict {
+	var stale *tools.StaleFileError
+	if !errors.As(err, &stale) {
+		return nil
+	}
+	if conflict := ism.checkSuggestionConflict(filePath, suggestion); conflict != nil {
+		return conflict
+	}
+	return &EditConflict{FilePath: filePath, Current: stale.Current, Exists: true, Proposed: applySuggestionTo(suggestion, stale.Current)}
+}
+
+// conflictResponse は3者比較を示し、再生成・強制適用・中止を選ばせる応答
+func conflictResponse(session *InteractiveSession, conflict *EditConflict) *InteractionResponse {
+	suggestion := session.PendingSuggestion
+	suggestion.Metadata[metaConflict] = "true"
+	session.State = SessionStateWaitingForConfirmation
+	return &InteractionResponse{
+		SessionID:            session.ID,
+		ResponseType:         ResponseTypeConfirmation,
+		Message:              fmt.Sprintf("%s\n\n%s\n%s", i18n.T("conflict.detected", conflict.FilePath), conflict.Comparison(), i18n.T("conflict.options")),
+		RequiresConfirmation: true,
+		Metadata: map[string]string{
+			"action":        "edit_conflict",
+			"suggestion_id": suggestion.ID,
+			"file_path":     conflict.FilePath,
+		},
+		GeneratedAt: time.Now(),
+	}
+}
+
+// handleConflictChoice は変更の衝突で止めた提案への回答（r: 再生成、f: 強制適用、a: 中止）を処理する
+// それ以外の入力なら handled が false を返し、通常の入力として扱う（提案は保留のまま）
+func (ism *interactiveSessionManager) handleConflictChoice(ctx context.Context, session *InteractiveSession, answer string) (response *InteractionResponse, handled bool, err error) {
+	suggestion := session.PendingSuggestion
+	switch answer {
+	case "r", "regenerate", "再生成":
+		return ism.regenerateSuggestion(ctx, session)
+	case "f", "force", "強制":
+		response, err := ism.forceSuggestion(ctx, session)
+		return response, true, err
+	case "a", "abort", "n", "no", "中止":
+		if err := ism.ConfirmSuggestion(session.ID, suggestion.ID, false); err != nil {
+			return nil, true, err
+		}
+		return &InteractionResponse{
+			SessionID:    session.ID,
+			ResponseType: ResponseTypeMessage,
+			Message:      i18n.T("conflict.aborted", suggestion.FilePath),
+			Metadata:     map[string]string{"action": "edit_conflict_aborted", "suggestion_id": suggestion.ID},
+			GeneratedAt:  time.Now(),
+		}, true, nil
+	}
+	return nil, false, nil
+}
+
+// forceSuggestion は変更を承知で提案を適用する
+// 置換元が現在の内容にも一意にあれば現在の内容に適用し（並行した変更を残す）、なければ作成時の内容に適用した結果で上書きする
+func (ism *interactiveSessionManager) forceSuggestion(ctx context.Context, session *InteractiveSession) (*InteractionResponse, error) {
+	suggestion := session.PendingSuggestion
+	filePath := suggestion.FilePath
+	conflict := ism.checkSuggestionConflict(filePath, suggestion)
+	if conflict != nil {
+		if suggestion.OriginalCode != "" && strings.Count(conflict.Current, suggestion.OriginalCode) != 1 {
+			if conflict.Proposed == "" || !conflict.BaseKnown {
+				return nil, fmt.Errorf("%s", i18n.T("conflict.force_unavailable", filePath))
+			}
+			suggestion.OriginalCode, suggestion.SuggestedCode = "", conflict.Proposed
+		}
+		suggestion.Metadata[metaBaseHash] = baseAbsent
+		if conflict.Exists {
+			suggestion.Metadata[metaBaseHash] = tools.ContentHash(conflict.Current)
+		}
+		suggestion.base = conflict.Current
+	}
+	delete(suggestion.Metadata, metaConflict)
+
+	suggestion.UserConfirmed = true
+	if err := ism.ApplySuggestion(ctx, session.ID, suggestion.ID); err != nil {
+		var again *EditConflict
+		if errors.As(err, &again) {
+			return conflictResponse(session, again), nil
+		}
+		return nil, fmt.Errorf("提案適用エラー: %w", err)
+	}
+	return &InteractionResponse{
+		SessionID:    session.ID,
+		ResponseType: ResponseTypeCompletion,
+		Message:      i18n.T("conflict.forced", filePath),
+		Metadata:     map[string]string{"action": "suggestion_applied", "suggestion_id": suggestion.ID, "file_path": filePath},
+		GeneratedAt:  time.Now(),
+	}, nil
+}
+
+// regenerateSuggestion は提案を破棄し、現在の内容を添えて元の依頼から提案を作り直させる
+func (ism *interactiveSessionManager) regenerateSuggestion(ctx context.Context, session *InteractiveSession) (*InteractionResponse, bool, error) {
+	suggestion := session.PendingSuggestion
+	request := suggestion.Metadata["original_input"]
+	if request == "" {
+		return nil, false, nil // 元の依頼が分からなければ通常の入力として扱う
+	}
+	session.PendingSuggestion = nil
+	session.State = SessionStateIdle
+
+	current := ""
+	if data, err := os.ReadFile(suggestion.FilePath); err == nil {
+		current = string(data)
+		if len(current) > maxRegenerateContextBytes {
+			current = current[:maxRegenerateContextBytes] + "\n... (truncated)"
+		}
+	}
+	prompt := fmt.Sprintf("%s\n\nNote: %s changed on disk after your previous suggestion, so it was not applied. "+
+		"Base the new suggestion on its current content:\n```\n%s\n```", request, suggestion.FilePath, current)
+	response, err := ism.processUserInputFallback(ctx, session.ID, prompt)
+	return response, true, err
+}
@@ -0,0 +1,105 @@
+package interactive
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func TestApplySuggestionDetectsConcurrentChange(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	editTool := tools.NewEditTool(security.NewDefaultConstraints("."), ".", 10*1024*1024)
+	manager := NewInteractiveSessionManager(nil, nil, nil, editTool, nil, "test-model", config.DefaultConfig()).(*interactiveSessionManager)
+	ctx := context.Background()
+
+	base := "package a\n\nfunc A() {}\n"
+	propose := func() *InteractiveSession {
+		if err := os.WriteFile("a.go", []byte(base), 0644); err != nil {
+			t.Fatal(err)
+		}
+		session, err := manager.CreateSession(CodingSessionTypeGeneral)
+		if err != nil {
+			t.Fatal(err)
+		}
+		suggestion := &CodeSuggestion{ID: "s1", FilePath: "a.go", OriginalCode: "func A() {}", SuggestedCode: "func A() { println(1) }",
+			Metadata: map[string]string{"original_input": "make A print"}}
+		manager.snapshotSuggestionBase(suggestion)
+		session.PendingSuggestion = suggestion
+		session.State = SessionStateWaitingForConfirmation
+		// 確認を待つ間にエディタで別の関数が追加された
+		if err := os.WriteFile("a.go", []byte(base+"\nfunc B() {}\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return session
+	}
+
+	t.Run("shows a three-way comparison instead of applying", func(t *testing.T) {
+		session := propose()
+		response, err := manager.processUserInputFallback(ctx, session.ID, "y")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if response.Metadata["action"] != "edit_conflict" || !response.RequiresConfirmation || session.PendingSuggestion == nil {
+			t.Fatalf("expected a conflict prompt, got %+v", response)
+		}
+		for _, want := range []string{"+func A() { println(1) }", "+func B() {}", "--- base/a.go", "+++ current/a.go"} {
+			if !strings.Contains(response.Message, want) {
+				t.Errorf("comparison is missing %q:\n%s", want, response.Message)
+			}
+		}
+		if data, _ := os.ReadFile("a.go"); string(data) != base+"\nfunc B() {}\n" {
+			t.Errorf("the file was modified: %q", data)
+		}
+
+		// 他の入力では提案を保留のまま通常どおり扱う
+		if _, handled, _ := manager.handleConflictChoice(ctx, session, "what changed?"); handled {
+			t.Error("unrelated input should not resolve the conflict")
+		}
+		response, err = manager.processUserInputFallback(ctx, session.ID, "a")
+		if err != nil || response.Metadata["action"] != "edit_conflict_aborted" || session.PendingSuggestion != nil {
+			t.Fatalf("abort did not discard the suggestion: %+v, %v", response, err)
+		}
+	})
+
+	t.Run("force applies onto the current content", func(t *testing.T) {
+		session := propose()
+		if _, err := manager.processUserInputFallback(ctx, session.ID, "y"); err != nil {
+			t.Fatal(err)
+		}
+		response, err := manager.processUserInputFallback(ctx, session.ID, "f")
+		if err != nil || response.Metadata["action"] != "suggestion_applied" {
+			t.Fatalf("force failed: %+v, %v", response, err)
+		}
+		want := "package a\n\nfunc A() { println(1) }\n\nfunc B() {}\n"
+		if data, _ := os.ReadFile("a.go"); string(data) != want {
+			t.Errorf("force should keep the concurrent change:\n%s", data)
+		}
+	})
+
+	t.Run("unchanged files apply normally", func(t *testing.T) {
+		session := propose()
+		if err := os.WriteFile("a.go", []byte(base), 0644); err != nil {
+			t.Fatal(err)
+		}
+		session.PendingSuggestion.UserConfirmed = true
+		if err := manager.ApplySuggestion(ctx, session.ID, "s1"); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if data, _ := os.ReadFile("a.go"); !strings.Contains(string(data), "println(1)") {
+			t.Errorf("suggestion was not applied: %q", data)
+		}
+	})
+}
@@ -0,0 +1,193 @@
+package interactive
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/usage"
+)
+
+// プロンプトを構成する要素
+const (
+	ContextSegmentSystem     = "system"      // システムプロンプト（PromptAdapterが付与）
+	ContextSegmentTemplate   = "template"    // 応答テンプレートの固定部分（指示・タグ一覧）
+	ContextSegmentMemory     = "memory"      // 長期コンテキスト（開発パターン・設定）
+	ContextSegmentContext    = "context"     // 取得したコンテキスト項目
+	ContextSegmentHistory    = "history"     // セッション履歴の要約
+	ContextSegmentLastOutput = "last_output" // 直前のコマンド出力
+)
+
+// デフォルトのコンテキスト長（設定がない場合）
+const defaultContextWindow = 32768
+
+// ContextSegment はプロンプト要素毎のトークン数
+type ContextSegment struct {
+	Name   string `json:"name"`
+	Tokens int    `json:"tokens"`
+	Items  int    `json:"items,omitempty"`
+}
+
+// ContextItemUsage はコンテキスト項目毎のトークン数とスコア
+type ContextItemUsage struct {
+	ID          string  `json:"id"`
+	Tier        string  `json:"tier"`
+	ContentType string  `json:"content_type,omitempty"`
+	Importance  float64 `json:"importance"`
+	Relevance   float64 `json:"relevance"`
+	Tokens      int     `json:"tokens"`
+	Preview     string  `json:"preview"`
+	Pinned      bool    `json:"pinned,omitempty"` // 圧縮・退避で除かない（/pin-context）
+}
+
+// ContextUsage は次のリクエストでプロンプトを占める内容の内訳
+type ContextUsage struct {
+	Model          string             `json:"model"`
+	ContextWindow  int                `json:"context_window"`
+	ReservedOutput int                `json:"reserved_output"` // 応答生成用に確保するトークン
+	Segments       []ContextSegment   `json:"segments"`
+	Items          []ContextItemUsage `json:"items"`
+}
+
+// UsedTokens はプロンプト全体のトークン数
+func (cu *ContextUsage) UsedTokens() int {
+	total := 0
+	for _, segment := range cu.Segments {
+		total += segment.Tokens
+	}
+	return total
+}
+
+// RemainingTokens は応答用の確保分を除いた残り予算
+func (cu *ContextUsage) RemainingTokens() int {
+	remaining := cu.ContextWindow - cu.ReservedOutput - cu.UsedTokens()
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+// ContextUsage はセッションの現在のプロンプト構成をトークン数で集計
+func (ism *interactiveSessionManager) ContextUsage(sessionID string) (*ContextUsage, error) {
+	session, err := ism.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ContextUsage{
+		Model:         ism.getConfiguredModel(),
+		ContextWindow: defaultContextWindow,
+	}
+	systemTokens := 0
+	if ism.config != nil {
+		if ism.config.ContextWindow > 0 {
+			result.ContextWindow = ism.config.ContextWindow
+		}
+		result.ReservedOutput = ism.config.MaxTokens
+		systemTokens = usage.EstimateTokens(ism.config.GenerateSystemPrompt())
+	}
+
+	// 入力・コンテキストを空にして描画した分がテンプレートの固定部分（固定したコンテキスト項目は項目として数える）
+	data := ism.interactivePromptData(session, session.UserIntent)
+	data.PinnedContext = nil
+	templateTokens := usage.EstimateTokens(ism.renderInteractivePrompt(data))
+
+	memory := ContextSegment{Name: ContextSegmentMemory}
+	retrieved := ContextSegment{Name: ContextSegmentContext}
+	if ism.contextManager != nil {
+		for _, item := range ism.contextManager.Items() {
+			tokens := usage.EstimateTokens(item.Content)
+			if item.Type == contextmanager.ContextTypeLongTerm {
+				memory.Tokens += tokens
+				memory.Items++
+			} else {
+				retrieved.Tokens += tokens
+				retrieved.Items++
+			}
+			result.Items = append(result.Items, ContextItemUsage{
+				ID:          item.ID,
+				Tier:        contextTierName(item.Type),
+				ContentType: item.Metadata["content_type"],
+				Importance:  item.Importance,
+				Relevance:   item.Relevance,
+				Tokens:      tokens,
+				Preview:     contextPreview(item.Content, 60),
+				Pinned:      item.Pinned,
+			})
+		}
+		// 固定した項目は一覧の先頭に示す
+		sort.SliceStable(result.Items, func(i, j int) bool {
+			return result.Items[i].Pinned && !result.Items[j].Pinned
+		})
+	}
+
+	result.Segments = []ContextSegment{
+		{Name: ContextSegmentSystem, Tokens: systemTokens},
+		{Name: ContextSegmentTemplate, Tokens: templateTokens},
+		memory,
+		retrieved,
+		{Name: ContextSegmentHistory, Tokens: usage.EstimateTokens(ism.buildSessionContext(session))},
+		{Name: ContextSegmentLastOutput, Tokens: usage.EstimateTokens(session.LastCommandOutput)},
+	}
+	return result, nil
+}
+
+// contextTierName はコンテキスト階層の表示名
+func contextTierName(contextType contextmanager.ContextType) string {
+	switch contextType {
+	case contextmanager.ContextTypeImmediate:
+		return "immediate"
+	case contextmanager.ContextTypeShortTerm:
+		return "short"
+	case contextmanager.ContextTypeMediumTerm:
+		return "medium"
+	default:
+		return "long"
+	}
+}
+
+// contextPreview は最初の空でない行を指定文字数で切り詰めて返す
+func contextPreview(content string, maxRunes int) string {
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		runes := []rune(line)
+		if len(runes) > maxRunes {
+			return string(runes[:maxRunes]) + "…"
+		}
+		return line
+	}
+	return ""
+}
+
+// CompressionMetrics はセッションのコンテキスト圧縮の削減率・忠実度の累計
+func (ism *interactiveSessionManager) CompressionMetrics(sessionID string) contextmanager.CompressionMetrics {
+	if ism.contextManager == nil {
+		return contextmanager.CompressionMetrics{}
+	}
+	return ism.contextManager.CompressionMetrics(sessionID)
+}
+
+// GetPerformanceStats はセッションの応答・コンテキスト圧縮の統計を返す
+func (ism *interactiveSessionManager) GetPerformanceStats(sessionID string) map[string]interface{} {
+	stats := make(map[string]interface{})
+	if metrics, err := ism.GetSessionMetrics(sessionID); err == nil {
+		stats["total_interactions"] = metrics.TotalInteractions
+		stats["average_response_time_ms"] = metrics.AverageResponseTime.Milliseconds()
+	}
+
+	compression := ism.CompressionMetrics(sessionID)
+	stats["compression_count"] = compression.Compressions
+	stats["compression_original_bytes"] = compression.OriginalBytes
+	stats["compression_compressed_bytes"] = compression.CompressedBytes
+	stats["compression_ratio"] = compression.Ratio()
+	stats["compression_fidelity_avg"] = compression.AverageFidelity
+	stats["compression_fidelity_min"] = compression.MinFidelity
+	stats["compression_repaired"] = compression.Repaired
+	if compression.Last != nil {
+		stats["compression_method"] = compression.Last.Method
+	}
+	return stats
+}
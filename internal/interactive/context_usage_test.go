@@ -0,0 +1,59 @@
+package interactive
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/contextmanager"
+)
+
+func TestContextUsage(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.ContextWindow = 8192
+	cfg.MaxTokens = 1024
+
+	contextManager := contextmanager.NewSmartContextManager()
+	manager := NewInteractiveSessionManager(contextManager, &patchProvider{}, nil, nil, nil, "test-model", cfg).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	manager.addToSmartContext(session.ID, strings.Repeat("func main() {}\n", 20), "file")
+	contextManager.AddContext(&contextmanager.ContextItem{
+		Type:       contextmanager.ContextTypeLongTerm,
+		Content:    "project uses go modules",
+		Importance: 0.3,
+	})
+
+	usage, err := manager.ContextUsage(session.ID)
+	if err != nil {
+		t.Fatalf("ContextUsage failed: %v", err)
+	}
+	if usage.ContextWindow != 8192 || usage.ReservedOutput != 1024 || usage.Model != "test-model" {
+		t.Errorf("unexpected budget: %+v", usage)
+	}
+
+	segments := make(map[string]ContextSegment)
+	for _, segment := range usage.Segments {
+		segments[segment.Name] = segment
+	}
+	if segments[ContextSegmentSystem].Tokens == 0 || segments[ContextSegmentTemplate].Tokens == 0 {
+		t.Errorf("system prompt and template should be counted: %+v", usage.Segments)
+	}
+	if segments[ContextSegmentContext].Items != 1 || segments[ContextSegmentMemory].Items != 1 {
+		t.Errorf("items should be split into context and memory: %+v", usage.Segments)
+	}
+
+	if len(usage.Items) != 2 || usage.Items[0].ContentType != "file" || usage.Items[0].Tier != "immediate" {
+		t.Errorf("items should be ordered by importance: %+v", usage.Items)
+	}
+	if usage.Items[0].Preview != "func main() {}" {
+		t.Errorf("unexpected preview: %q", usage.Items[0].Preview)
+	}
+
+	if usage.UsedTokens()+usage.RemainingTokens()+usage.ReservedOutput != usage.ContextWindow {
+		t.Errorf("budget does not add up: used %d remaining %d", usage.UsedTokens(), usage.RemainingTokens())
+	}
+}
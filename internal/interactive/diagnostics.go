@@ -0,0 +1,49 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/diagnostic"
+)
+
+// diagnosticsTagPattern は応答中の環境レポートの要求（<DIAGNOSTICS>cargo rustc</DIAGNOSTICS> 等）
+var diagnosticsTagPattern = regexp.MustCompile(`(?s)<DIAGNOSTICS>(.*?)</DIAGNOSTICS>`)
+
+// executeDiagnosticsTags は応答中の <DIAGNOSTICS> を実行し、環境に依存する不具合の調査のための環境レポートを返す
+// 引数は既定に加えてバージョンを調べるツール名（空白・カンマ区切り、省略可）
+func (ism *interactiveSessionManager) executeDiagnosticsTags(ctx context.Context, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range diagnosticsTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		extra := strings.FieldsFunc(match[1], func(r rune) bool { return r == ',' || r == ' ' || r == '\n' || r == '\t' })
+		actions = append(actions, "環境レポート")
+		if err := budget.UseTool(ctx, "diagnostics"); err != nil {
+			results = append(results, fmt.Sprintf("⚠️ 環境レポートの作成エラー: %v", err))
+			continue
+		}
+
+		var tools []string
+		for _, name := range extra {
+			if !diagnostic.ValidToolName(name) {
+				results = append(results, fmt.Sprintf("⚠️ ツール名が不正です: %s", name))
+				continue
+			}
+			if ism.permissions != nil {
+				if err := ism.permissions.CheckCommand(name + " --version"); err != nil {
+					results = append(results, fmt.Sprintf("⚠️ %s のバージョンは調べられません: %v", name, err))
+					continue
+				}
+			}
+			tools = append(tools, name)
+		}
+		report := diagnostic.CaptureEnvironment(ctx, diagnostic.EnvironmentOptions{
+			Config: ism.config,
+			Tools:  tools,
+		})
+		results = append(results, "🩺 "+report.Markdown())
+	}
+	return results, actions
+}
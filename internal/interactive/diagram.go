@@ -0,0 +1,59 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+)
+
+// diagramTagPattern は応答中のアーキテクチャ図の要求（<DIAGRAM>mermaid calls</DIAGRAM> 等）
+var diagramTagPattern = regexp.MustCompile(`<DIAGRAM>(.*?)</DIAGRAM>`)
+
+// executeDiagramTags は応答中の <DIAGRAM> を実行し、作業ディレクトリの依存関係の図をコードブロックとして返す
+// 引数は形式（mermaid, dot）、calls（呼び出している関数を辺に表示）、depth=N（ディレクトリを束ねる階層）
+// 図は応答の本文に入るため、/save のエクスポートにもそのまま残る
+func (ism *interactiveSessionManager) executeDiagramTags(ctx context.Context, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range diagramTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		spec := strings.TrimSpace(match[1])
+		format, opts := parseDiagramSpec(spec)
+		actions = append(actions, fmt.Sprintf("アーキテクチャ図: %s", format))
+
+		diagram, err := analysis.BuildArchitectureDiagram(ctx, ".", opts)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ アーキテクチャ図の作成エラー: %v", err))
+			continue
+		}
+		rendered, err := diagram.Render(format)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ アーキテクチャ図の作成エラー: %v", err))
+			continue
+		}
+		results = append(results, fmt.Sprintf("🗺️ アーキテクチャ図（%d 個の単位、%d 本の依存）:\n```%s\n%s```",
+			len(diagram.Nodes), len(diagram.Edges), format, rendered))
+	}
+	return results, actions
+}
+
+// parseDiagramSpec は <DIAGRAM> の引数を形式と図の作り方にする（未知の語は無視）
+func parseDiagramSpec(spec string) (string, analysis.DiagramOptions) {
+	format := analysis.DiagramMermaid
+	var opts analysis.DiagramOptions
+	for _, word := range strings.Fields(strings.ToLower(spec)) {
+		switch {
+		case word == analysis.DiagramMermaid || word == analysis.DiagramDOT:
+			format = word
+		case word == "calls":
+			opts.Calls = true
+		case strings.HasPrefix(word, "depth="):
+			if depth, err := strconv.Atoi(strings.TrimPrefix(word, "depth=")); err == nil && depth > 0 {
+				opts.Depth = depth
+			}
+		}
+	}
+	return format, opts
+}
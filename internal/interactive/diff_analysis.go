@@ -0,0 +1,1054 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+)
+
+// analyzeGitStatus はgit statusの結果を分析して具体的提案を生成
+func (ism *interactiveSessionManager) analyzeGitStatus(gitOutput string) []string {
+	var suggestions []string
+
+	// 変更されたファイル数をカウント
+	modifiedFiles := strings.Count(gitOutput, "modified:")
+	untrackedDirs := strings.Count(gitOutput, "/")
+
+	if strings.Contains(gitOutput, "Changes not staged for commit") {
+		if modifiedFiles > 10 {
+			suggestions = append(suggestions, fmt.Sprintf("多数の変更ファイル(%d個)があります。`git add .` で一括ステージング", modifiedFiles))
+		} else if modifiedFiles > 0 {
+			suggestions = append(suggestions, "変更されたファイルを確認し、必要に応じて `git add <ファイル名>` でステージング")
+		}
+
+		// 変更内容の詳細分析を提案
+		suggestions = append(suggestions, "実際の変更内容を確認: `git diff` で詳細な差分を表示")
+		suggestions = append(suggestions, "特定ファイルの変更内容を確認: `git diff <ファイル名>`")
+	}
+
+	if strings.Contains(gitOutput, "Untracked files") {
+		if untrackedDirs > 0 {
+			suggestions = append(suggestions, "新しいディレクトリが追加されています。内容を確認して `git add` でトラッキング")
+		}
+		suggestions = append(suggestions, "未追跡ファイルの内容を確認し、必要に応じてGitに追加")
+	}
+
+	if strings.Contains(gitOutput, "no changes added to commit") {
+		suggestions = append(suggestions, "変更をステージング後、`git commit -m \"説明\"` でコミット作成")
+	}
+
+	if strings.Contains(gitOutput, "feature/") {
+		suggestions = append(suggestions, "機能ブランチでの作業中です。完了後はメインブランチへのマージを検討")
+	}
+
+	return suggestions
+}
+
+// analyzeGitDiff はgit diffの結果を分析してコード変更の意味を理解
+func (ism *interactiveSessionManager) analyzeGitDiff(diffOutput string) []string {
+	var suggestions []string
+
+	if strings.TrimSpace(diffOutput) == "" {
+		suggestions = append(suggestions, "変更はありません。新しい機能の実装を検討しましょう")
+		return suggestions
+	}
+
+	// 変更の意味を分析
+	changeAnalysis := ism.analyzeChangeSemantics(diffOutput)
+
+	// 分析結果に基づく智能的な提案を生成
+	suggestions = ism.generateSemanticSuggestions(changeAnalysis)
+
+	return suggestions
+}
+
+// ChangeSemantics は変更の意味を表現する構造体
+type ChangeSemantics struct {
+	ChangeType         string                 // 変更の種類（feature, fix, refactor, docs等）
+	AffectedAreas      []string               // 影響を受ける領域
+	Impact             *analysis.ImpactReport // 依存グラフによる影響範囲
+	RiskLevel          string                 // リスクレベル（low, medium, high, critical）
+	TestRequirements   []string               // 必要なテスト
+	ReviewPoints       []string               // レビューすべき点
+	Dependencies       []string               // 影響する依存関係
+	ArchitectureImpact string                 // アーキテクチャへの影響
+	QualityMetrics     map[string]interface{} // 品質メトリクス
+}
+
+// analyzeChangeSemantics は変更の意味を分析
+func (ism *interactiveSessionManager) analyzeChangeSemantics(diffOutput string) *ChangeSemantics {
+	analysis := &ChangeSemantics{
+		AffectedAreas:    []string{},
+		TestRequirements: []string{},
+		ReviewPoints:     []string{},
+		Dependencies:     []string{},
+		QualityMetrics:   make(map[string]interface{}),
+	}
+
+	// 1. 変更の種類を判定
+	analysis.ChangeType = ism.detectChangeType(diffOutput)
+
+	// 2. 影響領域の分析
+	analysis.AffectedAreas, analysis.Impact = ism.identifyAffectedAreas(diffOutput)
+
+	// 3. リスクレベルの評価
+	analysis.RiskLevel = ism.evaluateRiskLevel(diffOutput, analysis.AffectedAreas)
+
+	// 4. テスト要件の特定
+	analysis.TestRequirements = ism.identifyTestRequirements(diffOutput, analysis.ChangeType)
+
+	// 5. レビューポイントの抽出
+	analysis.ReviewPoints = ism.extractReviewPoints(diffOutput, analysis.ChangeType, analysis.RiskLevel)
+
+	// 6. 依存関係への影響
+	analysis.Dependencies = ism.analyzeDependencyImpact(diffOutput)
+
+	// 7. アーキテクチャ影響の評価
+	analysis.ArchitectureImpact = ism.evaluateArchitectureImpact(diffOutput, analysis.AffectedAreas)
+
+	return analysis
+}
+
+// detectChangeType は変更の種類を検出
+func (ism *interactiveSessionManager) detectChangeType(diffOutput string) string {
+	// 新機能追加の検出
+	if strings.Contains(diffOutput, "+func New") || strings.Contains(diffOutput, "+type ") ||
+		strings.Contains(diffOutput, "+// API:") {
+		return "feature"
+	}
+
+	// バグ修正の検出
+	if strings.Contains(diffOutput, "fix") || strings.Contains(diffOutput, "bug") ||
+		strings.Contains(diffOutput, "-\t\treturn err") || strings.Contains(diffOutput, "+\t\treturn fmt.Errorf") {
+		return "fix"
+	}
+
+	// リファクタリングの検出
+	if strings.Contains(diffOutput, "-func ") && strings.Contains(diffOutput, "+func ") {
+		return "refactor"
+	}
+
+	// ドキュメント更新の検出
+	if strings.Contains(diffOutput, "README.md") || strings.Contains(diffOutput, "CLAUDE.md") ||
+		strings.Contains(diffOutput, "+//") {
+		return "docs"
+	}
+
+	// テスト追加の検出
+	if strings.Contains(diffOutput, "_test.go") || strings.Contains(diffOutput, "+\tfunc Test") {
+		return "test"
+	}
+
+	// 設定変更の検出
+	if strings.Contains(diffOutput, "config") || strings.Contains(diffOutput, ".json") ||
+		strings.Contains(diffOutput, ".yaml") {
+		return "config"
+	}
+
+	return "general"
+}
+
+// identifyAffectedAreas は変更したパッケージ・ファイルと、それに依存する箇所を依存グラフから特定
+func (ism *interactiveSessionManager) identifyAffectedAreas(diffOutput string) ([]string, *analysis.ImpactReport) {
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return []string{}, nil
+	}
+	report, err := analysis.AnalyzeDiffImpact(context.Background(), projectPath, diffOutput)
+	if err != nil {
+		return []string{}, nil
+	}
+	return report.Areas(), report
+}
+
+// suggestionImpact はファイル編集の提案が宣言するシンボルに依存する箇所を求める（求められなければ nil）
+func (ism *interactiveSessionManager) suggestionImpact(ctx context.Context, suggestion *CodeSuggestion) *analysis.ImpactReport {
+	if suggestion == nil || suggestion.FilePath == "" || ism.isCommandSuggestion(suggestion.SuggestedCode) {
+		return nil
+	}
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	symbols := analysis.DeclaredSymbols(suggestion.FilePath, suggestion.SuggestedCode)
+	report, err := analysis.AnalyzeImpact(ctx, projectPath, map[string][]string{suggestion.FilePath: symbols})
+	if err != nil {
+		return nil
+	}
+	return report
+}
+
+// evaluateRiskLevel はリスクレベルを評価
+func (ism *interactiveSessionManager) evaluateRiskLevel(diffOutput string, affectedAreas []string) string {
+	// 重要なファイルの変更
+	if strings.Contains(diffOutput, "internal/security/") ||
+		strings.Contains(diffOutput, "password") || strings.Contains(diffOutput, "auth") {
+		return "critical"
+	}
+
+	// 複数の重要領域に影響
+	if len(affectedAreas) >= 3 {
+		return "high"
+	}
+
+	// コア機能への影響
+	if strings.Contains(diffOutput, "internal/llm/") ||
+		strings.Contains(diffOutput, "internal/handlers/chat.go") {
+		return "medium"
+	}
+
+	// ドキュメントやテストのみ
+	if strings.Contains(diffOutput, "README.md") || strings.Contains(diffOutput, "_test.go") {
+		return "low"
+	}
+
+	return "medium"
+}
+
+// identifyTestRequirements はテスト要件を特定
+func (ism *interactiveSessionManager) identifyTestRequirements(diffOutput string, changeType string) []string {
+	requirements := []string{}
+
+	switch changeType {
+	case "feature":
+		requirements = append(requirements, "新機能の単体テスト作成")
+		requirements = append(requirements, "統合テストシナリオの追加")
+		requirements = append(requirements, "エッジケースのテスト")
+	case "fix":
+		requirements = append(requirements, "バグ再現テストの作成")
+		requirements = append(requirements, "リグレッションテストの実行")
+	case "refactor":
+		requirements = append(requirements, "既存テストの動作確認")
+		requirements = append(requirements, "パフォーマンステストの実行")
+	case "config":
+		requirements = append(requirements, "設定値のバリデーションテスト")
+		requirements = append(requirements, "異常設定時の動作確認")
+	}
+
+	// コード内容に基づく追加テスト
+	if strings.Contains(diffOutput, "error") {
+		requirements = append(requirements, "エラーハンドリングのテスト")
+	}
+	if strings.Contains(diffOutput, "concurrent") || strings.Contains(diffOutput, "goroutine") {
+		requirements = append(requirements, "並行処理の競合状態テスト")
+	}
+
+	return requirements
+}
+
+// extractReviewPoints はレビューポイントを抽出
+func (ism *interactiveSessionManager) extractReviewPoints(diffOutput string, changeType string, riskLevel string) []string {
+	points := []string{}
+
+	// リスクレベル別のレビューポイント
+	switch riskLevel {
+	case "critical":
+		points = append(points, "セキュリティ影響の詳細確認")
+		points = append(points, "権限昇格の可能性チェック")
+		points = append(points, "データ漏洩リスクの評価")
+	case "high":
+		points = append(points, "アーキテクチャ設計との整合性確認")
+		points = append(points, "パフォーマンス影響の測定")
+		points = append(points, "後方互換性の保証")
+	case "medium":
+		points = append(points, "コード品質と可読性の確認")
+		points = append(points, "エラーハンドリングの適切性")
+	case "low":
+		points = append(points, "ドキュメントの正確性確認")
+		points = append(points, "コードスタイルの統一")
+	}
+
+	// 特定パターンのレビューポイント
+	if strings.Contains(diffOutput, "+import") {
+		points = append(points, "新しい依存関係の必要性と安全性")
+	}
+	if strings.Contains(diffOutput, "TODO") || strings.Contains(diffOutput, "FIXME") {
+		points = append(points, "残存する技術的負債の対応計画")
+	}
+
+	return points
+}
+
+// analyzeDependencyImpact は依存関係への影響を分析
+func (ism *interactiveSessionManager) analyzeDependencyImpact(diffOutput string) []string {
+	dependencies := []string{}
+
+	if strings.Contains(diffOutput, "go.mod") {
+		dependencies = append(dependencies, "Go モジュール依存関係の更新")
+	}
+	if strings.Contains(diffOutput, "+import") {
+		dependencies = append(dependencies, "新規パッケージ依存の追加")
+	}
+	if strings.Contains(diffOutput, "internal/llm/") {
+		dependencies = append(dependencies, "LLM プロバイダー統合への影響")
+	}
+	if strings.Contains(diffOutput, "internal/tools/") {
+		dependencies = append(dependencies, "ツールチェーン統合への影響")
+	}
+
+	return dependencies
+}
+
+// evaluateArchitectureImpact はアーキテクチャ影響を評価
+func (ism *interactiveSessionManager) evaluateArchitectureImpact(diffOutput string, affectedAreas []string) string {
+	if len(affectedAreas) >= 4 {
+		return "システム全体アーキテクチャに重大な変更。設計レビュー必須"
+	}
+
+	if strings.Contains(diffOutput, "interface") && strings.Contains(diffOutput, "+") {
+		return "新しいインターフェース追加。契約設計の確認が必要"
+	}
+
+	if strings.Contains(diffOutput, "internal/") && len(affectedAreas) >= 2 {
+		return "内部アーキテクチャの結合度に影響。モジュール境界の再検討推奨"
+	}
+
+	if strings.Contains(diffOutput, "config") {
+		return "設定アーキテクチャの変更。運用環境への影響確認必要"
+	}
+
+	return "局所的変更。アーキテクチャ影響は限定的"
+}
+
+// generateSemanticSuggestions は意味解析に基づく提案を生成
+func (ism *interactiveSessionManager) generateSemanticSuggestions(analysis *ChangeSemantics) []string {
+	suggestions := []string{}
+
+	// 変更タイプ別の主要メッセージ
+	switch analysis.ChangeType {
+	case "feature":
+		suggestions = append(suggestions, "🚀 新機能開発: "+strings.Join(analysis.AffectedAreas, ", ")+"への機能追加を検出")
+	case "fix":
+		suggestions = append(suggestions, "🔧 バグ修正: 品質向上のための修正を実施")
+	case "refactor":
+		suggestions = append(suggestions, "♻️ リファクタリング: コード品質改善を検出")
+	case "docs":
+		suggestions = append(suggestions, "📚 ドキュメント更新: プロジェクト情報の最新化")
+	case "config":
+		suggestions = append(suggestions, "⚙️ 設定変更: システム動作に影響する設定の変更")
+	default:
+		suggestions = append(suggestions, "🔄 一般的な変更: "+strings.Join(analysis.AffectedAreas, ", ")+"の更新")
+	}
+
+	// リスクレベル別の警告
+	switch analysis.RiskLevel {
+	case "critical":
+		suggestions = append(suggestions, "⚠️ 【重要】クリティカル変更: 慎重なレビューとテストが必須")
+	case "high":
+		suggestions = append(suggestions, "⚡ 高影響変更: 十分なテストとレビューを実施")
+	case "medium":
+		suggestions = append(suggestions, "📋 中程度の影響: 標準的なレビュープロセスを実施")
+	}
+
+	// 依存グラフによる影響範囲
+	if analysis.Impact != nil {
+		suggestions = append(suggestions, analysis.Impact.Summary())
+	}
+
+	// アーキテクチャ影響
+	if analysis.ArchitectureImpact != "局所的変更。アーキテクチャ影響は限定的" {
+		suggestions = append(suggestions, "🏗️ アーキテクチャ影響: "+analysis.ArchitectureImpact)
+	}
+
+	// テスト要件
+	if len(analysis.TestRequirements) > 0 {
+		suggestions = append(suggestions, "🧪 推奨テスト: "+strings.Join(analysis.TestRequirements, ", "))
+	}
+
+	// レビューポイント
+	if len(analysis.ReviewPoints) > 0 {
+		suggestions = append(suggestions, "👁️ レビューポイント: "+strings.Join(analysis.ReviewPoints, ", "))
+	}
+
+	// 依存関係への影響
+	if len(analysis.Dependencies) > 0 {
+		suggestions = append(suggestions, "🔗 依存関係: "+strings.Join(analysis.Dependencies, ", "))
+	}
+
+	// 次ステップの提案
+	suggestions = append(suggestions, "✅ 推奨次ステップ: 変更内容確認後、適切なテスト実行とコードレビュー実施")
+
+	return suggestions
+}
+
+// summarizeGitDiff はgit diffの出力を詳細分析して要約する
+func (ism *interactiveSessionManager) summarizeGitDiff(diffOutput string) string {
+	if strings.TrimSpace(diffOutput) == "" {
+		return "変更はありません。"
+	}
+
+	// 詳細分析を実行
+	analysis := ism.performDetailedDiffAnalysis(diffOutput)
+
+	// 結果をフォーマット
+	summary := fmt.Sprintf("📊 **変更サマリー**\n")
+	summary += fmt.Sprintf("• ファイル数: %d個  ", len(analysis.ChangedFiles))
+	summary += fmt.Sprintf("• 変更規模: +%d行, -%d行  ", analysis.AddedLines, analysis.DeletedLines)
+	summary += fmt.Sprintf("• リスクレベル: %s\n", ism.formatRiskLevel(analysis.RiskLevel))
+
+	// ファイル別詳細情報
+	if len(analysis.FileSummaries) > 0 {
+		summary += "\n📝 **変更ファイル詳細:**\n"
+		for i, fileSummary := range analysis.FileSummaries {
+			if i >= 6 { // 最大6個まで詳細表示
+				summary += fmt.Sprintf("• ... その他 %d個のファイル\n", len(analysis.FileSummaries)-6)
+				break
+			}
+
+			icon := ism.getFileTypeIcon(fileSummary.Path)
+			summary += fmt.Sprintf("• %s **%s** (+%d/-%d行) %s\n",
+				icon, fileSummary.Path, fileSummary.AddedLines, fileSummary.DeletedLines, fileSummary.ChangeType)
+
+			// 重要な変更内容を表示
+			if len(fileSummary.KeyChanges) > 0 {
+				for _, change := range fileSummary.KeyChanges[:min(2, len(fileSummary.KeyChanges))] {
+					summary += fmt.Sprintf("  └ %s\n", change)
+				}
+			}
+		}
+	}
+
+	// 影響度分析
+	if len(analysis.ImpactAreas) > 0 {
+		summary += "\n🎯 **影響領域:**\n"
+		for _, area := range analysis.ImpactAreas {
+			summary += fmt.Sprintf("• %s %s\n", area.Icon, area.Description)
+		}
+	}
+
+	// 具体的な技術的変更
+	if len(analysis.TechnicalChanges) > 0 {
+		summary += "\n🔧 **技術的変更:**\n"
+		for _, change := range analysis.TechnicalChanges {
+			summary += fmt.Sprintf("• %s\n", change)
+		}
+	}
+
+	// セキュリティ・品質の注意点
+	if len(analysis.SecurityConcerns) > 0 || len(analysis.QualityIssues) > 0 {
+		summary += "\n⚠️ **要注意:**\n"
+		for _, concern := range analysis.SecurityConcerns {
+			summary += fmt.Sprintf("• 🔐 %s\n", concern)
+		}
+		for _, issue := range analysis.QualityIssues {
+			summary += fmt.Sprintf("• 📊 %s\n", issue)
+		}
+	}
+
+	// パフォーマンス影響
+	if analysis.PerformanceImpact != "" {
+		summary += fmt.Sprintf("\n⚡ **パフォーマンス影響:** %s\n", analysis.PerformanceImpact)
+	}
+
+	summary += "\n💡 個別ファイルの詳細: `git diff <ファイル名>` | 全diff確認: `git diff --no-pager`"
+
+	return summary
+}
+
+// DetailedDiffAnalysis は詳細なdiff分析結果
+type DetailedDiffAnalysis struct {
+	ChangedFiles      []string      `json:"changed_files"`
+	AddedLines        int           `json:"added_lines"`
+	DeletedLines      int           `json:"deleted_lines"`
+	RiskLevel         string        `json:"risk_level"`
+	FileSummaries     []FileSummary `json:"file_summaries"`
+	ImpactAreas       []ImpactArea  `json:"impact_areas"`
+	TechnicalChanges  []string      `json:"technical_changes"`
+	SecurityConcerns  []string      `json:"security_concerns"`
+	QualityIssues     []string      `json:"quality_issues"`
+	PerformanceImpact string        `json:"performance_impact,omitempty"`
+}
+
+// FileSummary はファイル別の変更サマリー
+type FileSummary struct {
+	Path         string   `json:"path"`
+	AddedLines   int      `json:"added_lines"`
+	DeletedLines int      `json:"deleted_lines"`
+	ChangeType   string   `json:"change_type"`
+	KeyChanges   []string `json:"key_changes"`
+}
+
+// ImpactArea は影響領域
+type ImpactArea struct {
+	Icon        string `json:"icon"`
+	Description string `json:"description"`
+}
+
+// performDetailedDiffAnalysis は詳細なdiff分析を実行
+func (ism *interactiveSessionManager) performDetailedDiffAnalysis(diffOutput string) *DetailedDiffAnalysis {
+	analysis := &DetailedDiffAnalysis{
+		ChangedFiles:     ism.extractChangedFilesFromDiff(diffOutput),
+		AddedLines:       strings.Count(diffOutput, "\n+") - strings.Count(diffOutput, "\n+++"),
+		DeletedLines:     strings.Count(diffOutput, "\n-") - strings.Count(diffOutput, "\n---"),
+		FileSummaries:    []FileSummary{},
+		ImpactAreas:      []ImpactArea{},
+		TechnicalChanges: []string{},
+		SecurityConcerns: []string{},
+		QualityIssues:    []string{},
+	}
+
+	// リスクレベル評価
+	analysis.RiskLevel = ism.calculateRiskLevel(analysis.AddedLines, analysis.DeletedLines, analysis.ChangedFiles)
+
+	// ファイル別分析
+	analysis.FileSummaries = ism.analyzeIndividualFiles(diffOutput, analysis.ChangedFiles)
+
+	// 影響領域の特定
+	analysis.ImpactAreas = ism.identifyImpactAreas(analysis.ChangedFiles, diffOutput)
+
+	// 技術的変更の抽出
+	analysis.TechnicalChanges = ism.extractTechnicalChanges(diffOutput)
+
+	// セキュリティ・品質チェック
+	analysis.SecurityConcerns = ism.identifySecurityConcerns(diffOutput)
+	analysis.QualityIssues = ism.identifyQualityIssues(diffOutput, analysis)
+
+	// パフォーマンス影響評価
+	analysis.PerformanceImpact = ism.evaluatePerformanceImpact(diffOutput, analysis.ChangedFiles)
+
+	return analysis
+}
+
+// calculateRiskLevel はリスクレベルを計算
+func (ism *interactiveSessionManager) calculateRiskLevel(addedLines, deletedLines int, changedFiles []string) string {
+	totalChange := addedLines + deletedLines
+
+	// 重要なファイルのチェック
+	hasSecurityFile := false
+	hasCoreFile := false
+	for _, file := range changedFiles {
+		if strings.Contains(file, "security") || strings.Contains(file, "auth") {
+			hasSecurityFile = true
+		}
+		if strings.Contains(file, "main.go") || strings.Contains(file, "session.go") {
+			hasCoreFile = true
+		}
+	}
+
+	if hasSecurityFile || totalChange > 500 {
+		return "🔴 HIGH"
+	} else if hasCoreFile || totalChange > 200 || len(changedFiles) > 8 {
+		return "🟡 MEDIUM"
+	}
+	return "🟢 LOW"
+}
+
+// analyzeIndividualFiles はファイル別の詳細分析
+func (ism *interactiveSessionManager) analyzeIndividualFiles(diffOutput string, changedFiles []string) []FileSummary {
+	summaries := []FileSummary{}
+
+	for _, file := range changedFiles {
+		// ファイル別の変更行数を計算
+		fileSection := ism.extractFileSection(diffOutput, file)
+		addedLines := strings.Count(fileSection, "\n+") - strings.Count(fileSection, "\n+++")
+		deletedLines := strings.Count(fileSection, "\n-") - strings.Count(fileSection, "\n---")
+
+		// 変更タイプを判定
+		changeType := ism.determineChangeType(fileSection, file)
+
+		// 主要な変更を抽出
+		keyChanges := ism.extractKeyChanges(fileSection, file)
+
+		summaries = append(summaries, FileSummary{
+			Path:         file,
+			AddedLines:   addedLines,
+			DeletedLines: deletedLines,
+			ChangeType:   changeType,
+			KeyChanges:   keyChanges,
+		})
+	}
+
+	return summaries
+}
+
+// extractFileSection はファイル別のdiff部分を抽出
+func (ism *interactiveSessionManager) extractFileSection(diffOutput, fileName string) string {
+	lines := strings.Split(diffOutput, "\n")
+	var fileLines []string
+	inFile := false
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git") && strings.Contains(line, fileName) {
+			inFile = true
+			fileLines = []string{line}
+		} else if strings.HasPrefix(line, "diff --git") && inFile {
+			break
+		} else if inFile {
+			fileLines = append(fileLines, line)
+		}
+	}
+
+	return strings.Join(fileLines, "\n")
+}
+
+// determineChangeType は変更タイプを判定
+func (ism *interactiveSessionManager) determineChangeType(fileSection, fileName string) string {
+	if strings.Contains(fileSection, "+func New") {
+		return "新機能追加"
+	} else if strings.Contains(fileSection, "+type ") && strings.Contains(fileSection, "struct") {
+		return "構造拡張"
+	} else if strings.Contains(fileSection, "test") {
+		return "テスト更新"
+	} else if strings.Contains(fileName, "config") {
+		return "設定変更"
+	} else if strings.Contains(fileName, ".md") {
+		return "ドキュメント"
+	} else if strings.Contains(fileSection, "-") && strings.Contains(fileSection, "+") {
+		return "リファクタ"
+	} else if strings.Count(fileSection, "+") > strings.Count(fileSection, "-") {
+		return "機能拡張"
+	}
+	return "修正・改善"
+}
+
+// extractKeyChanges は主要な変更を抽出
+func (ism *interactiveSessionManager) extractKeyChanges(fileSection, fileName string) []string {
+	changes := []string{}
+
+	// 新しい関数
+	if funcMatches := regexp.MustCompile(`\+func\s+(\w+)`).FindAllStringSubmatch(fileSection, -1); len(funcMatches) > 0 {
+		if len(funcMatches) <= 3 {
+			for _, match := range funcMatches {
+				changes = append(changes, fmt.Sprintf("新関数: %s()", match[1]))
+			}
+		} else {
+			changes = append(changes, fmt.Sprintf("%d個の新しい関数を追加", len(funcMatches)))
+		}
+	}
+
+	// 新しい構造体
+	if structMatches := regexp.MustCompile(`\+type\s+(\w+)\s+struct`).FindAllStringSubmatch(fileSection, -1); len(structMatches) > 0 {
+		for _, match := range structMatches {
+			changes = append(changes, fmt.Sprintf("新構造体: %s", match[1]))
+		}
+	}
+
+	// インポート変更
+	if strings.Contains(fileSection, "+\t\"") {
+		importCount := strings.Count(fileSection, "+\t\"")
+		changes = append(changes, fmt.Sprintf("%d個のパッケージを新規導入", importCount))
+	}
+
+	// エラーハンドリング改善
+	if strings.Contains(fileSection, "fmt.Errorf") || strings.Contains(fileSection, "errors.New") {
+		changes = append(changes, "エラーハンドリング強化")
+	}
+
+	return changes
+}
+
+// formatRiskLevel はリスクレベルをフォーマット
+func (ism *interactiveSessionManager) formatRiskLevel(riskLevel string) string {
+	switch riskLevel {
+	case "🔴 HIGH":
+		return "🔴 HIGH (要慎重レビュー)"
+	case "🟡 MEDIUM":
+		return "🟡 MEDIUM (標準レビュー)"
+	default:
+		return "🟢 LOW (軽微な変更)"
+	}
+}
+
+// identifyImpactAreas は影響領域を特定
+func (ism *interactiveSessionManager) identifyImpactAreas(changedFiles []string, diffOutput string) []ImpactArea {
+	areas := []ImpactArea{}
+	areaMap := make(map[string]bool)
+
+	for _, file := range changedFiles {
+		if strings.Contains(file, "internal/handlers/chat.go") && !areaMap["chat"] {
+			areas = append(areas, ImpactArea{Icon: "💬", Description: "チャット・会話システム"})
+			areaMap["chat"] = true
+		}
+		if strings.Contains(file, "internal/interactive/") && !areaMap["interactive"] {
+			areas = append(areas, ImpactArea{Icon: "🎯", Description: "インタラクティブ機能"})
+			areaMap["interactive"] = true
+		}
+		if strings.Contains(file, "internal/config/") && !areaMap["config"] {
+			areas = append(areas, ImpactArea{Icon: "⚙️", Description: "設定・構成管理"})
+			areaMap["config"] = true
+		}
+		if strings.Contains(file, "cmd/") && !areaMap["cli"] {
+			areas = append(areas, ImpactArea{Icon: "🖥️", Description: "CLI インターフェース"})
+			areaMap["cli"] = true
+		}
+		if strings.Contains(file, "internal/tools/") && !areaMap["tools"] {
+			areas = append(areas, ImpactArea{Icon: "🔧", Description: "ツール・ユーティリティ"})
+			areaMap["tools"] = true
+		}
+		if strings.Contains(file, "internal/handlers/") && !areaMap["handlers"] {
+			areas = append(areas, ImpactArea{Icon: "🎛️", Description: "ハンドラー・処理制御"})
+			areaMap["handlers"] = true
+		}
+	}
+
+	return areas
+}
+
+// extractTechnicalChanges は技術的変更を抽出
+func (ism *interactiveSessionManager) extractTechnicalChanges(diffOutput string) []string {
+	changes := []string{}
+
+	// 同期・並行処理の追加
+	if strings.Contains(diffOutput, "+sync.") || strings.Contains(diffOutput, "+go func") {
+		changes = append(changes, "並行処理・同期機能の追加")
+	}
+
+	// エラーハンドリング強化
+	if strings.Contains(diffOutput, "+\t\treturn fmt.Errorf") {
+		errorCount := strings.Count(diffOutput, "+\t\treturn fmt.Errorf")
+		changes = append(changes, fmt.Sprintf("エラーハンドリング改善 (%d箇所)", errorCount))
+	}
+
+	// 新しいインターフェース追加
+	if strings.Contains(diffOutput, "+type ") && strings.Contains(diffOutput, "interface") {
+		changes = append(changes, "新インターフェース定義の追加")
+	}
+
+	// コンテキスト処理
+	if strings.Contains(diffOutput, "context.Context") || strings.Contains(diffOutput, "ctx context.Context") {
+		changes = append(changes, "コンテキスト管理の統合")
+	}
+
+	// メモリ管理改善
+	if strings.Contains(diffOutput, "sync.Pool") || strings.Contains(diffOutput, "make([]") {
+		changes = append(changes, "メモリ使用効率の最適化")
+	}
+
+	// ログ機能追加
+	if strings.Contains(diffOutput, "log.") || strings.Contains(diffOutput, "logger.") {
+		changes = append(changes, "ログ機能の強化")
+	}
+
+	return changes
+}
+
+// identifySecurityConcerns はセキュリティ懸念を特定
+func (ism *interactiveSessionManager) identifySecurityConcerns(diffOutput string) []string {
+	concerns := []string{}
+
+	// 認証関連
+	if strings.Contains(diffOutput, "auth") || strings.Contains(diffOutput, "token") {
+		concerns = append(concerns, "認証・認可システムの変更")
+	}
+
+	// パスワード・秘密情報
+	if strings.Contains(diffOutput, "password") || strings.Contains(diffOutput, "secret") || strings.Contains(diffOutput, "key") {
+		concerns = append(concerns, "機密情報の取り扱い変更")
+	}
+
+	// ファイルアクセス権限
+	if strings.Contains(diffOutput, "os.OpenFile") || strings.Contains(diffOutput, "0644") || strings.Contains(diffOutput, "0755") {
+		concerns = append(concerns, "ファイル権限・アクセス制御の変更")
+	}
+
+	// 外部コマンド実行
+	if strings.Contains(diffOutput, "exec.Command") || strings.Contains(diffOutput, "exec.CommandContext") {
+		concerns = append(concerns, "外部コマンド実行によるセキュリティ影響")
+	}
+
+	// 入力検証
+	if strings.Contains(diffOutput, "strings.Contains") && strings.Contains(diffOutput, "user") {
+		concerns = append(concerns, "ユーザー入力処理の変更 - 検証強化を確認")
+	}
+
+	return concerns
+}
+
+// identifyQualityIssues は品質問題を特定
+func (ism *interactiveSessionManager) identifyQualityIssues(diffOutput string, analysis *DetailedDiffAnalysis) []string {
+	issues := []string{}
+
+	// 大規模な関数追加
+	funcCount := strings.Count(diffOutput, "+func ")
+	if funcCount > 10 {
+		issues = append(issues, fmt.Sprintf("大量の関数追加 (%d個) - 複雑度増加に注意", funcCount))
+	}
+
+	// テストの不足
+	hasTest := false
+	for _, file := range analysis.ChangedFiles {
+		if strings.Contains(file, "_test.go") {
+			hasTest = true
+			break
+		}
+	}
+	if analysis.AddedLines > 200 && !hasTest {
+		issues = append(issues, "大きな変更に対するテストコードの追加が推奨")
+	}
+
+	// エラーハンドリングの不足
+	addedFuncs := strings.Count(diffOutput, "+func ")
+	errorHandling := strings.Count(diffOutput, "return") + strings.Count(diffOutput, "err")
+	if addedFuncs > 3 && errorHandling < addedFuncs {
+		issues = append(issues, "エラーハンドリングの不足が疑われます")
+	}
+
+	// コメント不足
+	commentCount := strings.Count(diffOutput, "+//")
+	if analysis.AddedLines > 300 && commentCount < 10 {
+		issues = append(issues, "コードコメント・ドキュメントの追加を検討")
+	}
+
+	return issues
+}
+
+// evaluatePerformanceImpact はパフォーマンス影響を評価
+func (ism *interactiveSessionManager) evaluatePerformanceImpact(diffOutput string, changedFiles []string) string {
+	impacts := []string{}
+
+	// 並行処理の追加
+	if strings.Contains(diffOutput, "+go func") || strings.Contains(diffOutput, "+sync.") {
+		impacts = append(impacts, "並行処理による高速化期待")
+	}
+
+	// データベース・I/O操作
+	if strings.Contains(diffOutput, "os.ReadFile") || strings.Contains(diffOutput, "os.WriteFile") {
+		impacts = append(impacts, "ファイルI/O処理の追加")
+	}
+
+	// ネットワーク処理
+	if strings.Contains(diffOutput, "http.") || strings.Contains(diffOutput, "net/") {
+		impacts = append(impacts, "ネットワーク通信処理の追加")
+	}
+
+	// メモリ使用量の変化
+	if strings.Contains(diffOutput, "make([]") || strings.Contains(diffOutput, "make(map") {
+		impacts = append(impacts, "メモリ使用量への影響")
+	}
+
+	// 大量のループ処理
+	if strings.Count(diffOutput, "+\tfor ") > 5 {
+		impacts = append(impacts, "複数ループ処理による計算負荷増加")
+	}
+
+	if len(impacts) == 0 {
+		return "軽微 - 大きなパフォーマンス影響なし"
+	}
+
+	return strings.Join(impacts, "、")
+}
+
+// getFileTypeIcon はファイルタイプに応じたアイコンを返す
+func (ism *interactiveSessionManager) getFileTypeIcon(filename string) string {
+	ext := filepath.Ext(filename)
+	basename := filepath.Base(filename)
+
+	switch {
+	case ext == ".go":
+		return "🐹"
+	case ext == ".js" || ext == ".ts" || ext == ".jsx" || ext == ".tsx":
+		return "📜"
+	case ext == ".py":
+		return "🐍"
+	case ext == ".md":
+		return "📚"
+	case ext == ".json" || ext == ".yaml" || ext == ".yml":
+		return "⚙️"
+	case strings.Contains(basename, "test"):
+		return "🧪"
+	case ext == ".dockerfile" || basename == "Dockerfile":
+		return "🐳"
+	case ext == ".sh" || ext == ".bash":
+		return "⚡"
+	case strings.Contains(filename, "config"):
+		return "🔧"
+	default:
+		return "📄"
+	}
+}
+
+// extractChangePatterns は変更パターンを抽出
+func (ism *interactiveSessionManager) extractChangePatterns(diffOutput string) []string {
+	patterns := []string{}
+
+	// 新機能追加
+	if strings.Contains(diffOutput, "+func New") {
+		patterns = append(patterns, "🚀 新しいコンストラクタ関数の追加")
+	}
+	if strings.Contains(diffOutput, "+type ") && strings.Contains(diffOutput, "struct") {
+		patterns = append(patterns, "🏗️ 新しい構造体定義の追加")
+	}
+	if strings.Contains(diffOutput, "+func ") {
+		funcCount := strings.Count(diffOutput, "+func ")
+		if funcCount > 1 {
+			patterns = append(patterns, fmt.Sprintf("⚡ %d個の新しい関数の追加", funcCount))
+		} else {
+			patterns = append(patterns, "⚡ 新しい関数の追加")
+		}
+	}
+
+	// インポート変更
+	if strings.Contains(diffOutput, "+import") {
+		patterns = append(patterns, "📦 新しいパッケージの導入")
+	}
+
+	// 設定変更
+	if strings.Contains(diffOutput, "config") || strings.Contains(diffOutput, "Config") {
+		patterns = append(patterns, "⚙️ 設定システムの変更")
+	}
+
+	// テスト追加
+	if strings.Contains(diffOutput, "_test.go") {
+		patterns = append(patterns, "🧪 テストコードの追加・変更")
+	}
+
+	// ドキュメント更新
+	if strings.Contains(diffOutput, "README.md") || strings.Contains(diffOutput, "CLAUDE.md") {
+		patterns = append(patterns, "📚 プロジェクトドキュメントの更新")
+	}
+
+	// エラーハンドリング改善
+	if strings.Contains(diffOutput, "fmt.Errorf") || strings.Contains(diffOutput, "errors.New") {
+		patterns = append(patterns, "🔧 エラーハンドリングの改善")
+	}
+
+	// 依存関係
+	if strings.Contains(diffOutput, "go.mod") {
+		patterns = append(patterns, "🔗 Go モジュール依存関係の更新")
+	}
+
+	// パフォーマンス改善
+	if strings.Contains(diffOutput, "goroutine") || strings.Contains(diffOutput, "sync.") {
+		patterns = append(patterns, "⚡ 並行処理・パフォーマンス改善")
+	}
+
+	return patterns
+}
+
+// extractChangedFilesFromDiff はdiff出力から変更されたファイル一覧を抽出
+func (ism *interactiveSessionManager) extractChangedFilesFromDiff(diffOutput string) []string {
+	var files []string
+	lines := strings.Split(diffOutput, "\n")
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "diff --git") {
+			// "diff --git a/file.go b/file.go" の形式
+			parts := strings.Fields(line)
+			if len(parts) >= 4 {
+				filename := strings.TrimPrefix(parts[2], "a/")
+				files = append(files, filename)
+			}
+		}
+	}
+
+	return files
+}
+
+// analyzeGoCodeChanges はGoコードの変更を詳細分析
+func (ism *interactiveSessionManager) analyzeGoCodeChanges(filename, diffOutput string) []string {
+	var suggestions []string
+
+	// 構造体の変更
+	if strings.Contains(diffOutput, "type ") && strings.Contains(diffOutput, "struct") {
+		suggestions = append(suggestions, fmt.Sprintf("Go構造体変更(%s): APIの後方互換性を確認", filename))
+	}
+
+	// インターフェース の変更
+	if strings.Contains(diffOutput, "interface") {
+		suggestions = append(suggestions, fmt.Sprintf("Go インターフェース変更(%s): 実装クラスへの影響を確認", filename))
+	}
+
+	// メソッドの変更
+	if strings.Contains(diffOutput, "func (") {
+		suggestions = append(suggestions, fmt.Sprintf("Goメソッド変更(%s): 関連する単体テストの更新", filename))
+	}
+
+	// パッケージ名の変更
+	if strings.Contains(diffOutput, "package ") {
+		suggestions = append(suggestions, fmt.Sprintf("Goパッケージ変更(%s): インポート文の全体的な更新が必要", filename))
+	}
+
+	// コンストラクタ関数
+	if strings.Contains(diffOutput, "+func New") {
+		suggestions = append(suggestions, fmt.Sprintf("新しいコンストラクタ(%s): 初期化ロジックの検証", filename))
+	}
+
+	// エラー定義
+	if strings.Contains(diffOutput, "errors.New") || strings.Contains(diffOutput, "fmt.Errorf") {
+		suggestions = append(suggestions, fmt.Sprintf("エラーメッセージ変更(%s): エラーハンドリングテストの確認", filename))
+	}
+
+	// 実行後の提案
+	suggestions = append(suggestions, fmt.Sprintf("`go fmt %s` でフォーマット、`go vet %s` で静的解析", filename, filename))
+
+	return suggestions
+}
+
+// analyzeFileOperations はファイル操作の結果を分析
+func (ism *interactiveSessionManager) analyzeFileOperations(action, result string) []string {
+	var suggestions []string
+
+	if strings.Contains(action, "ファイル作成") && strings.Contains(result, "成功") {
+		suggestions = append(suggestions, "作成されたファイルの内容を確認し、必要に応じて編集")
+		suggestions = append(suggestions, "関連するテストファイルの作成を検討")
+	}
+
+	if strings.Contains(action, "ファイル読み込み") {
+		if strings.Contains(result, "go") {
+			suggestions = append(suggestions, "Goコードの構文チェック: `go fmt` と `go vet` を実行")
+		}
+		suggestions = append(suggestions, "ファイル内容に基づいて必要な修正や改善を実施")
+	}
+
+	return suggestions
+}
+
+// analyzeErrors はエラー内容を分析して解決策を提案
+func (ism *interactiveSessionManager) analyzeErrors(errorOutput string) []string {
+	var suggestions []string
+
+	if strings.Contains(errorOutput, "permission denied") {
+		suggestions = append(suggestions, "権限エラーです。`chmod +x` または管理者権限で再実行")
+	}
+
+	if strings.Contains(errorOutput, "command not found") {
+		suggestions = append(suggestions, "コマンドが見つかりません。インストール状況を確認")
+	}
+
+	if strings.Contains(errorOutput, "go: cannot find module") {
+		suggestions = append(suggestions, "`go mod tidy` でモジュール依存関係を解決")
+	}
+
+	if strings.Contains(errorOutput, "syntax error") {
+		suggestions = append(suggestions, "構文エラーがあります。該当ファイルを確認して修正")
+	}
+
+	return suggestions
+}
+
+// analyzeBuildResults はビルド結果を分析
+func (ism *interactiveSessionManager) analyzeBuildResults(buildOutput string) []string {
+	var suggestions []string
+
+	if strings.Contains(buildOutput, "Build succeeded") || len(strings.TrimSpace(buildOutput)) == 0 {
+		suggestions = append(suggestions, "ビルド成功！テストの実行を検討: `go test ./...`")
+		suggestions = append(suggestions, "実行ファイルの動作確認を実施")
+	}
+
+	if strings.Contains(buildOutput, "error:") || strings.Contains(buildOutput, "failed") {
+		suggestions = append(suggestions, "ビルドエラーを修正後、再度ビルドを実行")
+		suggestions = append(suggestions, "依存関係の確認: `go mod download`")
+	}
+
+	return suggestions
+}
+
+// removeDuplicateSuggestions は重複する提案を除去
+func (ism *interactiveSessionManager) removeDuplicateSuggestions(suggestions []string) []string {
+	seen := make(map[string]bool)
+	var unique []string
+
+	for _, suggestion := range suggestions {
+		if !seen[suggestion] {
+			seen[suggestion] = true
+			unique = append(unique, suggestion)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,78 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// 応答中のファイルの移動・削除（<FILEMOVE>移動元|移動先</FILEMOVE>、<FILEDELETE>パス</FILEDELETE>）
+var (
+	fileMoveTagPattern   = regexp.MustCompile(`<FILEMOVE>(.*?)\|(.*?)</FILEMOVE>`)
+	fileDeleteTagPattern = regexp.MustCompile(`<FILEDELETE>(.*?)</FILEDELETE>`)
+)
+
+// SetFileConfirmation はファイル削除の確認方法を設定する（nilなら削除を断る）
+// 逐次表示のチャットは端末で確認し、TUI・単発実行では確認できないため削除しない
+func (ism *interactiveSessionManager) SetFileConfirmation(confirmation security.UserConfirmation) {
+	if ism.fileOpsTool != nil {
+		ism.fileOpsTool.SetConfirmation(confirmation)
+	}
+}
+
+// executeFileOpsTags は応答中の <FILEMOVE>・<FILEDELETE> を実行する
+// Git で追跡しているパスは git mv / git rm を使い、変更は vyb refactor undo で取り消せる
+func (ism *interactiveSessionManager) executeFileOpsTags(ctx context.Context, session *InteractiveSession, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range fileMoveTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		source, destination := strings.TrimSpace(match[1]), strings.TrimSpace(match[2])
+		actions = append(actions, fmt.Sprintf("ファイル移動: %s → %s", source, destination))
+		result, err := ism.runFileOp(ctx, "move: "+source, func() (*tools.ToolExecutionResult, error) {
+			return ism.fileOpsTool.Move(ctx, tools.MoveRequest{Source: source, Destination: destination})
+		})
+		auditFileWrite(ctx, destination, "move", 0, err)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ファイル移動エラー (%s): %s", source, result))
+			continue
+		}
+		results = append(results, fmt.Sprintf("✅ %s", result))
+		ism.recordFileModification(session.ID, destination)
+		publishEditApplied(session.ID, destination, "move")
+	}
+
+	for _, match := range fileDeleteTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		path := strings.TrimSpace(match[1])
+		actions = append(actions, fmt.Sprintf("ファイル削除: %s", path))
+		result, err := ism.runFileOp(ctx, "delete: "+path, func() (*tools.ToolExecutionResult, error) {
+			return ism.fileOpsTool.Delete(ctx, tools.DeleteRequest{Path: path, Recursive: true})
+		})
+		auditFileWrite(ctx, path, "delete", 0, err)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ファイル削除エラー (%s): %s", path, result))
+			continue
+		}
+		results = append(results, fmt.Sprintf("✅ %s", result))
+	}
+	return results, actions
+}
+
+// runFileOp は予算を確認してから移動・削除を実行し、結果の本文を返す
+func (ism *interactiveSessionManager) runFileOp(ctx context.Context, label string, run func() (*tools.ToolExecutionResult, error)) (string, error) {
+	if ism.fileOpsTool == nil {
+		err := fmt.Errorf("ファイル操作ツールが初期化されていません")
+		return err.Error(), err
+	}
+	if err := budget.UseTool(ctx, label); err != nil {
+		return err.Error(), err
+	}
+	result, err := run()
+	if result == nil {
+		return fmt.Sprint(err), err
+	}
+	return result.Content, err
+}
@@ -0,0 +1,308 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/markdown"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+const (
+	fixLoopMaxContextFiles = 5         // 修正依頼に添付するファイルの最大数
+	fixLoopMaxFileBytes    = 32 * 1024 // 添付するファイル1件あたりの最大サイズ
+)
+
+// fixPatchPattern はLLMが返す全文置換パッチ（<FILE path="...">内容</FILE>）
+var fixPatchPattern = regexp.MustCompile(`(?s)<FILE path="([^"]+)">\n?(.*?)\n?</FILE>`)
+
+// FixIteration は自動修正ループの1回分の記録
+type FixIteration struct {
+	Iteration    int           `json:"iteration"`
+	Result       *tasks.Result `json:"result"`        // 修正前の検証結果
+	PatchedFiles []string      `json:"patched_files"` // この回で書き換えたファイル
+}
+
+// FixLoopReport は自動修正ループの最終結果
+type FixLoopReport struct {
+	Success    bool           `json:"success"`
+	Iterations []FixIteration `json:"iterations"`
+	Final      *tasks.Result  `json:"final"`
+	StopReason string         `json:"stop_reason,omitempty"`
+}
+
+// Summary はループ結果をユーザー向けに整形
+func (r *FixLoopReport) Summary() string {
+	var sb strings.Builder
+	if r.Success {
+		if len(r.Iterations) == 0 {
+			sb.WriteString(i18n.T("fixloop.verified") + "\n")
+		} else {
+			sb.WriteString(i18n.T("fixloop.succeeded", len(r.Iterations)) + "\n")
+		}
+	} else {
+		sb.WriteString(i18n.T("fixloop.failed", len(r.Iterations)))
+		if r.StopReason != "" {
+			sb.WriteString(fmt.Sprintf("（%s）", r.StopReason))
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, iteration := range r.Iterations {
+		sb.WriteString(i18n.T("fixloop.iteration",
+			iteration.Iteration, iteration.Result.Kind, len(iteration.Result.Failures), strings.Join(iteration.PatchedFiles, ", ")) + "\n")
+	}
+
+	if r.Final != nil && !r.Final.Success {
+		sb.WriteString("\n")
+		sb.WriteString(tasks.FormatFailures(r.Final))
+	}
+	return sb.String()
+}
+
+// recordFileModification は検証待ちの変更ファイルを記録
+func (ism *interactiveSessionManager) recordFileModification(sessionID, filePath string) {
+	if filePath == "" {
+		return
+	}
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+	if ism.modifiedFiles == nil {
+		ism.modifiedFiles = make(map[string][]string)
+	}
+	ism.modifiedFiles[sessionID] = append(ism.modifiedFiles[sessionID], filePath)
+}
+
+// takeModifiedFiles は検証待ちの変更ファイルを取り出してクリア
+func (ism *interactiveSessionManager) takeModifiedFiles(sessionID string) []string {
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+	files := ism.modifiedFiles[sessionID]
+	delete(ism.modifiedFiles, sessionID)
+	return files
+}
+
+// fixLoopConfig は自動修正ループ設定を返す
+func (ism *interactiveSessionManager) fixLoopConfig() config.FixLoopConfig {
+	if ism.config == nil {
+		return config.DefaultFixLoopConfig()
+	}
+	return ism.config.FixLoop
+}
+
+// verifyModifications は編集が適用された応答についてビルド・テストを検証し、
+// 失敗時は自動修正ループの結果を応答に追記
+func (ism *interactiveSessionManager) verifyModifications(ctx context.Context, sessionID string, response *InteractionResponse) {
+	modified := ism.takeModifiedFiles(sessionID)
+	if len(modified) == 0 || !ism.fixLoopConfig().Enabled {
+		return
+	}
+
+	report, err := ism.RunFixLoop(ctx, sessionID)
+	if err != nil {
+		// ビルドシステムがないプロジェクトでは検証しない
+		return
+	}
+
+	response.Message = strings.TrimRight(response.Message, "\n") + "\n\n" + report.Summary()
+	if response.Metadata == nil {
+		response.Metadata = make(map[string]string)
+	}
+	response.Metadata["fix_loop_success"] = fmt.Sprintf("%t", report.Success)
+	response.Metadata["fix_loop_iterations"] = fmt.Sprintf("%d", len(report.Iterations))
+}
+
+// RunFixLoop はビルド・テストを実行し、失敗が解消するか上限に達するまで
+// 失敗内容をLLMに渡して修正パッチを適用・再実行する
+func (ism *interactiveSessionManager) RunFixLoop(ctx context.Context, sessionID string) (*FixLoopReport, error) {
+	session, err := ism.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	ctx = logger.WithAuditSession(ctx, sessionID)
+
+	cfg := ism.fixLoopConfig()
+	runner, err := tasks.NewRunner(".", ism.execBackend)
+	if err != nil {
+		return nil, err
+	}
+	runner.SetTimeout(time.Duration(cfg.TimeoutSeconds) * time.Second)
+
+	report := &FixLoopReport{}
+	for iteration := 0; ; iteration++ {
+		result, err := ism.verifyProject(ctx, runner, cfg.RunTests)
+		if err != nil {
+			return nil, err
+		}
+		report.Final = result
+		session.LastCommandOutput = result.Output
+
+		if result.Success {
+			report.Success = true
+			return report, nil
+		}
+
+		ism.addToSmartContext(sessionID, tasks.FormatFailures(result), "task_failures")
+
+		if iteration >= cfg.MaxIterations {
+			report.StopReason = i18n.T("fixloop.max_iterations", cfg.MaxIterations)
+			return report, nil
+		}
+
+		patched, err := ism.requestFix(ctx, session, result)
+		if err != nil {
+			report.StopReason = err.Error()
+			return report, nil
+		}
+		if len(patched) == 0 {
+			report.StopReason = i18n.T("fixloop.no_patch")
+			return report, nil
+		}
+
+		report.Iterations = append(report.Iterations, FixIteration{
+			Iteration:    iteration + 1,
+			Result:       result,
+			PatchedFiles: patched,
+		})
+	}
+}
+
+// verifyProject はビルドと（必要なら）テストを実行し、最初の失敗または最後の結果を返す
+func (ism *interactiveSessionManager) verifyProject(ctx context.Context, runner *tasks.Runner, runTests bool) (*tasks.Result, error) {
+	kinds := []tasks.Kind{tasks.KindBuild}
+	if runTests {
+		kinds = append(kinds, tasks.KindTest)
+	}
+
+	var last *tasks.Result
+	for _, kind := range kinds {
+		if runner.System().Command(kind) == "" {
+			continue
+		}
+		if err := budget.UseTool(ctx, string(kind)+": "+runner.System().Command(kind)); err != nil {
+			return nil, err
+		}
+		result, err := runner.Run(ctx, kind)
+		if err != nil {
+			return nil, err
+		}
+		auditTaskResult(ctx, result)
+		last = result
+		if !result.Success {
+			return result, nil
+		}
+	}
+
+	if last == nil {
+		return nil, fmt.Errorf("%s にビルド・テストコマンドがありません", runner.System().Name)
+	}
+	return last, nil
+}
+
+// requestFix は失敗内容と関連ファイルをLLMに渡し、返されたパッチを適用
+func (ism *interactiveSessionManager) requestFix(ctx context.Context, session *InteractiveSession, result *tasks.Result) ([]string, error) {
+	if ism.llmProvider == nil {
+		return nil, fmt.Errorf("LLMプロバイダーが利用できません")
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("The following command failed after code changes were applied. Fix the code so that it succeeds.\n\n")
+	prompt.WriteString(tasks.FormatFailures(result))
+
+	for _, path := range ism.failureFiles(result) {
+		content, err := os.ReadFile(path)
+		if err != nil || len(content) > fixLoopMaxFileBytes {
+			continue
+		}
+		prompt.WriteString(fmt.Sprintf("\n<FILE path=\"%s\">\n%s\n</FILE>\n", path, string(content)))
+	}
+
+	prompt.WriteString(`
+Respond with the COMPLETE new content of every file you change, using exactly this format:
+<FILE path="relative/path">
+full file content
+</FILE>
+Do not include files you do not change. Keep explanations to one short sentence.`)
+
+	response, err := ism.llmProvider.Chat(ctx, llm.ChatRequest{
+		Model:    ism.getConfiguredModel(),
+		Messages: []llm.ChatMessage{{Role: "user", Content: prompt.String()}},
+		Stream:   false,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("LLM応答生成エラー: %w", err)
+	}
+
+	var patched []string
+	for _, match := range fixPatchPattern.FindAllStringSubmatch(response.Message.Content, -1) {
+		path := filepath.Clean(strings.TrimSpace(match[1]))
+		content := markdown.StripCodeFence(match[2])
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		if err := ism.createFile(ctx, session, path, content); err != nil {
+			return patched, fmt.Errorf("パッチ適用エラー (%s): %w", path, err)
+		}
+		patched = append(patched, path)
+		session.Metrics.FilesModified++
+	}
+	return patched, nil
+}
+
+// failureFiles は失敗箇所のファイルパスを重複なく解決
+func (ism *interactiveSessionManager) failureFiles(result *tasks.Result) []string {
+	var files []string
+	seen := make(map[string]bool)
+	for _, failure := range result.Failures {
+		if failure.File == "" || len(files) >= fixLoopMaxContextFiles {
+			continue
+		}
+		path := resolveFailurePath(failure.File)
+		if path == "" || seen[path] {
+			continue
+		}
+		seen[path] = true
+		files = append(files, path)
+	}
+	return files
+}
+
+// resolveFailurePath はパッケージ相対で出力されたパス（go test等）をプロジェクト内で探索
+func resolveFailurePath(file string) string {
+	if _, err := os.Stat(file); err == nil {
+		return filepath.Clean(file)
+	}
+	if strings.Contains(file, string(filepath.Separator)) {
+		return ""
+	}
+
+	found := ""
+	filepath.Walk(".", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			name := info.Name()
+			if path != "." && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Name() == file {
+			found = path
+			return filepath.SkipAll
+		}
+		return nil
+	})
+	return found
+}
@@ -0,0 +1,125 @@
+package interactive
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// patchProvider は固定の修正パッチを返すテスト用プロバイダー
+type patchProvider struct {
+	calls   int
+	content string
+}
+
+func (p *patchProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.calls++
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.content}, Done: true}, nil
+}
+
+func (p *patchProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *patchProvider) GetModelInfo(model string) (*llm.ModelInfo, error) {
+	return &llm.ModelInfo{Name: model}, nil
+}
+
+func (p *patchProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+func TestRunFixLoop(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not available")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	makefile := "build:\n\t@true\ntest:\n\t@grep -q fixed status.txt || (echo 'status.txt:1: not fixed' && exit 1)\n"
+	if err := os.WriteFile("Makefile", []byte(makefile), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("status.txt", []byte("broken\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &patchProvider{content: "Fixed the status.\n<FILE path=\"status.txt\">\n```\nfixed\n```\n</FILE>"}
+	manager := NewInteractiveSessionManager(nil, provider, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	report, err := manager.RunFixLoop(context.Background(), session.ID)
+	if err != nil {
+		t.Fatalf("RunFixLoop failed: %v", err)
+	}
+	if !report.Success {
+		t.Fatalf("expected success, got %+v", report)
+	}
+	if len(report.Iterations) != 1 || provider.calls != 1 {
+		t.Errorf("expected one fix iteration, got %d (calls %d)", len(report.Iterations), provider.calls)
+	}
+	if iteration := report.Iterations[0]; len(iteration.Result.Failures) != 1 || iteration.Result.Failures[0].File != "status.txt" {
+		t.Errorf("unexpected failures: %+v", iteration.Result.Failures)
+	}
+
+	data, _ := os.ReadFile("status.txt")
+	if string(data) != "fixed\n" {
+		t.Errorf("expected patched content, got %q", string(data))
+	}
+	if !strings.Contains(report.Summary(), "1 回の修正") {
+		t.Errorf("unexpected summary: %s", report.Summary())
+	}
+}
+
+func TestRunFixLoop_MaxIterations(t *testing.T) {
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not available")
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.WriteFile("Makefile", []byte("test:\n\t@echo 'main.c:3:1: error: always broken' && exit 1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	provider := &patchProvider{content: "<FILE path=\"main.c\">\nint main(void) { return 0; }\n</FILE>"}
+	manager := NewInteractiveSessionManager(nil, provider, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatalf("CreateSession failed: %v", err)
+	}
+
+	report, err := manager.RunFixLoop(context.Background(), session.ID)
+	if err != nil {
+		t.Fatalf("RunFixLoop failed: %v", err)
+	}
+	if report.Success {
+		t.Fatal("expected failure")
+	}
+	maxIterations := manager.fixLoopConfig().MaxIterations
+	if len(report.Iterations) != maxIterations || provider.calls != maxIterations {
+		t.Errorf("expected %d iterations, got %d (calls %d)", maxIterations, len(report.Iterations), provider.calls)
+	}
+	if report.StopReason == "" || report.Final == nil || report.Final.Success {
+		t.Errorf("unexpected final state: %+v", report)
+	}
+}
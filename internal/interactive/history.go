@@ -0,0 +1,16 @@
+package interactive
+
+import (
+	"github.com/glkt/vyb-code/internal/history"
+)
+
+// QuoteHistory は過去のセッションのやり取りを次の応答のコンテキストとしてセッションに追加
+func (ism *interactiveSessionManager) QuoteHistory(sessionID string, exchanges ...history.Exchange) error {
+	if _, err := ism.GetSession(sessionID); err != nil {
+		return err
+	}
+	for _, exchange := range exchanges {
+		ism.addToSmartContext(sessionID, exchange.Quote(), "history_quote")
+	}
+	return nil
+}
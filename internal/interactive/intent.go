@@ -0,0 +1,168 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// analyzeUserIntent はClaude Code式簡素化された意図解析（安全性チェック中心）
+func (ism *interactiveSessionManager) analyzeUserIntent(
+	ctx context.Context,
+	session *InteractiveSession,
+	input string,
+) (string, error) {
+	// Claude Code式: モデル自体の判断を優先、最低限の分類のみ
+	lowerInput := strings.ToLower(input)
+
+	// 危険なコマンドの基本チェック（安全性のため）
+	if strings.Contains(lowerInput, "rm -rf") || strings.Contains(lowerInput, "sudo") ||
+		strings.Contains(lowerInput, "delete") && strings.Contains(lowerInput, "all") {
+		return "potentially_dangerous", nil
+	}
+
+	// 基本的な要求タイプの大まかな分類（モデル判断の補助程度）
+	if strings.Contains(lowerInput, "作って") || strings.Contains(lowerInput, "作成") ||
+		strings.Contains(lowerInput, "実装") || strings.Contains(lowerInput, "create") {
+		return "creation_request", nil
+	}
+
+	// その他はすべて一般的な要求として扱う（モデルが詳細判断）
+	return "general_request", nil
+}
+
+// advanceConversationFlow は会話フローを進行
+func (ism *interactiveSessionManager) advanceConversationFlow(
+	session *InteractiveSession,
+	intent string,
+) error {
+	flow, exists := ism.loadConversationFlow(session.ID)
+	if !exists {
+		return fmt.Errorf("会話フローが見つかりません")
+	}
+
+	// 現在のステップを完了
+	now := time.Now()
+	flow.CurrentStep.EndTime = &now
+	flow.CurrentStep.Success = true
+	flow.StepHistory = append(flow.StepHistory, flow.CurrentStep)
+	flow.CompletedSteps++
+
+	// 次のステップを決定
+	nextStepType := ism.determineNextFlowStep(flow.CurrentStep.StepType, intent)
+	flow.CurrentStep = FlowStep{
+		StepID:      fmt.Sprintf("step_%d", time.Now().UnixNano()),
+		StepType:    nextStepType,
+		Description: ism.getStepDescription(nextStepType),
+		StartTime:   now,
+	}
+
+	// 進捗更新
+	flow.Progress = float64(flow.CompletedSteps) / float64(flow.EstimatedSteps)
+	if flow.Progress > 1.0 {
+		flow.Progress = 1.0
+	}
+
+	return ism.saveConversationFlow(session.ID, flow)
+}
+
+// determineNextFlowStep は次のフローステップを決定
+func (ism *interactiveSessionManager) determineNextFlowStep(
+	currentStep FlowStepType,
+	intent string,
+) FlowStepType {
+	switch currentStep {
+	case FlowStepTypeUnderstanding:
+		return FlowStepTypeAnalysis
+	case FlowStepTypeAnalysis:
+		return FlowStepTypePlanning
+	case FlowStepTypePlanning:
+		return FlowStepTypeImplementation
+	case FlowStepTypeImplementation:
+		return FlowStepTypeTesting
+	case FlowStepTypeTesting:
+		return FlowStepTypeVerification
+	default:
+		return FlowStepTypeCompletion
+	}
+}
+
+// getStepDescription はステップの説明を取得
+func (ism *interactiveSessionManager) getStepDescription(stepType FlowStepType) string {
+	switch stepType {
+	case FlowStepTypeUnderstanding:
+		return "要求の理解"
+	case FlowStepTypeAnalysis:
+		return "コード分析"
+	case FlowStepTypePlanning:
+		return "実装計画"
+	case FlowStepTypeImplementation:
+		return "実装"
+	case FlowStepTypeTesting:
+		return "テスト"
+	case FlowStepTypeVerification:
+		return "検証"
+	case FlowStepTypeCompletion:
+		return "完了"
+	default:
+		return "不明"
+	}
+}
+
+// performCognitiveReasoning はCognitiveEngineを活用した高度な推論を実行
+func (ism *interactiveSessionManager) performCognitiveReasoning(ctx context.Context, input, intent string) map[string]interface{} {
+	if ism.cognitiveEngine == nil {
+		return map[string]interface{}{
+			"status": "cognitive_engine_unavailable",
+		}
+	}
+
+	// タイムアウト付きで推論を実行
+	reasoningCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	reasoningCtx, release, err := ism.acquireAnalysis(reasoningCtx)
+	if err != nil {
+		return map[string]interface{}{
+			"status": "reasoning_skipped",
+			"error":  err.Error(),
+		}
+	}
+	defer release()
+
+	// 推論を実行
+	reasoningResult, err := ism.cognitiveEngine.ProcessUserInput(reasoningCtx, input)
+	if err != nil {
+		return map[string]interface{}{
+			"status": "reasoning_failed",
+			"error":  err.Error(),
+		}
+	}
+
+	// 推論結果を分析
+	insights := map[string]interface{}{
+		"status":          "reasoning_completed",
+		"confidence":      reasoningResult.Confidence,
+		"processing_time": reasoningResult.ProcessingTime,
+	}
+
+	// 推論チェーンの情報を追加
+	if len(reasoningResult.InferenceChains) > 0 {
+		insights["inference_chains_count"] = len(reasoningResult.InferenceChains)
+		// 推論チェーンの詳細は必要に応じて追加
+	}
+
+	// 学習的洞察があれば追加
+	if len(reasoningResult.Insights) > 0 {
+		insights["learning_insights_count"] = len(reasoningResult.Insights)
+	}
+
+	// 選択された解決策の情報
+	if reasoningResult.SelectedSolution != nil {
+		insights["solution_approach"] = reasoningResult.SelectedSolution.Approach
+		insights["solution_confidence"] = reasoningResult.SelectedSolution.Confidence
+	}
+
+	return insights
+}
@@ -0,0 +1,134 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/jobs"
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// モデルに返すジョブ出力の上限
+const jobOutputMaxBytes = 4 * 1024
+
+// ジョブ操作の構造化タグ
+var (
+	jobStartRegex  = regexp.MustCompile(`<JOBSTART>(.*?)</JOBSTART>`)
+	jobOutputRegex = regexp.MustCompile(`<JOBOUTPUT>(.*?)</JOBOUTPUT>`)
+	jobKillRegex   = regexp.MustCompile(`<JOBKILL>(.*?)</JOBKILL>`)
+)
+
+// StartJob はコマンドをバックグラウンドジョブとして起動
+func (ism *interactiveSessionManager) StartJob(ctx context.Context, sessionID string, command string) (jobs.Info, error) {
+	if _, err := ism.GetSession(sessionID); err != nil {
+		return jobs.Info{}, err
+	}
+
+	if err := budget.UseTool(ctx, "job: "+command); err != nil {
+		return jobs.Info{}, err
+	}
+
+	startTime := time.Now()
+	info, err := ism.jobManager.Start(command)
+	ctx = logger.WithAuditSession(ctx, sessionID)
+	if err != nil {
+		auditCommand(ctx, command, "", startTime, err)
+		return info, err
+	}
+	auditCommand(ctx, command, fmt.Sprintf("background job %d (pid %d)", info.ID, info.PID), startTime, nil)
+	return info, nil
+}
+
+// Jobs はバックグラウンドジョブの一覧を返す
+func (ism *interactiveSessionManager) Jobs() []jobs.Info {
+	return ism.jobManager.List()
+}
+
+// JobOutput はジョブの状態と直近の出力を返す
+func (ism *interactiveSessionManager) JobOutput(id int, maxBytes int) (jobs.Info, string, error) {
+	info, err := ism.jobManager.Get(id)
+	if err != nil {
+		return info, "", err
+	}
+	output, err := ism.jobManager.Tail(id, maxBytes)
+	return info, output, err
+}
+
+// KillJob はジョブを停止
+func (ism *interactiveSessionManager) KillJob(id int) (jobs.Info, error) {
+	return ism.jobManager.Kill(id)
+}
+
+// StopJobs は実行中のジョブをすべて停止（チャット・vyb run の終了時）
+// 拡張のイベント受信プロセスもここで終了させる
+func (ism *interactiveSessionManager) StopJobs() {
+	ism.jobManager.StopAll()
+	// 生成中のセッションの題名は保存先を閉じる前に記録する
+	ism.titleWG.Wait()
+	if ism.extensions != nil {
+		ism.extensions.Close()
+	}
+}
+
+// executeJobTags はLLM応答中のジョブ操作タグを実行し、結果と実行内容を返す
+func (ism *interactiveSessionManager) executeJobTags(ctx context.Context, session *InteractiveSession, llmResponse string) ([]string, []string) {
+	var results, actions []string
+
+	for _, match := range jobStartRegex.FindAllStringSubmatch(llmResponse, -1) {
+		command := strings.TrimSpace(match[1])
+		info, err := ism.StartJob(ctx, session.ID, command)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ジョブ起動エラー: %v", err))
+		} else {
+			results = append(results, fmt.Sprintf("🚀 ジョブ %d を起動しました: `%s` (<JOBOUTPUT>%d</JOBOUTPUT> で出力を確認)", info.ID, command, info.ID))
+		}
+		actions = append(actions, fmt.Sprintf("ジョブ起動: %s", command))
+	}
+
+	for _, match := range jobOutputRegex.FindAllStringSubmatch(llmResponse, -1) {
+		id, err := strconv.Atoi(strings.TrimSpace(match[1]))
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ 無効なジョブIDです: %s", match[1]))
+			continue
+		}
+		info, output, err := ism.JobOutput(id, jobOutputMaxBytes)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ジョブ出力取得エラー: %v", err))
+		} else {
+			results = append(results, fmt.Sprintf("📋 ジョブ %d (%s):\n%s", id, formatJobStatus(info), output))
+			session.LastCommandOutput = output
+			ism.rememberResult(session.ID, "job", fmt.Sprintf("job %d: %s", id, info.Command), output, true)
+		}
+		actions = append(actions, fmt.Sprintf("ジョブ出力確認: %d", id))
+	}
+
+	for _, match := range jobKillRegex.FindAllStringSubmatch(llmResponse, -1) {
+		id, err := strconv.Atoi(strings.TrimSpace(match[1]))
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ 無効なジョブIDです: %s", match[1]))
+			continue
+		}
+		info, err := ism.KillJob(id)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ジョブ停止エラー: %v", err))
+		} else {
+			results = append(results, fmt.Sprintf("🛑 ジョブ %d を停止しました (%s)", id, formatJobStatus(info)))
+		}
+		actions = append(actions, fmt.Sprintf("ジョブ停止: %d", id))
+	}
+
+	return results, actions
+}
+
+// formatJobStatus はジョブの状態を短く表記
+func formatJobStatus(info jobs.Info) string {
+	if info.Status == jobs.StatusExited {
+		return fmt.Sprintf("%s, exit code %d", info.Status, info.ExitCode)
+	}
+	return string(info.Status)
+}
@@ -0,0 +1,39 @@
+package interactive
+
+import (
+	"os"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/knowledge"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// knowledgeConfig は言語・フレームワーク別の指針の設定を返す
+func (ism *interactiveSessionManager) knowledgeConfig() config.KnowledgeConfig {
+	if ism.config == nil {
+		return config.DefaultKnowledgeConfig()
+	}
+	return ism.config.Knowledge
+}
+
+// guideData は作業ディレクトリのプロジェクトで検出した指針をプロンプトのパラメーターにする（無効なら nil）
+// マニフェスト・.vyb/knowledge の変更を反映するため毎ターン検出する
+func (ism *interactiveSessionManager) guideData() []prompts.Guide {
+	cfg := ism.knowledgeConfig()
+	if !cfg.Enabled {
+		return nil
+	}
+	projectDir, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+
+	var guides []prompts.Guide
+	for _, guide := range knowledge.Detect(projectDir, cfg.Disabled) {
+		if cfg.MaxGuides > 0 && len(guides) >= cfg.MaxGuides {
+			break
+		}
+		guides = append(guides, prompts.Guide{Name: guide.Name, Guidance: guide.Guidance})
+	}
+	return guides
+}
@@ -0,0 +1,53 @@
+package interactive
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// TestGuideData は検出したフレームワークの指針をプロンプトに加え、設定で除けることをテストする
+func TestGuideData(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.WriteFile("go.mod", []byte("module example.com/cli\n\nrequire github.com/spf13/cobra v1.8.0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.DefaultConfig()
+	manager := NewInteractiveSessionManager(nil, nil, nil, nil, nil, "test-model", cfg).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := manager.interactivePromptData(session, "")
+	if len(data.Guides) != 2 || data.Guides[0].Name != "go-cobra" || data.Guides[1].Name != "go" {
+		t.Fatalf("Guides = %+v", data.Guides)
+	}
+	prompt := manager.renderInteractivePrompt(data)
+	if !strings.Contains(prompt, "## 🧩 Framework Guidance") || !strings.Contains(prompt, "### go-cobra") || !strings.Contains(prompt, "RunE") {
+		t.Errorf("指針がプロンプトにありません:\n%s", prompt)
+	}
+
+	cfg.Knowledge.MaxGuides = 1
+	if guides := manager.guideData(); len(guides) != 1 || guides[0].Name != "go-cobra" {
+		t.Errorf("最大数を超える言語の指針は除くはず: %+v", guides)
+	}
+	cfg.Knowledge.Disabled = []string{"go-cobra"}
+	if guides := manager.guideData(); len(guides) != 1 || guides[0].Name != "go" {
+		t.Errorf("disabled の指針は除くはず: %+v", guides)
+	}
+	cfg.Knowledge.Enabled = false
+	if guides := manager.guideData(); guides != nil {
+		t.Errorf("無効なら指針を加えないはず: %+v", guides)
+	}
+}
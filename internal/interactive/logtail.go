@@ -0,0 +1,89 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// logTailTagPattern は応答中のログの追跡の要求（<LOGTAIL>logs/server.log seconds=30</LOGTAIL> 等）
+var logTailTagPattern = regexp.MustCompile(`<LOGTAIL>(.*?)</LOGTAIL>`)
+
+// executeLogTailTags は応答中の <LOGTAIL> を実行し、ログの末尾と追っている間に追記された行を返す
+// 引数はファイルのパスまたは unit:名前（journalctl）、seconds=N（追う秒数）、lines=N（末尾の行数）、
+// max=N（追う間に集める最大行数）、grep=正規表現
+func (ism *interactiveSessionManager) executeLogTailTags(ctx context.Context, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range logTailTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		opts, err := parseLogTailSpec(match[1])
+		source := opts.Path
+		if opts.Unit != "" {
+			source = "unit:" + opts.Unit
+		}
+		actions = append(actions, fmt.Sprintf("ログの追跡: %s", source))
+		if err == nil {
+			err = budget.UseTool(ctx, "log_tail: "+source)
+		}
+		if err == nil && opts.Path != "" {
+			opts.Path, err = security.NewPathJail(".").Resolve(opts.Path)
+			if err == nil && ism.permissions != nil {
+				err = ism.permissions.CheckPath(opts.Path, "read")
+			}
+		}
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ログの追跡エラー (%s): %v", source, err))
+			continue
+		}
+
+		if opts.Follow > 0 {
+			fmt.Printf("\033[38;5;244m👀 Watching %s for %s...\033[0m\n", source, opts.Follow)
+		}
+		result, err := tools.TailLog(ctx, opts)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ログの追跡エラー (%s): %v", source, err))
+			continue
+		}
+		results = append(results, "📜 "+result.Format())
+	}
+	return results, actions
+}
+
+// parseLogTailSpec は <LOGTAIL> の引数をログの追跡の指定にする（未知の語はパスとして扱う）
+func parseLogTailSpec(spec string) (tools.LogTailOptions, error) {
+	var opts tools.LogTailOptions
+	number := func(word, prefix string) int {
+		n, _ := strconv.Atoi(strings.TrimPrefix(word, prefix))
+		return n
+	}
+	for _, word := range strings.Fields(spec) {
+		switch {
+		case strings.HasPrefix(word, "unit:"):
+			opts.Unit = strings.TrimPrefix(word, "unit:")
+		case strings.HasPrefix(word, "seconds="):
+			opts.Follow = time.Duration(number(word, "seconds=")) * time.Second
+		case strings.HasPrefix(word, "lines="):
+			opts.Lines = number(word, "lines=")
+		case strings.HasPrefix(word, "max="):
+			opts.MaxLines = number(word, "max=")
+		case strings.HasPrefix(word, "grep="):
+			filter, err := regexp.Compile(strings.TrimPrefix(word, "grep="))
+			if err != nil {
+				return opts, fmt.Errorf("grep の正規表現が不正です: %w", err)
+			}
+			opts.Filter = filter
+		default:
+			opts.Path = word
+		}
+	}
+	if opts.Path == "" && opts.Unit == "" {
+		return opts, fmt.Errorf("ログファイルか unit:名前 を指定してください")
+	}
+	return opts, nil
+}
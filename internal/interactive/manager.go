@@ -2,8 +2,8 @@ package interactive
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -12,39 +12,113 @@ import (
 
 	"github.com/glkt/vyb-code/internal/ai"
 	"github.com/glkt/vyb-code/internal/analysis"
+	"github.com/glkt/vyb-code/internal/branch"
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/checkpoint"
 	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/jobs"
 	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/plugins"
+	"github.com/glkt/vyb-code/internal/prompts"
 	"github.com/glkt/vyb-code/internal/reasoning"
+	"github.com/glkt/vyb-code/internal/recall"
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/remote"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/scheduler"
 	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/storage"
 	"github.com/glkt/vyb-code/internal/tools"
-	"github.com/glkt/vyb-code/internal/ui"
+	"github.com/glkt/vyb-code/internal/transcript"
 )
 
 // インタラクティブセッション管理実装
 type interactiveSessionManager struct {
-	mu                sync.RWMutex
-	sessions          map[string]*InteractiveSession
-	contextManager    contextmanager.ContextManager
-	llmProvider       llm.Provider
-	aiService         *ai.AIService    // AI機能統合サービス
-	editTool          *tools.EditTool  // ファイル編集ツール
-	writeTool         *tools.WriteTool // ファイル書き込みツール
-	bashTool          *tools.BashTool  // コマンド実行ツール
-	vibeConfig        *VibeConfig
-	activeSessions    map[string]time.Time // セッション活性状況追跡
-	sessionMetrics    map[string]*SessionMetrics
-	conversationFlows map[string]*ConversationFlow
-	proactiveExt      *ProactiveExtension // プロアクティブ拡張
-	modelName         string              // 設定されたモデル名
+	mu             sync.RWMutex
+	sessions       map[string]*InteractiveSession
+	contextManager contextmanager.ContextManager
+	llmProvider    llm.Provider
+	aiService      *ai.AIService         // AI機能統合サービス
+	editTool       *tools.EditTool       // ファイル編集ツール
+	writeTool      *tools.WriteTool      // ファイル書き込みツール
+	patchTool      *tools.ApplyPatchTool // unified diff のパッチ適用ツール
+	fileOpsTool    *tools.FileOpsTool    // ファイルの移動・削除ツール
+	bashTool       *tools.BashTool       // コマンド実行ツール
+	vibeConfig     *VibeConfig
+	activeSessions map[string]time.Time // セッション活性状況追跡
+	proactiveExt   *ProactiveExtension  // プロアクティブ拡張
+	modelName      string               // 設定されたモデル名
+
+	// セッション・メトリクス・会話フロー・会話の保存先（SetStore、既定はメモリ）
+	store         storage.Store
+	storeWarnOnce sync.Once
 
 	// 科学的認知分析システム統合
 	cognitiveAnalyzer *analysis.CognitiveAnalyzer
-	cognitiveEngine   *reasoning.CognitiveEngine
-	config            *config.Config
+	// 統合分析器（プロジェクト分析の結果をキャッシュするため使い回す）
+	unifiedAnalyzer     *analysis.UnifiedAnalyzer
+	unifiedAnalyzerOnce sync.Once
+	cognitiveEngine     *reasoning.CognitiveEngine
+	config              *config.Config
 
 	// Claude Code風ツール実行フロー
 	executionFlow *tools.ExecutionFlow
+	toolRegistry  *tools.UnifiedToolRegistry
+
+	// ビルド・テスト・リント実行用のバックエンド（nilならホスト実行）
+	execBackend sandbox.Backend
+
+	// 自動修正ループで検証待ちの変更ファイル（セッションID別）
+	modifiedFiles map[string][]string
+
+	// プロンプトテンプレート（~/.vyb/prompts で上書き可能）
+	promptRegistry *prompts.Registry
+
+	// セッションから起動したバックグラウンドジョブ
+	jobManager *jobs.Manager
+
+	// ターン毎のワークスペースチェックポイント（nilなら無効）
+	checkpointStore *checkpoint.Store
+	checkpoints     map[string]*sessionCheckpoints
+
+	// エクスポート用の会話記録（セッションID別）
+	transcripts map[string]*transcript.Transcript
+
+	// 会話の分岐ツリーと現在の分岐に入った時刻（/branch、セッションID別）
+	branches        map[string]*branch.Tree
+	branchEnteredAt map[string]time.Time
+
+	// 読み込んだサードパーティ拡張
+	extensions *plugins.Extensions
+
+	// ツール層を置き換えたリモートエージェント（vyb --remote、nilならローカル）
+	remote *remote.Client
+
+	// LLMリクエスト・ツール実行・分析の同時実行数の制限（nilなら制限しない）
+	scheduler *scheduler.Scheduler
+
+	// 提案の根拠（/why）とターン中に取得したコンテキスト（セッションID別）
+	provenanceMu     sync.Mutex
+	provenance       map[string][]*SuggestionProvenance
+	retrievedContext map[string][]ProvenanceContext
+
+	// 応答生成パイプラインの段（pipeline.stages で差し替え可能）
+	pipeline Stages
+
+	// プロジェクト・ユーザーの許可規則（規則がなければnil）
+	permissions *security.Permissions
+
+	// 題名を生成済み・生成中・設定済みのセッションと、生成中の goroutine
+	titled  map[string]bool
+	titleWG sync.WaitGroup
+
+	// コマンド・ツールの実行結果の記憶（<RECALL>、セッションID別）
+	recallMu     sync.Mutex
+	recallStores map[string]*recall.Store
 }
 
 // NewInteractiveSessionManager は新しいインタラクティブセッション管理を作成
@@ -61,33 +135,79 @@ func NewInteractiveSessionManager(
 		vibeConfig = DefaultVibeConfig()
 	}
 
+	// プロジェクト（.vyb/permissions.yaml）・ユーザー設定の許可規則を各ツールの制約に適用
+	permissions := loadPermissions(cfg)
+
 	// WriteToolを初期化（セキュリティ制約とパス設定）
+	writeConstraints := security.NewDefaultConstraints(".") // デフォルト制約
+	writeConstraints.Permissions = permissions
 	writeTool := tools.NewWriteTool(
-		security.NewDefaultConstraints("."), // デフォルト制約
-		".",                                 // 現在のディレクトリ
-		10*1024*1024,                        // 10MB制限
+		writeConstraints,
+		".",          // 現在のディレクトリ
+		10*1024*1024, // 10MB制限
 	)
+	patchTool := tools.NewApplyPatchTool(writeConstraints, ".", 10*1024*1024)
+	fileOpsTool := tools.NewFileOpsTool(writeConstraints, ".")
+	if editTool != nil {
+		editTool.SetPermissions(permissions)
+	}
+
+	// 設定に応じてネットワークポリシーを構築
+	var networkPolicy *security.NetworkPolicy
+	if cfg != nil {
+		networkPolicy = security.NewNetworkPolicy(
+			cfg.Network.Mode,
+			cfg.Network.AllowedDomains,
+			cfg.Network.BlockedDomains,
+		)
+		networkPolicy.SetAllowLoopback(cfg.Network.AllowLoopback)
+		if cfg.Network.AuditRequests {
+			networkPolicy.SetAuditLogger(security.NewAuditLogger())
+		}
+	}
 
 	// BashToolを初期化（コマンド実行用）
+	bashConstraints := security.NewDefaultConstraints(".") // デフォルト制約
+	bashConstraints.NetworkPolicy = networkPolicy
+	bashConstraints.Permissions = permissions
 	bashTool := tools.NewBashTool(
-		security.NewDefaultConstraints("."), // デフォルト制約
-		".",                                 // 現在のディレクトリ
+		bashConstraints,
+		".", // 現在のディレクトリ
 	)
 
+	// 設定に応じてサンドボックス実行バックエンドを適用
+	var execBackend sandbox.Backend
+	if cfg != nil {
+		if backend, err := sandbox.New(cfg.Sandbox); err == nil {
+			execBackend = backend
+			bashTool.SetBackend(backend)
+		}
+	}
+
 	manager := &interactiveSessionManager{
-		sessions:          make(map[string]*InteractiveSession),
-		contextManager:    contextManager,
-		llmProvider:       llmProvider,
-		aiService:         aiService,
-		editTool:          editTool,
-		writeTool:         writeTool,
-		bashTool:          bashTool,
-		vibeConfig:        vibeConfig,
-		activeSessions:    make(map[string]time.Time),
-		sessionMetrics:    make(map[string]*SessionMetrics),
-		conversationFlows: make(map[string]*ConversationFlow),
-		modelName:         modelName,
-		config:            cfg,
+		sessions:        make(map[string]*InteractiveSession),
+		contextManager:  contextManager,
+		llmProvider:     llmProvider,
+		aiService:       aiService,
+		editTool:        editTool,
+		writeTool:       writeTool,
+		patchTool:       patchTool,
+		fileOpsTool:     fileOpsTool,
+		bashTool:        bashTool,
+		vibeConfig:      vibeConfig,
+		activeSessions:  make(map[string]time.Time),
+		store:           storage.NewMemoryStore(),
+		modelName:       modelName,
+		config:          cfg,
+		execBackend:     execBackend,
+		modifiedFiles:   make(map[string][]string),
+		promptRegistry:  prompts.DefaultRegistry(),
+		checkpointStore: newCheckpointStore(cfg != nil && cfg.Checkpoints.Enabled),
+		jobManager:      jobs.NewManager(execBackend, bashConstraints, "."),
+		checkpoints:     make(map[string]*sessionCheckpoints),
+		transcripts:     make(map[string]*transcript.Transcript),
+		permissions:     permissions,
+		titled:          make(map[string]bool),
 	}
 
 	// 科学的認知分析システム初期化
@@ -108,12 +228,59 @@ func NewInteractiveSessionManager(
 			security.NewDefaultConstraints("."),
 			nil, // MCPマネージャーは必要に応じて初期化
 		)
-		manager.executionFlow = tools.NewExecutionFlow(toolRegistry, cfg, security.NewDefaultConstraints("."))
+		toolRegistry.SetExecutionBackend(execBackend)
+		toolRegistry.SetNetworkPolicy(networkPolicy)
+		toolRegistry.SetPermissions(permissions)
+		if err := toolRegistry.ConfigureWebTools(cfg.WebTools); err != nil {
+			fmt.Printf("Warning: Webツール設定エラー: %v\n", err)
+		}
+		// サードパーティ拡張のツール・イベント購読者を登録
+		manager.extensions = plugins.LoadExtensions(&plugins.Host{Bus: events.Default(), Tools: toolRegistry}, cfg.Extensions, ".")
+		for name, err := range manager.extensions.Errors {
+			fmt.Printf("Warning: 拡張 %s の読み込みエラー: %v\n", name, err)
+		}
+		flowConstraints := security.NewDefaultConstraints(".")
+		flowConstraints.Permissions = permissions
+		manager.executionFlow = tools.NewExecutionFlow(toolRegistry, cfg, flowConstraints)
+		manager.toolRegistry = toolRegistry
 	}
 
+	// 応答生成パイプラインを構成
+	manager.pipeline = manager.buildPipeline()
+
 	return manager
 }
 
+// ToolNames は入力補完用のツール名と説明の一覧（ツール未初期化なら nil）
+func (ism *interactiveSessionManager) ToolNames() map[string]string {
+	if ism.toolRegistry == nil {
+		return nil
+	}
+	return ism.toolRegistry.ToolNames()
+}
+
+// SetExecutionObserver はツール実行イベントの通知先を設定
+func (ism *interactiveSessionManager) SetExecutionObserver(observer tools.ExecutionObserver) {
+	if ism.executionFlow != nil {
+		ism.executionFlow.SetObserver(observer)
+	}
+}
+
+// SetExecutionBackend はコマンド・ツール・タスク・バックグラウンドジョブの実行バックエンドを差し替える
+// セッションの処理を始める前に呼ぶ（vyb replay が記録した出力を返すバックエンドで再実行するために使う）
+func (ism *interactiveSessionManager) SetExecutionBackend(backend sandbox.Backend) {
+	if backend == nil {
+		return
+	}
+	ism.jobManager.StopAll()
+	ism.jobManager = jobs.NewManager(backend, security.NewDefaultConstraints("."), ".")
+	ism.execBackend = backend
+	ism.bashTool.SetBackend(backend)
+	if ism.toolRegistry != nil {
+		ism.toolRegistry.SetExecutionBackend(backend)
+	}
+}
+
 // formatToolExecutionResults はツール実行結果をフォーマット
 func (ism *interactiveSessionManager) formatToolExecutionResults(steps []tools.ExecutionStep) string {
 	var results strings.Builder
@@ -135,6 +302,50 @@ func (ism *interactiveSessionManager) formatToolExecutionResults(steps []tools.E
 	return results.String()
 }
 
+// addToolResultsToContext は構造化されたツール結果をコンテキスト項目として登録
+func (ism *interactiveSessionManager) addToolResultsToContext(sessionID string, steps []tools.ExecutionStep) {
+	if ism.contextManager == nil {
+		return
+	}
+
+	for _, step := range steps {
+		if step.Result == nil {
+			continue
+		}
+		bundle, ok := step.Result.Data.(*tools.BatchReadBundle)
+		if !ok {
+			continue
+		}
+		for _, item := range bundle.ContextItems() {
+			item.Metadata["session_id"] = sessionID
+			if err := ism.contextManager.AddContext(item); err != nil {
+				fmt.Printf("コンテキスト追加エラー: %v\n", err)
+			}
+		}
+	}
+}
+
+// recordToolModifications はファイルを変更したツール実行を検証待ちとして記録
+func (ism *interactiveSessionManager) recordToolModifications(sessionID string, steps []tools.ExecutionStep) {
+	for _, step := range steps {
+		if !step.Success || (step.Tool != "write" && step.Tool != "edit" && step.Tool != "multiedit") {
+			continue
+		}
+		if filePath, ok := step.Parameters["file_path"].(string); ok {
+			ism.recordFileModification(sessionID, filePath)
+		}
+	}
+}
+
+// publishEditApplied はツールを経由しないファイル変更（提案の適用・ファイル作成）をイベントバスに通知
+// （write・edit・multiedit ツールによる変更はツールレジストリが通知する）
+func publishEditApplied(sessionID, filePath, source string) {
+	if filePath == "" {
+		return
+	}
+	events.Publish(events.EditApplied, sessionID, map[string]interface{}{"path": filePath, "source": source})
+}
+
 // processUserInputWithToolResults はツール実行結果を含むLLM応答を生成
 func (ism *interactiveSessionManager) processUserInputWithToolResults(
 	ctx context.Context,
@@ -212,10 +423,19 @@ func DefaultVibeConfig() *VibeConfig {
 
 // CreateSession は新しいインタラクティブセッションを作成
 func (ism *interactiveSessionManager) CreateSession(sessionType CodingSessionType) (*InteractiveSession, error) {
+	sessionID := fmt.Sprintf("session_%d", time.Now().UnixNano())
+	// ロック解除後に通知する（購読者がマネージャーを呼んでも詰まらないように）
+	var focus string
+	defer func() {
+		if note := ism.remoteContextNote(); note != "" {
+			ism.addToSmartContext(sessionID, note, "remote")
+		}
+		events.Publish(events.SessionStarted, sessionID, map[string]interface{}{"focus": focus})
+	}()
+
 	ism.mu.Lock()
 	defer ism.mu.Unlock()
 
-	sessionID := fmt.Sprintf("session_%d", time.Now().UnixNano())
 	now := time.Now()
 
 	session := &InteractiveSession{
@@ -261,13 +481,14 @@ func (ism *interactiveSessionManager) CreateSession(sessionType CodingSessionTyp
 		session.SessionMetadata["focus"] = "general"
 		session.SessionMetadata["priority"] = "development"
 	}
+	focus = session.SessionMetadata["focus"]
 
 	ism.sessions[sessionID] = session
 	ism.activeSessions[sessionID] = now
-	ism.sessionMetrics[sessionID] = session.Metrics
+	ism.persistSession(session, false)
 
 	// 会話フローの初期化
-	ism.conversationFlows[sessionID] = &ConversationFlow{
+	ism.reportStoreError(ism.saveConversationFlow(sessionID, &ConversationFlow{
 		CurrentStep:    FlowStep{StepType: FlowStepTypeUnderstanding, StartTime: now},
 		StepHistory:    make([]FlowStep, 0),
 		Progress:       0.0,
@@ -275,7 +496,7 @@ func (ism *interactiveSessionManager) CreateSession(sessionType CodingSessionTyp
 		CompletedSteps: 0,
 		NextSteps:      []string{"ユーザーの目標を理解する"},
 		FlowMetadata:   make(map[string]string),
-	}
+	}))
 
 	return session, nil
 }
@@ -287,7 +508,7 @@ func (ism *interactiveSessionManager) GetSession(sessionID string) (*Interactive
 
 	session, exists := ism.sessions[sessionID]
 	if !exists {
-		return nil, fmt.Errorf("セッション %s が見つかりません", sessionID)
+		return nil, i18n.Errorf("error.session_not_found", sessionID)
 	}
 
 	// アクティビティ更新
@@ -306,13 +527,17 @@ func (ism *interactiveSessionManager) UpdateSession(session *InteractiveSession)
 
 	session.LastActivity = time.Now()
 	ism.sessions[session.ID] = session
-	ism.sessionMetrics[session.ID] = session.Metrics
+	ism.persistSession(session, false)
 
 	return nil
 }
 
 // CloseSession はセッションを終了
 func (ism *interactiveSessionManager) CloseSession(sessionID string) error {
+	ism.discardCheckpoints(sessionID)
+	// 生成中の題名を記録に残す
+	ism.titleWG.Wait()
+
 	ism.mu.Lock()
 	defer ism.mu.Unlock()
 
@@ -326,17 +551,20 @@ func (ism *interactiveSessionManager) CloseSession(sessionID string) error {
 	session.LastActivity = time.Now()
 
 	// 会話フロー完了
-	if flow, flowExists := ism.conversationFlows[sessionID]; flowExists {
+	if flow, flowExists := ism.loadConversationFlow(sessionID); flowExists {
 		now := time.Now()
 		flow.CurrentStep.EndTime = &now
 		flow.Progress = 1.0
 		flow.CompletedSteps = flow.EstimatedSteps
+		ism.reportStoreError(ism.saveConversationFlow(sessionID, flow))
 	}
 
-	// リソースクリア（但し、メトリクスは保持）
+	// リソースクリア（メトリクス・会話フローは保存先に残す）
+	ism.persistSession(session, true)
 	delete(ism.sessions, sessionID)
 	delete(ism.activeSessions, sessionID)
-	delete(ism.conversationFlows, sessionID)
+	delete(ism.titled, sessionID)
+	ism.forgetResults(sessionID)
 
 	return nil
 }
@@ -448,10 +676,55 @@ func (ism *interactiveSessionManager) GenerateCodeSuggestion(
 	startTime := time.Now()
 	session.State = SessionStateProcessing
 
+	// 対象ファイルの指定がなければ固定したファイルを対象にする
+	if request.FilePath == "" && len(session.PinnedFiles) > 0 {
+		request.FilePath = session.PinnedFiles[0]
+		if request.Code == "" {
+			if data := pinnedFileData(session.PinnedFiles[:1]); data[0].Content != "" {
+				request.Code = data[0].Content
+			}
+		}
+	}
+
+	suggestion, relevantContext, confidence, err := ism.generateSuggestion(ctx, session, request)
+	if err != nil {
+		return nil, err
+	}
+	approval := ism.evaluateApproval(ctx, suggestion)
+	ism.recordProvenance(ctx, session, suggestion, request.UserDescription, relevantContext, confidence)
+
+	// セッション状態更新（適用時に変更を検出できるよう対象ファイルの内容を記録）
+	ism.snapshotSuggestionBase(suggestion)
+	session.State = SessionStateWaitingForConfirmation
+	session.PendingSuggestion = suggestion
+	session.Metrics.CodeSuggestionsGiven++
+	session.Metrics.TotalInteractions++
+
+	// 自動承認ポリシーに一致した提案は確認せずに適用・破棄する（結果は suggestion.Applied と Metadata["approval"] に残る）
+	if err := ism.enforceApproval(ctx, session, suggestion, approval); err != nil {
+		return nil, fmt.Errorf("提案の自動適用エラー: %w", err)
+	}
+
+	responseTime := time.Since(startTime)
+	session.Metrics.AverageResponseTime = ism.updateAverageResponseTime(
+		session.Metrics.AverageResponseTime,
+		responseTime,
+		session.Metrics.TotalInteractions,
+	)
+
+	return suggestion, nil
+}
+
+// generateSuggestion はモデルに提案を問い合わせ、応答を解析して信頼度・影響を評価する（提案の保留・承認は呼び出し側が行う）
+func (ism *interactiveSessionManager) generateSuggestion(
+	ctx context.Context,
+	session *InteractiveSession,
+	request *SuggestionRequest,
+) (*CodeSuggestion, []*contextmanager.ContextItem, ConfidenceExplanation, error) {
 	// 関連コンテキストを取得
-	relevantContext, err := ism.GetRelevantContext(sessionID, request.UserDescription, 10)
+	relevantContext, err := ism.GetRelevantContext(session.ID, request.UserDescription, 10)
 	if err != nil {
-		return nil, fmt.Errorf("関連コンテキスト取得エラー: %w", err)
+		return nil, nil, ConfidenceExplanation{}, fmt.Errorf("関連コンテキスト取得エラー: %w", err)
 	}
 
 	// LLMプロンプトの構築
@@ -472,33 +745,26 @@ func (ism *interactiveSessionManager) GenerateCodeSuggestion(
 	response, err := ism.llmProvider.Chat(ctx, chatReq)
 	if err != nil {
 		session.State = SessionStateError
-		return nil, fmt.Errorf("LLM応答生成エラー: %w", err)
+		return nil, nil, ConfidenceExplanation{}, fmt.Errorf("LLM応答生成エラー: %w", err)
 	}
 
 	// 応答からコード提案を抽出・構造化
 	suggestion, err := ism.parseSuggestionResponse(response.Message.Content, request)
 	if err != nil {
-		return nil, fmt.Errorf("提案解析エラー: %w", err)
+		return nil, nil, ConfidenceExplanation{}, fmt.Errorf("提案解析エラー: %w", err)
 	}
 
 	// 提案の信頼度とインパクト評価
-	suggestion.Confidence = ism.calculateSuggestionConfidence(session, request, relevantContext)
+	confidence := ism.explainSuggestionConfidence(session, request, relevantContext)
+	suggestion.Confidence = confidence.Score
 	suggestion.ImpactLevel = ism.evaluateImpactLevel(request)
-
-	// セッション状態更新
-	session.State = SessionStateWaitingForConfirmation
-	session.PendingSuggestion = suggestion
-	session.Metrics.CodeSuggestionsGiven++
-	session.Metrics.TotalInteractions++
-
-	responseTime := time.Since(startTime)
-	session.Metrics.AverageResponseTime = ism.updateAverageResponseTime(
-		session.Metrics.AverageResponseTime,
-		responseTime,
-		session.Metrics.TotalInteractions,
-	)
-
-	return suggestion, nil
+	if report := ism.suggestionImpact(ctx, suggestion); report != nil {
+		if suggestion.Metadata == nil {
+			suggestion.Metadata = make(map[string]string)
+		}
+		suggestion.Metadata["blast_radius"] = report.Summary()
+	}
+	return suggestion, relevantContext, confidence, nil
 }
 
 // buildSuggestionPrompt は提案用のプロンプトを構築
@@ -595,9 +861,11 @@ func (ism *interactiveSessionManager) ConfirmSuggestion(
 	}
 
 	session.LastActivity = time.Now()
+	events.Publish(events.SuggestionDecided, sessionID, map[string]interface{}{"accepted": accepted})
 
 	// ユーザー満足度の学習更新
 	ism.updateUserSatisfactionScore(session, accepted)
+	ism.persistSession(session, false)
 
 	return nil
 }
@@ -612,6 +880,7 @@ func (ism *interactiveSessionManager) ApplySuggestion(
 	if err != nil {
 		return err
 	}
+	ctx = logger.WithAuditSession(ctx, sessionID)
 
 	if session.PendingSuggestion == nil {
 		return fmt.Errorf("保留中の提案がありません")
@@ -641,11 +910,14 @@ func (ism *interactiveSessionManager) ApplySuggestion(
 
 			// BashToolでコマンド実行
 			if ism.bashTool != nil {
-				result, err := ism.bashTool.Execute(command, "ユーザー要求によるコマンド実行", 30000) // 30秒タイムアウト
+				startTime := time.Now()
+				result, err := ism.bashTool.ExecuteContext(ctx, command, "ユーザー要求によるコマンド実行", 30000) // 30秒タイムアウト
 				if err != nil {
+					auditCommand(ctx, command, "", startTime, err)
 					session.State = SessionStateError
 					return fmt.Errorf("コマンド実行エラー: %v", err)
 				}
+				auditCommand(ctx, command, result.Content, startTime, nil)
 
 				fmt.Printf("Debug: コマンド実行結果:\n%s\n", result.Content)
 				session.LastCommandOutput = result.Content
@@ -668,6 +940,12 @@ func (ism *interactiveSessionManager) ApplySuggestion(
 		}
 
 		if filePath != "" {
+			// 提案の作成後に対象ファイルが変わっていれば、古い内容を前提にした書き込みで壊さないよう止めて対処を選ばせる
+			if conflict := ism.checkSuggestionConflict(filePath, session.PendingSuggestion); conflict != nil {
+				session.State = SessionStateWaitingForConfirmation
+				auditFileWrite(ctx, filePath, "edit", len(suggestedCode), conflict)
+				return conflict
+			}
 			if session.PendingSuggestion.OriginalCode == "" {
 				// 新規ファイル作成
 				fmt.Printf("Debug: ファイル作成中: %s\n", filePath)
@@ -679,8 +957,11 @@ func (ism *interactiveSessionManager) ApplySuggestion(
 				result, err := ism.writeTool.Write(writeRequest)
 				if err != nil || result.IsError {
 					session.State = SessionStateError
-					return fmt.Errorf("ファイル作成エラー: %v", err)
+					err = fmt.Errorf("ファイル作成エラー: %v", err)
+					auditFileWrite(ctx, filePath, "write", len(suggestedCode), err)
+					return err
 				}
+				auditFileWrite(ctx, filePath, "write", len(suggestedCode), nil)
 
 				// 詳細な成功メッセージを表示
 				absPath, err := filepath.Abs(filePath)
@@ -694,23 +975,38 @@ func (ism *interactiveSessionManager) ApplySuggestion(
 			} else {
 				// 既存ファイル編集
 				editRequest := tools.EditRequest{
-					FilePath:  filePath,
-					OldString: session.PendingSuggestion.OriginalCode,
-					NewString: suggestedCode,
+					FilePath:     filePath,
+					OldString:    session.PendingSuggestion.OriginalCode,
+					NewString:    suggestedCode,
+					ExpectedHash: expectedHash(session.PendingSuggestion),
 				}
 
 				result, err := ism.editTool.Edit(editRequest)
+				if conflict := ism.staleEditConflict(err, filePath, session.PendingSuggestion); conflict != nil {
+					session.State = SessionStateWaitingForConfirmation
+					auditFileWrite(ctx, filePath, "edit", len(suggestedCode), conflict)
+					return conflict
+				}
 				if err != nil || result.IsError {
 					session.State = SessionStateError
-					return fmt.Errorf("ファイル編集エラー: %v", err)
+					err = fmt.Errorf("ファイル編集エラー: %v", err)
+					auditFileWrite(ctx, filePath, "edit", len(suggestedCode), err)
+					return err
 				}
+				auditFileWrite(ctx, filePath, "edit", len(suggestedCode), nil)
 			}
 		} else {
 			return fmt.Errorf("ファイルパスが特定できません")
 		}
 	}
 
+	if !ism.isCommandSuggestion(suggestedCode) {
+		ism.recordFileModification(sessionID, session.PendingSuggestion.FilePath)
+		publishEditApplied(sessionID, session.PendingSuggestion.FilePath, "suggestion")
+	}
+
 	session.PendingSuggestion.Applied = true
+	ism.markProvenanceApplied(sessionID, session.PendingSuggestion.ID)
 	session.State = SessionStateIdle
 	session.Metrics.FilesModified++
 
@@ -730,6 +1026,71 @@ func (ism *interactiveSessionManager) ProcessUserInput(
 	ctx context.Context,
 	sessionID string,
 	input string,
+) (*InteractionResponse, error) {
+	// 以降のLLM呼び出し・ツール実行をセッションの監査ログに記録
+	ctx = logger.WithAuditSession(ctx, sessionID)
+
+	// ツール実行回数・トークン数・経過時間を数え、上限を超えたらターンを打ち切る
+	tracker := budget.New(ism.turnLimits())
+	ctx = budget.WithTracker(ctx, tracker)
+	if limit := tracker.Limits().MaxWallTime; limit > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, limit)
+		defer cancel()
+	}
+
+	// ファイルを変更したターンは /rewind で開始前に戻せるよう記録
+	pending := ism.beginTurn(sessionID, input)
+	defer ism.finishTurn(pending)
+	// ターン中に更新したメトリクスを保存
+	defer ism.persistSessionByID(sessionID)
+
+	// ターンの所要時間と成否を通知（メトリクス・トレースの出力が購読）
+	startTime := time.Now()
+	var response *InteractionResponse
+	var err error
+	defer func() {
+		data := map[string]interface{}{
+			"duration_ms": time.Since(startTime).Milliseconds(),
+			"success":     err == nil,
+			"needs_input": response != nil && response.RequiresConfirmation,
+		}
+		if err != nil {
+			data["error"] = err.Error()
+		}
+		events.Publish(events.TurnCompleted, sessionID, data)
+	}()
+
+	ism.recordUserInput(ctx, sessionID, input)
+	response, err = ism.routeUserInput(ctx, sessionID, input)
+	if err != nil && tracker.Exceeded() != nil {
+		// 上限で打ち切ったターンはエラーにせず、それまでに行った操作を返す
+		response, err = budgetExceededResponse(sessionID), nil
+		appendBudgetSummary(response, tracker)
+	}
+	if err != nil || response == nil {
+		return response, err
+	}
+	ism.recordAssistantResponse(ctx, sessionID, response.Message)
+	ism.startTitleGeneration(sessionID, input, response.Message)
+	events.Publish(events.ResponseGenerated, sessionID, map[string]interface{}{
+		"input":    input,
+		"response": response.Message,
+	})
+
+	// 編集が適用された場合はビルド・テストで検証し、失敗時は自動修正を反復
+	ism.verifyModifications(ctx, sessionID, response)
+	if _, reported := response.Metadata["budget_exceeded"]; !reported {
+		appendBudgetSummary(response, tracker)
+	}
+	return response, nil
+}
+
+// routeUserInput は入力内容に応じてツール実行・プロアクティブ拡張・通常処理に振り分け
+func (ism *interactiveSessionManager) routeUserInput(
+	ctx context.Context,
+	sessionID string,
+	input string,
 ) (*InteractionResponse, error) {
 	// 1. Claude Code風ツール実行分析
 	if ism.executionFlow != nil {
@@ -739,7 +1100,19 @@ func (ism *interactiveSessionManager) ProcessUserInput(
 			if plan.Confidence > 0.6 && !plan.RequiresConfirmation {
 				// 高信頼度かつ確認不要の場合は自動実行
 				steps, execErr := ism.executionFlow.ExecutePlan(ctx, plan)
+				if budget.IsExceeded(execErr) {
+					// 上限までに実行したステップは記録して打ち切る
+					ism.recordToolModifications(sessionID, steps)
+					ism.recordToolSteps(sessionID, steps)
+					auditToolSteps(ctx, steps)
+					return nil, execErr
+				}
 				if execErr == nil && len(steps) > 0 {
+					// バッチ読み取りしたファイルはコンテキスト管理に登録（圧縮対象）
+					ism.addToolResultsToContext(sessionID, steps)
+					ism.recordToolModifications(sessionID, steps)
+					ism.recordToolSteps(sessionID, steps)
+					auditToolSteps(ctx, steps)
 					// ツール実行結果を取得してLLM応答に含める
 					toolResults := ism.formatToolExecutionResults(steps)
 					// ツール実行結果を含めてLLM応答を生成
@@ -772,34 +1145,23 @@ func (ism *interactiveSessionManager) processUserInputFallback(
 	session.State = SessionStateProcessing
 	startTime := time.Now()
 
-	// 入力の意図解析
-	intent, err := ism.analyzeUserIntent(ctx, session, input)
-	if err != nil {
+	// 入力の意図解析（パイプラインの intent 段）
+	turn := &Turn{Session: session, Input: input, StartTime: startTime}
+	if err := ism.pipeline.Intent.AnalyzeIntent(ctx, turn); err != nil {
 		return nil, fmt.Errorf("意図解析エラー: %w", err)
 	}
+	intent := turn.Intent
 
 	session.UserIntent = intent
 
-	// SmartContextManagerを活用してユーザー入力をコンテキストに追加
-	ism.addToSmartContext(sessionID, input, "user_input")
-
-	// CognitiveEngineを活用した高度な推論処理
-	reasoningInsights := ism.performCognitiveReasoning(ctx, input, intent)
-
-	// 推論結果をセッションメタデータに追加
-	if session.SessionMetadata == nil {
-		session.SessionMetadata = make(map[string]string)
-	}
-	if reasoningInsights["status"] == "reasoning_completed" {
-		session.SessionMetadata["reasoning_confidence"] = fmt.Sprintf("%.2f", reasoningInsights["confidence"])
-		session.SessionMetadata["reasoning_processing_time"] = fmt.Sprintf("%v", reasoningInsights["processing_time"])
-		if reasoningInsights["solution_approach"] != nil {
-			session.SessionMetadata["reasoning_approach"] = reasoningInsights["solution_approach"].(string)
-		}
-	}
-
 	// 確認応答の処理チェック
 	trimmedInput := strings.TrimSpace(strings.ToLower(input))
+	// 変更の衝突で止めた提案は再生成・強制適用・中止を選ぶ
+	if session.PendingSuggestion != nil && session.PendingSuggestion.Metadata[metaConflict] == "true" {
+		if response, handled, err := ism.handleConflictChoice(ctx, session, trimmedInput); handled {
+			return response, err
+		}
+	}
 	if (trimmedInput == "y" || trimmedInput == "yes" || trimmedInput == "はい" || trimmedInput == "ok") && session.PendingSuggestion != nil {
 		// 提案確認処理
 		fmt.Printf("Debug: 提案確認受理 - ID: %s\n", session.PendingSuggestion.ID)
@@ -811,6 +1173,10 @@ func (ism *interactiveSessionManager) processUserInputFallback(
 		}
 
 		err = ism.ApplySuggestion(ctx, sessionID, session.PendingSuggestion.ID)
+		var conflict *EditConflict
+		if errors.As(err, &conflict) {
+			return conflictResponse(session, conflict), nil
+		}
 		if err != nil {
 			session.State = SessionStateError
 			return nil, fmt.Errorf("提案適用エラー: %w", err)
@@ -820,7 +1186,7 @@ func (ism *interactiveSessionManager) processUserInputFallback(
 		response := &InteractionResponse{
 			SessionID:            sessionID,
 			ResponseType:         ResponseTypeCompletion,
-			Message:              "✅ 提案を適用しました！",
+			Message:              i18n.T("suggestion.applied"),
 			RequiresConfirmation: false,
 			Metadata: map[string]string{
 				"action":        "suggestion_applied",
@@ -857,8 +1223,8 @@ func (ism *interactiveSessionManager) processUserInputFallback(
 		return nil, fmt.Errorf("コンテキスト追加エラー: %w", err)
 	}
 
-	// 応答生成
-	response, err := ism.generateInteractiveResponse(ctx, session, input, intent)
+	// 応答生成（パイプラインの retrieval 以降の段）
+	response, err := ism.pipeline.respond(ctx, turn)
 	if err != nil {
 		session.State = SessionStateError
 		return nil, fmt.Errorf("応答生成エラー: %w", err)
@@ -909,12 +1275,20 @@ func (ism *interactiveSessionManager) GetSessionMetrics(sessionID string) (*Sess
 	ism.mu.RLock()
 	defer ism.mu.RUnlock()
 
-	metrics, exists := ism.sessionMetrics[sessionID]
-	if !exists {
-		return nil, fmt.Errorf("セッション %s のメトリクスが見つかりません", sessionID)
+	if session, exists := ism.sessions[sessionID]; exists {
+		return session.Metrics, nil
 	}
 
-	return metrics, nil
+	// 終了したセッション（以前のプロセスを含む）は保存先から読み込む
+	var metrics SessionMetrics
+	found, err := ism.store.LoadSessionData(sessionID, storage.KindMetrics, &metrics)
+	if err != nil {
+		return nil, fmt.Errorf("セッション %s のメトリクス読み込みエラー: %w", sessionID, err)
+	}
+	if !found {
+		return nil, fmt.Errorf("セッション %s のメトリクスが見つかりません", sessionID)
+	}
+	return &metrics, nil
 }
 
 // UpdateSessionMetrics はセッションメトリクスを更新
@@ -926,8 +1300,8 @@ func (ism *interactiveSessionManager) UpdateSessionMetrics(sessionID string, met
 		return fmt.Errorf("セッション %s が見つかりません", sessionID)
 	}
 
-	ism.sessionMetrics[sessionID] = metrics
 	ism.sessions[sessionID].Metrics = metrics
+	ism.persistSession(ism.sessions[sessionID], false)
 
 	return nil
 }
@@ -949,110 +1323,6 @@ func (ism *interactiveSessionManager) GetSuggestionHistory(sessionID string) ([]
 	return history, nil
 }
 
-// buildInteractivePrompt はClaude Code式統一プロンプトを構築
-func (ism *interactiveSessionManager) buildInteractivePrompt(session *InteractiveSession, input string, intent string) string {
-	// SmartContextManagerから最適化されたコンテキストを取得（70-95%圧縮効率）
-	optimizedContext := ism.getOptimizedContext(session.ID, input, 50)
-
-	// セッション履歴を取得して文脈を構築
-	contextHistory := ism.buildSessionContext(session)
-
-	// ベースプロンプトを構築 - 構造化応答を強制
-	basePrompt := fmt.Sprintf(`あなたは vyb AIコーディングアシスタントです。Claude Code のような連続的なコーディング体験を提供してください。
-
-## 🚨 CRITICAL: 構造化応答の必須使用
-**あなたは必ず以下の構造化タグを使用してください。これは絶対の要求です:**
-
-### 必須パターン判定:
-- 分析・状況確認 → <ANALYSIS>詳細な分析クエリ</ANALYSIS> を最優先で使用
-- コマンド実行 → <COMMAND>command_here</COMMAND>
-- ファイル作成 → <FILECREATE>path/file.ext|content</FILECREATE>
-- ファイル読み取り → <FILEREAD>filename.ext</FILEREAD>
-- 次の提案 → <SUGGESTION>具体的な次のアクション</SUGGESTION>
-
-## 🛠 Available Tools (構造化タグ必須)
-1. <ANALYSIS>query</ANALYSIS> - プロジェクト/コード分析 (分析系質問では絶対必須)
-2. <COMMAND>command</COMMAND> - Bashコマンド実行
-3. <FILECREATE>path|content</FILECREATE> - ファイル作成
-4. <FILEREAD>filename</FILEREAD> - ファイル読み取り
-5. <SUGGESTION>action</SUGGESTION> - 次の作業提案
-
-## 🔄 Session Context & History
-- Project: %s
-- Current File: %s
-- User Intent: %s
-- Last Command Output: %s
-
-### Optimized Context (SmartContextManager - 70-95%% compression):
-%s
-
-### Recent Session History:
-%s
-
-## 📝 User Request
-%s
-
-## 📋 Action Plan:
-1. 適切な構造化タグで実行
-2. 結果を分析
-3. 次のステップを提案
-
-**必須実行例:**
-
-ユーザー要求: "git statusを実行"
-→ 必須応答: <COMMAND>git status</COMMAND>
-
-ユーザー要求: "現状を分析"
-→ 必須応答: <ANALYSIS>プロジェクト状況の詳細分析</ANALYSIS>
-
-ユーザー要求: "ファイルを作成"
-→ 必須応答: <FILECREATE>filename.ext|content here</FILECREATE>
-
-🚨 **CRITICAL**: あなたの応答は必ずこれらのタグを含む必要があります。タグなしの応答は許可されません。`,
-		ism.sessionTypeToString(session.Type),
-		session.CurrentFile,
-		intent,
-		session.LastCommandOutput,
-		optimizedContext,
-		contextHistory,
-		input,
-	)
-
-	// プロアクティブ拡張が利用可能な場合、プロンプトを拡張
-	if ism.proactiveExt != nil {
-		enhancedPrompt := ism.proactiveExt.EnhancePrompt(basePrompt, input)
-		return enhancedPrompt
-	}
-
-	return basePrompt
-}
-
-// buildSessionContext はセッション履歴から文脈を構築
-func (ism *interactiveSessionManager) buildSessionContext(session *InteractiveSession) string {
-	if session.Metrics.TotalInteractions == 0 {
-		return "新しいセッションです。"
-	}
-
-	context := fmt.Sprintf(`
-- 総インタラクション数: %d
-- 受け入れられた提案: %d / %d
-- 変更されたファイル: %d
-- 変更された行数: %d`,
-		session.Metrics.TotalInteractions,
-		session.Metrics.SuggestionsAccepted,
-		session.Metrics.CodeSuggestionsGiven,
-		session.Metrics.FilesModified,
-		session.Metrics.LinesChanged,
-	)
-
-	// 最後の作業内容があれば追加
-	if session.LastCommandOutput != "" {
-		context += "\n- 最後の実行結果: " + session.LastCommandOutput[:min(200, len(session.LastCommandOutput))] + "..."
-	}
-
-	return context
-}
-
 // min は二つの整数の最小値を返す
 func min(a, b int) int {
 	if a < b {
@@ -1061,8 +1331,12 @@ func min(a, b int) int {
 	return b
 }
 
-// normalizeLanguage はLLM応答の言語を日本語に統一
+// normalizeLanguage はLLM応答の言語を日本語に統一（日本語設定時のみ）
 func (ism *interactiveSessionManager) normalizeLanguage(content string) string {
+	if i18n.Current() != i18n.LangJa {
+		return content
+	}
+
 	// 繁体字・簡体字の一般的なパターンを日本語に変換
 	replacements := map[string]string{
 		"创建文件": "ファイル作成",
@@ -1097,6 +1371,16 @@ func (ism *interactiveSessionManager) normalizeLanguage(content string) string {
 	return result
 }
 
+// structuredExecution は応答中の構造化タグを実行した結果
+type structuredExecution struct {
+	results      []string // ユーザーに表示する実行結果
+	observations []string // モデルに返す実行結果（ファイル内容を省略しない）
+	actions      []string // 実行した操作（提案を含む）
+	suggestions  []string
+	toolCalls    int // 提案を除いた実行数
+	analysis     *AnalysisReport
+}
+
 // parseAndExecuteStructuredResponse はLLM応答を解析して実際のツール実行を行う
 func (ism *interactiveSessionManager) parseAndExecuteStructuredResponse(
 	ctx context.Context,
@@ -1104,30 +1388,56 @@ func (ism *interactiveSessionManager) parseAndExecuteStructuredResponse(
 	llmResponse string,
 	originalInput string,
 ) (*InteractionResponse, error) {
-	var allResults []string
-	var executedActions []string
+	execution := ism.executeStructuredTags(ctx, session, llmResponse, nil)
+
+	// 構造化されたパターンが見つからない場合はnilを返す（通常処理に戻る）
+	if len(execution.actions) == 0 {
+		return nil, nil
+	}
+	return ism.buildStructuredResponse(session, llmResponse, execution), nil
+}
+
+// executeStructuredTags はLLM応答中の構造化タグ（コマンド・ファイル作成・ツリー・読み取り・分析・ジョブ・提案）を順に実行
+func (ism *interactiveSessionManager) executeStructuredTags(
+	ctx context.Context,
+	session *InteractiveSession,
+	llmResponse string,
+	streamed *toolStream,
+) *structuredExecution {
+	execution := &structuredExecution{}
+	add := func(result string) {
+		execution.results = append(execution.results, result)
+		execution.observations = append(execution.observations, result)
+	}
 
-	// 1. コマンド実行パターンをチェック
-	commandRegex := regexp.MustCompile(`<COMMAND>(.*?)</COMMAND>`)
-	commandMatches := commandRegex.FindAllStringSubmatch(llmResponse, -1)
+	// 1. コマンド実行パターンをチェック（応答の生成中に実行したコマンドはその結果を使う）
+	commandMatches := commandTagPattern.FindAllStringSubmatch(llmResponse, -1)
 
 	if len(commandMatches) > 0 {
+		seen := make(map[string]int)
 		for _, match := range commandMatches {
 			if len(match) > 1 {
 				command := strings.TrimSpace(match[1])
-				result, err := ism.executeBashCommand(ctx, session, command)
+				var result string
+				var err error
+				if done, ok := streamed.take(commandBlockID(seen, command)); ok {
+					result, err = done.output, done.err
+				} else {
+					result, err = ism.executeBashCommand(ctx, session, command)
+				}
 				if err != nil {
-					allResults = append(allResults, fmt.Sprintf("⚠️ コマンドエラー: %v", err))
+					add(fmt.Sprintf("⚠️ コマンドエラー: %v", err))
 				} else {
 					// git diff の場合は要約版を使用
 					if strings.Contains(command, "git diff") {
 						summarizedResult := ism.summarizeGitDiff(result)
-						allResults = append(allResults, fmt.Sprintf("✅ `%s`:\n%s", command, summarizedResult))
+						add(fmt.Sprintf("✅ `%s`:\n%s", command, summarizedResult))
 					} else {
-						allResults = append(allResults, fmt.Sprintf("✅ `%s`:\n%s", command, result))
+						add(fmt.Sprintf("✅ `%s`:\n%s", command, result))
 					}
 				}
-				executedActions = append(executedActions, fmt.Sprintf("コマンド実行: %s", command))
+				execution.actions = append(execution.actions, fmt.Sprintf("コマンド実行: %s", command))
+				execution.toolCalls++
 			}
 		}
 	}
@@ -1143,16 +1453,43 @@ func (ism *interactiveSessionManager) parseAndExecuteStructuredResponse(
 				content := strings.TrimSpace(match[2])
 				err := ism.createFile(ctx, session, filePath, content)
 				if err != nil {
-					allResults = append(allResults, fmt.Sprintf("⚠️ ファイル作成エラー (%s): %v", filePath, err))
+					add(fmt.Sprintf("⚠️ ファイル作成エラー (%s): %v", filePath, err))
 				} else {
-					allResults = append(allResults, fmt.Sprintf("✅ ファイル作成成功: %s", filePath))
+					add(fmt.Sprintf("✅ ファイル作成成功: %s", filePath))
+					ism.recordFileModification(session.ID, filePath)
+					publishEditApplied(session.ID, filePath, "create")
 				}
-				executedActions = append(executedActions, fmt.Sprintf("ファイル作成: %s", filePath))
+				execution.actions = append(execution.actions, fmt.Sprintf("ファイル作成: %s", filePath))
+				execution.toolCalls++
 			}
 		}
 	}
 
-	// 3. ファイル読み取りパターンをチェック
+	// 3. unified diff のパッチを適用
+	patchResults, patchActions := ism.executePatchTags(ctx, session, llmResponse)
+	for _, result := range patchResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, patchActions...)
+	execution.toolCalls += len(patchActions)
+
+	// 4. ファイルの移動・削除
+	fileOpsResults, fileOpsActions := ism.executeFileOpsTags(ctx, session, llmResponse)
+	for _, result := range fileOpsResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, fileOpsActions...)
+	execution.toolCalls += len(fileOpsActions)
+
+	// 5. ディレクトリツリー（find・ls -la の代わりに構造を把握する）
+	treeResults, treeActions := ism.executeTreeTags(ctx, llmResponse)
+	for _, result := range treeResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, treeActions...)
+	execution.toolCalls += len(treeActions)
+
+	// 6. ファイル読み取りパターンをチェック
 	fileReadRegex := regexp.MustCompile(`<FILEREAD>(.*?)</FILEREAD>`)
 	readMatches := fileReadRegex.FindAllStringSubmatch(llmResponse, -1)
 
@@ -1162,21 +1499,23 @@ func (ism *interactiveSessionManager) parseAndExecuteStructuredResponse(
 				filePath := strings.TrimSpace(match[1])
 				content, err := ism.readFile(ctx, session, filePath)
 				if err != nil {
-					allResults = append(allResults, fmt.Sprintf("⚠️ ファイル読み取りエラー (%s): %v", filePath, err))
+					add(fmt.Sprintf("⚠️ ファイル読み取りエラー (%s): %v", filePath, err))
 				} else {
-					// 内容が長すぎる場合は省略
+					// 内容が長すぎる場合は省略（モデルにはエージェントループの上限まで渡す）
 					displayContent := content
 					if len(content) > 500 {
 						displayContent = content[:500] + "...(省略)"
 					}
-					allResults = append(allResults, fmt.Sprintf("📄 %s:\n%s", filePath, displayContent))
+					execution.results = append(execution.results, fmt.Sprintf("📄 %s:\n%s", filePath, displayContent))
+					execution.observations = append(execution.observations, fmt.Sprintf("📄 %s:\n%s", filePath, truncateObservation(content)))
 				}
-				executedActions = append(executedActions, fmt.Sprintf("ファイル読み込み: %s", filePath))
+				execution.actions = append(execution.actions, fmt.Sprintf("ファイル読み込み: %s", filePath))
+				execution.toolCalls++
 			}
 		}
 	}
 
-	// 4. 分析パターンをチェック
+	// 7. 分析パターンをチェック
 	analysisRegex := regexp.MustCompile(`<ANALYSIS>(.*?)</ANALYSIS>`)
 	analysisMatches := analysisRegex.FindAllStringSubmatch(llmResponse, -1)
 
@@ -1185,70 +1524,125 @@ func (ism *interactiveSessionManager) parseAndExecuteStructuredResponse(
 			if len(match) > 1 {
 				query := strings.TrimSpace(match[1])
 				result := ism.performAnalysis(session, query)
-				allResults = append(allResults, fmt.Sprintf("🔍 分析結果:\n%s", result))
-				executedActions = append(executedActions, fmt.Sprintf("分析実行: %s", query))
+				add(fmt.Sprintf("🔍 分析結果:\n%s", result))
+				// ヘッドレス出力・エディタ連携向けに構造化した結果も返す（プロジェクト分析はキャッシュを使う）
+				if report, err := ism.AnalysisReport(ctx, query); err == nil {
+					execution.analysis = report
+				}
+				execution.actions = append(execution.actions, fmt.Sprintf("分析実行: %s", query))
+				execution.toolCalls++
 			}
 		}
 	}
 
-	// 5. 提案パターンをチェック
+	// 8. アーキテクチャ図（依存関係の Mermaid・DOT）
+	diagramResults, diagramActions := ism.executeDiagramTags(ctx, llmResponse)
+	for _, result := range diagramResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, diagramActions...)
+	execution.toolCalls += len(diagramActions)
+
+	// 9. バックグラウンドジョブの起動・出力確認・停止
+	jobResults, jobActions := ism.executeJobTags(ctx, session, llmResponse)
+	for _, result := range jobResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, jobActions...)
+	execution.toolCalls += len(jobActions)
+
+	// 10. 設計判断・制約等のコンテキストへの固定
+	pinResults, pinActions := ism.executePinTags(session, llmResponse)
+	for _, result := range pinResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, pinActions...)
+	execution.toolCalls += len(pinActions)
+
+	// 11. 以前の実行結果の検索
+	recallResults, recallActions := ism.executeRecallTags(ctx, session, llmResponse)
+	for _, result := range recallResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, recallActions...)
+	execution.toolCalls += len(recallActions)
+
+	// 12. 環境レポート（環境に依存する不具合の調査）
+	diagnosticsResults, diagnosticsActions := ism.executeDiagnosticsTags(ctx, llmResponse)
+	for _, result := range diagnosticsResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, diagnosticsActions...)
+	execution.toolCalls += len(diagnosticsActions)
+
+	// 13. ログの追跡（リクエストを送る間のサーバーのログ等）
+	logTailResults, logTailActions := ism.executeLogTailTags(ctx, llmResponse)
+	for _, result := range logTailResults {
+		add(result)
+	}
+	execution.actions = append(execution.actions, logTailActions...)
+	execution.toolCalls += len(logTailActions)
+
+	// 14. 提案パターンをチェック
 	suggestionRegex := regexp.MustCompile(`<SUGGESTION>(.*?)</SUGGESTION>`)
 	suggestionMatches := suggestionRegex.FindAllStringSubmatch(llmResponse, -1)
 
-	var suggestions []string
 	if len(suggestionMatches) > 0 {
 		for _, match := range suggestionMatches {
 			if len(match) > 1 {
 				suggestion := strings.TrimSpace(match[1])
-				suggestions = append(suggestions, suggestion)
-				executedActions = append(executedActions, fmt.Sprintf("提案: %s", suggestion))
+				execution.suggestions = append(execution.suggestions, suggestion)
+				execution.actions = append(execution.actions, fmt.Sprintf("提案: %s", suggestion))
 			}
 		}
 	}
 
-	// 何らかのアクションが実行された場合、統合された応答を生成
-	if len(executedActions) > 0 {
-		cleanMessage := ism.extractCleanMessage(llmResponse)
+	return execution
+}
 
-		// 実行結果をまとめる
-		var responseMessage strings.Builder
-		responseMessage.WriteString(cleanMessage)
+// buildStructuredResponse は構造化タグを実行した結果から、本文・実行結果・提案をまとめた応答を生成
+func (ism *interactiveSessionManager) buildStructuredResponse(
+	session *InteractiveSession,
+	llmResponse string,
+	execution *structuredExecution,
+) *InteractionResponse {
+	cleanMessage := ism.extractCleanMessage(llmResponse)
 
-		if len(allResults) > 0 {
-			responseMessage.WriteString("\n\n🔄 **実行結果:**\n")
-			responseMessage.WriteString(strings.Join(allResults, "\n\n"))
-		}
+	// 実行結果をまとめる
+	var responseMessage strings.Builder
+	responseMessage.WriteString(cleanMessage)
 
-		// 提案があれば追加
-		if len(suggestions) > 0 {
-			responseMessage.WriteString("\n\n💡 **次のステップの提案:**\n• ")
-			responseMessage.WriteString(strings.Join(suggestions, "\n• "))
-		}
+	if len(execution.results) > 0 {
+		responseMessage.WriteString("\n\n" + i18n.T("agentloop.results") + "\n")
+		responseMessage.WriteString(strings.Join(execution.results, "\n\n"))
+	}
 
-		// 連続体験のための次のステップ提案を追加
-		nextStepPrompt := ism.generateNextStepSuggestion(executedActions, allResults)
-		if nextStepPrompt != "" {
-			responseMessage.WriteString("\n\n")
-			responseMessage.WriteString(nextStepPrompt)
-		}
+	// 提案があれば追加
+	if len(execution.suggestions) > 0 {
+		responseMessage.WriteString("\n\n💡 **次のステップの提案:**\n• ")
+		responseMessage.WriteString(strings.Join(execution.suggestions, "\n• "))
+	}
 
-		response := &InteractionResponse{
-			SessionID:            session.ID,
-			ResponseType:         ResponseTypeMessage,
-			Message:              responseMessage.String(),
-			RequiresConfirmation: false,
-			GeneratedAt:          time.Now(),
-			Metadata: map[string]string{
-				"actions_count":    fmt.Sprintf("%d", len(executedActions)),
-				"has_executions":   "true",
-				"executed_actions": strings.Join(executedActions, ", "),
-			},
-		}
-		return response, nil
+	// 連続体験のための次のステップ提案を追加
+	nextStepPrompt := ism.generateNextStepSuggestion(execution.actions, execution.results)
+	if nextStepPrompt != "" {
+		responseMessage.WriteString("\n\n")
+		responseMessage.WriteString(nextStepPrompt)
 	}
 
-	// 構造化されたパターンが見つからない場合はnilを返す（通常処理に戻る）
-	return nil, nil
+	return &InteractionResponse{
+		SessionID:            session.ID,
+		ResponseType:         ResponseTypeMessage,
+		Message:              responseMessage.String(),
+		RequiresConfirmation: false,
+		Analysis:             execution.analysis,
+		GeneratedAt:          time.Now(),
+		Metadata: map[string]string{
+			"actions_count":    fmt.Sprintf("%d", len(execution.actions)),
+			"has_executions":   "true",
+			"executed_actions": strings.Join(execution.actions, ", "),
+		},
+	}
 }
 
 // extractCleanMessage は構造化タグを除去したメッセージを抽出
@@ -1259,6 +1653,18 @@ func (ism *interactiveSessionManager) extractCleanMessage(content string) string
 	content = regexp.MustCompile(`<FILEREAD>.*?</FILEREAD>`).ReplaceAllString(content, "")
 	content = regexp.MustCompile(`<ANALYSIS>.*?</ANALYSIS>`).ReplaceAllString(content, "")
 	content = regexp.MustCompile(`<SUGGESTION>.*?</SUGGESTION>`).ReplaceAllString(content, "")
+	content = patchTagPattern.ReplaceAllString(content, "")
+	content = fileMoveTagPattern.ReplaceAllString(content, "")
+	content = fileDeleteTagPattern.ReplaceAllString(content, "")
+	content = treeTagPattern.ReplaceAllString(content, "")
+	content = diagramTagPattern.ReplaceAllString(content, "")
+	content = jobStartRegex.ReplaceAllString(content, "")
+	content = jobOutputRegex.ReplaceAllString(content, "")
+	content = jobKillRegex.ReplaceAllString(content, "")
+	content = pinTagRegex.ReplaceAllString(content, "")
+	content = recallTagPattern.ReplaceAllString(content, "")
+	content = diagnosticsTagPattern.ReplaceAllString(content, "")
+	content = logTailTagPattern.ReplaceAllString(content, "")
 
 	// 改行を整理
 	content = strings.TrimSpace(content)
@@ -1274,13 +1680,20 @@ func (ism *interactiveSessionManager) executeBashCommand(ctx context.Context, se
 	if ism.bashTool == nil {
 		return "", fmt.Errorf("BashToolが初期化されていません")
 	}
+	if err := budget.UseTool(ctx, "bash: "+command); err != nil {
+		return "", err
+	}
 
-	result, err := ism.bashTool.Execute(command, "Interactive command execution", 30000) // 30秒タイムアウト
+	startTime := time.Now()
+	result, err := ism.bashTool.ExecuteContext(ctx, command, "Interactive command execution", 30000) // 30秒タイムアウト
 	if err != nil {
+		auditCommand(ctx, command, "", startTime, err)
 		return "", fmt.Errorf("コマンド実行エラー: %w", err)
 	}
+	auditCommand(ctx, command, result.Content, startTime, nil)
 
 	session.LastCommandOutput = result.Content
+	ism.rememberResult(session.ID, "bash", command, result.Content, result.ExitCode == 0 && !result.TimedOut && !result.IsError)
 	return result.Content, nil
 }
 
@@ -1289,6 +1702,9 @@ func (ism *interactiveSessionManager) createFile(ctx context.Context, session *I
 	if ism.writeTool == nil {
 		return fmt.Errorf("WriteToolが初期化されていません")
 	}
+	if err := budget.UseTool(ctx, "write: "+filePath); err != nil {
+		return err
+	}
 
 	// WriteRequestを作成
 	writeReq := tools.WriteRequest{
@@ -1298,13 +1714,17 @@ func (ism *interactiveSessionManager) createFile(ctx context.Context, session *I
 
 	result, err := ism.writeTool.Write(writeReq)
 	if err != nil {
+		auditFileWrite(ctx, filePath, "write", len(content), err)
 		return fmt.Errorf("ファイル作成エラー: %w", err)
 	}
 
 	if result.IsError {
-		return fmt.Errorf("ファイル作成失敗: %s", result.Content)
+		err = fmt.Errorf("ファイル作成失敗: %s", result.Content)
+		auditFileWrite(ctx, filePath, "write", len(content), err)
+		return err
 	}
 
+	auditFileWrite(ctx, filePath, "write", len(content), nil)
 	return nil
 }
 
@@ -1314,17 +1734,29 @@ func (ism *interactiveSessionManager) readFile(ctx context.Context, session *Int
 	if ism.bashTool == nil {
 		return "", fmt.Errorf("ファイル読み取りツールが初期化されていません")
 	}
-
-	result, err := ism.bashTool.Execute(fmt.Sprintf("cat %s", filePath), "Read file content", 10000)
-	if err != nil {
-		return "", fmt.Errorf("ファイル読み取りエラー: %w", err)
+	if err := budget.UseTool(ctx, "read: "+filePath); err != nil {
+		return "", err
+	}
+	// cat では許可規則が検証されないため、読み取りを禁止したパスはここで拒否する
+	if ism.permissions != nil {
+		if resolved, err := security.NewPathJail(".").Resolve(filePath); err == nil {
+			if err := ism.permissions.CheckPath(resolved, "read"); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	result, err := ism.bashTool.ExecuteContext(ctx, fmt.Sprintf("cat %s", filePath), "Read file content", 10000)
+	if err != nil {
+		return "", fmt.Errorf("ファイル読み取りエラー: %w", err)
 	}
 
 	if result.IsError {
 		return "", fmt.Errorf("ファイル読み取り失敗: %s", result.Content)
 	}
 
-	return result.Content, nil
+	// .env 等の値・機密情報は LLM に渡す前に伏せる
+	return redact.File(filePath, result.Content), nil
 }
 
 // isDangerousFileOperation は危険なファイル操作かどうかを判定
@@ -1409,357 +1841,6 @@ func (ism *interactiveSessionManager) generateFallbackResponse(session *Interact
 	}, nil
 }
 
-// performAnalysis は科学的認知分析システムを使用した高度な分析処理を実行
-func (ism *interactiveSessionManager) performAnalysis(_ *InteractiveSession, query string) string {
-	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
-	defer cancel()
-
-	var analysisComponents []string
-
-	// 1. 既存の統合分析システムを活用
-	unifiedAnalysisResult := ism.performUnifiedAnalysis(query)
-	if unifiedAnalysisResult != "" {
-		analysisComponents = append(analysisComponents, unifiedAnalysisResult)
-	}
-
-	// 2. 高度な認知分析の実行
-	if ism.cognitiveAnalyzer != nil {
-		cognitiveResult := ism.performDetailedCognitiveAnalysis(ctx, query)
-		if cognitiveResult != "" {
-			analysisComponents = append(analysisComponents, cognitiveResult)
-		}
-	}
-
-	// 3. プロジェクト構造の詳細分析
-	if projectPath, err := os.Getwd(); err == nil {
-		structureAnalysis := ism.performProjectStructureAnalysisImpl(projectPath)
-		if structureAnalysis != "" {
-			analysisComponents = append(analysisComponents, structureAnalysis)
-		}
-	}
-
-	// 4. Git分析の詳細化
-	gitAnalysis := ism.performDetailedGitAnalysis()
-	if gitAnalysis != "" {
-		analysisComponents = append(analysisComponents, gitAnalysis)
-	}
-
-	// 5. 依存関係とセキュリティ分析
-	securityAnalysis := ism.performSecurityAnalysisImpl()
-	if securityAnalysis != "" {
-		analysisComponents = append(analysisComponents, securityAnalysis)
-	}
-
-	// 6. フォールバック
-	if len(analysisComponents) == 0 {
-		analysisComponents = append(analysisComponents, ism.performBasicAnalysis(query))
-	}
-
-	// 分析結果の統合フォーマット
-	return ism.formatComprehensiveAnalysisResponse(query, analysisComponents)
-}
-
-// performScientificCognitiveAnalysis は科学的認知分析を実行
-func (ism *interactiveSessionManager) performScientificCognitiveAnalysis(query string) string {
-	if ism.cognitiveAnalyzer == nil {
-		return ""
-	}
-
-	ctx := context.Background()
-
-	// 分析リクエストを作成
-	analysisRequest := &analysis.AnalysisRequest{
-		UserInput: query,
-		Response:  "", // 初期分析時は空
-		Context: map[string]interface{}{
-			"project_type":  "go",
-			"analysis_type": "project_analysis",
-		},
-		AnalysisDepth:   "standard",
-		RequiredMetrics: []string{"confidence", "reasoning_depth", "creativity"},
-	}
-
-	// 認知分析を実行
-	result, err := ism.cognitiveAnalyzer.AnalyzeCognitive(ctx, analysisRequest)
-	if err != nil {
-		return fmt.Sprintf("🧠 科学的認知分析: エラー (%v)", err)
-	}
-
-	// 結果をフォーマット
-	return ism.formatCognitiveAnalysisResult(result)
-}
-
-// formatCognitiveAnalysisResult は認知分析結果をフォーマット
-func (ism *interactiveSessionManager) formatCognitiveAnalysisResult(result *analysis.CognitiveAnalysisResult) string {
-	var parts []string
-
-	parts = append(parts, "🧠 **科学的認知分析結果**")
-
-	// セマンティックエントロピーによる信頼度
-	if result.Confidence != nil {
-		parts = append(parts, fmt.Sprintf("📊 信頼度: %.2f (セマンティックエントロピー: %.3f)",
-			result.Confidence.OverallConfidence, result.Confidence.SemanticEntropy))
-	}
-
-	// 推論深度分析
-	if result.ReasoningDepth != nil {
-		parts = append(parts, fmt.Sprintf("🔬 推論深度: %d (論理構造スコア: %.3f)",
-			result.ReasoningDepth.OverallDepth, result.ReasoningDepth.ComplexityScore))
-	}
-
-	// 創造性測定
-	if result.Creativity != nil {
-		parts = append(parts, fmt.Sprintf("🎨 創造性: %.2f (流暢性: %.2f, 柔軟性: %.2f, 独創性: %.2f)",
-			result.Creativity.OverallScore,
-			result.Creativity.Fluency,
-			result.Creativity.Flexibility,
-			result.Creativity.Originality))
-	}
-
-	// 統合評価
-	parts = append(parts, fmt.Sprintf("⚡ 統合品質スコア: %.2f", result.OverallQuality))
-	parts = append(parts, fmt.Sprintf("🔒 信頼スコア: %.2f", result.TrustScore))
-
-	// 推奨アクション
-	if len(result.RecommendedActions) > 0 {
-		parts = append(parts, "📋 推奨アクション:")
-		for _, action := range result.RecommendedActions {
-			parts = append(parts, fmt.Sprintf("  • %s", action))
-		}
-	}
-
-	return strings.Join(parts, "\n")
-}
-
-// collectBasicProjectInfo は基本的なプロジェクト情報を収集
-func (ism *interactiveSessionManager) collectBasicProjectInfo() string {
-	var info []string
-
-	if ism.bashTool != nil {
-		// Git状態
-		if result, err := ism.bashTool.Execute("git status --porcelain", "Git status", 3000); err == nil && !result.IsError {
-			fileCount := len(strings.Split(strings.TrimSpace(result.Content), "\n"))
-			if strings.TrimSpace(result.Content) != "" {
-				info = append(info, fmt.Sprintf("📊 Git状態: %d個のファイルに変更", fileCount))
-			} else {
-				info = append(info, "📊 Git状態: クリーン")
-			}
-		}
-
-		// プロジェクト規模
-		if result, err := ism.bashTool.Execute("find . -name '*.go' -type f | wc -l", "Go files count", 3000); err == nil && !result.IsError {
-			info = append(info, fmt.Sprintf("🏗️ プロジェクト規模: %s個のGoファイル", strings.TrimSpace(result.Content)))
-		}
-	}
-
-	if len(info) > 0 {
-		return strings.Join(info, "\n")
-	}
-
-	return ""
-}
-
-// performBasicAnalysis はフォールバック用の基本分析
-func (ism *interactiveSessionManager) performBasicAnalysis(query string) string {
-	return fmt.Sprintf("🔍 基本分析: %s\n（科学的認知分析システムが利用できません）\n💡 基本的なプロジェクト状態を確認しました", query)
-}
-
-// performUnifiedAnalysis は既存のUnifiedAnalyzerを活用した統合分析を実行
-func (ism *interactiveSessionManager) performUnifiedAnalysis(query string) string {
-	if ism.config == nil {
-		return ""
-	}
-
-	// UnifiedAnalyzerを初期化
-	if llmClient, ok := ism.llmProvider.(ai.LLMClient); ok {
-		unifiedAnalyzer := analysis.NewUnifiedAnalyzer(ism.config, llmClient)
-
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-		defer cancel()
-
-		// プロジェクト分析を実行
-		projectAnalysis, err := unifiedAnalyzer.AnalyzeProject(ctx, ".")
-		if err != nil {
-			return fmt.Sprintf("🔬 統合分析エラー: %v", err)
-		}
-
-		// 分析結果をフォーマット
-		return ism.formatProjectAnalysis(projectAnalysis)
-	}
-
-	return ""
-}
-
-// formatProjectAnalysis はプロジェクト分析結果をフォーマット
-func (ism *interactiveSessionManager) formatProjectAnalysis(analysis *analysis.ProjectAnalysis) string {
-	if analysis == nil {
-		return ""
-	}
-
-	var result []string
-
-	// プロジェクト基本情報
-	result = append(result, fmt.Sprintf("📋 **プロジェクト概要**"))
-	result = append(result, fmt.Sprintf("  • 名前: %s", analysis.ProjectName))
-	result = append(result, fmt.Sprintf("  • 言語: %s", analysis.Language))
-	result = append(result, fmt.Sprintf("  • フレームワーク: %s", analysis.Framework))
-
-	// ファイル構造
-	if analysis.FileStructure != nil {
-		result = append(result, fmt.Sprintf("🏗️ **プロジェクト構造**"))
-		result = append(result, fmt.Sprintf("  • 総ファイル数: %d", analysis.FileStructure.TotalFiles))
-		result = append(result, fmt.Sprintf("  • 総行数: %s", ism.formatNumber(analysis.FileStructure.TotalLines)))
-
-		if len(analysis.FileStructure.Languages) > 0 {
-			result = append(result, "  • 言語別ファイル数:")
-			for lang, count := range analysis.FileStructure.Languages {
-				result = append(result, fmt.Sprintf("    - %s: %d ファイル", lang, count))
-			}
-		}
-	}
-
-	// 品質メトリクス
-	if analysis.QualityMetrics != nil {
-		result = append(result, fmt.Sprintf("📊 **コード品質**"))
-		result = append(result, fmt.Sprintf("  • テストカバレッジ: %.1f%%", analysis.QualityMetrics.TestCoverage*100))
-		result = append(result, fmt.Sprintf("  • 保守性: %.1f/10", analysis.QualityMetrics.Maintainability*10))
-		result = append(result, fmt.Sprintf("  • 複雑度: %.1f", analysis.QualityMetrics.CodeComplexity))
-		if analysis.QualityMetrics.IssueCount > 0 {
-			result = append(result, fmt.Sprintf("  • ⚠️ 検出された問題: %d件", analysis.QualityMetrics.IssueCount))
-		}
-	}
-
-	// 依存関係
-	if len(analysis.Dependencies) > 0 {
-		result = append(result, fmt.Sprintf("📦 **依存関係 (%d件)**", len(analysis.Dependencies)))
-		outdatedCount := 0
-		vulnerableCount := 0
-		for _, dep := range analysis.Dependencies {
-			if dep.Outdated {
-				outdatedCount++
-			}
-			if len(dep.Vulnerabilities) > 0 {
-				vulnerableCount++
-			}
-		}
-		if outdatedCount > 0 {
-			result = append(result, fmt.Sprintf("  • ⚠️ 古いバージョン: %d件", outdatedCount))
-		}
-		if vulnerableCount > 0 {
-			result = append(result, fmt.Sprintf("  • 🔒 セキュリティ問題: %d件", vulnerableCount))
-		}
-	}
-
-	// セキュリティ問題
-	if len(analysis.SecurityIssues) > 0 {
-		result = append(result, fmt.Sprintf("🔒 **セキュリティ問題 (%d件)**", len(analysis.SecurityIssues)))
-		for _, issue := range analysis.SecurityIssues {
-			result = append(result, fmt.Sprintf("  • %s: %s", issue.Type, issue.Description))
-		}
-	}
-
-	// 改善提案
-	if len(analysis.Recommendations) > 0 {
-		result = append(result, fmt.Sprintf("💡 **改善提案 (%d件)**", len(analysis.Recommendations)))
-		for i, rec := range analysis.Recommendations {
-			if i < 5 { // 最初の5件のみ表示
-				result = append(result, fmt.Sprintf("  • %s: %s", rec.Type, rec.Description))
-			}
-		}
-		if len(analysis.Recommendations) > 5 {
-			result = append(result, fmt.Sprintf("  • ... および%d件の追加提案", len(analysis.Recommendations)-5))
-		}
-	}
-
-	return strings.Join(result, "\n")
-}
-
-// formatNumber は数値を読みやすい形式でフォーマット
-func (ism *interactiveSessionManager) formatNumber(num int) string {
-	if num < 1000 {
-		return fmt.Sprintf("%d", num)
-	} else if num < 1000000 {
-		return fmt.Sprintf("%.1fk", float64(num)/1000)
-	} else {
-		return fmt.Sprintf("%.1fM", float64(num)/1000000)
-	}
-}
-
-// performDetailedGitAnalysis は詳細なGit分析を実行
-func (ism *interactiveSessionManager) performDetailedGitAnalysis() string {
-	if ism.bashTool == nil {
-		return ""
-	}
-
-	var gitResults []string
-
-	// Git状態の詳細分析
-	if result, err := ism.bashTool.Execute("git status --porcelain -b", "Git detailed status", 5000); err == nil && !result.IsError {
-		lines := strings.Split(strings.TrimSpace(result.Content), "\n")
-		if len(lines) > 0 && strings.HasPrefix(lines[0], "##") {
-			branchInfo := strings.TrimPrefix(lines[0], "## ")
-			gitResults = append(gitResults, fmt.Sprintf("🌿 **ブランチ**: %s", branchInfo))
-		}
-
-		modifiedCount := 0
-		addedCount := 0
-		deletedCount := 0
-		for _, line := range lines[1:] {
-			if len(line) >= 2 {
-				status := line[:2]
-				if strings.Contains(status, "M") {
-					modifiedCount++
-				}
-				if strings.Contains(status, "A") {
-					addedCount++
-				}
-				if strings.Contains(status, "D") {
-					deletedCount++
-				}
-			}
-		}
-
-		if modifiedCount+addedCount+deletedCount > 0 {
-			gitResults = append(gitResults, fmt.Sprintf("📝 **変更統計**: 変更 %d, 追加 %d, 削除 %d", modifiedCount, addedCount, deletedCount))
-		}
-	}
-
-	// コミット履歴分析
-	if result, err := ism.bashTool.Execute("git log --oneline -10 --no-merges", "Recent commits", 5000); err == nil && !result.IsError {
-		commitLines := strings.Split(strings.TrimSpace(result.Content), "\n")
-		if len(commitLines) > 0 {
-			gitResults = append(gitResults, fmt.Sprintf("📋 **最近のコミット** (%d件):", len(commitLines)))
-			for i, commit := range commitLines {
-				if i < 3 { // 最新3件のみ表示
-					gitResults = append(gitResults, fmt.Sprintf("  • %s", commit))
-				}
-			}
-		}
-	}
-
-	// ブランチ分析
-	if result, err := ism.bashTool.Execute("git branch -a", "Git branches", 3000); err == nil && !result.IsError {
-		branches := strings.Split(strings.TrimSpace(result.Content), "\n")
-		localBranches := 0
-		remoteBranches := 0
-		for _, branch := range branches {
-			branch = strings.TrimSpace(branch)
-			if strings.HasPrefix(branch, "remotes/") {
-				remoteBranches++
-			} else if branch != "" {
-				localBranches++
-			}
-		}
-		gitResults = append(gitResults, fmt.Sprintf("🌳 **ブランチ**: ローカル %d, リモート %d", localBranches, remoteBranches))
-	}
-
-	if len(gitResults) > 0 {
-		return strings.Join(gitResults, "\n")
-	}
-
-	return ""
-}
-
 // addToSmartContext はSmartContextManagerを活用してコンテキストを追加
 func (ism *interactiveSessionManager) addToSmartContext(sessionID, content, contentType string) {
 	if ism.contextManager == nil {
@@ -1799,6 +1880,9 @@ func (ism *interactiveSessionManager) getOptimizedContext(sessionID, query strin
 		return "（コンテキスト取得エラー）"
 	}
 
+	// 提案の根拠として記録（/why）
+	ism.noteRetrievedContext(sessionID, contextItems)
+
 	// コンテキストアイテムがない場合
 	if len(contextItems) == 0 {
 		return "（コンテキストなし）"
@@ -1846,54 +1930,6 @@ func (ism *interactiveSessionManager) getOptimizedContext(sessionID, query strin
 	return strings.Join(result, "\n")
 }
 
-// performCognitiveReasoning はCognitiveEngineを活用した高度な推論を実行
-func (ism *interactiveSessionManager) performCognitiveReasoning(ctx context.Context, input, intent string) map[string]interface{} {
-	if ism.cognitiveEngine == nil {
-		return map[string]interface{}{
-			"status": "cognitive_engine_unavailable",
-		}
-	}
-
-	// タイムアウト付きで推論を実行
-	reasoningCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
-	defer cancel()
-
-	// 推論を実行
-	reasoningResult, err := ism.cognitiveEngine.ProcessUserInput(reasoningCtx, input)
-	if err != nil {
-		return map[string]interface{}{
-			"status": "reasoning_failed",
-			"error":  err.Error(),
-		}
-	}
-
-	// 推論結果を分析
-	insights := map[string]interface{}{
-		"status":          "reasoning_completed",
-		"confidence":      reasoningResult.Confidence,
-		"processing_time": reasoningResult.ProcessingTime,
-	}
-
-	// 推論チェーンの情報を追加
-	if len(reasoningResult.InferenceChains) > 0 {
-		insights["inference_chains_count"] = len(reasoningResult.InferenceChains)
-		// 推論チェーンの詳細は必要に応じて追加
-	}
-
-	// 学習的洞察があれば追加
-	if len(reasoningResult.Insights) > 0 {
-		insights["learning_insights_count"] = len(reasoningResult.Insights)
-	}
-
-	// 選択された解決策の情報
-	if reasoningResult.SelectedSolution != nil {
-		insights["solution_approach"] = reasoningResult.SelectedSolution.Approach
-		insights["solution_confidence"] = reasoningResult.SelectedSolution.Confidence
-	}
-
-	return insights
-}
-
 // generateNextStepSuggestion は実行結果を分析して具体的な次のステップ提案を生成
 func (ism *interactiveSessionManager) generateNextStepSuggestion(executedActions []string, results []string) string {
 	if len(executedActions) == 0 {
@@ -1941,1862 +1977,152 @@ func (ism *interactiveSessionManager) generateNextStepSuggestion(executedActions
 	return "💡 **次のステップ:** 他にご質問や作業があればお聞かせください。"
 }
 
-// analyzeGitStatus はgit statusの結果を分析して具体的提案を生成
-func (ism *interactiveSessionManager) analyzeGitStatus(gitOutput string) []string {
-	var suggestions []string
-
-	// 変更されたファイル数をカウント
-	modifiedFiles := strings.Count(gitOutput, "modified:")
-	untrackedDirs := strings.Count(gitOutput, "/")
+// ヘルパーメソッド
 
-	if strings.Contains(gitOutput, "Changes not staged for commit") {
-		if modifiedFiles > 10 {
-			suggestions = append(suggestions, fmt.Sprintf("多数の変更ファイル(%d個)があります。`git add .` で一括ステージング", modifiedFiles))
-		} else if modifiedFiles > 0 {
-			suggestions = append(suggestions, "変更されたファイルを確認し、必要に応じて `git add <ファイル名>` でステージング")
-		}
+// sessionTypeToString はセッションタイプを文字列に変換
+func (ism *interactiveSessionManager) sessionTypeToString(sessionType CodingSessionType) string {
+	return i18n.T("session.type." + ism.sessionTypeKey(sessionType))
+}
 
-		// 変更内容の詳細分析を提案
-		suggestions = append(suggestions, "実際の変更内容を確認: `git diff` で詳細な差分を表示")
-		suggestions = append(suggestions, "特定ファイルの変更内容を確認: `git diff <ファイル名>`")
+// sessionTypeKey はテンプレート選択用のセッションタイプ識別子を返す
+func (ism *interactiveSessionManager) sessionTypeKey(sessionType CodingSessionType) string {
+	switch sessionType {
+	case CodingSessionTypeDebugging:
+		return "debugging"
+	case CodingSessionTypeRefactor:
+		return "refactor"
+	case CodingSessionTypeReview:
+		return "review"
+	case CodingSessionTypeLearning:
+		return "learning"
+	default:
+		return "general"
 	}
+}
 
-	if strings.Contains(gitOutput, "Untracked files") {
-		if untrackedDirs > 0 {
-			suggestions = append(suggestions, "新しいディレクトリが追加されています。内容を確認して `git add` でトラッキング")
-		}
-		suggestions = append(suggestions, "未追跡ファイルの内容を確認し、必要に応じてGitに追加")
+// suggestionTypeToString は提案タイプを文字列に変換
+func (ism *interactiveSessionManager) suggestionTypeToString(suggestionType SuggestionType) string {
+	switch suggestionType {
+	case SuggestionTypeBugFix:
+		return "バグ修正"
+	case SuggestionTypeOptimization:
+		return "パフォーマンス最適化"
+	case SuggestionTypeRefactoring:
+		return "リファクタリング"
+	case SuggestionTypeDocumentation:
+		return "ドキュメント追加"
+	case SuggestionTypeSecurity:
+		return "セキュリティ修正"
+	case SuggestionTypeTestGeneration:
+		return "テスト生成"
+	default:
+		return "改善提案"
 	}
+}
 
-	if strings.Contains(gitOutput, "no changes added to commit") {
-		suggestions = append(suggestions, "変更をステージング後、`git commit -m \"説明\"` でコミット作成")
+// formatContextForPrompt はコンテキストをプロンプト用に整形
+func (ism *interactiveSessionManager) formatContextForPrompt(context []*contextmanager.ContextItem) string {
+	if len(context) == 0 {
+		return "関連コンテキストなし"
 	}
 
-	if strings.Contains(gitOutput, "feature/") {
-		suggestions = append(suggestions, "機能ブランチでの作業中です。完了後はメインブランチへのマージを検討")
+	var formatted strings.Builder
+	for i, item := range context {
+		formatted.WriteString(fmt.Sprintf("%d. %s\n", i+1, item.Content))
 	}
 
-	return suggestions
+	return formatted.String()
 }
 
-// analyzeGitDiff はgit diffの結果を分析してコード変更の意味を理解
-func (ism *interactiveSessionManager) analyzeGitDiff(diffOutput string) []string {
-	var suggestions []string
-
-	if strings.TrimSpace(diffOutput) == "" {
-		suggestions = append(suggestions, "変更はありません。新しい機能の実装を検討しましょう")
-		return suggestions
+// updateAverageResponseTime は平均応答時間を更新
+func (ism *interactiveSessionManager) updateAverageResponseTime(
+	currentAverage time.Duration,
+	newTime time.Duration,
+	totalCount int,
+) time.Duration {
+	if totalCount <= 1 {
+		return newTime
 	}
 
-	// 変更の意味を分析
-	changeAnalysis := ism.analyzeChangeSemantics(diffOutput)
-
-	// 分析結果に基づく智能的な提案を生成
-	suggestions = ism.generateSemanticSuggestions(changeAnalysis)
-
-	return suggestions
-}
-
-// ChangeSemantics は変更の意味を表現する構造体
-type ChangeSemantics struct {
-	ChangeType         string                 // 変更の種類（feature, fix, refactor, docs等）
-	AffectedAreas      []string               // 影響を受ける領域
-	RiskLevel          string                 // リスクレベル（low, medium, high, critical）
-	TestRequirements   []string               // 必要なテスト
-	ReviewPoints       []string               // レビューすべき点
-	Dependencies       []string               // 影響する依存関係
-	ArchitectureImpact string                 // アーキテクチャへの影響
-	QualityMetrics     map[string]interface{} // 品質メトリクス
+	total := currentAverage*time.Duration(totalCount-1) + newTime
+	return total / time.Duration(totalCount)
 }
 
-// analyzeChangeSemantics は変更の意味を分析
-func (ism *interactiveSessionManager) analyzeChangeSemantics(diffOutput string) *ChangeSemantics {
-	analysis := &ChangeSemantics{
-		AffectedAreas:    []string{},
-		TestRequirements: []string{},
-		ReviewPoints:     []string{},
-		Dependencies:     []string{},
-		QualityMetrics:   make(map[string]interface{}),
+// abs は絶対値を返す
+func abs(x int) int {
+	if x < 0 {
+		return -x
 	}
-
-	// 1. 変更の種類を判定
-	analysis.ChangeType = ism.detectChangeType(diffOutput)
-
-	// 2. 影響領域の分析
-	analysis.AffectedAreas = ism.identifyAffectedAreas(diffOutput)
-
-	// 3. リスクレベルの評価
-	analysis.RiskLevel = ism.evaluateRiskLevel(diffOutput, analysis.AffectedAreas)
-
-	// 4. テスト要件の特定
-	analysis.TestRequirements = ism.identifyTestRequirements(diffOutput, analysis.ChangeType)
-
-	// 5. レビューポイントの抽出
-	analysis.ReviewPoints = ism.extractReviewPoints(diffOutput, analysis.ChangeType, analysis.RiskLevel)
-
-	// 6. 依存関係への影響
-	analysis.Dependencies = ism.analyzeDependencyImpact(diffOutput)
-
-	// 7. アーキテクチャ影響の評価
-	analysis.ArchitectureImpact = ism.evaluateArchitectureImpact(diffOutput, analysis.AffectedAreas)
-
-	return analysis
+	return x
 }
 
-// detectChangeType は変更の種類を検出
-func (ism *interactiveSessionManager) detectChangeType(diffOutput string) string {
-	// 新機能追加の検出
-	if strings.Contains(diffOutput, "+func New") || strings.Contains(diffOutput, "+type ") ||
-		strings.Contains(diffOutput, "+// API:") {
-		return "feature"
-	}
-
-	// バグ修正の検出
-	if strings.Contains(diffOutput, "fix") || strings.Contains(diffOutput, "bug") ||
-		strings.Contains(diffOutput, "-\t\treturn err") || strings.Contains(diffOutput, "+\t\treturn fmt.Errorf") {
-		return "fix"
-	}
+// calculateSuggestionConfidence は提案の信頼度を計算
+func (ism *interactiveSessionManager) calculateSuggestionConfidence(
+	session *InteractiveSession,
+	request *SuggestionRequest,
+	context []*contextmanager.ContextItem,
+) float64 {
+	return ism.explainSuggestionConfidence(session, request, context).Score
+}
 
-	// リファクタリングの検出
-	if strings.Contains(diffOutput, "-func ") && strings.Contains(diffOutput, "+func ") {
-		return "refactor"
-	}
+// explainSuggestionConfidence は提案の信頼度を要因毎の内訳付きで計算（/why で表示する）
+func (ism *interactiveSessionManager) explainSuggestionConfidence(
+	session *InteractiveSession,
+	request *SuggestionRequest,
+	context []*contextmanager.ContextItem,
+) ConfidenceExplanation {
+	explanation := ConfidenceExplanation{Score: 0.5, Base: 0.5, BaseReason: i18n.T("why.factor.base")} // ベース信頼度
 
-	// ドキュメント更新の検出
-	if strings.Contains(diffOutput, "README.md") || strings.Contains(diffOutput, "CLAUDE.md") ||
-		strings.Contains(diffOutput, "+//") {
-		return "docs"
+	// コンテキストの豊富さによる調整
+	if len(context) > 5 {
+		explanation.add("context", 0.2, i18n.T("why.factor.context", len(context)))
 	}
 
-	// テスト追加の検出
-	if strings.Contains(diffOutput, "_test.go") || strings.Contains(diffOutput, "+\tfunc Test") {
-		return "test"
+	// 提案タイプによる調整
+	switch request.Type {
+	case SuggestionTypeBugFix:
+		explanation.add("type", 0.1, i18n.T("why.factor.bugfix")) // バグ修正は比較的信頼度高
+	case SuggestionTypeOptimization:
+		explanation.add("type", -0.1, i18n.T("why.factor.optimization")) // パフォーマンス最適化は慎重に
+	case SuggestionTypeSecurity:
+		explanation.add("type", 0.15, i18n.T("why.factor.security")) // セキュリティは重要
 	}
 
-	// 設定変更の検出
-	if strings.Contains(diffOutput, "config") || strings.Contains(diffOutput, ".json") ||
-		strings.Contains(diffOutput, ".yaml") {
-		return "config"
+	// セッション履歴による調整
+	if session.Metrics.SuggestionsAccepted > session.Metrics.SuggestionsRejected {
+		explanation.add("history", 0.1, i18n.T("why.factor.history",
+			session.Metrics.SuggestionsAccepted, session.Metrics.SuggestionsRejected))
 	}
 
-	return "general"
+	return explanation
 }
 
-// identifyAffectedAreas は影響を受ける領域を特定
-func (ism *interactiveSessionManager) identifyAffectedAreas(diffOutput string) []string {
-	areas := []string{}
-
-	if strings.Contains(diffOutput, "internal/llm/") {
-		areas = append(areas, "LLM統合レイヤー")
-	}
-	if strings.Contains(diffOutput, "internal/handlers/chat.go") {
-		areas = append(areas, "チャット機能")
-	}
-	if strings.Contains(diffOutput, "internal/interactive/") {
-		areas = append(areas, "インタラクティブ機能")
-	}
-	if strings.Contains(diffOutput, "internal/tools/") {
-		areas = append(areas, "ツール機能")
-	}
-	if strings.Contains(diffOutput, "internal/config/") {
-		areas = append(areas, "設定システム")
-	}
-	if strings.Contains(diffOutput, "internal/security/") {
-		areas = append(areas, "セキュリティ層")
-	}
-	if strings.Contains(diffOutput, "cmd/vyb/") {
-		areas = append(areas, "CLI インターフェース")
-	}
-
-	return areas
-}
-
-// evaluateRiskLevel はリスクレベルを評価
-func (ism *interactiveSessionManager) evaluateRiskLevel(diffOutput string, affectedAreas []string) string {
-	// 重要なファイルの変更
-	if strings.Contains(diffOutput, "internal/security/") ||
-		strings.Contains(diffOutput, "password") || strings.Contains(diffOutput, "auth") {
-		return "critical"
-	}
-
-	// 複数の重要領域に影響
-	if len(affectedAreas) >= 3 {
-		return "high"
-	}
-
-	// コア機能への影響
-	if strings.Contains(diffOutput, "internal/llm/") ||
-		strings.Contains(diffOutput, "internal/handlers/chat.go") {
-		return "medium"
-	}
-
-	// ドキュメントやテストのみ
-	if strings.Contains(diffOutput, "README.md") || strings.Contains(diffOutput, "_test.go") {
-		return "low"
-	}
-
-	return "medium"
-}
-
-// identifyTestRequirements はテスト要件を特定
-func (ism *interactiveSessionManager) identifyTestRequirements(diffOutput string, changeType string) []string {
-	requirements := []string{}
-
-	switch changeType {
-	case "feature":
-		requirements = append(requirements, "新機能の単体テスト作成")
-		requirements = append(requirements, "統合テストシナリオの追加")
-		requirements = append(requirements, "エッジケースのテスト")
-	case "fix":
-		requirements = append(requirements, "バグ再現テストの作成")
-		requirements = append(requirements, "リグレッションテストの実行")
-	case "refactor":
-		requirements = append(requirements, "既存テストの動作確認")
-		requirements = append(requirements, "パフォーマンステストの実行")
-	case "config":
-		requirements = append(requirements, "設定値のバリデーションテスト")
-		requirements = append(requirements, "異常設定時の動作確認")
-	}
-
-	// コード内容に基づく追加テスト
-	if strings.Contains(diffOutput, "error") {
-		requirements = append(requirements, "エラーハンドリングのテスト")
-	}
-	if strings.Contains(diffOutput, "concurrent") || strings.Contains(diffOutput, "goroutine") {
-		requirements = append(requirements, "並行処理の競合状態テスト")
-	}
-
-	return requirements
-}
-
-// extractReviewPoints はレビューポイントを抽出
-func (ism *interactiveSessionManager) extractReviewPoints(diffOutput string, changeType string, riskLevel string) []string {
-	points := []string{}
-
-	// リスクレベル別のレビューポイント
-	switch riskLevel {
-	case "critical":
-		points = append(points, "セキュリティ影響の詳細確認")
-		points = append(points, "権限昇格の可能性チェック")
-		points = append(points, "データ漏洩リスクの評価")
-	case "high":
-		points = append(points, "アーキテクチャ設計との整合性確認")
-		points = append(points, "パフォーマンス影響の測定")
-		points = append(points, "後方互換性の保証")
-	case "medium":
-		points = append(points, "コード品質と可読性の確認")
-		points = append(points, "エラーハンドリングの適切性")
-	case "low":
-		points = append(points, "ドキュメントの正確性確認")
-		points = append(points, "コードスタイルの統一")
-	}
-
-	// 特定パターンのレビューポイント
-	if strings.Contains(diffOutput, "+import") {
-		points = append(points, "新しい依存関係の必要性と安全性")
-	}
-	if strings.Contains(diffOutput, "TODO") || strings.Contains(diffOutput, "FIXME") {
-		points = append(points, "残存する技術的負債の対応計画")
-	}
-
-	return points
-}
-
-// analyzeDependencyImpact は依存関係への影響を分析
-func (ism *interactiveSessionManager) analyzeDependencyImpact(diffOutput string) []string {
-	dependencies := []string{}
-
-	if strings.Contains(diffOutput, "go.mod") {
-		dependencies = append(dependencies, "Go モジュール依存関係の更新")
-	}
-	if strings.Contains(diffOutput, "+import") {
-		dependencies = append(dependencies, "新規パッケージ依存の追加")
-	}
-	if strings.Contains(diffOutput, "internal/llm/") {
-		dependencies = append(dependencies, "LLM プロバイダー統合への影響")
-	}
-	if strings.Contains(diffOutput, "internal/tools/") {
-		dependencies = append(dependencies, "ツールチェーン統合への影響")
-	}
-
-	return dependencies
-}
-
-// evaluateArchitectureImpact はアーキテクチャ影響を評価
-func (ism *interactiveSessionManager) evaluateArchitectureImpact(diffOutput string, affectedAreas []string) string {
-	if len(affectedAreas) >= 4 {
-		return "システム全体アーキテクチャに重大な変更。設計レビュー必須"
-	}
-
-	if strings.Contains(diffOutput, "interface") && strings.Contains(diffOutput, "+") {
-		return "新しいインターフェース追加。契約設計の確認が必要"
-	}
-
-	if strings.Contains(diffOutput, "internal/") && len(affectedAreas) >= 2 {
-		return "内部アーキテクチャの結合度に影響。モジュール境界の再検討推奨"
-	}
-
-	if strings.Contains(diffOutput, "config") {
-		return "設定アーキテクチャの変更。運用環境への影響確認必要"
-	}
-
-	return "局所的変更。アーキテクチャ影響は限定的"
-}
-
-// generateSemanticSuggestions は意味解析に基づく提案を生成
-func (ism *interactiveSessionManager) generateSemanticSuggestions(analysis *ChangeSemantics) []string {
-	suggestions := []string{}
-
-	// 変更タイプ別の主要メッセージ
-	switch analysis.ChangeType {
-	case "feature":
-		suggestions = append(suggestions, "🚀 新機能開発: "+strings.Join(analysis.AffectedAreas, ", ")+"への機能追加を検出")
-	case "fix":
-		suggestions = append(suggestions, "🔧 バグ修正: 品質向上のための修正を実施")
-	case "refactor":
-		suggestions = append(suggestions, "♻️ リファクタリング: コード品質改善を検出")
-	case "docs":
-		suggestions = append(suggestions, "📚 ドキュメント更新: プロジェクト情報の最新化")
-	case "config":
-		suggestions = append(suggestions, "⚙️ 設定変更: システム動作に影響する設定の変更")
-	default:
-		suggestions = append(suggestions, "🔄 一般的な変更: "+strings.Join(analysis.AffectedAreas, ", ")+"の更新")
-	}
-
-	// リスクレベル別の警告
-	switch analysis.RiskLevel {
-	case "critical":
-		suggestions = append(suggestions, "⚠️ 【重要】クリティカル変更: 慎重なレビューとテストが必須")
-	case "high":
-		suggestions = append(suggestions, "⚡ 高影響変更: 十分なテストとレビューを実施")
-	case "medium":
-		suggestions = append(suggestions, "📋 中程度の影響: 標準的なレビュープロセスを実施")
-	}
-
-	// アーキテクチャ影響
-	if analysis.ArchitectureImpact != "局所的変更。アーキテクチャ影響は限定的" {
-		suggestions = append(suggestions, "🏗️ アーキテクチャ影響: "+analysis.ArchitectureImpact)
-	}
-
-	// テスト要件
-	if len(analysis.TestRequirements) > 0 {
-		suggestions = append(suggestions, "🧪 推奨テスト: "+strings.Join(analysis.TestRequirements, ", "))
-	}
-
-	// レビューポイント
-	if len(analysis.ReviewPoints) > 0 {
-		suggestions = append(suggestions, "👁️ レビューポイント: "+strings.Join(analysis.ReviewPoints, ", "))
-	}
-
-	// 依存関係への影響
-	if len(analysis.Dependencies) > 0 {
-		suggestions = append(suggestions, "🔗 依存関係: "+strings.Join(analysis.Dependencies, ", "))
-	}
-
-	// 次ステップの提案
-	suggestions = append(suggestions, "✅ 推奨次ステップ: 変更内容確認後、適切なテスト実行とコードレビュー実施")
-
-	return suggestions
-}
-
-// summarizeGitDiff はgit diffの出力を詳細分析して要約する
-func (ism *interactiveSessionManager) summarizeGitDiff(diffOutput string) string {
-	if strings.TrimSpace(diffOutput) == "" {
-		return "変更はありません。"
-	}
-
-	// 詳細分析を実行
-	analysis := ism.performDetailedDiffAnalysis(diffOutput)
-
-	// 結果をフォーマット
-	summary := fmt.Sprintf("📊 **変更サマリー**\n")
-	summary += fmt.Sprintf("• ファイル数: %d個  ", len(analysis.ChangedFiles))
-	summary += fmt.Sprintf("• 変更規模: +%d行, -%d行  ", analysis.AddedLines, analysis.DeletedLines)
-	summary += fmt.Sprintf("• リスクレベル: %s\n", ism.formatRiskLevel(analysis.RiskLevel))
-
-	// ファイル別詳細情報
-	if len(analysis.FileSummaries) > 0 {
-		summary += "\n📝 **変更ファイル詳細:**\n"
-		for i, fileSummary := range analysis.FileSummaries {
-			if i >= 6 { // 最大6個まで詳細表示
-				summary += fmt.Sprintf("• ... その他 %d個のファイル\n", len(analysis.FileSummaries)-6)
-				break
-			}
-
-			icon := ism.getFileTypeIcon(fileSummary.Path)
-			summary += fmt.Sprintf("• %s **%s** (+%d/-%d行) %s\n",
-				icon, fileSummary.Path, fileSummary.AddedLines, fileSummary.DeletedLines, fileSummary.ChangeType)
-
-			// 重要な変更内容を表示
-			if len(fileSummary.KeyChanges) > 0 {
-				for _, change := range fileSummary.KeyChanges[:min(2, len(fileSummary.KeyChanges))] {
-					summary += fmt.Sprintf("  └ %s\n", change)
-				}
-			}
-		}
-	}
-
-	// 影響度分析
-	if len(analysis.ImpactAreas) > 0 {
-		summary += "\n🎯 **影響領域:**\n"
-		for _, area := range analysis.ImpactAreas {
-			summary += fmt.Sprintf("• %s %s\n", area.Icon, area.Description)
-		}
-	}
-
-	// 具体的な技術的変更
-	if len(analysis.TechnicalChanges) > 0 {
-		summary += "\n🔧 **技術的変更:**\n"
-		for _, change := range analysis.TechnicalChanges {
-			summary += fmt.Sprintf("• %s\n", change)
-		}
-	}
-
-	// セキュリティ・品質の注意点
-	if len(analysis.SecurityConcerns) > 0 || len(analysis.QualityIssues) > 0 {
-		summary += "\n⚠️ **要注意:**\n"
-		for _, concern := range analysis.SecurityConcerns {
-			summary += fmt.Sprintf("• 🔐 %s\n", concern)
-		}
-		for _, issue := range analysis.QualityIssues {
-			summary += fmt.Sprintf("• 📊 %s\n", issue)
-		}
-	}
-
-	// パフォーマンス影響
-	if analysis.PerformanceImpact != "" {
-		summary += fmt.Sprintf("\n⚡ **パフォーマンス影響:** %s\n", analysis.PerformanceImpact)
-	}
-
-	summary += "\n💡 個別ファイルの詳細: `git diff <ファイル名>` | 全diff確認: `git diff --no-pager`"
-
-	return summary
-}
-
-// DetailedDiffAnalysis は詳細なdiff分析結果
-type DetailedDiffAnalysis struct {
-	ChangedFiles      []string
-	AddedLines        int
-	DeletedLines      int
-	RiskLevel         string
-	FileSummaries     []FileSummary
-	ImpactAreas       []ImpactArea
-	TechnicalChanges  []string
-	SecurityConcerns  []string
-	QualityIssues     []string
-	PerformanceImpact string
-}
-
-// FileSummary はファイル別の変更サマリー
-type FileSummary struct {
-	Path         string
-	AddedLines   int
-	DeletedLines int
-	ChangeType   string
-	KeyChanges   []string
-}
-
-// ImpactArea は影響領域
-type ImpactArea struct {
-	Icon        string
-	Description string
-}
-
-// performDetailedDiffAnalysis は詳細なdiff分析を実行
-func (ism *interactiveSessionManager) performDetailedDiffAnalysis(diffOutput string) *DetailedDiffAnalysis {
-	analysis := &DetailedDiffAnalysis{
-		ChangedFiles:     ism.extractChangedFilesFromDiff(diffOutput),
-		AddedLines:       strings.Count(diffOutput, "\n+") - strings.Count(diffOutput, "\n+++"),
-		DeletedLines:     strings.Count(diffOutput, "\n-") - strings.Count(diffOutput, "\n---"),
-		FileSummaries:    []FileSummary{},
-		ImpactAreas:      []ImpactArea{},
-		TechnicalChanges: []string{},
-		SecurityConcerns: []string{},
-		QualityIssues:    []string{},
-	}
-
-	// リスクレベル評価
-	analysis.RiskLevel = ism.calculateRiskLevel(analysis.AddedLines, analysis.DeletedLines, analysis.ChangedFiles)
-
-	// ファイル別分析
-	analysis.FileSummaries = ism.analyzeIndividualFiles(diffOutput, analysis.ChangedFiles)
-
-	// 影響領域の特定
-	analysis.ImpactAreas = ism.identifyImpactAreas(analysis.ChangedFiles, diffOutput)
-
-	// 技術的変更の抽出
-	analysis.TechnicalChanges = ism.extractTechnicalChanges(diffOutput)
-
-	// セキュリティ・品質チェック
-	analysis.SecurityConcerns = ism.identifySecurityConcerns(diffOutput)
-	analysis.QualityIssues = ism.identifyQualityIssues(diffOutput, analysis)
-
-	// パフォーマンス影響評価
-	analysis.PerformanceImpact = ism.evaluatePerformanceImpact(diffOutput, analysis.ChangedFiles)
-
-	return analysis
-}
-
-// calculateRiskLevel はリスクレベルを計算
-func (ism *interactiveSessionManager) calculateRiskLevel(addedLines, deletedLines int, changedFiles []string) string {
-	totalChange := addedLines + deletedLines
-
-	// 重要なファイルのチェック
-	hasSecurityFile := false
-	hasCoreFile := false
-	for _, file := range changedFiles {
-		if strings.Contains(file, "security") || strings.Contains(file, "auth") {
-			hasSecurityFile = true
-		}
-		if strings.Contains(file, "main.go") || strings.Contains(file, "session.go") {
-			hasCoreFile = true
-		}
-	}
-
-	if hasSecurityFile || totalChange > 500 {
-		return "🔴 HIGH"
-	} else if hasCoreFile || totalChange > 200 || len(changedFiles) > 8 {
-		return "🟡 MEDIUM"
-	}
-	return "🟢 LOW"
-}
-
-// analyzeIndividualFiles はファイル別の詳細分析
-func (ism *interactiveSessionManager) analyzeIndividualFiles(diffOutput string, changedFiles []string) []FileSummary {
-	summaries := []FileSummary{}
-
-	for _, file := range changedFiles {
-		// ファイル別の変更行数を計算
-		fileSection := ism.extractFileSection(diffOutput, file)
-		addedLines := strings.Count(fileSection, "\n+") - strings.Count(fileSection, "\n+++")
-		deletedLines := strings.Count(fileSection, "\n-") - strings.Count(fileSection, "\n---")
-
-		// 変更タイプを判定
-		changeType := ism.determineChangeType(fileSection, file)
-
-		// 主要な変更を抽出
-		keyChanges := ism.extractKeyChanges(fileSection, file)
-
-		summaries = append(summaries, FileSummary{
-			Path:         file,
-			AddedLines:   addedLines,
-			DeletedLines: deletedLines,
-			ChangeType:   changeType,
-			KeyChanges:   keyChanges,
-		})
-	}
-
-	return summaries
-}
-
-// extractFileSection はファイル別のdiff部分を抽出
-func (ism *interactiveSessionManager) extractFileSection(diffOutput, fileName string) string {
-	lines := strings.Split(diffOutput, "\n")
-	var fileLines []string
-	inFile := false
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "diff --git") && strings.Contains(line, fileName) {
-			inFile = true
-			fileLines = []string{line}
-		} else if strings.HasPrefix(line, "diff --git") && inFile {
-			break
-		} else if inFile {
-			fileLines = append(fileLines, line)
-		}
-	}
-
-	return strings.Join(fileLines, "\n")
-}
-
-// determineChangeType は変更タイプを判定
-func (ism *interactiveSessionManager) determineChangeType(fileSection, fileName string) string {
-	if strings.Contains(fileSection, "+func New") {
-		return "新機能追加"
-	} else if strings.Contains(fileSection, "+type ") && strings.Contains(fileSection, "struct") {
-		return "構造拡張"
-	} else if strings.Contains(fileSection, "test") {
-		return "テスト更新"
-	} else if strings.Contains(fileName, "config") {
-		return "設定変更"
-	} else if strings.Contains(fileName, ".md") {
-		return "ドキュメント"
-	} else if strings.Contains(fileSection, "-") && strings.Contains(fileSection, "+") {
-		return "リファクタ"
-	} else if strings.Count(fileSection, "+") > strings.Count(fileSection, "-") {
-		return "機能拡張"
-	}
-	return "修正・改善"
-}
-
-// extractKeyChanges は主要な変更を抽出
-func (ism *interactiveSessionManager) extractKeyChanges(fileSection, fileName string) []string {
-	changes := []string{}
-
-	// 新しい関数
-	if funcMatches := regexp.MustCompile(`\+func\s+(\w+)`).FindAllStringSubmatch(fileSection, -1); len(funcMatches) > 0 {
-		if len(funcMatches) <= 3 {
-			for _, match := range funcMatches {
-				changes = append(changes, fmt.Sprintf("新関数: %s()", match[1]))
-			}
-		} else {
-			changes = append(changes, fmt.Sprintf("%d個の新しい関数を追加", len(funcMatches)))
-		}
-	}
-
-	// 新しい構造体
-	if structMatches := regexp.MustCompile(`\+type\s+(\w+)\s+struct`).FindAllStringSubmatch(fileSection, -1); len(structMatches) > 0 {
-		for _, match := range structMatches {
-			changes = append(changes, fmt.Sprintf("新構造体: %s", match[1]))
-		}
-	}
-
-	// インポート変更
-	if strings.Contains(fileSection, "+\t\"") {
-		importCount := strings.Count(fileSection, "+\t\"")
-		changes = append(changes, fmt.Sprintf("%d個のパッケージを新規導入", importCount))
-	}
-
-	// エラーハンドリング改善
-	if strings.Contains(fileSection, "fmt.Errorf") || strings.Contains(fileSection, "errors.New") {
-		changes = append(changes, "エラーハンドリング強化")
-	}
-
-	return changes
-}
-
-// formatRiskLevel はリスクレベルをフォーマット
-func (ism *interactiveSessionManager) formatRiskLevel(riskLevel string) string {
-	switch riskLevel {
-	case "🔴 HIGH":
-		return "🔴 HIGH (要慎重レビュー)"
-	case "🟡 MEDIUM":
-		return "🟡 MEDIUM (標準レビュー)"
-	default:
-		return "🟢 LOW (軽微な変更)"
-	}
-}
-
-// identifyImpactAreas は影響領域を特定
-func (ism *interactiveSessionManager) identifyImpactAreas(changedFiles []string, diffOutput string) []ImpactArea {
-	areas := []ImpactArea{}
-	areaMap := make(map[string]bool)
-
-	for _, file := range changedFiles {
-		if strings.Contains(file, "internal/handlers/chat.go") && !areaMap["chat"] {
-			areas = append(areas, ImpactArea{Icon: "💬", Description: "チャット・会話システム"})
-			areaMap["chat"] = true
-		}
-		if strings.Contains(file, "internal/interactive/") && !areaMap["interactive"] {
-			areas = append(areas, ImpactArea{Icon: "🎯", Description: "インタラクティブ機能"})
-			areaMap["interactive"] = true
-		}
-		if strings.Contains(file, "internal/config/") && !areaMap["config"] {
-			areas = append(areas, ImpactArea{Icon: "⚙️", Description: "設定・構成管理"})
-			areaMap["config"] = true
-		}
-		if strings.Contains(file, "cmd/") && !areaMap["cli"] {
-			areas = append(areas, ImpactArea{Icon: "🖥️", Description: "CLI インターフェース"})
-			areaMap["cli"] = true
-		}
-		if strings.Contains(file, "internal/tools/") && !areaMap["tools"] {
-			areas = append(areas, ImpactArea{Icon: "🔧", Description: "ツール・ユーティリティ"})
-			areaMap["tools"] = true
-		}
-		if strings.Contains(file, "internal/handlers/") && !areaMap["handlers"] {
-			areas = append(areas, ImpactArea{Icon: "🎛️", Description: "ハンドラー・処理制御"})
-			areaMap["handlers"] = true
-		}
-	}
-
-	return areas
-}
-
-// extractTechnicalChanges は技術的変更を抽出
-func (ism *interactiveSessionManager) extractTechnicalChanges(diffOutput string) []string {
-	changes := []string{}
-
-	// 同期・並行処理の追加
-	if strings.Contains(diffOutput, "+sync.") || strings.Contains(diffOutput, "+go func") {
-		changes = append(changes, "並行処理・同期機能の追加")
-	}
-
-	// エラーハンドリング強化
-	if strings.Contains(diffOutput, "+\t\treturn fmt.Errorf") {
-		errorCount := strings.Count(diffOutput, "+\t\treturn fmt.Errorf")
-		changes = append(changes, fmt.Sprintf("エラーハンドリング改善 (%d箇所)", errorCount))
-	}
-
-	// 新しいインターフェース追加
-	if strings.Contains(diffOutput, "+type ") && strings.Contains(diffOutput, "interface") {
-		changes = append(changes, "新インターフェース定義の追加")
-	}
-
-	// コンテキスト処理
-	if strings.Contains(diffOutput, "context.Context") || strings.Contains(diffOutput, "ctx context.Context") {
-		changes = append(changes, "コンテキスト管理の統合")
-	}
-
-	// メモリ管理改善
-	if strings.Contains(diffOutput, "sync.Pool") || strings.Contains(diffOutput, "make([]") {
-		changes = append(changes, "メモリ使用効率の最適化")
-	}
-
-	// ログ機能追加
-	if strings.Contains(diffOutput, "log.") || strings.Contains(diffOutput, "logger.") {
-		changes = append(changes, "ログ機能の強化")
-	}
-
-	return changes
-}
-
-// identifySecurityConcerns はセキュリティ懸念を特定
-func (ism *interactiveSessionManager) identifySecurityConcerns(diffOutput string) []string {
-	concerns := []string{}
-
-	// 認証関連
-	if strings.Contains(diffOutput, "auth") || strings.Contains(diffOutput, "token") {
-		concerns = append(concerns, "認証・認可システムの変更")
-	}
-
-	// パスワード・秘密情報
-	if strings.Contains(diffOutput, "password") || strings.Contains(diffOutput, "secret") || strings.Contains(diffOutput, "key") {
-		concerns = append(concerns, "機密情報の取り扱い変更")
-	}
-
-	// ファイルアクセス権限
-	if strings.Contains(diffOutput, "os.OpenFile") || strings.Contains(diffOutput, "0644") || strings.Contains(diffOutput, "0755") {
-		concerns = append(concerns, "ファイル権限・アクセス制御の変更")
-	}
-
-	// 外部コマンド実行
-	if strings.Contains(diffOutput, "exec.Command") || strings.Contains(diffOutput, "exec.CommandContext") {
-		concerns = append(concerns, "外部コマンド実行によるセキュリティ影響")
-	}
-
-	// 入力検証
-	if strings.Contains(diffOutput, "strings.Contains") && strings.Contains(diffOutput, "user") {
-		concerns = append(concerns, "ユーザー入力処理の変更 - 検証強化を確認")
-	}
-
-	return concerns
-}
-
-// identifyQualityIssues は品質問題を特定
-func (ism *interactiveSessionManager) identifyQualityIssues(diffOutput string, analysis *DetailedDiffAnalysis) []string {
-	issues := []string{}
-
-	// 大規模な関数追加
-	funcCount := strings.Count(diffOutput, "+func ")
-	if funcCount > 10 {
-		issues = append(issues, fmt.Sprintf("大量の関数追加 (%d個) - 複雑度増加に注意", funcCount))
-	}
-
-	// テストの不足
-	hasTest := false
-	for _, file := range analysis.ChangedFiles {
-		if strings.Contains(file, "_test.go") {
-			hasTest = true
-			break
-		}
-	}
-	if analysis.AddedLines > 200 && !hasTest {
-		issues = append(issues, "大きな変更に対するテストコードの追加が推奨")
-	}
-
-	// エラーハンドリングの不足
-	addedFuncs := strings.Count(diffOutput, "+func ")
-	errorHandling := strings.Count(diffOutput, "return") + strings.Count(diffOutput, "err")
-	if addedFuncs > 3 && errorHandling < addedFuncs {
-		issues = append(issues, "エラーハンドリングの不足が疑われます")
-	}
-
-	// コメント不足
-	commentCount := strings.Count(diffOutput, "+//")
-	if analysis.AddedLines > 300 && commentCount < 10 {
-		issues = append(issues, "コードコメント・ドキュメントの追加を検討")
-	}
-
-	return issues
-}
-
-// evaluatePerformanceImpact はパフォーマンス影響を評価
-func (ism *interactiveSessionManager) evaluatePerformanceImpact(diffOutput string, changedFiles []string) string {
-	impacts := []string{}
-
-	// 並行処理の追加
-	if strings.Contains(diffOutput, "+go func") || strings.Contains(diffOutput, "+sync.") {
-		impacts = append(impacts, "並行処理による高速化期待")
-	}
-
-	// データベース・I/O操作
-	if strings.Contains(diffOutput, "os.ReadFile") || strings.Contains(diffOutput, "os.WriteFile") {
-		impacts = append(impacts, "ファイルI/O処理の追加")
-	}
-
-	// ネットワーク処理
-	if strings.Contains(diffOutput, "http.") || strings.Contains(diffOutput, "net/") {
-		impacts = append(impacts, "ネットワーク通信処理の追加")
-	}
-
-	// メモリ使用量の変化
-	if strings.Contains(diffOutput, "make([]") || strings.Contains(diffOutput, "make(map") {
-		impacts = append(impacts, "メモリ使用量への影響")
-	}
-
-	// 大量のループ処理
-	if strings.Count(diffOutput, "+\tfor ") > 5 {
-		impacts = append(impacts, "複数ループ処理による計算負荷増加")
-	}
-
-	if len(impacts) == 0 {
-		return "軽微 - 大きなパフォーマンス影響なし"
-	}
-
-	return strings.Join(impacts, "、")
-}
-
-// getFileTypeIcon はファイルタイプに応じたアイコンを返す
-func (ism *interactiveSessionManager) getFileTypeIcon(filename string) string {
-	ext := filepath.Ext(filename)
-	basename := filepath.Base(filename)
-
-	switch {
-	case ext == ".go":
-		return "🐹"
-	case ext == ".js" || ext == ".ts" || ext == ".jsx" || ext == ".tsx":
-		return "📜"
-	case ext == ".py":
-		return "🐍"
-	case ext == ".md":
-		return "📚"
-	case ext == ".json" || ext == ".yaml" || ext == ".yml":
-		return "⚙️"
-	case strings.Contains(basename, "test"):
-		return "🧪"
-	case ext == ".dockerfile" || basename == "Dockerfile":
-		return "🐳"
-	case ext == ".sh" || ext == ".bash":
-		return "⚡"
-	case strings.Contains(filename, "config"):
-		return "🔧"
-	default:
-		return "📄"
-	}
-}
-
-// extractChangePatterns は変更パターンを抽出
-func (ism *interactiveSessionManager) extractChangePatterns(diffOutput string) []string {
-	patterns := []string{}
-
-	// 新機能追加
-	if strings.Contains(diffOutput, "+func New") {
-		patterns = append(patterns, "🚀 新しいコンストラクタ関数の追加")
-	}
-	if strings.Contains(diffOutput, "+type ") && strings.Contains(diffOutput, "struct") {
-		patterns = append(patterns, "🏗️ 新しい構造体定義の追加")
-	}
-	if strings.Contains(diffOutput, "+func ") {
-		funcCount := strings.Count(diffOutput, "+func ")
-		if funcCount > 1 {
-			patterns = append(patterns, fmt.Sprintf("⚡ %d個の新しい関数の追加", funcCount))
-		} else {
-			patterns = append(patterns, "⚡ 新しい関数の追加")
-		}
-	}
-
-	// インポート変更
-	if strings.Contains(diffOutput, "+import") {
-		patterns = append(patterns, "📦 新しいパッケージの導入")
-	}
-
-	// 設定変更
-	if strings.Contains(diffOutput, "config") || strings.Contains(diffOutput, "Config") {
-		patterns = append(patterns, "⚙️ 設定システムの変更")
-	}
-
-	// テスト追加
-	if strings.Contains(diffOutput, "_test.go") {
-		patterns = append(patterns, "🧪 テストコードの追加・変更")
-	}
-
-	// ドキュメント更新
-	if strings.Contains(diffOutput, "README.md") || strings.Contains(diffOutput, "CLAUDE.md") {
-		patterns = append(patterns, "📚 プロジェクトドキュメントの更新")
-	}
-
-	// エラーハンドリング改善
-	if strings.Contains(diffOutput, "fmt.Errorf") || strings.Contains(diffOutput, "errors.New") {
-		patterns = append(patterns, "🔧 エラーハンドリングの改善")
-	}
-
-	// 依存関係
-	if strings.Contains(diffOutput, "go.mod") {
-		patterns = append(patterns, "🔗 Go モジュール依存関係の更新")
-	}
-
-	// パフォーマンス改善
-	if strings.Contains(diffOutput, "goroutine") || strings.Contains(diffOutput, "sync.") {
-		patterns = append(patterns, "⚡ 並行処理・パフォーマンス改善")
-	}
-
-	return patterns
-}
-
-// extractChangedFilesFromDiff はdiff出力から変更されたファイル一覧を抽出
-func (ism *interactiveSessionManager) extractChangedFilesFromDiff(diffOutput string) []string {
-	var files []string
-	lines := strings.Split(diffOutput, "\n")
-
-	for _, line := range lines {
-		if strings.HasPrefix(line, "diff --git") {
-			// "diff --git a/file.go b/file.go" の形式
-			parts := strings.Fields(line)
-			if len(parts) >= 4 {
-				filename := strings.TrimPrefix(parts[2], "a/")
-				files = append(files, filename)
-			}
-		}
-	}
-
-	return files
-}
-
-// analyzeGoCodeChanges はGoコードの変更を詳細分析
-func (ism *interactiveSessionManager) analyzeGoCodeChanges(filename, diffOutput string) []string {
-	var suggestions []string
-
-	// 構造体の変更
-	if strings.Contains(diffOutput, "type ") && strings.Contains(diffOutput, "struct") {
-		suggestions = append(suggestions, fmt.Sprintf("Go構造体変更(%s): APIの後方互換性を確認", filename))
-	}
-
-	// インターフェース の変更
-	if strings.Contains(diffOutput, "interface") {
-		suggestions = append(suggestions, fmt.Sprintf("Go インターフェース変更(%s): 実装クラスへの影響を確認", filename))
-	}
-
-	// メソッドの変更
-	if strings.Contains(diffOutput, "func (") {
-		suggestions = append(suggestions, fmt.Sprintf("Goメソッド変更(%s): 関連する単体テストの更新", filename))
-	}
-
-	// パッケージ名の変更
-	if strings.Contains(diffOutput, "package ") {
-		suggestions = append(suggestions, fmt.Sprintf("Goパッケージ変更(%s): インポート文の全体的な更新が必要", filename))
-	}
-
-	// コンストラクタ関数
-	if strings.Contains(diffOutput, "+func New") {
-		suggestions = append(suggestions, fmt.Sprintf("新しいコンストラクタ(%s): 初期化ロジックの検証", filename))
-	}
-
-	// エラー定義
-	if strings.Contains(diffOutput, "errors.New") || strings.Contains(diffOutput, "fmt.Errorf") {
-		suggestions = append(suggestions, fmt.Sprintf("エラーメッセージ変更(%s): エラーハンドリングテストの確認", filename))
-	}
-
-	// 実行後の提案
-	suggestions = append(suggestions, fmt.Sprintf("`go fmt %s` でフォーマット、`go vet %s` で静的解析", filename, filename))
-
-	return suggestions
-}
-
-// analyzeFileOperations はファイル操作の結果を分析
-func (ism *interactiveSessionManager) analyzeFileOperations(action, result string) []string {
-	var suggestions []string
-
-	if strings.Contains(action, "ファイル作成") && strings.Contains(result, "成功") {
-		suggestions = append(suggestions, "作成されたファイルの内容を確認し、必要に応じて編集")
-		suggestions = append(suggestions, "関連するテストファイルの作成を検討")
-	}
-
-	if strings.Contains(action, "ファイル読み込み") {
-		if strings.Contains(result, "go") {
-			suggestions = append(suggestions, "Goコードの構文チェック: `go fmt` と `go vet` を実行")
-		}
-		suggestions = append(suggestions, "ファイル内容に基づいて必要な修正や改善を実施")
-	}
-
-	return suggestions
-}
-
-// analyzeErrors はエラー内容を分析して解決策を提案
-func (ism *interactiveSessionManager) analyzeErrors(errorOutput string) []string {
-	var suggestions []string
-
-	if strings.Contains(errorOutput, "permission denied") {
-		suggestions = append(suggestions, "権限エラーです。`chmod +x` または管理者権限で再実行")
-	}
-
-	if strings.Contains(errorOutput, "command not found") {
-		suggestions = append(suggestions, "コマンドが見つかりません。インストール状況を確認")
-	}
-
-	if strings.Contains(errorOutput, "go: cannot find module") {
-		suggestions = append(suggestions, "`go mod tidy` でモジュール依存関係を解決")
-	}
-
-	if strings.Contains(errorOutput, "syntax error") {
-		suggestions = append(suggestions, "構文エラーがあります。該当ファイルを確認して修正")
-	}
-
-	return suggestions
-}
-
-// analyzeBuildResults はビルド結果を分析
-func (ism *interactiveSessionManager) analyzeBuildResults(buildOutput string) []string {
-	var suggestions []string
-
-	if strings.Contains(buildOutput, "Build succeeded") || len(strings.TrimSpace(buildOutput)) == 0 {
-		suggestions = append(suggestions, "ビルド成功！テストの実行を検討: `go test ./...`")
-		suggestions = append(suggestions, "実行ファイルの動作確認を実施")
-	}
-
-	if strings.Contains(buildOutput, "error:") || strings.Contains(buildOutput, "failed") {
-		suggestions = append(suggestions, "ビルドエラーを修正後、再度ビルドを実行")
-		suggestions = append(suggestions, "依存関係の確認: `go mod download`")
-	}
-
-	return suggestions
-}
-
-// removeDuplicateSuggestions は重複する提案を除去
-func (ism *interactiveSessionManager) removeDuplicateSuggestions(suggestions []string) []string {
-	seen := make(map[string]bool)
-	var unique []string
-
-	for _, suggestion := range suggestions {
-		if !seen[suggestion] {
-			seen[suggestion] = true
-			unique = append(unique, suggestion)
-		}
-	}
-
-	return unique
-}
-
-// determineResponseType はLLM応答から応答タイプを判定（Claude Code式簡素化）
-func (ism *interactiveSessionManager) determineResponseType(llmResponse string, intent string) ResponseType {
-	// コードブロック（```）を含む場合はコード提案として扱う
-	if strings.Contains(llmResponse, "```") {
-		return ResponseTypeCodeSuggestion
-	}
-
-	// 作成要求の場合でコードが含まれる場合はコード提案
-	if intent == "creation_request" && (strings.Contains(llmResponse, "package") ||
-		strings.Contains(llmResponse, "func") || strings.Contains(llmResponse, "def") ||
-		strings.Contains(llmResponse, "class")) {
-		return ResponseTypeCodeSuggestion
-	}
-
-	// 確認プロンプトが含まれる場合
-	if strings.Contains(llmResponse, "適用しますか") || strings.Contains(llmResponse, "実行しますか") {
-		return ResponseTypeConfirmation
-	}
-
-	// デフォルトはメッセージ（モデルの自然な判断を信頼）
-	return ResponseTypeMessage
-}
-
-// requiresConfirmation は確認が必要かどうかを判定
-func (ism *interactiveSessionManager) requiresConfirmation(responseType ResponseType, intent string) bool {
-	// コード提案は基本的に確認が必要
-	if responseType == ResponseTypeCodeSuggestion {
-		return true
-	}
-
-	// ファイル修正系の意図は確認が必要
-	if strings.Contains(intent, "修正") || strings.Contains(intent, "最適化") || strings.Contains(intent, "リファクタリング") {
-		return true
-	}
-
-	return false
-}
-
-// extractCodeSuggestionsFromLLM はLLM応答からコード提案を抽出
-func (ism *interactiveSessionManager) extractCodeSuggestionsFromLLM(llmResponse string, originalInput string) ([]*CodeSuggestion, error) {
-	var suggestions []*CodeSuggestion
-
-	// 元の入力からファイルパスを抽出
-	suggestedFilePath := ism.extractFilePathFromInput(originalInput)
-
-	// マークダウンコードブロックを抽出
-	codeBlockPattern := regexp.MustCompile("```(?:go|javascript|python|rust|java|c|cpp|csharp)?\\s*\\n([\\s\\S]*?)\\n```")
-	matches := codeBlockPattern.FindAllStringSubmatch(llmResponse, -1)
-
-	for i, match := range matches {
-		if len(match) > 1 {
-			suggestion := &CodeSuggestion{
-				ID:            fmt.Sprintf("llm_suggestion_%d_%d", time.Now().UnixNano(), i),
-				Type:          SuggestionTypeImprovement,
-				OriginalCode:  "", // 元コードは別途特定が必要
-				SuggestedCode: strings.TrimSpace(match[1]),
-				Explanation:   ism.extractExplanationFromLLM(llmResponse, i),
-				Confidence:    0.85, // LLM生成なので高い信頼度
-				ImpactLevel:   ImpactLevelMedium,
-				FilePath:      suggestedFilePath, // 抽出されたファイルパスを設定
-				LineRange:     [2]int{0, 0},
-				Metadata: map[string]string{
-					"generated_by":   "llm",
-					"model":          "qwen2.5-coder:14b",
-					"benefits":       "AI生成による実装, ベストプラクティスに基づく",
-					"risks":          "実際の動作確認が必要",
-					"estimated_time": "5-10分",
-					"original_input": originalInput,
-				},
-				CreatedAt: time.Now(),
-			}
-			suggestions = append(suggestions, suggestion)
-		}
-	}
-
-	// コードブロックがない場合は一般的な提案として扱う
-	if len(suggestions) == 0 {
-		suggestion := &CodeSuggestion{
-			ID:            fmt.Sprintf("llm_suggestion_%d", time.Now().UnixNano()),
-			Type:          SuggestionTypeImprovement,
-			OriginalCode:  "",
-			SuggestedCode: llmResponse, // 全体を提案として扱う
-			Explanation:   "AIによる提案",
-			Confidence:    0.7,
-			ImpactLevel:   ImpactLevelLow,
-			FilePath:      suggestedFilePath, // 抽出されたファイルパスを設定
-			LineRange:     [2]int{0, 0},
-			Metadata: map[string]string{
-				"generated_by":   "llm",
-				"model":          "qwen2.5-coder:14b",
-				"estimated_time": "確認が必要",
-				"original_input": originalInput,
-			},
-			CreatedAt: time.Now(),
-		}
-		suggestions = append(suggestions, suggestion)
-	}
-
-	return suggestions, nil
-}
-
-// extractExplanationFromLLM はLLM応答から説明部分を抽出
-func (ism *interactiveSessionManager) extractExplanationFromLLM(llmResponse string, codeIndex int) string {
-	// コードブロック前後のテキストから説明を抽出（簡易実装）
-	lines := strings.Split(llmResponse, "\n")
-	var explanation strings.Builder
-
-	inCodeBlock := false
-	for _, line := range lines {
-		if strings.HasPrefix(line, "```") {
-			inCodeBlock = !inCodeBlock
-			continue
-		}
-
-		if !inCodeBlock && strings.TrimSpace(line) != "" {
-			explanation.WriteString(line)
-			explanation.WriteString(" ")
-		}
-	}
-
-	result := strings.TrimSpace(explanation.String())
-	if result == "" {
-		return "AIによるコード提案です。"
-	}
-
-	// 長すぎる場合は短縮
-	if len(result) > 200 {
-		return result[:200] + "..."
-	}
-
-	return result
-}
-
-// ヘルパーメソッド
-
-// sessionTypeToString はセッションタイプを文字列に変換
-func (ism *interactiveSessionManager) sessionTypeToString(sessionType CodingSessionType) string {
-	switch sessionType {
-	case CodingSessionTypeDebugging:
-		return "デバッグ作業"
-	case CodingSessionTypeRefactor:
-		return "リファクタリング"
-	case CodingSessionTypeReview:
-		return "コードレビュー"
-	case CodingSessionTypeLearning:
-		return "学習・説明"
-	default:
-		return "一般的なコーディング"
-	}
-}
-
-// suggestionTypeToString は提案タイプを文字列に変換
-func (ism *interactiveSessionManager) suggestionTypeToString(suggestionType SuggestionType) string {
-	switch suggestionType {
-	case SuggestionTypeBugFix:
-		return "バグ修正"
-	case SuggestionTypeOptimization:
-		return "パフォーマンス最適化"
-	case SuggestionTypeRefactoring:
-		return "リファクタリング"
-	case SuggestionTypeDocumentation:
-		return "ドキュメント追加"
-	case SuggestionTypeSecurity:
-		return "セキュリティ修正"
-	case SuggestionTypeTestGeneration:
-		return "テスト生成"
-	default:
-		return "改善提案"
-	}
-}
-
-// formatContextForPrompt はコンテキストをプロンプト用に整形
-func (ism *interactiveSessionManager) formatContextForPrompt(context []*contextmanager.ContextItem) string {
-	if len(context) == 0 {
-		return "関連コンテキストなし"
-	}
-
-	var formatted strings.Builder
-	for i, item := range context {
-		formatted.WriteString(fmt.Sprintf("%d. %s\n", i+1, item.Content))
-	}
-
-	return formatted.String()
-}
-
-// updateAverageResponseTime は平均応答時間を更新
-func (ism *interactiveSessionManager) updateAverageResponseTime(
-	currentAverage time.Duration,
-	newTime time.Duration,
-	totalCount int,
-) time.Duration {
-	if totalCount <= 1 {
-		return newTime
-	}
-
-	total := currentAverage*time.Duration(totalCount-1) + newTime
-	return total / time.Duration(totalCount)
-}
-
-// abs は絶対値を返す
-func abs(x int) int {
-	if x < 0 {
-		return -x
-	}
-	return x
-}
-
-// calculateSuggestionConfidence は提案の信頼度を計算
-func (ism *interactiveSessionManager) calculateSuggestionConfidence(
-	session *InteractiveSession,
-	request *SuggestionRequest,
-	context []*contextmanager.ContextItem,
-) float64 {
-	confidence := 0.5 // ベース信頼度
-
-	// コンテキストの豊富さによる調整
-	if len(context) > 5 {
-		confidence += 0.2
-	}
-
-	// 提案タイプによる調整
-	switch request.Type {
-	case SuggestionTypeBugFix:
-		confidence += 0.1 // バグ修正は比較的信頼度高
-	case SuggestionTypeOptimization:
-		confidence -= 0.1 // パフォーマンス最適化は慎重に
-	case SuggestionTypeSecurity:
-		confidence += 0.15 // セキュリティは重要
-	}
-
-	// セッション履歴による調整
-	if session.Metrics.SuggestionsAccepted > session.Metrics.SuggestionsRejected {
-		confidence += 0.1
-	}
-
-	// 0.0-1.0の範囲に正規化
-	if confidence > 1.0 {
-		confidence = 1.0
-	}
-	if confidence < 0.0 {
-		confidence = 0.0
-	}
-
-	return confidence
-}
-
-// evaluateImpactLevel は影響レベルを評価
-func (ism *interactiveSessionManager) evaluateImpactLevel(request *SuggestionRequest) ImpactLevel {
-	switch request.Type {
-	case SuggestionTypeSecurity:
-		return ImpactLevelCritical
-	case SuggestionTypeBugFix:
-		return ImpactLevelHigh
-	case SuggestionTypeOptimization, SuggestionTypeRefactoring:
-		return ImpactLevelMedium
-	case SuggestionTypeDocumentation:
-		return ImpactLevelLow
-	default:
-		return ImpactLevelMedium
-	}
-}
-
-// updateUserSatisfactionScore はユーザー満足度スコアを更新
-func (ism *interactiveSessionManager) updateUserSatisfactionScore(session *InteractiveSession, accepted bool) {
-	currentScore := session.Metrics.UserSatisfactionScore
-	weight := 0.1 // 学習レート
-
-	if accepted {
-		session.Metrics.UserSatisfactionScore = currentScore + weight*(1.0-currentScore)
-	} else {
-		session.Metrics.UserSatisfactionScore = currentScore + weight*(0.0-currentScore)
-	}
-}
-
-// analyzeUserIntent はClaude Code式簡素化された意図解析（安全性チェック中心）
-func (ism *interactiveSessionManager) analyzeUserIntent(
-	ctx context.Context,
-	session *InteractiveSession,
-	input string,
-) (string, error) {
-	// Claude Code式: モデル自体の判断を優先、最低限の分類のみ
-	lowerInput := strings.ToLower(input)
-
-	// 危険なコマンドの基本チェック（安全性のため）
-	if strings.Contains(lowerInput, "rm -rf") || strings.Contains(lowerInput, "sudo") ||
-		strings.Contains(lowerInput, "delete") && strings.Contains(lowerInput, "all") {
-		return "potentially_dangerous", nil
-	}
-
-	// 基本的な要求タイプの大まかな分類（モデル判断の補助程度）
-	if strings.Contains(lowerInput, "作って") || strings.Contains(lowerInput, "作成") ||
-		strings.Contains(lowerInput, "実装") || strings.Contains(lowerInput, "create") {
-		return "creation_request", nil
-	}
-
-	// その他はすべて一般的な要求として扱う（モデルが詳細判断）
-	return "general_request", nil
-}
-
-// advanceConversationFlow は会話フローを進行
-func (ism *interactiveSessionManager) advanceConversationFlow(
-	session *InteractiveSession,
-	intent string,
-) error {
-	flow, exists := ism.conversationFlows[session.ID]
-	if !exists {
-		return fmt.Errorf("会話フローが見つかりません")
-	}
-
-	// 現在のステップを完了
-	now := time.Now()
-	flow.CurrentStep.EndTime = &now
-	flow.CurrentStep.Success = true
-	flow.StepHistory = append(flow.StepHistory, flow.CurrentStep)
-	flow.CompletedSteps++
-
-	// 次のステップを決定
-	nextStepType := ism.determineNextFlowStep(flow.CurrentStep.StepType, intent)
-	flow.CurrentStep = FlowStep{
-		StepID:      fmt.Sprintf("step_%d", time.Now().UnixNano()),
-		StepType:    nextStepType,
-		Description: ism.getStepDescription(nextStepType),
-		StartTime:   now,
-	}
-
-	// 進捗更新
-	flow.Progress = float64(flow.CompletedSteps) / float64(flow.EstimatedSteps)
-	if flow.Progress > 1.0 {
-		flow.Progress = 1.0
-	}
-
-	return nil
-}
-
-// determineNextFlowStep は次のフローステップを決定
-func (ism *interactiveSessionManager) determineNextFlowStep(
-	currentStep FlowStepType,
-	intent string,
-) FlowStepType {
-	switch currentStep {
-	case FlowStepTypeUnderstanding:
-		return FlowStepTypeAnalysis
-	case FlowStepTypeAnalysis:
-		return FlowStepTypePlanning
-	case FlowStepTypePlanning:
-		return FlowStepTypeImplementation
-	case FlowStepTypeImplementation:
-		return FlowStepTypeTesting
-	case FlowStepTypeTesting:
-		return FlowStepTypeVerification
-	default:
-		return FlowStepTypeCompletion
-	}
-}
-
-// getStepDescription はステップの説明を取得
-func (ism *interactiveSessionManager) getStepDescription(stepType FlowStepType) string {
-	switch stepType {
-	case FlowStepTypeUnderstanding:
-		return "要求の理解"
-	case FlowStepTypeAnalysis:
-		return "コード分析"
-	case FlowStepTypePlanning:
-		return "実装計画"
-	case FlowStepTypeImplementation:
-		return "実装"
-	case FlowStepTypeTesting:
-		return "テスト"
-	case FlowStepTypeVerification:
-		return "検証"
-	case FlowStepTypeCompletion:
-		return "完了"
-	default:
-		return "不明"
-	}
-}
-
-// generateInteractiveResponse はインタラクティブな応答を生成
-func (ism *interactiveSessionManager) generateInteractiveResponse(
-	ctx context.Context,
-	session *InteractiveSession,
-	input string,
-	intent string,
-) (*InteractionResponse, error) {
-	// 応答時間計測開始
-	startTime := time.Now()
-
-	// LLM統合による実際の応答生成
-	prompt := ism.buildInteractivePrompt(session, input, intent)
-
-	// トークン数を推定
-	estimatedTokens := len(prompt) / 4
-
-	// ClaudeCode風進捗表示を開始
-	progressIndicator := ui.NewProgressIndicator("Generating response…", estimatedTokens)
-	progressIndicator.Start()
-
-	defer func() {
-		progressIndicator.Stop()
-	}()
-
-	// LLM呼び出し
-	chatReq := llm.ChatRequest{
-		Model: ism.getConfiguredModel(), // 設定からモデルを取得
-		Messages: []llm.ChatMessage{
-			{
-				Role:    "user",
-				Content: prompt,
-			},
-		},
-		Stream: false,
-	}
-
-	// 中断可能なコンテキストを作成
-	llmCtx := progressIndicator.GetContext()
-	if llmCtx.Err() != nil {
-		progressIndicator.CompleteWithResult(false, "Request interrupted")
-		return nil, fmt.Errorf("request interrupted by user")
-	}
-
-	llmResponse, err := ism.llmProvider.Chat(llmCtx, chatReq)
-	if err != nil {
-		// LLM失敗時の進捗表示完了
-		progressIndicator.CompleteWithResult(false, "LLM request failed")
-		return ism.generateFallbackResponse(session, input, intent, err)
-	}
-
-	// 応答受信トークン数を更新
-	receivedTokens := len(llmResponse.Message.Content) / 4
-	progressIndicator.UpdateTokens(receivedTokens)
-
-	// LLM応答の言語統一処理（繁体字等を日本語に修正）
-	cleanedResponse := ism.normalizeLanguage(llmResponse.Message.Content)
-	llmResponse.Message.Content = cleanedResponse
-
-	// SmartContextManagerにLLM応答を追加
-	ism.addToSmartContext(session.ID, cleanedResponse, "llm_response")
-
-	// 構造化された応答を解析して実際のツール実行を行う
-	finalResponse, err := ism.parseAndExecuteStructuredResponse(ctx, session, llmResponse.Message.Content, input)
-	if err != nil {
-		return ism.generateFallbackResponse(session, input, intent, err)
-	}
-
-	if finalResponse != nil {
-		// 構造化応答にもメタ情報を追加
-		ism.addMetaInfoToResponse(finalResponse, startTime, chatReq.Model, len(prompt))
-
-		// 構造化応答完了の進捗メッセージ
-		progressIndicator.CompleteWithResult(true, "Structured response executed successfully")
-
-		return finalResponse, nil
-	}
-
-	// 構造化応答がない場合：分析系の質問は強制的にANALYSISを実行
-	if ism.shouldForceAnalysis(input, intent) {
-		analysisResult := ism.performAnalysis(session, input)
-
-		response := &InteractionResponse{
-			SessionID:            session.ID,
-			ResponseType:         ResponseTypeAnalysis,
-			Message:              fmt.Sprintf("🔍 **プロジェクト分析結果**\n\n%s\n\n**AI応答:**\n%s", analysisResult, llmResponse.Message.Content),
-			RequiresConfirmation: false,
-			Metadata: map[string]string{
-				"forced_analysis": "true",
-				"analysis_result": "included",
-				"ai_response":     "enhanced",
-			},
-			GeneratedAt: time.Now(),
-		}
-
-		// メタ情報を追加
-		ism.addMetaInfoToResponse(response, startTime, chatReq.Model, len(prompt))
-
-		// 分析完了の進捗メッセージ
-		progressIndicator.CompleteWithResult(true, "Analysis completed successfully")
-
-		return response, nil
-	}
-
-	// 通常のLLM応答を返す
-	responseType := ism.determineResponseType(llmResponse.Message.Content, intent)
-
-	response := &InteractionResponse{
-		SessionID:            session.ID,
-		ResponseType:         responseType,
-		Message:              llmResponse.Message.Content,
-		RequiresConfirmation: ism.requiresConfirmation(responseType, intent),
-		Metadata:             make(map[string]string),
-		GeneratedAt:          time.Now(),
-	}
-
-	// コード提案の場合、提案を解析
-	if responseType == ResponseTypeCodeSuggestion {
-		suggestions, err := ism.extractCodeSuggestionsFromLLM(llmResponse.Message.Content, input)
-		if err == nil && len(suggestions) > 0 {
-			response.Suggestions = suggestions
-
-			// Claude Code式: コマンド実行の場合は即座に実行
-			if ism.isCommandSuggestion(suggestions[0].SuggestedCode) {
-				extractedCmd := ism.extractCommandFromSuggestion(suggestions[0].SuggestedCode)
-				if ism.isSafeCommand(extractedCmd) {
-					// 安全なコマンドは即座に実行
-					err = ism.executeCommandDirectly(ctx, session, suggestions[0])
-					if err != nil {
-						return nil, fmt.Errorf("コマンド実行エラー: %w", err)
-					}
-					// 実行結果を応答に含める
-					response.ResponseType = ResponseTypeMessage
-					response.Message = fmt.Sprintf("コマンド実行結果:\n%s", session.LastCommandOutput)
-					response.RequiresConfirmation = false
-					session.State = SessionStateIdle
-				} else {
-					// 危険なコマンドは確認を求める
-					session.PendingSuggestion = suggestions[0]
-					session.State = SessionStateWaitingForConfirmation
-				}
-			} else {
-				// ファイル操作の場合は危険性を判定
-				if ism.isDangerousFileOperation(suggestions[0]) {
-					// 危険なファイル操作は確認を求める
-					session.PendingSuggestion = suggestions[0]
-					session.State = SessionStateWaitingForConfirmation
-				} else {
-					// 安全なファイル操作は確認を求める（基本動作）
-					session.PendingSuggestion = suggestions[0]
-					session.State = SessionStateWaitingForConfirmation
-				}
-			}
-		}
-	}
-
-	response.Metadata["intent"] = intent
-	response.Metadata["session_type"] = ism.sessionTypeToString(session.Type)
-	response.Metadata["llm_model"] = chatReq.Model
-
-	// メタ情報を追加
-	ism.addMetaInfoToResponse(response, startTime, chatReq.Model, len(prompt))
-
-	// 成功時の進捗完了メッセージ
-	progressIndicator.CompleteWithResult(true, "Response generated successfully")
-
-	return response, nil
-}
-
-// isSafeCommand は安全なコマンドかどうかを判定
-func (ism *interactiveSessionManager) isSafeCommand(command string) bool {
-	command = strings.TrimSpace(command)
-
-	// 安全なコマンドのリスト（読み取り専用操作）
-	safeCommands := []string{
-		"git status",
-		"git log",
-		"git branch",
-		"git diff",
-		"git show",
-		"ls",
-		"pwd",
-		"cat",
-		"head",
-		"tail",
-		"grep",
-		"find",
-		"which",
-		"echo",
-		"date",
-		"whoami",
-		"id",
-	}
-
-	// コマンドの先頭部分を抽出
-	commandParts := strings.Fields(command)
-	if len(commandParts) == 0 {
-		return false
-	}
-
-	baseCommand := commandParts[0]
-
-	// 安全なコマンドかチェック
-	for _, safe := range safeCommands {
-		safeParts := strings.Fields(safe)
-		if len(safeParts) > 0 && safeParts[0] == baseCommand {
-			// git statusのような複合コマンドの場合、完全一致をチェック
-			if len(safeParts) > 1 {
-				if len(commandParts) >= len(safeParts) {
-					match := true
-					for i, part := range safeParts {
-						if commandParts[i] != part {
-							match = false
-							break
-						}
-					}
-					if match {
-						return true
-					}
-				}
-			} else {
-				return true
-			}
-		}
-	}
-
-	return false
-}
-
-// executeCommandDirectly は安全なコマンドを直接実行
-func (ism *interactiveSessionManager) executeCommandDirectly(ctx context.Context, session *InteractiveSession, suggestion *CodeSuggestion) error {
-	command := ism.extractCommandFromSuggestion(suggestion.SuggestedCode)
-	if command == "" {
-		return fmt.Errorf("コマンドを抽出できませんでした")
-	}
-
-	// BashToolを使ってコマンド実行
-	result, err := ism.bashTool.Execute(command, "安全なコマンドの直接実行", 30000)
-	if err != nil {
-		return fmt.Errorf("コマンド実行失敗: %w", err)
-	}
-
-	// セッションに実行結果を保存
-	session.LastCommandOutput = result.Content
-	session.LastActivity = time.Now()
-
-	// 提案を適用済みにマーク
-	suggestion.Applied = true
-	suggestion.UserConfirmed = true
-
-	return nil
-}
-
-// parseSuggestionResponse は提案応答を解析
-func (ism *interactiveSessionManager) parseSuggestionResponse(
-	response string,
-	request *SuggestionRequest,
-) (*CodeSuggestion, error) {
-	// 簡易的な解析実装
-	suggestion := &CodeSuggestion{
-		ID:            fmt.Sprintf("suggestion_%d", time.Now().UnixNano()),
-		Type:          request.Type,
-		OriginalCode:  request.Code,
-		SuggestedCode: "// 改善されたコード\n" + request.Code,
-		Explanation:   "コードの改善を提案しました。",
-		Confidence:    0.8,
-		FilePath:      request.FilePath,
-		LineRange:     request.LineRange,
-		Metadata:      make(map[string]string),
-		CreatedAt:     time.Now(),
-		UserConfirmed: false,
-		Applied:       false,
-	}
-
-	// 実際は応答テキストからコードブロックや説明を抽出
-	// 高度な解析は将来のバージョンで実装予定
-
-	return suggestion, nil
-}
-
-// extractFilePathFromInput はユーザー入力からファイルパスを抽出
-func (ism *interactiveSessionManager) extractFilePathFromInput(input string) string {
-	// ファイル名のパターンを抽出（例: "test.goを作成", "hello.jsファイル"等）
-	patterns := []string{
-		`(\w+\.(?:go|js|py|java|rs|cpp|c|ts|jsx|tsx|vue|html|css|json|yaml|yml|xml|md|txt))`,
-		`(\w+ファイル)`,
-	}
-
-	for _, pattern := range patterns {
-		re := regexp.MustCompile(pattern)
-		matches := re.FindStringSubmatch(input)
-		if len(matches) > 1 {
-			filename := matches[1]
-			// "ファイル"という文字が含まれている場合は、適切な拡張子を推定
-			if strings.Contains(filename, "ファイル") {
-				baseFilename := strings.ReplaceAll(filename, "ファイル", "")
-				// コンテキストから言語を推定
-				if strings.Contains(input, "Go") || strings.Contains(input, "go") {
-					return baseFilename + ".go"
-				} else if strings.Contains(input, "JavaScript") || strings.Contains(input, "JS") {
-					return baseFilename + ".js"
-				} else if strings.Contains(input, "Python") {
-					return baseFilename + ".py"
-				}
-				// デフォルトは.txtファイル
-				return baseFilename + ".txt"
-			}
-			return filename
-		}
-	}
-
-	// 特定できない場合はデフォルトのファイル名を生成
-	if strings.Contains(input, "Go") || strings.Contains(input, "go") {
-		return "main.go"
-	} else if strings.Contains(input, "JavaScript") || strings.Contains(input, "JS") {
-		return "index.js"
-	} else if strings.Contains(input, "Python") {
-		return "main.py"
-	}
-
-	return "output.txt" // 最終フォールバック
-}
-
-// isCommandSuggestion はコマンド実行の提案かどうかを判定
-func (ism *interactiveSessionManager) isCommandSuggestion(suggestedCode string) bool {
-	// 1. BashToolパターン（最も一般的）
-	if strings.Contains(suggestedCode, "BashTool") {
-		return true
-	}
-
-	// 2. bashコードブロック
-	if strings.Contains(suggestedCode, "```bash") || strings.Contains(suggestedCode, "```sh") {
-		return true
-	}
-
-	// 3. $ プレフィックス付きコマンド
-	if strings.Contains(suggestedCode, "$ ") {
-		return true
-	}
-
-	// 4. 一般的なコマンドの直接記述
-	lines := strings.Split(suggestedCode, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-		if strings.HasPrefix(line, "git ") ||
-			strings.HasPrefix(line, "ls") ||
-			strings.HasPrefix(line, "pwd") ||
-			strings.HasPrefix(line, "cat ") ||
-			strings.HasPrefix(line, "head ") ||
-			strings.HasPrefix(line, "tail ") {
-			return true
-		}
-	}
-
-	// 5. FileOperations等のファイル操作は除外（コマンド実行ではない）
-	if strings.Contains(suggestedCode, "FileOperations") {
-		return false // ファイル操作として扱う
-	}
-
-	// 6. git関連のキーワードがある場合
-	if strings.Contains(suggestedCode, "git status") ||
-		strings.Contains(suggestedCode, "git branch") ||
-		strings.Contains(suggestedCode, "git log") {
-		return true
-	}
-
-	// 7. 単体で短いコマンドの場合
-	trimmed := strings.TrimSpace(suggestedCode)
-	if trimmed == "git status" || trimmed == "ls" || trimmed == "pwd" {
-		return true
+// evaluateImpactLevel は影響レベルを評価
+func (ism *interactiveSessionManager) evaluateImpactLevel(request *SuggestionRequest) ImpactLevel {
+	switch request.Type {
+	case SuggestionTypeSecurity:
+		return ImpactLevelCritical
+	case SuggestionTypeBugFix:
+		return ImpactLevelHigh
+	case SuggestionTypeOptimization, SuggestionTypeRefactoring:
+		return ImpactLevelMedium
+	case SuggestionTypeDocumentation:
+		return ImpactLevelLow
+	default:
+		return ImpactLevelMedium
 	}
-
-	return false
 }
 
-// extractCommandFromSuggestion は提案からコマンドを抽出
-func (ism *interactiveSessionManager) extractCommandFromSuggestion(suggestedCode string) string {
-	// 1. BashToolパターンの抽出（最も一般的）
-	if strings.Contains(suggestedCode, "BashTool") {
-		// BashTool.run_command("git status") パターン
-		re := regexp.MustCompile(`BashTool\.run_command\("([^"]+)"\)`)
-		matches := re.FindStringSubmatch(suggestedCode)
-		if len(matches) > 1 {
-			return matches[1]
-		}
-
-		// BashTool("git status") パターン
-		re = regexp.MustCompile(`BashTool\("([^"]+)"\)`)
-		matches = re.FindStringSubmatch(suggestedCode)
-		if len(matches) > 1 {
-			return matches[1]
-		}
-	}
-
-	// 2. コードブロック内のコマンド抽出
-	lines := strings.Split(suggestedCode, "\n")
-	for _, line := range lines {
-		line = strings.TrimSpace(line)
-
-		// bash/sh コードブロック内のコマンド
-		if strings.HasPrefix(line, "git ") ||
-			strings.HasPrefix(line, "ls") ||
-			strings.HasPrefix(line, "pwd") ||
-			strings.HasPrefix(line, "cat ") ||
-			strings.HasPrefix(line, "head ") ||
-			strings.HasPrefix(line, "tail ") {
-			return line
-		}
-
-		// $ プレフィックス付きコマンド
-		if strings.HasPrefix(line, "$ ") {
-			return strings.TrimPrefix(line, "$ ")
-		}
-	}
-
-	// 3. 直接コマンド形式
-	trimmed := strings.TrimSpace(suggestedCode)
-	if strings.HasPrefix(trimmed, "git ") ||
-		strings.HasPrefix(trimmed, "ls") ||
-		strings.HasPrefix(trimmed, "pwd") ||
-		strings.HasPrefix(trimmed, "cat ") {
-		return trimmed
-	}
-
-	// 4. ツール形式の一般的パターン
-	if strings.Contains(suggestedCode, "git status") {
-		return "git status"
-	}
-	if strings.Contains(suggestedCode, "git branch") {
-		return "git branch"
-	}
-	if strings.Contains(suggestedCode, `"ls"`) || strings.Contains(suggestedCode, "ls") {
-		return "ls -la"
-	}
+// updateUserSatisfactionScore はユーザー満足度スコアを更新
+func (ism *interactiveSessionManager) updateUserSatisfactionScore(session *InteractiveSession, accepted bool) {
+	currentScore := session.Metrics.UserSatisfactionScore
+	weight := 0.1 // 学習レート
 
-	// 5. 実際のgit status結果が表示されている場合、コマンドを実行すべき
-	if strings.Contains(suggestedCode, "On branch") ||
-		strings.Contains(suggestedCode, "nothing to commit") ||
-		strings.Contains(suggestedCode, "working tree clean") ||
-		strings.Contains(suggestedCode, "Changes not staged") ||
-		strings.Contains(suggestedCode, "Untracked files") {
-		return "git status"
+	if accepted {
+		session.Metrics.UserSatisfactionScore = currentScore + weight*(1.0-currentScore)
+	} else {
+		session.Metrics.UserSatisfactionScore = currentScore + weight*(0.0-currentScore)
 	}
-
-	return ""
 }
 
 // GetProactiveExtension はプロアクティブ拡張を取得
@@ -3805,7 +2131,7 @@ func (ism *interactiveSessionManager) getConfiguredModel() string {
 	if ism.modelName != "" {
 		return ism.modelName
 	}
-	return "qwen2.5-coder:14b" // デフォルトモデル
+	return config.DefaultModel
 }
 
 func (ism *interactiveSessionManager) GetProactiveExtension() *ProactiveExtension {
@@ -3845,327 +2171,28 @@ func (ism *interactiveSessionManager) shouldUseProactiveExtension(input string)
 	return false
 }
 
-// shouldForceAnalysis は分析を強制実行すべきかどうかを判定
-func (ism *interactiveSessionManager) shouldForceAnalysis(input, intent string) bool {
-	lowerInput := strings.ToLower(input)
-	lowerIntent := strings.ToLower(intent)
-
-	// 明確な分析要求キーワード
-	forceAnalysisKeywords := []string{
-		"分析", "analyze", "問題点", "問題", "状況", "状態",
-		"リポジトリ", "repository", "プロジェクト", "project",
-		"現状", "current", "詳細", "detail", "調査", "investigate",
-		"確認", "check", "診断", "diagnose", "評価", "evaluate",
-	}
-
-	for _, keyword := range forceAnalysisKeywords {
-		if strings.Contains(lowerInput, keyword) || strings.Contains(lowerIntent, keyword) {
-			return true
-		}
-	}
-
-	return false
-}
-
-// performDetailedCognitiveAnalysis は詳細な認知分析を実行
-func (ism *interactiveSessionManager) performDetailedCognitiveAnalysis(ctx context.Context, query string) string {
-	if ism.cognitiveAnalyzer == nil {
-		return ""
-	}
-
-	// 深度の高い分析リクエストを作成
-	request := &analysis.AnalysisRequest{
-		UserInput:       query,
-		Response:        "",
-		AnalysisDepth:   "deep",
-		RequiredMetrics: []string{"semantic_entropy", "confidence", "reasoning", "creativity"},
-		Context: map[string]interface{}{
-			"analysis_type": "detailed_cognitive",
-			"user_query":    query,
-			"timestamp":     time.Now().Format(time.RFC3339),
-		},
-	}
-
-	result, err := ism.cognitiveAnalyzer.AnalyzeCognitive(ctx, request)
-	if err != nil {
-		return fmt.Sprintf("⚠️ 認知分析エラー: %v", err)
-	}
-
-	// 詳細な結果をフォーマット
-	var details []string
-	details = append(details, "🧠 **詳細認知分析結果**")
-	details = append(details, fmt.Sprintf("  • **信頼度スコア**: %.2f/1.0 %s",
-		result.TrustScore, ism.getConfidenceEmoji(result.TrustScore)))
-	details = append(details, fmt.Sprintf("  • **全体品質**: %.2f/1.0", result.OverallQuality))
-
-	if result.Confidence != nil {
-		details = append(details, fmt.Sprintf("  • **信頼度分析**: %.3f (セマンティックエントロピー: %.3f)",
-			result.Confidence.OverallConfidence, result.Confidence.SemanticEntropy))
-	}
-
-	if result.ReasoningDepth != nil {
-		details = append(details, fmt.Sprintf("  • **論理的推論**: 深度%d (論理構造評価: %.1f)",
-			result.ReasoningDepth.OverallDepth, result.ReasoningDepth.LogicalCoherence))
-	}
-
-	if result.Creativity != nil {
-		details = append(details, fmt.Sprintf("  • **創造性評価**: %.2f (流暢性: %.1f, 独創性: %.1f)",
-			result.Creativity.OverallScore, result.Creativity.Fluency, result.Creativity.Originality))
-	}
-
-	if len(result.RecommendedActions) > 0 {
-		details = append(details, "  • **推奨アクション**:")
-		for i, action := range result.RecommendedActions {
-			if i < 3 { // 最大3つまで表示
-				details = append(details, fmt.Sprintf("    - %s", action))
-			}
-		}
-	}
-
-	return strings.Join(details, "\n")
-}
-
-// performProjectStructureAnalysisImpl はプロジェクト構造の詳細分析を実行
-func (ism *interactiveSessionManager) performProjectStructureAnalysisImpl(projectPath string) string {
-	var details []string
-	details = append(details, "🏗️ **プロジェクト構造分析**")
-
-	// ファイル数とディレクトリ構造の分析
-	fileCount := 0
-	dirCount := 0
-	var largeFiles []string
-	var languageStats = make(map[string]int)
-
-	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return nil
-		}
-
-		// 隠しディレクトリ(.git等)をスキップ
-		if strings.Contains(path, "/.") {
-			return nil
-		}
-
-		if info.IsDir() {
-			dirCount++
-		} else {
-			fileCount++
-
-			// 大きなファイルをチェック
-			if info.Size() > 1024*1024 { // 1MB以上
-				largeFiles = append(largeFiles, fmt.Sprintf("%s (%s)",
-					path, ism.formatFileSize(info.Size())))
-			}
-
-			// 言語別統計
-			ext := filepath.Ext(path)
-			if lang := ism.getLanguageFromExtension(ext); lang != "" {
-				languageStats[lang]++
-			}
-		}
-
-		return nil
-	})
-
-	details = append(details, fmt.Sprintf("  • **規模**: %d ファイル, %d ディレクトリ", fileCount, dirCount))
-
-	// 言語別統計
-	if len(languageStats) > 0 {
-		var langDetails []string
-		for lang, count := range languageStats {
-			langDetails = append(langDetails, fmt.Sprintf("%s(%d)", lang, count))
-		}
-		if len(langDetails) <= 5 {
-			details = append(details, fmt.Sprintf("  • **言語分布**: %s", strings.Join(langDetails, ", ")))
-		}
-	}
-
-	// 大きなファイルの警告
-	if len(largeFiles) > 0 {
-		details = append(details, "  • **大きなファイル**:")
-		for i, file := range largeFiles {
-			if i < 3 { // 最大3つまで表示
-				details = append(details, fmt.Sprintf("    - ⚠️ %s", file))
-			}
-		}
-	}
-
-	return strings.Join(details, "\n")
-}
-
-// performSecurityAnalysisImpl はセキュリティ分析を実行
-func (ism *interactiveSessionManager) performSecurityAnalysisImpl() string {
-	var details []string
-	details = append(details, "🔒 **セキュリティ分析**")
-
-	projectPath, err := os.Getwd()
-	if err != nil {
-		return ""
-	}
-
-	var sensitiveFiles []string
-
-	// セキュリティ関連のファイルパターンをチェック
-	securityPatterns := map[string]string{
-		"password": "パスワード関連",
-		"secret":   "シークレット情報",
-		"api_key":  "APIキー",
-		"token":    "トークン",
-		"private":  "プライベート情報",
-	}
-
-	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
-		if err != nil || info.IsDir() {
-			return nil
-		}
-
-		// 隠しファイル・ディレクトリをスキップ
-		if strings.Contains(path, "/.") {
-			return nil
-		}
-
-		filename := strings.ToLower(filepath.Base(path))
-		for pattern, description := range securityPatterns {
-			if strings.Contains(filename, pattern) {
-				sensitiveFiles = append(sensitiveFiles, fmt.Sprintf("%s (%s)", path, description))
-			}
-		}
-
-		// 設定ファイルで機密情報の可能性があるものをチェック
-		if strings.HasSuffix(filename, ".env") ||
-			strings.HasSuffix(filename, ".config") ||
-			strings.HasSuffix(filename, ".yaml") ||
-			strings.HasSuffix(filename, ".yml") {
-			sensitiveFiles = append(sensitiveFiles, fmt.Sprintf("%s (設定ファイル)", path))
-		}
-
-		return nil
-	})
-
-	// 結果の構築
-	if len(sensitiveFiles) > 0 {
-		details = append(details, fmt.Sprintf("  • **注意を要するファイル**: %d件", len(sensitiveFiles)))
-		for i, file := range sensitiveFiles {
-			if i < 5 { // 最大5つまで表示
-				details = append(details, fmt.Sprintf("    - ⚠️ %s", file))
-			}
-		}
-	} else {
-		details = append(details, "  • ✅ **機密性の懸念**: 明らかなセキュリティリスクは検出されませんでした")
-	}
-
-	// Git関連のセキュリティチェック
-	if _, err := os.Stat(filepath.Join(projectPath, ".git")); err == nil {
-		gitignoreExists := false
-		if _, err := os.Stat(filepath.Join(projectPath, ".gitignore")); err == nil {
-			gitignoreExists = true
-		}
-
-		if gitignoreExists {
-			details = append(details, "  • ✅ **.gitignore**: 存在します")
-		} else {
-			details = append(details, "  • ⚠️ **.gitignore**: 存在しません（推奨）")
-		}
-	}
-
-	return strings.Join(details, "\n")
-}
-
-// formatComprehensiveAnalysisResponse は包括的分析レスポンスをフォーマット
-func (ism *interactiveSessionManager) formatComprehensiveAnalysisResponse(query string, components []string) string {
-	if len(components) == 0 {
-		return fmt.Sprintf("🔍 **分析完了**\n\n**クエリ**: %s\n\n分析を実行しましたが、詳細な結果を取得できませんでした。", query)
-	}
-
-	var result strings.Builder
-	result.WriteString(fmt.Sprintf("🔍 **高度な分析結果**\n\n"))
-	result.WriteString(fmt.Sprintf("**クエリ**: %s\n\n", query))
-
-	// 分析コンポーネントを結合
-	result.WriteString(strings.Join(components, "\n\n"))
-
-	// 総合的なアクション提案を追加
-	result.WriteString("\n\n💡 **推奨アクション**")
-	result.WriteString("\n  • 分析結果を基にした具体的な改善を検討してください")
-	result.WriteString("\n  • コード品質・構造の最適化を進めてください")
-	result.WriteString("\n  • セキュリティ面での懸念があれば優先的に対応してください")
-	result.WriteString("\n  • プロジェクトの健全性向上を継続的に行ってください")
-
-	return result.String()
-}
-
-// 補助メソッド
-func (ism *interactiveSessionManager) getConfidenceEmoji(confidence float64) string {
-	if confidence >= 0.8 {
-		return "🟢"
-	} else if confidence >= 0.6 {
-		return "🟡"
-	} else {
-		return "🔴"
-	}
-}
-
-func (ism *interactiveSessionManager) getUncertaintyLevel(entropy float64) string {
-	if entropy < 0.3 {
-		return "低"
-	} else if entropy < 0.7 {
-		return "中"
-	} else {
-		return "高"
-	}
-}
-
-func (ism *interactiveSessionManager) formatFileSize(size int64) string {
-	if size < 1024 {
-		return fmt.Sprintf("%d B", size)
-	} else if size < 1024*1024 {
-		return fmt.Sprintf("%.1f KB", float64(size)/1024)
-	} else {
-		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
-	}
-}
-
-func (ism *interactiveSessionManager) getLanguageFromExtension(ext string) string {
-	languages := map[string]string{
-		".go":    "Go",
-		".js":    "JavaScript",
-		".ts":    "TypeScript",
-		".py":    "Python",
-		".java":  "Java",
-		".cpp":   "C++",
-		".c":     "C",
-		".rs":    "Rust",
-		".php":   "PHP",
-		".rb":    "Ruby",
-		".swift": "Swift",
-		".kt":    "Kotlin",
-		".cs":    "C#",
-		".html":  "HTML",
-		".css":   "CSS",
-		".sql":   "SQL",
-		".sh":    "Shell",
-		".yml":   "YAML",
-		".yaml":  "YAML",
-		".json":  "JSON",
-		".xml":   "XML",
-		".md":    "Markdown",
-	}
-
-	return languages[strings.ToLower(ext)]
-}
-
 // addMetaInfoToResponse は応答にリアルタイムメタ情報を追加
-func (ism *interactiveSessionManager) addMetaInfoToResponse(response *InteractionResponse, startTime time.Time, modelName string, promptLength int) {
+// プロバイダーの実測値があれば最初のトークンまでの時間・生成速度・入力/出力トークン数を、なければプロンプト長からの推定を表示する
+func (ism *interactiveSessionManager) addMetaInfoToResponse(response *InteractionResponse, startTime time.Time, modelName string, promptLength int, metrics *llm.ResponseMetrics) {
 	responseTime := time.Since(startTime)
 
 	// トークン数の概算（プロンプト長 / 4）
 	estimatedTokens := promptLength / 4
 
 	// メタ情報をメッセージの末尾に追加
-	metaInfo := fmt.Sprintf("\n\n---\n⏱️ **応答時間**: %v | 🤖 **モデル**: %s | 📊 **推定トークン**: %d",
-		responseTime.Round(time.Millisecond),
-		modelName,
-		estimatedTokens)
+	metaInfo := fmt.Sprintf("\n\n---\n⏱️ **応答時間**: %v", responseTime.Round(time.Millisecond))
+	if metrics != nil && metrics.TimeToFirstToken > 0 {
+		metaInfo += fmt.Sprintf(" | ⚡ **最初のトークン**: %v", metrics.TimeToFirstToken.Round(time.Millisecond))
+	}
+	if metrics != nil && metrics.TokensPerSecond > 0 {
+		metaInfo += fmt.Sprintf(" | 🚀 **生成速度**: %.1f tok/s", metrics.TokensPerSecond)
+	}
+	metaInfo += fmt.Sprintf(" | 🤖 **モデル**: %s", modelName)
+	if metrics != nil && metrics.PromptTokens+metrics.CompletionTokens > 0 {
+		metaInfo += fmt.Sprintf(" | 📊 **トークン**: 入力 %d / 出力 %d", metrics.PromptTokens, metrics.CompletionTokens)
+	} else {
+		metaInfo += fmt.Sprintf(" | 📊 **推定トークン**: %d", estimatedTokens)
+	}
 
 	response.Message += metaInfo
 
@@ -4177,4 +2204,11 @@ func (ism *interactiveSessionManager) addMetaInfoToResponse(response *Interactio
 	response.Metadata["model_name"] = modelName
 	response.Metadata["estimated_tokens"] = fmt.Sprintf("%d", estimatedTokens)
 	response.Metadata["prompt_length"] = fmt.Sprintf("%d", promptLength)
+	if metrics != nil {
+		response.Metadata["ttft_ms"] = fmt.Sprintf("%d", metrics.TimeToFirstToken.Milliseconds())
+		response.Metadata["tokens_per_second"] = fmt.Sprintf("%.1f", metrics.TokensPerSecond)
+		response.Metadata["prompt_tokens"] = fmt.Sprintf("%d", metrics.PromptTokens)
+		response.Metadata["completion_tokens"] = fmt.Sprintf("%d", metrics.CompletionTokens)
+		response.Metadata["streamed"] = fmt.Sprintf("%t", metrics.Streamed)
+	}
 }
@@ -1,10 +1,14 @@
 package interactive
 
 import (
+	"context"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/storage"
 )
 
 // TestInteractiveSession は基本的なインタラクティブセッションをテストする
@@ -231,3 +235,137 @@ func TestSessionMetrics(t *testing.T) {
 		t.Errorf("期待値: 0.8, 実際値: %f", metrics.UserSatisfactionScore)
 	}
 }
+
+// TestRunShellCommand は !command の実行結果がセッションとコンテキストに記録されることをテストする
+func TestRunShellCommand(t *testing.T) {
+	contextManager := contextmanager.NewSmartContextManager()
+	manager := NewInteractiveSessionManager(contextManager, nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := manager.RunShellCommand(context.Background(), session.ID, "echo hello-shell")
+	if err != nil {
+		t.Fatalf("RunShellCommand failed: %v", err)
+	}
+	if result.ExitCode != 0 || !strings.Contains(result.Content, "hello-shell") {
+		t.Fatalf("unexpected result: %+v", result)
+	}
+	if !strings.Contains(session.LastCommandOutput, "hello-shell") || session.SessionMetadata["last_shell_command"] != "echo hello-shell" {
+		t.Errorf("session not updated: %q %v", session.LastCommandOutput, session.SessionMetadata)
+	}
+
+	found := false
+	for _, item := range contextManager.Items() {
+		if item.Metadata["content_type"] == "shell_output" && strings.Contains(item.Content, "$ echo hello-shell (exit code 0)") {
+			found = true
+		}
+	}
+	if !found {
+		t.Error("shell output should be added to the context")
+	}
+
+	// セキュリティ制約で禁止されたコマンドは実行しない
+	if _, err := manager.RunShellCommand(context.Background(), session.ID, "rm -rf build"); err == nil {
+		t.Error("blocked command should be rejected")
+	}
+}
+
+// TestTranscriptRecordsShellCommands は !command が /save 用の会話記録に残ることをテストする
+func TestTranscriptRecordsShellCommands(t *testing.T) {
+	manager := NewInteractiveSessionManager(contextmanager.NewSmartContextManager(), nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.RunShellCommand(context.Background(), session.ID, "echo hello-transcript"); err != nil {
+		t.Fatalf("RunShellCommand failed: %v", err)
+	}
+	manager.RunShellCommand(context.Background(), session.ID, "rm -rf build")
+
+	record, err := manager.Transcript(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.Model != "test-model" || len(record.Entries) != 2 {
+		t.Fatalf("unexpected transcript: %+v", record)
+	}
+	first, second := record.Entries[0], record.Entries[1]
+	if first.Command != "echo hello-transcript" || !first.Success || !strings.Contains(first.Content, "hello-transcript") {
+		t.Errorf("unexpected first entry: %+v", first)
+	}
+	if second.Success || second.Error == "" {
+		t.Errorf("rejected command should be recorded as failed: %+v", second)
+	}
+
+	// 返されるのはコピーで、呼び出し側の変更は記録に影響しない
+	record.Entries = nil
+	if again, _ := manager.Transcript(session.ID); len(again.Entries) != 2 {
+		t.Error("transcript should be returned as a copy")
+	}
+
+	if _, err := manager.Transcript("missing"); err == nil {
+		t.Error("unknown session should return an error")
+	}
+}
+
+// TestSessionTitleGeneration は最初のやり取りの後に題名が付き、/title で変えた題名は上書きされないことをテストする
+func TestSessionTitleGeneration(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{"Title: \"Fix the login redirect.\"\nExplanation follows"}}
+	cfg := config.DefaultConfig()
+	cfg.SessionTitles.Model = "tiny-model"
+	manager := NewInteractiveSessionManager(nil, provider, nil, nil, nil, "test-model", cfg).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager.startTitleGeneration(session.ID, "login redirects to 404", "The route is missing.")
+	// 2回目以降のやり取りでは生成しない
+	manager.startTitleGeneration(session.ID, "thanks", "You're welcome.")
+	manager.titleWG.Wait()
+	if got := manager.SessionTitle(session.ID); got != "Fix the login redirect" {
+		t.Fatalf("unexpected title: %q", got)
+	}
+	if len(provider.requests) != 1 || provider.requests[0].Model != "tiny-model" {
+		t.Fatalf("title should be generated once with the title model: %+v", provider.requests)
+	}
+
+	if err := manager.SetSessionTitle(session.ID, "  Login fix  "); err != nil {
+		t.Fatal(err)
+	}
+	records, _ := manager.store.ListSessions(storage.SessionQuery{})
+	if len(records) != 1 || records[0].Title != "Login fix" {
+		t.Errorf("renamed title should be stored: %+v", records)
+	}
+	if err := manager.SetSessionTitle(session.ID, "   "); err == nil {
+		t.Error("empty title should be rejected")
+	}
+}
+
+// TestSessionTitleFallback はモデルがなければ最初の入力を切り詰めて題名にすることをテストする
+func TestSessionTitleFallback(t *testing.T) {
+	cfg := config.DefaultConfig()
+	cfg.SessionTitles.MaxLength = 10
+	manager := NewInteractiveSessionManager(nil, nil, nil, nil, nil, "test-model", cfg).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	manager.startTitleGeneration(session.ID, "\n  add   retry to the http client\n", "")
+	manager.titleWG.Wait()
+	if got := manager.SessionTitle(session.ID); got != "add retry…" {
+		t.Errorf("unexpected fallback title: %q", got)
+	}
+
+	cfg.SessionTitles.Enabled = false
+	other, _ := manager.CreateSession(CodingSessionTypeGeneral)
+	manager.startTitleGeneration(other.ID, "anything", "")
+	manager.titleWG.Wait()
+	if got := manager.SessionTitle(other.ID); got != "" {
+		t.Errorf("disabled titles should not be generated: %q", got)
+	}
+}
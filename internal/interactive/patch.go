@@ -0,0 +1,50 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// patchTagPattern は応答中の unified diff のパッチ（複数行）
+var patchTagPattern = regexp.MustCompile(`(?s)<PATCH>(.*?)</PATCH>`)
+
+// executePatchTags は応答中の <PATCH> を適用し、結果と変更したファイルを返す
+// ハンクは行番号・空白のずれを許して当て、1つでも当たらなければそのパッチのファイルは変更しない
+func (ism *interactiveSessionManager) executePatchTags(ctx context.Context, session *InteractiveSession, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range patchTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		patch := strings.Trim(match[1], "\n")
+		actions = append(actions, "パッチ適用")
+		if ism.patchTool == nil {
+			results = append(results, "⚠️ パッチ適用エラー: パッチ適用ツールが初期化されていません")
+			continue
+		}
+		if err := budget.UseTool(ctx, "apply_patch"); err != nil {
+			results = append(results, fmt.Sprintf("⚠️ パッチ適用エラー: %v", err))
+			continue
+		}
+
+		result, err := ism.patchTool.Apply(tools.ApplyPatchRequest{Patch: patch})
+		if err != nil {
+			auditFileWrite(ctx, "", "apply_patch", len(patch), err)
+			results = append(results, fmt.Sprintf("⚠️ %s", result.Content))
+			continue
+		}
+		files, _ := result.Metadata["files"].([]tools.PatchFileResult)
+		for _, file := range files {
+			auditFileWrite(ctx, file.Path, "apply_patch", len(patch), nil)
+			if file.Action == "delete" {
+				continue
+			}
+			ism.recordFileModification(session.ID, file.Path)
+			publishEditApplied(session.ID, file.Path, "patch")
+		}
+		results = append(results, fmt.Sprintf("✅ %s", result.Content))
+	}
+	return results, actions
+}
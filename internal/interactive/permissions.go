@@ -0,0 +1,35 @@
+package interactive
+
+import (
+	"fmt"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// loadPermissions はユーザー設定と .vyb/permissions.yaml の許可規則を読み込む（規則がなければnil）
+// 読み込めない・無効な規則は警告を表示し、読み込めた規則だけを適用する
+func loadPermissions(cfg *config.Config) *security.Permissions {
+	user := config.DefaultPermissionsConfig()
+	if cfg != nil {
+		user = cfg.Permissions
+	}
+	resolved, err := config.LoadPermissions(user, "")
+	if err != nil {
+		fmt.Printf("Warning: 許可規則の読み込みエラー: %v\n", err)
+		return nil
+	}
+	for _, issue := range resolved.Issues {
+		fmt.Printf("Warning: 許可規則: %s\n", issue)
+	}
+	if len(resolved.Rules) == 0 {
+		return nil
+	}
+	return &security.Permissions{
+		Root:          resolved.Root,
+		AllowCommands: resolved.Config.Commands.Allow,
+		DenyCommands:  resolved.Config.Commands.Deny,
+		DenyPaths:     resolved.Config.Paths.Deny,
+		ReadOnlyPaths: resolved.Config.Paths.ReadOnly,
+	}
+}
@@ -0,0 +1,87 @@
+package interactive
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/storage"
+)
+
+// SetStore はセッション・メトリクス・会話フロー・会話の保存先を設定（既定はメモリ）
+// セッション開始前に設定すること
+func (ism *interactiveSessionManager) SetStore(store storage.Store) {
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+	if store == nil {
+		store = storage.NewMemoryStore()
+	}
+	ism.store = store
+}
+
+// persistSession はセッションの記録とメトリクスを保存する
+func (ism *interactiveSessionManager) persistSession(session *InteractiveSession, closed bool) {
+	projectDir, _ := os.Getwd()
+	record := storage.SessionRecord{
+		ID:           session.ID,
+		Type:         session.SessionMetadata["focus"],
+		Title:        session.Title,
+		Model:        ism.modelName,
+		ProjectDir:   projectDir,
+		StartedAt:    session.StartTime,
+		LastActivity: session.LastActivity,
+		Closed:       closed,
+	}
+	if err := ism.store.SaveSession(record); err != nil {
+		ism.reportStoreError(err)
+		return
+	}
+	if session.Metrics != nil {
+		ism.reportStoreError(ism.store.SaveSessionData(session.ID, storage.KindMetrics, session.Metrics))
+	}
+}
+
+// persistSessionByID はアクティブなセッションを保存する（ターン終了時）
+func (ism *interactiveSessionManager) persistSessionByID(sessionID string) {
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+	if session, exists := ism.sessions[sessionID]; exists {
+		ism.persistSession(session, false)
+	}
+}
+
+// loadConversationFlow はセッションの会話フローを読み込む
+func (ism *interactiveSessionManager) loadConversationFlow(sessionID string) (*ConversationFlow, bool) {
+	var flow ConversationFlow
+	found, err := ism.store.LoadSessionData(sessionID, storage.KindConversationFlow, &flow)
+	if err != nil {
+		ism.reportStoreError(err)
+		return nil, false
+	}
+	return &flow, found
+}
+
+// saveConversationFlow はセッションの会話フローを保存する
+func (ism *interactiveSessionManager) saveConversationFlow(sessionID string, flow *ConversationFlow) error {
+	return ism.store.SaveSessionData(sessionID, storage.KindConversationFlow, flow)
+}
+
+// appendMessage は会話の発言を保存する（セッション検索・集計用）
+func (ism *interactiveSessionManager) appendMessage(sessionID, role, content string) {
+	ism.reportStoreError(ism.store.AppendMessage(storage.Message{
+		SessionID: sessionID,
+		Role:      role,
+		Content:   content,
+		Timestamp: time.Now(),
+	}))
+}
+
+// reportStoreError は保存の失敗を最初の1回だけ警告する（保存できなくてもセッションは続ける）
+func (ism *interactiveSessionManager) reportStoreError(err error) {
+	if err == nil {
+		return
+	}
+	ism.storeWarnOnce.Do(func() {
+		fmt.Fprintf(os.Stderr, "Warning: セッション情報を保存できません: %v\n", err)
+	})
+}
@@ -0,0 +1,149 @@
+package interactive
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/input"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// PinFiles はファイルをセッションに固定する（毎ターン最新の内容をプロンプトに含め、編集の既定の対象にする）
+// 固定後のファイル一覧を返す
+func (ism *interactiveSessionManager) PinFiles(sessionID string, paths ...string) ([]string, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	var resolved []string
+	for _, path := range paths {
+		path, err := pinnablePath(workDir, path)
+		if err != nil {
+			return nil, err
+		}
+		resolved = append(resolved, path)
+	}
+
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+	session, exists := ism.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("セッション %s が見つかりません", sessionID)
+	}
+	for _, path := range resolved {
+		if !containsString(session.PinnedFiles, path) {
+			session.PinnedFiles = append(session.PinnedFiles, path)
+		}
+	}
+	if session.CurrentFile == "" && len(session.PinnedFiles) > 0 {
+		session.CurrentFile = session.PinnedFiles[0]
+	}
+	return append([]string(nil), session.PinnedFiles...), nil
+}
+
+// UnpinFiles はファイルの固定を解除する（paths が空なら全て）。固定が残るファイル一覧を返す
+func (ism *interactiveSessionManager) UnpinFiles(sessionID string, paths ...string) ([]string, error) {
+	workDir, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+	session, exists := ism.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("セッション %s が見つかりません", sessionID)
+	}
+
+	remove := make(map[string]bool)
+	for _, path := range paths {
+		normalized := normalizePinPath(workDir, path)
+		if !containsString(session.PinnedFiles, normalized) {
+			return nil, fmt.Errorf("%s は固定されていません", path)
+		}
+		remove[normalized] = true
+	}
+	var kept []string
+	for _, path := range session.PinnedFiles {
+		if len(paths) > 0 && !remove[path] {
+			kept = append(kept, path)
+		}
+	}
+	// 固定を外したファイルが現在のファイルなら、残りの固定ファイルに移す
+	if session.CurrentFile != "" && containsString(session.PinnedFiles, session.CurrentFile) && !containsString(kept, session.CurrentFile) {
+		session.CurrentFile = ""
+		if len(kept) > 0 {
+			session.CurrentFile = kept[0]
+		}
+	}
+	session.PinnedFiles = kept
+	return append([]string(nil), kept...), nil
+}
+
+// PinnedFiles はセッションに固定したファイル
+func (ism *interactiveSessionManager) PinnedFiles(sessionID string) []string {
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+	if session, exists := ism.sessions[sessionID]; exists {
+		return append([]string(nil), session.PinnedFiles...)
+	}
+	return nil
+}
+
+// pinnedFileData は固定したファイルの最新の内容をプロンプト用に読み込む（@メンションと同じ上限）
+func pinnedFileData(paths []string) []prompts.PinnedFile {
+	if len(paths) == 0 {
+		return nil
+	}
+	read := make(map[string]input.FileMention)
+	for _, mention := range input.ResolveFiles(paths, ".") {
+		read[mention.Path] = mention
+	}
+
+	pinned := make([]prompts.PinnedFile, 0, len(paths))
+	for _, path := range paths {
+		file := prompts.PinnedFile{Path: path, Language: strings.TrimPrefix(filepath.Ext(path), ".")}
+		mention, ok := read[path]
+		switch {
+		case !ok:
+			file.Note = "file no longer exists"
+		case mention.Err != nil:
+			file.Note = mention.Err.Error()
+		default:
+			file.Content = strings.TrimSuffix(mention.Content, "\n")
+			if mention.Truncated {
+				file.Note = fmt.Sprintf("truncated: showing %d of %d bytes", len(mention.Content), mention.Size)
+			}
+		}
+		pinned = append(pinned, file)
+	}
+	return pinned
+}
+
+// pinnablePath は固定できるプロジェクト内のファイルか確認し、作業ディレクトリからの相対パスを返す
+func pinnablePath(workDir, path string) (string, error) {
+	normalized := normalizePinPath(workDir, path)
+	info, err := os.Stat(filepath.Join(workDir, normalized))
+	if err != nil {
+		return "", fmt.Errorf("ファイルが見つかりません: %s", path)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("ディレクトリは固定できません: %s", path)
+	}
+	if normalized == ".." || strings.HasPrefix(normalized, "../") {
+		return "", fmt.Errorf("プロジェクト外のファイルは固定できません: %s", path)
+	}
+	return normalized, nil
+}
+
+// normalizePinPath はパスを作業ディレクトリからの相対パス（/ 区切り）にそろえる
+func normalizePinPath(workDir, path string) string {
+	if filepath.IsAbs(path) {
+		if rel, err := filepath.Rel(workDir, path); err == nil {
+			path = rel
+		}
+	}
+	return filepath.ToSlash(filepath.Clean(path))
+}
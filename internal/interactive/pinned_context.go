@@ -0,0 +1,122 @@
+package interactive
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+)
+
+// pinTagRegex はモデルが設計判断・制約等をコンテキストに固定する構造化タグ
+var pinTagRegex = regexp.MustCompile(`<PIN>(.*?)</PIN>`)
+
+// contentTypePinnedNote は /pin-context・<PIN> で追加したメモのコンテキスト項目の種類
+const contentTypePinnedNote = "pinned_note"
+
+// PinContext はコンテキストを固定し、圧縮・退避で除かず毎ターンのプロンプトに含める
+// target がコンテキスト項目のID（/context に表示）ならその項目を、ファイルなら /pin と同じくファイルを固定し、
+// それ以外は設計判断・制約等のメモとして新しい項目を追加して固定する。固定した項目のID（ファイルならパス）を返す
+func (ism *interactiveSessionManager) PinContext(sessionID, target string) (string, error) {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return "", fmt.Errorf("固定する内容が空です")
+	}
+	if _, err := ism.GetSession(sessionID); err != nil {
+		return "", err
+	}
+	if ism.contextManager == nil {
+		return "", fmt.Errorf("このセッションはコンテキスト管理を利用できません")
+	}
+
+	if ism.contextManager.SetPinned(target, true) == nil {
+		return target, nil
+	}
+	if info, err := os.Stat(target); err == nil && !info.IsDir() {
+		pinned, err := ism.PinFiles(sessionID, target)
+		if err != nil {
+			return "", err
+		}
+		return pinned[len(pinned)-1], nil
+	}
+
+	item := &contextmanager.ContextItem{
+		Type:       contextmanager.ContextTypeImmediate,
+		Content:    target,
+		Importance: 1.0,
+		Pinned:     true,
+		Metadata: map[string]string{
+			"session_id":   sessionID,
+			"content_type": contentTypePinnedNote,
+		},
+	}
+	if err := ism.contextManager.AddContext(item); err != nil {
+		return "", err
+	}
+	return item.ID, nil
+}
+
+// UnpinContext は固定を解除する（コンテキスト項目のIDなら項目、ファイルなら /unpin と同じ）
+// 解除したメモはコンテキストに残るが、他の項目と同じく圧縮・退避の対象になる
+func (ism *interactiveSessionManager) UnpinContext(sessionID, target string) error {
+	target = strings.TrimSpace(target)
+	if _, err := ism.GetSession(sessionID); err != nil {
+		return err
+	}
+	if ism.contextManager != nil {
+		for _, item := range ism.PinnedContext(sessionID) {
+			if item.ID == target {
+				return ism.contextManager.SetPinned(target, false)
+			}
+		}
+	}
+	if workDir, err := os.Getwd(); err == nil && containsString(ism.PinnedFiles(sessionID), normalizePinPath(workDir, target)) {
+		_, err := ism.UnpinFiles(sessionID, target)
+		return err
+	}
+	return fmt.Errorf("%s は固定されていません", target)
+}
+
+// PinnedContext はセッションで固定したコンテキスト項目（追加した順）
+func (ism *interactiveSessionManager) PinnedContext(sessionID string) []contextmanager.ContextItem {
+	if ism.contextManager == nil {
+		return nil
+	}
+	var pinned []contextmanager.ContextItem
+	for _, item := range ism.contextManager.Items() {
+		if owner := item.Metadata["session_id"]; item.Pinned && (owner == "" || owner == sessionID) {
+			pinned = append(pinned, item)
+		}
+	}
+	sort.SliceStable(pinned, func(i, j int) bool {
+		return pinned[i].Timestamp.Before(pinned[j].Timestamp)
+	})
+	return pinned
+}
+
+// pinnedContextData は固定したコンテキスト項目の内容をプロンプト用に返す
+func (ism *interactiveSessionManager) pinnedContextData(sessionID string) []string {
+	var contents []string
+	for _, item := range ism.PinnedContext(sessionID) {
+		contents = append(contents, strings.TrimSpace(item.Content))
+	}
+	return contents
+}
+
+// executePinTags はLLM応答中の <PIN> タグの内容をコンテキストに固定し、結果と実行内容を返す
+func (ism *interactiveSessionManager) executePinTags(session *InteractiveSession, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range pinTagRegex.FindAllStringSubmatch(llmResponse, -1) {
+		note := strings.TrimSpace(match[1])
+		id, err := ism.PinContext(session.ID, note)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ コンテキスト固定エラー: %v", err))
+		} else {
+			results = append(results, fmt.Sprintf("📌 コンテキストに固定しました (%s): %s", id, contextPreview(note, 80)))
+		}
+		actions = append(actions, fmt.Sprintf("コンテキスト固定: %s", contextPreview(note, 40)))
+	}
+	return results, actions
+}
@@ -0,0 +1,163 @@
+package interactive
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+)
+
+// TestPinnedFiles は固定したファイルが毎ターン最新の内容で読まれ、解除すると現在のファイルが移ることをテストする
+func TestPinnedFiles(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err := os.WriteFile("main.go", []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile("util.go", []byte("package main\n\nfunc util() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manager := NewInteractiveSessionManager(nil, nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := manager.PinFiles(session.ID, "missing.go"); err == nil {
+		t.Error("存在しないファイルは固定できないはず")
+	}
+	if _, err := manager.PinFiles(session.ID, "../outside.go"); err == nil {
+		t.Error("プロジェクト外のファイルは固定できないはず")
+	}
+	pinned, err := manager.PinFiles(session.ID, "./main.go", filepath.Join(dir, "util.go"), "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pinned) != 2 || pinned[0] != "main.go" || pinned[1] != "util.go" {
+		t.Fatalf("pinned = %v", pinned)
+	}
+	if session.CurrentFile != "main.go" {
+		t.Errorf("CurrentFile = %q, 最初の固定ファイルのはず", session.CurrentFile)
+	}
+
+	// 固定後に編集した内容がプロンプトに入る
+	if err := os.WriteFile("main.go", []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := manager.interactivePromptData(session, "")
+	if len(data.Pinned) != 2 || data.Pinned[0].Content != "package main\n\nfunc main() {}" || data.Pinned[0].Language != "go" {
+		t.Fatalf("Pinned = %+v", data.Pinned)
+	}
+	if err := os.Remove("util.go"); err != nil {
+		t.Fatal(err)
+	}
+	if data := manager.interactivePromptData(session, ""); data.Pinned[1].Content != "" || data.Pinned[1].Note == "" {
+		t.Errorf("削除したファイルは注記だけのはず: %+v", data.Pinned[1])
+	}
+
+	if _, err := manager.UnpinFiles(session.ID, "other.go"); err == nil {
+		t.Error("固定していないファイルの解除はエラーのはず")
+	}
+	remaining, err := manager.UnpinFiles(session.ID, "main.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || session.CurrentFile != "util.go" {
+		t.Errorf("remaining = %v, CurrentFile = %q", remaining, session.CurrentFile)
+	}
+	if remaining, _ := manager.UnpinFiles(session.ID); len(remaining) != 0 || len(manager.PinnedFiles(session.ID)) != 0 {
+		t.Errorf("全て解除されるはず: %v", remaining)
+	}
+}
+
+// TestPinnedContext は固定したメモ・項目・ファイルがプロンプトと /context の一覧に残り、解除できることをテストする
+func TestPinnedContext(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+	if err := os.WriteFile("schema.sql", []byte("create table users (id int);\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	contextManager := contextmanager.NewSmartContextManager()
+	manager := NewInteractiveSessionManager(contextManager, nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	noteID, err := manager.PinContext(session.ID, "Decision: keep the public API backwards compatible")
+	if err != nil {
+		t.Fatal(err)
+	}
+	existing := &contextmanager.ContextItem{ID: "ctx_existing", Type: contextmanager.ContextTypeShortTerm, Content: "Constraint: no cgo"}
+	if err := contextManager.AddContext(existing); err != nil {
+		t.Fatal(err)
+	}
+	if id, err := manager.PinContext(session.ID, "ctx_existing"); err != nil || id != "ctx_existing" || !existing.Pinned {
+		t.Fatalf("PinContext(id) = %q, %v (pinned %v)", id, err, existing.Pinned)
+	}
+	if id, err := manager.PinContext(session.ID, "schema.sql"); err != nil || id != "schema.sql" {
+		t.Fatalf("PinContext(file) = %q, %v", id, err)
+	}
+	if files := manager.PinnedFiles(session.ID); len(files) != 1 || files[0] != "schema.sql" {
+		t.Errorf("ファイルは /pin と同じく固定されるはず: %v", files)
+	}
+
+	// モデルも <PIN> タグで固定でき、タグは本文から除かれる
+	response := "Agreed. <PIN>Decision: store timestamps in UTC</PIN>"
+	results, actions := manager.executePinTags(session, response)
+	if len(results) != 1 || len(actions) != 1 || !strings.HasPrefix(results[0], "📌") {
+		t.Fatalf("results = %v, actions = %v", results, actions)
+	}
+	if clean := manager.extractCleanMessage(response); strings.Contains(clean, "PIN") {
+		t.Errorf("タグが本文に残っています: %q", clean)
+	}
+
+	data := manager.interactivePromptData(session, "")
+	if len(data.PinnedContext) != 3 || data.PinnedContext[0] != "Decision: keep the public API backwards compatible" || data.PinnedContext[2] != "Decision: store timestamps in UTC" {
+		t.Fatalf("PinnedContext = %q", data.PinnedContext)
+	}
+	prompt := manager.renderInteractivePrompt(data)
+	if !strings.Contains(prompt, "## 📍 Pinned Context") || !strings.Contains(prompt, "- Constraint: no cgo") {
+		t.Errorf("固定したコンテキストがプロンプトにありません:\n%s", prompt)
+	}
+
+	contextManager.AddContext(&contextmanager.ContextItem{Type: contextmanager.ContextTypeImmediate, Content: "unpinned item", Importance: 0.9})
+	contextUsage, err := manager.ContextUsage(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(contextUsage.Items) != 4 || !contextUsage.Items[2].Pinned || contextUsage.Items[3].Pinned {
+		t.Errorf("固定した項目が一覧の先頭にありません: %+v", contextUsage.Items)
+	}
+
+	if err := manager.UnpinContext(session.ID, noteID); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UnpinContext(session.ID, "schema.sql"); err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.UnpinContext(session.ID, "ctx_unknown"); err == nil {
+		t.Error("固定していない項目の解除はエラーのはず")
+	}
+	if pinned := manager.PinnedContext(session.ID); len(pinned) != 2 || len(manager.PinnedFiles(session.ID)) != 0 {
+		t.Errorf("解除後の固定 = %+v", pinned)
+	}
+}
@@ -0,0 +1,253 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/ui"
+)
+
+// 応答生成パイプライン
+//
+// 1つの入力に対する応答は次の段を順に通して生成する。各段はインターフェースで、
+// RegisterStages で登録した実装を設定（pipeline.stages）で選んで差し替えられる。
+//
+//	intent     入力の意図を判定する
+//	retrieval  コンテキストを集めてモデルへのプロンプトを組み立てる
+//	generation モデルに問い合わせる
+//	execution  応答中のツール呼び出し・コマンド・コード提案を実行する
+//	render     ユーザーに返す応答を組み立てる
+
+// DefaultStageName は組み込みの段の実装名
+const DefaultStageName = "default"
+
+// Turn は1つの入力の処理状態（各段が順に埋める）
+type Turn struct {
+	Session   *InteractiveSession
+	Input     string
+	StartTime time.Time
+
+	Intent   string                // IntentStage が設定
+	Prompt   string                // RetrievalStage が設定
+	Request  llm.ChatRequest       // GenerationStage がモデルに送ったリクエスト
+	Content  string                // GenerationStage が設定したモデルの応答
+	Metrics  *llm.ResponseMetrics  // GenerationStage の問い合わせの実測値（キャッシュヒット等では nil）
+	Progress *ui.ProgressIndicator // generation 以降の進捗表示（render で完了させる）
+
+	// Response を設定すると、generation までの段では以降の段を省いてそのまま返し、
+	// execution では render に組み立て済みの応答として渡す
+	Response *InteractionResponse
+
+	// ExecutionStage の結果（RenderStage が応答に含める）
+	ResponseType  ResponseType
+	Suggestions   []*CodeSuggestion
+	Analysis      string                 // 分析系の質問で実行したプロジェクト分析の結果
+	CommandOutput string                 // 確認せずに実行したコマンドの出力
+	CommandRan    bool                   // コマンドを確認せずに実行したか
+	Impact        *analysis.ImpactReport // 保留中の提案の影響範囲
+
+	approval    approvalDecision
+	approvalErr error
+	streamed    *toolStream // generation 中に実行を始めたコマンド
+}
+
+// IntentStage は入力の意図を判定して Turn.Intent を設定する
+type IntentStage interface {
+	AnalyzeIntent(ctx context.Context, turn *Turn) error
+}
+
+// RetrievalStage はコンテキストを集めて Turn.Prompt を組み立てる
+type RetrievalStage interface {
+	Retrieve(ctx context.Context, turn *Turn) error
+}
+
+// GenerationStage はモデルに問い合わせて Turn.Content を設定する
+type GenerationStage interface {
+	Generate(ctx context.Context, turn *Turn) error
+}
+
+// ExecutionStage はモデルの応答に含まれるツール呼び出し・コマンド・コード提案を実行する
+type ExecutionStage interface {
+	Execute(ctx context.Context, turn *Turn) error
+}
+
+// RenderStage は Turn から Turn.Response を組み立てる
+type RenderStage interface {
+	Render(ctx context.Context, turn *Turn) error
+}
+
+// Stages は応答生成パイプラインを構成する段（登録する実装は差し替える段のみ設定すればよい）
+type Stages struct {
+	Intent     IntentStage
+	Retrieval  RetrievalStage
+	Generation GenerationStage
+	Execution  ExecutionStage
+	Render     RenderStage
+}
+
+// StageDeps は登録された段の実装を作成する時に渡す依存
+// Defaults は組み込みの段で、包んで前後に処理を加えることができる
+type StageDeps struct {
+	LLM      llm.Provider
+	Model    string
+	Config   *config.Config
+	Defaults Stages
+}
+
+// StageFactory は段の実装を作成する
+type StageFactory func(deps StageDeps) (Stages, error)
+
+var (
+	stageMu        sync.RWMutex
+	stageFactories = make(map[string]StageFactory)
+)
+
+// RegisterStages は段の実装を名前で登録する（pipeline.stages で段毎に選ぶ）
+// 同じ名前で登録し直すと置き換える。"default" は組み込みの実装のため登録できない
+func RegisterStages(name string, factory StageFactory) error {
+	if name == "" || name == DefaultStageName {
+		return fmt.Errorf("段の実装名 %q は登録できません", name)
+	}
+	if factory == nil {
+		return fmt.Errorf("段の実装 %s の作成関数がありません", name)
+	}
+	stageMu.Lock()
+	defer stageMu.Unlock()
+	stageFactories[name] = factory
+	return nil
+}
+
+// RegisteredStages は登録されている段の実装名（"default" を含む）
+func RegisteredStages() []string {
+	stageMu.RLock()
+	defer stageMu.RUnlock()
+	names := []string{DefaultStageName}
+	for name := range stageFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names[1:])
+	return names
+}
+
+// buildPipeline は設定で選ばれた段の実装でパイプラインを構成する
+// 登録されていない・作成に失敗した・該当する段を持たない実装は警告して組み込みの段を使う
+func (ism *interactiveSessionManager) buildPipeline() Stages {
+	defaults := Stages{
+		Intent:     &intentStage{ism},
+		Retrieval:  &retrievalStage{ism},
+		Generation: &generationStage{ism},
+		Execution:  &executionStage{ism},
+		Render:     &renderStage{ism},
+	}
+	if ism.config == nil || len(ism.config.Pipeline.Stages) == 0 {
+		return defaults
+	}
+
+	deps := StageDeps{LLM: ism.llmProvider, Model: ism.getConfiguredModel(), Config: ism.config, Defaults: defaults}
+	created := make(map[string]Stages)
+	resolve := func(kind string) (Stages, bool) {
+		name := ism.config.Pipeline.Stages[kind]
+		if name == "" || name == DefaultStageName {
+			return Stages{}, false
+		}
+		if stages, ok := created[name]; ok {
+			return stages, true
+		}
+		stageMu.RLock()
+		factory := stageFactories[name]
+		stageMu.RUnlock()
+		if factory == nil {
+			fmt.Printf("Warning: パイプラインの段 %s の実装 %s は登録されていません（組み込みの実装を使用）\n", kind, name)
+			return Stages{}, false
+		}
+		stages, err := factory(deps)
+		if err != nil {
+			fmt.Printf("Warning: パイプラインの段の実装 %s の作成エラー: %v\n", name, err)
+			return Stages{}, false
+		}
+		created[name] = stages
+		return stages, true
+	}
+	pipeline := defaults
+	for _, kind := range config.ValidPipelineStages() {
+		if stages, ok := resolve(kind); ok && !pipeline.replace(kind, stages) {
+			fmt.Printf("Warning: 実装 %s は段 %s を提供していません（組み込みの実装を使用）\n", ism.config.Pipeline.Stages[kind], kind)
+		}
+	}
+	return pipeline
+}
+
+// replace は from が kind の段を提供していれば置き換える
+func (p *Stages) replace(kind string, from Stages) bool {
+	switch {
+	case kind == "intent" && from.Intent != nil:
+		p.Intent = from.Intent
+	case kind == "retrieval" && from.Retrieval != nil:
+		p.Retrieval = from.Retrieval
+	case kind == "generation" && from.Generation != nil:
+		p.Generation = from.Generation
+	case kind == "execution" && from.Execution != nil:
+		p.Execution = from.Execution
+	case kind == "render" && from.Render != nil:
+		p.Render = from.Render
+	default:
+		return false
+	}
+	return true
+}
+
+// respond は意図を判定済みのターンを retrieval から render までの段に順に通して応答を返す
+func (p Stages) respond(ctx context.Context, turn *Turn) (*InteractionResponse, error) {
+	if turn.StartTime.IsZero() {
+		turn.StartTime = time.Now()
+	}
+	if err := p.Retrieval.Retrieve(ctx, turn); err != nil {
+		return nil, fmt.Errorf("コンテキスト取得エラー: %w", err)
+	}
+	if turn.Response != nil {
+		return turn.Response, nil
+	}
+
+	// ClaudeCode風進捗表示を開始（送信トークン数はプロンプトから推定）
+	turn.Progress = ui.NewProgressIndicator("Generating response…", len(turn.Prompt)/4)
+	turn.Progress.Start()
+	defer turn.Progress.Stop()
+
+	// 中断可能なコンテキストを作成（ターンのコンテキストの添付ファイル・画像を引き継ぐ）
+	if turn.Progress.GetContext().Err() != nil {
+		turn.Progress.CompleteWithResult(false, "Request interrupted")
+		return nil, fmt.Errorf("request interrupted by user")
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-turn.Progress.GetContext().Done():
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	if err := p.Generation.Generate(ctx, turn); err != nil {
+		return nil, err
+	}
+	if turn.Response != nil {
+		return turn.Response, nil
+	}
+	if err := p.Execution.Execute(ctx, turn); err != nil {
+		return nil, err
+	}
+	if err := p.Render.Render(ctx, turn); err != nil {
+		return nil, err
+	}
+	if turn.Response == nil {
+		return nil, fmt.Errorf("応答が組み立てられていません")
+	}
+	return turn.Response, nil
+}
@@ -0,0 +1,253 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// 組み込みの段（セッション管理の状態を使うためマネージャーを持つ）
+
+// intentStage は入力を分類し、SmartContextManagerへの記録とCognitiveEngineによる推論を行う
+type intentStage struct {
+	ism *interactiveSessionManager
+}
+
+func (s *intentStage) AnalyzeIntent(ctx context.Context, turn *Turn) error {
+	intent, err := s.ism.analyzeUserIntent(ctx, turn.Session, turn.Input)
+	if err != nil {
+		return err
+	}
+	turn.Intent = intent
+
+	// SmartContextManagerを活用してユーザー入力をコンテキストに追加
+	s.ism.addToSmartContext(turn.Session.ID, turn.Input, "user_input")
+
+	// CognitiveEngineを活用した高度な推論処理
+	reasoningInsights := s.ism.performCognitiveReasoning(ctx, turn.Input, intent)
+
+	// 推論結果をセッションメタデータに追加
+	session := turn.Session
+	if session.SessionMetadata == nil {
+		session.SessionMetadata = make(map[string]string)
+	}
+	if reasoningInsights["status"] == "reasoning_completed" {
+		session.SessionMetadata["reasoning_confidence"] = fmt.Sprintf("%.2f", reasoningInsights["confidence"])
+		session.SessionMetadata["reasoning_processing_time"] = fmt.Sprintf("%v", reasoningInsights["processing_time"])
+		if reasoningInsights["solution_approach"] != nil {
+			session.SessionMetadata["reasoning_approach"] = reasoningInsights["solution_approach"].(string)
+		}
+	}
+	return nil
+}
+
+// retrievalStage は圧縮済みコンテキスト・セッション履歴・固定ファイルからプロンプトを組み立てる
+type retrievalStage struct {
+	ism *interactiveSessionManager
+}
+
+func (s *retrievalStage) Retrieve(_ context.Context, turn *Turn) error {
+	turn.Prompt = s.ism.buildInteractivePrompt(turn.Session, turn.Input, turn.Intent)
+	return nil
+}
+
+// generationStage は設定されたモデルにプロンプトを送り、応答の言語を統一する
+// 問い合わせに失敗した場合はフォールバック応答を Turn.Response に設定する
+type generationStage struct {
+	ism *interactiveSessionManager
+}
+
+func (s *generationStage) Generate(ctx context.Context, turn *Turn) error {
+	turn.Request = llm.ChatRequest{
+		Model: s.ism.getConfiguredModel(), // 設定からモデルを取得
+		Messages: []llm.ChatMessage{
+			{
+				Role:    "user",
+				Content: turn.Prompt,
+			},
+		},
+		Stream: false,
+	}
+
+	// 応答の生成中に完成したコマンドから実行を始める（結果は execution 段で使う）
+	llmResponse, streamed, err := s.ism.chatWithToolStream(ctx, turn.Session, turn.Request)
+	turn.streamed = streamed
+	if err != nil {
+		// LLM失敗時の進捗表示完了
+		turn.Progress.CompleteWithResult(false, "LLM request failed")
+		response, err := s.ism.generateFallbackResponse(turn.Session, turn.Input, turn.Intent, err)
+		if err != nil {
+			return err
+		}
+		turn.Response = response
+		return nil
+	}
+
+	// 応答受信トークン数を更新（プロバイダーが報告しなければ文字数から推定）
+	turn.Metrics = llmResponse.Metrics
+	if tokens := llmResponse.CompletionTokens(); tokens > 0 {
+		turn.Progress.UpdateTokens(tokens)
+	} else {
+		turn.Progress.UpdateTokens(len(llmResponse.Message.Content) / 4)
+	}
+
+	// LLM応答の言語統一処理（繁体字等を日本語に修正）
+	turn.Content = s.ism.normalizeLanguage(llmResponse.Message.Content)
+
+	// SmartContextManagerにLLM応答を追加
+	s.ism.addToSmartContext(turn.Session.ID, turn.Content, "llm_response")
+	return nil
+}
+
+// executionStage は構造化タグのツール実行（エージェントループ）、分析系の質問のプロジェクト分析、
+// コード提案の安全なコマンドの実行・確認待ちへの登録と自動承認ポリシーの適用を行う
+type executionStage struct {
+	ism *interactiveSessionManager
+}
+
+func (s *executionStage) Execute(ctx context.Context, turn *Turn) error {
+	ism := s.ism
+	session := turn.Session
+
+	// 構造化された応答を解析して実際のツール実行を行い、実行結果をモデルに返して最終回答まで続ける
+	execution := ism.executeStructuredTags(ctx, session, turn.Content, turn.streamed)
+	if len(execution.actions) > 0 {
+		turn.Response = ism.runAgentLoop(ctx, session, turn.Request, turn.Content, execution, turn.Progress)
+		return nil
+	}
+
+	// 構造化応答がない場合：分析系の質問は強制的にANALYSISを実行
+	if ism.shouldForceAnalysis(turn.Input, turn.Intent) {
+		turn.ResponseType = ResponseTypeAnalysis
+		turn.Analysis = ism.performAnalysis(session, turn.Input)
+		return nil
+	}
+
+	turn.ResponseType = ism.determineResponseType(turn.Content, turn.Intent)
+	if turn.ResponseType != ResponseTypeCodeSuggestion {
+		return nil
+	}
+
+	// コード提案の場合、提案を解析
+	suggestions, err := ism.extractCodeSuggestionsFromLLM(turn.Content, turn.Input)
+	if err != nil || len(suggestions) == 0 {
+		return nil
+	}
+	turn.Suggestions = suggestions
+
+	// Claude Code式: コマンド実行の場合は即座に実行
+	if ism.isCommandSuggestion(suggestions[0].SuggestedCode) {
+		extractedCmd := ism.extractCommandFromSuggestion(suggestions[0].SuggestedCode)
+		if security.NewCommandPolicy(".", nil).Evaluate(extractedCmd) == nil {
+			// 読み取り中心のコマンドの規則を満たすものは即座に実行（&& や | で繋いだコマンドも評価）
+			if err := ism.executeCommandDirectly(ctx, session, suggestions[0]); err != nil {
+				return fmt.Errorf("コマンド実行エラー: %w", err)
+			}
+			turn.CommandRan = true
+			turn.CommandOutput = session.LastCommandOutput
+			session.State = SessionStateIdle
+		} else {
+			// 危険なコマンドは確認を求める
+			session.PendingSuggestion = suggestions[0]
+			session.State = SessionStateWaitingForConfirmation
+		}
+	} else {
+		// ファイル操作は確認を求める（危険なファイル操作は自動承認ポリシーでも確認する）
+		// 確認中に対象ファイルが変わった場合に検出できるよう、現在の内容を記録しておく
+		ism.snapshotSuggestionBase(suggestions[0])
+		session.PendingSuggestion = suggestions[0]
+		session.State = SessionStateWaitingForConfirmation
+		// 適用前に影響範囲を確認プロンプトへ表示
+		if report := ism.suggestionImpact(ctx, session.PendingSuggestion); report != nil {
+			turn.Impact = report
+			session.PendingSuggestion.Metadata["blast_radius"] = report.Summary()
+		}
+	}
+
+	if session.PendingSuggestion == suggestions[0] {
+		// 確認ダイアログの前に自動承認ポリシーを評価し、一致したルールに従って適用・破棄する
+		turn.approval = ism.evaluateApproval(ctx, suggestions[0])
+		ism.recordProvenance(ctx, session, suggestions[0], turn.Input, nil,
+			fixedConfidence(suggestions[0].Confidence, i18n.T("why.factor.extracted")))
+		if turn.approval.rule != "" {
+			turn.approvalErr = ism.enforceApproval(ctx, session, suggestions[0], turn.approval)
+		}
+	}
+	return nil
+}
+
+// renderStage は実行結果から応答を組み立て、メタ情報を追加して進捗表示を完了する
+type renderStage struct {
+	ism *interactiveSessionManager
+}
+
+func (s *renderStage) Render(_ context.Context, turn *Turn) error {
+	ism := s.ism
+	session := turn.Session
+
+	// エージェントループの最終応答
+	if turn.Response != nil {
+		ism.addMetaInfoToResponse(turn.Response, turn.StartTime, turn.Request.Model, len(turn.Prompt), turn.Metrics)
+		turn.Progress.CompleteWithResult(true, "Structured response executed successfully")
+		return nil
+	}
+
+	if turn.Analysis != "" {
+		turn.Response = &InteractionResponse{
+			SessionID:            session.ID,
+			ResponseType:         ResponseTypeAnalysis,
+			Message:              fmt.Sprintf("🔍 **プロジェクト分析結果**\n\n%s\n\n**AI応答:**\n%s", turn.Analysis, turn.Content),
+			RequiresConfirmation: false,
+			Metadata: map[string]string{
+				"forced_analysis": "true",
+				"analysis_result": "included",
+				"ai_response":     "enhanced",
+			},
+			GeneratedAt: time.Now(),
+		}
+		ism.addMetaInfoToResponse(turn.Response, turn.StartTime, turn.Request.Model, len(turn.Prompt), turn.Metrics)
+		turn.Progress.CompleteWithResult(true, "Analysis completed successfully")
+		return nil
+	}
+
+	// 通常のLLM応答を返す
+	response := &InteractionResponse{
+		SessionID:            session.ID,
+		ResponseType:         turn.ResponseType,
+		Message:              turn.Content,
+		RequiresConfirmation: ism.requiresConfirmation(turn.ResponseType, turn.Intent),
+		Suggestions:          turn.Suggestions,
+		Metadata:             make(map[string]string),
+		GeneratedAt:          time.Now(),
+	}
+	if turn.CommandRan {
+		// 実行結果を応答に含める
+		response.ResponseType = ResponseTypeMessage
+		response.Message = fmt.Sprintf("コマンド実行結果:\n%s", turn.CommandOutput)
+		response.RequiresConfirmation = false
+	}
+	if turn.Impact != nil {
+		response.Message += "\n\n" + turn.Impact.Format()
+		response.Metadata["blast_radius"] = turn.Impact.Summary()
+	}
+	if len(turn.Suggestions) > 0 {
+		renderApproval(response, turn.Suggestions[0], turn.approval, turn.approvalErr)
+	}
+
+	response.Metadata["intent"] = turn.Intent
+	response.Metadata["session_type"] = ism.sessionTypeToString(session.Type)
+	response.Metadata["llm_model"] = turn.Request.Model
+
+	// メタ情報を追加
+	ism.addMetaInfoToResponse(response, turn.StartTime, turn.Request.Model, len(turn.Prompt), turn.Metrics)
+
+	// 成功時の進捗完了メッセージ
+	turn.Progress.CompleteWithResult(true, "Response generated successfully")
+
+	turn.Response = response
+	return nil
+}
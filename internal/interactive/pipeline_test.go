@@ -0,0 +1,111 @@
+package interactive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/ui"
+)
+
+// cannedGeneration はモデルに問い合わせずに決まった応答を返す段
+type cannedGeneration struct {
+	content string
+}
+
+func (g *cannedGeneration) Generate(_ context.Context, turn *Turn) error {
+	turn.Request.Model = "canned"
+	turn.Content = g.content
+	return nil
+}
+
+// footerRender は組み込みの render 段の応答に注記を加える段
+type footerRender struct {
+	next RenderStage
+}
+
+func (r *footerRender) Render(ctx context.Context, turn *Turn) error {
+	if err := r.next.Render(ctx, turn); err != nil {
+		return err
+	}
+	turn.Response.Message += "\n-- footer"
+	return nil
+}
+
+func TestDefaultStages(t *testing.T) {
+	provider := &scriptedProvider{responses: []string{"plain answer"}}
+	manager, session := newAgentLoopManager(t, provider, config.DefaultAgentLoopConfig())
+	ctx := context.Background()
+
+	turn := &Turn{Session: session, Input: "hello there"}
+	if err := manager.pipeline.Intent.AnalyzeIntent(ctx, turn); err != nil || turn.Intent != "general_request" {
+		t.Fatalf("unexpected intent %q: %v", turn.Intent, err)
+	}
+	response, err := manager.pipeline.respond(ctx, turn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(turn.Prompt, "hello there") || len(provider.requests) != 1 {
+		t.Errorf("prompt was not sent: %d requests", len(provider.requests))
+	}
+	if !strings.HasPrefix(response.Message, "plain answer\n") || response.Metadata["llm_model"] != "test-model" || response.Metadata["intent"] != "general_request" {
+		t.Errorf("unexpected response: %q %+v", response.Message, response.Metadata)
+	}
+
+	// render 段は実行結果だけから応答を組み立てる
+	turn = &Turn{Session: session, Intent: "general_request", Content: "ls", CommandRan: true, CommandOutput: "main.go",
+		Progress: ui.NewProgressIndicator("test", 0)}
+	if err := (&renderStage{manager}).Render(ctx, turn); err != nil {
+		t.Fatal(err)
+	}
+	if turn.Response.ResponseType != ResponseTypeMessage || !strings.HasPrefix(turn.Response.Message, "コマンド実行結果:\nmain.go\n") || turn.Response.RequiresConfirmation {
+		t.Errorf("command result was not rendered: %+v", turn.Response)
+	}
+}
+
+func TestRegisteredStages(t *testing.T) {
+	err := RegisterStages("test-canned", func(deps StageDeps) (Stages, error) {
+		return Stages{
+			Generation: &cannedGeneration{content: "canned answer"},
+			Render:     &footerRender{next: deps.Defaults.Render},
+		}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if RegisterStages(DefaultStageName, func(StageDeps) (Stages, error) { return Stages{}, nil }) == nil {
+		t.Error("the default name should be reserved")
+	}
+	if names := strings.Join(RegisteredStages(), ","); !strings.HasPrefix(names, "default,") || !strings.Contains(names, "test-canned") {
+		t.Errorf("unexpected stage names: %s", names)
+	}
+
+	cfg := config.DefaultConfig()
+	cfg.Pipeline.Stages = map[string]string{
+		"intent":     "test-canned", // intent 段は提供していないため組み込みを使う
+		"retrieval":  "not-registered",
+		"generation": "test-canned",
+		"render":     "test-canned",
+	}
+	manager := NewInteractiveSessionManager(nil, nil, nil, nil, nil, "test-model", cfg).(*interactiveSessionManager)
+	if _, ok := manager.pipeline.Intent.(*intentStage); !ok {
+		t.Errorf("intent stage should fall back to the default: %T", manager.pipeline.Intent)
+	}
+	if _, ok := manager.pipeline.Retrieval.(*retrievalStage); !ok {
+		t.Errorf("retrieval stage should fall back to the default: %T", manager.pipeline.Retrieval)
+	}
+
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	turn := &Turn{Session: session, Input: "hello", Intent: "general_request"}
+	response, err := manager.pipeline.respond(context.Background(), turn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(response.Message, "canned answer\n") || !strings.HasSuffix(response.Message, "\n-- footer") || response.Metadata["llm_model"] != "canned" {
+		t.Errorf("registered stages were not used: %q %+v", response.Message, response.Metadata)
+	}
+}
@@ -41,6 +41,13 @@ func NewProactiveExtension(sessionManager *interactiveSessionManager) *Proactive
 	}
 }
 
+// SetProjectPath は分析対象のディレクトリを切り替え、前の分析結果を破棄
+func (pe *ProactiveExtension) SetProjectPath(projectPath string) {
+	pe.projectPath = projectPath
+	pe.analysisCache = nil
+	pe.lastAnalysisTime = time.Time{}
+}
+
 // EnhanceProcessUserInput は既存のProcessUserInputをプロアクティブ機能で拡張
 func (pe *ProactiveExtension) EnhanceProcessUserInput(
 	ctx context.Context,
@@ -125,8 +132,14 @@ func (pe *ProactiveExtension) performCognitiveAnalysis(ctx context.Context, inpu
 		RequiredMetrics: []string{"confidence"}, // 最小限のメトリクス
 	}
 
+	ctx, release, err := pe.sessionManager.acquireAnalysis(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	// 認知分析を実行（エラーが発生してもプロアクティブ機能に影響しない）
-	_, err := pe.sessionManager.cognitiveAnalyzer.AnalyzeCognitive(ctx, request)
+	_, err = pe.sessionManager.cognitiveAnalyzer.AnalyzeCognitive(ctx, request)
 	return err
 }
 
@@ -273,6 +286,12 @@ func (pe *ProactiveExtension) shouldPerformAnalysis() bool {
 }
 
 func (pe *ProactiveExtension) performProjectAnalysis(ctx context.Context) error {
+	_, release, err := pe.sessionManager.acquireAnalysis(ctx)
+	if err != nil {
+		return err
+	}
+	defer release()
+
 	analysis, err := pe.projectAnalyzer.AnalyzeProject(pe.projectPath)
 	if err != nil {
 		return err
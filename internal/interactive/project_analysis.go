@@ -0,0 +1,723 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/ai"
+	"github.com/glkt/vyb-code/internal/analysis"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+// performAnalysis は科学的認知分析システムを使用した高度な分析処理を実行
+func (ism *interactiveSessionManager) performAnalysis(_ *InteractiveSession, query string) string {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Second)
+	defer cancel()
+
+	var analysisComponents []string
+
+	// 1. 既存の統合分析システムを活用
+	unifiedAnalysisResult := ism.performUnifiedAnalysis(query)
+	if unifiedAnalysisResult != "" {
+		analysisComponents = append(analysisComponents, unifiedAnalysisResult)
+	}
+
+	// 2. 高度な認知分析の実行
+	if ism.cognitiveAnalyzer != nil {
+		cognitiveResult := ism.performDetailedCognitiveAnalysis(ctx, query)
+		if cognitiveResult != "" {
+			analysisComponents = append(analysisComponents, cognitiveResult)
+		}
+	}
+
+	// 3. プロジェクト構造の詳細分析
+	if projectPath, err := os.Getwd(); err == nil {
+		structureAnalysis := ism.performProjectStructureAnalysisImpl(projectPath)
+		if structureAnalysis != "" {
+			analysisComponents = append(analysisComponents, structureAnalysis)
+		}
+	}
+
+	// 4. Git分析の詳細化
+	gitAnalysis := ism.performDetailedGitAnalysis()
+	if gitAnalysis != "" {
+		analysisComponents = append(analysisComponents, gitAnalysis)
+	}
+
+	// 5. 依存関係とセキュリティ分析
+	securityAnalysis := ism.performSecurityAnalysisImpl()
+	if securityAnalysis != "" {
+		analysisComponents = append(analysisComponents, securityAnalysis)
+	}
+
+	// 6. フォールバック
+	if len(analysisComponents) == 0 {
+		analysisComponents = append(analysisComponents, ism.performBasicAnalysis(query))
+	}
+
+	// 分析結果の統合フォーマット
+	return ism.formatComprehensiveAnalysisResponse(query, analysisComponents)
+}
+
+// performScientificCognitiveAnalysis は科学的認知分析を実行
+func (ism *interactiveSessionManager) performScientificCognitiveAnalysis(query string) string {
+	if ism.cognitiveAnalyzer == nil {
+		return ""
+	}
+
+	ctx, release, err := ism.acquireAnalysis(context.Background())
+	if err != nil {
+		return ""
+	}
+	defer release()
+
+	// 分析リクエストを作成
+	analysisRequest := &analysis.AnalysisRequest{
+		UserInput: query,
+		Response:  "", // 初期分析時は空
+		Context: map[string]interface{}{
+			"project_type":  "go",
+			"analysis_type": "project_analysis",
+		},
+		AnalysisDepth:   "standard",
+		RequiredMetrics: []string{"confidence", "reasoning_depth", "creativity"},
+	}
+
+	// 認知分析を実行
+	result, err := ism.cognitiveAnalyzer.AnalyzeCognitive(ctx, analysisRequest)
+	if err != nil {
+		return fmt.Sprintf("🧠 科学的認知分析: エラー (%v)", err)
+	}
+
+	// 結果をフォーマット
+	return ism.formatCognitiveAnalysisResult(result)
+}
+
+// formatCognitiveAnalysisResult は認知分析結果をフォーマット
+func (ism *interactiveSessionManager) formatCognitiveAnalysisResult(result *analysis.CognitiveAnalysisResult) string {
+	var parts []string
+
+	parts = append(parts, "🧠 **科学的認知分析結果**")
+
+	// セマンティックエントロピーによる信頼度
+	if result.Confidence != nil {
+		parts = append(parts, fmt.Sprintf("📊 信頼度: %.2f (セマンティックエントロピー: %.3f)",
+			result.Confidence.OverallConfidence, result.Confidence.SemanticEntropy))
+	}
+
+	// 推論深度分析
+	if result.ReasoningDepth != nil {
+		parts = append(parts, fmt.Sprintf("🔬 推論深度: %d (論理構造スコア: %.3f)",
+			result.ReasoningDepth.OverallDepth, result.ReasoningDepth.ComplexityScore))
+	}
+
+	// 創造性測定
+	if result.Creativity != nil {
+		parts = append(parts, fmt.Sprintf("🎨 創造性: %.2f (流暢性: %.2f, 柔軟性: %.2f, 独創性: %.2f)",
+			result.Creativity.OverallScore,
+			result.Creativity.Fluency,
+			result.Creativity.Flexibility,
+			result.Creativity.Originality))
+	}
+
+	// 統合評価
+	parts = append(parts, fmt.Sprintf("⚡ 統合品質スコア: %.2f", result.OverallQuality))
+	parts = append(parts, fmt.Sprintf("🔒 信頼スコア: %.2f", result.TrustScore))
+
+	// 推奨アクション
+	if len(result.RecommendedActions) > 0 {
+		parts = append(parts, "📋 推奨アクション:")
+		for _, action := range result.RecommendedActions {
+			parts = append(parts, fmt.Sprintf("  • %s", action))
+		}
+	}
+
+	return strings.Join(parts, "\n")
+}
+
+// collectBasicProjectInfo は基本的なプロジェクト情報を収集
+func (ism *interactiveSessionManager) collectBasicProjectInfo() string {
+	var info []string
+
+	if ism.bashTool != nil {
+		// Git状態
+		if result, err := ism.bashTool.Execute("git status --porcelain", "Git status", 3000); err == nil && !result.IsError {
+			fileCount := len(strings.Split(strings.TrimSpace(result.Content), "\n"))
+			if strings.TrimSpace(result.Content) != "" {
+				info = append(info, fmt.Sprintf("📊 Git状態: %d個のファイルに変更", fileCount))
+			} else {
+				info = append(info, "📊 Git状態: クリーン")
+			}
+		}
+
+		// プロジェクト規模
+		if result, err := ism.bashTool.Execute("find . -name '*.go' -type f | wc -l", "Go files count", 3000); err == nil && !result.IsError {
+			info = append(info, fmt.Sprintf("🏗️ プロジェクト規模: %s個のGoファイル", strings.TrimSpace(result.Content)))
+		}
+	}
+
+	if len(info) > 0 {
+		return strings.Join(info, "\n")
+	}
+
+	return ""
+}
+
+// performBasicAnalysis はフォールバック用の基本分析
+func (ism *interactiveSessionManager) performBasicAnalysis(query string) string {
+	return fmt.Sprintf("🔍 基本分析: %s\n（科学的認知分析システムが利用できません）\n💡 基本的なプロジェクト状態を確認しました", query)
+}
+
+// performUnifiedAnalysis は既存のUnifiedAnalyzerを活用した統合分析を実行
+func (ism *interactiveSessionManager) performUnifiedAnalysis(query string) string {
+	if ism.config == nil {
+		return ""
+	}
+
+	unifiedAnalyzer := ism.sharedUnifiedAnalyzer()
+	if unifiedAnalyzer == nil {
+		return ""
+	}
+	// /workspace で切り替えたモジュール毎に結果をキャッシュするため絶対パスで分析する
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// HEAD と未コミットの変更が前回と同じなら、分析の空きを待たずにキャッシュから答える
+	if cached := unifiedAnalyzer.CachedProject(ctx, projectPath); cached != nil {
+		return ism.formatProjectAnalysis(cached)
+	}
+
+	ctx, release, err := ism.acquireAnalysis(ctx)
+	if err != nil {
+		return ""
+	}
+	defer release()
+
+	// プロジェクト分析を実行
+	projectAnalysis, err := unifiedAnalyzer.AnalyzeProject(ctx, projectPath)
+	if err != nil {
+		return fmt.Sprintf("🔬 統合分析エラー: %v", err)
+	}
+
+	// 分析結果をフォーマット
+	return ism.formatProjectAnalysis(projectAnalysis)
+}
+
+// sharedUnifiedAnalyzer は分析結果のキャッシュを引き継ぐため、UnifiedAnalyzerを一度だけ作成して使い回す
+// ファイル変更の通知（ツール・提案の適用・リファクタリング）でキャッシュを無効化する
+func (ism *interactiveSessionManager) sharedUnifiedAnalyzer() *analysis.UnifiedAnalyzer {
+	llmClient, ok := ism.llmProvider.(ai.LLMClient)
+	if !ok {
+		return nil
+	}
+	ism.unifiedAnalyzerOnce.Do(func() {
+		unifiedAnalyzer := analysis.NewUnifiedAnalyzer(ism.config, llmClient)
+		events.Default().Subscribe(events.EditApplied, func(events.Event) {
+			unifiedAnalyzer.InvalidateProjects()
+		})
+		ism.unifiedAnalyzer = unifiedAnalyzer
+	})
+	return ism.unifiedAnalyzer
+}
+
+// formatProjectAnalysis はプロジェクト分析結果をフォーマット
+func (ism *interactiveSessionManager) formatProjectAnalysis(analysis *analysis.ProjectAnalysis) string {
+	if analysis == nil {
+		return ""
+	}
+
+	var result []string
+
+	// プロジェクト基本情報
+	result = append(result, fmt.Sprintf("📋 **プロジェクト概要**"))
+	result = append(result, fmt.Sprintf("  • 名前: %s", analysis.ProjectName))
+	result = append(result, fmt.Sprintf("  • 言語: %s", analysis.Language))
+	result = append(result, fmt.Sprintf("  • フレームワーク: %s", analysis.Framework))
+
+	// ファイル構造
+	if analysis.FileStructure != nil {
+		result = append(result, fmt.Sprintf("🏗️ **プロジェクト構造**"))
+		result = append(result, fmt.Sprintf("  • 総ファイル数: %d", analysis.FileStructure.TotalFiles))
+		result = append(result, fmt.Sprintf("  • 総行数: %s", ism.formatNumber(analysis.FileStructure.TotalLines)))
+
+		if len(analysis.FileStructure.Languages) > 0 {
+			result = append(result, "  • 言語別ファイル数:")
+			for lang, count := range analysis.FileStructure.Languages {
+				result = append(result, fmt.Sprintf("    - %s: %d ファイル", lang, count))
+			}
+		}
+	}
+
+	// 品質メトリクス
+	if analysis.QualityMetrics != nil {
+		result = append(result, fmt.Sprintf("📊 **コード品質**"))
+		// TestCoverage・Maintainability は0-100のスケール
+		coverageLabel := fmt.Sprintf("%.1f%%", analysis.QualityMetrics.TestCoverage)
+		if source := analysis.QualityMetrics.CoverageSource; source != "" {
+			coverageLabel += fmt.Sprintf(" (%s)", source)
+		}
+		result = append(result, fmt.Sprintf("  • テストカバレッジ: %s", coverageLabel))
+		result = append(result, fmt.Sprintf("  • 保守性: %.1f/100", analysis.QualityMetrics.Maintainability))
+		result = append(result, fmt.Sprintf("  • 複雑度: %.1f", analysis.QualityMetrics.CodeComplexity))
+		for _, hotspot := range analysis.QualityMetrics.Hotspots {
+			result = append(result, fmt.Sprintf("    - %s (%s:%d): %d", hotspot.Name, hotspot.File, hotspot.Line, hotspot.Complexity))
+		}
+		if analysis.QualityMetrics.IssueCount > 0 {
+			result = append(result, fmt.Sprintf("  • ⚠️ 検出された問題: %d件", analysis.QualityMetrics.IssueCount))
+		}
+	}
+
+	// 依存関係
+	if len(analysis.Dependencies) > 0 {
+		result = append(result, fmt.Sprintf("📦 **依存関係 (%d件)**", len(analysis.Dependencies)))
+		outdatedCount := 0
+		vulnerableCount := 0
+		for _, dep := range analysis.Dependencies {
+			if dep.Outdated {
+				outdatedCount++
+			}
+			if len(dep.Vulnerabilities) > 0 {
+				vulnerableCount++
+			}
+		}
+		if outdatedCount > 0 {
+			result = append(result, fmt.Sprintf("  • ⚠️ 古いバージョン: %d件", outdatedCount))
+		}
+		if vulnerableCount > 0 {
+			result = append(result, fmt.Sprintf("  • 🔒 セキュリティ問題: %d件", vulnerableCount))
+		}
+	}
+
+	// セキュリティ問題
+	if len(analysis.SecurityIssues) > 0 {
+		result = append(result, fmt.Sprintf("🔒 **セキュリティ問題 (%d件)**", len(analysis.SecurityIssues)))
+		for _, issue := range analysis.SecurityIssues {
+			result = append(result, fmt.Sprintf("  • %s: %s", issue.Type, issue.Description))
+		}
+	}
+
+	// 改善提案
+	if len(analysis.Recommendations) > 0 {
+		result = append(result, fmt.Sprintf("💡 **改善提案 (%d件)**", len(analysis.Recommendations)))
+		for i, rec := range analysis.Recommendations {
+			if i < 5 { // 最初の5件のみ表示
+				result = append(result, fmt.Sprintf("  • %s: %s", rec.Type, rec.Description))
+			}
+		}
+		if len(analysis.Recommendations) > 5 {
+			result = append(result, fmt.Sprintf("  • ... および%d件の追加提案", len(analysis.Recommendations)-5))
+		}
+	}
+
+	return strings.Join(result, "\n")
+}
+
+// formatNumber は数値を読みやすい形式でフォーマット
+func (ism *interactiveSessionManager) formatNumber(num int) string {
+	if num < 1000 {
+		return fmt.Sprintf("%d", num)
+	} else if num < 1000000 {
+		return fmt.Sprintf("%.1fk", float64(num)/1000)
+	} else {
+		return fmt.Sprintf("%.1fM", float64(num)/1000000)
+	}
+}
+
+// performDetailedGitAnalysis は詳細なGit分析を実行
+func (ism *interactiveSessionManager) performDetailedGitAnalysis() string {
+	if ism.bashTool == nil {
+		return ""
+	}
+
+	var gitResults []string
+
+	// Git状態の詳細分析
+	if result, err := ism.bashTool.Execute("git status --porcelain -b", "Git detailed status", 5000); err == nil && !result.IsError {
+		lines := strings.Split(strings.TrimSpace(result.Content), "\n")
+		if len(lines) > 0 && strings.HasPrefix(lines[0], "##") {
+			branchInfo := strings.TrimPrefix(lines[0], "## ")
+			gitResults = append(gitResults, fmt.Sprintf("🌿 **ブランチ**: %s", branchInfo))
+		}
+
+		modifiedCount := 0
+		addedCount := 0
+		deletedCount := 0
+		for _, line := range lines[1:] {
+			if len(line) >= 2 {
+				status := line[:2]
+				if strings.Contains(status, "M") {
+					modifiedCount++
+				}
+				if strings.Contains(status, "A") {
+					addedCount++
+				}
+				if strings.Contains(status, "D") {
+					deletedCount++
+				}
+			}
+		}
+
+		if modifiedCount+addedCount+deletedCount > 0 {
+			gitResults = append(gitResults, fmt.Sprintf("📝 **変更統計**: 変更 %d, 追加 %d, 削除 %d", modifiedCount, addedCount, deletedCount))
+		}
+	}
+
+	// コミット履歴分析
+	if result, err := ism.bashTool.Execute("git log --oneline -10 --no-merges", "Recent commits", 5000); err == nil && !result.IsError {
+		commitLines := strings.Split(strings.TrimSpace(result.Content), "\n")
+		if len(commitLines) > 0 {
+			gitResults = append(gitResults, fmt.Sprintf("📋 **最近のコミット** (%d件):", len(commitLines)))
+			for i, commit := range commitLines {
+				if i < 3 { // 最新3件のみ表示
+					gitResults = append(gitResults, fmt.Sprintf("  • %s", commit))
+				}
+			}
+		}
+	}
+
+	// ブランチ分析
+	if result, err := ism.bashTool.Execute("git branch -a", "Git branches", 3000); err == nil && !result.IsError {
+		branches := strings.Split(strings.TrimSpace(result.Content), "\n")
+		localBranches := 0
+		remoteBranches := 0
+		for _, branch := range branches {
+			branch = strings.TrimSpace(branch)
+			if strings.HasPrefix(branch, "remotes/") {
+				remoteBranches++
+			} else if branch != "" {
+				localBranches++
+			}
+		}
+		gitResults = append(gitResults, fmt.Sprintf("🌳 **ブランチ**: ローカル %d, リモート %d", localBranches, remoteBranches))
+	}
+
+	if len(gitResults) > 0 {
+		return strings.Join(gitResults, "\n")
+	}
+
+	return ""
+}
+
+// shouldForceAnalysis は分析を強制実行すべきかどうかを判定
+func (ism *interactiveSessionManager) shouldForceAnalysis(input, intent string) bool {
+	lowerInput := strings.ToLower(input)
+	lowerIntent := strings.ToLower(intent)
+
+	// 明確な分析要求キーワード
+	forceAnalysisKeywords := []string{
+		"分析", "analyze", "問題点", "問題", "状況", "状態",
+		"リポジトリ", "repository", "プロジェクト", "project",
+		"現状", "current", "詳細", "detail", "調査", "investigate",
+		"確認", "check", "診断", "diagnose", "評価", "evaluate",
+	}
+
+	for _, keyword := range forceAnalysisKeywords {
+		if strings.Contains(lowerInput, keyword) || strings.Contains(lowerIntent, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// performDetailedCognitiveAnalysis は詳細な認知分析を実行
+func (ism *interactiveSessionManager) performDetailedCognitiveAnalysis(ctx context.Context, query string) string {
+	if ism.cognitiveAnalyzer == nil {
+		return ""
+	}
+
+	ctx, release, err := ism.acquireAnalysis(ctx)
+	if err != nil {
+		return ""
+	}
+	defer release()
+
+	// 深度の高い分析リクエストを作成
+	request := &analysis.AnalysisRequest{
+		UserInput:       query,
+		Response:        "",
+		AnalysisDepth:   "deep",
+		RequiredMetrics: []string{"semantic_entropy", "confidence", "reasoning", "creativity"},
+		Context: map[string]interface{}{
+			"analysis_type": "detailed_cognitive",
+			"user_query":    query,
+			"timestamp":     time.Now().Format(time.RFC3339),
+		},
+	}
+
+	result, err := ism.cognitiveAnalyzer.AnalyzeCognitive(ctx, request)
+	if err != nil {
+		return fmt.Sprintf("⚠️ 認知分析エラー: %v", err)
+	}
+
+	// 詳細な結果をフォーマット
+	var details []string
+	details = append(details, "🧠 **詳細認知分析結果**")
+	details = append(details, fmt.Sprintf("  • **信頼度スコア**: %.2f/1.0 %s",
+		result.TrustScore, ism.getConfidenceEmoji(result.TrustScore)))
+	details = append(details, fmt.Sprintf("  • **全体品質**: %.2f/1.0", result.OverallQuality))
+
+	if result.Confidence != nil {
+		details = append(details, fmt.Sprintf("  • **信頼度分析**: %.3f (セマンティックエントロピー: %.3f)",
+			result.Confidence.OverallConfidence, result.Confidence.SemanticEntropy))
+	}
+
+	if result.ReasoningDepth != nil {
+		details = append(details, fmt.Sprintf("  • **論理的推論**: 深度%d (論理構造評価: %.1f)",
+			result.ReasoningDepth.OverallDepth, result.ReasoningDepth.LogicalCoherence))
+	}
+
+	if result.Creativity != nil {
+		details = append(details, fmt.Sprintf("  • **創造性評価**: %.2f (流暢性: %.1f, 独創性: %.1f)",
+			result.Creativity.OverallScore, result.Creativity.Fluency, result.Creativity.Originality))
+	}
+
+	if len(result.RecommendedActions) > 0 {
+		details = append(details, "  • **推奨アクション**:")
+		for i, action := range result.RecommendedActions {
+			if i < 3 { // 最大3つまで表示
+				details = append(details, fmt.Sprintf("    - %s", action))
+			}
+		}
+	}
+
+	return strings.Join(details, "\n")
+}
+
+// performProjectStructureAnalysisImpl はプロジェクト構造の詳細分析を実行
+func (ism *interactiveSessionManager) performProjectStructureAnalysisImpl(projectPath string) string {
+	var details []string
+	details = append(details, "🏗️ **プロジェクト構造分析**")
+
+	// ファイル数とディレクトリ構造の分析
+	fileCount := 0
+	dirCount := 0
+	var largeFiles []string
+	var languageStats = make(map[string]int)
+
+	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		// 隠しディレクトリ(.git等)をスキップ
+		if strings.Contains(path, "/.") {
+			return nil
+		}
+
+		if info.IsDir() {
+			dirCount++
+		} else {
+			fileCount++
+
+			// 大きなファイルをチェック
+			if info.Size() > 1024*1024 { // 1MB以上
+				largeFiles = append(largeFiles, fmt.Sprintf("%s (%s)",
+					path, ism.formatFileSize(info.Size())))
+			}
+
+			// 言語別統計
+			ext := filepath.Ext(path)
+			if lang := ism.getLanguageFromExtension(ext); lang != "" {
+				languageStats[lang]++
+			}
+		}
+
+		return nil
+	})
+
+	details = append(details, fmt.Sprintf("  • **規模**: %d ファイル, %d ディレクトリ", fileCount, dirCount))
+
+	// 言語別統計
+	if len(languageStats) > 0 {
+		var langDetails []string
+		for lang, count := range languageStats {
+			langDetails = append(langDetails, fmt.Sprintf("%s(%d)", lang, count))
+		}
+		if len(langDetails) <= 5 {
+			details = append(details, fmt.Sprintf("  • **言語分布**: %s", strings.Join(langDetails, ", ")))
+		}
+	}
+
+	// 大きなファイルの警告
+	if len(largeFiles) > 0 {
+		details = append(details, "  • **大きなファイル**:")
+		for i, file := range largeFiles {
+			if i < 3 { // 最大3つまで表示
+				details = append(details, fmt.Sprintf("    - ⚠️ %s", file))
+			}
+		}
+	}
+
+	return strings.Join(details, "\n")
+}
+
+// performSecurityAnalysisImpl はセキュリティ分析を実行
+func (ism *interactiveSessionManager) performSecurityAnalysisImpl() string {
+	var details []string
+	details = append(details, "🔒 **セキュリティ分析**")
+
+	projectPath, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+
+	var sensitiveFiles []string
+
+	// セキュリティ関連のファイルパターンをチェック
+	securityPatterns := map[string]string{
+		"password": "パスワード関連",
+		"secret":   "シークレット情報",
+		"api_key":  "APIキー",
+		"token":    "トークン",
+		"private":  "プライベート情報",
+	}
+
+	filepath.Walk(projectPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() {
+			return nil
+		}
+
+		// 隠しファイル・ディレクトリをスキップ
+		if strings.Contains(path, "/.") {
+			return nil
+		}
+
+		filename := strings.ToLower(filepath.Base(path))
+		for pattern, description := range securityPatterns {
+			if strings.Contains(filename, pattern) {
+				sensitiveFiles = append(sensitiveFiles, fmt.Sprintf("%s (%s)", path, description))
+			}
+		}
+
+		// 設定ファイルで機密情報の可能性があるものをチェック
+		if strings.HasSuffix(filename, ".env") ||
+			strings.HasSuffix(filename, ".config") ||
+			strings.HasSuffix(filename, ".yaml") ||
+			strings.HasSuffix(filename, ".yml") {
+			sensitiveFiles = append(sensitiveFiles, fmt.Sprintf("%s (設定ファイル)", path))
+		}
+
+		return nil
+	})
+
+	// 結果の構築
+	if len(sensitiveFiles) > 0 {
+		details = append(details, fmt.Sprintf("  • **注意を要するファイル**: %d件", len(sensitiveFiles)))
+		for i, file := range sensitiveFiles {
+			if i < 5 { // 最大5つまで表示
+				details = append(details, fmt.Sprintf("    - ⚠️ %s", file))
+			}
+		}
+	} else {
+		details = append(details, "  • ✅ **機密性の懸念**: 明らかなセキュリティリスクは検出されませんでした")
+	}
+
+	// Git関連のセキュリティチェック
+	if _, err := os.Stat(filepath.Join(projectPath, ".git")); err == nil {
+		gitignoreExists := false
+		if _, err := os.Stat(filepath.Join(projectPath, ".gitignore")); err == nil {
+			gitignoreExists = true
+		}
+
+		if gitignoreExists {
+			details = append(details, "  • ✅ **.gitignore**: 存在します")
+		} else {
+			details = append(details, "  • ⚠️ **.gitignore**: 存在しません（推奨）")
+		}
+	}
+
+	return strings.Join(details, "\n")
+}
+
+// formatComprehensiveAnalysisResponse は包括的分析レスポンスをフォーマット
+func (ism *interactiveSessionManager) formatComprehensiveAnalysisResponse(query string, components []string) string {
+	if len(components) == 0 {
+		return fmt.Sprintf("🔍 **分析完了**\n\n**クエリ**: %s\n\n分析を実行しましたが、詳細な結果を取得できませんでした。", query)
+	}
+
+	var result strings.Builder
+	result.WriteString(fmt.Sprintf("🔍 **高度な分析結果**\n\n"))
+	result.WriteString(fmt.Sprintf("**クエリ**: %s\n\n", query))
+
+	// 分析コンポーネントを結合
+	result.WriteString(strings.Join(components, "\n\n"))
+
+	// 総合的なアクション提案を追加
+	result.WriteString("\n\n💡 **推奨アクション**")
+	result.WriteString("\n  • 分析結果を基にした具体的な改善を検討してください")
+	result.WriteString("\n  • コード品質・構造の最適化を進めてください")
+	result.WriteString("\n  • セキュリティ面での懸念があれば優先的に対応してください")
+	result.WriteString("\n  • プロジェクトの健全性向上を継続的に行ってください")
+
+	return result.String()
+}
+
+// 補助メソッド
+func (ism *interactiveSessionManager) getConfidenceEmoji(confidence float64) string {
+	if confidence >= 0.8 {
+		return "🟢"
+	} else if confidence >= 0.6 {
+		return "🟡"
+	} else {
+		return "🔴"
+	}
+}
+
+func (ism *interactiveSessionManager) getUncertaintyLevel(entropy float64) string {
+	if entropy < 0.3 {
+		return "低"
+	} else if entropy < 0.7 {
+		return "中"
+	} else {
+		return "高"
+	}
+}
+
+func (ism *interactiveSessionManager) formatFileSize(size int64) string {
+	if size < 1024 {
+		return fmt.Sprintf("%d B", size)
+	} else if size < 1024*1024 {
+		return fmt.Sprintf("%.1f KB", float64(size)/1024)
+	} else {
+		return fmt.Sprintf("%.1f MB", float64(size)/(1024*1024))
+	}
+}
+
+func (ism *interactiveSessionManager) getLanguageFromExtension(ext string) string {
+	languages := map[string]string{
+		".go":    "Go",
+		".js":    "JavaScript",
+		".ts":    "TypeScript",
+		".py":    "Python",
+		".java":  "Java",
+		".cpp":   "C++",
+		".c":     "C",
+		".rs":    "Rust",
+		".php":   "PHP",
+		".rb":    "Ruby",
+		".swift": "Swift",
+		".kt":    "Kotlin",
+		".cs":    "C#",
+		".html":  "HTML",
+		".css":   "CSS",
+		".sql":   "SQL",
+		".sh":    "Shell",
+		".yml":   "YAML",
+		".yaml":  "YAML",
+		".json":  "JSON",
+		".xml":   "XML",
+		".md":    "Markdown",
+	}
+
+	return languages[strings.ToLower(ext)]
+}
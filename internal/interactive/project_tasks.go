@@ -0,0 +1,122 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// RunProjectTask はプロジェクトのビルド・テスト・リントを実行し、
+// 失敗内容を修正用のコンテキストとしてセッションに追加
+func (ism *interactiveSessionManager) RunProjectTask(ctx context.Context, sessionID string, kind tasks.Kind) (*tasks.Result, error) {
+	session, err := ism.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+
+	runner, err := tasks.NewRunner(".", ism.execBackend)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := runner.Run(ctx, kind)
+	if err != nil {
+		return nil, err
+	}
+	auditTaskResult(logger.WithAuditSession(ctx, sessionID), result)
+	ism.recordTask(sessionID, result)
+
+	summary := tasks.FormatFailures(result)
+
+	ism.mu.Lock()
+	session.LastCommandOutput = result.Output
+	if session.SessionMetadata == nil {
+		session.SessionMetadata = make(map[string]string)
+	}
+	session.SessionMetadata["last_task"] = string(kind)
+	session.SessionMetadata["last_task_success"] = fmt.Sprintf("%t", result.Success)
+	session.SessionMetadata["last_task_failures"] = fmt.Sprintf("%d", len(result.Failures))
+	session.LastActivity = time.Now()
+	ism.mu.Unlock()
+
+	// 失敗時のみ次の応答で修正できるようにコンテキストへ追加
+	if !result.Success {
+		ism.addToSmartContext(sessionID, summary, "task_failures")
+	}
+
+	return result, nil
+}
+
+// シェルコマンド出力をコンテキストに追加する際の上限（末尾を残す）
+const shellContextMaxBytes = 8 * 1024
+
+// デフォルトのシェルコマンドタイムアウト（設定がない場合）
+const defaultShellTimeout = 2 * time.Minute
+
+// RunShellCommand はユーザーが直接入力したコマンドをBashToolで実行し（セキュリティ制約を適用）、
+// 出力を次の応答のコンテキストとしてセッションに記録
+func (ism *interactiveSessionManager) RunShellCommand(ctx context.Context, sessionID string, command string) (*tools.ToolExecutionResult, error) {
+	session, err := ism.GetSession(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	if ism.bashTool == nil {
+		return nil, fmt.Errorf("BashToolが初期化されていません")
+	}
+
+	timeout := defaultShellTimeout
+	if ism.config != nil && ism.config.CommandTimeout > 0 {
+		timeout = time.Duration(ism.config.CommandTimeout) * time.Second
+	}
+
+	ctx = logger.WithAuditSession(ctx, sessionID)
+	startTime := time.Now()
+	var result *tools.ToolExecutionResult
+	if ism.remote != nil {
+		result, err = ism.runRemoteShell(ctx, sessionID, command, timeout)
+	} else {
+		result, err = ism.bashTool.ExecuteContext(ctx, command, "User shell command", int(timeout.Milliseconds()))
+	}
+	if err != nil {
+		auditCommand(ctx, command, "", startTime, err)
+		ism.recordCommand(sessionID, command, result, err)
+		return result, fmt.Errorf("コマンド実行エラー: %w", err)
+	}
+	auditCommand(ctx, command, result.Content, startTime, nil)
+	ism.recordCommand(sessionID, command, result, nil)
+
+	ism.mu.Lock()
+	session.LastCommandOutput = result.Content
+	if session.SessionMetadata == nil {
+		session.SessionMetadata = make(map[string]string)
+	}
+	session.SessionMetadata["last_shell_command"] = command
+	session.SessionMetadata["last_shell_exit_code"] = fmt.Sprintf("%d", result.ExitCode)
+	session.LastActivity = time.Now()
+	ism.mu.Unlock()
+
+	ism.addToSmartContext(sessionID, formatShellContext(command, result), "shell_output")
+	return result, nil
+}
+
+// formatShellContext はコマンドと終了コード、出力の末尾をモデル向けにまとめる
+func formatShellContext(command string, result *tools.ToolExecutionResult) string {
+	output := result.Content
+	if len(output) > shellContextMaxBytes {
+		start := len(output) - shellContextMaxBytes
+		for start < len(output) && !utf8.RuneStart(output[start]) {
+			start++
+		}
+		output = "...(truncated)\n" + output[start:]
+	}
+	status := fmt.Sprintf("exit code %d", result.ExitCode)
+	if result.TimedOut {
+		status = "timed out"
+	}
+	return fmt.Sprintf("ユーザーが実行したコマンド: $ %s (%s)\n%s", command, status, output)
+}
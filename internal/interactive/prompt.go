@@ -0,0 +1,128 @@
+package interactive
+
+import (
+	"fmt"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// buildInteractivePrompt はClaude Code式統一プロンプトを構築
+func (ism *interactiveSessionManager) buildInteractivePrompt(session *InteractiveSession, input string, intent string) string {
+	// SmartContextManagerから最適化されたコンテキストを取得（70-95%圧縮効率）
+	optimizedContext := ism.getOptimizedContext(session.ID, input, 50)
+
+	// セッション履歴を取得して文脈を構築
+	contextHistory := ism.buildSessionContext(session)
+
+	// ベースプロンプトをテンプレートから構築 - 構造化応答を強制
+	data := ism.interactivePromptData(session, intent)
+	data.LastOutput = session.LastCommandOutput
+	data.Context = optimizedContext
+	data.History = contextHistory
+	data.Input = input
+	basePrompt := ism.renderInteractivePrompt(data)
+
+	// プロアクティブ拡張が利用可能な場合、プロンプトを拡張
+	if ism.proactiveExt != nil {
+		enhancedPrompt := ism.proactiveExt.EnhancePrompt(basePrompt, input)
+		return enhancedPrompt
+	}
+
+	return basePrompt
+}
+
+// interactivePromptData はセッションに依存するテンプレート変数（入力・コンテキスト以外）を構築
+func (ism *interactiveSessionManager) interactivePromptData(session *InteractiveSession, intent string) prompts.Data {
+	memory, _ := config.LoadProjectMemory("")
+	data := prompts.Data{
+		SessionType:  ism.sessionTypeKey(session.Type),
+		SessionLabel: ism.sessionTypeToString(session.Type),
+		Language:     string(i18n.Current()),
+		ModelFamily:  prompts.ModelFamily(ism.getConfiguredModel()),
+		Tools:        structuredResponseTools(ism.recallConfig().Enabled),
+		Memory:       memory,
+		Guides:       ism.guideData(),
+		CurrentFile:  session.CurrentFile,
+		Intent:       intent,
+	}
+	applyTemplateData(session.Template, &data)
+	data.Pinned = pinnedFileData(session.PinnedFiles)
+	data.PinnedContext = ism.pinnedContextData(session.ID)
+	return data
+}
+
+// renderInteractivePrompt はテンプレートを描画（上書きテンプレートが壊れている場合は組み込みで継続）
+func (ism *interactiveSessionManager) renderInteractivePrompt(data prompts.Data) string {
+	prompt, err := ism.promptRegistry.Render(prompts.TemplateInteractive, data)
+	if err != nil {
+		fmt.Printf("Warning: %s\n", i18n.T("error.prompt_template", err))
+		prompt, _ = prompts.NewRegistry("").Render(prompts.TemplateInteractive, data)
+	}
+	return prompt
+}
+
+// structuredResponseTools は応答プロンプトに列挙する構造化タグ（説明は現在の言語）
+// 実行結果の記憶が無効なら <RECALL> は案内しない
+func structuredResponseTools(recall bool) []prompts.Tool {
+	definitions := []struct{ name, usage string }{
+		{"analysis", "<ANALYSIS>query</ANALYSIS>"},
+		{"command", "<COMMAND>command</COMMAND>"},
+		{"filecreate", "<FILECREATE>path|content</FILECREATE>"},
+		{"patch", "<PATCH>unified diff (--- a/path, +++ b/path, @@ hunks)</PATCH>"},
+		{"filemove", "<FILEMOVE>source|destination</FILEMOVE>"},
+		{"filedelete", "<FILEDELETE>path</FILEDELETE>"},
+		{"tree", "<TREE>path [depth=N] [max=N] [hidden]</TREE>"},
+		{"fileread", "<FILEREAD>filename</FILEREAD>"},
+		{"suggestion", "<SUGGESTION>action</SUGGESTION>"},
+		{"diagram", "<DIAGRAM>mermaid|dot [calls] [depth=N]</DIAGRAM>"},
+		{"jobstart", "<JOBSTART>command</JOBSTART>"},
+		{"joboutput", "<JOBOUTPUT>job id</JOBOUTPUT>"},
+		{"jobkill", "<JOBKILL>job id</JOBKILL>"},
+		{"pin", "<PIN>decision, constraint or file path</PIN>"},
+		{"diagnostics", "<DIAGNOSTICS>[extra tools to check, e.g. cargo rustc]</DIAGNOSTICS>"},
+		{"logtail", "<LOGTAIL>log file | unit:name [seconds=N] [lines=N] [max=N] [grep=regex]</LOGTAIL>"},
+		{"recall", "<RECALL>what to look up in earlier command output | #id | recent</RECALL>"},
+	}
+
+	tools := make([]prompts.Tool, 0, len(definitions))
+	for _, def := range definitions {
+		if def.name == "recall" && !recall {
+			continue
+		}
+		tools = append(tools, prompts.Tool{
+			Name:        def.name,
+			Usage:       def.usage,
+			Description: i18n.T("tool." + def.name + ".description"),
+			Purpose:     i18n.T("tool." + def.name + ".purpose"),
+		})
+	}
+	return tools
+}
+
+// buildSessionContext はセッション履歴から文脈を構築
+func (ism *interactiveSessionManager) buildSessionContext(session *InteractiveSession) string {
+	if session.Metrics.TotalInteractions == 0 {
+		return "新しいセッションです。"
+	}
+
+	context := fmt.Sprintf(`
+- 総インタラクション数: %d
+- 受け入れられた提案: %d / %d
+- 変更されたファイル: %d
+- 変更された行数: %d`,
+		session.Metrics.TotalInteractions,
+		session.Metrics.SuggestionsAccepted,
+		session.Metrics.CodeSuggestionsGiven,
+		session.Metrics.FilesModified,
+		session.Metrics.LinesChanged,
+	)
+
+	// 最後の作業内容があれば追加
+	if session.LastCommandOutput != "" {
+		context += "\n- 最後の実行結果: " + session.LastCommandOutput[:min(200, len(session.LastCommandOutput))] + "..."
+	}
+
+	return context
+}
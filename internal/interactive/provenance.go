@@ -0,0 +1,309 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+const (
+	maxProvenanceRecords = 20 // セッション毎に保持する提案の根拠の件数
+	provenancePreviewLen = 80 // コンテキストの内容の表示文字数
+)
+
+// SuggestionProvenance は提案の根拠（/why）
+// どのコンテキスト・ファイル・分析結果をもとに作られ、信頼度がどう決まったかを記録する
+type SuggestionProvenance struct {
+	SuggestionID string                `json:"suggestion_id"`
+	Input        string                `json:"input"`
+	FilePath     string                `json:"file_path,omitempty"`
+	Model        string                `json:"model,omitempty"`
+	Context      []ProvenanceContext   `json:"context"`
+	Files        []string              `json:"files"`
+	Analysis     map[string]string     `json:"analysis,omitempty"` // 意図・推論・影響範囲・プロジェクト分析の結果
+	Confidence   ConfidenceExplanation `json:"confidence"`
+	CreatedAt    time.Time             `json:"created_at"`
+	AppliedAt    *time.Time            `json:"applied_at,omitempty"`
+}
+
+// ProvenanceContext はプロンプトに使ったコンテキスト項目
+type ProvenanceContext struct {
+	ID         string  `json:"id"`
+	Type       string  `json:"type"`             // immediate, short, medium, long
+	Source     string  `json:"source,omitempty"` // content_type（user_input, tool_result, file_content 等）
+	File       string  `json:"file,omitempty"`
+	Relevance  float64 `json:"relevance"`
+	Importance float64 `json:"importance"`
+	Preview    string  `json:"preview"`
+}
+
+// ConfidenceExplanation は提案の信頼度の内訳（ベース値 + 各要因の増減）
+type ConfidenceExplanation struct {
+	Score      float64            `json:"score"`
+	Base       float64            `json:"base"`
+	BaseReason string             `json:"base_reason"`
+	Factors    []ConfidenceFactor `json:"factors,omitempty"`
+}
+
+// ConfidenceFactor は信頼度を増減させた要因
+type ConfidenceFactor struct {
+	Name   string  `json:"name"`
+	Delta  float64 `json:"delta"`
+	Reason string  `json:"reason"`
+}
+
+// add は要因を加え、信頼度を 0.0-1.0 に収める
+func (e *ConfidenceExplanation) add(name string, delta float64, reason string) {
+	e.Factors = append(e.Factors, ConfidenceFactor{Name: name, Delta: delta, Reason: reason})
+	e.Score += delta
+	if e.Score > 1.0 {
+		e.Score = 1.0
+	}
+	if e.Score < 0.0 {
+		e.Score = 0.0
+	}
+}
+
+// fixedConfidence は応答から抽出した提案の既定の信頼度（要因なし）
+func fixedConfidence(score float64, reason string) ConfidenceExplanation {
+	return ConfidenceExplanation{Score: score, Base: score, BaseReason: reason}
+}
+
+// SuggestionProvenance は提案の根拠を返す（suggestionID が空なら最新の提案）
+func (ism *interactiveSessionManager) SuggestionProvenance(sessionID, suggestionID string) (*SuggestionProvenance, error) {
+	ism.provenanceMu.Lock()
+	defer ism.provenanceMu.Unlock()
+
+	records := ism.provenance[sessionID]
+	if len(records) == 0 {
+		return nil, fmt.Errorf("%s", i18n.T("why.none"))
+	}
+	if suggestionID == "" {
+		record := *records[len(records)-1]
+		return &record, nil
+	}
+	for i := len(records) - 1; i >= 0; i-- {
+		if records[i].SuggestionID == suggestionID || strings.HasSuffix(records[i].SuggestionID, suggestionID) {
+			record := *records[i]
+			return &record, nil
+		}
+	}
+	return nil, fmt.Errorf("%s", i18n.T("why.not_found", suggestionID))
+}
+
+// ProvenanceHistory はセッションの提案の根拠を古い順に返す
+func (ism *interactiveSessionManager) ProvenanceHistory(sessionID string) []*SuggestionProvenance {
+	ism.provenanceMu.Lock()
+	defer ism.provenanceMu.Unlock()
+
+	history := make([]*SuggestionProvenance, 0, len(ism.provenance[sessionID]))
+	for _, record := range ism.provenance[sessionID] {
+		copied := *record
+		history = append(history, &copied)
+	}
+	return history
+}
+
+// noteRetrievedContext はターン中にプロンプトのために取得したコンテキストを記録する（次の提案の根拠になる）
+func (ism *interactiveSessionManager) noteRetrievedContext(sessionID string, items []*contextmanager.ContextItem) {
+	ism.provenanceMu.Lock()
+	defer ism.provenanceMu.Unlock()
+
+	if ism.retrievedContext == nil {
+		ism.retrievedContext = make(map[string][]ProvenanceContext)
+	}
+	ism.retrievedContext[sessionID] = provenanceContext(items)
+}
+
+// recordProvenance は提案の根拠を記録する
+// retrieved が nil ならターン中に取得したコンテキスト（noteRetrievedContext）を使う
+func (ism *interactiveSessionManager) recordProvenance(
+	ctx context.Context,
+	session *InteractiveSession,
+	suggestion *CodeSuggestion,
+	input string,
+	retrieved []*contextmanager.ContextItem,
+	confidence ConfidenceExplanation,
+) {
+	if session == nil || suggestion == nil {
+		return
+	}
+	analysis := ism.provenanceAnalysis(ctx, session, suggestion)
+
+	ism.provenanceMu.Lock()
+	defer ism.provenanceMu.Unlock()
+
+	items := provenanceContext(retrieved)
+	if retrieved == nil {
+		items = ism.retrievedContext[session.ID]
+	}
+	delete(ism.retrievedContext, session.ID)
+
+	record := &SuggestionProvenance{
+		SuggestionID: suggestion.ID,
+		Input:        input,
+		FilePath:     suggestion.FilePath,
+		Model:        suggestion.Metadata["model"],
+		Context:      items,
+		Files:        provenanceFiles(session, suggestion, items),
+		Analysis:     analysis,
+		Confidence:   confidence,
+		CreatedAt:    time.Now(),
+	}
+	if record.Model == "" {
+		record.Model = ism.getConfiguredModel()
+	}
+
+	if ism.provenance == nil {
+		ism.provenance = make(map[string][]*SuggestionProvenance)
+	}
+	records := append(ism.provenance[session.ID], record)
+	if len(records) > maxProvenanceRecords {
+		records = records[len(records)-maxProvenanceRecords:]
+	}
+	ism.provenance[session.ID] = records
+}
+
+// markProvenanceApplied は提案の適用時刻を記録する
+func (ism *interactiveSessionManager) markProvenanceApplied(sessionID, suggestionID string) {
+	ism.provenanceMu.Lock()
+	defer ism.provenanceMu.Unlock()
+
+	for _, record := range ism.provenance[sessionID] {
+		if record.SuggestionID == suggestionID {
+			now := time.Now()
+			record.AppliedAt = &now
+			return
+		}
+	}
+}
+
+// provenanceAnalysis は提案に使われた分析結果（意図・推論・影響範囲・キャッシュ済みのプロジェクト分析）を集める
+func (ism *interactiveSessionManager) provenanceAnalysis(ctx context.Context, session *InteractiveSession, suggestion *CodeSuggestion) map[string]string {
+	analysis := make(map[string]string)
+	if session.UserIntent != "" {
+		analysis["intent"] = session.UserIntent
+	}
+	for key, value := range session.SessionMetadata {
+		if strings.HasPrefix(key, "reasoning_") && value != "" {
+			analysis[key] = value
+		}
+	}
+	if blastRadius := suggestion.Metadata["blast_radius"]; blastRadius != "" {
+		analysis["blast_radius"] = blastRadius
+	}
+	if approval := suggestion.Metadata["approval"]; approval != "" {
+		analysis["approval"] = approval
+	}
+	if unifiedAnalyzer := ism.sharedUnifiedAnalyzer(); unifiedAnalyzer != nil {
+		if projectPath, err := os.Getwd(); err == nil {
+			if project := unifiedAnalyzer.CachedProject(ctx, projectPath); project != nil {
+				analysis["project"] = strings.TrimSpace(fmt.Sprintf("%s %s (%s)", project.Language, project.Framework, project.AnalyzedAt.Format("15:04:05")))
+			}
+		}
+	}
+	if len(analysis) == 0 {
+		return nil
+	}
+	return analysis
+}
+
+// provenanceContext はコンテキスト項目を根拠として記録する形に変換する
+func provenanceContext(items []*contextmanager.ContextItem) []ProvenanceContext {
+	result := make([]ProvenanceContext, 0, len(items))
+	for _, item := range items {
+		if item == nil {
+			continue
+		}
+		result = append(result, ProvenanceContext{
+			ID:         item.ID,
+			Type:       contextTierName(item.Type),
+			Source:     item.Metadata["content_type"],
+			File:       item.Metadata["file"],
+			Relevance:  item.Relevance,
+			Importance: item.Importance,
+			Preview:    contextPreview(item.Content, provenancePreviewLen),
+		})
+	}
+	return result
+}
+
+// provenanceFiles は提案に関わったファイル（対象・作業中・コンテキストに含まれたファイル）
+func provenanceFiles(session *InteractiveSession, suggestion *CodeSuggestion, items []ProvenanceContext) []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, file := range []string{suggestion.FilePath, session.CurrentFile} {
+		if file != "" && !seen[file] {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+	var fromContext []string
+	for _, item := range items {
+		if item.File != "" && !seen[item.File] {
+			seen[item.File] = true
+			fromContext = append(fromContext, item.File)
+		}
+	}
+	sort.Strings(fromContext)
+	return append(files, fromContext...)
+}
+
+// Format は /why で表示する根拠のレポート
+func (p *SuggestionProvenance) Format() string {
+	var b strings.Builder
+	b.WriteString(i18n.T("why.title", p.SuggestionID))
+	b.WriteString("\n")
+	if p.AppliedAt != nil {
+		b.WriteString("  " + i18n.T("why.applied", p.AppliedAt.Format("15:04:05")) + "\n")
+	} else {
+		b.WriteString("  " + i18n.T("why.not_applied") + "\n")
+	}
+	if p.Input != "" {
+		b.WriteString("  " + i18n.T("why.input", contextPreview(p.Input, provenancePreviewLen)) + "\n")
+	}
+	if p.Model != "" {
+		b.WriteString("  " + i18n.T("why.model", p.Model) + "\n")
+	}
+
+	b.WriteString("\n" + i18n.T("why.context", len(p.Context)) + "\n")
+	if len(p.Context) == 0 {
+		b.WriteString("  " + i18n.T("why.context_none") + "\n")
+	}
+	for _, item := range p.Context {
+		source := item.Type
+		if item.Source != "" {
+			source += "/" + item.Source
+		}
+		fmt.Fprintf(&b, "  • [%s rel %.2f imp %.2f] %s\n", source, item.Relevance, item.Importance, item.Preview)
+	}
+
+	if len(p.Files) > 0 {
+		b.WriteString("\n" + i18n.T("why.files", strings.Join(p.Files, ", ")) + "\n")
+	}
+
+	if len(p.Analysis) > 0 {
+		b.WriteString("\n" + i18n.T("why.analysis") + "\n")
+		keys := make([]string, 0, len(p.Analysis))
+		for key := range p.Analysis {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			fmt.Fprintf(&b, "  • %s: %s\n", key, p.Analysis[key])
+		}
+	}
+
+	b.WriteString("\n" + i18n.T("why.confidence", p.Confidence.Score) + "\n")
+	fmt.Fprintf(&b, "    %.2f  %s\n", p.Confidence.Base, p.Confidence.BaseReason)
+	for _, factor := range p.Confidence.Factors {
+		fmt.Fprintf(&b, "  %+.2f  %s\n", factor.Delta, factor.Reason)
+	}
+	return b.String()
+}
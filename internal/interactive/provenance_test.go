@@ -0,0 +1,98 @@
+package interactive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+)
+
+// TestExplainSuggestionConfidence は信頼度の内訳の合計が calculateSuggestionConfidence と一致することをテストする
+func TestExplainSuggestionConfidence(t *testing.T) {
+	manager := NewInteractiveSessionManager(contextmanager.NewSmartContextManager(), nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	session.Metrics.SuggestionsAccepted = 3
+	session.Metrics.SuggestionsRejected = 1
+
+	items := make([]*contextmanager.ContextItem, 6)
+	for i := range items {
+		items[i] = &contextmanager.ContextItem{ID: "ctx", Content: "func main() {}"}
+	}
+	request := &SuggestionRequest{Type: SuggestionTypeOptimization, UserDescription: "speed up the loop"}
+
+	explanation := manager.explainSuggestionConfidence(session, request, items)
+	var names []string
+	total := explanation.Base
+	for _, factor := range explanation.Factors {
+		names = append(names, factor.Name)
+		total += factor.Delta
+	}
+	if strings.Join(names, ",") != "context,type,history" {
+		t.Errorf("factors = %v", names)
+	}
+	if diff := total - explanation.Score; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("内訳の合計 %.2f とスコア %.2f が一致しない", total, explanation.Score)
+	}
+	if score := manager.calculateSuggestionConfidence(session, request, items); score != explanation.Score {
+		t.Errorf("calculateSuggestionConfidence = %.2f, want %.2f", score, explanation.Score)
+	}
+}
+
+// TestSuggestionProvenance は取得したコンテキストが提案の根拠として記録され、適用が反映されることをテストする
+func TestSuggestionProvenance(t *testing.T) {
+	manager := NewInteractiveSessionManager(contextmanager.NewSmartContextManager(), nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manager.SuggestionProvenance(session.ID, ""); err == nil {
+		t.Error("提案が無ければエラーのはず")
+	}
+
+	session.UserIntent = "fix the nil check"
+	session.CurrentFile = "main.go"
+	manager.noteRetrievedContext(session.ID, []*contextmanager.ContextItem{
+		{ID: "c1", Content: "package util\n\nfunc Helper() {}", Relevance: 0.9, Metadata: map[string]string{"file": "util/helper.go", "content_type": "file_content"}},
+	})
+	suggestion := &CodeSuggestion{ID: "llm_suggestion_1", FilePath: "main.go", Confidence: 0.85, Metadata: map[string]string{"blast_radius": "2 dependents"}}
+	manager.recordProvenance(context.Background(), session, suggestion, "fix the nil check in main.go", nil, fixedConfidence(0.85, "extracted"))
+
+	provenance, err := manager.SuggestionProvenance(session.ID, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(provenance.Context) != 1 || provenance.Context[0].Source != "file_content" || provenance.Context[0].Preview != "package util" {
+		t.Errorf("context = %+v", provenance.Context)
+	}
+	if strings.Join(provenance.Files, ",") != "main.go,util/helper.go" {
+		t.Errorf("files = %v", provenance.Files)
+	}
+	if provenance.Analysis["intent"] != "fix the nil check" || provenance.Analysis["blast_radius"] != "2 dependents" {
+		t.Errorf("analysis = %v", provenance.Analysis)
+	}
+	if provenance.Model != "test-model" || provenance.AppliedAt != nil {
+		t.Errorf("model = %s, applied = %v", provenance.Model, provenance.AppliedAt)
+	}
+
+	// 取得したコンテキストは1つの提案にだけ使われる
+	manager.recordProvenance(context.Background(), session, &CodeSuggestion{ID: "llm_suggestion_2"}, "again", nil, fixedConfidence(0.7, "extracted"))
+	if latest, _ := manager.SuggestionProvenance(session.ID, ""); latest.SuggestionID != "llm_suggestion_2" || len(latest.Context) != 0 {
+		t.Errorf("latest = %+v", latest)
+	}
+
+	manager.markProvenanceApplied(session.ID, "llm_suggestion_1")
+	applied, err := manager.SuggestionProvenance(session.ID, "suggestion_1")
+	if err != nil || applied.AppliedAt == nil {
+		t.Fatalf("ID の末尾で引けて適用済みのはず: %+v, %v", applied, err)
+	}
+	if report := applied.Format(); !strings.Contains(report, "util/helper.go") || !strings.Contains(report, "0.85") {
+		t.Errorf("report:\n%s", report)
+	}
+	if history := manager.ProvenanceHistory(session.ID); len(history) != 2 {
+		t.Errorf("history = %d", len(history))
+	}
+}
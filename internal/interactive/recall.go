@@ -0,0 +1,103 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/recall"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// recallTagPattern はモデルが以前の実行結果を検索する構造化タグ（<RECALL>test failure</RECALL> 等）
+var recallTagPattern = regexp.MustCompile(`(?s)<RECALL>(.*?)</RECALL>`)
+
+// recallConfig は実行結果の記憶の設定を返す
+func (ism *interactiveSessionManager) recallConfig() config.RecallConfig {
+	if ism.config == nil {
+		return config.DefaultRecallConfig()
+	}
+	return ism.config.Recall
+}
+
+// recallStore はセッションの実行結果の記憶を返す（無効なら nil、なければ作成）
+func (ism *interactiveSessionManager) recallStore(sessionID string) *recall.Store {
+	cfg := ism.recallConfig()
+	if !cfg.Enabled {
+		return nil
+	}
+
+	ism.recallMu.Lock()
+	defer ism.recallMu.Unlock()
+	if store, ok := ism.recallStores[sessionID]; ok {
+		return store
+	}
+	opts := recall.Options{MaxBytes: cfg.MaxBytes}
+	if cfg.Semantic && ism.config != nil {
+		opts.Embedder = llm.NewEmbeddingClient(ism.config)
+		opts.Model = ism.config.ResolvedEmbeddingModel(cfg.EmbeddingModel)
+	}
+	store := recall.NewStore(opts)
+	if ism.recallStores == nil {
+		ism.recallStores = make(map[string]*recall.Store)
+	}
+	ism.recallStores[sessionID] = store
+	return store
+}
+
+// rememberResult はコマンド・ツールの実行結果を後から <RECALL> で検索できるよう記憶する
+func (ism *interactiveSessionManager) rememberResult(sessionID, source, label, content string, success bool) {
+	if store := ism.recallStore(sessionID); store != nil {
+		store.Add(recall.Entry{Source: source, Label: label, Content: content, Success: success})
+	}
+}
+
+// rememberTranscript は会話記録に追加したツール・コマンドの結果を記憶する（復元したセッションの記録も含む）
+func (ism *interactiveSessionManager) rememberTranscript(sessionID string, entries []transcript.Entry) {
+	for _, entry := range entries {
+		if entry.Kind != transcript.KindTool && entry.Kind != transcript.KindCommand {
+			continue
+		}
+		source := entry.Tool
+		if source == "" {
+			source = string(entry.Kind)
+		}
+		label := entry.Command
+		if label == "" {
+			label = entry.Path
+		}
+		content := entry.Content
+		if entry.Error != "" {
+			content = strings.TrimSpace(content + "\n" + entry.Error)
+		}
+		ism.rememberResult(sessionID, source, label, content, entry.Success)
+	}
+}
+
+// forgetResults はセッションの実行結果の記憶を捨てる
+func (ism *interactiveSessionManager) forgetResults(sessionID string) {
+	ism.recallMu.Lock()
+	defer ism.recallMu.Unlock()
+	delete(ism.recallStores, sessionID)
+}
+
+// executeRecallTags は応答中の <RECALL> を実行し、以前の実行結果から関係する部分を返す
+// 空・"recent" は新しい結果、"#3" は指定した結果を返す
+func (ism *interactiveSessionManager) executeRecallTags(ctx context.Context, session *InteractiveSession, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range recallTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		query := strings.TrimSpace(match[1])
+		actions = append(actions, fmt.Sprintf("実行結果の検索: %s", query))
+		store := ism.recallStore(session.ID)
+		if store == nil {
+			results = append(results, "⚠️ 実行結果の記憶は無効です（recall.enabled）")
+			continue
+		}
+		matches := store.Search(ctx, query, ism.recallConfig().MaxResults)
+		results = append(results, fmt.Sprintf("🧠 %s:\n%s", query, recall.Format(matches)))
+	}
+	return results, actions
+}
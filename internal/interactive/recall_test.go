@@ -0,0 +1,55 @@
+package interactive
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// TestRecallTags はコマンド・!command の実行結果を記憶し、<RECALL> で再実行せずに参照できることをテストする
+func TestRecallTags(t *testing.T) {
+	manager := NewInteractiveSessionManager(nil, nil, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	manager.executeStructuredTags(ctx, session, "<COMMAND>echo 'TestLogin failed: expected 200, got 500'</COMMAND>", nil)
+	if _, err := manager.RunShellCommand(ctx, session.ID, "echo build-ok"); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(session.LastCommandOutput, "build-ok") {
+		t.Fatalf("最後の出力は !command のはず: %q", session.LastCommandOutput)
+	}
+
+	execution := manager.executeStructuredTags(ctx, session, "以前の失敗を確認します <RECALL>TestLogin failure</RECALL>", nil)
+	if execution.toolCalls != 1 || len(execution.observations) != 1 || !strings.Contains(execution.observations[0], "expected 200, got 500") {
+		t.Fatalf("最新でない実行結果も検索できるはず: %+v", execution)
+	}
+	if strings.Contains(manager.extractCleanMessage("<RECALL>x</RECALL>確認しました"), "RECALL") {
+		t.Error("表示する本文からタグを除くはず")
+	}
+
+	if err := manager.CloseSession(session.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := manager.recallStores[session.ID]; ok {
+		t.Error("セッションの終了で記憶を捨てるはず")
+	}
+
+	// 無効なら記憶せず、プロンプトにも案内しない
+	cfg := config.DefaultConfig()
+	cfg.Recall.Enabled = false
+	manager.config = cfg
+	for _, tool := range structuredResponseTools(cfg.Recall.Enabled) {
+		if tool.Name == "recall" {
+			t.Error("無効なら <RECALL> を案内しないはず")
+		}
+	}
+	if manager.recallStore("other") != nil {
+		t.Error("無効なら記憶しないはず")
+	}
+}
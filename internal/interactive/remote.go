@@ -0,0 +1,73 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/remote"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// UseRemoteTools はツール層をリモートエージェントのツールに置き換える（vyb --remote）
+// 以降のツール呼び出しと !command はリモートホストで実行される
+func (ism *interactiveSessionManager) UseRemoteTools(client *remote.Client) ([]string, error) {
+	if ism.toolRegistry == nil {
+		return nil, fmt.Errorf("ツールレジストリが初期化されていません")
+	}
+	mounted, err := remote.Mount(ism.toolRegistry, client)
+	if err != nil {
+		return mounted, err
+	}
+	ism.mu.Lock()
+	ism.remote = client
+	ism.mu.Unlock()
+	return mounted, nil
+}
+
+// remoteContextNote はリモートで作業していることをモデルに伝えるコンテキスト（未接続なら空）
+func (ism *interactiveSessionManager) remoteContextNote() string {
+	ism.mu.RLock()
+	client := ism.remote
+	ism.mu.RUnlock()
+	if client == nil || client.Info == nil {
+		return ""
+	}
+	return fmt.Sprintf("ファイル操作・コマンド実行はリモートホスト %s（%s、作業ディレクトリ %s）で行われます。パスはリモートの作業ディレクトリを基準にしてください",
+		client.Info.Hostname, client.Info.OS, client.Info.WorkDir)
+}
+
+// runRemoteShell はリモートの bash ツールでコマンドを実行し、ローカル実行と同じ形の結果にする
+func (ism *interactiveSessionManager) runRemoteShell(ctx context.Context, sessionID, command string, timeout time.Duration) (*tools.ToolExecutionResult, error) {
+	response, err := ism.toolRegistry.ExecuteTool(ctx, &tools.ToolRequest{
+		ID:       fmt.Sprintf("shell_%d", time.Now().UnixNano()),
+		ToolName: "bash",
+		Parameters: map[string]interface{}{
+			"command":     command,
+			"description": "User shell command",
+			"timeout":     float64(timeout.Milliseconds()),
+		},
+		Context: &tools.RequestContext{SessionID: sessionID},
+	})
+	if response == nil {
+		return nil, err
+	}
+
+	result := &tools.ToolExecutionResult{
+		Content: response.Content,
+		IsError: !response.Success,
+		Tool:    "bash",
+		Server:  ism.remote.Host,
+	}
+	if response.Metadata != nil {
+		result.Duration = response.Metadata.ExecutionTime.String()
+		if code, ok := response.Metadata.Debug["exit_code"].(float64); ok {
+			result.ExitCode = int(code)
+		}
+		result.TimedOut, _ = response.Metadata.Debug["timed_out"].(bool)
+	}
+	if result.Content == "" && response.Error != "" {
+		result.Content = response.Error
+	}
+	return result, err
+}
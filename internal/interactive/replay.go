@@ -0,0 +1,76 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// ReplayResult は記録したモデル応答を構造化応答の処理に通した結果（vyb eval 用）
+type ReplayResult struct {
+	Message    string   `json:"message"`    // ユーザーに表示する応答
+	ToolCalls  []string `json:"tool_calls"` // 実行したツール呼び出し（"bash: go test ./..." 等、実行順）
+	Structured bool     `json:"structured"` // 構造化タグを含み、ツール実行として処理されたか
+}
+
+// Replayer はモデルに問い合わせずに、応答プロンプトの描画と応答の解析・実行を行う
+// ツールはカレントディレクトリを作業ディレクトリとして実行する
+type Replayer struct {
+	manager *interactiveSessionManager
+	session *InteractiveSession
+}
+
+// NewReplayer は設定に基づいてセッションを1つ持つ Replayer を作成
+func NewReplayer(cfg *config.Config) (*Replayer, error) {
+	editTool := tools.NewEditTool(security.NewDefaultConstraints("."), ".", 10*1024*1024)
+	manager := NewInteractiveSessionManager(
+		contextmanager.NewSmartContextManager(),
+		nil, // モデルには問い合わせない
+		nil,
+		editTool,
+		nil,
+		cfg.ResolvedModel(),
+		cfg,
+	).(*interactiveSessionManager)
+
+	session, err := manager.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		return nil, fmt.Errorf("セッション作成エラー: %w", err)
+	}
+	return &Replayer{manager: manager, session: session}, nil
+}
+
+// Prompt は入力に対してモデルへ送る応答プロンプト（上書きテンプレートを含む）を描画する
+// 会話履歴・取得コンテキストは含めないため、同じ入力とテンプレートからは同じプロンプトになる
+func (r *Replayer) Prompt(ctx context.Context, input string) string {
+	intent, _ := r.manager.analyzeUserIntent(ctx, r.session, input)
+	data := r.manager.interactivePromptData(r.session, intent)
+	data.Input = input
+	return r.manager.renderInteractivePrompt(data)
+}
+
+// Replay はモデル応答を通常のターンと同じ解析・実行処理に通し、実行したツールと応答を返す
+func (r *Replayer) Replay(ctx context.Context, input, llmResponse string) (*ReplayResult, error) {
+	tracker := budget.New(budget.Limits{})
+	ctx = budget.WithTracker(ctx, tracker)
+
+	content := r.manager.normalizeLanguage(llmResponse)
+	response, err := r.manager.parseAndExecuteStructuredResponse(ctx, r.session, content, input)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ReplayResult{ToolCalls: tracker.Actions()}
+	if response != nil {
+		result.Message = response.Message
+		result.Structured = true
+	} else {
+		result.Message = r.manager.extractCleanMessage(content)
+	}
+	return result, nil
+}
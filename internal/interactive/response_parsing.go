@@ -0,0 +1,404 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// determineResponseType はLLM応答から応答タイプを判定（Claude Code式簡素化）
+func (ism *interactiveSessionManager) determineResponseType(llmResponse string, intent string) ResponseType {
+	// コードブロック（```）を含む場合はコード提案として扱う
+	if strings.Contains(llmResponse, "```") {
+		return ResponseTypeCodeSuggestion
+	}
+
+	// 作成要求の場合でコードが含まれる場合はコード提案
+	if intent == "creation_request" && (strings.Contains(llmResponse, "package") ||
+		strings.Contains(llmResponse, "func") || strings.Contains(llmResponse, "def") ||
+		strings.Contains(llmResponse, "class")) {
+		return ResponseTypeCodeSuggestion
+	}
+
+	// 確認プロンプトが含まれる場合
+	if strings.Contains(llmResponse, "適用しますか") || strings.Contains(llmResponse, "実行しますか") {
+		return ResponseTypeConfirmation
+	}
+
+	// デフォルトはメッセージ（モデルの自然な判断を信頼）
+	return ResponseTypeMessage
+}
+
+// requiresConfirmation は確認が必要かどうかを判定
+func (ism *interactiveSessionManager) requiresConfirmation(responseType ResponseType, intent string) bool {
+	// コード提案は基本的に確認が必要
+	if responseType == ResponseTypeCodeSuggestion {
+		return true
+	}
+
+	// ファイル修正系の意図は確認が必要
+	if strings.Contains(intent, "修正") || strings.Contains(intent, "最適化") || strings.Contains(intent, "リファクタリング") {
+		return true
+	}
+
+	return false
+}
+
+// extractCodeSuggestionsFromLLM はLLM応答からコード提案を抽出
+func (ism *interactiveSessionManager) extractCodeSuggestionsFromLLM(llmResponse string, originalInput string) ([]*CodeSuggestion, error) {
+	var suggestions []*CodeSuggestion
+
+	// 元の入力からファイルパスを抽出
+	suggestedFilePath := ism.extractFilePathFromInput(originalInput)
+
+	// マークダウンコードブロックを抽出
+	codeBlockPattern := regexp.MustCompile("```(?:go|javascript|python|rust|java|c|cpp|csharp)?\\s*\\n([\\s\\S]*?)\\n```")
+	matches := codeBlockPattern.FindAllStringSubmatch(llmResponse, -1)
+
+	for i, match := range matches {
+		if len(match) > 1 {
+			suggestion := &CodeSuggestion{
+				ID:            fmt.Sprintf("llm_suggestion_%d_%d", time.Now().UnixNano(), i),
+				Type:          SuggestionTypeImprovement,
+				OriginalCode:  "", // 元コードは別途特定が必要
+				SuggestedCode: strings.TrimSpace(match[1]),
+				Explanation:   ism.extractExplanationFromLLM(llmResponse, i),
+				Confidence:    0.85, // LLM生成なので高い信頼度
+				ImpactLevel:   ImpactLevelMedium,
+				FilePath:      suggestedFilePath, // 抽出されたファイルパスを設定
+				LineRange:     [2]int{0, 0},
+				Metadata: map[string]string{
+					"generated_by":   "llm",
+					"model":          ism.getConfiguredModel(),
+					"benefits":       "AI生成による実装, ベストプラクティスに基づく",
+					"risks":          "実際の動作確認が必要",
+					"estimated_time": "5-10分",
+					"original_input": originalInput,
+				},
+				CreatedAt: time.Now(),
+			}
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+
+	// コードブロックがない場合は一般的な提案として扱う
+	if len(suggestions) == 0 {
+		suggestion := &CodeSuggestion{
+			ID:            fmt.Sprintf("llm_suggestion_%d", time.Now().UnixNano()),
+			Type:          SuggestionTypeImprovement,
+			OriginalCode:  "",
+			SuggestedCode: llmResponse, // 全体を提案として扱う
+			Explanation:   "AIによる提案",
+			Confidence:    0.7,
+			ImpactLevel:   ImpactLevelLow,
+			FilePath:      suggestedFilePath, // 抽出されたファイルパスを設定
+			LineRange:     [2]int{0, 0},
+			Metadata: map[string]string{
+				"generated_by":   "llm",
+				"model":          ism.getConfiguredModel(),
+				"estimated_time": "確認が必要",
+				"original_input": originalInput,
+			},
+			CreatedAt: time.Now(),
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+
+	return suggestions, nil
+}
+
+// extractExplanationFromLLM はLLM応答から説明部分を抽出
+func (ism *interactiveSessionManager) extractExplanationFromLLM(llmResponse string, codeIndex int) string {
+	// コードブロック前後のテキストから説明を抽出（簡易実装）
+	lines := strings.Split(llmResponse, "\n")
+	var explanation strings.Builder
+
+	inCodeBlock := false
+	for _, line := range lines {
+		if strings.HasPrefix(line, "```") {
+			inCodeBlock = !inCodeBlock
+			continue
+		}
+
+		if !inCodeBlock && strings.TrimSpace(line) != "" {
+			explanation.WriteString(line)
+			explanation.WriteString(" ")
+		}
+	}
+
+	result := strings.TrimSpace(explanation.String())
+	if result == "" {
+		return "AIによるコード提案です。"
+	}
+
+	// 長すぎる場合は短縮
+	if len(result) > 200 {
+		return result[:200] + "..."
+	}
+
+	return result
+}
+
+// executeCommandDirectly は安全なコマンドを直接実行
+func (ism *interactiveSessionManager) executeCommandDirectly(ctx context.Context, session *InteractiveSession, suggestion *CodeSuggestion) error {
+	command := ism.extractCommandFromSuggestion(suggestion.SuggestedCode)
+	if command == "" {
+		return fmt.Errorf("コマンドを抽出できませんでした")
+	}
+
+	// BashToolを使ってコマンド実行
+	result, err := ism.bashTool.ExecuteContext(ctx, command, "安全なコマンドの直接実行", 30000)
+	if err != nil {
+		return fmt.Errorf("コマンド実行失敗: %w", err)
+	}
+
+	// セッションに実行結果を保存
+	session.LastCommandOutput = result.Content
+	session.LastActivity = time.Now()
+
+	// 提案を適用済みにマーク
+	suggestion.Applied = true
+	suggestion.UserConfirmed = true
+
+	return nil
+}
+
+// 提案応答の説明・信頼度の見出し（buildSuggestionPrompt が指定する形式、英語で答えた場合も受け付ける）
+var (
+	suggestionExplanationPattern = regexp.MustCompile(`(?i)\*{0,2}(?:説明|explanation)\*{0,2}\s*[:：]\s*\*{0,2}`)
+	suggestionConfidencePattern  = regexp.MustCompile(`(?i)\*{0,2}(?:信頼度|confidence)\*{0,2}\s*[:：]\s*\*{0,2}\s*([01](?:\.\d+)?)`)
+)
+
+// parseSuggestionResponse は提案応答から提案コード（最初のコードブロック）・説明・モデル自身の信頼度を取り出す
+func (ism *interactiveSessionManager) parseSuggestionResponse(
+	response string,
+	request *SuggestionRequest,
+) (*CodeSuggestion, error) {
+	code, ok := firstCodeBlock(response)
+	if !ok {
+		return nil, fmt.Errorf("応答に提案コードのコードブロックがありません")
+	}
+
+	suggestion := &CodeSuggestion{
+		ID:            fmt.Sprintf("suggestion_%d", time.Now().UnixNano()),
+		Type:          request.Type,
+		OriginalCode:  request.Code,
+		SuggestedCode: code,
+		Confidence:    0.8,
+		FilePath:      request.FilePath,
+		LineRange:     request.LineRange,
+		Metadata:      make(map[string]string),
+		CreatedAt:     time.Now(),
+		UserConfirmed: false,
+		Applied:       false,
+	}
+
+	// 説明は見出しから信頼度の行まで（見出しがなければコードブロックの後の文章）
+	explanation := ""
+	if loc := suggestionExplanationPattern.FindStringIndex(response); loc != nil {
+		explanation = response[loc[1]:]
+	} else if end := strings.LastIndex(response, "```"); end >= 0 {
+		explanation = response[end+3:]
+	}
+	if loc := suggestionConfidencePattern.FindStringIndex(explanation); loc != nil {
+		explanation = explanation[:loc[0]]
+	}
+	suggestion.Explanation = strings.TrimSpace(explanation)
+
+	if match := suggestionConfidencePattern.FindStringSubmatch(response); match != nil {
+		if value, err := strconv.ParseFloat(match[1], 64); err == nil && value <= 1 {
+			suggestion.Confidence = value
+			suggestion.Metadata["model_confidence"] = match[1]
+		}
+	}
+	return suggestion, nil
+}
+
+// firstCodeBlock は最初のフェンス付きコードブロックの中身を返す
+func firstCodeBlock(text string) (string, bool) {
+	start := strings.Index(text, "```")
+	if start < 0 {
+		return "", false
+	}
+	body := text[start+3:]
+	newline := strings.Index(body, "\n")
+	if newline < 0 {
+		return "", false
+	}
+	body = body[newline+1:]
+	end := strings.Index(body, "```")
+	if end < 0 {
+		return "", false
+	}
+	return strings.TrimSuffix(body[:end], "\n"), true
+}
+
+// extractFilePathFromInput はユーザー入力からファイルパスを抽出
+func (ism *interactiveSessionManager) extractFilePathFromInput(input string) string {
+	// ファイル名のパターンを抽出（例: "test.goを作成", "hello.jsファイル"等）
+	patterns := []string{
+		`(\w+\.(?:go|js|py|java|rs|cpp|c|ts|jsx|tsx|vue|html|css|json|yaml|yml|xml|md|txt))`,
+		`(\w+ファイル)`,
+	}
+
+	for _, pattern := range patterns {
+		re := regexp.MustCompile(pattern)
+		matches := re.FindStringSubmatch(input)
+		if len(matches) > 1 {
+			filename := matches[1]
+			// "ファイル"という文字が含まれている場合は、適切な拡張子を推定
+			if strings.Contains(filename, "ファイル") {
+				baseFilename := strings.ReplaceAll(filename, "ファイル", "")
+				// コンテキストから言語を推定
+				if strings.Contains(input, "Go") || strings.Contains(input, "go") {
+					return baseFilename + ".go"
+				} else if strings.Contains(input, "JavaScript") || strings.Contains(input, "JS") {
+					return baseFilename + ".js"
+				} else if strings.Contains(input, "Python") {
+					return baseFilename + ".py"
+				}
+				// デフォルトは.txtファイル
+				return baseFilename + ".txt"
+			}
+			return filename
+		}
+	}
+
+	// 特定できない場合はデフォルトのファイル名を生成
+	if strings.Contains(input, "Go") || strings.Contains(input, "go") {
+		return "main.go"
+	} else if strings.Contains(input, "JavaScript") || strings.Contains(input, "JS") {
+		return "index.js"
+	} else if strings.Contains(input, "Python") {
+		return "main.py"
+	}
+
+	return "output.txt" // 最終フォールバック
+}
+
+// isCommandSuggestion はコマンド実行の提案かどうかを判定
+func (ism *interactiveSessionManager) isCommandSuggestion(suggestedCode string) bool {
+	// 1. BashToolパターン（最も一般的）
+	if strings.Contains(suggestedCode, "BashTool") {
+		return true
+	}
+
+	// 2. bashコードブロック
+	if strings.Contains(suggestedCode, "```bash") || strings.Contains(suggestedCode, "```sh") {
+		return true
+	}
+
+	// 3. $ プレフィックス付きコマンド
+	if strings.Contains(suggestedCode, "$ ") {
+		return true
+	}
+
+	// 4. 一般的なコマンドの直接記述
+	lines := strings.Split(suggestedCode, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if strings.HasPrefix(line, "git ") ||
+			strings.HasPrefix(line, "ls") ||
+			strings.HasPrefix(line, "pwd") ||
+			strings.HasPrefix(line, "cat ") ||
+			strings.HasPrefix(line, "head ") ||
+			strings.HasPrefix(line, "tail ") {
+			return true
+		}
+	}
+
+	// 5. FileOperations等のファイル操作は除外（コマンド実行ではない）
+	if strings.Contains(suggestedCode, "FileOperations") {
+		return false // ファイル操作として扱う
+	}
+
+	// 6. git関連のキーワードがある場合
+	if strings.Contains(suggestedCode, "git status") ||
+		strings.Contains(suggestedCode, "git branch") ||
+		strings.Contains(suggestedCode, "git log") {
+		return true
+	}
+
+	// 7. 単体で短いコマンドの場合
+	trimmed := strings.TrimSpace(suggestedCode)
+	if trimmed == "git status" || trimmed == "ls" || trimmed == "pwd" {
+		return true
+	}
+
+	return false
+}
+
+// extractCommandFromSuggestion は提案からコマンドを抽出
+func (ism *interactiveSessionManager) extractCommandFromSuggestion(suggestedCode string) string {
+	// 1. BashToolパターンの抽出（最も一般的）
+	if strings.Contains(suggestedCode, "BashTool") {
+		// BashTool.run_command("git status") パターン
+		re := regexp.MustCompile(`BashTool\.run_command\("([^"]+)"\)`)
+		matches := re.FindStringSubmatch(suggestedCode)
+		if len(matches) > 1 {
+			return matches[1]
+		}
+
+		// BashTool("git status") パターン
+		re = regexp.MustCompile(`BashTool\("([^"]+)"\)`)
+		matches = re.FindStringSubmatch(suggestedCode)
+		if len(matches) > 1 {
+			return matches[1]
+		}
+	}
+
+	// 2. コードブロック内のコマンド抽出
+	lines := strings.Split(suggestedCode, "\n")
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+
+		// bash/sh コードブロック内のコマンド
+		if strings.HasPrefix(line, "git ") ||
+			strings.HasPrefix(line, "ls") ||
+			strings.HasPrefix(line, "pwd") ||
+			strings.HasPrefix(line, "cat ") ||
+			strings.HasPrefix(line, "head ") ||
+			strings.HasPrefix(line, "tail ") {
+			return line
+		}
+
+		// $ プレフィックス付きコマンド
+		if strings.HasPrefix(line, "$ ") {
+			return strings.TrimPrefix(line, "$ ")
+		}
+	}
+
+	// 3. 直接コマンド形式
+	trimmed := strings.TrimSpace(suggestedCode)
+	if strings.HasPrefix(trimmed, "git ") ||
+		strings.HasPrefix(trimmed, "ls") ||
+		strings.HasPrefix(trimmed, "pwd") ||
+		strings.HasPrefix(trimmed, "cat ") {
+		return trimmed
+	}
+
+	// 4. ツール形式の一般的パターン
+	if strings.Contains(suggestedCode, "git status") {
+		return "git status"
+	}
+	if strings.Contains(suggestedCode, "git branch") {
+		return "git branch"
+	}
+	if strings.Contains(suggestedCode, `"ls"`) || strings.Contains(suggestedCode, "ls") {
+		return "ls -la"
+	}
+
+	// 5. 実際のgit status結果が表示されている場合、コマンドを実行すべき
+	if strings.Contains(suggestedCode, "On branch") ||
+		strings.Contains(suggestedCode, "nothing to commit") ||
+		strings.Contains(suggestedCode, "working tree clean") ||
+		strings.Contains(suggestedCode, "Changes not staged") ||
+		strings.Contains(suggestedCode, "Untracked files") {
+		return "git status"
+	}
+
+	return ""
+}
@@ -0,0 +1,33 @@
+package interactive
+
+import (
+	"context"
+
+	"github.com/glkt/vyb-code/internal/scheduler"
+)
+
+// SetScheduler はLLMリクエスト・ツール実行・分析の同時実行数を制限するスケジューラーを設定
+func (ism *interactiveSessionManager) SetScheduler(s *scheduler.Scheduler) {
+	ism.mu.Lock()
+	ism.scheduler = s
+	ism.mu.Unlock()
+	if ism.toolRegistry != nil {
+		ism.toolRegistry.SetScheduler(s)
+	}
+}
+
+// acquireAnalysis はプロジェクト分析・認知分析の枠をバックグラウンド優先度で確保する
+// 返すコンテキストもバックグラウンド優先度になり、分析中のLLMリクエストは対話より後回しになる
+// 空きを待つ間に ctx が終了した場合は分析を行わないようエラーを返す
+func (ism *interactiveSessionManager) acquireAnalysis(ctx context.Context) (context.Context, func(), error) {
+	ism.mu.RLock()
+	sched := ism.scheduler
+	ism.mu.RUnlock()
+
+	ctx = scheduler.Background(ctx)
+	release, err := sched.Acquire(ctx, scheduler.ClassAnalysis)
+	if err != nil {
+		return ctx, nil, err
+	}
+	return ctx, release, nil
+}
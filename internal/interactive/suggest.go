@@ -0,0 +1,134 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// 提案の種類の名前（vyb suggest --type・code/suggest の type、SuggestionType の並びと同じ順）
+var suggestionTypeNames = []string{"improvement", "bugfix", "optimization", "refactor", "documentation", "security", "test"}
+
+// SuggestionTypeNames は指定できる提案の種類の名前を返す
+func SuggestionTypeNames() []string {
+	return append([]string(nil), suggestionTypeNames...)
+}
+
+// String は提案の種類の名前（improvement, bugfix 等）を返す
+func (t SuggestionType) String() string {
+	if int(t) >= 0 && int(t) < len(suggestionTypeNames) {
+		return suggestionTypeNames[t]
+	}
+	return suggestionTypeNames[SuggestionTypeImprovement]
+}
+
+// ParseSuggestionType は提案の種類の名前を変換（空なら improvement）
+func ParseSuggestionType(name string) (SuggestionType, error) {
+	if name == "" {
+		return SuggestionTypeImprovement, nil
+	}
+	for i, known := range suggestionTypeNames {
+		if strings.EqualFold(name, known) {
+			return SuggestionType(i), nil
+		}
+	}
+	return SuggestionTypeImprovement, fmt.Errorf("不明な提案の種類です: %s (有効な値: %s)", name, strings.Join(suggestionTypeNames, ", "))
+}
+
+// NewFileSuggestionRequest はファイルの行範囲（1始まり、両端を含む）を対象にした提案リクエストを作る
+// 範囲が 0, 0 ならファイル全体、終了行がファイルの行数を超えれば末尾までを対象にする
+func NewFileSuggestionRequest(filePath string, startLine, endLine int, suggestionType SuggestionType, description string) (*SuggestionRequest, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("対象ファイル読み込みエラー: %w", err)
+	}
+	lines := strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+	if startLine == 0 && endLine == 0 {
+		startLine, endLine = 1, len(lines)
+	}
+	if startLine < 1 || endLine < startLine {
+		return nil, fmt.Errorf("無効な行範囲です: %d:%d", startLine, endLine)
+	}
+	if startLine > len(lines) {
+		return nil, fmt.Errorf("開始行がファイルの行数 (%d) を超えています: %d", len(lines), startLine)
+	}
+	if endLine > len(lines) {
+		endLine = len(lines)
+	}
+
+	return &SuggestionRequest{
+		Type:            suggestionType,
+		FilePath:        filePath,
+		Code:            strings.Join(lines[startLine-1:endLine], "\n"),
+		LineRange:       [2]int{startLine, endLine},
+		UserDescription: description,
+		Context:         make(map[string]string),
+		Priority:        5,
+	}, nil
+}
+
+// SuggestionResult は単発のコード提案の結果（エディタ・スクリプト向けの安定したJSON形式）
+type SuggestionResult struct {
+	Type          string            `json:"type"`
+	FilePath      string            `json:"file_path"`
+	StartLine     int               `json:"start_line"` // 置き換える範囲（1始まり、両端を含む）
+	EndLine       int               `json:"end_line"`
+	OriginalCode  string            `json:"original_code"`
+	SuggestedCode string            `json:"suggested_code"`
+	Explanation   string            `json:"explanation"`
+	Confidence    float64           `json:"confidence"` // 0.0-1.0
+	Impact        string            `json:"impact"`     // low, medium, high, critical
+	Model         string            `json:"model"`
+	Metadata      map[string]string `json:"metadata,omitempty"` // blast_radius（影響範囲）、model_confidence（モデル自身の評価）
+}
+
+// Suggester はセッションを開かずに単発のコード提案を返せるセッション管理
+type Suggester interface {
+	Suggest(ctx context.Context, request *SuggestionRequest) (*SuggestionResult, error)
+}
+
+// Suggest は一時的なセッションで提案を1つ生成して返す
+// 提案は保留・適用せず、自動承認ポリシーも評価しない（ファイルは変更しない）
+func (ism *interactiveSessionManager) Suggest(ctx context.Context, request *SuggestionRequest) (*SuggestionResult, error) {
+	if request == nil || strings.TrimSpace(request.Code) == "" {
+		return nil, fmt.Errorf("提案の対象コードがありません")
+	}
+	if ism.llmProvider == nil {
+		return nil, fmt.Errorf("LLMプロバイダーが設定されていません")
+	}
+
+	session, err := ism.CreateSession(CodingSessionTypeGeneral)
+	if err != nil {
+		return nil, fmt.Errorf("セッション作成エラー: %w", err)
+	}
+	defer ism.CloseSession(session.ID)
+	session.CurrentFile = request.FilePath
+	session.UserIntent = request.UserDescription
+
+	suggestion, _, _, err := ism.generateSuggestion(ctx, session, request)
+	if err != nil {
+		return nil, err
+	}
+
+	impact := config.ValidImpactLevels()
+	result := &SuggestionResult{
+		Type:          suggestion.Type.String(),
+		FilePath:      suggestion.FilePath,
+		StartLine:     suggestion.LineRange[0],
+		EndLine:       suggestion.LineRange[1],
+		OriginalCode:  suggestion.OriginalCode,
+		SuggestedCode: suggestion.SuggestedCode,
+		Explanation:   suggestion.Explanation,
+		Confidence:    suggestion.Confidence,
+		Impact:        impact[ImpactLevelMedium],
+		Model:         ism.getConfiguredModel(),
+		Metadata:      suggestion.Metadata,
+	}
+	if int(suggestion.ImpactLevel) < len(impact) {
+		result.Impact = impact[suggestion.ImpactLevel]
+	}
+	return result, nil
+}
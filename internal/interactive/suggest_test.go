@@ -0,0 +1,67 @@
+package interactive
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+)
+
+// TestSuggest は行範囲の提案リクエストからモデルの応答を構造化して返し、ファイルは変更しないことをテストする
+func TestSuggest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "calc.go")
+	source := "package calc\n\nfunc Add(a, b int) int {\n\treturn a - b\n}\n"
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	suggestionType, err := ParseSuggestionType("BugFix")
+	if err != nil || suggestionType != SuggestionTypeBugFix || suggestionType.String() != "bugfix" {
+		t.Fatalf("ParseSuggestionType = %v, %v", suggestionType, err)
+	}
+	if _, err := ParseSuggestionType("rewrite"); err == nil {
+		t.Error("不明な種類はエラーになるはず")
+	}
+
+	request, err := NewFileSuggestionRequest(path, 3, 99, suggestionType, "Add subtracts")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.LineRange != [2]int{3, 5} || request.Code != "func Add(a, b int) int {\n\treturn a - b\n}" {
+		t.Fatalf("終了行はファイル末尾に丸めるはず: %v %q", request.LineRange, request.Code)
+	}
+	for _, lines := range [][2]int{{0, 2}, {4, 3}, {9, 10}} {
+		if _, err := NewFileSuggestionRequest(path, lines[0], lines[1], suggestionType, ""); err == nil {
+			t.Errorf("無効な行範囲 %v はエラーになるはず", lines)
+		}
+	}
+
+	provider := &patchProvider{content: "**提案コード:**\n```go\nfunc Add(a, b int) int {\n\treturn a + b\n}\n```\n\n**説明:**\nAdd は引数を足すべきです。\n\n**信頼度:** 0.9"}
+	manager := NewInteractiveSessionManager(contextmanager.NewSmartContextManager(), provider, nil, nil, nil, "test-model", nil).(*interactiveSessionManager)
+	result, err := manager.Suggest(context.Background(), request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SuggestedCode != "func Add(a, b int) int {\n\treturn a + b\n}" || result.Explanation != "Add は引数を足すべきです。" {
+		t.Errorf("提案コードと説明を取り出すはず: %+v", result)
+	}
+	if result.Type != "bugfix" || result.Impact != "high" || result.StartLine != 3 || result.EndLine != 5 || result.Model != "test-model" {
+		t.Errorf("結果 = %+v", result)
+	}
+	if result.Confidence <= 0 || result.Confidence > 1 || result.Metadata["model_confidence"] != "0.9" {
+		t.Errorf("信頼度 = %g, %v", result.Confidence, result.Metadata)
+	}
+	if data, _ := os.ReadFile(path); string(data) != source {
+		t.Errorf("ファイルは変更しないはず: %s", data)
+	}
+	if sessions, _ := manager.ListActiveSessions(); len(sessions) != 0 {
+		t.Errorf("一時的なセッションは閉じるはず: %d", len(sessions))
+	}
+
+	provider.content = "I cannot help with that."
+	if _, err := manager.Suggest(context.Background(), request); err == nil {
+		t.Error("コードブロックのない応答はエラーになるはず")
+	}
+}
@@ -0,0 +1,54 @@
+package interactive
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/templates"
+)
+
+// templateSessionTypes はテンプレートの session_type とセッション種別の対応
+var templateSessionTypes = map[string]CodingSessionType{
+	"general":   CodingSessionTypeGeneral,
+	"debugging": CodingSessionTypeDebugging,
+	"refactor":  CodingSessionTypeRefactor,
+	"review":    CodingSessionTypeReview,
+	"learning":  CodingSessionTypeLearning,
+}
+
+// SetSessionTemplate はセッションにテンプレートを適用する（session_type があればセッション種別も切り替える）
+func (ism *interactiveSessionManager) SetSessionTemplate(sessionID string, tmpl *templates.Template) error {
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+
+	session, exists := ism.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("セッション %s が見つかりません", sessionID)
+	}
+	session.Template = tmpl
+	if sessionType, ok := templateSessionTypes[tmpl.SessionType]; ok {
+		session.Type = sessionType
+	}
+	if session.SessionMetadata != nil {
+		session.SessionMetadata["template"] = tmpl.Name
+	}
+	return nil
+}
+
+// applyTemplateData はテンプレートの指示・計画の骨組み・重視するツールをプロンプトのパラメーターに反映する
+func applyTemplateData(tmpl *templates.Template, data *prompts.Data) {
+	if tmpl == nil {
+		return
+	}
+	data.Template = tmpl.Name
+	data.Instructions = strings.TrimSpace(tmpl.SystemPrompt)
+	data.Plan = tmpl.Plan
+	for i := range data.Tools {
+		for _, name := range tmpl.Tools {
+			if strings.EqualFold(data.Tools[i].Name, name) {
+				data.Tools[i].Focus = true
+			}
+		}
+	}
+}
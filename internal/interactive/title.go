@@ -0,0 +1,158 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/scheduler"
+)
+
+// titleTimeout は題名の生成を待つ上限（過ぎたら最初の入力を題名にする）
+const titleTimeout = 15 * time.Second
+
+// titleExcerptRunes は題名の生成に渡す入力・応答の最大文字数
+const titleExcerptRunes = 800
+
+// titlePrompt は最初のやり取りから題名を求める指示
+const titlePrompt = `Write a short title (3 to 7 words) describing the coding task in this conversation.
+Use the language of the user's message. Reply with the title only: no quotes, no punctuation at the end, no explanation.`
+
+// SessionTitle はセッションの題名（まだなければ空）
+func (ism *interactiveSessionManager) SessionTitle(sessionID string) string {
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+	if session, exists := ism.sessions[sessionID]; exists {
+		return session.Title
+	}
+	return ""
+}
+
+// SetSessionTitle はセッションの題名を設定して保存する（以降は自動で付け直さない）
+func (ism *interactiveSessionManager) SetSessionTitle(sessionID, title string) error {
+	title = cleanTitle(title, ism.titleConfig().MaxLength)
+	if title == "" {
+		return fmt.Errorf("題名が空です")
+	}
+
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+	session, exists := ism.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("セッション %s が見つかりません", sessionID)
+	}
+	session.Title = title
+	ism.titled[sessionID] = true
+	ism.persistSession(session, false)
+	return nil
+}
+
+// titleConfig は題名の生成設定（設定がなければ既定値）
+func (ism *interactiveSessionManager) titleConfig() config.SessionTitleConfig {
+	if ism.config == nil {
+		return config.DefaultSessionTitleConfig()
+	}
+	return ism.config.SessionTitles
+}
+
+// startTitleGeneration は最初のやり取りの後、バックグラウンドでセッションの題名を生成する
+// 応答を待たせないよう別の goroutine で行い、CloseSession・StopJobs は生成の終了を待つ
+func (ism *interactiveSessionManager) startTitleGeneration(sessionID, input, answer string) {
+	cfg := ism.titleConfig()
+	if !cfg.Enabled {
+		return
+	}
+
+	ism.mu.Lock()
+	if ism.titled[sessionID] {
+		ism.mu.Unlock()
+		return
+	}
+	if _, exists := ism.sessions[sessionID]; !exists {
+		ism.mu.Unlock()
+		return
+	}
+	ism.titled[sessionID] = true
+	ism.titleWG.Add(1)
+	ism.mu.Unlock()
+
+	go func() {
+		defer ism.titleWG.Done()
+		title := ism.generateTitle(input, answer, cfg)
+
+		ism.mu.Lock()
+		defer ism.mu.Unlock()
+		// 生成中に /title で付けた題名は上書きしない
+		if session, exists := ism.sessions[sessionID]; exists && session.Title == "" {
+			session.Title = title
+			ism.persistSession(session, false)
+		}
+	}()
+}
+
+// generateTitle はモデルに題名を求める（失敗・空の応答なら最初の入力から作る）
+func (ism *interactiveSessionManager) generateTitle(input, answer string, cfg config.SessionTitleConfig) string {
+	fallback := cleanTitle(input, cfg.MaxLength)
+	if ism.llmProvider == nil {
+		return fallback
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = ism.getConfiguredModel()
+	}
+	// 監査ログのセッションを引き継がず、対話のリクエストより後回しにする
+	ctx, cancel := context.WithTimeout(scheduler.Background(context.Background()), titleTimeout)
+	defer cancel()
+
+	conversation := fmt.Sprintf("User: %s\n\nAssistant: %s", truncateRunes(input, titleExcerptRunes), truncateRunes(answer, titleExcerptRunes))
+	response, err := ism.llmProvider.Chat(ctx, llm.ChatRequest{
+		Model: model,
+		Messages: []llm.ChatMessage{
+			{Role: "system", Content: titlePrompt},
+			{Role: "user", Content: conversation},
+		},
+		Stream: false,
+	})
+	if err != nil || response == nil {
+		return fallback
+	}
+	if title := cleanTitle(response.Message.Content, cfg.MaxLength); title != "" {
+		return title
+	}
+	return fallback
+}
+
+// cleanTitle はモデルの応答・入力を1行の題名に整える（最初の空でない行、引用符・見出し記号・末尾の句点やコロンを除き maxRunes 文字まで）
+func cleanTitle(text string, maxRunes int) string {
+	var line string
+	for _, candidate := range strings.Split(text, "\n") {
+		if candidate = strings.TrimSpace(candidate); candidate != "" {
+			line = candidate
+			break
+		}
+	}
+	for _, prefix := range []string{"Title:", "title:", "題名:", "タイトル:"} {
+		line = strings.TrimPrefix(line, prefix)
+	}
+	line = strings.Join(strings.Fields(line), " ")
+	line = strings.Trim(line, "\"'`*#「」『』 ")
+	line = strings.TrimRight(line, ".。:： ")
+	if maxRunes > 0 && utf8.RuneCountInString(line) > maxRunes {
+		line = strings.TrimSpace(string([]rune(line)[:maxRunes-1])) + "…"
+	}
+	return line
+}
+
+// truncateRunes は文字数の上限で切り詰める
+func truncateRunes(text string, maxRunes int) string {
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	return string(runes[:maxRunes]) + "…"
+}
@@ -0,0 +1,151 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// commandTagPattern はコマンド実行タグ（生成途中の応答と完成した応答で同じ規則を使う）
+var commandTagPattern = regexp.MustCompile(`<COMMAND>(.*?)</COMMAND>`)
+
+// streamedCommand は応答の生成中に実行を始めた <COMMAND> の1件
+type streamedCommand struct {
+	id      string // commandBlockID で付けたID
+	command string
+	output  string
+	err     error
+}
+
+// commandBlockID は <COMMAND> ブロックのID（同じコマンドの何回目の出現か）を返す
+// seen は応答ごとの出現回数で、再試行で本文が最初から渡し直されても同じブロックには同じIDが付く
+func commandBlockID(seen map[string]int, command string) string {
+	seen[command]++
+	return fmt.Sprintf("%s#%d", command, seen[command])
+}
+
+// toolStream は生成途中の応答を読み進め、閉じタグまで届いた <COMMAND> を出現順に実行する
+// 実行は1つのゴルーチンで順に行うため、応答の完成後にまとめて実行する場合と順序・結果は変わらない
+type toolStream struct {
+	ism     *interactiveSessionManager
+	ctx     context.Context
+	session *InteractiveSession
+
+	mu       sync.Mutex
+	cond     *sync.Cond
+	content  string                      // 前回受け取った本文
+	scanned  int                         // 今回の本文で読んだブロック数
+	seen     map[string]int              // 今回の本文でのコマンドごとの出現回数
+	byID     map[string]*streamedCommand // 実行した（実行待ちを含む）ブロック
+	commands []*streamedCommand          // 見つけた順（実行待ちを含む）
+	executed int                         // 実行を終えた件数
+	closed   bool
+	finished chan struct{}
+}
+
+// newToolStream は実行用のゴルーチンを開始する（close で終了を待つ）
+func newToolStream(ctx context.Context, ism *interactiveSessionManager, session *InteractiveSession) *toolStream {
+	stream := &toolStream{
+		ism:      ism,
+		ctx:      ctx,
+		session:  session,
+		seen:     make(map[string]int),
+		byID:     make(map[string]*streamedCommand),
+		finished: make(chan struct{}),
+	}
+	stream.cond = sync.NewCond(&stream.mu)
+	go stream.run()
+	return stream
+}
+
+// update は受信済みの本文全体を受け取り、新たに完成したコマンドを実行待ちに加える
+func (s *toolStream) update(content string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if !strings.HasPrefix(content, s.content) {
+		// 再試行で本文が最初から渡し直された（実行済みのブロックはIDで見分けて再実行しない）
+		s.scanned = 0
+		s.seen = make(map[string]int)
+	}
+	s.content = content
+
+	matches := commandTagPattern.FindAllStringSubmatch(s.ism.normalizeLanguage(content), -1)
+	for _, match := range matches[min(s.scanned, len(matches)):] {
+		s.scanned++
+		command := strings.TrimSpace(match[1])
+		id := commandBlockID(s.seen, command)
+		if s.byID[id] != nil {
+			continue
+		}
+		streamed := &streamedCommand{id: id, command: command}
+		s.byID[id] = streamed
+		s.commands = append(s.commands, streamed)
+	}
+	s.cond.Signal()
+}
+
+// run は実行待ちのコマンドを順に実行する
+func (s *toolStream) run() {
+	defer close(s.finished)
+	for {
+		s.mu.Lock()
+		for s.executed == len(s.commands) && !s.closed {
+			s.cond.Wait()
+		}
+		if s.executed == len(s.commands) {
+			s.mu.Unlock()
+			return
+		}
+		command := s.commands[s.executed]
+		s.mu.Unlock()
+
+		command.output, command.err = s.ism.executeBashCommand(s.ctx, s.session, command.command)
+
+		s.mu.Lock()
+		s.executed++
+		s.mu.Unlock()
+	}
+}
+
+// close は読み進めるのをやめ、実行を始めたコマンドが全て終わるまで待つ
+func (s *toolStream) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.cond.Signal()
+	s.mu.Unlock()
+	<-s.finished
+}
+
+// take は完成した応答のIDのブロックを生成中に実行していればその結果を返す（close の後に呼ぶ）
+func (s *toolStream) take(id string) (*streamedCommand, bool) {
+	if s == nil {
+		return nil, false
+	}
+	streamed, ok := s.byID[id]
+	return streamed, ok
+}
+
+// chatWithToolStream はモデルに問い合わせ、tool streaming が有効なら生成中に完成した <COMMAND> を実行し始める
+// 返す toolStream（無効ならnil）は実行を終えており、executeStructuredTags に渡して結果を使う
+func (ism *interactiveSessionManager) chatWithToolStream(
+	ctx context.Context,
+	session *InteractiveSession,
+	request llm.ChatRequest,
+) (*llm.ChatResponse, *toolStream, error) {
+	if !ism.agentLoopConfig().StreamTools {
+		response, err := ism.llmProvider.Chat(ctx, request)
+		return response, nil, err
+	}
+
+	stream := newToolStream(ctx, ism, session)
+	response, err := ism.llmProvider.Chat(llm.WithStreamHandler(ctx, stream.update), request)
+	stream.close()
+	return response, stream, err
+}
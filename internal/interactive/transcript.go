@@ -0,0 +1,120 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// Transcript はセッションの会話記録のコピーを返す（/save でのエクスポート用）
+func (ism *interactiveSessionManager) Transcript(sessionID string) (*transcript.Transcript, error) {
+	ism.mu.RLock()
+	defer ism.mu.RUnlock()
+
+	if _, exists := ism.sessions[sessionID]; !exists {
+		return nil, fmt.Errorf("セッションが見つかりません: %s", sessionID)
+	}
+	record, exists := ism.transcripts[sessionID]
+	if !exists {
+		return transcript.New(sessionID, ism.modelName), nil
+	}
+	return record.Clone(), nil
+}
+
+// appendTranscript はセッションの会話記録に項目を追加（ツール・コマンドの結果は <RECALL> 用にも記憶する）
+func (ism *interactiveSessionManager) appendTranscript(sessionID string, entries ...transcript.Entry) {
+	ism.rememberTranscript(sessionID, entries)
+
+	ism.mu.Lock()
+	defer ism.mu.Unlock()
+
+	if ism.transcripts == nil {
+		ism.transcripts = make(map[string]*transcript.Transcript)
+	}
+	record, exists := ism.transcripts[sessionID]
+	if !exists {
+		record = transcript.New(sessionID, ism.modelName)
+		ism.transcripts[sessionID] = record
+	}
+	for _, entry := range entries {
+		record.Add(entry)
+	}
+}
+
+// recordUserInput はユーザー入力を会話記録・保存先・監査ログに残す
+func (ism *interactiveSessionManager) recordUserInput(ctx context.Context, sessionID, input string) {
+	ism.appendTranscript(sessionID, transcript.Entry{Kind: transcript.KindUser, Content: input, Success: true})
+	ism.appendMessage(sessionID, "user", input)
+	logger.AuditContext(ctx, logger.AuditEvent{Type: logger.AuditUserInput, Content: input, Success: true})
+}
+
+// recordAssistantResponse は最終的な応答を会話記録・保存先・監査ログに残す
+func (ism *interactiveSessionManager) recordAssistantResponse(ctx context.Context, sessionID, message string) {
+	ism.appendTranscript(sessionID, transcript.Entry{Kind: transcript.KindAssistant, Content: message, Success: true})
+	ism.appendMessage(sessionID, "assistant", message)
+	logger.AuditContext(ctx, logger.AuditEvent{Type: logger.AuditAssistant, Model: ism.modelName, Content: message, Success: true})
+}
+
+// recordToolSteps はツール実行フローの各ステップを会話記録に残す（監査ログは auditToolSteps）
+func (ism *interactiveSessionManager) recordToolSteps(sessionID string, steps []tools.ExecutionStep) {
+	entries := make([]transcript.Entry, 0, len(steps))
+	for _, step := range steps {
+		entry := transcript.Entry{
+			Kind:      transcript.KindTool,
+			Timestamp: step.StartTime,
+			Tool:      step.Tool,
+			Success:   step.Success,
+		}
+		if filePath, ok := step.Parameters["file_path"].(string); ok {
+			entry.Path = filePath
+		}
+		if command, ok := step.Parameters["command"].(string); ok {
+			entry.Command = command
+		}
+		if step.Result != nil {
+			entry.Content = step.Result.Content
+			entry.Error = step.Result.Error
+		}
+		entries = append(entries, entry)
+	}
+	ism.appendTranscript(sessionID, entries...)
+}
+
+// recordCommand は !command の実行結果を会話記録に残す
+func (ism *interactiveSessionManager) recordCommand(sessionID, command string, result *tools.ToolExecutionResult, err error) {
+	entry := transcript.Entry{Kind: transcript.KindCommand, Command: command, Success: err == nil}
+	if result != nil {
+		entry.Content = result.Content
+		entry.ExitCode = result.ExitCode
+		entry.Success = err == nil && result.ExitCode == 0 && !result.TimedOut
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	ism.appendTranscript(sessionID, entry)
+}
+
+// recordTask はビルド・テスト・リントの実行結果を会話記録に残す
+func (ism *interactiveSessionManager) recordTask(sessionID string, result *tasks.Result) {
+	ism.appendTranscript(sessionID, transcript.Entry{
+		Kind:     transcript.KindCommand,
+		Tool:     string(result.Kind),
+		Command:  result.Command,
+		Content:  tasks.FormatFailures(result),
+		ExitCode: result.ExitCode,
+		Success:  result.Success,
+	})
+}
+
+// recordTurnDiff はターン中のワークスペース変更を会話記録と監査ログに残す
+func (ism *interactiveSessionManager) recordTurnDiff(sessionID, patch string) {
+	if patch == "" {
+		return
+	}
+	ism.appendTranscript(sessionID, transcript.Entry{Kind: transcript.KindDiff, Content: patch, Success: true})
+	logger.Audit(logger.AuditEvent{SessionID: sessionID, Type: logger.AuditDiff, Content: patch, Success: true})
+}
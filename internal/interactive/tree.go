@@ -0,0 +1,69 @@
+package interactive
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// treeTagPattern は応答中のディレクトリツリーの要求（<TREE>internal depth=2</TREE> 等）
+var treeTagPattern = regexp.MustCompile(`<TREE>(.*?)</TREE>`)
+
+// executeTreeTags は応答中の <TREE> を実行し、除外パターンを適用したディレクトリツリーを簡潔な形式で返す
+// 引数はパス（省略時は作業ディレクトリ）、depth=N（辿る深さ）、max=N（最大件数）、hidden（隠しファイルも含める）
+func (ism *interactiveSessionManager) executeTreeTags(ctx context.Context, llmResponse string) ([]string, []string) {
+	var results, actions []string
+	for _, match := range treeTagPattern.FindAllStringSubmatch(llmResponse, -1) {
+		path, opts := parseTreeSpec(match[1])
+		actions = append(actions, fmt.Sprintf("ディレクトリツリー: %s", path))
+		if err := budget.UseTool(ctx, "tree: "+path); err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ディレクトリツリーの作成エラー: %v", err))
+			continue
+		}
+
+		resolved, err := security.NewPathJail(".").ResolveDir(path)
+		if err == nil && ism.permissions != nil {
+			err = ism.permissions.CheckPath(resolved, "read")
+		}
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ディレクトリツリーの作成エラー (%s): %v", path, err))
+			continue
+		}
+		tree, err := tools.BuildTree(resolved, opts)
+		if err != nil {
+			results = append(results, fmt.Sprintf("⚠️ ディレクトリツリーの作成エラー (%s): %v", path, err))
+			continue
+		}
+		results = append(results, fmt.Sprintf("🌳 %s:\n%s", path, tree.Compact()))
+	}
+	return results, actions
+}
+
+// parseTreeSpec は <TREE> の引数をパスとツリーの作り方にする（未知の語はパスとして扱う）
+func parseTreeSpec(spec string) (string, tools.TreeOptions) {
+	path := "."
+	var opts tools.TreeOptions
+	for _, word := range strings.Fields(spec) {
+		switch {
+		case strings.HasPrefix(word, "depth="):
+			if depth, err := strconv.Atoi(strings.TrimPrefix(word, "depth=")); err == nil && depth > 0 {
+				opts.Depth = depth
+			}
+		case strings.HasPrefix(word, "max="):
+			if limit, err := strconv.Atoi(strings.TrimPrefix(word, "max=")); err == nil && limit > 0 {
+				opts.MaxEntries = limit
+			}
+		case word == "hidden":
+			opts.ShowHidden = true
+		default:
+			path = word
+		}
+	}
+	return path, opts
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/templates"
 )
 
 // インタラクティブセッションの状態
@@ -33,6 +34,7 @@ const (
 // インタラクティブセッション
 type InteractiveSession struct {
 	ID                string                        `json:"id"`
+	Title             string                        `json:"title,omitempty"` // 最初のやり取りから生成、または /title で設定した題名
 	State             SessionState                  `json:"state"`
 	Type              CodingSessionType             `json:"type"`
 	StartTime         time.Time                     `json:"start_time"`
@@ -46,6 +48,8 @@ type InteractiveSession struct {
 	SessionMetadata   map[string]string             `json:"session_metadata"`
 	Metrics           *SessionMetrics               `json:"metrics"`
 	LastCommandOutput string                        `json:"last_command_output,omitempty"` // 最後のコマンド実行結果
+	Template          *templates.Template           `json:"template,omitempty"`            // 開始時に選んだセッションテンプレート
+	PinnedFiles       []string                      `json:"pinned_files,omitempty"`        // /pin で固定したファイル（毎ターン最新の内容をプロンプトに含める）
 }
 
 // コード提案
@@ -63,6 +67,8 @@ type CodeSuggestion struct {
 	CreatedAt     time.Time         `json:"created_at"`
 	UserConfirmed bool              `json:"user_confirmed"`
 	Applied       bool              `json:"applied"`
+
+	base string // 作成時の対象ファイルの内容（適用時の3者比較用、保存しない）
 }
 
 // 提案の種類
@@ -150,6 +156,7 @@ type InteractionResponse struct {
 	ContextUpdate        []*contextmanager.ContextItem `json:"context_update,omitempty"`
 	RequiresConfirmation bool                          `json:"requires_confirmation"`
 	Metadata             map[string]string             `json:"metadata"`
+	Analysis             *AnalysisReport               `json:"analysis,omitempty"` // ターン中に行ったプロジェクト分析
 	GeneratedAt          time.Time                     `json:"generated_at"`
 }
 
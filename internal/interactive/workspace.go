@@ -0,0 +1,30 @@
+package interactive
+
+import (
+	"fmt"
+	"time"
+)
+
+// EnterWorkspace はモジュール切替（/workspace）後の作業ディレクトリをセッションに反映する
+// プロアクティブ分析の対象を移し、以降の応答のために切替をコンテキストへ残す
+func (ism *interactiveSessionManager) EnterWorkspace(sessionID, module, dir string) error {
+	session, err := ism.GetSession(sessionID)
+	if err != nil {
+		return err
+	}
+
+	ism.mu.Lock()
+	if session.SessionMetadata == nil {
+		session.SessionMetadata = make(map[string]string)
+	}
+	session.SessionMetadata["workspace_module"] = module
+	session.LastActivity = time.Now()
+	ism.mu.Unlock()
+
+	if ism.proactiveExt != nil {
+		ism.proactiveExt.SetProjectPath(dir)
+	}
+
+	ism.addToSmartContext(sessionID, fmt.Sprintf("作業範囲をモジュール %s (%s) に切り替えました。以降のファイル操作・ビルド・テスト・分析はこのディレクトリが基準です", module, dir), "workspace")
+	return nil
+}
@@ -0,0 +1,270 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// ジョブ毎に保持する出力の上限
+const DefaultBufferBytes = 64 * 1024
+
+// 停止要求後に強制終了するまでの猶予
+const killGracePeriod = 3 * time.Second
+
+// Status はジョブの状態
+type Status string
+
+const (
+	StatusRunning Status = "running" // 実行中
+	StatusExited  Status = "exited"  // 終了（終了コードはExitCode）
+	StatusKilled  Status = "killed"  // 停止要求により終了
+)
+
+// Info はジョブの状態のスナップショット
+type Info struct {
+	ID          int       `json:"id"`
+	Command     string    `json:"command"`
+	Status      Status    `json:"status"`
+	ExitCode    int       `json:"exit_code"`
+	PID         int       `json:"pid"`
+	StartedAt   time.Time `json:"started_at"`
+	EndedAt     time.Time `json:"ended_at,omitempty"`
+	OutputBytes int64     `json:"output_bytes"` // これまでの総出力バイト数
+}
+
+// job は実行中・終了済みのバックグラウンドプロセス
+type job struct {
+	info   Info
+	cmd    *exec.Cmd
+	cancel context.CancelFunc
+	output *RingBuffer
+	done   chan struct{}
+	killed bool
+}
+
+// Manager はセッションから起動したバックグラウンドジョブの一覧を管理
+// 開発サーバーやウォッチャーのように終了しないコマンドをタイムアウトなしで実行する
+type Manager struct {
+	mu          sync.Mutex
+	jobs        map[int]*job
+	nextID      int
+	backend     sandbox.Backend
+	constraints *security.Constraints
+	workDir     string
+	bufferBytes int
+}
+
+// NewManager はジョブ管理を作成（backendがnilならホストで実行）
+func NewManager(backend sandbox.Backend, constraints *security.Constraints, workDir string) *Manager {
+	if backend == nil {
+		backend = sandbox.NewHostBackend()
+	}
+	return &Manager{
+		jobs:        make(map[int]*job),
+		nextID:      1,
+		backend:     backend,
+		constraints: constraints,
+		workDir:     workDir,
+		bufferBytes: DefaultBufferBytes,
+	}
+}
+
+// Start はコマンドをバックグラウンドで起動（セキュリティ制約を適用）
+func (m *Manager) Start(command string) (Info, error) {
+	if m.constraints != nil {
+		if err := m.constraints.ValidateCommand(command); err != nil {
+			return Info{}, fmt.Errorf("コマンド実行拒否: %w", err)
+		}
+	}
+
+	workDir := m.workDir
+	if m.constraints != nil {
+		resolved, err := m.constraints.ResolveWorkDir(workDir)
+		if err != nil {
+			return Info{}, fmt.Errorf("作業ディレクトリエラー: %w", err)
+		}
+		workDir = resolved
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd, err := m.backend.Command(ctx, command, workDir)
+	if err != nil {
+		cancel()
+		return Info{}, fmt.Errorf("実行バックエンドエラー: %w", err)
+	}
+
+	output := NewRingBuffer(m.bufferBytes)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return Info{}, fmt.Errorf("コマンド開始エラー: %w", err)
+	}
+
+	m.mu.Lock()
+	j := &job{
+		info: Info{
+			ID:        m.nextID,
+			Command:   command,
+			Status:    StatusRunning,
+			PID:       cmd.Process.Pid,
+			StartedAt: time.Now(),
+		},
+		cmd:    cmd,
+		cancel: cancel,
+		output: output,
+		done:   make(chan struct{}),
+	}
+	m.jobs[j.info.ID] = j
+	m.nextID++
+	info := j.info
+	m.mu.Unlock()
+
+	go m.wait(j)
+	return info, nil
+}
+
+// wait はプロセスの終了を待って状態を更新
+func (m *Manager) wait(j *job) {
+	err := j.cmd.Wait()
+
+	m.mu.Lock()
+	j.info.EndedAt = time.Now()
+	j.info.Status = StatusExited
+	if j.killed {
+		j.info.Status = StatusKilled
+	}
+	if err != nil {
+		j.info.ExitCode = -1
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			j.info.ExitCode = exitErr.ExitCode()
+		}
+	}
+	m.mu.Unlock()
+
+	j.cancel()
+	close(j.done)
+}
+
+// List はジョブをID順に返す
+func (m *Manager) List() []Info {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	infos := make([]Info, 0, len(m.jobs))
+	for _, j := range m.jobs {
+		info := j.info
+		info.OutputBytes = j.output.Written()
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, k int) bool { return infos[i].ID < infos[k].ID })
+	return infos
+}
+
+// Get はジョブの状態を返す
+func (m *Manager) Get(id int) (Info, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return Info{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	info := j.info
+	info.OutputBytes = j.output.Written()
+	return info, nil
+}
+
+// Output はoffset（総出力バイト数）以降の出力と次回のoffsetを返す
+// 保持上限を超えて捨てられた出力がある場合は先頭にその旨を付ける
+func (m *Manager) Output(id int, offset int64) (string, int64, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return "", offset, err
+	}
+	data, next, dropped := j.output.ReadFrom(offset)
+	text := string(data)
+	if dropped > 0 {
+		text = fmt.Sprintf("...(%d bytes dropped)\n", dropped) + text
+	}
+	return text, next, nil
+}
+
+// Tail は直近の出力を最大maxBytes返す
+func (m *Manager) Tail(id int, maxBytes int) (string, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return "", err
+	}
+	return string(j.output.Tail(maxBytes)), nil
+}
+
+// Kill はジョブを停止（猶予後も終了しなければ強制終了）し、終了を待つ
+func (m *Manager) Kill(id int) (Info, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return Info{}, err
+	}
+
+	select {
+	case <-j.done:
+		return m.Get(id)
+	default:
+	}
+
+	m.mu.Lock()
+	j.killed = true
+	m.mu.Unlock()
+
+	terminateProcess(j.cmd)
+	select {
+	case <-j.done:
+	case <-time.After(killGracePeriod):
+		killProcess(j.cmd)
+		j.cancel()
+		<-j.done
+	}
+	return m.Get(id)
+}
+
+// StopAll は実行中のジョブをすべて停止（セッション終了時）
+func (m *Manager) StopAll() {
+	for _, info := range m.List() {
+		if info.Status == StatusRunning {
+			m.Kill(info.ID)
+		}
+	}
+}
+
+// Wait はジョブの終了を最大timeoutまで待つ（終了していればtrue）
+func (m *Manager) Wait(id int, timeout time.Duration) (bool, error) {
+	j, err := m.lookup(id)
+	if err != nil {
+		return false, err
+	}
+	select {
+	case <-j.done:
+		return true, nil
+	case <-time.After(timeout):
+		return false, nil
+	}
+}
+
+// lookup はIDからジョブを取得
+func (m *Manager) lookup(id int) (*job, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	j, exists := m.jobs[id]
+	if !exists {
+		return nil, fmt.Errorf("ジョブ %d が見つかりません", id)
+	}
+	return j, nil
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+func TestRingBuffer(t *testing.T) {
+	rb := NewRingBuffer(8)
+	rb.Write([]byte("hello "))
+	rb.Write([]byte("world"))
+
+	if got := string(rb.Tail(0)); got != "lo world" {
+		t.Errorf("Tail = %q, want last 8 bytes", got)
+	}
+	if rb.Written() != 11 {
+		t.Errorf("Written = %d", rb.Written())
+	}
+
+	// 捨てられた範囲を要求すると欠落分が返る
+	data, next, dropped := rb.ReadFrom(0)
+	if string(data) != "lo world" || next != 11 || dropped != 3 {
+		t.Errorf("ReadFrom(0) = %q, %d, %d", data, next, dropped)
+	}
+	data, next, dropped = rb.ReadFrom(9)
+	if string(data) != "ld" || next != 11 || dropped != 0 {
+		t.Errorf("ReadFrom(9) = %q, %d, %d", data, next, dropped)
+	}
+
+	rb.Write([]byte("0123456789"))
+	if got := string(rb.Tail(4)); got != "6789" {
+		t.Errorf("Tail(4) = %q", got)
+	}
+}
+
+func TestManagerRunsJobToCompletion(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	manager := NewManager(nil, nil, t.TempDir())
+
+	info, err := manager.Start("echo started; echo oops >&2; exit 3")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done, _ := manager.Wait(info.ID, 5*time.Second); !done {
+		t.Fatal("job did not finish")
+	}
+
+	info, _ = manager.Get(info.ID)
+	if info.Status != StatusExited || info.ExitCode != 3 {
+		t.Errorf("unexpected state: %+v", info)
+	}
+	output, next, err := manager.Output(info.ID, 0)
+	if err != nil || !strings.Contains(output, "started") || !strings.Contains(output, "oops") {
+		t.Errorf("Output = %q, %v", output, err)
+	}
+	if rest, _, _ := manager.Output(info.ID, next); rest != "" {
+		t.Errorf("no new output expected after offset, got %q", rest)
+	}
+}
+
+func TestManagerKillsLongRunningJob(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a POSIX shell")
+	}
+	manager := NewManager(nil, nil, t.TempDir())
+
+	info, err := manager.Start("echo ready; sleep 30")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if done, _ := manager.Wait(info.ID, 200*time.Millisecond); done {
+		t.Fatal("job should still be running")
+	}
+	if list := manager.List(); len(list) != 1 || list[0].Status != StatusRunning {
+		t.Fatalf("List = %+v", list)
+	}
+
+	start := time.Now()
+	info, err = manager.Kill(info.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Status != StatusKilled {
+		t.Errorf("status = %s, want killed", info.Status)
+	}
+	if time.Since(start) > killGracePeriod+time.Second {
+		t.Errorf("kill took too long: %s", time.Since(start))
+	}
+
+	if _, err := manager.Get(99); err == nil {
+		t.Error("unknown job should return an error")
+	}
+}
+
+func TestManagerAppliesConstraints(t *testing.T) {
+	manager := NewManager(nil, security.NewDefaultConstraints("."), ".")
+	if _, err := manager.Start("rm -rf /tmp/x"); err == nil {
+		t.Error("blocked command should be rejected")
+	}
+}
@@ -0,0 +1,31 @@
+//go:build !windows
+// +build !windows
+
+package jobs
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// setProcessGroup は子プロセスごと停止できるよう新しいプロセスグループで起動
+func setProcessGroup(cmd *exec.Cmd) {
+	if cmd.SysProcAttr == nil {
+		cmd.SysProcAttr = &syscall.SysProcAttr{}
+	}
+	cmd.SysProcAttr.Setpgid = true
+}
+
+// terminateProcess はプロセスグループにSIGTERMを送る
+func terminateProcess(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGTERM)
+	}
+}
+
+// killProcess はプロセスグループを強制終了
+func killProcess(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+}
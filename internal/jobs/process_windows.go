@@ -0,0 +1,21 @@
+//go:build windows
+// +build windows
+
+package jobs
+
+import "os/exec"
+
+// setProcessGroup はWindowsでは何もしない
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// terminateProcess はWindowsでは穏当な停止手段がないため強制終了
+func terminateProcess(cmd *exec.Cmd) {
+	killProcess(cmd)
+}
+
+// killProcess はプロセスを強制終了
+func killProcess(cmd *exec.Cmd) {
+	if cmd.Process != nil {
+		cmd.Process.Kill()
+	}
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import "sync"
+
+// RingBuffer は直近の出力のみを保持する固定長バッファ（古い出力から捨てる）
+type RingBuffer struct {
+	mu      sync.Mutex
+	data    []byte
+	size    int
+	written int64 // これまでに書き込まれた総バイト数
+}
+
+// NewRingBuffer は指定サイズのリングバッファを作成
+func NewRingBuffer(size int) *RingBuffer {
+	return &RingBuffer{data: make([]byte, 0, size), size: size}
+}
+
+// Write は出力を追記し、上限を超えた分を先頭から捨てる
+func (rb *RingBuffer) Write(p []byte) (int, error) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	rb.written += int64(len(p))
+	if len(p) >= rb.size {
+		rb.data = append(rb.data[:0], p[len(p)-rb.size:]...)
+		return len(p), nil
+	}
+	if overflow := len(rb.data) + len(p) - rb.size; overflow > 0 {
+		rb.data = append(rb.data[:0], rb.data[overflow:]...)
+	}
+	rb.data = append(rb.data, p...)
+	return len(p), nil
+}
+
+// Written はこれまでに書き込まれた総バイト数
+func (rb *RingBuffer) Written() int64 {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+	return rb.written
+}
+
+// ReadFrom は総バイト数で表したoffset以降の出力と次回のoffsetを返す
+// 既に捨てられた範囲は読めないため、dropped に欠落したバイト数を返す
+func (rb *RingBuffer) ReadFrom(offset int64) (data []byte, next int64, dropped int64) {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	oldest := rb.written - int64(len(rb.data))
+	if offset < oldest {
+		dropped = oldest - offset
+		offset = oldest
+	}
+	if offset > rb.written {
+		offset = rb.written
+	}
+	data = append([]byte{}, rb.data[offset-oldest:]...)
+	return data, rb.written, dropped
+}
+
+// Tail は末尾から最大maxBytesの出力を返す
+func (rb *RingBuffer) Tail(maxBytes int) []byte {
+	rb.mu.Lock()
+	defer rb.mu.Unlock()
+
+	data := rb.data
+	if maxBytes > 0 && len(data) > maxBytes {
+		data = data[len(data)-maxBytes:]
+	}
+	return append([]byte{}, data...)
+}
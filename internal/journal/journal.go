@@ -0,0 +1,319 @@
+package journal
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// トランザクションの状態
+const (
+	StateOpen       = "open"
+	StateCommitted  = "committed"
+	StateRolledBack = "rolled_back"
+	StateUndone     = "undone"
+)
+
+// Entry はトランザクションで書き換えた1ファイルの変更前の状態
+type Entry struct {
+	Path     string      `json:"path"` // 絶対パス
+	Existed  bool        `json:"existed"`
+	Before   []byte      `json:"before,omitempty"`
+	Mode     os.FileMode `json:"mode,omitempty"`
+	Link     string      `json:"link,omitempty"`    // シンボリックリンクだった場合のリンク先（リンク自体を記録・復元する）
+	AfterSum string      `json:"after_sum"`         // 書き込んだ内容のSHA-256（取り消し時の競合検出用）
+	Removed  bool        `json:"removed,omitempty"` // トランザクションで削除した（取り消し時は存在しないことを確認）
+}
+
+// Transaction は複数ファイルの書き換えを1単位として記録し、まとめて取り消せるようにする
+type Transaction struct {
+	ID          string    `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+	State       string    `json:"state"`
+	Entries     []*Entry  `json:"entries"`
+
+	dir   string
+	index map[string]*Entry
+}
+
+// DefaultDir はジャーナルの保存先（~/.vyb/journal）
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".vyb", "journal")
+	}
+	return filepath.Join(home, ".vyb", "journal")
+}
+
+// Begin はトランザクションを開始（Commit するまでジャーナルには保存しない）
+func Begin(dir, description string) *Transaction {
+	now := time.Now()
+	return &Transaction{
+		ID:          now.Format("20060102-150405.000"),
+		Description: description,
+		CreatedAt:   now,
+		State:       StateOpen,
+		dir:         dir,
+		index:       make(map[string]*Entry),
+	}
+}
+
+// Write はファイルの変更前の状態を記録してから書き込む
+func (t *Transaction) Write(path string, content []byte) error {
+	entry, err := t.track(path)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(entry.Path), 0755); err != nil {
+		return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	}
+	if err := os.WriteFile(entry.Path, content, entry.Mode); err != nil {
+		return fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+	entry.AfterSum = checksum(content)
+	entry.Removed = false
+	return nil
+}
+
+// Track は移動・削除など Write 以外の方法（git mv 等）で変更するファイルの変更前の状態を記録する
+// 変更後の状態は Commit の時点のファイルから記録する
+func (t *Transaction) Track(path string) error {
+	_, err := t.track(path)
+	return err
+}
+
+// track はファイルの変更前の状態を記録（記録済みならそのエントリを返す）
+func (t *Transaction) track(path string) (*Entry, error) {
+	if t.State != StateOpen {
+		return nil, fmt.Errorf("トランザクションは終了しています: %s", t.State)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, ok := t.index[abs]
+	if !ok {
+		entry = &Entry{Path: abs, Mode: 0644}
+		if info, err := os.Lstat(abs); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			if entry.Link, err = os.Readlink(abs); err != nil {
+				return nil, fmt.Errorf("変更前のリンクの読み込みエラー: %w", err)
+			}
+			entry.Existed = true
+		} else if err == nil {
+			before, err := os.ReadFile(abs)
+			if err != nil {
+				return nil, fmt.Errorf("変更前の内容の読み込みエラー: %w", err)
+			}
+			entry.Existed = true
+			entry.Before = before
+			entry.Mode = info.Mode().Perm()
+		}
+		t.index[abs] = entry
+		t.Entries = append(t.Entries, entry)
+	}
+	return entry, nil
+}
+
+// Files は書き換えたファイルの一覧
+func (t *Transaction) Files() []string {
+	files := make([]string, 0, len(t.Entries))
+	for _, entry := range t.Entries {
+		files = append(files, entry.Path)
+	}
+	return files
+}
+
+// Rollback は未確定の書き換えをすべて元に戻す
+func (t *Transaction) Rollback() error {
+	if t.State != StateOpen {
+		return fmt.Errorf("トランザクションは終了しています: %s", t.State)
+	}
+	if err := restore(t.Entries); err != nil {
+		return err
+	}
+	t.State = StateRolledBack
+	return nil
+}
+
+// Commit は書き換えを確定し、後で取り消せるようジャーナルに保存する
+func (t *Transaction) Commit() error {
+	if t.State != StateOpen {
+		return fmt.Errorf("トランザクションは終了しています: %s", t.State)
+	}
+	// Track したファイルの変更後の状態を記録
+	for _, entry := range t.Entries {
+		if entry.AfterSum != "" || entry.Removed {
+			continue
+		}
+		if sum, err := currentSum(entry.Path); err == nil {
+			entry.AfterSum = sum
+		} else {
+			entry.Removed = true
+		}
+	}
+	t.State = StateCommitted
+	return t.save()
+}
+
+// Undo は確定済みのトランザクションを取り消す
+// 確定後に別の変更が加えられたファイルがあれば、force でない限り何も戻さずにエラーにする
+func (t *Transaction) Undo(force bool) error {
+	if t.State != StateCommitted {
+		return fmt.Errorf("取り消せるのは確定済みのトランザクションのみです: %s", t.State)
+	}
+	if !force {
+		var changed []string
+		for _, entry := range t.Entries {
+			sum, err := currentSum(entry.Path)
+			if entry.Removed {
+				if err == nil {
+					changed = append(changed, entry.Path)
+				}
+				continue
+			}
+			if err != nil || sum != entry.AfterSum {
+				changed = append(changed, entry.Path)
+			}
+		}
+		if len(changed) > 0 {
+			return fmt.Errorf("確定後に変更されたファイルがあります（--force で上書き）: %s", strings.Join(changed, ", "))
+		}
+	}
+	if err := restore(t.Entries); err != nil {
+		return err
+	}
+	t.State = StateUndone
+	return t.save()
+}
+
+// save はトランザクションをジャーナルに保存
+func (t *Transaction) save() error {
+	if err := os.MkdirAll(t.dir, 0700); err != nil {
+		return fmt.Errorf("ジャーナルディレクトリ作成エラー: %w", err)
+	}
+	data, err := json.MarshalIndent(t, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(t.dir, t.ID+".json"), data, 0600); err != nil {
+		return fmt.Errorf("ジャーナル保存エラー: %w", err)
+	}
+	return nil
+}
+
+// List はジャーナルのトランザクションを新しい順に返す
+func List(dir string) ([]*Transaction, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var transactions []*Transaction
+	for _, path := range paths {
+		t, err := load(dir, path)
+		if err != nil {
+			continue
+		}
+		transactions = append(transactions, t)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		return transactions[i].CreatedAt.After(transactions[j].CreatedAt)
+	})
+	return transactions, nil
+}
+
+// Load はIDのトランザクションを読み込む（"latest" は取り消していない最新のもの）
+func Load(dir, id string) (*Transaction, error) {
+	if id == "" || id == "latest" {
+		transactions, err := List(dir)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range transactions {
+			if t.State == StateCommitted {
+				return t, nil
+			}
+		}
+		return nil, fmt.Errorf("取り消せるトランザクションがありません")
+	}
+	t, err := load(dir, filepath.Join(dir, id+".json"))
+	if err != nil {
+		return nil, fmt.Errorf("トランザクション %s が見つかりません: %w", id, err)
+	}
+	return t, nil
+}
+
+func load(dir, path string) (*Transaction, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var t Transaction
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, err
+	}
+	t.dir = dir
+	t.index = make(map[string]*Entry)
+	for _, entry := range t.Entries {
+		t.index[entry.Path] = entry
+	}
+	return &t, nil
+}
+
+// restore は変更前の状態を逆順に復元（存在しなかったファイルは削除、移動・削除したファイルはディレクトリごと作り直す）
+func restore(entries []*Entry) error {
+	var failed []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		var err error
+		if entry.Existed && entry.Link != "" {
+			if err = os.MkdirAll(filepath.Dir(entry.Path), 0755); err == nil {
+				if err = os.Remove(entry.Path); err == nil || os.IsNotExist(err) {
+					err = os.Symlink(entry.Link, entry.Path)
+				}
+			}
+		} else if entry.Existed {
+			if err = os.MkdirAll(filepath.Dir(entry.Path), 0755); err == nil {
+				err = os.WriteFile(entry.Path, entry.Before, entry.Mode)
+			}
+		} else if err = os.Remove(entry.Path); os.IsNotExist(err) {
+			err = nil
+		}
+		if err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %v", entry.Path, err))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("復元に失敗したファイルがあります: %s", strings.Join(failed, "; "))
+	}
+	return nil
+}
+
+// currentSum は現在のファイルの内容（シンボリックリンクならリンク先のパス）のSHA-256
+func currentSum(path string) (string, error) {
+	if info, err := os.Lstat(path); err == nil && info.Mode()&os.ModeSymlink != 0 {
+		target, err := os.Readlink(path)
+		if err != nil {
+			return "", err
+		}
+		return checksum([]byte("symlink:" + target)), nil
+	}
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return checksum(content), nil
+}
+
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,177 @@
+package journal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRollback(t *testing.T) {
+	dir := t.TempDir()
+	journalDir := filepath.Join(dir, "journal")
+	existing := filepath.Join(dir, "a.txt")
+	created := filepath.Join(dir, "sub", "b.txt")
+	if err := os.WriteFile(existing, []byte("original\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := Begin(journalDir, "rename")
+	for _, write := range []struct{ path, content string }{
+		{existing, "first\n"},
+		{existing, "second\n"},
+		{created, "new\n"},
+	} {
+		if err := tx.Write(write.path, []byte(write.content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if len(tx.Files()) != 2 {
+		t.Errorf("同じファイルは1回だけ記録するはず: %v", tx.Files())
+	}
+
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, existing); got != "original\n" {
+		t.Errorf("元の内容に戻るはず: %q", got)
+	}
+	if info, _ := os.Stat(existing); info.Mode().Perm() != 0600 {
+		t.Errorf("パーミッションを保持するはず: %v", info.Mode())
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Errorf("新規作成したファイルは削除されるはず: %v", err)
+	}
+	if _, err := os.Stat(journalDir); !os.IsNotExist(err) {
+		t.Errorf("ロールバックしたトランザクションは保存しないはず")
+	}
+	if err := tx.Write(existing, []byte("x")); err == nil {
+		t.Error("終了したトランザクションには書き込めないはず")
+	}
+}
+
+func TestCommitAndUndo(t *testing.T) {
+	dir := t.TempDir()
+	journalDir := filepath.Join(dir, "journal")
+	path := filepath.Join(dir, "a.txt")
+	if err := os.WriteFile(path, []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tx := Begin(journalDir, "rename Foo to Bar")
+	if err := tx.Write(path, []byte("changed\n")); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	latest, err := Load(journalDir, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.ID != tx.ID || latest.Description != "rename Foo to Bar" {
+		t.Errorf("最新のトランザクションを読み込むはず: %+v", latest)
+	}
+
+	// 確定後に編集されていれば取り消さない
+	if err := os.WriteFile(path, []byte("edited\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := latest.Undo(false); err == nil || !strings.Contains(err.Error(), "a.txt") {
+		t.Errorf("競合を検出するはず: %v", err)
+	}
+	if got := readFile(t, path); got != "edited\n" {
+		t.Errorf("競合時は何も戻さないはず: %q", got)
+	}
+
+	if err := latest.Undo(true); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, path); got != "original\n" {
+		t.Errorf("取り消しで元に戻るはず: %q", got)
+	}
+
+	transactions, err := List(journalDir)
+	if err != nil || len(transactions) != 1 || transactions[0].State != StateUndone {
+		t.Errorf("取り消し済みとして保存されるはず: %+v, %v", transactions, err)
+	}
+	if _, err := Load(journalDir, "latest"); err == nil {
+		t.Error("取り消し済みのみなら latest はないはず")
+	}
+}
+
+func TestTrackMoveAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	journalDir := filepath.Join(dir, "journal")
+	source := filepath.Join(dir, "old", "a.txt")
+	moved := filepath.Join(dir, "new", "a.txt")
+	deleted := filepath.Join(dir, "b.txt")
+	for path, content := range map[string]string{source: "a\n", deleted: "b\n"} {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx := Begin(journalDir, "move and delete")
+	for _, path := range []string{source, moved, deleted} {
+		if err := tx.Track(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(moved), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(source, moved); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.RemoveAll(filepath.Dir(source)); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(deleted); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 削除したファイルが作り直されていれば競合として取り消さない
+	if err := os.WriteFile(deleted, []byte("recreated\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := Load(journalDir, "latest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Undo(false); err == nil || !strings.Contains(err.Error(), "b.txt") {
+		t.Fatalf("作り直したファイルは競合になるはず: %v", err)
+	}
+	if err := os.Remove(deleted); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := loaded.Undo(false); err != nil {
+		t.Fatal(err)
+	}
+	if got := readFile(t, source); got != "a\n" {
+		t.Errorf("移動元が復元されていない: %q", got)
+	}
+	if got := readFile(t, deleted); got != "b\n" {
+		t.Errorf("削除したファイルが復元されていない: %q", got)
+	}
+	if _, err := os.Stat(moved); !os.IsNotExist(err) {
+		t.Error("移動先は取り消しで削除されるはず")
+	}
+}
@@ -0,0 +1,273 @@
+// Package knowledge はプロジェクトの言語・フレームワーク（React+TypeScript、cobra を使う Go CLI 等）を検出し、
+// 該当する指針（慣用句・テストフレームワーク・ディレクトリ構成）をプロンプトに加える
+// 組み込みの指針に加えて .vyb/knowledge/<name>.yaml でプロジェクト毎に追加・上書きできる
+package knowledge
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/glkt/vyb-code/internal/analysis"
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// Guide は .vyb/knowledge/<name>.yaml の言語・フレームワーク別の指針
+type Guide struct {
+	Name        string    `yaml:"name" json:"name"`
+	Description string    `yaml:"description,omitempty" json:"description,omitempty"`
+	Detect      Detection `yaml:"detect" json:"detect"`
+	Guidance    string    `yaml:"guidance" json:"guidance"` // プロンプトに加える指針
+
+	Path string `yaml:"-" json:"path,omitempty"` // 読み込んだファイル（組み込みなら空）
+}
+
+// Detection は指針を適用するプロジェクトの条件（指定した条件を全て満たせば適用）
+type Detection struct {
+	Files        []string `yaml:"files,omitempty" json:"files,omitempty"`               // いずれかに一致するファイルがある（glob）
+	Dependencies []string `yaml:"dependencies,omitempty" json:"dependencies,omitempty"` // いずれかのパッケージに依存している
+}
+
+// Source は表示用の定義元
+func (g *Guide) Source() string {
+	if g.Path == "" {
+		return "built-in"
+	}
+	return g.Path
+}
+
+// Framework は依存関係で検出する（言語だけでなくフレームワークの）指針か
+func (g *Guide) Framework() bool {
+	return len(g.Detect.Dependencies) > 0
+}
+
+// Validate は定義の値を検証する
+func (g *Guide) Validate() error {
+	if g.Name == "" {
+		return fmt.Errorf("指針に name がありません")
+	}
+	if strings.TrimSpace(g.Guidance) == "" {
+		return fmt.Errorf("指針 %s: guidance がありません", g.Name)
+	}
+	if len(g.Detect.Files) == 0 && len(g.Detect.Dependencies) == 0 {
+		return fmt.Errorf("指針 %s: detect に files か dependencies を指定してください", g.Name)
+	}
+	for _, pattern := range g.Detect.Files {
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return fmt.Errorf("指針 %s: 不正な files のパターンです: %s", g.Name, pattern)
+		}
+	}
+	return nil
+}
+
+// Matches はプロジェクトが指針の条件を満たすか
+func (g *Guide) Matches(project *Project) bool {
+	if len(g.Detect.Files) > 0 && !project.hasAnyFile(g.Detect.Files) {
+		return false
+	}
+	if len(g.Detect.Dependencies) > 0 && !project.hasAnyDependency(g.Detect.Dependencies) {
+		return false
+	}
+	return true
+}
+
+// Project は検出に使うプロジェクトの情報（マニフェストから読んだ依存関係）
+type Project struct {
+	Dir          string
+	Dependencies []string
+}
+
+// Scan はプロジェクトの依存関係（package.json, go.mod, requirements.txt, Cargo.toml 等）を読み込む
+func Scan(projectDir string) *Project {
+	project := &Project{Dir: projectDir}
+	deps, _ := analysis.NewProjectAnalyzer(nil).AnalyzeDependencies(projectDir)
+	for _, dep := range deps {
+		project.Dependencies = append(project.Dependencies, strings.ToLower(dep.Name))
+	}
+	return project
+}
+
+func (p *Project) hasAnyFile(patterns []string) bool {
+	for _, pattern := range patterns {
+		if matches, _ := filepath.Glob(filepath.Join(p.Dir, pattern)); len(matches) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasAnyDependency は依存関係のいずれかに一致するか（大文字小文字を区別せず、Go のメジャーバージョン等のサブパスも一致）
+func (p *Project) hasAnyDependency(names []string) bool {
+	for _, name := range names {
+		name = strings.ToLower(name)
+		for _, dep := range p.Dependencies {
+			if dep == name || strings.HasPrefix(dep, name+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Dir はプロジェクトの指針ディレクトリ（.vyb/knowledge）
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, config.ProjectConfigDir, "knowledge")
+}
+
+// Parse はYAMLの指針を解析・検証する（name がなければ defaultName）
+func Parse(data []byte, defaultName string) (*Guide, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var guide Guide
+	if err := decoder.Decode(&guide); err != nil {
+		return nil, fmt.Errorf("指針の解析エラー: %w", err)
+	}
+	if guide.Name == "" {
+		guide.Name = defaultName
+	}
+	guide.Guidance = strings.TrimSpace(guide.Guidance)
+	if err := guide.Validate(); err != nil {
+		return nil, err
+	}
+	return &guide, nil
+}
+
+// Builtin は組み込みの指針を名前順に返す
+func Builtin() []*Guide {
+	entries, _ := builtinFS.ReadDir("builtin")
+	var builtins []*Guide
+	for _, entry := range entries {
+		data, err := builtinFS.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		guide, err := Parse(data, strings.TrimSuffix(entry.Name(), ".yaml"))
+		if err != nil {
+			panic(fmt.Sprintf("組み込みの指針 %s が不正です: %v", entry.Name(), err))
+		}
+		builtins = append(builtins, guide)
+	}
+	return builtins
+}
+
+// List はプロジェクトと組み込みの指針を名前順に返す（同じ名前はプロジェクトの定義を優先、解析できないファイルはエラーとして返す）
+func List(dir string) ([]*Guide, map[string]error) {
+	byName := make(map[string]*Guide)
+	for _, guide := range Builtin() {
+		byName[guide.Name] = guide
+	}
+
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		paths = append(paths, matches...)
+	}
+	invalid := make(map[string]error)
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			invalid[path] = fmt.Errorf("指針の読み込みエラー: %w", err)
+			continue
+		}
+		guide, err := Parse(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if err != nil {
+			invalid[path] = fmt.Errorf("%s: %w", path, err)
+			continue
+		}
+		guide.Path = path
+		byName[guide.Name] = guide
+	}
+
+	list := make([]*Guide, 0, len(byName))
+	for _, guide := range byName {
+		list = append(list, guide)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, invalid
+}
+
+// Detect はプロジェクトに該当する指針を返す（フレームワークの指針を言語の指針より先に、disabled の名前は除く）
+// 解析できない指針のファイルは無視する
+func Detect(projectDir string, disabled []string) []*Guide {
+	guides, _ := List(Dir(projectDir))
+	return Match(guides, Scan(projectDir), disabled)
+}
+
+// Match は指針のうちプロジェクトに該当するものを返す（フレームワーク・名前の順）
+func Match(guides []*Guide, project *Project, disabled []string) []*Guide {
+	var matched []*Guide
+	for _, guide := range guides {
+		if contains(disabled, guide.Name) || !guide.Matches(project) {
+			continue
+		}
+		matched = append(matched, guide)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if matched[i].Framework() != matched[j].Framework() {
+			return matched[i].Framework()
+		}
+		return matched[i].Name < matched[j].Name
+	})
+	return matched
+}
+
+// WriteBuiltin は組み込みの指針を編集用にディレクトリへ書き出す（names が空なら全て、既存のファイルは force なしでは残す）
+// 書き出したファイルと、既にあって残したファイルを返す
+func WriteBuiltin(dir string, names []string, force bool) ([]string, []string, error) {
+	for _, name := range names {
+		if !contains(builtinNames(), name) {
+			return nil, nil, fmt.Errorf("組み込みの指針 %s はありません（%s）", name, strings.Join(builtinNames(), ", "))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("指針ディレクトリの作成エラー: %w", err)
+	}
+
+	var written, skipped []string
+	for _, name := range builtinNames() {
+		if len(names) > 0 && !contains(names, name) {
+			continue
+		}
+		path := filepath.Join(dir, name+".yaml")
+		if _, err := os.Stat(path); err == nil && !force {
+			skipped = append(skipped, path)
+			continue
+		}
+		data, err := builtinFS.ReadFile("builtin/" + name + ".yaml")
+		if err != nil {
+			return written, skipped, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return written, skipped, fmt.Errorf("指針の書き込みエラー: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, skipped, nil
+}
+
+func builtinNames() []string {
+	var names []string
+	for _, guide := range Builtin() {
+		names = append(names, guide.Name)
+	}
+	return names
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+package knowledge
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func names(guides []*Guide) []string {
+	var list []string
+	for _, guide := range guides {
+		list = append(list, guide.Name)
+	}
+	return list
+}
+
+// TestDetect はマニフェストから言語・フレームワークを検出し、フレームワークの指針を先に返すことをテストする
+func TestDetect(t *testing.T) {
+	goDir := t.TempDir()
+	writeFile(t, filepath.Join(goDir, "go.mod"), "module example.com/tool\n\ngo 1.21\n\nrequire (\n\tgithub.com/spf13/cobra v1.8.0\n\tgolang.org/x/sync v0.5.0 // indirect\n)\n")
+	if got := names(Detect(goDir, nil)); len(got) != 2 || got[0] != "go-cobra" || got[1] != "go" {
+		t.Errorf("cobra を使う Go CLI = %v", got)
+	}
+	if got := names(Detect(goDir, []string{"go"})); len(got) != 1 || got[0] != "go-cobra" {
+		t.Errorf("disabled の指針は除くはず: %v", got)
+	}
+
+	webDir := t.TempDir()
+	writeFile(t, filepath.Join(webDir, "package.json"), `{"dependencies": {"react": "^18.2.0"}, "devDependencies": {"typescript": "^5.0.0"}}`)
+	if got := names(Detect(webDir, nil)); len(got) != 0 {
+		t.Errorf("tsconfig.json がなければ React+TypeScript ではないはず: %v", got)
+	}
+	writeFile(t, filepath.Join(webDir, "tsconfig.json"), "{}")
+	if got := names(Detect(webDir, nil)); len(got) != 1 || got[0] != "react-typescript" {
+		t.Errorf("React+TypeScript = %v", got)
+	}
+
+	pyDir := t.TempDir()
+	writeFile(t, filepath.Join(pyDir, "requirements.txt"), "Django>=4.2\npytest\n")
+	if got := names(Detect(pyDir, nil)); len(got) != 1 || got[0] != "django" {
+		t.Errorf("依存関係の名前は大文字小文字を区別しないはず: %v", got)
+	}
+}
+
+// TestProjectGuides は .vyb/knowledge の指針が追加・組み込みの上書きになり、不正な定義はエラーになることをテストする
+func TestProjectGuides(t *testing.T) {
+	projectDir := t.TempDir()
+	dir := Dir(projectDir)
+	written, skipped, err := WriteBuiltin(dir, []string{"go"}, false)
+	if err != nil || len(written) != 1 || len(skipped) != 0 {
+		t.Fatalf("written = %v, skipped = %v, err = %v", written, skipped, err)
+	}
+	if _, _, err := WriteBuiltin(dir, []string{"cobol"}, false); err == nil {
+		t.Error("存在しない組み込みの指針はエラーのはず")
+	}
+
+	writeFile(t, filepath.Join(dir, "go.yaml"), "description: Our Go rules\ndetect:\n  files: [go.mod]\nguidance: Use testify for assertions.\n")
+	writeFile(t, filepath.Join(dir, "echo.yml"), "detect:\n  dependencies: [github.com/labstack/echo]\nguidance: Register handlers in internal/http/routes.go.\n")
+	writeFile(t, filepath.Join(dir, "broken.yaml"), "guidance: no detection\n")
+	writeFile(t, filepath.Join(projectDir, "go.mod"), "module example.com/api\n\nrequire github.com/labstack/echo/v4 v4.11.0\n")
+
+	list, invalid := List(dir)
+	if len(list) != len(Builtin())+1 || len(invalid) != 1 {
+		t.Fatalf("list = %v, invalid = %v", names(list), invalid)
+	}
+	guides := Detect(projectDir, nil)
+	if got := names(guides); len(got) != 2 || got[0] != "echo" || got[1] != "go" {
+		t.Fatalf("メジャーバージョン付きのモジュールも一致するはず: %v", got)
+	}
+	if guides[1].Path == "" || guides[1].Guidance != "Use testify for assertions." {
+		t.Errorf("プロジェクトの定義が組み込みより優先されるはず: %+v", guides[1])
+	}
+}
@@ -0,0 +1,61 @@
+// Package kotlin は Kotlin（Gradle Kotlin DSL）の言語パック
+package kotlin
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func init() {
+	tools.RegisterLanguagePack(Pack{})
+}
+
+var (
+	// dependencyPattern は dependencies ブロックの implementation("group:artifact:version") 等
+	dependencyPattern = regexp.MustCompile(`(?m)^\s*(?:implementation|api|compileOnly|runtimeOnly|testImplementation|testRuntimeOnly|kapt|ksp)\s*\(\s*"([^"]+)"`)
+	versionPattern    = regexp.MustCompile(`(?m)^version\s*=\s*"([^"]+)"`)
+)
+
+// Pack は Kotlin の言語パック
+type Pack struct{}
+
+func (Pack) Name() string         { return "Kotlin" }
+func (Pack) Extensions() []string { return []string{".kt", ".kts"} }
+func (Pack) ManifestFile() string { return "build.gradle.kts" }
+func (Pack) BuildCommand() string { return "./gradlew build" }
+func (Pack) TestCommand() string  { return "./gradlew test" }
+
+// Detect は Gradle の Kotlin DSL（build.gradle.kts, settings.gradle.kts）があれば Kotlin のプロジェクトとみなす
+func (Pack) Detect(projectDir string) bool {
+	for _, name := range []string{"build.gradle.kts", "settings.gradle.kts"} {
+		if _, err := os.Stat(filepath.Join(projectDir, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseManifest は build.gradle.kts の依存宣言を "group:artifact" として読み取る（バージョンは除く）
+func (Pack) ParseManifest(content []byte) (*tools.PackageManifest, error) {
+	manifest := &tools.PackageManifest{Dependencies: []string{}}
+	if match := versionPattern.FindSubmatch(content); match != nil {
+		manifest.Version = string(match[1])
+	}
+	seen := make(map[string]bool)
+	for _, match := range dependencyPattern.FindAllSubmatch(content, -1) {
+		coordinate := strings.Split(string(match[1]), ":")
+		name := coordinate[0]
+		if len(coordinate) >= 2 {
+			name = coordinate[0] + ":" + coordinate[1]
+		}
+		if !seen[name] {
+			seen[name] = true
+			manifest.Dependencies = append(manifest.Dependencies, name)
+		}
+	}
+	return manifest, nil
+}
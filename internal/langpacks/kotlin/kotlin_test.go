@@ -0,0 +1,55 @@
+package kotlin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func TestParseManifest(t *testing.T) {
+	build := `plugins {
+    kotlin("jvm") version "1.9.22"
+}
+
+version = "0.3.0"
+
+dependencies {
+    implementation("io.ktor:ktor-server-core:2.3.7")
+    implementation("io.ktor:ktor-server-core-jvm:2.3.7")
+    testImplementation("org.jetbrains.kotlin:kotlin-test")
+    ksp("com.google.dagger:dagger-compiler:2.48")
+}
+`
+	manifest, err := Pack{}.ParseManifest([]byte(build))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Version != "0.3.0" {
+		t.Errorf("version = %q", manifest.Version)
+	}
+	want := "io.ktor:ktor-server-core,io.ktor:ktor-server-core-jvm,org.jetbrains.kotlin:kotlin-test,com.google.dagger:dagger-compiler"
+	if got := strings.Join(manifest.Dependencies, ","); got != want {
+		t.Errorf("dependencies = %s", got)
+	}
+}
+
+func TestDetectProjectLanguages(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "settings.gradle.kts"), []byte(`rootProject.name = "app"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	languages, err := tools.NewLanguageManager().DetectProjectLanguages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	found := false
+	for _, lang := range languages {
+		found = found || lang.GetName() == "Kotlin"
+	}
+	if !found {
+		t.Error("Gradle の Kotlin DSL があれば Kotlin を検出するはず")
+	}
+}
@@ -0,0 +1,11 @@
+// Package langpacks は組み込みの言語パックをまとめて登録する
+//
+// 言語パックは tools.LanguagePack を実装する独立したパッケージで、init で
+// tools.RegisterLanguagePack を呼ぶ。新しい言語はサブパッケージを追加し、ここで読み込む
+package langpacks
+
+import (
+	_ "github.com/glkt/vyb-code/internal/langpacks/kotlin"
+	_ "github.com/glkt/vyb-code/internal/langpacks/php"
+	_ "github.com/glkt/vyb-code/internal/langpacks/ruby"
+)
@@ -0,0 +1,58 @@
+// Package php は PHP（Composer）の言語パック
+package php
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func init() {
+	tools.RegisterLanguagePack(Pack{})
+}
+
+// Pack は PHP の言語パック
+type Pack struct{}
+
+func (Pack) Name() string         { return "PHP" }
+func (Pack) Extensions() []string { return []string{".php"} }
+func (Pack) ManifestFile() string { return "composer.json" }
+func (Pack) BuildCommand() string { return "composer install" }
+func (Pack) TestCommand() string  { return "vendor/bin/phpunit" }
+
+// Detect は composer.json があれば PHP のプロジェクトとみなす
+func (Pack) Detect(projectDir string) bool {
+	_, err := os.Stat(filepath.Join(projectDir, "composer.json"))
+	return err == nil
+}
+
+// ParseManifest は composer.json の require・require-dev を依存関係として読み取る
+// PHP 本体（php）と拡張（ext-*）はパッケージではないので除く
+func (Pack) ParseManifest(content []byte) (*tools.PackageManifest, error) {
+	var composer struct {
+		Name       string            `json:"name"`
+		Version    string            `json:"version"`
+		Require    map[string]string `json:"require"`
+		RequireDev map[string]string `json:"require-dev"`
+	}
+	if err := json.Unmarshal(content, &composer); err != nil {
+		return nil, fmt.Errorf("composer.json 解析エラー: %w", err)
+	}
+
+	manifest := &tools.PackageManifest{Name: composer.Name, Version: composer.Version, Dependencies: []string{}}
+	for _, requires := range []map[string]string{composer.Require, composer.RequireDev} {
+		for name := range requires {
+			if name == "php" || strings.HasPrefix(name, "ext-") {
+				continue
+			}
+			manifest.Dependencies = append(manifest.Dependencies, name)
+		}
+	}
+	sort.Strings(manifest.Dependencies)
+	return manifest, nil
+}
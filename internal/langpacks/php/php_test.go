@@ -0,0 +1,38 @@
+package php
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func TestParseManifest(t *testing.T) {
+	composer := `{
+  "name": "acme/shop",
+  "version": "1.2.0",
+  "require": {"php": ">=8.1", "ext-json": "*", "laravel/framework": "^10.0", "guzzlehttp/guzzle": "^7.0"},
+  "require-dev": {"phpunit/phpunit": "^10.0"}
+}`
+	manifest, err := Pack{}.ParseManifest([]byte(composer))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if manifest.Name != "acme/shop" || manifest.Version != "1.2.0" {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	if got := strings.Join(manifest.Dependencies, ","); got != "guzzlehttp/guzzle,laravel/framework,phpunit/phpunit" {
+		t.Errorf("dependencies = %s", got)
+	}
+
+	if _, err := (Pack{}).ParseManifest([]byte("{")); err == nil {
+		t.Error("不正なJSONはエラーのはず")
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	lang := tools.NewLanguageManager().DetectLanguage("src/Controller.php")
+	if lang == nil || lang.GetName() != "PHP" || lang.GetDependencyFile() != "composer.json" {
+		t.Errorf("登録した言語パックで検出できるはず: %v", lang)
+	}
+}
@@ -0,0 +1,50 @@
+// Package ruby は Ruby（Bundler）の言語パック
+package ruby
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func init() {
+	tools.RegisterLanguagePack(Pack{})
+}
+
+// gemPattern は Gemfile の gem 宣言
+var gemPattern = regexp.MustCompile(`(?m)^\s*gem\s+["']([^"']+)["']`)
+
+// Pack は Ruby の言語パック
+type Pack struct{}
+
+func (Pack) Name() string         { return "Ruby" }
+func (Pack) Extensions() []string { return []string{".rb", ".rake"} }
+func (Pack) ManifestFile() string { return "Gemfile" }
+func (Pack) BuildCommand() string { return "bundle install" }
+func (Pack) TestCommand() string  { return "bundle exec rake" }
+func (Pack) LintCommand() string  { return "bundle exec rubocop" }
+
+// Detect は Gemfile か *.gemspec があれば Ruby のプロジェクトとみなす
+func (Pack) Detect(projectDir string) bool {
+	if _, err := os.Stat(filepath.Join(projectDir, "Gemfile")); err == nil {
+		return true
+	}
+	specs, _ := filepath.Glob(filepath.Join(projectDir, "*.gemspec"))
+	return len(specs) > 0
+}
+
+// ParseManifest は Gemfile の gem 宣言を依存関係として読み取る
+func (Pack) ParseManifest(content []byte) (*tools.PackageManifest, error) {
+	manifest := &tools.PackageManifest{Dependencies: []string{}}
+	seen := make(map[string]bool)
+	for _, match := range gemPattern.FindAllSubmatch(content, -1) {
+		name := string(match[1])
+		if !seen[name] {
+			seen[name] = true
+			manifest.Dependencies = append(manifest.Dependencies, name)
+		}
+	}
+	return manifest, nil
+}
@@ -0,0 +1,49 @@
+package ruby
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func TestParseManifest(t *testing.T) {
+	gemfile := `source "https://rubygems.org"
+
+gemspec
+gem "rails", "~> 7.1"
+gem 'pg'
+  gem "rspec-rails", group: :test
+# gem "debug"
+gem "pg"
+`
+	manifest, err := Pack{}.ParseManifest([]byte(gemfile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(manifest.Dependencies, ","); got != "rails,pg,rspec-rails" {
+		t.Errorf("dependencies = %s", got)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	dir := t.TempDir()
+	if (Pack{}).Detect(dir) {
+		t.Error("Gemfile が無ければ検出しないはず")
+	}
+	if err := os.WriteFile(filepath.Join(dir, "mygem.gemspec"), []byte(""), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if !(Pack{}).Detect(dir) {
+		t.Error("gemspec があれば検出するはず")
+	}
+}
+
+func TestRegistered(t *testing.T) {
+	manager := tools.NewLanguageManager()
+	if lang := manager.DetectLanguage("app/models/user.rb"); lang == nil || lang.GetName() != "Ruby" || lang.GetLintCommand() != "bundle exec rubocop" {
+		t.Errorf("登録した言語パックで検出できるはず: %v", lang)
+	}
+}
@@ -0,0 +1,59 @@
+package llm
+
+import (
+	"context"
+	"sync"
+)
+
+// fileAttachments はコンテキストで運ぶ添付ファイルの本文（最初のリクエストでのみ使用）
+type fileAttachments struct {
+	mu    sync.Mutex
+	text  string
+	taken bool
+}
+
+// fileAttachmentsKey はコンテキストに添付ファイルを保持するキー
+type fileAttachmentsKey struct{}
+
+// WithFileAttachments は次のLLMリクエストのユーザーメッセージに追記するファイル内容を設定
+func WithFileAttachments(ctx context.Context, text string) context.Context {
+	if text == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, fileAttachmentsKey{}, &fileAttachments{text: text})
+}
+
+// takeFileAttachments はコンテキストの添付ファイルを取り出す（同じターンの後続リクエストには付けない）
+func takeFileAttachments(ctx context.Context) string {
+	attachments, ok := ctx.Value(fileAttachmentsKey{}).(*fileAttachments)
+	if !ok {
+		return ""
+	}
+	attachments.mu.Lock()
+	defer attachments.mu.Unlock()
+	if attachments.taken {
+		return ""
+	}
+	attachments.taken = true
+	return attachments.text
+}
+
+// attachFiles は添付ファイルの内容を最後のユーザーメッセージに追記
+func attachFiles(ctx context.Context, req ChatRequest) ChatRequest {
+	text := takeFileAttachments(ctx)
+	if text == "" {
+		return req
+	}
+
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role != "user" {
+			continue
+		}
+		messages := make([]ChatMessage, len(req.Messages))
+		copy(messages, req.Messages)
+		messages[i].Content += "\n\n" + text
+		req.Messages = messages
+		break
+	}
+	return req
+}
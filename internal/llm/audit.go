@@ -0,0 +1,85 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// AuditingProvider はLLMリクエスト・応答を監査ログに記録するプロバイダー
+// セッションIDはコンテキスト（logger.WithAuditSession）から取得する
+type AuditingProvider struct {
+	provider Provider
+}
+
+// NewAuditingProvider は監査記録付きプロバイダーを作成
+func NewAuditingProvider(provider Provider) *AuditingProvider {
+	return &AuditingProvider{provider: provider}
+}
+
+// Chat はリクエストと応答（トークン数・レイテンシ）を記録して元のプロバイダーに委譲
+func (ap *AuditingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	logger.AuditContext(ctx, logger.AuditEvent{
+		Type:    logger.AuditLLMRequest,
+		Model:   req.Model,
+		Content: formatAuditMessages(req.Messages),
+		Success: true,
+		Bytes:   messagesLength(req.Messages),
+	})
+
+	startTime := time.Now()
+	resp, err := ap.provider.Chat(ctx, req)
+
+	event := logger.AuditEvent{
+		Type:      logger.AuditLLMResponse,
+		Model:     req.Model,
+		LatencyMs: time.Since(startTime).Milliseconds(),
+		Success:   err == nil,
+	}
+	if err != nil {
+		event.Error = err.Error()
+	} else {
+		event.Content = resp.Message.Content
+		event.Bytes = len(resp.Message.Content)
+		event.PromptTokens = resp.PromptTokens()
+		event.CompletionTokens = resp.CompletionTokens()
+	}
+	logger.AuditContext(ctx, event)
+
+	return resp, err
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (ap *AuditingProvider) SupportsFunctionCalling() bool {
+	return ap.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (ap *AuditingProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return ap.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (ap *AuditingProvider) ListModels() ([]ModelInfo, error) {
+	return ap.provider.ListModels()
+}
+
+// formatAuditMessages はメッセージ列を「role: content」形式で連結
+func formatAuditMessages(messages []ChatMessage) string {
+	parts := make([]string, 0, len(messages))
+	for _, msg := range messages {
+		parts = append(parts, msg.Role+": "+msg.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// messagesLength はメッセージ本文の合計バイト数
+func messagesLength(messages []ChatMessage) int {
+	total := 0
+	for _, msg := range messages {
+		total += len(msg.Content)
+	}
+	return total
+}
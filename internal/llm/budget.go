@@ -0,0 +1,62 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/glkt/vyb-code/internal/budget"
+	"github.com/glkt/vyb-code/internal/usage"
+)
+
+// BudgetProvider はコンテキストのターン上限（budget.Tracker）でトークン数を数えるプロバイダー
+// 上限を超えたターンでは以降のリクエストを送らずに超過エラーを返す
+// キャッシュヒットを数えないよう、CachingProviderより内側でラップする
+type BudgetProvider struct {
+	provider Provider
+}
+
+// NewBudgetProvider はターン上限付きプロバイダーを作成
+func NewBudgetProvider(provider Provider) *BudgetProvider {
+	return &BudgetProvider{provider: provider}
+}
+
+// Chat は上限内であれば元のプロバイダーに委譲し、応答のトークン数を加算
+func (bp *BudgetProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	if err := budget.Check(ctx); err != nil {
+		return nil, err
+	}
+	resp, err := bp.provider.Chat(ctx, req)
+	if err != nil {
+		// 経過時間の上限で打ち切られた場合は超過エラーとして返す
+		if exceeded := budget.Check(ctx); exceeded != nil && ctx.Err() != nil {
+			return nil, exceeded
+		}
+		return resp, err
+	}
+
+	// 件数を返さないプロバイダーでは文字数から推定
+	promptTokens := resp.PromptTokens()
+	if promptTokens == 0 {
+		promptTokens = usage.EstimateTokens(formatAuditMessages(req.Messages))
+	}
+	completionTokens := resp.CompletionTokens()
+	if completionTokens == 0 {
+		completionTokens = usage.EstimateTokens(resp.Message.Content)
+	}
+	budget.AddTokens(ctx, promptTokens+completionTokens)
+	return resp, nil
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (bp *BudgetProvider) SupportsFunctionCalling() bool {
+	return bp.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (bp *BudgetProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return bp.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (bp *BudgetProvider) ListModels() ([]ModelInfo, error) {
+	return bp.provider.ListModels()
+}
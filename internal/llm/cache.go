@@ -0,0 +1,375 @@
+package llm
+
+import (
+	"container/list"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/storage"
+)
+
+// Embedder はテキストの埋め込みベクトルを生成できるプロバイダー
+type Embedder interface {
+	Embed(ctx context.Context, model string, text string) ([]float64, error)
+}
+
+// CacheStats はLLM応答キャッシュの利用統計
+type CacheStats struct {
+	Hits         int64 `json:"hits"`          // 完全一致によるヒット数
+	SemanticHits int64 `json:"semantic_hits"` // 類似プロンプト照合によるヒット数
+	Misses       int64 `json:"misses"`        // ミス数（プロバイダー呼び出し数）
+	Evictions    int64 `json:"evictions"`     // 期限切れ・容量超過による削除数
+	Entries      int   `json:"entries"`       // 現在の保持件数
+}
+
+// cacheEntry はキャッシュされた1件の応答
+type cacheEntry struct {
+	key       string
+	paramKey  string // モデル・生成パラメータのみのキー（類似照合の対象範囲）
+	response  ChatResponse
+	embedding []float64
+	expiresAt time.Time
+}
+
+// persistedEntry は保存先に書き込むエントリ（プロセスを跨いで再利用する）
+type persistedEntry struct {
+	ParamKey  string       `json:"param_key"`
+	Response  ChatResponse `json:"response"`
+	Embedding []float64    `json:"embedding,omitempty"`
+}
+
+// CachingProvider はプロンプトの内容をキーに応答をキャッシュするProviderラッパー
+type CachingProvider struct {
+	provider       Provider
+	ttl            time.Duration
+	maxEntries     int
+	embedder       Embedder
+	embeddingModel string
+	threshold      float64
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	order   *list.List // 先頭が最近使用したエントリ
+	stats   CacheStats
+	now     func() time.Time
+
+	// 応答を書き込み、メモリにない時に参照する保存先（nilならメモリのみ）
+	persistent storage.Store
+}
+
+// NewCachingProvider は設定に基づいてキャッシュ付きプロバイダーを作成
+func NewCachingProvider(provider Provider, cfg config.LLMCacheConfig) *CachingProvider {
+	maxEntries := cfg.MaxEntries
+	if maxEntries <= 0 {
+		maxEntries = config.DefaultLLMCacheConfig().MaxEntries
+	}
+
+	cp := &CachingProvider{
+		provider:       provider,
+		ttl:            time.Duration(cfg.TTLMinutes) * time.Minute,
+		maxEntries:     maxEntries,
+		embeddingModel: cfg.EmbeddingModel,
+		threshold:      cfg.SimilarityThreshold,
+		entries:        make(map[string]*list.Element),
+		order:          list.New(),
+		now:            time.Now,
+	}
+
+	// 類似照合が有効で、プロバイダーが埋め込みに対応していれば利用
+	if cfg.SemanticMatching {
+		if embedder, ok := provider.(Embedder); ok {
+			cp.embedder = embedder
+		}
+	}
+
+	return cp
+}
+
+// SetEmbedder は類似プロンプト照合に使う埋め込みプロバイダーを設定（nilで無効化）
+func (cp *CachingProvider) SetEmbedder(embedder Embedder) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.embedder = embedder
+}
+
+// SetStore は応答を永続化する保存先を設定（nilでメモリのみ）
+// 類似照合はメモリ上のエントリだけを対象にする
+func (cp *CachingProvider) SetStore(store storage.Store) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.persistent = store
+}
+
+// Chat はキャッシュを確認し、ヒットしなければ元のプロバイダーへ問い合わせる
+func (cp *CachingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// 画像付きリクエストはテキストだけでは同一性を判定できないためキャッシュしない
+	if hasImages(req.Messages) {
+		return cp.provider.Chat(ctx, req)
+	}
+
+	paramKey := cacheParamKey(req)
+	normalized := normalizeMessages(req.Messages)
+	key := hashKey(paramKey, normalized)
+
+	if response, ok := cp.lookup(key); ok {
+		return response, nil
+	}
+
+	// 類似プロンプトの照合（埋め込み取得に失敗した場合は照合しない）
+	var embedding []float64
+	if embedder := cp.getEmbedder(); embedder != nil {
+		if vec, err := embedder.Embed(ctx, cp.embeddingModel, normalized); err == nil && len(vec) > 0 {
+			embedding = vec
+			if response, ok := cp.lookupSimilar(paramKey, embedding); ok {
+				return response, nil
+			}
+		}
+	}
+
+	cp.mu.Lock()
+	cp.stats.Misses++
+	cp.mu.Unlock()
+
+	response, err := cp.provider.Chat(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 完了した空でない応答のみキャッシュ
+	if response != nil && response.Done && strings.TrimSpace(response.Message.Content) != "" {
+		// 実測値はこの問い合わせのもののため、キャッシュヒットには引き継がない
+		cached := *response
+		cached.Metrics = nil
+		cp.store(&cacheEntry{
+			key:       key,
+			paramKey:  paramKey,
+			response:  cached,
+			embedding: embedding,
+		})
+	}
+
+	return response, nil
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (cp *CachingProvider) SupportsFunctionCalling() bool {
+	return cp.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (cp *CachingProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return cp.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (cp *CachingProvider) ListModels() ([]ModelInfo, error) {
+	return cp.provider.ListModels()
+}
+
+// Stats は現在のキャッシュ統計を返す
+func (cp *CachingProvider) Stats() CacheStats {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	stats := cp.stats
+	stats.Entries = cp.order.Len()
+	return stats
+}
+
+// Clear はキャッシュを全て削除（保存先のエントリも含む）
+func (cp *CachingProvider) Clear() {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	cp.entries = make(map[string]*list.Element)
+	cp.order.Init()
+	if cp.persistent != nil {
+		cp.persistent.DeleteBucket(storage.BucketLLMCache)
+	}
+}
+
+// getEmbedder は現在の埋め込みプロバイダーを取得
+func (cp *CachingProvider) getEmbedder() Embedder {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+	return cp.embedder
+}
+
+// lookup は完全一致のエントリを検索
+func (cp *CachingProvider) lookup(key string) (*ChatResponse, bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	elem, ok := cp.entries[key]
+	if !ok {
+		if elem, ok = cp.loadPersisted(key); !ok {
+			return nil, false
+		}
+	}
+	entry := elem.Value.(*cacheEntry)
+	if cp.expired(entry) {
+		cp.remove(elem)
+		return nil, false
+	}
+
+	cp.order.MoveToFront(elem)
+	cp.stats.Hits++
+	response := entry.response
+	return &response, true
+}
+
+// lookupSimilar は同一モデル・パラメータのエントリから最も類似した応答を検索
+func (cp *CachingProvider) lookupSimilar(paramKey string, embedding []float64) (*ChatResponse, bool) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	var best *list.Element
+	bestScore := cp.threshold
+	for elem := cp.order.Front(); elem != nil; {
+		next := elem.Next()
+		entry := elem.Value.(*cacheEntry)
+		if cp.expired(entry) {
+			cp.remove(elem)
+		} else if entry.paramKey == paramKey && len(entry.embedding) > 0 {
+			if score := CosineSimilarity(embedding, entry.embedding); score >= bestScore {
+				best = elem
+				bestScore = score
+			}
+		}
+		elem = next
+	}
+
+	if best == nil {
+		return nil, false
+	}
+
+	cp.order.MoveToFront(best)
+	cp.stats.SemanticHits++
+	response := best.Value.(*cacheEntry).response
+	return &response, true
+}
+
+// store はエントリを保存先とメモリに追加
+func (cp *CachingProvider) store(entry *cacheEntry) {
+	cp.mu.Lock()
+	defer cp.mu.Unlock()
+
+	if cp.ttl > 0 {
+		entry.expiresAt = cp.now().Add(cp.ttl)
+	}
+
+	if cp.persistent != nil {
+		if data, err := json.Marshal(persistedEntry{ParamKey: entry.paramKey, Response: entry.response, Embedding: entry.embedding}); err == nil {
+			cp.persistent.Put(storage.BucketLLMCache, entry.key, data, entry.expiresAt)
+		}
+	}
+	cp.insert(entry)
+}
+
+// insert はエントリをメモリに追加し、容量超過分を古い順に削除（ロック取得済みで呼び出す）
+func (cp *CachingProvider) insert(entry *cacheEntry) *list.Element {
+	if elem, ok := cp.entries[entry.key]; ok {
+		elem.Value = entry
+		cp.order.MoveToFront(elem)
+		return elem
+	}
+
+	elem := cp.order.PushFront(entry)
+	cp.entries[entry.key] = elem
+	for cp.order.Len() > cp.maxEntries {
+		cp.remove(cp.order.Back())
+	}
+	return elem
+}
+
+// loadPersisted は保存先のエントリをメモリに読み込む（ロック取得済みで呼び出す）
+func (cp *CachingProvider) loadPersisted(key string) (*list.Element, bool) {
+	if cp.persistent == nil {
+		return nil, false
+	}
+	data, ok, err := cp.persistent.Get(storage.BucketLLMCache, key)
+	if err != nil || !ok {
+		return nil, false
+	}
+	var persisted persistedEntry
+	if err := json.Unmarshal(data, &persisted); err != nil {
+		return nil, false
+	}
+	entry := &cacheEntry{
+		key:       key,
+		paramKey:  persisted.ParamKey,
+		response:  persisted.Response,
+		embedding: persisted.Embedding,
+	}
+	if cp.ttl > 0 {
+		entry.expiresAt = cp.now().Add(cp.ttl)
+	}
+	return cp.insert(entry), true
+}
+
+// expired はエントリが有効期限切れかどうか（TTL 0 は無期限）
+func (cp *CachingProvider) expired(entry *cacheEntry) bool {
+	return !entry.expiresAt.IsZero() && cp.now().After(entry.expiresAt)
+}
+
+// remove はエントリを削除（ロック取得済みで呼び出す）
+func (cp *CachingProvider) remove(elem *list.Element) {
+	entry := elem.Value.(*cacheEntry)
+	delete(cp.entries, entry.key)
+	cp.order.Remove(elem)
+	cp.stats.Evictions++
+}
+
+// cacheParamKey はモデルと生成パラメータからキーを作成
+func cacheParamKey(req ChatRequest) string {
+	params := struct {
+		Model       string   `json:"model"`
+		Temperature *float64 `json:"temperature,omitempty"`
+		TopP        *float64 `json:"top_p,omitempty"`
+		MaxTokens   *int     `json:"max_tokens,omitempty"`
+	}{req.Model, req.Temperature, req.TopP, req.MaxTokens}
+
+	data, _ := json.Marshal(params)
+	return string(data)
+}
+
+// normalizeMessages は空白の揺れを除いた会話テキストを作成
+func normalizeMessages(messages []ChatMessage) string {
+	var builder strings.Builder
+	for _, msg := range messages {
+		builder.WriteString(strings.ToLower(strings.TrimSpace(msg.Role)))
+		builder.WriteString(": ")
+		builder.WriteString(strings.Join(strings.Fields(msg.Content), " "))
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+// hashKey はパラメータと正規化済みメッセージからキャッシュキーを生成
+func hashKey(paramKey, normalized string) string {
+	sum := sha256.Sum256([]byte(paramKey + "\x00" + normalized))
+	return hex.EncodeToString(sum[:])
+}
+
+// CosineSimilarity は2つのベクトルのコサイン類似度を計算
+func CosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
@@ -0,0 +1,97 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/codecheck"
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// CodeCheckProvider は応答中のコードブロックを返す前に検証し、失敗したら問題を伝えて応答を作り直させるプロバイダー
+// 作り直しは max_repairs 回までで、直らなければ最後の応答をそのまま返す（検証結果は監査ログに記録する）
+// ストリーミング中の受け取り側には作り直した応答が最初から渡し直される
+type CodeCheckProvider struct {
+	provider   Provider
+	checker    *codecheck.Checker
+	maxRepairs int
+}
+
+// NewCodeCheckProvider は生成コードを検証するプロバイダーを作成（無効・設定が不正なら provider をそのまま返す）
+func NewCodeCheckProvider(provider Provider, cfg config.CodeCheckConfig) Provider {
+	if !cfg.Enabled {
+		return provider
+	}
+	checker, err := codecheck.New(cfg.Languages, time.Duration(cfg.TimeoutSeconds)*time.Second)
+	if err != nil {
+		return provider
+	}
+	return &CodeCheckProvider{provider: provider, checker: checker, maxRepairs: cfg.MaxRepairs}
+}
+
+// Chat は応答を検証し、失敗したコードブロックがあれば修正を求めて問い合わせ直す
+func (cp *CodeCheckProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := cp.provider.Chat(ctx, req)
+	for round := 0; err == nil; round++ {
+		issues := cp.checker.Check(ctx, resp.Message.Content)
+		if ctx.Err() != nil {
+			break
+		}
+		if round > 0 || len(issues) > 0 {
+			auditCodeCheck(ctx, req.Model, round, issues)
+		}
+		if len(issues) == 0 || round >= cp.maxRepairs {
+			break
+		}
+
+		repair := req
+		repair.Messages = append(append([]ChatMessage{}, req.Messages...),
+			ChatMessage{Role: "assistant", Content: resp.Message.Content},
+			ChatMessage{Role: "user", Content: codecheck.RepairPrompt(issues)},
+		)
+		repaired, repairErr := cp.provider.Chat(ctx, repair)
+		if repairErr != nil {
+			break // 作り直せなければ最初の応答を返す
+		}
+		resp = repaired
+	}
+	return resp, err
+}
+
+// auditCodeCheck は round 回目の応答の検証結果を監査ログに記録（0 は最初の応答）
+func auditCodeCheck(ctx context.Context, model string, round int, issues []codecheck.Issue) {
+	event := logger.AuditEvent{
+		Type:     logger.AuditCodeCheck,
+		Model:    model,
+		Success:  len(issues) == 0,
+		Metadata: map[string]string{"round": strconv.Itoa(round), "issues": strconv.Itoa(len(issues))},
+	}
+	if len(issues) > 0 {
+		blocks := make([]string, len(issues))
+		for i, issue := range issues {
+			blocks[i] = fmt.Sprintf("block %d (%s, %s)", issue.Block, issue.Language, issue.Tool)
+		}
+		event.Error = strings.Join(blocks, ", ")
+		event.Content = codecheck.RepairPrompt(issues)
+	}
+	logger.AuditContext(ctx, event)
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (cp *CodeCheckProvider) SupportsFunctionCalling() bool {
+	return cp.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (cp *CodeCheckProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return cp.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (cp *CodeCheckProvider) ListModels() ([]ModelInfo, error) {
+	return cp.provider.ListModels()
+}
@@ -0,0 +1,64 @@
+package llm
+
+import (
+	"context"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// EventProvider はLLM呼び出しの所要時間・トークン数をイベントバスに通知するプロバイダー
+// キャッシュヒットを実際の呼び出しとして数えないよう、CachingProviderより内側でラップする
+type EventProvider struct {
+	provider Provider
+	bus      *events.Bus
+}
+
+// NewEventProvider はイベント通知付きプロバイダーを作成
+func NewEventProvider(provider Provider, bus *events.Bus) *EventProvider {
+	return &EventProvider{provider: provider, bus: bus}
+}
+
+// Chat は元のプロバイダーに委譲し、結果を llm.completed として通知
+func (ep *EventProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	startTime := time.Now()
+	resp, err := ep.provider.Chat(ctx, req)
+	if !ep.bus.HasSubscribers() {
+		return resp, err
+	}
+
+	data := map[string]interface{}{
+		"model":       req.Model,
+		"duration_ms": time.Since(startTime).Milliseconds(),
+		"success":     err == nil,
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	} else {
+		data["prompt_tokens"] = resp.PromptTokens()
+		data["completion_tokens"] = resp.CompletionTokens()
+		if resp.Metrics != nil {
+			data["ttft_ms"] = resp.Metrics.TimeToFirstToken.Milliseconds()
+			data["tokens_per_second"] = resp.Metrics.TokensPerSecond
+		}
+	}
+	ep.bus.Publish(events.Event{Type: events.LLMCompleted, SessionID: logger.AuditSessionFromContext(ctx), Data: data})
+
+	return resp, err
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (ep *EventProvider) SupportsFunctionCalling() bool {
+	return ep.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (ep *EventProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return ep.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (ep *EventProvider) ListModels() ([]ModelInfo, error) {
+	return ep.provider.ListModels()
+}
@@ -0,0 +1,38 @@
+package llm
+
+import "time"
+
+// ResponseMetrics は1回の問い合わせの実測値（キャッシュヒット等で測れなければ ChatResponse.Metrics は nil）
+type ResponseMetrics struct {
+	TimeToFirstToken time.Duration // 送信から最初の本文の断片まで（ストリーミングでなければサーバーの読み込み＋プロンプト評価の時間）
+	Total            time.Duration // 送信から応答完了まで
+	PromptTokens     int
+	CompletionTokens int
+	TokensPerSecond  float64 // 生成速度（サーバーの生成時間、なければ最初の断片から完了までで算出）
+	Streamed         bool
+}
+
+// newResponseMetrics は送信時刻 start・最初の断片の受信時刻 firstChunk（ゼロ値なら不明）とサーバーの計測値から実測値を作る
+func newResponseMetrics(resp *ChatResponse, start, firstChunk time.Time) *ResponseMetrics {
+	end := time.Now()
+	metrics := &ResponseMetrics{
+		Total:            end.Sub(start),
+		PromptTokens:     resp.PromptTokens(),
+		CompletionTokens: resp.CompletionTokens(),
+		Streamed:         !firstChunk.IsZero(),
+	}
+
+	generation := time.Duration(resp.EvalDuration)
+	if metrics.Streamed {
+		metrics.TimeToFirstToken = firstChunk.Sub(start)
+		if generation <= 0 {
+			generation = end.Sub(firstChunk)
+		}
+	} else if resp.PromptEvalDuration > 0 {
+		metrics.TimeToFirstToken = time.Duration(resp.LoadDuration + resp.PromptEvalDuration)
+	}
+	if generation > 0 && metrics.CompletionTokens > 0 {
+		metrics.TokensPerSecond = float64(metrics.CompletionTokens) / generation.Seconds()
+	}
+	return metrics
+}
@@ -0,0 +1,250 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ModelDetails はローカルにあるモデルの詳細（プロバイダーが返さない項目はゼロ値）
+type ModelDetails struct {
+	Name              string    `json:"name"`
+	SizeBytes         int64     `json:"size_bytes,omitempty"`
+	ModifiedAt        time.Time `json:"modified_at,omitempty"`
+	Digest            string    `json:"digest,omitempty"`
+	Family            string    `json:"family,omitempty"`
+	ParameterSize     string    `json:"parameter_size,omitempty"`     // 例: 14.8B
+	QuantizationLevel string    `json:"quantization_level,omitempty"` // 例: Q4_K_M
+	Format            string    `json:"format,omitempty"`             // 例: gguf
+	ContextLength     int       `json:"context_length,omitempty"`     // 学習時のコンテキスト長
+	NumCtx            int       `json:"num_ctx,omitempty"`            // Modelfileで指定された実行時のコンテキスト長
+}
+
+// PullProgress は ollama pull の進捗（レイヤー毎）
+type PullProgress struct {
+	Status    string `json:"status"`
+	Digest    string `json:"digest,omitempty"`
+	Total     int64  `json:"total,omitempty"`
+	Completed int64  `json:"completed,omitempty"`
+}
+
+// ollamaModelDetails は /api/tags と /api/show に共通する details
+type ollamaModelDetails struct {
+	Format            string `json:"format"`
+	Family            string `json:"family"`
+	ParameterSize     string `json:"parameter_size"`
+	QuantizationLevel string `json:"quantization_level"`
+}
+
+// ListLocalModels はpull済みモデルをサイズ・量子化等の詳細付きで取得（名前順）
+func (c *OllamaClient) ListLocalModels(ctx context.Context) ([]ModelDetails, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/api/tags", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Models []struct {
+			Name       string             `json:"name"`
+			Size       int64              `json:"size"`
+			Digest     string             `json:"digest"`
+			ModifiedAt time.Time          `json:"modified_at"`
+			Details    ollamaModelDetails `json:"details"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	models := make([]ModelDetails, 0, len(result.Models))
+	for _, model := range result.Models {
+		models = append(models, ModelDetails{
+			Name:              model.Name,
+			SizeBytes:         model.Size,
+			ModifiedAt:        model.ModifiedAt,
+			Digest:            model.Digest,
+			Family:            model.Details.Family,
+			ParameterSize:     model.Details.ParameterSize,
+			QuantizationLevel: model.Details.QuantizationLevel,
+			Format:            model.Details.Format,
+		})
+	}
+	sort.Slice(models, func(i, j int) bool { return models[i].Name < models[j].Name })
+	return models, nil
+}
+
+// ShowModel は /api/show でモデルの詳細とコンテキスト長を取得
+func (c *OllamaClient) ShowModel(ctx context.Context, name string) (*ModelDetails, error) {
+	reqBody, err := json.Marshal(map[string]string{"model": name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/api/show", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("model %q is not pulled", name)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ollama API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Parameters string                 `json:"parameters"`
+		Details    ollamaModelDetails     `json:"details"`
+		ModelInfo  map[string]interface{} `json:"model_info"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	details := &ModelDetails{
+		Name:              name,
+		Family:            result.Details.Family,
+		ParameterSize:     result.Details.ParameterSize,
+		QuantizationLevel: result.Details.QuantizationLevel,
+		Format:            result.Details.Format,
+		NumCtx:            parseNumCtx(result.Parameters),
+	}
+	// model_info のキーはアーキテクチャ名付き（例: qwen2.context_length）
+	for key, value := range result.ModelInfo {
+		if strings.HasSuffix(key, ".context_length") {
+			if length, ok := value.(float64); ok {
+				details.ContextLength = int(length)
+			}
+		}
+	}
+	return details, nil
+}
+
+// Pull はモデルをダウンロードし、進捗をコールバックに通知する（完了まで待機）
+func (c *OllamaClient) Pull(ctx context.Context, name string, progress func(PullProgress)) error {
+	reqBody, err := json.Marshal(map[string]interface{}{"model": name, "stream": true})
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/api/pull", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// ダウンロードは数分以上かかるため、応答全体のタイムアウトは適用しない（中断はctxで行う）
+	client := &http.Client{Transport: c.HTTPClient.Transport}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("ollama API returned status %d", resp.StatusCode)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		var event struct {
+			PullProgress
+			Error string `json:"error"`
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			continue
+		}
+		if event.Error != "" {
+			return fmt.Errorf("pull failed: %s", event.Error)
+		}
+		if progress != nil {
+			progress(event.PullProgress)
+		}
+		if event.Status == "success" {
+			return nil
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read pull progress: %w", err)
+	}
+	return fmt.Errorf("pull ended before completion")
+}
+
+// ListOpenAICompatibleModels はOpenAI互換サーバー（LM Studio, vLLM）の /v1/models を取得
+func ListOpenAICompatibleModels(ctx context.Context, client *http.Client, baseURL string) ([]ModelDetails, error) {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", strings.TrimSuffix(baseURL, "/")+"/v1/models", nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("models API returned status %d", resp.StatusCode)
+	}
+
+	var result struct {
+		Data []struct {
+			ID          string `json:"id"`
+			MaxModelLen int    `json:"max_model_len"` // vLLMのみ
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	models := make([]ModelDetails, 0, len(result.Data))
+	for _, model := range result.Data {
+		models = append(models, ModelDetails{Name: model.ID, ContextLength: model.MaxModelLen})
+	}
+	return models, nil
+}
+
+// SameModel はモデル名が同じか（タグ省略時は :latest として比較）
+func SameModel(a, b string) bool {
+	return normalizeModelName(a) == normalizeModelName(b)
+}
+
+func normalizeModelName(name string) string {
+	if !strings.Contains(name, ":") {
+		return name + ":latest"
+	}
+	return name
+}
+
+// parseNumCtx はModelfileのパラメーター（"num_ctx 8192" の行）からコンテキスト長を取得
+func parseNumCtx(parameters string) int {
+	for _, line := range strings.Split(parameters, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[0] == "num_ctx" {
+			if value, err := strconv.Atoi(fields[1]); err == nil {
+				return value
+			}
+		}
+	}
+	return 0
+}
@@ -0,0 +1,107 @@
+package llm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newModelsServer は /api/tags, /api/show, /api/pull, /v1/models に応答するテスト用サーバー
+func newModelsServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[
+				{"name":"qwen2.5-coder:7b","size":4683087332,"details":{"family":"qwen2","parameter_size":"7.6B","quantization_level":"Q4_K_M","format":"gguf"}},
+				{"name":"llama3:latest","size":4661224676,"details":{"family":"llama"}}]}`))
+		case "/api/show":
+			w.Write([]byte(`{"parameters":"stop \"<|im_end|>\"\nnum_ctx 8192","details":{"family":"qwen2"},"model_info":{"qwen2.context_length":32768,"general.architecture":"qwen2"}}`))
+		case "/api/pull":
+			w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"status\":\"pulling abc\",\"digest\":\"sha256:abc\",\"total\":100,\"completed\":50}\n{\"status\":\"pulling abc\",\"digest\":\"sha256:abc\",\"total\":100,\"completed\":100}\n{\"status\":\"success\"}\n"))
+		case "/v1/models":
+			w.Write([]byte(`{"data":[{"id":"Qwen/Qwen2.5-Coder-7B-Instruct","max_model_len":32768}]}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestListLocalModels(t *testing.T) {
+	server := newModelsServer(t)
+	defer server.Close()
+
+	models, err := NewOllamaClient(server.URL).ListLocalModels(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 2 || models[0].Name != "llama3:latest" {
+		t.Fatalf("名前順に並ぶはず: %+v", models)
+	}
+	if qwen := models[1]; qwen.SizeBytes != 4683087332 || qwen.QuantizationLevel != "Q4_K_M" || qwen.ParameterSize != "7.6B" {
+		t.Errorf("詳細: %+v", qwen)
+	}
+}
+
+func TestShowModelContextLength(t *testing.T) {
+	server := newModelsServer(t)
+	defer server.Close()
+
+	details, err := NewOllamaClient(server.URL).ShowModel(context.Background(), "qwen2.5-coder:7b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if details.ContextLength != 32768 || details.NumCtx != 8192 {
+		t.Errorf("コンテキスト長: %d, num_ctx: %d", details.ContextLength, details.NumCtx)
+	}
+}
+
+func TestPullReportsProgress(t *testing.T) {
+	server := newModelsServer(t)
+	defer server.Close()
+
+	var events []PullProgress
+	err := NewOllamaClient(server.URL).Pull(context.Background(), "qwen2.5-coder:7b", func(p PullProgress) {
+		events = append(events, p)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 4 || events[2].Completed != 100 || events[3].Status != "success" {
+		t.Errorf("進捗: %+v", events)
+	}
+}
+
+func TestPullError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"status\":\"pulling manifest\"}\n{\"error\":\"pull model manifest: file does not exist\"}\n"))
+	}))
+	defer server.Close()
+
+	if err := NewOllamaClient(server.URL).Pull(context.Background(), "missing", nil); err == nil {
+		t.Error("存在しないモデルの取得はエラーになるはず")
+	}
+}
+
+func TestListOpenAICompatibleModels(t *testing.T) {
+	server := newModelsServer(t)
+	defer server.Close()
+
+	models, err := ListOpenAICompatibleModels(context.Background(), server.Client(), server.URL+"/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(models) != 1 || models[0].ContextLength != 32768 {
+		t.Errorf("モデル: %+v", models)
+	}
+}
+
+func TestSameModel(t *testing.T) {
+	if !SameModel("llama3", "llama3:latest") {
+		t.Error("タグ省略時は :latest として一致するはず")
+	}
+	if SameModel("qwen2.5-coder:7b", "qwen2.5-coder:14b") {
+		t.Error("異なるタグに一致した")
+	}
+}
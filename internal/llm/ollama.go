@@ -1,18 +1,24 @@
 package llm
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
 )
 
 // OllamaのHTTP APIに接続するためのクライアント構造体
 type OllamaClient struct {
 	BaseURL    string       // Ollama server URL (e.g., "http://localhost:11434")
 	HTTPClient *http.Client // HTTP通信用のクライアント
+	EmbedAPI   string       // 埋め込みAPI（embed, embeddings、空なら /api/embed を試して旧APIに切り替え）
 }
 
 // デフォルト設定でOllamaクライアントを作成するコンストラクタ関数
@@ -32,6 +38,11 @@ func NewOllamaClient(baseURL string) *OllamaClient {
 
 // Ollamaにチャットリクエストを送信し、レスポンスを返すメソッド
 func (c *OllamaClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// 生成途中の本文を受け取る関数があればストリーミングで問い合わせる
+	if handler := streamHandlerFrom(ctx); handler != nil && !req.Stream {
+		return c.chatStreamToHandler(ctx, req, handler)
+	}
+
 	// リクエスト構造体をJSON形式に変換（API呼び出し用）
 	reqBody, err := json.Marshal(req)
 	if err != nil {
@@ -48,15 +59,16 @@ func (c *OllamaClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 	httpReq.Header.Set("Content-Type", "application/json")
 
 	// OllamaにHTTPリクエストを送信
+	start := time.Now()
 	resp, err := c.HTTPClient.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("failed to send request: %w", err)
 	}
 	defer resp.Body.Close() // レスポンスボディを確実にクローズ
 
-	// HTTPステータスが成功かチェック
+	// HTTPステータスが成功かチェック（再試行の判定のためステータスを保持）
 	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("ollama API returned status %d", resp.StatusCode)
+		return nil, &StatusError{StatusCode: resp.StatusCode}
 	}
 
 	// JSONレスポンスを構造体に変換
@@ -64,15 +76,198 @@ func (c *OllamaClient) Chat(ctx context.Context, req ChatRequest) (*ChatResponse
 	if err := json.NewDecoder(resp.Body).Decode(&chatResp); err != nil {
 		return nil, fmt.Errorf("failed to decode response: %w", err)
 	}
+	chatResp.Metrics = newResponseMetrics(&chatResp, start, time.Time{})
 
 	return &chatResp, nil
 }
 
+// ChatStream はストリーミングでチャットし、応答の断片を受け取る度に onChunk を呼ぶ
+// 返す応答は断片を連結したもので、トークン数は最後の断片（done）の値
+func (c *OllamaClient) ChatStream(ctx context.Context, req ChatRequest, onChunk func(chunk *ChatResponse)) (*ChatResponse, error) {
+	req.Stream = true
+	reqBody, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/api/chat", bytes.NewBuffer(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	// 最初のトークンまでの時間を測るため、送信時刻と本文を含む最初の断片の受信時刻を記録する
+	start := time.Now()
+	var firstChunk time.Time
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{StatusCode: resp.StatusCode}
+	}
+
+	var content strings.Builder
+	result := &ChatResponse{Message: ChatMessage{Role: "assistant"}}
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		var chunk ChatResponse
+		if err := json.Unmarshal(scanner.Bytes(), &chunk); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %w", err)
+		}
+		if firstChunk.IsZero() && chunk.Message.Content != "" {
+			firstChunk = time.Now()
+		}
+		content.WriteString(chunk.Message.Content)
+		if onChunk != nil {
+			onChunk(&chunk)
+		}
+		if chunk.Done {
+			result.Done = true
+			result.PromptEvalCount = chunk.PromptEvalCount
+			result.EvalCount = chunk.EvalCount
+			result.Usage = chunk.Usage
+			result.LoadDuration = chunk.LoadDuration
+			result.PromptEvalDuration = chunk.PromptEvalDuration
+			result.EvalDuration = chunk.EvalDuration
+			break
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	result.Message.Content = content.String()
+	if firstChunk.IsZero() {
+		firstChunk = time.Now()
+	}
+	result.Metrics = newResponseMetrics(result, start, firstChunk)
+	return result, nil
+}
+
+// Ollamaの埋め込みAPIでテキストの埋め込みベクトルを取得する
+func (c *OllamaClient) Embed(ctx context.Context, model string, text string) ([]float64, error) {
+	vectors, err := c.EmbedBatch(ctx, model, []string{text})
+	if err != nil {
+		return nil, err
+	}
+	return vectors[0], nil
+}
+
+// EmbedBatch は複数のテキストの埋め込みベクトルを texts と同じ順で取得する
+// /api/embed は1リクエストでまとめて送り、古いサーバーの /api/embeddings では1件ずつ送る
+func (c *OllamaClient) EmbedBatch(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if c.EmbedAPI != "embeddings" {
+		vectors, err := c.embed(ctx, model, texts)
+		var statusErr *StatusError
+		if err == nil || c.EmbedAPI == "embed" || !errors.As(err, &statusErr) || statusErr.StatusCode != http.StatusNotFound {
+			return vectors, err
+		}
+	}
+
+	vectors := make([][]float64, 0, len(texts))
+	for _, text := range texts {
+		vector, err := c.legacyEmbed(ctx, model, text)
+		if err != nil {
+			return nil, err
+		}
+		vectors = append(vectors, vector)
+	}
+	return vectors, nil
+}
+
+// embed は /api/embed（Ollama 0.3.4 以降）で埋め込みを取得
+func (c *OllamaClient) embed(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	var result struct {
+		Embeddings [][]float64 `json:"embeddings"`
+	}
+	if err := c.postJSON(ctx, "/api/embed", map[string]interface{}{"model": model, "input": texts}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embeddings) != len(texts) {
+		return nil, fmt.Errorf("ollama returned %d embeddings for %d inputs", len(result.Embeddings), len(texts))
+	}
+	return result.Embeddings, nil
+}
+
+// legacyEmbed は /api/embeddings（旧API）で1件の埋め込みを取得
+func (c *OllamaClient) legacyEmbed(ctx context.Context, model string, text string) ([]float64, error) {
+	var result struct {
+		Embedding []float64 `json:"embedding"`
+	}
+	if err := c.postJSON(ctx, "/api/embeddings", map[string]string{"model": model, "prompt": text}, &result); err != nil {
+		return nil, err
+	}
+	if len(result.Embedding) == 0 {
+		return nil, fmt.Errorf("ollama returned an empty embedding")
+	}
+	return result.Embedding, nil
+}
+
+// postJSON は path に body をJSONで送り、応答を out にデコードする
+func (c *OllamaClient) postJSON(ctx context.Context, path string, body interface{}, out interface{}) error {
+	reqBody, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+path, bytes.NewBuffer(reqBody))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// NewEmbeddingClient は設定の埋め込みAPI（URL・APIの種類）に接続するクライアントを作成
+func NewEmbeddingClient(cfg *config.Config) *OllamaClient {
+	client := NewOllamaClient(cfg.ResolvedEmbeddingURL())
+	if cfg.Embeddings.API != "auto" {
+		client.EmbedAPI = cfg.Embeddings.API
+	}
+	return client
+}
+
 // OllamaがFunction Callingに対応しているかを返す（現在は未対応）
 func (c *OllamaClient) SupportsFunctionCalling() bool {
 	return false // Ollamaは現在Function Calling未対応
 }
 
+// Ping はサーバーに到達できるか確認する（モデルを読み込まない /api/version を使用）
+func (c *OllamaClient) Ping(ctx context.Context) error {
+	httpReq, err := http.NewRequestWithContext(ctx, "GET", c.BaseURL+"/api/version", nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := c.HTTPClient.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("failed to send request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
 // 指定されたモデルの情報を取得する（簡易実装）
 func (c *OllamaClient) GetModelInfo(model string) (*ModelInfo, error) {
 	// 基本的なモデル情報を返す（拡張版ではOllama APIを呼び出す）
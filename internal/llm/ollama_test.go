@@ -200,6 +200,104 @@ func TestOllamaChat(t *testing.T) {
 	}
 }
 
+// TestOllamaChatStream はストリーミング応答の断片が連結され、最後の断片のトークン数が返ることをテストする
+func TestOllamaChatStream(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("stream: true で送るはず")
+		}
+		encoder := json.NewEncoder(w)
+		encoder.Encode(ChatResponse{Message: ChatMessage{Role: "assistant", Content: "Hel"}})
+		encoder.Encode(ChatResponse{Message: ChatMessage{Role: "assistant", Content: "lo"}})
+		encoder.Encode(ChatResponse{Done: true, PromptEvalCount: 12, EvalCount: 2})
+	}))
+	defer server.Close()
+
+	var chunks int
+	resp, err := NewOllamaClient(server.URL).ChatStream(context.Background(), ChatRequest{Model: "m"}, func(chunk *ChatResponse) { chunks++ })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message.Content != "Hello" || resp.CompletionTokens() != 2 || resp.PromptTokens() != 12 || chunks != 3 {
+		t.Errorf("resp = %+v, chunks = %d", resp, chunks)
+	}
+}
+
+// TestOllamaChatStreamMetrics は最初の断片までの時間・生成速度・トークン数の内訳が応答に付くことをテストする
+func TestOllamaChatStreamMetrics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(30 * time.Millisecond) // プロンプト評価
+		encoder := json.NewEncoder(w)
+		encoder.Encode(ChatResponse{Message: ChatMessage{Role: "assistant", Content: "Hi"}})
+		w.(http.Flusher).Flush()
+		time.Sleep(20 * time.Millisecond)
+		encoder.Encode(ChatResponse{Done: true, PromptEvalCount: 40, EvalCount: 10, EvalDuration: int64(500 * time.Millisecond)})
+	}))
+	defer server.Close()
+
+	resp, err := NewOllamaClient(server.URL).ChatStream(context.Background(), ChatRequest{Model: "m"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	metrics := resp.Metrics
+	if metrics == nil || !metrics.Streamed {
+		t.Fatalf("metrics = %+v", metrics)
+	}
+	if metrics.TimeToFirstToken < 30*time.Millisecond || metrics.Total < metrics.TimeToFirstToken+20*time.Millisecond {
+		t.Errorf("unexpected timing: ttft %v, total %v", metrics.TimeToFirstToken, metrics.Total)
+	}
+	// サーバーの生成時間があればそれで生成速度を求める
+	if metrics.TokensPerSecond != 20 || metrics.PromptTokens != 40 || metrics.CompletionTokens != 10 {
+		t.Errorf("unexpected metrics: %+v", metrics)
+	}
+
+	// ストリーミングでなければサーバーの読み込み＋プロンプト評価時間を最初のトークンまでの時間とする
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(ChatResponse{Message: ChatMessage{Role: "assistant", Content: "Hi"}, Done: true, EvalCount: 4,
+			LoadDuration: int64(100 * time.Millisecond), PromptEvalDuration: int64(200 * time.Millisecond), EvalDuration: int64(time.Second)})
+	}))
+	defer server.Close()
+	resp, err = NewOllamaClient(server.URL).Chat(context.Background(), ChatRequest{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Metrics == nil || resp.Metrics.Streamed || resp.Metrics.TimeToFirstToken != 300*time.Millisecond || resp.Metrics.TokensPerSecond != 4 {
+		t.Errorf("unexpected non-streaming metrics: %+v", resp.Metrics)
+	}
+}
+
+// TestOllamaChatWithStreamHandler は受け取り先が設定されていれば Chat がストリーミングで送り、受信済みの本文全体を渡すことをテストする
+func TestOllamaChatWithStreamHandler(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ChatRequest
+		json.NewDecoder(r.Body).Decode(&req)
+		if !req.Stream {
+			t.Error("stream: true で送るはず")
+		}
+		encoder := json.NewEncoder(w)
+		encoder.Encode(ChatResponse{Message: ChatMessage{Role: "assistant", Content: "<COMMAND>ls"}})
+		encoder.Encode(ChatResponse{Message: ChatMessage{Role: "assistant", Content: "</COMMAND>"}})
+		encoder.Encode(ChatResponse{Done: true, EvalCount: 2})
+	}))
+	defer server.Close()
+
+	var received []string
+	ctx := WithStreamHandler(context.Background(), func(content string) { received = append(received, content) })
+	resp, err := NewOllamaClient(server.URL).Chat(ctx, ChatRequest{Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Message.Content != "<COMMAND>ls</COMMAND>" || !resp.Done {
+		t.Errorf("resp = %+v", resp)
+	}
+	want := []string{"<COMMAND>ls", "<COMMAND>ls</COMMAND>"}
+	if len(received) != len(want) || received[0] != want[0] || received[1] != want[1] {
+		t.Errorf("received = %q, want %q", received, want)
+	}
+}
+
 // TestOllamaChatNetworkError はネットワークエラーのテストする
 func TestOllamaChatNetworkError(t *testing.T) {
 	client := NewOllamaClient("http://invalid-host:99999")
@@ -493,3 +591,56 @@ func TestListModelsJSONError(t *testing.T) {
 		t.Errorf("期待されるエラーメッセージが含まれていません: %v", err)
 	}
 }
+
+// TestOllamaEmbedBatch は /api/embed での一括取得と、旧サーバーでの /api/embeddings への切り替えのテスト
+func TestOllamaEmbedBatch(t *testing.T) {
+	newServer := func(supportsEmbed bool, calls map[string]int) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls[r.URL.Path]++
+			var req map[string]interface{}
+			json.NewDecoder(r.Body).Decode(&req)
+			switch {
+			case r.URL.Path == "/api/embed" && supportsEmbed:
+				inputs := req["input"].([]interface{})
+				embeddings := make([][]float64, len(inputs))
+				for i, input := range inputs {
+					embeddings[i] = []float64{float64(len(input.(string)))}
+				}
+				json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": embeddings})
+			case r.URL.Path == "/api/embeddings":
+				json.NewEncoder(w).Encode(map[string]interface{}{"embedding": []float64{float64(len(req["prompt"].(string)))}})
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+	}
+
+	calls := map[string]int{}
+	server := newServer(true, calls)
+	defer server.Close()
+	vectors, err := NewOllamaClient(server.URL).EmbedBatch(context.Background(), "m", []string{"a", "bbb"})
+	if err != nil {
+		t.Fatalf("埋め込み取得エラー: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 3 || calls["/api/embed"] != 1 {
+		t.Fatalf("/api/embed で一括取得されていません: %v %v", vectors, calls)
+	}
+
+	legacyCalls := map[string]int{}
+	legacy := newServer(false, legacyCalls)
+	defer legacy.Close()
+	vectors, err = NewOllamaClient(legacy.URL).EmbedBatch(context.Background(), "m", []string{"a", "bbb"})
+	if err != nil {
+		t.Fatalf("旧APIへの切り替えに失敗: %v", err)
+	}
+	if len(vectors) != 2 || vectors[1][0] != 3 || legacyCalls["/api/embeddings"] != 2 {
+		t.Fatalf("/api/embeddings で1件ずつ取得されていません: %v %v", vectors, legacyCalls)
+	}
+
+	// API を明示した場合は切り替えない
+	client := NewOllamaClient(legacy.URL)
+	client.EmbedAPI = "embed"
+	if _, err := client.EmbedBatch(context.Background(), "m", []string{"a"}); err == nil {
+		t.Fatal("embed 指定時は旧APIに切り替えずエラーを返すべき")
+	}
+}
@@ -23,6 +23,11 @@ func NewPromptAdapter(provider Provider, cfg *config.Config) *PromptAdapter {
 
 // Chat はシステムプロンプトを自動追加してチャットリクエストを送信
 func (pa *PromptAdapter) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	// 添付画像を最後のユーザーメッセージに付与（非対応モデルではテキストで通知）
+	req = attachImages(ctx, req)
+	// @メンションで添付したファイルの内容を追記
+	req = attachFiles(ctx, req)
+
 	// システムプロンプトを生成
 	systemPrompt := pa.config.GenerateSystemPrompt()
 
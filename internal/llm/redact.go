@@ -0,0 +1,68 @@
+package llm
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/redact"
+)
+
+// RedactingProvider は送信前のメッセージからAPIキー・秘密鍵・パスワード等の機密情報を伏せるプロバイダー
+// 伏せる範囲は redact.Default()（設定の redaction）に従い、伏せた件数を監査ログに記録する
+// キャッシュ・監査ログにも伏せた内容だけが残るよう、それらより外側でラップする
+type RedactingProvider struct {
+	provider Provider
+}
+
+// NewRedactingProvider は機密情報を伏せるプロバイダーを作成
+func NewRedactingProvider(provider Provider) *RedactingProvider {
+	return &RedactingProvider{provider: provider}
+}
+
+// Chat はメッセージの機密情報を伏せてから元のプロバイダーに委譲
+func (rp *RedactingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	messages, result := RedactMessages(req.Messages)
+	if result.Count > 0 {
+		req.Messages = messages
+		logger.AuditContext(ctx, logger.AuditEvent{
+			Type:    logger.AuditRedaction,
+			Model:   req.Model,
+			Success: true,
+			Metadata: map[string]string{
+				"count": strconv.Itoa(result.Count),
+				"kinds": result.String(),
+			},
+		})
+	}
+	return rp.provider.Chat(ctx, req)
+}
+
+// RedactMessages はメッセージ本文の機密情報を伏せた複製と伏せた件数を返す（元のメッセージは変えない）
+func RedactMessages(messages []ChatMessage) ([]ChatMessage, redact.Result) {
+	redactor := redact.Default()
+	var total redact.Result
+	redacted := make([]ChatMessage, len(messages))
+	for i, msg := range messages {
+		var result redact.Result
+		msg.Content, result = redactor.Redact(msg.Content)
+		total.Add(result)
+		redacted[i] = msg
+	}
+	return redacted, total
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (rp *RedactingProvider) SupportsFunctionCalling() bool {
+	return rp.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (rp *RedactingProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return rp.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (rp *RedactingProvider) ListModels() ([]ModelInfo, error) {
+	return rp.provider.ListModels()
+}
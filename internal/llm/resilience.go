@@ -0,0 +1,296 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// サーキットブレーカーの状態
+const (
+	CircuitClosed   = "closed"    // 通常（リクエストを送る）
+	CircuitOpen     = "open"      // 連続失敗により停止中（クールダウンまで送らない）
+	CircuitHalfOpen = "half-open" // クールダウン後、1件だけ試行して回復を確認
+)
+
+// StatusError はLLMサーバーが成功以外のHTTPステータスを返したエラー
+type StatusError struct {
+	StatusCode int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("ollama API returned status %d", e.StatusCode)
+}
+
+// IsTransient は再試行・フェイルオーバーで回復し得るエラーか（接続失敗・タイムアウト・429・5xx）
+func IsTransient(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+	return errors.Is(err, io.ErrUnexpectedEOF) || errors.Is(err, io.EOF)
+}
+
+// Endpoint はフェイルオーバー対象の1つのLLMエンドポイント
+type Endpoint struct {
+	Name     string   // 表示名（例: ollama@http://localhost:11434）
+	Provider Provider // 実際に問い合わせるプロバイダー
+	Model    string   // 使用するモデル（空ならリクエストのモデルをそのまま使用）
+}
+
+// EndpointHealth はエンドポイントの状態（/info で表示）
+type EndpointHealth struct {
+	Name                string    `json:"name"`
+	Model               string    `json:"model,omitempty"`
+	State               string    `json:"state"`
+	Active              bool      `json:"active"` // 直近の応答を返したエンドポイント
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	LastError           string    `json:"last_error,omitempty"`
+	LastSuccess         time.Time `json:"last_success,omitempty"`
+	RetryAt             time.Time `json:"retry_at,omitempty"` // open の場合、次に試行する時刻
+}
+
+// endpointState はエンドポイント毎のサーキットブレーカー
+type endpointState struct {
+	Endpoint
+	failures    int
+	openedAt    time.Time // ゼロ値なら closed
+	trial       bool      // half-open の試行中
+	lastError   string
+	lastSuccess time.Time
+}
+
+// ResilientProvider は一時的なエラーを指数バックオフで再試行し、
+// 停止中のエンドポイントをサーキットブレーカーで切り離して代替エンドポイントへ切り替えるProviderラッパー
+type ResilientProvider struct {
+	endpoints      []*endpointState
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+	threshold      int
+	cooldown       time.Duration
+
+	mu     sync.Mutex
+	active int
+	now    func() time.Time
+	sleep  func(ctx context.Context, d time.Duration) error
+}
+
+// NewResilientProvider はエンドポイント（先頭がプライマリ）と設定から再試行付きプロバイダーを作成
+func NewResilientProvider(endpoints []Endpoint, cfg config.ResilienceConfig) *ResilientProvider {
+	defaults := config.DefaultResilienceConfig()
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = defaults.FailureThreshold
+	}
+	if cfg.InitialBackoffMs <= 0 {
+		cfg.InitialBackoffMs = defaults.InitialBackoffMs
+	}
+	if cfg.MaxBackoffMs < cfg.InitialBackoffMs {
+		cfg.MaxBackoffMs = cfg.InitialBackoffMs
+	}
+
+	rp := &ResilientProvider{
+		maxRetries:     cfg.MaxRetries,
+		initialBackoff: time.Duration(cfg.InitialBackoffMs) * time.Millisecond,
+		maxBackoff:     time.Duration(cfg.MaxBackoffMs) * time.Millisecond,
+		threshold:      cfg.FailureThreshold,
+		cooldown:       time.Duration(cfg.CooldownSeconds) * time.Second,
+		now:            time.Now,
+		sleep:          sleepContext,
+	}
+	for _, endpoint := range endpoints {
+		rp.endpoints = append(rp.endpoints, &endpointState{Endpoint: endpoint})
+	}
+	return rp
+}
+
+// Chat は利用可能なエンドポイントに順に問い合わせ、最初に成功した応答を返す
+func (rp *ResilientProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	var lastErr error
+	for i, endpoint := range rp.endpoints {
+		if !rp.allow(endpoint) {
+			continue
+		}
+
+		endpointReq := req
+		if endpoint.Model != "" {
+			endpointReq.Model = endpoint.Model
+		}
+		resp, err := rp.chatWithRetry(ctx, endpoint, endpointReq)
+		if err == nil {
+			rp.recordSuccess(i)
+			return resp, nil
+		}
+		// 中断や、リクエスト自体の誤り（どのエンドポイントでも失敗する）は切り替えずに返す
+		if ctx.Err() != nil || !IsTransient(err) {
+			rp.release(endpoint)
+			return nil, err
+		}
+		rp.recordFailure(endpoint, err)
+		lastErr = fmt.Errorf("%s: %w", endpoint.Name, err)
+	}
+
+	if lastErr == nil {
+		return nil, fmt.Errorf("all LLM endpoints are unavailable (circuit open, retrying after cooldown)")
+	}
+	return nil, lastErr
+}
+
+// chatWithRetry は一時的なエラーを指数バックオフで再試行
+func (rp *ResilientProvider) chatWithRetry(ctx context.Context, endpoint *endpointState, req ChatRequest) (*ChatResponse, error) {
+	backoff := rp.initialBackoff
+	for attempt := 0; ; attempt++ {
+		resp, err := endpoint.Provider.Chat(ctx, req)
+		if err == nil || attempt >= rp.maxRetries || !IsTransient(err) || ctx.Err() != nil {
+			return resp, err
+		}
+		if sleepErr := rp.sleep(ctx, backoff); sleepErr != nil {
+			return nil, err
+		}
+		backoff *= 2
+		if backoff > rp.maxBackoff {
+			backoff = rp.maxBackoff
+		}
+	}
+}
+
+// allow はエンドポイントに送ってよいか（open中はクールダウン経過後に1件だけ試行）
+func (rp *ResilientProvider) allow(endpoint *endpointState) bool {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	if endpoint.openedAt.IsZero() {
+		return true
+	}
+	if rp.now().Before(endpoint.openedAt.Add(rp.cooldown)) || endpoint.trial {
+		return false
+	}
+	endpoint.trial = true
+	return true
+}
+
+// release は一時的でないエラーで終わった試行を状態を変えずに解放
+func (rp *ResilientProvider) release(endpoint *endpointState) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	endpoint.trial = false
+}
+
+func (rp *ResilientProvider) recordSuccess(index int) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	endpoint := rp.endpoints[index]
+	endpoint.failures = 0
+	endpoint.openedAt = time.Time{}
+	endpoint.trial = false
+	endpoint.lastSuccess = rp.now()
+	rp.active = index
+}
+
+func (rp *ResilientProvider) recordFailure(endpoint *endpointState, err error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	endpoint.failures++
+	endpoint.lastError = err.Error()
+	// half-open の試行が失敗した場合は即座に再び open
+	if endpoint.trial || endpoint.failures >= rp.threshold {
+		endpoint.openedAt = rp.now()
+	}
+	endpoint.trial = false
+}
+
+// Health は各エンドポイントのサーキットブレーカーの状態を返す
+func (rp *ResilientProvider) Health() []EndpointHealth {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+
+	health := make([]EndpointHealth, 0, len(rp.endpoints))
+	for i, endpoint := range rp.endpoints {
+		status := EndpointHealth{
+			Name:                endpoint.Name,
+			Model:               endpoint.Model,
+			State:               CircuitClosed,
+			Active:              i == rp.active,
+			ConsecutiveFailures: endpoint.failures,
+			LastError:           endpoint.lastError,
+			LastSuccess:         endpoint.lastSuccess,
+		}
+		if !endpoint.openedAt.IsZero() {
+			status.RetryAt = endpoint.openedAt.Add(rp.cooldown)
+			status.State = CircuitOpen
+			if !rp.now().Before(status.RetryAt) {
+				status.State = CircuitHalfOpen
+			}
+		}
+		health = append(health, status)
+	}
+	return health
+}
+
+// Pinger はサーバーへの到達を確認できるプロバイダー
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Probe はいずれかのエンドポイントに到達できるか確認する（起動時・オフライン中の復帰確認）
+// 到達できたエンドポイントはサーキットブレーカーを閉じ、クールダウンを待たずに使えるようにする
+func (rp *ResilientProvider) Probe(ctx context.Context) error {
+	var failures []string
+	for i, endpoint := range rp.endpoints {
+		pinger, ok := endpoint.Provider.(Pinger)
+		if !ok {
+			return nil // 確認できないプロバイダーは到達可能とみなす
+		}
+		if err := pinger.Ping(ctx); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", endpoint.Name, err))
+			continue
+		}
+		rp.recordSuccess(i)
+		return nil
+	}
+	return fmt.Errorf("no LLM endpoint is reachable (%s)", strings.Join(failures, "; "))
+}
+
+// SupportsFunctionCalling はプライマリに委譲
+func (rp *ResilientProvider) SupportsFunctionCalling() bool {
+	return rp.endpoints[0].Provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo はプライマリに委譲
+func (rp *ResilientProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return rp.endpoints[0].Provider.GetModelInfo(model)
+}
+
+// ListModels はプライマリに委譲
+func (rp *ResilientProvider) ListModels() ([]ModelInfo, error) {
+	return rp.endpoints[0].Provider.ListModels()
+}
+
+// sleepContext は中断可能な待機
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,189 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// flakyProvider は指定した回数だけ失敗してから応答するテスト用プロバイダー
+type flakyProvider struct {
+	countingProvider
+	failures int
+	err      error
+	models   []string
+}
+
+func (p *flakyProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.models = append(p.models, req.Model)
+	if p.failures != 0 {
+		p.failures--
+		p.calls++
+		return nil, p.err
+	}
+	return p.countingProvider.Chat(ctx, req)
+}
+
+func newTestResilientProvider(endpoints []Endpoint, cfg config.ResilienceConfig) (*ResilientProvider, *time.Time) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	rp := NewResilientProvider(endpoints, cfg)
+	rp.now = func() time.Time { return now }
+	rp.sleep = func(ctx context.Context, d time.Duration) error { return nil }
+	return rp, &now
+}
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{&StatusError{StatusCode: 503}, true},
+		{&StatusError{StatusCode: 429}, true},
+		{fmt.Errorf("wrapped: %w", &StatusError{StatusCode: 404}), false},
+		{context.Canceled, false},
+		{fmt.Errorf("failed to decode response: invalid"), false},
+	}
+	for _, tt := range tests {
+		if got := IsTransient(tt.err); got != tt.want {
+			t.Errorf("IsTransient(%v) = %t, 期待値: %t", tt.err, got, tt.want)
+		}
+	}
+
+	// 接続できないサーバーへの送信は一時的なエラー
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	_, err := NewOllamaClient(server.URL).Chat(context.Background(), userRequest("m", "hi"))
+	if !IsTransient(err) {
+		t.Errorf("接続失敗は一時的なエラーのはず: %v", err)
+	}
+}
+
+func TestResilientProviderRetriesTransientErrors(t *testing.T) {
+	primary := &flakyProvider{failures: 2, err: &StatusError{StatusCode: 503}}
+	rp, _ := newTestResilientProvider([]Endpoint{{Name: "primary", Provider: primary}}, config.DefaultResilienceConfig())
+
+	resp, err := rp.Chat(context.Background(), userRequest("m", "hi"))
+	if err != nil || resp.Message.Content != "answer: hi" {
+		t.Fatalf("再試行で回復するはず: %v", err)
+	}
+	if primary.calls != 3 {
+		t.Errorf("呼び出し回数: %d", primary.calls)
+	}
+}
+
+func TestResilientProviderDoesNotRetryPermanentErrors(t *testing.T) {
+	primary := &flakyProvider{failures: 1, err: &StatusError{StatusCode: 400}}
+	fallback := &flakyProvider{}
+	rp, _ := newTestResilientProvider([]Endpoint{{Name: "primary", Provider: primary}, {Name: "fallback", Provider: fallback}}, config.DefaultResilienceConfig())
+
+	if _, err := rp.Chat(context.Background(), userRequest("m", "hi")); err == nil {
+		t.Fatal("400はそのままエラーになるはず")
+	}
+	if primary.calls != 1 || fallback.calls != 0 {
+		t.Errorf("再試行・切り替えしないはず: primary=%d fallback=%d", primary.calls, fallback.calls)
+	}
+}
+
+func TestResilientProviderFailoverAndCircuitBreaker(t *testing.T) {
+	cfg := config.DefaultResilienceConfig()
+	cfg.MaxRetries = 0
+	cfg.FailureThreshold = 2
+	cfg.CooldownSeconds = 30
+
+	primary := &flakyProvider{failures: -1, err: &StatusError{StatusCode: 502}}
+	fallback := &flakyProvider{}
+	rp, now := newTestResilientProvider([]Endpoint{
+		{Name: "primary", Provider: primary},
+		{Name: "fallback", Provider: fallback, Model: "small:3b"},
+	}, cfg)
+
+	// プライマリの停止中は代替エンドポイントのモデルで応答
+	for i := 0; i < 3; i++ {
+		if _, err := rp.Chat(context.Background(), userRequest("big:14b", "hi")); err != nil {
+			t.Fatalf("フェイルオーバーするはず: %v", err)
+		}
+	}
+	if primary.calls != 2 {
+		t.Errorf("ブレーカーが開いた後はプライマリに送らないはず: %d", primary.calls)
+	}
+	if fallback.models[0] != "small:3b" {
+		t.Errorf("代替エンドポイントのモデル: %v", fallback.models)
+	}
+
+	health := rp.Health()
+	if health[0].State != CircuitOpen || health[0].ConsecutiveFailures != 2 || !health[1].Active {
+		t.Errorf("状態: %+v", health)
+	}
+
+	// クールダウン後は1件だけ試行し、回復すれば閉じる
+	*now = now.Add(31 * time.Second)
+	if state := rp.Health()[0].State; state != CircuitHalfOpen {
+		t.Errorf("クールダウン後の状態: %s", state)
+	}
+	primary.failures = 0
+	if _, err := rp.Chat(context.Background(), userRequest("big:14b", "hi")); err != nil {
+		t.Fatal(err)
+	}
+	health = rp.Health()
+	if health[0].State != CircuitClosed || !health[0].Active || health[0].ConsecutiveFailures != 0 {
+		t.Errorf("回復後の状態: %+v", health[0])
+	}
+	if primary.models[len(primary.models)-1] != "big:14b" {
+		t.Errorf("プライマリにはリクエストのモデルを送るはず: %v", primary.models)
+	}
+}
+
+func TestResilientProviderAllEndpointsDown(t *testing.T) {
+	cfg := config.DefaultResilienceConfig()
+	cfg.MaxRetries = 0
+	cfg.FailureThreshold = 1
+
+	primary := &flakyProvider{failures: -1, err: &StatusError{StatusCode: 500}}
+	rp, _ := newTestResilientProvider([]Endpoint{{Name: "primary", Provider: primary}}, cfg)
+
+	if _, err := rp.Chat(context.Background(), userRequest("m", "hi")); err == nil {
+		t.Fatal("エラーになるはず")
+	}
+	// ブレーカーが開いている間は送らずにエラー
+	if _, err := rp.Chat(context.Background(), userRequest("m", "hi")); err == nil || primary.calls != 1 {
+		t.Errorf("送信せずにエラーになるはず: err=%v calls=%d", err, primary.calls)
+	}
+}
+
+func TestResilientProviderProbe(t *testing.T) {
+	cfg := config.DefaultResilienceConfig()
+	cfg.MaxRetries = 0
+	cfg.FailureThreshold = 1
+
+	down := httptest.NewServer(http.NotFoundHandler())
+	down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/version" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `{"version":"0.5.0"}`)
+	}))
+	defer up.Close()
+
+	rp, _ := newTestResilientProvider([]Endpoint{{Name: "down", Provider: NewOllamaClient(down.URL)}}, cfg)
+	if err := rp.Probe(context.Background()); err == nil || !strings.Contains(err.Error(), "down") {
+		t.Errorf("到達できなければエンドポイント名付きのエラーのはず: %v", err)
+	}
+
+	// 到達できたエンドポイントはブレーカーが閉じる
+	rp, _ = newTestResilientProvider([]Endpoint{{Name: "down", Provider: NewOllamaClient(down.URL)}, {Name: "up", Provider: NewOllamaClient(up.URL)}}, cfg)
+	rp.recordFailure(rp.endpoints[1], fmt.Errorf("connection refused"))
+	if err := rp.Probe(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if health := rp.Health(); health[1].State != CircuitClosed || !health[1].Active {
+		t.Errorf("health = %+v", health[1])
+	}
+}
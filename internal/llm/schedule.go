@@ -0,0 +1,58 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/glkt/vyb-code/internal/scheduler"
+)
+
+// ScheduledProvider はスケジューラーで同時リクエスト数を制限するプロバイダー
+// 上限を超えたリクエストは待機し、ユーザーが待っているリクエストがバックグラウンドの処理より先に送られる
+// キャッシュヒットで枠を使わないよう、CachingProviderより内側でラップする
+type ScheduledProvider struct {
+	provider  Provider
+	scheduler *scheduler.Scheduler
+	priority  scheduler.Priority // コンテキストで優先度が指定されていない場合の優先度
+}
+
+// NewScheduledProvider は同時リクエスト数を制限するプロバイダーを作成
+func NewScheduledProvider(provider Provider, sched *scheduler.Scheduler) *ScheduledProvider {
+	return &ScheduledProvider{provider: provider, scheduler: sched, priority: scheduler.PriorityInteractive}
+}
+
+// WithPriority はコンテキストで指定がない場合に priority で待機するコピーを返す
+// （圧縮の検証等、呼び出し側で優先度を指定できない裏の処理用）
+func (sp *ScheduledProvider) WithPriority(priority scheduler.Priority) *ScheduledProvider {
+	copied := *sp
+	copied.priority = priority
+	return &copied
+}
+
+// Chat は空きを待ってから元のプロバイダーに委譲
+func (sp *ScheduledProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	priority, ok := scheduler.PriorityFrom(ctx)
+	if !ok {
+		priority = sp.priority
+	}
+	release, err := sp.scheduler.AcquirePriority(ctx, scheduler.ClassLLM, priority)
+	if err != nil {
+		return nil, err
+	}
+	defer release()
+	return sp.provider.Chat(ctx, req)
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (sp *ScheduledProvider) SupportsFunctionCalling() bool {
+	return sp.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (sp *ScheduledProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return sp.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (sp *ScheduledProvider) ListModels() ([]ModelInfo, error) {
+	return sp.provider.ListModels()
+}
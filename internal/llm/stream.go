@@ -0,0 +1,37 @@
+package llm
+
+import (
+	"context"
+	"strings"
+)
+
+// streamHandlerKey は応答の受信途中の本文を受け取る関数のコンテキストキー
+type streamHandlerKey struct{}
+
+// WithStreamHandler は応答の生成途中の本文を受け取る関数をコンテキストに設定する
+// ストリーミングに対応したプロバイダー（Ollama）は Chat をストリーミングで送り、断片を受信する度に
+// それまでに受信した本文全体を渡す。ラッパーはコンテキストをそのまま渡すため途中の層に手を加える必要はない
+//
+// キャッシュヒットでは呼ばれず、再試行・フェイルオーバーでは本文が最初から渡し直されるため、
+// 受け取り側は前回の続きでない本文を受け取ったら読み進めるのをやめ、Chat が返す応答を正とすること
+func WithStreamHandler(ctx context.Context, handler func(content string)) context.Context {
+	return context.WithValue(ctx, streamHandlerKey{}, handler)
+}
+
+// streamHandlerFrom はコンテキストに設定された受け取り先を返す（なければnil）
+func streamHandlerFrom(ctx context.Context) func(content string) {
+	handler, _ := ctx.Value(streamHandlerKey{}).(func(content string))
+	return handler
+}
+
+// chatStreamToHandler はストリーミングで問い合わせ、受信した本文全体を handler に渡す
+func (c *OllamaClient) chatStreamToHandler(ctx context.Context, req ChatRequest, handler func(content string)) (*ChatResponse, error) {
+	var received strings.Builder
+	return c.ChatStream(ctx, req, func(chunk *ChatResponse) {
+		if chunk.Message.Content == "" {
+			return
+		}
+		received.WriteString(chunk.Message.Content)
+		handler(received.String())
+	})
+}
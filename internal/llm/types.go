@@ -5,8 +5,9 @@ import "context"
 // ChatMessage represents a single message in the conversation
 // LLMとの会話における1つのメッセージ（ユーザーまたはAIからの発言）
 type ChatMessage struct {
-	Role    string `json:"role"`    // "user" or "assistant" - 発言者の役割
-	Content string `json:"content"` // Message content - メッセージの内容
+	Role    string   `json:"role"`             // "user" or "assistant" - 発言者の役割
+	Content string   `json:"content"`          // Message content - メッセージの内容
+	Images  []string `json:"images,omitempty"` // Base64 images for multimodal models - マルチモーダルモデル向けの画像（base64）
 }
 
 // ChatRequest represents a request to the LLM API
@@ -23,8 +24,43 @@ type ChatRequest struct {
 // ChatResponse represents a response from the LLM API
 // LLM APIからのレスポンス
 type ChatResponse struct {
-	Message ChatMessage `json:"message"` // AI's response message - AIからの返答メッセージ
-	Done    bool        `json:"done"`    // Whether response is complete - 応答完了フラグ
+	Message         ChatMessage `json:"message"`                     // AI's response message - AIからの返答メッセージ
+	Done            bool        `json:"done"`                        // Whether response is complete - 応答完了フラグ
+	PromptEvalCount int         `json:"prompt_eval_count,omitempty"` // Prompt token count - プロンプトのトークン数
+	EvalCount       int         `json:"eval_count,omitempty"`        // Generated token count - 生成トークン数
+	Usage           *TokenUsage `json:"usage,omitempty"`             // OpenAI-compatible usage - OpenAI互換APIのトークン使用量
+
+	LoadDuration       int64 `json:"load_duration,omitempty"`        // Model load time in ns - モデル読み込み時間（ナノ秒）
+	PromptEvalDuration int64 `json:"prompt_eval_duration,omitempty"` // Prompt evaluation time in ns - プロンプト評価時間（ナノ秒）
+	EvalDuration       int64 `json:"eval_duration,omitempty"`        // Generation time in ns - 生成時間（ナノ秒）
+
+	Metrics *ResponseMetrics `json:"-"` // Client-side measurements - クライアントでの実測値（キャッシュには保存しない）
+}
+
+// TokenUsage represents token usage reported by OpenAI-compatible APIs
+// OpenAI互換APIが返すトークン使用量
+type TokenUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`     // Prompt token count - プロンプトのトークン数
+	CompletionTokens int `json:"completion_tokens"` // Generated token count - 生成トークン数
+	TotalTokens      int `json:"total_tokens"`      // Total token count - 合計トークン数
+}
+
+// PromptTokens returns the prompt token count reported by the provider (0 if unknown)
+// プロバイダーが報告したプロンプトのトークン数（不明なら0）
+func (r *ChatResponse) PromptTokens() int {
+	if r.Usage != nil && r.Usage.PromptTokens > 0 {
+		return r.Usage.PromptTokens
+	}
+	return r.PromptEvalCount
+}
+
+// CompletionTokens returns the generated token count reported by the provider (0 if unknown)
+// プロバイダーが報告した生成トークン数（不明なら0）
+func (r *ChatResponse) CompletionTokens() int {
+	if r.Usage != nil && r.Usage.CompletionTokens > 0 {
+		return r.Usage.CompletionTokens
+	}
+	return r.EvalCount
 }
 
 // ModelInfo contains information about an available LLM model
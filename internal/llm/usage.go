@@ -0,0 +1,63 @@
+package llm
+
+import (
+	"context"
+
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/usage"
+)
+
+// UsageProvider はリクエスト毎のトークン使用量を記録するプロバイダー
+// キャッシュヒットを課金対象に含めないよう、CachingProviderより内側でラップする
+type UsageProvider struct {
+	provider Provider
+	tracker  *usage.Tracker
+}
+
+// NewUsageProvider は使用量記録付きプロバイダーを作成
+func NewUsageProvider(provider Provider, tracker *usage.Tracker) *UsageProvider {
+	return &UsageProvider{provider: provider, tracker: tracker}
+}
+
+// Chat は元のプロバイダーに委譲し、成功した応答のトークン数を記録
+func (up *UsageProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := up.provider.Chat(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+
+	record := usage.Record{
+		SessionID:        logger.AuditSessionFromContext(ctx),
+		Model:            req.Model,
+		PromptTokens:     resp.PromptTokens(),
+		CompletionTokens: resp.CompletionTokens(),
+	}
+	// 件数を返さないプロバイダーでは文字数から推定
+	if record.PromptTokens == 0 {
+		record.PromptTokens = usage.EstimateTokens(formatAuditMessages(req.Messages))
+		record.Estimated = true
+	}
+	if record.CompletionTokens == 0 {
+		record.CompletionTokens = usage.EstimateTokens(resp.Message.Content)
+		record.Estimated = true
+	}
+	// 記録失敗で応答を失わないようエラーは無視
+	up.tracker.Record(record)
+
+	return resp, nil
+}
+
+// SupportsFunctionCalling は元のプロバイダーに委譲
+func (up *UsageProvider) SupportsFunctionCalling() bool {
+	return up.provider.SupportsFunctionCalling()
+}
+
+// GetModelInfo は元のプロバイダーに委譲
+func (up *UsageProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return up.provider.GetModelInfo(model)
+}
+
+// ListModels は元のプロバイダーに委譲
+func (up *UsageProvider) ListModels() ([]ModelInfo, error) {
+	return up.provider.ListModels()
+}
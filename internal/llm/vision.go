@@ -0,0 +1,133 @@
+package llm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// 添付できる画像の最大サイズ
+const maxImageBytes = 20 * 1024 * 1024
+
+// visionModelPatterns は画像入力に対応するモデル名の特徴
+var visionModelPatterns = []string{
+	"llava", "bakllava", "qwen-vl", "qwen2-vl", "qwen2.5vl", "qwen2.5-vl",
+	"minicpm-v", "llama3.2-vision", "moondream", "gemma3", "granite3.2-vision", "mistral-small3.1",
+}
+
+// SupportsVision はモデルが画像入力に対応しているかを名前から判定
+func SupportsVision(model string) bool {
+	name := strings.ToLower(model)
+	for _, pattern := range visionModelPatterns {
+		if strings.Contains(name, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeImage は画像データを検証してbase64文字列に変換
+func EncodeImage(data []byte) (string, error) {
+	if len(data) == 0 {
+		return "", fmt.Errorf("画像データが空です")
+	}
+	if len(data) > maxImageBytes {
+		return "", fmt.Errorf("画像が大きすぎます: %d bytes (上限 %d bytes)", len(data), maxImageBytes)
+	}
+	if contentType := http.DetectContentType(data); !strings.HasPrefix(contentType, "image/") {
+		return "", fmt.Errorf("画像ファイルではありません: %s", contentType)
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+// LoadImage は画像ファイルを読み込んでbase64文字列に変換
+func LoadImage(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("画像読み込みエラー: %w", err)
+	}
+	encoded, err := EncodeImage(data)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", path, err)
+	}
+	return encoded, nil
+}
+
+// imageAttachments はコンテキストで運ぶ添付画像（最初のリクエストでのみ使用）
+type imageAttachments struct {
+	mu     sync.Mutex
+	images []string
+	taken  bool
+}
+
+// imagesKey はコンテキストに添付画像を保持するキー
+type imagesKey struct{}
+
+// WithImages は次のLLMリクエストに添付する画像（base64）をコンテキストに設定
+func WithImages(ctx context.Context, images []string) context.Context {
+	if len(images) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, imagesKey{}, &imageAttachments{images: images})
+}
+
+// takeImages はコンテキストの添付画像を取り出す（同じターンの後続リクエストには付けない）
+func takeImages(ctx context.Context) []string {
+	attachments, ok := ctx.Value(imagesKey{}).(*imageAttachments)
+	if !ok {
+		return nil
+	}
+	attachments.mu.Lock()
+	defer attachments.mu.Unlock()
+	if attachments.taken {
+		return nil
+	}
+	attachments.taken = true
+	return attachments.images
+}
+
+// attachImages は添付画像を最後のユーザーメッセージに付与
+// 画像非対応モデルでは画像を送らず、添付があった旨をテキストで伝える
+func attachImages(ctx context.Context, req ChatRequest) ChatRequest {
+	images := takeImages(ctx)
+	if len(images) == 0 {
+		return req
+	}
+
+	last := -1
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == "user" {
+			last = i
+			break
+		}
+	}
+	if last < 0 {
+		return req
+	}
+
+	messages := make([]ChatMessage, len(req.Messages))
+	copy(messages, req.Messages)
+	if SupportsVision(req.Model) {
+		messages[last].Images = append(append([]string{}, messages[last].Images...), images...)
+	} else {
+		messages[last].Content += "\n\n" + i18n.T("vision.unsupported_note", len(images), req.Model)
+	}
+	req.Messages = messages
+	return req
+}
+
+// hasImages はメッセージに画像が含まれるかを判定
+func hasImages(messages []ChatMessage) bool {
+	for _, msg := range messages {
+		if len(msg.Images) > 0 {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package llm
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// recordingProvider は受け取ったリクエストを保持するテスト用プロバイダー
+type recordingProvider struct {
+	requests []ChatRequest
+}
+
+func (p *recordingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	return &ChatResponse{Message: ChatMessage{Role: "assistant", Content: "ok"}, Done: true}, nil
+}
+
+func (p *recordingProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *recordingProvider) GetModelInfo(model string) (*ModelInfo, error) {
+	return &ModelInfo{Name: model}, nil
+}
+
+func (p *recordingProvider) ListModels() ([]ModelInfo, error) { return nil, nil }
+
+// pngHeader は画像として判定される最小限のPNGシグネチャ
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR")
+
+func TestSupportsVision(t *testing.T) {
+	for model, want := range map[string]bool{
+		"llava:13b":           true,
+		"qwen2.5vl:7b":        true,
+		"llama3.2-vision":     true,
+		"qwen2.5-coder:14b":   false,
+		"deepseek-coder:6.7b": false,
+	} {
+		if got := SupportsVision(model); got != want {
+			t.Errorf("SupportsVision(%q) = %t, want %t", model, got, want)
+		}
+	}
+}
+
+func TestEncodeImage(t *testing.T) {
+	if _, err := EncodeImage(pngHeader); err != nil {
+		t.Errorf("png should be accepted: %v", err)
+	}
+	if _, err := EncodeImage([]byte("plain text")); err == nil {
+		t.Error("text should be rejected")
+	}
+	if _, err := EncodeImage(nil); err == nil {
+		t.Error("empty data should be rejected")
+	}
+}
+
+func TestPromptAdapterAttachesImagesOnce(t *testing.T) {
+	encoded, _ := EncodeImage(pngHeader)
+	base := &recordingProvider{}
+	adapter := NewPromptAdapter(NewCachingProvider(base, config.DefaultLLMCacheConfig()), config.DefaultConfig())
+
+	ctx := WithImages(context.Background(), []string{encoded})
+	for i := 0; i < 2; i++ {
+		if _, err := adapter.Chat(ctx, userRequest("llava:7b", "explain this")); err != nil {
+			t.Fatalf("Chat failed: %v", err)
+		}
+	}
+
+	if len(base.requests) != 2 {
+		t.Fatalf("image request should bypass cache, got %d requests", len(base.requests))
+	}
+	first := base.requests[0].Messages[len(base.requests[0].Messages)-1]
+	if len(first.Images) != 1 || first.Images[0] != encoded {
+		t.Errorf("first request should carry the image: %+v", first)
+	}
+	second := base.requests[1].Messages[len(base.requests[1].Messages)-1]
+	if len(second.Images) != 0 {
+		t.Error("images should only be sent with the first request of a turn")
+	}
+}
+
+func TestPromptAdapterDegradesWithoutVision(t *testing.T) {
+	encoded, _ := EncodeImage(pngHeader)
+	base := &recordingProvider{}
+	adapter := NewPromptAdapter(base, config.DefaultConfig())
+
+	ctx := WithImages(context.Background(), []string{encoded})
+	if _, err := adapter.Chat(ctx, userRequest("qwen2.5-coder:14b", "explain this")); err != nil {
+		t.Fatalf("Chat failed: %v", err)
+	}
+
+	last := base.requests[0].Messages[len(base.requests[0].Messages)-1]
+	if len(last.Images) != 0 {
+		t.Error("images should not be sent to a text-only model")
+	}
+	if !strings.HasPrefix(last.Content, "explain this") || !strings.Contains(last.Content, "qwen2.5-coder:14b") {
+		t.Errorf("user message should mention the dropped image: %q", last.Content)
+	}
+}
+
+func TestPromptAdapterAttachesFilesOnce(t *testing.T) {
+	base := &recordingProvider{}
+	adapter := NewPromptAdapter(base, config.DefaultConfig())
+
+	ctx := WithFileAttachments(context.Background(), "## Attached files\n\n### @main.go")
+	for i := 0; i < 2; i++ {
+		if _, err := adapter.Chat(ctx, userRequest("qwen2.5-coder:7b", "review @main.go")); err != nil {
+			t.Fatalf("Chat failed: %v", err)
+		}
+	}
+
+	first := base.requests[0].Messages[len(base.requests[0].Messages)-1]
+	if !strings.HasPrefix(first.Content, "review @main.go\n\n## Attached files") {
+		t.Errorf("first request should carry the file contents: %q", first.Content)
+	}
+	second := base.requests[1].Messages[len(base.requests[1].Messages)-1]
+	if second.Content != "review @main.go" {
+		t.Errorf("files should only be attached to the first request of a turn: %q", second.Content)
+	}
+}
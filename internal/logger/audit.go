@@ -0,0 +1,284 @@
+package logger
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/redact"
+)
+
+// 監査イベントの種類
+const (
+	AuditLLMRequest  = "llm_request"
+	AuditLLMResponse = "llm_response"
+	AuditCommand     = "command"
+	AuditFileWrite   = "file_write"
+	AuditToolCall    = "tool_call"
+	AuditTask        = "task"
+	AuditUserInput   = "user_input"
+	AuditAssistant   = "assistant_response"
+	AuditDiff        = "diff"
+	AuditBranch      = "branch"     // 会話の分岐の作成・切替・取り込み
+	AuditRedaction   = "redaction"  // LLMに送る前に伏せた機密情報の件数
+	AuditCodeCheck   = "code_check" // 応答中の生成コードの検証結果と修正の往復
+)
+
+// デフォルトで記録する本文の最大バイト数
+const defaultAuditMaxContentBytes = 8 * 1024
+
+// AuditEvent はセッション内でエージェントが行った1操作の記録（JSONL 1行）
+type AuditEvent struct {
+	Timestamp        time.Time         `json:"timestamp"`
+	SessionID        string            `json:"session_id"`
+	Type             string            `json:"type"`
+	Tool             string            `json:"tool,omitempty"`
+	Command          string            `json:"command,omitempty"`
+	Path             string            `json:"path,omitempty"`
+	Model            string            `json:"model,omitempty"`
+	Content          string            `json:"content,omitempty"` // プロンプト・応答・出力（切り詰め）
+	PromptTokens     int               `json:"prompt_tokens,omitempty"`
+	CompletionTokens int               `json:"completion_tokens,omitempty"`
+	LatencyMs        int64             `json:"latency_ms,omitempty"`
+	Bytes            int               `json:"bytes,omitempty"`
+	ExitCode         int               `json:"exit_code,omitempty"`
+	Success          bool              `json:"success"`
+	Error            string            `json:"error,omitempty"`
+	Metadata         map[string]string `json:"metadata,omitempty"`
+}
+
+// AuditRecorder はセッション毎のJSONLファイルに監査イベントを書き込む
+type AuditRecorder struct {
+	mu              sync.Mutex
+	dir             string
+	maxContentBytes int
+	files           map[string]*os.File
+}
+
+// NewAuditRecorder は保存先ディレクトリを指定して監査レコーダーを作成
+func NewAuditRecorder(dir string, maxContentBytes int) *AuditRecorder {
+	if maxContentBytes <= 0 {
+		maxContentBytes = defaultAuditMaxContentBytes
+	}
+	return &AuditRecorder{
+		dir:             dir,
+		maxContentBytes: maxContentBytes,
+		files:           make(map[string]*os.File),
+	}
+}
+
+// DefaultAuditDir は監査ログの保存先（~/.vyb/logs）
+func DefaultAuditDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "vyb-logs")
+	}
+	return filepath.Join(homeDir, ".vyb", "logs")
+}
+
+// Dir は保存先ディレクトリを返す
+func (r *AuditRecorder) Dir() string {
+	return r.dir
+}
+
+// Record はイベントをセッションのJSONLファイルに追記
+func (r *AuditRecorder) Record(event AuditEvent) error {
+	if event.SessionID == "" {
+		return fmt.Errorf("監査イベントにセッションIDがありません")
+	}
+	if event.Timestamp.IsZero() {
+		event.Timestamp = time.Now()
+	}
+	redactEvent(&event)
+	if len(event.Content) > r.maxContentBytes {
+		event.Content = event.Content[:r.maxContentBytes] + "...(truncated)"
+	}
+
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, ok := r.files[event.SessionID]
+	if !ok {
+		if err := os.MkdirAll(r.dir, 0700); err != nil {
+			return fmt.Errorf("監査ログディレクトリ作成失敗: %w", err)
+		}
+		file, err = os.OpenFile(AuditLogPath(r.dir, event.SessionID), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+		if err != nil {
+			return fmt.Errorf("監査ログファイル開封失敗: %w", err)
+		}
+		r.files[event.SessionID] = file
+	}
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// redactEvent は本文・コマンド・エラー・メタデータの機密情報を伏せ、伏せた件数をメタデータに残す
+func redactEvent(event *AuditEvent) {
+	redactor := redact.Default()
+	var total redact.Result
+	for _, field := range []*string{&event.Content, &event.Command, &event.Error} {
+		var result redact.Result
+		*field, result = redactor.Redact(*field)
+		total.Add(result)
+	}
+	if len(event.Metadata) > 0 {
+		// 呼び出し元のマップは書き換えない
+		metadata := make(map[string]string, len(event.Metadata)+1)
+		for key, value := range event.Metadata {
+			var result redact.Result
+			metadata[key], result = redactor.Redact(value)
+			total.Add(result)
+		}
+		event.Metadata = metadata
+	}
+	if total.Count > 0 {
+		if event.Metadata == nil {
+			event.Metadata = make(map[string]string)
+		}
+		event.Metadata["redactions"] = strconv.Itoa(total.Count)
+	}
+}
+
+// Close は開いている監査ログファイルを全て閉じる
+func (r *AuditRecorder) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var firstErr error
+	for sessionID, file := range r.files {
+		if err := file.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+		delete(r.files, sessionID)
+	}
+	return firstErr
+}
+
+// AuditLogPath はセッションの監査ログファイルパス
+func AuditLogPath(dir, sessionID string) string {
+	// パス区切りを含むIDでディレクトリ外に書き込まないようにする
+	safeID := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(sessionID)
+	return filepath.Join(dir, safeID+".jsonl")
+}
+
+// ReadAuditLog はセッションの監査ログを読み込み
+func ReadAuditLog(dir, sessionID string) ([]AuditEvent, error) {
+	file, err := os.Open(AuditLogPath(dir, sessionID))
+	if err != nil {
+		return nil, fmt.Errorf("監査ログが見つかりません: %s", sessionID)
+	}
+	defer file.Close()
+
+	var events []AuditEvent
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var event AuditEvent
+		if err := json.Unmarshal([]byte(line), &event); err != nil {
+			continue // 書き込み途中の行は無視
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// AuditSession は監査ログが存在するセッションの概要
+type AuditSession struct {
+	SessionID string    `json:"session_id"`
+	Path      string    `json:"path"`
+	Size      int64     `json:"size"`
+	Modified  time.Time `json:"modified"`
+}
+
+// ListAuditSessions は監査ログのあるセッションを新しい順に返す
+func ListAuditSessions(dir string) ([]AuditSession, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	sessions := make([]AuditSession, 0, len(paths))
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		sessions = append(sessions, AuditSession{
+			SessionID: strings.TrimSuffix(filepath.Base(path), ".jsonl"),
+			Path:      path,
+			Size:      info.Size(),
+			Modified:  info.ModTime(),
+		})
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].Modified.After(sessions[j].Modified) })
+	return sessions, nil
+}
+
+// グローバル監査レコーダー（未設定なら記録しない）
+var (
+	auditMu       sync.RWMutex
+	auditRecorder *AuditRecorder
+)
+
+// SetAuditRecorder はグローバル監査レコーダーを設定し、それまでのレコーダーを返す（nilで無効化）
+func SetAuditRecorder(recorder *AuditRecorder) *AuditRecorder {
+	auditMu.Lock()
+	defer auditMu.Unlock()
+	previous := auditRecorder
+	auditRecorder = recorder
+	return previous
+}
+
+// Audit はグローバル監査レコーダーにイベントを記録（記録失敗は処理を止めない）
+func Audit(event AuditEvent) {
+	auditMu.RLock()
+	recorder := auditRecorder
+	auditMu.RUnlock()
+	if recorder == nil || event.SessionID == "" {
+		return
+	}
+	recorder.Record(event)
+}
+
+// auditSessionKey はコンテキストにセッションIDを保持するキー
+type auditSessionKey struct{}
+
+// WithAuditSession は監査用のセッションIDをコンテキストに設定
+func WithAuditSession(ctx context.Context, sessionID string) context.Context {
+	return context.WithValue(ctx, auditSessionKey{}, sessionID)
+}
+
+// AuditSessionFromContext はコンテキストから監査用のセッションIDを取得
+func AuditSessionFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	sessionID, _ := ctx.Value(auditSessionKey{}).(string)
+	return sessionID
+}
+
+// AuditContext はコンテキストのセッションIDでイベントを記録
+func AuditContext(ctx context.Context, event AuditEvent) {
+	if event.SessionID == "" {
+		event.SessionID = AuditSessionFromContext(ctx)
+	}
+	Audit(event)
+}
@@ -0,0 +1,68 @@
+package maintenance
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+)
+
+// staleBranches はコミットが staleAfter より古い、または既定のブランチにマージ済みのローカルブランチを返す
+// 現在のブランチと既定のブランチは除く
+func staleBranches(ctx context.Context, projectDir string, staleAfter time.Duration, now time.Time) ([]StaleBranch, error) {
+	refs, err := gitexec.Run(ctx, projectDir, "for-each-ref", "--format=%(refname:short)%09%(committerdate:unix)%09%(authorname)", "refs/heads")
+	if err != nil {
+		return nil, err
+	}
+	current, _ := gitexec.Run(ctx, projectDir, "symbolic-ref", "--quiet", "--short", "HEAD")
+	current = strings.TrimSpace(current)
+	base := defaultBranch(ctx, projectDir)
+
+	merged := make(map[string]bool)
+	if out, err := gitexec.Run(ctx, projectDir, "branch", "--merged", base, "--format=%(refname:short)"); err == nil {
+		for _, name := range strings.Fields(out) {
+			merged[name] = true
+		}
+	}
+
+	var stale []StaleBranch
+	for _, line := range strings.Split(strings.TrimSpace(refs), "\n") {
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 2 || fields[0] == current || fields[0] == base {
+			continue
+		}
+		unix, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		branch := StaleBranch{Name: fields[0], LastCommit: time.Unix(unix, 0), Merged: merged[fields[0]]}
+		if len(fields) == 3 {
+			branch.Author = fields[2]
+		}
+		if branch.Merged || now.Sub(branch.LastCommit) > staleAfter {
+			stale = append(stale, branch)
+		}
+	}
+	sort.Slice(stale, func(i, j int) bool { return stale[i].LastCommit.Before(stale[j].LastCommit) })
+	return stale, nil
+}
+
+// defaultBranch はマージ済みの判定に使うブランチ（origin/HEAD の指す先、なければ main・master、どちらもなければ HEAD）
+func defaultBranch(ctx context.Context, projectDir string) string {
+	if out, err := gitexec.Run(ctx, projectDir, "symbolic-ref", "--quiet", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		if name := strings.TrimPrefix(strings.TrimSpace(out), "origin/"); name != "" {
+			if _, err := gitexec.Run(ctx, projectDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+				return name
+			}
+		}
+	}
+	for _, name := range []string{"main", "master"} {
+		if _, err := gitexec.Run(ctx, projectDir, "rev-parse", "--verify", "--quiet", "refs/heads/"+name); err == nil {
+			return name
+		}
+	}
+	return "HEAD"
+}
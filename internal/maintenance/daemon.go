@@ -0,0 +1,158 @@
+package maintenance
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// 実行予定を確認する間隔（スリープ復帰後も予定時刻を過ぎていればすぐに実行する）
+const pollInterval = time.Minute
+
+// Runner はプロジェクトの定期メンテナンスのタスクを実行する
+type Runner struct {
+	ProjectDir string
+	Config     config.DaemonConfig
+	// RefreshIndex は埋め込みインデックスを更新する（nil ならインデックスの更新を省く）
+	RefreshIndex func(ctx context.Context) (*IndexResult, error)
+	now          func() time.Time
+}
+
+func (r *Runner) clock() time.Time {
+	if r.now != nil {
+		return r.now()
+	}
+	return time.Now()
+}
+
+// Run は設定のタスクを順に実行し、結果を返す（失敗したタスクは Report.Errors に記録して続ける）
+func (r *Runner) Run(ctx context.Context) *Report {
+	report := &Report{StartedAt: r.clock(), Tasks: r.Config.Tasks}
+	for _, task := range r.Config.Tasks {
+		if ctx.Err() != nil {
+			report.addError(task, ctx.Err().Error())
+			continue
+		}
+		switch task {
+		case "vulnerabilities":
+			scanVulnerabilities(ctx, r.ProjectDir, report)
+		case "index":
+			if r.RefreshIndex == nil {
+				continue
+			}
+			result, err := r.RefreshIndex(ctx)
+			if err != nil {
+				report.addError(task, err.Error())
+			}
+			report.Index = result
+		case "branches":
+			staleAfter := time.Duration(r.Config.StaleBranchDays) * 24 * time.Hour
+			branches, err := staleBranches(ctx, r.ProjectDir, staleAfter, r.clock())
+			if err != nil {
+				report.addError(task, err.Error())
+			}
+			report.Branches = branches
+		default:
+			report.addError(task, fmt.Sprintf("不明なタスクです: %s", task))
+		}
+	}
+	report.FinishedAt = r.clock()
+	return report
+}
+
+func (report *Report) addError(task, message string) {
+	if report.Errors == nil {
+		report.Errors = make(map[string]string)
+	}
+	report.Errors[task] = message
+}
+
+// scheduledBefore は now 以前で直近の実行予定時刻（at は HH:MM、ローカル時刻）
+func scheduledBefore(at string, now time.Time) (time.Time, error) {
+	clock, err := time.Parse("15:04", at)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("実行時刻は HH:MM で指定してください: %q", at)
+	}
+	scheduled := time.Date(now.Year(), now.Month(), now.Day(), clock.Hour(), clock.Minute(), 0, 0, now.Location())
+	if scheduled.After(now) {
+		scheduled = scheduled.AddDate(0, 0, -1)
+	}
+	return scheduled, nil
+}
+
+// NextRun は lastRun（未実行ならゼロ値）以降の次の実行予定時刻
+// 前回の予定時刻に実行されていなければ（停止・スリープ中だった等） now を返す
+func NextRun(at string, lastRun, now time.Time) (time.Time, error) {
+	scheduled, err := scheduledBefore(at, now)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if lastRun.Before(scheduled) {
+		return now, nil
+	}
+	return scheduled.AddDate(0, 0, 1), nil
+}
+
+// Daemon は毎日の実行時刻にタスクを実行し、結果をストアに記録する
+type Daemon struct {
+	Runner *Runner
+	Store  *Store
+	// OnReport は実行結果を記録した後に呼ばれる（nil 可）
+	OnReport func(report *Report, err error)
+}
+
+// Run は ctx が終了するまで予定時刻にタスクを実行する（once なら1回だけ実行して戻る）
+func (d *Daemon) Run(ctx context.Context, once bool) error {
+	if _, err := scheduledBefore(d.Runner.Config.At, time.Now()); err != nil {
+		return err
+	}
+	release, err := d.Store.Lock(d.Runner.ProjectDir)
+	if err != nil {
+		return err
+	}
+	defer release()
+
+	if once {
+		return d.runOnce(ctx)
+	}
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		state, err := d.Store.Load(d.Runner.ProjectDir)
+		if err != nil {
+			state = &State{}
+		}
+		var lastRun time.Time
+		if latest := state.Latest(); latest != nil {
+			lastRun = latest.StartedAt
+		}
+		now := d.Runner.clock()
+		if next, _ := NextRun(d.Runner.Config.At, lastRun, now); !next.After(now) {
+			if err := d.runOnce(ctx); err != nil && ctx.Err() == nil {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Daemon) runOnce(ctx context.Context) error {
+	report := d.Runner.Run(ctx)
+	if ctx.Err() != nil {
+		// 中断した実行は記録しない（次回起動時に改めて実行する）
+		return nil
+	}
+	err := d.Store.Record(d.Runner.ProjectDir, report)
+	if d.OnReport != nil {
+		d.OnReport(report, err)
+	}
+	return err
+}
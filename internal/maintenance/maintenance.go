@@ -0,0 +1,259 @@
+package maintenance
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/process"
+)
+
+// 保存しておく実行結果の数（プロジェクト毎）
+const maxReports = 14
+
+// Vulnerability は依存関係の既知の脆弱性
+type Vulnerability struct {
+	ID       string   `json:"id"`                 // スキャナーの識別子（GO-2024-0001, GHSA-..., PYSEC-...）
+	Aliases  []string `json:"aliases,omitempty"`  // CVE 等の別名
+	Package  string   `json:"package"`            // 影響を受けるモジュール・パッケージ
+	Version  string   `json:"version,omitempty"`  // 使用中のバージョン（範囲の場合あり）
+	FixedIn  string   `json:"fixed_in,omitempty"` // 修正されたバージョン
+	Severity string   `json:"severity,omitempty"`
+	Summary  string   `json:"summary,omitempty"`
+	Scanner  string   `json:"scanner"` // govulncheck, npm-audit, pip-audit
+}
+
+// Key は実行間で同じ脆弱性を識別するキー
+func (v Vulnerability) Key() string {
+	return v.Scanner + ":" + v.ID + ":" + v.Package
+}
+
+// DisplayID は表示用の識別子（CVE の別名があれば CVE）
+func (v Vulnerability) DisplayID() string {
+	for _, alias := range v.Aliases {
+		if strings.HasPrefix(alias, "CVE-") {
+			return alias
+		}
+	}
+	return v.ID
+}
+
+// StaleBranch は古い・マージ済みのローカルブランチ
+type StaleBranch struct {
+	Name       string    `json:"name"`
+	LastCommit time.Time `json:"last_commit"`
+	Author     string    `json:"author,omitempty"`
+	Merged     bool      `json:"merged"` // 既定のブランチにマージ済み
+}
+
+// IndexResult は埋め込みインデックスの更新結果
+type IndexResult struct {
+	Files    int    `json:"files"`
+	Embedded int    `json:"embedded"` // 新規・変更のため埋め込みを生成したファイル数
+	Removed  int    `json:"removed"`  // 削除されたためインデックスから除いたファイル数
+	Model    string `json:"model,omitempty"`
+}
+
+// Report は1回の定期メンテナンスの結果
+type Report struct {
+	StartedAt       time.Time         `json:"started_at"`
+	FinishedAt      time.Time         `json:"finished_at"`
+	Tasks           []string          `json:"tasks"`
+	Scanners        []string          `json:"scanners,omitempty"` // 実行した脆弱性スキャナー
+	Vulnerabilities []Vulnerability   `json:"vulnerabilities,omitempty"`
+	Branches        []StaleBranch     `json:"branches,omitempty"`
+	Index           *IndexResult      `json:"index,omitempty"`
+	Errors          map[string]string `json:"errors,omitempty"` // タスク（またはスキャナー）毎の失敗
+}
+
+// State はプロジェクトの定期メンテナンスの記録
+type State struct {
+	ProjectDir string    `json:"project_dir"`
+	Reports    []*Report `json:"reports"` // 新しい順
+	SeenAt     time.Time `json:"seen_at,omitempty"`
+	// Seen は要約を表示済みの脆弱性（Vulnerability.Key）。以降の要約ではこれ以外を新しい脆弱性とする
+	Seen []string `json:"seen,omitempty"`
+}
+
+// Latest は直近の実行結果（未実行なら nil）
+func (s *State) Latest() *Report {
+	if len(s.Reports) == 0 {
+		return nil
+	}
+	return s.Reports[0]
+}
+
+// Summary は前回の要約以降の実行結果のまとめ
+type Summary struct {
+	Since              time.Time // 前回の要約（ゼロ値なら初めての要約）
+	LastRun            time.Time
+	Runs               int // 前回の要約以降の実行回数
+	NewVulnerabilities []Vulnerability
+	Vulnerabilities    int // 直近の実行で見つかった脆弱性の総数
+	Resolved           int // 要約済みで直近の実行では見つからなかった脆弱性の数
+	StaleBranches      []StaleBranch
+	Index              *IndexResult
+	Errors             map[string]string
+}
+
+// Unseen は前回の要約以降の実行結果をまとめる（新しい実行がなければ nil）
+func (s *State) Unseen() *Summary {
+	latest := s.Latest()
+	if latest == nil || !latest.FinishedAt.After(s.SeenAt) {
+		return nil
+	}
+
+	summary := &Summary{
+		Since:         s.SeenAt,
+		LastRun:       latest.FinishedAt,
+		StaleBranches: latest.Branches,
+		Index:         latest.Index,
+		Errors:        latest.Errors,
+	}
+	for _, report := range s.Reports {
+		if report.FinishedAt.After(s.SeenAt) {
+			summary.Runs++
+		}
+	}
+
+	seen := make(map[string]bool, len(s.Seen))
+	for _, key := range s.Seen {
+		seen[key] = true
+	}
+	current := make(map[string]bool)
+	for _, vuln := range latest.Vulnerabilities {
+		current[vuln.Key()] = true
+		if !seen[vuln.Key()] {
+			summary.NewVulnerabilities = append(summary.NewVulnerabilities, vuln)
+		}
+	}
+	summary.Vulnerabilities = len(latest.Vulnerabilities)
+	for key := range seen {
+		if !current[key] {
+			summary.Resolved++
+		}
+	}
+	return summary
+}
+
+// MarkSeen は直近の実行結果を要約済みにする
+func (s *State) MarkSeen(now time.Time) {
+	s.SeenAt = now
+	s.Seen = nil
+	if latest := s.Latest(); latest != nil {
+		for _, vuln := range latest.Vulnerabilities {
+			s.Seen = append(s.Seen, vuln.Key())
+		}
+		sort.Strings(s.Seen)
+	}
+}
+
+// DefaultDir は定期メンテナンスの記録の既定の保存先（~/.vyb/maintenance）
+func DefaultDir() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".vyb", "maintenance")
+	}
+	return filepath.Join(home, ".vyb", "maintenance")
+}
+
+// Store はプロジェクト毎の定期メンテナンスの記録（<dir>/<project hash>.json）
+type Store struct {
+	dir string
+}
+
+// NewStore は保存先を指定してストアを作成
+func NewStore(dir string) *Store {
+	return &Store{dir: dir}
+}
+
+func (s *Store) path(projectDir, ext string) string {
+	sum := sha256.Sum256([]byte(filepath.Clean(projectDir)))
+	return filepath.Join(s.dir, hex.EncodeToString(sum[:8])+ext)
+}
+
+// Load はプロジェクトの記録を読み込む（未実行なら空の記録）
+func (s *Store) Load(projectDir string) (*State, error) {
+	data, err := os.ReadFile(s.path(projectDir, ".json"))
+	if os.IsNotExist(err) {
+		return &State{ProjectDir: projectDir}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("定期メンテナンスの記録の読み込みエラー: %w", err)
+	}
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("定期メンテナンスの記録の形式エラー: %w", err)
+	}
+	return &state, nil
+}
+
+// Save はプロジェクトの記録を書き込む（一時ファイルに書いてから置き換える）
+func (s *Store) Save(state *State) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return fmt.Errorf("定期メンテナンスの保存先作成エラー: %w", err)
+	}
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("定期メンテナンスの記録のJSON変換エラー: %w", err)
+	}
+	path := s.path(state.ProjectDir, ".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("定期メンテナンスの記録の保存エラー: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("定期メンテナンスの記録の保存エラー: %w", err)
+	}
+	return nil
+}
+
+// Record は実行結果を追加する（古いものから maxReports を超えた分を捨てる）
+func (s *Store) Record(projectDir string, report *Report) error {
+	state, err := s.Load(projectDir)
+	if err != nil {
+		// 壊れた記録は作り直す
+		state = &State{ProjectDir: projectDir}
+	}
+	state.Reports = append([]*Report{report}, state.Reports...)
+	if len(state.Reports) > maxReports {
+		state.Reports = state.Reports[:maxReports]
+	}
+	return s.Save(state)
+}
+
+// Lock はプロジェクトのデーモンが1つだけ動作するようPIDファイルを作成し、解除する関数を返す
+func (s *Store) Lock(projectDir string) (func(), error) {
+	if pid := s.RunningPID(projectDir); pid != 0 {
+		return nil, fmt.Errorf("このプロジェクトのデーモンは既に動作中です (PID %d)", pid)
+	}
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return nil, fmt.Errorf("定期メンテナンスの保存先作成エラー: %w", err)
+	}
+	path := s.path(projectDir, ".pid")
+	if err := os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0600); err != nil {
+		return nil, fmt.Errorf("PIDファイルの作成エラー: %w", err)
+	}
+	return func() { os.Remove(path) }, nil
+}
+
+// RunningPID は動作中のプロジェクトのデーモンのPID（動作していなければ0）
+func (s *Store) RunningPID(projectDir string) int {
+	data, err := os.ReadFile(s.path(projectDir, ".pid"))
+	if err != nil {
+		return 0
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 || !process.Alive(pid) {
+		return 0
+	}
+	return pid
+}
@@ -0,0 +1,143 @@
+package maintenance
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestParseScannerOutput(t *testing.T) {
+	// osv メッセージが finding の後に来ても、同じ経路の重複は1件にまとめる
+	govulncheck := `{"config":{"scanner_name":"govulncheck"}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v0.2.0","trace":[{"module":"example.com/lib","version":"v0.1.0","function":"Parse"}]}}
+{"finding":{"osv":"GO-2024-0001","fixed_version":"v0.2.0","trace":[{"module":"example.com/lib","version":"v0.1.0","function":"Load"}]}}
+{"osv":{"id":"GO-2024-0001","aliases":["CVE-2024-1234","GHSA-xxxx-yyyy-zzzz"],"summary":"Panic on malformed input"}}
+`
+	vulns, err := parseGovulncheck([]byte(govulncheck))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 1 || vulns[0].DisplayID() != "CVE-2024-1234" || vulns[0].Package != "example.com/lib" || vulns[0].FixedIn != "v0.2.0" || vulns[0].Summary == "" {
+		t.Errorf("govulncheck の結果を1件にまとめるはず: %+v", vulns)
+	}
+
+	npm := `{"vulnerabilities":{
+		"lodash":{"via":[{"source":1065,"name":"lodash","title":"Prototype Pollution","url":"https://github.com/advisories/GHSA-p6mc-m468-83gw","severity":"high","range":"<4.17.19"}],"fixAvailable":{"name":"lodash","version":"4.17.21"}},
+		"uses-lodash":{"via":["lodash"],"fixAvailable":true}}}`
+	vulns, err = parseNpmAudit([]byte(npm))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 1 || vulns[0].ID != "GHSA-p6mc-m468-83gw" || vulns[0].FixedIn != "4.17.21" || vulns[0].Severity != "high" {
+		t.Errorf("他のパッケージ経由の参照は数えないはず: %+v", vulns)
+	}
+
+	pip := `[{"name":"flask","version":"0.5","vulns":[{"id":"PYSEC-2019-179","fix_versions":["1.0"],"aliases":["CVE-2019-1010083"],"description":"Denial of service\nDetails"}]},{"name":"requests","version":"2.31.0","vulns":[]}]`
+	vulns, err = parsePipAudit([]byte(pip))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vulns) != 1 || vulns[0].DisplayID() != "CVE-2019-1010083" || vulns[0].Summary != "Denial of service" {
+		t.Errorf("古い形式の pip-audit の出力も解析するはず: %+v", vulns)
+	}
+
+	if _, err := parseNpmAudit([]byte(`{"error":{"summary":"No lockfile"}}`)); err == nil {
+		t.Error("npm audit のエラーを返すはず")
+	}
+}
+
+func TestStoreSummary(t *testing.T) {
+	store := NewStore(t.TempDir())
+	project := "/work/project"
+	start := time.Date(2026, 10, 14, 3, 0, 0, 0, time.Local)
+	old := Vulnerability{ID: "GO-1", Package: "a", Scanner: "govulncheck"}
+
+	if err := store.Record(project, &Report{FinishedAt: start, Vulnerabilities: []Vulnerability{old}}); err != nil {
+		t.Fatal(err)
+	}
+	state, _ := store.Load(project)
+	if summary := state.Unseen(); summary == nil || len(summary.NewVulnerabilities) != 1 {
+		t.Fatalf("初めての要約では全ての脆弱性が新しいはず: %+v", summary)
+	}
+	state.MarkSeen(start.Add(time.Hour))
+	store.Save(state)
+	if state.Unseen() != nil {
+		t.Error("要約済みの結果は表示しないはず")
+	}
+
+	// 翌日の実行で2件増え、1件解消
+	added := []Vulnerability{{ID: "GO-2", Package: "b", Scanner: "govulncheck"}, {ID: "GHSA-3", Package: "c", Scanner: "npm-audit"}}
+	store.Record(project, &Report{FinishedAt: start.Add(12 * time.Hour)})
+	store.Record(project, &Report{FinishedAt: start.Add(24 * time.Hour), Vulnerabilities: added, Branches: []StaleBranch{{Name: "old-feature"}}})
+	state, _ = store.Load(project)
+	summary := state.Unseen()
+	if summary == nil || summary.Runs != 2 || len(summary.NewVulnerabilities) != 2 || summary.Resolved != 1 || len(summary.StaleBranches) != 1 {
+		t.Fatalf("前回の要約以降の結果をまとめるはず: %+v", summary)
+	}
+
+	for i := 0; i < maxReports+3; i++ {
+		store.Record(project, &Report{FinishedAt: start})
+	}
+	if state, _ := store.Load(project); len(state.Reports) != maxReports {
+		t.Errorf("古い実行結果は捨てるはず: %d", len(state.Reports))
+	}
+}
+
+func TestNextRun(t *testing.T) {
+	now := time.Date(2026, 10, 15, 10, 0, 0, 0, time.Local)
+	tests := []struct {
+		name    string
+		lastRun time.Time
+		want    time.Time
+	}{
+		{"未実行ならすぐ実行", time.Time{}, now},
+		{"今日の予定時刻に実行済み", time.Date(2026, 10, 15, 3, 0, 5, 0, time.Local), time.Date(2026, 10, 16, 3, 0, 0, 0, time.Local)},
+		{"予定時刻に停止していた", time.Date(2026, 10, 14, 3, 0, 0, 0, time.Local), now},
+	}
+	for _, tt := range tests {
+		got, err := NextRun("03:00", tt.lastRun, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("%s: %v %v (want %v)", tt.name, got, err, tt.want)
+		}
+	}
+	if _, err := NextRun("25:00", time.Time{}, now); err == nil {
+		t.Error("不正な時刻はエラーになるはず")
+	}
+}
+
+func TestStaleBranches(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git が見つかりません")
+	}
+	dir := t.TempDir()
+	run := func(env []string, args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=test", "-c", "user.email=test@example.com"}, args...)...)
+		cmd.Env = append(os.Environ(), env...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v エラー: %v %s", args, err, output)
+		}
+	}
+	oldDate := []string{"GIT_COMMITTER_DATE=2020-01-01T00:00:00Z", "GIT_AUTHOR_DATE=2020-01-01T00:00:00Z"}
+	run(nil, "init", "-q", "-b", "main")
+	run(nil, "commit", "-q", "--allow-empty", "-m", "initial")
+	run(nil, "branch", "merged-feature")
+	run(nil, "checkout", "-q", "-b", "old-feature")
+	run(oldDate, "commit", "-q", "--allow-empty", "-m", "old work")
+	run(nil, "checkout", "-q", "-b", "active")
+	run(nil, "commit", "-q", "--allow-empty", "-m", "recent work")
+
+	branches, err := staleBranches(context.Background(), dir, 30*24*time.Hour, time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := map[string]bool{}
+	for _, branch := range branches {
+		names[branch.Name] = branch.Merged
+	}
+	if merged, ok := names["old-feature"]; len(branches) != 2 || !names["merged-feature"] || !ok || merged {
+		t.Errorf("マージ済みと古いブランチを返し、現在・既定のブランチは除くはず: %+v", branches)
+	}
+}
@@ -0,0 +1,255 @@
+package maintenance
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/textutil"
+)
+
+// scanner は依存関係の脆弱性スキャナー（外部コマンド）
+type scanner struct {
+	name     string
+	manifest string // このファイルがあるプロジェクトでのみ実行
+	command  string
+	args     []string
+	install  string // 見つからないときの案内
+	parse    func(data []byte) ([]Vulnerability, error)
+}
+
+// scanners は対応している脆弱性スキャナー
+var scanners = []scanner{
+	{
+		name:     "govulncheck",
+		manifest: "go.mod",
+		command:  "govulncheck",
+		args:     []string{"-json", "./..."},
+		install:  "go install golang.org/x/vuln/cmd/govulncheck@latest",
+		parse:    parseGovulncheck,
+	},
+	{
+		name:     "npm-audit",
+		manifest: "package-lock.json",
+		command:  "npm",
+		args:     []string{"audit", "--json"},
+		install:  "Node.js (npm)",
+		parse:    parseNpmAudit,
+	},
+	{
+		name:     "pip-audit",
+		manifest: "requirements.txt",
+		command:  "pip-audit",
+		args:     []string{"-r", "requirements.txt", "-f", "json"},
+		install:  "pip install pip-audit",
+		parse:    parsePipAudit,
+	},
+}
+
+// scanVulnerabilities はプロジェクトのマニフェストに対応するスキャナーを実行する
+// 見つからない・失敗したスキャナーは errors に記録して他のスキャナーを続ける
+func scanVulnerabilities(ctx context.Context, projectDir string, report *Report) {
+	for _, s := range scanners {
+		if _, err := os.Stat(filepath.Join(projectDir, s.manifest)); err != nil {
+			continue
+		}
+		if _, err := exec.LookPath(s.command); err != nil {
+			report.addError(s.name, fmt.Sprintf("%s が見つかりません（%s）", s.command, s.install))
+			continue
+		}
+
+		var stdout, stderr bytes.Buffer
+		cmd := exec.CommandContext(ctx, s.command, s.args...)
+		cmd.Dir = projectDir
+		cmd.Stdout = &stdout
+		cmd.Stderr = &stderr
+		// npm audit・pip-audit は脆弱性が見つかると0以外で終了するため、出力を解析できるかで判断する
+		runErr := cmd.Run()
+		vulns, err := s.parse(stdout.Bytes())
+		if err != nil {
+			if runErr != nil {
+				err = fmt.Errorf("%v: %s", runErr, strings.TrimSpace(lastLine(stderr.String())))
+			}
+			report.addError(s.name, err.Error())
+			continue
+		}
+		report.Scanners = append(report.Scanners, s.name)
+		report.Vulnerabilities = append(report.Vulnerabilities, vulns...)
+	}
+	sort.SliceStable(report.Vulnerabilities, func(i, j int) bool {
+		return report.Vulnerabilities[i].Key() < report.Vulnerabilities[j].Key()
+	})
+}
+
+// parseGovulncheck は govulncheck -json の出力（JSONオブジェクトの列）を解析する
+// 同じ脆弱性・モジュールの複数の呼び出し経路は1件にまとめる
+func parseGovulncheck(data []byte) ([]Vulnerability, error) {
+	type osvEntry struct {
+		ID      string   `json:"id"`
+		Aliases []string `json:"aliases"`
+		Summary string   `json:"summary"`
+	}
+	type message struct {
+		OSV     *osvEntry `json:"osv"`
+		Finding *struct {
+			OSV          string `json:"osv"`
+			FixedVersion string `json:"fixed_version"`
+			Trace        []struct {
+				Module  string `json:"module"`
+				Version string `json:"version"`
+			} `json:"trace"`
+		} `json:"finding"`
+	}
+
+	entries := make(map[string]*osvEntry)
+	var vulns []Vulnerability
+	found := make(map[string]bool)
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	for {
+		var msg message
+		if err := decoder.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("govulncheck の出力を解析できません: %w", err)
+		}
+		if msg.OSV != nil {
+			entries[msg.OSV.ID] = msg.OSV
+		}
+		if msg.Finding == nil || len(msg.Finding.Trace) == 0 {
+			continue
+		}
+		vuln := Vulnerability{
+			ID:      msg.Finding.OSV,
+			Package: msg.Finding.Trace[0].Module,
+			Version: msg.Finding.Trace[0].Version,
+			FixedIn: msg.Finding.FixedVersion,
+			Scanner: "govulncheck",
+		}
+		if found[vuln.Key()] {
+			continue
+		}
+		found[vuln.Key()] = true
+		vulns = append(vulns, vuln)
+	}
+	// osv メッセージは finding より前に出力されるとは限らない
+	for i := range vulns {
+		if entry := entries[vulns[i].ID]; entry != nil {
+			vulns[i].Aliases = entry.Aliases
+			vulns[i].Summary = entry.Summary
+		}
+	}
+	return vulns, nil
+}
+
+// parseNpmAudit は npm audit --json（npm 7 以降）の出力を解析する
+// via の文字列は他のパッケージ経由の脆弱性を指すだけなので、勧告（オブジェクト）のみ数える
+func parseNpmAudit(data []byte) ([]Vulnerability, error) {
+	var audit struct {
+		Vulnerabilities map[string]struct {
+			Via          []json.RawMessage `json:"via"`
+			FixAvailable json.RawMessage   `json:"fixAvailable"`
+		} `json:"vulnerabilities"`
+		Error *struct {
+			Summary string `json:"summary"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(data, &audit); err != nil {
+		return nil, fmt.Errorf("npm audit の出力を解析できません: %w", err)
+	}
+	if audit.Error != nil {
+		return nil, fmt.Errorf("npm audit: %s", audit.Error.Summary)
+	}
+
+	var vulns []Vulnerability
+	found := make(map[string]bool)
+	for _, entry := range audit.Vulnerabilities {
+		var fix struct {
+			Version string `json:"version"`
+		}
+		json.Unmarshal(entry.FixAvailable, &fix)
+		for _, raw := range entry.Via {
+			var advisory struct {
+				Source   json.Number `json:"source"`
+				Name     string      `json:"name"`
+				Title    string      `json:"title"`
+				URL      string      `json:"url"`
+				Severity string      `json:"severity"`
+				Range    string      `json:"range"`
+			}
+			if json.Unmarshal(raw, &advisory) != nil {
+				continue
+			}
+			id := advisory.Source.String()
+			if i := strings.LastIndex(advisory.URL, "/"); i >= 0 && strings.HasPrefix(advisory.URL[i+1:], "GHSA-") {
+				id = advisory.URL[i+1:]
+			}
+			vuln := Vulnerability{
+				ID:       id,
+				Package:  advisory.Name,
+				Version:  advisory.Range,
+				FixedIn:  fix.Version,
+				Severity: advisory.Severity,
+				Summary:  advisory.Title,
+				Scanner:  "npm-audit",
+			}
+			if !found[vuln.Key()] {
+				found[vuln.Key()] = true
+				vulns = append(vulns, vuln)
+			}
+		}
+	}
+	return vulns, nil
+}
+
+// parsePipAudit は pip-audit -f json の出力（古い版は依存関係の配列のみ）を解析する
+func parsePipAudit(data []byte) ([]Vulnerability, error) {
+	type dependency struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+		Vulns   []struct {
+			ID          string   `json:"id"`
+			FixVersions []string `json:"fix_versions"`
+			Aliases     []string `json:"aliases"`
+			Description string   `json:"description"`
+		} `json:"vulns"`
+	}
+	var audit struct {
+		Dependencies []dependency `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &audit); err != nil {
+		if err := json.Unmarshal(data, &audit.Dependencies); err != nil {
+			return nil, fmt.Errorf("pip-audit の出力を解析できません: %w", err)
+		}
+	}
+
+	var vulns []Vulnerability
+	for _, dep := range audit.Dependencies {
+		for _, v := range dep.Vulns {
+			vulns = append(vulns, Vulnerability{
+				ID:      v.ID,
+				Aliases: v.Aliases,
+				Package: dep.Name,
+				Version: dep.Version,
+				FixedIn: strings.Join(v.FixVersions, ", "),
+				Summary: textutil.FirstLine(v.Description),
+				Scanner: "pip-audit",
+			})
+		}
+	}
+	return vulns, nil
+}
+
+func lastLine(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.LastIndexByte(s, '\n'); i >= 0 {
+		return s[i+1:]
+	}
+	return s
+}
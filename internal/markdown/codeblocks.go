@@ -0,0 +1,51 @@
+package markdown
+
+import "strings"
+
+// CodeBlock は応答中の ``` で囲まれたコードブロック
+type CodeBlock struct {
+	Language string
+	Code     string
+}
+
+// CodeBlocks は Markdown のコードブロックを出現順に取り出す（閉じていない最後のブロックも含む）
+func CodeBlocks(content string) []CodeBlock {
+	var blocks []CodeBlock
+	var current *CodeBlock
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "```") {
+			if current != nil {
+				lines = append(lines, line)
+			}
+			continue
+		}
+		if current == nil {
+			current = &CodeBlock{Language: strings.TrimSpace(strings.TrimPrefix(trimmed, "```"))}
+			lines = nil
+			continue
+		}
+		current.Code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+		current = nil
+	}
+	if current != nil && len(lines) > 0 {
+		current.Code = strings.Join(lines, "\n")
+		blocks = append(blocks, *current)
+	}
+	return blocks
+}
+
+// StripCodeFence はモデルが全文を ``` で囲んで返した場合に囲みを外す（開始と終了の両方で囲まれていなければそのまま返す）
+func StripCodeFence(content string) string {
+	trimmed := strings.TrimSpace(content)
+	if !strings.HasPrefix(trimmed, "```") || !strings.HasSuffix(trimmed, "```") {
+		return content
+	}
+	lines := strings.Split(trimmed, "\n")
+	if len(lines) < 2 {
+		return content
+	}
+	return strings.Join(lines[1:len(lines)-1], "\n") + "\n"
+}
@@ -0,0 +1,160 @@
+package markdown
+
+import (
+	"os"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// シンタックスハイライトのテーマ
+const (
+	ThemeDark  = "dark"  // 暗い背景の端末向け
+	ThemeLight = "light" // 明るい背景の端末向け
+	ThemeNone  = "none"  // 色付けしない
+)
+
+// テーマ毎の chroma のスタイル
+var themeStyles = map[string]string{
+	ThemeDark:  "monokai",
+	ThemeLight: "github",
+}
+
+// ValidThemes は設定できるテーマ
+func ValidThemes() []string {
+	return []string{ThemeDark, ThemeLight, ThemeNone}
+}
+
+// IsValidTheme はテーマ名が有効か（空は既定の dark）
+func IsValidTheme(theme string) bool {
+	if theme == "" {
+		return true
+	}
+	for _, valid := range ValidThemes() {
+		if theme == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// Highlighter はコードブロック・差分を言語に応じて端末用に色付けする
+type Highlighter struct {
+	style *chroma.Style // nil なら色付けしない
+}
+
+// NewHighlighter はテーマのハイライターを作成（NO_COLOR が設定されていれば色付けしない）
+func NewHighlighter(theme string) *Highlighter {
+	if theme == "" {
+		theme = ThemeDark
+	}
+	name, ok := themeStyles[theme]
+	if !ok || os.Getenv("NO_COLOR") != "" {
+		return &Highlighter{}
+	}
+	return &Highlighter{style: styles.Get(name)}
+}
+
+// Enabled は色付けするか
+func (h *Highlighter) Enabled() bool {
+	return h != nil && h.style != nil
+}
+
+// Lines はコードを色付けして行毎に返す（色は行を跨がないため行単位で出力・装飾できる）
+// 色付けしない場合・言語が分からない場合は nil
+func (h *Highlighter) Lines(language, code string) []string {
+	if !h.Enabled() {
+		return nil
+	}
+	lexer := lexerFor(language, code)
+	if lexer == nil {
+		return nil
+	}
+	iterator, err := chroma.Coalesce(lexer).Tokenise(nil, code+"\n")
+	if err != nil {
+		return nil
+	}
+
+	lines := make([]string, 0, strings.Count(code, "\n")+1)
+	for _, tokens := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		// 行末の改行は色付けの外に出し、空のトークンは出力しない
+		var visible []chroma.Token
+		for _, token := range tokens {
+			token.Value = strings.TrimSuffix(token.Value, "\n")
+			if token.Value != "" {
+				visible = append(visible, token)
+			}
+		}
+		var b strings.Builder
+		if err := formatters.TTY256.Format(&b, h.style, chroma.Literator(visible...)); err != nil {
+			return nil
+		}
+		lines = append(lines, b.String())
+	}
+	if len(lines) != cap(lines) {
+		return nil
+	}
+	return lines
+}
+
+// Code はコードを色付けする（色付けできなければそのまま）
+func (h *Highlighter) Code(language, code string) string {
+	lines := h.Lines(language, code)
+	if lines == nil {
+		return code
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Diff は unified diff を色付けする（追加・削除行・ハンク見出し）
+func (h *Highlighter) Diff(patch string) string {
+	return h.Code("diff", patch)
+}
+
+// lexerFor は言語名（```go 等）から字句解析器を選ぶ。言語名がなければ内容から推定
+func lexerFor(language, code string) chroma.Lexer {
+	language = strings.ToLower(strings.TrimSpace(language))
+	// ```go title="main.go" のような追加情報は除く
+	if i := strings.IndexAny(language, " \t{"); i >= 0 {
+		language = language[:i]
+	}
+	if language == "" {
+		if IsUnifiedDiff(code) {
+			return lexers.Get("diff")
+		}
+		return lexers.Analyse(code)
+	}
+	if lexer := lexers.Get(language); lexer != nil {
+		return lexer
+	}
+	return nil
+}
+
+// IsUnifiedDiff はテキストが unified diff（git diff 形式を含む）で始まるか
+func IsUnifiedDiff(text string) bool {
+	lines := strings.SplitN(text, "\n", 3)
+	if strings.HasPrefix(lines[0], "diff --git ") {
+		return true
+	}
+	return len(lines) >= 2 && strings.HasPrefix(lines[0], "--- ") && strings.HasPrefix(lines[1], "+++ ")
+}
+
+// IsDiffLine は unified diff の続きの行か（差分の終わりの判定用）
+func IsDiffLine(line string) bool {
+	if line == "" {
+		return false
+	}
+	switch line[0] {
+	case ' ', '+', '-', '@', '\\':
+		return true
+	}
+	for _, prefix := range []string{"diff ", "index ", "new file", "deleted file", "old mode", "new mode", "similarity", "rename ", "Binary files"} {
+		if strings.HasPrefix(line, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,96 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+)
+
+const escapePrefix = "\033["
+
+func TestHighlighter_Lines(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	h := NewHighlighter(ThemeDark)
+
+	code := "/* start\nend */\nfunc main() {}\n"
+	lines := h.Lines("go", code)
+	if len(lines) != 4 {
+		t.Fatalf("入力と同じ行数を返すはず: %q", lines)
+	}
+	for i, line := range lines[:3] {
+		// 色は行を跨がない（各行がリセットで終わる）
+		if !strings.HasPrefix(line, escapePrefix) || !strings.HasSuffix(line, Reset) {
+			t.Errorf("行 %d が行内で色付け・リセットされていない: %q", i, line)
+		}
+	}
+	if lines[3] != "" {
+		t.Errorf("空行はそのまま: %q", lines[3])
+	}
+
+	if h.Lines("no-such-language", "x") != nil {
+		t.Error("未対応の言語は nil を返すはず")
+	}
+	if h.Code("no-such-language", "x := 1") != "x := 1" {
+		t.Error("未対応の言語はそのまま返すはず")
+	}
+}
+
+func TestHighlighter_Themes(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	code := "package main"
+
+	dark := NewHighlighter(ThemeDark).Code("go", code)
+	light := NewHighlighter(ThemeLight).Code("go", code)
+	if dark == code || light == code || dark == light {
+		t.Errorf("dark と light で異なる配色になるはず: %q %q", dark, light)
+	}
+	if got := NewHighlighter(ThemeNone).Code("go", code); got != code {
+		t.Errorf("none は色付けしないはず: %q", got)
+	}
+	if !NewHighlighter("").Enabled() {
+		t.Error("空のテーマは dark として扱うはず")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if NewHighlighter(ThemeDark).Enabled() {
+		t.Error("NO_COLOR が設定されていれば色付けしないはず")
+	}
+
+	if !IsValidTheme("light") || IsValidTheme("solarized") {
+		t.Error("テーマ名の検証が不正")
+	}
+}
+
+func TestHighlighter_Diff(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	patch := "diff --git a/x.go b/x.go\n--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b"
+	if !IsUnifiedDiff(patch) || !IsUnifiedDiff("--- a/x\n+++ b/x\n") || IsUnifiedDiff("--- \nnot a diff") {
+		t.Error("unified diff の判定が不正")
+	}
+
+	lines := strings.Split(NewHighlighter(ThemeDark).Diff(patch), "\n")
+	if len(lines) != 6 || lines[4] == lines[5] || !strings.Contains(lines[4], "-a") || !strings.Contains(lines[5], "+b") {
+		t.Errorf("削除行と追加行を別の色にするはず: %q", lines)
+	}
+	// 言語名がなくても差分は差分として色付け
+	if NewHighlighter(ThemeDark).Lines("", patch) == nil {
+		t.Error("言語名のない差分も色付けするはず")
+	}
+}
+
+func TestRenderer_CodeBlockHighlighting(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	config := NewRenderer().config
+	config.AutoWidth = false
+
+	config.SyntaxTheme = ThemeNone
+	plain := NewRendererWithConfig(config).Render("```go\nfunc main() {}\n```")
+	if !strings.Contains(plain, "func main() {}") {
+		t.Errorf("none ではコードをそのまま表示するはず:\n%q", plain)
+	}
+
+	config.SyntaxTheme = ThemeDark
+	colored := NewRendererWithConfig(config).Render("```go\nfunc main() {}\n```")
+	if strings.Contains(colored, "func main() {}") || !strings.Contains(colored, "main") {
+		t.Errorf("言語に応じて色付けするはず:\n%q", colored)
+	}
+}
@@ -33,7 +33,8 @@ const (
 
 // Markdown レンダラー
 type Renderer struct {
-	config RenderConfig
+	config      RenderConfig
+	highlighter *Highlighter
 }
 
 // レンダリング設定
@@ -45,28 +46,28 @@ type RenderConfig struct {
 	IndentSize        int
 	MaxTableWidth     int
 	UseUnicodeSymbols bool
-	AutoWidth         bool // ターミナル幅に自動調整
+	AutoWidth         bool   // ターミナル幅に自動調整
+	SyntaxTheme       string // コードブロックの配色 "dark", "light", "none"（空は dark）
 }
 
 // デフォルト設定でレンダラーを作成
 func NewRenderer() *Renderer {
-	return &Renderer{
-		config: RenderConfig{
-			EnableColors:      true,
-			EnableAnimations:  true,
-			CodeBlockStyle:    "bordered",
-			TableStyle:        "box",
-			IndentSize:        2,
-			MaxTableWidth:     80,
-			UseUnicodeSymbols: true,
-			AutoWidth:         true,
-		},
-	}
+	return NewRendererWithConfig(RenderConfig{
+		EnableColors:      true,
+		EnableAnimations:  true,
+		CodeBlockStyle:    "bordered",
+		TableStyle:        "box",
+		IndentSize:        2,
+		MaxTableWidth:     80,
+		UseUnicodeSymbols: true,
+		AutoWidth:         true,
+		SyntaxTheme:       ThemeDark,
+	})
 }
 
 // 設定付きレンダラーを作成
 func NewRendererWithConfig(config RenderConfig) *Renderer {
-	return &Renderer{config: config}
+	return &Renderer{config: config, highlighter: NewHighlighter(config.SyntaxTheme)}
 }
 
 // ターミナル幅を取得（リアルタイム）
@@ -442,7 +443,7 @@ func (r *Renderer) renderCodeBlock(language string, lines []string, maxWidth int
 	}
 
 	// コード行
-	for _, line := range lines {
+	for _, line := range r.highlightCode(language, lines) {
 		result.WriteString(r.renderCodeLine(line))
 	}
 
@@ -459,24 +460,36 @@ func (r *Renderer) renderCodeBlock(language string, lines []string, maxWidth int
 	return result.String()
 }
 
-// コード行を描画（拡張シンタックスハイライト）
+// コードブロックを言語に応じて色付け（字句解析器がなければキーワードの簡易ハイライト）
+func (r *Renderer) highlightCode(language string, lines []string) []string {
+	if !r.highlighter.Enabled() {
+		return lines
+	}
+	if highlighted := r.highlighter.Lines(language, strings.Join(lines, "\n")); len(highlighted) == len(lines) {
+		return highlighted
+	}
+	result := make([]string, len(lines))
+	for i, line := range lines {
+		result[i] = r.applySyntaxHighlighting(line)
+	}
+	return result
+}
+
+// 色付け済みのコード行を描画
 func (r *Renderer) renderCodeLine(line string) string {
 	if !r.config.EnableColors {
 		return fmt.Sprintf("│ %s\n", line)
 	}
 
-	// シンタックスハイライト
-	highlighted := r.applySyntaxHighlighting(line)
-
 	switch r.config.CodeBlockStyle {
 	case "bordered":
-		return fmt.Sprintf("%s│%s %s\n", Gray, Reset, highlighted)
+		return fmt.Sprintf("%s│%s %s\n", Gray, Reset, line)
 	default:
-		return fmt.Sprintf("%s│%s %s\n", Gray, Reset, highlighted)
+		return fmt.Sprintf("%s│%s %s\n", Gray, Reset, line)
 	}
 }
 
-// 拡張シンタックスハイライト
+// 簡易シンタックスハイライト（キーワード・文字列・コメント・数値）
 func (r *Renderer) applySyntaxHighlighting(line string) string {
 	// Go のキーワード
 	goKeywords := []string{"package", "import", "func", "var", "const", "type", "struct", "interface", "if", "else", "for", "range", "return", "defer", "go", "select", "case", "default", "switch"}
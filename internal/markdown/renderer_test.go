@@ -584,3 +584,38 @@ func BenchmarkRenderer_LargeDocument(b *testing.B) {
 		renderer.Render(content)
 	}
 }
+
+// TestCodeBlocks はコードブロックを出現順に取り出すことをテストする
+func TestCodeBlocks(t *testing.T) {
+	content := "Here:\n```go\nfunc a() {}\n```\ntext\n  ```\nplain\nlines\n  ```\n```sh\necho unterminated"
+	blocks := CodeBlocks(content)
+	if len(blocks) != 3 {
+		t.Fatalf("blocks = %+v", blocks)
+	}
+	if blocks[0].Language != "go" || blocks[0].Code != "func a() {}" {
+		t.Errorf("blocks[0] = %+v", blocks[0])
+	}
+	if blocks[1].Language != "" || blocks[1].Code != "plain\nlines" {
+		t.Errorf("blocks[1] = %+v", blocks[1])
+	}
+	if blocks[2].Language != "sh" || blocks[2].Code != "echo unterminated" {
+		t.Errorf("blocks[2] = %+v", blocks[2])
+	}
+}
+
+// TestStripCodeFence は全文を開始と終了の両方で囲むコードフェンスだけを外すことをテストする
+func TestStripCodeFence(t *testing.T) {
+	tests := map[string]string{
+		"```go\npackage main\n```":  "package main\n",
+		"\n```\nAdd hooks\n```\n\n": "Add hooks\n",
+		"```diff\n--- a\n+++ b":     "```diff\n--- a\n+++ b",
+		"```":                       "```",
+		"plain text\n":              "plain text\n",
+		"text\n```\ncode\n```":      "text\n```\ncode\n```",
+	}
+	for input, want := range tests {
+		if got := StripCodeFence(input); got != want {
+			t.Errorf("StripCodeFence(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/i18n"
+)
+
+// 通知方法
+const (
+	MethodAuto    = "auto"    // デスクトップ通知が使えればそれ、なければ端末のベル
+	MethodBell    = "bell"    // 端末のベル（BEL）
+	MethodOSC777  = "osc777"  // OSC 777 のエスケープシーケンス（対応端末が通知を表示）
+	MethodDesktop = "desktop" // notify-send（Linux）・osascript（macOS）
+)
+
+// 通知するイベント
+const (
+	EventComplete = "complete" // ターンが完了した
+	EventInput    = "input"    // ターンが確認待ちで終わった
+	EventError    = "error"    // ターンが失敗した
+)
+
+// 通知のタイトル
+const title = "vyb"
+
+// Notifier は長いターンの終了をデスクトップ・端末に通知する
+type Notifier struct {
+	cfg         config.NotificationConfig
+	terminal    func() (io.WriteCloser, error)          // ベル・OSC 777 の書き込み先
+	run         func(name string, args ...string) error // デスクトップ通知のコマンド実行
+	lookPath    func(name string) (string, error)       // デスクトップ通知のコマンド検索
+	unsubscribe func()
+}
+
+// Start は設定に従ってターン完了イベントの購読を開始する（無効なら nil）
+func Start(cfg config.NotificationConfig, bus *events.Bus) *Notifier {
+	if !cfg.Enabled {
+		return nil
+	}
+	n := New(cfg)
+	n.unsubscribe = bus.Subscribe(events.TurnCompleted, n.handle)
+	return n
+}
+
+// New は通知を作成する（イベントの購読はしない）
+func New(cfg config.NotificationConfig) *Notifier {
+	return &Notifier{
+		cfg:      cfg,
+		terminal: openTerminal,
+		run: func(name string, args ...string) error {
+			// イベントの配信を止めないよう終了は待たない
+			cmd := exec.Command(name, args...)
+			if err := cmd.Start(); err != nil {
+				return err
+			}
+			go cmd.Wait()
+			return nil
+		},
+		lookPath: exec.LookPath,
+	}
+}
+
+// Close は購読を解除する
+func (n *Notifier) Close() {
+	if n == nil || n.unsubscribe == nil {
+		return
+	}
+	n.unsubscribe()
+	n.unsubscribe = nil
+}
+
+// handle はしきい値以上かかったターンの終了を種類に応じて通知する
+func (n *Notifier) handle(event events.Event) {
+	duration := time.Duration(numberValue(event.Data["duration_ms"])) * time.Millisecond
+	if duration < time.Duration(n.cfg.ThresholdSeconds)*time.Second {
+		return
+	}
+
+	kind := EventComplete
+	if success, _ := event.Data["success"].(bool); !success {
+		// ユーザーが中断したターンは通知しない
+		if message, _ := event.Data["error"].(string); strings.Contains(message, context.Canceled.Error()) {
+			return
+		}
+		kind = EventError
+	} else if needsInput, _ := event.Data["needs_input"].(bool); needsInput {
+		kind = EventInput
+	}
+	if !n.wants(kind) {
+		return
+	}
+
+	elapsed := duration.Round(time.Second).String()
+	if err := n.Send(title, i18n.T("notify."+kind, elapsed)); err != nil {
+		fmt.Fprintf(os.Stderr, "通知エラー: %v\n", err)
+	}
+}
+
+// wants は設定で通知するイベントか
+func (n *Notifier) wants(kind string) bool {
+	for _, event := range n.cfg.Events {
+		if event == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Send は設定した方法で通知を送る
+func (n *Notifier) Send(title, body string) error {
+	switch n.cfg.Method {
+	case MethodBell:
+		return n.writeTerminal("\a")
+	case MethodOSC777:
+		return n.writeTerminal(osc777(title, body))
+	case MethodDesktop:
+		return n.desktop(title, body)
+	default:
+		// auto: デスクトップ通知を試し、使えなければベル
+		if err := n.desktop(title, body); err == nil {
+			return nil
+		}
+		return n.writeTerminal("\a")
+	}
+}
+
+// desktop は OS のデスクトップ通知を送る
+func (n *Notifier) desktop(title, body string) error {
+	switch runtime.GOOS {
+	case "darwin":
+		if _, err := n.lookPath("osascript"); err != nil {
+			return fmt.Errorf("osascript が見つかりません")
+		}
+		script := fmt.Sprintf("display notification %s with title %s", appleScriptString(body), appleScriptString(title))
+		return n.run("osascript", "-e", script)
+	case "linux", "freebsd", "openbsd", "netbsd":
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return fmt.Errorf("デスクトップ環境が見つかりません")
+		}
+		if _, err := n.lookPath("notify-send"); err != nil {
+			return fmt.Errorf("notify-send が見つかりません")
+		}
+		return n.run("notify-send", "--app-name=vyb", title, body)
+	}
+	return fmt.Errorf("%s ではデスクトップ通知に対応していません", runtime.GOOS)
+}
+
+// writeTerminal は制御端末にエスケープシーケンスを書き込む
+func (n *Notifier) writeTerminal(sequence string) error {
+	terminal, err := n.terminal()
+	if err != nil {
+		return err
+	}
+	defer terminal.Close()
+	_, err = io.WriteString(terminal, sequence)
+	return err
+}
+
+// openTerminal は制御端末を開く（TUI が標準出力を取り込んでいても端末に届くよう /dev/tty を優先）
+func openTerminal() (io.WriteCloser, error) {
+	if tty, err := os.OpenFile("/dev/tty", os.O_WRONLY, 0); err == nil {
+		return tty, nil
+	}
+	return nopCloser{os.Stderr}, nil
+}
+
+type nopCloser struct{ io.Writer }
+
+func (nopCloser) Close() error { return nil }
+
+// osc777 は OSC 777 の通知シーケンス（区切り文字・制御文字は除く）
+func osc777(title, body string) string {
+	clean := func(text string) string {
+		return strings.Map(func(r rune) rune {
+			if r == ';' || r < 0x20 || r == 0x7f {
+				return ' '
+			}
+			return r
+		}, text)
+	}
+	return fmt.Sprintf("\033]777;notify;%s;%s\a", clean(title), clean(body))
+}
+
+// appleScriptString は AppleScript の文字列リテラル
+func appleScriptString(text string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(text) + `"`
+}
+
+// numberValue はイベントの数値（int64・float64 等）を取り出す
+func numberValue(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
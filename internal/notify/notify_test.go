@@ -0,0 +1,108 @@
+package notify
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+type bufferCloser struct{ *bytes.Buffer }
+
+func (bufferCloser) Close() error { return nil }
+
+// newTestNotifier は端末への書き込みを記録し、デスクトップ通知は使えない通知を作成
+func newTestNotifier(cfg config.NotificationConfig) (*Notifier, *bytes.Buffer) {
+	var output bytes.Buffer
+	n := New(cfg)
+	n.terminal = func() (io.WriteCloser, error) { return bufferCloser{&output}, nil }
+	n.lookPath = func(name string) (string, error) { return "", errors.New("not found") }
+	return n, &output
+}
+
+func turnCompleted(durationMS int64, success, needsInput bool, errMessage string) events.Event {
+	data := map[string]interface{}{"duration_ms": durationMS, "success": success, "needs_input": needsInput}
+	if errMessage != "" {
+		data["error"] = errMessage
+	}
+	return events.Event{Type: events.TurnCompleted, Data: data}
+}
+
+func TestStartNotifiesLongTurns(t *testing.T) {
+	cfg := config.DefaultNotificationConfig()
+	cfg.Method = MethodBell
+	bus := events.NewBus()
+	n := Start(cfg, bus)
+	defer n.Close()
+
+	var output bytes.Buffer
+	n.terminal = func() (io.WriteCloser, error) { return bufferCloser{&output}, nil }
+
+	bus.Publish(turnCompleted(5_000, true, false, ""))
+	if output.Len() != 0 {
+		t.Errorf("しきい値より短いターンは通知しないはず: %q", output.String())
+	}
+	bus.Publish(turnCompleted(45_000, true, false, ""))
+	if output.String() != "\a" {
+		t.Errorf("しきい値以上のターンはベルで通知するはず: %q", output.String())
+	}
+
+	n.Close()
+	bus.Publish(turnCompleted(45_000, true, false, ""))
+	if output.String() != "\a" {
+		t.Error("Close 後は通知しないはず")
+	}
+
+	cfg.Enabled = false
+	if Start(cfg, bus) != nil {
+		t.Error("無効なら購読しないはず")
+	}
+}
+
+func TestHandleFiltersEventTypes(t *testing.T) {
+	cfg := config.DefaultNotificationConfig()
+	cfg.Method = MethodOSC777
+	cfg.Events = []string{EventInput, EventError}
+	n, output := newTestNotifier(cfg)
+
+	n.handle(turnCompleted(60_000, true, false, ""))
+	if output.Len() != 0 {
+		t.Errorf("設定にないイベントは通知しないはず: %q", output.String())
+	}
+
+	n.handle(turnCompleted(60_000, true, true, ""))
+	if !strings.HasPrefix(output.String(), "\033]777;notify;vyb;") || !strings.HasSuffix(output.String(), "\a") {
+		t.Errorf("確認待ちを OSC 777 で通知するはず: %q", output.String())
+	}
+
+	output.Reset()
+	n.handle(turnCompleted(60_000, false, false, "context canceled"))
+	if output.Len() != 0 {
+		t.Errorf("中断したターンは通知しないはず: %q", output.String())
+	}
+	n.handle(turnCompleted(60_000, false, false, "LLM error"))
+	if output.Len() == 0 {
+		t.Error("失敗したターンを通知するはず")
+	}
+}
+
+func TestSendAutoFallsBackToBell(t *testing.T) {
+	n, output := newTestNotifier(config.DefaultNotificationConfig())
+	if err := n.Send("vyb", "done"); err != nil {
+		t.Fatal(err)
+	}
+	if output.String() != "\a" {
+		t.Errorf("デスクトップ通知が使えなければベルを鳴らすはず: %q", output.String())
+	}
+}
+
+func TestOSC777SanitizesText(t *testing.T) {
+	got := osc777("vyb", "a;b\nc\x07")
+	if got != "\033]777;notify;vyb;a b c \a" {
+		t.Errorf("区切り文字・制御文字を除くはず: %q", got)
+	}
+}
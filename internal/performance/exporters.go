@@ -0,0 +1,332 @@
+package performance
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+// Exporters は設定で有効にしたメトリクス・トレースの出力
+type Exporters struct {
+	Metrics *MetricsExporter // 無効なら nil
+	Traces  *TraceExporter   // 無効なら nil
+}
+
+// StartExporters は設定に従って /metrics の公開とトレースの送信を開始する
+// どちらも無効なら nil を返す
+func StartExporters(cfg config.ObservabilityConfig, bus *events.Bus, monitor *RealtimeMonitor) (*Exporters, error) {
+	if cfg.MetricsAddress == "" && cfg.OTLPEndpoint == "" {
+		return nil, nil
+	}
+
+	exporters := &Exporters{}
+	if cfg.MetricsAddress != "" {
+		metrics := NewMetricsExporter(monitor)
+		if err := metrics.Serve(cfg.MetricsAddress); err != nil {
+			return nil, err
+		}
+		metrics.Attach(bus)
+		exporters.Metrics = metrics
+	}
+	if cfg.OTLPEndpoint != "" {
+		traces := NewTraceExporter(cfg)
+		traces.Attach(bus)
+		exporters.Traces = traces
+	}
+	return exporters, nil
+}
+
+// Close は /metrics の公開を止め、送信待ちのトレースを送り切る
+func (e *Exporters) Close() {
+	if e == nil {
+		return
+	}
+	if e.Metrics != nil {
+		e.Metrics.Close()
+	}
+	if e.Traces != nil {
+		e.Traces.Close()
+	}
+}
+
+// 所要時間のヒストグラムの境界（秒）
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60, 120}
+
+// メトリクスの種類
+const (
+	metricCounter   = "counter"
+	metricHistogram = "histogram"
+)
+
+type metricFamily struct {
+	name   string
+	help   string
+	kind   string
+	labels []string
+	series map[string]*metricSeries
+}
+
+type metricSeries struct {
+	labels []string
+	value  float64  // counter の値
+	counts []uint64 // histogram の境界毎の件数（累積前）
+	sum    float64
+	count  uint64
+}
+
+// MetricsExporter はイベントバスのターン・LLM・ツールのイベントを集計し、Prometheus 形式で公開する
+// リアルタイム監視があればその現在値も gauge として出力する
+type MetricsExporter struct {
+	mu       sync.Mutex
+	families map[string]*metricFamily
+	order    []string
+
+	monitor     *RealtimeMonitor
+	server      *http.Server
+	listener    net.Listener
+	unsubscribe func()
+}
+
+// NewMetricsExporter はメトリクス出力を作成（monitor は nil でもよい）
+func NewMetricsExporter(monitor *RealtimeMonitor) *MetricsExporter {
+	e := &MetricsExporter{families: make(map[string]*metricFamily), monitor: monitor}
+	e.define("vyb_sessions_started_total", "Sessions started.", metricCounter)
+	e.define("vyb_turns_total", "User inputs processed.", metricCounter, "status")
+	e.define("vyb_turn_duration_seconds", "Time to process one user input, including LLM calls and tools.", metricHistogram)
+	e.define("vyb_llm_requests_total", "LLM requests sent to the provider.", metricCounter, "model", "status")
+	e.define("vyb_llm_request_duration_seconds", "LLM request latency.", metricHistogram, "model")
+	e.define("vyb_llm_tokens_total", "Tokens reported by the provider.", metricCounter, "model", "type")
+	e.define("vyb_tool_executions_total", "Tool executions.", metricCounter, "tool", "status")
+	e.define("vyb_tool_duration_seconds", "Tool execution time.", metricHistogram, "tool")
+	e.define("vyb_edits_applied_total", "File edits applied.", metricCounter, "source")
+	return e
+}
+
+func (e *MetricsExporter) define(name, help, kind string, labels ...string) {
+	e.families[name] = &metricFamily{name: name, help: help, kind: kind, labels: labels, series: make(map[string]*metricSeries)}
+	e.order = append(e.order, name)
+}
+
+// Attach はイベントバスを購読する（Close で解除）
+func (e *MetricsExporter) Attach(bus *events.Bus) {
+	e.unsubscribe = bus.Subscribe("*", e.Observe)
+}
+
+// Observe は1件のイベントをメトリクスに反映する
+func (e *MetricsExporter) Observe(event events.Event) {
+	switch event.Type {
+	case events.SessionStarted:
+		e.add("vyb_sessions_started_total", 1)
+	case events.TurnCompleted:
+		e.add("vyb_turns_total", 1, statusLabel(event.Data))
+		e.observe("vyb_turn_duration_seconds", durationSeconds(event.Data))
+	case events.LLMCompleted:
+		model := stringValue(event.Data["model"])
+		e.add("vyb_llm_requests_total", 1, model, statusLabel(event.Data))
+		e.observe("vyb_llm_request_duration_seconds", durationSeconds(event.Data), model)
+		e.add("vyb_llm_tokens_total", numberValue(event.Data["prompt_tokens"]), model, "prompt")
+		e.add("vyb_llm_tokens_total", numberValue(event.Data["completion_tokens"]), model, "completion")
+	case events.ToolExecuted:
+		tool := stringValue(event.Data["tool"])
+		e.add("vyb_tool_executions_total", 1, tool, statusLabel(event.Data))
+		e.observe("vyb_tool_duration_seconds", durationSeconds(event.Data), tool)
+	case events.EditApplied:
+		e.add("vyb_edits_applied_total", 1, stringValue(event.Data["source"]))
+	}
+}
+
+func (e *MetricsExporter) seriesFor(name string, labels []string) *metricSeries {
+	family := e.families[name]
+	key := strings.Join(labels, "\xff")
+	series, ok := family.series[key]
+	if !ok {
+		series = &metricSeries{labels: labels}
+		if family.kind == metricHistogram {
+			series.counts = make([]uint64, len(durationBuckets))
+		}
+		family.series[key] = series
+	}
+	return series
+}
+
+func (e *MetricsExporter) add(name string, value float64, labels ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.seriesFor(name, labels).value += value
+}
+
+func (e *MetricsExporter) observe(name string, value float64, labels ...string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	series := e.seriesFor(name, labels)
+	for i, bound := range durationBuckets {
+		if value <= bound {
+			series.counts[i]++
+			break
+		}
+	}
+	series.sum += value
+	series.count++
+}
+
+// WriteTo は Prometheus のテキスト形式でメトリクスを書き出す
+func (e *MetricsExporter) WriteTo(w io.Writer) (int64, error) {
+	var b strings.Builder
+
+	e.mu.Lock()
+	for _, name := range e.order {
+		family := e.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n# TYPE %s %s\n", name, family.help, name, family.kind)
+
+		keys := make([]string, 0, len(family.series))
+		for key := range family.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			series := family.series[key]
+			if family.kind == metricCounter {
+				fmt.Fprintf(&b, "%s%s %s\n", name, formatLabels(family.labels, series.labels), formatValue(series.value))
+				continue
+			}
+			bucketLabels := append(append([]string{}, family.labels...), "le")
+			bucketValues := append(append([]string{}, series.labels...), "")
+			var cumulative uint64
+			for i, bound := range durationBuckets {
+				cumulative += series.counts[i]
+				bucketValues[len(bucketValues)-1] = formatValue(bound)
+				fmt.Fprintf(&b, "%s_bucket%s %d\n", name, formatLabels(bucketLabels, bucketValues), cumulative)
+			}
+			bucketValues[len(bucketValues)-1] = "+Inf"
+			fmt.Fprintf(&b, "%s_bucket%s %d\n", name, formatLabels(bucketLabels, bucketValues), series.count)
+			fmt.Fprintf(&b, "%s_sum%s %s\n", name, formatLabels(family.labels, series.labels), formatValue(series.sum))
+			fmt.Fprintf(&b, "%s_count%s %d\n", name, formatLabels(family.labels, series.labels), series.count)
+		}
+	}
+	e.mu.Unlock()
+
+	var memStats runtime.MemStats
+	runtime.ReadMemStats(&memStats)
+	gauges := map[string]float64{
+		"go_goroutines":           float64(runtime.NumGoroutine()),
+		"go_memstats_alloc_bytes": float64(memStats.Alloc),
+	}
+	if e.monitor != nil {
+		for name, value := range e.monitor.gaugeValues() {
+			gauges[name] = value
+		}
+	}
+	names := make([]string, 0, len(gauges))
+	for name := range gauges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(&b, "# TYPE %s gauge\n%s %s\n", name, name, formatValue(gauges[name]))
+	}
+
+	n, err := io.WriteString(w, b.String())
+	return int64(n), err
+}
+
+// ServeHTTP は /metrics の応答を返す
+func (e *MetricsExporter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	e.WriteTo(w)
+}
+
+// Serve は address で /metrics の公開を開始する（待ち受けに失敗したらエラー）
+func (e *MetricsExporter) Serve(address string) error {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("メトリクス公開エラー (%s): %w", address, err)
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", e)
+	e.listener = listener
+	e.server = &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go e.server.Serve(listener)
+	return nil
+}
+
+// Addr は /metrics を公開しているアドレス（未公開なら空）
+func (e *MetricsExporter) Addr() string {
+	if e.listener == nil {
+		return ""
+	}
+	return e.listener.Addr().String()
+}
+
+// Close は購読を解除し、/metrics の公開を止める
+func (e *MetricsExporter) Close() error {
+	if e.unsubscribe != nil {
+		e.unsubscribe()
+		e.unsubscribe = nil
+	}
+	if e.server == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	return e.server.Shutdown(ctx)
+}
+
+// formatLabels は {name="value",...} 形式に整形（ラベルがなければ空）
+func formatLabels(names, values []string) string {
+	if len(names) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		value := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(values[i])
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, value)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(value float64) string {
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+// statusLabel はイベントの success から "ok" / "error" を返す
+func statusLabel(data map[string]interface{}) string {
+	if success, _ := data["success"].(bool); success {
+		return "ok"
+	}
+	return "error"
+}
+
+// durationSeconds はイベントの duration_ms を秒に変換
+func durationSeconds(data map[string]interface{}) float64 {
+	return numberValue(data["duration_ms"]) / 1000
+}
+
+func stringValue(value interface{}) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return ""
+}
+
+func numberValue(value interface{}) float64 {
+	switch v := value.(type) {
+	case int:
+		return float64(v)
+	case int64:
+		return float64(v)
+	case float64:
+		return v
+	}
+	return 0
+}
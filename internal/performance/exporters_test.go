@@ -0,0 +1,109 @@
+package performance
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+// TestMetricsExporter はイベントの集計と /metrics の出力をテストする
+func TestMetricsExporter(t *testing.T) {
+	bus := events.NewBus()
+	exporters, err := StartExporters(config.ObservabilityConfig{MetricsAddress: "127.0.0.1:0"}, bus, nil)
+	if err != nil {
+		t.Fatalf("メトリクス公開エラー: %v", err)
+	}
+	defer exporters.Close()
+
+	bus.Publish(events.Event{Type: events.LLMCompleted, Data: map[string]interface{}{
+		"model": "qwen", "duration_ms": int64(1500), "success": true, "prompt_tokens": 100, "completion_tokens": 20,
+	}})
+	bus.Publish(events.Event{Type: events.ToolExecuted, Data: map[string]interface{}{"tool": "bash", "duration_ms": int64(40), "success": false}})
+	bus.Publish(events.Event{Type: events.TurnCompleted, Data: map[string]interface{}{"duration_ms": int64(2000), "success": true}})
+
+	resp, err := http.Get("http://" + exporters.Metrics.Addr() + "/metrics")
+	if err != nil {
+		t.Fatalf("/metrics の取得エラー: %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	output := string(body)
+
+	for _, want := range []string{
+		`vyb_llm_requests_total{model="qwen",status="ok"} 1`,
+		`vyb_llm_tokens_total{model="qwen",type="prompt"} 100`,
+		`vyb_llm_request_duration_seconds_bucket{model="qwen",le="1"} 0`,
+		`vyb_llm_request_duration_seconds_bucket{model="qwen",le="2.5"} 1`,
+		`vyb_llm_request_duration_seconds_sum{model="qwen"} 1.5`,
+		`vyb_tool_executions_total{tool="bash",status="error"} 1`,
+		`vyb_turns_total{status="ok"} 1`,
+		`vyb_turn_duration_seconds_count 1`,
+		"# TYPE go_goroutines gauge",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("出力に %q が含まれるはず:\n%s", want, output)
+		}
+	}
+}
+
+// TestTraceExporter はターンを親、LLM・ツールを子とするトレースの送信をテストする
+func TestTraceExporter(t *testing.T) {
+	received := make(chan map[string]interface{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/traces" || r.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("送信先・ヘッダーが不正: %s %v", r.URL.Path, r.Header)
+		}
+		var payload map[string]interface{}
+		json.NewDecoder(r.Body).Decode(&payload)
+		received <- payload
+	}))
+	defer server.Close()
+
+	bus := events.NewBus()
+	traces := NewTraceExporter(config.ObservabilityConfig{OTLPEndpoint: server.URL, OTLPHeaders: map[string]string{"Authorization": "Bearer token"}})
+	traces.Attach(bus)
+
+	now := time.Now()
+	// 前のターンの残りは含めない
+	bus.Publish(events.Event{Type: events.ToolExecuted, Time: now.Add(-time.Minute), Data: map[string]interface{}{"tool": "read", "duration_ms": int64(5), "success": true}})
+	bus.Publish(events.Event{Type: events.LLMCompleted, Time: now.Add(-500 * time.Millisecond), Data: map[string]interface{}{"model": "qwen", "duration_ms": int64(400), "success": true}})
+	bus.Publish(events.Event{Type: events.ToolExecuted, Time: now.Add(-100 * time.Millisecond), Data: map[string]interface{}{"tool": "bash", "duration_ms": int64(50), "success": false, "error": "exit 1"}})
+	bus.Publish(events.Event{Type: events.TurnCompleted, Time: now, SessionID: "s1", Data: map[string]interface{}{"duration_ms": int64(1000), "success": true}})
+	traces.Close()
+
+	var payload map[string]interface{}
+	select {
+	case payload = <-received:
+	default:
+		t.Fatal("Close までにトレースを送るはず")
+	}
+	resourceSpans := payload["resourceSpans"].([]interface{})[0].(map[string]interface{})
+	spans := resourceSpans["scopeSpans"].([]interface{})[0].(map[string]interface{})["spans"].([]interface{})
+	if len(spans) != 3 {
+		t.Fatalf("ターンと2つの子スパンを送るはず: %d", len(spans))
+	}
+	root := spans[0].(map[string]interface{})
+	if root["name"] != "turn" || root["parentSpanId"] != nil {
+		t.Errorf("先頭はターンのスパンのはず: %v", root)
+	}
+	for _, raw := range spans[1:] {
+		span := raw.(map[string]interface{})
+		if span["parentSpanId"] != root["spanId"] || span["traceId"] != root["traceId"] {
+			t.Errorf("子スパンはターンを親とするはず: %v", span)
+		}
+	}
+	failed := spans[2].(map[string]interface{})
+	if failed["name"] != "tool bash" || failed["status"].(map[string]interface{})["message"] != "exit 1" {
+		t.Errorf("失敗したツールはエラーのステータスになるはず: %v", failed)
+	}
+	if bus.HasSubscribers() {
+		t.Error("Close で購読を解除するはず")
+	}
+}
@@ -508,6 +508,20 @@ func (rm *RealtimeMonitor) GetMetrics() *RealtimeMetrics {
 	return rm.metrics
 }
 
+// gaugeValues はメトリクス出力用に現在値を取り出す（名前は Prometheus のメトリクス名）
+func (rm *RealtimeMonitor) gaugeValues() map[string]float64 {
+	rm.mutex.RLock()
+	defer rm.mutex.RUnlock()
+
+	return map[string]float64{
+		"vyb_monitor_response_time_seconds":         rm.metrics.ResponseTime.Current,
+		"vyb_monitor_response_time_average_seconds": rm.metrics.ResponseTime.Average,
+		"vyb_monitor_memory_megabytes":              rm.metrics.MemoryUsage.Current,
+		"vyb_monitor_requests":                      float64(rm.metrics.RequestCount.Total),
+		"vyb_monitor_proactive_hits":                float64(rm.metrics.ProactiveHits.Total),
+	}
+}
+
 // パフォーマンスサマリーを生成
 func (rm *RealtimeMonitor) GeneratePerformanceSummary() string {
 	rm.mutex.RLock()
@@ -0,0 +1,305 @@
+package performance
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+const (
+	traceQueueSize     = 64              // 送信待ちのトレース数（超えた分は捨てる）
+	maxPendingSpans    = 1024            // ターン終了を待つLLM・ツールのスパンの上限
+	traceSendTimeout   = 5 * time.Second // 1回の送信のタイムアウト
+	traceShutdownGrace = 5 * time.Second // 終了時に送信待ちのトレースを送る時間
+)
+
+// OTLP のスパン種別・ステータス
+const (
+	spanKindInternal = 1
+	spanKindClient   = 3
+	statusOK         = 1
+	statusError      = 2
+)
+
+// traceSpan は送信前のスパン
+type traceSpan struct {
+	name       string
+	kind       int
+	start      time.Time
+	end        time.Time
+	err        string
+	attributes map[string]interface{}
+}
+
+// TraceExporter はターン毎のトレースを OTLP/HTTP（JSON）で送る
+// ターンのスパンを親とし、そのターン中のLLM呼び出し・ツール実行を子スパンにする
+type TraceExporter struct {
+	endpoint    string
+	headers     map[string]string
+	serviceName string
+	client      *http.Client
+
+	mu          sync.Mutex
+	pending     []traceSpan
+	queue       chan []byte
+	done        chan struct{}
+	closed      bool
+	reported    bool // 送信エラーを表示済みか（毎回は表示しない）
+	unsubscribe func()
+}
+
+// NewTraceExporter はトレース送信を作成し、送信用のゴルーチンを開始する
+func NewTraceExporter(cfg config.ObservabilityConfig) *TraceExporter {
+	endpoint := strings.TrimSuffix(cfg.OTLPEndpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	serviceName := cfg.ServiceName
+	if serviceName == "" {
+		serviceName = config.DefaultObservabilityConfig().ServiceName
+	}
+
+	t := &TraceExporter{
+		endpoint:    endpoint,
+		headers:     cfg.OTLPHeaders,
+		serviceName: serviceName,
+		client:      &http.Client{Timeout: traceSendTimeout},
+		queue:       make(chan []byte, traceQueueSize),
+		done:        make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Attach はイベントバスを購読する（Close で解除）
+func (t *TraceExporter) Attach(bus *events.Bus) {
+	t.unsubscribe = bus.Subscribe("*", t.Observe)
+}
+
+// Observe はLLM・ツールのスパンを溜め、ターン完了時にまとめて送信キューに積む
+func (t *TraceExporter) Observe(event events.Event) {
+	switch event.Type {
+	case events.LLMCompleted:
+		model := stringValue(event.Data["model"])
+		t.addPending(spanFromEvent(event, "llm.chat "+model, spanKindClient, map[string]interface{}{
+			"gen_ai.request.model":       model,
+			"gen_ai.usage.input_tokens":  event.Data["prompt_tokens"],
+			"gen_ai.usage.output_tokens": event.Data["completion_tokens"],
+		}))
+	case events.ToolExecuted:
+		tool := stringValue(event.Data["tool"])
+		t.addPending(spanFromEvent(event, "tool "+tool, spanKindInternal, map[string]interface{}{
+			"vyb.tool.name": tool,
+		}))
+	case events.TurnCompleted:
+		turn := spanFromEvent(event, "turn", spanKindInternal, map[string]interface{}{
+			"vyb.session.id": event.SessionID,
+		})
+		t.mu.Lock()
+		var children []traceSpan
+		for _, span := range t.pending {
+			// ミリ秒への丸めでターン開始より僅かに前になる場合を許容
+			if !span.start.Before(turn.start.Add(-time.Millisecond)) {
+				children = append(children, span)
+			}
+		}
+		t.pending = nil
+		t.mu.Unlock()
+
+		body, err := t.encode(turn, children)
+		if err != nil {
+			return
+		}
+		t.enqueue(body)
+	}
+}
+
+func (t *TraceExporter) addPending(span traceSpan) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending = append(t.pending, span)
+	if len(t.pending) > maxPendingSpans {
+		t.pending = t.pending[len(t.pending)-maxPendingSpans:]
+	}
+}
+
+func (t *TraceExporter) enqueue(body []byte) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	select {
+	case t.queue <- body:
+	default:
+		// 送信先が応答しない場合は捨てる
+	}
+}
+
+// spanFromEvent はイベント時刻を終了、duration_ms 前を開始とするスパンを作る
+func spanFromEvent(event events.Event, name string, kind int, attributes map[string]interface{}) traceSpan {
+	duration := time.Duration(numberValue(event.Data["duration_ms"])) * time.Millisecond
+	span := traceSpan{
+		name:       name,
+		kind:       kind,
+		start:      event.Time.Add(-duration),
+		end:        event.Time,
+		attributes: attributes,
+	}
+	if success, _ := event.Data["success"].(bool); !success {
+		span.err = stringValue(event.Data["error"])
+		if span.err == "" {
+			span.err = "failed"
+		}
+	}
+	return span
+}
+
+// encode はターンとその子スパンを OTLP/JSON の ExportTraceServiceRequest にする
+func (t *TraceExporter) encode(turn traceSpan, children []traceSpan) ([]byte, error) {
+	traceID, err := randomHex(16)
+	if err != nil {
+		return nil, err
+	}
+	turnID, err := randomHex(8)
+	if err != nil {
+		return nil, err
+	}
+
+	spans := []map[string]interface{}{otlpSpan(turn, traceID, turnID, "")}
+	for _, child := range children {
+		spanID, err := randomHex(8)
+		if err != nil {
+			return nil, err
+		}
+		spans = append(spans, otlpSpan(child, traceID, spanID, turnID))
+	}
+
+	return json.Marshal(map[string]interface{}{
+		"resourceSpans": []interface{}{map[string]interface{}{
+			"resource": map[string]interface{}{
+				"attributes": otlpAttributes(map[string]interface{}{"service.name": t.serviceName}),
+			},
+			"scopeSpans": []interface{}{map[string]interface{}{
+				"scope": map[string]interface{}{"name": "vyb"},
+				"spans": spans,
+			}},
+		}},
+	})
+}
+
+func otlpSpan(span traceSpan, traceID, spanID, parentID string) map[string]interface{} {
+	status := map[string]interface{}{"code": statusOK}
+	if span.err != "" {
+		status = map[string]interface{}{"code": statusError, "message": span.err}
+	}
+	encoded := map[string]interface{}{
+		"traceId":           traceID,
+		"spanId":            spanID,
+		"name":              span.name,
+		"kind":              span.kind,
+		"startTimeUnixNano": strconv.FormatInt(span.start.UnixNano(), 10),
+		"endTimeUnixNano":   strconv.FormatInt(span.end.UnixNano(), 10),
+		"attributes":        otlpAttributes(span.attributes),
+		"status":            status,
+	}
+	if parentID != "" {
+		encoded["parentSpanId"] = parentID
+	}
+	return encoded
+}
+
+// otlpAttributes は属性を OTLP の KeyValue 列に変換（空・未設定の値は省く）
+func otlpAttributes(attributes map[string]interface{}) []map[string]interface{} {
+	var encoded []map[string]interface{}
+	for key, value := range attributes {
+		var typed map[string]interface{}
+		switch v := value.(type) {
+		case string:
+			if v == "" {
+				continue
+			}
+			typed = map[string]interface{}{"stringValue": v}
+		case bool:
+			typed = map[string]interface{}{"boolValue": v}
+		case int, int64:
+			typed = map[string]interface{}{"intValue": fmt.Sprint(v)}
+		case float64:
+			typed = map[string]interface{}{"doubleValue": v}
+		default:
+			continue
+		}
+		encoded = append(encoded, map[string]interface{}{"key": key, "value": typed})
+	}
+	return encoded
+}
+
+func randomHex(size int) (string, error) {
+	buf := make([]byte, size)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func (t *TraceExporter) run() {
+	defer close(t.done)
+	for body := range t.queue {
+		if err := t.send(body); err != nil && !t.reported {
+			t.reported = true
+			fmt.Fprintf(os.Stderr, "トレース送信エラー (%s): %v\n", t.endpoint, err)
+		}
+	}
+}
+
+func (t *TraceExporter) send(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, t.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range t.headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// Close は購読を解除し、送信待ちのトレースを送り切る（最大 traceShutdownGrace 待つ）
+func (t *TraceExporter) Close() {
+	if t.unsubscribe != nil {
+		t.unsubscribe()
+		t.unsubscribe = nil
+	}
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return
+	}
+	t.closed = true
+	close(t.queue)
+	t.mu.Unlock()
+
+	select {
+	case <-t.done:
+	case <-time.After(traceShutdownGrace):
+	}
+}
@@ -0,0 +1,480 @@
+package plugins
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"plugin"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// ExtensionManifestFile は拡張ディレクトリのマニフェスト名
+const ExtensionManifestFile = "plugin.yaml"
+
+// GoExtensionSymbol は Go プラグイン（.so）が公開する初期化関数の名前
+// シグネチャは func(*plugins.Host) error
+const GoExtensionSymbol = "VybExtension"
+
+const (
+	eventQueueSize    = 256             // 外部プロセスへ送る前に溜めるイベント数（超えた分は捨てる）
+	sinkShutdownGrace = 3 * time.Second // 終了時に外部プロセスの終了を待つ時間
+)
+
+// ExtensionManifest は plugin.yaml の内容
+//
+// 外部プロセス拡張は command で実行ファイルを指定する:
+//   - events に一致するイベントは `command events` の標準入力に JSON Lines で送る（オブザーバー・出力先）
+//   - tools のツールは呼び出し毎に `command tool <name>` を起動し、パラメーターのJSONを標準入力に渡す
+//     （標準出力が結果、終了コード0以外は失敗として標準エラーをエラーメッセージにする）
+//
+// Go 拡張は go_plugin で .so を指定し、VybExtension(*Host) でツール・購読者・言語パックを登録する
+//
+// languages はプロジェクト分析に追加する言語の宣言的な定義（command・go_plugin が無くてもよい）
+type ExtensionManifest struct {
+	Name        string                           `yaml:"name" json:"name"`
+	Description string                           `yaml:"description,omitempty" json:"description,omitempty"`
+	Version     string                           `yaml:"version,omitempty" json:"version,omitempty"`
+	Command     string                           `yaml:"command,omitempty" json:"command,omitempty"` // 引数付き可。相対パスは拡張ディレクトリ基準
+	Events      []string                         `yaml:"events,omitempty" json:"events,omitempty"`   // 購読するイベント（"*"、"tool.*" 等）
+	Tools       []ExtensionToolSpec              `yaml:"tools,omitempty" json:"tools,omitempty"`
+	GoPlugin    string                           `yaml:"go_plugin,omitempty" json:"go_plugin,omitempty"`
+	Languages   []*tools.DeclarativeLanguagePack `yaml:"languages,omitempty" json:"languages,omitempty"`
+	Settings    map[string]interface{}           `yaml:"settings,omitempty" json:"settings,omitempty"` // Go 拡張に渡す任意の設定
+	Dir         string                           `yaml:"-" json:"dir"`
+}
+
+// ExtensionToolSpec は外部プロセス拡張が提供するツールの定義
+type ExtensionToolSpec struct {
+	Name        string                        `yaml:"name" json:"name"`
+	Description string                        `yaml:"description" json:"description"`
+	Parameters  map[string]ExtensionParameter `yaml:"parameters,omitempty" json:"parameters,omitempty"`
+}
+
+// ExtensionParameter は拡張ツールのパラメーター定義
+type ExtensionParameter struct {
+	Type        string `yaml:"type" json:"type"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool   `yaml:"required,omitempty" json:"required,omitempty"`
+}
+
+// ToolRegistrar は拡張がツールを追加する先（tools.UnifiedToolRegistry が満たす）
+type ToolRegistrar interface {
+	RegisterTool(tool tools.UnifiedToolInterface) error
+}
+
+// Host は拡張に公開する機能
+type Host struct {
+	Bus   *events.Bus
+	Tools ToolRegistrar
+
+	// Go 拡張の読み込み中はその拡張の設定（plugin.yaml の settings）
+	Settings map[string]interface{}
+
+	unsubscribe []func()
+}
+
+// RegisterLanguagePack はプロジェクト分析に言語を追加する（以降に作成した LanguageManager で使われる）
+func (h *Host) RegisterLanguagePack(pack tools.LanguagePack) {
+	tools.RegisterLanguagePack(pack)
+}
+
+// Subscribe はイベントを購読する（拡張の終了時に自動で解除される）
+func (h *Host) Subscribe(pattern string, handler events.Handler) {
+	h.unsubscribe = append(h.unsubscribe, h.Bus.Subscribe(pattern, handler))
+}
+
+// Extensions は読み込んだ拡張
+type Extensions struct {
+	Loaded []*ExtensionManifest
+	Errors map[string]error // 拡張名（またはマニフェストのパス）毎の読み込みエラー
+
+	host  *Host
+	sinks []*eventSink
+}
+
+// ExtensionDirs は拡張を探すディレクトリ（~/.vyb/plugins、設定で有効ならプロジェクトの .vyb/plugins）
+func ExtensionDirs(projectDir string, cfg config.ExtensionsConfig) []string {
+	var dirs []string
+	if home, err := os.UserHomeDir(); err == nil {
+		dirs = append(dirs, filepath.Join(home, ".vyb", "plugins"))
+	}
+	if cfg.Project && projectDir != "" {
+		dirs = append(dirs, filepath.Join(projectDir, config.ProjectConfigDir, "plugins"))
+	}
+	return dirs
+}
+
+// DiscoverExtensions はディレクトリ直下の <name>/plugin.yaml を読み込む（同名は先のディレクトリを優先）
+func DiscoverExtensions(dirs ...string) ([]*ExtensionManifest, map[string]error) {
+	seen := make(map[string]bool)
+	var manifests []*ExtensionManifest
+	invalid := make(map[string]error)
+
+	for _, dir := range dirs {
+		paths, _ := filepath.Glob(filepath.Join(dir, "*", ExtensionManifestFile))
+		sort.Strings(paths)
+		for _, path := range paths {
+			manifest, err := LoadExtensionManifest(path)
+			if err != nil {
+				invalid[path] = err
+				continue
+			}
+			if seen[manifest.Name] {
+				continue
+			}
+			seen[manifest.Name] = true
+			manifests = append(manifests, manifest)
+		}
+	}
+	return manifests, invalid
+}
+
+// LoadExtensionManifest は plugin.yaml を読み込んで検証する
+func LoadExtensionManifest(path string) (*ExtensionManifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("マニフェスト読み込みエラー: %w", err)
+	}
+	var manifest ExtensionManifest
+	if err := yaml.Unmarshal(data, &manifest); err != nil {
+		return nil, fmt.Errorf("マニフェスト解析エラー (%s): %w", path, err)
+	}
+	manifest.Dir = filepath.Dir(path)
+	if manifest.Name == "" {
+		manifest.Name = filepath.Base(manifest.Dir)
+	}
+
+	if manifest.Command == "" && manifest.GoPlugin == "" && len(manifest.Languages) == 0 {
+		return nil, fmt.Errorf("%s: command・go_plugin・languages のいずれかが必要です", path)
+	}
+	if manifest.Command == "" && (len(manifest.Events) > 0 || len(manifest.Tools) > 0) {
+		return nil, fmt.Errorf("%s: events・tools には command が必要です", path)
+	}
+	for _, spec := range manifest.Tools {
+		if spec.Name == "" || spec.Description == "" {
+			return nil, fmt.Errorf("%s: ツールには name と description が必要です", path)
+		}
+	}
+	for _, language := range manifest.Languages {
+		if err := language.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	return &manifest, nil
+}
+
+// LoadExtensions は設定に従って拡張を探し、購読者とツールを host に登録する
+// 個々の拡張の失敗は Errors に記録し、他の拡張の読み込みは続ける
+func LoadExtensions(host *Host, cfg config.ExtensionsConfig, projectDir string) *Extensions {
+	loaded := &Extensions{host: host, Errors: make(map[string]error)}
+	if !cfg.Enabled {
+		return loaded
+	}
+
+	disabled := make(map[string]bool)
+	for _, name := range cfg.Disabled {
+		disabled[name] = true
+	}
+	timeout := time.Duration(cfg.TimeoutSeconds) * time.Second
+
+	manifests, invalid := DiscoverExtensions(ExtensionDirs(projectDir, cfg)...)
+	for path, err := range invalid {
+		loaded.Errors[path] = err
+	}
+	for _, manifest := range manifests {
+		if disabled[manifest.Name] {
+			continue
+		}
+		if err := loaded.load(manifest, timeout); err != nil {
+			loaded.Errors[manifest.Name] = err
+			continue
+		}
+		loaded.Loaded = append(loaded.Loaded, manifest)
+	}
+	return loaded
+}
+
+// load は1つの拡張のツール・購読者・Go プラグインを登録
+func (e *Extensions) load(manifest *ExtensionManifest, timeout time.Duration) error {
+	for _, language := range manifest.Languages {
+		e.host.RegisterLanguagePack(language)
+	}
+	if manifest.GoPlugin != "" {
+		if err := e.loadGoPlugin(manifest); err != nil {
+			return err
+		}
+	}
+	if manifest.Command == "" {
+		return nil
+	}
+
+	program, args, err := resolveCommand(manifest)
+	if err != nil {
+		return err
+	}
+	for _, spec := range manifest.Tools {
+		if e.host.Tools == nil {
+			break
+		}
+		tool := newExternalTool(manifest.Name, spec, program, args, manifest.Dir, timeout)
+		if err := e.host.Tools.RegisterTool(tool); err != nil {
+			return fmt.Errorf("ツール %s の登録エラー: %w", spec.Name, err)
+		}
+	}
+	if len(manifest.Events) > 0 && e.host.Bus != nil {
+		sink := &eventSink{name: manifest.Name, program: program, args: args, dir: manifest.Dir, patterns: manifest.Events, queue: make(chan events.Event, eventQueueSize)}
+		e.host.Subscribe("*", sink.enqueue)
+		e.sinks = append(e.sinks, sink)
+	}
+	return nil
+}
+
+// loadGoPlugin は .so を開いて VybExtension(*Host) を呼ぶ
+func (e *Extensions) loadGoPlugin(manifest *ExtensionManifest) error {
+	path := manifest.GoPlugin
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(manifest.Dir, path)
+	}
+	module, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("Goプラグイン読み込みエラー: %w", err)
+	}
+	symbol, err := module.Lookup(GoExtensionSymbol)
+	if err != nil {
+		return fmt.Errorf("Goプラグインに %s がありません: %w", GoExtensionSymbol, err)
+	}
+	init, ok := symbol.(func(*Host) error)
+	if !ok {
+		return fmt.Errorf("%s のシグネチャが func(*plugins.Host) error ではありません", GoExtensionSymbol)
+	}
+
+	e.host.Settings = manifest.Settings
+	defer func() { e.host.Settings = nil }()
+	if err := init(e.host); err != nil {
+		return fmt.Errorf("Goプラグイン初期化エラー: %w", err)
+	}
+	return nil
+}
+
+// Close は購読を解除し、イベントを受け取る外部プロセスを終了させる
+func (e *Extensions) Close() {
+	if e.host != nil {
+		for _, unsubscribe := range e.host.unsubscribe {
+			unsubscribe()
+		}
+		e.host.unsubscribe = nil
+	}
+	for _, sink := range e.sinks {
+		sink.close()
+	}
+	e.sinks = nil
+}
+
+// resolveCommand は command を実行ファイルと引数に分ける（相対パスは拡張ディレクトリ基準）
+func resolveCommand(manifest *ExtensionManifest) (string, []string, error) {
+	fields := strings.Fields(manifest.Command)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("command が空です")
+	}
+	program := fields[0]
+	if strings.ContainsRune(program, filepath.Separator) || strings.HasPrefix(program, ".") {
+		if !filepath.IsAbs(program) {
+			program = filepath.Join(manifest.Dir, program)
+		}
+		if _, err := os.Stat(program); err != nil {
+			return "", nil, fmt.Errorf("command が見つかりません: %w", err)
+		}
+	} else if _, err := exec.LookPath(program); err != nil {
+		return "", nil, fmt.Errorf("command が見つかりません: %w", err)
+	}
+	return program, fields[1:], nil
+}
+
+// eventSink はイベントを外部プロセスの標準入力に JSON Lines で送る
+// プロセスは最初のイベントで起動し、書き込みに失敗したら以降のイベントを捨てる
+type eventSink struct {
+	name     string
+	program  string
+	args     []string
+	dir      string
+	patterns []string
+
+	mu      sync.Mutex
+	queue   chan events.Event
+	started bool
+	closed  bool
+	done    chan struct{}
+}
+
+// enqueue は購読するイベントをキューに積む（購読者として呼ばれるため待たない）
+func (s *eventSink) enqueue(event events.Event) {
+	matched := false
+	for _, pattern := range s.patterns {
+		if events.Match(pattern, event.Type) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	if !s.started {
+		s.started = true
+		s.done = make(chan struct{})
+		go s.run()
+	}
+	select {
+	case s.queue <- event:
+	default:
+		// 外部プロセスが追いつかない場合は捨てる
+	}
+}
+
+func (s *eventSink) run() {
+	defer close(s.done)
+
+	cmd := exec.Command(s.program, append(append([]string{}, s.args...), "events")...)
+	cmd.Dir = s.dir
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err == nil {
+		err = cmd.Start()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "拡張 %s の起動エラー: %v\n", s.name, err)
+		for range s.queue {
+		}
+		return
+	}
+	writer := bufio.NewWriter(stdin)
+	encoder := json.NewEncoder(writer)
+	healthy := true
+	for event := range s.queue {
+		if !healthy {
+			continue
+		}
+		if err := encoder.Encode(event); err == nil {
+			err = writer.Flush()
+		}
+		if err != nil {
+			healthy = false
+		}
+	}
+	stdin.Close()
+
+	exited := make(chan struct{})
+	go func() {
+		cmd.Wait()
+		close(exited)
+	}()
+	select {
+	case <-exited:
+	case <-time.After(sinkShutdownGrace):
+		cmd.Process.Kill()
+		<-exited
+	}
+}
+
+// close はキューを閉じ、送信済みのイベントを処理した外部プロセスの終了を待つ
+func (s *eventSink) close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.queue)
+	done := s.done
+	s.mu.Unlock()
+
+	if done != nil {
+		<-done
+	}
+}
+
+// ExternalTool は外部プロセス拡張のツール
+type ExternalTool struct {
+	*tools.BaseTool
+	program string
+	args    []string
+	dir     string
+	timeout time.Duration
+}
+
+func newExternalTool(extension string, spec ExtensionToolSpec, program string, args []string, dir string, timeout time.Duration) *ExternalTool {
+	base := tools.NewBaseTool(spec.Name, spec.Description, "1.0.0", tools.CategoryUtility)
+	schema := tools.ToolSchema{
+		Name:        spec.Name,
+		Description: spec.Description,
+		Version:     extension,
+		Parameters:  make(map[string]tools.Parameter),
+	}
+	for name, param := range spec.Parameters {
+		schema.Parameters[name] = tools.Parameter{Type: param.Type, Description: param.Description}
+		if param.Required {
+			schema.Required = append(schema.Required, name)
+		}
+	}
+	sort.Strings(schema.Required)
+	base.SetSchema(schema)
+
+	return &ExternalTool{BaseTool: base, program: program, args: args, dir: dir, timeout: timeout}
+}
+
+// Execute は `command tool <name>` を起動し、パラメーターのJSONを標準入力に渡す
+func (t *ExternalTool) Execute(ctx context.Context, request *tools.ToolRequest) (*tools.ToolResponse, error) {
+	params, err := json.Marshal(request.Parameters)
+	if err != nil {
+		return nil, fmt.Errorf("パラメーターのJSON変換エラー: %w", err)
+	}
+	if t.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, t.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, t.program, append(append([]string{}, t.args...), "tool", t.GetName())...)
+	cmd.Dir = t.dir
+	cmd.Stdin = bytes.NewReader(params)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	runErr := cmd.Run()
+	response := &tools.ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  runErr == nil,
+		Content:  stdout.String(),
+	}
+	if runErr != nil {
+		response.Error = strings.TrimSpace(stderr.String())
+		if response.Error == "" {
+			response.Error = runErr.Error()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			response.Error = fmt.Sprintf("拡張ツールがタイムアウトしました（%s）", t.timeout)
+		}
+	}
+	return response, nil
+}
@@ -0,0 +1,167 @@
+package plugins
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// writeExtension は plugin.yaml と実行スクリプトを持つ拡張ディレクトリを作成
+func writeExtension(t *testing.T, root, name, manifest, script string) string {
+	t.Helper()
+	dir := filepath.Join(root, name)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, ExtensionManifestFile), []byte(manifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if script != "" {
+		if err := os.WriteFile(filepath.Join(dir, "ext.sh"), []byte(script), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+const testExtensionScript = `#!/bin/sh
+case "$1" in
+tool)
+  if [ "$2" = "fail" ]; then echo "broken" >&2; exit 1; fi
+  echo "$2:"; cat ;;
+events)
+  cat >> events.jsonl ;;
+esac
+`
+
+func TestDiscoverExtensions(t *testing.T) {
+	user := t.TempDir()
+	project := t.TempDir()
+	writeExtension(t, user, "echo", "name: echo\ncommand: ./ext.sh\ntools:\n  - name: echo\n    description: echo params\n", testExtensionScript)
+	writeExtension(t, project, "echo", "name: echo\ncommand: ./ext.sh\n", testExtensionScript)
+	writeExtension(t, project, "broken", "description: no command\n", "")
+
+	manifests, invalid := DiscoverExtensions(user, project)
+	if len(manifests) != 1 || manifests[0].Dir != filepath.Join(user, "echo") {
+		t.Errorf("同名の拡張は先のディレクトリを優先するはず: %+v", manifests)
+	}
+	if len(invalid) != 1 {
+		t.Errorf("command のないマニフェストはエラーになるはず: %v", invalid)
+	}
+}
+
+func TestExtensionDirs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	dirs := ExtensionDirs("/work", config.ExtensionsConfig{})
+	if len(dirs) != 1 || dirs[0] != filepath.Join(home, ".vyb", "plugins") {
+		t.Errorf("既定ではユーザーの拡張のみのはず: %v", dirs)
+	}
+	dirs = ExtensionDirs("/work", config.ExtensionsConfig{Project: true})
+	if len(dirs) != 2 || dirs[1] != filepath.Join("/work", ".vyb", "plugins") {
+		t.Errorf("project 有効時はプロジェクトの拡張も探すはず: %v", dirs)
+	}
+}
+
+func TestLoadExtensionsToolsAndEvents(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	root := filepath.Join(home, ".vyb", "plugins")
+	dir := writeExtension(t, root, "echo", `name: echo
+command: ./ext.sh
+events: ["edit.*"]
+tools:
+  - name: echo
+    description: echo params
+    parameters:
+      text: {type: string, required: true}
+  - name: fail
+    description: always fails
+`, testExtensionScript)
+	writeExtension(t, root, "off", "name: off\ncommand: ./ext.sh\n", testExtensionScript)
+
+	bus := events.NewBus()
+	registry := tools.NewUnifiedToolRegistry(security.NewDefaultConstraints(home), nil)
+	cfg := config.DefaultExtensionsConfig()
+	cfg.Disabled = []string{"off"}
+	loaded := LoadExtensions(&Host{Bus: bus, Tools: registry}, cfg, "")
+	if len(loaded.Errors) != 0 || len(loaded.Loaded) != 1 {
+		t.Fatalf("echo 拡張のみ読み込むはず: loaded=%d errors=%v", len(loaded.Loaded), loaded.Errors)
+	}
+
+	response, err := registry.ExecuteTool(context.Background(), &tools.ToolRequest{ToolName: "echo", Parameters: map[string]interface{}{"text": "hi"}})
+	if err != nil || !response.Success || !strings.Contains(response.Content, `echo:`) || !strings.Contains(response.Content, `"text":"hi"`) {
+		t.Errorf("パラメーターを標準入力で渡すはず: %+v err=%v", response, err)
+	}
+	response, _ = registry.ExecuteTool(context.Background(), &tools.ToolRequest{ToolName: "fail", Parameters: map[string]interface{}{}})
+	if response == nil || response.Success || response.Error != "broken" {
+		t.Errorf("終了コード0以外は失敗として標準エラーを返すはず: %+v", response)
+	}
+
+	bus.Publish(events.Event{Type: events.EditApplied, Data: map[string]interface{}{"path": "main.go"}})
+	bus.Publish(events.Event{Type: events.SessionStarted})
+	loaded.Close()
+	if bus.HasSubscribers() {
+		t.Error("Close で購読を解除するはず")
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "events.jsonl"))
+	if err != nil {
+		t.Fatalf("イベントが外部プロセスに届くはず: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 1 || !strings.Contains(lines[0], `"type":"edit.applied"`) || !strings.Contains(lines[0], `"path":"main.go"`) {
+		t.Errorf("購読パターンに一致するイベントのみ送るはず: %v", lines)
+	}
+}
+
+func TestLoadExtensionsDisabled(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	writeExtension(t, filepath.Join(home, ".vyb", "plugins"), "echo", "name: echo\ncommand: ./ext.sh\nevents: ['*']\n", testExtensionScript)
+
+	bus := events.NewBus()
+	loaded := LoadExtensions(&Host{Bus: bus}, config.ExtensionsConfig{Enabled: false}, "")
+	if len(loaded.Loaded) != 0 || bus.HasSubscribers() {
+		t.Error("無効時は拡張を読み込まないはず")
+	}
+	loaded.Close()
+}
+
+func TestLoadExtensionsLanguages(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	root := filepath.Join(home, ".vyb", "plugins")
+	writeExtension(t, root, "elixir", `name: elixir
+languages:
+  - name: Elixir
+    extensions: [ex, .exs]
+    manifest: mix.exs
+    dependency_pattern: '^\s*\{:(\w+),'
+    test: mix test
+`, "")
+	writeExtension(t, root, "broken", "languages:\n  - name: Zig\n    extensions: [zig]\n    dependency_pattern: '('\n", "")
+
+	loaded := LoadExtensions(&Host{}, config.ExtensionsConfig{Enabled: true}, "")
+	defer loaded.Close()
+	if len(loaded.Loaded) != 1 || len(loaded.Errors) != 1 {
+		t.Fatalf("loaded = %d, errors = %v", len(loaded.Loaded), loaded.Errors)
+	}
+
+	lang := tools.NewLanguageManager().DetectLanguage("lib/app.ex")
+	if lang == nil || lang.GetName() != "Elixir" || lang.GetTestCommand() != "mix test" {
+		t.Fatalf("宣言した言語パックで検出できるはず: %v", lang)
+	}
+	deps := lang.ParseDependencies("defp deps do\n  [\n    {:phoenix, \"~> 1.7\"},\n    {:ecto_sql, \"~> 3.10\"}\n  ]\nend\n")
+	if strings.Join(deps, ",") != "phoenix,ecto_sql" {
+		t.Errorf("dependencies = %v", deps)
+	}
+}
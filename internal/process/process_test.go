@@ -0,0 +1,15 @@
+package process
+
+import (
+	"os"
+	"testing"
+)
+
+func TestAlive(t *testing.T) {
+	if !Alive(os.Getpid()) {
+		t.Error("current process should be alive")
+	}
+	if Alive(-1) {
+		t.Error("invalid pid should not be alive")
+	}
+}
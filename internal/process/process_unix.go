@@ -0,0 +1,21 @@
+//go:build !windows
+// +build !windows
+
+// Package process は他のプロセスの状態の確認（ロックやスナップショットを残したプロセスが動作中か等）
+package process
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// Alive はプロセスが動作中か（シグナル0で存在のみ確認）
+func Alive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,17 @@
+//go:build windows
+// +build windows
+
+// Package process は他のプロセスの状態の確認（ロックやスナップショットを残したプロセスが動作中か等）
+package process
+
+import "os"
+
+// Alive はプロセスが動作中か（Windowsでは終了したプロセスは開けない）
+func Alive(pid int) bool {
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	process.Release()
+	return true
+}
@@ -0,0 +1,276 @@
+package prompts
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+//go:embed templates/*.tmpl
+var embeddedTemplates embed.FS
+
+// テンプレートファイルの拡張子
+const templateExt = ".tmpl"
+
+// テンプレート名
+const (
+	TemplateInteractive  = "interactive"  // インタラクティブセッションの応答プロンプト
+	TemplateReview       = "review"       // vyb review の差分レビュープロンプト
+	TemplateTestGen      = "testgen"      // vyb gen tests のテスト生成プロンプト
+	TemplateDocGen       = "docgen"       // vyb gen docs のドキュメントコメント生成プロンプト
+	TemplateReadme       = "readme"       // vyb gen docs --readme のパッケージREADME生成プロンプト
+	TemplateRefactor     = "refactor"     // vyb refactor の複数ファイル書き換えプロンプト
+	TemplateScaffold     = "scaffold"     // vyb scaffold ci のCI設定・Makefile生成プロンプト
+	TemplateCommitMsg    = "commitmsg"    // vyb hooks の commit-msg フックのコミットメッセージ提案プロンプト
+	TemplateLearn        = "learn"        // vyb learn のプロジェクト概要（VYB.md のオンボーディング）生成プロンプト
+	TemplateCIFix        = "cifix"        // vyb ci-fix の CI の失敗の修正依頼プロンプト
+	TemplateResolve      = "resolve"      // vyb git resolve のマージ競合の解決提案プロンプト
+	TemplateReleaseNotes = "releasenotes" // vyb release notes の利用者向けの変更の要約プロンプト
+)
+
+// 取得元
+const (
+	SourceEmbedded = "embedded"
+	SourceOverride = "override"
+)
+
+// Tool はプロンプトに列挙するツール
+type Tool struct {
+	Name        string // ツール名
+	Usage       string // 呼び出し形式（構造化タグ等）
+	Description string // 説明
+	Purpose     string // 使用すべき場面
+	Focus       bool   // セッションテンプレートが重視するツール
+}
+
+// PinnedFile はセッションに固定したファイル（毎ターン最新の内容を読み込む）
+type PinnedFile struct {
+	Path     string
+	Language string // コードブロックの言語（拡張子）
+	Content  string
+	Note     string // 切り詰め・削除等の注記
+}
+
+// Guide はプロジェクトで検出した言語・フレームワークの指針（.vyb/knowledge）
+type Guide struct {
+	Name     string
+	Guidance string
+}
+
+// Data はテンプレートに渡すパラメーター
+type Data struct {
+	SessionType  string // general, debugging, refactor, review, learning
+	SessionLabel string // 表示用のセッション種別
+	Language     string // 応答言語（ja, en）
+	ModelFamily  string // モデルファミリー（qwen, llama等）
+	Tools        []Tool
+
+	Memory string  // プロジェクトメモリ（VYB.md）
+	Guides []Guide // 検出した言語・フレームワークの指針
+
+	Template     string   // セッションテンプレート名（vyb --template）
+	Instructions string   // テンプレートの指示
+	Plan         []string // テンプレートの作業計画の骨組み
+
+	Pinned        []PinnedFile // /pin で固定したファイル（編集の既定の対象）
+	PinnedContext []string     // /pin-context・<PIN> で固定した設計判断・制約等（圧縮で除かない）
+
+	CurrentFile string
+	Intent      string
+	LastOutput  string
+	Context     string
+	History     string
+	Input       string
+
+	Framework  string // テストフレームワーク（go, pytest, jest, vitest）
+	TargetFile string // 生成するファイルのパス
+}
+
+// Info はテンプレートの所在情報
+type Info struct {
+	Name   string `json:"name"`
+	Source string `json:"source"`         // embedded, override
+	Path   string `json:"path,omitempty"` // 上書きファイルのパス
+}
+
+// Registry は組み込みテンプレートとユーザー上書きテンプレートを管理
+type Registry struct {
+	overrideDir string
+}
+
+// NewRegistry は上書きディレクトリを指定してレジストリを作成（空なら組み込みのみ）
+func NewRegistry(overrideDir string) *Registry {
+	return &Registry{overrideDir: overrideDir}
+}
+
+// DefaultRegistry は ~/.vyb/prompts を上書きディレクトリとするレジストリを返す
+func DefaultRegistry() *Registry {
+	return NewRegistry(DefaultOverrideDir())
+}
+
+// DefaultOverrideDir はユーザー上書きテンプレートの保存先
+func DefaultOverrideDir() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(homeDir, ".vyb", "prompts")
+}
+
+// OverrideDir は上書きディレクトリを返す
+func (r *Registry) OverrideDir() string {
+	return r.overrideDir
+}
+
+// OverridePath はテンプレート名に対応する上書きファイルのパス
+func (r *Registry) OverridePath(name string) string {
+	if r.overrideDir == "" {
+		return ""
+	}
+	return filepath.Join(r.overrideDir, name+templateExt)
+}
+
+// Candidates はデータに応じたテンプレート名の探索順（具体的なものから）
+// 例: interactive.debugging.en → interactive.debugging → interactive.en → interactive
+func Candidates(name string, data Data) []string {
+	var candidates []string
+	if data.SessionType != "" && data.Language != "" {
+		candidates = append(candidates, name+"."+data.SessionType+"."+data.Language)
+	}
+	if data.SessionType != "" {
+		candidates = append(candidates, name+"."+data.SessionType)
+	}
+	if data.Language != "" {
+		candidates = append(candidates, name+"."+data.Language)
+	}
+	return append(candidates, name)
+}
+
+// Source はテンプレート本文を取得（上書きファイルを優先）
+func (r *Registry) Source(name string) (string, Info, error) {
+	if path := r.OverridePath(name); path != "" {
+		if data, err := os.ReadFile(path); err == nil {
+			return string(data), Info{Name: name, Source: SourceOverride, Path: path}, nil
+		}
+	}
+
+	data, err := embeddedTemplates.ReadFile("templates/" + name + templateExt)
+	if err != nil {
+		return "", Info{}, fmt.Errorf("テンプレートが見つかりません: %s", name)
+	}
+	return string(data), Info{Name: name, Source: SourceEmbedded}, nil
+}
+
+// Resolve はデータに最も適合するテンプレートを探索
+func (r *Registry) Resolve(name string, data Data) (string, Info, error) {
+	for _, candidate := range Candidates(name, data) {
+		if source, info, err := r.Source(candidate); err == nil {
+			return source, info, nil
+		}
+	}
+	return "", Info{}, fmt.Errorf("テンプレートが見つかりません: %s", name)
+}
+
+// Render はテンプレートを解決して描画
+func (r *Registry) Render(name string, data Data) (string, error) {
+	source, info, err := r.Resolve(name, data)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(info.Name).Funcs(templateFuncs).Option("missingkey=zero").Parse(source)
+	if err != nil {
+		return "", fmt.Errorf("テンプレート解析エラー (%s): %w", info.Name, err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return "", fmt.Errorf("テンプレート描画エラー (%s): %w", info.Name, err)
+	}
+	return strings.TrimRight(buf.String(), "\n"), nil
+}
+
+// List は利用可能なテンプレート一覧を返す（上書きがあればそちらを表示）
+func (r *Registry) List() ([]Info, error) {
+	infos := make(map[string]Info)
+
+	entries, err := fs.ReadDir(embeddedTemplates, "templates")
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range entries {
+		name := strings.TrimSuffix(entry.Name(), templateExt)
+		infos[name] = Info{Name: name, Source: SourceEmbedded}
+	}
+
+	if r.overrideDir != "" {
+		overrides, _ := filepath.Glob(filepath.Join(r.overrideDir, "*"+templateExt))
+		for _, path := range overrides {
+			name := strings.TrimSuffix(filepath.Base(path), templateExt)
+			infos[name] = Info{Name: name, Source: SourceOverride, Path: path}
+		}
+	}
+
+	list := make([]Info, 0, len(infos))
+	for _, info := range infos {
+		list = append(list, info)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// EnsureOverride は上書きファイルがなければ組み込みテンプレートをコピーしてパスを返す
+func (r *Registry) EnsureOverride(name string) (string, error) {
+	path := r.OverridePath(name)
+	if path == "" {
+		return "", fmt.Errorf("上書きディレクトリが設定されていません")
+	}
+	if _, err := os.Stat(path); err == nil {
+		return path, nil
+	}
+
+	// 組み込みがない名前（例: interactive.review）は基本テンプレートから作成
+	source, _, err := r.Source(name)
+	if err != nil {
+		base := strings.SplitN(name, ".", 2)[0]
+		if source, _, err = r.Source(base); err != nil {
+			return "", err
+		}
+	}
+
+	if err := os.MkdirAll(r.overrideDir, 0755); err != nil {
+		return "", fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		return "", fmt.Errorf("テンプレート書き込みエラー: %w", err)
+	}
+	return path, nil
+}
+
+// templateFuncs はテンプレート内で使える補助関数
+var templateFuncs = template.FuncMap{
+	"inc": func(i int) int { return i + 1 },
+}
+
+// ModelFamily はモデル名からファミリー名を推定（qwen2.5-coder:14b → qwen）
+func ModelFamily(model string) string {
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	name = strings.SplitN(name, ":", 2)[0]
+
+	// 長い名前を先に照合（codellama を llama より優先）
+	for _, family := range []string{"codellama", "deepseek", "qwen", "llama", "mistral", "gemma", "phi", "starcoder"} {
+		if strings.HasPrefix(name, family) {
+			return family
+		}
+	}
+	return "generic"
+}
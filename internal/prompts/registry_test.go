@@ -0,0 +1,197 @@
+package prompts
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func testData() Data {
+	return Data{
+		SessionType:  "general",
+		SessionLabel: "一般的なコーディング",
+		Language:     "ja",
+		ModelFamily:  "qwen",
+		Tools: []Tool{
+			{Name: "command", Usage: "<COMMAND>command</COMMAND>", Description: "Bashコマンド実行", Purpose: "コマンド実行"},
+		},
+		Input: "git statusを実行",
+	}
+}
+
+func TestRegistry_RenderEmbedded(t *testing.T) {
+	registry := NewRegistry("")
+
+	prompt, err := registry.Render(TemplateInteractive, testData())
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+
+	for _, expected := range []string{
+		"1. <COMMAND>command</COMMAND> - Bashコマンド実行",
+		"- コマンド実行 → <COMMAND>command</COMMAND>",
+		"git statusを実行",
+		"完全な形で示してください", // qwen向けの追加指示
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("prompt should contain %q", expected)
+		}
+	}
+	if strings.Contains(prompt, "Debugging Session") {
+		t.Error("general session should not include debugging guidance")
+	}
+}
+
+func TestRegistry_RenderProjectMemory(t *testing.T) {
+	registry := NewRegistry("")
+
+	data := testData()
+	prompt, err := registry.Render(TemplateInteractive, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if strings.Contains(prompt, "Project Memory") {
+		t.Error("prompt should not include an empty project memory section")
+	}
+
+	data.Memory = "- テストは make test で実行する"
+	prompt, err = registry.Render(TemplateInteractive, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(prompt, "## 📌 Project Memory (VYB.md)") || !strings.Contains(prompt, data.Memory+"\n\n## 🔄 Session Context") {
+		t.Errorf("project memory section is not rendered before the session context:\n%s", prompt)
+	}
+}
+
+func TestRegistry_RenderSessionTemplate(t *testing.T) {
+	registry := NewRegistry("")
+
+	data := testData()
+	data.Template = "bugfix"
+	data.Instructions = "修正前に失敗を再現してください。"
+	data.Plan = []string{"失敗を再現する", "原因を特定する"}
+	data.Tools[0].Focus = true
+	prompt, err := registry.Render(TemplateInteractive, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	for _, expected := range []string{
+		"## 🧭 Session Template: bugfix\n修正前に失敗を再現してください。",
+		"1. 失敗を再現する\n2. 原因を特定する",
+		"- 重点的に使うツール: <COMMAND>command</COMMAND>",
+	} {
+		if !strings.Contains(prompt, expected) {
+			t.Errorf("prompt should contain %q:\n%s", expected, prompt)
+		}
+	}
+}
+
+func TestRegistry_ResolveBySessionTypeAndLanguage(t *testing.T) {
+	registry := NewRegistry("")
+	data := testData()
+	data.SessionType = "debugging"
+	data.Language = "en"
+
+	_, info, err := registry.Resolve(TemplateInteractive, data)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+	if info.Name != "interactive.en" {
+		t.Errorf("expected interactive.en, got %s", info.Name)
+	}
+
+	prompt, err := registry.Render(TemplateInteractive, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if !strings.Contains(prompt, "Debugging Session") || !strings.Contains(prompt, "You are the vyb AI coding assistant") {
+		t.Errorf("unexpected prompt: %s", prompt)
+	}
+}
+
+func TestRegistry_Override(t *testing.T) {
+	dir := t.TempDir()
+	registry := NewRegistry(dir)
+
+	// 未上書きなら組み込み
+	if _, info, _ := registry.Source(TemplateInteractive); info.Source != SourceEmbedded {
+		t.Errorf("expected embedded source, got %s", info.Source)
+	}
+
+	// セッションタイプ別の上書きが基本テンプレートより優先される
+	override := filepath.Join(dir, "interactive.review.tmpl")
+	if err := os.WriteFile(override, []byte("REVIEW {{.Input}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	data := testData()
+	data.SessionType = "review"
+	prompt, err := registry.Render(TemplateInteractive, data)
+	if err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	if prompt != "REVIEW git statusを実行" {
+		t.Errorf("expected override prompt, got %q", prompt)
+	}
+
+	list, err := registry.List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	found := false
+	for _, info := range list {
+		if info.Name == "interactive.review" && info.Source == SourceOverride {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("override should be listed: %+v", list)
+	}
+}
+
+func TestRegistry_EnsureOverride(t *testing.T) {
+	dir := t.TempDir()
+	registry := NewRegistry(dir)
+
+	// 組み込みにない名前は基本テンプレートから作成
+	path, err := registry.EnsureOverride("interactive.debugging")
+	if err != nil {
+		t.Fatalf("EnsureOverride failed: %v", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	embedded, _, _ := NewRegistry("").Source(TemplateInteractive)
+	if string(data) != embedded {
+		t.Error("override should start from the base template")
+	}
+
+	// 既存の上書きは変更しない
+	if err := os.WriteFile(path, []byte("custom"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.EnsureOverride("interactive.debugging"); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "custom" {
+		t.Error("existing override should be preserved")
+	}
+}
+
+func TestModelFamily(t *testing.T) {
+	tests := map[string]string{
+		"qwen2.5-coder:14b":       "qwen",
+		"codellama:7b":            "codellama",
+		"llama3.1":                "llama",
+		"library/deepseek-coder":  "deepseek",
+		"Mistral-7B-Instruct":     "mistral",
+		"some-unknown-model:q4_0": "generic",
+	}
+	for model, expected := range tests {
+		if family := ModelFamily(model); family != expected {
+			t.Errorf("ModelFamily(%q) = %q, want %q", model, family, expected)
+		}
+	}
+}
@@ -0,0 +1,399 @@
+// Package recall はセッション中のコマンド・ツールの実行結果を記憶し、
+// 後からキーワード（設定で有効なら埋め込みの類似度も）で検索できるようにする
+// 合計サイズが上限を超えたら古い結果から要約して残す
+package recall
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+const (
+	// 既定の合計サイズの上限
+	defaultMaxBytes = 1 << 20
+	// 埋め込みを生成する本文の長さ
+	embedPrefixBytes = 2000
+	// 埋め込みだけで一致とみなすコサイン類似度の下限
+	minSimilarity = 0.3
+	// 検索結果に含める抜粋の最大行数
+	excerptMaxLines = 40
+)
+
+// Embedder は複数テキストの埋め込みベクトルをまとめて生成できるプロバイダー
+type Embedder interface {
+	EmbedBatch(ctx context.Context, model string, texts []string) ([][]float64, error)
+}
+
+// Entry は記憶した実行結果の1件
+type Entry struct {
+	ID         int       `json:"id"`
+	Source     string    `json:"source"` // bash, job, test 等の実行元
+	Label      string    `json:"label"`  // コマンド・ファイルパス等
+	Content    string    `json:"content"`
+	Success    bool      `json:"success"`
+	Summarized bool      `json:"summarized"` // 上限を超えたため要約に置き換えた
+	Bytes      int       `json:"bytes"`      // 元の出力のサイズ
+	Timestamp  time.Time `json:"timestamp"`
+
+	vector []float64
+}
+
+// Match は検索結果の1件
+type Match struct {
+	Entry   Entry
+	Score   float64
+	Excerpt string // 検索語を含む行とその前後（一致する行がなければ先頭）
+}
+
+// Options は Store の設定
+type Options struct {
+	MaxBytes int      // 記憶する出力の合計サイズ（0なら 1MB）
+	Embedder Embedder // nil ならキーワードのみで検索する
+	Model    string   // 埋め込みモデル
+}
+
+// Store は1セッション分の実行結果の記憶
+type Store struct {
+	mu      sync.Mutex
+	opts    Options
+	entries []*Entry // 古い順
+	nextID  int
+	size    int
+}
+
+// NewStore は空の記憶を作成
+func NewStore(opts Options) *Store {
+	if opts.MaxBytes <= 0 {
+		opts.MaxBytes = defaultMaxBytes
+	}
+	return &Store{opts: opts, nextID: 1}
+}
+
+// Add は実行結果を記憶してIDを返す（空の出力は記憶せず0を返す）
+func (s *Store) Add(entry Entry) int {
+	if strings.TrimSpace(entry.Content) == "" {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry.ID = s.nextID
+	s.nextID++
+	entry.Bytes = len(entry.Content)
+	entry.Summarized = false
+	entry.vector = nil
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	s.entries = append(s.entries, &entry)
+	s.size += len(entry.Content)
+	s.compact()
+	return entry.ID
+}
+
+// compact は合計サイズが上限以下になるまで古い結果から要約し、それでも超えれば古い結果を捨てる
+func (s *Store) compact() {
+	for _, entry := range s.entries {
+		if s.size <= s.opts.MaxBytes {
+			return
+		}
+		if entry.Summarized {
+			continue
+		}
+		summary := Summarize(entry.Content)
+		s.size += len(summary) - len(entry.Content)
+		entry.Content = summary
+		entry.Summarized = true
+		entry.vector = nil
+	}
+	for s.size > s.opts.MaxBytes && len(s.entries) > 1 {
+		s.size -= len(s.entries[0].Content)
+		s.entries = s.entries[1:]
+	}
+}
+
+// Len は記憶している結果の数
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// Get はIDの結果を返す
+func (s *Store) Get(id int) (Entry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, entry := range s.entries {
+		if entry.ID == id {
+			return *entry, true
+		}
+	}
+	return Entry{}, false
+}
+
+// Recent は新しい順に最大 limit 件の結果を返す
+func (s *Store) Recent(limit int) []Entry {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var recent []Entry
+	for i := len(s.entries) - 1; i >= 0 && (limit <= 0 || len(recent) < limit); i-- {
+		recent = append(recent, *s.entries[i])
+	}
+	return recent
+}
+
+var idQueryPattern = regexp.MustCompile(`^#?(\d+)$`)
+
+// Search は query に関係する結果を関連の高い順に最大 limit 件返す
+// "#3" はIDで1件、空・"recent"・"last" は新しい順に返す。埋め込みの生成に失敗したらキーワードのみで検索する
+func (s *Store) Search(ctx context.Context, query string, limit int) []Match {
+	query = strings.TrimSpace(query)
+	if match := idQueryPattern.FindStringSubmatch(query); match != nil {
+		id, _ := strconv.Atoi(match[1])
+		if entry, ok := s.Get(id); ok {
+			return []Match{{Entry: entry, Score: 1, Excerpt: excerpt(entry.Content, nil)}}
+		}
+		return nil
+	}
+	switch strings.ToLower(query) {
+	case "", "recent", "last", "latest":
+		var matches []Match
+		for _, entry := range s.Recent(limit) {
+			matches = append(matches, Match{Entry: entry, Excerpt: excerpt(entry.Content, nil)})
+		}
+		return matches
+	}
+
+	terms := Terms(query)
+	similarities := s.similarities(ctx, query)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	lexical := s.lexicalScores(terms)
+	maxLexical := 0.0
+	for _, score := range lexical {
+		maxLexical = math.Max(maxLexical, score)
+	}
+
+	var matches []Match
+	for i, entry := range s.entries {
+		score := 0.0
+		if maxLexical > 0 {
+			score = lexical[i] / maxLexical
+		}
+		if similarities != nil {
+			similarity := similarities[entry.ID]
+			if score == 0 && similarity < minSimilarity {
+				continue
+			}
+			score = (score + similarity) / 2
+		}
+		if score <= 0 {
+			continue
+		}
+		matches = append(matches, Match{Entry: *entry, Score: score, Excerpt: excerpt(entry.Content, terms)})
+	}
+	// 同じ関連度なら新しい結果を優先する
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].Score != matches[j].Score {
+			return matches[i].Score > matches[j].Score
+		}
+		return matches[i].Entry.ID > matches[j].Entry.ID
+	})
+	if limit > 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// lexicalScores は各結果の検索語の出現による関連度（出現する結果が少ない語ほど重い）
+// ラベル（コマンド等）に含まれる語は本文の2倍に数える
+func (s *Store) lexicalScores(terms []string) []float64 {
+	scores := make([]float64, len(s.entries))
+	if len(terms) == 0 {
+		return scores
+	}
+	texts := make([]string, len(s.entries))
+	labels := make([]string, len(s.entries))
+	for i, entry := range s.entries {
+		texts[i] = strings.ToLower(entry.Content)
+		labels[i] = strings.ToLower(entry.Source + " " + entry.Label)
+	}
+	for _, term := range terms {
+		df := 0
+		for i := range s.entries {
+			if strings.Contains(texts[i], term) || strings.Contains(labels[i], term) {
+				df++
+			}
+		}
+		if df == 0 {
+			continue
+		}
+		idf := math.Log(1 + float64(len(s.entries))/float64(df))
+		for i := range s.entries {
+			tf := strings.Count(texts[i], term) + 2*strings.Count(labels[i], term)
+			if tf > 0 {
+				scores[i] += idf * (1 + math.Log(float64(tf)))
+			}
+		}
+	}
+	return scores
+}
+
+// similarities は各結果（ID別）とクエリの埋め込みのコサイン類似度（埋め込みを使わない・失敗したら nil）
+func (s *Store) similarities(ctx context.Context, query string) map[int]float64 {
+	if s.opts.Embedder == nil {
+		return nil
+	}
+
+	s.mu.Lock()
+	var pending []*Entry
+	var texts []string
+	for _, entry := range s.entries {
+		if entry.vector == nil {
+			pending = append(pending, entry)
+			texts = append(texts, embedText(entry))
+		}
+	}
+	s.mu.Unlock()
+
+	vectors, err := s.opts.Embedder.EmbedBatch(ctx, s.opts.Model, append(texts, query))
+	if err != nil || len(vectors) != len(texts)+1 {
+		return nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, entry := range pending {
+		entry.vector = vectors[i]
+	}
+	queryVector := vectors[len(vectors)-1]
+	similarities := make(map[int]float64, len(s.entries))
+	for _, entry := range s.entries {
+		if entry.vector != nil {
+			similarities[entry.ID] = llm.CosineSimilarity(queryVector, entry.vector)
+		}
+	}
+	return similarities
+}
+
+// embedText は埋め込みを生成するテキスト（ラベルと本文の先頭）
+func embedText(entry *Entry) string {
+	content := entry.Content
+	if len(content) > embedPrefixBytes {
+		content = content[:embedPrefixBytes]
+	}
+	return entry.Label + "\n" + content
+}
+
+// Terms はクエリを小文字の検索語に分ける（1文字の語と重複は除く）
+func Terms(query string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, word := range strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '.' && r != '/' && r != '-'
+	}) {
+		word = strings.Trim(word, ".-/")
+		if len([]rune(word)) < 2 || seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+	return terms
+}
+
+// excerpt は検索語を含む行と前後1行を返す（一致する行がなければ先頭から）
+func excerpt(content string, terms []string) string {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) <= excerptMaxLines {
+		return strings.Join(lines, "\n")
+	}
+
+	keep := make([]bool, len(lines))
+	found := false
+	for i, line := range lines {
+		lower := strings.ToLower(line)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				for j := i - 1; j <= i+1; j++ {
+					if j >= 0 && j < len(lines) {
+						keep[j] = true
+					}
+				}
+				found = true
+				break
+			}
+		}
+	}
+	if !found {
+		return strings.Join(lines[:excerptMaxLines], "\n") + fmt.Sprintf("\n...(%d lines omitted)", len(lines)-excerptMaxLines)
+	}
+
+	var sb strings.Builder
+	kept, skipped := 0, 0
+	for i, line := range lines {
+		if !keep[i] || kept >= excerptMaxLines {
+			skipped++
+			continue
+		}
+		if skipped > 0 {
+			sb.WriteString(fmt.Sprintf("...(%d lines)\n", skipped))
+			skipped = 0
+		}
+		sb.WriteString(line + "\n")
+		kept++
+	}
+	if skipped > 0 {
+		sb.WriteString(fmt.Sprintf("...(%d lines)\n", skipped))
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// Format は検索結果をモデルに返す形式にする
+func Format(matches []Match) string {
+	if len(matches) == 0 {
+		return "No earlier results match."
+	}
+	var sb strings.Builder
+	for i, match := range matches {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		sb.WriteString(match.Entry.Heading())
+		sb.WriteString("\n```\n")
+		sb.WriteString(match.Excerpt)
+		sb.WriteString("\n```")
+	}
+	return sb.String()
+}
+
+// Heading は結果の見出し（ID・実行元・ラベル・成否・時刻・サイズ）
+func (e Entry) Heading() string {
+	status := "ok"
+	if !e.Success {
+		status = "failed"
+	}
+	heading := fmt.Sprintf("#%d [%s] %s (%s, %s, %s", e.ID, e.Source, e.Label, status, e.Timestamp.Format("15:04:05"), formatBytes(e.Bytes))
+	if e.Summarized {
+		heading += ", summarized"
+	}
+	return heading + ")"
+}
+
+func formatBytes(n int) string {
+	if n < 1024 {
+		return fmt.Sprintf("%d B", n)
+	}
+	return fmt.Sprintf("%.1f KB", float64(n)/1024)
+}
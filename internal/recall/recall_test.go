@@ -0,0 +1,116 @@
+package recall
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestSearchKeywords(t *testing.T) {
+	store := NewStore(Options{})
+	store.Add(Entry{Source: "bash", Label: "go build ./...", Content: "ok", Success: true})
+	testID := store.Add(Entry{Source: "bash", Label: "go test ./internal/parser", Content: "--- FAIL: TestParseEmpty (0.00s)\n    parser_test.go:42: expected error for empty input, got nil\nFAIL"})
+	store.Add(Entry{Source: "bash", Label: "ls", Content: "main.go\nparser.go"})
+	if id := store.Add(Entry{Source: "bash", Label: "true", Content: "  \n"}); id != 0 || store.Len() != 3 {
+		t.Errorf("空の出力は記憶しないはず: %d %d", id, store.Len())
+	}
+
+	matches := store.Search(context.Background(), "what did the test failure say", 3)
+	if len(matches) == 0 || matches[0].Entry.ID != testID || !strings.Contains(matches[0].Excerpt, "parser_test.go:42") {
+		t.Fatalf("テストの失敗を最初に返すはず: %+v", matches)
+	}
+	if !strings.Contains(Format(matches[:1]), "#2 [bash] go test ./internal/parser (failed") {
+		t.Errorf("見出しにIDとコマンドを含むはず: %s", Format(matches[:1]))
+	}
+	if matches := store.Search(context.Background(), "#3", 3); len(matches) != 1 || matches[0].Entry.Label != "ls" {
+		t.Errorf("IDで取り出せるはず: %+v", matches)
+	}
+	if matches := store.Search(context.Background(), "recent", 2); len(matches) != 2 || matches[0].Entry.Label != "ls" {
+		t.Errorf("新しい順に返すはず: %+v", matches)
+	}
+	if matches := store.Search(context.Background(), "kubernetes", 3); len(matches) != 0 {
+		t.Errorf("関係のない結果は返さないはず: %+v", matches)
+	}
+}
+
+// fakeEmbedder は語の有無を次元とするベクトルを返す
+type fakeEmbedder struct {
+	fail bool
+}
+
+func (f fakeEmbedder) EmbedBatch(ctx context.Context, model string, texts []string) ([][]float64, error) {
+	if f.fail {
+		return nil, errors.New("embedding server down")
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		lower := strings.ToLower(text)
+		vectors[i] = []float64{0.1, 0, 0}
+		if strings.Contains(lower, "test") || strings.Contains(lower, "assert") {
+			vectors[i][1] = 1
+		}
+		if strings.Contains(lower, "build") || strings.Contains(lower, "compile") {
+			vectors[i][2] = 1
+		}
+	}
+	return vectors, nil
+}
+
+func TestSearchSemantic(t *testing.T) {
+	for _, fail := range []bool{false, true} {
+		store := NewStore(Options{Embedder: fakeEmbedder{fail: fail}, Model: "embed"})
+		store.Add(Entry{Source: "bash", Label: "go vet ./...", Content: "compile error: undefined: Foo"})
+		store.Add(Entry{Source: "bash", Label: "npm run check", Content: "assert.equal failed in login"})
+
+		matches := store.Search(context.Background(), "build problems", 3)
+		if fail {
+			// 埋め込みが使えなければキーワードのみ（"build" は出力にない）
+			if len(matches) != 0 {
+				t.Errorf("埋め込みの失敗時はキーワードのみで検索するはず: %+v", matches)
+			}
+			continue
+		}
+		if len(matches) != 1 || matches[0].Entry.Label != "go vet ./..." {
+			t.Errorf("キーワードが一致しなくても意味の近い結果を返すはず: %+v", matches)
+		}
+	}
+}
+
+func TestStoreSummarizesOldEntries(t *testing.T) {
+	store := NewStore(Options{MaxBytes: 8 * 1024})
+	var output strings.Builder
+	for i := 1; i <= 200; i++ {
+		if i == 120 {
+			output.WriteString("main.go:12:3: undefined: handler\n")
+			continue
+		}
+		fmt.Fprintf(&output, "compiling package %d of 200\n", i)
+	}
+	first := store.Add(Entry{Source: "bash", Label: "make build", Content: output.String()})
+	second := store.Add(Entry{Source: "bash", Label: "make build", Content: output.String()})
+
+	old, _ := store.Get(first)
+	recent, _ := store.Get(second)
+	if !old.Summarized || recent.Summarized {
+		t.Fatalf("古い結果から要約するはず: %v %v", old.Summarized, recent.Summarized)
+	}
+	if !strings.Contains(old.Content, "120: main.go:12:3: undefined: handler") || !strings.Contains(old.Content, "compiling package 200 of 200") || old.Bytes != len(output.String()) {
+		t.Errorf("要約にはエラーの行と末尾を残すはず:\n%s", old.Content)
+	}
+	if matches := store.Search(context.Background(), "undefined handler", 1); len(matches) != 1 || matches[0].Entry.ID != second {
+		t.Errorf("同じ関連度なら新しい結果を返すはず: %+v", matches)
+	}
+
+	// 要約しても収まらなければ古い結果を捨てる
+	for i := 0; i < 20; i++ {
+		store.Add(Entry{Source: "bash", Label: "make build", Content: output.String()})
+	}
+	if _, ok := store.Get(first); ok {
+		t.Error("最も古い結果は捨てるはず")
+	}
+	if store.size > 8*1024 {
+		t.Errorf("合計サイズを上限以下に保つはず: %d", store.size)
+	}
+}
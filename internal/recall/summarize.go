@@ -0,0 +1,69 @@
+package recall
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// これ以下の出力は要約しない
+	summarizeMinBytes = 2048
+	// 要約に残す先頭・末尾の行数
+	summaryHeadLines = 5
+	summaryTailLines = 10
+	// 要約に残すエラー等の行の最大数
+	summaryMaxNotable = 20
+	// 要約の1行の最大長
+	summaryMaxLineBytes = 200
+)
+
+// notableLinePattern はエラー・失敗・警告等、要約しても残す行
+var notableLinePattern = regexp.MustCompile(`(?i)\b(error|errors|fail|failed|failure|panic|fatal|exception|traceback|warning|expected|got|want|undefined|cannot|denied|not found)\b|^\s*(---|===|FAIL|ok)\s|:\d+:\d*:?\s`)
+
+// Summarize は長い出力を先頭・末尾とエラー等の行（行番号付き）に縮める
+func Summarize(content string) string {
+	if len(content) <= summarizeMinBytes {
+		return content
+	}
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if len(lines) <= summaryHeadLines+summaryTailLines {
+		// 行数が少なく1行が長い出力は各行を切り詰める
+		for i := range lines {
+			lines[i] = clipLine(lines[i])
+		}
+		return strings.Join(lines, "\n")
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("[summary of %d lines, %d bytes]\n", len(lines), len(content)))
+	for _, line := range lines[:summaryHeadLines] {
+		sb.WriteString(clipLine(line) + "\n")
+	}
+
+	tailStart := len(lines) - summaryTailLines
+	notable := 0
+	for i := summaryHeadLines; i < tailStart && notable < summaryMaxNotable; i++ {
+		if notableLinePattern.MatchString(lines[i]) {
+			sb.WriteString(fmt.Sprintf("%d: %s\n", i+1, clipLine(lines[i])))
+			notable++
+		}
+	}
+	sb.WriteString("...\n")
+	for _, line := range lines[tailStart:] {
+		sb.WriteString(clipLine(line) + "\n")
+	}
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+func clipLine(line string) string {
+	if len(line) <= summaryMaxLineBytes {
+		return line
+	}
+	cut := summaryMaxLineBytes
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + "…"
+}
@@ -0,0 +1,310 @@
+// Package redact はLLMに送る内容・監査ログから機密情報（APIキー、秘密鍵、パスワード、.env の値等）を伏せる。
+// 一致した値は [REDACTED:種類] に置き換え、伏せた件数を種類毎に返す。
+// 伏せる範囲は強さ（off, low, standard, strict）で選び、強いほど誤検出も増える。
+package redact
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// 伏せ字化の強さ
+const (
+	LevelOff      = "off"      // 伏せない
+	LevelLow      = "low"      // 形式の決まったトークン・秘密鍵のみ（誤検出ほぼなし）
+	LevelStandard = "standard" // 加えて秘密らしい名前への代入・.env の値・URL の認証情報
+	LevelStrict   = "strict"   // 加えて引用符なしの代入・JWT・ランダムな長い文字列
+)
+
+// ValidLevels は設定できる強さ（弱い順）
+func ValidLevels() []string {
+	return []string{LevelOff, LevelLow, LevelStandard, LevelStrict}
+}
+
+// levelRank は強さの順位（off が 0）
+func levelRank(level string) int {
+	for rank, candidate := range ValidLevels() {
+		if candidate == level {
+			return rank
+		}
+	}
+	return -1
+}
+
+// Placeholder は種類 kind の値を置き換える文字列
+func Placeholder(kind string) string {
+	return "[REDACTED:" + kind + "]"
+}
+
+// rule は伏せる値のパターン1つ
+type rule struct {
+	kind    string
+	rank    int            // 適用する最低の強さ
+	pattern *regexp.Regexp // 一致した範囲
+	value   int            // 伏せるサブマッチの番号（0 は一致全体、キー名は残す）
+	accept  func(value string) bool
+}
+
+// secretName は値を伏せる代入先の名前の末尾（max_tokens・tokenizer 等は対象外）
+const secretName = `(?:password|passwd|secret|token|api[_-]?key|apikey|access[_-]?key|private[_-]?key|credentials?)(?:[_-]?key)?`
+
+// builtinRules は組み込みのパターン（強さの弱い順）
+var builtinRules = []rule{
+	{kind: "private_key", rank: 1, pattern: regexp.MustCompile(`-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----(?s:.*?)(?:-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----|\z)`)},
+	{kind: "aws_access_key", rank: 1, pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{kind: "github_token", rank: 1, pattern: regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{kind: "slack_token", rank: 1, pattern: regexp.MustCompile(`\bxox[baprs]-[A-Za-z0-9-]{10,}`)},
+	{kind: "api_key", rank: 1, pattern: regexp.MustCompile(`\b(?:sk-(?:ant-|proj-)?[A-Za-z0-9_-]{20,}|AIza[0-9A-Za-z_-]{35}|[rs]k_live_[0-9A-Za-z]{16,})`)},
+
+	{kind: "credential", rank: 2, value: 1, accept: notPlaceholder,
+		pattern: regexp.MustCompile(`(?i)\b[\w.-]*` + secretName + `["']?\s*[:=]{1,2}\s*["']([^"'\s]{8,})["']`)},
+	{kind: "env", rank: 2, value: 1, accept: notPlaceholder,
+		pattern: regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?[A-Z][A-Z0-9_]*(?:PASSWORD|PASSWD|SECRET|TOKEN|API_?KEY|ACCESS_KEY|PRIVATE_KEY|CREDENTIALS?)(?:_KEY)?[ \t]*=[ \t]*["']?([^\s"'#]{4,})`)},
+	{kind: "url_credentials", rank: 2, value: 1, accept: notPlaceholder,
+		pattern: regexp.MustCompile(`\b[a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]+:([^\s@/]{3,})@`)},
+	{kind: "bearer_token", rank: 2, value: 1,
+		pattern: regexp.MustCompile(`(?i)(?:\bauthorization["']?\s*[:=]\s*["']?(?:basic|bearer|token)\s+|\bbearer\s+)([A-Za-z0-9._~+/-]{16,}=*)`)},
+
+	{kind: "credential", rank: 3, value: 1, accept: notPlaceholder,
+		pattern: regexp.MustCompile(`(?i)\b[\w.-]*` + secretName + `\s*[:=]\s*([^\s"'(){}\[\],;]{8,})`)},
+	{kind: "jwt", rank: 3, pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`)},
+	{kind: "high_entropy", rank: 3, accept: looksRandom, pattern: regexp.MustCompile(`[A-Za-z0-9+/_-]{32,}={0,2}`)},
+}
+
+// envAssignment は .env ファイルの1行の代入（値は名前によらず伏せる）
+var envAssignment = regexp.MustCompile(`(?m)^[ \t]*(?:export[ \t]+)?[A-Za-z_][A-Za-z0-9_.]*[ \t]*=[ \t]*(\S.*?)[ \t]*$`)
+
+// Result は伏せた件数
+type Result struct {
+	Count int            `json:"count"`
+	Kinds map[string]int `json:"kinds,omitempty"` // 種類毎の件数
+}
+
+// Add は other の件数を加える
+func (r *Result) Add(other Result) {
+	if other.Count == 0 {
+		return
+	}
+	if r.Kinds == nil {
+		r.Kinds = make(map[string]int)
+	}
+	r.Count += other.Count
+	for kind, count := range other.Kinds {
+		r.Kinds[kind] += count
+	}
+}
+
+// String は「件数 (種類 件数, ...)」の形式
+func (r Result) String() string {
+	kinds := make([]string, 0, len(r.Kinds))
+	for kind := range r.Kinds {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	parts := make([]string, 0, len(kinds))
+	for _, kind := range kinds {
+		parts = append(parts, fmt.Sprintf("%s %d", kind, r.Kinds[kind]))
+	}
+	if len(parts) == 0 {
+		return fmt.Sprint(r.Count)
+	}
+	return fmt.Sprintf("%d (%s)", r.Count, strings.Join(parts, ", "))
+}
+
+// Redactor は強さと追加パターンに従って機密情報を伏せる（nil は何も伏せない）
+type Redactor struct {
+	level string
+	rules []rule
+}
+
+// New は強さ level と追加の正規表現 patterns（サブマッチがあれば1つ目だけを伏せる）から作成
+func New(level string, patterns []string) (*Redactor, error) {
+	rank := levelRank(level)
+	if rank < 0 {
+		return nil, fmt.Errorf("unknown redaction level %q (valid: %s)", level, strings.Join(ValidLevels(), ", "))
+	}
+	r := &Redactor{level: level}
+	if rank == 0 {
+		return r, nil
+	}
+	for _, builtin := range builtinRules {
+		if builtin.rank <= rank {
+			r.rules = append(r.rules, builtin)
+		}
+	}
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redaction pattern %q: %v", pattern, err)
+		}
+		custom := rule{kind: "custom", pattern: compiled}
+		if compiled.NumSubexp() > 0 {
+			custom.value = 1
+		}
+		r.rules = append(r.rules, custom)
+	}
+	return r, nil
+}
+
+// Level は伏せ字化の強さ
+func (r *Redactor) Level() string {
+	if r == nil {
+		return LevelOff
+	}
+	return r.level
+}
+
+// Redact は text の機密情報を伏せ、伏せた件数を返す
+func (r *Redactor) Redact(text string) (string, Result) {
+	var result Result
+	if r == nil || text == "" {
+		return text, result
+	}
+	for _, rl := range r.rules {
+		text = rl.apply(text, &result)
+	}
+	return text, result
+}
+
+// RedactFile はファイルの内容を伏せる（.env 等の環境変数ファイルは名前によらず全ての値を伏せる）
+func (r *Redactor) RedactFile(name, content string) (string, Result) {
+	var result Result
+	if r == nil || levelRank(r.level) < levelRank(LevelStandard) {
+		return r.Redact(content)
+	}
+	if IsEnvFile(name) {
+		content = rule{kind: "env", pattern: envAssignment, value: 1}.apply(content, &result)
+	}
+	content, rest := r.Redact(content)
+	result.Add(rest)
+	return content, result
+}
+
+// apply は text 中の一致を伏せて件数を result に加える
+func (rl rule) apply(text string, result *Result) string {
+	matches := rl.pattern.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+	var b strings.Builder
+	last, count := 0, 0
+	for _, match := range matches {
+		start, end := match[2*rl.value], match[2*rl.value+1]
+		if start < 0 {
+			continue
+		}
+		value := text[start:end]
+		// 伏せ済みの値・例示の値はそのまま（同じ内容を何度伏せても変わらない）
+		if strings.Contains(value, "REDACTED") || (rl.accept != nil && !rl.accept(value)) {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(Placeholder(rl.kind))
+		last = end
+		count++
+	}
+	if count == 0 {
+		return text
+	}
+	b.WriteString(text[last:])
+	result.Add(Result{Count: count, Kinds: map[string]int{rl.kind: count}})
+	return b.String()
+}
+
+// IsEnvFile は環境変数ファイル（.env、.env.local、production.env 等、.env.example 等の雛形を除く）か
+func IsEnvFile(name string) bool {
+	base := strings.ToLower(filepath.Base(name))
+	for _, suffix := range []string{".example", ".sample", ".template", ".dist"} {
+		if strings.HasSuffix(base, suffix) {
+			return false
+		}
+	}
+	return base == ".env" || strings.HasPrefix(base, ".env.") || strings.HasSuffix(base, ".env")
+}
+
+// notPlaceholder は値が例示（changeme、${VAR}、<your-key> 等）でないか
+func notPlaceholder(value string) bool {
+	if strings.ContainsAny(value[:1], "$<{%") {
+		return false
+	}
+	lower := strings.ToLower(value)
+	for _, word := range []string{"example", "changeme", "your", "dummy", "placeholder", "xxxx", "****"} {
+		if strings.Contains(lower, word) {
+			return false
+		}
+	}
+	return strings.Trim(value, value[:1]) != ""
+}
+
+// looksRandom は大文字・小文字・数字を含み、文字の偏りが小さい（エントロピーが高い）か
+// 16進数のみの文字列（コミットハッシュ・チェックサム）は対象外
+func looksRandom(value string) bool {
+	var upper, lower, digit bool
+	counts := make(map[rune]int)
+	for _, c := range value {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+		counts[c]++
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / float64(len(value))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 4.0
+}
+
+var (
+	mu              sync.RWMutex
+	defaultRedactor = mustNew(LevelStandard)
+)
+
+func mustNew(level string) *Redactor {
+	r, err := New(level, nil)
+	if err != nil {
+		panic(err)
+	}
+	return r
+}
+
+// SetDefault は LLM への送信・監査ログで使う Redactor を設定し、それまでのものを返す（起動時に設定を読み込んだ後に呼ぶ）
+func SetDefault(r *Redactor) *Redactor {
+	mu.Lock()
+	defer mu.Unlock()
+	previous := defaultRedactor
+	defaultRedactor = r
+	return previous
+}
+
+// Default は設定された Redactor（未設定なら standard）
+func Default() *Redactor {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultRedactor
+}
+
+// String は既定の Redactor で text を伏せる
+func String(text string) string {
+	redacted, _ := Default().Redact(text)
+	return redacted
+}
+
+// File は既定の Redactor でファイルの内容を伏せる
+func File(name, content string) string {
+	redacted, _ := Default().RedactFile(name, content)
+	return redacted
+}
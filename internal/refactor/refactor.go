@@ -0,0 +1,339 @@
+package refactor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/events"
+	"github.com/glkt/vyb-code/internal/journal"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/markdown"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+const (
+	// DefaultMaxFiles はモデルに渡す候補ファイルの既定の上限
+	DefaultMaxFiles = 15
+
+	maxCandidateBytes = 64 * 1024  // 候補にするファイル1件あたりの最大サイズ
+	maxPromptBytes    = 160 * 1024 // プロンプトに添付するファイルの合計サイズ
+)
+
+var (
+	// editPattern はモデルが返す全文置換（<FILE path="...">内容</FILE>）
+	editPattern = regexp.MustCompile(`(?s)<FILE path="([^"]+)">\n?(.*?)\n?</FILE>`)
+
+	// 指示から検索語を取り出すパターン（引用・バッククォート・識別子）
+	quotedPattern     = regexp.MustCompile("[`\"']([^`\"'\\s]{2,})[`\"']")
+	identifierPattern = regexp.MustCompile(`[A-Za-z_][A-Za-z0-9_.]{2,}`)
+
+	// 候補探索で読み飛ばすディレクトリ
+	skippedDirs = map[string]bool{".git": true, "vendor": true, "node_modules": true, "dist": true, "build": true, "target": true}
+)
+
+// 識別子らしくない指示の単語
+var stopWords = map[string]bool{
+	"rename": true, "move": true, "extract": true, "inline": true, "replace": true, "change": true,
+	"the": true, "and": true, "into": true, "from": true, "with": true, "all": true, "for": true,
+	"function": true, "method": true, "type": true, "field": true, "variable": true, "package": true,
+	"file": true, "files": true, "use": true, "make": true, "add": true, "remove": true, "split": true,
+}
+
+// Edit は1ファイル分の書き換え（全文）
+type Edit struct {
+	Path    string `json:"path"` // プロジェクト相対
+	Before  string `json:"-"`
+	Content string `json:"-"`
+	Created bool   `json:"created"`
+}
+
+// Diff は書き換えの unified diff
+func (e *Edit) Diff() string {
+	oldName := "a/" + filepath.ToSlash(e.Path)
+	if e.Created {
+		oldName = "/dev/null"
+	}
+	return diff.Unified(oldName, "b/"+filepath.ToSlash(e.Path), e.Before, e.Content, 3)
+}
+
+// Plan はモデルが計画した複数ファイルの書き換え
+type Plan struct {
+	Instruction string  `json:"instruction"`
+	Summary     string  `json:"summary"` // <FILE> 以外の説明
+	Edits       []*Edit `json:"edits"`
+}
+
+// Outcome はトランザクションとして適用した結果
+type Outcome struct {
+	TransactionID string        `json:"transaction_id,omitempty"`
+	Applied       bool          `json:"applied"`
+	RolledBack    bool          `json:"rolled_back"`
+	Validation    *tasks.Result `json:"validation,omitempty"` // 最後に実行したビルド・テスト
+}
+
+// Refactorer は指示から複数ファイルの書き換えを計画し、トランザクションとして適用する
+type Refactorer struct {
+	Provider   llm.Provider
+	Model      string
+	Language   string
+	Memory     string // プロジェクトメモリ（VYB.md）
+	Registry   *prompts.Registry
+	ProjectDir string
+	MaxFiles   int
+}
+
+// Candidates は指示に含まれる識別子を参照しているファイルを、出現数の多い順に返す
+func (r *Refactorer) Candidates(instruction string) ([]string, error) {
+	terms := SearchTerms(instruction)
+	if len(terms) == 0 {
+		return nil, fmt.Errorf("指示から対象の識別子を特定できません。--files で対象ファイルを指定してください")
+	}
+
+	type scored struct {
+		path  string
+		score int
+	}
+	var found []scored
+	err := filepath.Walk(r.ProjectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return nil
+		}
+		if info.IsDir() {
+			if path != r.ProjectDir && (skippedDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if info.Size() > maxCandidateBytes {
+			return nil
+		}
+		content, err := os.ReadFile(path)
+		if err != nil || bytes.IndexByte(content, 0) >= 0 {
+			return nil
+		}
+		score := 0
+		for _, term := range terms {
+			score += bytes.Count(content, []byte(term))
+		}
+		if score > 0 {
+			rel, _ := filepath.Rel(r.ProjectDir, path)
+			found = append(found, scored{rel, score})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(found) == 0 {
+		return nil, fmt.Errorf("%s を参照しているファイルがありません", strings.Join(terms, ", "))
+	}
+
+	sort.SliceStable(found, func(i, j int) bool {
+		if found[i].score != found[j].score {
+			return found[i].score > found[j].score
+		}
+		return found[i].path < found[j].path
+	})
+	limit := r.MaxFiles
+	if limit <= 0 {
+		limit = DefaultMaxFiles
+	}
+	var files []string
+	for i, candidate := range found {
+		if i >= limit {
+			break
+		}
+		files = append(files, candidate.path)
+	}
+	return files, nil
+}
+
+// SearchTerms は指示から検索する識別子を取り出す（引用されたものを優先）
+func SearchTerms(instruction string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		term = strings.Trim(term, ".")
+		if len(term) < 3 || seen[term] || stopWords[strings.ToLower(term)] {
+			return
+		}
+		seen[term] = true
+		terms = append(terms, term)
+	}
+
+	for _, match := range quotedPattern.FindAllStringSubmatch(instruction, -1) {
+		add(match[1])
+	}
+	if len(terms) > 0 {
+		return terms
+	}
+	// 引用がなければ大文字・アンダースコア・ドットを含む識別子らしい単語
+	for _, word := range identifierPattern.FindAllString(instruction, -1) {
+		if strings.ToLower(word) != word || strings.ContainsAny(word, "_.") {
+			add(word)
+		}
+	}
+	if len(terms) > 0 {
+		return terms
+	}
+	for _, word := range identifierPattern.FindAllString(instruction, -1) {
+		if len(word) >= 4 {
+			add(word)
+		}
+	}
+	return terms
+}
+
+// Plan は候補ファイルを添付して書き換えを計画させる（ファイルは変更しない）
+func (r *Refactorer) Plan(ctx context.Context, instruction string, files []string) (*Plan, error) {
+	var attached strings.Builder
+	total := 0
+	for _, file := range files {
+		content, err := os.ReadFile(filepath.Join(r.ProjectDir, file))
+		if err != nil {
+			return nil, fmt.Errorf("ファイル読み込みエラー: %w", err)
+		}
+		if total+len(content) > maxPromptBytes {
+			break
+		}
+		total += len(content)
+		fmt.Fprintf(&attached, "<FILE path=\"%s\">\n%s\n</FILE>\n\n", filepath.ToSlash(file), content)
+	}
+
+	registry := r.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	prompt, err := registry.Render(prompts.TemplateRefactor, prompts.Data{
+		SessionType: "refactor",
+		Language:    r.Language,
+		ModelFamily: prompts.ModelFamily(r.Model),
+		Memory:      r.Memory,
+		Context:     attached.String(),
+		Input:       instruction,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	temperature := 0.1
+	resp, err := r.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       r.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("リファクタリング計画エラー: %w", err)
+	}
+
+	plan, err := r.ParsePlan(instruction, resp.Message.Content)
+	if err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ParsePlan はモデルの回答から書き換えを取り出し、ワークスペース外・変更なしのファイルを除く
+func (r *Refactorer) ParsePlan(instruction, answer string) (*Plan, error) {
+	constraints := security.NewDefaultConstraints(r.ProjectDir)
+	plan := &Plan{Instruction: instruction, Summary: strings.TrimSpace(editPattern.ReplaceAllString(answer, ""))}
+
+	seen := make(map[string]bool)
+	for _, match := range editPattern.FindAllStringSubmatch(answer, -1) {
+		rel := filepath.Clean(strings.TrimSpace(match[1]))
+		if filepath.IsAbs(rel) {
+			rel, _ = filepath.Rel(r.ProjectDir, rel)
+		}
+		abs, err := constraints.ResolvePath(filepath.Join(r.ProjectDir, rel))
+		if err != nil {
+			return nil, fmt.Errorf("ワークスペース外のファイルは変更できません: %s", match[1])
+		}
+		if seen[rel] {
+			continue
+		}
+		seen[rel] = true
+
+		content := markdown.StripCodeFence(match[2])
+		if !strings.HasSuffix(content, "\n") {
+			content += "\n"
+		}
+		edit := &Edit{Path: rel, Content: content}
+		if before, err := os.ReadFile(abs); err == nil {
+			edit.Before = string(before)
+		} else {
+			edit.Created = true
+		}
+		if edit.Before == edit.Content && !edit.Created {
+			continue
+		}
+		plan.Edits = append(plan.Edits, edit)
+	}
+	if len(plan.Edits) == 0 {
+		return nil, fmt.Errorf("応答に変更するファイルがありません")
+	}
+	return plan, nil
+}
+
+// Apply は計画をトランザクションとして書き込み、ビルド（と必要ならテスト）で検証する
+// 書き込み・検証のいずれかが失敗した場合はすべて元に戻す（runner が nil なら検証しない）
+func (r *Refactorer) Apply(ctx context.Context, plan *Plan, journalDir string, runner *tasks.Runner, runTests bool) (*Outcome, error) {
+	tx := journal.Begin(journalDir, plan.Instruction)
+	outcome := &Outcome{}
+
+	for _, edit := range plan.Edits {
+		if err := tx.Write(filepath.Join(r.ProjectDir, edit.Path), []byte(edit.Content)); err != nil {
+			if rollbackErr := tx.Rollback(); rollbackErr != nil {
+				return nil, fmt.Errorf("%v（ロールバック失敗: %v）", err, rollbackErr)
+			}
+			outcome.RolledBack = true
+			return outcome, err
+		}
+	}
+
+	if runner != nil {
+		kinds := []tasks.Kind{tasks.KindBuild}
+		if runTests {
+			kinds = append(kinds, tasks.KindTest)
+		}
+		for _, kind := range kinds {
+			if runner.System().Command(kind) == "" {
+				continue
+			}
+			result, err := runner.Run(ctx, kind)
+			if err != nil {
+				if rollbackErr := tx.Rollback(); rollbackErr != nil {
+					return nil, fmt.Errorf("%v（ロールバック失敗: %v）", err, rollbackErr)
+				}
+				outcome.RolledBack = true
+				return outcome, err
+			}
+			outcome.Validation = result
+			if !result.Success {
+				if err := tx.Rollback(); err != nil {
+					return nil, fmt.Errorf("ロールバック失敗: %w", err)
+				}
+				outcome.RolledBack = true
+				return outcome, nil
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	for _, edit := range plan.Edits {
+		events.Publish(events.EditApplied, "", map[string]interface{}{"path": edit.Path, "source": "refactor"})
+	}
+	outcome.Applied = true
+	outcome.TransactionID = tx.ID
+	return outcome, nil
+}
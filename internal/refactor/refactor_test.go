@@ -0,0 +1,198 @@
+package refactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/journal"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+// cannedProvider は決まった回答を返し、受け取ったプロンプトを記録するテスト用プロバイダー
+type cannedProvider struct {
+	answer string
+	prompt string
+}
+
+func (p *cannedProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.prompt = req.Messages[len(req.Messages)-1].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}}, nil
+}
+
+func (p *cannedProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *cannedProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *cannedProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+const (
+	greetSource = "package demo\n\nfunc Hello() string { return \"hi\" }\n"
+	mainSource  = "package demo\n\nfunc Run() string { return Hello() }\n"
+)
+
+func newTestProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod":    "module example.com/demo\n\ngo 1.20\n",
+		"greet.go":  greetSource,
+		"run.go":    mainSource,
+		"README.md": "Call Hello to greet.\n",
+		"other.go":  "package demo\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestSearchTerms(t *testing.T) {
+	tests := []struct {
+		instruction string
+		want        []string
+	}{
+		{"rename `Hello` to `Greet`", []string{"Hello", "Greet"}},
+		{"rename Hello to Greet", []string{"Hello", "Greet"}},
+		{"move config.Load into the loader", []string{"config.Load"}},
+		{"rename parse_args to parse_flags", []string{"parse_args", "parse_flags"}},
+		{"extract the retry logic", []string{"retry", "logic"}},
+	}
+	for _, tt := range tests {
+		if got := SearchTerms(tt.instruction); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%q: %v, want %v", tt.instruction, got, tt.want)
+		}
+	}
+}
+
+func TestCandidates(t *testing.T) {
+	dir := newTestProject(t)
+	r := &Refactorer{ProjectDir: dir}
+
+	files, err := r.Candidates("rename Hello to Greet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(files, []string{"README.md", "greet.go", "run.go"}) {
+		t.Errorf("Hello を参照するファイルのみのはず: %v", files)
+	}
+
+	r.MaxFiles = 1
+	if files, _ := r.Candidates("rename Hello to Greet"); len(files) != 1 {
+		t.Errorf("MaxFiles で絞り込むはず: %v", files)
+	}
+	if _, err := r.Candidates("rename Missing to Other"); err == nil {
+		t.Error("参照がなければエラーのはず")
+	}
+}
+
+func TestParsePlan(t *testing.T) {
+	dir := newTestProject(t)
+	r := &Refactorer{ProjectDir: dir}
+
+	answer := "Renamed the function.\n\n<FILE path=\"greet.go\">\n```go\npackage demo\n\nfunc Greet() string { return \"hi\" }\n```\n</FILE>\n" +
+		"<FILE path=\"other.go\">\npackage demo\n</FILE>\n" +
+		"<FILE path=\"sub/new.go\">\npackage sub\n</FILE>"
+	plan, err := r.ParsePlan("rename", answer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plan.Summary != "Renamed the function." {
+		t.Errorf("summary: %q", plan.Summary)
+	}
+	if len(plan.Edits) != 2 {
+		t.Fatalf("変更のないファイルは除くはず: %+v", plan.Edits)
+	}
+	if edit := plan.Edits[0]; edit.Before != greetSource || strings.Contains(edit.Content, "```") || !strings.Contains(edit.Diff(), "+func Greet()") {
+		t.Errorf("greet.go: %+v", edit)
+	}
+	if edit := plan.Edits[1]; !edit.Created || !strings.HasPrefix(edit.Diff(), "--- /dev/null") {
+		t.Errorf("新規ファイル: %+v", edit)
+	}
+
+	if _, err := r.ParsePlan("rename", "<FILE path=\"../escape.go\">x</FILE>"); err == nil {
+		t.Error("ワークスペース外はエラーのはず")
+	}
+	if _, err := r.ParsePlan("rename", "nothing to change"); err == nil {
+		t.Error("変更がなければエラーのはず")
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	dir := newTestProject(t)
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	provider := &cannedProvider{answer: "<FILE path=\"greet.go\">\npackage demo\n\nfunc Greet() string { return \"hi\" }\n</FILE>\n" +
+		"<FILE path=\"run.go\">\npackage demo\n\nfunc Run() string { return Greet() }\n</FILE>"}
+	r := &Refactorer{Provider: provider, Model: "test", Language: "en", Registry: prompts.DefaultRegistry(), ProjectDir: dir}
+
+	plan, err := r.Plan(context.Background(), "rename Hello to Greet", []string{"greet.go", "run.go"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(provider.prompt, "rename Hello to Greet") || !strings.Contains(provider.prompt, mainSource) {
+		t.Errorf("指示とファイルを添付するはず: %s", provider.prompt)
+	}
+
+	runner, err := tasks.NewRunner(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcome, err := r.Apply(context.Background(), plan, journalDir, runner, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outcome.Applied || outcome.RolledBack || outcome.Validation == nil || !outcome.Validation.Success {
+		t.Fatalf("ビルドが通れば確定するはず: %+v", outcome)
+	}
+
+	tx, err := journal.Load(journalDir, "latest")
+	if err != nil || tx.ID != outcome.TransactionID || len(tx.Entries) != 2 {
+		t.Fatalf("ジャーナルに記録するはず: %+v, %v", tx, err)
+	}
+	if err := tx.Undo(false); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "run.go")); string(data) != mainSource {
+		t.Errorf("取り消しで元に戻るはず: %s", data)
+	}
+}
+
+func TestApplyRollsBackOnBuildFailure(t *testing.T) {
+	dir := newTestProject(t)
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	r := &Refactorer{ProjectDir: dir}
+
+	// 呼び出し側を更新し忘れた書き換え
+	plan, err := r.ParsePlan("rename Hello to Greet", "<FILE path=\"greet.go\">\npackage demo\n\nfunc Greet() string { return \"hi\" }\n</FILE>\n"+
+		"<FILE path=\"extra.go\">\npackage demo\n</FILE>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	runner, err := tasks.NewRunner(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcome, err := r.Apply(context.Background(), plan, journalDir, runner, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if outcome.Applied || !outcome.RolledBack || outcome.Validation == nil || outcome.Validation.Success {
+		t.Fatalf("ビルド失敗でロールバックするはず: %+v", outcome)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "greet.go")); string(data) != greetSource {
+		t.Errorf("元の内容に戻るはず: %s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "extra.go")); !os.IsNotExist(err) {
+		t.Error("新規ファイルは削除されるはず")
+	}
+	if transactions, _ := journal.List(journalDir); len(transactions) != 0 {
+		t.Errorf("ロールバックしたものは保存しないはず: %+v", transactions)
+	}
+}
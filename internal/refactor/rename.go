@@ -0,0 +1,521 @@
+package refactor
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxMentions は名前変更後に残った文字列の出現として報告する上限
+const maxMentions = 200
+
+// Mention は名前変更の対象にならなかった旧名の出現（コメント・文字列・設定ファイル等、手作業で確認する）
+type Mention struct {
+	Path string `json:"path"` // プロジェクト相対
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+// Rename は型検査で求めた参照の書き換えと、残った旧名の出現
+type Rename struct {
+	Symbol     string    `json:"symbol"`
+	OldName    string    `json:"old_name"`
+	NewName    string    `json:"new_name"`
+	Declared   string    `json:"declared"` // 宣言の位置（path:line）
+	References int       `json:"references"`
+	Plan       *Plan     `json:"plan"`
+	Mentions   []Mention `json:"mentions,omitempty"`
+	Skipped    []string  `json:"skipped,omitempty"` // ビルド条件で除外したため型検査していないファイル
+	Warnings   []string  `json:"warnings,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"` // Mentions が上限で打ち切られた
+}
+
+// goPackage は型検査したモジュール内のパッケージ（ディレクトリ単位、外部テストパッケージは別）
+type goPackage struct {
+	path  string // インポートパス（外部テストパッケージは _test 付き）
+	types *types.Package
+	info  *types.Info
+}
+
+// goWorkspace はモジュール内の Go パッケージをソースから型検査する
+type goWorkspace struct {
+	fset       *token.FileSet
+	root       string            // go.mod のあるディレクトリ
+	module     string            // モジュールパス
+	dirs       map[string]string // インポートパス → ディレクトリ
+	checked    map[string]*goPackage
+	checking   map[string]bool
+	packages   []*goPackage
+	skipped    []string
+	fallback   types.ImporterFrom
+	typeErrors int
+}
+
+// PlanRename は symbol（Name、Type.Method、Type.Field）の宣言と全ての参照を型検査で求め、newName への書き換えを計画する
+// 同じ名前の宣言が複数のパッケージにあれば file（宣言のあるファイル）で絞り込む。ファイルは変更しない
+func (r *Refactorer) PlanRename(symbol, newName, file string) (*Rename, error) {
+	if !token.IsIdentifier(newName) {
+		return nil, fmt.Errorf("%q は Go の識別子として使えません", newName)
+	}
+	typeName, member, _ := strings.Cut(symbol, ".")
+	if member == "" {
+		typeName, member = "", symbol
+	}
+	if !token.IsIdentifier(member) || (typeName != "" && !token.IsIdentifier(typeName)) {
+		return nil, fmt.Errorf("シンボルは Name または Type.Member の形式で指定してください: %s", symbol)
+	}
+	if member == newName {
+		return nil, fmt.Errorf("新しい名前が現在の名前と同じです: %s", newName)
+	}
+
+	ws, err := loadGoWorkspace(r.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	if file != "" && !filepath.IsAbs(file) {
+		file = filepath.Join(r.ProjectDir, file)
+	}
+	target, err := ws.lookup(typeName, member, file)
+	if err != nil {
+		return nil, err
+	}
+	if err := ws.checkConflict(target, newName); err != nil {
+		return nil, err
+	}
+
+	rename := &Rename{
+		Symbol:   symbol,
+		OldName:  member,
+		NewName:  newName,
+		Declared: ws.position(target.Pos()),
+		Skipped:  ws.skipped,
+	}
+	if ws.typeErrors > 0 {
+		rename.Warnings = append(rename.Warnings, fmt.Sprintf("%d type error(s) while loading the module; references in code that does not type-check may be missed", ws.typeErrors))
+	}
+	if target.Exported() && !token.IsExported(newName) {
+		rename.Warnings = append(rename.Warnings, fmt.Sprintf("%s becomes unexported; uses outside package %s will no longer compile", newName, target.Pkg().Name()))
+	}
+
+	edits, references, err := r.renameEdits(ws, target, newName)
+	if err != nil {
+		return nil, err
+	}
+	rename.References = references
+	rename.Plan = &Plan{
+		Instruction: fmt.Sprintf("rename %s to %s", symbol, newName),
+		Summary:     fmt.Sprintf("%d reference(s) to %s in %d file(s)", references, symbol, len(edits)),
+		Edits:       edits,
+	}
+	rename.Mentions, rename.Truncated = r.remainingMentions(member, edits)
+	return rename, nil
+}
+
+// loadGoWorkspace は projectDir を含むモジュールの全パッケージを読み込み、型検査する
+func loadGoWorkspace(projectDir string) (*goWorkspace, error) {
+	root, module, err := findModule(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	ws := &goWorkspace{
+		fset:     token.NewFileSet(),
+		root:     root,
+		module:   module,
+		dirs:     make(map[string]string),
+		checked:  make(map[string]*goPackage),
+		checking: make(map[string]bool),
+	}
+	ws.fallback, _ = importer.ForCompiler(ws.fset, "source", nil).(types.ImporterFrom)
+
+	err = filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil || !info.IsDir() {
+			return nil
+		}
+		name := info.Name()
+		if path != root {
+			if skippedDirs[name] || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "testdata" {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil {
+				return filepath.SkipDir // 入れ子のモジュール
+			}
+		}
+		rel, _ := filepath.Rel(root, path)
+		importPath := module
+		if rel != "." {
+			importPath = module + "/" + filepath.ToSlash(rel)
+		}
+		ws.dirs[importPath] = path
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(ws.dirs))
+	for path := range ws.dirs {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		if _, err := ws.load(path); err != nil {
+			return nil, err
+		}
+	}
+	if len(ws.packages) == 0 {
+		return nil, fmt.Errorf("%s に Go のパッケージがありません", root)
+	}
+	return ws, nil
+}
+
+// findModule は dir から上に go.mod を探し、モジュールのルートとパスを返す
+func findModule(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for current := dir; ; current = filepath.Dir(current) {
+		data, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if fields := strings.Fields(line); len(fields) >= 2 && fields[0] == "module" {
+					return current, strings.Trim(fields[1], `"`), nil
+				}
+			}
+			return "", "", fmt.Errorf("%s にモジュールパスがありません", filepath.Join(current, "go.mod"))
+		}
+		if filepath.Dir(current) == current {
+			return "", "", fmt.Errorf("名前の変更は Go モジュール内でのみ使えます（%s から上に go.mod がありません）", dir)
+		}
+	}
+}
+
+// Import はモジュール内のパッケージをソースから、それ以外を GOROOT・モジュールキャッシュのソースから読み込む
+func (ws *goWorkspace) Import(path string) (*types.Package, error) {
+	return ws.ImportFrom(path, ws.root, 0)
+}
+
+// ImportFrom は types.ImporterFrom の実装
+func (ws *goWorkspace) ImportFrom(path, dir string, mode types.ImportMode) (*types.Package, error) {
+	if _, ok := ws.dirs[path]; ok {
+		pkg, err := ws.load(path)
+		if err != nil {
+			return nil, err
+		}
+		if pkg == nil {
+			return nil, fmt.Errorf("%s に Go のファイルがありません", path)
+		}
+		return pkg.types, nil
+	}
+	if ws.fallback == nil {
+		return nil, fmt.Errorf("%s を読み込めません", path)
+	}
+	return ws.fallback.ImportFrom(path, dir, mode)
+}
+
+// load はインポートパスのパッケージ（テストファイルを含む）を型検査する（Go のファイルがなければ nil）
+// 外部テストパッケージ（package xxx_test）は別のパッケージとして検査し、参照を探す対象に加える
+func (ws *goWorkspace) load(importPath string) (*goPackage, error) {
+	if pkg, ok := ws.checked[importPath]; ok {
+		return pkg, nil
+	}
+	if ws.checking[importPath] {
+		return nil, fmt.Errorf("インポートが循環しています: %s", importPath)
+	}
+	ws.checking[importPath] = true
+	defer delete(ws.checking, importPath)
+
+	dir := ws.dirs[importPath]
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	byPackage := make(map[string][]*ast.File)
+	var order []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if match, err := build.Default.MatchFile(dir, name); err != nil || !match {
+			rel, _ := filepath.Rel(ws.root, filepath.Join(dir, name))
+			ws.skipped = append(ws.skipped, filepath.ToSlash(rel))
+			continue
+		}
+		file, err := parser.ParseFile(ws.fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, fmt.Errorf("構文エラーのため名前を変更できません: %w", err)
+		}
+		if _, ok := byPackage[file.Name.Name]; !ok {
+			order = append(order, file.Name.Name)
+		}
+		byPackage[file.Name.Name] = append(byPackage[file.Name.Name], file)
+	}
+
+	var main *goPackage
+	var external []*ast.File
+	for _, name := range order {
+		if strings.HasSuffix(name, "_test") && len(order) > 1 {
+			external = append(external, byPackage[name]...)
+			continue
+		}
+		main = ws.check(importPath, byPackage[name])
+	}
+	ws.checked[importPath] = main
+	if len(external) > 0 {
+		ws.check(importPath+"_test", external)
+	}
+	return main, nil
+}
+
+// check は1つのパッケージを型検査する（型エラーがあっても分かる範囲で参照を記録する）
+func (ws *goWorkspace) check(importPath string, files []*ast.File) *goPackage {
+	pkg := &goPackage{
+		path: importPath,
+		info: &types.Info{
+			Defs: make(map[*ast.Ident]types.Object),
+			Uses: make(map[*ast.Ident]types.Object),
+		},
+	}
+	config := &types.Config{
+		Importer: ws,
+		Error:    func(error) { ws.typeErrors++ },
+	}
+	pkg.types, _ = config.Check(importPath, ws.fset, files, pkg.info)
+	ws.packages = append(ws.packages, pkg)
+	return pkg
+}
+
+// lookup は名前を変更する宣言を探す（typeName が空ならパッケージレベルの宣言）
+func (ws *goWorkspace) lookup(typeName, member, file string) (types.Object, error) {
+	var found []types.Object
+	for _, pkg := range ws.packages {
+		if pkg.types == nil || strings.HasSuffix(pkg.path, "_test") {
+			continue
+		}
+		var object types.Object
+		if typeName == "" {
+			object = pkg.types.Scope().Lookup(member)
+		} else if named, ok := pkg.types.Scope().Lookup(typeName).(*types.TypeName); ok {
+			object, _, _ = types.LookupFieldOrMethod(named.Type(), true, pkg.types, member)
+			if object != nil && object.Pkg() != pkg.types {
+				object = nil // 埋め込んだ外部の型のメンバーは変更できない
+			}
+		}
+		if object == nil {
+			continue
+		}
+		if file != "" && filepath.Clean(ws.fset.Position(object.Pos()).Filename) != filepath.Clean(file) {
+			continue
+		}
+		found = append(found, object)
+	}
+
+	symbol := member
+	if typeName != "" {
+		symbol = typeName + "." + member
+	}
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("%s の宣言が見つかりません", symbol)
+	case 1:
+		return found[0], nil
+	}
+	var candidates []string
+	for _, object := range found {
+		candidates = append(candidates, ws.position(object.Pos()))
+	}
+	return nil, fmt.Errorf("%s の宣言が複数あります。--file で宣言のあるファイルを指定してください: %s", symbol, strings.Join(candidates, ", "))
+}
+
+// checkConflict は新しい名前が同じスコープ・型の既存の宣言と衝突しないか確かめる
+func (ws *goWorkspace) checkConflict(target types.Object, newName string) error {
+	pkg := target.Pkg()
+	switch object := target.(type) {
+	case *types.Func:
+		if recv := object.Type().(*types.Signature).Recv(); recv != nil {
+			if existing, _, _ := types.LookupFieldOrMethod(recv.Type(), true, pkg, newName); existing != nil {
+				return fmt.Errorf("%s には既に %s があります（%s）", recv.Type(), newName, ws.position(existing.Pos()))
+			}
+			return nil
+		}
+	case *types.Var:
+		if object.IsField() {
+			for _, p := range ws.packages {
+				for _, tv := range p.info.Defs {
+					named, ok := tv.(*types.TypeName)
+					if !ok || named.Pkg() != pkg {
+						continue
+					}
+					if st, ok := named.Type().Underlying().(*types.Struct); ok && structHasField(st, object) {
+						if existing, _, _ := types.LookupFieldOrMethod(named.Type(), true, pkg, newName); existing != nil {
+							return fmt.Errorf("%s には既に %s があります（%s）", named.Name(), newName, ws.position(existing.Pos()))
+						}
+					}
+				}
+			}
+			return nil
+		}
+	}
+	if existing := pkg.Scope().Lookup(newName); existing != nil {
+		return fmt.Errorf("パッケージ %s には既に %s があります（%s）", pkg.Name(), newName, ws.position(existing.Pos()))
+	}
+	return nil
+}
+
+// structHasField は構造体が field を直接持つか
+func structHasField(st *types.Struct, field *types.Var) bool {
+	for i := 0; i < st.NumFields(); i++ {
+		if st.Field(i) == field {
+			return true
+		}
+	}
+	return false
+}
+
+// position は宣言の位置をモジュールのルートからの path:line で返す
+func (ws *goWorkspace) position(pos token.Pos) string {
+	position := ws.fset.Position(pos)
+	rel, err := filepath.Rel(ws.root, position.Filename)
+	if err != nil {
+		rel = position.Filename
+	}
+	return fmt.Sprintf("%s:%d", filepath.ToSlash(rel), position.Line)
+}
+
+// renameEdits は target を指す識別子（宣言・参照、型なら埋め込みフィールドの参照も）を newName に置き換えた書き換えを作る
+func (r *Refactorer) renameEdits(ws *goWorkspace, target types.Object, newName string) ([]*Edit, int, error) {
+	refers := func(object types.Object) bool {
+		if object == target {
+			return true
+		}
+		// 埋め込んだ型の名前はフィールド名でもある（s.Type のセレクター）
+		if field, ok := object.(*types.Var); ok && field.Embedded() {
+			fieldType := field.Type()
+			if pointer, ok := fieldType.(*types.Pointer); ok {
+				fieldType = pointer.Elem()
+			}
+			if named, ok := fieldType.(*types.Named); ok && named.Obj() == target {
+				return true
+			}
+		}
+		return false
+	}
+
+	offsets := make(map[string]map[int]bool) // ファイル → 置き換える識別子の先頭
+	references := 0
+	for _, pkg := range ws.packages {
+		for _, idents := range []map[*ast.Ident]types.Object{pkg.info.Defs, pkg.info.Uses} {
+			for ident, object := range idents {
+				if object == nil || ident.Name != target.Name() || !refers(object) {
+					continue
+				}
+				position := ws.fset.Position(ident.Pos())
+				if offsets[position.Filename] == nil {
+					offsets[position.Filename] = make(map[int]bool)
+				}
+				if !offsets[position.Filename][position.Offset] {
+					offsets[position.Filename][position.Offset] = true
+					references++
+				}
+			}
+		}
+	}
+
+	files := make([]string, 0, len(offsets))
+	for file := range offsets {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var edits []*Edit
+	for _, file := range files {
+		before, err := os.ReadFile(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("ファイル読み込みエラー: %w", err)
+		}
+		positions := make([]int, 0, len(offsets[file]))
+		for offset := range offsets[file] {
+			positions = append(positions, offset)
+		}
+		sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+		content := before
+		for _, offset := range positions {
+			if !bytes.HasPrefix(content[offset:], []byte(target.Name())) {
+				return nil, 0, fmt.Errorf("%s が読み込み後に変更されました", file)
+			}
+			content = append(append(append([]byte{}, content[:offset]...), newName...), content[offset+len(target.Name()):]...)
+		}
+		rel, err := filepath.Rel(r.ProjectDir, file)
+		if err != nil {
+			rel = file
+		}
+		edits = append(edits, &Edit{Path: rel, Before: string(before), Content: string(content)})
+	}
+	if len(edits) == 0 {
+		return nil, 0, fmt.Errorf("%s への参照が見つかりません", target.Name())
+	}
+	return edits, references, nil
+}
+
+// remainingMentions は書き換え後も旧名が単語として残る箇所（コメント・文字列・設定・ドキュメント等）を探す
+func (r *Refactorer) remainingMentions(name string, edits []*Edit) ([]Mention, bool) {
+	pattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	renamed := make(map[string]string)
+	for _, edit := range edits {
+		renamed[filepath.Clean(filepath.Join(r.ProjectDir, edit.Path))] = edit.Content
+	}
+
+	var mentions []Mention
+	truncated := false
+	_ = filepath.Walk(r.ProjectDir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || truncated {
+			return nil
+		}
+		if info.IsDir() {
+			if path != r.ProjectDir && (skippedDirs[info.Name()] || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		content, ok := renamed[filepath.Clean(path)]
+		if !ok {
+			if info.Size() > maxCandidateBytes {
+				return nil
+			}
+			data, err := os.ReadFile(path)
+			if err != nil || bytes.IndexByte(data, 0) >= 0 {
+				return nil
+			}
+			content = string(data)
+		}
+		if !pattern.MatchString(content) {
+			return nil
+		}
+		rel, _ := filepath.Rel(r.ProjectDir, path)
+		scanner := bufio.NewScanner(strings.NewReader(content))
+		scanner.Buffer(make([]byte, 0, 64*1024), maxCandidateBytes)
+		for line := 1; scanner.Scan(); line++ {
+			if !pattern.MatchString(scanner.Text()) {
+				continue
+			}
+			if len(mentions) >= maxMentions {
+				truncated = true
+				break
+			}
+			mentions = append(mentions, Mention{Path: filepath.ToSlash(rel), Line: line, Text: strings.TrimSpace(scanner.Text())})
+		}
+		return nil
+	})
+	return mentions, truncated
+}
@@ -0,0 +1,125 @@
+package refactor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+// newRenameProject は2つのパッケージ・外部テストパッケージ・ドキュメントを持つモジュールを作成する
+func newRenameProject(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.20\n",
+		"greet/greet.go": "package greet\n\n// Greeter は挨拶する\ntype Greeter struct{ Name string }\n\n" +
+			"// Hello は挨拶を返す\nfunc (g Greeter) Hello() string { return Hello() + \", \" + g.Name }\n\n" +
+			"// Hello は既定の挨拶\nfunc Hello() string { return \"hello\" }\n",
+		"greet/greet_test.go": "package greet_test\n\nimport (\n\t\"testing\"\n\n\t\"example.com/app/greet\"\n)\n\n" +
+			"func TestHello(t *testing.T) {\n\tif greet.Hello() != \"hello\" {\n\t\tt.Fatal(\"Hello\")\n\t}\n}\n",
+		"main.go": "package main\n\nimport \"example.com/app/greet\"\n\ntype app struct{ greet.Greeter }\n\n" +
+			"func main() {\n\ta := app{greet.Greeter{Name: \"vyb\"}}\n\tprintln(greet.Hello(), a.Greeter.Hello(), a.Hello())\n}\n",
+		"README.md":   "Call greet.Hello for the default greeting.\n",
+		"config.yaml": "greeting: Hello\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestPlanRenameFunction(t *testing.T) {
+	dir := newRenameProject(t)
+	r := &Refactorer{ProjectDir: dir}
+
+	rename, err := r.PlanRename("Hello", "Greet", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rename.Declared != "greet/greet.go:10" || rename.References != 4 || len(rename.Plan.Edits) != 3 {
+		t.Fatalf("宣言と3ファイルの参照を見つけるはず: %s, %d references, %d edits", rename.Declared, rename.References, len(rename.Plan.Edits))
+	}
+	contents := make(map[string]string)
+	for _, edit := range rename.Plan.Edits {
+		contents[filepath.ToSlash(edit.Path)] = edit.Content
+	}
+	if !strings.Contains(contents["greet/greet.go"], "func Greet() string") || !strings.Contains(contents["greet/greet.go"], "func (g Greeter) Hello() string { return Greet()") {
+		t.Errorf("同名のメソッドは変更しないはず:\n%s", contents["greet/greet.go"])
+	}
+	if !strings.Contains(contents["main.go"], "greet.Greet(), a.Greeter.Hello(), a.Hello()") || !strings.Contains(contents["greet/greet_test.go"], "greet.Greet() != \"hello\"") {
+		t.Errorf("他のパッケージ・外部テストの参照も変更するはず:\n%s\n%s", contents["main.go"], contents["greet/greet_test.go"])
+	}
+
+	// コメント・文字列・設定・ドキュメントに残った旧名は確認用に報告する
+	mentioned := make(map[string]bool)
+	for _, mention := range rename.Mentions {
+		mentioned[mention.Path] = true
+	}
+	for _, path := range []string{"greet/greet.go", "greet/greet_test.go", "README.md", "config.yaml"} {
+		if !mentioned[path] {
+			t.Errorf("%s に残った Hello が報告されていません: %+v", path, rename.Mentions)
+		}
+	}
+
+	journalDir := filepath.Join(t.TempDir(), "journal")
+	runner, err := tasks.NewRunner(dir, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	outcome, err := r.Apply(context.Background(), rename.Plan, journalDir, runner, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !outcome.Applied || outcome.RolledBack {
+		t.Fatalf("全ての参照を変更すればビルドが通るはず: %+v", outcome.Validation)
+	}
+}
+
+func TestPlanRenameMembersAndConflicts(t *testing.T) {
+	dir := newRenameProject(t)
+	r := &Refactorer{ProjectDir: dir}
+
+	rename, err := r.PlanRename("Greeter.Hello", "Hi", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rename.References != 3 {
+		t.Errorf("メソッドの宣言と2つの呼び出し（埋め込み経由を含む）のはず: %d", rename.References)
+	}
+
+	// 型の名前は埋め込みフィールドの名前でもある
+	rename, err = r.PlanRename("Greeter", "Welcomer", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, edit := range rename.Plan.Edits {
+		if edit.Path == "main.go" && (!strings.Contains(edit.Content, "struct{ greet.Welcomer }") || !strings.Contains(edit.Content, "a.Welcomer.Hello()")) {
+			t.Errorf("埋め込みフィールドの参照も変更するはず:\n%s", edit.Content)
+		}
+	}
+
+	for _, tt := range []struct{ symbol, newName string }{
+		{"Hello", "Greeter"},      // パッケージ内の既存の宣言
+		{"Greeter.Hello", "Name"}, // 既存のフィールド
+		{"Missing", "Other"},
+		{"Hello", "func"},
+		{"Hello", "Hello"},
+	} {
+		if _, err := r.PlanRename(tt.symbol, tt.newName, ""); err == nil {
+			t.Errorf("%s → %s はエラーになるはず", tt.symbol, tt.newName)
+		}
+	}
+	if _, err := r.PlanRename("Hello", "Greet", "main.go"); err == nil {
+		t.Error("宣言のないファイルを指定したらエラーになるはず")
+	}
+}
@@ -0,0 +1,158 @@
+package release
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+)
+
+// Commit は変更履歴に載せるコミット1つ
+type Commit struct {
+	Hash        string `json:"hash"`
+	Subject     string `json:"subject"`
+	Body        string `json:"body,omitempty"`
+	Type        string `json:"type"` // feat, fix 等（Conventional Commits でなければ空）
+	Scope       string `json:"scope,omitempty"`
+	Description string `json:"description"` // 種類・スコープを除いた要約
+	Breaking    bool   `json:"breaking"`
+	PRs         []int  `json:"prs,omitempty"`
+}
+
+// ShortHash は短縮したコミットハッシュを返す
+func (c *Commit) ShortHash() string {
+	if len(c.Hash) > 7 {
+		return c.Hash[:7]
+	}
+	return c.Hash
+}
+
+var (
+	// type(scope)!: description
+	conventionalPattern = regexp.MustCompile(`^([a-zA-Z]+)(?:\(([^)]*)\))?(!)?:\s*(.+)$`)
+	// 要約の末尾の (#123)（スカッシュマージ）
+	squashPattern = regexp.MustCompile(`\s*\(#(\d+)\)\s*$`)
+	// GitHub のマージコミット
+	mergePattern = regexp.MustCompile(`^Merge pull request #(\d+)`)
+)
+
+// 区切り文字（git log --format 用）
+const (
+	fieldSeparator  = "\x1f"
+	recordSeparator = "\x1e"
+)
+
+// ParseCommit は要約と本文から Conventional Commits の種類・スコープ・破壊的変更・PR番号を取り出す
+func ParseCommit(hash, subject, body string) *Commit {
+	commit := &Commit{Hash: hash, Subject: subject, Body: strings.TrimSpace(body), Description: subject}
+	if match := squashPattern.FindStringSubmatch(commit.Description); match != nil {
+		commit.addPR(match[1])
+		commit.Description = squashPattern.ReplaceAllString(commit.Description, "")
+	}
+	if match := conventionalPattern.FindStringSubmatch(commit.Description); match != nil {
+		commit.Type = strings.ToLower(match[1])
+		commit.Scope = match[2]
+		commit.Breaking = match[3] == "!"
+		commit.Description = match[4]
+	}
+	for _, line := range strings.Split(commit.Body, "\n") {
+		if strings.HasPrefix(line, "BREAKING CHANGE:") || strings.HasPrefix(line, "BREAKING-CHANGE:") {
+			commit.Breaking = true
+		}
+	}
+	return commit
+}
+
+// addPR は重複しないようにPR番号を加える
+func (c *Commit) addPR(number string) {
+	n, err := strconv.Atoi(number)
+	if err != nil {
+		return
+	}
+	for _, existing := range c.PRs {
+		if existing == n {
+			return
+		}
+	}
+	c.PRs = append(c.PRs, n)
+}
+
+// Log は範囲（v1.2.0..HEAD 等）のマージ以外のコミットを新しい順に返す
+// マージコミット（Merge pull request #N）で取り込まれたコミットにはそのPR番号を付ける
+func Log(ctx context.Context, dir, revisionRange string) ([]*Commit, error) {
+	output, err := gitexec.Run(ctx, dir, "log", "--no-merges", "--format=%H"+fieldSeparator+"%s"+fieldSeparator+"%b"+recordSeparator, revisionRange)
+	if err != nil {
+		return nil, err
+	}
+	var commits []*Commit
+	byHash := make(map[string]*Commit)
+	for _, record := range strings.Split(output, recordSeparator) {
+		fields := strings.SplitN(strings.TrimLeft(record, "\n"), fieldSeparator, 3)
+		if len(fields) < 3 {
+			continue
+		}
+		commit := ParseCommit(fields[0], fields[1], fields[2])
+		commits = append(commits, commit)
+		byHash[commit.Hash] = commit
+	}
+
+	merges, err := gitexec.Run(ctx, dir, "log", "--merges", "--format=%H %s", revisionRange)
+	if err != nil {
+		return nil, err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(merges), "\n") {
+		hash, subject, _ := strings.Cut(line, " ")
+		match := mergePattern.FindStringSubmatch(subject)
+		if match == nil {
+			continue
+		}
+		merged, err := gitexec.Run(ctx, dir, "rev-list", hash+"^1.."+hash+"^2")
+		if err != nil {
+			continue
+		}
+		for _, mergedHash := range strings.Fields(merged) {
+			if commit, ok := byHash[mergedHash]; ok {
+				commit.addPR(match[1])
+			}
+		}
+	}
+	return commits, nil
+}
+
+// ResolveRange は指定の範囲を git の範囲にする
+// 空なら直近のタグから HEAD まで（タグがなければ全履歴）、".." を含まなければ指定から HEAD まで
+func ResolveRange(ctx context.Context, dir, spec string) string {
+	if strings.Contains(spec, "..") {
+		return spec
+	}
+	if spec != "" {
+		return spec + "..HEAD"
+	}
+	tag, err := gitexec.Run(ctx, dir, "describe", "--tags", "--abbrev=0")
+	if err != nil {
+		return "HEAD"
+	}
+	return strings.TrimSpace(tag) + "..HEAD"
+}
+
+// RangeEnd は範囲の終わりの参照を返す（v1.2.0..v1.3.0 なら v1.3.0）
+func RangeEnd(revisionRange string) string {
+	if index := strings.LastIndex(revisionRange, ".."); index >= 0 {
+		revisionRange = revisionRange[index+2:]
+	}
+	if revisionRange == "" {
+		return "HEAD"
+	}
+	return revisionRange
+}
+
+// CommitDate は参照のコミット日（YYYY-MM-DD）を返す
+func CommitDate(ctx context.Context, dir, ref string) string {
+	date, err := gitexec.Run(ctx, dir, "log", "-1", "--format=%cs", ref)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(date)
+}
@@ -0,0 +1,220 @@
+package release
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Unreleased はタグの付いていない変更の見出し
+const Unreleased = "Unreleased"
+
+// 変更の分類（表示する順）
+var groups = []struct {
+	title string
+	types []string
+}{
+	{"Features", []string{"feat"}},
+	{"Bug Fixes", []string{"fix"}},
+	{"Performance", []string{"perf"}},
+	{"Documentation", []string{"docs"}},
+	{"Chores", []string{"chore", "refactor", "test", "build", "ci", "style", "revert"}},
+}
+
+// otherTitle は Conventional Commits でないコミットの分類
+const otherTitle = "Other Changes"
+
+// Group は種類毎にまとめたコミット
+type Group struct {
+	Title   string    `json:"title"`
+	Commits []*Commit `json:"commits"`
+}
+
+// Notes は1つのリリースの変更履歴
+type Notes struct {
+	Version    string    `json:"version"`
+	Date       string    `json:"date,omitempty"`
+	Range      string    `json:"range"`
+	Highlights string    `json:"highlights,omitempty"` // モデルがまとめた利用者向けの変更（Markdown の箇条書き）
+	Breaking   []*Commit `json:"breaking,omitempty"`
+	Groups     []*Group  `json:"groups"`
+	Repo       string    `json:"repo,omitempty"` // GitHub の "owner/repo"（PR・コミットのリンクに使う）
+}
+
+// NewNotes はコミットを種類毎にまとめる（release のスコープのコミットは除く）
+func NewNotes(version, date, revisionRange, repo string, commits []*Commit) *Notes {
+	notes := &Notes{Version: version, Date: date, Range: revisionRange, Repo: repo}
+	byTitle := make(map[string]*Group)
+	for _, commit := range commits {
+		if commit.Scope == "release" {
+			continue
+		}
+		if commit.Breaking {
+			notes.Breaking = append(notes.Breaking, commit)
+		}
+		title := groupTitle(commit.Type)
+		group, ok := byTitle[title]
+		if !ok {
+			group = &Group{Title: title}
+			byTitle[title] = group
+		}
+		group.Commits = append(group.Commits, commit)
+	}
+	for _, g := range groups {
+		if group, ok := byTitle[g.title]; ok {
+			notes.Groups = append(notes.Groups, group)
+		}
+	}
+	if group, ok := byTitle[otherTitle]; ok {
+		notes.Groups = append(notes.Groups, group)
+	}
+	return notes
+}
+
+// groupTitle はコミットの種類の分類名を返す
+func groupTitle(commitType string) string {
+	for _, g := range groups {
+		for _, t := range g.types {
+			if t == commitType {
+				return g.title
+			}
+		}
+	}
+	return otherTitle
+}
+
+// Empty は載せるコミットがないか
+func (n *Notes) Empty() bool {
+	return len(n.Groups) == 0
+}
+
+// Heading は変更履歴の見出しの行を返す（Keep a Changelog の形式）
+func (n *Notes) Heading() string {
+	if n.Date == "" {
+		return fmt.Sprintf("## [%s]", n.Version)
+	}
+	return fmt.Sprintf("## [%s] - %s", n.Version, n.Date)
+}
+
+// CommitList はモデルに渡す分類済みのコミット一覧（本文の先頭行を含む）
+func (n *Notes) CommitList() string {
+	var b strings.Builder
+	for _, group := range n.Groups {
+		fmt.Fprintf(&b, "%s:\n", group.Title)
+		for _, commit := range group.Commits {
+			fmt.Fprintf(&b, "- %s", entryText(commit))
+			if commit.Breaking {
+				b.WriteString(" [BREAKING]")
+			}
+			if line := strings.TrimSpace(strings.SplitN(commit.Body, "\n", 2)[0]); line != "" {
+				fmt.Fprintf(&b, "\n  %s", line)
+			}
+			b.WriteString("\n")
+		}
+	}
+	return strings.TrimRight(b.String(), "\n")
+}
+
+// Markdown は変更履歴の1つのリリースの節を返す
+func (n *Notes) Markdown() string {
+	var b strings.Builder
+	b.WriteString(n.Heading() + "\n")
+	if n.Highlights != "" {
+		b.WriteString("\n### Highlights\n\n" + strings.TrimSpace(n.Highlights) + "\n")
+	}
+	if len(n.Breaking) > 0 {
+		b.WriteString("\n### Breaking Changes\n\n")
+		for _, commit := range n.Breaking {
+			b.WriteString(n.entry(commit))
+		}
+	}
+	for _, group := range n.Groups {
+		fmt.Fprintf(&b, "\n### %s\n\n", group.Title)
+		for _, commit := range group.Commits {
+			b.WriteString(n.entry(commit))
+		}
+	}
+	return b.String()
+}
+
+// entry はコミット1つの箇条書きの行（PR・コミットへの参照付き）
+func (n *Notes) entry(commit *Commit) string {
+	var refs []string
+	for _, pr := range commit.PRs {
+		if n.Repo != "" {
+			refs = append(refs, fmt.Sprintf("[#%d](https://github.com/%s/pull/%d)", pr, n.Repo, pr))
+		} else {
+			refs = append(refs, fmt.Sprintf("#%d", pr))
+		}
+	}
+	if n.Repo != "" {
+		refs = append(refs, fmt.Sprintf("[%s](https://github.com/%s/commit/%s)", commit.ShortHash(), n.Repo, commit.Hash))
+	} else {
+		refs = append(refs, commit.ShortHash())
+	}
+	return fmt.Sprintf("- %s (%s)\n", entryText(commit), strings.Join(refs, ", "))
+}
+
+// entryText はスコープを太字にしたコミットの説明
+func entryText(commit *Commit) string {
+	if commit.Scope == "" {
+		return commit.Description
+	}
+	return fmt.Sprintf("**%s:** %s", commit.Scope, commit.Description)
+}
+
+// UpdateChangelog は CHANGELOG.md の内容にリリースの節を書き込む
+// 同じバージョンの節があれば置き換え、なければ最初のリリースの節の前（新しい順）に入れる
+func UpdateChangelog(existing string, notes *Notes) string {
+	section := strings.TrimRight(notes.Markdown(), "\n") + "\n"
+	if strings.TrimSpace(existing) == "" {
+		return "# Changelog\n\n" + section
+	}
+
+	lines := strings.Split(strings.TrimRight(existing, "\n"), "\n")
+	start, end := -1, len(lines)
+	first := -1
+	for i, line := range lines {
+		if !strings.HasPrefix(line, "## ") {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		if start >= 0 {
+			end = i
+			break
+		}
+		if headingVersion(line) == headingVersion(notes.Heading()) {
+			start = i
+		}
+	}
+
+	var before, after []string
+	switch {
+	case start >= 0:
+		before, after = lines[:start], lines[end:]
+	case first >= 0:
+		before, after = lines[:first], lines[first:]
+	default:
+		before = lines
+	}
+
+	var parts []string
+	if head := strings.TrimRight(strings.Join(before, "\n"), "\n"); head != "" {
+		parts = append(parts, head)
+	}
+	parts = append(parts, strings.TrimRight(section, "\n"))
+	if tail := strings.TrimRight(strings.Join(after, "\n"), "\n"); tail != "" {
+		parts = append(parts, tail)
+	}
+	return strings.Join(parts, "\n\n") + "\n"
+}
+
+// headingVersion は見出しの行からバージョンを取り出す（"## [v1.2.0] - 2026-01-01" なら 1.2.0、先頭の v は区別しない）
+func headingVersion(line string) string {
+	fields := strings.Fields(strings.TrimPrefix(line, "## "))
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.TrimPrefix(strings.Trim(fields[0], "[]"), "v")
+}
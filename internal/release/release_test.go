@@ -0,0 +1,171 @@
+package release
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// TestParseCommit は Conventional Commits の種類・スコープ・破壊的変更・スカッシュマージのPR番号の解析をテストする
+func TestParseCommit(t *testing.T) {
+	commit := ParseCommit("0123456789abcdef", "feat(api)!: add streaming endpoint (#42)", "")
+	if commit.Type != "feat" || commit.Scope != "api" || !commit.Breaking || commit.Description != "add streaming endpoint" {
+		t.Errorf("解析結果 = %+v", commit)
+	}
+	if len(commit.PRs) != 1 || commit.PRs[0] != 42 || commit.ShortHash() != "0123456" {
+		t.Errorf("PR番号・短縮ハッシュ = %v %s", commit.PRs, commit.ShortHash())
+	}
+	commit = ParseCommit("abc", "Fix: typo in help", "Details.\n\nBREAKING CHANGE: flag renamed")
+	if commit.Type != "fix" || !commit.Breaking {
+		t.Errorf("本文の BREAKING CHANGE は破壊的変更のはず: %+v", commit)
+	}
+	if commit := ParseCommit("abc", "Update README", ""); commit.Type != "" || commit.Description != "Update README" {
+		t.Errorf("Conventional Commits でない要約 = %+v", commit)
+	}
+	if RangeEnd("v1.2.0..HEAD") != "HEAD" || RangeEnd("v1.2.0...v1.3.0") != "v1.3.0" || RangeEnd("v1.2.0..") != "HEAD" {
+		t.Error("RangeEnd の結果が想定外")
+	}
+}
+
+// TestNotesAndChangelog は種類毎のまとめ・参照のリンク・CHANGELOG.md の挿入と置き換えをテストする
+func TestNotesAndChangelog(t *testing.T) {
+	commits := []*Commit{
+		ParseCommit("1111111aaaa", "chore(release): v1.3.0", ""),
+		ParseCommit("2222222bbbb", "fix: handle empty config (#7)", ""),
+		ParseCommit("3333333cccc", "feat(cli)!: rename --out to --output", ""),
+		ParseCommit("4444444dddd", "Tidy imports", ""),
+		ParseCommit("5555555eeee", "refactor: split parser", ""),
+	}
+	notes := NewNotes("v1.3.0", "2026-10-15", "v1.2.0..HEAD", "glkt/app", commits)
+	var titles []string
+	for _, group := range notes.Groups {
+		titles = append(titles, group.Title)
+	}
+	if strings.Join(titles, ",") != "Features,Bug Fixes,Chores,Other Changes" || len(notes.Breaking) != 1 {
+		t.Fatalf("分類 = %v, 破壊的変更 = %d", titles, len(notes.Breaking))
+	}
+	notes.Highlights = "- Configs without settings no longer crash"
+	markdown := notes.Markdown()
+	for _, want := range []string{
+		"## [v1.3.0] - 2026-10-15\n\n### Highlights\n\n- Configs without settings no longer crash\n\n### Breaking Changes\n",
+		"- handle empty config ([#7](https://github.com/glkt/app/pull/7), [2222222](https://github.com/glkt/app/commit/2222222bbbb))\n",
+		"- **cli:** rename --out to --output (",
+	} {
+		if !strings.Contains(markdown, want) {
+			t.Errorf("Markdown に %q が含まれるはず:\n%s", want, markdown)
+		}
+	}
+	if strings.Contains(markdown, "release") {
+		t.Errorf("release のスコープのコミットは載せないはず:\n%s", markdown)
+	}
+
+	if changelog := UpdateChangelog("", notes); !strings.HasPrefix(changelog, "# Changelog\n\n## [v1.3.0]") {
+		t.Errorf("新しい CHANGELOG.md:\n%s", changelog)
+	}
+	existing := "# Changelog\n\nAll notable changes.\n\n## 1.2.0 (2026-01-01)\n\n- Old entry\n"
+	changelog := UpdateChangelog(existing, notes)
+	if !strings.HasPrefix(changelog, "# Changelog\n\nAll notable changes.\n\n## [v1.3.0] - 2026-10-15\n") || !strings.HasSuffix(changelog, "\n\n## 1.2.0 (2026-01-01)\n\n- Old entry\n") {
+		t.Errorf("最初のリリースの前に入れるはず:\n%s", changelog)
+	}
+	notes.Highlights = ""
+	replaced := UpdateChangelog(changelog, notes)
+	if strings.Count(replaced, "## [v1.3.0]") != 1 || strings.Contains(replaced, "Highlights") || !strings.Contains(replaced, "- Old entry") {
+		t.Errorf("同じバージョンの節は置き換えるはず:\n%s", replaced)
+	}
+}
+
+// answerProvider は固定の回答を返すテスト用プロバイダー
+type answerProvider struct {
+	llm.Provider
+	answer string
+	prompt string
+}
+
+func (p *answerProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.prompt = req.Messages[0].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}}, nil
+}
+
+// TestSummarize はコミット一覧をプロンプトに渡し、応答から箇条書きだけを取り出すことをテストする
+func TestSummarize(t *testing.T) {
+	notes := NewNotes("v2.0.0", "", "HEAD", "", []*Commit{ParseCommit("abc1234", "feat: add dark mode", "Follows the OS setting.")})
+	provider := &answerProvider{answer: "Here are the notes:\n\n```markdown\n- Dark mode that follows\n  the OS setting\n* Faster startup\n```"}
+	summarizer := &Summarizer{Provider: provider, Model: "test-model", Language: "en"}
+	summary, err := summarizer.Summarize(context.Background(), notes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary != "- Dark mode that follows the OS setting\n- Faster startup" {
+		t.Errorf("要約 = %q", summary)
+	}
+	for _, want := range []string{"v2.0.0", "Features:\n- add dark mode\n  Follows the OS setting."} {
+		if !strings.Contains(provider.prompt, want) {
+			t.Errorf("プロンプトに %q が含まれるはず:\n%s", want, provider.prompt)
+		}
+	}
+	provider.answer = "Nothing user-facing."
+	if _, err := summarizer.Summarize(context.Background(), notes); err == nil {
+		t.Error("箇条書きのない応答はエラーになるはず")
+	}
+}
+
+// TestLog はマージコミットで取り込んだコミットへのPR番号の対応付けをテストする
+func TestLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git が見つかりません")
+	}
+	dir := t.TempDir()
+	run := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(os.Environ(), "GIT_AUTHOR_NAME=t", "GIT_AUTHOR_EMAIL=t@example.com", "GIT_COMMITTER_NAME=t", "GIT_COMMITTER_EMAIL=t@example.com")
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v エラー: %v %s", args, err, output)
+		}
+	}
+	write := func(name, content string) {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run("init", "-q", "-b", "main")
+	write("a.txt", "a")
+	run("add", ".")
+	run("commit", "-q", "-m", "initial")
+	run("tag", "v1.0.0")
+	run("checkout", "-q", "-b", "feature")
+	write("b.txt", "b")
+	run("add", ".")
+	run("commit", "-q", "-m", "feat: add b")
+	run("checkout", "-q", "main")
+	write("c.txt", "c")
+	run("add", ".")
+	run("commit", "-q", "-m", "fix: repair c (#3)")
+	run("merge", "-q", "--no-ff", "-m", "Merge pull request #12 from glkt/feature", "feature")
+
+	ctx := context.Background()
+	revisionRange := ResolveRange(ctx, dir, "")
+	if revisionRange != "v1.0.0..HEAD" || ResolveRange(ctx, dir, "v1.0.0") != "v1.0.0..HEAD" {
+		t.Errorf("既定の範囲は直近のタグから = %s", revisionRange)
+	}
+	commits, err := Log(ctx, dir, revisionRange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	prs := make(map[string][]int)
+	for _, commit := range commits {
+		prs[commit.Subject] = commit.PRs
+	}
+	if len(commits) != 2 || len(prs["feat: add b"]) != 1 || prs["feat: add b"][0] != 12 || prs["fix: repair c (#3)"][0] != 3 {
+		t.Errorf("コミットとPR番号 = %v", prs)
+	}
+	if CommitDate(ctx, dir, "HEAD") == "" {
+		t.Error("コミット日を取得するはず")
+	}
+}
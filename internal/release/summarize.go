@@ -0,0 +1,74 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// maxCommitListBytes はプロンプトに含めるコミット一覧の上限
+const maxCommitListBytes = 24 * 1024
+
+// Summarizer はコミット一覧から利用者向けの変更の要約をモデルに書かせる
+type Summarizer struct {
+	Provider llm.Provider
+	Model    string
+	Language string
+	Memory   string // プロジェクトメモリ（VYB.md）
+	Registry *prompts.Registry
+}
+
+// Summarize はリリースの利用者向けの変更を Markdown の箇条書きでまとめる
+func (s *Summarizer) Summarize(ctx context.Context, notes *Notes) (string, error) {
+	registry := s.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	commits := notes.CommitList()
+	if len(commits) > maxCommitListBytes {
+		commits = commits[:maxCommitListBytes] + "\n... (truncated)"
+	}
+	prompt, err := registry.Render(prompts.TemplateReleaseNotes, prompts.Data{
+		Language:    s.Language,
+		ModelFamily: prompts.ModelFamily(s.Model),
+		Memory:      s.Memory,
+		Intent:      notes.Version,
+		Input:       commits,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	temperature := 0.3
+	resp, err := s.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       s.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", err
+	}
+	summary := cleanSummary(resp.Message.Content)
+	if summary == "" {
+		return "", fmt.Errorf("モデルが変更の要約を返しませんでした")
+	}
+	return summary, nil
+}
+
+// cleanSummary はモデルの応答から箇条書きだけを取り出す（コードブロックの囲み・見出し・前置きを除く）
+func cleanSummary(content string) string {
+	var items []string
+	for _, line := range strings.Split(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "- "), strings.HasPrefix(trimmed, "* "):
+			items = append(items, "- "+strings.TrimSpace(trimmed[2:]))
+		case len(items) > 0 && strings.HasPrefix(line, "  ") && trimmed != "":
+			items[len(items)-1] += " " + trimmed
+		}
+	}
+	return strings.Join(items, "\n")
+}
@@ -0,0 +1,187 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+	"sort"
+	"sync"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// ToolExecutor はエージェントが公開するツール群（tools.UnifiedToolRegistry が満たす）
+type ToolExecutor interface {
+	ListTools() []tools.UnifiedToolInterface
+	ExecuteTool(ctx context.Context, request *tools.ToolRequest) (*tools.ToolResponse, error)
+}
+
+// ToolFailure は tools/call の失敗応答の data（ツールのエラー応答とエラーコード）
+type ToolFailure struct {
+	Code     string              `json:"code,omitempty"` // tools.ToolError のコード（security_violation 等）
+	Response *tools.ToolResponse `json:"response,omitempty"`
+}
+
+// Agent はリモートホストでツール層を標準入出力の JSON-RPC として公開する
+// LLM・UI はローカルの vyb が担い、ファイル操作・コマンド実行のみをこのホストで行う
+type Agent struct {
+	executor ToolExecutor
+	workDir  string
+	version  string
+
+	writeMu sync.Mutex
+	out     io.Writer
+	wg      sync.WaitGroup
+
+	mu      sync.Mutex
+	running map[int64]context.CancelFunc // 実行中の tools/call（$/cancel で中断）
+}
+
+// NewAgent は新しいリモートエージェントを作成
+func NewAgent(executor ToolExecutor, workDir, version string) *Agent {
+	return &Agent{executor: executor, workDir: workDir, version: version, running: make(map[int64]context.CancelFunc)}
+}
+
+// Serve は入力が閉じられるか shutdown を受信するまでリクエストを処理
+// tools/call は並行に実行し、終了時は実行中のツールをキャンセルして完了を待つ
+func (a *Agent) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	a.out = out
+	defer a.wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var req Request
+		if err := json.Unmarshal(line, &req); err != nil {
+			a.reply(0, nil, &RPCError{Code: ErrCodeParse, Message: fmt.Sprintf("JSON解析エラー: %v", err)})
+			continue
+		}
+
+		switch req.Method {
+		case MethodInitialize:
+			a.reply(req.ID, a.initializeResult(), nil)
+		case MethodToolsCall:
+			var request tools.ToolRequest
+			if err := json.Unmarshal(req.Params, &request); err != nil {
+				a.reply(req.ID, nil, &RPCError{Code: ErrCodeInvalidParams, Message: fmt.Sprintf("パラメータ解析エラー: %v", err)})
+				continue
+			}
+			callCtx, cancelCall := context.WithCancel(ctx)
+			a.mu.Lock()
+			a.running[req.ID] = cancelCall
+			a.mu.Unlock()
+			a.wg.Add(1)
+			go func(id int64) {
+				defer a.wg.Done()
+				defer a.finish(id)
+				a.callTool(callCtx, id, &request)
+			}(req.ID)
+		case MethodCancel:
+			var params cancelParams
+			if json.Unmarshal(req.Params, &params) == nil {
+				a.finish(params.ID)
+			}
+		case MethodShutdown:
+			a.reply(req.ID, struct{}{}, nil)
+			return nil
+		default:
+			a.reply(req.ID, nil, &RPCError{Code: ErrCodeMethodNotFound, Message: fmt.Sprintf("不明なメソッド: %s", req.Method)})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("入力読み取りエラー: %w", err)
+	}
+	return nil
+}
+
+// finish は tools/call のコンテキストを解放する（実行中なら中断）
+func (a *Agent) finish(id int64) {
+	a.mu.Lock()
+	cancel, ok := a.running[id]
+	delete(a.running, id)
+	a.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// initializeResult はこのホストの環境と提供するツール
+func (a *Agent) initializeResult() *InitializeResult {
+	hostname, _ := os.Hostname()
+	result := &InitializeResult{
+		ProtocolVersion: ProtocolVersion,
+		Version:         a.version,
+		Hostname:        hostname,
+		WorkDir:         a.workDir,
+		OS:              runtime.GOOS,
+	}
+	for _, tool := range a.executor.ListTools() {
+		if !tool.IsEnabled() {
+			continue
+		}
+		result.Tools = append(result.Tools, ToolSpec{Schema: tool.GetSchema(), Capabilities: tool.GetCapabilities()})
+	}
+	sort.Slice(result.Tools, func(i, j int) bool { return result.Tools[i].Schema.Name < result.Tools[j].Schema.Name })
+	return result
+}
+
+// callTool はツールを実行して結果を返す（失敗時はエラー応答の data にツールの応答を含める）
+func (a *Agent) callTool(ctx context.Context, id int64, request *tools.ToolRequest) {
+	response, err := a.executor.ExecuteTool(ctx, request)
+	if err == nil {
+		a.reply(id, response, nil)
+		return
+	}
+
+	data, _ := json.Marshal(ToolFailure{Code: toolErrorCode(err), Response: response})
+	a.reply(id, nil, &RPCError{Code: ErrCodeToolFailed, Message: err.Error(), Data: data})
+}
+
+// toolErrorCode はツールのエラーコード（ツール固有のエラーでなければ空）
+func toolErrorCode(err error) string {
+	var toolErr *tools.ToolError
+	var execErr *tools.ExecutionError
+	var validationErr *tools.ValidationError
+	switch {
+	case errors.As(err, &execErr):
+		return execErr.Code
+	case errors.As(err, &validationErr):
+		return validationErr.Code
+	case errors.As(err, &toolErr):
+		return toolErr.Code
+	}
+	return ""
+}
+
+// reply は応答を1行のJSONとして書き出す
+func (a *Agent) reply(id int64, result interface{}, rpcErr *RPCError) {
+	response := Response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			response.Error = &RPCError{Code: ErrCodeInvalidRequest, Message: fmt.Sprintf("応答のエンコードエラー: %v", err)}
+		} else {
+			response.Result = data
+		}
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return
+	}
+	a.writeMu.Lock()
+	defer a.writeMu.Unlock()
+	a.out.Write(append(data, '\n'))
+}
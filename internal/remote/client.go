@@ -0,0 +1,316 @@
+package remote
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// ErrClosed は切断済みの接続を使った場合のエラー
+var ErrClosed = errors.New("リモートエージェントとの接続は閉じられています")
+
+// 接続失敗時のエラーに含める ssh の標準エラーの上限
+const stderrTailBytes = 4 * 1024
+
+// Client はリモートエージェントへの接続
+type Client struct {
+	Info *InitializeResult // 接続先の環境と提供するツール
+	Host string            // 表示用の接続先（ssh で接続した場合）
+
+	out    io.WriteCloser
+	cmd    *exec.Cmd
+	stderr *tailWriter
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *Response
+	err     error // 受信の終了理由（nil なら接続中）
+}
+
+// sshArgs はエージェントを起動する ssh の引数（ホストがオプションと解釈されないよう -- の後に置く）
+func sshArgs(cfg config.RemoteConfig) []string {
+	agentCommand := cfg.AgentCommand
+	if agentCommand == "" {
+		agentCommand = config.DefaultRemoteConfig().AgentCommand
+	}
+	if cfg.Dir != "" {
+		agentCommand += " --dir " + shellQuote(cfg.Dir)
+	}
+
+	args := append([]string{}, cfg.SSHArgs...)
+	if cfg.ConnectTimeoutSeconds > 0 {
+		args = append(args, "-o", fmt.Sprintf("ConnectTimeout=%d", cfg.ConnectTimeoutSeconds))
+	}
+	return append(args, "-T", "--", cfg.Host, agentCommand)
+}
+
+// Dial は ssh でリモートホストのエージェント（既定は vyb agent --stdio）を起動して接続する
+func Dial(ctx context.Context, cfg config.RemoteConfig) (*Client, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("リモートホストが指定されていません")
+	}
+	if err := checkHost(cfg.Host); err != nil {
+		return nil, err
+	}
+
+	cmd := exec.Command("ssh", sshArgs(cfg)...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &tailWriter{limit: stderrTailBytes}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("ssh を起動できません: %w", err)
+	}
+
+	if cfg.ConnectTimeoutSeconds > 0 {
+		var cancel context.CancelFunc
+		// 接続と認証に加えてリモートでの起動を待つ
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(cfg.ConnectTimeoutSeconds)*time.Second*2)
+		defer cancel()
+	}
+
+	client := newClient(stdout, stdin)
+	client.cmd = cmd
+	client.stderr = stderr
+	client.Host = cfg.Host
+	if err := client.initialize(ctx); err != nil {
+		client.Close()
+		if tail := strings.TrimSpace(stderr.String()); tail != "" {
+			err = fmt.Errorf("%w\n%s", err, tail)
+		}
+		return nil, fmt.Errorf("%s のエージェントに接続できません: %w", cfg.Host, err)
+	}
+	return client, nil
+}
+
+// NewClient は接続済みの入出力（パイプ等）でエージェントと初期化を行う
+func NewClient(ctx context.Context, in io.Reader, out io.WriteCloser) (*Client, error) {
+	client := newClient(in, out)
+	if err := client.initialize(ctx); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func newClient(in io.Reader, out io.WriteCloser) *Client {
+	client := &Client{
+		out:     out,
+		pending: make(map[int64]chan *Response),
+	}
+	go client.readLoop(in)
+	return client
+}
+
+// initialize はエージェントの環境とツール一覧を取得し、プロトコルバージョンを確認
+func (c *Client) initialize(ctx context.Context) error {
+	response, err := c.call(ctx, MethodInitialize, nil)
+	if err != nil {
+		return err
+	}
+	if response.Error != nil {
+		return response.Error
+	}
+	var info InitializeResult
+	if err := json.Unmarshal(response.Result, &info); err != nil {
+		return fmt.Errorf("initialize 応答の解析エラー: %w", err)
+	}
+	if info.ProtocolVersion != ProtocolVersion {
+		return fmt.Errorf("エージェントのプロトコルバージョン %s はこのバージョン（%s）と互換性がありません。リモートの vyb を更新してください", info.ProtocolVersion, ProtocolVersion)
+	}
+	c.Info = &info
+	return nil
+}
+
+// Call はリモートでツールを実行する
+// ツールが失敗した場合はエージェントが返したツールの応答とエラーを返す
+func (c *Client) Call(ctx context.Context, request *tools.ToolRequest) (*tools.ToolResponse, error) {
+	params, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	response, err := c.call(ctx, MethodToolsCall, params)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.Error != nil {
+		var failure ToolFailure
+		if len(response.Error.Data) > 0 {
+			json.Unmarshal(response.Error.Data, &failure)
+		}
+		if failure.Code != "" {
+			return failure.Response, tools.NewToolError(failure.Code, response.Error.Message)
+		}
+		return failure.Response, errors.New(response.Error.Message)
+	}
+
+	var result tools.ToolResponse
+	if err := json.Unmarshal(response.Result, &result); err != nil {
+		return nil, fmt.Errorf("ツール応答の解析エラー: %w", err)
+	}
+	return &result, nil
+}
+
+// Close はエージェントを終了して接続を閉じる
+func (c *Client) Close() error {
+	if c == nil {
+		return nil
+	}
+	if c.Err() == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		c.call(ctx, MethodShutdown, nil)
+		cancel()
+	}
+	c.out.Close()
+	if c.cmd != nil {
+		waited := make(chan struct{})
+		go func() {
+			c.cmd.Wait()
+			close(waited)
+		}()
+		select {
+		case <-waited:
+		case <-time.After(3 * time.Second):
+			c.cmd.Process.Kill()
+			<-waited
+		}
+	}
+	return nil
+}
+
+// Err は接続が切れた理由（接続中なら nil）
+func (c *Client) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// call はリクエストを送信して応答を待つ（キャンセル時はエージェントに中断を通知）
+func (c *Client) call(ctx context.Context, method string, params json.RawMessage) (*Response, error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	reply := make(chan *Response, 1)
+	c.pending[id] = reply
+	c.mu.Unlock()
+
+	if err := c.write(Request{JSONRPC: "2.0", ID: id, Method: method, Params: params}); err != nil {
+		c.forget(id)
+		return nil, err
+	}
+
+	select {
+	case response, ok := <-reply:
+		if !ok {
+			return nil, c.Err()
+		}
+		return response, nil
+	case <-ctx.Done():
+		c.forget(id)
+		if method == MethodToolsCall {
+			params, _ := json.Marshal(cancelParams{ID: id})
+			c.write(Request{JSONRPC: "2.0", Method: MethodCancel, Params: params})
+		}
+		return nil, ctx.Err()
+	}
+}
+
+func (c *Client) forget(id int64) {
+	c.mu.Lock()
+	delete(c.pending, id)
+	c.mu.Unlock()
+}
+
+func (c *Client) write(request Request) error {
+	data, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := c.out.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("リモートエージェントへの送信エラー: %w", err)
+	}
+	return nil
+}
+
+// readLoop は応答を待っているリクエストへ振り分ける（終了時は待機中のリクエストをすべて失敗させる）
+func (c *Client) readLoop(in io.Reader) {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+	for scanner.Scan() {
+		var response Response
+		if err := json.Unmarshal(scanner.Bytes(), &response); err != nil {
+			continue
+		}
+		c.mu.Lock()
+		reply, ok := c.pending[response.ID]
+		delete(c.pending, response.ID)
+		c.mu.Unlock()
+		if ok {
+			reply <- &response
+		}
+	}
+
+	err := ErrClosed
+	if scanErr := scanner.Err(); scanErr != nil {
+		err = fmt.Errorf("%w: %v", ErrClosed, scanErr)
+	}
+	c.mu.Lock()
+	c.err = err
+	for id, reply := range c.pending {
+		close(reply)
+		delete(c.pending, id)
+	}
+	c.mu.Unlock()
+}
+
+// shellQuote はリモートのシェルに渡す引数をシングルクォートで囲む
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+// tailWriter は書き込まれた内容の末尾のみ保持する
+type tailWriter struct {
+	mu    sync.Mutex
+	limit int
+	data  []byte
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.data = append(w.data, p...)
+	if len(w.data) > w.limit {
+		w.data = w.data[len(w.data)-w.limit:]
+	}
+	return len(p), nil
+}
+
+func (w *tailWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return string(w.data)
+}
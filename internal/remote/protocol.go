@@ -0,0 +1,79 @@
+package remote
+
+import (
+	"encoding/json"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// リモートエージェントのプロトコルバージョン（ローカルとリモートで一致が必要）
+const ProtocolVersion = "1"
+
+// JSON-RPC メソッド名（ローカル → リモートエージェント）
+const (
+	MethodInitialize = "initialize"
+	MethodToolsCall  = "tools/call"
+	MethodShutdown   = "shutdown"
+	MethodCancel     = "$/cancel" // 通知: 実行中の tools/call を中断（Ctrl+C）
+)
+
+// JSON-RPC エラーコード
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeToolFailed     = -32010 // ツールの実行エラー（応答の data に ToolResponse）
+)
+
+// 1行のメッセージの最大サイズ（ファイル内容を含むため大きめ）
+const maxMessageSize = 32 * 1024 * 1024
+
+// Request は JSON-RPC リクエスト（改行区切り）
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response は JSON-RPC 応答
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      int64           `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// RPCError は JSON-RPC エラー
+type RPCError struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// Error はerrorインターフェースを実装
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// cancelParams は $/cancel のパラメータ
+type cancelParams struct {
+	ID int64 `json:"id"`
+}
+
+// InitializeResult は initialize の応答（リモートの環境と提供するツール）
+type InitializeResult struct {
+	ProtocolVersion string     `json:"protocol_version"`
+	Version         string     `json:"version"`
+	Hostname        string     `json:"hostname"`
+	WorkDir         string     `json:"work_dir"`
+	OS              string     `json:"os"`
+	Tools           []ToolSpec `json:"tools"`
+}
+
+// ToolSpec はリモートで実行できるツールの定義
+type ToolSpec struct {
+	Schema       tools.ToolSchema       `json:"schema"`
+	Capabilities []tools.ToolCapability `json:"capabilities"`
+}
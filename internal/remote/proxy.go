@@ -0,0 +1,98 @@
+package remote
+
+import (
+	"context"
+	"sort"
+
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// Registry はリモートのツールで置き換えるツールレジストリ（tools.UnifiedToolRegistry が満たす）
+type Registry interface {
+	ListTools() []tools.UnifiedToolInterface
+	RegisterTool(tool tools.UnifiedToolInterface) error
+	UnregisterTool(name string) error
+}
+
+// ローカルのファイル・コマンド・Git を扱うツールの機能（リモート接続中は使わせない）
+var localCapabilities = []tools.ToolCapability{
+	tools.CapabilityFileRead,
+	tools.CapabilityFileWrite,
+	tools.CapabilityFileEdit,
+	tools.CapabilityCommand,
+	tools.CapabilityGit,
+}
+
+// Mount はレジストリのツールをリモートのツールで置き換え、置き換えたツール名を返す
+// リモートに無いローカルのファイル・コマンド・Git ツールは、ローカルを誤って操作しないよう外す
+// （Web ツール等のローカルで完結するツールはそのまま残る）
+func Mount(registry Registry, client *Client) ([]string, error) {
+	remoteNames := make(map[string]bool, len(client.Info.Tools))
+	for _, spec := range client.Info.Tools {
+		remoteNames[spec.Schema.Name] = true
+	}
+
+	for _, tool := range registry.ListTools() {
+		name := tool.GetName()
+		if remoteNames[name] || touchesLocalMachine(tool.GetCapabilities()) {
+			registry.UnregisterTool(name)
+		}
+	}
+
+	var mounted []string
+	for _, spec := range client.Info.Tools {
+		if err := registry.RegisterTool(&remoteTool{client: client, spec: spec}); err != nil {
+			return mounted, err
+		}
+		mounted = append(mounted, spec.Schema.Name)
+	}
+	sort.Strings(mounted)
+	return mounted, nil
+}
+
+func touchesLocalMachine(capabilities []tools.ToolCapability) bool {
+	for _, capability := range capabilities {
+		for _, local := range localCapabilities {
+			if capability == local {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// remoteTool はリモートエージェントで実行するツール
+// パラメーターの検証とセキュリティ制約はリモート側のレジストリが適用する
+type remoteTool struct {
+	client *Client
+	spec   ToolSpec
+}
+
+func (t *remoteTool) GetName() string                        { return t.spec.Schema.Name }
+func (t *remoteTool) GetDescription() string                 { return t.spec.Schema.Description }
+func (t *remoteTool) GetSchema() tools.ToolSchema            { return t.spec.Schema }
+func (t *remoteTool) GetVersion() string                     { return t.spec.Schema.Version }
+func (t *remoteTool) IsEnabled() bool                        { return true }
+func (t *remoteTool) Configure(map[string]interface{}) error { return nil }
+
+func (t *remoteTool) GetCapabilities() []tools.ToolCapability {
+	return t.spec.Capabilities
+}
+
+// ValidateRequest は必須パラメーターのみ確認する（型等はリモートで検証）
+func (t *remoteTool) ValidateRequest(request *tools.ToolRequest) error {
+	if request == nil {
+		return tools.ErrInvalidRequest
+	}
+	for _, required := range t.spec.Schema.Required {
+		if _, exists := request.Parameters[required]; !exists {
+			return tools.NewValidationError("required parameter missing: " + required)
+		}
+	}
+	return nil
+}
+
+// Execute はリモートでツールを実行する
+func (t *remoteTool) Execute(ctx context.Context, request *tools.ToolRequest) (*tools.ToolResponse, error) {
+	return t.client.Call(ctx, request)
+}
@@ -0,0 +1,169 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// connect はパイプでつないだエージェントに接続する（remoteDir が「リモート」の作業ディレクトリ）
+func connect(t *testing.T, remoteDir string) *Client {
+	t.Helper()
+	registry := tools.NewUnifiedToolRegistry(security.NewDefaultConstraints(remoteDir), nil)
+	agent := NewAgent(registry, remoteDir, "test")
+
+	toAgent, agentIn := io.Pipe()
+	fromAgent, agentOut := io.Pipe()
+	served := make(chan error, 1)
+	go func() {
+		served <- agent.Serve(context.Background(), toAgent, agentOut)
+		agentOut.Close()
+	}()
+
+	client, err := NewClient(context.Background(), fromAgent, agentIn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		client.Close()
+		if err := <-served; err != nil {
+			t.Errorf("Serve: %v", err)
+		}
+	})
+	return client
+}
+
+func TestMount_RunsToolsOnRemote(t *testing.T) {
+	remoteDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(remoteDir, "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	client := connect(t, remoteDir)
+	if client.Info.WorkDir != remoteDir || len(client.Info.Tools) == 0 {
+		t.Fatalf("Info = %+v", client.Info)
+	}
+
+	localDir := t.TempDir()
+	local := tools.NewUnifiedToolRegistry(security.NewDefaultConstraints(localDir), nil)
+	mounted, err := Mount(local, client)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mounted) != len(client.Info.Tools) || !strings.Contains(strings.Join(mounted, ","), "bash") {
+		t.Errorf("mounted = %v", mounted)
+	}
+
+	// ローカルのレジストリから実行してもリモートのファイルを読み書きする
+	response, err := local.ExecuteTool(context.Background(), &tools.ToolRequest{
+		ToolName:   "write",
+		Parameters: map[string]interface{}{"file_path": filepath.Join(remoteDir, "notes.txt"), "content": "hello"},
+	})
+	if err != nil || !response.Success {
+		t.Fatalf("write = %+v, %v", response, err)
+	}
+	if data, err := os.ReadFile(filepath.Join(remoteDir, "notes.txt")); err != nil || string(data) != "hello" {
+		t.Errorf("リモートに書き込まれるはず: %q, %v", data, err)
+	}
+	response, err = local.ExecuteTool(context.Background(), &tools.ToolRequest{
+		ToolName:   "read",
+		Parameters: map[string]interface{}{"file_path": filepath.Join(remoteDir, "main.go")},
+	})
+	if err != nil || !strings.Contains(response.Content, "package main") {
+		t.Errorf("read = %+v, %v", response, err)
+	}
+
+	// リモートのセキュリティ制約が適用され、エラーコードも伝わる
+	_, err = local.ExecuteTool(context.Background(), &tools.ToolRequest{
+		ToolName:   "read",
+		Parameters: map[string]interface{}{"file_path": filepath.Join(localDir, "secret.txt")},
+	})
+	var toolErr *tools.ToolError
+	if !errors.As(err, &toolErr) || toolErr.Code != "execution_error" || !strings.Contains(toolErr.Message, "Invalid file path") {
+		t.Errorf("作業ディレクトリ外は拒否されるはず: %v", err)
+	}
+
+	// 必須パラメーターはローカルで検証
+	if _, err := local.ExecuteTool(context.Background(), &tools.ToolRequest{ToolName: "read", Parameters: map[string]interface{}{}}); err == nil {
+		t.Error("必須パラメーターが無ければエラーのはず")
+	}
+}
+
+func TestMount_RemovesLocalOnlyTools(t *testing.T) {
+	client := connect(t, t.TempDir())
+
+	local := tools.NewUnifiedToolRegistry(security.NewDefaultConstraints(t.TempDir()), nil)
+	localOnly := tools.NewBaseTool("local_shell", "runs locally", "1", tools.CategoryCommand)
+	localOnly.AddCapability(tools.CapabilityCommand)
+	local.RegisterTool(localOnly)
+	if _, err := Mount(local, client); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := local.GetTool("local_shell"); err == nil {
+		t.Error("リモートに無いローカルのコマンドツールは外すはず")
+	}
+	if _, err := local.GetTool("bash"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestClient_ClosedConnection(t *testing.T) {
+	client := connect(t, t.TempDir())
+	client.out.Close()
+
+	_, err := client.Call(context.Background(), &tools.ToolRequest{ToolName: "ls", Parameters: map[string]interface{}{}})
+	if err == nil {
+		t.Fatal("切断後はエラーのはず")
+	}
+}
+
+func TestApplyTarget(t *testing.T) {
+	tests := []struct {
+		target string
+		want   config.RemoteConfig
+	}{
+		{"dev@build01:/srv/app", config.RemoteConfig{Host: "dev@build01", Dir: "/srv/app"}},
+		{"build01", config.RemoteConfig{Host: "build01"}},
+		{"ssh://dev@build01:2222/~/app", config.RemoteConfig{Host: "dev@build01", Dir: "~/app", SSHArgs: []string{"-p", "2222"}}},
+		{"ssh://build01/srv/app", config.RemoteConfig{Host: "build01", Dir: "/srv/app"}},
+	}
+	for _, tt := range tests {
+		var got config.RemoteConfig
+		if err := ApplyTarget(&got, tt.target); err != nil {
+			t.Errorf("ApplyTarget(%q): %v", tt.target, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ApplyTarget(%q) = %+v, want %+v", tt.target, got, tt.want)
+		}
+	}
+	if err := ApplyTarget(&config.RemoteConfig{}, ":/srv"); err == nil {
+		t.Error("ホストが無ければエラーのはず")
+	}
+	for _, target := range []string{"-oProxyCommand=id:/srv", "ssh://-oProxyCommand=id/srv"} {
+		if err := ApplyTarget(&config.RemoteConfig{}, target); err == nil {
+			t.Errorf("ApplyTarget(%q): - で始まるホストはエラーのはず", target)
+		}
+	}
+}
+
+func TestDial_RejectsOptionLikeHost(t *testing.T) {
+	_, err := Dial(context.Background(), config.RemoteConfig{Host: "-oProxyCommand=touch /tmp/pwned"})
+	if err == nil || !strings.Contains(err.Error(), "- で始まる") {
+		t.Fatalf("- で始まるホストは ssh を起動せずにエラーのはず: %v", err)
+	}
+
+	args := sshArgs(config.RemoteConfig{Host: "dev@build01", Dir: "/srv/app", SSHArgs: []string{"-p", "2222"}, ConnectTimeoutSeconds: 5})
+	want := []string{"-p", "2222", "-o", "ConnectTimeout=5", "-T", "--", "dev@build01", "vyb agent --stdio --dir '/srv/app'"}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("sshArgs = %q, want %q", args, want)
+	}
+}
@@ -0,0 +1,58 @@
+package remote
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// ApplyTarget は --remote の指定を接続設定に反映する
+// 形式は [user@]host[:dir]（scp と同じ）または ssh://[user@]host[:port][/dir]
+func ApplyTarget(cfg *config.RemoteConfig, target string) error {
+	if strings.HasPrefix(target, "ssh://") {
+		parsed, err := url.Parse(target)
+		if err != nil || parsed.Hostname() == "" {
+			return fmt.Errorf("リモートの指定が不正です: %s", target)
+		}
+		if err := checkHost(parsed.Hostname()); err != nil {
+			return err
+		}
+		cfg.Host = parsed.Hostname()
+		if parsed.User != nil && parsed.User.Username() != "" {
+			cfg.Host = parsed.User.Username() + "@" + cfg.Host
+		}
+		if port := parsed.Port(); port != "" {
+			cfg.SSHArgs = append(cfg.SSHArgs, "-p", port)
+		}
+		// ssh://host/~/app はホームディレクトリ基準
+		if dir := strings.TrimPrefix(parsed.Path, "/~"); dir != parsed.Path {
+			cfg.Dir = "~" + dir
+		} else if parsed.Path != "" && parsed.Path != "/" {
+			cfg.Dir = parsed.Path
+		}
+		return nil
+	}
+
+	host, dir, _ := strings.Cut(target, ":")
+	if host == "" || strings.ContainsAny(host, " /") {
+		return fmt.Errorf("リモートの指定が不正です: %s（[user@]host[:dir] の形式で指定してください）", target)
+	}
+	if err := checkHost(host); err != nil {
+		return err
+	}
+	cfg.Host = host
+	if dir != "" {
+		cfg.Dir = dir
+	}
+	return nil
+}
+
+// checkHost は ssh がオプションとして解釈するホスト（- で始まるもの）を拒否する
+func checkHost(host string) error {
+	if strings.HasPrefix(host, "-") {
+		return fmt.Errorf("リモートホストが不正です（- で始まるホストは指定できません）: %s", host)
+	}
+	return nil
+}
@@ -0,0 +1,147 @@
+package replay
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// Provider は記録したモデル応答を記録順に返すプロバイダー（ターン毎に Load で差し替える）
+type Provider struct {
+	mu        sync.Mutex
+	responses []logger.AuditEvent
+	used      int
+	exhausted int // 記録した応答を使い切った後の問い合わせ数
+}
+
+// Load はターンで記録されたモデル応答（llm_response）を設定する
+func (p *Provider) Load(responses []logger.AuditEvent) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.responses = responses
+	p.used = 0
+	p.exhausted = 0
+}
+
+// Usage は使った応答数と、使い切った後の問い合わせ数
+func (p *Provider) Usage() (used, exhausted int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.used, p.exhausted
+}
+
+// Chat は次の記録した応答を返す（失敗が記録されていれば同じエラーを返す）
+func (p *Provider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.used >= len(p.responses) {
+		p.exhausted++
+		return nil, fmt.Errorf("記録されたモデル応答を使い切りました（%d 件）", len(p.responses))
+	}
+	event := p.responses[p.used]
+	p.used++
+	if !event.Success {
+		return nil, fmt.Errorf("%s", event.Error)
+	}
+	return &llm.ChatResponse{
+		Message:         llm.ChatMessage{Role: "assistant", Content: event.Content},
+		Done:            true,
+		PromptEvalCount: event.PromptTokens,
+		EvalCount:       event.CompletionTokens,
+	}, nil
+}
+
+// SupportsFunctionCalling は記録した応答を返すだけのため常に false
+func (p *Provider) SupportsFunctionCalling() bool {
+	return false
+}
+
+// GetModelInfo はモデル名だけの情報を返す
+func (p *Provider) GetModelInfo(model string) (*llm.ModelInfo, error) {
+	return &llm.ModelInfo{Name: model}, nil
+}
+
+// ListModels は空の一覧を返す
+func (p *Provider) ListModels() ([]llm.ModelInfo, error) {
+	return nil, nil
+}
+
+// Backend は記録したコマンドの出力と終了コードを返す実行バックエンド
+// 同じコマンドが複数回記録されていれば記録順に返し、記録のないコマンドは実行せずに失敗させる
+type Backend struct {
+	mu         sync.Mutex
+	outputs    map[string][]logger.AuditEvent
+	unrecorded []string
+}
+
+// Load はターンで記録されたコマンド・タスク・bash ツールの実行結果を設定する
+func (b *Backend) Load(events []logger.AuditEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.outputs = make(map[string][]logger.AuditEvent)
+	b.unrecorded = nil
+	for _, event := range events {
+		b.outputs[event.Command] = append(b.outputs[event.Command], event)
+	}
+}
+
+// Unrecorded は記録がなく実行しなかったコマンド
+func (b *Backend) Unrecorded() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]string(nil), b.unrecorded...)
+}
+
+// Name はバックエンド名を返す
+func (b *Backend) Name() string {
+	return "replay"
+}
+
+// Command は記録した出力を標準出力に書いて記録した終了コードで終わるコマンドを返す
+func (b *Backend) Command(ctx context.Context, command string, workDir string) (*exec.Cmd, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	recorded := b.outputs[command]
+	if len(recorded) == 0 {
+		b.unrecorded = append(b.unrecorded, command)
+		return exec.CommandContext(ctx, "sh", "-c", "echo 'vyb replay: command was not recorded' >&2; exit 127"), nil
+	}
+	event := recorded[0]
+	b.outputs[command] = recorded[1:]
+
+	output := event.Content
+	if output == "" && event.Error != "" {
+		output = event.Error
+	}
+	cmd := exec.CommandContext(ctx, "sh", "-c", "cat; exit "+strconv.Itoa(exitCode(event)))
+	cmd.Stdin = strings.NewReader(output)
+	return cmd, nil
+}
+
+// Available は常に利用できる
+func (b *Backend) Available() error {
+	return nil
+}
+
+// IsSandboxed はコマンドを実際には実行しないため true
+func (b *Backend) IsSandboxed() bool {
+	return true
+}
+
+// exitCode は記録した終了コード（失敗で終了コードの記録がなければ 1）
+func exitCode(event logger.AuditEvent) int {
+	if event.ExitCode != 0 {
+		return event.ExitCode
+	}
+	if !event.Success {
+		return 1
+	}
+	return 0
+}
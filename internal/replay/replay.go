@@ -0,0 +1,410 @@
+// Package replay は監査ログに記録したセッションのユーザー入力を、記録したモデル応答・コマンド出力を返す
+// モックに対して決定的に再実行し、記録と異なる操作を報告する（vyb replay）。
+// 再実行はプロジェクトの複製の中で行うため、元のファイルは変更しない
+package replay
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/textutil"
+)
+
+// 監査ログで本文が切り詰められたことを示す末尾（logger.AuditRecorder が付ける）
+const truncatedSuffix = "...(truncated)"
+
+// 応答の末尾に付くメタ情報（応答時間等、再実行毎に変わるため比べない）の区切り
+const metaSeparator = "\n\n---\n⏱️"
+
+// Turn は1つのユーザー入力と、次の入力までに記録されたイベント
+type Turn struct {
+	Input  string
+	Events []logger.AuditEvent
+}
+
+// Split はイベントをユーザー入力毎のターンに分ける（最初の入力より前のイベントは含めない）
+func Split(events []logger.AuditEvent) []Turn {
+	var turns []Turn
+	for _, event := range events {
+		if event.Type == logger.AuditUserInput {
+			turns = append(turns, Turn{Input: event.Content})
+			continue
+		}
+		if len(turns) > 0 {
+			last := &turns[len(turns)-1]
+			last.Events = append(last.Events, event)
+		}
+	}
+	return turns
+}
+
+// Responses はターンで記録されたモデル応答
+func (t Turn) Responses() []logger.AuditEvent {
+	var responses []logger.AuditEvent
+	for _, event := range t.Events {
+		if event.Type == logger.AuditLLMResponse {
+			responses = append(responses, event)
+		}
+	}
+	return responses
+}
+
+// Outputs はターンで記録されたコマンド・タスク・bash ツールの実行結果
+func (t Turn) Outputs() []logger.AuditEvent {
+	var outputs []logger.AuditEvent
+	for _, event := range t.Events {
+		switch {
+		case event.Type == logger.AuditCommand, event.Type == logger.AuditTask:
+			outputs = append(outputs, event)
+		case event.Type == logger.AuditToolCall && event.Command != "":
+			outputs = append(outputs, event)
+		}
+	}
+	return outputs
+}
+
+// Answer はターンの最終的な応答（記録されていなければ空）
+func (t Turn) Answer() string {
+	answer := ""
+	for _, event := range t.Events {
+		if event.Type == logger.AuditAssistant {
+			answer = event.Content
+		}
+	}
+	return answer
+}
+
+// Confirmed は応答の後にコマンド実行・ファイル書き込みが記録されているか（保留中の提案を適用した）
+func (t Turn) Confirmed() bool {
+	answered := false
+	for _, event := range t.Events {
+		switch event.Type {
+		case logger.AuditAssistant:
+			answered = true
+		case logger.AuditCommand, logger.AuditFileWrite:
+			if answered {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Actions はターンで行った操作を記録順に並べる（"model: …"、"command: go test ./..."、"write: main.go" 等）
+// モデルの応答の生成中に実行を始めた操作（tool streaming）は、記録の前後が実行のタイミングで
+// 変わるため、その応答の後に並べる
+func (t Turn) Actions() []string {
+	var actions, generating []string
+	requesting := false
+	for _, event := range t.Events {
+		switch event.Type {
+		case logger.AuditLLMRequest:
+			requesting = true
+		case logger.AuditLLMResponse:
+			actions = append(actions, describe(event))
+			actions = append(actions, generating...)
+			generating, requesting = nil, false
+		default:
+			action := describe(event)
+			switch {
+			case action == "":
+			case requesting:
+				generating = append(generating, action)
+			default:
+				actions = append(actions, action)
+			}
+		}
+	}
+	return append(actions, generating...)
+}
+
+// describe はイベントを比較用の1行にする（操作でないイベントは空）
+func describe(event logger.AuditEvent) string {
+	status := ""
+	if !event.Success {
+		status = " (failed)"
+	}
+	switch event.Type {
+	case logger.AuditLLMResponse:
+		return "model: " + summaryLine(event.Content, 60) + status
+	case logger.AuditCommand:
+		return "command: " + event.Command + status
+	case logger.AuditTask:
+		return event.Tool + ": " + event.Command + status
+	case logger.AuditFileWrite:
+		return "wrote: " + event.Path + status
+	case logger.AuditToolCall:
+		target := event.Path
+		if event.Command != "" {
+			target = event.Command
+		}
+		return strings.TrimSpace(event.Tool+": "+target) + status
+	}
+	return ""
+}
+
+// summaryLine は本文の最初の空でない行を最大 limit 文字にする
+func summaryLine(text string, limit int) string {
+	line := textutil.FirstLine(text)
+	if runes := []rune(line); len(runes) > limit {
+		return string(runes[:limit]) + "…"
+	}
+	return line
+}
+
+// TurnReport はターン1つの再実行結果
+type TurnReport struct {
+	Index       int      `json:"index"`
+	Input       string   `json:"input"`
+	Recorded    []string `json:"recorded"` // 記録した操作
+	Replayed    []string `json:"replayed"` // 再実行で行った操作
+	Applied     bool     `json:"applied"`  // 記録に従って保留中の提案を適用したか
+	Message     string   `json:"message,omitempty"`
+	Divergences []string `json:"divergences,omitempty"`
+	Error       string   `json:"error,omitempty"`
+}
+
+// Report はセッション全体の再実行結果
+type Report struct {
+	SessionID string       `json:"session_id"`
+	ReplayID  string       `json:"replay_id"`          // 再実行のセッションID（監査ログのファイル名）
+	WorkDir   string       `json:"work_dir,omitempty"` // 残した作業ディレクトリ（Keep の時のみ）
+	Turns     []TurnReport `json:"turns"`
+}
+
+// Diverged は記録と異なる操作・応答があったか
+func (r *Report) Diverged() bool {
+	for _, turn := range r.Turns {
+		if len(turn.Divergences) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// backendSetter は実行バックエンドを差し替えられるセッション管理
+type backendSetter interface {
+	SetExecutionBackend(backend sandbox.Backend)
+}
+
+// Runner は作業ディレクトリにプロジェクトを複製し、記録したセッションのユーザー入力を順に再実行する
+type Runner struct {
+	// NewManager は作業ディレクトリへ移動した後で、記録した応答を返すプロバイダーを渡して呼ばれる
+	NewManager func(provider llm.Provider) (interactive.SessionManager, error)
+	// ProjectDir は作業ディレクトリへ複製するプロジェクト（空なら空の作業ディレクトリで再実行する）
+	ProjectDir string
+	// WorkRoot は作業ディレクトリを作る場所（空なら一時ディレクトリ）
+	WorkRoot string
+	// Keep なら作業ディレクトリと再実行の監査ログを削除せずに残す
+	Keep bool
+}
+
+// Run は記録したイベントのユーザー入力を順に再実行し、ターン毎に記録と比べる
+func (r *Runner) Run(ctx context.Context, sessionID string, events []logger.AuditEvent) (*Report, error) {
+	turns := Split(events)
+	if len(turns) == 0 {
+		return nil, fmt.Errorf("セッション %s に再実行できるユーザー入力がありません", sessionID)
+	}
+
+	root, err := os.MkdirTemp(r.WorkRoot, "vyb-replay-")
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリの作成エラー: %w", err)
+	}
+	if !r.Keep {
+		defer os.RemoveAll(root)
+	}
+	workDir := filepath.Join(root, "project")
+	auditDir := filepath.Join(root, "logs")
+	if err := os.MkdirAll(workDir, 0755); err != nil {
+		return nil, fmt.Errorf("作業ディレクトリの作成エラー: %w", err)
+	}
+	if r.ProjectDir != "" {
+		if err := copyProject(r.ProjectDir, workDir); err != nil {
+			return nil, fmt.Errorf("プロジェクトの複製エラー: %w", err)
+		}
+	}
+
+	previous, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリ取得エラー: %w", err)
+	}
+	if err := os.Chdir(workDir); err != nil {
+		return nil, fmt.Errorf("作業ディレクトリへの移動エラー: %w", err)
+	}
+	defer os.Chdir(previous)
+
+	// 再実行で行った操作は別の監査ログに記録して、記録と同じ形で比べる
+	recorder := logger.NewAuditRecorder(auditDir, 0)
+	previousRecorder := logger.SetAuditRecorder(recorder)
+	defer func() {
+		logger.SetAuditRecorder(previousRecorder)
+		recorder.Close()
+	}()
+
+	provider := &Provider{}
+	backend := &Backend{}
+	manager, err := r.NewManager(llm.NewAuditingProvider(provider))
+	if err != nil {
+		return nil, err
+	}
+	setter, ok := manager.(backendSetter)
+	if !ok {
+		return nil, fmt.Errorf("このセッション管理は実行バックエンドを差し替えられません")
+	}
+	setter.SetExecutionBackend(backend)
+	session, err := manager.CreateSession(interactive.CodingSessionTypeGeneral)
+	if err != nil {
+		return nil, fmt.Errorf("セッション作成エラー: %w", err)
+	}
+
+	report := &Report{SessionID: sessionID, ReplayID: session.ID}
+	if r.Keep {
+		report.WorkDir = root
+	}
+	for i, turn := range turns {
+		result := TurnReport{Index: i + 1, Input: turn.Input, Recorded: turn.Actions()}
+		provider.Load(turn.Responses())
+		backend.Load(turn.Outputs())
+
+		response, err := manager.ProcessUserInput(ctx, session.ID, turn.Input)
+		if err != nil {
+			result.Error = err.Error()
+		} else if response != nil {
+			result.Message = response.Message
+		}
+		applied, err := settlePending(ctx, manager, session.ID, turn.Confirmed())
+		result.Applied = applied
+		if err != nil && result.Error == "" {
+			result.Error = err.Error()
+		}
+
+		used, exhausted := provider.Usage()
+		if recorded := len(turn.Responses()); used < recorded {
+			result.Divergences = append(result.Divergences, fmt.Sprintf("used %d of %d recorded model responses", used, recorded))
+		}
+		if exhausted > 0 {
+			result.Divergences = append(result.Divergences, fmt.Sprintf("%d model request(s) beyond the recording", exhausted))
+		}
+		for _, command := range backend.Unrecorded() {
+			result.Divergences = append(result.Divergences, "command was not recorded: "+command)
+		}
+		report.Turns = append(report.Turns, result)
+	}
+
+	// 再実行の監査ログをターン毎に分けて記録と比べる
+	replayedEvents, err := logger.ReadAuditLog(auditDir, session.ID)
+	if err != nil {
+		return nil, fmt.Errorf("再実行の監査ログ読み込みエラー: %w", err)
+	}
+	replayed := Split(replayedEvents)
+	for i := range report.Turns {
+		var turn Turn
+		if i < len(replayed) {
+			turn = replayed[i]
+		}
+		result := &report.Turns[i]
+		result.Replayed = turn.Actions()
+		result.Divergences = append(result.Divergences, compareActions(result.Recorded, result.Replayed)...)
+		if result.Error == "" && !sameAnswer(turns[i].Answer(), turn.Answer()) {
+			result.Divergences = append(result.Divergences, "final response differs from the recording")
+		}
+	}
+	return report, nil
+}
+
+// settlePending は記録に従って保留中の提案を適用または破棄し、適用したかを返す
+func settlePending(ctx context.Context, manager interactive.SessionManager, sessionID string, confirmed bool) (bool, error) {
+	session, err := manager.GetSession(sessionID)
+	if err != nil || session.PendingSuggestion == nil {
+		return false, err
+	}
+	suggestionID := session.PendingSuggestion.ID
+	if err := manager.ConfirmSuggestion(sessionID, suggestionID, confirmed); err != nil || !confirmed {
+		return false, err
+	}
+	if err := manager.ApplySuggestion(ctx, sessionID, suggestionID); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// compareActions は記録と再実行の操作を順に比べ、最初に異なる位置と過不足を返す
+func compareActions(recorded, replayed []string) []string {
+	for i := 0; i < len(recorded) || i < len(replayed); i++ {
+		switch {
+		case i >= len(replayed):
+			return []string{fmt.Sprintf("step %d: recorded %q was not replayed (%d missing)", i+1, recorded[i], len(recorded)-i)}
+		case i >= len(recorded):
+			return []string{fmt.Sprintf("step %d: replay did %q beyond the recording (%d extra)", i+1, replayed[i], len(replayed)-i)}
+		case recorded[i] != replayed[i]:
+			return []string{fmt.Sprintf("step %d: recorded %q, replayed %q", i+1, recorded[i], replayed[i])}
+		}
+	}
+	return nil
+}
+
+// sameAnswer は応答時間等のメタ情報を除いて応答を比べる（記録が切り詰められていれば先頭のみ）
+func sameAnswer(recorded, replayed string) bool {
+	recorded = stripMeta(recorded)
+	replayed = stripMeta(replayed)
+	if strings.HasSuffix(recorded, truncatedSuffix) {
+		return strings.HasPrefix(replayed, strings.TrimSuffix(recorded, truncatedSuffix))
+	}
+	return recorded == replayed
+}
+
+// stripMeta は応答の末尾のメタ情報を取り除く
+func stripMeta(message string) string {
+	if index := strings.LastIndex(message, metaSeparator); index >= 0 {
+		return message[:index]
+	}
+	return message
+}
+
+// copyProject は除外パターンに一致しない通常のファイルを dst に複製する
+func copyProject(src, dst string) error {
+	return ignore.Walk(src, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+		if info.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(path, target, info.Mode().Perm())
+	})
+}
+
+// copyFile はファイル1つを複製する
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
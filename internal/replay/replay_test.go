@@ -0,0 +1,182 @@
+package replay
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// scriptedProvider は用意した応答を順に返すテスト用プロバイダー
+type scriptedProvider struct {
+	responses []string
+	calls     int
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	content := p.responses[len(p.responses)-1]
+	if p.calls < len(p.responses) {
+		content = p.responses[p.calls]
+	}
+	p.calls++
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: content}, Done: true}, nil
+}
+
+func (p *scriptedProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *scriptedProvider) GetModelInfo(model string) (*llm.ModelInfo, error) {
+	return &llm.ModelInfo{Name: model}, nil
+}
+
+func (p *scriptedProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+func newManager(provider llm.Provider) (interactive.SessionManager, error) {
+	cfg := config.DefaultConfig()
+	cfg.SessionTitles.Enabled = false
+	return interactive.NewInteractiveSessionManager(contextmanager.NewSmartContextManager(), provider, nil, nil, nil, "test-model", cfg), nil
+}
+
+// recordSession はプロジェクトの中で実際にコマンドを実行するセッションを記録する
+func recordSession(t *testing.T, project string, responses []string, inputs ...string) (string, []logger.AuditEvent) {
+	t.Helper()
+	wd, _ := os.Getwd()
+	if err := os.Chdir(project); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	auditDir := t.TempDir()
+	recorder := logger.NewAuditRecorder(auditDir, 0)
+	previous := logger.SetAuditRecorder(recorder)
+	defer func() {
+		logger.SetAuditRecorder(previous)
+		recorder.Close()
+	}()
+
+	manager, _ := newManager(llm.NewAuditingProvider(&scriptedProvider{responses: responses}))
+	session, err := manager.CreateSession(interactive.CodingSessionTypeGeneral)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, input := range inputs {
+		if _, err := manager.ProcessUserInput(context.Background(), session.ID, input); err != nil {
+			t.Fatal(err)
+		}
+	}
+	events, err := logger.ReadAuditLog(auditDir, session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return session.ID, events
+}
+
+func TestReplayMatchesRecording(t *testing.T) {
+	project := t.TempDir()
+	if err := os.WriteFile(filepath.Join(project, "notes.txt"), []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	sessionID, events := recordSession(t, project, []string{
+		"Checking. <COMMAND>echo recorded-output && echo cleared > notes.txt</COMMAND>",
+		"Removed the notes file.",
+	}, "clean up the notes")
+	if data, _ := os.ReadFile(filepath.Join(project, "notes.txt")); string(data) != "cleared\n" {
+		t.Fatalf("the recorded session should have overwritten the file: %q", data)
+	}
+	if err := os.WriteFile(filepath.Join(project, "notes.txt"), []byte("original\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	runner := &Runner{NewManager: newManager, ProjectDir: project}
+	report, err := runner.Run(context.Background(), sessionID, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Diverged() || len(report.Turns) != 1 {
+		t.Fatalf("replay should match the recording: %+v", report.Turns)
+	}
+	turn := report.Turns[0]
+	if !strings.Contains(strings.Join(turn.Replayed, "\n"), "command: echo recorded-output && echo cleared > notes.txt") {
+		t.Errorf("command was not replayed: %v", turn.Replayed)
+	}
+	if !strings.Contains(turn.Message, "recorded-output") {
+		t.Errorf("recorded output was not returned: %q", turn.Message)
+	}
+	// 記録した出力を返すだけでコマンドは実行しない
+	if data, _ := os.ReadFile(filepath.Join(project, "notes.txt")); string(data) != "original\n" {
+		t.Errorf("replay must not touch the project: %q", data)
+	}
+}
+
+func TestReplayReportsDivergence(t *testing.T) {
+	sessionID, events := recordSession(t, t.TempDir(), []string{
+		"<COMMAND>echo first</COMMAND>",
+		"Done.",
+	}, "check the build")
+
+	// モデル応答を書き換えると記録にないコマンドを実行しようとする
+	for i, event := range events {
+		if event.Type == logger.AuditLLMResponse && strings.Contains(event.Content, "echo first") {
+			events[i].Content = "<COMMAND>echo second</COMMAND>"
+		}
+	}
+	report, err := (&Runner{NewManager: newManager}).Run(context.Background(), sessionID, events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !report.Diverged() {
+		t.Fatal("changed response should diverge")
+	}
+	divergences := strings.Join(report.Turns[0].Divergences, "\n")
+	for _, want := range []string{"command was not recorded: echo second", `recorded "command: echo first"`} {
+		if !strings.Contains(divergences, want) {
+			t.Errorf("missing divergence %q:\n%s", want, divergences)
+		}
+	}
+}
+
+func TestSplit(t *testing.T) {
+	events := []logger.AuditEvent{
+		{Type: logger.AuditBranch, Content: "before any input"},
+		{Type: logger.AuditUserInput, Content: "first"},
+		{Type: logger.AuditLLMResponse, Content: "reply", Success: true},
+		{Type: logger.AuditAssistant, Content: "reply\n\n---\n⏱️ **応答時間**: 5ms"},
+		{Type: logger.AuditFileWrite, Tool: "write", Path: "main.go", Success: true},
+		{Type: logger.AuditUserInput, Content: "second"},
+		{Type: logger.AuditToolCall, Tool: "bash", Command: "ls", Success: true},
+	}
+	turns := Split(events)
+	if len(turns) != 2 || turns[0].Input != "first" || len(turns[0].Events) != 3 {
+		t.Fatalf("unexpected turns: %+v", turns)
+	}
+	if !turns[0].Confirmed() || turns[1].Confirmed() {
+		t.Error("the write after the answer means the suggestion was applied")
+	}
+	if got := strings.Join(turns[0].Actions(), ","); got != "model: reply,wrote: main.go" {
+		t.Errorf("unexpected actions: %s", got)
+	}
+	if len(turns[1].Outputs()) != 1 || !sameAnswer(turns[0].Answer(), "reply\n\n---\n⏱️ **応答時間**: 9ms") {
+		t.Error("outputs or answer comparison is wrong")
+	}
+}
+
+func TestActionsOrdersStreamedCommandsAfterResponse(t *testing.T) {
+	turn := Turn{Events: []logger.AuditEvent{
+		{Type: logger.AuditLLMRequest},
+		{Type: logger.AuditCommand, Command: "go test ./...", Success: true},
+		{Type: logger.AuditLLMResponse, Content: "<COMMAND>go test ./...</COMMAND>", Success: true},
+		{Type: logger.AuditLLMRequest},
+		{Type: logger.AuditLLMResponse, Content: "done", Success: true},
+		{Type: logger.AuditFileWrite, Tool: "write", Path: "main.go", Success: true},
+	}}
+	want := "model: <COMMAND>go test ./...</COMMAND>,command: go test ./...,model: done,wrote: main.go"
+	if got := strings.Join(turn.Actions(), ","); got != want {
+		t.Errorf("actions = %s, want %s", got, want)
+	}
+}
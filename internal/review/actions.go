@@ -0,0 +1,97 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// WorkflowRun は GitHub Actions のワークフロー実行
+type WorkflowRun struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	HeadBranch string `json:"head_branch"`
+	HeadSHA    string `json:"head_sha"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// Job はワークフロー実行の1ジョブ
+type Job struct {
+	ID         int64  `json:"id"`
+	Name       string `json:"name"`
+	Conclusion string `json:"conclusion"`
+	HTMLURL    string `json:"html_url"`
+}
+
+// NewPullRequest はPRの作成内容
+type NewPullRequest struct {
+	Title string `json:"title"`
+	Head  string `json:"head"`
+	Base  string `json:"base"`
+	Body  string `json:"body"`
+	Draft bool   `json:"draft"`
+}
+
+// WorkflowRun はワークフロー実行の情報を取得
+func (c *GitHubClient) WorkflowRun(ctx context.Context, repo string, runID int64) (*WorkflowRun, error) {
+	body, err := c.do(ctx, "GET", fmt.Sprintf("/repos/%s/actions/runs/%d", repo, runID), "application/vnd.github+json", nil)
+	if err != nil {
+		return nil, err
+	}
+	var run WorkflowRun
+	if err := json.Unmarshal(body, &run); err != nil {
+		return nil, fmt.Errorf("ワークフロー実行の解析エラー: %w", err)
+	}
+	return &run, nil
+}
+
+// FailedJobs はワークフロー実行の失敗したジョブ（再実行があれば最新の試行）を返す
+func (c *GitHubClient) FailedJobs(ctx context.Context, repo string, runID int64) ([]Job, error) {
+	body, err := c.do(ctx, "GET", fmt.Sprintf("/repos/%s/actions/runs/%d/jobs?filter=latest&per_page=100", repo, runID), "application/vnd.github+json", nil)
+	if err != nil {
+		return nil, err
+	}
+	var list struct {
+		Jobs []Job `json:"jobs"`
+	}
+	if err := json.Unmarshal(body, &list); err != nil {
+		return nil, fmt.Errorf("ジョブ一覧の解析エラー: %w", err)
+	}
+	var failed []Job
+	for _, job := range list.Jobs {
+		if job.Conclusion == "failure" || job.Conclusion == "timed_out" {
+			failed = append(failed, job)
+		}
+	}
+	return failed, nil
+}
+
+// JobLog はジョブのログ（プレーンテキスト）を取得
+func (c *GitHubClient) JobLog(ctx context.Context, repo string, jobID int64) (string, error) {
+	body, err := c.do(ctx, "GET", fmt.Sprintf("/repos/%s/actions/jobs/%d/logs", repo, jobID), "application/vnd.github+json", nil)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// CreatePullRequest はPRを作成
+func (c *GitHubClient) CreatePullRequest(ctx context.Context, repo string, pr NewPullRequest) (*PullRequest, error) {
+	if c.Token == "" {
+		return nil, fmt.Errorf("GITHUB_TOKEN が設定されていません")
+	}
+	payload, err := json.Marshal(pr)
+	if err != nil {
+		return nil, err
+	}
+	body, err := c.do(ctx, "POST", fmt.Sprintf("/repos/%s/pulls", repo), "application/vnd.github+json", payload)
+	if err != nil {
+		return nil, err
+	}
+	var created PullRequest
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("PR作成結果の解析エラー: %w", err)
+	}
+	return &created, nil
+}
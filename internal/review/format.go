@@ -0,0 +1,109 @@
+package review
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// 重要度毎の表示色（ANSI 256色）
+var severityColors = map[string]string{
+	SeverityCritical: "\033[38;5;196m",
+	SeverityMajor:    "\033[38;5;214m",
+	SeverityMinor:    "\033[38;5;27m",
+	SeverityNit:      "\033[38;5;244m",
+}
+
+const colorReset = "\033[0m"
+
+// Summary は重要度毎の件数（例: "1 critical, 2 minor"）
+func (r *Report) Summary() string {
+	counts := make(map[string]int)
+	for _, finding := range r.Findings {
+		counts[finding.Severity]++
+	}
+	var parts []string
+	for _, severity := range ValidSeverities() {
+		if counts[severity] > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", counts[severity], severity))
+		}
+	}
+	if len(parts) == 0 {
+		return "no findings"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// WriteText はターミナル向けに指摘を書き出す
+func WriteText(w io.Writer, report *Report, color bool) {
+	paint := func(severity, text string) string {
+		if !color {
+			return text
+		}
+		return severityColors[severity] + text + colorReset
+	}
+
+	fmt.Fprintf(w, "Review of %s (%d files, %d chunks, model %s)\n\n", report.Source, report.Files, report.Chunks, report.Model)
+	for _, finding := range report.Findings {
+		fmt.Fprintf(w, "%s %s\n", paint(finding.Severity, fmt.Sprintf("[%s]", finding.Severity)), finding.Location())
+		fmt.Fprintf(w, "  %s\n", finding.Message)
+		if finding.Suggestion != "" {
+			fmt.Fprintf(w, "  → %s\n", finding.Suggestion)
+		}
+		fmt.Fprintln(w)
+	}
+	for _, failure := range report.Errors {
+		fmt.Fprintf(w, "%s %s\n", paint(SeverityCritical, "[error]"), failure)
+	}
+	if len(report.Skipped) > 0 {
+		fmt.Fprintf(w, "Skipped: %s\n", strings.Join(report.Skipped, ", "))
+	}
+	fmt.Fprintf(w, "Summary: %s\n", report.Summary())
+}
+
+// FormatMarkdown は指摘をファイル毎のMarkdownにまとめる
+func FormatMarkdown(report *Report) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Code review: %s\n\n", report.Source)
+	fmt.Fprintf(&b, "- Files: %d (%d chunks)\n- Model: %s\n- Summary: %s\n", report.Files, report.Chunks, report.Model, report.Summary())
+
+	var files []string
+	byFile := make(map[string][]Finding)
+	for _, finding := range report.Findings {
+		if _, ok := byFile[finding.File]; !ok {
+			files = append(files, finding.File)
+		}
+		byFile[finding.File] = append(byFile[finding.File], finding)
+	}
+	for _, file := range files {
+		fmt.Fprintf(&b, "\n## `%s`\n\n", file)
+		for _, finding := range byFile[file] {
+			b.WriteString(findingMarkdown(finding, true))
+		}
+	}
+
+	if len(report.Errors) > 0 {
+		b.WriteString("\n## Errors\n\n")
+		for _, failure := range report.Errors {
+			fmt.Fprintf(&b, "- %s\n", failure)
+		}
+	}
+	if len(report.Skipped) > 0 {
+		fmt.Fprintf(&b, "\n_Skipped: %s_\n", strings.Join(report.Skipped, ", "))
+	}
+	return b.String()
+}
+
+// findingMarkdown は指摘1件のMarkdown（location=false はインラインコメント用）
+func findingMarkdown(finding Finding, location bool) string {
+	var b strings.Builder
+	b.WriteString("- **" + finding.Severity + "**")
+	if location {
+		fmt.Fprintf(&b, " `%s`", finding.Location())
+	}
+	b.WriteString(" " + finding.Message + "\n")
+	if finding.Suggestion != "" {
+		fmt.Fprintf(&b, "  > %s\n", finding.Suggestion)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package review
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// DefaultBase は比較元のブランチ（main、なければ master）
+func DefaultBase(ctx context.Context, dir string) string {
+	for _, branch := range []string{"main", "master"} {
+		cmd := exec.CommandContext(ctx, "git", "rev-parse", "--verify", "--quiet", branch)
+		cmd.Dir = dir
+		if cmd.Run() == nil {
+			return branch
+		}
+	}
+	return "main"
+}
+
+// GitDiff は base...HEAD（base から分岐後の変更）の差分を取得
+func GitDiff(ctx context.Context, dir, base string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "diff", "--no-color", "--no-ext-diff", base+"...HEAD")
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("git diff %s...HEAD エラー: %s", base, strings.TrimSpace(string(output)))
+	}
+	return string(output), nil
+}
+
+// RemoteRepo は origin リモートから "owner/repo" を取得
+func RemoteRepo(ctx context.Context, dir string) (string, error) {
+	cmd := exec.CommandContext(ctx, "git", "remote", "get-url", "origin")
+	cmd.Dir = dir
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("originリモート取得エラー: %w", err)
+	}
+	return ParseRepo(string(output))
+}
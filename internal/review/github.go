@@ -0,0 +1,176 @@
+package review
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultGitHubAPI は GitHub REST API のベースURL
+const DefaultGitHubAPI = "https://api.github.com"
+
+// リモートURLから owner/repo を取り出すパターン（https・ssh形式）
+var githubRemotePattern = regexp.MustCompile(`github\.com[:/]([^/]+)/([^/]+?)(?:\.git)?/?$`)
+
+// GitHubClient はPRの差分取得とレビュー投稿を行う
+type GitHubClient struct {
+	BaseURL    string
+	Token      string
+	HTTPClient *http.Client
+}
+
+// PullRequest はレビューに必要なPRの情報
+type PullRequest struct {
+	Number  int    `json:"number"`
+	Title   string `json:"title"`
+	HTMLURL string `json:"html_url"`
+	Head    struct {
+		SHA string `json:"sha"`
+	} `json:"head"`
+}
+
+// NewGitHubClient は GITHUB_TOKEN（なければ GH_TOKEN）で認証するクライアントを作成
+func NewGitHubClient() *GitHubClient {
+	token := os.Getenv("GITHUB_TOKEN")
+	if token == "" {
+		token = os.Getenv("GH_TOKEN")
+	}
+	return &GitHubClient{
+		BaseURL:    DefaultGitHubAPI,
+		Token:      token,
+		HTTPClient: &http.Client{Timeout: 60 * time.Second},
+	}
+}
+
+// ParseRepo はリモートURLから "owner/repo" を取り出す
+func ParseRepo(remoteURL string) (string, error) {
+	match := githubRemotePattern.FindStringSubmatch(strings.TrimSpace(remoteURL))
+	if match == nil {
+		return "", fmt.Errorf("GitHubのリモートではありません: %s", remoteURL)
+	}
+	return match[1] + "/" + match[2], nil
+}
+
+// PullRequest はPRの情報を取得
+func (c *GitHubClient) PullRequest(ctx context.Context, repo string, number int) (*PullRequest, error) {
+	body, err := c.do(ctx, "GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, number), "application/vnd.github+json", nil)
+	if err != nil {
+		return nil, err
+	}
+	var pr PullRequest
+	if err := json.Unmarshal(body, &pr); err != nil {
+		return nil, fmt.Errorf("PR情報の解析エラー: %w", err)
+	}
+	return &pr, nil
+}
+
+// PullRequestDiff はPRの差分（unified diff）を取得
+func (c *GitHubClient) PullRequestDiff(ctx context.Context, repo string, number int) (string, error) {
+	body, err := c.do(ctx, "GET", fmt.Sprintf("/repos/%s/pulls/%d", repo, number), "application/vnd.github.v3.diff", nil)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+// reviewComment はPRレビューの行コメント
+type reviewComment struct {
+	Path string `json:"path"`
+	Line int    `json:"line"`
+	Side string `json:"side"`
+	Body string `json:"body"`
+}
+
+// PostReview は差分内の指摘を行コメント、それ以外を本文としてPRレビューを投稿し、レビューのURLを返す
+func (c *GitHubClient) PostReview(ctx context.Context, repo string, pr *PullRequest, report *Report) (string, error) {
+	if c.Token == "" {
+		return "", fmt.Errorf("GITHUB_TOKEN が設定されていません")
+	}
+
+	var comments []reviewComment
+	var outside []Finding
+	for _, finding := range report.Findings {
+		if !finding.InDiff {
+			outside = append(outside, finding)
+			continue
+		}
+		comments = append(comments, reviewComment{
+			Path: finding.File,
+			Line: finding.Line,
+			Side: "RIGHT",
+			Body: strings.TrimPrefix(findingMarkdown(finding, false), "- "),
+		})
+	}
+
+	var body strings.Builder
+	fmt.Fprintf(&body, "**vyb review** (%s): %s\n", report.Model, report.Summary())
+	if len(outside) > 0 {
+		body.WriteString("\n")
+		for _, finding := range outside {
+			body.WriteString(findingMarkdown(finding, true))
+		}
+	}
+
+	payload, err := json.Marshal(map[string]interface{}{
+		"commit_id": pr.Head.SHA,
+		"event":     "COMMENT",
+		"body":      body.String(),
+		"comments":  comments,
+	})
+	if err != nil {
+		return "", err
+	}
+	respBody, err := c.do(ctx, "POST", fmt.Sprintf("/repos/%s/pulls/%d/reviews", repo, pr.Number), "application/vnd.github+json", payload)
+	if err != nil {
+		return "", err
+	}
+	var created struct {
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.Unmarshal(respBody, &created); err != nil {
+		return "", fmt.Errorf("レビュー投稿結果の解析エラー: %w", err)
+	}
+	return created.HTMLURL, nil
+}
+
+// do はAPIリクエストを送信し、2xx以外はエラーにする
+func (c *GitHubClient) do(ctx context.Context, method, path, accept string, payload []byte) ([]byte, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, strings.TrimSuffix(c.BaseURL, "/")+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("リクエスト作成エラー: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+	}
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API接続エラー: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("GitHub API応答読み込みエラー: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("GitHub API エラー (%s %s): status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return body, nil
+}
@@ -0,0 +1,301 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+// 指摘の重要度（重い順）
+const (
+	SeverityCritical = "critical" // バグ・セキュリティ問題・データ破損
+	SeverityMajor    = "major"    // 誤動作の恐れ・設計上の問題
+	SeverityMinor    = "minor"    // 保守性・エラー処理の改善
+	SeverityNit      = "nit"      // 命名・スタイル
+)
+
+// DefaultMaxChunkBytes は1回のレビュー要求に含める差分の上限（超えるファイルはハンク単位で分割）
+const DefaultMaxChunkBytes = 12000
+
+// レビュー対象外のファイル（生成物・ロックファイル）
+var skippedFiles = map[string]bool{
+	"go.sum":            true,
+	"package-lock.json": true,
+	"yarn.lock":         true,
+	"pnpm-lock.yaml":    true,
+	"Cargo.lock":        true,
+	"poetry.lock":       true,
+}
+
+// ValidSeverities は重要度を重い順に返す
+func ValidSeverities() []string {
+	return []string{SeverityCritical, SeverityMajor, SeverityMinor, SeverityNit}
+}
+
+// SeverityRank は重要度の順位（重いほど大きい、不明なら0）
+func SeverityRank(severity string) int {
+	switch severity {
+	case SeverityCritical:
+		return 4
+	case SeverityMajor:
+		return 3
+	case SeverityMinor:
+		return 2
+	case SeverityNit:
+		return 1
+	}
+	return 0
+}
+
+// Finding はレビューの指摘1件
+type Finding struct {
+	Severity   string `json:"severity"`
+	File       string `json:"file"`
+	Line       int    `json:"line,omitempty"`
+	Message    string `json:"message"`
+	Suggestion string `json:"suggestion,omitempty"`
+	InDiff     bool   `json:"in_diff"` // 行が差分に含まれる（PRにインラインコメントできる）
+}
+
+// Location は "file:line" 形式の位置
+func (f Finding) Location() string {
+	if f.Line > 0 {
+		return fmt.Sprintf("%s:%d", f.File, f.Line)
+	}
+	return f.File
+}
+
+// Chunk はレビュー要求1回分の差分
+type Chunk struct {
+	File  *diff.FileDiff
+	Part  int // 分割した場合の番号（1始まり）
+	Parts int
+	Patch string
+}
+
+// Report はレビュー結果
+type Report struct {
+	Source   string    `json:"source"` // 例: main...HEAD, PR #12
+	Model    string    `json:"model"`
+	Files    int       `json:"files"`
+	Chunks   int       `json:"chunks"`
+	Skipped  []string  `json:"skipped,omitempty"` // 対象外としたファイル
+	Findings []Finding `json:"findings"`
+	Errors   []string  `json:"errors,omitempty"` // レビューに失敗したチャンク
+}
+
+// CountAtLeast は指定した重要度以上の指摘の数
+func (r *Report) CountAtLeast(severity string) int {
+	count := 0
+	for _, finding := range r.Findings {
+		if SeverityRank(finding.Severity) >= SeverityRank(severity) {
+			count++
+		}
+	}
+	return count
+}
+
+// Filter は指定した重要度以上の指摘のみを残す
+func (r *Report) Filter(minSeverity string) {
+	kept := r.Findings[:0]
+	for _, finding := range r.Findings {
+		if SeverityRank(finding.Severity) >= SeverityRank(minSeverity) {
+			kept = append(kept, finding)
+		}
+	}
+	r.Findings = kept
+}
+
+// Chunks は差分をファイル毎（大きいファイルはハンク単位）のレビュー単位に分割
+func Chunks(files []*diff.FileDiff, maxBytes int) ([]Chunk, []string) {
+	if maxBytes <= 0 {
+		maxBytes = DefaultMaxChunkBytes
+	}
+
+	var chunks []Chunk
+	var skipped []string
+	for _, file := range files {
+		if file.Binary || file.IsDelete || len(file.Hunks) == 0 || skippedFiles[filepath.Base(file.Path)] {
+			skipped = append(skipped, file.Path)
+			continue
+		}
+
+		var parts []string
+		var current strings.Builder
+		for _, hunk := range file.Hunks {
+			if current.Len() > 0 && current.Len()+len(hunk) > maxBytes {
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			current.WriteString(hunk)
+		}
+		parts = append(parts, current.String())
+
+		for i, part := range parts {
+			chunks = append(chunks, Chunk{File: file, Part: i + 1, Parts: len(parts), Patch: part})
+		}
+	}
+	return chunks, skipped
+}
+
+// Reviewer はレビュー用プロンプトでチャンク毎にモデルへ問い合わせる
+type Reviewer struct {
+	Provider llm.Provider
+	Model    string
+	Language string // 指摘の記述言語（ja, en）
+	Memory   string // プロジェクトメモリ（VYB.md）
+	Registry *prompts.Registry
+	MaxBytes int
+
+	// Progress はチャンクのレビュー開始時に呼ばれる（nil可）
+	Progress func(chunk Chunk, index, total int)
+}
+
+// Review は差分全体をレビューし、指摘を重要度・ファイル・行の順に並べて返す
+func (r *Reviewer) Review(ctx context.Context, source string, files []*diff.FileDiff) (*Report, error) {
+	chunks, skipped := Chunks(files, r.MaxBytes)
+	report := &Report{
+		Source:   source,
+		Model:    r.Model,
+		Files:    len(files) - len(skipped),
+		Chunks:   len(chunks),
+		Skipped:  skipped,
+		Findings: []Finding{},
+	}
+
+	for i, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+		if r.Progress != nil {
+			r.Progress(chunk, i, len(chunks))
+		}
+		findings, err := r.reviewChunk(ctx, chunk)
+		if err != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", chunk.File.Path, err))
+			continue
+		}
+		report.Findings = append(report.Findings, findings...)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if SeverityRank(a.Severity) != SeverityRank(b.Severity) {
+			return SeverityRank(a.Severity) > SeverityRank(b.Severity)
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Line < b.Line
+	})
+	return report, nil
+}
+
+// reviewChunk は1チャンクをレビューして指摘を返す
+func (r *Reviewer) reviewChunk(ctx context.Context, chunk Chunk) ([]Finding, error) {
+	registry := r.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	currentFile := chunk.File.Path
+	if chunk.Parts > 1 {
+		currentFile = fmt.Sprintf("%s (part %d/%d)", chunk.File.Path, chunk.Part, chunk.Parts)
+	}
+	prompt, err := registry.Render(prompts.TemplateReview, prompts.Data{
+		SessionType: "review",
+		Language:    r.Language,
+		ModelFamily: prompts.ModelFamily(r.Model),
+		Memory:      r.Memory,
+		CurrentFile: currentFile,
+		Input:       chunk.Patch,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	temperature := 0.2
+	resp, err := r.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       r.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ParseFindings(resp.Message.Content, chunk.File)
+}
+
+// rawFinding はモデルが返す指摘（行番号が文字列の場合もある）
+type rawFinding struct {
+	Severity   string      `json:"severity"`
+	File       string      `json:"file"`
+	Line       interface{} `json:"line"`
+	Message    string      `json:"message"`
+	Suggestion string      `json:"suggestion"`
+}
+
+// ParseFindings はモデルの回答からJSON配列の指摘を取り出し、重要度・ファイル・行を正規化する
+func ParseFindings(content string, file *diff.FileDiff) ([]Finding, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("応答に指摘のJSON配列がありません")
+	}
+
+	var raw []rawFinding
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("指摘のJSON解析エラー: %w", err)
+	}
+
+	findings := make([]Finding, 0, len(raw))
+	for _, item := range raw {
+		message := strings.TrimSpace(item.Message)
+		if message == "" {
+			continue
+		}
+		finding := Finding{
+			Severity:   normalizeSeverity(item.Severity),
+			File:       file.Path,
+			Line:       parseLine(item.Line),
+			Message:    message,
+			Suggestion: strings.TrimSpace(item.Suggestion),
+		}
+		finding.InDiff = finding.Line > 0 && file.HasLine(finding.Line)
+		findings = append(findings, finding)
+	}
+	return findings, nil
+}
+
+// normalizeSeverity はモデルが使いがちな別名を4段階に寄せる
+func normalizeSeverity(severity string) string {
+	switch strings.ToLower(strings.TrimSpace(severity)) {
+	case "critical", "blocker", "security":
+		return SeverityCritical
+	case "major", "high", "error", "bug":
+		return SeverityMajor
+	case "nit", "low", "info", "style", "trivial":
+		return SeverityNit
+	default:
+		return SeverityMinor
+	}
+}
+
+func parseLine(value interface{}) int {
+	switch v := value.(type) {
+	case float64:
+		return int(v)
+	case string:
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil {
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,240 @@
+package review
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+)
+
+const testPatch = `diff --git a/app.go b/app.go
+--- a/app.go
++++ b/app.go
+@@ -10,3 +10,4 @@ func run() {
+ 	x := load()
+-	use(x)
++	if x != nil {
++		use(x)
+ 	}
+diff --git a/go.sum b/go.sum
+--- a/go.sum
++++ b/go.sum
+@@ -1 +1 @@
+-a v1
++a v2
+`
+
+// scriptedProvider はファイル名に応じた回答を返すテスト用プロバイダー
+type scriptedProvider struct {
+	answers map[string]string
+	prompts []string
+}
+
+func (p *scriptedProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	prompt := req.Messages[0].Content
+	p.prompts = append(p.prompts, prompt)
+	for file, answer := range p.answers {
+		if strings.Contains(prompt, "File: "+file) {
+			return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: answer}, Done: true}, nil
+		}
+	}
+	return nil, fmt.Errorf("unexpected prompt")
+}
+
+func (p *scriptedProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *scriptedProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *scriptedProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+func TestParseFindings(t *testing.T) {
+	file := diff.ParseFiles(testPatch)[0]
+	answer := "Here you go:\n```json\n[{\"severity\":\"HIGH\",\"line\":\"12\",\"message\":\"nil check hides error\",\"suggestion\":\"return the error\"},{\"severity\":\"style\",\"line\":99,\"message\":\"rename\"},{\"severity\":\"major\",\"message\":\"\"}]\n```"
+
+	findings, err := ParseFindings(answer, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(findings) != 2 {
+		t.Fatalf("空の指摘は除くはず: %+v", findings)
+	}
+	if f := findings[0]; f.Severity != SeverityMajor || f.Line != 12 || !f.InDiff || f.File != "app.go" {
+		t.Errorf("正規化: %+v", f)
+	}
+	if f := findings[1]; f.Severity != SeverityNit || f.InDiff {
+		t.Errorf("差分外の行: %+v", f)
+	}
+
+	if _, err := ParseFindings("looks good", file); err == nil {
+		t.Error("JSON配列がなければエラーのはず")
+	}
+}
+
+func TestChunksSplitsLargeFiles(t *testing.T) {
+	files := diff.ParseFiles(testPatch)
+	chunks, skipped := Chunks(files, 0)
+	if len(chunks) != 1 || chunks[0].File.Path != "app.go" {
+		t.Fatalf("チャンク: %+v", chunks)
+	}
+	if len(skipped) != 1 || skipped[0] != "go.sum" {
+		t.Errorf("ロックファイルは対象外: %v", skipped)
+	}
+
+	big := &diff.FileDiff{Path: "big.go", Hunks: []string{strings.Repeat("a", 60), strings.Repeat("b", 60), strings.Repeat("c", 10)}}
+	chunks, _ = Chunks([]*diff.FileDiff{big}, 100)
+	if len(chunks) != 2 || chunks[0].Parts != 2 || chunks[1].Part != 2 || len(chunks[1].Patch) != 70 {
+		t.Errorf("ハンク単位で分割するはず: %d chunks", len(chunks))
+	}
+}
+
+func TestReviewerReview(t *testing.T) {
+	provider := &scriptedProvider{answers: map[string]string{
+		"app.go": `[{"severity":"nit","line":11,"message":"naming"},{"severity":"critical","line":12,"message":"panic on nil"}]`,
+	}}
+	reviewer := &Reviewer{Provider: provider, Model: "qwen2.5-coder:14b", Language: "en", Registry: prompts.NewRegistry("")}
+
+	report, err := reviewer.Review(context.Background(), "main...HEAD", diff.ParseFiles(testPatch))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Files != 1 || report.Chunks != 1 || len(report.Errors) != 0 {
+		t.Fatalf("レポート: %+v", report)
+	}
+	if report.Findings[0].Severity != SeverityCritical {
+		t.Errorf("重要度順に並ぶはず: %+v", report.Findings)
+	}
+	if report.CountAtLeast(SeverityMajor) != 1 || report.Summary() != "1 critical, 1 nit" {
+		t.Errorf("集計: %d, %s", report.CountAtLeast(SeverityMajor), report.Summary())
+	}
+	if !strings.Contains(provider.prompts[0], "+\t\tuse(x)") || !strings.Contains(provider.prompts[0], "JSON array") {
+		t.Errorf("プロンプトに差分と出力形式が含まれていない:\n%s", provider.prompts[0])
+	}
+
+	markdown := FormatMarkdown(report)
+	if !strings.Contains(markdown, "## `app.go`") || !strings.Contains(markdown, "**critical** `app.go:12` panic on nil") {
+		t.Errorf("Markdown:\n%s", markdown)
+	}
+}
+
+func TestParseRepo(t *testing.T) {
+	for _, remote := range []string{"git@github.com:glkt3912/vyb-code.git", "https://github.com/glkt3912/vyb-code", "https://github.com/glkt3912/vyb-code.git\n"} {
+		if repo, err := ParseRepo(remote); err != nil || repo != "glkt3912/vyb-code" {
+			t.Errorf("ParseRepo(%q) = %q, %v", remote, repo, err)
+		}
+	}
+	if _, err := ParseRepo("https://gitlab.com/a/b.git"); err == nil {
+		t.Error("GitHub以外はエラーのはず")
+	}
+}
+
+func TestGitHubClientPostReview(t *testing.T) {
+	var posted map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == "GET" && r.Header.Get("Accept") == "application/vnd.github.v3.diff":
+			io.WriteString(w, testPatch)
+		case r.Method == "GET":
+			io.WriteString(w, `{"number":7,"title":"t","head":{"sha":"abc123"}}`)
+		case r.Method == "POST" && r.URL.Path == "/repos/o/r/pulls/7/reviews":
+			json.NewDecoder(r.Body).Decode(&posted)
+			io.WriteString(w, `{"html_url":"https://github.com/o/r/pull/7#review"}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := &GitHubClient{BaseURL: server.URL, Token: "token", HTTPClient: server.Client()}
+	pr, err := client.PullRequest(context.Background(), "o/r", 7)
+	if err != nil || pr.Head.SHA != "abc123" {
+		t.Fatalf("PR: %+v, %v", pr, err)
+	}
+	patch, err := client.PullRequestDiff(context.Background(), "o/r", 7)
+	if err != nil || len(diff.ParseFiles(patch)) != 2 {
+		t.Fatalf("差分: %v", err)
+	}
+
+	report := &Report{Model: "m", Findings: []Finding{
+		{Severity: SeverityMajor, File: "app.go", Line: 12, Message: "inline", InDiff: true},
+		{Severity: SeverityMinor, File: "app.go", Line: 40, Message: "outside"},
+	}}
+	url, err := client.PostReview(context.Background(), "o/r", pr, report)
+	if err != nil || !strings.HasSuffix(url, "#review") {
+		t.Fatalf("投稿: %s, %v", url, err)
+	}
+	comments := posted["comments"].([]interface{})
+	if len(comments) != 1 || posted["commit_id"] != "abc123" {
+		t.Errorf("差分内の指摘のみ行コメントにするはず: %+v", posted)
+	}
+	if !strings.Contains(posted["body"].(string), "`app.go:40` outside") {
+		t.Errorf("差分外の指摘は本文に含めるはず: %s", posted["body"])
+	}
+
+	client.Token = ""
+	if _, err := client.PostReview(context.Background(), "o/r", pr, report); err == nil {
+		t.Error("トークンなしはエラーのはず")
+	}
+}
+
+func TestGitHubClientActions(t *testing.T) {
+	var created map[string]interface{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/repos/o/r/actions/runs/42", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id":42,"name":"CI","head_branch":"main","head_sha":"abc123"}`)
+	})
+	mux.HandleFunc("/repos/o/r/actions/runs/42/jobs", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"jobs":[{"id":1,"name":"lint","conclusion":"success"},{"id":2,"name":"test","conclusion":"failure"}]}`)
+	})
+	mux.HandleFunc("/repos/o/r/actions/jobs/2/logs", func(w http.ResponseWriter, r *http.Request) {
+		// 実際の API はログの保存先へリダイレクトする
+		http.Redirect(w, r, "/storage/job-2.txt", http.StatusFound)
+	})
+	mux.HandleFunc("/storage/job-2.txt", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "--- FAIL: TestLogin (0.00s)\n")
+	})
+	mux.HandleFunc("/repos/o/r/pulls", func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&created)
+		io.WriteString(w, `{"number":8,"html_url":"https://github.com/o/r/pull/8"}`)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	ctx := context.Background()
+	client := &GitHubClient{BaseURL: server.URL, Token: "token", HTTPClient: server.Client()}
+	run, err := client.WorkflowRun(ctx, "o/r", 42)
+	if err != nil || run.HeadBranch != "main" {
+		t.Fatalf("実行: %+v, %v", run, err)
+	}
+	jobs, err := client.FailedJobs(ctx, "o/r", 42)
+	if err != nil || len(jobs) != 1 || jobs[0].Name != "test" {
+		t.Fatalf("失敗したジョブのみ返すはず: %+v, %v", jobs, err)
+	}
+	log, err := client.JobLog(ctx, "o/r", jobs[0].ID)
+	if err != nil || !strings.Contains(log, "TestLogin") {
+		t.Fatalf("リダイレクト先のログを返すはず: %q, %v", log, err)
+	}
+
+	pr, err := client.CreatePullRequest(ctx, "o/r", NewPullRequest{Title: "Fix failing test TestLogin", Head: "vyb/ci-fix/run-42", Base: "main", Draft: true})
+	if err != nil || pr.Number != 8 {
+		t.Fatalf("PR作成: %+v, %v", pr, err)
+	}
+	if created["head"] != "vyb/ci-fix/run-42" || created["base"] != "main" || created["draft"] != true {
+		t.Errorf("作成内容: %+v", created)
+	}
+	client.Token = ""
+	if _, err := client.CreatePullRequest(ctx, "o/r", NewPullRequest{}); err == nil {
+		t.Error("トークンなしはエラーのはず")
+	}
+}
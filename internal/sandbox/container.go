@@ -0,0 +1,106 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// コンテナ内のワークスペースマウント先
+const containerWorkspace = "/workspace"
+
+// ContainerBackend はDocker/Podmanコンテナ内でコマンドを実行するバックエンド
+type ContainerBackend struct {
+	runtime string               // コンテナランタイム（docker, podman）
+	config  config.SandboxConfig // サンドボックス設定
+}
+
+// NewContainerBackend は新しいコンテナ実行バックエンドを作成
+func NewContainerBackend(runtime string, cfg config.SandboxConfig) *ContainerBackend {
+	return &ContainerBackend{
+		runtime: runtime,
+		config:  cfg,
+	}
+}
+
+// Name はバックエンド名を返す
+func (c *ContainerBackend) Name() string {
+	return c.runtime
+}
+
+// Command はコンテナ実行用のコマンドを構築
+func (c *ContainerBackend) Command(ctx context.Context, command string, workDir string) (*exec.Cmd, error) {
+	if err := c.Available(); err != nil {
+		return nil, err
+	}
+
+	args, err := c.BuildArgs(command, workDir)
+	if err != nil {
+		return nil, err
+	}
+
+	return exec.CommandContext(ctx, c.runtime, args...), nil
+}
+
+// BuildArgs はコンテナランタイムへ渡す引数を構築
+func (c *ContainerBackend) BuildArgs(command string, workDir string) ([]string, error) {
+	if c.config.Image == "" {
+		return nil, fmt.Errorf("サンドボックスのコンテナイメージが設定されていません")
+	}
+
+	absWorkDir, err := filepath.Abs(workDir)
+	if err != nil {
+		return nil, fmt.Errorf("作業ディレクトリの解決に失敗: %w", err)
+	}
+
+	// ワークスペースのマウント指定
+	mount := absWorkDir + ":" + containerWorkspace
+	if c.config.ReadOnlyWorkspace {
+		mount += ":ro"
+	}
+
+	// ルートファイルシステムは読み取り専用、/tmpのみ書き込み可、権限昇格を禁止
+	args := []string{
+		"run", "--rm", "-i",
+		"--read-only",
+		"--tmpfs", "/tmp",
+		"--cap-drop", "ALL",
+		"--security-opt", "no-new-privileges",
+		"-v", mount,
+		"-w", containerWorkspace,
+	}
+
+	// ネットワーク制限
+	if !c.config.AllowNetwork {
+		args = append(args, "--network", "none")
+	}
+
+	// リソース制限
+	if c.config.MemoryLimit != "" {
+		args = append(args, "--memory", c.config.MemoryLimit)
+	}
+	if c.config.CPULimit != "" {
+		args = append(args, "--cpus", c.config.CPULimit)
+	}
+
+	args = append(args, c.config.ExtraArgs...)
+	args = append(args, c.config.Image, "sh", "-c", command)
+
+	return args, nil
+}
+
+// Available はコンテナランタイムの存在を確認
+func (c *ContainerBackend) Available() error {
+	if _, err := exec.LookPath(c.runtime); err != nil {
+		return fmt.Errorf("コンテナランタイム '%s' が見つかりません: %w", c.runtime, err)
+	}
+	return nil
+}
+
+// IsSandboxed はコンテナ実行のため常にtrue
+func (c *ContainerBackend) IsSandboxed() bool {
+	return true
+}
@@ -0,0 +1,39 @@
+package sandbox
+
+import (
+	"context"
+	"os/exec"
+)
+
+// HostBackend はホスト上でコマンドを直接実行するバックエンド
+type HostBackend struct {
+	shell string // 使用するシェル
+}
+
+// NewHostBackend は新しいホスト実行バックエンドを作成
+func NewHostBackend() *HostBackend {
+	return &HostBackend{shell: "bash"}
+}
+
+// Name はバックエンド名を返す
+func (h *HostBackend) Name() string {
+	return ModeHost
+}
+
+// Command はホストシェル経由でコマンドを構築
+func (h *HostBackend) Command(ctx context.Context, command string, workDir string) (*exec.Cmd, error) {
+	cmd := exec.CommandContext(ctx, h.shell, "-c", command)
+	cmd.Dir = workDir
+	return cmd, nil
+}
+
+// Available はシェルの存在を確認
+func (h *HostBackend) Available() error {
+	_, err := exec.LookPath(h.shell)
+	return err
+}
+
+// IsSandboxed はホスト実行のため常にfalse
+func (h *HostBackend) IsSandboxed() bool {
+	return false
+}
@@ -0,0 +1,59 @@
+package sandbox
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// 実行バックエンドのモード定義
+const (
+	ModeHost   = "host"   // ホスト上で直接実行
+	ModeDocker = "docker" // Dockerコンテナ内で実行
+	ModePodman = "podman" // Podmanコンテナ内で実行
+)
+
+// Backend はシェルコマンドの実行環境を抽象化するインターフェース
+type Backend interface {
+	// Name はバックエンド名を返す
+	Name() string
+
+	// Command は指定コマンドを実行するための *exec.Cmd を構築する
+	Command(ctx context.Context, command string, workDir string) (*exec.Cmd, error)
+
+	// Available はバックエンドが利用可能かチェックする
+	Available() error
+
+	// IsSandboxed はファイルシステム・ネットワークが制限されているかを返す
+	IsSandboxed() bool
+}
+
+// New は設定に応じた実行バックエンドを作成
+func New(cfg config.SandboxConfig) (Backend, error) {
+	switch strings.ToLower(cfg.Mode) {
+	case "", ModeHost:
+		return NewHostBackend(), nil
+	case ModeDocker, ModePodman:
+		return NewContainerBackend(strings.ToLower(cfg.Mode), cfg), nil
+	default:
+		return nil, fmt.Errorf("未対応のサンドボックスモードです: %s（有効な値: %v）", cfg.Mode, ValidModes())
+	}
+}
+
+// ValidModes は有効なサンドボックスモード一覧を返す
+func ValidModes() []string {
+	return []string{ModeHost, ModeDocker, ModePodman}
+}
+
+// IsValidMode はモード名が有効かチェック
+func IsValidMode(mode string) bool {
+	for _, valid := range ValidModes() {
+		if strings.ToLower(mode) == valid {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,110 @@
+package sandbox
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// TestNewBackend は設定に応じたバックエンド生成をテストする
+func TestNewBackend(t *testing.T) {
+	tests := []struct {
+		mode        string
+		expectName  string
+		expectError bool
+	}{
+		{mode: "", expectName: ModeHost},
+		{mode: "host", expectName: ModeHost},
+		{mode: "docker", expectName: ModeDocker},
+		{mode: "Podman", expectName: ModePodman},
+		{mode: "landlock", expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			cfg := config.DefaultSandboxConfig()
+			cfg.Mode = tt.mode
+
+			backend, err := New(cfg)
+			if tt.expectError {
+				if err == nil {
+					t.Error("期待されるエラーが発生しませんでした")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("予期しないエラー: %v", err)
+			}
+			if backend.Name() != tt.expectName {
+				t.Errorf("期待値: %s, 実際値: %s", tt.expectName, backend.Name())
+			}
+		})
+	}
+}
+
+// TestHostBackendCommand はホスト実行をテストする
+func TestHostBackendCommand(t *testing.T) {
+	backend := NewHostBackend()
+	if backend.IsSandboxed() {
+		t.Error("ホストバックエンドはサンドボックス扱いであってはいけません")
+	}
+
+	cmd, err := backend.Command(context.Background(), "echo hello", t.TempDir())
+	if err != nil {
+		t.Fatalf("コマンド構築エラー: %v", err)
+	}
+
+	output, err := cmd.Output()
+	if err != nil {
+		t.Skipf("bashが利用できない環境: %v", err)
+	}
+	if strings.TrimSpace(string(output)) != "hello" {
+		t.Errorf("期待値: hello, 実際値: %s", output)
+	}
+}
+
+// TestContainerBackendBuildArgs はコンテナ引数の構築をテストする
+func TestContainerBackendBuildArgs(t *testing.T) {
+	cfg := config.DefaultSandboxConfig()
+	cfg.Mode = ModeDocker
+	cfg.ReadOnlyWorkspace = true
+
+	backend := NewContainerBackend(ModeDocker, cfg)
+	if !backend.IsSandboxed() {
+		t.Error("コンテナバックエンドはサンドボックス扱いであるべきです")
+	}
+
+	args, err := backend.BuildArgs("go test ./...", "/project")
+	if err != nil {
+		t.Fatalf("引数構築エラー: %v", err)
+	}
+	joined := strings.Join(args, " ")
+
+	expected := []string{
+		"--network none",
+		"-v /project:/workspace:ro",
+		"--cap-drop ALL",
+		"--memory 1g",
+		cfg.Image + " sh -c go test ./...",
+	}
+	for _, want := range expected {
+		if !strings.Contains(joined, want) {
+			t.Errorf("引数に '%s' が含まれていません: %s", want, joined)
+		}
+	}
+
+	// ネットワーク許可時は --network none を付与しない
+	cfg.AllowNetwork = true
+	args, _ = NewContainerBackend(ModeDocker, cfg).BuildArgs("ls", "/project")
+	if strings.Contains(strings.Join(args, " "), "--network none") {
+		t.Error("ネットワーク許可時に --network none が付与されています")
+	}
+
+	// イメージ未設定はエラー
+	cfg.Image = ""
+	if _, err := NewContainerBackend(ModeDocker, cfg).BuildArgs("ls", "/project"); err == nil {
+		t.Error("イメージ未設定でエラーが発生しませんでした")
+	}
+}
@@ -0,0 +1,151 @@
+package scaffold
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"gopkg.in/yaml.v3"
+)
+
+// FileChange は生成した設定ファイルの変更（適用前に差分を確認できる）
+type FileChange struct {
+	Path   string `json:"path"`
+	Target Target `json:"target"`
+	Before string `json:"-"`
+	After  string `json:"-"`
+}
+
+// Diff は変更の unified diff
+func (c *FileChange) Diff() string {
+	return diff.Unified("a/"+filepath.ToSlash(c.Path), "b/"+filepath.ToSlash(c.Path), c.Before, c.After, 3)
+}
+
+// Apply は変更をファイルに書き込む（.github/workflows 等のディレクトリは作成する）
+func (c *FileChange) Apply() error {
+	if err := os.MkdirAll(filepath.Dir(c.Path), 0755); err != nil {
+		return fmt.Errorf("ディレクトリ作成エラー: %w", err)
+	}
+	if err := os.WriteFile(c.Path, []byte(c.After), 0644); err != nil {
+		return fmt.Errorf("ファイル書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// Generator はLLMでCI設定・Makefileを生成する
+type Generator struct {
+	Provider llm.Provider
+	Model    string
+	Language string
+	Memory   string // プロジェクトメモリ（VYB.md）
+	Registry *prompts.Registry
+}
+
+// Generate はプロジェクトの情報から生成対象の設定を作り、変更（未適用）を返す（変更なしなら nil）
+// 既存の設定があれば内容を保ちつつ更新させる
+func (g *Generator) Generate(ctx context.Context, project *Project, target Target) (*FileChange, error) {
+	path := filepath.Join(project.Dir, target.Path())
+	existing, _ := os.ReadFile(path)
+
+	registry := g.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	prompt, err := registry.Render(prompts.TemplateScaffold, prompts.Data{
+		SessionType: "scaffold",
+		Language:    g.Language,
+		ModelFamily: prompts.ModelFamily(g.Model),
+		Memory:      g.Memory,
+		Intent:      target.Label(),
+		Context:     project.Summary(),
+		LastOutput:  string(existing),
+		TargetFile:  filepath.ToSlash(target.Path()),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	temperature := 0.2
+	resp, err := g.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       g.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("設定生成エラー: %w", err)
+	}
+
+	content, err := Normalize(target, resp.Message.Content)
+	if err != nil {
+		return nil, err
+	}
+	if content == "" || content == string(existing) {
+		return nil, nil
+	}
+	return &FileChange{Path: path, Target: target, Before: string(existing), After: content}, nil
+}
+
+// Normalize はモデルの回答から設定ファイルの内容を取り出して検証する
+// YAML は構文を確認し、Makefile はスペースで字下げされたレシピ行をタブに直す
+func Normalize(target Target, answer string) (string, error) {
+	content := unwrapMarkdown(answer)
+	if content == "" {
+		return "", nil
+	}
+	switch target {
+	case TargetGitHub, TargetGitLab:
+		var document map[string]interface{}
+		if err := yaml.Unmarshal([]byte(content), &document); err != nil {
+			return "", fmt.Errorf("生成された %s のYAMLが不正です: %w", target.Path(), err)
+		}
+		if len(document) == 0 {
+			return "", fmt.Errorf("生成された %s が空です", target.Path())
+		}
+	case TargetMakefile:
+		content = tabIndentRecipes(content)
+	}
+	return content, nil
+}
+
+// codeBlockPattern は回答中の最初のコードブロック
+var codeBlockPattern = regexp.MustCompile("(?s)```[A-Za-z0-9_-]*\\n(.*?)\\n?```")
+
+// unwrapMarkdown は回答がコードブロックを含めばその中身を、含まなければ全体を返す
+func unwrapMarkdown(answer string) string {
+	content := strings.TrimSpace(answer)
+	if match := codeBlockPattern.FindStringSubmatch(content); match != nil {
+		content = strings.TrimSpace(match[1])
+	}
+	if content == "" {
+		return ""
+	}
+	return content + "\n"
+}
+
+// makeRuleLine はMakefileのルール行（ターゲット: 依存）
+var makeRuleLine = regexp.MustCompile(`^[^\s#=][^=]*:([^=]|$)`)
+
+// tabIndentRecipes はルールに続くスペース字下げの行をタブ字下げに直す（make はタブ以外を受け付けない）
+func tabIndentRecipes(content string) string {
+	lines := strings.Split(content, "\n")
+	inRecipe := false
+	for i, line := range lines {
+		switch {
+		case makeRuleLine.MatchString(line):
+			inRecipe = true
+		case strings.TrimSpace(line) == "":
+			inRecipe = false
+		case inRecipe && strings.HasPrefix(line, " "):
+			lines[i] = "\t" + strings.TrimLeft(line, " ")
+		case !strings.HasPrefix(line, "\t"):
+			inRecipe = false
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,280 @@
+package scaffold
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// Target は生成する設定の種類
+type Target string
+
+const (
+	TargetGitHub   Target = "github"   // GitHub Actions のワークフロー
+	TargetGitLab   Target = "gitlab"   // GitLab CI
+	TargetMakefile Target = "makefile" // Makefile
+)
+
+// Targets は対応する生成対象の一覧
+func Targets() []Target {
+	return []Target{TargetGitHub, TargetGitLab, TargetMakefile}
+}
+
+// ParseTarget は --target の指定を解釈する（github-actions, make 等の別名も受け付ける）
+func ParseTarget(name string) (Target, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "github", "github-actions", "gha", "actions":
+		return TargetGitHub, nil
+	case "gitlab", "gitlab-ci":
+		return TargetGitLab, nil
+	case "makefile", "make":
+		return TargetMakefile, nil
+	}
+	return "", fmt.Errorf("未対応の生成対象です: %s (github, gitlab, makefile)", name)
+}
+
+// Path はプロジェクトのルートからの設定ファイルのパス
+func (t Target) Path() string {
+	switch t {
+	case TargetGitHub:
+		return filepath.Join(".github", "workflows", "ci.yml")
+	case TargetGitLab:
+		return ".gitlab-ci.yml"
+	case TargetMakefile:
+		return "Makefile"
+	}
+	return ""
+}
+
+// Label は表示・プロンプト用の名前
+func (t Target) Label() string {
+	switch t {
+	case TargetGitHub:
+		return "GitHub Actions workflow"
+	case TargetGitLab:
+		return "GitLab CI pipeline"
+	case TargetMakefile:
+		return "Makefile"
+	}
+	return string(t)
+}
+
+// BuildSystem は検出したビルドシステム（BuildManager の検出結果を要約したもの）
+type BuildSystem struct {
+	Type       string   `json:"type"`
+	ConfigFile string   `json:"config_file,omitempty"` // プロジェクトのルートからの相対パス
+	Targets    []string `json:"targets,omitempty"`
+}
+
+// Project は設定の生成に使うプロジェクトの情報
+type Project struct {
+	Dir          string                `json:"dir"`
+	Languages    []string              `json:"languages"`
+	Versions     map[string]string     `json:"versions,omitempty"` // 言語 → 要求バージョン（go.mod, .nvmrc 等）
+	BuildSystem  string                `json:"build_system,omitempty"`
+	Commands     map[tasks.Kind]string `json:"commands,omitempty"`
+	BuildSystems []BuildSystem         `json:"build_systems,omitempty"`
+	Platforms    []string              `json:"platforms,omitempty"` // OS固有のソースがある対象OS
+}
+
+// Inspect はプロジェクトの言語・タスクのコマンド・既存のビルド設定・バージョン要件を調べる
+func Inspect(dir string) (*Project, error) {
+	info, err := os.Stat(dir)
+	if err != nil {
+		return nil, fmt.Errorf("プロジェクトディレクトリが見つかりません: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("ディレクトリではありません: %s", dir)
+	}
+
+	project := &Project{Dir: dir, Versions: detectVersions(dir)}
+	if system := tasks.Detect(dir); system != nil {
+		project.BuildSystem = system.Name
+		project.Commands = system.Commands
+	}
+
+	analyzer := tools.NewAdvancedProjectAnalyzer(security.NewDefaultConstraints(dir), dir)
+	for _, system := range analyzer.DetectBuildSystems() {
+		if language := system.Metadata["language"]; language != "" {
+			project.Languages = append(project.Languages, language)
+			// 依存関係ファイルのない言語（補助スクリプト等）はビルドシステムに含めない
+			if system.ConfigFile == "" {
+				continue
+			}
+		}
+		entry := BuildSystem{Type: system.Type, Targets: system.Targets}
+		if system.ConfigFile != "" {
+			if rel, err := filepath.Rel(dir, system.ConfigFile); err == nil {
+				entry.ConfigFile = filepath.ToSlash(rel)
+			} else {
+				entry.ConfigFile = system.ConfigFile
+			}
+		}
+		project.BuildSystems = append(project.BuildSystems, entry)
+	}
+	sort.Strings(project.Languages)
+	project.Platforms = detectPlatforms(dir)
+	return project, nil
+}
+
+// Existing は生成対象の既存の設定ファイルがあればそのパスを返す
+func (p *Project) Existing(target Target) string {
+	path := filepath.Join(p.Dir, target.Path())
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+	return ""
+}
+
+// DefaultTarget は既存の設定から生成対象を選ぶ（GitLab CI だけがあれば gitlab、それ以外は github）
+func (p *Project) DefaultTarget() Target {
+	hasGitHub, hasGitLab := false, false
+	for _, system := range p.BuildSystems {
+		switch system.Type {
+		case "github_actions":
+			hasGitHub = true
+		case "gitlab_ci":
+			hasGitLab = true
+		}
+	}
+	if hasGitLab && !hasGitHub {
+		return TargetGitLab
+	}
+	return TargetGitHub
+}
+
+// Summary はプロンプトに添付するプロジェクトの要約
+func (p *Project) Summary() string {
+	var summary strings.Builder
+	if len(p.Languages) > 0 {
+		fmt.Fprintf(&summary, "- Languages: %s\n", strings.Join(p.Languages, ", "))
+	}
+	if len(p.Versions) > 0 {
+		names := make([]string, 0, len(p.Versions))
+		for name := range p.Versions {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			fmt.Fprintf(&summary, "- Required %s version: %s\n", name, p.Versions[name])
+		}
+	}
+	if p.BuildSystem != "" {
+		fmt.Fprintf(&summary, "- Build system: %s\n", p.BuildSystem)
+		for _, kind := range tasks.ValidKinds() {
+			if command := p.Commands[kind]; command != "" {
+				fmt.Fprintf(&summary, "  - %s: %s\n", kind, command)
+			}
+		}
+	}
+	for _, system := range p.BuildSystems {
+		fmt.Fprintf(&summary, "- Existing %s", system.Type)
+		if system.ConfigFile != "" {
+			fmt.Fprintf(&summary, " (%s)", system.ConfigFile)
+		}
+		if len(system.Targets) > 0 {
+			fmt.Fprintf(&summary, ": targets %s", strings.Join(system.Targets, ", "))
+		}
+		summary.WriteString("\n")
+	}
+	if len(p.Platforms) > 0 {
+		fmt.Fprintf(&summary, "- OS-specific sources for: %s (test on these OSes in the matrix)\n", strings.Join(p.Platforms, ", "))
+	}
+	return summary.String()
+}
+
+var (
+	goDirectivePattern    = regexp.MustCompile(`(?m)^go\s+(\S+)`)
+	requiresPythonPattern = regexp.MustCompile(`(?m)^requires-python\s*=\s*["']([^"']+)["']`)
+	rustChannelPattern    = regexp.MustCompile(`(?m)^channel\s*=\s*["']([^"']+)["']`)
+)
+
+// detectVersions はマニフェスト等から言語の要求バージョンを読み取る
+func detectVersions(dir string) map[string]string {
+	versions := make(map[string]string)
+	read := func(name string) string {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return ""
+		}
+		return string(data)
+	}
+
+	if match := goDirectivePattern.FindStringSubmatch(read("go.mod")); match != nil {
+		versions["Go"] = match[1]
+	}
+
+	if nvmrc := strings.TrimSpace(read(".nvmrc")); nvmrc != "" {
+		versions["Node.js"] = nvmrc
+	} else if manifest := read("package.json"); manifest != "" {
+		var pkg struct {
+			Engines map[string]string `json:"engines"`
+		}
+		if json.Unmarshal([]byte(manifest), &pkg) == nil && pkg.Engines["node"] != "" {
+			versions["Node.js"] = pkg.Engines["node"]
+		}
+	}
+
+	if pythonVersion := strings.TrimSpace(read(".python-version")); pythonVersion != "" {
+		versions["Python"] = pythonVersion
+	} else if match := requiresPythonPattern.FindStringSubmatch(read("pyproject.toml")); match != nil {
+		versions["Python"] = match[1]
+	}
+
+	if match := rustChannelPattern.FindStringSubmatch(read("rust-toolchain.toml")); match != nil {
+		versions["Rust"] = match[1]
+	} else if channel := strings.TrimSpace(read("rust-toolchain")); channel != "" && !strings.Contains(channel, "\n") {
+		versions["Rust"] = channel
+	}
+
+	if len(versions) == 0 {
+		return nil
+	}
+	return versions
+}
+
+// platformSuffixes はファイル名の接尾辞（Goのビルド制約）と対象OS
+var platformSuffixes = map[string]string{
+	"_windows": "windows",
+	"_darwin":  "macos",
+	"_linux":   "linux",
+}
+
+// detectPlatforms はOS固有のソース（foo_windows.go 等）があるOSを返す（ビルドマトリクスの判断に使う）
+func detectPlatforms(dir string) []string {
+	found := make(map[string]bool)
+	filepath.WalkDir(dir, func(path string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if entry.IsDir() {
+			name := entry.Name()
+			if path != dir && (strings.HasPrefix(name, ".") || name == "vendor" || name == "node_modules") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		base := strings.TrimSuffix(strings.TrimSuffix(entry.Name(), ".go"), "_test")
+		for suffix, platform := range platformSuffixes {
+			if strings.HasSuffix(entry.Name(), ".go") && strings.HasSuffix(base, suffix) {
+				found[platform] = true
+			}
+		}
+		return nil
+	})
+
+	platforms := make([]string, 0, len(found))
+	for platform := range found {
+		platforms = append(platforms, platform)
+	}
+	sort.Strings(platforms)
+	return platforms
+}
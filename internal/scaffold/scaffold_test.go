@@ -0,0 +1,147 @@
+package scaffold
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// answerProvider は決まった回答を返すテスト用プロバイダー
+type answerProvider struct {
+	answer   string
+	requests []llm.ChatRequest
+}
+
+func (p *answerProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}, Done: true}, nil
+}
+
+func (p *answerProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *answerProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *answerProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+func TestInspect(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":             "module example.com/app\n\ngo 1.21\n",
+		"main.go":            "package main\n\nfunc main() {}\n",
+		"term_windows.go":    "package main\n",
+		"term_linux_test.go": "package main\n",
+		"Makefile":           "build:\n\tgo build ./...\n\ntest:\n\tgo test ./...\n",
+		".gitlab-ci.yml":     "test:\n  script: make test\n",
+		"web/.nvmrc":         "20\n",
+	})
+
+	project, err := Inspect(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(project.Languages) != 1 || project.Languages[0] != "Go" || project.Versions["Go"] != "1.21" {
+		t.Errorf("languages = %v, versions = %v", project.Languages, project.Versions)
+	}
+	if project.BuildSystem != tasks.SystemMake || project.Commands[tasks.KindTest] != "make test" {
+		t.Errorf("build system = %s, commands = %v", project.BuildSystem, project.Commands)
+	}
+	if strings.Join(project.Platforms, ",") != "linux,windows" {
+		t.Errorf("platforms = %v", project.Platforms)
+	}
+	if project.DefaultTarget() != TargetGitLab {
+		t.Errorf("GitLab CI だけがあれば gitlab を選ぶはず: %s", project.DefaultTarget())
+	}
+	if project.Existing(TargetGitLab) == "" || project.Existing(TargetGitHub) != "" {
+		t.Error("既存の設定ファイルを判定できるはず")
+	}
+
+	summary := project.Summary()
+	for _, want := range []string{"Required Go version: 1.21", "test: make test", "Existing gitlab_ci (.gitlab-ci.yml)", "linux, windows"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("要約に %q がありません:\n%s", want, summary)
+		}
+	}
+}
+
+func TestParseTarget(t *testing.T) {
+	for name, want := range map[string]Target{"github-actions": TargetGitHub, "GitLab": TargetGitLab, "make": TargetMakefile} {
+		if got, err := ParseTarget(name); err != nil || got != want {
+			t.Errorf("ParseTarget(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseTarget("jenkins"); err == nil {
+		t.Error("未対応の対象はエラーのはず")
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	makefile, err := Normalize(TargetMakefile, "```make\n.PHONY: test\n\nVERSION := 1.0\ntest: build\n    go test ./...\n    go vet ./...\n```")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if makefile != ".PHONY: test\n\nVERSION := 1.0\ntest: build\n\tgo test ./...\n\tgo vet ./...\n" {
+		t.Errorf("レシピ行はタブ字下げになるはず: %q", makefile)
+	}
+
+	if _, err := Normalize(TargetGitHub, "```yaml\nname: CI\non: [push\n```"); err == nil {
+		t.Error("不正なYAMLはエラーのはず")
+	}
+}
+
+func TestGenerator_Generate(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{"go.mod": "module example.com/app\n\ngo 1.21\n", "main.go": "package main\n"})
+	project, err := Inspect(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	workflow := "name: CI\non: [push]\njobs:\n  test:\n    runs-on: ubuntu-latest\n    steps:\n      - run: go test ./...\n"
+	provider := &answerProvider{answer: "Here it is:\n```yaml\n" + workflow + "```"}
+	generator := &Generator{Provider: provider, Model: "qwen2.5-coder", Language: "en"}
+
+	change, err := generator.Generate(context.Background(), project, TargetGitHub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if change == nil || change.After != workflow || change.Before != "" {
+		t.Fatalf("change = %+v", change)
+	}
+	prompt := provider.requests[0].Messages[0].Content
+	if !strings.Contains(prompt, "GitHub Actions workflow") || !strings.Contains(prompt, "go test ./...") {
+		t.Errorf("プロンプトに対象と検出したコマンドを含むはず:\n%s", prompt)
+	}
+	if !strings.Contains(change.Diff(), "+name: CI") {
+		t.Errorf("diff = %s", change.Diff())
+	}
+
+	if err := change.Apply(); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, ".github", "workflows", "ci.yml")); string(data) != workflow {
+		t.Errorf("適用後の内容 = %q", data)
+	}
+
+	// 既存の内容と同じなら変更なし
+	if change, err := generator.Generate(context.Background(), project, TargetGitHub); err != nil || change != nil {
+		t.Errorf("変更なしなら nil のはず: %+v, %v", change, err)
+	}
+}
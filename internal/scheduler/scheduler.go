@@ -0,0 +1,262 @@
+package scheduler
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// Class は同時実行数を制限する処理の種類
+type Class string
+
+const (
+	ClassLLM      Class = "llm"      // LLMへのリクエスト
+	ClassTool     Class = "tool"     // ツールの実行
+	ClassAnalysis Class = "analysis" // プロジェクト全体の分析（統合分析・認知分析等）
+)
+
+// Priority は処理の優先度
+type Priority int
+
+const (
+	PriorityInteractive Priority = iota // ユーザーが応答を待っている処理
+	PriorityBackground                  // プロアクティブ分析等の裏で行う処理
+)
+
+type priorityKey struct{}
+
+// WithPriority は ctx で行う処理の優先度を指定する
+func WithPriority(ctx context.Context, priority Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, priority)
+}
+
+// Background は ctx で行う処理をバックグラウンド優先度にする
+func Background(ctx context.Context) context.Context {
+	return WithPriority(ctx, PriorityBackground)
+}
+
+// PriorityFrom は ctx に指定された優先度（未指定なら false）
+func PriorityFrom(ctx context.Context) (Priority, bool) {
+	priority, ok := ctx.Value(priorityKey{}).(Priority)
+	return priority, ok
+}
+
+// Stats は処理の種類毎の実行・待機状況
+type Stats struct {
+	Limit     int           `json:"limit"`
+	Running   int           `json:"running"`
+	Queued    int           `json:"queued"`
+	Completed int64         `json:"completed"`
+	Waited    int64         `json:"waited"`     // 待機した回数
+	TotalWait time.Duration `json:"total_wait"` // 待機時間の合計
+}
+
+// waiter は空きを待っている処理
+type waiter struct {
+	class    Class
+	priority Priority
+	ready    chan struct{}
+	queuedAt time.Time
+}
+
+// Scheduler はLLMリクエスト・ツール実行・分析の同時実行数を制限する
+// 空きがなければ待機させ、ユーザーが待っている処理をバックグラウンドの処理より先に実行する
+// バックグラウンドの処理は種類に関わらず MaxBackground 件までしか同時に実行しない
+type Scheduler struct {
+	mu            sync.Mutex
+	limits        map[Class]int
+	maxBackground int
+	running       map[Class]int
+	background    int
+	queue         []*waiter // 到着順
+	stats         map[Class]*Stats
+}
+
+// New は設定に基づいてスケジューラーを作成
+func New(cfg config.ConcurrencyConfig) *Scheduler {
+	defaults := config.DefaultConcurrencyConfig()
+	positive := func(value, fallback int) int {
+		if value > 0 {
+			return value
+		}
+		return fallback
+	}
+	s := &Scheduler{
+		limits: map[Class]int{
+			ClassLLM:      positive(cfg.MaxLLMRequests, defaults.MaxLLMRequests),
+			ClassTool:     positive(cfg.MaxToolExecutions, defaults.MaxToolExecutions),
+			ClassAnalysis: positive(cfg.MaxAnalyses, defaults.MaxAnalyses),
+		},
+		maxBackground: positive(cfg.MaxBackground, defaults.MaxBackground),
+		running:       make(map[Class]int),
+		stats:         make(map[Class]*Stats),
+	}
+	for class, limit := range s.limits {
+		s.stats[class] = &Stats{Limit: limit}
+	}
+	return s
+}
+
+// Acquire は class の処理を実行できるまで待ち、終了時に呼ぶ解放関数を返す
+// 優先度は ctx から取得する（未指定ならユーザーが待っている処理）
+// スケジューラーが nil の場合は待たずに実行する
+func (s *Scheduler) Acquire(ctx context.Context, class Class) (func(), error) {
+	priority, _ := PriorityFrom(ctx)
+	return s.AcquirePriority(ctx, class, priority)
+}
+
+// AcquirePriority は優先度を指定して class の処理の実行を待つ
+func (s *Scheduler) AcquirePriority(ctx context.Context, class Class, priority Priority) (func(), error) {
+	if s == nil {
+		return func() {}, nil
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	if s.fits(class, priority) && !s.waitingAhead(class, priority) {
+		s.start(class, priority)
+		s.mu.Unlock()
+		return s.releaser(class, priority), nil
+	}
+	w := &waiter{class: class, priority: priority, ready: make(chan struct{}), queuedAt: time.Now()}
+	s.queue = append(s.queue, w)
+	s.statsFor(class).Queued++
+	s.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return s.releaser(class, priority), nil
+	case <-ctx.Done():
+		s.mu.Lock()
+		if s.dequeue(w) {
+			s.statsFor(class).Queued--
+			s.mu.Unlock()
+			return nil, ctx.Err()
+		}
+		s.mu.Unlock()
+		// 中断と同時に実行が割り当てられた場合は返却する
+		s.releaser(class, priority)()
+		return nil, ctx.Err()
+	}
+}
+
+// Stats は処理の種類毎の実行・待機状況のコピーを返す
+func (s *Scheduler) Stats() map[Class]Stats {
+	if s == nil {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make(map[Class]Stats, len(s.stats))
+	for class, stats := range s.stats {
+		result[class] = *stats
+	}
+	return result
+}
+
+// Classes は制限している処理の種類（名前順）
+func (s *Scheduler) Classes() []Class {
+	if s == nil {
+		return nil
+	}
+	classes := make([]Class, 0, len(s.limits))
+	for class := range s.limits {
+		classes = append(classes, class)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i] < classes[j] })
+	return classes
+}
+
+// fits は空きがあるか（バックグラウンドの処理は全体の上限も確認）
+func (s *Scheduler) fits(class Class, priority Priority) bool {
+	if limit, ok := s.limits[class]; ok && s.running[class] >= limit {
+		return false
+	}
+	return priority != PriorityBackground || s.background < s.maxBackground
+}
+
+// waitingAhead は先に実行すべき処理（同じ種類で同じか高い優先度）が待っているか
+func (s *Scheduler) waitingAhead(class Class, priority Priority) bool {
+	for _, w := range s.queue {
+		if w.class == class && w.priority <= priority {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) start(class Class, priority Priority) {
+	s.running[class]++
+	if priority == PriorityBackground {
+		s.background++
+	}
+	s.statsFor(class).Running = s.running[class]
+}
+
+func (s *Scheduler) releaser(class Class, priority Priority) func() {
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.mu.Lock()
+			defer s.mu.Unlock()
+			s.running[class]--
+			if priority == PriorityBackground {
+				s.background--
+			}
+			stats := s.statsFor(class)
+			stats.Running = s.running[class]
+			stats.Completed++
+			s.dispatch()
+		})
+	}
+}
+
+// dispatch は空きに合わせて待機中の処理を開始する（ユーザーが待っている処理を先に、同じ優先度は到着順）
+func (s *Scheduler) dispatch() {
+	for _, priority := range []Priority{PriorityInteractive, PriorityBackground} {
+		blocked := make(map[Class]bool)
+		kept := s.queue[:0]
+		for _, w := range s.queue {
+			if w.priority != priority || blocked[w.class] || !s.fits(w.class, w.priority) {
+				if w.priority == priority {
+					// 同じ種類の後続が追い越さないようにする
+					blocked[w.class] = true
+				}
+				kept = append(kept, w)
+				continue
+			}
+			s.start(w.class, w.priority)
+			stats := s.statsFor(w.class)
+			stats.Queued--
+			stats.Waited++
+			stats.TotalWait += time.Since(w.queuedAt)
+			close(w.ready)
+		}
+		s.queue = kept
+	}
+}
+
+func (s *Scheduler) dequeue(target *waiter) bool {
+	for i, w := range s.queue {
+		if w == target {
+			s.queue = append(s.queue[:i], s.queue[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Scheduler) statsFor(class Class) *Stats {
+	stats, ok := s.stats[class]
+	if !ok {
+		stats = &Stats{}
+		s.stats[class] = stats
+	}
+	return stats
+}
@@ -0,0 +1,154 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// acquireAsync は別のゴルーチンで枠を待ち、確保できたら解放関数を送る
+func acquireAsync(s *Scheduler, class Class, priority Priority) <-chan func() {
+	acquired := make(chan func(), 1)
+	go func() {
+		release, err := s.AcquirePriority(context.Background(), class, priority)
+		if err == nil {
+			acquired <- release
+		}
+	}()
+	return acquired
+}
+
+// waitQueued は class の待機数が n になるまで待つ
+func waitQueued(t *testing.T, s *Scheduler, class Class, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for s.Stats()[class].Queued != n {
+		if time.Now().After(deadline) {
+			t.Fatalf("queued = %d, want %d", s.Stats()[class].Queued, n)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func notAcquired(t *testing.T, acquired <-chan func(), label string) {
+	t.Helper()
+	select {
+	case <-acquired:
+		t.Fatalf("%s は待機するはず", label)
+	case <-time.After(20 * time.Millisecond):
+	}
+}
+
+func TestScheduler_Limit(t *testing.T) {
+	s := New(config.ConcurrencyConfig{MaxLLMRequests: 1, MaxToolExecutions: 2, MaxAnalyses: 1, MaxBackground: 1})
+
+	release, err := s.Acquire(context.Background(), ClassLLM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// ツールは別の枠なので待たない
+	if releaseTool, err := s.Acquire(context.Background(), ClassTool); err != nil {
+		t.Fatal(err)
+	} else {
+		releaseTool()
+	}
+
+	second := acquireAsync(s, ClassLLM, PriorityInteractive)
+	waitQueued(t, s, ClassLLM, 1)
+	notAcquired(t, second, "上限を超えたリクエスト")
+
+	release()
+	release() // 二重解放は無視される
+	(<-second)()
+
+	stats := s.Stats()[ClassLLM]
+	if stats.Limit != 1 || stats.Running != 0 || stats.Queued != 0 || stats.Completed != 2 || stats.Waited != 1 {
+		t.Errorf("stats = %+v", stats)
+	}
+}
+
+func TestScheduler_InteractiveFirst(t *testing.T) {
+	s := New(config.ConcurrencyConfig{MaxLLMRequests: 1, MaxBackground: 2})
+
+	release, _ := s.Acquire(context.Background(), ClassLLM)
+	background := acquireAsync(s, ClassLLM, PriorityBackground)
+	waitQueued(t, s, ClassLLM, 1)
+	interactive := acquireAsync(s, ClassLLM, PriorityInteractive)
+	waitQueued(t, s, ClassLLM, 2)
+
+	// 後から来たユーザーの処理が先に実行される
+	release()
+	releaseInteractive := <-interactive
+	notAcquired(t, background, "バックグラウンドの処理")
+	releaseInteractive()
+	(<-background)()
+}
+
+func TestScheduler_BackgroundCap(t *testing.T) {
+	s := New(config.ConcurrencyConfig{MaxLLMRequests: 4, MaxAnalyses: 4, MaxBackground: 1})
+	ctx := Background(context.Background())
+
+	release, err := s.Acquire(ctx, ClassAnalysis)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 種類が違ってもバックグラウンドの処理は全体で1件まで
+	llm := acquireAsync(s, ClassLLM, PriorityBackground)
+	waitQueued(t, s, ClassLLM, 1)
+	notAcquired(t, llm, "2件目のバックグラウンドの処理")
+
+	// ユーザーの処理は影響を受けない
+	if releaseInteractive, err := s.Acquire(context.Background(), ClassLLM); err != nil {
+		t.Fatal(err)
+	} else {
+		releaseInteractive()
+	}
+
+	release()
+	(<-llm)()
+}
+
+func TestScheduler_CancelWhileQueued(t *testing.T) {
+	s := New(config.ConcurrencyConfig{MaxLLMRequests: 1})
+	release, _ := s.Acquire(context.Background(), ClassLLM)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if _, err := s.Acquire(ctx, ClassLLM); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("err = %v", err)
+	}
+	if stats := s.Stats()[ClassLLM]; stats.Queued != 0 {
+		t.Errorf("中断した処理は待機列から外れるはず: %+v", stats)
+	}
+
+	release()
+	if releaseNext, err := s.Acquire(context.Background(), ClassLLM); err != nil {
+		t.Fatal(err)
+	} else {
+		releaseNext()
+	}
+}
+
+func TestScheduler_Nil(t *testing.T) {
+	var s *Scheduler
+	release, err := s.Acquire(context.Background(), ClassTool)
+	if err != nil {
+		t.Fatal(err)
+	}
+	release()
+	if s.Stats() != nil || s.Classes() != nil {
+		t.Error("nil のスケジューラーは状況を持たないはず")
+	}
+}
+
+func TestPriorityFrom(t *testing.T) {
+	if _, ok := PriorityFrom(context.Background()); ok {
+		t.Error("未指定なら false のはず")
+	}
+	if priority, ok := PriorityFrom(Background(context.Background())); !ok || priority != PriorityBackground {
+		t.Errorf("PriorityFrom = %v, %v", priority, ok)
+	}
+}
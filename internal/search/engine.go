@@ -12,6 +12,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // ファイル検索エンジン
@@ -129,7 +131,7 @@ func (e *Engine) IndexProject() error {
 
 	e.indexedFiles = make(map[string]FileInfo)
 
-	err := filepath.Walk(e.workspaceDir, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(e.workspaceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // エラーが発生したファイルはスキップ
 		}
@@ -726,7 +728,7 @@ func (e *Engine) IndexProjectParallel(ctx context.Context) error {
 
 	// ファイルリストを収集
 	var filePaths []string
-	err := filepath.Walk(e.workspaceDir, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(e.workspaceDir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
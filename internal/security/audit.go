@@ -12,10 +12,12 @@ import (
 // 監査ログエントリ
 type AuditEntry struct {
 	Timestamp   time.Time `json:"timestamp"`
-	EventType   string    `json:"event_type"` // "command", "file", "llm_response"
+	EventType   string    `json:"event_type"` // "command", "file", "llm_response", "network"
 	Action      string    `json:"action"`     // "allowed", "blocked", "suspicious"
 	Command     string    `json:"command,omitempty"`
 	FilePath    string    `json:"file_path,omitempty"`
+	URL         string    `json:"url,omitempty"`
+	Method      string    `json:"method,omitempty"`
 	LLMResponse string    `json:"llm_response,omitempty"`
 	Reason      string    `json:"reason"`
 	RiskLevel   string    `json:"risk_level"` // "safe", "suspicious", "dangerous"
@@ -141,6 +143,31 @@ func (a *AuditLogger) LogConfigChange(setting, newValue string) {
 	a.writeEntry(entry)
 }
 
+// 外部通信リクエストの記録
+func (a *AuditLogger) LogNetworkRequest(method, target, action, reason string) {
+	if !a.enabled {
+		return
+	}
+
+	riskLevel := "safe"
+	if action == "blocked" {
+		riskLevel = "suspicious"
+	}
+
+	entry := AuditEntry{
+		Timestamp: time.Now(),
+		EventType: "network",
+		Action:    action,
+		URL:       target,
+		Method:    method,
+		Reason:    reason,
+		RiskLevel: riskLevel,
+		UserID:    getUserID(),
+	}
+
+	a.writeEntry(entry)
+}
+
 // 監査ログエントリをファイルに書き込み
 func (a *AuditLogger) writeEntry(entry AuditEntry) {
 	// ログディレクトリを作成
@@ -224,6 +251,9 @@ func (a *AuditLogger) ShowRecentLogs(limit int) error {
 		if entry.Command != "" {
 			fmt.Printf("   コマンド: %s\n", entry.Command)
 		}
+		if entry.URL != "" {
+			fmt.Printf("   通信先: %s %s\n", entry.Method, entry.URL)
+		}
 	}
 
 	return nil
@@ -0,0 +1,523 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// CommandRule はバイナリごとの実行規則
+type CommandRule struct {
+	Subcommands     []string            // 許可するサブコマンド（最初の位置引数、空なら制限なし）
+	ForbiddenFlags  []string            // 禁止するフラグ（1文字の短縮形は "-rf" のような結合指定も検出）
+	GlobalFlags     []string            // サブコマンドより前でだけ禁止するフラグ（git -c 等、サブコマンドの後では別の意味になる）
+	SubcommandFlags map[string][]string // サブコマンドごとに禁止するフラグ（go env -w 等）
+	ValueFlags      []string            // 次の引数を値に取るフラグ（値はパスとして扱わない）
+	PathFlags       []string            // 値がパスとなるフラグ（PathArgsに関わらずワークスペース内に制限）
+	PatternFlags    []string            // パターンを指定するフラグ（指定時は先頭の位置引数もパスとして扱う）
+	ExecFlags       []string            // 続く引数を ";" または "+" まで入れ子のコマンドとして評価（find -exec）
+	PathArgs        bool                // 位置引数をパスとしてワークスペース内に制限
+	PatternArgs     int                 // パス検査から除外する先頭の位置引数の数（grepのパターン等）
+	LongSingleDash  bool                // "-name" のような1文字ダッシュの長いフラグを使う（結合指定として分解しない）
+	WrapsCommand    bool                // 最初の位置引数以降を入れ子のコマンドとして評価（xargs等）
+}
+
+// inspectsArgs は引数の内容を検査する規則か（展開を含む引数を評価できない）
+func (r CommandRule) inspectsArgs() bool {
+	return r.PathArgs || r.WrapsCommand || len(r.Subcommands) > 0 || len(r.ForbiddenFlags) > 0 ||
+		len(r.GlobalFlags) > 0 || len(r.SubcommandFlags) > 0 || len(r.PathFlags) > 0 || len(r.ExecFlags) > 0
+}
+
+// 代入を許可する環境変数（表示・ロケール・ビルド対象の指定のみ）
+// PATH・LD_PRELOAD・GIT_*・GOFLAGS 等は実行するコマンドや設定を差し替えられるため、一覧にない変数は全て拒否する
+var safeEnvVars = map[string]bool{
+	"LANG": true, "LANGUAGE": true, "LC_ALL": true, "LC_CTYPE": true, "LC_COLLATE": true, "LC_MESSAGES": true,
+	"TZ": true, "TERM": true, "COLUMNS": true, "NO_COLOR": true, "FORCE_COLOR": true, "CLICOLOR": true, "CI": true,
+	"GOOS": true, "GOARCH": true, "CGO_ENABLED": true, "GOMAXPROCS": true,
+	"NODE_ENV": true, "RUST_BACKTRACE": true, "PYTHONUNBUFFERED": true, "PYTHONDONTWRITEBYTECODE": true,
+}
+
+// DefaultCommandRules は読み取り中心の開発コマンドの既定規則
+func DefaultCommandRules() map[string]CommandRule {
+	readPaths := CommandRule{PathArgs: true}
+	return map[string]CommandRule{
+		"ls":   {PathArgs: true, ValueFlags: []string{"-I", "--ignore", "-w", "--width"}},
+		"cat":  readPaths,
+		"head": {PathArgs: true, ValueFlags: []string{"-n", "-c", "--lines", "--bytes"}},
+		"tail": {PathArgs: true, ValueFlags: []string{"-n", "-c", "--lines", "--bytes"}},
+		"wc":   readPaths,
+		"stat": {PathArgs: true, ValueFlags: []string{"-c", "--format", "--printf"}},
+		"file": readPaths,
+		"tree": {PathArgs: true, ValueFlags: []string{"-L", "-I", "-P"}, PathFlags: []string{"-o"}},
+		"sort": {PathArgs: true, ValueFlags: []string{"-k", "-t", "--key", "--field-separator"}, PathFlags: []string{"-o", "--output"}},
+		"uniq": readPaths,
+		"cut":  {PathArgs: true, ValueFlags: []string{"-d", "-f", "-c", "-b", "--delimiter", "--fields"}},
+		"tr":   {},
+		"echo": {},
+		"pwd":  {},
+		"cd":   readPaths, // 移動先もワークスペース内に限る（以降の相対パスはワークスペース基準で保守的に評価）
+		"true": {},
+		"date": {},
+		"grep": {
+			PathArgs:     true,
+			PatternArgs:  1,
+			PatternFlags: []string{"-e", "--regexp", "-f", "--file"},
+			PathFlags:    []string{"-f", "--file"},
+			ValueFlags: []string{
+				"-e", "--regexp", "-m", "--max-count", "-A", "-B", "-C",
+				"--after-context", "--before-context", "--context",
+				"--include", "--exclude", "--exclude-dir", "--color", "--colour", "--label",
+			},
+		},
+		"find": {
+			PathArgs:       true,
+			LongSingleDash: true,
+			ForbiddenFlags: []string{"-delete", "-fprint", "-fprint0", "-fprintf", "-fls"},
+			ExecFlags:      []string{"-exec", "-execdir", "-ok", "-okdir"},
+		},
+		"xargs": {
+			WrapsCommand: true,
+			ValueFlags:   []string{"-I", "-n", "-P", "-d", "-L", "-s", "-E", "--max-args", "--max-procs", "--delimiter"},
+			PathFlags:    []string{"-a", "--arg-file"},
+		},
+		"git": {
+			Subcommands: []string{
+				"status", "log", "diff", "show", "branch", "blame", "ls-files", "ls-tree",
+				"rev-parse", "grep", "shortlog", "describe", "remote", "tag", "reflog",
+				"add", "commit", "stash",
+			},
+			// git -c/--config-env/--exec-path・git grep -O（ページャーとして任意のプログラムを起動）等は任意コマンドの実行につながる
+			// -c はサブコマンドの後では git log -c（結合差分）等の別のフラグになるため、サブコマンドより前でだけ禁止する
+			ForbiddenFlags:  []string{"--upload-pack", "--receive-pack", "--force", "-f", "-D", "--delete", "--output"},
+			GlobalFlags:     []string{"-c", "--config-env", "--exec-path"},
+			SubcommandFlags: map[string][]string{"grep": {"-O", "--open-files-in-pager"}},
+			PathFlags:       []string{"-C", "--git-dir", "--work-tree"},
+			ValueFlags:      []string{"-m", "--message", "-n", "--max-count", "--author", "--since", "--until", "--format", "--pretty"},
+		},
+		"go": {
+			Subcommands:    []string{"build", "test", "vet", "list", "version", "env", "doc", "mod", "fmt"},
+			PathArgs:       true,
+			LongSingleDash: true,
+			ForbiddenFlags: []string{"-exec", "-toolexec"},
+			// go env -w/-u は GOFLAGS=-toolexec=... や CC を永続的に書き換え、次のビルドで任意のコマンドを実行できる
+			SubcommandFlags: map[string][]string{"env": {"-w", "-u"}},
+			PathFlags:       []string{"-o", "-C", "-modfile", "-coverprofile", "-cpuprofile", "-memprofile", "-trace", "-outputdir"},
+			ValueFlags:      []string{"-run", "-bench", "-count", "-timeout", "-tags", "-p", "-parallel"},
+		},
+		"npm": {
+			Subcommands:    []string{"test", "run", "ls", "list", "outdated"},
+			ForbiddenFlags: []string{"--prefix", "--script-shell"},
+		},
+		"python":  {PathArgs: true, ForbiddenFlags: []string{"-c"}},
+		"python3": {PathArgs: true, ForbiddenFlags: []string{"-c"}},
+		"node":    {PathArgs: true, ForbiddenFlags: []string{"-e", "--eval", "-p", "--print", "-r", "--require"}},
+	}
+}
+
+// CommandPolicy はシェル構文を解析し、各コマンドのargvをバイナリごとの規則で評価する
+// 規則のないバイナリ・関数定義・実行コマンドを差し替える環境変数の代入は拒否し、
+// パス引数とリダイレクト先はワークスペース内に制限する
+type CommandPolicy struct {
+	jail  *PathJail
+	rules map[string]CommandRule
+	allow func(command string) bool // 規則のないバイナリ・サブコマンドを追加で許可するコマンド（許可規則）
+}
+
+// NewCommandPolicy はポリシーを作成（rulesがnilなら既定規則）
+func NewCommandPolicy(workspace string, rules map[string]CommandRule) *CommandPolicy {
+	if rules == nil {
+		rules = DefaultCommandRules()
+	}
+	return &CommandPolicy{jail: NewPathJail(workspace), rules: rules}
+}
+
+// policyArg は評価用に展開した引数（変数展開やコマンド置換を含む場合はstatic=false）
+type policyArg struct {
+	value  string
+	static bool
+}
+
+// Evaluate はコマンド文字列がポリシーを満たすか検証（違反時はその理由を返す）
+func (p *CommandPolicy) Evaluate(command string) error {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return fmt.Errorf("コマンドの構文解析に失敗しました: %w", err)
+	}
+	if len(file.Stmts) == 0 {
+		return fmt.Errorf("空のコマンドです")
+	}
+
+	// コマンド置換やサブシェル内のコマンドも再帰的に評価される
+	var violation error
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if violation != nil {
+			return false
+		}
+		switch n := node.(type) {
+		case *syntax.CallExpr:
+			violation = p.evaluateCall(n)
+		case *syntax.Redirect:
+			violation = p.evaluateRedirect(n)
+		case *syntax.FuncDecl:
+			violation = fmt.Errorf("関数定義は許可されていません: %s", n.Name.Value)
+		case *syntax.DeclClause:
+			violation = fmt.Errorf("'%s' は許可されていません", n.Variant.Value)
+		case *syntax.CoprocClause:
+			violation = fmt.Errorf("coprocは許可されていません")
+		}
+		return violation == nil
+	})
+	return violation
+}
+
+// evaluateCall は単純コマンド（代入と argv）を評価
+func (p *CommandPolicy) evaluateCall(call *syntax.CallExpr) error {
+	for _, assign := range call.Assigns {
+		if assign.Name == nil {
+			return fmt.Errorf("環境変数の代入を評価できません")
+		}
+		if !safeEnvVars[assign.Name.Value] || assign.Index != nil || assign.Array != nil {
+			return fmt.Errorf("環境変数 %s の変更は許可されていません", assign.Name.Value)
+		}
+		if assign.Value != nil && !wordArg(assign.Value).static {
+			return fmt.Errorf("環境変数 %s に展開を含む値は指定できません", assign.Name.Value)
+		}
+	}
+	if len(call.Args) == 0 {
+		return nil
+	}
+
+	args := make([]policyArg, len(call.Args))
+	for i, word := range call.Args {
+		args[i] = wordArg(word)
+	}
+	return p.evaluateArgv(args)
+}
+
+// evaluateArgv は argv をバイナリの規則で評価
+func (p *CommandPolicy) evaluateArgv(args []policyArg) error {
+	name := args[0]
+	if !name.static {
+		return fmt.Errorf("コマンド名を静的に決定できません")
+	}
+	if strings.Contains(name.value, "/") {
+		return fmt.Errorf("パス指定のコマンドは実行できません: %s", name.value)
+	}
+	// 許可規則はバイナリとサブコマンドを許可リストに加えるだけで、禁止フラグ・パス・リダイレクトの検証は省かない
+	allowedByRule := p.allow != nil && p.allow(joinArgs(args))
+	rule, ok := p.rules[name.value]
+	if !ok {
+		if !allowedByRule {
+			return fmt.Errorf("コマンド '%s' は許可されていません", name.value)
+		}
+		rule = DefaultCommandRules()[name.value]
+	}
+
+	positional := 0
+	subcommand := ""
+	patternFromFlag := false
+	endOfFlags := false
+	for i := 1; i < len(args); i++ {
+		arg := args[i]
+
+		// 展開後の値が禁止フラグやパスになり得るため、引数を検査するコマンドでは拒否
+		if !arg.static && rule.inspectsArgs() {
+			return fmt.Errorf("'%s' に展開を含む引数は指定できません: %s", name.value, arg.value)
+		}
+
+		if !endOfFlags && arg.static && arg.value == "--" {
+			endOfFlags = true
+			continue
+		}
+
+		if !endOfFlags && arg.static && len(arg.value) > 1 && strings.HasPrefix(arg.value, "-") {
+			for _, flag := range splitFlags(arg.value, rule) {
+				if isForbiddenFlag(rule, rule.forbiddenFlags(positional, subcommand), flag.name) {
+					return fmt.Errorf("'%s' のフラグ '%s' は禁止されています", name.value, flag.name)
+				}
+
+				if containsString(rule.ExecFlags, flag.name) {
+					end := i + 1
+					for end < len(args) && !(args[end].static && (args[end].value == ";" || args[end].value == "+")) {
+						end++
+					}
+					if end == i+1 {
+						return fmt.Errorf("'%s' に実行するコマンドがありません", flag.name)
+					}
+					if err := p.evaluateArgv(args[i+1 : end]); err != nil {
+						return err
+					}
+					i = end
+					break
+				}
+
+				if containsString(rule.PatternFlags, flag.name) {
+					patternFromFlag = true
+				}
+				takesValue := containsString(rule.ValueFlags, flag.name) ||
+					containsString(rule.PathFlags, flag.name) ||
+					containsString(rule.PatternFlags, flag.name)
+				value, hasValue := policyArg{value: flag.value, static: true}, flag.hasValue
+				if takesValue && !hasValue && i+1 < len(args) {
+					i++
+					value, hasValue = args[i], true
+				}
+				if !hasValue {
+					continue
+				}
+
+				checkValue := containsString(rule.PathFlags, flag.name) ||
+					(rule.PathArgs && !containsString(rule.ValueFlags, flag.name) && !containsString(rule.PatternFlags, flag.name))
+				if checkValue {
+					if err := p.checkPathArg(value); err != nil {
+						return err
+					}
+				}
+			}
+			continue
+		}
+
+		// 位置引数
+		if positional == 0 && len(rule.Subcommands) > 0 {
+			if !allowedByRule && (!arg.static || !containsString(rule.Subcommands, arg.value)) {
+				return fmt.Errorf("'%s' のサブコマンド '%s' は許可されていません", name.value, arg.value)
+			}
+			subcommand = arg.value
+			positional++
+			continue
+		}
+		if rule.WrapsCommand {
+			return p.evaluateArgv(args[i:])
+		}
+
+		index := positional
+		if len(rule.Subcommands) > 0 {
+			index--
+		}
+		positional++
+		if !rule.PathArgs || (index < rule.PatternArgs && !patternFromFlag) {
+			continue
+		}
+		if err := p.checkPathArg(arg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinArgs は argv を許可規則と照合する1行のコマンドにする
+func joinArgs(args []policyArg) string {
+	values := make([]string, len(args))
+	for i, arg := range args {
+		values[i] = arg.value
+	}
+	return strings.Join(values, " ")
+}
+
+// evaluateRedirect はリダイレクト先がワークスペース内か検証
+func (p *CommandPolicy) evaluateRedirect(redirect *syntax.Redirect) error {
+	switch redirect.Op {
+	case syntax.Hdoc, syntax.DashHdoc, syntax.WordHdoc:
+		return nil
+	case syntax.DplIn, syntax.DplOut:
+		// 2>&1 や >&- のようなファイルディスクリプタの複製
+		arg := wordArg(redirect.Word)
+		if arg.static && (arg.value == "-" || isDigits(arg.value)) {
+			return nil
+		}
+	}
+
+	arg := wordArg(redirect.Word)
+	if arg.static && arg.value == os.DevNull {
+		return nil
+	}
+	if !arg.static {
+		return fmt.Errorf("展開を含むリダイレクト先は評価できません")
+	}
+	return p.CheckPath(arg.value)
+}
+
+// checkPathArg はパスとして扱う引数を検証
+func (p *CommandPolicy) checkPathArg(arg policyArg) error {
+	if !arg.static {
+		return fmt.Errorf("展開を含む引数はパスとして評価できません")
+	}
+	return p.CheckPath(arg.value)
+}
+
+// CheckPath はパスがワークスペース内か検証（相対パスはワークスペース基準、シンボリックリンクは実体で判定）
+func (p *CommandPolicy) CheckPath(path string) error {
+	if path == os.DevNull {
+		return nil
+	}
+
+	if strings.HasPrefix(path, "~") {
+		home, err := os.UserHomeDir()
+		if err != nil || (path != "~" && !strings.HasPrefix(path, "~/")) {
+			return fmt.Errorf("ワークスペース外のパスは指定できません: %s", path)
+		}
+		path = filepath.Join(home, strings.TrimPrefix(path, "~"))
+	}
+	if _, err := p.jail.Resolve(path); err != nil {
+		return fmt.Errorf("ワークスペース外のパスは指定できません: %w", err)
+	}
+	return nil
+}
+
+// forbiddenFlags は引数の位置で禁止するフラグ（positional はそれまでの位置引数の数）
+func (r CommandRule) forbiddenFlags(positional int, subcommand string) []string {
+	if positional == 0 {
+		return append(append([]string(nil), r.ForbiddenFlags...), r.GlobalFlags...)
+	}
+	return append(append([]string(nil), r.ForbiddenFlags...), r.SubcommandFlags[subcommand]...)
+}
+
+// isForbiddenFlag は禁止フラグか（"--open-files" のような長いフラグの省略形も禁止フラグとみなす）
+func isForbiddenFlag(rule CommandRule, forbiddenFlags []string, name string) bool {
+	if containsString(forbiddenFlags, name) {
+		return true
+	}
+	if rule.LongSingleDash || !strings.HasPrefix(name, "--") || len(name) < 3 {
+		return false
+	}
+	for _, forbidden := range forbiddenFlags {
+		if strings.HasPrefix(forbidden, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// policyFlag はフラグ引数を分解した1つのフラグ
+type policyFlag struct {
+	name     string
+	value    string
+	hasValue bool
+}
+
+// splitFlags は "--name=value" や "-la"、"-n5" のようなフラグ引数を個々のフラグに分解
+func splitFlags(arg string, rule CommandRule) []policyFlag {
+	if name, value, ok := strings.Cut(arg, "="); ok && (strings.HasPrefix(arg, "--") || rule.LongSingleDash) {
+		return []policyFlag{{name: name, value: value, hasValue: true}}
+	}
+	if strings.HasPrefix(arg, "--") || rule.LongSingleDash || len(arg) == 2 {
+		return []policyFlag{{name: arg}}
+	}
+
+	// 結合された短縮フラグ（値を取るフラグ以降は値として扱う）
+	var flags []policyFlag
+	letters := arg[1:]
+	for i, letter := range letters {
+		name := "-" + string(letter)
+		rest := letters[i+len(string(letter)):]
+		takesValue := containsString(rule.ValueFlags, name) ||
+			containsString(rule.PathFlags, name) ||
+			containsString(rule.PatternFlags, name)
+		if takesValue && rest != "" {
+			flags = append(flags, policyFlag{name: name, value: rest, hasValue: true})
+			break
+		}
+		flags = append(flags, policyFlag{name: name})
+	}
+	return flags
+}
+
+// wordArg はシェルの単語をクォート除去した値に変換（静的に決まらない場合はstatic=false）
+func wordArg(word *syntax.Word) policyArg {
+	if word == nil {
+		return policyArg{}
+	}
+
+	// ブレース展開はパスを隠せるため静的な値とみなさない
+	probe := &syntax.Word{Parts: append([]syntax.WordPart{}, word.Parts...)}
+	if syntax.SplitBraces(probe) {
+		for _, part := range probe.Parts {
+			// find -exec の {} のように展開されないものは除く
+			if brace, ok := part.(*syntax.BraceExp); ok && (brace.Sequence || len(brace.Elems) > 1) {
+				return policyArg{value: wordSource(word)}
+			}
+		}
+	}
+
+	var builder strings.Builder
+	for _, part := range word.Parts {
+		switch wp := part.(type) {
+		case *syntax.Lit:
+			builder.WriteString(unescapeLiteral(wp.Value, false))
+		case *syntax.SglQuoted:
+			if wp.Dollar {
+				return policyArg{value: wordSource(word)}
+			}
+			builder.WriteString(wp.Value)
+		case *syntax.DblQuoted:
+			for _, inner := range wp.Parts {
+				lit, ok := inner.(*syntax.Lit)
+				if !ok {
+					return policyArg{value: wordSource(word)}
+				}
+				builder.WriteString(unescapeLiteral(lit.Value, true))
+			}
+		default:
+			return policyArg{value: wordSource(word)}
+		}
+	}
+	return policyArg{value: builder.String(), static: true}
+}
+
+// wordSource はエラーメッセージ用に単語を元の表記で返す
+func wordSource(word *syntax.Word) string {
+	var builder strings.Builder
+	syntax.NewPrinter().Print(&builder, word)
+	return builder.String()
+}
+
+// unescapeLiteral はバックスラッシュによるエスケープを除去
+// ダブルクォート内では $ ` " \ と改行のみがエスケープ対象
+func unescapeLiteral(value string, quoted bool) string {
+	if !strings.Contains(value, "\\") {
+		return value
+	}
+	var builder strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i+1 >= len(value) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		next := value[i+1]
+		if quoted && !strings.ContainsRune("$`\"\\\n", rune(next)) {
+			builder.WriteByte(value[i])
+			continue
+		}
+		i++
+		if next != '\n' {
+			builder.WriteByte(next)
+		}
+	}
+	return builder.String()
+}
+
+// containsString はスライスに値が含まれるか
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// isDigits は空でない数字のみの文字列か
+func isDigits(value string) bool {
+	if value == "" {
+		return false
+	}
+	for _, r := range value {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
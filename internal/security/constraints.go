@@ -17,6 +17,9 @@ type Constraints struct {
 	BlockedPaths      []string // アクセス禁止パス
 	MaxFileSize       int64    // 最大ファイルサイズ（バイト）
 	ReadOnlyMode      bool     // 読み取り専用モード
+
+	NetworkPolicy *NetworkPolicy // 外部通信ポリシー（nilの場合は未適用）
+	Permissions   *Permissions   // プロジェクト・ユーザーの許可規則（nilの場合は未適用）
 }
 
 // デフォルトのセキュリティ制約を作成
@@ -77,24 +80,89 @@ func (c *Constraints) IsCommandAllowed(command string) error {
 	return fmt.Errorf("command '%s' is not in the allowed list", baseCommand)
 }
 
-// ValidateCommand はコマンドのバリデーション（IsCommandAllowedのエイリアス）
+// ValidateCommand はコマンドのバリデーション（許可規則・許可リストとネットワークポリシー）
+// シェル構文を解析し、&& や | で繋いだコマンド・コマンド置換の中のコマンドも含めて評価する
+// 許可規則の allow は既定の許可リストにないコマンドを許可するが、既定の禁止コマンドは許可しない
 func (c *Constraints) ValidateCommand(command string) error {
-	return c.IsCommandAllowed(command)
+	if c.Permissions != nil {
+		if err := c.Permissions.CheckCommand(command); err != nil {
+			return err
+		}
+	}
+	if err := c.CommandPolicy().Evaluate(command); err != nil {
+		return err
+	}
+	if c.NetworkPolicy != nil {
+		return c.NetworkPolicy.CheckCommand(command)
+	}
+	return nil
 }
 
-// パスがワークスペース内かチェック
-func (c *Constraints) IsPathAllowed(path string) bool {
-	absPath, err := filepath.Abs(path)
-	if err != nil {
+// CommandPolicy は許可リストのコマンドをシェル構文で評価するポリシー
+// 既定の規則（DefaultCommandRules）があるコマンドはサブコマンド・禁止フラグ・パスも検証し、それ以外は名前で許可する
+// 許可規則に一致するコマンドはサブコマンドの制限だけを外し、禁止フラグ・パス・リダイレクトは同様に検証する
+func (c *Constraints) CommandPolicy() *CommandPolicy {
+	defaults := DefaultCommandRules()
+	rules := make(map[string]CommandRule, len(c.AllowedCommands))
+	for _, name := range c.AllowedCommands {
+		rules[name] = defaults[name]
+	}
+	for _, blocked := range c.BlockedCommands {
+		delete(rules, blocked)
+	}
+	policy := NewCommandPolicy(c.WorkspaceDir, rules)
+	if c.Permissions != nil && len(c.Permissions.AllowCommands) > 0 {
+		policy.allow = func(command string) bool {
+			return !c.isBlockedCommand(command) && c.Permissions.allowsSimpleCommand(command)
+		}
+	}
+	return policy
+}
+
+// isBlockedCommand は単純コマンドのバイナリが禁止コマンドか
+func (c *Constraints) isBlockedCommand(command string) bool {
+	fields := strings.Fields(command)
+	if len(fields) == 0 {
 		return false
 	}
+	base := filepath.Base(fields[0])
+	for _, blocked := range c.BlockedCommands {
+		if base == blocked {
+			return true
+		}
+	}
+	return false
+}
+
+// パスがワークスペース内かチェック（シンボリックリンクと ".." を解決して判定）
+func (c *Constraints) IsPathAllowed(path string) bool {
+	_, err := c.ResolvePath(path)
+	return err == nil
+}
+
+// ResolvePath はパスをワークスペース内の実体パスに解決（外を指す場合はエラー）
+// 検証後のファイル操作は返されたパスに対して行う
+func (c *Constraints) ResolvePath(path string) (string, error) {
+	return NewPathJail(c.WorkspaceDir).Resolve(path)
+}
 
-	absWorkspace, err := filepath.Abs(c.WorkspaceDir)
+// ResolvePathFor はパスを解決し、操作（read, write, create, delete）が許可規則に違反しないか検証
+func (c *Constraints) ResolvePathFor(path, operation string) (string, error) {
+	resolved, err := c.ResolvePath(path)
 	if err != nil {
-		return false
+		return "", err
 	}
+	if c.Permissions != nil {
+		if err := c.Permissions.CheckPath(resolved, operation); err != nil {
+			return "", err
+		}
+	}
+	return resolved, nil
+}
 
-	return strings.HasPrefix(absPath, absWorkspace)
+// ResolveWorkDir はコマンドの作業ディレクトリを検証して実体パスを返す
+func (c *Constraints) ResolveWorkDir(dir string) (string, error) {
+	return NewPathJail(c.WorkspaceDir).ResolveDir(dir)
 }
 
 // 環境変数のフィルタリング（機密情報の除外）
@@ -130,15 +198,10 @@ func (c *Constraints) FilterEnvironment() []string {
 
 // ファイルアクセスが許可されているかチェック
 func (c *Constraints) IsFileAccessAllowed(filePath string, operation string) error {
-	// パスの正規化
-	absPath, err := filepath.Abs(filePath)
+	// パスの正規化とワークスペース外へのアクセスチェック
+	absPath, err := c.ResolvePath(filePath)
 	if err != nil {
-		return fmt.Errorf("パス正規化失敗: %w", err)
-	}
-
-	// ワークスペース外へのアクセスチェック
-	if !c.IsPathAllowed(absPath) {
-		return fmt.Errorf("ワークスペース外のファイルアクセスは禁止されています: %s", filePath)
+		return fmt.Errorf("ワークスペース外のファイルアクセスは禁止されています: %w", err)
 	}
 
 	// 禁止パスチェック
@@ -148,6 +211,13 @@ func (c *Constraints) IsFileAccessAllowed(filePath string, operation string) err
 		}
 	}
 
+	// プロジェクト・ユーザーの許可規則
+	if c.Permissions != nil {
+		if err := c.Permissions.CheckPath(absPath, operation); err != nil {
+			return err
+		}
+	}
+
 	// 読み取り専用モードでの書き込みチェック
 	if c.ReadOnlyMode && (operation == "write" || operation == "create" || operation == "delete") {
 		return fmt.Errorf("読み取り専用モードのため書き込み操作は禁止されています")
@@ -182,18 +252,19 @@ func (c *Constraints) IsFileAccessAllowed(filePath string, operation string) err
 
 // ディレクトリ作成が許可されているかチェック
 func (c *Constraints) IsDirectoryCreationAllowed(dirPath string) error {
-	absPath, err := filepath.Abs(dirPath)
+	absPath, err := c.ResolvePath(dirPath)
 	if err != nil {
-		return fmt.Errorf("パス正規化失敗: %w", err)
-	}
-
-	if !c.IsPathAllowed(absPath) {
-		return fmt.Errorf("ワークスペース外のディレクトリ作成は禁止されています: %s", dirPath)
+		return fmt.Errorf("ワークスペース外のディレクトリ作成は禁止されています: %w", err)
 	}
 
 	if c.ReadOnlyMode {
 		return fmt.Errorf("読み取り専用モードのためディレクトリ作成は禁止されています")
 	}
+	if c.Permissions != nil {
+		if err := c.Permissions.CheckPath(absPath, "create"); err != nil {
+			return err
+		}
+	}
 
 	// 禁止パスチェック
 	for _, blockedPath := range c.BlockedPaths {
@@ -0,0 +1,284 @@
+package security
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+// ネットワークポリシーモード
+const (
+	NetworkModeAllowlist = "allowlist" // 許可リストのドメインのみ通信可
+	NetworkModeDenyAll   = "deny_all"  // 全ての外部通信を禁止
+	NetworkModeAllowAll  = "allow_all" // ブロックリスト以外の通信を許可
+)
+
+// パッケージインストール系コマンド（先頭の引数が完全に一致するもの）と通信先レジストリの対応
+var packageInstallRegistries = []struct {
+	args  []string
+	hosts []string
+}{
+	{args: []string{"go", "get"}, hosts: []string{"proxy.golang.org", "sum.golang.org"}},
+	{args: []string{"go", "install"}, hosts: []string{"proxy.golang.org", "sum.golang.org"}},
+	{args: []string{"go", "mod", "download"}, hosts: []string{"proxy.golang.org", "sum.golang.org"}},
+	{args: []string{"npm", "install"}, hosts: []string{"registry.npmjs.org"}},
+	{args: []string{"npm", "i"}, hosts: []string{"registry.npmjs.org"}},
+	{args: []string{"npm", "ci"}, hosts: []string{"registry.npmjs.org"}},
+	{args: []string{"yarn", "add"}, hosts: []string{"registry.yarnpkg.com"}},
+	{args: []string{"pip", "install"}, hosts: []string{"pypi.org", "files.pythonhosted.org"}},
+	{args: []string{"pip3", "install"}, hosts: []string{"pypi.org", "files.pythonhosted.org"}},
+	{args: []string{"cargo", "add"}, hosts: []string{"crates.io", "index.crates.io"}},
+	{args: []string{"cargo", "fetch"}, hosts: []string{"crates.io", "index.crates.io"}},
+}
+
+// NetworkPolicy はモデルの代理で行われる外部通信を制御する
+type NetworkPolicy struct {
+	mu             sync.RWMutex
+	mode           string
+	allowedDomains []string
+	blockedDomains []string
+	allowLoopback  bool
+	auditLogger    *AuditLogger
+}
+
+// NewNetworkPolicy は新しいネットワークポリシーを作成
+func NewNetworkPolicy(mode string, allowedDomains, blockedDomains []string) *NetworkPolicy {
+	if !IsValidNetworkMode(mode) {
+		mode = NetworkModeAllowlist
+	}
+	return &NetworkPolicy{
+		mode:           mode,
+		allowedDomains: normalizeDomains(allowedDomains),
+		blockedDomains: normalizeDomains(blockedDomains),
+	}
+}
+
+// ValidNetworkModes は有効なポリシーモード一覧を返す
+func ValidNetworkModes() []string {
+	return []string{NetworkModeAllowlist, NetworkModeDenyAll, NetworkModeAllowAll}
+}
+
+// IsValidNetworkMode はモード名が有効かチェック
+func IsValidNetworkMode(mode string) bool {
+	for _, valid := range ValidNetworkModes() {
+		if mode == valid {
+			return true
+		}
+	}
+	return false
+}
+
+// SetAuditLogger は外部通信の監査ログ出力先を設定
+func (p *NetworkPolicy) SetAuditLogger(logger *AuditLogger) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.auditLogger = logger
+}
+
+// SetAllowLoopback はローカルホストへの通信をモードに関わらず許可するか設定
+func (p *NetworkPolicy) SetAllowLoopback(allow bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.allowLoopback = allow
+}
+
+// Mode は現在のポリシーモードを返す
+func (p *NetworkPolicy) Mode() string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.mode
+}
+
+// IsHostAllowed はホストへの通信が許可されているかチェック
+func (p *NetworkPolicy) IsHostAllowed(host string) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	host = strings.ToLower(stripPort(host))
+	if host == "" {
+		return fmt.Errorf("通信先ホストが空です")
+	}
+
+	// ブロックリストは全モードで優先
+	if matchesDomainList(host, p.blockedDomains) {
+		return fmt.Errorf("ドメイン '%s' はネットワークポリシーでブロックされています", host)
+	}
+
+	// ローカルホストへの通信は設定で許可した場合のみモードに関わらず許可
+	if p.allowLoopback && isLoopbackHost(host) {
+		return nil
+	}
+
+	switch p.mode {
+	case NetworkModeDenyAll:
+		return fmt.Errorf("ネットワークポリシー（deny_all）により外部通信は禁止されています: %s", host)
+	case NetworkModeAllowAll:
+		return nil
+	default:
+		if matchesDomainList(host, p.allowedDomains) {
+			return nil
+		}
+		return fmt.Errorf("ドメイン '%s' はネットワーク許可リストに含まれていません", host)
+	}
+}
+
+// CheckURL はURLへの通信が許可されているかチェックし、監査ログに記録
+func (p *NetworkPolicy) CheckURL(method, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		p.audit(method, rawURL, "blocked", "URL解析失敗")
+		return fmt.Errorf("URL解析エラー: %w", err)
+	}
+
+	if err := p.IsHostAllowed(parsed.Host); err != nil {
+		p.audit(method, rawURL, "blocked", err.Error())
+		return err
+	}
+
+	p.audit(method, rawURL, "allowed", "ネットワークポリシー通過")
+	return nil
+}
+
+// CheckCommand はパッケージインストール等、外部通信を伴うコマンドを検証
+// &&・;・| 等で繋いだ個々のコマンドを、先頭の環境変数の代入を除いた解析後の引数で照合する
+func (p *NetworkPolicy) CheckCommand(command string) error {
+	matched := false
+	for _, argv := range networkCommandArgs(command) {
+		for _, registry := range packageInstallRegistries {
+			if !hasArgsPrefix(argv, registry.args) {
+				continue
+			}
+			for _, host := range registry.hosts {
+				if err := p.IsHostAllowed(host); err != nil {
+					p.audit("EXEC", command, "blocked", err.Error())
+					return fmt.Errorf("パッケージ取得コマンドが拒否されました: %w", err)
+				}
+			}
+			matched = true
+			break
+		}
+	}
+
+	if matched {
+		p.audit("EXEC", command, "allowed", "パッケージレジストリへの通信を許可")
+	}
+	return nil
+}
+
+// networkCommandArgs は照合する個々のコマンドの引数（env による代入も除く。解析できなければ空白で区切ったコマンド全体）
+func networkCommandArgs(command string) [][]string {
+	commands := simpleCommandArgs(command)
+	if len(commands) == 0 {
+		return [][]string{strings.Fields(command)}
+	}
+	for i, argv := range commands {
+		if argv[0] != "env" {
+			continue
+		}
+		argv = argv[1:]
+		for len(argv) > 0 && (strings.HasPrefix(argv[0], "-") || strings.Contains(argv[0], "=")) {
+			argv = argv[1:]
+		}
+		commands[i] = argv
+	}
+	return commands
+}
+
+// hasArgsPrefix は argv の先頭が args と1つずつ完全に一致するか
+func hasArgsPrefix(argv, args []string) bool {
+	if len(argv) < len(args) {
+		return false
+	}
+	for i, arg := range args {
+		if argv[i] != arg {
+			return false
+		}
+	}
+	return true
+}
+
+// WrapClient はHTTPクライアントの全リクエスト（リダイレクト含む）にポリシーを適用
+func (p *NetworkPolicy) WrapClient(client *http.Client) *http.Client {
+	if client == nil {
+		client = &http.Client{}
+	}
+	base := client.Transport
+	if _, already := base.(*policyTransport); already {
+		return client
+	}
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &policyTransport{policy: p, base: base}
+	return client
+}
+
+// policyTransport はリクエスト毎にポリシーを検証するRoundTripper
+type policyTransport struct {
+	policy *NetworkPolicy
+	base   http.RoundTripper
+}
+
+// RoundTrip はポリシー検証後にリクエストを送信
+func (t *policyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.policy.CheckURL(req.Method, req.URL.String()); err != nil {
+		return nil, err
+	}
+	return t.base.RoundTrip(req)
+}
+
+// audit は通信の監査ログを記録
+func (p *NetworkPolicy) audit(method, target, action, reason string) {
+	p.mu.RLock()
+	logger := p.auditLogger
+	p.mu.RUnlock()
+
+	if logger != nil {
+		logger.LogNetworkRequest(method, target, action, reason)
+	}
+}
+
+// matchesDomainList はホストがドメインリストに一致するか（サブドメイン含む）
+func matchesDomainList(host string, domains []string) bool {
+	for _, domain := range domains {
+		domain = strings.TrimPrefix(domain, "*.")
+		if host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeDomains はドメインリストを小文字化・空要素除去
+func normalizeDomains(domains []string) []string {
+	normalized := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		domain = strings.ToLower(strings.TrimSpace(domain))
+		if domain != "" {
+			normalized = append(normalized, domain)
+		}
+	}
+	return normalized
+}
+
+// stripPort はホスト文字列からポート番号を除去
+func stripPort(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return host
+}
+
+// isLoopbackHost はローカルホストかどうか判定
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.IsLoopback()
+	}
+	return false
+}
@@ -0,0 +1,162 @@
+package security
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// NetworkPolicy.IsHostAllowed のテスト
+func TestNetworkPolicy_IsHostAllowed(t *testing.T) {
+	tests := []struct {
+		name        string
+		mode        string
+		allowed     []string
+		blocked     []string
+		host        string
+		expectAllow bool
+	}{
+		{"Allowlisted domain", NetworkModeAllowlist, []string{"go.dev"}, nil, "go.dev", true},
+		{"Allowlisted subdomain", NetworkModeAllowlist, []string{"go.dev"}, nil, "pkg.go.dev", true},
+		{"Wildcard domain", NetworkModeAllowlist, []string{"*.example.com"}, nil, "api.example.com", true},
+		{"Suffix is not subdomain", NetworkModeAllowlist, []string{"go.dev"}, nil, "evilgo.dev", false},
+		{"Unlisted domain", NetworkModeAllowlist, []string{"go.dev"}, nil, "example.com", false},
+		{"Host with port", NetworkModeAllowlist, []string{"go.dev"}, nil, "go.dev:443", true},
+		{"Deny all", NetworkModeDenyAll, []string{"go.dev"}, nil, "go.dev", false},
+		{"Allow all", NetworkModeAllowAll, nil, nil, "example.com", true},
+		{"Blocklist wins over allow all", NetworkModeAllowAll, nil, []string{"example.com"}, "www.example.com", false},
+		{"Loopback denied by default", NetworkModeDenyAll, nil, nil, "localhost:11434", false},
+		{"Loopback IP denied by default", NetworkModeAllowlist, []string{"go.dev"}, nil, "127.0.0.1:8080", false},
+		{"Allowlisted localhost", NetworkModeAllowlist, []string{"localhost"}, nil, "localhost:8080", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			policy := NewNetworkPolicy(tt.mode, tt.allowed, tt.blocked)
+			err := policy.IsHostAllowed(tt.host)
+			if tt.expectAllow && err != nil {
+				t.Errorf("Expected %s to be allowed, got: %v", tt.host, err)
+			}
+			if !tt.expectAllow && err == nil {
+				t.Errorf("Expected %s to be blocked", tt.host)
+			}
+		})
+	}
+}
+
+// 設定で許可した場合のみローカルホストへの通信をモードに関わらず許可
+func TestNetworkPolicy_AllowLoopback(t *testing.T) {
+	policy := NewNetworkPolicy(NetworkModeDenyAll, nil, []string{"localhost"})
+	for _, host := range []string{"127.0.0.1:8080", "[::1]:11434", "localhost"} {
+		if err := policy.IsHostAllowed(host); err == nil {
+			t.Errorf("Expected %s to be blocked without allow_loopback", host)
+		}
+	}
+	if err := policy.CheckURL("GET", "http://127.0.0.1:6379/"); err == nil {
+		t.Error("Expected loopback URL to be blocked under deny_all")
+	}
+
+	policy.SetAllowLoopback(true)
+	for _, host := range []string{"127.0.0.1:8080", "[::1]:11434"} {
+		if err := policy.IsHostAllowed(host); err != nil {
+			t.Errorf("Expected %s to be allowed with allow_loopback: %v", host, err)
+		}
+	}
+	if err := policy.IsHostAllowed("localhost"); err == nil {
+		t.Error("Expected the blocklist to win over allow_loopback")
+	}
+	if err := policy.IsHostAllowed("example.com"); err == nil {
+		t.Error("Expected allow_loopback not to allow external hosts")
+	}
+}
+
+// 無効なモードは許可リストモードにフォールバック
+func TestNetworkPolicy_InvalidModeFallsBack(t *testing.T) {
+	policy := NewNetworkPolicy("unknown", nil, nil)
+	if policy.Mode() != NetworkModeAllowlist {
+		t.Errorf("Expected fallback to %s, got %s", NetworkModeAllowlist, policy.Mode())
+	}
+}
+
+// パッケージ取得コマンドの検証テスト
+func TestNetworkPolicy_CheckCommand(t *testing.T) {
+	policy := NewNetworkPolicy(NetworkModeAllowlist, []string{"registry.npmjs.org"}, nil)
+
+	if err := policy.CheckCommand("npm install lodash"); err != nil {
+		t.Errorf("Expected npm install to be allowed: %v", err)
+	}
+	if err := policy.CheckCommand("pip install requests"); err == nil {
+		t.Error("Expected pip install to be blocked")
+	}
+	if err := policy.CheckCommand("ls -la"); err != nil {
+		t.Errorf("Expected non-network command to pass: %v", err)
+	}
+
+	// 繋いだコマンドや環境変数の代入の後ろのインストールも照合する
+	for _, command := range []string{
+		"cd x && pip install evil",
+		"true; curl -s example.com | pip install -r /dev/stdin",
+		"FOO=1 pip install requests",
+		"env PIP_INDEX_URL=http://mirror pip3 install requests",
+		"ls $(pip install requests)",
+		"npm install lodash && cargo add serde",
+	} {
+		if err := policy.CheckCommand(command); err == nil {
+			t.Errorf("Expected %q to be blocked", command)
+		}
+	}
+	for _, command := range []string{
+		"cd web && npm install lodash",
+		"NODE_ENV=production npm ci",
+		"echo 'pip install requests'",
+		"npm installer",
+		"go getter ./...",
+		"cargo fetcher",
+	} {
+		if err := policy.CheckCommand(command); err != nil {
+			t.Errorf("Expected %q to be allowed: %v", command, err)
+		}
+	}
+}
+
+// Constraints経由でのネットワークポリシー適用テスト
+func TestConstraints_ValidateCommandWithNetworkPolicy(t *testing.T) {
+	constraints := NewDefaultConstraints("/tmp")
+	constraints.AllowedCommands = append(constraints.AllowedCommands, "pip")
+	constraints.NetworkPolicy = NewNetworkPolicy(NetworkModeDenyAll, nil, nil)
+
+	if err := constraints.ValidateCommand("pip install requests"); err == nil {
+		t.Error("Expected pip install to be blocked by deny_all policy")
+	}
+	if err := constraints.ValidateCommand("echo ok && FOO=1 pip install requests"); err == nil {
+		t.Error("Expected chained pip install to be blocked by deny_all policy")
+	}
+}
+
+// WrapClient によるHTTPリクエスト制御のテスト
+func TestNetworkPolicy_WrapClient(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	// ローカルホストへの通信は allow_loopback の場合のみ許可
+	policy := NewNetworkPolicy(NetworkModeDenyAll, nil, nil)
+	client := policy.WrapClient(&http.Client{})
+	if _, err := client.Get(server.URL); err == nil {
+		t.Fatal("Expected loopback request to be blocked by default")
+	}
+	policy.SetAllowLoopback(true)
+	resp, err := client.Get(server.URL)
+	if err != nil {
+		t.Fatalf("Expected loopback request to succeed: %v", err)
+	}
+	resp.Body.Close()
+
+	// 許可リスト外のホストは送信前にブロック
+	_, err = client.Get("http://example.com/")
+	if err == nil || !strings.Contains(err.Error(), "deny_all") {
+		t.Errorf("Expected request to be blocked by policy, got: %v", err)
+	}
+}
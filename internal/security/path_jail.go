@@ -0,0 +1,108 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// PathJail はツールがアクセスするパスをワークスペース内に閉じ込める
+// 相対パスはワークスペース基準で解決し、".." とシンボリックリンクを実体まで辿ってから判定する
+type PathJail struct {
+	root     string // 実体パスに解決済みのワークスペース
+	original string // 指定されたワークスペース（絶対パス）
+}
+
+// NewPathJail はワークスペースを起点にしたパス検証を作成（空なら現在のディレクトリ）
+func NewPathJail(root string) *PathJail {
+	if root == "" {
+		root = "."
+	}
+	original, err := filepath.Abs(root)
+	if err != nil {
+		original = filepath.Clean(root)
+	}
+	return &PathJail{root: resolveExisting(original), original: original}
+}
+
+// Root はワークスペースの実体パス
+func (j *PathJail) Root() string {
+	return j.root
+}
+
+// Resolve はパスを正規化した実体パスに変換し、ワークスペース外を指す場合はエラーを返す
+// 存在しないパス（作成予定のファイル等）は存在する最も近い親ディレクトリまでを解決して判定する
+func (j *PathJail) Resolve(path string) (string, error) {
+	if path == "" {
+		return "", fmt.Errorf("パスが空です")
+	}
+	if strings.ContainsRune(path, 0) {
+		return "", fmt.Errorf("不正なパスです: %q", path)
+	}
+
+	abs := path
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(j.original, abs)
+	}
+	abs = filepath.Clean(abs)
+
+	resolved := resolveExisting(abs)
+	if !isWithin(j.root, resolved) {
+		if resolved != abs && isWithin(j.root, j.rebase(abs)) {
+			return "", fmt.Errorf("シンボリックリンクがワークスペース外を指しています: %s", path)
+		}
+		return "", fmt.Errorf("ワークスペース外のパスです: %s", path)
+	}
+	return resolved, nil
+}
+
+// ResolveDir はコマンドの作業ディレクトリとして使うディレクトリを検証
+func (j *PathJail) ResolveDir(path string) (string, error) {
+	resolved, err := j.Resolve(path)
+	if err != nil {
+		return "", err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return "", fmt.Errorf("ディレクトリが存在しません: %s", path)
+	}
+	if !info.IsDir() {
+		return "", fmt.Errorf("ディレクトリではありません: %s", path)
+	}
+	return resolved, nil
+}
+
+// rebase は指定時のワークスペース表記のパスを実体側の表記に置き換える（エラー理由の判定用）
+func (j *PathJail) rebase(abs string) string {
+	if rel, err := filepath.Rel(j.original, abs); err == nil {
+		return filepath.Join(j.root, rel)
+	}
+	return abs
+}
+
+// resolveExisting は存在する最も深い祖先までシンボリックリンクを解決し、残りを連結
+func resolveExisting(path string) string {
+	var rest []string
+	current := path
+	for {
+		if resolved, err := filepath.EvalSymlinks(current); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...)
+		}
+		parent := filepath.Dir(current)
+		if parent == current {
+			return path
+		}
+		rest = append([]string{filepath.Base(current)}, rest...)
+		current = parent
+	}
+}
+
+// isWithin はpathがroot自身またはその配下か（パス要素単位で比較）
+func isWithin(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) && !filepath.IsAbs(rel)
+}
@@ -0,0 +1,111 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// シンボリックリンクと ".." を含むパス解決のテスト
+func TestPathJail_Resolve(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(workspace, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "src", "main.go"), []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.txt"), []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(workspace, "src"), filepath.Join(workspace, "inner")); err != nil {
+		t.Skipf("シンボリックリンクを作成できない環境: %v", err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "escape")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(workspace, "secret.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	jail := NewPathJail(workspace)
+
+	allowed := []string{
+		".",
+		"src/main.go",
+		"src/../src/main.go",
+		"inner/main.go",
+		"src/new_file.go",
+		"new_dir/nested/file.go",
+		filepath.Join(workspace, "src", "main.go"),
+	}
+	for _, path := range allowed {
+		if _, err := jail.Resolve(path); err != nil {
+			t.Errorf("ワークスペース内のパスが拒否された: %q: %v", path, err)
+		}
+	}
+
+	tests := []struct {
+		path   string
+		reason string
+	}{
+		{"escape/secret.txt", "シンボリックリンク"},
+		{"escape/new_file.txt", "シンボリックリンク"},
+		{"secret.txt", "シンボリックリンク"},
+		{"../outside.txt", "ワークスペース外"},
+		{"src/../../outside.txt", "ワークスペース外"},
+		{filepath.Join(outside, "secret.txt"), "ワークスペース外"},
+		{workspace + "-other/file.txt", "ワークスペース外"},
+		{"", "空"},
+	}
+	for _, tt := range tests {
+		_, err := jail.Resolve(tt.path)
+		if err == nil || !strings.Contains(err.Error(), tt.reason) {
+			t.Errorf("%q: 理由 %q を含むエラーを期待: %v", tt.path, tt.reason, err)
+		}
+	}
+}
+
+// 解決結果がシンボリックリンクの実体パスになることのテスト
+func TestPathJail_ResolveReturnsRealPath(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "src"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(workspace, "src"), filepath.Join(workspace, "link")); err != nil {
+		t.Skipf("シンボリックリンクを作成できない環境: %v", err)
+	}
+
+	jail := NewPathJail(workspace)
+	resolved, err := jail.Resolve("link/file.go")
+	if err != nil {
+		t.Fatalf("解決に失敗: %v", err)
+	}
+	if want := filepath.Join(jail.Root(), "src", "file.go"); resolved != want {
+		t.Errorf("期待値: %s, 実際値: %s", want, resolved)
+	}
+}
+
+// 作業ディレクトリ検証のテスト
+func TestPathJail_ResolveDir(t *testing.T) {
+	workspace := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(workspace, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(workspace, "file.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	jail := NewPathJail(workspace)
+	if _, err := jail.ResolveDir("sub"); err != nil {
+		t.Errorf("ワークスペース内のディレクトリが拒否された: %v", err)
+	}
+	for _, dir := range []string{"file.txt", "missing", "..", "/"} {
+		if _, err := jail.ResolveDir(dir); err == nil {
+			t.Errorf("作業ディレクトリとして許可されるべきでない: %q", dir)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package security
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"mvdan.cc/sh/v3/syntax"
+)
+
+// Permissions はユーザー設定とプロジェクトの .vyb/permissions.yaml で宣言したコマンド・パスの規則
+// 既定の制約（Constraints）に加えて評価し、deny はどの allow よりも優先する
+type Permissions struct {
+	Root          string   // パスの規則の基準ディレクトリ（プロジェクトのルート）
+	AllowCommands []string // 既定の許可リストに加えて実行を許可するコマンド
+	DenyCommands  []string // 実行を禁止するコマンド
+	DenyPaths     []string // 読み書きを禁止するパス
+	ReadOnlyPaths []string // 作成・編集・削除を禁止するパス
+}
+
+// CheckCommand はコマンド（&& や | で繋いだ各コマンドを含む）が禁止規則に一致しないか検証
+func (p *Permissions) CheckCommand(command string) error {
+	for _, part := range append([]string{strings.TrimSpace(command)}, splitSimpleCommands(command)...) {
+		if pattern, ok := matchCommandPatterns(p.DenyCommands, part); ok {
+			return fmt.Errorf("コマンド '%s' はプロジェクトの許可規則で禁止されています（%s）", part, pattern)
+		}
+	}
+	return nil
+}
+
+// AllowsCommand はコマンドを構成する全てのコマンドが許可規則に一致するか
+func (p *Permissions) AllowsCommand(command string) bool {
+	if len(p.AllowCommands) == 0 {
+		return false
+	}
+	// 前方一致で後続のコマンドまで許可しないよう、全体ではなく個々のコマンドを照合する
+	parts := splitSimpleCommands(command)
+	for _, part := range parts {
+		if !p.allowsSimpleCommand(part) {
+			return false
+		}
+	}
+	return len(parts) > 0
+}
+
+// allowsSimpleCommand は単純コマンド1つが許可規則に一致するか
+func (p *Permissions) allowsSimpleCommand(command string) bool {
+	_, ok := matchCommandPatterns(p.AllowCommands, command)
+	return ok
+}
+
+// CheckPath は解決済みの絶対パスへの操作（read, write, create, delete）が規則に違反しないか検証
+func (p *Permissions) CheckPath(absPath, operation string) error {
+	rel, ok := p.relative(absPath)
+	if !ok {
+		return nil
+	}
+	if pattern, ok := matchPathPatterns(p.DenyPaths, rel); ok {
+		return fmt.Errorf("アクセス禁止パスです（プロジェクトの許可規則 %s）: %s", pattern, rel)
+	}
+	if operation != "read" {
+		if pattern, ok := matchPathPatterns(p.ReadOnlyPaths, rel); ok {
+			return fmt.Errorf("読み取り専用のパスです（プロジェクトの許可規則 %s）: %s", pattern, rel)
+		}
+	}
+	return nil
+}
+
+// relative はルートからの相対パス（スラッシュ区切り、ルート外なら false）
+func (p *Permissions) relative(absPath string) (string, bool) {
+	root := resolveExisting(p.Root)
+	if !isWithin(root, absPath) {
+		return "", false
+	}
+	rel, err := filepath.Rel(root, absPath)
+	if err != nil || rel == "." {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// matchCommandPatterns はコマンドに一致する最初のパターンを返す
+// パターンはコマンド全体、または単語の区切りまでの前方と照合し（"git push" は "git push origin" に一致）、* は任意の文字列に一致する
+func matchCommandPatterns(patterns []string, command string) (string, bool) {
+	command = strings.Join(strings.Fields(command), " ")
+	if command == "" {
+		return "", false
+	}
+	for _, pattern := range patterns {
+		normalized := strings.Join(strings.Fields(pattern), " ")
+		expr := "^" + strings.ReplaceAll(regexp.QuoteMeta(normalized), `\*`, ".*") + "( .*)?$"
+		if re, err := regexp.Compile(expr); err == nil && re.MatchString(command) {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// matchPathPatterns はパスに一致する最初のパターンを返す
+// "/" を含まないパターンは任意の階層の名前と、それ以外はルートからのパスとその親ディレクトリと照合する
+// （"migrations" や "/db/migrations/" はその配下の全てに一致する）
+func matchPathPatterns(patterns []string, rel string) (string, bool) {
+	elements := strings.Split(rel, "/")
+	for _, pattern := range patterns {
+		normalized := strings.TrimSuffix(strings.Trim(filepath.ToSlash(pattern), "/"), "/**")
+		normalized = strings.TrimPrefix(normalized, "./")
+		if normalized == "" {
+			continue
+		}
+		if !strings.Contains(normalized, "/") {
+			for _, element := range elements {
+				if ok, _ := filepath.Match(normalized, element); ok {
+					return pattern, true
+				}
+			}
+			continue
+		}
+		for i := range elements {
+			if ok, _ := filepath.Match(normalized, strings.Join(elements[:i+1], "/")); ok {
+				return pattern, true
+			}
+		}
+	}
+	return "", false
+}
+
+// splitSimpleCommands はシェルの構文を解析し、&&・;・| 等で繋いだ個々のコマンドを返す（解析できなければ空）
+func splitSimpleCommands(command string) []string {
+	var commands []string
+	for _, args := range simpleCommandArgs(command) {
+		commands = append(commands, strings.Join(args, " "))
+	}
+	return commands
+}
+
+// simpleCommandArgs は splitSimpleCommands と同じ個々のコマンドを、解析後の引数のまま返す
+func simpleCommandArgs(command string) [][]string {
+	file, err := syntax.NewParser().Parse(strings.NewReader(command), "")
+	if err != nil {
+		return nil
+	}
+	var commands [][]string
+	syntax.Walk(file, func(node syntax.Node) bool {
+		if call, ok := node.(*syntax.CallExpr); ok && len(call.Args) > 0 {
+			args := make([]string, len(call.Args))
+			for i, word := range call.Args {
+				args[i] = wordArg(word).value
+			}
+			commands = append(commands, args)
+		}
+		return true
+	})
+	return commands
+}
@@ -175,7 +175,7 @@ func TestConstraints_IsPathAllowed(t *testing.T) {
 		{
 			name:   "Relative path in workspace",
 			path:   "file.txt",
-			expect: false, // 相対パスは現在の実装では処理されない
+			expect: true, // 相対パスはワークスペース基準で解決
 		},
 		{
 			name:   "Path traversal attempt",
@@ -187,6 +187,16 @@ func TestConstraints_IsPathAllowed(t *testing.T) {
 			path:   "/etc/passwd",
 			expect: false,
 		},
+		{
+			name:   "Sibling directory sharing the prefix",
+			path:   "/workspace2/file.txt",
+			expect: false,
+		},
+		{
+			name:   "Relative traversal",
+			path:   "sub/../../etc/passwd",
+			expect: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -304,3 +314,118 @@ func TestSecureExecutor_Integration(t *testing.T) {
 		})
 	}
 }
+
+// 許可規則はコマンドを許可リストに加えるだけで、リダイレクト・禁止フラグ・パスの検証は省かない
+func TestConstraints_PermissionsKeepPolicyChecks(t *testing.T) {
+	workspace := t.TempDir()
+	constraints := NewDefaultConstraints(workspace)
+	constraints.Permissions = &Permissions{
+		Root:          workspace,
+		AllowCommands: []string{"mytool test", "git *"},
+	}
+
+	commands := []struct {
+		command string
+		allowed bool
+	}{
+		{"mytool test", true},
+		{"mytool test > test.log", true},
+		{"git push origin main", true}, // 許可規則は既定にないサブコマンドも許可する
+		{"mytool install", false},
+		{"mytool test > /root/.bashrc", false},
+		{"make test > /root/.bashrc", false},
+		{"git -c core.pager=id log", false},
+		{"git log > /etc/cron.d/x", false},
+		{"git push --force origin main", false},
+		{"git -C /etc status", false},
+		{"git status && mytool test >> ~/.profile", false},
+	}
+	for _, tt := range commands {
+		if err := constraints.ValidateCommand(tt.command); (err == nil) != tt.allowed {
+			t.Errorf("ValidateCommand(%q) = %v, want allowed=%t", tt.command, err, tt.allowed)
+		}
+	}
+}
+
+// プロジェクトの許可規則のテスト
+func TestConstraints_Permissions(t *testing.T) {
+	workspace := t.TempDir()
+	constraints := NewDefaultConstraints(workspace)
+	constraints.Permissions = &Permissions{
+		Root:          workspace,
+		AllowCommands: []string{"mytool check", "rm -rf build"},
+		DenyCommands:  []string{"git push", "go test -run *"},
+		DenyPaths:     []string{"secrets/**"},
+		ReadOnlyPaths: []string{"/db/migrations/", "go.sum"},
+	}
+
+	commands := []struct {
+		command string
+		allowed bool
+	}{
+		{"git status", true},
+		{"git push origin main", false},
+		{"git status && git push", false},
+		{"echo ok | git   push", false},
+		{"go test -run TestX ./...", false},
+		{"mytool check --verbose", true},
+		{"mytool deploy", false},                     // 許可規則にも既定の許可リストにもない
+		{"mytool check && mytool deploy", false},     // 繋いだ全てのコマンドが許可規則に一致する必要がある
+		{"mytool check; curl http://example", false}, // 前方一致で後続のコマンドを許可しない
+		{"rm -rf build", false},                      // 既定の禁止コマンドは許可規則でも許可しない
+	}
+	for _, tt := range commands {
+		if err := constraints.ValidateCommand(tt.command); (err == nil) != tt.allowed {
+			t.Errorf("ValidateCommand(%q) = %v, want allowed=%t", tt.command, err, tt.allowed)
+		}
+	}
+
+	paths := []struct {
+		path      string
+		operation string
+		allowed   bool
+	}{
+		{"main.go", "write", true},
+		{"secrets/key.txt", "read", false},
+		{"db/migrations/001.sql", "read", true},
+		{"db/migrations/001.sql", "write", false},
+		{"db/migrations", "create", false},
+		{"vendor/mod/go.sum", "write", false},
+		{"db/migrations_old/001.sql", "write", true},
+	}
+	for _, tt := range paths {
+		_, err := constraints.ResolvePathFor(tt.path, tt.operation)
+		if (err == nil) != tt.allowed {
+			t.Errorf("ResolvePathFor(%q, %s) = %v, want allowed=%t", tt.path, tt.operation, err, tt.allowed)
+		}
+	}
+	if err := constraints.IsDirectoryCreationAllowed("db/migrations/new"); err == nil {
+		t.Error("creating a directory under a read-only path should be denied")
+	}
+}
+
+// TestConstraints_ValidateCommandParsesShell は繋いだコマンド・コマンド置換も許可リストで評価することをテストする
+func TestConstraints_ValidateCommandParsesShell(t *testing.T) {
+	constraints := NewDefaultConstraints(t.TempDir())
+	commands := []struct {
+		command string
+		allowed bool
+	}{
+		{"git status && go test ./...", true},
+		{"grep -r TODO . | wc -l", true},
+		{"make build", true}, // 既定の規則のないコマンドは名前で許可
+		{"echo hi && sudo reboot", false},
+		{"git status | sh", false},
+		{"ls $(rm -rf ~)", false},
+		{"echo `rm -rf .`", false},
+		{"(cd . && rm -rf x)", false},
+		{`find . -exec rm {} \;`, false},
+		{"cat /etc/passwd", false},
+		{"PATH=/tmp ls", false},
+	}
+	for _, tt := range commands {
+		if err := constraints.ValidateCommand(tt.command); (err == nil) != tt.allowed {
+			t.Errorf("ValidateCommand(%q) = %v, want allowed=%t", tt.command, err, tt.allowed)
+		}
+	}
+}
@@ -0,0 +1,438 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// HTTP API のパスの接頭辞
+const apiPrefix = "/v1"
+
+// 受信するリクエスト本文の最大サイズ
+const maxRequestBodySize = 10 * 1024 * 1024
+
+// WebSocket 購読者毎に溜めておけるイベント数（読むのが遅い購読者はそれ以上を取りこぼす）
+const subscriberBuffer = 256
+
+// EventQueued は他のプロンプトの処理を待っていることを知らせる session/event の種別（HTTP API のみ）
+const EventQueued = "queued"
+
+// toolLister はツール名と説明の一覧を返せるセッション管理
+type toolLister interface {
+	ToolNames() map[string]string
+}
+
+// ToolInfo は GET /v1/tools の1件
+type ToolInfo struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// SessionInfo は GET /v1/sessions の1件
+type SessionInfo struct {
+	SessionID string    `json:"session_id"`
+	CreatedAt time.Time `json:"created_at"`
+	Busy      bool      `json:"busy"` // プロンプトを処理中・順番待ち
+}
+
+// HTTPServer はチームで共有する vyb のための REST/WebSocket API（vyb serve --http）
+// 各利用者はトークンで認証し、自分が作成したセッションのみ操作できる
+type HTTPServer struct {
+	backend SessionBackend
+	version string
+	tokens  map[string]string // トークン → 利用者名
+	log     logger.Logger
+
+	// ツール実行イベントの通知先はバックエンドで1つのため、プロンプトは全セッションを通して1つずつ処理する
+	turn chan struct{}
+
+	mu       sync.Mutex
+	sessions map[string]*httpSession
+}
+
+// httpSession は HTTP API で作成したセッション
+type httpSession struct {
+	id        string
+	owner     string
+	createdAt time.Time
+
+	cancel      context.CancelFunc // 実行中（順番待ちを含む）のプロンプト
+	subscribers map[chan []byte]struct{}
+}
+
+// NewHTTPServer は利用者名 → トークンの対応で認証する HTTP API サーバーを作成
+func NewHTTPServer(backend SessionBackend, version string, tokens map[string]string, log logger.Logger) *HTTPServer {
+	byToken := make(map[string]string, len(tokens))
+	for name, token := range tokens {
+		byToken[token] = name
+	}
+	return &HTTPServer{
+		backend:  backend,
+		version:  version,
+		tokens:   byToken,
+		log:      log,
+		turn:     make(chan struct{}, 1),
+		sessions: make(map[string]*httpSession),
+	}
+}
+
+// Serve は ctx が終了するまで listener で API を提供する（TLS の証明書・鍵を指定すれば HTTPS）
+func (s *HTTPServer) Serve(ctx context.Context, listener net.Listener, certFile, keyFile string) error {
+	srv := &http.Server{
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+	}
+	done := make(chan error, 1)
+	go func() {
+		if certFile != "" {
+			done <- srv.ServeTLS(listener, certFile, keyFile)
+		} else {
+			done <- srv.Serve(listener)
+		}
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		s.cancelAll()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx)
+		return nil
+	}
+}
+
+// ServeHTTP はリクエストを認証して振り分ける
+//
+//	GET    /v1/health                  死活確認（認証不要）
+//	GET    /v1/tools                   ツールの一覧
+//	GET    /v1/sessions                自分のセッションの一覧
+//	POST   /v1/sessions                セッション作成 {"session_type": "general"}
+//	DELETE /v1/sessions/{id}           セッション終了
+//	POST   /v1/sessions/{id}/messages  プロンプト送信 {"prompt": "..."}（応答まで待つ）
+//	POST   /v1/sessions/{id}/cancel    実行中のプロンプトを中止
+//	GET    /v1/sessions/{id}/events    イベントの購読（WebSocket）
+func (s *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimSuffix(r.URL.Path, "/")
+	if path == apiPrefix+"/health" {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok", "server_name": "vyb", "server_version": s.version, "protocol_version": ProtocolVersion})
+		return
+	}
+	if !strings.HasPrefix(path, apiPrefix+"/") {
+		writeHTTPError(w, http.StatusNotFound, ErrCodeMethodNotFound, "未対応のパスです: "+r.URL.Path)
+		return
+	}
+
+	// ブラウザの WebSocket はヘッダーを付けられないため、イベントの購読のみクエリでもトークンを受け付ける
+	owner, ok := s.authenticate(r, strings.HasSuffix(path, "/events"))
+	if !ok {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="vyb"`)
+		writeHTTPError(w, http.StatusUnauthorized, ErrCodeInvalidRequest, "認証が必要です（Authorization: Bearer <token>）")
+		return
+	}
+
+	parts := strings.Split(strings.TrimPrefix(path, apiPrefix+"/"), "/")
+	switch {
+	case len(parts) == 1 && parts[0] == "tools" && r.Method == http.MethodGet:
+		s.handleTools(w)
+	case len(parts) == 1 && parts[0] == "sessions" && r.Method == http.MethodGet:
+		s.handleListSessions(w, owner)
+	case len(parts) == 1 && parts[0] == "sessions" && r.Method == http.MethodPost:
+		s.handleCreateSession(w, r, owner)
+	case len(parts) >= 2 && parts[0] == "sessions":
+		session := s.session(parts[1], owner)
+		if session == nil {
+			writeHTTPError(w, http.StatusNotFound, ErrCodeInvalidParams, "セッションが見つかりません: "+parts[1])
+			return
+		}
+		switch {
+		case len(parts) == 2 && r.Method == http.MethodDelete:
+			s.handleCloseSession(w, session)
+		case len(parts) == 3 && parts[2] == "messages" && r.Method == http.MethodPost:
+			s.handlePrompt(w, r, session)
+		case len(parts) == 3 && parts[2] == "cancel" && r.Method == http.MethodPost:
+			writeJSON(w, http.StatusOK, map[string]bool{"cancelled": s.cancelPrompt(session)})
+		case len(parts) == 3 && parts[2] == "events" && r.Method == http.MethodGet:
+			s.handleEvents(w, r, session)
+		default:
+			writeHTTPError(w, http.StatusMethodNotAllowed, ErrCodeMethodNotFound, "未対応のメソッドです: "+r.Method+" "+r.URL.Path)
+		}
+	default:
+		writeHTTPError(w, http.StatusNotFound, ErrCodeMethodNotFound, "未対応のメソッドです: "+r.Method+" "+r.URL.Path)
+	}
+}
+
+// authenticate は Bearer トークン（allowQuery なら access_token クエリも）を検証し、利用者名を返す
+func (s *HTTPServer) authenticate(r *http.Request, allowQuery bool) (string, bool) {
+	token := strings.TrimSpace(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	if token == "" && allowQuery {
+		token = r.URL.Query().Get("access_token")
+	}
+	if token == "" {
+		return "", false
+	}
+	// 一致する位置でかかる時間が変わらないよう全てのトークンと比較する
+	var owner string
+	matched := false
+	for candidate, name := range s.tokens {
+		if subtle.ConstantTimeCompare([]byte(candidate), []byte(token)) == 1 {
+			owner, matched = name, true
+		}
+	}
+	return owner, matched
+}
+
+func (s *HTTPServer) handleTools(w http.ResponseWriter) {
+	tools := []ToolInfo{}
+	if lister, ok := s.backend.(toolLister); ok {
+		for name, description := range lister.ToolNames() {
+			tools = append(tools, ToolInfo{Name: name, Description: description})
+		}
+	}
+	sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"tools": tools})
+}
+
+func (s *HTTPServer) handleListSessions(w http.ResponseWriter, owner string) {
+	s.mu.Lock()
+	sessions := []SessionInfo{}
+	for _, session := range s.sessions {
+		if session.owner == owner {
+			sessions = append(sessions, SessionInfo{SessionID: session.id, CreatedAt: session.createdAt, Busy: session.cancel != nil})
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].CreatedAt.Before(sessions[j].CreatedAt) })
+	writeJSON(w, http.StatusOK, map[string]interface{}{"sessions": sessions})
+}
+
+func (s *HTTPServer) handleCreateSession(w http.ResponseWriter, r *http.Request, owner string) {
+	var params SessionOpenParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	sessionType, err := parseSessionType(params.SessionType)
+	if err != nil {
+		writeHTTPError(w, http.StatusBadRequest, ErrCodeInvalidParams, err.Error())
+		return
+	}
+	created, err := s.backend.CreateSession(sessionType)
+	if err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	s.sessions[created.ID] = &httpSession{id: created.ID, owner: owner, createdAt: time.Now(), subscribers: make(map[chan []byte]struct{})}
+	s.mu.Unlock()
+	s.logInfo("HTTP API セッションを作成しました", map[string]interface{}{"user": owner, "session_id": created.ID})
+	writeJSON(w, http.StatusCreated, SessionOpenResult{SessionID: created.ID})
+}
+
+func (s *HTTPServer) handleCloseSession(w http.ResponseWriter, session *httpSession) {
+	s.cancelPrompt(session)
+	if err := s.backend.CloseSession(session.id); err != nil {
+		writeHTTPError(w, http.StatusInternalServerError, ErrCodeInternal, err.Error())
+		return
+	}
+	s.mu.Lock()
+	delete(s.sessions, session.id)
+	for ch := range session.subscribers {
+		close(ch)
+		delete(session.subscribers, ch)
+	}
+	s.mu.Unlock()
+	writeJSON(w, http.StatusOK, map[string]bool{"closed": true})
+}
+
+// handlePrompt はプロンプトを順番に処理し、完了まで待って結果を返す
+// 途中のツール実行・差分は events の購読者に送る。クライアントが切断すればプロンプトを中止する
+func (s *HTTPServer) handlePrompt(w http.ResponseWriter, r *http.Request, session *httpSession) {
+	var params SessionPromptParams
+	if !decodeBody(w, r, &params) {
+		return
+	}
+	if params.Prompt == "" {
+		writeHTTPError(w, http.StatusBadRequest, ErrCodeInvalidParams, "prompt は必須です")
+		return
+	}
+	params.SessionID = session.id
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	s.mu.Lock()
+	if session.cancel != nil {
+		s.mu.Unlock()
+		writeHTTPError(w, http.StatusConflict, ErrCodeSessionBusy, "このセッションは他のプロンプトを処理中です")
+		return
+	}
+	session.cancel = cancel
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		session.cancel = nil
+		s.mu.Unlock()
+	}()
+
+	select {
+	case s.turn <- struct{}{}:
+	default:
+		s.broadcast(session, NotifySessionEvent, SessionEvent{SessionID: session.id, Type: EventQueued, Message: "他のプロンプトの完了を待っています"})
+		select {
+		case s.turn <- struct{}{}:
+		case <-ctx.Done():
+			writeHTTPError(w, http.StatusConflict, ErrCodeCancelled, "プロンプトはキャンセルされました")
+			return
+		}
+	}
+	defer func() { <-s.turn }()
+
+	start := time.Now()
+	result, rpcErr := runPrompt(ctx, s.backend, params, func(method string, params interface{}) {
+		s.broadcast(session, method, params)
+	})
+	s.logInfo("HTTP API プロンプトを処理しました", map[string]interface{}{
+		"user":        session.owner,
+		"session_id":  session.id,
+		"success":     rpcErr == nil,
+		"duration_ms": time.Since(start).Milliseconds(),
+	})
+	switch {
+	case rpcErr == nil:
+		writeJSON(w, http.StatusOK, result)
+	case rpcErr.Code == ErrCodeCancelled:
+		writeHTTPError(w, http.StatusConflict, rpcErr.Code, rpcErr.Message)
+	default:
+		writeHTTPError(w, http.StatusInternalServerError, rpcErr.Code, rpcErr.Message)
+	}
+}
+
+// handleEvents は WebSocket でセッションのイベントを stdio と同じ JSON-RPC 通知の形式で送る
+func (s *HTTPServer) handleEvents(w http.ResponseWriter, r *http.Request, session *httpSession) {
+	// ハンドシェイクの完了直後に送ったプロンプトのイベントも届くよう、切り替える前に購読する
+	events := make(chan []byte, subscriberBuffer)
+	s.mu.Lock()
+	if s.sessions[session.id] != session {
+		s.mu.Unlock()
+		writeHTTPError(w, http.StatusNotFound, ErrCodeInvalidParams, "セッションが見つかりません: "+session.id)
+		return
+	}
+	session.subscribers[events] = struct{}{}
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(session.subscribers, events)
+		s.mu.Unlock()
+	}()
+
+	ws, err := upgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer ws.Close()
+
+	for {
+		select {
+		case data, ok := <-events:
+			if !ok {
+				return // セッション終了
+			}
+			if err := ws.WriteText(data); err != nil {
+				return
+			}
+		case <-ws.Closed():
+			return
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// broadcast はセッションのイベント購読者に通知を送る（詰まっている購読者には送らない）
+func (s *HTTPServer) broadcast(session *httpSession, method string, params interface{}) {
+	data, err := json.Marshal(Notification{JSONRPC: "2.0", Method: method, Params: params})
+	if err != nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for ch := range session.subscribers {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+// session は利用者のセッションを返す（他の利用者のセッションは存在しないものとして扱う）
+func (s *HTTPServer) session(id, owner string) *httpSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session := s.sessions[id]; session != nil && session.owner == owner {
+		return session
+	}
+	return nil
+}
+
+// cancelPrompt はセッションで実行中・順番待ちのプロンプトを中止
+func (s *HTTPServer) cancelPrompt(session *httpSession) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if session.cancel == nil {
+		return false
+	}
+	session.cancel()
+	return true
+}
+
+// cancelAll は全てのセッションのプロンプトを中止（サーバー停止時）
+func (s *HTTPServer) cancelAll() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, session := range s.sessions {
+		if session.cancel != nil {
+			session.cancel()
+		}
+	}
+}
+
+func (s *HTTPServer) logInfo(msg string, fields map[string]interface{}) {
+	if s.log != nil {
+		s.log.Info(msg, fields)
+	}
+}
+
+// decodeBody はJSONの本文をデコードし、失敗時はエラー応答を返す（本文なしは既定値）
+func decodeBody(w http.ResponseWriter, r *http.Request, out interface{}) bool {
+	err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBodySize)).Decode(out)
+	if err == nil || errors.Is(err, io.EOF) {
+		return true
+	}
+	writeHTTPError(w, http.StatusBadRequest, ErrCodeInvalidParams, fmt.Sprintf("パラメータ解析エラー: %v", err))
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeHTTPError は stdio と同じエラーコードを {"error": {...}} で返す
+func writeHTTPError(w http.ResponseWriter, status, code int, message string) {
+	writeJSON(w, status, map[string]*RPCError{"error": {Code: code, Message: message}})
+}
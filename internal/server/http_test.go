@@ -0,0 +1,181 @@
+package server
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/interactive"
+)
+
+// httpFakeBackend はセッション毎に異なるIDを返すテスト用のセッション管理
+type httpFakeBackend struct {
+	fakeBackend
+	created int
+}
+
+func (f *httpFakeBackend) CreateSession(sessionType interactive.CodingSessionType) (*interactive.InteractiveSession, error) {
+	f.created++
+	return &interactive.InteractiveSession{ID: fmt.Sprintf("session-%d", f.created)}, nil
+}
+
+func (f *httpFakeBackend) CloseSession(sessionID string) error {
+	return nil
+}
+
+func (f *httpFakeBackend) ToolNames() map[string]string {
+	return map[string]string{"write": "Write a file", "read": "Read a file"}
+}
+
+// apiRequest はトークン付きでAPIを呼び、ステータスとJSONの応答を返す
+func apiRequest(t *testing.T, method, url, token, body string) (int, map[string]interface{}) {
+	t.Helper()
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var decoded map[string]interface{}
+	json.NewDecoder(resp.Body).Decode(&decoded)
+	return resp.StatusCode, decoded
+}
+
+func TestHTTPServer_SessionsAreAuthenticatedAndOwned(t *testing.T) {
+	backend := &httpFakeBackend{fakeBackend: fakeBackend{filePath: filepath.Join(t.TempDir(), "main.go")}}
+	srv := httptest.NewServer(NewHTTPServer(backend, "test", map[string]string{"alice": "alice-token-0123456", "bob": "bob-token-0123456789"}, nil))
+	defer srv.Close()
+
+	if status, _ := apiRequest(t, http.MethodGet, srv.URL+"/v1/health", "", ""); status != http.StatusOK {
+		t.Errorf("死活確認は認証不要のはず: %d", status)
+	}
+	for _, token := range []string{"", "wrong-token"} {
+		if status, _ := apiRequest(t, http.MethodGet, srv.URL+"/v1/sessions", token, ""); status != http.StatusUnauthorized {
+			t.Errorf("トークン %q は拒否するはず: %d", token, status)
+		}
+	}
+
+	status, created := apiRequest(t, http.MethodPost, srv.URL+"/v1/sessions", "alice-token-0123456", `{"session_type":"general"}`)
+	if status != http.StatusCreated || created["session_id"] != "session-1" {
+		t.Fatalf("セッションを作成するはず: %d %v", status, created)
+	}
+	if status, _ := apiRequest(t, http.MethodPost, srv.URL+"/v1/sessions/session-1/messages", "bob-token-0123456789", `{"prompt":"hi"}`); status != http.StatusNotFound {
+		t.Errorf("他の利用者のセッションは操作できないはず: %d", status)
+	}
+	if _, listed := apiRequest(t, http.MethodGet, srv.URL+"/v1/sessions", "bob-token-0123456789", ""); len(listed["sessions"].([]interface{})) != 0 {
+		t.Errorf("他の利用者のセッションは一覧に出ないはず: %v", listed)
+	}
+	if status, _ := apiRequest(t, http.MethodPost, srv.URL+"/v1/sessions/session-1/messages", "alice-token-0123456", `{}`); status != http.StatusBadRequest {
+		t.Errorf("prompt がなければ 400 のはず: %d", status)
+	}
+
+	_, tools := apiRequest(t, http.MethodGet, srv.URL+"/v1/tools", "bob-token-0123456789", "")
+	if list := tools["tools"].([]interface{}); len(list) != 2 || list[0].(map[string]interface{})["name"] != "read" {
+		t.Errorf("ツールを名前順に返すはず: %v", tools)
+	}
+
+	if status, _ := apiRequest(t, http.MethodDelete, srv.URL+"/v1/sessions/session-1", "alice-token-0123456", ""); status != http.StatusOK {
+		t.Errorf("セッションを終了するはず: %d", status)
+	}
+	if status, _ := apiRequest(t, http.MethodDelete, srv.URL+"/v1/sessions/session-1", "alice-token-0123456", ""); status != http.StatusNotFound {
+		t.Errorf("終了したセッションは見つからないはず: %d", status)
+	}
+}
+
+func TestHTTPServer_PromptStreamsEventsOverWebSocket(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	backend := &httpFakeBackend{fakeBackend: fakeBackend{filePath: filePath}}
+	srv := httptest.NewServer(NewHTTPServer(backend, "test", map[string]string{"alice": "alice-token-0123456"}, nil))
+	defer srv.Close()
+	apiRequest(t, http.MethodPost, srv.URL+"/v1/sessions", "alice-token-0123456", "")
+
+	// ブラウザと同じくクエリのトークンで購読する
+	conn, err := net.Dial("tcp", strings.TrimPrefix(srv.URL, "http://"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	fmt.Fprintf(conn, "GET /v1/sessions/session-1/events?access_token=alice-token-0123456 HTTP/1.1\r\nHost: vyb\r\n"+
+		"Upgrade: websocket\r\nConnection: Upgrade\r\nSec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\nSec-WebSocket-Version: 13\r\n\r\n")
+	reader := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusSwitchingProtocols || resp.Header.Get("Sec-WebSocket-Accept") != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("WebSocket に切り替えるはず: %d %v", resp.StatusCode, resp.Header)
+	}
+
+	status, result := apiRequest(t, http.MethodPost, srv.URL+"/v1/sessions/session-1/messages", "alice-token-0123456", `{"prompt":"add main"}`)
+	if status != http.StatusOK || result["message"] != "done: add main" || result["edits"] != float64(1) {
+		t.Fatalf("プロンプトの結果を返すはず: %d %v", status, result)
+	}
+
+	var methods, types []string
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for len(types) == 0 || types[len(types)-1] != EventMessage {
+		payload := readServerFrame(t, reader)
+		var notification struct {
+			Method string `json:"method"`
+			Params struct {
+				Type string `json:"type"`
+				Diff string `json:"diff"`
+			} `json:"params"`
+		}
+		if err := json.Unmarshal(payload, &notification); err != nil {
+			t.Fatalf("通知はJSONのはず: %q", payload)
+		}
+		methods = append(methods, notification.Method)
+		types = append(types, notification.Params.Type)
+	}
+	if strings.Join(methods, ",") != "session/event,session/event,edit/apply,session/event" || strings.Join(types, ",") != "tool_use,tool_result,,message" {
+		t.Errorf("stdio と同じ通知を順に送るはず: %v %v", methods, types)
+	}
+}
+
+// readServerFrame はサーバーからのテキストフレーム（マスクなし）を1つ読む
+func readServerFrame(t *testing.T, reader *bufio.Reader) []byte {
+	t.Helper()
+	var head [2]byte
+	if _, err := io.ReadFull(reader, head[:]); err != nil {
+		t.Fatal(err)
+	}
+	if head[0] != 0x80|opText {
+		t.Fatalf("テキストフレームのはず: %x", head[0])
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		io.ReadFull(reader, ext[:])
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		io.ReadFull(reader, ext[:])
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(reader, payload); err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
@@ -0,0 +1,170 @@
+package server
+
+import "encoding/json"
+
+// エディタ連携プロトコルのバージョン
+const ProtocolVersion = "1"
+
+// JSON-RPC メソッド名（クライアント → サーバー）
+const (
+	MethodInitialize     = "initialize"
+	MethodSessionOpen    = "session/open"
+	MethodSessionPrompt  = "session/prompt"
+	MethodSessionCancel  = "session/cancel"
+	MethodSessionClose   = "session/close"
+	MethodProjectAnalyze = "project/analyze"
+	MethodCodeSuggest    = "code/suggest"
+	MethodShutdown       = "shutdown"
+	MethodExit           = "exit"
+)
+
+// JSON-RPC 通知名（サーバー → クライアント）
+const (
+	NotifySessionEvent = "session/event"
+	NotifyEditApply    = "edit/apply"
+)
+
+// session/event のイベント種別
+const (
+	EventToolUse    = "tool_use"
+	EventToolResult = "tool_result"
+	EventMessage    = "message"
+)
+
+// JSON-RPC 標準エラーコードと独自エラーコード
+const (
+	ErrCodeParse          = -32700
+	ErrCodeInvalidRequest = -32600
+	ErrCodeMethodNotFound = -32601
+	ErrCodeInvalidParams  = -32602
+	ErrCodeInternal       = -32603
+	ErrCodeSessionBusy    = -32001 // 他のプロンプトを処理中
+	ErrCodeCancelled      = -32002 // プロンプトがキャンセルされた
+)
+
+// Request は受信するJSON-RPCメッセージ（IDなしは通知）
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification はIDを持たない通知かどうか
+func (r *Request) IsNotification() bool {
+	return len(r.ID) == 0
+}
+
+// Response はJSON-RPC応答
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *RPCError       `json:"error,omitempty"`
+}
+
+// Notification はサーバーから送信する通知
+type Notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+// RPCError はJSON-RPCエラー
+type RPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error はerrorインターフェースを実装
+func (e *RPCError) Error() string {
+	return e.Message
+}
+
+// InitializeResult は initialize の応答
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocol_version"`
+	ServerName      string             `json:"server_name"`
+	ServerVersion   string             `json:"server_version"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+}
+
+// ServerCapabilities はサーバーが提供する機能
+type ServerCapabilities struct {
+	Events   bool `json:"events"`   // session/event 通知
+	Edits    bool `json:"edits"`    // edit/apply 通知
+	Cancel   bool `json:"cancel"`   // session/cancel
+	Analysis bool `json:"analysis"` // project/analyze
+	Suggest  bool `json:"suggest"`  // code/suggest
+}
+
+// SessionOpenParams は session/open のパラメータ
+type SessionOpenParams struct {
+	SessionType string `json:"session_type,omitempty"` // general, debugging, refactor, review, learning
+}
+
+// SessionOpenResult は session/open の応答
+type SessionOpenResult struct {
+	SessionID string `json:"session_id"`
+}
+
+// SessionPromptParams は session/prompt のパラメータ
+type SessionPromptParams struct {
+	SessionID string `json:"session_id"`
+	Prompt    string `json:"prompt"`
+}
+
+// SessionPromptResult は session/prompt の応答
+type SessionPromptResult struct {
+	SessionID            string `json:"session_id"`
+	Message              string `json:"message"`
+	RequiresConfirmation bool   `json:"requires_confirmation"`
+	ToolCalls            int    `json:"tool_calls"`
+	Edits                int    `json:"edits"`
+}
+
+// SessionRefParams はセッションIDのみを持つパラメータ
+type SessionRefParams struct {
+	SessionID string `json:"session_id"`
+}
+
+// ProjectAnalyzeParams は project/analyze のパラメータ（応答は interactive.AnalysisReport）
+type ProjectAnalyzeParams struct {
+	Query string `json:"query,omitempty"`
+}
+
+// CodeSuggestParams は code/suggest のパラメータ（応答は interactive.SuggestionResult）
+// code を省略するとファイルの start_line〜end_line（省略時はファイル全体）を読み込む
+type CodeSuggestParams struct {
+	FilePath    string `json:"file_path"`
+	StartLine   int    `json:"start_line,omitempty"`
+	EndLine     int    `json:"end_line,omitempty"`
+	Type        string `json:"type,omitempty"` // improvement, bugfix, optimization, refactor, documentation, security, test
+	Description string `json:"description,omitempty"`
+	Code        string `json:"code,omitempty"` // 未保存のバッファの内容（指定すればファイルは読まない）
+}
+
+// SessionEvent は session/event 通知の内容
+type SessionEvent struct {
+	SessionID  string                 `json:"session_id"`
+	Type       string                 `json:"type"`
+	StepID     string                 `json:"step_id,omitempty"`
+	Tool       string                 `json:"tool,omitempty"`
+	Parameters map[string]interface{} `json:"parameters,omitempty"`
+	Success    *bool                  `json:"success,omitempty"`
+	Output     string                 `json:"output,omitempty"`
+	Message    string                 `json:"message,omitempty"`
+}
+
+// EditApply は edit/apply 通知の内容
+type EditApply struct {
+	SessionID    string `json:"session_id"`
+	FilePath     string `json:"file_path"`
+	Diff         string `json:"diff"`
+	Applied      bool   `json:"applied"` // true: ツールで適用済み / false: 提案のみ
+	SuggestionID string `json:"suggestion_id,omitempty"`
+	Explanation  string `json:"explanation,omitempty"`
+	StartLine    int    `json:"start_line,omitempty"`
+	EndLine      int    `json:"end_line,omitempty"`
+}
@@ -0,0 +1,538 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// 受信メッセージ1行の最大サイズ
+const maxMessageSize = 10 * 1024 * 1024
+
+// ファイルを変更するツール（edit/apply 通知の対象）
+var fileMutatingTools = map[string]bool{
+	"write":     true,
+	"edit":      true,
+	"multiedit": true,
+}
+
+// SessionBackend はサーバーが利用するセッション管理機能
+type SessionBackend interface {
+	CreateSession(sessionType interactive.CodingSessionType) (*interactive.InteractiveSession, error)
+	CloseSession(sessionID string) error
+	ProcessUserInput(ctx context.Context, sessionID string, input string) (*interactive.InteractionResponse, error)
+}
+
+// executionObservable はツール実行イベントを通知できるセッション管理
+type executionObservable interface {
+	SetExecutionObserver(observer tools.ExecutionObserver)
+}
+
+// analysisReporter はプロジェクト分析を構造化して返せるセッション管理
+type analysisReporter interface {
+	AnalysisReport(ctx context.Context, query string) (*interactive.AnalysisReport, error)
+}
+
+// Server はエディタ連携用のJSON-RPCサーバー（改行区切りJSON）
+type Server struct {
+	backend SessionBackend
+	version string
+
+	writeMu sync.Mutex
+	out     io.Writer
+
+	// 実行中のプロンプト（同時に1つまで）
+	promptMu      sync.Mutex
+	activeSession string
+	cancelPrompt  context.CancelFunc
+
+	wg       sync.WaitGroup
+	shutdown bool
+}
+
+// NewServer は新しいエディタ連携サーバーを作成
+func NewServer(backend SessionBackend, version string) *Server {
+	return &Server{
+		backend: backend,
+		version: version,
+	}
+}
+
+// Serve は入力が閉じられるか exit を受信するまでメッセージを処理
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	s.out = out
+
+	// 入力終了時は実行中のプロンプト完了を待つ
+	defer s.wg.Wait()
+
+	// 読み取りはブロックするため別goroutineで行い、コンテキスト終了を監視可能にする
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(in)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			line := append([]byte(nil), scanner.Bytes()...)
+			select {
+			case lines <- line:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.cancelActivePrompt()
+			return ctx.Err()
+
+		case err := <-readErr:
+			if err != nil {
+				return fmt.Errorf("入力読み取りエラー: %w", err)
+			}
+			return nil
+
+		case line := <-lines:
+			if len(line) == 0 {
+				continue
+			}
+
+			var req Request
+			if err := json.Unmarshal(line, &req); err != nil {
+				s.writeError(nil, &RPCError{Code: ErrCodeParse, Message: fmt.Sprintf("JSON解析エラー: %v", err)})
+				continue
+			}
+
+			if req.Method == MethodExit {
+				s.cancelActivePrompt()
+				return nil
+			}
+
+			s.handle(ctx, &req)
+		}
+	}
+}
+
+// handle はメソッドを振り分ける
+func (s *Server) handle(ctx context.Context, req *Request) {
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		s.reply(req, nil, &RPCError{Code: ErrCodeInvalidRequest, Message: "無効なJSON-RPCリクエストです"})
+		return
+	}
+
+	if s.shutdown && req.Method != MethodShutdown {
+		s.reply(req, nil, &RPCError{Code: ErrCodeInvalidRequest, Message: "サーバーはシャットダウン済みです"})
+		return
+	}
+
+	switch req.Method {
+	case MethodInitialize:
+		_, analysis := s.backend.(analysisReporter)
+		_, suggest := s.backend.(interactive.Suggester)
+		s.reply(req, InitializeResult{
+			ProtocolVersion: ProtocolVersion,
+			ServerName:      "vyb",
+			ServerVersion:   s.version,
+			Capabilities:    ServerCapabilities{Events: true, Edits: true, Cancel: true, Analysis: analysis, Suggest: suggest},
+		}, nil)
+
+	case MethodSessionOpen:
+		var params SessionOpenParams
+		if !s.decodeParams(req, &params) {
+			return
+		}
+		sessionType, err := parseSessionType(params.SessionType)
+		if err != nil {
+			s.reply(req, nil, &RPCError{Code: ErrCodeInvalidParams, Message: err.Error()})
+			return
+		}
+		session, err := s.backend.CreateSession(sessionType)
+		if err != nil {
+			s.reply(req, nil, &RPCError{Code: ErrCodeInternal, Message: err.Error()})
+			return
+		}
+		s.reply(req, SessionOpenResult{SessionID: session.ID}, nil)
+
+	case MethodSessionPrompt:
+		var params SessionPromptParams
+		if !s.decodeParams(req, &params) {
+			return
+		}
+		if params.SessionID == "" || params.Prompt == "" {
+			s.reply(req, nil, &RPCError{Code: ErrCodeInvalidParams, Message: "session_id と prompt は必須です"})
+			return
+		}
+		s.startPrompt(ctx, req, params)
+
+	case MethodSessionCancel:
+		var params SessionRefParams
+		if !s.decodeParams(req, &params) {
+			return
+		}
+		s.reply(req, map[string]bool{"cancelled": s.cancelSessionPrompt(params.SessionID)}, nil)
+
+	case MethodSessionClose:
+		var params SessionRefParams
+		if !s.decodeParams(req, &params) {
+			return
+		}
+		s.cancelSessionPrompt(params.SessionID)
+		if err := s.backend.CloseSession(params.SessionID); err != nil {
+			s.reply(req, nil, &RPCError{Code: ErrCodeInvalidParams, Message: err.Error()})
+			return
+		}
+		s.reply(req, map[string]bool{"closed": true}, nil)
+
+	case MethodProjectAnalyze:
+		var params ProjectAnalyzeParams
+		if !s.decodeParams(req, &params) {
+			return
+		}
+		reporter, ok := s.backend.(analysisReporter)
+		if !ok {
+			s.reply(req, nil, &RPCError{Code: ErrCodeMethodNotFound, Message: "未対応のメソッド: " + req.Method})
+			return
+		}
+		// 分析には時間がかかるため、他のメッセージの受信を止めないよう別goroutineで行う
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			report, err := reporter.AnalysisReport(ctx, params.Query)
+			if err != nil {
+				s.reply(req, nil, &RPCError{Code: ErrCodeInternal, Message: err.Error()})
+				return
+			}
+			s.reply(req, report, nil)
+		}()
+
+	case MethodCodeSuggest:
+		var params CodeSuggestParams
+		if !s.decodeParams(req, &params) {
+			return
+		}
+		suggester, ok := s.backend.(interactive.Suggester)
+		if !ok {
+			s.reply(req, nil, &RPCError{Code: ErrCodeMethodNotFound, Message: "未対応のメソッド: " + req.Method})
+			return
+		}
+		request, err := suggestionRequest(params)
+		if err != nil {
+			s.reply(req, nil, &RPCError{Code: ErrCodeInvalidParams, Message: err.Error()})
+			return
+		}
+		// 提案は他のメッセージの受信を止めないよう別goroutineで生成する（セッションのプロンプトとは独立）
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			result, err := suggester.Suggest(ctx, request)
+			if err != nil {
+				s.reply(req, nil, &RPCError{Code: ErrCodeInternal, Message: err.Error()})
+				return
+			}
+			s.reply(req, result, nil)
+		}()
+
+	case MethodShutdown:
+		s.shutdown = true
+		s.cancelActivePrompt()
+		s.reply(req, map[string]bool{"ok": true}, nil)
+
+	default:
+		s.reply(req, nil, &RPCError{Code: ErrCodeMethodNotFound, Message: "未対応のメソッド: " + req.Method})
+	}
+}
+
+// suggestionRequest は code/suggest のパラメータから提案リクエストを作る
+func suggestionRequest(params CodeSuggestParams) (*interactive.SuggestionRequest, error) {
+	if params.FilePath == "" {
+		return nil, fmt.Errorf("file_path は必須です")
+	}
+	suggestionType, err := interactive.ParseSuggestionType(params.Type)
+	if err != nil {
+		return nil, err
+	}
+	if params.Code == "" {
+		return interactive.NewFileSuggestionRequest(params.FilePath, params.StartLine, params.EndLine, suggestionType, params.Description)
+	}
+	return &interactive.SuggestionRequest{
+		Type:            suggestionType,
+		FilePath:        params.FilePath,
+		Code:            params.Code,
+		LineRange:       [2]int{params.StartLine, params.EndLine},
+		UserDescription: params.Description,
+		Context:         make(map[string]string),
+		Priority:        5,
+	}, nil
+}
+
+// startPrompt はプロンプトをバックグラウンドで処理し、完了時に応答を返す
+func (s *Server) startPrompt(ctx context.Context, req *Request, params SessionPromptParams) {
+	s.promptMu.Lock()
+	if s.cancelPrompt != nil {
+		s.promptMu.Unlock()
+		s.reply(req, nil, &RPCError{Code: ErrCodeSessionBusy, Message: "他のプロンプトを処理中です"})
+		return
+	}
+	promptCtx, cancel := context.WithCancel(ctx)
+	s.activeSession = params.SessionID
+	s.cancelPrompt = cancel
+	s.promptMu.Unlock()
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() {
+			s.promptMu.Lock()
+			s.activeSession = ""
+			s.cancelPrompt = nil
+			s.promptMu.Unlock()
+			cancel()
+		}()
+
+		result, rpcErr := s.runPrompt(promptCtx, params)
+		s.reply(req, result, rpcErr)
+	}()
+}
+
+// runPrompt はツール実行イベントを通知しながらユーザー入力を処理
+func (s *Server) runPrompt(ctx context.Context, params SessionPromptParams) (*SessionPromptResult, *RPCError) {
+	return runPrompt(ctx, s.backend, params, s.notify)
+}
+
+// runPrompt はツール実行イベント・差分を notify で通知しながらユーザー入力を処理（stdio・HTTP で共通）
+func runPrompt(ctx context.Context, backend SessionBackend, params SessionPromptParams, notify func(method string, params interface{})) (*SessionPromptResult, *RPCError) {
+	observer := &promptObserver{notify: notify, sessionID: params.SessionID, snapshots: make(map[string]string)}
+	if observable, ok := backend.(executionObservable); ok {
+		observable.SetExecutionObserver(observer)
+		defer observable.SetExecutionObserver(nil)
+	}
+
+	response, err := backend.ProcessUserInput(ctx, params.SessionID, params.Prompt)
+	if ctx.Err() == context.Canceled {
+		return nil, &RPCError{Code: ErrCodeCancelled, Message: "プロンプトはキャンセルされました"}
+	}
+	if err != nil {
+		return nil, &RPCError{Code: ErrCodeInternal, Message: err.Error()}
+	}
+
+	// 未適用のコード提案は差分として通知
+	for _, suggestion := range response.Suggestions {
+		if suggestion == nil || suggestion.FilePath == "" {
+			continue
+		}
+		observer.notifyEdit(EditApply{
+			FilePath:     suggestion.FilePath,
+			Diff:         diff.Unified("a/"+suggestion.FilePath, "b/"+suggestion.FilePath, suggestion.OriginalCode, suggestion.SuggestedCode, 3),
+			Applied:      suggestion.Applied,
+			SuggestionID: suggestion.ID,
+			Explanation:  suggestion.Explanation,
+			StartLine:    suggestion.LineRange[0],
+			EndLine:      suggestion.LineRange[1],
+		})
+	}
+
+	notify(NotifySessionEvent, SessionEvent{
+		SessionID: params.SessionID,
+		Type:      EventMessage,
+		Message:   response.Message,
+	})
+
+	return &SessionPromptResult{
+		SessionID:            params.SessionID,
+		Message:              response.Message,
+		RequiresConfirmation: response.RequiresConfirmation,
+		ToolCalls:            observer.toolCalls,
+		Edits:                observer.edits,
+	}, nil
+}
+
+// cancelSessionPrompt は指定セッションの実行中プロンプトをキャンセル
+func (s *Server) cancelSessionPrompt(sessionID string) bool {
+	s.promptMu.Lock()
+	defer s.promptMu.Unlock()
+
+	if s.cancelPrompt == nil || s.activeSession != sessionID {
+		return false
+	}
+	s.cancelPrompt()
+	return true
+}
+
+// cancelActivePrompt は実行中のプロンプトがあればキャンセル
+func (s *Server) cancelActivePrompt() {
+	s.promptMu.Lock()
+	defer s.promptMu.Unlock()
+
+	if s.cancelPrompt != nil {
+		s.cancelPrompt()
+	}
+}
+
+// decodeParams はパラメータをデコードし、失敗時はエラー応答を返す
+func (s *Server) decodeParams(req *Request, out interface{}) bool {
+	if len(req.Params) == 0 {
+		return true
+	}
+	if err := json.Unmarshal(req.Params, out); err != nil {
+		s.reply(req, nil, &RPCError{Code: ErrCodeInvalidParams, Message: fmt.Sprintf("パラメータ解析エラー: %v", err)})
+		return false
+	}
+	return true
+}
+
+// reply はリクエストへの応答を送信（通知には応答しない）
+func (s *Server) reply(req *Request, result interface{}, rpcErr *RPCError) {
+	if req.IsNotification() {
+		return
+	}
+	if rpcErr != nil {
+		s.writeError(req.ID, rpcErr)
+		return
+	}
+	if result == nil {
+		result = struct{}{}
+	}
+	s.write(Response{JSONRPC: "2.0", ID: req.ID, Result: result})
+}
+
+// writeError はエラー応答を送信
+func (s *Server) writeError(id json.RawMessage, rpcErr *RPCError) {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	s.write(Response{JSONRPC: "2.0", ID: id, Error: rpcErr})
+}
+
+// notify はクライアントへ通知を送信
+func (s *Server) notify(method string, params interface{}) {
+	s.write(Notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// write はメッセージを1行のJSONとして書き出す
+func (s *Server) write(message interface{}) {
+	data, err := json.Marshal(message)
+	if err != nil {
+		return
+	}
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.out.Write(append(data, '\n'))
+}
+
+// promptObserver はツール実行を session/event・edit/apply 通知に変換
+type promptObserver struct {
+	notify    func(method string, params interface{})
+	sessionID string
+
+	mu        sync.Mutex
+	snapshots map[string]string // ステップID → 変更前のファイル内容
+	toolCalls int
+	edits     int
+}
+
+// OnStepStart はツール実行開始を通知し、ファイル変更前の内容を記録
+func (o *promptObserver) OnStepStart(step tools.ExecutionStep) {
+	if path := mutatedFilePath(step); path != "" {
+		content, _ := os.ReadFile(path)
+		o.mu.Lock()
+		o.snapshots[step.StepID] = string(content)
+		o.mu.Unlock()
+	}
+
+	o.notify(NotifySessionEvent, SessionEvent{
+		SessionID:  o.sessionID,
+		Type:       EventToolUse,
+		StepID:     step.StepID,
+		Tool:       step.Tool,
+		Parameters: step.Parameters,
+	})
+}
+
+// OnStepComplete はツール実行結果を通知し、ファイル変更があれば差分を送信
+func (o *promptObserver) OnStepComplete(step tools.ExecutionStep) {
+	success := step.Success
+	event := SessionEvent{
+		SessionID:  o.sessionID,
+		Type:       EventToolResult,
+		StepID:     step.StepID,
+		Tool:       step.Tool,
+		Parameters: step.Parameters,
+		Success:    &success,
+	}
+	if step.Result != nil {
+		event.Output = step.Result.Content
+		event.Message = step.Result.Error
+	}
+
+	o.mu.Lock()
+	o.toolCalls++
+	before, tracked := o.snapshots[step.StepID]
+	delete(o.snapshots, step.StepID)
+	o.mu.Unlock()
+
+	o.notify(NotifySessionEvent, event)
+
+	if !tracked || !step.Success {
+		return
+	}
+	path := mutatedFilePath(step)
+	after, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+	oldName := "a/" + path
+	if before == "" {
+		oldName = "/dev/null"
+	}
+	if patch := diff.Unified(oldName, "b/"+path, before, string(after), 3); patch != "" {
+		o.notifyEdit(EditApply{FilePath: path, Diff: patch, Applied: true})
+	}
+}
+
+// notifyEdit は edit/apply 通知を送信
+func (o *promptObserver) notifyEdit(edit EditApply) {
+	o.mu.Lock()
+	o.edits++
+	o.mu.Unlock()
+
+	edit.SessionID = o.sessionID
+	o.notify(NotifyEditApply, edit)
+}
+
+// mutatedFilePath はファイル変更ツールの対象パスを返す
+func mutatedFilePath(step tools.ExecutionStep) string {
+	if !fileMutatingTools[step.Tool] {
+		return ""
+	}
+	path, _ := step.Parameters["file_path"].(string)
+	return path
+}
+
+// parseSessionType はセッション種別名を変換
+func parseSessionType(name string) (interactive.CodingSessionType, error) {
+	switch name {
+	case "", "general":
+		return interactive.CodingSessionTypeGeneral, nil
+	case "debugging":
+		return interactive.CodingSessionTypeDebugging, nil
+	case "refactor":
+		return interactive.CodingSessionTypeRefactor, nil
+	case "review":
+		return interactive.CodingSessionTypeReview, nil
+	case "learning":
+		return interactive.CodingSessionTypeLearning, nil
+	default:
+		return interactive.CodingSessionTypeGeneral, fmt.Errorf("未対応のセッション種別: %s", name)
+	}
+}
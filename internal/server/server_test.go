@@ -0,0 +1,250 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/interactive"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// fakeBackend はテスト用のセッション管理（writeツールの実行を模擬）
+type fakeBackend struct {
+	observer tools.ExecutionObserver
+	filePath string
+}
+
+func (f *fakeBackend) CreateSession(sessionType interactive.CodingSessionType) (*interactive.InteractiveSession, error) {
+	return &interactive.InteractiveSession{ID: "session-1"}, nil
+}
+
+func (f *fakeBackend) CloseSession(sessionID string) error {
+	if sessionID != "session-1" {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	return nil
+}
+
+func (f *fakeBackend) ProcessUserInput(ctx context.Context, sessionID string, input string) (*interactive.InteractionResponse, error) {
+	step := tools.ExecutionStep{
+		StepID:     "step_1",
+		Tool:       "write",
+		Parameters: map[string]interface{}{"file_path": f.filePath},
+		StartTime:  time.Now(),
+	}
+	f.observer.OnStepStart(step)
+	if err := os.WriteFile(f.filePath, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		return nil, err
+	}
+	step.EndTime = time.Now()
+	step.Success = true
+	step.Result = &tools.ToolResponse{Success: true, Content: "written"}
+	f.observer.OnStepComplete(step)
+
+	return &interactive.InteractionResponse{SessionID: sessionID, Message: "done: " + input}, nil
+}
+
+func (f *fakeBackend) SetExecutionObserver(observer tools.ExecutionObserver) {
+	f.observer = observer
+}
+
+func (f *fakeBackend) AnalysisReport(ctx context.Context, query string) (*interactive.AnalysisReport, error) {
+	return &interactive.AnalysisReport{
+		Query:       query,
+		ProjectPath: "/project",
+		Changes:     interactive.AnalyzeDiff("diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1 +1,2 @@\n package main\n+func main() {}\n"),
+	}, nil
+}
+
+func (f *fakeBackend) Suggest(ctx context.Context, request *interactive.SuggestionRequest) (*interactive.SuggestionResult, error) {
+	return &interactive.SuggestionResult{
+		Type:          request.Type.String(),
+		FilePath:      request.FilePath,
+		StartLine:     request.LineRange[0],
+		EndLine:       request.LineRange[1],
+		OriginalCode:  request.Code,
+		SuggestedCode: strings.ToUpper(request.Code),
+		Confidence:    0.7,
+	}, nil
+}
+
+// 1行ずつJSONメッセージをデコード
+func decodeMessages(t *testing.T, output string) []map[string]interface{} {
+	t.Helper()
+	var messages []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		var msg map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &msg); err != nil {
+			t.Fatalf("Invalid JSON line %q: %v", line, err)
+		}
+		messages = append(messages, msg)
+	}
+	return messages
+}
+
+func TestServer_PromptEmitsEventsAndEdits(t *testing.T) {
+	tempDir := t.TempDir()
+	filePath := filepath.Join(tempDir, "main.go")
+	if err := os.WriteFile(filePath, []byte("package main\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"session/open","params":{}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"session/prompt","params":{"session_id":"session-1","prompt":"add main"}}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	srv := NewServer(&fakeBackend{filePath: filePath}, "test")
+	if err := srv.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	messages := decodeMessages(t, out.String())
+
+	var sawToolUse, sawEdit bool
+	var promptResult map[string]interface{}
+	for _, msg := range messages {
+		switch msg["method"] {
+		case NotifySessionEvent:
+			params := msg["params"].(map[string]interface{})
+			if params["type"] == EventToolUse {
+				sawToolUse = true
+			}
+		case NotifyEditApply:
+			params := msg["params"].(map[string]interface{})
+			sawEdit = true
+			if params["applied"] != true || !strings.Contains(params["diff"].(string), "+func main() {}") {
+				t.Errorf("Unexpected edit payload: %v", params)
+			}
+		}
+		if id, ok := msg["id"].(float64); ok && id == 3 {
+			promptResult, _ = msg["result"].(map[string]interface{})
+		}
+	}
+
+	if !sawToolUse {
+		t.Error("Expected tool_use event")
+	}
+	if !sawEdit {
+		t.Error("Expected edit/apply notification")
+	}
+	if promptResult == nil || promptResult["message"] != "done: add main" || promptResult["edits"].(float64) != 1 {
+		t.Errorf("Unexpected prompt result: %v", promptResult)
+	}
+}
+
+func TestServer_ErrorResponses(t *testing.T) {
+	input := strings.Join([]string{
+		`not json`,
+		`{"jsonrpc":"2.0","id":1,"method":"unknown/method"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"session/prompt","params":{"session_id":""}}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	srv := NewServer(&fakeBackend{}, "test")
+	if err := srv.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	messages := decodeMessages(t, out.String())
+	expectedCodes := []float64{ErrCodeParse, ErrCodeMethodNotFound, ErrCodeInvalidParams}
+	if len(messages) != len(expectedCodes) {
+		t.Fatalf("Expected %d responses, got %d: %s", len(expectedCodes), len(messages), out.String())
+	}
+	for i, msg := range messages {
+		rpcErr, ok := msg["error"].(map[string]interface{})
+		if !ok || rpcErr["code"] != expectedCodes[i] {
+			t.Errorf("Response %d: expected error code %v, got %v", i, expectedCodes[i], msg)
+		}
+	}
+}
+
+func TestServer_ProjectAnalyze(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize"}`,
+		`{"jsonrpc":"2.0","id":2,"method":"project/analyze","params":{"query":"risks"}}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	srv := NewServer(&fakeBackend{}, "test")
+	if err := srv.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	var capabilities, report map[string]interface{}
+	for _, msg := range decodeMessages(t, out.String()) {
+		result, _ := msg["result"].(map[string]interface{})
+		switch msg["id"] {
+		case float64(1):
+			capabilities, _ = result["capabilities"].(map[string]interface{})
+		case float64(2):
+			report = result
+		}
+	}
+	if capabilities["analysis"] != true {
+		t.Errorf("Expected the analysis capability, got %v", capabilities)
+	}
+	if report == nil || report["query"] != "risks" {
+		t.Fatalf("Unexpected analysis result: %v", report)
+	}
+	changes, _ := report["changes"].(map[string]interface{})
+	if changes["added_lines"] != float64(1) {
+		t.Errorf("Expected structured diff analysis, got %v", changes)
+	}
+}
+
+func TestServer_CodeSuggest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "main.go")
+	if err := os.WriteFile(path, []byte("package main\n\nfunc main() {}\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize"}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"code/suggest","params":{"file_path":%q,"start_line":3,"end_line":3,"type":"refactor"}}`, path),
+		`{"jsonrpc":"2.0","id":3,"method":"code/suggest","params":{"file_path":"buffer.go","code":"x := 1","type":"rewrite"}}`,
+		`{"jsonrpc":"2.0","id":4,"method":"code/suggest","params":{"file_path":"buffer.go","code":"x := 1"}}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	srv := NewServer(&fakeBackend{}, "test")
+	if err := srv.Serve(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("Serve failed: %v", err)
+	}
+
+	results := map[float64]map[string]interface{}{}
+	var capabilities map[string]interface{}
+	for _, msg := range decodeMessages(t, out.String()) {
+		id, _ := msg["id"].(float64)
+		if rpcErr, ok := msg["error"].(map[string]interface{}); ok {
+			results[id] = rpcErr
+			continue
+		}
+		result, _ := msg["result"].(map[string]interface{})
+		if id == 1 {
+			capabilities, _ = result["capabilities"].(map[string]interface{})
+		}
+		results[id] = result
+	}
+	if capabilities["suggest"] != true {
+		t.Errorf("Expected the suggest capability, got %v", capabilities)
+	}
+	if got := results[2]; got["type"] != "refactor" || got["original_code"] != "func main() {}" || got["start_line"] != float64(3) {
+		t.Errorf("Expected the file range to be suggested on, got %v", got)
+	}
+	if got := results[3]; got["code"] != float64(ErrCodeInvalidParams) {
+		t.Errorf("Expected invalid params for an unknown type, got %v", got)
+	}
+	if got := results[4]; got["suggested_code"] != "X := 1" || got["type"] != "improvement" {
+		t.Errorf("Expected the buffer content to be used, got %v", got)
+	}
+}
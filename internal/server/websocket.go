@@ -0,0 +1,191 @@
+package server
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RFC 6455 のハンドシェイクで Sec-WebSocket-Key に連結する固定値
+const websocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// クライアントから受け付けるフレームの最大サイズ（イベントの購読のみで、クライアントからはほぼ制御フレームだけ）
+const maxClientFrameSize = 64 * 1024
+
+// WebSocket のオペコード
+const (
+	opText  = 0x1
+	opClose = 0x8
+	opPing  = 0x9
+	opPong  = 0xA
+)
+
+// websocketConn はサーバーからテキストメッセージを送るだけの最小限の WebSocket 接続
+// クライアントからのデータフレームは読み捨て、ping には pong、close には close で応える
+type websocketConn struct {
+	conn    net.Conn
+	reader  *bufio.Reader
+	writeMu sync.Mutex
+	closed  chan struct{}
+	once    sync.Once
+}
+
+// upgradeWebSocket は HTTP リクエストを WebSocket 接続に切り替える（失敗時はエラー応答を送信済み）
+func upgradeWebSocket(w http.ResponseWriter, r *http.Request) (*websocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if r.Method != http.MethodGet || !headerContains(r.Header, "Connection", "upgrade") ||
+		!headerContains(r.Header, "Upgrade", "websocket") || key == "" {
+		http.Error(w, "WebSocket のアップグレード要求ではありません", http.StatusBadRequest)
+		return nil, errors.New("not a websocket handshake")
+	}
+	if r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "未対応の WebSocket バージョンです", http.StatusUpgradeRequired)
+		return nil, errors.New("unsupported websocket version")
+	}
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "WebSocket に対応していません", http.StatusInternalServerError)
+		return nil, errors.New("response writer cannot be hijacked")
+	}
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	sum := sha1.Sum([]byte(key + websocketGUID))
+	handshake := "HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + base64.StdEncoding.EncodeToString(sum[:]) + "\r\n\r\n"
+	if _, err := conn.Write([]byte(handshake)); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	ws := &websocketConn{conn: conn, reader: rw.Reader, closed: make(chan struct{})}
+	go ws.readLoop()
+	return ws, nil
+}
+
+// headerContains はカンマ区切りのヘッダー値に token が含まれるか（大文字小文字を区別しない）
+func headerContains(header http.Header, name, token string) bool {
+	for _, value := range header.Values(name) {
+		for _, part := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(part), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WriteText はテキストメッセージを1フレームで送る
+func (ws *websocketConn) WriteText(data []byte) error {
+	return ws.writeFrame(opText, data)
+}
+
+// Closed はクライアントが切断・close を送ったときに閉じられる
+func (ws *websocketConn) Closed() <-chan struct{} {
+	return ws.closed
+}
+
+// Close は close フレームを送って接続を閉じる
+func (ws *websocketConn) Close() error {
+	ws.writeFrame(opClose, []byte{0x03, 0xE8}) // 1000: 正常終了
+	ws.markClosed()
+	return ws.conn.Close()
+}
+
+func (ws *websocketConn) markClosed() {
+	ws.once.Do(func() { close(ws.closed) })
+}
+
+// writeFrame はサーバーからのフレーム（マスクなし）を書き出す
+func (ws *websocketConn) writeFrame(opcode byte, payload []byte) error {
+	header := []byte{0x80 | opcode}
+	switch length := len(payload); {
+	case length < 126:
+		header = append(header, byte(length))
+	case length <= 0xFFFF:
+		header = append(header, 126, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(length))
+	default:
+		header = append(header, 127, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(length))
+	}
+
+	ws.writeMu.Lock()
+	defer ws.writeMu.Unlock()
+	ws.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	_, err := ws.conn.Write(append(header, payload...))
+	return err
+}
+
+// readLoop はクライアントからのフレームを読み、制御フレームに応答する
+func (ws *websocketConn) readLoop() {
+	defer ws.markClosed()
+	for {
+		opcode, payload, err := ws.readFrame()
+		if err != nil {
+			return
+		}
+		switch opcode {
+		case opPing:
+			ws.writeFrame(opPong, payload)
+		case opClose:
+			ws.writeFrame(opClose, payload)
+			ws.conn.Close()
+			return
+		}
+	}
+}
+
+// readFrame はクライアントからのフレーム（必ずマスクされている）を1つ読む
+func (ws *websocketConn) readFrame() (byte, []byte, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(ws.reader, head[:]); err != nil {
+		return 0, nil, err
+	}
+	opcode := head[0] & 0x0F
+	if head[1]&0x80 == 0 {
+		return 0, nil, errors.New("マスクされていないクライアントフレーム")
+	}
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(ws.reader, ext[:]); err != nil {
+			return 0, nil, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+	if length > maxClientFrameSize {
+		return 0, nil, fmt.Errorf("クライアントフレームが大きすぎます: %d バイト", length)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(ws.reader, mask[:]); err != nil {
+		return 0, nil, err
+	}
+	payload := make([]byte, length)
+	if _, err := io.ReadFull(ws.reader, payload); err != nil {
+		return 0, nil, err
+	}
+	for i := range payload {
+		payload[i] ^= mask[i%4]
+	}
+	return opcode, payload, nil
+}
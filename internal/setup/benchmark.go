@@ -0,0 +1,60 @@
+package setup
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// benchmarkPrompt は応答速度の計測に使う短いプロンプト
+const benchmarkPrompt = "Write a Go function that returns the sum of two ints. Reply with the code only."
+
+// 実用上の目安となる生成速度（トークン/秒）
+const minUsableTokensPerSecond = 5.0
+
+// BenchmarkResult は短いプロンプトでの計測結果
+type BenchmarkResult struct {
+	Model            string        `json:"model"`
+	Duration         time.Duration `json:"duration"`
+	CompletionTokens int           `json:"completion_tokens"`
+	TokensPerSecond  float64       `json:"tokens_per_second"` // トークン数が報告されない場合は0
+}
+
+// Slow は対話に使うには遅すぎるか
+func (r *BenchmarkResult) Slow() bool {
+	return r.TokensPerSecond > 0 && r.TokensPerSecond < minUsableTokensPerSecond
+}
+
+// String は "1.8s, 42 tokens (23.1 tok/s)" 形式の表示
+func (r *BenchmarkResult) String() string {
+	if r.TokensPerSecond == 0 {
+		return fmt.Sprintf("%.1fs", r.Duration.Seconds())
+	}
+	return fmt.Sprintf("%.1fs, %d tokens (%.1f tok/s)", r.Duration.Seconds(), r.CompletionTokens, r.TokensPerSecond)
+}
+
+// Benchmark は短いプロンプトを1回送信して応答時間と生成速度を計測（初回はモデルの読み込み時間を含む）
+func Benchmark(ctx context.Context, provider llm.Provider, model string) (*BenchmarkResult, error) {
+	maxTokens := 128
+	start := time.Now()
+	resp, err := provider.Chat(ctx, llm.ChatRequest{
+		Model:     model,
+		Messages:  []llm.ChatMessage{{Role: "user", Content: benchmarkPrompt}},
+		MaxTokens: &maxTokens,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ベンチマーク実行エラー: %w", err)
+	}
+
+	result := &BenchmarkResult{
+		Model:            model,
+		Duration:         time.Since(start),
+		CompletionTokens: resp.CompletionTokens(),
+	}
+	if result.CompletionTokens > 0 && result.Duration > 0 {
+		result.TokensPerSecond = float64(result.CompletionTokens) / result.Duration.Seconds()
+	}
+	return result, nil
+}
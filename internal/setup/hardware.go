@@ -0,0 +1,104 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// Hardware はモデル選択に使うマシンの性能
+type Hardware struct {
+	OS        string `json:"os"`
+	Arch      string `json:"arch"`
+	CPUs      int    `json:"cpus"`
+	RAMBytes  int64  `json:"ram_bytes"`      // 物理メモリ（不明なら0）
+	VRAMBytes int64  `json:"vram_bytes"`     // NVIDIA GPUのメモリ（なければ0）
+	GPU       string `json:"gpu,omitempty"`  // GPU名
+	Unified   bool   `json:"unified_memory"` // Apple Silicon等のユニファイドメモリ
+}
+
+// DetectHardware はメモリ量とGPUを検出（取得できない項目は0のまま）
+func DetectHardware(ctx context.Context) Hardware {
+	hw := Hardware{
+		OS:      runtime.GOOS,
+		Arch:    runtime.GOARCH,
+		CPUs:    runtime.NumCPU(),
+		Unified: runtime.GOOS == "darwin" && runtime.GOARCH == "arm64",
+	}
+
+	switch runtime.GOOS {
+	case "linux":
+		if data, err := os.ReadFile("/proc/meminfo"); err == nil {
+			hw.RAMBytes = parseMeminfo(string(data))
+		}
+	case "darwin":
+		if output, err := exec.CommandContext(ctx, "sysctl", "-n", "hw.memsize").Output(); err == nil {
+			hw.RAMBytes, _ = strconv.ParseInt(strings.TrimSpace(string(output)), 10, 64)
+		}
+	}
+
+	if output, err := exec.CommandContext(ctx, "nvidia-smi", "--query-gpu=name,memory.total", "--format=csv,noheader,nounits").Output(); err == nil {
+		hw.GPU, hw.VRAMBytes = parseNvidiaSMI(string(output))
+	}
+	return hw
+}
+
+// MemoryBudget はモデルに割り当てられるメモリの目安
+// GPUがあればVRAM、ユニファイドメモリなら物理メモリの2/3、CPU推論なら半分
+func (hw Hardware) MemoryBudget() int64 {
+	switch {
+	case hw.VRAMBytes > 0:
+		return hw.VRAMBytes
+	case hw.Unified:
+		return hw.RAMBytes * 2 / 3
+	default:
+		return hw.RAMBytes / 2
+	}
+}
+
+// parseMeminfo は /proc/meminfo の MemTotal をバイト数で返す
+func parseMeminfo(data string) int64 {
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "MemTotal:" {
+			kb, err := strconv.ParseInt(fields[1], 10, 64)
+			if err != nil {
+				return 0
+			}
+			return kb * 1024
+		}
+	}
+	return 0
+}
+
+// parseNvidiaSMI は nvidia-smi のCSV出力からGPU名と合計VRAM（バイト）を返す
+func parseNvidiaSMI(output string) (string, int64) {
+	var names []string
+	var total int64
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, ",")
+		if len(fields) != 2 {
+			continue
+		}
+		mib, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		names = append(names, strings.TrimSpace(fields[0]))
+		total += mib * 1024 * 1024
+	}
+	return strings.Join(names, ", "), total
+}
+
+// FormatBytes はGB単位の表示
+func FormatBytes(bytes int64) string {
+	if bytes <= 0 {
+		return "unknown"
+	}
+	return strconv.FormatFloat(float64(bytes)/(1<<30), 'f', 1, 64) + " GB"
+}
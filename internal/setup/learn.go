@@ -0,0 +1,566 @@
+package setup
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// vyb learn が VYB.md に書く節の開始・終了（節の外のユーザーのメモは変更しない）
+const (
+	OnboardingStart = "<!-- vyb learn: start -->"
+	OnboardingEnd   = "<!-- vyb learn: end -->"
+)
+
+// 調査の上限（VYB.md は毎ターンのプロンプトに含まれるため、節を短く保つ）
+const (
+	learnLayoutDepth     = 2
+	learnLayoutEntries   = 80
+	learnMaxEntryPoints  = 10
+	learnMaxLanguages    = 6
+	learnCommentFiles    = 200       // コメントの言語を調べるファイル数
+	learnCommitSubjects  = 50        // コミットの書式を調べる件数
+	learnReadmeBytes     = 4 * 1024  // 要約に渡す README の先頭
+	learnEntrySnippet    = 1536      // 要約に渡す入口のファイルの先頭
+	learnMaxSnippetBytes = 12 * 1024 // 要約に渡すファイルの合計
+)
+
+// LanguageCount は言語毎のソースファイル数
+type LanguageCount struct {
+	Language string `json:"language"`
+	Files    int    `json:"files"`
+}
+
+// Survey はプロジェクトの初回調査の結果（構成・入口・コマンド・規約）
+type Survey struct {
+	Name        string                `json:"name"`
+	Root        string                `json:"root"`
+	Languages   []LanguageCount       `json:"languages"`
+	BuildSystem string                `json:"build_system,omitempty"`
+	Commands    map[tasks.Kind]string `json:"commands,omitempty"`
+	Layout      string                `json:"layout"`       // ディレクトリツリー（簡潔な形式）
+	EntryPoints []string              `json:"entry_points"` // 実行の入口（ルートからの相対パスと起動方法）
+	Conventions []string              `json:"conventions"`  // 検出した規約
+	Docs        []string              `json:"docs"`         // README 等の文書
+
+	entryFiles []string // 要約に添付する入口のファイル
+}
+
+// languageByExt はソースファイルの拡張子と言語
+var languageByExt = map[string]string{
+	".go": "Go", ".ts": "TypeScript", ".tsx": "TypeScript", ".js": "JavaScript", ".jsx": "JavaScript",
+	".mjs": "JavaScript", ".py": "Python", ".rs": "Rust", ".java": "Java", ".kt": "Kotlin",
+	".rb": "Ruby", ".php": "PHP", ".cs": "C#", ".c": "C", ".h": "C", ".cpp": "C++", ".cc": "C++",
+	".hpp": "C++", ".swift": "Swift", ".scala": "Scala", ".sh": "Shell", ".vue": "Vue", ".svelte": "Svelte",
+}
+
+// goMainPackagePattern は Go の main パッケージの宣言
+var goMainPackagePattern = regexp.MustCompile(`(?m)^package main\b`)
+
+// conventionalCommitPattern は Conventional Commits の件名（feat: / fix(scope): 等）
+var conventionalCommitPattern = regexp.MustCompile(`^[a-z]+(\([^)]*\))?!?: `)
+
+// ScanProject はプロジェクトを一通り調べる（除外パターンに一致するパスは見ない）
+func ScanProject(ctx context.Context, projectDir string) (*Survey, error) {
+	root, err := filepath.Abs(projectDir)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := tools.BuildTree(root, tools.TreeOptions{Depth: learnLayoutDepth, MaxEntries: learnLayoutEntries})
+	if err != nil {
+		return nil, err
+	}
+	survey := &Survey{Name: filepath.Base(root), Root: root, Layout: tree.Compact()}
+	if system := tasks.Detect(root); system != nil {
+		survey.BuildSystem = system.Name
+		survey.Commands = system.Commands
+	}
+
+	var sources []string
+	counts := make(map[string]int)
+	tests := map[string]int{"beside": 0, "dir": 0}
+	err = ignore.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			if path != root && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		language, ok := languageByExt[filepath.Ext(d.Name())]
+		if !ok {
+			return nil
+		}
+		counts[language]++
+		sources = append(sources, path)
+		rel, _ := filepath.Rel(root, path)
+		if isTestSource(rel) {
+			if inTestDir(rel) {
+				tests["dir"]++
+			} else {
+				tests["beside"]++
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for language, files := range counts {
+		survey.Languages = append(survey.Languages, LanguageCount{Language: language, Files: files})
+	}
+	sort.Slice(survey.Languages, func(i, j int) bool {
+		if survey.Languages[i].Files != survey.Languages[j].Files {
+			return survey.Languages[i].Files > survey.Languages[j].Files
+		}
+		return survey.Languages[i].Language < survey.Languages[j].Language
+	})
+	if len(survey.Languages) > learnMaxLanguages {
+		survey.Languages = survey.Languages[:learnMaxLanguages]
+	}
+
+	survey.findEntryPoints(sources)
+	survey.findConventions(ctx, sources, tests)
+	for _, name := range []string{"README.md", "README", "CONTRIBUTING.md", "ARCHITECTURE.md", "CLAUDE.md", "AGENTS.md", "docs"} {
+		if _, err := os.Stat(filepath.Join(root, name)); err == nil {
+			survey.Docs = append(survey.Docs, name)
+		}
+	}
+	return survey, nil
+}
+
+// isTestSource はテストのファイルか（Go・JS/TS・Python・Ruby の命名規則）
+func isTestSource(rel string) bool {
+	name := filepath.Base(rel)
+	return strings.HasSuffix(name, "_test.go") || strings.Contains(name, ".test.") || strings.Contains(name, ".spec.") ||
+		(strings.HasPrefix(name, "test_") && strings.HasSuffix(name, ".py")) || strings.HasSuffix(name, "_test.py") ||
+		strings.HasSuffix(name, "_spec.rb") || inTestDir(rel)
+}
+
+// inTestDir はテスト専用のディレクトリ（tests/, test/, __tests__/, spec/）の中か
+func inTestDir(rel string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(rel)), "/") {
+		switch part {
+		case "tests", "test", "__tests__", "spec":
+			return true
+		}
+	}
+	return false
+}
+
+// findEntryPoints は実行の入口（Go の main パッケージ、package.json の bin・scripts、Python・Rust の main 等）を探す
+func (s *Survey) findEntryPoints(sources []string) {
+	add := func(rel, how string) {
+		if len(s.EntryPoints) >= learnMaxEntryPoints {
+			return
+		}
+		entry := "`" + filepath.ToSlash(rel) + "`"
+		if how != "" {
+			entry += " — " + how
+		}
+		s.EntryPoints = append(s.EntryPoints, entry)
+	}
+
+	seenDirs := make(map[string]bool)
+	for _, path := range sources {
+		rel, _ := filepath.Rel(s.Root, path)
+		name := filepath.Base(rel)
+		switch {
+		case strings.HasSuffix(name, ".go") && !strings.HasSuffix(name, "_test.go"):
+			dir := filepath.Dir(rel)
+			if seenDirs[dir] || !goMainFile(path) {
+				continue
+			}
+			seenDirs[dir] = true
+			pkg := "./" + filepath.ToSlash(dir)
+			if dir == "." {
+				pkg = "."
+			}
+			add(dir+"/", "`go run "+pkg+"`")
+			s.entryFiles = append(s.entryFiles, path)
+		case name == "__main__.py":
+			add(rel, "`python -m "+strings.ReplaceAll(filepath.ToSlash(filepath.Dir(rel)), "/", ".")+"`")
+			s.entryFiles = append(s.entryFiles, path)
+		case rel == "main.py" || rel == "app.py" || rel == "manage.py":
+			add(rel, "`python "+rel+"`")
+			s.entryFiles = append(s.entryFiles, path)
+		case filepath.ToSlash(rel) == "src/main.rs":
+			add(rel, "`cargo run`")
+			s.entryFiles = append(s.entryFiles, path)
+		case filepath.ToSlash(filepath.Dir(rel)) == "src/bin" && strings.HasSuffix(name, ".rs"):
+			add(rel, "`cargo run --bin "+strings.TrimSuffix(name, ".rs")+"`")
+		}
+	}
+
+	if data, err := os.ReadFile(filepath.Join(s.Root, "package.json")); err == nil {
+		var pkg struct {
+			Main    string            `json:"main"`
+			Bin     json.RawMessage   `json:"bin"`
+			Scripts map[string]string `json:"scripts"`
+		}
+		if json.Unmarshal(data, &pkg) == nil {
+			var bins map[string]string
+			var bin string
+			if json.Unmarshal(pkg.Bin, &bins) == nil {
+				names := make([]string, 0, len(bins))
+				for name := range bins {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					add(bins[name], "bin `"+name+"`")
+				}
+			} else if json.Unmarshal(pkg.Bin, &bin) == nil && bin != "" {
+				add(bin, "bin")
+			}
+			if pkg.Main != "" {
+				add(pkg.Main, "package main")
+			}
+			for _, script := range []string{"start", "dev"} {
+				if pkg.Scripts[script] != "" {
+					add("package.json", "`npm run "+script+"` ("+pkg.Scripts[script]+")")
+				}
+			}
+		}
+	}
+	for _, name := range []string{"Dockerfile", "docker-compose.yml", "compose.yaml"} {
+		if _, err := os.Stat(filepath.Join(s.Root, name)); err == nil {
+			add(name, "")
+		}
+	}
+}
+
+// goMainFile は Go のファイルが main パッケージの main 関数を定義しているか
+func goMainFile(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	content := string(data)
+	return goMainPackagePattern.MatchString(content) && strings.Contains(content, "\nfunc main()")
+}
+
+// findConventions はテストの配置・リンター・CI・コミットの書式・コメントの言語を調べる
+func (s *Survey) findConventions(ctx context.Context, sources []string, tests map[string]int) {
+	switch {
+	case tests["beside"] > 0 && tests["beside"] >= tests["dir"]:
+		s.Conventions = append(s.Conventions, fmt.Sprintf("Tests live next to the code they test (%d test files)", tests["beside"]+tests["dir"]))
+	case tests["dir"] > 0:
+		s.Conventions = append(s.Conventions, fmt.Sprintf("Tests live in dedicated test directories (%d test files)", tests["beside"]+tests["dir"]))
+	case len(sources) > 0:
+		s.Conventions = append(s.Conventions, "No automated tests were found")
+	}
+
+	var configs []string
+	for _, pattern := range []string{
+		".editorconfig", ".golangci.yml", ".golangci.yaml", ".eslintrc*", "eslint.config.*", ".prettierrc*",
+		"ruff.toml", ".flake8", "rustfmt.toml", ".rubocop.yml", ".pre-commit-config.yaml", "tsconfig.json",
+	} {
+		matches, _ := filepath.Glob(filepath.Join(s.Root, pattern))
+		for _, match := range matches {
+			configs = append(configs, "`"+filepath.Base(match)+"`")
+		}
+	}
+	if pyproject, err := os.ReadFile(filepath.Join(s.Root, "pyproject.toml")); err == nil {
+		for _, tool := range []string{"ruff", "black", "mypy", "pytest"} {
+			if strings.Contains(string(pyproject), "[tool."+tool) {
+				configs = append(configs, "`pyproject.toml` ["+tool+"]")
+			}
+		}
+	}
+	if len(configs) > 0 {
+		s.Conventions = append(s.Conventions, "Style and tooling config: "+strings.Join(configs, ", "))
+	}
+
+	workflows, _ := filepath.Glob(filepath.Join(s.Root, ".github", "workflows", "*.y*ml"))
+	for i := range workflows {
+		workflows[i] = "`" + filepath.Base(workflows[i]) + "`"
+	}
+	switch {
+	case len(workflows) > 0:
+		s.Conventions = append(s.Conventions, "CI: GitHub Actions ("+strings.Join(workflows, ", ")+")")
+	case fileExists(filepath.Join(s.Root, ".gitlab-ci.yml")):
+		s.Conventions = append(s.Conventions, "CI: GitLab CI (`.gitlab-ci.yml`)")
+	}
+
+	if style := commitStyle(ctx, s.Root); style != "" {
+		s.Conventions = append(s.Conventions, style)
+	}
+	if language := commentLanguage(sources); language != "" {
+		s.Conventions = append(s.Conventions, "Code comments are mostly written in "+language)
+	}
+}
+
+// commitStyle は直近のコミットの件名の書式（リポジトリでなければ空）
+func commitStyle(ctx context.Context, root string) string {
+	cmd := exec.CommandContext(ctx, "git", "log", "-n", fmt.Sprint(learnCommitSubjects), "--format=%s")
+	cmd.Dir = root
+	output, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+	subjects := strings.Split(strings.TrimSpace(string(output)), "\n")
+	if len(subjects) < 5 {
+		return ""
+	}
+	conventional, bracketed := 0, 0
+	for _, subject := range subjects {
+		if conventionalCommitPattern.MatchString(subject) {
+			conventional++
+		}
+		if strings.HasPrefix(subject, "[") && strings.Contains(subject, "] ") {
+			bracketed++
+		}
+	}
+	switch {
+	case conventional*10 >= len(subjects)*6:
+		return "Commit subjects follow Conventional Commits (`feat: ...`, `fix(scope): ...`)"
+	case bracketed*10 >= len(subjects)*6:
+		return "Commit subjects start with a bracketed tag (`[id] ...`)"
+	}
+	return "Commit subjects are plain imperative sentences"
+}
+
+// commentLanguage はコメント行の過半数が日本語ならその旨を返す（英語・判定できなければ空）
+func commentLanguage(sources []string) string {
+	japanese, total := 0, 0
+	for i, path := range sources {
+		if i >= learnCommentFiles {
+			break
+		}
+		file, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if !strings.HasPrefix(line, "//") && !strings.HasPrefix(line, "#") {
+				continue
+			}
+			total++
+			for _, r := range line {
+				if unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) {
+					japanese++
+					break
+				}
+			}
+		}
+		file.Close()
+	}
+	if total >= 10 && japanese*2 > total {
+		return "Japanese"
+	}
+	return ""
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// Markdown は VYB.md に書く節（overview はモデルが書いた概要、空なら省略）
+func (s *Survey) Markdown(overview string) string {
+	var b strings.Builder
+	b.WriteString(OnboardingStart + "\n")
+	b.WriteString("## Onboarding (generated by `vyb learn`)\n\n")
+	if overview = strings.TrimSpace(overview); overview != "" {
+		b.WriteString(overview + "\n\n")
+	}
+
+	if len(s.Languages) > 0 {
+		parts := make([]string, 0, len(s.Languages))
+		for _, language := range s.Languages {
+			unit := "files"
+			if language.Files == 1 {
+				unit = "file"
+			}
+			parts = append(parts, fmt.Sprintf("%s (%d %s)", language.Language, language.Files, unit))
+		}
+		b.WriteString("- Languages: " + strings.Join(parts, ", ") + "\n")
+	}
+	if s.BuildSystem != "" {
+		b.WriteString("- Build system: " + s.BuildSystem + "\n")
+	}
+	labels := map[tasks.Kind]string{tasks.KindBuild: "Build", tasks.KindTest: "Test", tasks.KindLint: "Lint"}
+	for _, kind := range tasks.ValidKinds() {
+		if command := s.Commands[kind]; command != "" {
+			fmt.Fprintf(&b, "- %s: `%s`\n", labels[kind], command)
+		}
+	}
+	if len(s.Docs) > 0 {
+		b.WriteString("- Docs: " + strings.Join(s.Docs, ", ") + "\n")
+	}
+
+	if len(s.EntryPoints) > 0 {
+		b.WriteString("\n### Entry points\n\n")
+		for _, entry := range s.EntryPoints {
+			b.WriteString("- " + entry + "\n")
+		}
+	}
+	if len(s.Conventions) > 0 {
+		b.WriteString("\n### Conventions\n\n")
+		for _, convention := range s.Conventions {
+			b.WriteString("- " + convention + "\n")
+		}
+	}
+	b.WriteString("\n### Layout\n\n```\n" + s.Layout + "```\n")
+	b.WriteString(OnboardingEnd + "\n")
+	return b.String()
+}
+
+// Summarizer はモデルに調査結果からプロジェクトの概要を書かせる
+type Summarizer struct {
+	Provider llm.Provider
+	Model    string
+	Language string
+	Registry *prompts.Registry
+}
+
+// Summarize は目的・アーキテクチャ・主要な流れの概要（Markdown）を返す
+// README・入口のファイルの先頭を添付し、節の外のユーザーのメモ（memory）と矛盾しないようにする
+func (g *Summarizer) Summarize(ctx context.Context, survey *Survey, memory string) (string, error) {
+	registry := g.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+
+	var files strings.Builder
+	budget := learnMaxSnippetBytes
+	attach := func(path string, limit int) {
+		data, err := os.ReadFile(path)
+		if err != nil || budget <= 0 {
+			return
+		}
+		if limit > budget {
+			limit = budget
+		}
+		if len(data) > limit {
+			data = append([]byte(strings.ToValidUTF8(string(data[:limit]), "")), "\n..."...)
+		}
+		rel, _ := filepath.Rel(survey.Root, path)
+		fmt.Fprintf(&files, "### %s\n```\n%s\n```\n\n", filepath.ToSlash(rel), strings.TrimRight(string(data), "\n"))
+		budget -= len(data)
+	}
+	for _, name := range []string{"README.md", "README"} {
+		if fileExists(filepath.Join(survey.Root, name)) {
+			attach(filepath.Join(survey.Root, name), learnReadmeBytes)
+			break
+		}
+	}
+	for _, path := range survey.entryFiles {
+		attach(path, learnEntrySnippet)
+	}
+
+	prompt, err := registry.Render(prompts.TemplateLearn, prompts.Data{
+		SessionType: "learn",
+		Language:    g.Language,
+		ModelFamily: prompts.ModelFamily(g.Model),
+		Memory:      memory,
+		CurrentFile: survey.Name,
+		Context:     survey.Markdown(""),
+		Input:       files.String(),
+		TargetFile:  config.ProjectMemoryFile,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	temperature := 0.2
+	resp, err := g.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       g.Model,
+		Messages:    []llm.ChatMessage{{Role: "user", Content: prompt}},
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("概要の生成エラー: %w", err)
+	}
+	return cleanOverview(resp.Message.Content), nil
+}
+
+// cleanOverview はモデルの回答からコードブロックの囲み・節の目印・見出しの重複を取り除く
+func cleanOverview(answer string) string {
+	content := strings.TrimSpace(answer)
+	if strings.HasPrefix(content, "```") && strings.HasSuffix(content, "```") {
+		if i := strings.Index(content, "\n"); i >= 0 {
+			content = strings.TrimSpace(strings.TrimSuffix(content[i+1:], "```"))
+		}
+	}
+	content = strings.NewReplacer(OnboardingStart, "", OnboardingEnd, "").Replace(content)
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		// 節の見出し（##）より深い見出しにそろえる
+		if strings.HasPrefix(line, "# ") || strings.HasPrefix(line, "## ") {
+			line = "### " + strings.TrimLeft(line, "# ")
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
+
+// SplitOnboarding は VYB.md の内容を vyb learn の節とそれ以外（ユーザーのメモ）に分ける
+func SplitOnboarding(content string) (section, rest string) {
+	start := strings.Index(content, OnboardingStart)
+	if start < 0 {
+		return "", content
+	}
+	end := strings.Index(content[start:], OnboardingEnd)
+	if end < 0 {
+		return content[start:], content[:start]
+	}
+	end += start + len(OnboardingEnd)
+	return content[start:end], content[:start] + strings.TrimPrefix(content[end:], "\n")
+}
+
+// WriteOnboarding は projectDir の VYB.md に vyb learn の節を書く
+// 既存の節は置き換え、節がなければ末尾に追加する（ファイルがなければ作成し、true を返す）
+func WriteOnboarding(projectDir, section string) (string, bool, error) {
+	path := filepath.Join(projectDir, config.ProjectMemoryFile)
+	existing, err := os.ReadFile(path)
+	created := os.IsNotExist(err)
+	if err != nil && !created {
+		return path, false, fmt.Errorf("プロジェクトメモリ読み込みエラー: %w", err)
+	}
+
+	content := string(existing)
+	if start := strings.Index(content, OnboardingStart); start >= 0 {
+		old, _ := SplitOnboarding(content)
+		content = content[:start] + strings.TrimSuffix(section, "\n") + content[start+len(old):]
+	} else {
+		if content == "" {
+			content = fmt.Sprintf("# %s\n\n", filepath.Base(projectDir))
+		} else {
+			content = strings.TrimRight(content, "\n") + "\n\n"
+		}
+		content += section
+	}
+	if !strings.HasSuffix(content, "\n") {
+		content += "\n"
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		return path, false, fmt.Errorf("プロジェクトメモリ書き込みエラー: %w", err)
+	}
+	return path, created, nil
+}
@@ -0,0 +1,76 @@
+package setup
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+// MemoryTemplate はプロジェクトメモリ（VYB.md）の雛形を生成
+// 検出したビルド・テスト・リントコマンドを記入し、残りはユーザーが埋める
+func MemoryTemplate(projectDir string) string {
+	name := filepath.Base(projectDir)
+	if abs, err := filepath.Abs(projectDir); err == nil {
+		name = filepath.Base(abs)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	b.WriteString("This file is included in every vyb prompt. Keep it short and factual.\n\n")
+
+	b.WriteString("## Commands\n\n")
+	system := tasks.Detect(projectDir)
+	labels := map[tasks.Kind]string{tasks.KindBuild: "Build", tasks.KindTest: "Test", tasks.KindLint: "Lint"}
+	for _, kind := range tasks.ValidKinds() {
+		if system != nil && system.Command(kind) != "" {
+			fmt.Fprintf(&b, "- %s: `%s`\n", labels[kind], system.Command(kind))
+		} else {
+			fmt.Fprintf(&b, "- %s: \n", labels[kind])
+		}
+	}
+
+	b.WriteString("\n## Layout\n\n")
+	dirs := topLevelDirs(projectDir)
+	for _, dir := range dirs {
+		fmt.Fprintf(&b, "- `%s/` - \n", dir)
+	}
+	if len(dirs) == 0 {
+		b.WriteString("- \n")
+	}
+
+	b.WriteString("\n## Conventions\n\n")
+	b.WriteString("- \n")
+	return b.String()
+}
+
+// WriteMemoryFile はプロジェクトルートに VYB.md を作成（既にあれば上書きせず false）
+func WriteMemoryFile(projectDir string) (string, bool, error) {
+	path := filepath.Join(projectDir, config.ProjectMemoryFile)
+	if _, err := os.Stat(path); err == nil {
+		return path, false, nil
+	}
+	if err := os.WriteFile(path, []byte(MemoryTemplate(projectDir)), 0644); err != nil {
+		return path, false, fmt.Errorf("プロジェクトメモリ作成エラー: %w", err)
+	}
+	return path, true, nil
+}
+
+// topLevelDirs は隠しディレクトリと依存物を除いた直下のディレクトリ
+func topLevelDirs(projectDir string) []string {
+	entries, err := os.ReadDir(projectDir)
+	if err != nil {
+		return nil
+	}
+	skip := map[string]bool{"node_modules": true, "vendor": true, "target": true, "dist": true, "build": true}
+	var dirs []string
+	for _, entry := range entries {
+		if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !skip[entry.Name()] {
+			dirs = append(dirs, entry.Name())
+		}
+	}
+	return dirs
+}
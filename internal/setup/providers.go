@@ -0,0 +1,84 @@
+package setup
+
+import (
+	"context"
+	"net/http"
+	"os/exec"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+// 各プロバイダーの既定エンドポイント
+const (
+	DefaultOllamaURL   = "http://localhost:11434"
+	DefaultLMStudioURL = "http://localhost:1234"
+	DefaultVLLMURL     = "http://localhost:8000"
+)
+
+// プロバイダー検出のタイムアウト
+const probeTimeout = 2 * time.Second
+
+// Probe はプロバイダーの検出結果
+type Probe struct {
+	Provider  string             `json:"provider"`
+	BaseURL   string             `json:"base_url"`
+	Installed bool               `json:"installed"` // CLIがPATHにある
+	Running   bool               `json:"running"`   // APIが応答した
+	Models    []llm.ModelDetails `json:"models"`
+	Error     string             `json:"error,omitempty"`
+}
+
+// HasModel はモデルがpull済みか（タグ省略時は :latest として比較）
+func (p Probe) HasModel(name string) bool {
+	for _, model := range p.Models {
+		if llm.SameModel(model.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectProviders はOllama・LM Studio・vLLMのローカルサーバーを検出
+func DetectProviders(ctx context.Context) []Probe {
+	client := &http.Client{Timeout: probeTimeout}
+	return []Probe{
+		ProbeOllama(ctx, client, DefaultOllamaURL),
+		probeOpenAICompatible(ctx, client, "lmstudio", DefaultLMStudioURL, "lms"),
+		probeOpenAICompatible(ctx, client, "vllm", DefaultVLLMURL, "vllm"),
+	}
+}
+
+// ProbeOllama はOllamaの起動状態とpull済みモデルを取得
+func ProbeOllama(ctx context.Context, client *http.Client, baseURL string) Probe {
+	probe := Probe{Provider: "ollama", BaseURL: baseURL, Installed: onPath("ollama")}
+
+	ollama := &llm.OllamaClient{BaseURL: baseURL, HTTPClient: client}
+	models, err := ollama.ListLocalModels(ctx)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.Running = true
+	probe.Models = models
+	return probe
+}
+
+// probeOpenAICompatible はOpenAI互換API（/v1/models）のサーバーを検出
+func probeOpenAICompatible(ctx context.Context, client *http.Client, provider, baseURL, binary string) Probe {
+	probe := Probe{Provider: provider, BaseURL: baseURL, Installed: onPath(binary)}
+
+	models, err := llm.ListOpenAICompatibleModels(ctx, client, baseURL)
+	if err != nil {
+		probe.Error = err.Error()
+		return probe
+	}
+	probe.Running = true
+	probe.Models = models
+	return probe
+}
+
+func onPath(binary string) bool {
+	_, err := exec.LookPath(binary)
+	return err == nil
+}
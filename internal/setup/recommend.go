@@ -0,0 +1,70 @@
+package setup
+
+import "fmt"
+
+// CatalogModel は推奨候補のモデルと動作に必要なメモリの目安（4bit量子化＋KVキャッシュ）
+type CatalogModel struct {
+	Name        string
+	MemoryBytes int64
+}
+
+// Catalog はコーディング向けの推奨候補（大きい順）
+var Catalog = []CatalogModel{
+	{Name: "qwen2.5-coder:32b", MemoryBytes: 22 << 30},
+	{Name: "qwen2.5-coder:14b", MemoryBytes: 11 << 30},
+	{Name: "qwen2.5-coder:7b", MemoryBytes: 6 << 30},
+	{Name: "qwen2.5-coder:3b", MemoryBytes: 3 << 30},
+	{Name: "qwen2.5-coder:1.5b", MemoryBytes: 2 << 30},
+}
+
+// Recommendation は推奨モデルとその理由
+type Recommendation struct {
+	Model     string `json:"model"`
+	Installed bool   `json:"installed"` // pull済み
+	Fits      bool   `json:"fits"`      // メモリの目安に収まる
+	Reason    string `json:"reason"`
+}
+
+// Recommend はメモリに収まる最大の候補を推奨する
+// メモリ量が不明な場合はpull済みの候補、なければ中間の 7b を推奨
+func Recommend(hw Hardware, ollama Probe) Recommendation {
+	budget := hw.MemoryBudget()
+	if budget <= 0 {
+		for _, candidate := range Catalog {
+			if ollama.HasModel(candidate.Name) {
+				return Recommendation{Model: candidate.Name, Installed: true, Fits: true, Reason: "memory size unknown; using an installed model"}
+			}
+		}
+		return Recommendation{Model: "qwen2.5-coder:7b", Fits: true, Reason: "memory size unknown; a mid-sized model is a safe default"}
+	}
+
+	for _, candidate := range Catalog {
+		if candidate.MemoryBytes <= budget {
+			return Recommendation{
+				Model:     candidate.Name,
+				Installed: ollama.HasModel(candidate.Name),
+				Fits:      true,
+				Reason:    fmt.Sprintf("largest model that fits the %s budget (needs ~%s)", budgetSource(hw), FormatBytes(candidate.MemoryBytes)),
+			}
+		}
+	}
+
+	smallest := Catalog[len(Catalog)-1]
+	return Recommendation{
+		Model:     smallest.Name,
+		Installed: ollama.HasModel(smallest.Name),
+		Reason:    fmt.Sprintf("even the smallest model needs ~%s; expect slow responses", FormatBytes(smallest.MemoryBytes)),
+	}
+}
+
+// budgetSource はメモリ予算の根拠の表示
+func budgetSource(hw Hardware) string {
+	switch {
+	case hw.VRAMBytes > 0:
+		return FormatBytes(hw.VRAMBytes) + " VRAM"
+	case hw.Unified:
+		return FormatBytes(hw.MemoryBudget()) + " unified memory"
+	default:
+		return FormatBytes(hw.MemoryBudget()) + " CPU memory"
+	}
+}
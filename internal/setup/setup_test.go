@@ -0,0 +1,280 @@
+package setup
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	data := "MemTotal:       16303160 kB\nMemFree:         1234 kB\n"
+	if got := parseMeminfo(data); got != 16303160*1024 {
+		t.Errorf("MemTotal: %d", got)
+	}
+	if got := parseMeminfo("MemFree: 1 kB\n"); got != 0 {
+		t.Errorf("MemTotalがない場合は0: %d", got)
+	}
+}
+
+func TestParseNvidiaSMI(t *testing.T) {
+	name, vram := parseNvidiaSMI("NVIDIA GeForce RTX 4090, 24564\nNVIDIA GeForce RTX 3060, 12288\n")
+	if name != "NVIDIA GeForce RTX 4090, NVIDIA GeForce RTX 3060" {
+		t.Errorf("GPU名: %q", name)
+	}
+	if vram != (24564+12288)*1024*1024 {
+		t.Errorf("VRAM: %d", vram)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	ollama := Probe{Models: []llm.ModelDetails{{Name: "qwen2.5-coder:7b"}}}
+
+	tests := []struct {
+		name      string
+		hw        Hardware
+		model     string
+		installed bool
+	}{
+		{"24GB GPU", Hardware{RAMBytes: 64 << 30, VRAMBytes: 24 << 30}, "qwen2.5-coder:32b", false},
+		{"16GB CPU", Hardware{RAMBytes: 16 << 30}, "qwen2.5-coder:7b", true},
+		{"Apple Silicon 24GB", Hardware{RAMBytes: 24 << 30, Unified: true}, "qwen2.5-coder:14b", false},
+		{"2GB", Hardware{RAMBytes: 2 << 30}, "qwen2.5-coder:1.5b", false},
+		{"unknown", Hardware{}, "qwen2.5-coder:7b", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Recommend(tt.hw, ollama)
+			if got.Model != tt.model || got.Installed != tt.installed {
+				t.Errorf("推奨: %+v, 期待値: %s (installed=%t)", got, tt.model, tt.installed)
+			}
+			if fits := tt.hw.RAMBytes != 2<<30; got.Fits != fits {
+				t.Errorf("メモリに収まるか: %t, 期待値: %t", got.Fits, fits)
+			}
+		})
+	}
+}
+
+func TestProbeHasModelNormalizesLatest(t *testing.T) {
+	probe := Probe{Models: []llm.ModelDetails{{Name: "llama3:latest"}}}
+	if !probe.HasModel("llama3") {
+		t.Error("タグ省略時は :latest として一致するはず")
+	}
+	if probe.HasModel("llama3:8b") {
+		t.Error("異なるタグに一致した")
+	}
+}
+
+// newOllamaServer は /api/tags と /api/chat に応答するテスト用サーバー
+func newOllamaServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			w.Write([]byte(`{"models":[{"name":"qwen2.5-coder:7b","size":4683087332}]}`))
+		case "/api/chat":
+			json.NewEncoder(w).Encode(llm.ChatResponse{
+				Message:   llm.ChatMessage{Role: "assistant", Content: "func add(a, b int) int { return a + b }"},
+				Done:      true,
+				EvalCount: 20,
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+}
+
+func TestProbeOllama(t *testing.T) {
+	server := newOllamaServer(t)
+	defer server.Close()
+
+	probe := ProbeOllama(context.Background(), server.Client(), server.URL)
+	if !probe.Running || len(probe.Models) != 1 || probe.Models[0].SizeBytes != 4683087332 {
+		t.Errorf("検出結果: %+v", probe)
+	}
+
+	server.Close()
+	if probe := ProbeOllama(context.Background(), server.Client(), server.URL); probe.Running || probe.Error == "" {
+		t.Errorf("停止中のサーバーが検出された: %+v", probe)
+	}
+}
+
+func TestBenchmark(t *testing.T) {
+	server := newOllamaServer(t)
+	defer server.Close()
+
+	result, err := Benchmark(context.Background(), llm.NewOllamaClient(server.URL), "qwen2.5-coder:7b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.CompletionTokens != 20 || result.TokensPerSecond <= 0 {
+		t.Errorf("計測結果: %+v", result)
+	}
+	if !strings.Contains(result.String(), "20 tokens") {
+		t.Errorf("表示: %s", result)
+	}
+}
+
+func TestWriteMemoryFile(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module example.com/app\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(dir, "internal"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	path, created, err := WriteMemoryFile(dir)
+	if err != nil || !created {
+		t.Fatalf("作成結果: created=%t err=%v", created, err)
+	}
+	data, _ := os.ReadFile(path)
+	for _, want := range []string{"- Test: `go test ./...`", "- `internal/` - "} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("VYB.md に %q が含まれていない:\n%s", want, data)
+		}
+	}
+
+	// 既存のファイルは上書きしない
+	os.WriteFile(path, []byte("custom"), 0644)
+	if _, created, _ := WriteMemoryFile(dir); created {
+		t.Error("既存の VYB.md を上書きした")
+	}
+	if data, _ := os.ReadFile(path); string(data) != "custom" {
+		t.Errorf("既存の内容が変更された: %s", data)
+	}
+}
+
+// writeProject はテスト用のプロジェクトを作成
+func writeProject(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestScanProject(t *testing.T) {
+	dir := writeProject(t, map[string]string{
+		"go.mod":                    "module example.com/app\n",
+		"README.md":                 "# app\n\nA tiny service.\n",
+		"cmd/app/main.go":           "package main\n\n// main はサーバーを起動する\nfunc main() {}\n",
+		"internal/store/store.go":   "package store\n\n// Store は保存先\ntype Store struct{}\n",
+		"internal/store/db_test.go": "package store\n",
+		"node_modules/x/index.js":   "module.exports = 1\n",
+		".golangci.yml":             "linters: {}\n",
+		".github/workflows/ci.yml":  "on: push\n",
+	})
+
+	survey, err := ScanProject(context.Background(), dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(survey.Languages) != 1 || survey.Languages[0].Language != "Go" || survey.Languages[0].Files != 3 {
+		t.Errorf("除外パターンの中を数えず Go だけのはず: %+v", survey.Languages)
+	}
+	if survey.Commands["test"] != "go test ./..." {
+		t.Errorf("テストコマンドを検出するはず: %+v", survey.Commands)
+	}
+	if len(survey.EntryPoints) != 1 || survey.EntryPoints[0] != "`cmd/app/` — `go run ./cmd/app`" {
+		t.Errorf("main パッケージを入口にするはず: %v", survey.EntryPoints)
+	}
+	conventions := strings.Join(survey.Conventions, "\n")
+	for _, want := range []string{"Tests live next to the code", "`.golangci.yml`", "GitHub Actions (`ci.yml`)"} {
+		if !strings.Contains(conventions, want) {
+			t.Errorf("規約に %q が含まれるはず:\n%s", want, conventions)
+		}
+	}
+
+	section := survey.Markdown("An overview.")
+	for _, want := range []string{OnboardingStart, "An overview.\n", "- Test: `go test ./...`", "### Entry points", "  internal/\n", OnboardingEnd} {
+		if !strings.Contains(section, want) {
+			t.Errorf("節に %q が含まれるはず:\n%s", want, section)
+		}
+	}
+	if strings.Contains(section, "node_modules") {
+		t.Errorf("除外したディレクトリを構成に含めないはず:\n%s", section)
+	}
+
+	provider := &answerProvider{answer: "```markdown\n# App\nServes things.\n## Architecture\n- cmd/app starts it\n```"}
+	summarizer := &Summarizer{Provider: provider, Model: "qwen2.5-coder", Language: "en"}
+	overview, err := summarizer.Summarize(context.Background(), survey, "Use tabs.")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if overview != "### App\nServes things.\n### Architecture\n- cmd/app starts it" {
+		t.Errorf("囲み・見出しを整えるはず: %q", overview)
+	}
+	for _, want := range []string{"Use tabs.", "A tiny service.", "cmd/app/main.go", "- Build system: go"} {
+		if !strings.Contains(provider.prompt, want) {
+			t.Errorf("プロンプトに %q が含まれるはず", want)
+		}
+	}
+}
+
+func TestWriteOnboarding(t *testing.T) {
+	dir := t.TempDir()
+	section := OnboardingStart + "\n## Onboarding\nfirst\n" + OnboardingEnd + "\n"
+
+	path, created, err := WriteOnboarding(dir, section)
+	if err != nil || !created {
+		t.Fatalf("作成結果: created=%t err=%v", created, err)
+	}
+	data, _ := os.ReadFile(path)
+	if !strings.HasPrefix(string(data), "# "+filepath.Base(dir)+"\n\n"+OnboardingStart) {
+		t.Errorf("新しい VYB.md は見出しと節になるはず:\n%s", data)
+	}
+
+	// 節だけを置き換え、ユーザーのメモは残す
+	notes := "# app\n\nUse tabs.\n\n"
+	os.WriteFile(path, []byte(notes+section+"\n## Later notes\n"), 0644)
+	if _, created, err := WriteOnboarding(dir, strings.Replace(section, "first", "second", 1)); err != nil || created {
+		t.Fatalf("更新結果: created=%t err=%v", created, err)
+	}
+	data, _ = os.ReadFile(path)
+	want := notes + strings.Replace(section, "first", "second", 1) + "\n## Later notes\n"
+	if string(data) != want {
+		t.Errorf("節だけを置き換えるはず:\n%s", data)
+	}
+	gotSection, rest := SplitOnboarding(string(data))
+	if !strings.Contains(gotSection, "second") || strings.Contains(rest, OnboardingStart) || !strings.Contains(rest, "Later notes") {
+		t.Errorf("節とメモに分けるはず: %q / %q", gotSection, rest)
+	}
+
+	// 節のない VYB.md には末尾に追加する
+	os.WriteFile(path, []byte("# app\nnotes"), 0644)
+	WriteOnboarding(dir, section)
+	if data, _ := os.ReadFile(path); string(data) != "# app\nnotes\n\n"+section {
+		t.Errorf("末尾に追加するはず:\n%s", data)
+	}
+}
+
+// answerProvider は固定の回答を返し、受け取ったプロンプトを記録する
+type answerProvider struct {
+	answer string
+	prompt string
+}
+
+func (p *answerProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.prompt = req.Messages[0].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: p.answer}, Done: true}, nil
+}
+
+func (p *answerProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *answerProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *answerProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
@@ -0,0 +1,184 @@
+package storage
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// memoryEntry はキーバリューの1件
+type memoryEntry struct {
+	value     []byte
+	expiresAt time.Time
+}
+
+// MemoryStore はプロセス内のメモリに保存する Store（storage.backend が memory の時・テスト用）
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]SessionRecord
+	data     map[string][]byte // セッションID + "\x00" + 種類 → JSON
+	messages []Message
+	entries  map[string]memoryEntry // bucket + "\x00" + キー
+	now      func() time.Time
+}
+
+// NewMemoryStore はメモリ上の Store を作成
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions: make(map[string]SessionRecord),
+		data:     make(map[string][]byte),
+		entries:  make(map[string]memoryEntry),
+		now:      time.Now,
+	}
+}
+
+// SaveSession はセッションを追加・更新する
+func (m *MemoryStore) SaveSession(session SessionRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.sessions[session.ID] = session
+	return nil
+}
+
+// ListSessions は最終アクティビティの新しい順にセッションを返す
+func (m *MemoryStore) ListSessions(query SessionQuery) ([]SessionRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]SessionRecord, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		if query.ProjectDir != "" && session.ProjectDir != query.ProjectDir {
+			continue
+		}
+		if !query.Since.IsZero() && session.LastActivity.Before(query.Since) {
+			continue
+		}
+		sessions = append(sessions, session)
+	}
+	sort.Slice(sessions, func(i, j int) bool { return sessions[i].LastActivity.After(sessions[j].LastActivity) })
+	if query.Limit > 0 && len(sessions) > query.Limit {
+		sessions = sessions[:query.Limit]
+	}
+	return sessions, nil
+}
+
+// SaveSessionData はセッションの状態を JSON で保存する（呼び出し元の値と共有しない）
+func (m *MemoryStore) SaveSessionData(sessionID, kind string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.data[sessionID+"\x00"+kind] = data
+	return nil
+}
+
+// LoadSessionData はセッションの状態を dest に読み込む
+func (m *MemoryStore) LoadSessionData(sessionID, kind string, dest interface{}) (bool, error) {
+	m.mu.RLock()
+	data, ok := m.data[sessionID+"\x00"+kind]
+	m.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(data, dest)
+}
+
+// AppendMessage は会話の発言を追加する
+func (m *MemoryStore) AppendMessage(message Message) error {
+	if message.Timestamp.IsZero() {
+		message.Timestamp = m.now()
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.messages = append(m.messages, message)
+	return nil
+}
+
+// SearchMessages は新しい順に発言を検索する
+func (m *MemoryStore) SearchMessages(query MessageQuery) ([]Message, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	text := strings.ToLower(query.Text)
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var found []Message
+	for i := len(m.messages) - 1; i >= 0 && len(found) < limit; i-- {
+		message := m.messages[i]
+		if query.SessionID != "" && message.SessionID != query.SessionID {
+			continue
+		}
+		if text != "" && !strings.Contains(strings.ToLower(message.Content), text) {
+			continue
+		}
+		found = append(found, message)
+	}
+	return found, nil
+}
+
+// Put は bucket 内のキーに値を保存する
+func (m *MemoryStore) Put(bucket, key string, value []byte, expiresAt time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.entries[bucket+"\x00"+key] = memoryEntry{value: append([]byte(nil), value...), expiresAt: expiresAt}
+	return nil
+}
+
+// Get は期限内の値を返す（期限切れの値は削除する）
+func (m *MemoryStore) Get(bucket, key string) ([]byte, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.entries[bucket+"\x00"+key]
+	if !ok {
+		return nil, false, nil
+	}
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		delete(m.entries, bucket+"\x00"+key)
+		return nil, false, nil
+	}
+	return append([]byte(nil), entry.value...), true, nil
+}
+
+// Delete はキーを削除する
+func (m *MemoryStore) Delete(bucket, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.entries, bucket+"\x00"+key)
+	return nil
+}
+
+// DeleteBucket は bucket 内の全てのキーを削除する
+func (m *MemoryStore) DeleteBucket(bucket string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for key := range m.entries {
+		if strings.HasPrefix(key, bucket+"\x00") {
+			delete(m.entries, key)
+		}
+	}
+	return nil
+}
+
+// Stats は保存している件数を返す
+func (m *MemoryStore) Stats() (Stats, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	entries := 0
+	now := m.now()
+	for _, entry := range m.entries {
+		if entry.expiresAt.IsZero() || now.Before(entry.expiresAt) {
+			entries++
+		}
+	}
+	return Stats{Backend: BackendMemory, Sessions: len(m.sessions), Messages: len(m.messages), Entries: entries}, nil
+}
+
+// Close は何もしない
+func (m *MemoryStore) Close() error {
+	return nil
+}
@@ -0,0 +1,307 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite" // 純粋な Go の SQLite ドライバー（cgo不要）
+)
+
+// migrations はスキーマの変更履歴（PRAGMA user_version に適用済みの数を記録する）
+// 既存の要素は変更せず、末尾に追加すること
+var migrations = []string{
+	`CREATE TABLE sessions (
+		id            TEXT PRIMARY KEY,
+		type          TEXT NOT NULL DEFAULT '',
+		model         TEXT NOT NULL DEFAULT '',
+		project_dir   TEXT NOT NULL DEFAULT '',
+		started_at    INTEGER NOT NULL,
+		last_activity INTEGER NOT NULL,
+		closed        INTEGER NOT NULL DEFAULT 0
+	);
+	CREATE INDEX sessions_last_activity ON sessions (last_activity);
+	CREATE TABLE session_data (
+		session_id TEXT NOT NULL,
+		kind       TEXT NOT NULL,
+		value      TEXT NOT NULL,
+		updated_at INTEGER NOT NULL,
+		PRIMARY KEY (session_id, kind)
+	);
+	CREATE TABLE messages (
+		id         INTEGER PRIMARY KEY AUTOINCREMENT,
+		session_id TEXT NOT NULL,
+		role       TEXT NOT NULL,
+		content    TEXT NOT NULL,
+		created_at INTEGER NOT NULL
+	);
+	CREATE INDEX messages_session ON messages (session_id, id);
+	CREATE TABLE kv (
+		bucket     TEXT NOT NULL,
+		key        TEXT NOT NULL,
+		value      BLOB NOT NULL,
+		expires_at INTEGER NOT NULL DEFAULT 0,
+		PRIMARY KEY (bucket, key)
+	);`,
+	`ALTER TABLE sessions ADD COLUMN title TEXT NOT NULL DEFAULT '';`,
+}
+
+// SQLiteStore は SQLite のファイルに保存する Store
+type SQLiteStore struct {
+	db   *sql.DB
+	path string
+	now  func() time.Time
+}
+
+// OpenSQLite はデータベースを開き、必要ならスキーマを作成・更新する
+func OpenSQLite(path string) (*SQLiteStore, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("保存先ディレクトリ作成エラー: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("データベースを開けません: %w", err)
+	}
+	// 書き込みの競合を避けるため接続は1本にする（複数プロセスは busy_timeout で待つ）
+	db.SetMaxOpenConns(1)
+
+	for _, pragma := range []string{"PRAGMA journal_mode = WAL", "PRAGMA busy_timeout = 5000"} {
+		if _, err := db.Exec(pragma); err != nil {
+			db.Close()
+			return nil, fmt.Errorf("データベース設定エラー (%s): %w", path, err)
+		}
+	}
+	if err := migrate(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("スキーマ更新エラー (%s): %w", path, err)
+	}
+	return &SQLiteStore{db: db, path: path, now: time.Now}, nil
+}
+
+// migrate は未適用のスキーマ変更を順に適用する
+func migrate(db *sql.DB) error {
+	var version int
+	if err := db.QueryRow("PRAGMA user_version").Scan(&version); err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("新しいバージョンの vyb で作成されたデータベースです（スキーマ %d）", version)
+	}
+	for i := version; i < len(migrations); i++ {
+		tx, err := db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(migrations[i]); err != nil {
+			tx.Rollback()
+			return err
+		}
+		// PRAGMA はプレースホルダーを使えない
+		if _, err := tx.Exec(fmt.Sprintf("PRAGMA user_version = %d", i+1)); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Path はデータベースファイルのパス
+func (s *SQLiteStore) Path() string {
+	return s.path
+}
+
+// SaveSession はセッションを追加・更新する
+func (s *SQLiteStore) SaveSession(session SessionRecord) error {
+	_, err := s.db.Exec(`INSERT INTO sessions (id, type, title, model, project_dir, started_at, last_activity, closed)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			type = excluded.type, title = excluded.title, model = excluded.model, project_dir = excluded.project_dir,
+			started_at = excluded.started_at, last_activity = excluded.last_activity, closed = excluded.closed`,
+		session.ID, session.Type, session.Title, session.Model, session.ProjectDir,
+		session.StartedAt.UnixNano(), session.LastActivity.UnixNano(), session.Closed)
+	return err
+}
+
+// ListSessions は最終アクティビティの新しい順にセッションを返す
+func (s *SQLiteStore) ListSessions(query SessionQuery) ([]SessionRecord, error) {
+	q := "SELECT id, type, title, model, project_dir, started_at, last_activity, closed FROM sessions WHERE 1 = 1"
+	var args []interface{}
+	if query.ProjectDir != "" {
+		q += " AND project_dir = ?"
+		args = append(args, query.ProjectDir)
+	}
+	if !query.Since.IsZero() {
+		q += " AND last_activity >= ?"
+		args = append(args, query.Since.UnixNano())
+	}
+	q += " ORDER BY last_activity DESC"
+	if query.Limit > 0 {
+		q += " LIMIT ?"
+		args = append(args, query.Limit)
+	}
+
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	sessions := []SessionRecord{}
+	for rows.Next() {
+		var session SessionRecord
+		var startedAt, lastActivity int64
+		if err := rows.Scan(&session.ID, &session.Type, &session.Title, &session.Model, &session.ProjectDir, &startedAt, &lastActivity, &session.Closed); err != nil {
+			return nil, err
+		}
+		session.StartedAt = time.Unix(0, startedAt)
+		session.LastActivity = time.Unix(0, lastActivity)
+		sessions = append(sessions, session)
+	}
+	return sessions, rows.Err()
+}
+
+// SaveSessionData はセッションの状態を JSON で保存する
+func (s *SQLiteStore) SaveSessionData(sessionID, kind string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	_, err = s.db.Exec(`INSERT INTO session_data (session_id, kind, value, updated_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (session_id, kind) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
+		sessionID, kind, string(data), s.now().UnixNano())
+	return err
+}
+
+// LoadSessionData はセッションの状態を dest に読み込む
+func (s *SQLiteStore) LoadSessionData(sessionID, kind string, dest interface{}) (bool, error) {
+	var data string
+	err := s.db.QueryRow("SELECT value FROM session_data WHERE session_id = ? AND kind = ?", sessionID, kind).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, json.Unmarshal([]byte(data), dest)
+}
+
+// AppendMessage は会話の発言を追加する
+func (s *SQLiteStore) AppendMessage(message Message) error {
+	if message.Timestamp.IsZero() {
+		message.Timestamp = s.now()
+	}
+	_, err := s.db.Exec("INSERT INTO messages (session_id, role, content, created_at) VALUES (?, ?, ?, ?)",
+		message.SessionID, message.Role, message.Content, message.Timestamp.UnixNano())
+	return err
+}
+
+// SearchMessages は新しい順に発言を検索する
+func (s *SQLiteStore) SearchMessages(query MessageQuery) ([]Message, error) {
+	limit := query.Limit
+	if limit <= 0 {
+		limit = defaultSearchLimit
+	}
+	q := "SELECT session_id, role, content, created_at FROM messages WHERE 1 = 1"
+	var args []interface{}
+	if query.SessionID != "" {
+		q += " AND session_id = ?"
+		args = append(args, query.SessionID)
+	}
+	if query.Text != "" {
+		// LIKE は ASCII の大文字小文字を区別しないので、それ以外も揃えるため lower で比較する
+		q += ` AND lower(content) LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escapeLike(strings.ToLower(query.Text))+"%")
+	}
+	q += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := s.db.Query(q, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var messages []Message
+	for rows.Next() {
+		var message Message
+		var createdAt int64
+		if err := rows.Scan(&message.SessionID, &message.Role, &message.Content, &createdAt); err != nil {
+			return nil, err
+		}
+		message.Timestamp = time.Unix(0, createdAt)
+		messages = append(messages, message)
+	}
+	return messages, rows.Err()
+}
+
+// escapeLike は LIKE の特殊文字をエスケープする
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// Put は bucket 内のキーに値を保存する
+func (s *SQLiteStore) Put(bucket, key string, value []byte, expiresAt time.Time) error {
+	_, err := s.db.Exec(`INSERT INTO kv (bucket, key, value, expires_at) VALUES (?, ?, ?, ?)
+		ON CONFLICT (bucket, key) DO UPDATE SET value = excluded.value, expires_at = excluded.expires_at`,
+		bucket, key, value, unixNanoOrZero(expiresAt))
+	return err
+}
+
+// Get は期限内の値を返す（期限切れの値は削除する）
+func (s *SQLiteStore) Get(bucket, key string) ([]byte, bool, error) {
+	var value []byte
+	var expiresAt int64
+	err := s.db.QueryRow("SELECT value, expires_at FROM kv WHERE bucket = ? AND key = ?", bucket, key).Scan(&value, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	if expiresAt != 0 && s.now().UnixNano() >= expiresAt {
+		return nil, false, s.Delete(bucket, key)
+	}
+	return value, true, nil
+}
+
+// Delete はキーを削除する
+func (s *SQLiteStore) Delete(bucket, key string) error {
+	_, err := s.db.Exec("DELETE FROM kv WHERE bucket = ? AND key = ?", bucket, key)
+	return err
+}
+
+// DeleteBucket は bucket 内の全てのキーを削除する
+func (s *SQLiteStore) DeleteBucket(bucket string) error {
+	_, err := s.db.Exec("DELETE FROM kv WHERE bucket = ?", bucket)
+	return err
+}
+
+// Stats は保存している件数を返す
+func (s *SQLiteStore) Stats() (Stats, error) {
+	stats := Stats{Backend: BackendSQLite, Path: s.path}
+	err := s.db.QueryRow(`SELECT
+		(SELECT count(*) FROM sessions),
+		(SELECT count(*) FROM messages),
+		(SELECT count(*) FROM kv WHERE expires_at = 0 OR expires_at > ?)`, s.now().UnixNano()).
+		Scan(&stats.Sessions, &stats.Messages, &stats.Entries)
+	return stats, err
+}
+
+// Close はデータベースを閉じる
+func (s *SQLiteStore) Close() error {
+	return s.db.Close()
+}
+
+// unixNanoOrZero はゼロ値の時刻を 0（期限なし）として返す
+func unixNanoOrZero(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+	return t.UnixNano()
+}
@@ -0,0 +1,128 @@
+// Package storage はセッション・セッション毎の状態（メトリクス・会話フロー）・会話・キャッシュを保存する。
+// 既定では SQLite（純粋な Go のドライバー）のファイルに永続化し、プロセスを跨いで集計・検索できる。
+// 機能毎に独自のファイル形式を作らず、ここに保存する
+package storage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// SessionRecord はセッション1件の記録
+type SessionRecord struct {
+	ID           string    `json:"id"`
+	Type         string    `json:"type"`
+	Title        string    `json:"title,omitempty"` // 最初のやり取りから生成、または /title で設定した題名
+	Model        string    `json:"model,omitempty"`
+	ProjectDir   string    `json:"project_dir,omitempty"`
+	StartedAt    time.Time `json:"started_at"`
+	LastActivity time.Time `json:"last_activity"`
+	Closed       bool      `json:"closed"`
+}
+
+// SessionQuery はセッション一覧の条件（ゼロ値の項目は条件にしない）
+type SessionQuery struct {
+	ProjectDir string
+	Since      time.Time // 最終アクティビティがこの時刻以降
+	Limit      int       // 最大件数（0なら全件）
+}
+
+// Message は会話の1発言
+type Message struct {
+	SessionID string    `json:"session_id"`
+	Role      string    `json:"role"` // user または assistant
+	Content   string    `json:"content"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// MessageQuery は会話の検索条件
+type MessageQuery struct {
+	Text      string // 含む文字列（大文字小文字を区別しない、空なら全て）
+	SessionID string // 特定セッションに限定
+	Limit     int    // 最大件数（0なら20）
+}
+
+// Stats は保存している件数
+type Stats struct {
+	Backend  string `json:"backend"`
+	Path     string `json:"path,omitempty"`
+	Sessions int    `json:"sessions"`
+	Messages int    `json:"messages"`
+	Entries  int    `json:"entries"` // キーバリューの件数（期限切れを除く）
+}
+
+// Store は保存先の抽象化
+type Store interface {
+	// SaveSession はセッションを追加・更新する
+	SaveSession(session SessionRecord) error
+	// ListSessions は最終アクティビティの新しい順にセッションを返す
+	ListSessions(query SessionQuery) ([]SessionRecord, error)
+
+	// SaveSessionData はセッションの状態（kind 毎に1つ、JSON に変換できる値）を保存する
+	SaveSessionData(sessionID, kind string, value interface{}) error
+	// LoadSessionData はセッションの状態を dest に読み込む（保存されていなければ false）
+	LoadSessionData(sessionID, kind string, dest interface{}) (bool, error)
+
+	// AppendMessage は会話の発言を追加する
+	AppendMessage(message Message) error
+	// SearchMessages は新しい順に発言を検索する
+	SearchMessages(query MessageQuery) ([]Message, error)
+
+	// Put は bucket 内のキーに値を保存する（expiresAt がゼロ値なら期限なし）
+	Put(bucket, key string, value []byte, expiresAt time.Time) error
+	// Get は期限内の値を返す（なければ false）
+	Get(bucket, key string) ([]byte, bool, error)
+	// Delete はキーを削除する
+	Delete(bucket, key string) error
+	// DeleteBucket は bucket 内の全てのキーを削除する
+	DeleteBucket(bucket string) error
+
+	// Stats は保存している件数を返す
+	Stats() (Stats, error)
+	Close() error
+}
+
+// 保存先の種類
+const (
+	BackendSQLite = "sqlite"
+	BackendMemory = "memory"
+)
+
+// 保存に使う bucket・状態の種類
+const (
+	KindMetrics          = "metrics"           // セッションのメトリクス
+	KindConversationFlow = "conversation_flow" // 会話フローの進行状況
+	BucketLLMCache       = "llm_cache"         // LLM応答キャッシュ
+)
+
+// defaultSearchLimit は件数を指定しない検索の最大件数
+const defaultSearchLimit = 20
+
+// DefaultPath は SQLite データベースの既定の場所（~/.vyb/vyb.db）
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "vyb.db")
+	}
+	return filepath.Join(homeDir, ".vyb", "vyb.db")
+}
+
+// Open は設定の保存先を開く
+func Open(cfg config.StorageConfig) (Store, error) {
+	switch cfg.Backend {
+	case BackendMemory:
+		return NewMemoryStore(), nil
+	case "", BackendSQLite:
+		path := cfg.Path
+		if path == "" {
+			path = DefaultPath()
+		}
+		return OpenSQLite(path)
+	default:
+		return nil, fmt.Errorf("未対応の保存先です: %s（有効な値: %v）", cfg.Backend, config.ValidStorageBackends())
+	}
+}
@@ -0,0 +1,196 @@
+package storage
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// forEachStore は同じテストを全ての保存先で実行する
+func forEachStore(t *testing.T, test func(t *testing.T, store Store)) {
+	t.Run("memory", func(t *testing.T) { test(t, NewMemoryStore()) })
+	t.Run("sqlite", func(t *testing.T) {
+		store, err := OpenSQLite(filepath.Join(t.TempDir(), "vyb.db"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer store.Close()
+		test(t, store)
+	})
+}
+
+func TestSessions(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		base := time.Unix(1700000000, 0)
+		for i, id := range []string{"a", "b", "c"} {
+			session := SessionRecord{ID: id, Type: "general", ProjectDir: "/p", StartedAt: base, LastActivity: base.Add(time.Duration(i) * time.Minute)}
+			if id == "c" {
+				session.ProjectDir = "/other"
+			}
+			if err := store.SaveSession(session); err != nil {
+				t.Fatal(err)
+			}
+		}
+		// 更新は上書きになる
+		if err := store.SaveSession(SessionRecord{ID: "a", Type: "general", Title: "Fix login bug", ProjectDir: "/p", StartedAt: base, LastActivity: base.Add(time.Hour), Closed: true}); err != nil {
+			t.Fatal(err)
+		}
+
+		sessions, err := store.ListSessions(SessionQuery{ProjectDir: "/p"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sessions) != 2 || sessions[0].ID != "a" || !sessions[0].Closed || sessions[1].ID != "b" {
+			t.Fatalf("unexpected sessions: %+v", sessions)
+		}
+		if sessions[0].Title != "Fix login bug" {
+			t.Errorf("title was not stored: %q", sessions[0].Title)
+		}
+		if !sessions[0].LastActivity.Equal(base.Add(time.Hour)) {
+			t.Errorf("last activity was not stored: %v", sessions[0].LastActivity)
+		}
+		sessions, _ = store.ListSessions(SessionQuery{Since: base.Add(90 * time.Second), Limit: 1})
+		if len(sessions) != 1 || sessions[0].ID != "a" {
+			t.Errorf("since/limit were not applied: %+v", sessions)
+		}
+	})
+}
+
+func TestSessionData(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		type metrics struct {
+			Commands int            `json:"commands"`
+			Tools    map[string]int `json:"tools"`
+		}
+		var loaded metrics
+		if ok, err := store.LoadSessionData("s1", KindMetrics, &loaded); ok || err != nil {
+			t.Fatalf("nothing should be stored yet: %v %v", ok, err)
+		}
+		saved := metrics{Commands: 3, Tools: map[string]int{"bash": 2}}
+		if err := store.SaveSessionData("s1", KindMetrics, saved); err != nil {
+			t.Fatal(err)
+		}
+		saved.Tools["bash"] = 10 // 保存後の変更は影響しない
+		if ok, err := store.LoadSessionData("s1", KindMetrics, &loaded); !ok || err != nil {
+			t.Fatalf("stored data was not found: %v %v", ok, err)
+		}
+		if loaded.Commands != 3 || loaded.Tools["bash"] != 2 {
+			t.Errorf("unexpected data: %+v", loaded)
+		}
+		if ok, _ := store.LoadSessionData("s1", KindConversationFlow, &loaded); ok {
+			t.Error("kinds must be stored separately")
+		}
+	})
+}
+
+func TestSearchMessages(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		for _, message := range []Message{
+			{SessionID: "s1", Role: "user", Content: "Fix the Parser bug"},
+			{SessionID: "s1", Role: "assistant", Content: "The parser now handles 100% of cases"},
+			{SessionID: "s2", Role: "user", Content: "add a parser_test"},
+		} {
+			if err := store.AppendMessage(message); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		found, err := store.SearchMessages(MessageQuery{Text: "PARSER"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(found) != 3 || found[0].SessionID != "s2" || found[2].Content != "Fix the Parser bug" {
+			t.Fatalf("messages should be matched case-insensitively, newest first: %+v", found)
+		}
+		if found[0].Timestamp.IsZero() {
+			t.Error("timestamp should default to now")
+		}
+		// LIKE の特殊文字は文字どおりに扱う
+		if found, _ := store.SearchMessages(MessageQuery{Text: "100%"}); len(found) != 1 {
+			t.Errorf("%% should match literally: %+v", found)
+		}
+		if found, _ := store.SearchMessages(MessageQuery{Text: "r_t"}); len(found) != 1 {
+			t.Errorf("_ should match literally: %+v", found)
+		}
+		if found, _ := store.SearchMessages(MessageQuery{SessionID: "s1", Limit: 1}); len(found) != 1 || found[0].Role != "assistant" {
+			t.Errorf("session/limit were not applied: %+v", found)
+		}
+	})
+}
+
+func TestKeyValue(t *testing.T) {
+	forEachStore(t, func(t *testing.T, store Store) {
+		if err := store.Put(BucketLLMCache, "k", []byte("v1"), time.Time{}); err != nil {
+			t.Fatal(err)
+		}
+		if err := store.Put(BucketLLMCache, "old", []byte("v"), time.Now().Add(-time.Second)); err != nil {
+			t.Fatal(err)
+		}
+		if value, ok, err := store.Get(BucketLLMCache, "k"); !ok || err != nil || string(value) != "v1" {
+			t.Fatalf("unexpected value: %q %v %v", value, ok, err)
+		}
+		if _, ok, _ := store.Get("other", "k"); ok {
+			t.Error("buckets must be separate")
+		}
+		if _, ok, _ := store.Get(BucketLLMCache, "old"); ok {
+			t.Error("expired values must not be returned")
+		}
+
+		stats, err := store.Stats()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if stats.Entries != 1 {
+			t.Errorf("unexpected stats: %+v", stats)
+		}
+		if err := store.Delete(BucketLLMCache, "k"); err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, _ := store.Get(BucketLLMCache, "k"); ok {
+			t.Error("deleted value was returned")
+		}
+
+		store.Put("a", "k1", []byte("1"), time.Time{})
+		store.Put("b", "k1", []byte("1"), time.Time{})
+		if err := store.DeleteBucket("a"); err != nil {
+			t.Fatal(err)
+		}
+		_, inA, _ := store.Get("a", "k1")
+		_, inB, _ := store.Get("b", "k1")
+		if inA || !inB {
+			t.Errorf("only bucket a should be cleared: %v %v", inA, inB)
+		}
+	})
+}
+
+func TestSQLitePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "vyb.db")
+	store, err := Open(config.StorageConfig{Backend: BackendSQLite, Path: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AppendMessage(Message{SessionID: "s1", Role: "user", Content: "hello"}); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	// 開き直しても残っていて、スキーマの再作成は行わない
+	reopened, err := OpenSQLite(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	stats, err := reopened.Stats()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Messages != 1 || stats.Path != path {
+		t.Errorf("messages were not persisted: %+v", stats)
+	}
+
+	if _, err := Open(config.StorageConfig{Backend: "redis"}); err == nil {
+		t.Error("unknown backend should fail")
+	}
+}
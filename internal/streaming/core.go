@@ -55,6 +55,8 @@ type UnifiedStreamConfig struct {
 	CodeBlockDelay  time.Duration `json:"code_block_delay"`
 	EnableStreaming bool          `json:"enable_streaming"`
 	MaxLineLength   int           `json:"max_line_length"`
+	SyntaxTheme     string        `json:"syntax_theme"` // コードブロック・差分の配色 dark/light/none（空は dark）
+	Fold            FoldConfig    `json:"fold"`         // 長いコマンド・ツール出力の折り畳み
 
 	// パフォーマンス設定
 	MaxWorkers int           `json:"max_workers"`
@@ -133,6 +135,7 @@ func DefaultStreamConfig() *UnifiedStreamConfig {
 		CodeBlockDelay:  5 * time.Millisecond,
 		EnableStreaming: true,
 		MaxLineLength:   100,
+		Fold:            DefaultFoldConfig(),
 		MaxWorkers:      4,
 		QueueSize:       40,
 		Timeout:         30 * time.Second,
@@ -0,0 +1,50 @@
+package streaming
+
+// FoldConfig は長いコマンド・ツール出力の折り畳みとページ分割の設定
+type FoldConfig struct {
+	Threshold int `json:"threshold"`  // これを超える行数の出力を折り畳む（0 以下で折り畳まない）
+	Keep      int `json:"keep"`       // 折り畳み時に先頭・末尾に残す行数
+	PageLines int `json:"page_lines"` // 展開時の1ページの行数
+}
+
+// DefaultFoldConfig - デフォルトの折り畳み設定
+func DefaultFoldConfig() FoldConfig {
+	return FoldConfig{Threshold: 30, Keep: 5, PageLines: 200}
+}
+
+// Folds は n 行の出力を折り畳むか
+func (c FoldConfig) Folds(n int) bool {
+	return c.Threshold > 0 && n > c.Threshold && n > 2*c.Keep
+}
+
+// Fold は出力を先頭・末尾に残す行と隠す行数に分ける（折り畳まない場合は全行を head で返す）
+func (c FoldConfig) Fold(lines []string) (head []string, hidden int, tail []string) {
+	if !c.Folds(len(lines)) {
+		return lines, 0, nil
+	}
+	return lines[:c.Keep], len(lines) - 2*c.Keep, lines[len(lines)-c.Keep:]
+}
+
+// Pages は n 行の出力を展開したときのページ数
+func (c FoldConfig) Pages(n int) int {
+	if c.PageLines <= 0 || n <= c.PageLines {
+		return 1
+	}
+	return (n + c.PageLines - 1) / c.PageLines
+}
+
+// Page は n 行の出力の page ページ目（0始まり）の行範囲
+func (c FoldConfig) Page(n, page int) (start, end int) {
+	if c.PageLines <= 0 {
+		return 0, n
+	}
+	start = page * c.PageLines
+	if start > n {
+		start = n
+	}
+	end = start + c.PageLines
+	if end > n {
+		end = n
+	}
+	return start, end
+}
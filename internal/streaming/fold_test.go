@@ -0,0 +1,32 @@
+package streaming
+
+import "testing"
+
+func TestFoldConfig(t *testing.T) {
+	c := FoldConfig{Threshold: 10, Keep: 3, PageLines: 4}
+	lines := make([]string, 12)
+	for i := range lines {
+		lines[i] = string(rune('a' + i))
+	}
+
+	head, hidden, tail := c.Fold(lines)
+	if len(head) != 3 || hidden != 6 || len(tail) != 3 || tail[2] != "l" {
+		t.Fatalf("Fold = %v, %d, %v", head, hidden, tail)
+	}
+	if _, hidden, _ := c.Fold(lines[:10]); hidden != 0 {
+		t.Fatalf("output at the threshold should not fold, hidden %d", hidden)
+	}
+	if (FoldConfig{Threshold: -1, Keep: 3}).Folds(100) {
+		t.Fatal("a negative threshold should disable folding")
+	}
+
+	if pages := c.Pages(len(lines)); pages != 3 {
+		t.Fatalf("Pages = %d", pages)
+	}
+	if start, end := c.Page(len(lines), 2); start != 8 || end != 12 {
+		t.Fatalf("Page(2) = %d-%d", start, end)
+	}
+	if start, end := (FoldConfig{}).Page(7, 0); start != 0 || end != 7 {
+		t.Fatalf("zero PageLines should return everything, got %d-%d", start, end)
+	}
+}
@@ -145,6 +145,13 @@ func (m *Manager) UpdateConfig(config *UnifiedStreamConfig) {
 	m.uiProcessor.SetConfig(config)
 }
 
+// FoldConfig - 長い出力の折り畳み設定を取得
+func (m *Manager) FoldConfig() FoldConfig {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.Fold
+}
+
 // GetGlobalMetrics - グローバルメトリクスを取得
 func (m *Manager) GetGlobalMetrics() *GlobalMetrics {
 	m.mu.RLock()
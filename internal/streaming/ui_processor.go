@@ -9,6 +9,8 @@ import (
 	"sync"
 	"time"
 	"unicode/utf8"
+
+	"github.com/glkt/vyb-code/internal/markdown"
 )
 
 // UIProcessor - UI表示専用ストリーミングプロセッサー
@@ -164,9 +166,11 @@ func (p *UIProcessor) tokenizeContent(content string) []UIToken {
 	var tokens []UIToken
 	lines := strings.Split(content, "\n")
 
-	for lineIndex, line := range lines {
+	for lineIndex := 0; lineIndex < len(lines); lineIndex++ {
+		line := lines[lineIndex]
+
 		// コードブロック判定
-		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+		if isFence(line) {
 			p.mu.Lock()
 			p.state.InCodeBlock = !p.state.InCodeBlock
 			if p.state.InCodeBlock {
@@ -185,11 +189,29 @@ func (p *UIProcessor) tokenizeContent(content string) []UIToken {
 
 		p.mu.RLock()
 		inCodeBlock := p.state.InCodeBlock
+		language := p.state.CodeLanguage
 		p.mu.RUnlock()
 
 		if inCodeBlock {
-			// コードブロック内：行単位で処理
-			tokens = append(tokens, p.processCodeLine(line))
+			// コードブロック内：ブロックの終わりまでまとめて色付けし、行単位で処理
+			end := lineIndex
+			for end < len(lines) && !isFence(lines[end]) {
+				end++
+			}
+			for _, code := range p.highlightLines(language, lines[lineIndex:end]) {
+				tokens = append(tokens, p.processCodeLine(code))
+			}
+			lineIndex = end - 1
+		} else if lineIndex+1 < len(lines) && markdown.IsUnifiedDiff(line+"\n"+lines[lineIndex+1]) {
+			// コードブロック外の unified diff：差分の行が続く間をまとめて色付け
+			end := lineIndex + 1
+			for end < len(lines) && markdown.IsDiffLine(lines[end]) {
+				end++
+			}
+			for _, diffLine := range p.highlightLines("diff", lines[lineIndex:end]) {
+				tokens = append(tokens, p.processCodeLine(diffLine))
+			}
+			lineIndex = end - 1
 		} else {
 			// 通常テキスト：単語・文レベルで処理
 			lineTokens := p.processTextLine(line, lineIndex)
@@ -200,6 +222,20 @@ func (p *UIProcessor) tokenizeContent(content string) []UIToken {
 	return tokens
 }
 
+// isFence - コードブロックの開始・終了行か
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), "```")
+}
+
+// highlightLines - コード行を言語に応じて色付け（無効・未対応の言語はそのまま）
+func (p *UIProcessor) highlightLines(language string, lines []string) []string {
+	highlighter := markdown.NewHighlighter(p.config.SyntaxTheme)
+	if highlighted := highlighter.Lines(language, strings.Join(lines, "\n")); len(highlighted) == len(lines) {
+		return highlighted
+	}
+	return lines
+}
+
 // processTextLine - テキスト行を処理
 func (p *UIProcessor) processTextLine(line string, lineIndex int) []UIToken {
 	var tokens []UIToken
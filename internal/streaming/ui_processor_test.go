@@ -0,0 +1,60 @@
+package streaming
+
+import (
+	"strings"
+	"testing"
+)
+
+func codeTokens(tokens []UIToken) []string {
+	var code []string
+	for _, token := range tokens {
+		if token.Type == UITokenCode {
+			code = append(code, token.Content)
+		}
+	}
+	return code
+}
+
+func TestUIProcessor_HighlightsCodeBlocks(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	processor := NewUIProcessor(DefaultStreamConfig())
+
+	tokens := processor.tokenizeContent("Example:\n```go\n/* a\nb */\nfunc main() {}\n```\ndone")
+	code := codeTokens(tokens)
+	if len(code) != 3 {
+		t.Fatalf("コード行を1行ずつトークンにするはず: %q", code)
+	}
+	for _, line := range code {
+		if !strings.Contains(line, "\033[") {
+			t.Errorf("コード行を色付けするはず: %q", line)
+		}
+	}
+	if tokens[len(tokens)-1].Content != "done" {
+		t.Errorf("コードブロック後は通常のテキストとして処理するはず: %+v", tokens[len(tokens)-1])
+	}
+}
+
+func TestUIProcessor_HighlightsDiffs(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	processor := NewUIProcessor(DefaultStreamConfig())
+
+	tokens := processor.tokenizeContent("Changes:\n--- a/x.go\n+++ b/x.go\n@@ -1 +1 @@\n-a\n+b\nThat's it.")
+	code := codeTokens(tokens)
+	if len(code) != 5 || !strings.Contains(code[3], "-a") || code[3] == "-a" {
+		t.Fatalf("差分の行をまとめて色付けするはず: %q", code)
+	}
+	if strings.Contains(strings.Join(code, ""), "That's") {
+		t.Error("差分の後の文は差分に含めないはず")
+	}
+}
+
+func TestUIProcessor_SyntaxThemeNone(t *testing.T) {
+	config := DefaultStreamConfig()
+	config.SyntaxTheme = "none"
+	processor := NewUIProcessor(config)
+
+	code := codeTokens(processor.tokenizeContent("```go\nfunc main() {}\n```"))
+	if len(code) != 1 || code[0] != "func main() {}" {
+		t.Errorf("none ではコードをそのまま出力するはず: %q", code)
+	}
+}
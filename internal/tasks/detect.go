@@ -0,0 +1,148 @@
+package tasks
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// Kind はタスクの種類
+type Kind string
+
+const (
+	KindBuild Kind = "build"
+	KindTest  Kind = "test"
+	KindLint  Kind = "lint"
+)
+
+// ValidKinds は有効なタスク種類一覧を返す
+func ValidKinds() []Kind {
+	return []Kind{KindBuild, KindTest, KindLint}
+}
+
+// ビルドシステム名
+const (
+	SystemMake   = "make"
+	SystemGo     = "go"
+	SystemNpm    = "npm"
+	SystemCargo  = "cargo"
+	SystemGradle = "gradle"
+)
+
+// BuildSystem は検出したビルドシステムとタスク毎の実行コマンド
+type BuildSystem struct {
+	Name     string          `json:"name"`
+	Commands map[Kind]string `json:"commands"` // 対応するコマンドがないタスクは含まない
+}
+
+// Command は指定タスクのコマンドを返す（未対応なら空文字）
+func (bs *BuildSystem) Command(kind Kind) string {
+	return bs.Commands[kind]
+}
+
+// makeTargetPattern はMakefileのターゲット定義行
+var makeTargetPattern = regexp.MustCompile(`(?m)^([A-Za-z0-9_.-]+)\s*:([^=]|$)`)
+
+// Detect はプロジェクトのビルドシステムを検出（Make, go, npm, cargo, gradle の順）
+func Detect(projectDir string) *BuildSystem {
+	language := detectLanguageSystem(projectDir)
+
+	// Makefileがあれば定義済みターゲットを優先し、不足分は言語標準コマンドで補う
+	if data, err := os.ReadFile(filepath.Join(projectDir, "Makefile")); err == nil {
+		targets := make(map[string]bool)
+		for _, match := range makeTargetPattern.FindAllStringSubmatch(string(data), -1) {
+			targets[match[1]] = true
+		}
+
+		system := &BuildSystem{Name: SystemMake, Commands: make(map[Kind]string)}
+		candidates := map[Kind][]string{
+			KindBuild: {"build", "all"},
+			KindTest:  {"test", "check"},
+			KindLint:  {"lint", "vet"},
+		}
+		for _, kind := range ValidKinds() {
+			for _, target := range candidates[kind] {
+				if targets[target] {
+					system.Commands[kind] = "make " + target
+					break
+				}
+			}
+			if system.Commands[kind] == "" && language != nil && language.Commands[kind] != "" {
+				system.Commands[kind] = language.Commands[kind]
+			}
+		}
+		if len(system.Commands) > 0 {
+			return system
+		}
+	}
+
+	return language
+}
+
+// detectLanguageSystem は言語標準のビルドツールを検出
+func detectLanguageSystem(projectDir string) *BuildSystem {
+	exists := func(name string) bool {
+		_, err := os.Stat(filepath.Join(projectDir, name))
+		return err == nil
+	}
+
+	switch {
+	case exists("go.mod"):
+		return &BuildSystem{Name: SystemGo, Commands: map[Kind]string{
+			KindBuild: "go build ./...",
+			KindTest:  "go test ./...",
+			KindLint:  "go vet ./...",
+		}}
+
+	case exists("package.json"):
+		system := &BuildSystem{Name: SystemNpm, Commands: make(map[Kind]string)}
+		scripts := readPackageScripts(filepath.Join(projectDir, "package.json"))
+		if scripts["build"] != "" {
+			system.Commands[KindBuild] = "npm run build"
+		}
+		if scripts["test"] != "" {
+			system.Commands[KindTest] = "npm test"
+		}
+		if scripts["lint"] != "" {
+			system.Commands[KindLint] = "npm run lint"
+		}
+		return system
+
+	case exists("Cargo.toml"):
+		return &BuildSystem{Name: SystemCargo, Commands: map[Kind]string{
+			KindBuild: "cargo build",
+			KindTest:  "cargo test",
+			KindLint:  "cargo clippy",
+		}}
+
+	case exists("build.gradle") || exists("build.gradle.kts"):
+		gradle := "gradle"
+		if exists("gradlew") {
+			gradle = "./gradlew"
+		}
+		return &BuildSystem{Name: SystemGradle, Commands: map[Kind]string{
+			KindBuild: gradle + " assemble",
+			KindTest:  gradle + " test",
+			KindLint:  gradle + " check -x test",
+		}}
+	}
+
+	return nil
+}
+
+// readPackageScripts はpackage.jsonのscriptsを読み込み
+func readPackageScripts(path string) map[string]string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil
+	}
+
+	var pkg struct {
+		Scripts map[string]string `json:"scripts"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return nil
+	}
+	return pkg.Scripts
+}
@@ -0,0 +1,150 @@
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// 解析結果として保持する失敗の最大件数
+const maxFailures = 50
+
+// Failure はコンパイラ・テスト・リンター出力から抽出した1件の失敗
+type Failure struct {
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`       // error, warning
+	Test     string `json:"test,omitempty"` // 失敗したテスト名
+	Message  string `json:"message"`
+}
+
+// Location は file:line:column 形式の位置を返す
+func (f Failure) Location() string {
+	if f.File == "" {
+		return ""
+	}
+	loc := f.File
+	if f.Line > 0 {
+		loc += ":" + strconv.Itoa(f.Line)
+		if f.Column > 0 {
+			loc += ":" + strconv.Itoa(f.Column)
+		}
+	}
+	return loc
+}
+
+var (
+	// go test の失敗テスト（--- FAIL: TestName (0.00s)）
+	goTestFailPattern = regexp.MustCompile(`^\s*--- FAIL: (\S+)`)
+	// go test のテスト内ログ（    foo_test.go:12: message）
+	goTestLogPattern = regexp.MustCompile(`^\s+(\S+\.go):(\d+): (.+)$`)
+	// rustc の診断見出し（error[E0308]: message）
+	rustHeaderPattern = regexp.MustCompile(`^(error|warning)(?:\[\w+\])?: (.+)$`)
+	// rustc の位置情報（ --> src/main.rs:3:5）
+	rustLocationPattern = regexp.MustCompile(`^\s*--> (\S+):(\d+):(\d+)`)
+	// tsc の診断（src/a.ts(3,5): error TS2322: message）
+	tscPattern = regexp.MustCompile(`^(\S+)\((\d+),(\d+)\): (error|warning) (.+)$`)
+	// 一般的なコンパイラ・リンター形式（file:line[:col]: [error:] message）
+	compilerPattern = regexp.MustCompile(`^(?:vet: )?(\S+\.[A-Za-z0-9]+):(\d+):(?:(\d+):)?\s*(?:(error|warning|note)\s*:\s*)?(.+)$`)
+)
+
+// ParseFailures はビルド・テスト・リント出力から構造化された失敗一覧を抽出
+func ParseFailures(output string) []Failure {
+	var failures []Failure
+	seen := make(map[string]bool)
+	add := func(f Failure) {
+		if f.Severity == "" {
+			f.Severity = "error"
+		}
+		key := fmt.Sprintf("%s|%s|%s", f.Location(), f.Test, f.Message)
+		if seen[key] || len(failures) >= maxFailures {
+			return
+		}
+		seen[key] = true
+		failures = append(failures, f)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n")
+	currentTest := ""
+	var pendingRust *Failure
+
+	for _, line := range lines {
+		// rustc: 見出しの後に位置情報が続く
+		if pendingRust != nil {
+			if m := rustLocationPattern.FindStringSubmatch(line); m != nil {
+				pendingRust.File = m[1]
+				pendingRust.Line, _ = strconv.Atoi(m[2])
+				pendingRust.Column, _ = strconv.Atoi(m[3])
+				add(*pendingRust)
+				pendingRust = nil
+				continue
+			}
+			if strings.TrimSpace(line) == "" {
+				add(*pendingRust)
+				pendingRust = nil
+			}
+		}
+
+		if m := goTestFailPattern.FindStringSubmatch(line); m != nil {
+			currentTest = m[1]
+			continue
+		}
+		if currentTest != "" {
+			if m := goTestLogPattern.FindStringSubmatch(line); m != nil {
+				lineNo, _ := strconv.Atoi(m[2])
+				add(Failure{File: m[1], Line: lineNo, Test: currentTest, Message: strings.TrimSpace(m[3])})
+				continue
+			}
+			if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+				currentTest = ""
+			}
+		}
+
+		if m := rustHeaderPattern.FindStringSubmatch(line); m != nil {
+			// "error: could not compile" 等の集計行は位置情報が続かないため空行で確定
+			pendingRust = &Failure{Severity: m[1], Message: m[2]}
+			continue
+		}
+
+		if m := tscPattern.FindStringSubmatch(line); m != nil {
+			lineNo, _ := strconv.Atoi(m[2])
+			col, _ := strconv.Atoi(m[3])
+			add(Failure{File: m[1], Line: lineNo, Column: col, Severity: m[4], Message: m[5]})
+			continue
+		}
+
+		if m := compilerPattern.FindStringSubmatch(line); m != nil {
+			lineNo, _ := strconv.Atoi(m[2])
+			col, _ := strconv.Atoi(m[3])
+			severity := m[4]
+			if severity == "note" {
+				continue
+			}
+			add(Failure{File: strings.TrimPrefix(m[1], "./"), Line: lineNo, Column: col, Severity: severity, Message: strings.TrimSpace(m[5])})
+		}
+	}
+
+	if pendingRust != nil {
+		add(*pendingRust)
+	}
+
+	// go test で位置情報のないテスト失敗も記録
+	for _, line := range lines {
+		if m := goTestFailPattern.FindStringSubmatch(line); m != nil {
+			found := false
+			for _, f := range failures {
+				if f.Test == m[1] {
+					found = true
+					break
+				}
+			}
+			if !found {
+				add(Failure{Test: m[1], Message: "test failed"})
+			}
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,192 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/sandbox"
+)
+
+const (
+	defaultTaskTimeout = 10 * time.Minute
+	maxOutputBytes     = 256 * 1024 // 保持する出力の最大サイズ（末尾を優先）
+	outputTailLines    = 30         // 構造化できなかった場合に表示する末尾行数
+)
+
+// Result はタスク実行結果
+type Result struct {
+	Kind     Kind          `json:"kind"`
+	System   string        `json:"system"`
+	Command  string        `json:"command"`
+	Success  bool          `json:"success"`
+	ExitCode int           `json:"exit_code"`
+	Output   string        `json:"output"`
+	Duration time.Duration `json:"duration"`
+	Failures []Failure     `json:"failures,omitempty"`
+}
+
+// Runner はプロジェクトのビルド・テスト・リントを実行
+type Runner struct {
+	projectDir string
+	backend    sandbox.Backend
+	system     *BuildSystem
+	timeout    time.Duration
+}
+
+// NewRunner はビルドシステムを検出してランナーを作成（backendがnilならホスト実行）
+func NewRunner(projectDir string, backend sandbox.Backend) (*Runner, error) {
+	system := Detect(projectDir)
+	if system == nil {
+		return nil, i18n.Errorf("error.build_system_not_found", projectDir)
+	}
+	if backend == nil {
+		backend = sandbox.NewHostBackend()
+	}
+
+	return &Runner{
+		projectDir: projectDir,
+		backend:    backend,
+		system:     system,
+		timeout:    defaultTaskTimeout,
+	}, nil
+}
+
+// NewRunnerWithSystem は検出せずに指定のビルドシステムでランナーを作成（backendがnilならホスト実行）
+func NewRunnerWithSystem(projectDir string, backend sandbox.Backend, system *BuildSystem) *Runner {
+	if backend == nil {
+		backend = sandbox.NewHostBackend()
+	}
+	return &Runner{
+		projectDir: projectDir,
+		backend:    backend,
+		system:     system,
+		timeout:    defaultTaskTimeout,
+	}
+}
+
+// System は検出したビルドシステムを返す
+func (r *Runner) System() *BuildSystem {
+	return r.system
+}
+
+// SetTimeout はタスク毎のタイムアウトを設定
+func (r *Runner) SetTimeout(timeout time.Duration) {
+	if timeout > 0 {
+		r.timeout = timeout
+	}
+}
+
+// RunBuild はビルドを実行
+func (r *Runner) RunBuild(ctx context.Context) (*Result, error) {
+	return r.Run(ctx, KindBuild)
+}
+
+// RunTests はテストを実行
+func (r *Runner) RunTests(ctx context.Context) (*Result, error) {
+	return r.Run(ctx, KindTest)
+}
+
+// RunLint はリントを実行
+func (r *Runner) RunLint(ctx context.Context) (*Result, error) {
+	return r.Run(ctx, KindLint)
+}
+
+// Run は指定タスクを実行し、失敗時は出力を構造化して返す
+// コマンドが非ゼロ終了した場合はエラーではなく Success=false の結果を返す
+func (r *Runner) Run(ctx context.Context, kind Kind) (*Result, error) {
+	command := r.system.Command(kind)
+	if command == "" {
+		return nil, i18n.Errorf("error.task_command_missing", r.system.Name, kind)
+	}
+	return r.RunCommand(ctx, kind, command)
+}
+
+// RunCommand は任意のコマンドを指定タスクとして実行する（対象ファイルを絞ったテスト等）
+func (r *Runner) RunCommand(ctx context.Context, kind Kind, command string) (*Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	cmd, err := r.backend.Command(ctx, command, r.projectDir)
+	if err != nil {
+		return nil, i18n.Errorf("error.task_command_build", err)
+	}
+
+	startTime := time.Now()
+	output, runErr := cmd.CombinedOutput()
+	result := &Result{
+		Kind:     kind,
+		System:   r.system.Name,
+		Command:  command,
+		Output:   truncateHead(string(output), maxOutputBytes),
+		Duration: time.Since(startTime),
+		Success:  runErr == nil,
+	}
+
+	if runErr != nil {
+		if ctx.Err() != nil {
+			return nil, i18n.Errorf("error.task_timeout", command, ctx.Err())
+		}
+		var exitErr *exec.ExitError
+		if !errors.As(runErr, &exitErr) {
+			return nil, i18n.Errorf("error.task_exec", command, runErr)
+		}
+		result.ExitCode = exitErr.ExitCode()
+		result.Failures = ParseFailures(result.Output)
+	}
+
+	return result, nil
+}
+
+// FormatFailures はタスク結果をLLM・ユーザー向けの修正用コンテキストに整形
+func FormatFailures(result *Result) string {
+	var sb strings.Builder
+	if result.Success {
+		sb.WriteString(fmt.Sprintf("✅ %s succeeded (%s, %s)\n", result.Kind, result.Command, result.Duration.Round(time.Millisecond)))
+		return sb.String()
+	}
+
+	sb.WriteString(fmt.Sprintf("❌ %s failed (%s, exit %d)\n", result.Kind, result.Command, result.ExitCode))
+	if len(result.Failures) == 0 {
+		// 構造化できなかった場合は出力末尾を添付
+		lines := strings.Split(strings.TrimRight(result.Output, "\n"), "\n")
+		if len(lines) > outputTailLines {
+			lines = lines[len(lines)-outputTailLines:]
+		}
+		sb.WriteString("```\n")
+		sb.WriteString(strings.Join(lines, "\n"))
+		sb.WriteString("\n```\n")
+		return sb.String()
+	}
+
+	for _, failure := range result.Failures {
+		sb.WriteString("- ")
+		if loc := failure.Location(); loc != "" {
+			sb.WriteString(loc + ": ")
+		}
+		if failure.Test != "" {
+			sb.WriteString("[" + failure.Test + "] ")
+		}
+		if failure.Severity == "warning" {
+			sb.WriteString("warning: ")
+		}
+		sb.WriteString(failure.Message + "\n")
+	}
+	return sb.String()
+}
+
+// truncateHead は長い出力の先頭を切り詰めて末尾を残す
+func truncateHead(s string, limit int) string {
+	if len(s) <= limit {
+		return s
+	}
+	s = s[len(s)-limit:]
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[i+1:]
+	}
+	return s
+}
@@ -0,0 +1,165 @@
+package tasks
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDetect(t *testing.T) {
+	t.Run("go", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "go.mod", "module example.com/x\n")
+
+		system := Detect(dir)
+		if system == nil || system.Name != SystemGo {
+			t.Fatalf("expected go system, got %+v", system)
+		}
+		if system.Command(KindTest) != "go test ./..." {
+			t.Errorf("unexpected test command: %s", system.Command(KindTest))
+		}
+	})
+
+	t.Run("makefile overrides with fallback", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "go.mod", "module example.com/x\n")
+		writeFile(t, dir, "Makefile", "VAR := 1\nbuild:\n\tgo build\ntest: build\n\tgo test\n")
+
+		system := Detect(dir)
+		if system == nil || system.Name != SystemMake {
+			t.Fatalf("expected make system, got %+v", system)
+		}
+		if system.Command(KindBuild) != "make build" || system.Command(KindTest) != "make test" {
+			t.Errorf("unexpected make commands: %+v", system.Commands)
+		}
+		if system.Command(KindLint) != "go vet ./..." {
+			t.Errorf("expected lint fallback to go vet, got %q", system.Command(KindLint))
+		}
+	})
+
+	t.Run("npm scripts", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "package.json", `{"scripts": {"test": "jest", "lint": "eslint ."}}`)
+
+		system := Detect(dir)
+		if system == nil || system.Name != SystemNpm {
+			t.Fatalf("expected npm system, got %+v", system)
+		}
+		if system.Command(KindBuild) != "" {
+			t.Errorf("expected no build command, got %q", system.Command(KindBuild))
+		}
+		if system.Command(KindLint) != "npm run lint" {
+			t.Errorf("unexpected lint command: %q", system.Command(KindLint))
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		if system := Detect(t.TempDir()); system != nil {
+			t.Errorf("expected nil, got %+v", system)
+		}
+	})
+}
+
+func TestParseFailures(t *testing.T) {
+	tests := []struct {
+		name     string
+		output   string
+		expected []Failure
+	}{
+		{
+			name:   "go build",
+			output: "# example.com/x\n./main.go:5:2: undefined: foo\n",
+			expected: []Failure{
+				{File: "main.go", Line: 5, Column: 2, Severity: "error", Message: "undefined: foo"},
+			},
+		},
+		{
+			name:   "go test",
+			output: "--- FAIL: TestAdd (0.00s)\n    add_test.go:12: expected 3, got 4\nFAIL\nFAIL\texample.com/x\t0.01s\n",
+			expected: []Failure{
+				{File: "add_test.go", Line: 12, Severity: "error", Test: "TestAdd", Message: "expected 3, got 4"},
+			},
+		},
+		{
+			name:   "rustc",
+			output: "error[E0308]: mismatched types\n --> src/main.rs:3:5\n  |\n",
+			expected: []Failure{
+				{File: "src/main.rs", Line: 3, Column: 5, Severity: "error", Message: "mismatched types"},
+			},
+		},
+		{
+			name:   "tsc",
+			output: "src/app.ts(10,4): error TS2322: Type 'string' is not assignable to type 'number'.\n",
+			expected: []Failure{
+				{File: "src/app.ts", Line: 10, Column: 4, Severity: "error", Message: "TS2322: Type 'string' is not assignable to type 'number'."},
+			},
+		},
+		{
+			name:   "gcc warning",
+			output: "util.c:7:10: warning: unused variable 'x'\nutil.c:7:10: note: declared here\n",
+			expected: []Failure{
+				{File: "util.c", Line: 7, Column: 10, Severity: "warning", Message: "unused variable 'x'"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			failures := ParseFailures(tt.output)
+			if len(failures) != len(tt.expected) {
+				t.Fatalf("expected %d failures, got %d: %+v", len(tt.expected), len(failures), failures)
+			}
+			for i, expected := range tt.expected {
+				if failures[i] != expected {
+					t.Errorf("failure %d: expected %+v, got %+v", i, expected, failures[i])
+				}
+			}
+		})
+	}
+}
+
+func TestRunner_Run(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, dir, "Makefile", "test:\n\t@echo 'calc.go:3: boom' && exit 2\nbuild:\n\t@echo ok\n")
+
+	if _, err := exec.LookPath("make"); err != nil {
+		t.Skip("make not available")
+	}
+
+	runner, err := NewRunner(dir, nil)
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+
+	result, err := runner.RunBuild(context.Background())
+	if err != nil {
+		t.Fatalf("RunBuild failed: %v", err)
+	}
+	if !result.Success {
+		t.Errorf("expected build success, output: %s", result.Output)
+	}
+
+	result, err = runner.RunTests(context.Background())
+	if err != nil {
+		t.Fatalf("RunTests failed: %v", err)
+	}
+	if result.Success || result.ExitCode == 0 {
+		t.Fatalf("expected test failure, got %+v", result)
+	}
+	if len(result.Failures) != 1 || result.Failures[0].File != "calc.go" || result.Failures[0].Line != 3 {
+		t.Errorf("unexpected failures: %+v", result.Failures)
+	}
+
+	if _, err := runner.RunLint(context.Background()); err == nil {
+		t.Error("expected error for missing lint command")
+	}
+}
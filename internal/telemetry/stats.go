@@ -0,0 +1,143 @@
+package telemetry
+
+import (
+	"path"
+	"sort"
+	"time"
+)
+
+// Week は1週間（月曜始まり）の利用状況
+type Week struct {
+	Start            time.Time `json:"start"`
+	Sessions         int       `json:"sessions"`
+	Turns            int       `json:"turns"`
+	Accepted         int       `json:"accepted"`
+	Rejected         int       `json:"rejected"`
+	Edits            int       `json:"edits"`
+	AverageLatencyMs int64     `json:"average_latency_ms"`
+
+	sessions map[string]bool
+	latency  int64
+}
+
+// AcceptanceRate は判断した提案のうち承認した割合（判断がなければ0）
+func (w Week) AcceptanceRate() float64 {
+	return rate(w.Accepted, w.Rejected)
+}
+
+// PackageEdits はパッケージ毎の変更回数
+type PackageEdits struct {
+	Package string `json:"package"`
+	Edits   int    `json:"edits"`
+}
+
+// Stats は期間内の利用統計の集計
+type Stats struct {
+	Since            time.Time      `json:"since"`
+	Sessions         int            `json:"sessions"`
+	Turns            int            `json:"turns"`
+	FailedTurns      int            `json:"failed_turns"`
+	Accepted         int            `json:"accepted"`
+	Rejected         int            `json:"rejected"`
+	AcceptanceRate   float64        `json:"acceptance_rate"`
+	AverageLatencyMs int64          `json:"average_latency_ms"`
+	MedianLatencyMs  int64          `json:"median_latency_ms"`
+	Weeks            []Week         `json:"weeks"`    // 古い順（記録のない週も含む）
+	Packages         []PackageEdits `json:"packages"` // 変更の多い順
+}
+
+// WeekStart は時刻を含む週の月曜0時（ローカル時刻）を返す
+func WeekStart(t time.Time) time.Time {
+	t = t.Local()
+	offset := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-offset, 0, 0, 0, 0, t.Location())
+}
+
+// Summarize は now を含む直近 weeks 週の記録を集計する（パッケージは上位 topPackages 件、0 なら全件）
+func Summarize(records []Record, weeks int, now time.Time, topPackages int) *Stats {
+	if weeks < 1 {
+		weeks = 1
+	}
+	since := WeekStart(now).AddDate(0, 0, -7*(weeks-1))
+	stats := &Stats{Since: since, Weeks: make([]Week, weeks)}
+	for i := range stats.Weeks {
+		stats.Weeks[i].Start = since.AddDate(0, 0, 7*i)
+		stats.Weeks[i].sessions = make(map[string]bool)
+	}
+
+	sessions := make(map[string]bool)
+	packages := make(map[string]int)
+	var latencies []int64
+	for _, record := range records {
+		if record.Time.Before(since) || record.Time.After(now) {
+			continue
+		}
+		week := &stats.Weeks[int(WeekStart(record.Time).Sub(since).Hours()+12)/(7*24)]
+		// セッション数は開始の記録がなくても、ターン等の記録があれば数える
+		if record.SessionID != "" {
+			sessions[record.SessionID] = true
+			week.sessions[record.SessionID] = true
+		}
+		switch record.Type {
+		case TypeTurn:
+			stats.Turns++
+			week.Turns++
+			week.latency += record.DurationMs
+			latencies = append(latencies, record.DurationMs)
+			if !record.Success {
+				stats.FailedTurns++
+			}
+		case TypeSuggestion:
+			if record.Accepted {
+				stats.Accepted++
+				week.Accepted++
+			} else {
+				stats.Rejected++
+				week.Rejected++
+			}
+		case TypeEdit:
+			week.Edits++
+			packages[path.Join(record.Project, record.Package)]++
+		}
+	}
+
+	for i := range stats.Weeks {
+		week := &stats.Weeks[i]
+		week.Sessions = len(week.sessions)
+		if week.Turns > 0 {
+			week.AverageLatencyMs = week.latency / int64(week.Turns)
+		}
+	}
+	stats.Sessions = len(sessions)
+	stats.AcceptanceRate = rate(stats.Accepted, stats.Rejected)
+	if len(latencies) > 0 {
+		var total int64
+		for _, latency := range latencies {
+			total += latency
+		}
+		stats.AverageLatencyMs = total / int64(len(latencies))
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		stats.MedianLatencyMs = latencies[len(latencies)/2]
+	}
+
+	for name, edits := range packages {
+		stats.Packages = append(stats.Packages, PackageEdits{Package: name, Edits: edits})
+	}
+	sort.Slice(stats.Packages, func(i, j int) bool {
+		if stats.Packages[i].Edits != stats.Packages[j].Edits {
+			return stats.Packages[i].Edits > stats.Packages[j].Edits
+		}
+		return stats.Packages[i].Package < stats.Packages[j].Package
+	})
+	if topPackages > 0 && len(stats.Packages) > topPackages {
+		stats.Packages = stats.Packages[:topPackages]
+	}
+	return stats
+}
+
+func rate(accepted, rejected int) float64 {
+	if accepted+rejected == 0 {
+		return 0
+	}
+	return float64(accepted) / float64(accepted+rejected)
+}
@@ -0,0 +1,220 @@
+package telemetry
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+// 記録の種類
+const (
+	TypeSession    = "session"    // セッションの開始
+	TypeTurn       = "turn"       // ターンの所要時間と成否
+	TypeSuggestion = "suggestion" // 提案の承認・拒否
+	TypeEdit       = "edit"       // ファイル変更（パッケージ単位）
+)
+
+// Record は利用統計の1件（入力・応答・ファイルの内容は含めない）
+type Record struct {
+	Time       time.Time `json:"time"`
+	Type       string    `json:"type"`
+	SessionID  string    `json:"session_id,omitempty"`
+	DurationMs int64     `json:"duration_ms,omitempty"` // ターンの所要時間
+	Success    bool      `json:"success,omitempty"`     // ターンが成功したか
+	Accepted   bool      `json:"accepted,omitempty"`    // 提案を承認したか
+	Project    string    `json:"project,omitempty"`     // 変更したプロジェクト（ディレクトリ名）
+	Package    string    `json:"package,omitempty"`     // 変更したファイルのディレクトリ（プロジェクトからの相対パス）
+}
+
+// Recorder はイベントバスを購読し、設定で選んだ種類の利用統計をローカルのJSONLファイルに追記する
+type Recorder struct {
+	mu          sync.Mutex
+	path        string
+	events      []string
+	projectDir  string // 変更したファイルのパッケージを求める基準（開始時の作業ディレクトリ）
+	unsubscribe func()
+}
+
+// Start は設定に従ってイベントの購読を開始する（無効なら nil）
+func Start(cfg config.TelemetryConfig, bus *events.Bus) *Recorder {
+	if !cfg.Enabled || len(cfg.Events) == 0 {
+		return nil
+	}
+	projectDir, _ := os.Getwd()
+	r := New(cfg, projectDir)
+	r.unsubscribe = bus.Subscribe("*", r.handle)
+	return r
+}
+
+// New は記録先を作成する（イベントの購読はしない）
+func New(cfg config.TelemetryConfig, projectDir string) *Recorder {
+	path := cfg.Path
+	if path == "" {
+		path = DefaultPath()
+	}
+	return &Recorder{path: path, events: cfg.Events, projectDir: projectDir}
+}
+
+// DefaultPath は利用統計の保存先（~/.vyb/telemetry.jsonl）
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "vyb-telemetry.jsonl")
+	}
+	return filepath.Join(homeDir, ".vyb", "telemetry.jsonl")
+}
+
+// Path は保存先ファイルパスを返す
+func (r *Recorder) Path() string {
+	return r.path
+}
+
+// Close は購読を解除する
+func (r *Recorder) Close() {
+	if r == nil || r.unsubscribe == nil {
+		return
+	}
+	r.unsubscribe()
+	r.unsubscribe = nil
+}
+
+// handle はイベントを記録の種類に変換し、設定で選んだ種類のみ記録する
+func (r *Recorder) handle(event events.Event) {
+	record, ok := r.convert(event)
+	if !ok || !r.wants(record.Type) {
+		return
+	}
+	if err := r.Write(record); err != nil {
+		fmt.Fprintf(os.Stderr, "利用統計の記録エラー: %v\n", err)
+	}
+}
+
+func (r *Recorder) convert(event events.Event) (Record, bool) {
+	record := Record{Time: event.Time, SessionID: event.SessionID}
+	switch event.Type {
+	case events.SessionStarted:
+		record.Type = TypeSession
+	case events.TurnCompleted:
+		record.Type = TypeTurn
+		record.DurationMs = numberValue(event.Data["duration_ms"])
+		record.Success, _ = event.Data["success"].(bool)
+	case events.SuggestionDecided:
+		record.Type = TypeSuggestion
+		record.Accepted, _ = event.Data["accepted"].(bool)
+	case events.EditApplied:
+		path, _ := event.Data["path"].(string)
+		pkg, ok := r.packageOf(path)
+		if !ok {
+			return record, false
+		}
+		record.Type = TypeEdit
+		record.Project = filepath.Base(r.projectDir)
+		record.Package = pkg
+	default:
+		return record, false
+	}
+	return record, true
+}
+
+// packageOf はファイルのディレクトリをプロジェクトからの相対パスで返す（プロジェクト外のファイルは記録しない）
+func (r *Recorder) packageOf(path string) (string, bool) {
+	if path == "" || r.projectDir == "" {
+		return "", false
+	}
+	// 相対パスは現在の作業ディレクトリ（/workspace で移動していればモジュール）を基準にする
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	rel, err := filepath.Rel(r.projectDir, filepath.Dir(path))
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return filepath.ToSlash(rel), true
+}
+
+// wants は設定で記録する種類か
+func (r *Recorder) wants(recordType string) bool {
+	for _, event := range r.events {
+		if event == recordType {
+			return true
+		}
+	}
+	return false
+}
+
+// Write は1件の利用統計を追記
+func (r *Recorder) Write(record Record) error {
+	if record.Time.IsZero() {
+		record.Time = time.Now()
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0700); err != nil {
+		return fmt.Errorf("利用統計ディレクトリ作成失敗: %w", err)
+	}
+	file, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return fmt.Errorf("利用統計ファイル開封失敗: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return err
+}
+
+// Load は指定時刻以降の利用統計を読み込み（sinceがゼロ値なら全件）
+func Load(path string, since time.Time) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("利用統計の読み込みエラー: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue // 書き込み途中の行は無視
+		}
+		if !since.IsZero() && record.Time.Before(since) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// numberValue はイベントのデータの数値（JSON経由なら float64）を整数にする
+func numberValue(value interface{}) int64 {
+	switch v := value.(type) {
+	case int64:
+		return v
+	case int:
+		return int64(v)
+	case float64:
+		return int64(v)
+	}
+	return 0
+}
@@ -0,0 +1,99 @@
+package telemetry
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
+)
+
+// TestRecorder はイベントを選んだ種類だけ内容なしで記録し、プロジェクト外の変更は除くことをテストする
+func TestRecorder(t *testing.T) {
+	projectDir := t.TempDir()
+	path := filepath.Join(t.TempDir(), "telemetry.jsonl")
+	recorder := New(config.TelemetryConfig{Enabled: true, Path: path, Events: []string{TypeTurn, TypeSuggestion, TypeEdit}}, projectDir)
+	bus := events.NewBus()
+	recorder.unsubscribe = bus.Subscribe("*", recorder.handle)
+
+	bus.Publish(events.Event{Type: events.SessionStarted, SessionID: "s1"})
+	bus.Publish(events.Event{Type: events.ResponseGenerated, SessionID: "s1", Data: map[string]interface{}{"input": "secret prompt"}})
+	bus.Publish(events.Event{Type: events.TurnCompleted, SessionID: "s1", Data: map[string]interface{}{"duration_ms": int64(1500), "success": true}})
+	bus.Publish(events.Event{Type: events.SuggestionDecided, SessionID: "s1", Data: map[string]interface{}{"accepted": true}})
+	bus.Publish(events.Event{Type: events.EditApplied, SessionID: "s1", Data: map[string]interface{}{"path": filepath.Join(projectDir, "internal", "api", "server.go")}})
+	bus.Publish(events.Event{Type: events.EditApplied, SessionID: "s1", Data: map[string]interface{}{"path": filepath.Join(projectDir, "main.go")}})
+	bus.Publish(events.Event{Type: events.EditApplied, SessionID: "s1", Data: map[string]interface{}{"path": "/etc/hosts"}})
+	recorder.Close()
+	bus.Publish(events.Event{Type: events.TurnCompleted, SessionID: "s1"})
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "secret") || strings.Contains(string(data), "/etc") {
+		t.Errorf("入力の内容やプロジェクト外のパスは記録しないはず: %s", data)
+	}
+	records, err := Load(path, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var types []string
+	for _, record := range records {
+		types = append(types, record.Type)
+	}
+	if strings.Join(types, ",") != "turn,suggestion,edit,edit" {
+		t.Fatalf("選んだ種類だけ記録し、Close 後は記録しないはず: %v", types)
+	}
+	if records[0].DurationMs != 1500 || !records[0].Success || !records[1].Accepted {
+		t.Errorf("ターン・提案の記録 = %+v, %+v", records[0], records[1])
+	}
+	if records[2].Package != "internal/api" || records[3].Package != "." || records[2].Project != filepath.Base(projectDir) {
+		t.Errorf("パッケージはプロジェクトからの相対パスのはず: %+v, %+v", records[2], records[3])
+	}
+
+	if Start(config.DefaultTelemetryConfig(), bus) != nil {
+		t.Error("既定では記録しないはず")
+	}
+}
+
+// TestSummarize は週毎のセッション数・承認率・変更の多いパッケージ・平均所要時間の集計をテストする
+func TestSummarize(t *testing.T) {
+	now := time.Date(2026, 3, 12, 15, 0, 0, 0, time.Local) // 木曜
+	lastWeek := now.AddDate(0, 0, -7)
+	records := []Record{
+		{Time: now.AddDate(0, 0, -30), Type: TypeTurn, SessionID: "old", DurationMs: 99999},
+		{Time: lastWeek, Type: TypeSession, SessionID: "a"},
+		{Time: lastWeek, Type: TypeTurn, SessionID: "a", DurationMs: 1000, Success: true},
+		{Time: lastWeek, Type: TypeSuggestion, SessionID: "a", Accepted: false},
+		{Time: now.Add(-time.Hour), Type: TypeTurn, SessionID: "b", DurationMs: 3000, Success: true},
+		{Time: now.Add(-time.Hour), Type: TypeTurn, SessionID: "c", DurationMs: 5000},
+		{Time: now.Add(-time.Hour), Type: TypeSuggestion, SessionID: "b", Accepted: true},
+		{Time: now.Add(-time.Hour), Type: TypeSuggestion, SessionID: "b", Accepted: true},
+		{Time: now.Add(-time.Hour), Type: TypeEdit, Project: "app", Package: "internal/api"},
+		{Time: now.Add(-time.Hour), Type: TypeEdit, Project: "app", Package: "internal/api"},
+		{Time: now.Add(-time.Hour), Type: TypeEdit, Project: "app", Package: "."},
+	}
+
+	stats := Summarize(records, 2, now, 1)
+	if !stats.Since.Equal(time.Date(2026, 3, 2, 0, 0, 0, 0, time.Local)) || len(stats.Weeks) != 2 {
+		t.Fatalf("前週の月曜から集計するはず: %v, %d", stats.Since, len(stats.Weeks))
+	}
+	if stats.Sessions != 3 || stats.Weeks[0].Sessions != 1 || stats.Weeks[1].Sessions != 2 {
+		t.Errorf("週毎のセッション数 = %d, %+v", stats.Sessions, stats.Weeks)
+	}
+	if stats.Turns != 3 || stats.FailedTurns != 1 || stats.AverageLatencyMs != 3000 || stats.MedianLatencyMs != 3000 {
+		t.Errorf("ターン = %+v", stats)
+	}
+	if stats.Weeks[1].AverageLatencyMs != 4000 || stats.Weeks[1].AcceptanceRate() != 1 {
+		t.Errorf("今週 = %+v", stats.Weeks[1])
+	}
+	if stats.Accepted != 2 || stats.Rejected != 1 || stats.AcceptanceRate < 0.66 || stats.AcceptanceRate > 0.67 {
+		t.Errorf("承認率 = %d/%d %g", stats.Accepted, stats.Rejected, stats.AcceptanceRate)
+	}
+	if len(stats.Packages) != 1 || stats.Packages[0] != (PackageEdits{Package: "app/internal/api", Edits: 2}) {
+		t.Errorf("変更の多いパッケージ = %+v", stats.Packages)
+	}
+}
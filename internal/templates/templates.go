@@ -0,0 +1,232 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/tasks"
+)
+
+//go:embed builtin/*.yaml
+var builtinFS embed.FS
+
+// セッション種別（prompts の SessionType と同じ値）
+var sessionTypes = []string{"general", "debugging", "refactor", "review", "learning"}
+
+// Template は .vyb/templates/<name>.yaml のセッションテンプレート
+// 開始時（vyb --template <name>）に選び、毎ターンのプロンプトに指示・計画の骨組み・重視するツールを加える
+type Template struct {
+	Name         string   `yaml:"name" json:"name"`
+	Description  string   `yaml:"description,omitempty" json:"description,omitempty"`
+	SessionType  string   `yaml:"session_type,omitempty" json:"session_type,omitempty"`   // general, debugging, refactor, review, learning
+	SystemPrompt string   `yaml:"system_prompt,omitempty" json:"system_prompt,omitempty"` // プロンプトに加える指示
+	Plan         []string `yaml:"plan,omitempty" json:"plan,omitempty"`                   // 作業計画の骨組み
+	Tools        []string `yaml:"tools,omitempty" json:"tools,omitempty"`                 // 重視する構造化タグ（command, fileread 等）
+	RunFirst     []string `yaml:"run_first,omitempty" json:"run_first,omitempty"`         // 開始時に実行するタスク（build, test, lint）
+
+	Path string `yaml:"-" json:"path,omitempty"` // 読み込んだファイル（組み込みなら空）
+}
+
+// Source は表示用の定義元
+func (t *Template) Source() string {
+	if t.Path == "" {
+		return "built-in"
+	}
+	return t.Path
+}
+
+// Validate は定義の値を検証する
+func (t *Template) Validate() error {
+	if t.Name == "" {
+		return fmt.Errorf("テンプレートに name がありません")
+	}
+	if t.SessionType != "" && !contains(sessionTypes, t.SessionType) {
+		return fmt.Errorf("テンプレート %s: 不明な session_type です: %s（%s）", t.Name, t.SessionType, strings.Join(sessionTypes, ", "))
+	}
+	for _, kind := range t.RunFirst {
+		if !isTaskKind(kind) {
+			return fmt.Errorf("テンプレート %s: run_first に指定できるのは build・test・lint です: %s", t.Name, kind)
+		}
+	}
+	return nil
+}
+
+// Tasks は開始時に実行するタスク
+func (t *Template) Tasks() []tasks.Kind {
+	kinds := make([]tasks.Kind, 0, len(t.RunFirst))
+	for _, kind := range t.RunFirst {
+		kinds = append(kinds, tasks.Kind(kind))
+	}
+	return kinds
+}
+
+// Dir はプロジェクトのテンプレートディレクトリ（.vyb/templates）
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, config.ProjectConfigDir, "templates")
+}
+
+// Parse はYAMLのテンプレート定義を解析・検証する（name がなければ defaultName）
+func Parse(data []byte, defaultName string) (*Template, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var tmpl Template
+	if err := decoder.Decode(&tmpl); err != nil {
+		return nil, fmt.Errorf("テンプレート定義の解析エラー: %w", err)
+	}
+	if tmpl.Name == "" {
+		tmpl.Name = defaultName
+	}
+	if err := tmpl.Validate(); err != nil {
+		return nil, err
+	}
+	return &tmpl, nil
+}
+
+// Builtin は組み込みのテンプレート（bugfix, feature, spike）を名前順に返す
+func Builtin() []*Template {
+	entries, _ := builtinFS.ReadDir("builtin")
+	var builtins []*Template
+	for _, entry := range entries {
+		data, err := builtinFS.ReadFile("builtin/" + entry.Name())
+		if err != nil {
+			continue
+		}
+		tmpl, err := Parse(data, strings.TrimSuffix(entry.Name(), ".yaml"))
+		if err != nil {
+			panic(fmt.Sprintf("組み込みテンプレート %s が不正です: %v", entry.Name(), err))
+		}
+		builtins = append(builtins, tmpl)
+	}
+	return builtins
+}
+
+// Load は名前（またはYAMLファイルのパス）からテンプレートを読み込む（プロジェクトの定義を組み込みより優先）
+func Load(dir, name string) (*Template, error) {
+	var candidates []string
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		candidates = []string{name}
+	} else {
+		candidates = []string{filepath.Join(dir, name+".yaml"), filepath.Join(dir, name+".yml")}
+	}
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("テンプレート読み込みエラー: %w", err)
+		}
+		tmpl, err := Parse(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		tmpl.Path = path
+		return tmpl, nil
+	}
+	for _, tmpl := range Builtin() {
+		if tmpl.Name == name {
+			return tmpl, nil
+		}
+	}
+	return nil, fmt.Errorf("テンプレート %s が見つかりません（%s、組み込み: %s）", name, dir, strings.Join(builtinNames(), ", "))
+}
+
+// List はプロジェクトと組み込みのテンプレートを名前順に返す（解析できないファイルはエラーとして返す）
+func List(dir string) ([]*Template, map[string]error) {
+	byName := make(map[string]*Template)
+	for _, tmpl := range Builtin() {
+		byName[tmpl.Name] = tmpl
+	}
+
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		paths = append(paths, matches...)
+	}
+	invalid := make(map[string]error)
+	for _, path := range paths {
+		tmpl, err := Load(dir, path)
+		if err != nil {
+			invalid[path] = err
+			continue
+		}
+		byName[tmpl.Name] = tmpl
+	}
+
+	list := make([]*Template, 0, len(byName))
+	for _, tmpl := range byName {
+		list = append(list, tmpl)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, invalid
+}
+
+// WriteBuiltin は組み込みテンプレートを編集用にディレクトリへ書き出す（names が空なら全て、既存のファイルは force なしでは残す）
+// 書き出したファイルと、既にあって残したファイルを返す
+func WriteBuiltin(dir string, names []string, force bool) ([]string, []string, error) {
+	for _, name := range names {
+		if !contains(builtinNames(), name) {
+			return nil, nil, fmt.Errorf("組み込みテンプレート %s はありません（%s）", name, strings.Join(builtinNames(), ", "))
+		}
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, nil, fmt.Errorf("テンプレートディレクトリの作成エラー: %w", err)
+	}
+
+	var written, skipped []string
+	for _, name := range builtinNames() {
+		if len(names) > 0 && !contains(names, name) {
+			continue
+		}
+		path := filepath.Join(dir, name+".yaml")
+		if _, err := os.Stat(path); err == nil && !force {
+			skipped = append(skipped, path)
+			continue
+		}
+		data, err := builtinFS.ReadFile("builtin/" + name + ".yaml")
+		if err != nil {
+			return written, skipped, err
+		}
+		if err := os.WriteFile(path, data, 0644); err != nil {
+			return written, skipped, fmt.Errorf("テンプレートの書き込みエラー: %w", err)
+		}
+		written = append(written, path)
+	}
+	return written, skipped, nil
+}
+
+func builtinNames() []string {
+	var names []string
+	for _, tmpl := range Builtin() {
+		names = append(names, tmpl.Name)
+	}
+	return names
+}
+
+func isTaskKind(kind string) bool {
+	for _, valid := range tasks.ValidKinds() {
+		if string(valid) == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
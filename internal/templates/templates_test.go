@@ -0,0 +1,60 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestBuiltin は組み込みテンプレートが揃っていて、bugfix が最初にテストを実行することをテストする
+func TestBuiltin(t *testing.T) {
+	names := builtinNames()
+	if len(names) != 3 || names[0] != "bugfix" || names[1] != "feature" || names[2] != "spike" {
+		t.Fatalf("builtin = %v", names)
+	}
+	bugfix, err := Load(t.TempDir(), "bugfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bugfix.Path != "" || bugfix.SessionType != "debugging" || len(bugfix.Tasks()) != 1 || bugfix.Tasks()[0] != "test" {
+		t.Errorf("bugfix = %+v", bugfix)
+	}
+}
+
+// TestLoadProjectOverride は .vyb/templates の定義が組み込みより優先され、不正な定義はエラーになることをテストする
+func TestLoadProjectOverride(t *testing.T) {
+	dir := Dir(t.TempDir())
+	written, skipped, err := WriteBuiltin(dir, []string{"bugfix"}, false)
+	if err != nil || len(written) != 1 || len(skipped) != 0 {
+		t.Fatalf("written = %v, skipped = %v, err = %v", written, skipped, err)
+	}
+	if _, skipped, _ := WriteBuiltin(dir, nil, false); len(skipped) != 1 {
+		t.Errorf("既存のファイルは残すはず: %v", skipped)
+	}
+	if _, _, err := WriteBuiltin(dir, []string{"nope"}, false); err == nil {
+		t.Error("存在しない組み込みテンプレートはエラーのはず")
+	}
+
+	custom := "description: Fix it fast\nrun_first: [lint, test]\nplan: [reproduce, fix]\n"
+	if err := os.WriteFile(filepath.Join(dir, "bugfix.yaml"), []byte(custom), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := Load(dir, "bugfix")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tmpl.Name != "bugfix" || tmpl.Path == "" || tmpl.Description != "Fix it fast" || len(tmpl.RunFirst) != 2 {
+		t.Errorf("template = %+v", tmpl)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("run_first: [deploy]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	list, invalid := List(dir)
+	if len(list) != 3 || len(invalid) != 1 {
+		t.Errorf("list = %d, invalid = %v", len(list), invalid)
+	}
+	if _, err := Load(dir, "missing"); err == nil {
+		t.Error("見つからないテンプレートはエラーのはず")
+	}
+}
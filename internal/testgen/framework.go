@@ -0,0 +1,238 @@
+package testgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// テストフレームワーク名
+const (
+	FrameworkGo     = "go"
+	FrameworkPytest = "pytest"
+	FrameworkJest   = "jest"
+	FrameworkVitest = "vitest"
+)
+
+// 参考として添付する既存テストの最大サイズ
+const maxExampleBytes = 4 * 1024
+
+// Plan は1ファイル分のテスト生成計画
+type Plan struct {
+	Framework       string `json:"framework"`
+	Source          string `json:"source"`    // テスト対象（プロジェクト相対）
+	TestPath        string `json:"test_path"` // 生成するテストファイル（プロジェクト相対）
+	TestCommand     string `json:"test_command"`
+	CoverageCommand string `json:"coverage_command"`
+	Example         string `json:"-"` // 同じ場所の既存テスト（スタイルの参考）
+}
+
+// PlanTarget はファイルまたはパッケージ（ディレクトリ）からテスト生成計画を作る
+// ディレクトリ指定時はテストのないソースファイルを最大 maxFiles 件対象にする
+func PlanTarget(projectDir, target string, maxFiles int) ([]*Plan, error) {
+	rel, err := relativeTo(projectDir, target)
+	if err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(filepath.Join(projectDir, rel))
+	if err != nil {
+		return nil, fmt.Errorf("対象が見つかりません: %s", target)
+	}
+
+	if !info.IsDir() {
+		plan, err := planFile(projectDir, rel)
+		if err != nil {
+			return nil, err
+		}
+		return []*Plan{plan}, nil
+	}
+
+	entries, err := os.ReadDir(filepath.Join(projectDir, rel))
+	if err != nil {
+		return nil, fmt.Errorf("ディレクトリ読み込みエラー: %w", err)
+	}
+	var names []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+
+	var plans []*Plan
+	for _, name := range names {
+		path := filepath.Join(rel, name)
+		if detectFramework(projectDir, path) == "" || isTestFile(name) {
+			continue
+		}
+		plan, err := planFile(projectDir, path)
+		if err != nil {
+			continue
+		}
+		// 既にテストがあるファイルは対象外
+		if plan.TestPath != conventionalTestPath(projectDir, plan.Framework, path) {
+			continue
+		}
+		plans = append(plans, plan)
+		if maxFiles > 0 && len(plans) >= maxFiles {
+			break
+		}
+	}
+	if len(plans) == 0 {
+		return nil, fmt.Errorf("テストのないソースファイルがありません: %s", target)
+	}
+	return plans, nil
+}
+
+// planFile は1ファイルのフレームワーク・テストパス・実行コマンドを決める
+func planFile(projectDir, source string) (*Plan, error) {
+	framework := detectFramework(projectDir, source)
+	if framework == "" {
+		return nil, fmt.Errorf("テスト生成に対応していないファイルです: %s", source)
+	}
+	if isTestFile(filepath.Base(source)) {
+		return nil, fmt.Errorf("テストファイルは対象にできません: %s", source)
+	}
+
+	testPath := conventionalTestPath(projectDir, framework, source)
+	if _, err := os.Stat(filepath.Join(projectDir, testPath)); err == nil {
+		// 既存のテストは上書きせず別名で生成
+		testPath = generatedTestPath(framework, testPath)
+	}
+
+	plan := &Plan{Framework: framework, Source: source, TestPath: testPath}
+	dir := filepath.Dir(source)
+	testDir := filepath.Dir(testPath)
+	switch framework {
+	case FrameworkGo:
+		pkg := "./" + filepath.ToSlash(dir)
+		plan.TestCommand = "go test -count=1 " + pkg
+		plan.CoverageCommand = "go test -count=1 -cover " + pkg
+	case FrameworkPytest:
+		plan.TestCommand = "python -m pytest -q " + filepath.ToSlash(testPath)
+		plan.CoverageCommand = fmt.Sprintf("python -m pytest -q --cov=%s --cov-report=term %s", filepath.ToSlash(dir), filepath.ToSlash(testDir))
+	case FrameworkJest:
+		plan.TestCommand = "npx jest " + filepath.ToSlash(testPath)
+		plan.CoverageCommand = fmt.Sprintf("npx jest --coverage --collectCoverageFrom=%s %s", filepath.ToSlash(source), filepath.ToSlash(testDir))
+	case FrameworkVitest:
+		plan.TestCommand = "npx vitest run " + filepath.ToSlash(testPath)
+		plan.CoverageCommand = fmt.Sprintf("npx vitest run --coverage --coverage.include=%s %s", filepath.ToSlash(source), filepath.ToSlash(testDir))
+	}
+	plan.Example = findExample(projectDir, framework, testDir)
+	return plan, nil
+}
+
+// detectFramework は拡張子とプロジェクト設定からテストフレームワークを判定（未対応は空）
+func detectFramework(projectDir, source string) string {
+	switch filepath.Ext(source) {
+	case ".go":
+		return FrameworkGo
+	case ".py":
+		return FrameworkPytest
+	case ".js", ".jsx", ".ts", ".tsx", ".mjs":
+		if packageDependsOn(projectDir, "vitest") {
+			return FrameworkVitest
+		}
+		return FrameworkJest
+	}
+	return ""
+}
+
+// isTestFile はファイル名がテストファイルの命名規則に当たるか
+func isTestFile(name string) bool {
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	return strings.HasSuffix(name, "_test.go") ||
+		strings.HasPrefix(name, "test_") || strings.HasSuffix(base, "_test") ||
+		strings.HasSuffix(base, ".test") || strings.HasSuffix(base, ".spec") ||
+		name == "conftest.py"
+}
+
+// conventionalTestPath はフレームワークの慣習に沿ったテストファイルのパス
+func conventionalTestPath(projectDir, framework, source string) string {
+	dir := filepath.Dir(source)
+	ext := filepath.Ext(source)
+	base := strings.TrimSuffix(filepath.Base(source), ext)
+
+	switch framework {
+	case FrameworkGo:
+		return filepath.Join(dir, base+"_test.go")
+	case FrameworkPytest:
+		// tests/ がある場合はそこに置く
+		if info, err := os.Stat(filepath.Join(projectDir, "tests")); err == nil && info.IsDir() {
+			return filepath.Join("tests", "test_"+base+".py")
+		}
+		return filepath.Join(dir, "test_"+base+".py")
+	default:
+		return filepath.Join(dir, base+".test"+ext)
+	}
+}
+
+// generatedTestPath は既存テストと衝突しない生成用のパス
+func generatedTestPath(framework, testPath string) string {
+	switch framework {
+	case FrameworkGo:
+		return strings.TrimSuffix(testPath, "_test.go") + "_generated_test.go"
+	case FrameworkPytest:
+		return strings.TrimSuffix(testPath, ".py") + "_generated.py"
+	default:
+		ext := filepath.Ext(testPath)
+		return strings.TrimSuffix(testPath, ".test"+ext) + ".generated.test" + ext
+	}
+}
+
+// findExample は同じディレクトリの既存テストを1つ読み込む（スタイルの参考）
+func findExample(projectDir, framework, testDir string) string {
+	entries, err := os.ReadDir(filepath.Join(projectDir, testDir))
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !isTestFile(name) || detectFramework(projectDir, name) != framework || name == "conftest.py" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(projectDir, testDir, name))
+		if err != nil {
+			continue
+		}
+		if len(data) > maxExampleBytes {
+			data = data[:maxExampleBytes]
+		}
+		return fmt.Sprintf("// %s\n%s", filepath.ToSlash(filepath.Join(testDir, name)), data)
+	}
+	return ""
+}
+
+// packageDependsOn は package.json の依存に指定パッケージがあるか
+func packageDependsOn(projectDir, name string) bool {
+	data, err := os.ReadFile(filepath.Join(projectDir, "package.json"))
+	if err != nil {
+		return false
+	}
+	var pkg struct {
+		Dependencies    map[string]string `json:"dependencies"`
+		DevDependencies map[string]string `json:"devDependencies"`
+	}
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return false
+	}
+	_, dep := pkg.Dependencies[name]
+	_, dev := pkg.DevDependencies[name]
+	return dep || dev
+}
+
+// relativeTo は対象パスをプロジェクト相対にする（プロジェクト外は拒否）
+func relativeTo(projectDir, target string) (string, error) {
+	abs := target
+	if !filepath.IsAbs(abs) {
+		abs = filepath.Join(projectDir, target)
+	}
+	rel, err := filepath.Rel(projectDir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("プロジェクト外のパスです: %s", target)
+	}
+	return rel, nil
+}
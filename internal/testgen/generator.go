@@ -0,0 +1,252 @@
+package testgen
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// DefaultMaxIterations はテスト失敗時に修正を依頼する既定の回数
+const DefaultMaxIterations = 3
+
+// プロンプトに添付するソースの最大サイズ
+const maxSourceBytes = 48 * 1024
+
+var (
+	// codeBlockPattern は応答中の最初のコードブロック
+	codeBlockPattern = regexp.MustCompile("(?s)```[\\w.+-]*\\n(.*?)\\n?```")
+
+	// カバレッジ出力（go test -cover, pytest-cov, jest/vitest のテキストレポート）
+	coveragePatterns = []*regexp.Regexp{
+		regexp.MustCompile(`coverage: ([\d.]+)% of statements`),
+		regexp.MustCompile(`(?m)^TOTAL\s+\d+\s+\d+\s+(?:\d+\s+\d+\s+)?([\d.]+)%`),
+		regexp.MustCompile(`All files\s*\|\s*([\d.]+)`),
+	}
+)
+
+// Result は1ファイル分のテスト生成結果
+type Result struct {
+	Source         string        `json:"source"`
+	TestPath       string        `json:"test_path"`
+	Framework      string        `json:"framework"`
+	Passed         bool          `json:"passed"`
+	Kept           bool          `json:"kept"`       // テストファイルを残したか（失敗時は既定で削除）
+	Iterations     int           `json:"iterations"` // 失敗後に修正を依頼した回数
+	CoverageBefore float64       `json:"coverage_before"`
+	CoverageAfter  float64       `json:"coverage_after"` // 取得できなければ -1
+	Final          *tasks.Result `json:"final,omitempty"`
+}
+
+// CoverageDelta はカバレッジの増分（ポイント、取得できなければ false）
+func (r *Result) CoverageDelta() (float64, bool) {
+	if r.CoverageBefore < 0 || r.CoverageAfter < 0 {
+		return 0, false
+	}
+	return r.CoverageAfter - r.CoverageBefore, true
+}
+
+// Generator はLLMでテストを生成し、書き込み・実行・失敗時の修正を繰り返す
+type Generator struct {
+	Provider      llm.Provider
+	Model         string
+	Language      string
+	Memory        string // プロジェクトメモリ（VYB.md）
+	Registry      *prompts.Registry
+	Writer        *tools.WriteTool
+	Runner        *tasks.Runner
+	ProjectDir    string
+	MaxIterations int
+	KeepFailing   bool // 修正しきれなかったテストも残す
+
+	// Progress は各段階の開始時に呼ばれる（nil可）
+	Progress func(message string)
+}
+
+// Generate は計画に沿ってテストを生成し、通るまで（または上限まで）修正する
+func (g *Generator) Generate(ctx context.Context, plan *Plan) (*Result, error) {
+	result := &Result{Source: plan.Source, TestPath: plan.TestPath, Framework: plan.Framework}
+
+	source, err := os.ReadFile(filepath.Join(g.ProjectDir, plan.Source))
+	if err != nil {
+		return nil, fmt.Errorf("ソース読み込みエラー: %w", err)
+	}
+	if len(source) > maxSourceBytes {
+		source = source[:maxSourceBytes]
+	}
+
+	// 以前に生成したファイルを上書きする場合は失敗時に元へ戻す
+	previous, readErr := os.ReadFile(filepath.Join(g.ProjectDir, plan.TestPath))
+	discard := func() {
+		if readErr == nil {
+			os.WriteFile(filepath.Join(g.ProjectDir, plan.TestPath), previous, 0644)
+			return
+		}
+		os.Remove(filepath.Join(g.ProjectDir, plan.TestPath))
+	}
+
+	g.progress("Measuring coverage before generation (%s)", plan.CoverageCommand)
+	result.CoverageBefore = g.coverage(ctx, plan)
+
+	prompt, err := g.render(plan, string(source))
+	if err != nil {
+		return nil, err
+	}
+	messages := []llm.ChatMessage{{Role: "user", Content: prompt}}
+
+	for iteration := 0; ; iteration++ {
+		g.progress("Generating %s", plan.TestPath)
+		code, err := g.ask(ctx, messages)
+		if err != nil {
+			if iteration > 0 {
+				discard()
+			}
+			return nil, err
+		}
+		messages = append(messages, llm.ChatMessage{Role: "assistant", Content: "```\n" + code + "\n```"})
+
+		if _, err := g.Writer.Write(tools.WriteRequest{FilePath: plan.TestPath, Content: code}); err != nil {
+			discard()
+			return nil, err
+		}
+
+		g.progress("Running %s", plan.TestCommand)
+		run, err := g.Runner.RunCommand(ctx, tasks.KindTest, plan.TestCommand)
+		if err != nil {
+			discard()
+			return nil, err
+		}
+		result.Final = run
+		if run.Success {
+			result.Passed = true
+			break
+		}
+		if iteration >= g.maxIterations() {
+			break
+		}
+
+		result.Iterations++
+		messages = append(messages, llm.ChatMessage{Role: "user", Content: fixRequest(plan, run)})
+	}
+
+	if !result.Passed && !g.KeepFailing {
+		discard()
+		result.CoverageAfter = result.CoverageBefore
+		return result, nil
+	}
+	result.Kept = true
+
+	g.progress("Measuring coverage after generation")
+	result.CoverageAfter = g.coverage(ctx, plan)
+	return result, nil
+}
+
+// render はテスト生成プロンプトを組み立てる
+func (g *Generator) render(plan *Plan, source string) (string, error) {
+	registry := g.Registry
+	if registry == nil {
+		registry = prompts.DefaultRegistry()
+	}
+	return registry.Render(prompts.TemplateTestGen, prompts.Data{
+		SessionType: "testgen",
+		Language:    g.Language,
+		ModelFamily: prompts.ModelFamily(g.Model),
+		Memory:      g.Memory,
+		CurrentFile: filepath.ToSlash(plan.Source),
+		Context:     plan.Example,
+		Input:       source,
+		Framework:   plan.Framework,
+		TargetFile:  filepath.ToSlash(plan.TestPath),
+	})
+}
+
+// ask はモデルに問い合わせ、応答からテストファイルの内容を取り出す
+func (g *Generator) ask(ctx context.Context, messages []llm.ChatMessage) (string, error) {
+	temperature := 0.2
+	resp, err := g.Provider.Chat(ctx, llm.ChatRequest{
+		Model:       g.Model,
+		Messages:    messages,
+		Temperature: &temperature,
+	})
+	if err != nil {
+		return "", fmt.Errorf("テスト生成エラー: %w", err)
+	}
+	code := ExtractCode(resp.Message.Content)
+	if code == "" {
+		return "", fmt.Errorf("応答にテストコードがありません")
+	}
+	return code, nil
+}
+
+// coverage はカバレッジを測定する（測定できなければ -1）
+func (g *Generator) coverage(ctx context.Context, plan *Plan) float64 {
+	run, err := g.Runner.RunCommand(ctx, tasks.KindTest, plan.CoverageCommand)
+	if err != nil {
+		return -1
+	}
+	return ParseCoverage(run.Output)
+}
+
+func (g *Generator) maxIterations() int {
+	if g.MaxIterations < 0 {
+		return 0
+	}
+	if g.MaxIterations == 0 {
+		return DefaultMaxIterations
+	}
+	return g.MaxIterations
+}
+
+func (g *Generator) progress(format string, args ...interface{}) {
+	if g.Progress != nil {
+		g.Progress(fmt.Sprintf(format, args...))
+	}
+}
+
+// fixRequest はテスト失敗を伝えて修正した全文を求めるメッセージ
+func fixRequest(plan *Plan, run *tasks.Result) string {
+	var sb strings.Builder
+	sb.WriteString("The generated tests failed:\n\n")
+	sb.WriteString(tasks.FormatFailures(run))
+	fmt.Fprintf(&sb, "\nFix the tests in %s and reply with the complete corrected file in a single code block. ", filepath.ToSlash(plan.TestPath))
+	sb.WriteString("Do not change the code under test; if a test asserts behaviour the code does not have, correct or remove that test.")
+	return sb.String()
+}
+
+// ExtractCode は応答から最初のコードブロックを取り出す（ブロックがなければ全文）
+func ExtractCode(content string) string {
+	if match := codeBlockPattern.FindStringSubmatch(content); match != nil {
+		return strings.TrimSpace(match[1]) + "\n"
+	}
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return ""
+	}
+	return content + "\n"
+}
+
+// ParseCoverage はテストランナーの出力からカバレッジ（%）を読み取る（なければ -1）
+func ParseCoverage(output string) float64 {
+	for _, pattern := range coveragePatterns {
+		matches := pattern.FindAllStringSubmatch(output, -1)
+		if len(matches) == 0 {
+			continue
+		}
+		// 複数パッケージの場合は最後（対象パッケージ）の値
+		if value, err := strconv.ParseFloat(matches[len(matches)-1][1], 64); err == nil {
+			return value
+		}
+	}
+	if strings.Contains(output, "[no test files]") || strings.Contains(output, "no tests ran") || strings.Contains(output, "No tests found") {
+		return 0
+	}
+	return -1
+}
@@ -0,0 +1,197 @@
+package testgen
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/prompts"
+	"github.com/glkt/vyb-code/internal/security"
+	"github.com/glkt/vyb-code/internal/tasks"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestPlanTarget(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"calc/add.go":          "package calc\n",
+		"calc/sub.go":          "package calc\n",
+		"calc/sub_test.go":     "package calc\n",
+		"py/util.py":           "def f(): pass\n",
+		"tests/conftest.py":    "",
+		"web/button.ts":        "export {}\n",
+		"package.json":         `{"devDependencies": {"vitest": "^1.0.0"}}`,
+		"docs/readme.md":       "# docs\n",
+		"calc/testdata/x.json": "{}",
+	})
+
+	tests := []struct {
+		target    string
+		framework string
+		testPath  string
+		command   string
+	}{
+		{"calc/add.go", FrameworkGo, "calc/add_test.go", "go test -count=1 ./calc"},
+		{"calc/sub.go", FrameworkGo, "calc/sub_generated_test.go", "go test -count=1 ./calc"},
+		{"py/util.py", FrameworkPytest, "tests/test_util.py", "python -m pytest -q tests/test_util.py"},
+		{"web/button.ts", FrameworkVitest, "web/button.test.ts", "npx vitest run web/button.test.ts"},
+	}
+	for _, tt := range tests {
+		plans, err := PlanTarget(dir, tt.target, 0)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.target, err)
+		}
+		plan := plans[0]
+		if plan.Framework != tt.framework || filepath.ToSlash(plan.TestPath) != tt.testPath || plan.TestCommand != tt.command {
+			t.Errorf("%s: %+v", tt.target, plan)
+		}
+	}
+
+	// パッケージ指定時はテストのないファイルのみ
+	plans, err := PlanTarget(dir, "calc", 0)
+	if err != nil || len(plans) != 1 || plans[0].Source != filepath.Join("calc", "add.go") {
+		t.Errorf("パッケージ: %+v, %v", plans, err)
+	}
+	if plans[0].Example == "" || !strings.Contains(plans[0].Example, "sub_test.go") {
+		t.Errorf("既存テストをスタイルの参考にするはず: %q", plans[0].Example)
+	}
+
+	for _, target := range []string{"docs/readme.md", "calc/sub_test.go", "../outside.go"} {
+		if _, err := PlanTarget(dir, target, 0); err == nil {
+			t.Errorf("%s は対象外のはず", target)
+		}
+	}
+}
+
+func TestParseCoverage(t *testing.T) {
+	tests := []struct {
+		output string
+		want   float64
+	}{
+		{"ok  \texample.com/calc\t0.002s\tcoverage: 62.5% of statements\n", 62.5},
+		{"?   \texample.com/calc\t[no test files]\n", 0},
+		{"Name    Stmts   Miss  Cover\nutil.py     10      2    80%\nTOTAL       10      2    80%\n", 80},
+		{"All files  |   91.3 |    80 |  100 |  91.3 |\n", 91.3},
+		{"pytest: error: unrecognized arguments: --cov\n", -1},
+	}
+	for _, tt := range tests {
+		if got := ParseCoverage(tt.output); got != tt.want {
+			t.Errorf("ParseCoverage(%q) = %v, 期待値: %v", tt.output, got, tt.want)
+		}
+	}
+}
+
+func TestExtractCode(t *testing.T) {
+	if got := ExtractCode("Here:\n```go\npackage calc\n```\nDone"); got != "package calc\n" {
+		t.Errorf("コードブロック: %q", got)
+	}
+	if got := ExtractCode("package calc"); got != "package calc\n" {
+		t.Errorf("ブロックなし: %q", got)
+	}
+}
+
+// sequenceProvider は回答を順に返すテスト用プロバイダー
+type sequenceProvider struct {
+	answers  []string
+	requests []llm.ChatRequest
+}
+
+func (p *sequenceProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.requests = append(p.requests, req)
+	answer := p.answers[0]
+	if len(p.answers) > 1 {
+		p.answers = p.answers[1:]
+	}
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: answer}, Done: true}, nil
+}
+
+func (p *sequenceProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *sequenceProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *sequenceProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+const (
+	failingTest = "```go\npackage calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\tif Add(1, 2) != 4 {\n\t\tt.Fatal(\"wrong\")\n\t}\n}\n```"
+	passingTest = "```go\npackage calc\n\nimport \"testing\"\n\nfunc TestAdd(t *testing.T) {\n\ttests := []struct{ a, b, want int }{{1, 2, 3}, {0, 0, 0}}\n\tfor _, tt := range tests {\n\t\tif got := Add(tt.a, tt.b); got != tt.want {\n\t\t\tt.Errorf(\"Add(%d, %d) = %d\", tt.a, tt.b, got)\n\t\t}\n\t}\n}\n```"
+)
+
+func newTestGenerator(t *testing.T, provider llm.Provider) (*Generator, string) {
+	t.Helper()
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"go.mod":      "module example.com/calc\n\ngo 1.20\n",
+		"calc/add.go": "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n",
+	})
+	return &Generator{
+		Provider:   provider,
+		Model:      "qwen2.5-coder:14b",
+		Language:   "en",
+		Registry:   prompts.NewRegistry(""),
+		Writer:     tools.NewWriteTool(security.NewDefaultConstraints(dir), dir, 1024*1024),
+		Runner:     tasks.NewRunnerWithSystem(dir, nil, &tasks.BuildSystem{Name: FrameworkGo}),
+		ProjectDir: dir,
+	}, dir
+}
+
+func TestGeneratorFixesFailingTests(t *testing.T) {
+	provider := &sequenceProvider{answers: []string{failingTest, passingTest}}
+	generator, dir := newTestGenerator(t, provider)
+
+	plans, err := PlanTarget(dir, "calc/add.go", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := generator.Generate(context.Background(), plans[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Passed || result.Iterations != 1 || !result.Kept {
+		t.Fatalf("1回の修正で通るはず: %+v", result)
+	}
+	if delta, ok := result.CoverageDelta(); !ok || result.CoverageBefore != 0 || delta != 50 {
+		t.Errorf("カバレッジ: %.1f → %.1f", result.CoverageBefore, result.CoverageAfter)
+	}
+
+	// 修正依頼には失敗内容が含まれる
+	fix := provider.requests[1].Messages[2].Content
+	if !strings.Contains(fix, "TestAdd") || !strings.Contains(fix, "calc/add_test.go") {
+		t.Errorf("修正依頼: %s", fix)
+	}
+	if !strings.Contains(provider.requests[0].Messages[0].Content, "table-driven") {
+		t.Error("Goの規約がプロンプトに含まれていない")
+	}
+}
+
+func TestGeneratorRemovesFailingTests(t *testing.T) {
+	generator, dir := newTestGenerator(t, &sequenceProvider{answers: []string{failingTest}})
+	generator.MaxIterations = 1
+
+	plans, _ := PlanTarget(dir, "calc/add.go", 0)
+	result, err := generator.Generate(context.Background(), plans[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Passed || result.Kept || result.Iterations != 1 || result.Final == nil {
+		t.Errorf("上限で停止するはず: %+v", result)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "calc", "add_test.go")); !os.IsNotExist(err) {
+		t.Error("通らなかったテストは削除されるはず")
+	}
+}
@@ -0,0 +1,24 @@
+// Package textutil はモデルの応答やコマンド出力を表示・要約する時の文字列処理
+package textutil
+
+import "strings"
+
+// FirstLine は最初の空でない行を前後の空白を除いて返す（なければ空文字列）
+func FirstLine(text string) string {
+	for _, line := range strings.Split(text, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			return line
+		}
+	}
+	return ""
+}
+
+// FirstLineMore は FirstLine に、その後にも空でない行が続く場合は more（" …" 等）を付けて返す
+func FirstLineMore(text, more string) string {
+	line := FirstLine(text)
+	rest := strings.TrimSpace(text)
+	if i := strings.IndexByte(rest, '\n'); i >= 0 && strings.TrimSpace(rest[i:]) != "" {
+		return line + more
+	}
+	return line
+}
@@ -0,0 +1,32 @@
+package textutil
+
+import "testing"
+
+func TestFirstLine(t *testing.T) {
+	tests := map[string]string{
+		"":                       "",
+		"single":                 "single",
+		"\n\n  go1.22.1  \nnext": "go1.22.1",
+		"first\nsecond":          "first",
+		" \n\t\n":                "",
+	}
+	for input, want := range tests {
+		if got := FirstLine(input); got != want {
+			t.Errorf("FirstLine(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestFirstLineMore(t *testing.T) {
+	tests := map[string]string{
+		"func A()":                "func A()",
+		"func A()\n":              "func A()",
+		"\nfunc A(\n\tb int,\n)":  "func A( …",
+		"  request\n\n  details ": "request …",
+	}
+	for input, want := range tests {
+		if got := FirstLineMore(input, " …"); got != want {
+			t.Errorf("FirstLineMore(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
@@ -232,6 +232,12 @@ func (a *AdvancedProjectAnalyzer) AnalyzeAdvanced() (*AdvancedProjectAnalysis, e
 	return analysis, nil
 }
 
+// DetectBuildSystems はビルドシステム（Makefile, Docker, CI設定, 言語標準のツール）のみを検出する
+func (a *AdvancedProjectAnalyzer) DetectBuildSystems() []BuildSystemInfo {
+	buildSystems, _ := a.analyzeBuildSystems()
+	return buildSystems
+}
+
 // ビルドシステムを解析
 func (a *AdvancedProjectAnalyzer) analyzeBuildSystems() ([]BuildSystemInfo, error) {
 	var buildSystems []BuildSystemInfo
@@ -286,13 +292,16 @@ func (a *AdvancedProjectAnalyzer) analyzeMakefile(path string) (BuildSystemInfo,
 
 	lines := strings.Split(string(content), "\n")
 	for _, line := range lines {
+		// ターゲット検出（行の開始がタブ・空白でなく、コロンを含む）
+		// .PHONY 等の特殊ターゲットとレシピの続きの行は除く
+		if line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ") {
+			continue
+		}
 		line = strings.TrimSpace(line)
-
-		// ターゲット検出（行の開始がタブでなく、コロンを含む）
-		if !strings.HasPrefix(line, "\t") && strings.Contains(line, ":") && !strings.HasPrefix(line, "#") {
+		if strings.Contains(line, ":") && !strings.HasPrefix(line, "#") {
 			target := strings.Split(line, ":")[0]
 			target = strings.TrimSpace(target)
-			if target != "" && !strings.Contains(target, "=") {
+			if target != "" && !strings.HasPrefix(target, ".") && !strings.ContainsAny(target, "= \t\"'$@") {
 				buildInfo.Targets = append(buildInfo.Targets, target)
 			}
 		}
@@ -0,0 +1,806 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// unified diff のパッチ適用（モデルが出力した差分を行番号・空白のずれを許して当てる）
+
+// maxPatchFuzz はハンクの前後から無視してよい文脈行の最大数（GNU patch の fuzz 相当）
+const maxPatchFuzz = 2
+
+// hunkHeaderPattern はハンクの見出し（@@ -1,3 +1,4 @@）
+var hunkHeaderPattern = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// 行の比較の緩さ（数値が大きいほど緩い）
+const (
+	matchExact         = iota // 完全一致
+	matchTrailingSpace        // 行末の空白を無視
+	matchWhitespace           // 空白の量・種類を無視
+	matchLevels               // 比較の段階の数
+)
+
+// patchLine はハンクの1行（op は ' ', '-', '+'）
+type patchLine struct {
+	op   byte
+	text string
+}
+
+// patchHunk はハンク1つ（oldStart は変更前の開始行、見出しに行番号がなければ0）
+type patchHunk struct {
+	header       string
+	oldStart     int
+	lines        []patchLine
+	oldNoNewline bool // 変更前の最終行に改行がない
+	newNoNewline bool // 変更後の最終行に改行がない
+}
+
+// patchFile はファイル1つ分のパッチ
+type patchFile struct {
+	oldPath  string
+	newPath  string
+	isNew    bool
+	isDelete bool
+	hunks    []*patchHunk
+}
+
+// path は変更後のパス（削除なら変更前のパス）
+func (f *patchFile) path() string {
+	if f.newPath != "" {
+		return f.newPath
+	}
+	return f.oldPath
+}
+
+// parsePatch は unified diff をファイル毎のハンクにする
+// モデルの出力を想定し、diff --git 行のないパッチ・行数の合わない見出し・行番号のない見出し（@@ @@）も受け付ける
+func parsePatch(patch string) ([]*patchFile, error) {
+	var files []*patchFile
+	var current *patchFile
+	var hunk *patchHunk
+	// 空行は空の文脈行として扱うが、ハンク末尾の空行は区切りの可能性があるので数えておく
+	bareBlanks := 0
+
+	finishHunk := func() {
+		if hunk != nil {
+			hunk.lines = hunk.lines[:len(hunk.lines)-bareBlanks]
+			if len(hunk.lines) > 0 {
+				current.hunks = append(current.hunks, hunk)
+			}
+		}
+		hunk = nil
+		bareBlanks = 0
+	}
+	startFile := func() {
+		finishHunk()
+		current = &patchFile{}
+		files = append(files, current)
+	}
+
+	lines := strings.Split(strings.ReplaceAll(patch, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		text := lines[i]
+		nextIsNewPath := i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ ")
+		switch {
+		case strings.HasPrefix(text, "diff --git "):
+			startFile()
+			if a, b, ok := strings.Cut(strings.TrimPrefix(text, "diff --git "), " "); ok {
+				current.oldPath, current.newPath = patchPath(a), patchPath(b)
+			}
+		case strings.HasPrefix(text, "--- ") && nextIsNewPath:
+			// diff --git 行の直後でなければ新しいファイルの始まり
+			if current == nil || hunk != nil || len(current.hunks) > 0 {
+				startFile()
+			}
+			finishHunk()
+			current.oldPath = patchPath(strings.TrimPrefix(text, "--- "))
+			current.isNew = current.oldPath == ""
+			i++
+			current.newPath = patchPath(strings.TrimPrefix(lines[i], "+++ "))
+			current.isDelete = current.newPath == ""
+		case strings.HasPrefix(text, "@@"):
+			if current == nil {
+				return nil, fmt.Errorf("ハンクの前にファイルの見出し（--- と +++）がありません: %s", text)
+			}
+			finishHunk()
+			hunk = &patchHunk{header: text}
+			if match := hunkHeaderPattern.FindStringSubmatch(text); match != nil {
+				hunk.oldStart, _ = strconv.Atoi(match[1])
+			}
+		case hunk == nil:
+			// ファイルの見出しの前後にある index・mode 等の行
+			switch {
+			case current != nil && strings.HasPrefix(text, "new file mode"):
+				current.isNew = true
+			case current != nil && strings.HasPrefix(text, "deleted file mode"):
+				current.isDelete = true
+			}
+		case strings.HasPrefix(text, `\`):
+			// "\ No newline at end of file" は直前の行に掛かる
+			if len(hunk.lines) > 0 {
+				switch hunk.lines[len(hunk.lines)-1].op {
+				case '-':
+					hunk.oldNoNewline = true
+				case '+':
+					hunk.newNoNewline = true
+				default:
+					hunk.oldNoNewline, hunk.newNoNewline = true, true
+				}
+			}
+		case text == "":
+			hunk.lines = append(hunk.lines, patchLine{op: ' '})
+			bareBlanks++
+		case text[0] == ' ' || text[0] == '-' || text[0] == '+':
+			hunk.lines = append(hunk.lines, patchLine{op: text[0], text: text[1:]})
+			bareBlanks = 0
+		default:
+			// ハンクの外の行（説明文など）でハンクを閉じる
+			finishHunk()
+		}
+	}
+	finishHunk()
+
+	var result []*patchFile
+	for _, file := range files {
+		if file.path() == "" {
+			return nil, fmt.Errorf("パッチにファイルのパスがありません")
+		}
+		if len(file.hunks) == 0 && !file.isDelete {
+			continue
+		}
+		result = append(result, file)
+	}
+	if len(result) == 0 {
+		return nil, fmt.Errorf("適用できるハンクがありません（unified diff 形式で --- / +++ / @@ を含めてください）")
+	}
+	return result, nil
+}
+
+// patchPath は --- / +++ 行のパスを取り出す（a/ b/ の接頭辞・タイムスタンプを除き、/dev/null は空）
+func patchPath(text string) string {
+	text = strings.TrimSpace(text)
+	if tab := strings.IndexByte(text, '\t'); tab >= 0 {
+		text = text[:tab]
+	}
+	text = strings.Trim(text, `"`)
+	if text == "/dev/null" {
+		return ""
+	}
+	for _, prefix := range []string{"a/", "b/"} {
+		if strings.HasPrefix(text, prefix) {
+			return text[len(prefix):]
+		}
+	}
+	return text
+}
+
+// hunkMatch はハンクを当てた位置（変更前の行番号、0始まり）と、一致させるために緩めた度合い
+type hunkMatch struct {
+	pos   int
+	level int // matchExact, matchTrailingSpace, matchWhitespace
+	fuzz  int // 無視した前後の文脈行の数
+	lines []patchLine
+}
+
+// applyHunks はファイルの内容にハンクを順に当てる
+// 見出しの行番号を手掛かりに、ずれた位置・空白の違い・前後の文脈行の違いを許して探し、候補が絞れなければエラーにする
+func applyHunks(content string, hunks []*patchHunk) (string, []hunkMatch, error) {
+	lines := strings.Split(content, "\n")
+	trailingNewline := strings.HasSuffix(content, "\n")
+	if trailingNewline || content == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	matches := make([]hunkMatch, 0, len(hunks))
+	next := 0 // ハンクは重ならず順に並ぶので、前のハンクより後ろを探す
+	offset := 0
+	for i, hunk := range hunks {
+		match, err := locateHunk(lines, hunk, next, offset)
+		if err != nil {
+			return "", nil, fmt.Errorf("ハンク %d（%s）: %w", i+1, hunk.header, err)
+		}
+		if hunk.oldStart > 0 {
+			offset = match.pos - (hunk.oldStart - 1)
+		}
+		matches = append(matches, match)
+		next = match.pos + countOld(match.lines)
+	}
+
+	var out []string
+	cursor := 0
+	for _, match := range matches {
+		out = append(out, lines[cursor:match.pos]...)
+		out = append(out, replaceHunk(lines[match.pos:], match)...)
+		cursor = match.pos + countOld(match.lines)
+	}
+	out = append(out, lines[cursor:]...)
+
+	// ファイル末尾に掛かるハンクの "\ No newline at end of file" に従う
+	if len(matches) > 0 {
+		last, lastHunk := matches[len(matches)-1], hunks[len(hunks)-1]
+		if last.pos+countOld(last.lines) == len(lines) && last.fuzz == 0 {
+			switch {
+			case lastHunk.newNoNewline:
+				trailingNewline = false
+			case lastHunk.oldNoNewline || content == "":
+				trailingNewline = true
+			}
+		}
+	}
+	if len(out) == 0 {
+		return "", matches, nil
+	}
+	result := strings.Join(out, "\n")
+	if trailingNewline {
+		result += "\n"
+	}
+	return result, matches, nil
+}
+
+// locateHunk はハンクの変更前の行がファイルのどこにあるか探す（from より前は探さない）
+func locateHunk(lines []string, hunk *patchHunk, from, offset int) (hunkMatch, error) {
+	expected := -1
+	if hunk.oldStart > 0 {
+		expected = hunk.oldStart - 1 + offset
+	}
+
+	for fuzz := 0; fuzz <= maxPatchFuzz; fuzz++ {
+		trimmed, skipped, ok := trimContext(hunk.lines, fuzz)
+		if !ok {
+			break
+		}
+		old := linesOf(trimmed, '+')
+		if len(old) == 0 {
+			// 文脈行を除いて手掛かりがなくなったハンクは当てない
+			if fuzz > 0 {
+				break
+			}
+			// 追加だけのハンクは見出しの位置（-N,0 は N 行目の後ろ）に挿入し、行番号がなければ末尾に足す
+			pos := len(lines)
+			if expected >= 0 {
+				pos = hunk.oldStart + offset
+			}
+			if pos < from {
+				pos = from
+			}
+			if pos > len(lines) {
+				pos = len(lines)
+			}
+			return hunkMatch{pos: pos, lines: trimmed}, nil
+		}
+		// 文脈行を除いた分だけ期待する位置もずれる
+		shifted := expected
+		if shifted >= 0 {
+			shifted += skipped
+		}
+		for level := matchExact; level < matchLevels; level++ {
+			pos, err := findLines(lines, old, from, shifted, level)
+			if err != nil {
+				return hunkMatch{}, err
+			}
+			if pos >= 0 {
+				return hunkMatch{pos: pos, level: level, fuzz: fuzz, lines: trimmed}, nil
+			}
+		}
+	}
+
+	first := ""
+	if old := linesOf(hunk.lines, '+'); len(old) > 0 {
+		first = strings.TrimSpace(old[0])
+	}
+	return hunkMatch{}, fmt.Errorf("変更前の行がファイルの内容と一致しません（最初の行: %q）。ファイルを読み直してからパッチを作り直してください", first)
+}
+
+// trimContext はハンクの前後から fuzz 行ずつ文脈行を除く（除けるのは文脈行だけ、先頭から除いた行数も返す）
+func trimContext(lines []patchLine, fuzz int) ([]patchLine, int, bool) {
+	start, end := 0, len(lines)
+	for i := 0; i < fuzz; i++ {
+		trimmed := false
+		if start < end && lines[start].op == ' ' {
+			start++
+			trimmed = true
+		}
+		if end > start && lines[end-1].op == ' ' {
+			end--
+			trimmed = true
+		}
+		if !trimmed {
+			return nil, 0, false
+		}
+	}
+	return lines[start:end], start, true
+}
+
+// findLines は old と一致する位置を探す（期待する位置に最も近い候補、候補が複数で決められなければエラー、なければ -1）
+func findLines(lines, old []string, from, expected, level int) (int, error) {
+	var candidates []int
+	for pos := from; pos+len(old) <= len(lines); pos++ {
+		if linesEqual(lines[pos:pos+len(old)], old, level) {
+			candidates = append(candidates, pos)
+		}
+	}
+	switch {
+	case len(candidates) == 0:
+		return -1, nil
+	case len(candidates) == 1:
+		return candidates[0], nil
+	case expected < 0:
+		return -1, fmt.Errorf("変更前の行がファイル内に %d 箇所あり、当てる位置を決められません（見出しに行番号を付けるか、文脈行を増やしてください）", len(candidates))
+	}
+
+	best, tie := -1, false
+	for _, pos := range candidates {
+		switch distance := absInt(pos - expected); {
+		case best < 0 || distance < absInt(best-expected):
+			best, tie = pos, false
+		case distance == absInt(best-expected):
+			tie = true
+		}
+	}
+	if tie {
+		return -1, fmt.Errorf("変更前の行が見出しの行番号から同じ距離に複数あり、当てる位置を決められません")
+	}
+	return best, nil
+}
+
+// linesEqual は行の並びが比較の段階 level で一致するか
+func linesEqual(a, b []string, level int) bool {
+	for i := range a {
+		if normalizeLine(a[i], level) != normalizeLine(b[i], level) {
+			return false
+		}
+	}
+	return true
+}
+
+// normalizeLine は比較の段階に応じて行の空白を整える
+func normalizeLine(line string, level int) string {
+	switch level {
+	case matchTrailingSpace:
+		return strings.TrimRight(line, " \t\r")
+	case matchWhitespace:
+		return strings.Join(strings.Fields(line), " ")
+	default:
+		return line
+	}
+}
+
+// replaceHunk はハンクを当てた後の行（文脈行はファイルの行をそのまま残し、追加行の字下げはファイルに合わせる）
+func replaceHunk(lines []string, match hunkMatch) []string {
+	indents := map[string]string{}
+	if match.level == matchWhitespace {
+		indents = indentMapping(lines, match.lines)
+	}
+
+	var out []string
+	i := 0
+	for _, line := range match.lines {
+		switch line.op {
+		case ' ':
+			out = append(out, lines[i])
+			i++
+		case '-':
+			i++
+		case '+':
+			text := line.text
+			indent := leadingWhitespace(text)
+			if replacement, ok := indents[indent]; ok {
+				text = replacement + text[len(indent):]
+			}
+			out = append(out, text)
+		}
+	}
+	return out
+}
+
+// indentMapping はパッチの字下げ → ファイルの字下げの対応（タブと空白の違い等、対応が一意な字下げだけ）
+func indentMapping(lines []string, hunk []patchLine) map[string]string {
+	mapping := map[string]string{}
+	conflicting := map[string]bool{}
+	i := 0
+	for _, line := range hunk {
+		if line.op == '+' {
+			continue
+		}
+		if strings.TrimSpace(line.text) != "" {
+			from, to := leadingWhitespace(line.text), leadingWhitespace(lines[i])
+			if existing, ok := mapping[from]; ok && existing != to {
+				conflicting[from] = true
+			}
+			mapping[from] = to
+		}
+		i++
+	}
+	for from := range conflicting {
+		delete(mapping, from)
+	}
+	return mapping
+}
+
+// leadingWhitespace は行頭の空白
+func leadingWhitespace(line string) string {
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// linesOf は op が skip の行を除いた行（'+' を除けば変更前、'-' を除けば変更後の行）
+func linesOf(lines []patchLine, skip byte) []string {
+	result := make([]string, 0, len(lines))
+	for _, line := range lines {
+		if line.op != skip {
+			result = append(result, line.text)
+		}
+	}
+	return result
+}
+
+// countOld はハンクの変更前の行数
+func countOld(lines []patchLine) int {
+	return len(linesOf(lines, '+'))
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+// ApplyPatchTool - unified diff 形式のパッチを適用するツール
+// 完全一致の置換（EditTool）と違い、行番号のずれ・空白の違いを許してハンクを当て、全てのファイルを検証してから書き込む
+type ApplyPatchTool struct {
+	constraints *security.Constraints
+	workDir     string
+	maxFileSize int64
+}
+
+func NewApplyPatchTool(constraints *security.Constraints, workDir string, maxFileSize int64) *ApplyPatchTool {
+	return &ApplyPatchTool{
+		constraints: constraints,
+		workDir:     workDir,
+		maxFileSize: maxFileSize,
+	}
+}
+
+// SetPermissions はプロジェクト・ユーザーの許可規則を設定（nilで既定の制約のみ）
+func (p *ApplyPatchTool) SetPermissions(permissions *security.Permissions) {
+	p.constraints.Permissions = permissions
+}
+
+type ApplyPatchRequest struct {
+	Patch  string `json:"patch"`
+	DryRun bool   `json:"dry_run,omitempty"` // 検証だけ行い書き込まない
+}
+
+// PatchFileResult - パッチを当てたファイル1つの結果
+type PatchFileResult struct {
+	Path    string `json:"path"`
+	OldPath string `json:"old_path,omitempty"` // リネーム元
+	Action  string `json:"action"`             // create, modify, delete, rename
+	Hunks   int    `json:"hunks"`
+	Fuzzy   int    `json:"fuzzy"` // 行番号以外のずれ（空白・文脈行）を許して当てたハンクの数
+	Added   int    `json:"added"`
+	Deleted int    `json:"deleted"`
+}
+
+// plannedPatch は書き込む前のファイル1つ分の変更
+type plannedPatch struct {
+	result   PatchFileResult
+	absPath  string
+	oldAbs   string // リネーム・削除で消すパス
+	content  string
+	original []byte // 書き込みに失敗した時に戻す内容（新規作成なら nil）
+}
+
+// Apply はパッチを検証し、全てのハンクが当たる場合だけまとめて書き込む（途中で失敗したら書き込んだファイルを戻す）
+func (p *ApplyPatchTool) Apply(req ApplyPatchRequest) (*ToolExecutionResult, error) {
+	fail := func(err error) (*ToolExecutionResult, error) {
+		return &ToolExecutionResult{
+			Content: fmt.Sprintf("パッチを適用できません（ファイルは変更していません）: %v", err),
+			IsError: true,
+			Tool:    "apply_patch",
+		}, err
+	}
+
+	files, err := parsePatch(req.Patch)
+	if err != nil {
+		return fail(err)
+	}
+	plans, err := p.plan(files)
+	if err != nil {
+		return fail(err)
+	}
+
+	if !req.DryRun {
+		if err := commitPatch(plans); err != nil {
+			return &ToolExecutionResult{
+				Content: fmt.Sprintf("パッチの書き込みに失敗したため元に戻しました: %v", err),
+				IsError: true,
+				Tool:    "apply_patch",
+			}, err
+		}
+	}
+
+	results := make([]PatchFileResult, 0, len(plans))
+	var summary strings.Builder
+	verb := "適用しました"
+	if req.DryRun {
+		verb = "適用できます（dry run）"
+	}
+	summary.WriteString(fmt.Sprintf("パッチを%s: %d ファイル\n", verb, len(plans)))
+	hunks, fuzzy := 0, 0
+	for _, plan := range plans {
+		r := plan.result
+		results = append(results, r)
+		hunks += r.Hunks
+		fuzzy += r.Fuzzy
+		line := fmt.Sprintf("  %s %s (+%d -%d, %d ハンク", r.Action, r.Path, r.Added, r.Deleted, r.Hunks)
+		if r.Fuzzy > 0 {
+			line += fmt.Sprintf("、うち %d 個は空白・文脈のずれを許して適用", r.Fuzzy)
+		}
+		summary.WriteString(line + ")\n")
+	}
+
+	return &ToolExecutionResult{
+		Content: strings.TrimRight(summary.String(), "\n"),
+		IsError: false,
+		Tool:    "apply_patch",
+		Metadata: map[string]interface{}{
+			"files":   results,
+			"hunks":   hunks,
+			"fuzzy":   fuzzy,
+			"dry_run": req.DryRun,
+		},
+	}, nil
+}
+
+// plan は全てのファイルにハンクを当てた結果をメモリ上で作る（1つでも当たらなければエラー）
+func (p *ApplyPatchTool) plan(files []*patchFile) ([]*plannedPatch, error) {
+	var plans []*plannedPatch
+	byPath := make(map[string]*plannedPatch) // 同じファイルへの複数のパッチは前の結果に続けて当てる
+
+	for _, file := range files {
+		action := "modify"
+		switch {
+		case file.isNew:
+			action = "create"
+		case file.isDelete:
+			action = "delete"
+		case file.oldPath != "" && file.newPath != "" && file.oldPath != file.newPath:
+			action = "rename"
+		}
+		operation := "write"
+		if action == "create" || action == "delete" {
+			operation = action
+		}
+
+		absPath, err := resolveToolPath(p.constraints, p.workDir, file.path(), operation)
+		if err != nil {
+			return nil, fmt.Errorf("%s: パスへのアクセスが許可されていません: %w", file.path(), err)
+		}
+		sourceAbs := absPath
+		if action == "rename" {
+			if sourceAbs, err = resolveToolPath(p.constraints, p.workDir, file.oldPath, "delete"); err != nil {
+				return nil, fmt.Errorf("%s: パスへのアクセスが許可されていません: %w", file.oldPath, err)
+			}
+		}
+
+		// 作成・リネーム先の既存のファイルは上書きしない（書き込みに失敗した時に元に戻せなくなる）
+		switch action {
+		case "create":
+			if _, err := os.Stat(absPath); err == nil || byPath[absPath] != nil {
+				return nil, fmt.Errorf("%s: 新規作成のパッチですが、ファイルが既に存在します", file.path())
+			}
+		case "rename":
+			if byPath[absPath] != nil || destinationExists(sourceAbs, absPath) {
+				return nil, fmt.Errorf("%s: リネーム先のファイルが既に存在します（%s から）", file.path(), file.oldPath)
+			}
+		}
+
+		plan := byPath[sourceAbs]
+		var original string
+		switch {
+		case plan != nil:
+			original = plan.content
+		case action == "create":
+		default:
+			data, err := os.ReadFile(sourceAbs)
+			if err != nil {
+				return nil, fmt.Errorf("%s: ファイルを読み込めません: %w", file.path(), err)
+			}
+			if int64(len(data)) > p.maxFileSize {
+				return nil, fmt.Errorf("%s: ファイルサイズが制限を超えています: %d bytes", file.path(), len(data))
+			}
+			original = string(data)
+		}
+
+		content, matches, err := applyHunks(original, file.hunks)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file.path(), err)
+		}
+		if action == "delete" && content != "" {
+			return nil, fmt.Errorf("%s: 削除のパッチがファイルの内容全体と一致しません", file.path())
+		}
+		if int64(len(content)) > p.maxFileSize {
+			return nil, fmt.Errorf("%s: 適用後のファイルサイズが制限を超えています: %d bytes", file.path(), len(content))
+		}
+
+		if plan == nil {
+			plan = &plannedPatch{absPath: absPath, result: PatchFileResult{Path: file.path(), Action: action}}
+			if action != "create" {
+				plan.original = []byte(original)
+			}
+			plans = append(plans, plan)
+		}
+		if action == "rename" || action == "delete" {
+			plan.oldAbs = sourceAbs
+			if action == "rename" {
+				plan.result.OldPath = file.oldPath
+			}
+			plan.result.Action = action
+			delete(byPath, sourceAbs)
+		}
+		plan.absPath = absPath
+		plan.result.Path = file.path()
+		plan.content = content
+		byPath[absPath] = plan
+
+		for _, match := range matches {
+			plan.result.Hunks++
+			if match.level != matchExact || match.fuzz > 0 {
+				plan.result.Fuzzy++
+			}
+			for _, line := range match.lines {
+				switch line.op {
+				case '+':
+					plan.result.Added++
+				case '-':
+					plan.result.Deleted++
+				}
+			}
+		}
+	}
+	return plans, nil
+}
+
+// destinationExists はリネーム先に別のファイルがあるか（大文字・小文字だけのリネームで同じファイルを指す場合は除く）
+func destinationExists(sourceAbs, destAbs string) bool {
+	destInfo, err := os.Stat(destAbs)
+	if err != nil {
+		return false
+	}
+	sourceInfo, err := os.Stat(sourceAbs)
+	return err != nil || !os.SameFile(sourceInfo, destInfo)
+}
+
+// commitPatch は計画した変更を書き込む（失敗したらそれまでの変更を元に戻す）
+func commitPatch(plans []*plannedPatch) error {
+	for i, plan := range plans {
+		if err := writePlannedPatch(plan); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				revertPlannedPatch(plans[j])
+			}
+			return fmt.Errorf("%s: %w", plan.result.Path, err)
+		}
+	}
+	return nil
+}
+
+// writePlannedPatch はファイル1つ分の変更を書き込む（一時ファイル経由で置き換える）
+func writePlannedPatch(plan *plannedPatch) error {
+	if plan.result.Action == "delete" {
+		return os.Remove(plan.oldAbs)
+	}
+	// リネームは先に os.Rename で名前を変える（大文字・小文字だけのリネームでは新旧のパスが
+	// 同じファイルを指すため、新しいパスに書いてから古いパスを削除すると書いた内容ごと消える）
+	if plan.result.Action == "rename" {
+		if err := os.MkdirAll(filepath.Dir(plan.absPath), 0755); err != nil {
+			return err
+		}
+		if err := os.Rename(plan.oldAbs, plan.absPath); err != nil {
+			return err
+		}
+	}
+	if _, err := writeFileStreaming(plan.absPath, strings.NewReader(plan.content), false); err != nil {
+		if plan.result.Action == "rename" {
+			os.Rename(plan.absPath, plan.oldAbs)
+		}
+		return err
+	}
+	return nil
+}
+
+// revertPlannedPatch は書き込んだ変更を元に戻す
+func revertPlannedPatch(plan *plannedPatch) {
+	switch plan.result.Action {
+	case "create":
+		os.Remove(plan.absPath)
+	case "rename":
+		writeFileStreaming(plan.absPath, strings.NewReader(string(plan.original)), false)
+		os.Rename(plan.absPath, plan.oldAbs)
+	case "delete":
+		writeFileStreaming(plan.oldAbs, strings.NewReader(string(plan.original)), false)
+	default:
+		writeFileStreaming(plan.absPath, strings.NewReader(string(plan.original)), false)
+	}
+}
+
+// UnifiedApplyPatchTool - unified diff 形式のパッチを適用する統一ツール
+type UnifiedApplyPatchTool struct {
+	*BaseTool
+}
+
+// NewUnifiedApplyPatchTool - 新しいパッチ適用ツールを作成
+func NewUnifiedApplyPatchTool(constraints *security.Constraints) *UnifiedApplyPatchTool {
+	base := NewBaseTool("apply_patch", "unified diff 形式のパッチを適用します", "1.0.0", CategoryFile)
+	base.AddCapability(CapabilityFileRead)
+	base.AddCapability(CapabilityFileWrite)
+	base.AddCapability(CapabilityFileEdit)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "apply_patch",
+		Description: "unified diff（--- / +++ / @@ のハンク）を適用します。行番号のずれ・空白の違いは許し、全てのハンクが当たる場合だけまとめて書き込みます",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"patch": {
+				Type:        "string",
+				Description: "unified diff 形式のパッチ（複数ファイル可、新規作成・削除は /dev/null）",
+			},
+			"dry_run": {
+				Type:        "boolean",
+				Description: "検証だけ行い書き込まない（デフォルト：false）",
+				Default:     false,
+			},
+		},
+		Required: []string{"patch"},
+		Examples: []ToolExample{
+			{
+				Description: "関数の戻り値を変更する",
+				Parameters: map[string]interface{}{
+					"patch": "--- a/main.go\n+++ b/main.go\n@@ -3,3 +3,3 @@\n func answer() int {\n-\treturn 41\n+\treturn 42\n }\n",
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedApplyPatchTool{BaseTool: base}
+}
+
+// Execute - パッチの適用を実行
+func (t *UnifiedApplyPatchTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	patch, _ := request.Parameters["patch"].(string)
+	if strings.TrimSpace(patch) == "" {
+		return nil, NewToolError("invalid_parameter", "patch parameter is required")
+	}
+	dryRun, _ := request.Parameters["dry_run"].(bool)
+
+	constraints := t.constraints
+	if constraints == nil {
+		constraints = security.NewDefaultConstraints(".")
+	}
+	tool := NewApplyPatchTool(constraints, "", constraints.MaxFileSize)
+	result, err := tool.Apply(ApplyPatchRequest{Patch: patch, DryRun: dryRun})
+	if err != nil {
+		return nil, NewExecutionError(result.Content, -1)
+	}
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  result.Content,
+		Data:     result.Metadata["files"],
+		Metadata: &ResponseMetadata{
+			Debug: result.Metadata,
+		},
+	}, nil
+}
@@ -2,6 +2,7 @@ package tools
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -12,9 +13,10 @@ import (
 	"regexp"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/sandbox"
 	"github.com/glkt/vyb-code/internal/search"
 	"github.com/glkt/vyb-code/internal/security"
 )
@@ -26,6 +28,7 @@ type BashTool struct {
 	constraints *security.Constraints
 	workDir     string
 	timeout     time.Duration
+	backend     sandbox.Backend // 実行バックエンド（ホスト/コンテナ）
 }
 
 func NewBashTool(constraints *security.Constraints, workDir string) *BashTool {
@@ -33,10 +36,23 @@ func NewBashTool(constraints *security.Constraints, workDir string) *BashTool {
 		constraints: constraints,
 		workDir:     workDir,
 		timeout:     30 * time.Second, // デフォルト30秒タイムアウト
+		backend:     sandbox.NewHostBackend(),
+	}
+}
+
+// SetBackend は実行バックエンドを設定
+func (b *BashTool) SetBackend(backend sandbox.Backend) {
+	if backend != nil {
+		b.backend = backend
 	}
 }
 
 func (b *BashTool) Execute(command string, description string, timeoutMs int) (*ToolExecutionResult, error) {
+	return b.ExecuteContext(context.Background(), command, description, timeoutMs)
+}
+
+// ExecuteContext はctxのキャンセル（Ctrl+C等）で実行中のコマンドを停止できるExecute
+func (b *BashTool) ExecuteContext(ctx context.Context, command string, description string, timeoutMs int) (*ToolExecutionResult, error) {
 	// タイムアウト設定
 	timeout := b.timeout
 	if timeoutMs > 0 {
@@ -56,22 +72,41 @@ func (b *BashTool) Execute(command string, description string, timeoutMs int) (*
 		}, err
 	}
 
+	// 作業ディレクトリがワークスペース内か検証（ワークスペース未設定の制約では制限しない）
+	workDir := b.workDir
+	if b.constraints.WorkspaceDir != "" {
+		resolved, err := b.constraints.ResolveWorkDir(workDir)
+		if err != nil {
+			return &ToolExecutionResult{
+				Content:  fmt.Sprintf("作業ディレクトリエラー: %v", err),
+				IsError:  true,
+				Tool:     "bash",
+				ExitCode: -1,
+			}, err
+		}
+		workDir = resolved
+	}
+
 	// コマンド実行
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	ctx, cancel := context.WithTimeout(ctx, timeout)
 	defer cancel()
 
-	cmd := exec.CommandContext(ctx, "bash", "-c", command)
-	cmd.Dir = b.workDir
-
-	stdout, err := cmd.StdoutPipe()
-	if err != nil {
-		return nil, fmt.Errorf("stdout pipe作成エラー: %w", err)
-	}
-	stderr, err := cmd.StderrPipe()
+	cmd, err := b.backend.Command(ctx, command, workDir)
 	if err != nil {
-		return nil, fmt.Errorf("stderr pipe作成エラー: %w", err)
+		return &ToolExecutionResult{
+			Content:  fmt.Sprintf("実行バックエンドエラー: %v", err),
+			IsError:  true,
+			Tool:     "bash",
+			ExitCode: -1,
+		}, err
 	}
 
+	// 出力はexec側でコピーさせ、停止後に子プロセスがパイプを保持していてもWaitDelayで打ち切る
+	var stdoutBuf, stderrBuf bytes.Buffer
+	cmd.Stdout = &stdoutBuf
+	cmd.Stderr = &stderrBuf
+	cmd.WaitDelay = time.Second
+
 	start := time.Now()
 	if err := cmd.Start(); err != nil {
 		return &ToolExecutionResult{
@@ -83,30 +118,7 @@ func (b *BashTool) Execute(command string, description string, timeoutMs int) (*
 		}, err
 	}
 
-	// 出力を読み取り
-	var stdoutBuf, stderrBuf strings.Builder
-	var wg sync.WaitGroup
-
-	wg.Add(2)
-	go func() {
-		defer wg.Done()
-		scanner := bufio.NewScanner(stdout)
-		for scanner.Scan() {
-			stdoutBuf.WriteString(scanner.Text())
-			stdoutBuf.WriteString("\n")
-		}
-	}()
-	go func() {
-		defer wg.Done()
-		scanner := bufio.NewScanner(stderr)
-		for scanner.Scan() {
-			stderrBuf.WriteString(scanner.Text())
-			stderrBuf.WriteString("\n")
-		}
-	}()
-
 	err = cmd.Wait()
-	wg.Wait() // goroutineの完了を待つ
 	duration := time.Since(start)
 
 	// タイムアウト検出
@@ -121,16 +133,16 @@ func (b *BashTool) Execute(command string, description string, timeoutMs int) (*
 		}
 	}
 
-	// 出力構築
-	output := stdoutBuf.String()
+	// 出力構築（行単位で読んでいた頃と同様に末尾を改行で揃える）
+	output := terminateLine(stdoutBuf.String())
 	if stderrBuf.Len() > 0 {
 		if output != "" {
 			output += "\n"
 		}
-		output += stderrBuf.String()
+		output += terminateLine(stderrBuf.String())
 	}
 
-	return &ToolExecutionResult{
+	result := &ToolExecutionResult{
 		Content:  output,
 		IsError:  exitCode != 0 || timedOut,
 		Tool:     "bash",
@@ -141,8 +153,23 @@ func (b *BashTool) Execute(command string, description string, timeoutMs int) (*
 			"command":     command,
 			"description": description,
 			"timeout_ms":  timeoutMs,
+			"backend":     b.backend.Name(),
 		},
-	}, nil
+	}
+
+	// 呼び出し元のキャンセルはエラーとして伝え、後続の処理を止められるようにする
+	if err != nil && ctx.Err() == context.Canceled {
+		return result, fmt.Errorf("コマンドがキャンセルされました: %w", context.Canceled)
+	}
+	return result, nil
+}
+
+// terminateLine は空でない出力の末尾を改行で終える
+func terminateLine(s string) string {
+	if s != "" && !strings.HasSuffix(s, "\n") {
+		s += "\n"
+	}
+	return s
 }
 
 // GlobTool - ファイルパターンマッチング
@@ -199,7 +226,7 @@ func (g *GlobTool) Find(pattern string, path string) (*ToolExecutionResult, erro
 func (g *GlobTool) globRecursive(dir, pattern string) ([]string, error) {
 	var matches []string
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // スキップして継続
 		}
@@ -363,7 +390,7 @@ type GrepMatch struct {
 func (g *GrepTool) searchFiles(dir string, regex *regexp.Regexp, options GrepOptions) ([]GrepMatch, error) {
 	var matches []GrepMatch
 
-	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(dir, func(path string, info os.FileInfo, err error) error {
 		if err != nil || info.IsDir() {
 			return nil
 		}
@@ -607,6 +634,14 @@ func NewWebFetchTool() *WebFetchTool {
 	}
 }
 
+// SetNetworkPolicy はWeb取得にネットワークポリシーを適用
+func (w *WebFetchTool) SetNetworkPolicy(policy *security.NetworkPolicy) {
+	if policy == nil {
+		return
+	}
+	w.client = policy.WrapClient(w.client)
+}
+
 func (w *WebFetchTool) Fetch(url string, prompt string) (*ToolExecutionResult, error) {
 	// HTTP/HTTPSチェック
 	if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
@@ -642,7 +677,7 @@ func (w *WebFetchTool) Fetch(url string, prompt string) (*ToolExecutionResult, e
 		}, fmt.Errorf("HTTP error: %d", resp.StatusCode)
 	}
 
-	body, err := io.ReadAll(resp.Body)
+	body, err := io.ReadAll(io.LimitReader(resp.Body, defaultWebFetchMaxBytes))
 	if err != nil {
 		return &ToolExecutionResult{
 			Content: fmt.Sprintf("レスポンス読み取りエラー: %v", err),
@@ -671,15 +706,7 @@ func (w *WebFetchTool) Fetch(url string, prompt string) (*ToolExecutionResult, e
 }
 
 func (w *WebFetchTool) htmlToMarkdown(html string) string {
-	// 基本的なHTML→Markdown変換
-	content := html
-
-	// HTMLタグの除去（簡易版）
-	re := regexp.MustCompile(`<[^>]*>`)
-	content = re.ReplaceAllString(content, "")
-
-	// 実際の実装では、より高度なHTML→Markdown変換ライブラリを使用
-	return strings.TrimSpace(content)
+	return HTMLToMarkdown(html)
 }
 
 // formatUnifiedResults - 統一検索エンジン結果のフォーマット
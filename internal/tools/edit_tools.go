@@ -1,7 +1,8 @@
 package tools
 
 import (
-	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -78,31 +79,46 @@ func NewEditTool(constraints *security.Constraints, workDir string, maxFileSize
 	}
 }
 
+// SetPermissions はプロジェクト・ユーザーの許可規則を設定（nilで既定の制約のみ）
+func (e *EditTool) SetPermissions(permissions *security.Permissions) {
+	e.constraints.Permissions = permissions
+}
+
 type EditRequest struct {
-	FilePath   string `json:"file_path"`
-	OldString  string `json:"old_string"`
-	NewString  string `json:"new_string"`
-	ReplaceAll bool   `json:"replace_all,omitempty"`
+	FilePath     string `json:"file_path"`
+	OldString    string `json:"old_string"`
+	NewString    string `json:"new_string"`
+	ReplaceAll   bool   `json:"replace_all,omitempty"`
+	ExpectedHash string `json:"expected_hash,omitempty"` // 編集を作った時点の内容の ContentHash（空なら確認しない）
+}
+
+// ContentHash はファイル内容のハッシュ（編集を作ってから適用するまでに変更されていないかの確認に使う）
+func ContentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// StaleFileError は編集を作った後にファイルが変更されていたエラー（Current は現在の内容）
+type StaleFileError struct {
+	FilePath     string
+	ExpectedHash string
+	ActualHash   string
+	Current      string
+}
+
+func (e *StaleFileError) Error() string {
+	return fmt.Sprintf("%s changed on disk since the edit was generated", e.FilePath)
 }
 
 func (e *EditTool) Edit(req EditRequest) (*ToolExecutionResult, error) {
-	// ファイルパスの検証
-	absPath, err := filepath.Abs(req.FilePath)
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
+	absPath, err := resolveToolPath(e.constraints, e.workDir, req.FilePath, "write")
 	if err != nil {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パス解決エラー: %v", err),
-			IsError: true,
-			Tool:    "edit",
-		}, err
-	}
-
-	// セキュリティ制約チェック（ワークスペース内かどうか）
-	if !e.constraints.IsPathAllowed(absPath) {
-		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パスがワークスペース外です: %s", req.FilePath),
+			Content: fmt.Sprintf("パスへのアクセスが許可されていません: %v", err),
 			IsError: true,
 			Tool:    "edit",
-		}, fmt.Errorf("path outside workspace: %s", req.FilePath)
+		}, fmt.Errorf("path outside workspace: %w", err)
 	}
 
 	// ファイルの存在確認
@@ -135,6 +151,17 @@ func (e *EditTool) Edit(req EditRequest) (*ToolExecutionResult, error) {
 
 	originalContent := string(content)
 
+	// 編集を作った後に他の編集・エディタで変わっていれば、古い内容を前提にした置換で壊さないよう止める
+	if req.ExpectedHash != "" {
+		if actual := ContentHash(originalContent); actual != req.ExpectedHash {
+			return &ToolExecutionResult{
+				Content: fmt.Sprintf("編集の作成後にファイルが変更されています: %s", req.FilePath),
+				IsError: true,
+				Tool:    "edit",
+			}, &StaleFileError{FilePath: req.FilePath, ExpectedHash: req.ExpectedHash, ActualHash: actual, Current: originalContent}
+		}
+	}
+
 	// 文字列置換の実行
 	var modifiedContent string
 	var replacements int
@@ -203,6 +230,7 @@ func (e *EditTool) Edit(req EditRequest) (*ToolExecutionResult, error) {
 }
 
 // MultiEditTool - 複数の編集操作を一つのファイルに対して実行
+// 編集は順にメモリ上で適用し、全て成功した場合だけ一度に書き込む（途中まで編集された状態を残さない）
 type MultiEditTool struct {
 	editTool *EditTool
 }
@@ -219,123 +247,83 @@ type MultiEditRequest struct {
 }
 
 func (me *MultiEditTool) MultiEdit(req MultiEditRequest) (*ToolExecutionResult, error) {
-	// パス検証
-	absPath, err := filepath.Abs(req.FilePath)
-	if err != nil {
+	fail := func(message string, err error) (*ToolExecutionResult, error) {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パス解決エラー: %v", err),
+			Content: message,
 			IsError: true,
 			Tool:    "multiedit",
 		}, err
 	}
 
-	// セキュリティ制約チェック
-	if !me.editTool.constraints.IsPathAllowed(absPath) {
-		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パスがワークスペース外です: %s", req.FilePath),
-			IsError: true,
-			Tool:    "multiedit",
-		}, fmt.Errorf("path outside workspace")
+	if len(req.Edits) == 0 {
+		return fail("編集操作が指定されていません", fmt.Errorf("no edits specified"))
 	}
 
-	// ファイルの存在確認
-	if _, err := os.Stat(absPath); os.IsNotExist(err) {
-		// ファイルが存在しない場合は新規作成
-		if len(req.Edits) == 0 {
-			return &ToolExecutionResult{
-				Content: "編集操作が指定されていません",
-				IsError: true,
-				Tool:    "multiedit",
-			}, fmt.Errorf("no edits specified")
-		}
-
-		// 最初の編集で空文字列から開始する場合のみ新規ファイル作成を許可
-		firstEdit := req.Edits[0]
-		if firstEdit.OldString != "" {
-			return &ToolExecutionResult{
-				Content: fmt.Sprintf("ファイルが存在しません: %s", req.FilePath),
-				IsError: true,
-				Tool:    "multiedit",
-			}, fmt.Errorf("file not found")
-		}
-
-		// 新規ファイルを作成
-		err = os.WriteFile(absPath, []byte(firstEdit.NewString), 0644)
-		if err != nil {
-			return &ToolExecutionResult{
-				Content: fmt.Sprintf("新規ファイル作成エラー: %v", err),
-				IsError: true,
-				Tool:    "multiedit",
-			}, err
-		}
-
-		// 残りの編集を実行
-		req.Edits = req.Edits[1:]
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
+	absPath, err := resolveToolPath(me.editTool.constraints, me.editTool.workDir, req.FilePath, "write")
+	if err != nil {
+		return fail(fmt.Sprintf("パスへのアクセスが許可されていません: %v", err), fmt.Errorf("path outside workspace: %w", err))
 	}
 
-	// 現在のファイル内容を読み込み
+	// 現在のファイル内容を読み込み（存在しない場合は最初の編集の old_string が空なら新規作成）
+	var originalContent string
+	creating := false
 	content, err := os.ReadFile(absPath)
-	if err != nil {
-		return &ToolExecutionResult{
-			Content: fmt.Sprintf("ファイル読み込みエラー: %v", err),
-			IsError: true,
-			Tool:    "multiedit",
-		}, err
+	switch {
+	case os.IsNotExist(err):
+		if req.Edits[0].OldString != "" {
+			return fail(fmt.Sprintf("ファイルが存在しません: %s", req.FilePath), fmt.Errorf("file not found"))
+		}
+		creating = true
+	case err != nil:
+		return fail(fmt.Sprintf("ファイル読み込みエラー: %v", err), err)
+	case int64(len(content)) > me.editTool.maxFileSize:
+		return fail(fmt.Sprintf("ファイルサイズが制限を超えています: %d bytes", len(content)), fmt.Errorf("file too large"))
+	default:
+		originalContent = string(content)
 	}
 
-	currentContent := string(content)
+	currentContent := originalContent
 	totalReplacements := 0
 	successfulEdits := 0
 
-	// 各編集を順次実行
+	// 各編集を順次実行（前の編集の結果に対して次の編集を探す）
 	for i, editReq := range req.Edits {
-		editReq.FilePath = req.FilePath // ファイルパスを設定
-
-		// 文字列置換
-		var modifiedContent string
-		var replacements int
+		if creating && i == 0 {
+			currentContent = editReq.NewString
+			successfulEdits++
+			continue
+		}
+		if editReq.OldString == "" {
+			return fail(fmt.Sprintf("編集 %d: old_string が空です（ファイルは変更していません）", i+1), fmt.Errorf("empty old_string in edit %d", i+1))
+		}
 
+		occurrences := strings.Count(currentContent, editReq.OldString)
+		if occurrences == 0 {
+			return fail(fmt.Sprintf("編集 %d: 指定された文字列が見つかりません（ファイルは変更していません）: %s", i+1, editReq.OldString), fmt.Errorf("string not found in edit %d", i+1))
+		}
 		if editReq.ReplaceAll {
-			modifiedContent = strings.ReplaceAll(currentContent, editReq.OldString, editReq.NewString)
-			replacements = strings.Count(currentContent, editReq.OldString)
+			currentContent = strings.ReplaceAll(currentContent, editReq.OldString, editReq.NewString)
+			totalReplacements += occurrences
 		} else {
-			occurrences := strings.Count(currentContent, editReq.OldString)
-			if occurrences == 0 {
-				return &ToolExecutionResult{
-					Content: fmt.Sprintf("編集 %d: 指定された文字列が見つかりません: %s", i+1, editReq.OldString),
-					IsError: true,
-					Tool:    "multiedit",
-				}, fmt.Errorf("string not found in edit %d", i+1)
-			}
 			if occurrences > 1 {
-				return &ToolExecutionResult{
-					Content: fmt.Sprintf("編集 %d: 指定された文字列が複数存在します（%d箇所）", i+1, occurrences),
-					IsError: true,
-					Tool:    "multiedit",
-				}, fmt.Errorf("ambiguous match in edit %d", i+1)
+				return fail(fmt.Sprintf("編集 %d: 指定された文字列が複数存在します（%d箇所、ファイルは変更していません）", i+1, occurrences), fmt.Errorf("ambiguous match in edit %d", i+1))
 			}
-
-			modifiedContent = strings.Replace(currentContent, editReq.OldString, editReq.NewString, 1)
-			replacements = 1
+			currentContent = strings.Replace(currentContent, editReq.OldString, editReq.NewString, 1)
+			totalReplacements++
 		}
+		successfulEdits++
+	}
 
-		// 現在のコンテンツを更新
-		if modifiedContent != currentContent {
-			currentContent = modifiedContent
-			totalReplacements += replacements
-			successfulEdits++
-		}
+	if int64(len(currentContent)) > me.editTool.maxFileSize {
+		return fail(fmt.Sprintf("編集後のファイルサイズが制限を超えています: %d bytes", len(currentContent)), fmt.Errorf("file too large"))
 	}
 
-	// 変更があれば書き込み
-	if totalReplacements > 0 {
-		err = os.WriteFile(absPath, []byte(currentContent), 0644)
-		if err != nil {
-			return &ToolExecutionResult{
-				Content: fmt.Sprintf("ファイル書き込みエラー: %v", err),
-				IsError: true,
-				Tool:    "multiedit",
-			}, err
+	// 変更があれば一時ファイル経由でまとめて書き込み
+	changed := creating || currentContent != originalContent
+	if changed {
+		if _, err := writeFileStreaming(absPath, strings.NewReader(currentContent), false); err != nil {
+			return fail(fmt.Sprintf("ファイル書き込みエラー: %v", err), err)
 		}
 	}
 
@@ -348,7 +336,8 @@ func (me *MultiEditTool) MultiEdit(req MultiEditRequest) (*ToolExecutionResult,
 			"total_edits":        len(req.Edits),
 			"successful_edits":   successfulEdits,
 			"total_replacements": totalReplacements,
-			"changed":            totalReplacements > 0,
+			"created":            creating,
+			"changed":            changed,
 		},
 	}, nil
 }
@@ -375,23 +364,14 @@ type ReadRequest struct {
 }
 
 func (r *ReadTool) Read(req ReadRequest) (*ToolExecutionResult, error) {
-	// パス検証
-	absPath, err := filepath.Abs(req.FilePath)
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
+	absPath, err := resolveToolPath(r.constraints, r.workDir, req.FilePath, "read")
 	if err != nil {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パス解決エラー: %v", err),
+			Content: fmt.Sprintf("パスへのアクセスが許可されていません: %v", err),
 			IsError: true,
 			Tool:    "read",
-		}, err
-	}
-
-	// セキュリティ制約チェック
-	if !r.constraints.IsPathAllowed(absPath) {
-		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パスがワークスペース外です: %s", req.FilePath),
-			IsError: true,
-			Tool:    "read",
-		}, fmt.Errorf("path outside workspace")
+		}, fmt.Errorf("path outside workspace: %w", err)
 	}
 
 	// ファイル存在確認
@@ -404,52 +384,39 @@ func (r *ReadTool) Read(req ReadRequest) (*ToolExecutionResult, error) {
 		}, err
 	}
 
-	// サイズ制限チェック
-	if fileInfo.Size() > r.maxFileSize {
+	// バイナリファイルはテキストとして返さず、調べ方を案内する
+	binary, err := sniffBinary(absPath)
+	if err != nil {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("ファイルサイズが制限を超えています: %d bytes", fileInfo.Size()),
+			Content: fmt.Sprintf("ファイル読み取りエラー: %v", err),
 			IsError: true,
 			Tool:    "read",
-		}, fmt.Errorf("file too large")
+		}, err
 	}
-
-	// ファイル読み取り
-	file, err := os.Open(absPath)
-	if err != nil {
+	if binary {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("ファイル読み取りエラー: %v", err),
+			Content: binaryFileGuidance(req.FilePath, fileInfo.Size()),
 			IsError: true,
 			Tool:    "read",
-		}, err
+		}, fmt.Errorf("binary file: %s", req.FilePath)
 	}
-	defer file.Close()
 
-	var content strings.Builder
-	scanner := bufio.NewScanner(file)
-	lineNum := 1
-	totalLines := 0
-
-	// オフセット処理
-	for scanner.Scan() {
-		totalLines++
-
-		// オフセット以前の行をスキップ
-		if req.Offset > 0 && lineNum < req.Offset {
-			lineNum++
-			continue
-		}
-
-		// 制限行数チェック
-		if req.Limit > 0 && lineNum >= req.Offset+req.Limit {
-			break
+	// サイズ制限チェック（範囲指定なしの場合は概要と読み方を返す）
+	if fileInfo.Size() > r.maxFileSize && req.Limit <= 0 {
+		summary, _, err := summarizeLargeFile(absPath, fileInfo.Size())
+		if err != nil {
+			summary = fmt.Sprintf("ファイルサイズが制限を超えています: %d bytes", fileInfo.Size())
 		}
-
-		// 行番号付きで追加（Claude Codeスタイル）
-		content.WriteString(fmt.Sprintf("%5d→%s\n", lineNum, scanner.Text()))
-		lineNum++
+		return &ToolExecutionResult{
+			Content: summary,
+			IsError: true,
+			Tool:    "read",
+		}, fmt.Errorf("file too large: %d bytes (max: %d), read it in ranges with offset/limit", fileInfo.Size(), r.maxFileSize)
 	}
 
-	if err := scanner.Err(); err != nil {
+	// ファイル読み取り（全体をメモリに載せずに指定範囲の行のみ取り出す）
+	chunk, err := readLineRange(absPath, req.Offset, req.Limit)
+	if err != nil {
 		return &ToolExecutionResult{
 			Content: fmt.Sprintf("ファイル読み取りエラー: %v", err),
 			IsError: true,
@@ -458,16 +425,18 @@ func (r *ReadTool) Read(req ReadRequest) (*ToolExecutionResult, error) {
 	}
 
 	return &ToolExecutionResult{
-		Content: content.String(),
+		// 行番号付きで返す（Claude Codeスタイル）
+		Content: formatNumberedLines(chunk.Lines, chunk.Start),
 		IsError: false,
 		Tool:    "read",
 		Metadata: map[string]interface{}{
-			"file_path":   req.FilePath,
-			"total_lines": totalLines,
-			"lines_read":  lineNum - max(1, req.Offset),
-			"file_size":   fileInfo.Size(),
-			"offset":      req.Offset,
-			"limit":       req.Limit,
+			"file_path":       req.FilePath,
+			"total_lines":     chunk.TotalLines,
+			"lines_read":      len(chunk.Lines),
+			"file_size":       fileInfo.Size(),
+			"offset":          req.Offset,
+			"limit":           req.Limit,
+			"lines_truncated": chunk.Truncated,
 		},
 	}, nil
 }
@@ -490,35 +459,27 @@ func NewWriteTool(constraints *security.Constraints, workDir string, maxFileSize
 type WriteRequest struct {
 	FilePath string `json:"file_path"`
 	Content  string `json:"content"`
+	Append   bool   `json:"append,omitempty"` // 既存の内容の末尾に追記（上限を超える内容を分割して書き込む）
 }
 
 func (w *WriteTool) Write(req WriteRequest) (*ToolExecutionResult, error) {
-	// パス検証
-	absPath, err := filepath.Abs(req.FilePath)
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
+	absPath, err := resolveToolPath(w.constraints, w.workDir, req.FilePath, "write")
 	if err != nil {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パス解決エラー: %v", err),
-			IsError: true,
-			Tool:    "write",
-		}, err
-	}
-
-	// セキュリティ制約チェック
-	if !w.constraints.IsPathAllowed(absPath) {
-		return &ToolExecutionResult{
-			Content: fmt.Sprintf("パスがワークスペース外です: %s", req.FilePath),
+			Content: fmt.Sprintf("パスへのアクセスが許可されていません: %v", err),
 			IsError: true,
 			Tool:    "write",
-		}, fmt.Errorf("path outside workspace")
+		}, fmt.Errorf("path outside workspace: %w", err)
 	}
 
-	// サイズ制限チェック
+	// サイズ制限チェック（黙って切り詰めず、分割して書き込むよう案内する）
 	if int64(len(req.Content)) > w.maxFileSize {
 		return &ToolExecutionResult{
-			Content: fmt.Sprintf("コンテンツサイズが制限を超えています: %d bytes", len(req.Content)),
+			Content: writeTooLargeGuidance(int64(len(req.Content)), w.maxFileSize),
 			IsError: true,
 			Tool:    "write",
-		}, fmt.Errorf("content too large")
+		}, fmt.Errorf("content too large: %d bytes (max: %d)", len(req.Content), w.maxFileSize)
 	}
 
 	// 既存ファイルの存在チェック（上書き警告のため）
@@ -527,19 +488,8 @@ func (w *WriteTool) Write(req WriteRequest) (*ToolExecutionResult, error) {
 		overwriting = true
 	}
 
-	// ディレクトリ作成（必要に応じて）
-	dir := filepath.Dir(absPath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return &ToolExecutionResult{
-			Content: fmt.Sprintf("ディレクトリ作成エラー: %v", err),
-			IsError: true,
-			Tool:    "write",
-		}, err
-	}
-
-	// ファイル書き込み
-	err = os.WriteFile(absPath, []byte(req.Content), 0644)
-	if err != nil {
+	// ファイル書き込み（上書きは一時ファイル経由で置き換え、ディレクトリは必要に応じて作成）
+	if _, err := writeFileStreaming(absPath, strings.NewReader(req.Content), req.Append); err != nil {
 		return &ToolExecutionResult{
 			Content: fmt.Sprintf("ファイル書き込みエラー: %v", err),
 			IsError: true,
@@ -548,7 +498,10 @@ func (w *WriteTool) Write(req WriteRequest) (*ToolExecutionResult, error) {
 	}
 
 	action := "作成"
-	if overwriting {
+	switch {
+	case req.Append:
+		action = "追記"
+	case overwriting:
 		action = "上書き"
 	}
 
@@ -564,3 +517,12 @@ func (w *WriteTool) Write(req WriteRequest) (*ToolExecutionResult, error) {
 		},
 	}, nil
 }
+
+// resolveToolPath はツールに渡されたパスを作業ディレクトリ基準で解決し、ワークスペース内の実体パスを返す
+// 操作（read, write）がプロジェクトの許可規則で禁止されたパスはエラーにする
+func resolveToolPath(constraints *security.Constraints, workDir, path, operation string) (string, error) {
+	if !filepath.IsAbs(path) && workDir != "" {
+		path = filepath.Join(workDir, path)
+	}
+	return constraints.ResolvePathFor(path, operation)
+}
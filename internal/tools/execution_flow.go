@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/glkt/vyb-code/internal/budget"
 	"github.com/glkt/vyb-code/internal/config"
 	"github.com/glkt/vyb-code/internal/security"
 )
@@ -22,6 +24,15 @@ type ExecutionFlow struct {
 	executionHistory []ExecutionStep
 	autoExecution    bool
 	chainedExecution bool
+
+	// 実行イベント通知先（ヘッドレス実行等）
+	observer ExecutionObserver
+}
+
+// ExecutionObserver - ツール実行ステップの開始・完了通知を受け取る
+type ExecutionObserver interface {
+	OnStepStart(step ExecutionStep)
+	OnStepComplete(step ExecutionStep)
 }
 
 // ExecutionStep - 実行ステップ記録
@@ -139,24 +150,36 @@ func (ef *ExecutionFlow) extractToolSteps(userInput string) []toolStepCandidate
 
 	// ファイル読み取りパターン（元の文字列から抽出してケース保持）
 	filePattern := regexp.MustCompile(`(?:read|show|display|view|check|see|look\s+at|examine)\s+(?:me\s+)?(?:the\s+)?(?:content\s+of\s+)?(?:file\s+)?([^\s]+\.[a-zA-Z]+)`)
-	if matches := filePattern.FindAllStringSubmatch(userInput, -1); len(matches) > 0 {
+	if matches := filePattern.FindAllStringSubmatch(userInput, -1); len(matches) > 1 {
+		// 複数ファイルは1回のバッチ読み取りでまとめて取得
+		var paths []interface{}
+		var names []string
 		for _, match := range matches {
-			if len(match) > 1 {
-				filePath := match[1]
-				// 相対パスを正規化（セキュリティ要件を満たすため）
-				if !filepath.IsAbs(filePath) && !strings.HasPrefix(filePath, "./") && !strings.HasPrefix(filePath, "/") {
-					filePath = "./" + filePath
-				}
-				steps = append(steps, toolStepCandidate{
-					tool: "read",
-					parameters: map[string]interface{}{
-						"file_path": filePath,
-					},
-					description: fmt.Sprintf("Read file %s", match[1]),
-					rationale:   "User wants to examine file content",
-				})
-			}
+			paths = append(paths, match[1])
+			names = append(names, match[1])
+		}
+		steps = append(steps, toolStepCandidate{
+			tool: "batch_read",
+			parameters: map[string]interface{}{
+				"paths": paths,
+			},
+			description: fmt.Sprintf("Read files %s", strings.Join(names, ", ")),
+			rationale:   "User wants to examine multiple files",
+		})
+	} else if len(matches) == 1 {
+		filePath := matches[0][1]
+		// 相対パスを正規化（セキュリティ要件を満たすため）
+		if !filepath.IsAbs(filePath) && !strings.HasPrefix(filePath, "./") && !strings.HasPrefix(filePath, "/") {
+			filePath = "./" + filePath
 		}
+		steps = append(steps, toolStepCandidate{
+			tool: "read",
+			parameters: map[string]interface{}{
+				"file_path": filePath,
+			},
+			description: fmt.Sprintf("Read file %s", matches[0][1]),
+			rationale:   "User wants to examine file content",
+		})
 	}
 
 	// ファイル検索パターン
@@ -201,6 +224,12 @@ func (ef *ExecutionFlow) extractToolSteps(userInput string) []toolStepCandidate
 		})
 	}
 
+	// Git履歴パターン（blame・変更履歴・関数を最後に変更した人）
+	steps = append(steps, gitHistorySteps(userInput)...)
+
+	// 履歴検索パターン（関数がいつ導入・削除されたか）
+	steps = append(steps, gitPickaxeSteps(userInput)...)
+
 	// コマンド実行パターン
 	if matches := regexp.MustCompile(`(?:run|execute|exec)\s+["\']?([^"'\n]+)["\']?`).FindAllStringSubmatch(inputLower, -1); len(matches) > 0 {
 		for _, match := range matches {
@@ -221,6 +250,98 @@ func (ef *ExecutionFlow) extractToolSteps(userInput string) []toolStepCandidate
 	return steps
 }
 
+// git履歴の問い合わせパターン
+var (
+	gitSymbolPattern  = regexp.MustCompile(`(?i)who\s+(?:last\s+)?(?:touched|changed|modified|wrote|edited)\s+(?:the\s+)?(?:function\s+|method\s+)?([A-Za-z_]\w*)(?:\(\))?\s+in\s+([^\s]+\.[a-zA-Z]+)`)
+	gitSymbolPatternJ = regexp.MustCompile(`([^\s]+\.[a-zA-Z]+)\s*の\s*([A-Za-z_]\w*)(?:\(\))?\s*(?:関数|メソッド)?\s*を.*誰`)
+	gitBlamePattern   = regexp.MustCompile(`(?i)\bblame\s+(?:for\s+|on\s+|of\s+)?([^\s:]+\.[a-zA-Z]+)(?::(\d+)(?:-(\d+))?)?`)
+	gitLogPattern     = regexp.MustCompile(`(?i)(?:(?:history|log|commits)\s+(?:of|for)|why\s+(?:was|is|does)\b.*?)\s+([^\s]+\.[a-zA-Z]+)`)
+	gitLogPatternJ    = regexp.MustCompile(`([^\s]+\.[a-zA-Z]+)\s*の\s*(?:変更)?(?:履歴|経緯)`)
+)
+
+// gitHistorySteps - コードの経緯を尋ねる入力を git_history の実行ステップに変換
+func gitHistorySteps(userInput string) []toolStepCandidate {
+	step := func(parameters map[string]interface{}, description string) []toolStepCandidate {
+		return []toolStepCandidate{{
+			tool:        "git_history",
+			parameters:  parameters,
+			description: description,
+			rationale:   "User wants to know who changed the code and why",
+		}}
+	}
+
+	for _, match := range [][]string{gitSymbolPattern.FindStringSubmatch(userInput), swapped(gitSymbolPatternJ.FindStringSubmatch(userInput))} {
+		if match != nil {
+			return step(map[string]interface{}{
+				"operation": GitHistorySymbol,
+				"symbol":    match[1],
+				"file_path": match[2],
+			}, fmt.Sprintf("Find who last touched %s in %s", match[1], match[2]))
+		}
+	}
+
+	if match := gitBlamePattern.FindStringSubmatch(userInput); match != nil {
+		parameters := map[string]interface{}{
+			"operation": GitHistoryBlame,
+			"file_path": match[1],
+		}
+		if start, err := strconv.Atoi(match[2]); err == nil {
+			parameters["start_line"] = start
+			parameters["end_line"] = start
+			if end, err := strconv.Atoi(match[3]); err == nil {
+				parameters["end_line"] = end
+			}
+		}
+		return step(parameters, fmt.Sprintf("Blame %s", match[1]))
+	}
+
+	for _, match := range [][]string{gitLogPattern.FindStringSubmatch(userInput), gitLogPatternJ.FindStringSubmatch(userInput)} {
+		if match != nil {
+			file := match[len(match)-1]
+			return step(map[string]interface{}{
+				"operation": GitHistoryLog,
+				"file_path": file,
+			}, fmt.Sprintf("Show history of %s", file))
+		}
+	}
+	return nil
+}
+
+// 導入・削除の時期の問い合わせパターン（任意で in <パス> に限定）
+var (
+	gitPickaxePattern  = regexp.MustCompile(`(?i)when\s+(?:was|were|did)\s+(?:the\s+)?(?:function\s+|method\s+|type\s+|constant\s+)?["'\x60]?([A-Za-z_][\w.]*)(?:\(\))?["'\x60]?\s+(?:first\s+|get\s+)?(?:introduced|added|created|removed|deleted|dropped)(?:\s+(?:in|from|to)\s+([^\s?]+))?`)
+	gitPickaxePatternJ = regexp.MustCompile(`([A-Za-z_][\w.]*)(?:\(\))?\s*(?:関数|メソッド|型|定数)?\s*(?:は|が)\s*いつ.*(?:追加|導入|作|削除|消|なくな)`)
+)
+
+// gitPickaxeSteps - 関数等がいつ導入・削除されたかを尋ねる入力を git_pickaxe の実行ステップに変換
+func gitPickaxeSteps(userInput string) []toolStepCandidate {
+	match := gitPickaxePattern.FindStringSubmatch(userInput)
+	if match == nil {
+		match = gitPickaxePatternJ.FindStringSubmatch(userInput)
+	}
+	if match == nil {
+		return nil
+	}
+	parameters := map[string]interface{}{"term": match[1]}
+	if len(match) > 2 && match[2] != "" {
+		parameters["path"] = match[2]
+	}
+	return []toolStepCandidate{{
+		tool:        "git_pickaxe",
+		parameters:  parameters,
+		description: fmt.Sprintf("Search history for commits that added or removed %s", match[1]),
+		rationale:   "User wants to know when the code was introduced or removed",
+	}}
+}
+
+// swapped - 「ファイル の 関数」の順の一致を「関数, ファイル」の順に入れ替え
+func swapped(match []string) []string {
+	if match == nil {
+		return nil
+	}
+	return []string{match[0], match[2], match[1]}
+}
+
 // toolStepCandidate - ツール実行候補
 type toolStepCandidate struct {
 	tool        string
@@ -232,7 +353,7 @@ type toolStepCandidate struct {
 // assessRisk - ツール実行のリスクレベルを評価
 func (ef *ExecutionFlow) assessRisk(toolName string, parameters map[string]interface{}) RiskLevel {
 	switch toolName {
-	case "read", "ls", "grep":
+	case "read", "batch_read", "ls", "grep", "git_history", "git_pickaxe", "diagnostics", "log_tail":
 		return RiskLevelSafe // 読み取り専用
 	case "bash":
 		if cmd, ok := parameters["command"].(string); ok {
@@ -262,10 +383,12 @@ func (ef *ExecutionFlow) assessRisk(toolName string, parameters map[string]inter
 			return RiskLevelLow // その他のコマンド
 		}
 		return RiskLevelLow
-	case "edit", "write":
+	case "edit", "write", "apply_patch":
 		return RiskLevelMedium // ファイル変更
-	case "multiedit":
-		return RiskLevelHigh // 複数ファイル変更
+	case "move":
+		return RiskLevelMedium // ファイル移動（取り消し可能）
+	case "multiedit", "delete":
+		return RiskLevelHigh // 複数ファイル変更・削除
 	default:
 		return RiskLevelLow
 	}
@@ -283,11 +406,11 @@ func (ef *ExecutionFlow) calculateConfidence(steps []PlannedStep) float64 {
 
 		// ツール別調整
 		switch step.Tool {
-		case "read", "ls", "grep":
+		case "read", "batch_read", "ls", "grep", "git_history", "git_pickaxe", "diagnostics", "log_tail":
 			stepConfidence = 0.9 // 安全で確実
 		case "bash":
 			stepConfidence = 0.7 // コマンド依存
-		case "edit", "write":
+		case "edit", "multiedit", "write", "apply_patch":
 			stepConfidence = 0.6 // 変更系は慎重
 		}
 
@@ -340,6 +463,11 @@ func (ef *ExecutionFlow) ExecutePlan(ctx context.Context, plan *ExecutionPlan) (
 	results := make([]ExecutionStep, 0, len(plan.Steps))
 
 	for i, plannedStep := range plan.Steps {
+		// ターンの上限を超える場合はそれまでの結果で打ち切る
+		if err := budget.UseTool(ctx, plannedStep.Tool+": "+stepTarget(plannedStep.Parameters)); err != nil {
+			return results, err
+		}
+
 		stepID := fmt.Sprintf("step_%d_%d", time.Now().Unix(), i)
 
 		step := ExecutionStep{
@@ -351,6 +479,10 @@ func (ef *ExecutionFlow) ExecutePlan(ctx context.Context, plan *ExecutionPlan) (
 			Reasoning:   plannedStep.Rationale,
 		}
 
+		if ef.observer != nil {
+			ef.observer.OnStepStart(step)
+		}
+
 		// ツール実行
 		request := &ToolRequest{
 			ToolName:   plannedStep.Tool,
@@ -375,6 +507,10 @@ func (ef *ExecutionFlow) ExecutePlan(ctx context.Context, plan *ExecutionPlan) (
 		results = append(results, step)
 		ef.executionHistory = append(ef.executionHistory, step)
 
+		if ef.observer != nil {
+			ef.observer.OnStepComplete(step)
+		}
+
 		// チェーン実行が無効化されている場合、最初のステップのみ実行
 		if !ef.chainedExecution && i == 0 {
 			break
@@ -394,6 +530,11 @@ func (ef *ExecutionFlow) GetExecutionHistory() []ExecutionStep {
 	return ef.executionHistory
 }
 
+// SetObserver - 実行イベントの通知先を設定（nilで解除）
+func (ef *ExecutionFlow) SetObserver(observer ExecutionObserver) {
+	ef.observer = observer
+}
+
 // UpdateConfig - 設定を更新
 func (ef *ExecutionFlow) UpdateConfig(cfg *config.Config) {
 	ef.config = cfg
@@ -402,3 +543,13 @@ func (ef *ExecutionFlow) UpdateConfig(cfg *config.Config) {
 		ef.chainedExecution = cfg.Prompts.EnableChainedActions
 	}
 }
+
+// stepTarget はステップの対象（ファイル・コマンド・パターン）を1行で返す
+func stepTarget(parameters map[string]interface{}) string {
+	for _, key := range []string{"file_path", "command", "pattern", "path"} {
+		if value, ok := parameters[key].(string); ok && value != "" {
+			return value
+		}
+	}
+	return ""
+}
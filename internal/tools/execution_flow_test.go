@@ -71,6 +71,27 @@ func TestAnalyzeUserIntent(t *testing.T) {
 			expectedTool:  "bash",
 			expectedRisk:  RiskLevelLow,
 		},
+		{
+			name:          "who last touched a function",
+			input:         "who last touched applyDefaults in internal/config/config.go?",
+			expectedSteps: 1,
+			expectedTool:  "git_history",
+			expectedRisk:  RiskLevelSafe,
+		},
+		{
+			name:          "file history in Japanese",
+			input:         "main.go の変更履歴を教えて",
+			expectedSteps: 1,
+			expectedTool:  "git_history",
+			expectedRisk:  RiskLevelSafe,
+		},
+		{
+			name:          "when was a function removed",
+			input:         "when was the function legacyLoad removed?",
+			expectedSteps: 1,
+			expectedTool:  "git_pickaxe",
+			expectedRisk:  RiskLevelSafe,
+		},
 		{
 			name:          "no tool required",
 			input:         "explain how Go works",
@@ -104,6 +125,59 @@ func TestAnalyzeUserIntent(t *testing.T) {
 	}
 }
 
+func TestGitHistorySteps(t *testing.T) {
+	tests := []struct {
+		input  string
+		params map[string]interface{}
+	}{
+		{"who changed the function Load in config.go", map[string]interface{}{"operation": "symbol", "symbol": "Load", "file_path": "config.go"}},
+		{"config.go の Load 関数を最後に変更したのは誰？", map[string]interface{}{"operation": "symbol", "symbol": "Load", "file_path": "config.go"}},
+		{"git blame main.go:10-20", map[string]interface{}{"operation": "blame", "file_path": "main.go", "start_line": 10, "end_line": 20}},
+		{"why was this written this way in reader.go", map[string]interface{}{"operation": "history", "file_path": "reader.go"}},
+	}
+
+	for _, tt := range tests {
+		steps := gitHistorySteps(tt.input)
+		if len(steps) != 1 {
+			t.Errorf("%q: expected 1 step, got %d", tt.input, len(steps))
+			continue
+		}
+		if fmt.Sprint(steps[0].parameters) != fmt.Sprint(tt.params) {
+			t.Errorf("%q: parameters = %v, want %v", tt.input, steps[0].parameters, tt.params)
+		}
+	}
+
+	if steps := gitHistorySteps("explain the history of computing"); len(steps) != 0 {
+		t.Errorf("Expected no steps without a file, got %v", steps)
+	}
+}
+
+func TestGitPickaxeSteps(t *testing.T) {
+	tests := []struct {
+		input  string
+		params map[string]interface{}
+	}{
+		{"when was applyDefaults introduced?", map[string]interface{}{"term": "applyDefaults"}},
+		{"When did `Retry()` get removed from internal/llm?", map[string]interface{}{"term": "Retry", "path": "internal/llm"}},
+		{"applyDefaults 関数はいつ追加された？", map[string]interface{}{"term": "applyDefaults"}},
+	}
+
+	for _, tt := range tests {
+		steps := gitPickaxeSteps(tt.input)
+		if len(steps) != 1 {
+			t.Errorf("%q: expected 1 step, got %d", tt.input, len(steps))
+			continue
+		}
+		if fmt.Sprint(steps[0].parameters) != fmt.Sprint(tt.params) {
+			t.Errorf("%q: parameters = %v, want %v", tt.input, steps[0].parameters, tt.params)
+		}
+	}
+
+	if steps := gitPickaxeSteps("when was Go released"); len(steps) != 0 {
+		t.Errorf("Expected no steps without an introduce/remove verb, got %v", steps)
+	}
+}
+
 func TestRiskAssessment(t *testing.T) {
 	registry := NewUnifiedToolRegistry(security.NewDefaultConstraints("."), mcp.NewManager())
 	cfg := config.DefaultConfig()
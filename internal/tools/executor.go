@@ -4,7 +4,6 @@ import (
 	"bytes"
 	"context"
 	"fmt"
-	"os"
 	"os/exec"
 	"strings"
 	"time"
@@ -71,17 +70,13 @@ func (e *CommandExecutor) Execute(command string) (*ExecutionResult, error) {
 
 // 作業ディレクトリを変更
 func (e *CommandExecutor) ChangeWorkingDirectory(newDir string) error {
-	// セキュリティチェック：指定されたパスが許可されているか
-	if !e.constraints.IsPathAllowed(newDir) {
-		return fmt.Errorf("access denied: path outside workspace")
-	}
-
-	// ディレクトリが存在するかチェック
-	if _, err := os.Stat(newDir); os.IsNotExist(err) {
-		return fmt.Errorf("directory does not exist: %s", newDir)
+	// セキュリティチェック：ワークスペース内の存在するディレクトリか（シンボリックリンクは実体で判定）
+	resolved, err := e.constraints.ResolveWorkDir(newDir)
+	if err != nil {
+		return fmt.Errorf("access denied: %w", err)
 	}
 
-	e.workDir = newDir
+	e.workDir = resolved
 	return nil
 }
 
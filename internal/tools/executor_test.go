@@ -1,9 +1,12 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"os"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/glkt/vyb-code/internal/security"
 )
@@ -108,3 +111,68 @@ func TestCommandSecurity(t *testing.T) {
 		})
 	}
 }
+
+// TestBashToolExecuteContextCancel は呼び出し元のキャンセルで実行中のコマンドが停止することをテストする
+func TestBashToolExecuteContextCancel(t *testing.T) {
+	constraints := &security.Constraints{
+		AllowedCommands: []string{"sleep", "echo"},
+		MaxTimeout:      30,
+	}
+	bash := NewBashTool(constraints, t.TempDir())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(100*time.Millisecond, cancel)
+
+	start := time.Now()
+	result, err := bash.ExecuteContext(ctx, "sleep 10", "cancel test", 30000)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("context.Canceled を期待: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("キャンセル後もコマンドが継続: %v", elapsed)
+	}
+	if result == nil || !result.IsError || result.TimedOut {
+		t.Errorf("キャンセルはタイムアウトではないエラーとして扱うべき: %+v", result)
+	}
+
+	// タイムアウトはエラーを返さずTimedOutで通知
+	result, err = bash.ExecuteContext(context.Background(), "sleep 10", "timeout test", 100)
+	if err != nil {
+		t.Fatalf("タイムアウト時にエラーが返された: %v", err)
+	}
+	if !result.TimedOut {
+		t.Error("TimedOut が設定されていない")
+	}
+
+	result, err = bash.Execute("echo hello", "echo test", 0)
+	if err != nil || result.Content != "hello\n" {
+		t.Errorf("通常実行の出力が不正: %q, %v", result.Content, err)
+	}
+}
+
+// TestBashToolChainedCommands は && や | で繋いだコマンド・コマンド置換の中の禁止コマンドを実行しないことをテストする
+func TestBashToolChainedCommands(t *testing.T) {
+	workspace := t.TempDir()
+	bash := NewBashTool(security.NewDefaultConstraints(workspace), workspace)
+
+	for _, command := range []string{
+		"echo hi && sudo reboot",
+		"git status | sh",
+		"ls $(rm -rf ~)",
+		"echo ok; curl http://example.com",
+		"cat `rm -rf .`",
+		"ls || bash -c 'rm -rf ~'",
+		"echo hi > /etc/motd",
+		"git -c core.pager=sh log",
+	} {
+		result, err := bash.Execute(command, "chained", 5000)
+		if err == nil || result == nil || !result.IsError {
+			t.Errorf("%q should be refused: %+v", command, result)
+		}
+	}
+
+	result, err := bash.Execute("echo hello && echo world | wc -l", "allowed chain", 5000)
+	if err != nil || strings.Join(strings.Fields(result.Content), " ") != "hello 1" {
+		t.Errorf("allowed commands joined with && and | should run: %+v, %v", result, err)
+	}
+}
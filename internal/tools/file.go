@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/glkt/vyb-code/internal/ignore"
 )
 
 // FileOperations - 廃止予定: UnifiedFileOperationsを使用してください
@@ -77,7 +79,7 @@ func (f *FileOperations) SearchFiles(pattern string) ([]string, error) {
 	var matches []string
 
 	// 作業ディレクトリ以下を再帰的に走査
-	err := filepath.WalkDir(f.WorkDir, func(path string, d fs.DirEntry, err error) error {
+	err := ignore.WalkDir(f.WorkDir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return nil // エラーのあるファイルはスキップ
 		}
@@ -0,0 +1,413 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/journal"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// maxFileOpsFiles は1回の移動・削除で扱うファイルの上限（取り消し用に全ての内容をジャーナルに残すため）
+const maxFileOpsFiles = 1000
+
+// FileOpsTool - ファイル・ディレクトリの移動（リネーム）と削除
+// Git で追跡しているパスは git mv / git rm で変更し、変更前の内容をジャーナルに残して vyb refactor undo で取り消せるようにする
+type FileOpsTool struct {
+	constraints  *security.Constraints
+	workDir      string
+	journalDir   string
+	confirmation security.UserConfirmation // 削除の確認（nilなら削除しない）
+}
+
+func NewFileOpsTool(constraints *security.Constraints, workDir string) *FileOpsTool {
+	return &FileOpsTool{
+		constraints: constraints,
+		workDir:     workDir,
+		journalDir:  journal.DefaultDir(),
+	}
+}
+
+// SetPermissions はプロジェクト・ユーザーの許可規則を設定（nilで既定の制約のみ）
+func (f *FileOpsTool) SetPermissions(permissions *security.Permissions) {
+	f.constraints.Permissions = permissions
+}
+
+// SetConfirmation は削除の確認方法を設定（nilなら確認できないため削除を断る）
+func (f *FileOpsTool) SetConfirmation(confirmation security.UserConfirmation) {
+	f.confirmation = confirmation
+}
+
+// SetJournalDir は取り消し用のジャーナルの保存先を変更
+func (f *FileOpsTool) SetJournalDir(dir string) {
+	f.journalDir = dir
+}
+
+type MoveRequest struct {
+	Source      string `json:"source"`
+	Destination string `json:"destination"` // 既存のディレクトリならその中へ移動
+	Overwrite   bool   `json:"overwrite,omitempty"`
+}
+
+type DeleteRequest struct {
+	Path      string `json:"path"`
+	Recursive bool   `json:"recursive,omitempty"` // ディレクトリを中身ごと削除
+}
+
+// Move はファイル・ディレクトリを移動（リネーム）する
+func (f *FileOpsTool) Move(ctx context.Context, req MoveRequest) (*ToolExecutionResult, error) {
+	fail := func(message string, err error) (*ToolExecutionResult, error) {
+		return &ToolExecutionResult{Content: message, IsError: true, Tool: "move"}, err
+	}
+	if req.Source == "" || req.Destination == "" {
+		return fail("移動元と移動先を指定してください", fmt.Errorf("source and destination are required"))
+	}
+
+	source, err := f.resolve(req.Source, "delete")
+	if err != nil {
+		return fail(fmt.Sprintf("パスへのアクセスが許可されていません: %v", err), err)
+	}
+	sourceInfo, err := os.Lstat(source)
+	if err != nil {
+		return fail(fmt.Sprintf("移動元が存在しません: %s", req.Source), err)
+	}
+	destination, err := f.resolve(req.Destination, "create")
+	if err != nil {
+		return fail(fmt.Sprintf("パスへのアクセスが許可されていません: %v", err), err)
+	}
+	// 既存のディレクトリを指定した場合は mv と同じくその中へ移動
+	if info, err := os.Stat(destination); err == nil && info.IsDir() {
+		if destination, err = f.resolve(filepath.Join(destination, filepath.Base(source)), "create"); err != nil {
+			return fail(fmt.Sprintf("パスへのアクセスが許可されていません: %v", err), err)
+		}
+	}
+
+	switch {
+	case destination == source:
+		return fail("移動元と移動先が同じです", fmt.Errorf("source and destination are the same"))
+	case strings.HasPrefix(destination, source+string(filepath.Separator)):
+		return fail("ディレクトリを自身の中へは移動できません", fmt.Errorf("destination is inside source"))
+	}
+	overwriting := false
+	if info, err := os.Lstat(destination); err == nil {
+		if !req.Overwrite || info.IsDir() || sourceInfo.IsDir() {
+			return fail(fmt.Sprintf("移動先が既に存在します: %s（ファイルを置き換える場合は overwrite を指定）", req.Destination), fmt.Errorf("destination exists"))
+		}
+		if _, err := f.resolve(destination, "write"); err != nil {
+			return fail(fmt.Sprintf("パスへのアクセスが許可されていません: %v", err), err)
+		}
+		overwriting = true
+	}
+
+	files, err := collectFiles(source)
+	if err != nil {
+		return fail(err.Error(), err)
+	}
+	tx := journal.Begin(f.journalDir, fmt.Sprintf("move %s → %s", req.Source, req.Destination))
+	for _, file := range files {
+		rel, _ := filepath.Rel(source, file)
+		for _, path := range []string{file, filepath.Join(destination, rel)} {
+			if err := tx.Track(path); err != nil {
+				return fail(fmt.Sprintf("変更前の内容を記録できません: %v", err), err)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(destination), 0755); err != nil {
+		return fail(fmt.Sprintf("ディレクトリ作成エラー: %v", err), err)
+	}
+	useGit := gitTracked(ctx, source)
+	if useGit {
+		args := []string{"mv"}
+		if overwriting {
+			args = append(args, "-f")
+		}
+		_, err = runGit(ctx, source, append(args, "--", source, destination)...)
+	} else {
+		err = os.Rename(source, destination)
+	}
+	if err != nil {
+		return fail(fmt.Sprintf("移動エラー: %v", err), err)
+	}
+
+	return f.finish(tx, "move", fmt.Sprintf("%s → %s", req.Source, req.Destination), useGit, len(files), map[string]interface{}{
+		"source":      source,
+		"destination": destination,
+		"overwriting": overwriting,
+	}), nil
+}
+
+// Delete はファイル・ディレクトリを削除する（確認方法が設定されていなければ削除しない）
+func (f *FileOpsTool) Delete(ctx context.Context, req DeleteRequest) (*ToolExecutionResult, error) {
+	fail := func(message string, err error) (*ToolExecutionResult, error) {
+		return &ToolExecutionResult{Content: message, IsError: true, Tool: "delete"}, err
+	}
+	if req.Path == "" {
+		return fail("削除するパスを指定してください", fmt.Errorf("path is required"))
+	}
+
+	target, err := f.resolve(req.Path, "delete")
+	if err != nil {
+		return fail(fmt.Sprintf("パスへのアクセスが許可されていません: %v", err), err)
+	}
+	info, err := os.Lstat(target)
+	if err != nil {
+		return fail(fmt.Sprintf("削除するパスが存在しません: %s", req.Path), err)
+	}
+	if info.IsDir() && !req.Recursive {
+		return fail(fmt.Sprintf("%s はディレクトリです（中身ごと削除する場合は recursive を指定）", req.Path), fmt.Errorf("path is a directory"))
+	}
+
+	files, err := collectFiles(target)
+	if err != nil {
+		return fail(err.Error(), err)
+	}
+	useGit := gitTracked(ctx, target)
+
+	if f.confirmation == nil {
+		return fail(fmt.Sprintf("削除には確認が必要ですが、この環境では確認できないため削除しません: %s", req.Path), fmt.Errorf("deletion requires confirmation"))
+	}
+	details := fmt.Sprintf("%s（%d ファイル）", req.Path, len(files))
+	if useGit {
+		details += "、git rm で削除"
+	}
+	if !f.confirmation.ConfirmRiskyOperation("ファイル削除", details) {
+		return fail(fmt.Sprintf("削除はキャンセルされました: %s", req.Path), fmt.Errorf("deletion declined by user"))
+	}
+
+	tx := journal.Begin(f.journalDir, fmt.Sprintf("delete %s", req.Path))
+	for _, file := range files {
+		if err := tx.Track(file); err != nil {
+			return fail(fmt.Sprintf("変更前の内容を記録できません: %v", err), err)
+		}
+	}
+	if useGit {
+		if _, err := runGit(ctx, target, "rm", "-r", "-f", "-q", "--", target); err != nil {
+			return fail(fmt.Sprintf("削除エラー: %v", err), err)
+		}
+	}
+	// git rm は追跡していないファイルを残すため、残りもまとめて削除する
+	if err := os.RemoveAll(target); err != nil {
+		return fail(fmt.Sprintf("削除エラー: %v", err), err)
+	}
+
+	return f.finish(tx, "delete", req.Path, useGit, len(files), map[string]interface{}{
+		"path": target,
+	}), nil
+}
+
+// resolve はパスを作業ディレクトリ基準で解決し、ワークスペースのルート自体は対象にしない
+// シンボリックリンクは辿らずリンク自体を移動・削除するため、親ディレクトリまでを解決してワークスペース内か検証する
+func (f *FileOpsTool) resolve(path, operation string) (string, error) {
+	if !filepath.IsAbs(path) && f.workDir != "" {
+		path = filepath.Join(f.workDir, path)
+	}
+	path = filepath.Clean(path)
+	parent, err := resolveToolPath(f.constraints, f.workDir, filepath.Dir(path), operation)
+	if err != nil {
+		return "", err
+	}
+	resolved := filepath.Join(parent, filepath.Base(path))
+	if f.constraints.Permissions != nil {
+		if err := f.constraints.Permissions.CheckPath(resolved, operation); err != nil {
+			return "", err
+		}
+	}
+	if root, err := f.constraints.ResolvePath(f.constraints.WorkspaceDir); err == nil && resolved == root {
+		return "", fmt.Errorf("ワークスペースのルートは移動・削除できません")
+	}
+	return resolved, nil
+}
+
+// finish はジャーナルを確定して結果を作る（記録に失敗しても変更は済んでいるため成功として伝える）
+func (f *FileOpsTool) finish(tx *journal.Transaction, tool, target string, useGit bool, files int, metadata map[string]interface{}) *ToolExecutionResult {
+	action := map[string]string{"move": "移動", "delete": "削除"}[tool]
+	content := fmt.Sprintf("%sしました: %s（%d ファイル", action, target, files)
+	if useGit {
+		content += fmt.Sprintf("、git %s", map[string]string{"move": "mv", "delete": "rm"}[tool])
+	}
+	content += "）"
+	if err := tx.Commit(); err != nil {
+		content += fmt.Sprintf("\n⚠️ 取り消し用の記録に失敗しました: %v", err)
+	} else {
+		content += fmt.Sprintf("\n取り消し: vyb refactor undo %s", tx.ID)
+		metadata["journal_id"] = tx.ID
+	}
+	metadata["git"] = useGit
+	metadata["files"] = files
+	return &ToolExecutionResult{Content: content, IsError: false, Tool: tool, Metadata: metadata}
+}
+
+// collectFiles はパス配下の通常ファイルとシンボリックリンク（ファイル・リンクならそれ自身）を集める
+// シンボリックリンクの先は辿らない
+func collectFiles(root string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(root, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.Type().IsRegular() || entry.Type()&fs.ModeSymlink != 0 {
+			files = append(files, path)
+			if len(files) > maxFileOpsFiles {
+				return fmt.Errorf("ファイルが多すぎます（最大 %d）。より小さい単位で移動・削除してください", maxFileOpsFiles)
+			}
+		}
+		return nil
+	})
+	return files, err
+}
+
+// gitTracked はパス（ディレクトリなら配下のいずれか）を Git で追跡しているか
+func gitTracked(ctx context.Context, path string) bool {
+	_, err := runGit(ctx, path, "ls-files", "--error-unmatch", "--", path)
+	return err == nil
+}
+
+// UnifiedMoveTool - ファイル・ディレクトリを移動する統一ツール
+type UnifiedMoveTool struct {
+	*BaseTool
+	journalDir string
+}
+
+// NewUnifiedMoveTool - 新しい移動ツールを作成
+func NewUnifiedMoveTool(constraints *security.Constraints) *UnifiedMoveTool {
+	base := NewBaseTool("move", "ファイル・ディレクトリを移動（リネーム）します", "1.0.0", CategoryFile)
+	base.AddCapability(CapabilityFileWrite)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "move",
+		Description: "ファイル・ディレクトリを移動（リネーム）します。Git で追跡しているパスは git mv を使い、vyb refactor undo で取り消せます",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"source": {
+				Type:        "string",
+				Description: "移動元のパス",
+			},
+			"destination": {
+				Type:        "string",
+				Description: "移動先のパス（既存のディレクトリならその中へ移動）",
+			},
+			"overwrite": {
+				Type:        "boolean",
+				Description: "移動先の既存ファイルを置き換えるか（デフォルト：false）",
+				Default:     false,
+			},
+		},
+		Required: []string{"source", "destination"},
+		Examples: []ToolExample{
+			{
+				Description: "ファイルをリネームする",
+				Parameters: map[string]interface{}{
+					"source":      "internal/util/helpers.go",
+					"destination": "internal/util/strings.go",
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedMoveTool{BaseTool: base, journalDir: journal.DefaultDir()}
+}
+
+// Execute - 移動を実行
+func (t *UnifiedMoveTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	source, _ := request.Parameters["source"].(string)
+	destination, _ := request.Parameters["destination"].(string)
+	overwrite, _ := request.Parameters["overwrite"].(bool)
+
+	tool := newRegistryFileOpsTool(t.constraints, t.journalDir)
+	result, err := tool.Move(ctx, MoveRequest{Source: source, Destination: destination, Overwrite: overwrite})
+	if err != nil {
+		return nil, NewExecutionError(result.Content, -1)
+	}
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  result.Content,
+		Metadata: &ResponseMetadata{Debug: result.Metadata},
+	}, nil
+}
+
+// UnifiedDeleteTool - ファイル・ディレクトリを削除する統一ツール（端末で確認してから削除）
+type UnifiedDeleteTool struct {
+	*BaseTool
+	journalDir   string
+	confirmation security.UserConfirmation
+}
+
+// NewUnifiedDeleteTool - 新しい削除ツールを作成
+func NewUnifiedDeleteTool(constraints *security.Constraints) *UnifiedDeleteTool {
+	base := NewBaseTool("delete", "ファイル・ディレクトリを削除します（実行前に確認）", "1.0.0", CategoryFile)
+	base.AddCapability(CapabilityFileWrite)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "delete",
+		Description: "ユーザーの確認後にファイル・ディレクトリを削除します。Git で追跡しているパスは git rm を使い、vyb refactor undo で取り消せます",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"path": {
+				Type:        "string",
+				Description: "削除するパス",
+			},
+			"recursive": {
+				Type:        "boolean",
+				Description: "ディレクトリを中身ごと削除するか（デフォルト：false）",
+				Default:     false,
+			},
+		},
+		Required: []string{"path"},
+		Examples: []ToolExample{
+			{
+				Description: "使われなくなったファイルを削除する",
+				Parameters: map[string]interface{}{
+					"path": "internal/legacy/old_parser.go",
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedDeleteTool{BaseTool: base, journalDir: journal.DefaultDir(), confirmation: security.NewConsoleConfirmation()}
+}
+
+// SetConfirmation は削除の確認方法を設定（nilなら削除しない）
+func (t *UnifiedDeleteTool) SetConfirmation(confirmation security.UserConfirmation) {
+	t.confirmation = confirmation
+}
+
+// Execute - 削除を実行
+func (t *UnifiedDeleteTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	path, _ := request.Parameters["path"].(string)
+	recursive, _ := request.Parameters["recursive"].(bool)
+
+	tool := newRegistryFileOpsTool(t.constraints, t.journalDir)
+	tool.SetConfirmation(t.confirmation)
+	result, err := tool.Delete(ctx, DeleteRequest{Path: path, Recursive: recursive})
+	if err != nil {
+		return nil, NewExecutionError(result.Content, -1)
+	}
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  result.Content,
+		Metadata: &ResponseMetadata{Debug: result.Metadata},
+	}, nil
+}
+
+// newRegistryFileOpsTool は統一ツールから使う FileOpsTool（制約がなければ作業ディレクトリの既定の制約）
+func newRegistryFileOpsTool(constraints *security.Constraints, journalDir string) *FileOpsTool {
+	if constraints == nil {
+		constraints = security.NewDefaultConstraints(".")
+	}
+	tool := NewFileOpsTool(constraints, "")
+	tool.SetJournalDir(journalDir)
+	return tool
+}
@@ -1,9 +1,12 @@
 package tools
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
 	"testing"
+
+	"github.com/glkt/vyb-code/internal/security"
 )
 
 // TestFileOperations はファイル操作機能をテストする
@@ -92,3 +95,69 @@ func TestSecurityConstraints(t *testing.T) {
 		})
 	}
 }
+
+// TestFileToolsRejectSymlinkEscape はシンボリックリンク経由のワークスペース脱出を拒否することをテストする
+func TestFileToolsRejectSymlinkEscape(t *testing.T) {
+	workspace := t.TempDir()
+	outside := t.TempDir()
+	secret := filepath.Join(outside, "secret.txt")
+	if err := os.WriteFile(secret, []byte("secret"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(workspace, "escape")); err != nil {
+		t.Skipf("シンボリックリンクを作成できない環境: %v", err)
+	}
+
+	constraints := security.NewDefaultConstraints(workspace)
+	const maxSize = 1024 * 1024
+
+	if _, err := NewReadTool(constraints, workspace, maxSize).Read(ReadRequest{FilePath: "escape/secret.txt"}); err == nil {
+		t.Error("ReadTool: シンボリックリンク経由の読み込みが許可された")
+	}
+	if _, err := NewWriteTool(constraints, workspace, maxSize).Write(WriteRequest{FilePath: "escape/new.txt", Content: "x"}); err == nil {
+		t.Error("WriteTool: シンボリックリンク経由の書き込みが許可された")
+	}
+	if _, err := os.Stat(filepath.Join(outside, "new.txt")); err == nil {
+		t.Error("WriteTool: ワークスペース外にファイルが作成された")
+	}
+	if _, err := NewEditTool(constraints, workspace, maxSize).Edit(EditRequest{FilePath: "escape/secret.txt", OldString: "secret", NewString: "leaked"}); err == nil {
+		t.Error("EditTool: シンボリックリンク経由の編集が許可された")
+	}
+	if data, _ := os.ReadFile(secret); string(data) != "secret" {
+		t.Errorf("ワークスペース外のファイルが変更された: %q", data)
+	}
+
+	// ワークスペース内の相対パスは通常どおり扱える
+	if _, err := NewWriteTool(constraints, workspace, maxSize).Write(WriteRequest{FilePath: "ok.txt", Content: "hello"}); err != nil {
+		t.Errorf("ワークスペース内の書き込みが拒否された: %v", err)
+	}
+}
+
+func TestEditToolRejectsStaleContent(t *testing.T) {
+	workspace := t.TempDir()
+	path := filepath.Join(workspace, "a.go")
+	base := "package a\n\nfunc A() {}\n"
+	if err := os.WriteFile(path, []byte(base), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewEditTool(security.NewDefaultConstraints(workspace), workspace, 1024*1024)
+
+	// 編集を作った後に別の変更が入った
+	current := base + "\nfunc B() {}\n"
+	if err := os.WriteFile(path, []byte(current), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := tool.Edit(EditRequest{FilePath: "a.go", OldString: "func A() {}", NewString: "func A() { panic(1) }", ExpectedHash: ContentHash(base)})
+	var stale *StaleFileError
+	if !errors.As(err, &stale) || stale.Current != current || stale.ActualHash != ContentHash(current) {
+		t.Fatalf("expected a StaleFileError, got %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != current {
+		t.Errorf("stale edit modified the file: %q", data)
+	}
+
+	// 現在の内容のハッシュなら適用する
+	if _, err := tool.Edit(EditRequest{FilePath: "a.go", OldString: "func A() {}", NewString: "func A() { panic(1) }", ExpectedHash: ContentHash(current)}); err != nil {
+		t.Errorf("edit with the current hash failed: %v", err)
+	}
+}
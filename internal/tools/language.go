@@ -330,6 +330,11 @@ func NewLanguageManager() *LanguageManager {
 	manager.RegisterLanguage(&CppLanguageSupport{})
 	manager.RegisterLanguage(&CLanguageSupport{})
 
+	// 言語パック（同名の組み込み言語は置き換える）
+	for _, pack := range LanguagePacks() {
+		manager.RegisterLanguage(&packLanguageSupport{pack: pack})
+	}
+
 	return manager
 }
 
@@ -375,6 +380,13 @@ func (lm *LanguageManager) DetectProjectLanguages(projectDir string) ([]Language
 		return nil, err
 	}
 
+	// ソースファイルが無くてもマニフェスト等で検出できる言語（言語パック）
+	for name, lang := range lm.languages {
+		if detector, ok := lang.(projectDetector); ok && langCounts[name] == 0 && detector.DetectProject(projectDir) {
+			langCounts[name] = 1
+		}
+	}
+
 	// ファイル数が多い言語を主要言語として選択
 	for langName, count := range langCounts {
 		if count > 0 { // 少なくとも1つのファイルがある言語
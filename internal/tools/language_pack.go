@@ -0,0 +1,173 @@
+package tools
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LanguagePack はプロジェクト分析に言語を追加する拡張
+//
+// 言語毎に独立したパッケージで実装し、init で RegisterLanguagePack を呼んで登録する
+// （組み込みは internal/langpacks 配下）。プロジェクト外の言語パックは plugin.yaml の
+// languages（宣言的な定義）か Go 拡張の Host.RegisterLanguagePack で追加できる
+type LanguagePack interface {
+	Name() string
+	Extensions() []string // ソースファイルの拡張子（".rb" 等）
+	ManifestFile() string // 依存関係を記述するファイル（Gemfile 等）
+	// Detect はソースファイルが無くてもプロジェクトがこの言語を使うか判定する（マニフェストの有無等）
+	Detect(projectDir string) bool
+	ParseManifest(content []byte) (*PackageManifest, error)
+	BuildCommand() string
+	TestCommand() string
+}
+
+// LanguagePackLinter は lint コマンドを持つ言語パック（任意）
+type LanguagePackLinter interface {
+	LintCommand() string
+}
+
+// PackageManifest はマニフェストから読み取ったパッケージの情報
+type PackageManifest struct {
+	Name         string   `json:"name,omitempty"`
+	Version      string   `json:"version,omitempty"`
+	Dependencies []string `json:"dependencies"`
+}
+
+var (
+	languagePacksMu sync.RWMutex
+	languagePacks   = make(map[string]LanguagePack)
+)
+
+// RegisterLanguagePack は言語パックを登録する（同名の言語は組み込みも含めて置き換える）
+// 登録後に作成した LanguageManager から使われる
+func RegisterLanguagePack(pack LanguagePack) {
+	if pack == nil || pack.Name() == "" {
+		panic("tools: 名前の無い言語パックは登録できません")
+	}
+	languagePacksMu.Lock()
+	defer languagePacksMu.Unlock()
+	languagePacks[pack.Name()] = pack
+}
+
+// LanguagePacks は登録済みの言語パックを名前順に返す
+func LanguagePacks() []LanguagePack {
+	languagePacksMu.RLock()
+	defer languagePacksMu.RUnlock()
+	packs := make([]LanguagePack, 0, len(languagePacks))
+	for _, pack := range languagePacks {
+		packs = append(packs, pack)
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name() < packs[j].Name() })
+	return packs
+}
+
+// packLanguageSupport は言語パックを LanguageSupport として使うためのアダプター
+type packLanguageSupport struct {
+	pack LanguagePack
+}
+
+func (p *packLanguageSupport) GetName() string           { return p.pack.Name() }
+func (p *packLanguageSupport) GetExtensions() []string   { return p.pack.Extensions() }
+func (p *packLanguageSupport) GetBuildCommand() string   { return p.pack.BuildCommand() }
+func (p *packLanguageSupport) GetTestCommand() string    { return p.pack.TestCommand() }
+func (p *packLanguageSupport) GetDependencyFile() string { return p.pack.ManifestFile() }
+
+func (p *packLanguageSupport) GetLintCommand() string {
+	if linter, ok := p.pack.(LanguagePackLinter); ok {
+		return linter.LintCommand()
+	}
+	return ""
+}
+
+func (p *packLanguageSupport) ParseDependencies(content string) []string {
+	manifest, err := p.pack.ParseManifest([]byte(content))
+	if err != nil || manifest == nil {
+		return nil
+	}
+	return manifest.Dependencies
+}
+
+// DetectProject はマニフェスト等からプロジェクトがこの言語を使うか判定する
+func (p *packLanguageSupport) DetectProject(projectDir string) bool {
+	return p.pack.Detect(projectDir)
+}
+
+// projectDetector はソースファイル以外でも言語を検出できる LanguageSupport
+type projectDetector interface {
+	DetectProject(projectDir string) bool
+}
+
+// DeclarativeLanguagePack は設定ファイル（plugin.yaml の languages）で定義する言語パック
+type DeclarativeLanguagePack struct {
+	Language          string   `yaml:"name" json:"name"`
+	FileExtensions    []string `yaml:"extensions" json:"extensions"`
+	Manifest          string   `yaml:"manifest,omitempty" json:"manifest,omitempty"`
+	DependencyPattern string   `yaml:"dependency_pattern,omitempty" json:"dependency_pattern,omitempty"` // マニフェストの各行に適用し、1番目のグループを依存名とする正規表現
+	Build             string   `yaml:"build,omitempty" json:"build,omitempty"`
+	Test              string   `yaml:"test,omitempty" json:"test,omitempty"`
+	Lint              string   `yaml:"lint,omitempty" json:"lint,omitempty"`
+
+	dependencyPattern *regexp.Regexp
+}
+
+// Validate は定義を検証し、依存関係の正規表現をコンパイルする
+func (d *DeclarativeLanguagePack) Validate() error {
+	if d.Language == "" {
+		return fmt.Errorf("言語パックには name が必要です")
+	}
+	if len(d.FileExtensions) == 0 && d.Manifest == "" {
+		return fmt.Errorf("言語パック %s には extensions か manifest が必要です", d.Language)
+	}
+	for i, ext := range d.FileExtensions {
+		if !strings.HasPrefix(ext, ".") {
+			d.FileExtensions[i] = "." + ext
+		}
+	}
+	if d.DependencyPattern != "" {
+		pattern, err := regexp.Compile("(?m)" + d.DependencyPattern)
+		if err != nil {
+			return fmt.Errorf("言語パック %s の dependency_pattern が不正です: %w", d.Language, err)
+		}
+		if pattern.NumSubexp() < 1 {
+			return fmt.Errorf("言語パック %s の dependency_pattern には依存名のグループが必要です", d.Language)
+		}
+		d.dependencyPattern = pattern
+	}
+	return nil
+}
+
+func (d *DeclarativeLanguagePack) Name() string         { return d.Language }
+func (d *DeclarativeLanguagePack) Extensions() []string { return d.FileExtensions }
+func (d *DeclarativeLanguagePack) ManifestFile() string { return d.Manifest }
+func (d *DeclarativeLanguagePack) BuildCommand() string { return d.Build }
+func (d *DeclarativeLanguagePack) TestCommand() string  { return d.Test }
+func (d *DeclarativeLanguagePack) LintCommand() string  { return d.Lint }
+
+func (d *DeclarativeLanguagePack) Detect(projectDir string) bool {
+	if d.Manifest == "" {
+		return false
+	}
+	_, err := os.Stat(filepath.Join(projectDir, d.Manifest))
+	return err == nil
+}
+
+func (d *DeclarativeLanguagePack) ParseManifest(content []byte) (*PackageManifest, error) {
+	manifest := &PackageManifest{}
+	if d.dependencyPattern == nil {
+		return manifest, nil
+	}
+	seen := make(map[string]bool)
+	for _, match := range d.dependencyPattern.FindAllSubmatch(content, -1) {
+		name := string(match[1])
+		if name != "" && !seen[name] {
+			seen[name] = true
+			manifest.Dependencies = append(manifest.Dependencies, name)
+		}
+	}
+	return manifest, nil
+}
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// fakePack はマニフェストだけで検出する言語パック
+type fakePack struct{ name string }
+
+func (p fakePack) Name() string         { return p.name }
+func (p fakePack) Extensions() []string { return []string{".fake"} }
+func (p fakePack) ManifestFile() string { return "fake.toml" }
+func (p fakePack) BuildCommand() string { return "fake build" }
+func (p fakePack) TestCommand() string  { return "fake test" }
+
+func (p fakePack) Detect(projectDir string) bool {
+	_, err := os.Stat(filepath.Join(projectDir, "fake.toml"))
+	return err == nil
+}
+
+func (p fakePack) ParseManifest(content []byte) (*PackageManifest, error) {
+	return &PackageManifest{Dependencies: []string{string(content)}}, nil
+}
+
+func TestRegisterLanguagePack(t *testing.T) {
+	RegisterLanguagePack(fakePack{name: "FakeLang"})
+	defer func() {
+		languagePacksMu.Lock()
+		delete(languagePacks, "FakeLang")
+		languagePacksMu.Unlock()
+	}()
+
+	manager := NewLanguageManager()
+	lang := manager.DetectLanguage("main.fake")
+	if lang == nil || lang.GetName() != "FakeLang" || lang.GetLintCommand() != "" {
+		t.Fatalf("登録した言語パックで検出できるはず: %v", lang)
+	}
+	if deps := lang.ParseDependencies("dep"); len(deps) != 1 || deps[0] != "dep" {
+		t.Errorf("dependencies = %v", deps)
+	}
+
+	// ソースファイルが無くてもマニフェストで検出する
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "fake.toml"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	languages, err := manager.DetectProjectLanguages(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(languages) != 1 || languages[0].GetName() != "FakeLang" {
+		t.Errorf("languages = %v", languages)
+	}
+
+	// 同名の言語パックは組み込みを置き換える
+	RegisterLanguagePack(fakePack{name: "Go"})
+	defer func() {
+		languagePacksMu.Lock()
+		delete(languagePacks, "Go")
+		languagePacksMu.Unlock()
+	}()
+	if lang := NewLanguageManager().DetectLanguage("main.go"); lang != nil {
+		t.Errorf("組み込みの Go は置き換わるはず: %v", lang.GetName())
+	}
+}
+
+func TestDeclarativeLanguagePack_Validate(t *testing.T) {
+	pack := &DeclarativeLanguagePack{Language: "Elixir", FileExtensions: []string{"ex"}, DependencyPattern: `\{:(\w+),`}
+	if err := pack.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	if pack.Extensions()[0] != ".ex" {
+		t.Errorf("拡張子にはドットを補うはず: %v", pack.Extensions())
+	}
+	for _, invalid := range []*DeclarativeLanguagePack{
+		{FileExtensions: []string{".ex"}},
+		{Language: "Elixir"},
+		{Language: "Elixir", FileExtensions: []string{".ex"}, DependencyPattern: `\{:\w+,`},
+	} {
+		if err := invalid.Validate(); err == nil {
+			t.Errorf("不正な定義はエラーのはず: %+v", invalid)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+)
+
+// 大きなファイル・バイナリファイルの扱い
+const (
+	largeFileThreshold   = 256 * 1024       // 範囲指定なしでこれを超えるファイルは全文ではなく概要を返す
+	maxWriteContentBytes = 10 * 1024 * 1024 // write 1回で書き込める内容の上限（超える場合は追記で分割する）
+	defaultReadLines     = 2000             // 範囲指定なしで返す最大行数
+	maxLineBytes         = 2000             // 1行の最大長（ミニファイされたファイル等は切り詰める）
+	binarySniffBytes     = 8000             // バイナリ判定で調べる先頭のバイト数
+	summaryHeadLines     = 30               // 概要に含める先頭の行数
+	summaryMaxOutline    = 80               // 概要に含める宣言の最大数
+)
+
+// outlinePattern は概要に載せるトップレベルの宣言（主要言語の関数・型・クラス等）
+var outlinePattern = regexp.MustCompile(`^(?:func|type|var|const|package|class|def|async def|function|async function|export|interface|struct|enum|impl|trait|fn|pub|module|public|private|protected)\b`)
+
+// sniffBinary はファイルの先頭にNULバイトを含むかでバイナリを判定
+func sniffBinary(path string) (bool, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer file.Close()
+
+	head := make([]byte, binarySniffBytes)
+	n, err := io.ReadFull(file, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return false, err
+	}
+	return bytes.IndexByte(head[:n], 0) >= 0, nil
+}
+
+// binaryFileGuidance はバイナリファイルの読み取りを断る際の案内
+func binaryFileGuidance(path string, size int64) string {
+	return fmt.Sprintf("%s is a binary file (%s) and cannot be read as text. "+
+		"Inspect it with bash instead (e.g. `file %s`, `xxd %s | head`, `strings %s | head`), "+
+		"or read the source it was generated from.",
+		path, formatByteSize(size), path, path, path)
+}
+
+// writeTooLargeGuidance は書き込み内容が上限を超えた際の案内
+func writeTooLargeGuidance(size, limit int64) string {
+	return fmt.Sprintf("content is %s, above the %s limit for a single write. "+
+		"Write the first part (up to %s), then add the rest in further calls with append mode, "+
+		"or generate the file with a command instead of passing its content.",
+		formatByteSize(size), formatByteSize(limit), formatByteSize(limit))
+}
+
+// lineRange はファイルから読み取った行の範囲
+type lineRange struct {
+	Lines      []string
+	Start      int  // 最初の行の番号（1始まり）
+	TotalLines int  // ファイル全体の行数
+	Truncated  bool // 長すぎる行を切り詰めた
+}
+
+// readLineRange はファイルを先頭から流し読みし、start 行目（1始まり）から limit 行を返す
+// ファイル全体をメモリに載せず、長い行も maxLineBytes で切り詰めて読み進める
+func readLineRange(path string, start, limit int) (*lineRange, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	if start < 1 {
+		start = 1
+	}
+	result := &lineRange{Start: start}
+	reader := bufio.NewReader(file)
+	for {
+		line, truncated, err := readLimitedLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		result.TotalLines++
+		if result.TotalLines >= start && (limit <= 0 || len(result.Lines) < limit) {
+			result.Lines = append(result.Lines, line)
+			result.Truncated = result.Truncated || truncated
+		}
+	}
+	return result, nil
+}
+
+// readLimitedLine は1行を読み、maxLineBytes を超える部分は読み捨てる
+func readLimitedLine(reader *bufio.Reader) (string, bool, error) {
+	var line []byte
+	truncated := false
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		if len(line) < maxLineBytes {
+			remaining := maxLineBytes - len(line)
+			if len(chunk) > remaining {
+				line = append(line, chunk[:remaining]...)
+				truncated = true
+			} else {
+				line = append(line, chunk...)
+			}
+		} else if len(chunk) > 0 && !(len(chunk) == 1 && chunk[0] == '\n') {
+			truncated = true
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF && len(line) > 0 {
+			err = nil
+		}
+		text := strings.TrimRight(string(line), "\r\n")
+		if truncated {
+			text = strings.ToValidUTF8(text, "") + " …(line truncated)"
+		}
+		return text, truncated, err
+	}
+}
+
+// formatNumberedLines は行番号付きの形式（"%5d→"）に整形
+func formatNumberedLines(lines []string, start int) string {
+	var result strings.Builder
+	for i, line := range lines {
+		result.WriteString(fmt.Sprintf("%5d→%s\n", start+i, line))
+	}
+	return result.String()
+}
+
+// summarizeLargeFile は大きなファイルの概要（サイズ・行数・先頭・トップレベルの宣言と行番号）を作成
+// 範囲を指定して読み直すための案内を付ける
+func summarizeLargeFile(path string, size int64) (string, int, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", 0, err
+	}
+	defer file.Close()
+
+	var head []string
+	var outline []string
+	omitted := 0
+	totalLines := 0
+	reader := bufio.NewReader(file)
+	for {
+		line, _, err := readLimitedLine(reader)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", 0, err
+		}
+		totalLines++
+		if len(head) < summaryHeadLines {
+			head = append(head, line)
+		}
+		if outlinePattern.MatchString(line) {
+			if len(outline) < summaryMaxOutline {
+				outline = append(outline, fmt.Sprintf("%5d→%s", totalLines, truncateLine(line, 120)))
+			} else {
+				omitted++
+			}
+		}
+	}
+
+	var summary strings.Builder
+	summary.WriteString(fmt.Sprintf("%s is large (%s, %d lines), so only a summary is shown. "+
+		"Read specific parts with offset/limit (up to %d lines per call).\n\n",
+		filepath.Base(path), formatByteSize(size), totalLines, defaultReadLines))
+	if len(outline) > 0 {
+		summary.WriteString("Top-level declarations:\n")
+		summary.WriteString(strings.Join(outline, "\n"))
+		summary.WriteString("\n")
+		if omitted > 0 {
+			summary.WriteString(fmt.Sprintf("  … %d more\n", omitted))
+		}
+		summary.WriteString("\n")
+	}
+	summary.WriteString(fmt.Sprintf("First %d lines:\n", len(head)))
+	summary.WriteString(formatNumberedLines(head, 1))
+	return summary.String(), totalLines, nil
+}
+
+// truncateLine は行を最大 limit 文字に切り詰める
+func truncateLine(line string, limit int) string {
+	if utf8.RuneCountInString(line) <= limit {
+		return line
+	}
+	return string([]rune(line)[:limit]) + "…"
+}
+
+// writeFileStreaming は r の内容をファイルに流し込む
+// 上書きは同じディレクトリの一時ファイルに書いてから置き換え、途中で失敗しても元の内容を壊さない
+func writeFileStreaming(path string, r io.Reader, appendMode bool) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return 0, err
+	}
+
+	if appendMode {
+		file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0644)
+		if err != nil {
+			return 0, err
+		}
+		written, err := io.Copy(file, r)
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+		return written, err
+	}
+
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	temp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return 0, err
+	}
+	written, err := io.Copy(temp, r)
+	if closeErr := temp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Chmod(temp.Name(), mode)
+	}
+	if err == nil {
+		err = os.Rename(temp.Name(), path)
+	}
+	if err != nil {
+		os.Remove(temp.Name())
+		return 0, err
+	}
+	return written, nil
+}
+
+// formatByteSize はバイト数を読みやすい単位で表す
+func formatByteSize(size int64) string {
+	switch {
+	case size >= 1024*1024:
+		return fmt.Sprintf("%.1fMB", float64(size)/(1024*1024))
+	case size >= 1024:
+		return fmt.Sprintf("%.1fKB", float64(size)/1024)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
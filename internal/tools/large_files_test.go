@@ -0,0 +1,127 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+func TestReadLineRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "min.js")
+	long := strings.Repeat("x", maxLineBytes*3)
+	if err := os.WriteFile(path, []byte("first\n"+long+"\nthird\nfourth"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	chunk, err := readLineRange(path, 2, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.TotalLines != 4 || len(chunk.Lines) != 2 || chunk.Start != 2 || !chunk.Truncated {
+		t.Fatalf("chunk = %+v", chunk)
+	}
+	if !strings.HasSuffix(chunk.Lines[0], "…(line truncated)") || len(chunk.Lines[0]) > maxLineBytes+32 {
+		t.Errorf("長い行は切り詰めるはず: %d bytes", len(chunk.Lines[0]))
+	}
+	if chunk.Lines[1] != "third" {
+		t.Errorf("長い行の後も読み進めるはず: %q", chunk.Lines[1])
+	}
+}
+
+func TestUnifiedReadTool_LargeAndBinaryFiles(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewUnifiedReadTool(security.NewDefaultConstraints(dir))
+	read := func(params map[string]interface{}) (*ToolResponse, error) {
+		return tool.Execute(context.Background(), &ToolRequest{ID: "r", ToolName: "read", Parameters: params})
+	}
+
+	binary := filepath.Join(dir, "app.bin")
+	if err := os.WriteFile(binary, []byte{0x7f, 'E', 'L', 'F', 0, 0, 1}, 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := read(map[string]interface{}{"file_path": binary}); err == nil || !strings.Contains(err.Error(), "binary file") {
+		t.Errorf("バイナリは案内付きで拒否するはず: %v", err)
+	}
+
+	var large strings.Builder
+	for i := 0; large.Len() <= largeFileThreshold; i++ {
+		fmt.Fprintf(&large, "func Handler%d() {\n\treturn\n}\n", i)
+	}
+	largePath := filepath.Join(dir, "large.go")
+	if err := os.WriteFile(largePath, []byte(large.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// 範囲指定なしなら概要（宣言と行番号）を返す
+	response, err := read(map[string]interface{}{"file_path": largePath})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(response.Content, "only a summary is shown") || !strings.Contains(response.Content, "4→func Handler1()") ||
+		response.Metadata.Debug["summarized"] != true {
+		t.Errorf("summary = %.300s", response.Content)
+	}
+
+	// 範囲指定なら該当行を返し、続きの読み方を示す
+	response, err = read(map[string]interface{}{"file_path": largePath, "offset": float64(3), "limit": float64(3)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(response.Content, "4→func Handler1()") || !strings.Contains(response.Content, "continue with offset=6") {
+		t.Errorf("range = %s", response.Content)
+	}
+}
+
+func TestUnifiedWriteTool_AppendAndLimit(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewUnifiedWriteTool(security.NewDefaultConstraints(dir))
+	path := filepath.Join(dir, "gen", "data.csv")
+	write := func(content string, appendMode bool) error {
+		_, err := tool.Execute(context.Background(), &ToolRequest{ID: "w", ToolName: "write", Parameters: map[string]interface{}{
+			"file_path": path, "content": content, "append": appendMode,
+		}})
+		return err
+	}
+
+	if err := write("a,b\n", false); err != nil {
+		t.Fatal(err)
+	}
+	if err := write("1,2\n", true); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "a,b\n1,2\n" {
+		t.Errorf("追記後の内容 = %q", data)
+	}
+
+	err := write(strings.Repeat("x", maxWriteContentBytes+1), false)
+	if err == nil || !strings.Contains(err.Error(), "append mode") {
+		t.Errorf("上限超過は分割の案内付きで失敗するはず: %v", err)
+	}
+	if data, _ := os.ReadFile(path); string(data) != "a,b\n1,2\n" {
+		t.Error("失敗した書き込みで既存の内容が変わらないはず")
+	}
+}
+
+func TestWriteTool_TooLargeGuidance(t *testing.T) {
+	dir := t.TempDir()
+	tool := NewWriteTool(security.NewDefaultConstraints(dir), dir, 8)
+
+	result, err := tool.Write(WriteRequest{FilePath: "out.txt", Content: "0123456789"})
+	if err == nil || !result.IsError || !strings.Contains(result.Content, "append mode") {
+		t.Fatalf("result = %+v, err = %v", result, err)
+	}
+	if _, err := tool.Write(WriteRequest{FilePath: "out.txt", Content: "01234"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tool.Write(WriteRequest{FilePath: "out.txt", Content: "56789", Append: true}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "out.txt")); string(data) != "0123456789" {
+		t.Errorf("分割して書き込んだ内容 = %q", data)
+	}
+}
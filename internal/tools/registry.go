@@ -446,6 +446,10 @@ func (r *ToolRegistry) registerClaudeTools() {
 					"type":        "string",
 					"description": "書き込む内容",
 				},
+				"append": map[string]interface{}{
+					"type":        "boolean",
+					"description": "既存の内容の末尾に追記（大きな内容を分割して書き込む）",
+				},
 			},
 			"required": []string{"file_path", "content"},
 		},
@@ -847,6 +851,10 @@ func (r *ToolRegistry) handleWriteTool(arguments map[string]interface{}) (interf
 		return nil, fmt.Errorf("content引数が必要です")
 	}
 
+	if appendMode, ok := arguments["append"].(bool); ok {
+		req.Append = appendMode
+	}
+
 	result, err := r.writeTool.Write(req)
 	if err != nil {
 		return nil, err
@@ -0,0 +1,476 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/glkt/vyb-code/internal/contextmanager"
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// バッチ読み取りの既定値
+const (
+	defaultBatchReadFileBytes   = 64 * 1024  // ファイル毎の最大読み取りサイズ
+	defaultBatchReadTotalBytes  = 512 * 1024 // 全体の最大読み取りサイズ
+	defaultBatchReadConcurrency = 8          // 同時読み取り数
+	batchReadMaxFiles           = 200        // 展開後の最大ファイル数
+)
+
+// globSkipDirs は ** 展開時に除外パターン（ignore パッケージ）に加えて辿らないディレクトリ
+var globSkipDirs = map[string]bool{
+	".vscode": true,
+}
+
+// BatchReadFile - バッチ読み取りした1ファイルの結果
+type BatchReadFile struct {
+	Path      string `json:"path"`
+	Content   string `json:"content,omitempty"`
+	Size      int64  `json:"size"`
+	Lines     int    `json:"lines"`
+	Truncated bool   `json:"truncated"`
+	Error     string `json:"error,omitempty"`
+}
+
+// BatchReadBundle - バッチ読み取り結果（コンテキスト管理に渡せる構造化データ）
+type BatchReadBundle struct {
+	Files      []BatchReadFile `json:"files"`
+	TotalBytes int64           `json:"total_bytes"`
+	Skipped    []string        `json:"skipped,omitempty"` // 全体予算超過で読まなかったファイル
+	Unmatched  []string        `json:"unmatched,omitempty"`
+}
+
+// ContextItems - 読み取ったファイルをコンテキスト項目に変換
+func (b *BatchReadBundle) ContextItems() []*contextmanager.ContextItem {
+	var items []*contextmanager.ContextItem
+	now := time.Now()
+	for _, file := range b.Files {
+		if file.Error != "" || file.Content == "" {
+			continue
+		}
+		items = append(items, &contextmanager.ContextItem{
+			Type:       contextmanager.ContextTypeShortTerm,
+			Content:    fmt.Sprintf("File: %s\n%s", file.Path, file.Content),
+			Importance: 0.6,
+			Timestamp:  now,
+			LastAccess: now,
+			Metadata: map[string]string{
+				"file":         file.Path,
+				"file_type":    strings.TrimPrefix(filepath.Ext(file.Path), "."),
+				"content_type": "file_content",
+			},
+		})
+	}
+	return items
+}
+
+// UnifiedBatchReadTool - 複数ファイルを並行して読み取るツール
+type UnifiedBatchReadTool struct {
+	*BaseTool
+}
+
+// NewUnifiedBatchReadTool - 新しいバッチ読み取りツールを作成
+func NewUnifiedBatchReadTool(constraints *security.Constraints) *UnifiedBatchReadTool {
+	base := NewBaseTool("batch_read", "複数のファイルをまとめて読み取ります", "1.0.0", CategoryFile)
+	base.AddCapability(CapabilityFileRead)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "batch_read",
+		Description: "パスまたはGlobパターンのリストに一致するファイルを並行して読み取り、まとめて返します",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"paths": {
+				Type:        "array",
+				Description: "読み取るファイルのパスまたはGlobパターン（'**' 対応）",
+			},
+			"max_file_bytes": {
+				Type:        "integer",
+				Description: "ファイル毎の最大読み取りバイト数（省略可）",
+				Minimum:     floatPtr(1),
+			},
+			"max_total_bytes": {
+				Type:        "integer",
+				Description: "全体の最大読み取りバイト数（省略可）",
+				Minimum:     floatPtr(1),
+			},
+			"concurrency": {
+				Type:        "integer",
+				Description: "同時に読み取るファイル数（省略可）",
+				Minimum:     floatPtr(1),
+			},
+		},
+		Required: []string{"paths"},
+		Examples: []ToolExample{
+			{
+				Description: "Goのソースと設定ファイルをまとめて読み取り",
+				Parameters: map[string]interface{}{
+					"paths": []interface{}{"internal/**/*.go", "go.mod"},
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedBatchReadTool{BaseTool: base}
+}
+
+// Execute - バッチ読み取り実行
+func (t *UnifiedBatchReadTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	patterns := stringListParam(request.Parameters["paths"])
+	if len(patterns) == 0 {
+		return nil, NewToolError("invalid_parameter", "paths parameter is required")
+	}
+
+	maxFileBytes := int64(intParam(request.Parameters, "max_file_bytes", defaultBatchReadFileBytes))
+	maxTotalBytes := int64(intParam(request.Parameters, "max_total_bytes", defaultBatchReadTotalBytes))
+	concurrency := intParam(request.Parameters, "concurrency", defaultBatchReadConcurrency)
+
+	paths, unmatched, err := t.expandPaths(patterns)
+	if err != nil {
+		return nil, err
+	}
+
+	bundle := t.readFiles(ctx, paths, maxFileBytes, maxTotalBytes, concurrency)
+	bundle.Unmatched = unmatched
+
+	if err := ctx.Err(); err != nil {
+		return nil, NewToolError("cancelled", "Batch read cancelled: "+err.Error())
+	}
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  formatBatchReadBundle(bundle),
+		Data:     bundle,
+		Metadata: &ResponseMetadata{
+			Debug: map[string]interface{}{
+				"file_count":  len(bundle.Files),
+				"total_bytes": bundle.TotalBytes,
+				"skipped":     len(bundle.Skipped),
+			},
+		},
+	}, nil
+}
+
+// expandPaths - パス・Globパターンを重複のないファイル一覧に展開
+func (t *UnifiedBatchReadTool) expandPaths(patterns []string) ([]string, []string, error) {
+	seen := make(map[string]bool)
+	var paths, unmatched []string
+
+	for _, pattern := range patterns {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if strings.Contains(pattern, "..") {
+			return nil, nil, NewToolError("security_violation", "Path contains invalid characters: "+pattern)
+		}
+
+		var matches []string
+		switch {
+		case strings.Contains(pattern, "**"):
+			found, err := globDoubleStar(pattern)
+			if err != nil {
+				return nil, nil, NewToolError("invalid_parameter", fmt.Sprintf("Invalid glob pattern %s: %v", pattern, err))
+			}
+			matches = found
+		case strings.ContainsAny(pattern, "*?["):
+			found, err := filepath.Glob(pattern)
+			if err != nil {
+				return nil, nil, NewToolError("invalid_parameter", fmt.Sprintf("Invalid glob pattern %s: %v", pattern, err))
+			}
+			matches = found
+		default:
+			matches = []string{pattern}
+		}
+
+		matched := false
+		for _, match := range matches {
+			clean := filepath.Clean(match)
+			if info, err := os.Stat(clean); err == nil && info.IsDir() {
+				continue
+			}
+			if t.constraints != nil && !t.constraints.IsPathAllowed(clean) {
+				continue
+			}
+			matched = true
+			if !seen[clean] {
+				seen[clean] = true
+				paths = append(paths, clean)
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, pattern)
+		}
+	}
+
+	sort.Strings(paths)
+	if len(paths) > batchReadMaxFiles {
+		return nil, nil, NewToolError("limit_exceeded", fmt.Sprintf("Too many files matched: %d (max %d)", len(paths), batchReadMaxFiles))
+	}
+	return paths, unmatched, nil
+}
+
+// readFiles - ワーカープールでファイルを並行読み取りし、全体予算を入力順に適用
+func (t *UnifiedBatchReadTool) readFiles(ctx context.Context, paths []string, maxFileBytes, maxTotalBytes int64, concurrency int) *BatchReadBundle {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	results := make([]BatchReadFile, len(paths))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+
+	for w := 0; w < concurrency && w < len(paths); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = readFileWithLimit(paths[i], maxFileBytes)
+			}
+		}()
+	}
+
+feed:
+	for i := range paths {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	bundle := &BatchReadBundle{Files: make([]BatchReadFile, 0, len(paths))}
+	for i, file := range results {
+		if file.Path == "" {
+			// キャンセルで未処理
+			bundle.Skipped = append(bundle.Skipped, paths[i])
+			continue
+		}
+		if file.Error == "" {
+			remaining := maxTotalBytes - bundle.TotalBytes
+			if remaining <= 0 {
+				bundle.Skipped = append(bundle.Skipped, file.Path)
+				continue
+			}
+			if int64(len(file.Content)) > remaining {
+				file.Content = strings.ToValidUTF8(file.Content[:remaining], "")
+				file.Truncated = true
+			}
+			bundle.TotalBytes += int64(len(file.Content))
+		}
+		bundle.Files = append(bundle.Files, file)
+	}
+
+	return bundle
+}
+
+// readFileWithLimit - 最大サイズまでファイルを読み取り（バイナリはスキップ）
+func readFileWithLimit(path string, maxBytes int64) BatchReadFile {
+	result := BatchReadFile{Path: path}
+
+	f, err := os.Open(path)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	defer f.Close()
+
+	if info, err := f.Stat(); err == nil {
+		result.Size = info.Size()
+	}
+
+	data, err := io.ReadAll(io.LimitReader(f, maxBytes+1))
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	if bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0 {
+		result.Error = "binary file skipped"
+		return result
+	}
+
+	if int64(len(data)) > maxBytes {
+		data = data[:maxBytes]
+		result.Truncated = true
+	}
+	content := string(data)
+	if !utf8.ValidString(content) {
+		content = strings.ToValidUTF8(content, "")
+	}
+
+	// .env 等の値・機密情報は LLM に渡す前に伏せる
+	result.Content = redact.File(path, content)
+	result.Lines = strings.Count(content, "\n")
+	if content != "" && !strings.HasSuffix(content, "\n") {
+		result.Lines++
+	}
+	return result
+}
+
+// formatBatchReadBundle - LLMに渡すテキスト形式に整形
+func formatBatchReadBundle(bundle *BatchReadBundle) string {
+	var sb strings.Builder
+	for _, file := range bundle.Files {
+		if file.Error != "" {
+			sb.WriteString(fmt.Sprintf("==> %s (error: %s) <==\n\n", file.Path, file.Error))
+			continue
+		}
+		header := fmt.Sprintf("==> %s (%d lines", file.Path, file.Lines)
+		if file.Truncated {
+			header += ", truncated"
+		}
+		sb.WriteString(header + ") <==\n")
+		sb.WriteString(file.Content)
+		if !strings.HasSuffix(file.Content, "\n") {
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	if len(bundle.Skipped) > 0 {
+		sb.WriteString(fmt.Sprintf("Skipped (size budget exceeded): %s\n", strings.Join(bundle.Skipped, ", ")))
+	}
+	if len(bundle.Unmatched) > 0 {
+		sb.WriteString(fmt.Sprintf("No match: %s\n", strings.Join(bundle.Unmatched, ", ")))
+	}
+	return sb.String()
+}
+
+// globDoubleStar - '**' を含むGlobパターンをディレクトリ走査で展開
+func globDoubleStar(pattern string) ([]string, error) {
+	pattern = filepath.ToSlash(pattern)
+
+	// ワイルドカードを含まない先頭部分を走査の起点にする
+	root := "."
+	segments := strings.Split(pattern, "/")
+	for i, segment := range segments {
+		if strings.ContainsAny(segment, "*?[") {
+			if i > 0 {
+				root = strings.Join(segments[:i], "/")
+			}
+			break
+		}
+	}
+
+	re, err := globToRegexp(pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []string
+	err = ignore.WalkDir(root, func(path string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			return nil
+		}
+		if d.IsDir() {
+			if path != root && globSkipDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if re.MatchString(filepath.ToSlash(path)) {
+			matches = append(matches, path)
+		}
+		return nil
+	})
+	return matches, err
+}
+
+// globToRegexp - Globパターンを正規表現に変換（'**/' は0個以上のディレクトリ）
+func globToRegexp(pattern string) (*regexp.Regexp, error) {
+	pattern = strings.TrimPrefix(pattern, "./")
+
+	var sb strings.Builder
+	sb.WriteString(`^(?:\./)?`)
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString(`(?:.*/)?`)
+				} else {
+					sb.WriteString(`.*`)
+				}
+			} else {
+				sb.WriteString(`[^/]*`)
+			}
+		case '?':
+			sb.WriteString(`[^/]`)
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '[' in pattern")
+			}
+			class := pattern[i+1 : i+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + class + "]")
+			i += end
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+	return regexp.Compile(sb.String())
+}
+
+// stringListParam - 配列・文字列（カンマ区切り）いずれの形式でも文字列リストとして取得
+func stringListParam(value interface{}) []string {
+	switch v := value.(type) {
+	case []string:
+		return v
+	case []interface{}:
+		var list []string
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				list = append(list, s)
+			}
+		}
+		return list
+	case string:
+		var list []string
+		for _, item := range strings.Split(v, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = append(list, item)
+			}
+		}
+		return list
+	}
+	return nil
+}
+
+// intParam - 数値パラメータを取得（未指定・不正値は既定値）
+func intParam(params map[string]interface{}, key string, defaultValue int) int {
+	switch v := params[key].(type) {
+	case float64:
+		if v > 0 {
+			return int(v)
+		}
+	case int:
+		if v > 0 {
+			return v
+		}
+	}
+	return defaultValue
+}
@@ -0,0 +1,94 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/diagnostic"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// UnifiedDiagnosticsTool - 環境に依存する不具合の調査のため、ツールのバージョン・OS・環境変数・設定を集めるツール
+type UnifiedDiagnosticsTool struct {
+	*BaseTool
+}
+
+// NewUnifiedDiagnosticsTool - 新しい環境診断ツールを作成
+func NewUnifiedDiagnosticsTool(constraints *security.Constraints) *UnifiedDiagnosticsTool {
+	base := NewBaseTool("diagnostics", "ツールのバージョン・OS・関係する環境変数・vyb の設定を集めた環境レポートを作成します", "1.0.0", CategoryUtility)
+	base.AddCapability(CapabilityCommand)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "diagnostics",
+		Description: "go・node・docker 等のバージョン、OS、環境変数（機密情報は伏せ字）、vyb の設定を Markdown の環境レポートで返します",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"tools": {
+				Type:        "array",
+				Description: "既定（go, node, npm, python3, docker, git, ollama）に加えて `<name> --version` で調べるツール名",
+			},
+			"include_config": {
+				Type:        "boolean",
+				Description: "vyb の設定（プロバイダー・モデル・サンドボックス等）を含める",
+				Default:     true,
+			},
+		},
+		Examples: []ToolExample{
+			{
+				Description: "ビルドが環境によって失敗する原因を調べる",
+				Parameters:  map[string]interface{}{},
+			},
+			{
+				Description: "Rust のツールのバージョンも含める",
+				Parameters: map[string]interface{}{
+					"tools": []interface{}{"cargo", "rustc"},
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedDiagnosticsTool{BaseTool: base}
+}
+
+// Execute - 環境レポートを作成
+func (t *UnifiedDiagnosticsTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	if err := t.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	extra := stringListParam(request.Parameters["tools"])
+	for _, name := range extra {
+		if !diagnostic.ValidToolName(name) {
+			return nil, NewToolError("invalid_parameter", "Invalid tool name: "+name)
+		}
+		if t.constraints != nil {
+			if err := t.constraints.ValidateCommand(name + " --version"); err != nil {
+				return nil, NewToolError("security_violation", err.Error())
+			}
+		}
+	}
+
+	opts := diagnostic.EnvironmentOptions{Tools: extra}
+	if include, ok := request.Parameters["include_config"].(bool); !ok || include {
+		if resolved, err := config.LoadResolved(config.ResolveOptions{Profile: config.SelectProfile("")}); err == nil {
+			opts.Config, opts.Profile = resolved.Config, resolved.Profile
+		}
+	}
+	report := diagnostic.CaptureEnvironment(ctx, opts)
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  report.Markdown(),
+		Data:     report,
+		Metadata: &ResponseMetadata{
+			Debug: map[string]interface{}{
+				"tools":   len(report.Tools),
+				"missing": report.MissingTools(),
+			},
+		},
+	}, nil
+}
@@ -0,0 +1,353 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// git_history の操作
+const (
+	GitHistoryBlame  = "blame"   // 行毎の最終変更コミット
+	GitHistoryLog    = "history" // ファイルの変更履歴（リネームを追跡）
+	GitHistorySymbol = "symbol"  // 関数・メソッドを変更したコミット（最新が最後に触った人）
+)
+
+// git_history の件数の上限
+const (
+	defaultGitHistory = 10  // 履歴の既定の件数
+	maxGitHistory     = 100 // 履歴の最大件数
+	maxGitBlameLines  = 400 // blame で返す最大行数
+)
+
+// git log の1コミットの書式（フィールドは \x1f、コミットは \x1e で区切る）
+const gitCommitFormat = "%H%x1f%an%x1f%ae%x1f%aI%x1f%s%x1f%b%x1e"
+
+// GitCommitInfo - コミットの情報
+type GitCommitInfo struct {
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Email   string `json:"email"`
+	Date    string `json:"date"`
+	Subject string `json:"subject"`
+	Body    string `json:"body,omitempty"`
+}
+
+// GitBlameLine - blame の1行
+type GitBlameLine struct {
+	Line    int    `json:"line"`
+	Hash    string `json:"hash"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	Summary string `json:"summary"`
+	Content string `json:"content"`
+}
+
+// GitHistoryResult - git_history の結果
+type GitHistoryResult struct {
+	Operation string          `json:"operation"`
+	FilePath  string          `json:"file_path"`
+	Symbol    string          `json:"symbol,omitempty"`
+	Commits   []GitCommitInfo `json:"commits,omitempty"`
+	Blame     []GitBlameLine  `json:"blame,omitempty"`
+	Truncated bool            `json:"truncated,omitempty"`
+}
+
+// LastTouched - 最後に変更したコミット（履歴がなければ nil）
+func (r *GitHistoryResult) LastTouched() *GitCommitInfo {
+	if len(r.Commits) == 0 {
+		return nil
+	}
+	return &r.Commits[0]
+}
+
+// UnifiedGitHistoryTool - blame・ファイル履歴・関数の変更履歴を構造化して返すツール
+type UnifiedGitHistoryTool struct {
+	*BaseTool
+}
+
+// NewUnifiedGitHistoryTool - 新しいgit履歴ツールを作成
+func NewUnifiedGitHistoryTool(constraints *security.Constraints) *UnifiedGitHistoryTool {
+	base := NewBaseTool("git_history", "Git の blame・変更履歴からコードの経緯（作者・コミットメッセージ）を調べます", "1.0.0", CategoryGit)
+	base.AddCapability(CapabilityGit)
+	base.AddCapability(CapabilityFileRead)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "git_history",
+		Description: "ファイルの blame、変更履歴、関数を最後に変更したコミットを作者・日時・コミットメッセージ付きで返します",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"operation": {
+				Type:        "string",
+				Description: "blame: 行毎の最終変更, history: ファイルの変更履歴, symbol: 関数・メソッドの変更履歴",
+				Enum:        []string{GitHistoryBlame, GitHistoryLog, GitHistorySymbol},
+				Default:     GitHistoryLog,
+			},
+			"file_path": {
+				Type:        "string",
+				Description: "対象ファイルのパス",
+			},
+			"symbol": {
+				Type:        "string",
+				Description: "関数・メソッド名（symbol では必須、blame では関数の範囲に限定）",
+			},
+			"start_line": {
+				Type:        "integer",
+				Description: "blame の開始行（省略可）",
+				Minimum:     floatPtr(1),
+			},
+			"end_line": {
+				Type:        "integer",
+				Description: "blame の終了行（省略可）",
+				Minimum:     floatPtr(1),
+			},
+			"max_count": {
+				Type:        "integer",
+				Description: "返すコミットの最大件数（省略時10）",
+				Minimum:     floatPtr(1),
+				Maximum:     floatPtr(maxGitHistory),
+			},
+		},
+		Required: []string{"file_path"},
+		Examples: []ToolExample{
+			{
+				Description: "関数を最後に変更した人と理由を調べる",
+				Parameters: map[string]interface{}{
+					"operation": GitHistorySymbol,
+					"file_path": "internal/config/config.go",
+					"symbol":    "applyDefaults",
+				},
+			},
+			{
+				Description: "特定の行の経緯を調べる",
+				Parameters: map[string]interface{}{
+					"operation":  GitHistoryBlame,
+					"file_path":  "main.go",
+					"start_line": 10,
+					"end_line":   20,
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedGitHistoryTool{BaseTool: base}
+}
+
+// Execute - git履歴の取得を実行
+func (t *UnifiedGitHistoryTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	if err := t.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	filePath, _ := request.Parameters["file_path"].(string)
+	if filePath == "" {
+		return nil, NewToolError("invalid_parameter", "file_path parameter is required")
+	}
+	if strings.Contains(filePath, "..") {
+		return nil, NewToolError("security_violation", "Path contains invalid characters: "+filePath)
+	}
+	if t.constraints != nil {
+		resolved, err := t.constraints.ResolvePathFor(filePath, "read")
+		if err != nil {
+			return nil, NewToolError("security_violation", "Invalid file path: "+err.Error())
+		}
+		filePath = resolved
+	}
+
+	operation, _ := request.Parameters["operation"].(string)
+	if operation == "" {
+		operation = GitHistoryLog
+	}
+	symbol, _ := request.Parameters["symbol"].(string)
+	symbol = strings.TrimSpace(symbol)
+	maxCount := intParam(request.Parameters, "max_count", defaultGitHistory)
+	if maxCount > maxGitHistory {
+		maxCount = maxGitHistory
+	}
+
+	result := &GitHistoryResult{Operation: operation, FilePath: filePath, Symbol: symbol}
+	var err error
+	switch operation {
+	case GitHistoryBlame:
+		startLine := intParam(request.Parameters, "start_line", 0)
+		endLine := intParam(request.Parameters, "end_line", 0)
+		result.Blame, result.Truncated, err = gitBlame(ctx, filePath, symbol, startLine, endLine)
+	case GitHistoryLog:
+		result.Commits, err = gitFileHistory(ctx, filePath, maxCount)
+	case GitHistorySymbol:
+		if symbol == "" {
+			return nil, NewToolError("invalid_parameter", "symbol parameter is required for the symbol operation")
+		}
+		result.Commits, err = gitSymbolHistory(ctx, filePath, symbol, maxCount)
+	default:
+		return nil, NewToolError("invalid_parameter", fmt.Sprintf("Unknown operation: %s (blame, history, symbol)", operation))
+	}
+	if err != nil {
+		return nil, NewToolError("execution_failed", err.Error())
+	}
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  formatGitHistoryResult(result),
+		Data:     result,
+		Metadata: &ResponseMetadata{
+			Debug: map[string]interface{}{
+				"commits":     len(result.Commits),
+				"blame_lines": len(result.Blame),
+			},
+		},
+	}, nil
+}
+
+// runGit - ファイルのディレクトリで git を実行（リポジトリ外・対象がなければエラー）
+func runGit(ctx context.Context, filePath string, args ...string) (string, error) {
+	return gitexec.Run(ctx, filepath.Dir(filePath), args...)
+}
+
+// gitFileHistory - ファイルの変更履歴（新しい順、リネームを追跡）
+func gitFileHistory(ctx context.Context, filePath string, maxCount int) ([]GitCommitInfo, error) {
+	output, err := runGit(ctx, filePath, "log", "--follow", "-n", strconv.Itoa(maxCount), "--format="+gitCommitFormat, "--", filepath.Base(filePath))
+	if err != nil {
+		return nil, err
+	}
+	return parseGitCommits(output), nil
+}
+
+// gitSymbolHistory - 関数・メソッドの範囲を変更したコミット（新しい順）
+func gitSymbolHistory(ctx context.Context, filePath, symbol string, maxCount int) ([]GitCommitInfo, error) {
+	output, err := runGit(ctx, filePath, "log", "-n", strconv.Itoa(maxCount), "--no-patch", "--format="+gitCommitFormat,
+		fmt.Sprintf("-L:%s:%s", symbol, filepath.Base(filePath)))
+	if err != nil {
+		return nil, err
+	}
+	return parseGitCommits(output), nil
+}
+
+// gitBlame - 行毎の最終変更コミット（symbol 指定時は関数の範囲、行数が多ければ先頭のみ）
+func gitBlame(ctx context.Context, filePath, symbol string, startLine, endLine int) ([]GitBlameLine, bool, error) {
+	args := []string{"blame", "--line-porcelain"}
+	switch {
+	case symbol != "":
+		args = append(args, "-L", ":"+symbol)
+	case startLine > 0 && endLine >= startLine:
+		args = append(args, "-L", fmt.Sprintf("%d,%d", startLine, endLine))
+	case startLine > 0:
+		args = append(args, "-L", fmt.Sprintf("%d,", startLine))
+	}
+	args = append(args, "--", filepath.Base(filePath))
+
+	output, err := runGit(ctx, filePath, args...)
+	if err != nil {
+		return nil, false, err
+	}
+	lines := parseGitBlame(output)
+	if len(lines) > maxGitBlameLines {
+		return lines[:maxGitBlameLines], true, nil
+	}
+	return lines, false, nil
+}
+
+// parseGitCommits - gitCommitFormat の出力をコミット一覧に変換
+func parseGitCommits(output string) []GitCommitInfo {
+	var commits []GitCommitInfo
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.Split(strings.TrimLeft(record, "\n"), "\x1f")
+		if len(fields) < 6 || fields[0] == "" {
+			continue
+		}
+		commits = append(commits, GitCommitInfo{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    fields[3],
+			Subject: fields[4],
+			Body:    strings.TrimSpace(fields[5]),
+		})
+	}
+	return commits
+}
+
+// parseGitBlame - git blame --line-porcelain の出力を行毎の情報に変換
+func parseGitBlame(output string) []GitBlameLine {
+	var lines []GitBlameLine
+	var current GitBlameLine
+	for _, raw := range strings.Split(output, "\n") {
+		switch {
+		case strings.HasPrefix(raw, "\t"):
+			current.Content = raw[1:]
+			lines = append(lines, current)
+			current = GitBlameLine{}
+		case strings.HasPrefix(raw, "author "):
+			current.Author = strings.TrimPrefix(raw, "author ")
+		case strings.HasPrefix(raw, "author-time "):
+			if seconds, err := strconv.ParseInt(strings.TrimPrefix(raw, "author-time "), 10, 64); err == nil {
+				current.Date = time.Unix(seconds, 0).Format("2006-01-02")
+			}
+		case strings.HasPrefix(raw, "summary "):
+			current.Summary = strings.TrimPrefix(raw, "summary ")
+		default:
+			// ヘッダー行: <hash> <元の行> <現在の行> [<行数>]
+			fields := strings.Fields(raw)
+			if current.Hash == "" && len(fields) >= 3 && len(fields[0]) >= 40 {
+				current.Hash = fields[0]
+				current.Line, _ = strconv.Atoi(fields[2])
+			}
+		}
+	}
+	return lines
+}
+
+// formatGitHistoryResult - LLMに渡すテキスト形式
+func formatGitHistoryResult(result *GitHistoryResult) string {
+	var b strings.Builder
+	target := result.FilePath
+	if result.Symbol != "" {
+		target = fmt.Sprintf("%s (%s)", result.Symbol, result.FilePath)
+	}
+
+	switch result.Operation {
+	case GitHistoryBlame:
+		fmt.Fprintf(&b, "Blame of %s:\n", target)
+		for _, line := range result.Blame {
+			fmt.Fprintf(&b, "%5d %s %-16s %s | %s\n", line.Line, shortHash(line.Hash), line.Author, line.Summary, line.Content)
+		}
+		if result.Truncated {
+			fmt.Fprintf(&b, "... (truncated to %d lines)\n", maxGitBlameLines)
+		}
+	default:
+		if len(result.Commits) == 0 {
+			fmt.Fprintf(&b, "No commits found for %s\n", target)
+			break
+		}
+		last := result.LastTouched()
+		fmt.Fprintf(&b, "History of %s (%d commits, newest first). Last touched by %s <%s> on %s.\n\n",
+			target, len(result.Commits), last.Author, last.Email, last.Date)
+		for _, commit := range result.Commits {
+			fmt.Fprintf(&b, "%s %s %s: %s\n", shortHash(commit.Hash), commit.Date, commit.Author, commit.Subject)
+			if commit.Body != "" {
+				for _, line := range strings.Split(commit.Body, "\n") {
+					fmt.Fprintf(&b, "    %s\n", line)
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// shortHash - 表示用の短いコミットハッシュ
+func shortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
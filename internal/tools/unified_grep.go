@@ -9,6 +9,7 @@ import (
 	"regexp"
 	"strings"
 
+	"github.com/glkt/vyb-code/internal/ignore"
 	"github.com/glkt/vyb-code/internal/security"
 )
 
@@ -286,7 +287,7 @@ func (t *UnifiedGrepTool) performGrep(ctx context.Context, pattern, searchPath s
 func (t *UnifiedGrepTool) getFilesToSearch(searchPath string, options *UnifiedGrepOptions) ([]string, error) {
 	var files []string
 
-	err := filepath.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
+	err := ignore.Walk(searchPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // エラーは無視して続行
 		}
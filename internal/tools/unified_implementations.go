@@ -6,10 +6,11 @@ import (
 	"io/ioutil"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/sandbox"
 	"github.com/glkt/vyb-code/internal/security"
 )
 
@@ -27,7 +28,7 @@ func NewUnifiedReadTool(constraints *security.Constraints) *UnifiedReadTool {
 	// スキーマ設定
 	schema := ToolSchema{
 		Name:        "read",
-		Description: "ファイル内容を読み取り、指定された範囲の行を返します",
+		Description: "ファイル内容を読み取り、指定された範囲の行を返します。範囲指定なしで大きなファイルを読むと概要（宣言の一覧と行番号）を返し、バイナリファイルは読み取りません",
 		Version:     "1.0.0",
 		Parameters: map[string]Parameter{
 			"file_path": {
@@ -41,7 +42,7 @@ func NewUnifiedReadTool(constraints *security.Constraints) *UnifiedReadTool {
 			},
 			"limit": {
 				Type:        "integer",
-				Description: "読み取る行数（省略可）",
+				Description: "読み取る行数（省略可、1回の上限は2000行）",
 				Minimum:     floatPtr(1),
 			},
 		},
@@ -72,67 +73,105 @@ func NewUnifiedReadTool(constraints *security.Constraints) *UnifiedReadTool {
 func (t *UnifiedReadTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
 	filePath := request.Parameters["file_path"].(string)
 
-	// セキュリティチェック（簡易実装）
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
 	if t.constraints != nil {
-		// 基本的なパス検証
-		if strings.Contains(filePath, "..") || !filepath.IsAbs(filePath) && !strings.HasPrefix(filePath, "./") {
-			return nil, NewExecutionError("Invalid file path: "+filePath, -1)
+		resolved, err := t.constraints.ResolvePathFor(filePath, "read")
+		if err != nil {
+			return nil, NewExecutionError("Invalid file path: "+err.Error(), -1)
 		}
+		filePath = resolved
 	}
 
-	// ファイル読み取り
-	content, err := ioutil.ReadFile(filePath)
+	info, err := os.Stat(filePath)
 	if err != nil {
 		return nil, NewExecutionError("Failed to read file: "+err.Error(), -1)
 	}
+	if info.IsDir() {
+		return nil, NewExecutionError(fmt.Sprintf("%s is a directory; use ls or glob to list its files", request.Parameters["file_path"]), -1)
+	}
 
-	lines := strings.Split(string(content), "\n")
+	// バイナリファイルはテキストとして返さず、調べ方を案内する
+	binary, err := sniffBinary(filePath)
+	if err != nil {
+		return nil, NewExecutionError("Failed to read file: "+err.Error(), -1)
+	}
+	if binary {
+		return nil, NewExecutionError(binaryFileGuidance(filePath, info.Size()), -1)
+	}
 
-	// オフセットと制限の適用
+	// オフセット（0始まり）と制限の適用
 	offset := 0
-	limit := len(lines)
-
+	limit := 0
 	if offsetVal, ok := request.Parameters["offset"]; ok {
 		if o, ok := offsetVal.(float64); ok {
 			offset = int(o)
 		}
 	}
-
 	if limitVal, ok := request.Parameters["limit"]; ok {
 		if l, ok := limitVal.(float64); ok {
 			limit = int(l)
 		}
 	}
+	_, hasOffset := request.Parameters["offset"]
+	ranged := hasOffset || limit > 0
+
+	// 範囲指定なしの大きなファイルは、全文の代わりに概要を返してコンテキストを守る
+	if !ranged && info.Size() > largeFileThreshold {
+		summary, totalLines, err := summarizeLargeFile(filePath, info.Size())
+		if err != nil {
+			return nil, NewExecutionError("Failed to read file: "+err.Error(), -1)
+		}
+		return &ToolResponse{
+			ID:       request.ID,
+			ToolName: t.GetName(),
+			Success:  true,
+			Content:  summary,
+			Metadata: &ResponseMetadata{
+				Debug: map[string]interface{}{
+					"file_path":   filePath,
+					"file_size":   info.Size(),
+					"total_lines": totalLines,
+					"summarized":  true,
+				},
+			},
+		}, nil
+	}
 
-	// 範囲調整
-	if offset >= len(lines) {
-		lines = []string{}
-	} else {
-		end := offset + limit
-		if end > len(lines) {
-			end = len(lines)
+	if limit <= 0 || limit > defaultReadLines {
+		limit = defaultReadLines
+	}
+	chunk, err := readLineRange(filePath, offset+1, limit)
+	if err != nil {
+		return nil, NewExecutionError("Failed to read file: "+err.Error(), -1)
+	}
+
+	// .env 等の値は LLM に渡す前に伏せる（その他の機密情報は送信時に伏せる）
+	if redact.IsEnvFile(filePath) {
+		for i, line := range chunk.Lines {
+			chunk.Lines[i] = redact.File(filePath, line)
 		}
-		lines = lines[offset:end]
 	}
 
 	// 行番号付きで結果作成
-	var result strings.Builder
-	for i, line := range lines {
-		result.WriteString(fmt.Sprintf("%5d→%s\n", offset+i+1, line))
+	content := formatNumberedLines(chunk.Lines, offset+1)
+	if next := offset + len(chunk.Lines); next < chunk.TotalLines {
+		content += fmt.Sprintf("… %d more lines (continue with offset=%d)\n", chunk.TotalLines-next, next)
 	}
 
 	return &ToolResponse{
 		ID:       request.ID,
 		ToolName: t.GetName(),
 		Success:  true,
-		Content:  result.String(),
+		Content:  content,
 		Metadata: &ResponseMetadata{
 			Debug: map[string]interface{}{
-				"file_path":   filePath,
-				"total_lines": len(strings.Split(string(content), "\n")),
-				"offset":      offset,
-				"limit":       limit,
-				"returned":    len(lines),
+				"file_path":       filePath,
+				"file_size":       info.Size(),
+				"total_lines":     chunk.TotalLines,
+				"offset":          offset,
+				"limit":           limit,
+				"returned":        len(chunk.Lines),
+				"lines_truncated": chunk.Truncated,
 			},
 		},
 	}, nil
@@ -151,7 +190,7 @@ func NewUnifiedWriteTool(constraints *security.Constraints) *UnifiedWriteTool {
 
 	schema := ToolSchema{
 		Name:        "write",
-		Description: "指定されたファイルに内容を書き込みます",
+		Description: "指定されたファイルに内容を書き込みます。1回の書き込みは10MBまでで、大きなファイルは append で分割して書き込みます",
 		Version:     "1.0.0",
 		Parameters: map[string]Parameter{
 			"file_path": {
@@ -162,6 +201,11 @@ func NewUnifiedWriteTool(constraints *security.Constraints) *UnifiedWriteTool {
 				Type:        "string",
 				Description: "書き込む内容",
 			},
+			"append": {
+				Type:        "boolean",
+				Description: "既存の内容の末尾に追記するか（デフォルト：false）",
+				Default:     false,
+			},
 		},
 		Required: []string{"file_path", "content"},
 		Examples: []ToolExample{
@@ -184,35 +228,41 @@ func (t *UnifiedWriteTool) Execute(ctx context.Context, request *ToolRequest) (*
 	filePath := request.Parameters["file_path"].(string)
 	content := request.Parameters["content"].(string)
 
-	// セキュリティチェック（簡易実装）
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
 	if t.constraints != nil {
-		// 基本的なパス検証
-		if strings.Contains(filePath, "..") || !filepath.IsAbs(filePath) && !strings.HasPrefix(filePath, "./") {
-			return nil, NewExecutionError("Invalid file path: "+filePath, -1)
+		resolved, err := t.constraints.ResolvePathFor(filePath, "write")
+		if err != nil {
+			return nil, NewExecutionError("Invalid file path: "+err.Error(), -1)
 		}
+		filePath = resolved
 	}
 
-	// ディレクトリ作成
-	dir := filepath.Dir(filePath)
-	if err := os.MkdirAll(dir, 0755); err != nil {
-		return nil, NewExecutionError("Failed to create directory: "+err.Error(), -1)
+	// 上限を超える内容は黙って切り詰めず、分割して書き込むよう案内する
+	if int64(len(content)) > maxWriteContentBytes {
+		return nil, NewExecutionError(writeTooLargeGuidance(int64(len(content)), maxWriteContentBytes), -1)
 	}
+	appendMode, _ := request.Parameters["append"].(bool)
 
-	// ファイル書き込み
-	err := ioutil.WriteFile(filePath, []byte(content), 0644)
+	// ファイル書き込み（上書きは一時ファイル経由で置き換え）
+	written, err := writeFileStreaming(filePath, strings.NewReader(content), appendMode)
 	if err != nil {
 		return nil, NewExecutionError("Failed to write file: "+err.Error(), -1)
 	}
 
+	message := fmt.Sprintf("File written successfully: %s", filePath)
+	if appendMode {
+		message = fmt.Sprintf("Appended %d bytes to %s", written, filePath)
+	}
 	return &ToolResponse{
 		ID:       request.ID,
 		ToolName: t.GetName(),
 		Success:  true,
-		Content:  fmt.Sprintf("File written successfully: %s", filePath),
+		Content:  message,
 		Metadata: &ResponseMetadata{
 			Debug: map[string]interface{}{
 				"file_path":    filePath,
 				"content_size": len(content),
+				"append":       appendMode,
 			},
 		},
 	}, nil
@@ -294,12 +344,13 @@ func (t *UnifiedEditTool) Execute(ctx context.Context, request *ToolRequest) (*T
 		}
 	}
 
-	// セキュリティチェック（簡易実装）
+	// パス検証（ワークスペース外や、シンボリックリンクによる脱出を拒否）
 	if t.constraints != nil {
-		// 基本的なパス検証
-		if strings.Contains(filePath, "..") || !filepath.IsAbs(filePath) && !strings.HasPrefix(filePath, "./") {
-			return nil, NewExecutionError("Invalid file path: "+filePath, -1)
+		resolved, err := t.constraints.ResolvePathFor(filePath, "write")
+		if err != nil {
+			return nil, NewExecutionError("Invalid file path: "+err.Error(), -1)
 		}
+		filePath = resolved
 	}
 
 	// ファイル読み取り
@@ -355,10 +406,120 @@ func (t *UnifiedEditTool) Execute(ctx context.Context, request *ToolRequest) (*T
 	}, nil
 }
 
+// UnifiedMultiEditTool - 1つのファイルへの複数の編集をまとめて行う統一ツール
+type UnifiedMultiEditTool struct {
+	*BaseTool
+}
+
+// NewUnifiedMultiEditTool - 新しい統一マルチ編集ツールを作成
+func NewUnifiedMultiEditTool(constraints *security.Constraints) *UnifiedMultiEditTool {
+	base := NewBaseTool("multiedit", "1つのファイルに複数の編集をまとめて行います", "1.0.0", CategoryFile)
+	base.AddCapability(CapabilityFileRead)
+	base.AddCapability(CapabilityFileWrite)
+	base.AddCapability(CapabilityFileEdit)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "multiedit",
+		Description: "1つのファイルに文字列の置換を順に適用します。全ての編集が成功した場合だけ書き込み、1つでも失敗すればファイルは変更しません",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"file_path": {
+				Type:        "string",
+				Description: "編集するファイルのパス",
+			},
+			"edits": {
+				Type:        "array",
+				Description: "順に適用する編集（old_string, new_string, replace_all）。各編集は前の編集の結果に対して行い、最初の編集の old_string が空ならファイルを新規作成",
+			},
+		},
+		Required: []string{"file_path", "edits"},
+		Examples: []ToolExample{
+			{
+				Description: "関数名の変更と呼び出し箇所の修正をまとめて行う",
+				Parameters: map[string]interface{}{
+					"file_path": "./example.go",
+					"edits": []interface{}{
+						map[string]interface{}{"old_string": "func oldName(", "new_string": "func newName("},
+						map[string]interface{}{"old_string": "oldName()", "new_string": "newName()", "replace_all": true},
+					},
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedMultiEditTool{BaseTool: base}
+}
+
+// Execute - マルチ編集実行
+func (t *UnifiedMultiEditTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	filePath, _ := request.Parameters["file_path"].(string)
+	if filePath == "" {
+		return nil, NewToolError("invalid_parameter", "file_path parameter is required")
+	}
+	edits, err := editListParam(request.Parameters["edits"])
+	if err != nil {
+		return nil, NewToolError("invalid_parameter", err.Error())
+	}
+
+	constraints := t.constraints
+	if constraints == nil {
+		constraints = security.NewDefaultConstraints(".")
+	}
+	result, err := NewMultiEditTool(constraints, "", constraints.MaxFileSize).MultiEdit(MultiEditRequest{FilePath: filePath, Edits: edits})
+	if err != nil {
+		return nil, NewExecutionError(result.Content, -1)
+	}
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  result.Content,
+		Metadata: &ResponseMetadata{
+			Debug: result.Metadata,
+		},
+	}, nil
+}
+
+// editListParam は edits パラメータ（old_string, new_string, replace_all のオブジェクトの配列）を編集の一覧にする
+func editListParam(value interface{}) ([]EditRequest, error) {
+	var items []map[string]interface{}
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		items = v
+	case []interface{}:
+		for _, item := range v {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("each edit must be an object with old_string and new_string")
+			}
+			items = append(items, m)
+		}
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("edits parameter is required")
+	}
+
+	edits := make([]EditRequest, 0, len(items))
+	for i, item := range items {
+		oldString, okOld := item["old_string"].(string)
+		newString, okNew := item["new_string"].(string)
+		if !okOld || !okNew {
+			return nil, fmt.Errorf("edit %d: old_string and new_string are required", i+1)
+		}
+		replaceAll, _ := item["replace_all"].(bool)
+		edits = append(edits, EditRequest{OldString: oldString, NewString: newString, ReplaceAll: replaceAll})
+	}
+	return edits, nil
+}
+
 // UnifiedBashTool - 統一Bashツール
 type UnifiedBashTool struct {
 	*BaseTool
 	timeout time.Duration
+	backend sandbox.Backend // 実行バックエンド（ホスト/コンテナ）
 }
 
 // NewUnifiedBashTool - 新しい統一Bashツールを作成
@@ -412,6 +573,14 @@ func NewUnifiedBashTool(constraints *security.Constraints) *UnifiedBashTool {
 	return &UnifiedBashTool{
 		BaseTool: base,
 		timeout:  30 * time.Second,
+		backend:  sandbox.NewHostBackend(),
+	}
+}
+
+// SetBackend - 実行バックエンドを設定
+func (t *UnifiedBashTool) SetBackend(backend sandbox.Backend) {
+	if backend != nil {
+		t.backend = backend
 	}
 }
 
@@ -443,7 +612,10 @@ func (t *UnifiedBashTool) Execute(ctx context.Context, request *ToolRequest) (*T
 	defer cancel()
 
 	// コマンド実行
-	cmd := exec.CommandContext(cmdCtx, "bash", "-c", command)
+	cmd, err := t.backend.Command(cmdCtx, command, "")
+	if err != nil {
+		return nil, NewExecutionError("Execution backend error: "+err.Error(), -1)
+	}
 
 	startTime := time.Now()
 	output, err := cmd.CombinedOutput()
@@ -483,6 +655,7 @@ func (t *UnifiedBashTool) Execute(ctx context.Context, request *ToolRequest) (*T
 				"exit_code":   exitCode,
 				"timed_out":   timedOut,
 				"timeout_ms":  timeout.Milliseconds(),
+				"backend":     t.backend.Name(),
 			},
 		},
 	}, nil
@@ -0,0 +1,474 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/redact"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// log_tail の上限
+const (
+	defaultLogTailLines   = 50                     // 最初に返す末尾の行数
+	defaultLogFollowLines = 200                    // 追っている間に集める行数
+	maxLogTailLines       = 1000                   // 末尾・追記それぞれの最大行数
+	maxLogFollowSeconds   = 120                    // 追う最大秒数
+	maxLogTailReadBytes   = 256 * 1024             // 末尾を探すために読むファイルの末尾のバイト数
+	maxLogTailLineRunes   = 500                    // 1行の最大文字数
+	logTailPollInterval   = 200 * time.Millisecond // ファイルの追記を確かめる間隔
+	logTailJournalOutput  = "short-iso"            // journalctl の出力形式
+)
+
+// ログの行の重大度（行の文言から推定）
+const (
+	LogLevelError = "error"
+	LogLevelWarn  = "warn"
+	LogLevelInfo  = "info"
+	LogLevelDebug = "debug"
+)
+
+// 重大度を推定する語（先に一致したものを採る）
+var logLevelPatterns = []struct {
+	level   string
+	pattern *regexp.Regexp
+}{
+	{LogLevelError, regexp.MustCompile(`(?i)\b(error|err|fatal|panic|critical|crit|exception|traceback|failed)\b`)},
+	{LogLevelWarn, regexp.MustCompile(`(?i)\b(warn|warning)\b`)},
+	{LogLevelDebug, regexp.MustCompile(`(?i)\b(debug|trace)\b`)},
+	{LogLevelInfo, regexp.MustCompile(`(?i)\binfo\b`)},
+}
+
+// journalUnitPattern は journalctl に渡せるユニット名
+var journalUnitPattern = regexp.MustCompile(`^[A-Za-z0-9@._:\\-]+$`)
+
+// LogTailOptions - ログの末尾の読み取りと追記の追跡の指定
+type LogTailOptions struct {
+	Path     string         // 追うファイル（Unit と排他）
+	Unit     string         // journalctl のユニット
+	Lines    int            // 最初に返す末尾の行数
+	Follow   time.Duration  // 追記を追う時間（0 なら追わない）
+	MaxLines int            // 追っている間に集める最大行数（達したら時間前でも終える）
+	Filter   *regexp.Regexp // 一致する行だけを返す（nil なら全て）
+}
+
+// LogLine - ログの1行
+type LogLine struct {
+	Text  string `json:"text"`
+	Level string `json:"level,omitempty"`
+	New   bool   `json:"new,omitempty"` // 追っている間に追記された行
+}
+
+// LogTailResult - log_tail の結果
+type LogTailResult struct {
+	Source          string         `json:"source"`
+	Lines           []LogLine      `json:"lines"`
+	Levels          map[string]int `json:"levels,omitempty"` // 重大度毎の行数
+	FollowedSeconds float64        `json:"followed_seconds,omitempty"`
+	NewLines        int            `json:"new_lines"`
+	Truncated       bool           `json:"truncated,omitempty"` // 追記が上限の行数に達した
+}
+
+// UnifiedLogTailTool - ログファイル・journalctl のユニットの末尾を読み、一定時間追記を追うツール
+type UnifiedLogTailTool struct {
+	*BaseTool
+}
+
+// NewUnifiedLogTailTool - 新しいログ追跡ツールを作成
+func NewUnifiedLogTailTool(constraints *security.Constraints) *UnifiedLogTailTool {
+	base := NewBaseTool("log_tail", "ログファイル・journalctl のユニットの末尾を読み、指定した秒数・行数だけ追記を追います", "1.0.0", CategoryUtility)
+	base.AddCapability(CapabilityFileRead)
+	base.AddCapability(CapabilityStreaming)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "log_tail",
+		Description: "サーバー等のログの末尾と、追っている間に追記された行を重大度（error, warn, info, debug）付きで返します。リクエストを送る間のログを見るのに使います",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"path": {
+				Type:        "string",
+				Description: "ワークスペース内のログファイル（unit と排他）",
+			},
+			"unit": {
+				Type:        "string",
+				Description: "journalctl のユニット（nginx.service 等、path と排他）",
+			},
+			"lines": {
+				Type:        "integer",
+				Description: "最初に返す末尾の行数（省略時50）",
+				Minimum:     floatPtr(1),
+				Maximum:     floatPtr(maxLogTailLines),
+			},
+			"seconds": {
+				Type:        "integer",
+				Description: "追記を追う秒数（省略時は追わない）",
+				Minimum:     floatPtr(1),
+				Maximum:     floatPtr(maxLogFollowSeconds),
+			},
+			"max_lines": {
+				Type:        "integer",
+				Description: "追っている間に集める最大行数（達したら終える、省略時200）",
+				Minimum:     floatPtr(1),
+				Maximum:     floatPtr(maxLogTailLines),
+			},
+			"grep": {
+				Type:        "string",
+				Description: "一致する行だけを返す正規表現",
+			},
+		},
+		Examples: []ToolExample{
+			{
+				Description: "エンドポイントにリクエストを送る間の30秒、サーバーのログを追う",
+				Parameters: map[string]interface{}{
+					"path":    "logs/server.log",
+					"seconds": 30,
+				},
+			},
+			{
+				Description: "nginx のユニットのエラーだけを直近200行から探す",
+				Parameters: map[string]interface{}{
+					"unit":  "nginx.service",
+					"lines": 200,
+					"grep":  "(?i)error|crit",
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedLogTailTool{BaseTool: base}
+}
+
+// Execute - ログの末尾を読み、追記を追う
+func (t *UnifiedLogTailTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	if err := t.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	opts := LogTailOptions{
+		Lines:    intParam(request.Parameters, "lines", defaultLogTailLines),
+		Follow:   time.Duration(intParam(request.Parameters, "seconds", 0)) * time.Second,
+		MaxLines: intParam(request.Parameters, "max_lines", defaultLogFollowLines),
+	}
+	opts.Path, _ = request.Parameters["path"].(string)
+	opts.Unit, _ = request.Parameters["unit"].(string)
+	if grep, _ := request.Parameters["grep"].(string); grep != "" {
+		filter, err := regexp.Compile(grep)
+		if err != nil {
+			return nil, NewToolError("invalid_parameter", "Invalid grep pattern: "+err.Error())
+		}
+		opts.Filter = filter
+	}
+	if opts.Path != "" && t.constraints != nil {
+		resolved, err := t.constraints.ResolvePathFor(opts.Path, "read")
+		if err != nil {
+			return nil, NewToolError("security_violation", "Invalid path: "+err.Error())
+		}
+		opts.Path = resolved
+	}
+
+	result, err := TailLog(ctx, opts)
+	if err != nil {
+		return nil, NewToolError("execution_failed", err.Error())
+	}
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  result.Format(),
+		Data:     result,
+		Metadata: &ResponseMetadata{
+			Debug: map[string]interface{}{
+				"lines":     len(result.Lines),
+				"new_lines": result.NewLines,
+				"truncated": result.Truncated,
+			},
+		},
+	}, nil
+}
+
+// TailLog - ファイルまたは journalctl のユニットの末尾を読み、指定時間（または行数に達するまで）追記を追う
+// 行は既定の Redactor で機密情報を伏せ、長い行は切り詰める
+func TailLog(ctx context.Context, opts LogTailOptions) (*LogTailResult, error) {
+	opts.Path, opts.Unit = strings.TrimSpace(opts.Path), strings.TrimSpace(opts.Unit)
+	if (opts.Path == "") == (opts.Unit == "") {
+		return nil, fmt.Errorf("path か unit のどちらか一方を指定してください")
+	}
+	opts.Lines = clampLogLines(opts.Lines, defaultLogTailLines)
+	opts.MaxLines = clampLogLines(opts.MaxLines, defaultLogFollowLines)
+	if opts.Follow > maxLogFollowSeconds*time.Second {
+		opts.Follow = maxLogFollowSeconds * time.Second
+	}
+
+	if opts.Unit != "" {
+		return tailJournal(ctx, opts)
+	}
+	return tailFile(ctx, opts)
+}
+
+// clampLogLines - 行数の指定を 1〜maxLogTailLines に収める（未指定は既定値）
+func clampLogLines(lines, defaultValue int) int {
+	switch {
+	case lines <= 0:
+		return defaultValue
+	case lines > maxLogTailLines:
+		return maxLogTailLines
+	}
+	return lines
+}
+
+// tailFile - ファイルの末尾の行を読み、追記を追う（切り詰め・ローテーションされたら先頭から読み直す）
+func tailFile(ctx context.Context, opts LogTailOptions) (*LogTailResult, error) {
+	file, err := os.Open(opts.Path)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	if info.IsDir() {
+		file.Close()
+		return nil, fmt.Errorf("%s はディレクトリです", opts.Path)
+	}
+
+	result := &LogTailResult{Source: opts.Path}
+	offset := info.Size()
+	start := offset - maxLogTailReadBytes
+	if start < 0 {
+		start = 0
+	}
+	data := make([]byte, offset-start)
+	_, err = file.ReadAt(data, start)
+	file.Close()
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if start > 0 && len(lines) > 0 {
+		lines = lines[1:] // 途中から読んだ最初の行は欠けている
+	}
+	if len(data) == 0 {
+		lines = nil
+	}
+	result.addTail(lines, opts)
+
+	if opts.Follow <= 0 {
+		return result, nil
+	}
+
+	started := time.Now()
+	ctx, cancel := context.WithTimeout(ctx, opts.Follow)
+	defer cancel()
+	ticker := time.NewTicker(logTailPollInterval)
+	defer ticker.Stop()
+	var partial string
+	for !result.Truncated {
+		select {
+		case <-ctx.Done():
+			if partial != "" {
+				result.addNew(partial, opts)
+			}
+			result.FollowedSeconds = time.Since(started).Round(100 * time.Millisecond).Seconds()
+			return result, nil
+		case <-ticker.C:
+		}
+
+		current, err := os.Stat(opts.Path)
+		if err != nil {
+			continue // ローテーション中で一時的に存在しない
+		}
+		if !os.SameFile(info, current) || current.Size() < offset {
+			info, offset, partial = current, 0, ""
+		}
+		if current.Size() == offset {
+			continue
+		}
+		chunk, err := readFrom(opts.Path, offset, current.Size()-offset)
+		if err != nil {
+			continue
+		}
+		offset += int64(len(chunk))
+		text := partial + string(chunk)
+		lines := strings.Split(text, "\n")
+		partial = lines[len(lines)-1] // 改行で終わっていない行は次の追記を待つ
+		for _, line := range lines[:len(lines)-1] {
+			if result.addNew(line, opts) {
+				break
+			}
+		}
+	}
+	result.FollowedSeconds = time.Since(started).Round(100 * time.Millisecond).Seconds()
+	return result, nil
+}
+
+// readFrom - ファイルの offset から size バイトを読む（大量の追記は上限で区切る）
+func readFrom(path string, offset, size int64) ([]byte, error) {
+	if size > maxLogTailReadBytes {
+		size = maxLogTailReadBytes
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	data := make([]byte, size)
+	n, err := file.ReadAt(data, offset)
+	if err != nil && err != io.EOF {
+		return nil, err
+	}
+	return data[:n], nil
+}
+
+// tailJournal - journalctl でユニットの末尾の行を読み、追記を追う
+func tailJournal(ctx context.Context, opts LogTailOptions) (*LogTailResult, error) {
+	if !journalUnitPattern.MatchString(opts.Unit) {
+		return nil, fmt.Errorf("ユニット名が不正です: %s", opts.Unit)
+	}
+	journalctl, err := exec.LookPath("journalctl")
+	if err != nil {
+		return nil, fmt.Errorf("journalctl が見つかりません")
+	}
+	result := &LogTailResult{Source: "journalctl -u " + opts.Unit}
+
+	output, err := exec.CommandContext(ctx, journalctl, "-u", opts.Unit, "-n", strconv.Itoa(opts.Lines), "--no-pager", "-o", logTailJournalOutput).CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("journalctl エラー: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	var lines []string
+	for _, line := range strings.Split(strings.TrimRight(string(output), "\n"), "\n") {
+		if line != "" && !strings.HasPrefix(line, "-- ") { // "-- No entries --" 等の注記
+			lines = append(lines, line)
+		}
+	}
+	result.addTail(lines, opts)
+
+	if opts.Follow <= 0 {
+		return result, nil
+	}
+
+	started := time.Now()
+	followCtx, cancel := context.WithTimeout(ctx, opts.Follow)
+	defer cancel()
+	cmd := exec.CommandContext(followCtx, journalctl, "-u", opts.Unit, "-f", "-n", "0", "--no-pager", "-o", logTailJournalOutput)
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("journalctl の起動エラー: %w", err)
+	}
+	scanner := bufio.NewScanner(stdout)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if result.addNew(scanner.Text(), opts) {
+			cancel()
+			break
+		}
+	}
+	cancel()
+	_ = cmd.Wait() // 時間切れ・打ち切りで停止させるため終了状態は見ない
+	result.FollowedSeconds = time.Since(started).Round(100 * time.Millisecond).Seconds()
+	return result, nil
+}
+
+// addTail - 末尾の行のうちフィルターに一致する最後の opts.Lines 行を加える
+func (r *LogTailResult) addTail(lines []string, opts LogTailOptions) {
+	var matched []string
+	for _, line := range lines {
+		if opts.Filter == nil || opts.Filter.MatchString(line) {
+			matched = append(matched, line)
+		}
+	}
+	if len(matched) > opts.Lines {
+		matched = matched[len(matched)-opts.Lines:]
+	}
+	for _, line := range matched {
+		r.add(line, false)
+	}
+}
+
+// addNew - 追記された行を加え、上限の行数に達したら true を返す
+func (r *LogTailResult) addNew(line string, opts LogTailOptions) bool {
+	line = strings.TrimRight(line, "\r")
+	if line != "" && (opts.Filter == nil || opts.Filter.MatchString(line)) {
+		r.add(line, true)
+		r.NewLines++
+	}
+	if r.NewLines >= opts.MaxLines {
+		r.Truncated = true
+	}
+	return r.Truncated
+}
+
+// add - 機密情報を伏せ、重大度を推定して行を加える
+func (r *LogTailResult) add(line string, isNew bool) {
+	text := truncateLine(redact.String(strings.TrimRight(line, "\r")), maxLogTailLineRunes)
+	level := LogLevel(text)
+	if level != "" {
+		if r.Levels == nil {
+			r.Levels = make(map[string]int)
+		}
+		r.Levels[level]++
+	}
+	r.Lines = append(r.Lines, LogLine{Text: text, Level: level, New: isNew})
+}
+
+// LogLevel - 行の文言から重大度を推定（分からなければ空）
+func LogLevel(line string) string {
+	for _, p := range logLevelPatterns {
+		if p.pattern.MatchString(line) {
+			return p.level
+		}
+	}
+	return ""
+}
+
+// Format - モデルに渡す形式（重大度の集計、末尾の行、追っている間の追記の順）
+func (r *LogTailResult) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Log: %s\n", r.Source)
+	if r.FollowedSeconds > 0 {
+		fmt.Fprintf(&b, "Followed for %.1fs: %d new line(s)", r.FollowedSeconds, r.NewLines)
+		if r.Truncated {
+			b.WriteString(" (stopped at the line limit)")
+		}
+		b.WriteString("\n")
+	}
+	if len(r.Levels) > 0 {
+		levels := make([]string, 0, len(r.Levels))
+		for level, count := range r.Levels {
+			levels = append(levels, fmt.Sprintf("%s=%d", level, count))
+		}
+		sort.Strings(levels)
+		fmt.Fprintf(&b, "Levels: %s\n", strings.Join(levels, " "))
+	}
+
+	wroteNewHeader := false
+	if len(r.Lines) == 0 || r.Lines[0].New {
+		b.WriteString("(no earlier lines)\n")
+	}
+	for _, line := range r.Lines {
+		if line.New && !wroteNewHeader {
+			b.WriteString("--- new lines while following ---\n")
+			wroteNewHeader = true
+		}
+		b.WriteString(line.Text + "\n")
+	}
+	if r.FollowedSeconds > 0 && r.NewLines == 0 {
+		b.WriteString("--- no new lines while following ---\n")
+	}
+	return b.String()
+}
@@ -8,6 +8,7 @@ import (
 	"sort"
 	"strings"
 
+	"github.com/glkt/vyb-code/internal/ignore"
 	"github.com/glkt/vyb-code/internal/security"
 )
 
@@ -265,7 +266,7 @@ func (t *UnifiedLSTool) listSingle(dirPath string, showHidden bool, ignorePatter
 
 // walkRecursive - 再帰的にディレクトリをウォーク
 func (t *UnifiedLSTool) walkRecursive(rootPath string, showHidden bool, ignorePatterns []string, result *LSResult) error {
-	return filepath.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
+	return ignore.Walk(rootPath, func(path string, info os.FileInfo, err error) error {
 		if err != nil {
 			return nil // エラーのあるパスはスキップ
 		}
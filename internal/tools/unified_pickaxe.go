@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/gitexec"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// git_pickaxe の変更の種類（そのコミットでの検索語の出現数の増減）
+const (
+	PickaxeAdded    = "added"    // 出現が増えただけ（導入）
+	PickaxeRemoved  = "removed"  // 出現が減っただけ（削除）
+	PickaxeModified = "modified" // 増減の両方（移動・書き換え）
+)
+
+// git_pickaxe の上限
+const (
+	maxPickaxeSamples    = 3   // コミット毎に返す一致行の数
+	maxPickaxeSampleRune = 160 // 一致行の最大文字数
+)
+
+// git log -p の1コミットの書式（コミットの先頭を \x1e、フィールドを \x1f で区切り、差分が続く）
+const gitPickaxeFormat = "%x1e%H%x1f%an%x1f%ae%x1f%aI%x1f%s%x1f%b%x1f"
+
+// GitPickaxeFile - コミットで検索語の出現数が変わったファイル
+type GitPickaxeFile struct {
+	Path    string `json:"path"`
+	Added   int    `json:"added"`
+	Removed int    `json:"removed"`
+}
+
+// GitPickaxeCommit - 検索語の出現数を変えたコミット
+type GitPickaxeCommit struct {
+	GitCommitInfo
+	Change  string           `json:"change"`
+	Added   int              `json:"added"`
+	Removed int              `json:"removed"`
+	Files   []GitPickaxeFile `json:"files,omitempty"`
+	Lines   []string         `json:"lines,omitempty"` // 一致した差分行（先頭の数行）
+}
+
+// GitPickaxeResult - git_pickaxe の結果（新しい順）
+type GitPickaxeResult struct {
+	Term      string             `json:"term"`
+	Regex     bool               `json:"regex"`
+	Path      string             `json:"path"`
+	Commits   []GitPickaxeCommit `json:"commits,omitempty"`
+	Truncated bool               `json:"truncated,omitempty"`
+}
+
+// Introduced - 検索語を最初に導入したコミット（見つからなければ nil）
+func (r *GitPickaxeResult) Introduced() *GitPickaxeCommit {
+	for i := len(r.Commits) - 1; i >= 0; i-- {
+		if r.Commits[i].Change == PickaxeAdded {
+			return &r.Commits[i]
+		}
+	}
+	return nil
+}
+
+// Removed - 検索語を消したコミット（最新の変更が削除のときだけ返し、現在も残っていれば nil）
+func (r *GitPickaxeResult) Removed() *GitPickaxeCommit {
+	if len(r.Commits) == 0 || r.Commits[0].Change != PickaxeRemoved {
+		return nil
+	}
+	return &r.Commits[0]
+}
+
+// UnifiedGitPickaxeTool - git log -S/-G で検索語を追加・削除したコミットを探すツール
+type UnifiedGitPickaxeTool struct {
+	*BaseTool
+}
+
+// NewUnifiedGitPickaxeTool - 新しい pickaxe 検索ツールを作成
+func NewUnifiedGitPickaxeTool(constraints *security.Constraints) *UnifiedGitPickaxeTool {
+	base := NewBaseTool("git_pickaxe", "Git の履歴全体から文字列・正規表現を追加・削除したコミットを探します（git log -S/-G）", "1.0.0", CategoryGit)
+	base.AddCapability(CapabilityGit)
+	base.AddCapability(CapabilityFileRead)
+	base.SetConstraints(constraints)
+
+	schema := ToolSchema{
+		Name:        "git_pickaxe",
+		Description: "関数名などがいつ導入・削除されたかを、出現数を変えたコミットと増減・一致行付きで返します",
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"term": {
+				Type:        "string",
+				Description: "検索する文字列（regex=true なら正規表現）",
+			},
+			"regex": {
+				Type:        "boolean",
+				Description: "false: 出現数が変わったコミット (git log -S), true: 一致する行を変更したコミット (git log -G)",
+				Default:     false,
+			},
+			"path": {
+				Type:        "string",
+				Description: "検索を限定するファイル・ディレクトリ（省略時はワークスペース全体）",
+			},
+			"max_count": {
+				Type:        "integer",
+				Description: "返すコミットの最大件数（省略時10）",
+				Minimum:     floatPtr(1),
+				Maximum:     floatPtr(maxGitHistory),
+			},
+		},
+		Required: []string{"term"},
+		Examples: []ToolExample{
+			{
+				Description: "関数がいつ導入・削除されたかを調べる",
+				Parameters: map[string]interface{}{
+					"term": "func applyDefaults",
+				},
+			},
+			{
+				Description: "ディレクトリ内で正規表現に一致する行を変えたコミットを探す",
+				Parameters: map[string]interface{}{
+					"term":  `Timeout\s*=`,
+					"regex": true,
+					"path":  "internal/config",
+				},
+			},
+		},
+	}
+	base.SetSchema(schema)
+
+	return &UnifiedGitPickaxeTool{BaseTool: base}
+}
+
+// Execute - pickaxe 検索を実行
+func (t *UnifiedGitPickaxeTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	if err := t.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	term, _ := request.Parameters["term"].(string)
+	if strings.TrimSpace(term) == "" {
+		return nil, NewToolError("invalid_parameter", "term parameter is required")
+	}
+	regex, _ := request.Parameters["regex"].(bool)
+	path, _ := request.Parameters["path"].(string)
+	if strings.TrimSpace(path) == "" {
+		path = "."
+	}
+	if strings.Contains(path, "..") {
+		return nil, NewToolError("security_violation", "Path contains invalid characters: "+path)
+	}
+	if t.constraints != nil {
+		resolved, err := t.constraints.ResolvePathFor(path, "read")
+		if err != nil {
+			return nil, NewToolError("security_violation", "Invalid path: "+err.Error())
+		}
+		path = resolved
+	}
+	maxCount := intParam(request.Parameters, "max_count", defaultGitHistory)
+	if maxCount > maxGitHistory {
+		maxCount = maxGitHistory
+	}
+
+	result, err := gitPickaxe(ctx, path, term, regex, maxCount)
+	if err != nil {
+		return nil, NewToolError("execution_failed", err.Error())
+	}
+
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  formatGitPickaxeResult(result),
+		Data:     result,
+		Metadata: &ResponseMetadata{
+			Debug: map[string]interface{}{
+				"commits":   len(result.Commits),
+				"truncated": result.Truncated,
+			},
+		},
+	}, nil
+}
+
+// gitPickaxe - 検索語の出現数を変えたコミットを新しい順に探し、差分から増減を数える
+func gitPickaxe(ctx context.Context, path, term string, regex bool, maxCount int) (*GitPickaxeResult, error) {
+	dir, pathspec := path, "."
+	if info, err := os.Stat(path); err == nil && !info.IsDir() {
+		dir, pathspec = filepath.Dir(path), filepath.Base(path)
+	}
+
+	// 一致行の数え方（git の POSIX 正規表現と細部は異なるが、増減の判定には十分）
+	count := func(line string) int { return strings.Count(line, term) }
+	args := []string{"log", "-n", strconv.Itoa(maxCount + 1), "--no-color", "--no-ext-diff", "--unified=0", "-p", "--format=" + gitPickaxeFormat}
+	if regex {
+		pattern, err := regexp.Compile(term)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		count = func(line string) int { return len(pattern.FindAllStringIndex(line, -1)) }
+		args = append(args, "-G"+term)
+	} else {
+		args = append(args, "-S"+term)
+	}
+	args = append(args, "--", pathspec)
+
+	output, err := gitexec.Run(ctx, dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	commits := parseGitPickaxe(output, count)
+	result := &GitPickaxeResult{Term: term, Regex: regex, Path: path, Commits: commits}
+	if len(commits) > maxCount {
+		result.Commits, result.Truncated = commits[:maxCount], true
+	}
+	return result, nil
+}
+
+// parseGitPickaxe - gitPickaxeFormat と差分の出力を、ファイル毎の増減付きのコミット一覧に変換
+func parseGitPickaxe(output string, count func(string) int) []GitPickaxeCommit {
+	var commits []GitPickaxeCommit
+	for _, record := range strings.Split(output, "\x1e") {
+		fields := strings.SplitN(record, "\x1f", 7)
+		if len(fields) < 7 || fields[0] == "" {
+			continue
+		}
+		commit := GitPickaxeCommit{GitCommitInfo: GitCommitInfo{
+			Hash:    fields[0],
+			Author:  fields[1],
+			Email:   fields[2],
+			Date:    fields[3],
+			Subject: fields[4],
+			Body:    strings.TrimSpace(fields[5]),
+		}}
+
+		var file *GitPickaxeFile
+		for _, line := range strings.Split(fields[6], "\n") {
+			switch {
+			case strings.HasPrefix(line, "diff --git "):
+				name := line[len("diff --git "):]
+				if i := strings.LastIndex(name, " b/"); i >= 0 {
+					name = name[i+len(" b/"):]
+				}
+				commit.Files = append(commit.Files, GitPickaxeFile{Path: name})
+				file = &commit.Files[len(commit.Files)-1]
+			case file == nil, strings.HasPrefix(line, "+++ "), strings.HasPrefix(line, "--- "):
+			case strings.HasPrefix(line, "+"), strings.HasPrefix(line, "-"):
+				n := count(line[1:])
+				if n == 0 {
+					continue
+				}
+				if line[0] == '+' {
+					file.Added += n
+				} else {
+					file.Removed += n
+				}
+				if len(commit.Lines) < maxPickaxeSamples {
+					commit.Lines = append(commit.Lines, truncateLine(line[:1]+strings.TrimSpace(line[1:]), maxPickaxeSampleRune))
+				}
+			}
+		}
+
+		// 検索語が差分の中に現れなかったファイルは除く
+		files := commit.Files[:0]
+		for _, f := range commit.Files {
+			if f.Added+f.Removed > 0 {
+				files = append(files, f)
+				commit.Added += f.Added
+				commit.Removed += f.Removed
+			}
+		}
+		commit.Files = files
+		switch {
+		case commit.Removed == 0:
+			commit.Change = PickaxeAdded
+		case commit.Added == 0:
+			commit.Change = PickaxeRemoved
+		default:
+			commit.Change = PickaxeModified
+		}
+		commits = append(commits, commit)
+	}
+	return commits
+}
+
+// formatGitPickaxeResult - LLMに渡すテキスト形式
+func formatGitPickaxeResult(result *GitPickaxeResult) string {
+	var b strings.Builder
+	kind := "string"
+	if result.Regex {
+		kind = "regex"
+	}
+	if len(result.Commits) == 0 {
+		fmt.Fprintf(&b, "No commits added or removed the %s %q in %s\n", kind, result.Term, result.Path)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Commits that added or removed the %s %q in %s (%d, newest first).\n", kind, result.Term, result.Path, len(result.Commits))
+	if introduced := result.Introduced(); introduced != nil {
+		fmt.Fprintf(&b, "Introduced in %s on %s by %s: %s\n", shortHash(introduced.Hash), introduced.Date, introduced.Author, introduced.Subject)
+	}
+	if removed := result.Removed(); removed != nil {
+		fmt.Fprintf(&b, "Removed in %s on %s by %s: %s\n", shortHash(removed.Hash), removed.Date, removed.Author, removed.Subject)
+	} else {
+		b.WriteString("Still present after the newest matching commit.\n")
+	}
+	b.WriteString("\n")
+
+	for _, commit := range result.Commits {
+		fmt.Fprintf(&b, "%s %s %s [%s +%d/-%d]: %s\n", shortHash(commit.Hash), commit.Date, commit.Author, commit.Change, commit.Added, commit.Removed, commit.Subject)
+		for _, f := range commit.Files {
+			fmt.Fprintf(&b, "    %s (+%d/-%d)\n", f.Path, f.Added, f.Removed)
+		}
+		for _, line := range commit.Lines {
+			fmt.Fprintf(&b, "      %s\n", line)
+		}
+	}
+	if result.Truncated {
+		b.WriteString("... (older commits omitted; raise max_count or narrow the path)\n")
+	}
+	return b.String()
+}
@@ -3,12 +3,17 @@ package tools
 import (
 	"context"
 	"fmt"
+	"net/url"
 	"sort"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/events"
 	"github.com/glkt/vyb-code/internal/mcp"
+	"github.com/glkt/vyb-code/internal/sandbox"
+	"github.com/glkt/vyb-code/internal/scheduler"
 	"github.com/glkt/vyb-code/internal/security"
 )
 
@@ -19,6 +24,7 @@ type UnifiedToolRegistry struct {
 	categories  map[ToolCategory][]string
 	constraints *security.Constraints
 	mcpManager  *mcp.Manager
+	scheduler   *scheduler.Scheduler // 同時実行数の制限（nil なら制限しない）
 
 	// 実行統計
 	execStats   map[string]*ToolExecutionStats
@@ -154,6 +160,29 @@ func (r *UnifiedToolRegistry) ListTools() []UnifiedToolInterface {
 	return tools
 }
 
+// ToolNames - 有効なツール名と説明の一覧を取得（MCPツールは "server.tool"）
+func (r *UnifiedToolRegistry) ToolNames() map[string]string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make(map[string]string, len(r.tools))
+	for name, tool := range r.tools {
+		if tool.IsEnabled() {
+			names[name] = tool.GetDescription()
+		}
+	}
+
+	if r.mcpManager != nil {
+		for server, serverTools := range r.mcpManager.GetAllTools() {
+			for _, tool := range serverTools {
+				names[server+"."+tool.Name] = tool.Description
+			}
+		}
+	}
+
+	return names
+}
+
 // ListToolsByCategory - カテゴリ別ツール一覧を取得
 func (r *UnifiedToolRegistry) ListToolsByCategory(category ToolCategory) []UnifiedToolInterface {
 	r.mu.RLock()
@@ -194,16 +223,27 @@ func (r *UnifiedToolRegistry) ExecuteTool(ctx context.Context, request *ToolRequ
 		}
 	}
 
+	// 同時実行数の上限に達していれば空きを待つ
+	r.mu.RLock()
+	sched := r.scheduler
+	r.mu.RUnlock()
+	release, err := sched.Acquire(ctx, scheduler.ClassTool)
+	if err != nil {
+		return r.createErrorResponse(request, err), err
+	}
+
 	// 実行統計記録開始
 	startTime := time.Now()
 
 	// ツール実行
 	response, err := tool.Execute(ctx, request)
+	release()
 
 	// 実行統計更新
 	r.updateExecutionStats(request.ToolName, startTime, err == nil)
 
 	if err != nil {
+		publishToolExecuted(request, nil, err, time.Since(startTime))
 		return r.createErrorResponse(request, err), err
 	}
 
@@ -218,9 +258,61 @@ func (r *UnifiedToolRegistry) ExecuteTool(ctx context.Context, request *ToolRequ
 		response.Duration = time.Since(startTime)
 	}
 
+	publishToolExecuted(request, response, nil, response.Duration)
 	return response, nil
 }
 
+// publishToolExecuted はツール実行（ファイル変更ツールの成功時は変更の適用も）をイベントバスに通知
+func publishToolExecuted(request *ToolRequest, response *ToolResponse, err error, duration time.Duration) {
+	bus := events.Default()
+	if !bus.HasSubscribers() {
+		return
+	}
+	sessionID := ""
+	if request.Context != nil {
+		sessionID = request.Context.SessionID
+	}
+
+	data := map[string]interface{}{
+		"tool":        request.ToolName,
+		"success":     err == nil && response.Success,
+		"duration_ms": duration.Milliseconds(),
+	}
+	if err != nil {
+		data["error"] = err.Error()
+	} else if response.Error != "" {
+		data["error"] = response.Error
+	}
+	bus.Publish(events.Event{Type: events.ToolExecuted, SessionID: sessionID, Data: data})
+
+	if err != nil || !response.Success {
+		return
+	}
+	publishEdit := func(path string) {
+		bus.Publish(events.Event{Type: events.EditApplied, SessionID: sessionID, Data: map[string]interface{}{
+			"path":   path,
+			"source": request.ToolName,
+		}})
+	}
+	switch request.ToolName {
+	case "write", "edit", "multiedit":
+		if path, ok := request.Parameters["file_path"].(string); ok {
+			publishEdit(path)
+		}
+	case "apply_patch":
+		// パッチは複数のファイルを変更するため、適用結果のファイル毎に通知する
+		if dryRun, _ := request.Parameters["dry_run"].(bool); dryRun {
+			return
+		}
+		files, _ := response.Data.([]PatchFileResult)
+		for _, file := range files {
+			if file.Action != "delete" {
+				publishEdit(file.Path)
+			}
+		}
+	}
+}
+
 // GetToolSchemas - 全ツールのスキーマを取得
 func (r *UnifiedToolRegistry) GetToolSchemas() map[string]ToolSchema {
 	r.mu.RLock()
@@ -322,6 +414,75 @@ func (r *UnifiedToolRegistry) SearchTools(query string, category ToolCategory, c
 	return results
 }
 
+// SetScheduler - ツールの同時実行数を制限するスケジューラーを設定
+func (r *UnifiedToolRegistry) SetScheduler(s *scheduler.Scheduler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.scheduler = s
+}
+
+// SetExecutionBackend - コマンド実行ツールの実行バックエンドを設定
+func (r *UnifiedToolRegistry) SetExecutionBackend(backend sandbox.Backend) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, tool := range r.tools {
+		if bashTool, ok := tool.(*UnifiedBashTool); ok {
+			bashTool.SetBackend(backend)
+		}
+	}
+}
+
+// SetPermissions - ファイル・コマンドのツールにプロジェクト・ユーザーの許可規則を設定
+func (r *UnifiedToolRegistry) SetPermissions(permissions *security.Permissions) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.constraints != nil {
+		r.constraints.Permissions = permissions
+	}
+}
+
+// SetNetworkPolicy - 外部通信を行うツールにネットワークポリシーを設定
+func (r *UnifiedToolRegistry) SetNetworkPolicy(policy *security.NetworkPolicy) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if r.constraints != nil {
+		r.constraints.NetworkPolicy = policy
+	}
+
+	for _, tool := range r.tools {
+		switch webTool := tool.(type) {
+		case *UnifiedWebFetchTool:
+			webTool.SetNetworkPolicy(policy)
+		case *UnifiedWebSearchTool:
+			webTool.SetNetworkPolicy(policy)
+		}
+	}
+}
+
+// ConfigureWebTools - Webツールの検索バックエンドと取得オプションを設定
+func (r *UnifiedToolRegistry) ConfigureWebTools(cfg config.WebToolsConfig) error {
+	backend, err := NewSearchBackend(cfg)
+	if err != nil {
+		return err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, tool := range r.tools {
+		switch webTool := tool.(type) {
+		case *UnifiedWebFetchTool:
+			webTool.SetFetchOptions(cfg.FetchMaxBytes, time.Duration(cfg.CacheTTLMinutes)*time.Minute)
+		case *UnifiedWebSearchTool:
+			webTool.SetSearchBackend(backend)
+		}
+	}
+	return nil
+}
+
 // 内部メソッド
 
 // registerDefaultTools - デフォルトツールを登録
@@ -330,10 +491,21 @@ func (r *UnifiedToolRegistry) registerDefaultTools() {
 	readTool := NewUnifiedReadTool(r.constraints)
 	writeTool := NewUnifiedWriteTool(r.constraints)
 	editTool := NewUnifiedEditTool(r.constraints)
+	multiEditTool := NewUnifiedMultiEditTool(r.constraints)
+	applyPatchTool := NewUnifiedApplyPatchTool(r.constraints)
+	moveTool := NewUnifiedMoveTool(r.constraints)
+	deleteTool := NewUnifiedDeleteTool(r.constraints)
+
+	batchReadTool := NewUnifiedBatchReadTool(r.constraints)
 
 	r.RegisterTool(readTool)
 	r.RegisterTool(writeTool)
 	r.RegisterTool(editTool)
+	r.RegisterTool(multiEditTool)
+	r.RegisterTool(applyPatchTool)
+	r.RegisterTool(moveTool)
+	r.RegisterTool(deleteTool)
+	r.RegisterTool(batchReadTool)
 
 	// コマンドツール
 	bashTool := NewUnifiedBashTool(r.constraints)
@@ -343,10 +515,20 @@ func (r *UnifiedToolRegistry) registerDefaultTools() {
 	globTool := NewUnifiedGlobTool(r.constraints)
 	grepTool := NewUnifiedGrepTool(r.constraints)
 	lsTool := NewUnifiedLSTool(r.constraints)
+	treeTool := NewUnifiedTreeTool(r.constraints)
 
 	r.RegisterTool(globTool)
 	r.RegisterTool(grepTool)
 	r.RegisterTool(lsTool)
+	r.RegisterTool(treeTool)
+
+	// Gitツール
+	r.RegisterTool(NewUnifiedGitHistoryTool(r.constraints))
+	r.RegisterTool(NewUnifiedGitPickaxeTool(r.constraints))
+
+	// 診断ツール
+	r.RegisterTool(NewUnifiedDiagnosticsTool(r.constraints))
+	r.RegisterTool(NewUnifiedLogTailTool(r.constraints))
 
 	// Webツール
 	webFetchTool := NewUnifiedWebFetchTool(r.constraints)
@@ -378,6 +560,17 @@ func (r *UnifiedToolRegistry) validateSecurity(tool UnifiedToolInterface, reques
 		}
 	}
 
+	// 外部通信ツールの場合（ネットワークポリシーで事前に拒否）
+	if hasCapability(tool.GetCapabilities(), CapabilityNetwork) && r.constraints != nil && r.constraints.NetworkPolicy != nil {
+		if rawURL, ok := request.Parameters["url"].(string); ok {
+			if parsed, err := url.Parse(rawURL); err == nil {
+				if err := r.constraints.NetworkPolicy.IsHostAllowed(parsed.Host); err != nil {
+					return NewToolError("security_violation", "Network access denied: "+err.Error())
+				}
+			}
+		}
+	}
+
 	// コマンド実行ツールの場合
 	if hasCapability(tool.GetCapabilities(), CapabilityCommand) {
 		if command, ok := request.Parameters["command"].(string); ok {
@@ -3,11 +3,14 @@ package tools
 import (
 	"context"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"regexp"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/glkt/vyb-code/internal/journal"
 	"github.com/glkt/vyb-code/internal/security"
 )
 
@@ -66,6 +69,17 @@ func TestUnifiedToolRegistry_RegisterAndExecute(t *testing.T) {
 	})
 }
 
+func TestUnifiedToolRegistry_ToolNames(t *testing.T) {
+	registry := NewUnifiedToolRegistry(security.NewDefaultConstraints(t.TempDir()), nil)
+
+	names := registry.ToolNames()
+	for _, expected := range []string{"read", "write", "edit", "multiedit", "apply_patch", "move", "delete", "bash", "tree", "git_pickaxe", "diagnostics", "log_tail"} {
+		if names[expected] == "" {
+			t.Errorf("Expected tool %q with description in %v", expected, names)
+		}
+	}
+}
+
 func TestUnifiedWriteTool_Execute(t *testing.T) {
 	tempDir, err := os.MkdirTemp("", "unified-write-test-*")
 	if err != nil {
@@ -315,6 +329,261 @@ func main() {
 	})
 }
 
+func TestUnifiedMultiEditTool_Execute(t *testing.T) {
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "rename.go")
+	original := "package main\n\nfunc oldName() {}\n\nfunc main() {\n\toldName()\n\toldName()\n}\n"
+	if err := os.WriteFile(testFile, []byte(original), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewUnifiedMultiEditTool(security.NewDefaultConstraints(tempDir))
+	execute := func(path string, edits ...map[string]interface{}) (*ToolResponse, error) {
+		items := make([]interface{}, 0, len(edits))
+		for _, edit := range edits {
+			items = append(items, edit)
+		}
+		return tool.Execute(context.Background(), &ToolRequest{
+			ID:         "multiedit-1",
+			ToolName:   "multiedit",
+			Parameters: map[string]interface{}{"file_path": path, "edits": items},
+		})
+	}
+
+	t.Run("1つでも失敗すればファイルは変更しない", func(t *testing.T) {
+		_, err := execute(testFile,
+			map[string]interface{}{"old_string": "func oldName()", "new_string": "func newName()"},
+			map[string]interface{}{"old_string": "missing()", "new_string": "found()"},
+		)
+		if err == nil || !strings.Contains(err.Error(), "編集 2") {
+			t.Fatalf("Expected error for the second edit, got %v", err)
+		}
+		content, _ := os.ReadFile(testFile)
+		if string(content) != original {
+			t.Errorf("File should be unchanged after a failed edit:\n%s", content)
+		}
+	})
+
+	t.Run("編集は前の編集の結果に順に適用", func(t *testing.T) {
+		response, err := execute(testFile,
+			map[string]interface{}{"old_string": "func oldName()", "new_string": "func newName()"},
+			map[string]interface{}{"old_string": "oldName()", "new_string": "newName()", "replace_all": true},
+			map[string]interface{}{"old_string": "func newName() {}", "new_string": "func newName() { println(1) }"},
+		)
+		if err != nil || !response.Success {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		content, _ := os.ReadFile(testFile)
+		expected := "package main\n\nfunc newName() { println(1) }\n\nfunc main() {\n\tnewName()\n\tnewName()\n}\n"
+		if string(content) != expected {
+			t.Errorf("unexpected content:\n%s", content)
+		}
+		if response.Metadata.Debug["total_replacements"] != 4 {
+			t.Errorf("total_replacements = %v, want 4", response.Metadata.Debug["total_replacements"])
+		}
+	})
+
+	t.Run("最初の old_string が空なら新規作成", func(t *testing.T) {
+		newFile := filepath.Join(tempDir, "new.txt")
+		_, err := execute(newFile,
+			map[string]interface{}{"old_string": "", "new_string": "hello NAME\n"},
+			map[string]interface{}{"old_string": "NAME", "new_string": "world"},
+		)
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		content, _ := os.ReadFile(newFile)
+		if string(content) != "hello world\n" {
+			t.Errorf("unexpected content: %q", content)
+		}
+
+		// 後続の編集が失敗した場合は作成しない
+		failed := filepath.Join(tempDir, "failed.txt")
+		if _, err := execute(failed,
+			map[string]interface{}{"old_string": "", "new_string": "hello\n"},
+			map[string]interface{}{"old_string": "missing", "new_string": "x"},
+		); err == nil {
+			t.Fatal("Expected error for the second edit")
+		}
+		if _, err := os.Stat(failed); !os.IsNotExist(err) {
+			t.Error("File should not be created when a later edit fails")
+		}
+	})
+}
+
+func TestApplyPatchTool_Apply(t *testing.T) {
+	tempDir := t.TempDir()
+	tool := NewApplyPatchTool(security.NewDefaultConstraints(tempDir), tempDir, 1024*1024)
+
+	writeFile := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tempDir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	readFile := func(name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(tempDir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(content)
+	}
+
+	t.Run("行番号と空白のずれを許して適用", func(t *testing.T) {
+		writeFile("calc.go", "package calc\n\n// 追加された行\n\nfunc Add(a, b int) int {\n\treturn a + b \n}\n")
+		// 行番号が2行ずれ、字下げが空白、行末の空白もない
+		patch := "--- a/calc.go\n+++ b/calc.go\n@@ -3,3 +3,4 @@\n func Add(a, b int) int {\n-    return a + b\n+    sum := a + b\n+    return sum\n }\n"
+		result, err := tool.Apply(ApplyPatchRequest{Patch: patch})
+		if err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		expected := "package calc\n\n// 追加された行\n\nfunc Add(a, b int) int {\n\tsum := a + b\n\treturn sum\n}\n"
+		if got := readFile("calc.go"); got != expected {
+			t.Errorf("unexpected content:\n%s", got)
+		}
+		if files := result.Metadata["files"].([]PatchFileResult); files[0].Fuzzy != 1 || files[0].Added != 2 || files[0].Deleted != 1 {
+			t.Errorf("unexpected result: %+v", files)
+		}
+	})
+
+	t.Run("一部のハンクが当たらなければ何も書き込まない", func(t *testing.T) {
+		writeFile("a.txt", "one\ntwo\nthree\n")
+		writeFile("b.txt", "alpha\nbeta\n")
+		patch := "--- a/a.txt\n+++ b/a.txt\n@@ -1,3 +1,3 @@\n one\n-two\n+TWO\n three\n" +
+			"--- a/b.txt\n+++ b/b.txt\n@@ -1,2 +1,2 @@\n alpha\n-gamma\n+delta\n"
+		result, err := tool.Apply(ApplyPatchRequest{Patch: patch})
+		if err == nil || !result.IsError {
+			t.Fatal("Expected error for a hunk that does not match")
+		}
+		if !strings.Contains(result.Content, "b.txt") {
+			t.Errorf("Error should name the failing file: %s", result.Content)
+		}
+		if got := readFile("a.txt"); got != "one\ntwo\nthree\n" {
+			t.Errorf("a.txt should be unchanged, got %q", got)
+		}
+	})
+
+	t.Run("新規作成と削除", func(t *testing.T) {
+		writeFile("old.txt", "bye\n")
+		patch := "diff --git a/new.txt b/new.txt\nnew file mode 100644\n--- /dev/null\n+++ b/new.txt\n@@ -0,0 +1,2 @@\n+hello\n+world\n" +
+			"diff --git a/old.txt b/old.txt\ndeleted file mode 100644\n--- a/old.txt\n+++ /dev/null\n@@ -1 +0,0 @@\n-bye\n"
+		if _, err := tool.Apply(ApplyPatchRequest{Patch: patch}); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if got := readFile("new.txt"); got != "hello\nworld\n" {
+			t.Errorf("unexpected new file content: %q", got)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "old.txt")); !os.IsNotExist(err) {
+			t.Error("old.txt should be deleted")
+		}
+	})
+
+	t.Run("既存のファイルへのリネーム・新規作成は上書きしない", func(t *testing.T) {
+		writeFile("from.go", "package a\n")
+		writeFile("to.go", "package keep\n")
+		rename := "--- a/from.go\n+++ b/to.go\n@@ -1 +1 @@\n-package a\n+package b\n"
+		if _, err := tool.Apply(ApplyPatchRequest{Patch: rename}); err == nil {
+			t.Error("Expected error for a rename onto an existing file")
+		}
+		create := "--- /dev/null\n+++ b/to.go\n@@ -0,0 +1 @@\n+package c\n"
+		if _, err := tool.Apply(ApplyPatchRequest{Patch: create}); err == nil {
+			t.Error("Expected error for a new file that already exists")
+		}
+		if readFile("from.go") != "package a\n" || readFile("to.go") != "package keep\n" {
+			t.Errorf("files should be unchanged: %q %q", readFile("from.go"), readFile("to.go"))
+		}
+	})
+
+	t.Run("書き込みに失敗したらリネームを元に戻す", func(t *testing.T) {
+		writeFile("moved.go", "package moved\n")
+		writeFile("blocker.txt", "not a directory\n")
+		plans := []*plannedPatch{
+			{
+				result:   PatchFileResult{Path: "renamed.go", OldPath: "moved.go", Action: "rename"},
+				absPath:  filepath.Join(tempDir, "renamed.go"),
+				oldAbs:   filepath.Join(tempDir, "moved.go"),
+				content:  "package renamed\n",
+				original: []byte("package moved\n"),
+			},
+			{
+				result:  PatchFileResult{Path: "blocker.txt/new.go", Action: "create"},
+				absPath: filepath.Join(tempDir, "blocker.txt", "new.go"),
+				content: "package new\n",
+			},
+		}
+		if err := commitPatch(plans); err == nil {
+			t.Fatal("Expected error when a file cannot be written")
+		}
+		if got := readFile("moved.go"); got != "package moved\n" {
+			t.Errorf("source should be restored, got %q", got)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "renamed.go")); !os.IsNotExist(err) {
+			t.Error("rename destination should be removed on rollback")
+		}
+	})
+
+	t.Run("大文字・小文字だけのリネームでファイルを消さない", func(t *testing.T) {
+		writeFile("case.go", "package lower\n")
+		patch := "--- a/case.go\n+++ b/Case.go\n@@ -1 +1 @@\n-package lower\n+package upper\n"
+		if _, err := tool.Apply(ApplyPatchRequest{Patch: patch}); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+		if got := readFile("Case.go"); got != "package upper\n" {
+			t.Errorf("unexpected renamed content: %q", got)
+		}
+
+		// 大文字・小文字を区別しないファイルシステムでは新旧のパスが同じファイルを指す
+		same := filepath.Join(tempDir, "same.go")
+		writeFile("same.go", "package before\n")
+		plan := &plannedPatch{
+			result:   PatchFileResult{Path: "same.go", OldPath: "same.go", Action: "rename"},
+			absPath:  same,
+			oldAbs:   same,
+			content:  "package after\n",
+			original: []byte("package before\n"),
+		}
+		if err := writePlannedPatch(plan); err != nil {
+			t.Fatalf("writePlannedPatch failed: %v", err)
+		}
+		if got := readFile("same.go"); got != "package after\n" {
+			t.Errorf("file pointed to by both paths should be kept, got %q", got)
+		}
+		revertPlannedPatch(plan)
+		if got := readFile("same.go"); got != "package before\n" {
+			t.Errorf("revert should restore the original, got %q", got)
+		}
+	})
+
+	t.Run("行番号がなく候補が複数ならエラー", func(t *testing.T) {
+		writeFile("dup.txt", "x := 1\nx := 1\n")
+		patch := "--- a/dup.txt\n+++ b/dup.txt\n@@ @@\n-x := 1\n+x := 2\n"
+		if _, err := tool.Apply(ApplyPatchRequest{Patch: patch}); err == nil {
+			t.Error("Expected error for an ambiguous hunk")
+		}
+		if got := readFile("dup.txt"); got != "x := 1\nx := 1\n" {
+			t.Errorf("dup.txt should be unchanged, got %q", got)
+		}
+	})
+
+	t.Run("統一ツールからの dry run", func(t *testing.T) {
+		writeFile("dry.txt", "before\n")
+		response, err := NewUnifiedApplyPatchTool(security.NewDefaultConstraints(tempDir)).Execute(context.Background(), &ToolRequest{
+			ID:       "patch-1",
+			ToolName: "apply_patch",
+			Parameters: map[string]interface{}{
+				"patch":   "--- " + filepath.Join(tempDir, "dry.txt") + "\n+++ " + filepath.Join(tempDir, "dry.txt") + "\n@@ -1 +1 @@\n-before\n+after\n",
+				"dry_run": true,
+			},
+		})
+		if err != nil || !response.Success {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		if got := readFile("dry.txt"); got != "before\n" {
+			t.Errorf("dry run should not write, got %q", got)
+		}
+	})
+}
+
 func TestUnifiedBashTool_Execute(t *testing.T) {
 	constraints := security.NewDefaultConstraints(".")
 	tool := NewUnifiedBashTool(constraints)
@@ -479,3 +748,602 @@ func TestToolRegistry_Statistics(t *testing.T) {
 func contains(s, substr string) bool {
 	return strings.Contains(s, substr)
 }
+
+func TestUnifiedBatchReadTool_Execute(t *testing.T) {
+	tempDir := t.TempDir()
+	files := map[string]string{
+		"main.go":         "package main\n",
+		"pkg/util.go":     "package pkg\n\nfunc Util() {}\n",
+		"pkg/sub/deep.go": "package sub\n",
+		"README.md":       strings.Repeat("x", 100),
+	}
+	for name, content := range files {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tool := NewUnifiedBatchReadTool(security.NewDefaultConstraints(tempDir))
+
+	t.Run("Expand globs and read concurrently", func(t *testing.T) {
+		request := &ToolRequest{
+			ToolName: "batch_read",
+			Parameters: map[string]interface{}{
+				"paths": []interface{}{filepath.Join(tempDir, "**", "*.go"), filepath.Join(tempDir, "main.go"), filepath.Join(tempDir, "missing.txt")},
+			},
+		}
+		response, err := tool.Execute(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Batch read failed: %v", err)
+		}
+
+		bundle, ok := response.Data.(*BatchReadBundle)
+		if !ok {
+			t.Fatalf("Expected *BatchReadBundle, got %T", response.Data)
+		}
+		// 重複は除外、存在しないファイルはエラーとして記録
+		if len(bundle.Files) != 4 {
+			t.Fatalf("Expected 4 files, got %d: %+v", len(bundle.Files), bundle.Files)
+		}
+		var readable int
+		for _, file := range bundle.Files {
+			if file.Error == "" {
+				readable++
+			}
+		}
+		if readable != 3 {
+			t.Errorf("Expected 3 readable files, got %d", readable)
+		}
+		if !strings.Contains(response.Content, "func Util() {}") {
+			t.Errorf("Content missing file body: %s", response.Content)
+		}
+		if items := bundle.ContextItems(); len(items) != 3 || items[0].Metadata["file"] == "" {
+			t.Errorf("Unexpected context items: %d", len(items))
+		}
+	})
+
+	t.Run("Apply size budgets", func(t *testing.T) {
+		request := &ToolRequest{
+			ToolName: "batch_read",
+			Parameters: map[string]interface{}{
+				"paths":           []interface{}{filepath.Join(tempDir, "README.md"), filepath.Join(tempDir, "main.go"), filepath.Join(tempDir, "pkg", "util.go")},
+				"max_file_bytes":  float64(50),
+				"max_total_bytes": float64(60),
+			},
+		}
+		response, err := tool.Execute(context.Background(), request)
+		if err != nil {
+			t.Fatalf("Batch read failed: %v", err)
+		}
+
+		bundle := response.Data.(*BatchReadBundle)
+		if bundle.TotalBytes > 60 {
+			t.Errorf("Total budget exceeded: %d", bundle.TotalBytes)
+		}
+		if len(bundle.Files) == 0 || !bundle.Files[0].Truncated || len(bundle.Skipped) == 0 {
+			t.Errorf("Expected truncation and skipped files: %+v", bundle)
+		}
+	})
+
+	t.Run("Reject parent traversal", func(t *testing.T) {
+		request := &ToolRequest{
+			ToolName:   "batch_read",
+			Parameters: map[string]interface{}{"paths": "../etc/passwd"},
+		}
+		if _, err := tool.Execute(context.Background(), request); err == nil {
+			t.Error("Expected error for parent traversal")
+		}
+	})
+}
+
+func TestUnifiedGitHistoryTool_Execute(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	tempDir := t.TempDir()
+	testFile := filepath.Join(tempDir, "calc.go")
+
+	// 作者の異なる2つのコミットで同じ関数を変更
+	commit := func(author, message, content string) {
+		if err := os.WriteFile(testFile, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, args := range [][]string{
+			{"add", "calc.go"},
+			{"-c", "user.name=" + author, "-c", "user.email=" + strings.ToLower(author) + "@example.com", "commit", "-q", "-m", message},
+		} {
+			cmd := exec.Command("git", args...)
+			cmd.Dir = tempDir
+			if output, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, output)
+			}
+		}
+	}
+	cmd := exec.Command("git", "init", "-q")
+	cmd.Dir = tempDir
+	if output, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, output)
+	}
+	commit("Alice", "Add calc", "package calc\n\nfunc Add(a, b int) int {\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n")
+	commit("Bob", "Guard against overflow in Add", "package calc\n\nfunc Add(a, b int) int {\n\t// overflow check\n\treturn a + b\n}\n\nfunc Sub(a, b int) int {\n\treturn a - b\n}\n")
+
+	tool := NewUnifiedGitHistoryTool(security.NewDefaultConstraints(tempDir))
+	execute := func(params map[string]interface{}) *GitHistoryResult {
+		t.Helper()
+		params["file_path"] = testFile
+		response, err := tool.Execute(context.Background(), &ToolRequest{ID: "git-1", ToolName: "git_history", Parameters: params})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return response.Data.(*GitHistoryResult)
+	}
+
+	t.Run("File history", func(t *testing.T) {
+		result := execute(map[string]interface{}{"operation": GitHistoryLog})
+		if len(result.Commits) != 2 || result.Commits[1].Author != "Alice" {
+			t.Errorf("Expected 2 commits newest first, got %+v", result.Commits)
+		}
+	})
+
+	t.Run("Who last touched a function", func(t *testing.T) {
+		last := execute(map[string]interface{}{"operation": GitHistorySymbol, "symbol": "Add"}).LastTouched()
+		if last == nil || last.Author != "Bob" || last.Subject != "Guard against overflow in Add" {
+			t.Errorf("Expected Bob's commit, got %+v", last)
+		}
+		last = execute(map[string]interface{}{"operation": GitHistorySymbol, "symbol": "Sub"}).LastTouched()
+		if last == nil || last.Author != "Alice" {
+			t.Errorf("Expected Alice's commit for Sub, got %+v", last)
+		}
+	})
+
+	t.Run("Blame", func(t *testing.T) {
+		result := execute(map[string]interface{}{"operation": GitHistoryBlame, "start_line": 3, "end_line": 4})
+		if len(result.Blame) != 2 {
+			t.Fatalf("Expected 2 blame lines, got %+v", result.Blame)
+		}
+		if result.Blame[0].Author != "Alice" || result.Blame[1].Author != "Bob" || result.Blame[1].Content != "\t// overflow check" {
+			t.Errorf("Unexpected blame: %+v", result.Blame)
+		}
+	})
+
+	t.Run("Outside workspace", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), &ToolRequest{ID: "git-2", ToolName: "git_history", Parameters: map[string]interface{}{"file_path": "/etc/passwd"}})
+		if err == nil {
+			t.Error("Expected error for path outside workspace")
+		}
+	})
+}
+
+func TestUnifiedGitPickaxeTool_Execute(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	tempDir := t.TempDir()
+	git := func(args ...string) {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempDir
+		if output, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+	}
+	commit := func(author, message, file, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(tempDir, file), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		git("add", file)
+		git("-c", "user.name="+author, "-c", "user.email="+strings.ToLower(author)+"@example.com", "commit", "-q", "-m", message)
+	}
+
+	// Legacy を導入し、別ファイルに移してから削除する
+	git("init", "-q")
+	commit("Alice", "Add calc", "calc.go", "package calc\n\nfunc Add(a, b int) int { return a + b }\n")
+	commit("Bob", "Add Legacy helper", "calc.go", "package calc\n\nfunc Add(a, b int) int { return a + b }\n\nfunc Legacy() {}\n")
+	commit("Carol", "Document Add", "README.md", "Add sums two ints\n")
+	commit("Dave", "Drop Legacy", "calc.go", "package calc\n\nfunc Add(a, b int) int { return a + b }\n")
+
+	tool := NewUnifiedGitPickaxeTool(security.NewDefaultConstraints(tempDir))
+	execute := func(params map[string]interface{}) *GitPickaxeResult {
+		t.Helper()
+		response, err := tool.Execute(context.Background(), &ToolRequest{ID: "pickaxe-1", ToolName: "git_pickaxe", Parameters: params})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		return response.Data.(*GitPickaxeResult)
+	}
+
+	t.Run("Introduced and removed", func(t *testing.T) {
+		result := execute(map[string]interface{}{"term": "func Legacy"})
+		if len(result.Commits) != 2 {
+			t.Fatalf("Expected 2 commits, got %+v", result.Commits)
+		}
+		introduced, removed := result.Introduced(), result.Removed()
+		if introduced == nil || introduced.Author != "Bob" || introduced.Files[0].Path != "calc.go" || introduced.Added != 1 {
+			t.Errorf("Expected Bob's commit as the introduction, got %+v", introduced)
+		}
+		if removed == nil || removed.Author != "Dave" || removed.Change != PickaxeRemoved || len(removed.Lines) != 1 {
+			t.Errorf("Expected Dave's commit as the removal, got %+v", removed)
+		}
+	})
+
+	t.Run("Still present", func(t *testing.T) {
+		result := execute(map[string]interface{}{"term": "func Add", "path": "calc.go"})
+		if len(result.Commits) != 1 || result.Removed() != nil || result.Introduced().Author != "Alice" {
+			t.Errorf("Expected only Alice's introduction, got %+v", result.Commits)
+		}
+	})
+
+	t.Run("Regex across files", func(t *testing.T) {
+		result := execute(map[string]interface{}{"term": "Add( sums|\\()", "regex": true})
+		if len(result.Commits) != 2 || result.Commits[0].Files[0].Path != "README.md" || result.Commits[1].Files[0].Path != "calc.go" {
+			t.Errorf("Expected the README and calc.go commits, got %+v", result.Commits)
+		}
+		if result = execute(map[string]interface{}{"term": "Add( sums|\\()", "regex": true, "max_count": 1}); len(result.Commits) != 1 || !result.Truncated {
+			t.Errorf("Expected the newest commit only, got %+v", result)
+		}
+	})
+
+	t.Run("Outside workspace", func(t *testing.T) {
+		_, err := tool.Execute(context.Background(), &ToolRequest{ToolName: "git_pickaxe", Parameters: map[string]interface{}{"term": "root", "path": "/etc"}})
+		if err == nil {
+			t.Error("Expected error for path outside workspace")
+		}
+	})
+}
+
+// stubConfirmation は削除の確認に決まった答えを返す
+type stubConfirmation struct {
+	answer bool
+	asked  int
+}
+
+func (c *stubConfirmation) ConfirmCommand(command, reason string) bool { return c.answer }
+
+func (c *stubConfirmation) ConfirmRiskyOperation(operation, details string) bool {
+	c.asked++
+	return c.answer
+}
+
+func TestUnifiedTreeTool_Execute(t *testing.T) {
+	tempDir := t.TempDir()
+	for path, content := range map[string]string{
+		".gitignore":             "*.log\n",
+		"main.go":                "package main\n",
+		"debug.log":              "noise\n",
+		"cmd/app/main.go":        "package main\n",
+		"cmd/app/deep/x/y.go":    "package x\n",
+		"node_modules/pkg/a.js":  "a\n",
+		"internal/a.go":          "package internal\n",
+		"internal/b.go":          "package internal\n",
+		"internal/c.go":          "package internal\n",
+		".hidden/secret.txt":     "s\n",
+		"internal/sub/README.md": "# sub\n",
+	} {
+		full := filepath.Join(tempDir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(full, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tool := NewUnifiedTreeTool(security.NewDefaultConstraints(tempDir))
+	execute := func(params map[string]interface{}) (*ToolResponse, *DirectoryTree) {
+		t.Helper()
+		response, err := tool.Execute(context.Background(), &ToolRequest{ToolName: "tree", Parameters: params})
+		if err != nil {
+			t.Fatalf("Execute(%v) failed: %v", params, err)
+		}
+		return response, response.Data.(*DirectoryTree)
+	}
+
+	// 除外パターン・隠しファイルを除き、深さの上限より下は数だけ示す
+	response, tree := execute(map[string]interface{}{"path": tempDir, "depth": float64(2)})
+	for _, unwanted := range []string{"debug.log", "node_modules", ".hidden", "deep/"} {
+		if strings.Contains(response.Content, unwanted) {
+			t.Errorf("compact tree should not contain %q:\n%s", unwanted, response.Content)
+		}
+	}
+	for _, expected := range []string{"  cmd/\n", "    app/ +2\n", "  internal/\n", "    sub/ +1\n", "    a.go\n", "  main.go\n"} {
+		if !strings.Contains(response.Content, expected) {
+			t.Errorf("compact tree missing %q:\n%s", expected, response.Content)
+		}
+	}
+	if strings.Index(response.Content, "internal/") > strings.Index(response.Content, "main.go") {
+		t.Errorf("directories should be listed before files:\n%s", response.Content)
+	}
+	if tree.Dirs != 4 || tree.Files != 4 || tree.Truncated {
+		t.Errorf("unexpected counts: dirs=%d files=%d truncated=%v", tree.Dirs, tree.Files, tree.Truncated)
+	}
+
+	// 件数の上限では浅い階層を残して打ち切る
+	response, tree = execute(map[string]interface{}{"path": tempDir, "max_entries": float64(4), "show_hidden": true, "format": "full"})
+	if !tree.Truncated || tree.Dirs+tree.Files != 4 {
+		t.Errorf("expected truncation at 4 entries, got dirs=%d files=%d truncated=%v", tree.Dirs, tree.Files, tree.Truncated)
+	}
+	for _, expected := range []string{"├── .hidden/ (+1)", "├── cmd/ (+1)", "└── … +1", "truncated at the entry limit"} {
+		if !strings.Contains(response.Content, expected) {
+			t.Errorf("full tree missing %q:\n%s", expected, response.Content)
+		}
+	}
+
+	// 追加の除外パターン・ワークスペース外
+	response, _ = execute(map[string]interface{}{"path": tempDir, "ignore": []interface{}{"cmd"}})
+	if strings.Contains(response.Content, "cmd/") {
+		t.Errorf("ignore parameter should exclude cmd:\n%s", response.Content)
+	}
+	if _, err := tool.Execute(context.Background(), &ToolRequest{ToolName: "tree", Parameters: map[string]interface{}{"path": filepath.Dir(tempDir)}}); err == nil {
+		t.Error("expected error for a path outside the workspace")
+	}
+}
+
+func TestFileOpsTool_MoveAndDelete(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not found")
+	}
+	tempDir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = tempDir
+		output, err := cmd.CombinedOutput()
+		if err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, output)
+		}
+		return string(output)
+	}
+	git("init", "-q")
+	for name, content := range map[string]string{"tracked.go": "package a\n", "pkg/b.go": "package pkg\n", "notes.txt": "untracked\n"} {
+		path := filepath.Join(tempDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	git("add", "tracked.go", "pkg/b.go")
+
+	tool := NewFileOpsTool(security.NewDefaultConstraints(tempDir), tempDir)
+	journalDir := t.TempDir()
+	tool.SetJournalDir(journalDir)
+	ctx := context.Background()
+
+	t.Run("追跡しているファイルは git mv で移動", func(t *testing.T) {
+		result, err := tool.Move(ctx, MoveRequest{Source: "tracked.go", Destination: "renamed.go"})
+		if err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		if result.Metadata["git"] != true {
+			t.Errorf("tracked file should be moved with git mv: %+v", result.Metadata)
+		}
+		if status := git("status", "--porcelain"); !strings.Contains(status, "A  renamed.go") || strings.Contains(status, "tracked.go") {
+			t.Errorf("rename should be staged:\n%s", status)
+		}
+	})
+
+	t.Run("既存のディレクトリへはその中に移動し、追跡していなければ通常の移動", func(t *testing.T) {
+		result, err := tool.Move(ctx, MoveRequest{Source: "notes.txt", Destination: "pkg"})
+		if err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		if result.Metadata["git"] != false {
+			t.Errorf("untracked file should not use git: %+v", result.Metadata)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "pkg", "notes.txt")); err != nil {
+			t.Errorf("file was not moved into the directory: %v", err)
+		}
+		if _, err := tool.Move(ctx, MoveRequest{Source: "pkg/notes.txt", Destination: "renamed.go"}); err == nil {
+			t.Error("Expected error when the destination exists")
+		}
+		if _, err := tool.Move(ctx, MoveRequest{Source: "pkg", Destination: "../outside"}); err == nil {
+			t.Error("Expected error for a destination outside the workspace")
+		}
+	})
+
+	t.Run("削除は確認してから行い、取り消せる", func(t *testing.T) {
+		if _, err := tool.Delete(ctx, DeleteRequest{Path: "pkg", Recursive: true}); err == nil {
+			t.Error("Deletion without a confirmation method should be refused")
+		}
+
+		declined := &stubConfirmation{answer: false}
+		tool.SetConfirmation(declined)
+		if _, err := tool.Delete(ctx, DeleteRequest{Path: "pkg", Recursive: true}); err == nil || declined.asked != 1 {
+			t.Errorf("declined deletion should fail after asking once: %v (asked %d)", err, declined.asked)
+		}
+		if _, err := tool.Delete(ctx, DeleteRequest{Path: "pkg"}); err == nil {
+			t.Error("Deleting a directory without recursive should fail")
+		}
+
+		tool.SetConfirmation(&stubConfirmation{answer: true})
+		result, err := tool.Delete(ctx, DeleteRequest{Path: "pkg", Recursive: true})
+		if err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(tempDir, "pkg")); !os.IsNotExist(err) {
+			t.Error("directory should be deleted, including untracked files")
+		}
+
+		tx, err := journal.Load(journalDir, result.Metadata["journal_id"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Undo(false); err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+		for _, name := range []string{"pkg/b.go", "pkg/notes.txt"} {
+			if _, err := os.Stat(filepath.Join(tempDir, name)); err != nil {
+				t.Errorf("%s was not restored: %v", name, err)
+			}
+		}
+	})
+
+	t.Run("シンボリックリンクはリンク先ではなくリンク自体を移動・削除する", func(t *testing.T) {
+		real := filepath.Join(tempDir, "real.txt")
+		if err := os.WriteFile(real, []byte("keep me\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		for _, name := range []string{"link.txt", "tracked-link.txt"} {
+			if err := os.Symlink("real.txt", filepath.Join(tempDir, name)); err != nil {
+				t.Skipf("symlinks not supported: %v", err)
+			}
+		}
+		git("add", "tracked-link.txt")
+		assertReal := func() {
+			t.Helper()
+			if content, err := os.ReadFile(real); err != nil || string(content) != "keep me\n" {
+				t.Errorf("link target was changed: %q, %v", content, err)
+			}
+		}
+
+		tool.SetConfirmation(&stubConfirmation{answer: true})
+		result, err := tool.Delete(ctx, DeleteRequest{Path: "link.txt"})
+		if err != nil {
+			t.Fatalf("Delete failed: %v", err)
+		}
+		assertReal()
+		if _, err := os.Lstat(filepath.Join(tempDir, "link.txt")); !os.IsNotExist(err) {
+			t.Errorf("link should be deleted: %v", err)
+		}
+		tx, err := journal.Load(journalDir, result.Metadata["journal_id"].(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if files := tx.Files(); len(files) != 1 || filepath.Base(files[0]) != "link.txt" {
+			t.Errorf("journal should record the link itself: %v", files)
+		}
+		if err := tx.Undo(false); err != nil {
+			t.Fatalf("Undo failed: %v", err)
+		}
+		if target, err := os.Readlink(filepath.Join(tempDir, "link.txt")); err != nil || target != "real.txt" {
+			t.Errorf("link was not restored: %q, %v", target, err)
+		}
+
+		result, err = tool.Move(ctx, MoveRequest{Source: "tracked-link.txt", Destination: "moved-link.txt"})
+		if err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		if result.Metadata["git"] != true {
+			t.Errorf("tracked link should be moved with git mv: %+v", result.Metadata)
+		}
+		assertReal()
+		if target, err := os.Readlink(filepath.Join(tempDir, "moved-link.txt")); err != nil || target != "real.txt" {
+			t.Errorf("link itself should be moved: %q, %v", target, err)
+		}
+		if _, err := os.Lstat(filepath.Join(tempDir, "tracked-link.txt")); !os.IsNotExist(err) {
+			t.Errorf("source link should be gone: %v", err)
+		}
+
+		result, err = tool.Move(ctx, MoveRequest{Source: "link.txt", Destination: "plain-moved.txt"})
+		if err != nil {
+			t.Fatalf("Move failed: %v", err)
+		}
+		assertReal()
+		if info, err := os.Lstat(filepath.Join(tempDir, "plain-moved.txt")); err != nil || info.Mode()&os.ModeSymlink == 0 {
+			t.Errorf("untracked link itself should be moved: %v", err)
+		}
+	})
+}
+
+func TestUnifiedDiagnosticsTool_Execute(t *testing.T) {
+	tool := NewUnifiedDiagnosticsTool(security.NewDefaultConstraints(t.TempDir()))
+	ctx := context.Background()
+
+	response, err := tool.Execute(ctx, &ToolRequest{ToolName: "diagnostics", Parameters: map[string]interface{}{"include_config": false}})
+	if err != nil {
+		t.Fatalf("Execute failed: %v", err)
+	}
+	if !strings.Contains(response.Content, "### Environment") || !strings.Contains(response.Content, "| go |") {
+		t.Errorf("report should list the default tools:\n%s", response.Content)
+	}
+
+	for _, name := range []string{"../bin/sh", "rm -rf", "sudo"} {
+		params := map[string]interface{}{"tools": []interface{}{name}}
+		if _, err := tool.Execute(ctx, &ToolRequest{ToolName: "diagnostics", Parameters: params}); err == nil {
+			t.Errorf("tool name %q should be rejected", name)
+		}
+	}
+}
+
+func TestUnifiedLogTailTool_Execute(t *testing.T) {
+	tempDir := t.TempDir()
+	logPath := filepath.Join(tempDir, "server.log")
+	if err := os.WriteFile(logPath, []byte("INFO starting\nDEBUG config loaded\nINFO listening on :8080\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool := NewUnifiedLogTailTool(security.NewDefaultConstraints(tempDir))
+	ctx := context.Background()
+
+	t.Run("末尾の行を重大度付きで返す", func(t *testing.T) {
+		response, err := tool.Execute(ctx, &ToolRequest{ToolName: "log_tail", Parameters: map[string]interface{}{"path": "server.log", "lines": float64(2)}})
+		if err != nil {
+			t.Fatalf("Execute failed: %v", err)
+		}
+		result := response.Data.(*LogTailResult)
+		if len(result.Lines) != 2 || result.Lines[0].Text != "DEBUG config loaded" || result.Lines[1].Level != LogLevelInfo {
+			t.Errorf("tail = %+v", result.Lines)
+		}
+	})
+
+	t.Run("追っている間の追記を集め、上限の行数で終える", func(t *testing.T) {
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			file, err := os.OpenFile(logPath, os.O_APPEND|os.O_WRONLY, 0644)
+			if err != nil {
+				return
+			}
+			defer file.Close()
+			file.WriteString("GET /api/users 500\nERROR dial postgres://app:hunter2secret@db:5432 refused\n")
+			file.WriteString("WARN retrying\nINFO ignored after the limit\n")
+		}()
+		started := time.Now()
+		result, err := TailLog(ctx, LogTailOptions{Path: logPath, Lines: 1, Follow: 5 * time.Second, MaxLines: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if time.Since(started) > 4*time.Second || !result.Truncated || result.NewLines != 3 {
+			t.Errorf("should stop at max lines: new=%d truncated=%v after %s", result.NewLines, result.Truncated, time.Since(started))
+		}
+		if result.Levels[LogLevelError] != 1 || result.Levels[LogLevelWarn] != 1 {
+			t.Errorf("levels = %v", result.Levels)
+		}
+		content := result.Format()
+		if !strings.Contains(content, "--- new lines while following ---\nGET /api/users 500\n") || strings.Contains(content, "hunter2secret") {
+			t.Errorf("unexpected output:\n%s", content)
+		}
+	})
+
+	t.Run("切り詰められたファイルは先頭から読み直し、フィルターを適用する", func(t *testing.T) {
+		go func() {
+			time.Sleep(300 * time.Millisecond)
+			os.WriteFile(logPath, []byte("ERROR after rotation\nINFO noise\n"), 0644)
+		}()
+		result, err := TailLog(ctx, LogTailOptions{Path: logPath, Follow: time.Second, Filter: regexp.MustCompile("ERROR")})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.NewLines != 1 || result.Lines[len(result.Lines)-1].Text != "ERROR after rotation" {
+			t.Errorf("lines after truncation = %+v", result.Lines)
+		}
+	})
+
+	t.Run("不正な指定は拒否する", func(t *testing.T) {
+		for _, params := range []map[string]interface{}{
+			{"path": "/etc/passwd"},
+			{"path": "server.log", "unit": "nginx"},
+			{},
+			{"unit": "nginx; rm -rf /"},
+			{"path": "server.log", "grep": "("},
+		} {
+			if _, err := tool.Execute(ctx, &ToolRequest{ToolName: "log_tail", Parameters: params}); err == nil {
+				t.Errorf("params %v should be rejected", params)
+			}
+		}
+	})
+}
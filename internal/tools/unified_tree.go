@@ -0,0 +1,366 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/ignore"
+	"github.com/glkt/vyb-code/internal/security"
+)
+
+// tree の深さ・件数の上限
+const (
+	DefaultTreeDepth      = 3    // 既定の深さ（ルート直下が1）
+	DefaultTreeMaxEntries = 200  // 既定の最大件数
+	maxTreeDepth          = 20   // 指定できる最大の深さ
+	maxTreeEntries        = 5000 // 指定できる最大件数
+)
+
+// ツリーのノードの種類
+const (
+	TreeTypeDir     = "dir"
+	TreeTypeFile    = "file"
+	TreeTypeSymlink = "symlink"
+)
+
+// TreeOptions - ディレクトリツリーの作り方
+type TreeOptions struct {
+	Depth      int      // 辿る深さ（0なら DefaultTreeDepth）
+	MaxEntries int      // 含めるエントリの最大件数（0なら DefaultTreeMaxEntries）
+	ShowHidden bool     // . で始まるファイル・ディレクトリも含める
+	Ignore     []string // 除外する名前の glob（.gitignore・.vybignore・ignore.patterns に加えて）
+}
+
+// TreeNode - ディレクトリツリーのエントリ
+type TreeNode struct {
+	Name     string      `json:"name"`
+	Path     string      `json:"path"` // ルートからの相対パス（/ 区切り、ルートは "."）
+	Type     string      `json:"type"` // dir, file, symlink
+	Size     int64       `json:"size"`
+	ModTime  time.Time   `json:"mtime"`
+	Children []*TreeNode `json:"children,omitempty"`
+	Omitted  int         `json:"omitted,omitempty"` // 深さ・件数の上限で含めなかった子の数
+}
+
+// DirectoryTree - ディレクトリツリー
+type DirectoryTree struct {
+	Root      *TreeNode `json:"root"`
+	Path      string    `json:"path"` // ルートの絶対パス
+	Depth     int       `json:"depth"`
+	Dirs      int       `json:"dirs"`
+	Files     int       `json:"files"`
+	Truncated bool      `json:"truncated,omitempty"` // 件数の上限で打ち切った
+}
+
+// BuildTree - root 以下のディレクトリツリーを作る（除外パターンに一致するパスは含めない）
+// 浅い階層から順に辿るため、件数の上限に達した時は深い階層が省略される
+func BuildTree(root string, opts TreeOptions) (*DirectoryTree, error) {
+	if opts.Depth <= 0 {
+		opts.Depth = DefaultTreeDepth
+	}
+	if opts.Depth > maxTreeDepth {
+		opts.Depth = maxTreeDepth
+	}
+	if opts.MaxEntries <= 0 {
+		opts.MaxEntries = DefaultTreeMaxEntries
+	}
+	if opts.MaxEntries > maxTreeEntries {
+		opts.MaxEntries = maxTreeEntries
+	}
+
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get absolute path: %w", err)
+	}
+	info, err := os.Stat(absRoot)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", absRoot)
+	}
+
+	tree := &DirectoryTree{
+		Root:  &TreeNode{Name: filepath.Base(absRoot), Path: ".", Type: TreeTypeDir, ModTime: info.ModTime()},
+		Path:  absRoot,
+		Depth: opts.Depth,
+	}
+	matcher := ignore.New(absRoot)
+
+	type pending struct {
+		node  *TreeNode
+		path  string
+		depth int
+	}
+	queue := []pending{{node: tree.Root, path: absRoot}}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+
+		entries := readTreeEntries(current.path, matcher, opts)
+		if current.depth >= opts.Depth {
+			current.node.Omitted = len(entries)
+			continue
+		}
+		for i, entry := range entries {
+			if tree.Dirs+tree.Files >= opts.MaxEntries {
+				current.node.Omitted = len(entries) - i
+				tree.Truncated = true
+				break
+			}
+			child := &TreeNode{
+				Name:    entry.Name(),
+				Path:    filepath.ToSlash(filepath.Join(current.node.Path, entry.Name())),
+				Type:    TreeTypeFile,
+				Size:    entry.Size(),
+				ModTime: entry.ModTime(),
+			}
+			switch {
+			case entry.Mode()&os.ModeSymlink != 0:
+				child.Type = TreeTypeSymlink
+				tree.Files++
+			case entry.IsDir():
+				child.Type = TreeTypeDir
+				child.Size = 0
+				tree.Dirs++
+				queue = append(queue, pending{node: child, path: filepath.Join(current.path, entry.Name()), depth: current.depth + 1})
+			default:
+				tree.Files++
+			}
+			current.node.Children = append(current.node.Children, child)
+		}
+	}
+	return tree, nil
+}
+
+// readTreeEntries - ディレクトリのエントリ（除外・隠しファイルを除き、ディレクトリ優先の名前順）
+func readTreeEntries(dir string, matcher *ignore.Matcher, opts TreeOptions) []os.FileInfo {
+	dirEntries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var entries []os.FileInfo
+	for _, entry := range dirEntries {
+		name := entry.Name()
+		if !opts.ShowHidden && strings.HasPrefix(name, ".") {
+			continue
+		}
+		if matchesAnyGlob(name, opts.Ignore) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if matcher.Ignored(filepath.Join(dir, name), info.IsDir()) {
+			continue
+		}
+		entries = append(entries, info)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if entries[i].IsDir() != entries[j].IsDir() {
+			return entries[i].IsDir()
+		}
+		return entries[i].Name() < entries[j].Name()
+	})
+	return entries
+}
+
+// matchesAnyGlob - 名前がいずれかの glob に一致するか
+func matchesAnyGlob(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if matched, err := filepath.Match(pattern, name); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Compact - プロンプト向けの簡潔な形式（字下げのみ、ディレクトリは末尾に /、省略した数は +N）
+func (t *DirectoryTree) Compact() string {
+	var sb strings.Builder
+	sb.WriteString(t.Root.Name + "/")
+	if t.Root.Omitted > 0 {
+		sb.WriteString(fmt.Sprintf(" +%d", t.Root.Omitted))
+	}
+	sb.WriteString("\n")
+	var walk func(node *TreeNode, indent string)
+	walk = func(node *TreeNode, indent string) {
+		for _, child := range node.Children {
+			sb.WriteString(indent + child.Name)
+			if child.Type == TreeTypeDir {
+				sb.WriteString("/")
+			}
+			if child.Omitted > 0 {
+				sb.WriteString(fmt.Sprintf(" +%d", child.Omitted))
+			}
+			sb.WriteString("\n")
+			walk(child, indent+"  ")
+		}
+	}
+	walk(t.Root, "  ")
+	sb.WriteString(t.summary() + "\n")
+	return sb.String()
+}
+
+// Full - 表示向けの形式（罫線で階層を描き、サイズ・更新日時を付ける）
+func (t *DirectoryTree) Full() string {
+	return strings.Join(t.FullLines(), "\n") + "\n"
+}
+
+// FullLines - Full の行（TUI のペインで1行ずつ描画する）
+func (t *DirectoryTree) FullLines() []string {
+	lines := []string{t.Path + "/"}
+	var walk func(node *TreeNode, prefix string)
+	walk = func(node *TreeNode, prefix string) {
+		for i, child := range node.Children {
+			branch, next := "├── ", "│   "
+			if i == len(node.Children)-1 && node.Omitted == 0 {
+				branch, next = "└── ", "    "
+			}
+			label := child.Name
+			switch child.Type {
+			case TreeTypeDir:
+				label += "/"
+				if child.Omitted > 0 {
+					label += fmt.Sprintf(" (+%d)", child.Omitted)
+				}
+			case TreeTypeSymlink:
+				label += " @"
+			default:
+				label += "  " + formatByteSize(child.Size)
+			}
+			lines = append(lines, fmt.Sprintf("%s%s%s  %s", prefix, branch, label, child.ModTime.Format("2006-01-02 15:04")))
+			walk(child, prefix+next)
+		}
+		if node.Omitted > 0 && len(node.Children) > 0 {
+			lines = append(lines, fmt.Sprintf("%s└── … +%d", prefix, node.Omitted))
+		}
+	}
+	walk(t.Root, "")
+	return append(lines, t.summary())
+}
+
+// summary - 件数と打ち切りの有無
+func (t *DirectoryTree) summary() string {
+	summary := fmt.Sprintf("%d directories, %d files (depth %d)", t.Dirs, t.Files, t.Depth)
+	if t.Truncated {
+		summary += "; truncated at the entry limit, narrow the path or raise max_entries"
+	}
+	return summary
+}
+
+// UnifiedTreeTool - ディレクトリツリーツール（find・ls -la の代わりに構造を把握する）
+type UnifiedTreeTool struct {
+	*BaseTool
+	constraints *security.Constraints
+}
+
+// NewUnifiedTreeTool - ディレクトリツリーツールを作成
+func NewUnifiedTreeTool(constraints *security.Constraints) *UnifiedTreeTool {
+	description := "Shows the directory tree (name, type, size, mtime) under a path, respecting .gitignore/.vybignore, with depth and entry limits"
+	base := NewBaseTool("tree", description, "1.0.0", CategoryFile)
+	base.AddCapability(CapabilityFileRead)
+	base.SetConstraints(constraints)
+	base.SetSchema(ToolSchema{
+		Name:        "tree",
+		Description: description,
+		Version:     "1.0.0",
+		Parameters: map[string]Parameter{
+			"path": {
+				Type:        "string",
+				Description: "Directory to show (default: the workspace root)",
+			},
+			"depth": {
+				Type:        "number",
+				Description: fmt.Sprintf("How many levels to descend (default %d, max %d)", DefaultTreeDepth, maxTreeDepth),
+			},
+			"max_entries": {
+				Type:        "number",
+				Description: fmt.Sprintf("Maximum number of entries; shallower levels are kept first (default %d, max %d)", DefaultTreeMaxEntries, maxTreeEntries),
+			},
+			"show_hidden": {
+				Type:        "boolean",
+				Description: "Include entries starting with .",
+			},
+			"ignore": {
+				Type:        "array",
+				Description: "Additional name glob patterns to exclude",
+			},
+			"format": {
+				Type:        "string",
+				Description: "compact (indented names, for prompts) or full (sizes and mtimes)",
+				Enum:        []string{"compact", "full"},
+			},
+		},
+		Examples: []ToolExample{
+			{
+				Description: "Overview of the project layout",
+				Parameters:  map[string]interface{}{"path": ".", "depth": 2},
+			},
+			{
+				Description: "Detailed listing of one package",
+				Parameters:  map[string]interface{}{"path": "internal/tools", "depth": 1, "format": "full"},
+			},
+		},
+	})
+	return &UnifiedTreeTool{BaseTool: base, constraints: constraints}
+}
+
+// Execute - ディレクトリツリーを作る
+func (t *UnifiedTreeTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
+	if err := t.ValidateRequest(request); err != nil {
+		return nil, err
+	}
+
+	path, _ := request.Parameters["path"].(string)
+	if strings.TrimSpace(path) == "" {
+		path = "."
+	}
+	if t.constraints != nil {
+		resolved, err := t.constraints.ResolvePathFor(path, "read")
+		if err != nil {
+			return nil, NewToolError("security_violation", "Invalid path: "+err.Error())
+		}
+		path = resolved
+	}
+	format, _ := request.Parameters["format"].(string)
+	if format != "" && format != "compact" && format != "full" {
+		return nil, NewToolError("invalid_parameter", fmt.Sprintf("Unknown format: %s (compact, full)", format))
+	}
+	showHidden, _ := request.Parameters["show_hidden"].(bool)
+
+	tree, err := BuildTree(path, TreeOptions{
+		Depth:      intParam(request.Parameters, "depth", DefaultTreeDepth),
+		MaxEntries: intParam(request.Parameters, "max_entries", DefaultTreeMaxEntries),
+		ShowHidden: showHidden,
+		Ignore:     stringListParam(request.Parameters["ignore"]),
+	})
+	if err != nil {
+		return nil, NewToolError("execution_failed", fmt.Sprintf("Directory tree failed: %v", err))
+	}
+
+	content := tree.Compact()
+	if format == "full" {
+		content = tree.Full()
+	}
+	return &ToolResponse{
+		ID:       request.ID,
+		ToolName: t.GetName(),
+		Success:  true,
+		Content:  content,
+		Data:     tree,
+		Metadata: &ResponseMetadata{
+			Debug: map[string]interface{}{
+				"dirs":      tree.Dirs,
+				"files":     tree.Files,
+				"truncated": tree.Truncated,
+			},
+		},
+	}, nil
+}
@@ -16,8 +16,18 @@ import (
 type UnifiedWebFetchTool struct {
 	*BaseTool
 	httpClient *http.Client
+	maxBytes   int64          // 取得する本文の最大サイズ
+	cache      *webFetchCache // URL単位の取得結果キャッシュ
 }
 
+// Web取得のデフォルト設定
+const (
+	defaultWebFetchMaxBytes  = 2 * 1024 * 1024 // 2MB
+	defaultWebFetchCacheTTL  = 15 * time.Minute
+	webFetchCacheMaxEntries  = 100
+	webFetchMaxContentLength = 20000 // LLMへ渡す本文の最大文字数
+)
+
 // WebFetchResult - WebFetch結果
 type WebFetchResult struct {
 	URL         string            `json:"url"`
@@ -27,6 +37,8 @@ type WebFetchResult struct {
 	Size        int64             `json:"size"`
 	Headers     map[string]string `json:"headers"`
 	RedirectURL string            `json:"redirect_url,omitempty"`
+	Truncated   bool              `json:"truncated"`
+	Cached      bool              `json:"cached"`
 }
 
 // NewUnifiedWebFetchTool - 新しい統一WebFetchツールを作成
@@ -89,11 +101,34 @@ func NewUnifiedWebFetchTool(constraints *security.Constraints) *UnifiedWebFetchT
 	tool := &UnifiedWebFetchTool{
 		BaseTool:   base,
 		httpClient: client,
+		maxBytes:   defaultWebFetchMaxBytes,
+		cache:      newWebFetchCache(defaultWebFetchCacheTTL, webFetchCacheMaxEntries),
+	}
+
+	// 制約にネットワークポリシーがあれば適用
+	if constraints != nil && constraints.NetworkPolicy != nil {
+		tool.SetNetworkPolicy(constraints.NetworkPolicy)
 	}
 
 	return tool
 }
 
+// SetNetworkPolicy - 全リクエスト（リダイレクト含む）にネットワークポリシーを適用
+func (t *UnifiedWebFetchTool) SetNetworkPolicy(policy *security.NetworkPolicy) {
+	if policy == nil {
+		return
+	}
+	t.httpClient = policy.WrapClient(t.httpClient)
+}
+
+// SetFetchOptions - 最大取得サイズとキャッシュ有効期間を設定（TTLが0ならキャッシュ無効）
+func (t *UnifiedWebFetchTool) SetFetchOptions(maxBytes int64, cacheTTL time.Duration) {
+	if maxBytes > 0 {
+		t.maxBytes = maxBytes
+	}
+	t.cache.setTTL(cacheTTL)
+}
+
 // Execute - WebFetchツールを実行
 func (t *UnifiedWebFetchTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
 	if err := t.ValidateRequest(request); err != nil {
@@ -196,6 +231,13 @@ func (t *UnifiedWebFetchTool) ValidateRequest(request *ToolRequest) error {
 
 // performWebFetch - Web取得を実行
 func (t *UnifiedWebFetchTool) performWebFetch(ctx context.Context, urlStr, prompt string) (*WebFetchResult, error) {
+	// キャッシュ済みならそのまま返す
+	if cached, ok := t.cache.get(urlStr); ok {
+		cached.Cached = true
+		cached.Content = t.processContentWithPrompt(cached.Content, prompt)
+		return &cached, nil
+	}
+
 	// HTTPリクエストを作成
 	req, err := http.NewRequestWithContext(ctx, "GET", urlStr, nil)
 	if err != nil {
@@ -213,11 +255,15 @@ func (t *UnifiedWebFetchTool) performWebFetch(ctx context.Context, urlStr, promp
 	}
 	defer resp.Body.Close()
 
-	// レスポンス読み取り
-	content, err := io.ReadAll(resp.Body)
+	// レスポンス読み取り（サイズ上限付き）
+	content, err := io.ReadAll(io.LimitReader(resp.Body, t.maxBytes+1))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
+	truncated := int64(len(content)) > t.maxBytes
+	if truncated {
+		content = content[:t.maxBytes]
+	}
 
 	// ヘッダー情報を取得
 	headers := make(map[string]string)
@@ -233,56 +279,41 @@ func (t *UnifiedWebFetchTool) performWebFetch(ctx context.Context, urlStr, promp
 		redirectURL = resp.Header.Get("Location")
 	}
 
-	result := &WebFetchResult{
+	// HTMLはMarkdownに変換
+	contentType := resp.Header.Get("Content-Type")
+	body := strings.ToValidUTF8(string(content), "")
+	if strings.Contains(contentType, "html") {
+		body = HTMLToMarkdown(body)
+	}
+
+	result := WebFetchResult{
 		URL:         urlStr,
 		StatusCode:  resp.StatusCode,
-		ContentType: resp.Header.Get("Content-Type"),
-		Content:     string(content),
+		ContentType: contentType,
+		Content:     body,
 		Size:        int64(len(content)),
 		Headers:     headers,
 		RedirectURL: redirectURL,
+		Truncated:   truncated,
+	}
+
+	// 成功したレスポンスのみキャッシュ
+	if resp.StatusCode == http.StatusOK {
+		t.cache.set(urlStr, result)
 	}
 
 	// プロンプト処理（簡易実装 - 実際の実装ではLLMを使用）
-	processedContent := t.processContentWithPrompt(result.Content, prompt)
-	result.Content = processedContent
+	result.Content = t.processContentWithPrompt(result.Content, prompt)
 
-	return result, nil
+	return &result, nil
 }
 
 // processContentWithPrompt - プロンプトでコンテンツを処理（簡易実装）
 func (t *UnifiedWebFetchTool) processContentWithPrompt(content, prompt string) string {
-	// 実際の実装ではLLMを使用するが、ここでは簡易処理
-	maxLength := 5000
-	if len(content) > maxLength {
-		content = content[:maxLength] + "... (truncated)"
+	// 実際の実装ではLLMを使用するが、ここでは長さ制限のみ
+	if len(content) > webFetchMaxContentLength {
+		content = strings.ToValidUTF8(content[:webFetchMaxContentLength], "") + "... (truncated)"
 	}
 
-	// HTMLタグの簡易除去
-	content = t.stripHTMLTags(content)
-
 	return fmt.Sprintf("Prompt: %s\n\nContent:\n%s", prompt, content)
 }
-
-// stripHTMLTags - HTMLタグを簡易除去
-func (t *UnifiedWebFetchTool) stripHTMLTags(content string) string {
-	// 簡易的なHTMLタグ除去（本格的な実装では正規表現やHTMLパーサーを使用）
-	result := content
-
-	// よく使われるHTMLタグを除去
-	tags := []string{"<script>", "</script>", "<style>", "</style>", "<head>", "</head>"}
-	for _, tag := range tags {
-		result = strings.ReplaceAll(result, tag, "")
-	}
-
-	// 連続する空白を単一の空白に変換
-	result = strings.ReplaceAll(result, "\n", " ")
-	result = strings.ReplaceAll(result, "\t", " ")
-
-	// 複数の空白を単一に
-	for strings.Contains(result, "  ") {
-		result = strings.ReplaceAll(result, "  ", " ")
-	}
-
-	return strings.TrimSpace(result)
-}
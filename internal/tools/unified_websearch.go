@@ -4,7 +4,6 @@ import (
 	"context"
 	"fmt"
 	"net/http"
-	"net/url"
 	"strings"
 	"time"
 
@@ -15,11 +14,13 @@ import (
 type UnifiedWebSearchTool struct {
 	*BaseTool
 	httpClient *http.Client
+	backend    SearchBackend
 }
 
 // WebSearchResult - Web検索結果
 type WebSearchResult struct {
 	Query          string             `json:"query"`
+	Backend        string             `json:"backend"`
 	Results        []SearchResultItem `json:"results"`
 	TotalResults   int                `json:"total_results"`
 	SearchTime     time.Duration      `json:"search_time"`
@@ -109,11 +110,33 @@ func NewUnifiedWebSearchTool(constraints *security.Constraints) *UnifiedWebSearc
 	tool := &UnifiedWebSearchTool{
 		BaseTool:   base,
 		httpClient: client,
+		backend:    NewDuckDuckGoBackend(),
+	}
+
+	// 制約にネットワークポリシーがあれば適用
+	if constraints != nil && constraints.NetworkPolicy != nil {
+		tool.SetNetworkPolicy(constraints.NetworkPolicy)
 	}
 
 	return tool
 }
 
+// SetNetworkPolicy - 検索リクエストにネットワークポリシーを適用
+func (t *UnifiedWebSearchTool) SetNetworkPolicy(policy *security.NetworkPolicy) {
+	if policy == nil {
+		return
+	}
+	t.httpClient = policy.WrapClient(t.httpClient)
+}
+
+// SetSearchBackend - 検索バックエンドを差し替え
+func (t *UnifiedWebSearchTool) SetSearchBackend(backend SearchBackend) {
+	if backend == nil {
+		return
+	}
+	t.backend = backend
+}
+
 // Execute - WebSearchツールを実行
 func (t *UnifiedWebSearchTool) Execute(ctx context.Context, request *ToolRequest) (*ToolResponse, error) {
 	if err := t.ValidateRequest(request); err != nil {
@@ -234,65 +257,19 @@ func (t *UnifiedWebSearchTool) performWebSearch(
 
 	startTime := time.Now()
 
-	// 実際のWeb検索API呼び出し（ここではモック実装）
-	// 本格的な実装では Google Search API, Bing Search API, DuckDuckGo API などを使用
-	results, err := t.performMockSearch(query, maxResults, allowedDomains, blockedDomains)
+	results, err := t.backend.Search(ctx, t.httpClient, SearchQuery{
+		Query:      query,
+		MaxResults: maxResults,
+		SafeSearch: safeSearch,
+		Language:   language,
+	})
 	if err != nil {
-		return nil, fmt.Errorf("search API error: %w", err)
-	}
-
-	searchResult := &WebSearchResult{
-		Query:          query,
-		Results:        results,
-		TotalResults:   len(results),
-		SearchTime:     time.Since(startTime),
-		AllowedDomains: allowedDomains,
-		BlockedDomains: blockedDomains,
-		SafeSearch:     safeSearch,
-	}
-
-	return searchResult, nil
-}
-
-// performMockSearch - モック検索実行（実際の実装では外部API使用）
-func (t *UnifiedWebSearchTool) performMockSearch(
-	query string,
-	maxResults int,
-	allowedDomains,
-	blockedDomains []string,
-) ([]SearchResultItem, error) {
-
-	// モックデータ生成
-	mockResults := []SearchResultItem{
-		{
-			Title:     fmt.Sprintf("Results for '%s' - Example Site", query),
-			URL:       "https://example.com/search?q=" + url.QueryEscape(query),
-			Snippet:   fmt.Sprintf("Comprehensive information about %s with detailed explanations and examples.", query),
-			Domain:    "example.com",
-			Language:  "en",
-			Relevance: 0.95,
-		},
-		{
-			Title:     fmt.Sprintf("%s Tutorial and Guide", query),
-			URL:       "https://tutorials.org/" + url.QueryEscape(strings.ToLower(query)),
-			Snippet:   fmt.Sprintf("Learn about %s with step-by-step tutorials and practical examples.", query),
-			Domain:    "tutorials.org",
-			Language:  "en",
-			Relevance: 0.87,
-		},
-		{
-			Title:     fmt.Sprintf("Advanced %s Techniques", query),
-			URL:       "https://advanced.tech/" + url.QueryEscape(query),
-			Snippet:   fmt.Sprintf("Professional techniques and best practices for %s implementation.", query),
-			Domain:    "advanced.tech",
-			Language:  "en",
-			Relevance: 0.82,
-		},
+		return nil, fmt.Errorf("search API error (%s): %w", t.backend.Name(), err)
 	}
 
 	// ドメインフィルタリングを適用
 	var filteredResults []SearchResultItem
-	for _, result := range mockResults {
+	for _, result := range results {
 		// 禁止ドメインチェック
 		if t.isDomainBlocked(result.Domain, blockedDomains) {
 			continue
@@ -311,7 +288,18 @@ func (t *UnifiedWebSearchTool) performMockSearch(
 		filteredResults = filteredResults[:maxResults]
 	}
 
-	return filteredResults, nil
+	searchResult := &WebSearchResult{
+		Query:          query,
+		Backend:        t.backend.Name(),
+		Results:        filteredResults,
+		TotalResults:   len(filteredResults),
+		SearchTime:     time.Since(startTime),
+		AllowedDomains: allowedDomains,
+		BlockedDomains: blockedDomains,
+		SafeSearch:     safeSearch,
+	}
+
+	return searchResult, nil
 }
 
 // isDomainBlocked - ドメインが禁止リストに含まれるかチェック
@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"html"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTML→Markdown変換用の正規表現
+var (
+	htmlDropBlockPattern = regexp.MustCompile(`(?is)<(script|style|head|noscript|svg|iframe|nav|footer)[^>]*>.*?</(script|style|head|noscript|svg|iframe|nav|footer)>`)
+	htmlCommentPattern   = regexp.MustCompile(`(?s)<!--.*?-->`)
+	htmlHeadingPattern   = regexp.MustCompile(`(?is)<h([1-6])[^>]*>(.*?)</h[1-6]>`)
+	htmlPrePattern       = regexp.MustCompile(`(?is)<pre[^>]*>(.*?)</pre>`)
+	htmlCodePattern      = regexp.MustCompile(`(?is)<code[^>]*>(.*?)</code>`)
+	htmlLinkPattern      = regexp.MustCompile(`(?is)<a\s[^>]*href\s*=\s*["']([^"']*)["'][^>]*>(.*?)</a>`)
+	htmlStrongPattern    = regexp.MustCompile(`(?is)<(strong|b)(\s[^>]*)?>(.*?)</(strong|b)>`)
+	htmlEmPattern        = regexp.MustCompile(`(?is)<(em|i)(\s[^>]*)?>(.*?)</(em|i)>`)
+	htmlListItemPattern  = regexp.MustCompile(`(?is)<li[^>]*>`)
+	htmlBreakPattern     = regexp.MustCompile(`(?is)<br\s*/?>`)
+	htmlBlockEndPattern  = regexp.MustCompile(`(?is)</?(p|div|section|article|ul|ol|table|tr|blockquote|main|header)[^>]*>`)
+	htmlTagPattern       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesPattern    = regexp.MustCompile(`\n{3,}`)
+	inlineSpacePattern   = regexp.MustCompile(`[ \t]+`)
+)
+
+// HTMLToMarkdown はHTMLを簡易的なMarkdownに変換する
+func HTMLToMarkdown(content string) string {
+	content = htmlCommentPattern.ReplaceAllString(content, "")
+	content = htmlDropBlockPattern.ReplaceAllString(content, "")
+
+	// コードブロックは他の変換より先に退避
+	var codeBlocks []string
+	content = htmlPrePattern.ReplaceAllStringFunc(content, func(match string) string {
+		inner := htmlPrePattern.FindStringSubmatch(match)[1]
+		inner = html.UnescapeString(htmlTagPattern.ReplaceAllString(inner, ""))
+		codeBlocks = append(codeBlocks, "\n```\n"+strings.Trim(inner, "\n")+"\n```\n")
+		return codeBlockPlaceholder(len(codeBlocks) - 1)
+	})
+
+	content = htmlHeadingPattern.ReplaceAllStringFunc(content, func(match string) string {
+		parts := htmlHeadingPattern.FindStringSubmatch(match)
+		level := int(parts[1][0] - '0')
+		return "\n\n" + strings.Repeat("#", level) + " " + strings.TrimSpace(parts[2]) + "\n\n"
+	})
+	content = htmlCodePattern.ReplaceAllString(content, "`$1`")
+	content = htmlLinkPattern.ReplaceAllString(content, "[$2]($1)")
+	content = htmlStrongPattern.ReplaceAllString(content, "**$3**")
+	content = htmlEmPattern.ReplaceAllString(content, "*$3*")
+	content = htmlListItemPattern.ReplaceAllString(content, "\n- ")
+	content = htmlBreakPattern.ReplaceAllString(content, "\n")
+	content = htmlBlockEndPattern.ReplaceAllString(content, "\n\n")
+	content = htmlTagPattern.ReplaceAllString(content, "")
+	content = html.UnescapeString(content)
+
+	// 行内の余分な空白を整理
+	lines := strings.Split(content, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(inlineSpacePattern.ReplaceAllString(line, " "))
+	}
+	content = strings.Join(lines, "\n")
+	content = blankLinesPattern.ReplaceAllString(content, "\n\n")
+
+	// 退避したコードブロックを復元
+	for i, block := range codeBlocks {
+		content = strings.Replace(content, codeBlockPlaceholder(i), block, 1)
+	}
+
+	return strings.TrimSpace(content)
+}
+
+// codeBlockPlaceholder は退避したコードブロックの目印を返す
+func codeBlockPlaceholder(index int) string {
+	return "\x00CODEBLOCK" + strconv.Itoa(index) + "\x00"
+}
+
+// webFetchCacheEntry はWeb取得結果のキャッシュエントリ
+type webFetchCacheEntry struct {
+	result    WebFetchResult
+	expiresAt time.Time
+}
+
+// webFetchCache はURL単位のTTL付きキャッシュ
+type webFetchCache struct {
+	mu         sync.Mutex
+	entries    map[string]webFetchCacheEntry
+	ttl        time.Duration
+	maxEntries int
+}
+
+// newWebFetchCache は新しいWeb取得キャッシュを作成
+func newWebFetchCache(ttl time.Duration, maxEntries int) *webFetchCache {
+	return &webFetchCache{
+		entries:    make(map[string]webFetchCacheEntry),
+		ttl:        ttl,
+		maxEntries: maxEntries,
+	}
+}
+
+// get はキャッシュから有効な結果を取得
+func (c *webFetchCache) get(key string) (WebFetchResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	entry, ok := c.entries[key]
+	if !ok {
+		return WebFetchResult{}, false
+	}
+	if time.Now().After(entry.expiresAt) {
+		delete(c.entries, key)
+		return WebFetchResult{}, false
+	}
+	return entry.result, true
+}
+
+// set はキャッシュに結果を保存（TTLが0以下なら無効）
+func (c *webFetchCache) set(key string, result WebFetchResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.ttl <= 0 {
+		return
+	}
+
+	// 上限に達したら期限切れエントリを掃除し、それでも溢れる場合は最も古いものを削除
+	if len(c.entries) >= c.maxEntries {
+		now := time.Now()
+		var oldestKey string
+		var oldest time.Time
+		for k, e := range c.entries {
+			if now.After(e.expiresAt) {
+				delete(c.entries, k)
+				continue
+			}
+			if oldestKey == "" || e.expiresAt.Before(oldest) {
+				oldestKey, oldest = k, e.expiresAt
+			}
+		}
+		if len(c.entries) >= c.maxEntries && oldestKey != "" {
+			delete(c.entries, oldestKey)
+		}
+	}
+
+	c.entries[key] = webFetchCacheEntry{result: result, expiresAt: time.Now().Add(c.ttl)}
+}
+
+// setTTL はキャッシュ有効期間を変更
+func (c *webFetchCache) setTTL(ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ttl = ttl
+}
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"strings"
 	"time"
+
+	"github.com/glkt/vyb-code/internal/security"
 )
 
 // WebSearchTool - Web検索機能（Claude Code相当）
@@ -25,6 +27,14 @@ func NewWebSearchTool() *WebSearchTool {
 	}
 }
 
+// SetNetworkPolicy はWeb検索にネットワークポリシーを適用
+func (ws *WebSearchTool) SetNetworkPolicy(policy *security.NetworkPolicy) {
+	if policy == nil {
+		return
+	}
+	ws.client = policy.WrapClient(ws.client)
+}
+
 type SearchResult struct {
 	Title   string `json:"title"`
 	URL     string `json:"url"`
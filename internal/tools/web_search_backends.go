@@ -0,0 +1,323 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// 検索バックエンド名
+const (
+	SearchBackendDuckDuckGo = "duckduckgo"
+	SearchBackendSearxNG    = "searxng"
+	SearchBackendBrave      = "brave"
+)
+
+// 検索APIレスポンスの最大読み取りサイズ
+const maxSearchResponseBytes = 2 * 1024 * 1024
+
+// SearchQuery - 検索バックエンドへの問い合わせ内容
+type SearchQuery struct {
+	Query      string
+	MaxResults int
+	SafeSearch bool
+	Language   string
+}
+
+// SearchBackend - 差し替え可能なWeb検索バックエンド
+type SearchBackend interface {
+	Name() string
+	Search(ctx context.Context, client *http.Client, query SearchQuery) ([]SearchResultItem, error)
+}
+
+// ValidSearchBackends - 有効な検索バックエンド名一覧
+func ValidSearchBackends() []string {
+	return []string{SearchBackendDuckDuckGo, SearchBackendSearxNG, SearchBackendBrave}
+}
+
+// NewSearchBackend - 設定から検索バックエンドを作成
+func NewSearchBackend(cfg config.WebToolsConfig) (SearchBackend, error) {
+	switch cfg.SearchBackend {
+	case "", SearchBackendDuckDuckGo:
+		return NewDuckDuckGoBackend(), nil
+	case SearchBackendSearxNG:
+		if cfg.SearxNGURL == "" {
+			return nil, fmt.Errorf("SearxNGのURLが設定されていません")
+		}
+		return NewSearxNGBackend(cfg.SearxNGURL), nil
+	case SearchBackendBrave:
+		apiKey := cfg.BraveAPIKey
+		if apiKey == "" {
+			apiKey = os.Getenv("BRAVE_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("Brave Search APIキーが設定されていません（BRAVE_API_KEY）")
+		}
+		return NewBraveBackend(apiKey), nil
+	default:
+		return nil, fmt.Errorf("未対応の検索バックエンド: %s (有効な値: %v)", cfg.SearchBackend, ValidSearchBackends())
+	}
+}
+
+// SearxNGBackend - SearxNGインスタンスのJSON APIを使用
+type SearxNGBackend struct {
+	baseURL string
+}
+
+// NewSearxNGBackend - SearxNGバックエンドを作成
+func NewSearxNGBackend(baseURL string) *SearxNGBackend {
+	return &SearxNGBackend{baseURL: strings.TrimRight(baseURL, "/")}
+}
+
+// Name - バックエンド名
+func (s *SearxNGBackend) Name() string {
+	return SearchBackendSearxNG
+}
+
+// Search - SearxNGで検索
+func (s *SearxNGBackend) Search(ctx context.Context, client *http.Client, query SearchQuery) ([]SearchResultItem, error) {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	params.Set("format", "json")
+	params.Set("language", query.Language)
+	if query.SafeSearch {
+		params.Set("safesearch", "1")
+	} else {
+		params.Set("safesearch", "0")
+	}
+
+	var payload struct {
+		Results []struct {
+			Title         string  `json:"title"`
+			URL           string  `json:"url"`
+			Content       string  `json:"content"`
+			Score         float64 `json:"score"`
+			PublishedDate string  `json:"publishedDate"`
+		} `json:"results"`
+	}
+	if err := getSearchJSON(ctx, client, s.baseURL+"/search?"+params.Encode(), nil, &payload); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResultItem, 0, len(payload.Results))
+	for _, r := range payload.Results {
+		results = append(results, SearchResultItem{
+			Title:       r.Title,
+			URL:         r.URL,
+			Snippet:     r.Content,
+			Domain:      domainOf(r.URL),
+			PublishedAt: r.PublishedDate,
+			Language:    query.Language,
+			Relevance:   r.Score,
+		})
+	}
+	return results, nil
+}
+
+// BraveBackend - Brave Search APIを使用
+type BraveBackend struct {
+	apiKey   string
+	endpoint string
+}
+
+// NewBraveBackend - Braveバックエンドを作成
+func NewBraveBackend(apiKey string) *BraveBackend {
+	return &BraveBackend{
+		apiKey:   apiKey,
+		endpoint: "https://api.search.brave.com/res/v1/web/search",
+	}
+}
+
+// Name - バックエンド名
+func (b *BraveBackend) Name() string {
+	return SearchBackendBrave
+}
+
+// Search - Brave Search APIで検索
+func (b *BraveBackend) Search(ctx context.Context, client *http.Client, query SearchQuery) ([]SearchResultItem, error) {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	params.Set("count", strconv.Itoa(query.MaxResults))
+	params.Set("search_lang", query.Language)
+	if query.SafeSearch {
+		params.Set("safesearch", "strict")
+	} else {
+		params.Set("safesearch", "off")
+	}
+
+	var payload struct {
+		Web struct {
+			Results []struct {
+				Title       string `json:"title"`
+				URL         string `json:"url"`
+				Description string `json:"description"`
+				PageAge     string `json:"page_age"`
+				Language    string `json:"language"`
+			} `json:"results"`
+		} `json:"web"`
+	}
+	headers := map[string]string{"X-Subscription-Token": b.apiKey}
+	if err := getSearchJSON(ctx, client, b.endpoint+"?"+params.Encode(), headers, &payload); err != nil {
+		return nil, err
+	}
+
+	results := make([]SearchResultItem, 0, len(payload.Web.Results))
+	for i, r := range payload.Web.Results {
+		results = append(results, SearchResultItem{
+			Title:       r.Title,
+			URL:         r.URL,
+			Snippet:     HTMLToMarkdown(r.Description),
+			Domain:      domainOf(r.URL),
+			PublishedAt: r.PageAge,
+			Language:    r.Language,
+			Relevance:   rankRelevance(i),
+		})
+	}
+	return results, nil
+}
+
+// DuckDuckGo HTML版の結果抽出用パターン
+var (
+	ddgResultLinkPattern    = regexp.MustCompile(`(?is)<a[^>]*class="[^"]*result__a[^"]*"[^>]*href="([^"]*)"[^>]*>(.*?)</a>`)
+	ddgResultSnippetPattern = regexp.MustCompile(`(?is)<a[^>]*class="[^"]*result__snippet[^"]*"[^>]*>(.*?)</a>`)
+)
+
+// DuckDuckGoBackend - APIキー不要のDuckDuckGo HTML版を使用
+type DuckDuckGoBackend struct {
+	endpoint string
+}
+
+// NewDuckDuckGoBackend - DuckDuckGoバックエンドを作成
+func NewDuckDuckGoBackend() *DuckDuckGoBackend {
+	return &DuckDuckGoBackend{endpoint: "https://html.duckduckgo.com/html/"}
+}
+
+// Name - バックエンド名
+func (d *DuckDuckGoBackend) Name() string {
+	return SearchBackendDuckDuckGo
+}
+
+// Search - DuckDuckGoで検索
+func (d *DuckDuckGoBackend) Search(ctx context.Context, client *http.Client, query SearchQuery) ([]SearchResultItem, error) {
+	params := url.Values{}
+	params.Set("q", query.Query)
+	if !query.SafeSearch {
+		params.Set("kp", "-2")
+	}
+
+	body, err := getSearchBody(ctx, client, d.endpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	links := ddgResultLinkPattern.FindAllStringSubmatch(body, -1)
+	snippets := ddgResultSnippetPattern.FindAllStringSubmatch(body, -1)
+
+	results := make([]SearchResultItem, 0, len(links))
+	for i, link := range links {
+		resultURL := resolveDuckDuckGoURL(html.UnescapeString(link[1]))
+		if resultURL == "" {
+			continue
+		}
+		item := SearchResultItem{
+			Title:     HTMLToMarkdown(link[2]),
+			URL:       resultURL,
+			Domain:    domainOf(resultURL),
+			Language:  query.Language,
+			Relevance: rankRelevance(i),
+		}
+		if i < len(snippets) {
+			item.Snippet = HTMLToMarkdown(snippets[i][1])
+		}
+		results = append(results, item)
+	}
+	return results, nil
+}
+
+// resolveDuckDuckGoURL - DuckDuckGoのリダイレクトURLから実URLを取り出す
+func resolveDuckDuckGoURL(raw string) string {
+	if strings.HasPrefix(raw, "//") {
+		raw = "https:" + raw
+	}
+	parsed, err := url.Parse(raw)
+	if err != nil {
+		return ""
+	}
+	if target := parsed.Query().Get("uddg"); target != "" {
+		return target
+	}
+	// 広告等の内部リンクは除外
+	if strings.HasSuffix(parsed.Hostname(), "duckduckgo.com") {
+		return ""
+	}
+	return raw
+}
+
+// getSearchJSON - 検索APIを呼び出しJSONをデコード
+func getSearchJSON(ctx context.Context, client *http.Client, endpoint string, headers map[string]string, out interface{}) error {
+	if headers == nil {
+		headers = map[string]string{}
+	}
+	headers["Accept"] = "application/json"
+
+	body, err := getSearchBody(ctx, client, endpoint, headers)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(body), out); err != nil {
+		return fmt.Errorf("検索結果の解析に失敗: %w", err)
+	}
+	return nil
+}
+
+// getSearchBody - 検索エンドポイントにGETリクエストを送信
+func getSearchBody(ctx context.Context, client *http.Client, endpoint string, headers map[string]string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", endpoint, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "VybCode/1.0 (WebSearch Tool)")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("検索リクエスト失敗: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("検索APIエラー: %s", resp.Status)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxSearchResponseBytes))
+	if err != nil {
+		return "", fmt.Errorf("検索結果の読み取りに失敗: %w", err)
+	}
+	return string(data), nil
+}
+
+// domainOf - URLからドメインを取得
+func domainOf(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(parsed.Hostname(), "www.")
+}
+
+// rankRelevance - 順位から関連度スコアを算出
+func rankRelevance(rank int) float64 {
+	return 1.0 / float64(rank+1)
+}
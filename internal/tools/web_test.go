@@ -0,0 +1,155 @@
+package tools
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+func TestHTMLToMarkdown(t *testing.T) {
+	input := `<html><head><title>x</title></head><body>
+<script>alert(1)</script>
+<h1>Title</h1>
+<p>Read the <a href="https://go.dev/doc">docs</a> &amp; <strong>learn</strong>.</p>
+<ul><li>one</li><li>two</li></ul>
+<pre><code>fmt.Println("&lt;hi&gt;")</code></pre>
+</body></html>`
+
+	got := HTMLToMarkdown(input)
+
+	expected := []string{
+		"# Title",
+		"[docs](https://go.dev/doc)",
+		"& **learn**",
+		"- one",
+		"- two",
+		"```\nfmt.Println(\"<hi>\")\n```",
+	}
+	for _, want := range expected {
+		if !strings.Contains(got, want) {
+			t.Errorf("Expected markdown to contain %q, got:\n%s", want, got)
+		}
+	}
+	if strings.Contains(got, "alert") {
+		t.Errorf("Script content should be removed, got:\n%s", got)
+	}
+}
+
+func TestUnifiedWebFetchTool_SizeLimitAndCache(t *testing.T) {
+	var hits int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<p>" + strings.Repeat("a", 100) + "</p>"))
+	}))
+	defer server.Close()
+
+	tool := NewUnifiedWebFetchTool(nil)
+	tool.SetFetchOptions(20, time.Minute)
+
+	fetch := func() *WebFetchResult {
+		resp, err := tool.Execute(context.Background(), &ToolRequest{
+			ID:         "fetch",
+			ToolName:   "webfetch",
+			Parameters: map[string]interface{}{"url": server.URL, "prompt": "summarize"},
+		})
+		if err != nil {
+			t.Fatalf("Fetch failed: %v", err)
+		}
+		return resp.Data.(map[string]interface{})["result"].(*WebFetchResult)
+	}
+
+	first := fetch()
+	if !first.Truncated {
+		t.Error("Expected response to be truncated by size limit")
+	}
+	if first.Size != 20 {
+		t.Errorf("Expected size 20, got %d", first.Size)
+	}
+
+	second := fetch()
+	if !second.Cached {
+		t.Error("Expected second fetch to be served from cache")
+	}
+	if atomic.LoadInt32(&hits) != 1 {
+		t.Errorf("Expected 1 upstream request, got %d", hits)
+	}
+}
+
+func TestSearchBackends(t *testing.T) {
+	t.Run("SearxNG", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Query().Get("format") != "json" {
+				t.Errorf("Expected format=json, got %s", r.URL.RawQuery)
+			}
+			w.Write([]byte(`{"results":[{"title":"Go","url":"https://www.go.dev/doc","content":"Docs","score":2.5}]}`))
+		}))
+		defer server.Close()
+
+		results, err := NewSearxNGBackend(server.URL).Search(context.Background(), server.Client(), SearchQuery{Query: "go", MaxResults: 5, Language: "en"})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Domain != "go.dev" || results[0].Snippet != "Docs" {
+			t.Errorf("Unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("Brave", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("X-Subscription-Token") != "key" {
+				t.Error("Expected API key header")
+			}
+			w.Write([]byte(`{"web":{"results":[{"title":"Go","url":"https://go.dev/","description":"The <strong>Go</strong> language"}]}}`))
+		}))
+		defer server.Close()
+
+		backend := NewBraveBackend("key")
+		backend.endpoint = server.URL
+		results, err := backend.Search(context.Background(), server.Client(), SearchQuery{Query: "go", MaxResults: 5, Language: "en"})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 || results[0].Snippet != "The **Go** language" {
+			t.Errorf("Unexpected results: %+v", results)
+		}
+	})
+
+	t.Run("DuckDuckGo", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`<div class="result">
+<a rel="nofollow" class="result__a" href="//duckduckgo.com/l/?uddg=https%3A%2F%2Fpkg.go.dev%2Fnet%2Fhttp&amp;rut=x">net/<b>http</b></a>
+<a class="result__snippet" href="#">Package http provides HTTP client and server implementations.</a>
+</div>`))
+		}))
+		defer server.Close()
+
+		backend := NewDuckDuckGoBackend()
+		backend.endpoint = server.URL
+		results, err := backend.Search(context.Background(), server.Client(), SearchQuery{Query: "net/http", MaxResults: 5})
+		if err != nil {
+			t.Fatalf("Search failed: %v", err)
+		}
+		if len(results) != 1 {
+			t.Fatalf("Expected 1 result, got %d", len(results))
+		}
+		if results[0].URL != "https://pkg.go.dev/net/http" || results[0].Title != "net/**http**" {
+			t.Errorf("Unexpected result: %+v", results[0])
+		}
+	})
+
+	t.Run("Invalid configuration", func(t *testing.T) {
+		if _, err := NewSearchBackend(config.WebToolsConfig{SearchBackend: SearchBackendSearxNG}); err == nil {
+			t.Error("Expected error for searxng without URL")
+		}
+		if _, err := NewSearchBackend(config.WebToolsConfig{SearchBackend: "google"}); err == nil {
+			t.Error("Expected error for unknown backend")
+		}
+	})
+}
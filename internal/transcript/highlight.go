@@ -0,0 +1,208 @@
+package transcript
+
+import (
+	"html"
+	"strings"
+)
+
+// languageSyntax はハイライトに使う言語毎の字句規則
+type languageSyntax struct {
+	keywords      map[string]bool
+	lineComments  []string
+	blockComments bool   // /* */ をコメントとして扱う
+	quotes        string // 文字列リテラルの引用符
+}
+
+// newSyntax はキーワード一覧から字句規則を作成
+func newSyntax(keywords string, lineComments []string, blockComments bool, quotes string) *languageSyntax {
+	syntax := &languageSyntax{
+		keywords:      make(map[string]bool),
+		lineComments:  lineComments,
+		blockComments: blockComments,
+		quotes:        quotes,
+	}
+	for _, keyword := range strings.Fields(keywords) {
+		syntax.keywords[keyword] = true
+	}
+	return syntax
+}
+
+var (
+	goSyntax = newSyntax("break case chan const continue default defer else fallthrough for func go goto if import interface map package range return select struct switch type var nil true false iota",
+		[]string{"//"}, true, "\"'`")
+	jsSyntax = newSyntax("async await break case catch class const continue default delete do else export extends false finally for from function if import in instanceof interface let new null return switch this throw true try type typeof undefined var void while yield",
+		[]string{"//"}, true, "\"'`")
+	pythonSyntax = newSyntax("and as assert async await break class continue def del elif else except False finally for from global if import in is lambda None nonlocal not or pass raise return self True try while with yield",
+		[]string{"#"}, false, "\"'")
+	shellSyntax = newSyntax("case do done elif else esac export fi for function if in local return then until while",
+		[]string{"#"}, false, "\"'")
+	rustSyntax = newSyntax("as async await break const continue crate else enum false fn for if impl in let loop match mod move mut pub ref return self Self static struct super trait true type unsafe use where while",
+		[]string{"//"}, true, "\"")
+	cSyntax = newSyntax("auto break case char class const continue default delete do double else enum extern false final float for if int long namespace new null private protected public return short static struct switch this throw true try typedef union unsigned void volatile while",
+		[]string{"//"}, true, "\"'")
+)
+
+// languageSyntaxes はコードブロックの言語名と字句規則の対応
+var languageSyntaxes = map[string]*languageSyntax{
+	"go":         goSyntax,
+	"golang":     goSyntax,
+	"js":         jsSyntax,
+	"javascript": jsSyntax,
+	"ts":         jsSyntax,
+	"typescript": jsSyntax,
+	"jsx":        jsSyntax,
+	"tsx":        jsSyntax,
+	"py":         pythonSyntax,
+	"python":     pythonSyntax,
+	"sh":         shellSyntax,
+	"bash":       shellSyntax,
+	"shell":      shellSyntax,
+	"zsh":        shellSyntax,
+	"rs":         rustSyntax,
+	"rust":       rustSyntax,
+	"c":          cSyntax,
+	"cpp":        cSyntax,
+	"c++":        cSyntax,
+	"java":       cSyntax,
+	"kotlin":     cSyntax,
+	"swift":      cSyntax,
+	"yaml":       newSyntax("true false null", []string{"#"}, false, "\"'"),
+	"yml":        newSyntax("true false null", []string{"#"}, false, "\"'"),
+	"json":       newSyntax("true false null", nil, false, "\""),
+}
+
+// highlight はコードをエスケープし、言語が分かる場合はトークン毎にspanで色付けする
+func highlight(language, code string) string {
+	language = strings.ToLower(language)
+	if language == "diff" || language == "patch" {
+		return highlightDiff(code)
+	}
+	syntax, ok := languageSyntaxes[language]
+	if !ok {
+		return html.EscapeString(code)
+	}
+	return syntax.highlight(code)
+}
+
+// highlightDiff は追加・削除・ハンク行を色分け
+func highlightDiff(code string) string {
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		escaped := html.EscapeString(line)
+		switch {
+		case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---") || strings.HasPrefix(line, "diff "):
+			lines[i] = "<span class=\"meta\">" + escaped + "</span>"
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = "<span class=\"hunk\">" + escaped + "</span>"
+		case strings.HasPrefix(line, "+"):
+			lines[i] = "<span class=\"add\">" + escaped + "</span>"
+		case strings.HasPrefix(line, "-"):
+			lines[i] = "<span class=\"del\">" + escaped + "</span>"
+		default:
+			lines[i] = escaped + "\n"
+		}
+	}
+	// span（display:block）の行は改行を含めない
+	return strings.TrimSuffix(strings.Join(lines, ""), "\n")
+}
+
+// highlight はコメント・文字列・数値・キーワードを識別してspanで囲む
+func (s *languageSyntax) highlight(code string) string {
+	var sb strings.Builder
+	span := func(class, text string) {
+		sb.WriteString("<span class=\"" + class + "\">" + html.EscapeString(text) + "</span>")
+	}
+
+	for i := 0; i < len(code); {
+		rest := code[i:]
+
+		if comment := s.lineComment(rest); comment != "" {
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			span("com", rest[:end])
+			i += end
+			continue
+		}
+		if s.blockComments && strings.HasPrefix(rest, "/*") {
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				end = len(rest)
+			} else {
+				end += 4
+			}
+			span("com", rest[:end])
+			i += end
+			continue
+		}
+
+		c := code[i]
+		switch {
+		case strings.IndexByte(s.quotes, c) >= 0:
+			end := stringEnd(rest)
+			span("str", rest[:end])
+			i += end
+		case isDigit(c) && (i == 0 || !isIdent(code[i-1])):
+			end := 1
+			for end < len(rest) && (isIdent(rest[end]) || rest[end] == '.') {
+				end++
+			}
+			span("num", rest[:end])
+			i += end
+		case isIdent(c):
+			end := 1
+			for end < len(rest) && isIdent(rest[end]) {
+				end++
+			}
+			if word := rest[:end]; s.keywords[word] {
+				span("kw", word)
+			} else {
+				sb.WriteString(html.EscapeString(word))
+			}
+			i += end
+		default:
+			sb.WriteString(html.EscapeString(string(c)))
+			i++
+		}
+	}
+	return sb.String()
+}
+
+// lineComment は行コメントの開始記号で始まる場合にその記号を返す
+func (s *languageSyntax) lineComment(text string) string {
+	for _, prefix := range s.lineComments {
+		if strings.HasPrefix(text, prefix) {
+			return prefix
+		}
+	}
+	return ""
+}
+
+// stringEnd は引用符で始まる文字列リテラルの終端位置（閉じていなければ行末）
+func stringEnd(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote != '`' {
+				i++
+			}
+		case quote:
+			return i + 1
+		case '\n':
+			if quote != '`' {
+				return i
+			}
+		}
+	}
+	return len(text)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isIdent(c byte) bool {
+	return c == '_' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || c >= 0x80
+}
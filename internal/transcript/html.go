@@ -0,0 +1,213 @@
+package transcript
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// htmlStyle はエクスポートHTMLに埋め込むスタイル（外部リソースに依存しない）
+const htmlStyle = `body{font-family:-apple-system,"Segoe UI",Helvetica,Arial,sans-serif;max-width:960px;margin:2em auto;padding:0 1em;color:#1f2328;line-height:1.5}
+header ul{color:#59636e;padding-left:1.2em}
+section{border-left:4px solid #d1d9e0;padding:.2em 1em;margin:1.2em 0}
+section.user{border-color:#1a7f37}
+section.assistant{border-color:#0969da}
+section.tool,section.command{border-color:#9a6700}
+section.diff{border-color:#8250df}
+h2,h3{margin:.4em 0;font-size:1.05em}
+.time{color:#59636e;font-weight:normal;font-size:.9em}
+.ok{color:#1a7f37}.ng{color:#cf222e}
+pre{background:#f6f8fa;border-radius:6px;padding:.8em;overflow-x:auto;font-size:.88em;line-height:1.4}
+code{font-family:ui-monospace,SFMono-Regular,Menlo,Consolas,monospace}
+p code,li code{background:#eff1f3;border-radius:4px;padding:.1em .3em}
+.kw{color:#cf222e}.str{color:#0a3069}.com{color:#6e7781;font-style:italic}.num{color:#0550ae}
+.add{color:#116329;background:#dafbe1;display:block}.del{color:#82071e;background:#ffebe9;display:block}.hunk{color:#8250df;display:block}.meta{color:#59636e;font-weight:bold;display:block}`
+
+// RenderHTML は会話記録を単体で閲覧できるHTMLとして出力（コードブロックは構文ハイライト）
+func RenderHTML(t *Transcript) string {
+	var sb strings.Builder
+
+	title := html.EscapeString("vyb session " + t.SessionID)
+	sb.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	sb.WriteString("<title>" + title + "</title>\n<style>\n" + htmlStyle + "\n</style>\n</head>\n<body>\n")
+	sb.WriteString("<header>\n<h1>" + title + "</h1>\n<ul>\n")
+	for _, line := range headerLines(t) {
+		sb.WriteString("<li>" + html.EscapeString(line) + "</li>\n")
+	}
+	sb.WriteString("</ul>\n</header>\n")
+
+	for _, entry := range t.Entries {
+		switch entry.Kind {
+		case KindUser:
+			sb.WriteString(fmt.Sprintf("<section class=\"user\">\n<h2>👤 User <span class=\"time\">%s</span></h2>\n", clock(entry.Timestamp)))
+			sb.WriteString(markdownToHTML(entry.Content))
+		case KindAssistant:
+			sb.WriteString(fmt.Sprintf("<section class=\"assistant\">\n<h2>🤖 Assistant <span class=\"time\">%s</span></h2>\n", clock(entry.Timestamp)))
+			sb.WriteString(markdownToHTML(entry.Content))
+		case KindTool:
+			sb.WriteString("<section class=\"tool\">\n<h3>🔧 " + inlineHTML(entryTitle(entry)) + " " + statusHTML(entry) + "</h3>\n")
+			sb.WriteString(codeBlockHTML("text", toolOutput(entry)))
+		case KindCommand:
+			sb.WriteString("<section class=\"command\">\n<h3>⚡ " + inlineHTML(entryTitle(entry)) + " " + statusHTML(entry) + "</h3>\n")
+			sb.WriteString(codeBlockHTML("console", commandOutput(entry)))
+		case KindDiff:
+			sb.WriteString("<section class=\"diff\">\n<h3>📝 Changes</h3>\n")
+			sb.WriteString(codeBlockHTML("diff", entry.Content))
+		default:
+			continue
+		}
+		sb.WriteString("</section>\n")
+	}
+
+	sb.WriteString("</body>\n</html>\n")
+	return sb.String()
+}
+
+// statusHTML は成否表示
+func statusHTML(entry Entry) string {
+	class := "ok"
+	if !entry.Success {
+		class = "ng"
+	}
+	return fmt.Sprintf("<span class=\"%s\">%s</span>", class, html.EscapeString(statusMark(entry)))
+}
+
+// markdownToHTML は応答本文の簡易Markdown（フェンス付きコードブロック・見出し・箇条書き・段落）をHTMLに変換
+func markdownToHTML(text string) string {
+	var sb strings.Builder
+	var paragraph, items []string
+
+	flushParagraph := func() {
+		if len(paragraph) > 0 {
+			sb.WriteString("<p>" + strings.Join(paragraph, "<br>\n") + "</p>\n")
+			paragraph = nil
+		}
+	}
+	flushList := func() {
+		if len(items) > 0 {
+			sb.WriteString("<ul>\n")
+			for _, item := range items {
+				sb.WriteString("<li>" + item + "</li>\n")
+			}
+			sb.WriteString("</ul>\n")
+			items = nil
+		}
+	}
+
+	lines := strings.Split(strings.ReplaceAll(text, "\r\n", "\n"), "\n")
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		if fence, language, ok := openingFence(trimmed); ok {
+			flushParagraph()
+			flushList()
+			var code []string
+			for i++; i < len(lines); i++ {
+				if strings.TrimSpace(lines[i]) == fence {
+					break
+				}
+				code = append(code, lines[i])
+			}
+			sb.WriteString(codeBlockHTML(language, strings.Join(code, "\n")))
+			continue
+		}
+
+		switch {
+		case trimmed == "":
+			flushParagraph()
+			flushList()
+		case strings.HasPrefix(trimmed, "#"):
+			level := len(trimmed) - len(strings.TrimLeft(trimmed, "#"))
+			if level > 6 || !strings.HasPrefix(trimmed[level:], " ") {
+				paragraph = append(paragraph, inlineHTML(trimmed))
+				continue
+			}
+			flushParagraph()
+			flushList()
+			// 会話の見出し（h2）より下位にする
+			tag := fmt.Sprintf("h%d", level+3)
+			if level+3 > 6 {
+				tag = "h6"
+			}
+			sb.WriteString("<" + tag + ">" + inlineHTML(strings.TrimSpace(trimmed[level:])) + "</" + tag + ">\n")
+		case strings.HasPrefix(trimmed, "- ") || strings.HasPrefix(trimmed, "* "):
+			flushParagraph()
+			items = append(items, inlineHTML(trimmed[2:]))
+		default:
+			flushList()
+			paragraph = append(paragraph, inlineHTML(trimmed))
+		}
+	}
+	flushParagraph()
+	flushList()
+	return sb.String()
+}
+
+// openingFence は ``` または ~~~ で始まるフェンス行を判定
+func openingFence(line string) (fence, language string, ok bool) {
+	for _, c := range []byte{'`', '~'} {
+		n := longestPrefix(line, c)
+		if n >= 3 {
+			return line[:n], strings.TrimSpace(line[n:]), true
+		}
+	}
+	return "", "", false
+}
+
+// longestPrefix は先頭から続く文字の数
+func longestPrefix(s string, c byte) int {
+	n := 0
+	for n < len(s) && s[n] == c {
+		n++
+	}
+	return n
+}
+
+// inlineHTML はインラインコード（`code`）と強調（**bold**）を変換し、その他はエスケープ
+func inlineHTML(text string) string {
+	var sb strings.Builder
+	parts := strings.Split(text, "`")
+	for i, part := range parts {
+		// 閉じられていないバッククォートはそのまま
+		if i%2 == 1 && i < len(parts)-1 {
+			sb.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		if i%2 == 1 {
+			sb.WriteString("`")
+		}
+		sb.WriteString(boldHTML(html.EscapeString(part)))
+	}
+	return sb.String()
+}
+
+// boldHTML は **text** を <strong> に変換（エスケープ済みの文字列を受け取る）
+func boldHTML(escaped string) string {
+	parts := strings.Split(escaped, "**")
+	if len(parts) < 3 {
+		return escaped
+	}
+	var sb strings.Builder
+	for i, part := range parts {
+		switch {
+		case i%2 == 1 && i < len(parts)-1:
+			sb.WriteString("<strong>" + part + "</strong>")
+		case i%2 == 1:
+			sb.WriteString("**" + part)
+		default:
+			sb.WriteString(part)
+		}
+	}
+	return sb.String()
+}
+
+// codeBlockHTML はコードブロックを言語に応じてハイライトしたpre要素にする
+func codeBlockHTML(language, code string) string {
+	code = strings.TrimRight(code, "\n")
+	class := ""
+	if language != "" {
+		class = fmt.Sprintf(" class=\"language-%s\"", html.EscapeString(language))
+	}
+	return "<pre><code" + class + ">" + highlight(language, code) + "</code></pre>\n"
+}
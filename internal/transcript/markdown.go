@@ -0,0 +1,137 @@
+package transcript
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RenderMarkdown は会話記録をMarkdownとして出力
+// 応答本文はそのまま（コードブロックを含むMarkdown）、ツール出力・コマンド出力・差分はフェンスで囲む
+func RenderMarkdown(t *Transcript) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("# vyb session %s\n\n", t.SessionID))
+	for _, line := range headerLines(t) {
+		sb.WriteString("- " + line + "\n")
+	}
+	sb.WriteString("\n---\n")
+
+	for _, entry := range t.Entries {
+		sb.WriteString("\n")
+		switch entry.Kind {
+		case KindUser:
+			sb.WriteString(fmt.Sprintf("## 👤 User · %s\n\n", clock(entry.Timestamp)))
+			sb.WriteString(strings.TrimRight(entry.Content, "\n") + "\n")
+		case KindAssistant:
+			sb.WriteString(fmt.Sprintf("## 🤖 Assistant · %s\n\n", clock(entry.Timestamp)))
+			sb.WriteString(strings.TrimRight(entry.Content, "\n") + "\n")
+		case KindTool:
+			sb.WriteString(fmt.Sprintf("### 🔧 %s %s\n\n", entryTitle(entry), statusMark(entry)))
+			writeFence(&sb, "text", toolOutput(entry))
+		case KindCommand:
+			sb.WriteString(fmt.Sprintf("### ⚡ %s %s\n\n", entryTitle(entry), statusMark(entry)))
+			writeFence(&sb, "console", commandOutput(entry))
+		case KindDiff:
+			sb.WriteString("### 📝 Changes\n\n")
+			writeFence(&sb, "diff", entry.Content)
+		}
+	}
+	return sb.String()
+}
+
+// headerLines はセッション情報の行
+func headerLines(t *Transcript) []string {
+	lines := []string{}
+	if t.Model != "" {
+		lines = append(lines, "Model: "+t.Model)
+	}
+	if !t.CreatedAt.IsZero() {
+		lines = append(lines, "Started: "+t.CreatedAt.Format("2006-01-02 15:04:05"))
+	}
+	lines = append(lines, fmt.Sprintf("Turns: %d", t.Turns()))
+	lines = append(lines, "Exported: "+time.Now().Format("2006-01-02 15:04:05"))
+	return lines
+}
+
+// entryTitle はツール・コマンド項目の見出し
+func entryTitle(entry Entry) string {
+	switch {
+	case entry.Kind == KindCommand && entry.Tool != "" && entry.Tool != "bash":
+		return fmt.Sprintf("%s `%s`", entry.Tool, entry.Command)
+	case entry.Kind == KindCommand:
+		return fmt.Sprintf("`%s`", entry.Command)
+	case entry.Command != "":
+		return fmt.Sprintf("%s `%s`", entry.Tool, entry.Command)
+	case entry.Path != "":
+		return fmt.Sprintf("%s `%s`", entry.Tool, entry.Path)
+	default:
+		return entry.Tool
+	}
+}
+
+// statusMark は成否と終了コードの表示
+func statusMark(entry Entry) string {
+	switch {
+	case entry.Success:
+		return "✓"
+	case entry.ExitCode != 0:
+		return fmt.Sprintf("✗ (exit %d)", entry.ExitCode)
+	default:
+		return "✗"
+	}
+}
+
+// toolOutput はツール出力（エラーがあれば末尾に付与）
+func toolOutput(entry Entry) string {
+	output := strings.TrimRight(entry.Content, "\n")
+	if entry.Error != "" {
+		if output != "" {
+			output += "\n"
+		}
+		output += "error: " + entry.Error
+	}
+	return output
+}
+
+// commandOutput はプロンプト付きのコマンド出力
+func commandOutput(entry Entry) string {
+	return strings.TrimRight("$ "+entry.Command+"\n"+toolOutput(entry), "\n")
+}
+
+// writeFence は本文中のバッククォートより長いフェンスでコードブロックを書く
+func writeFence(sb *strings.Builder, language, content string) {
+	fence := strings.Repeat("`", longestRun(content, '`')+1)
+	if len(fence) < 3 {
+		fence = "```"
+	}
+	sb.WriteString(fence + language + "\n")
+	if content = strings.TrimRight(content, "\n"); content != "" {
+		sb.WriteString(content + "\n")
+	}
+	sb.WriteString(fence + "\n")
+}
+
+// longestRun は文字の最長連続数
+func longestRun(s string, c byte) int {
+	longest, current := 0, 0
+	for i := 0; i < len(s); i++ {
+		if s[i] != c {
+			current = 0
+			continue
+		}
+		current++
+		if current > longest {
+			longest = current
+		}
+	}
+	return longest
+}
+
+// clock は項目の時刻表示
+func clock(t time.Time) string {
+	if t.IsZero() {
+		return "--:--:--"
+	}
+	return t.Format("15:04:05")
+}
@@ -0,0 +1,199 @@
+package transcript
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// Kind は会話記録の項目の種類
+type Kind string
+
+const (
+	KindUser      Kind = "user"      // ユーザー入力
+	KindAssistant Kind = "assistant" // アシスタントの応答
+	KindTool      Kind = "tool"      // ツール呼び出し
+	KindCommand   Kind = "command"   // コマンド実行（!command・ビルド・テスト等）
+	KindDiff      Kind = "diff"      // ターン中のファイル変更
+)
+
+// Entry は会話記録の1項目
+type Entry struct {
+	Kind      Kind      `json:"kind"`
+	Timestamp time.Time `json:"timestamp"`
+	Content   string    `json:"content,omitempty"` // 入力・応答・ツール出力・unified diff
+	Tool      string    `json:"tool,omitempty"`
+	Command   string    `json:"command,omitempty"`
+	Path      string    `json:"path,omitempty"`
+	ExitCode  int       `json:"exit_code,omitempty"`
+	Success   bool      `json:"success"`
+	Error     string    `json:"error,omitempty"`
+}
+
+// Transcript はセッションの会話記録（エクスポート用）
+type Transcript struct {
+	SessionID string    `json:"session_id"`
+	Model     string    `json:"model,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	Entries   []Entry   `json:"entries"`
+}
+
+// New は空の会話記録を作成
+func New(sessionID, model string) *Transcript {
+	return &Transcript{
+		SessionID: sessionID,
+		Model:     model,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Add は項目を追加（時刻が未設定なら現在時刻）
+func (t *Transcript) Add(entry Entry) {
+	if entry.Timestamp.IsZero() {
+		entry.Timestamp = time.Now()
+	}
+	t.Entries = append(t.Entries, entry)
+}
+
+// Clone は項目を共有しないコピーを返す
+func (t *Transcript) Clone() *Transcript {
+	clone := *t
+	clone.Entries = append([]Entry{}, t.Entries...)
+	return &clone
+}
+
+// Turns はユーザー入力の数
+func (t *Transcript) Turns() int {
+	turns := 0
+	for _, entry := range t.Entries {
+		if entry.Kind == KindUser {
+			turns++
+		}
+	}
+	return turns
+}
+
+// FromAuditEvents は監査ログから会話記録を再構成
+// 入力・応答が記録されていない古いログではLLM応答を応答として扱う
+func FromAuditEvents(sessionID string, events []logger.AuditEvent) *Transcript {
+	t := &Transcript{SessionID: sessionID}
+
+	hasTurns := false
+	for _, event := range events {
+		if event.Type == logger.AuditUserInput {
+			hasTurns = true
+			break
+		}
+	}
+
+	for _, event := range events {
+		if t.CreatedAt.IsZero() || event.Timestamp.Before(t.CreatedAt) {
+			t.CreatedAt = event.Timestamp
+		}
+		if t.Model == "" && event.Model != "" {
+			t.Model = event.Model
+		}
+
+		entry := Entry{
+			Timestamp: event.Timestamp,
+			Content:   event.Content,
+			Tool:      event.Tool,
+			Command:   event.Command,
+			Path:      event.Path,
+			ExitCode:  event.ExitCode,
+			Success:   event.Success,
+			Error:     event.Error,
+		}
+		switch event.Type {
+		case logger.AuditUserInput:
+			entry.Kind = KindUser
+		case logger.AuditAssistant:
+			entry.Kind = KindAssistant
+		case logger.AuditLLMResponse:
+			if hasTurns {
+				continue
+			}
+			entry.Kind = KindAssistant
+		case logger.AuditToolCall:
+			entry.Kind = KindTool
+		case logger.AuditCommand, logger.AuditTask:
+			entry.Kind = KindCommand
+		case logger.AuditDiff:
+			entry.Kind = KindDiff
+		default:
+			continue
+		}
+		t.Entries = append(t.Entries, entry)
+	}
+	return t
+}
+
+// Format はエクスポート形式
+type Format string
+
+const (
+	FormatMarkdown Format = "markdown"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat は形式名を解釈（md/markdown/html）
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "md", "markdown":
+		return FormatMarkdown, nil
+	case "html", "htm":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("未対応のエクスポート形式です: %s（markdown または html）", name)
+	}
+}
+
+// FormatFromPath は拡張子から形式を判定（.html/.htm 以外はMarkdown）
+func FormatFromPath(path string) Format {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".html", ".htm":
+		return FormatHTML
+	default:
+		return FormatMarkdown
+	}
+}
+
+// Render は指定形式で会話記録を出力
+func Render(t *Transcript, format Format) string {
+	if format == FormatHTML {
+		return RenderHTML(t)
+	}
+	return RenderMarkdown(t)
+}
+
+// WriteFile は拡張子に応じた形式で会話記録をファイルに書き出す
+func WriteFile(t *Transcript, path string) (Format, error) {
+	format := FormatFromPath(path)
+	return format, WriteFileFormat(t, path, format)
+}
+
+// WriteFileFormat は指定形式で会話記録をファイルに書き出す
+func WriteFileFormat(t *Transcript, path string, format Format) error {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("出力ディレクトリ作成エラー: %w", err)
+		}
+	}
+	if err := os.WriteFile(path, []byte(Render(t, format)), 0644); err != nil {
+		return fmt.Errorf("エクスポート書き込みエラー: %w", err)
+	}
+	return nil
+}
+
+// DefaultFileName はエクスポート先を省略した場合のファイル名
+func DefaultFileName(sessionID string, format Format) string {
+	safeID := strings.NewReplacer("/", "_", "\\", "_", "..", "_").Replace(sessionID)
+	if format == FormatHTML {
+		return "vyb-" + safeID + ".html"
+	}
+	return "vyb-" + safeID + ".md"
+}
@@ -0,0 +1,150 @@
+package transcript
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/logger"
+)
+
+// sampleTranscript はツール呼び出し・コマンド・差分を含む会話記録
+func sampleTranscript() *Transcript {
+	t := New("session-1", "qwen2.5-coder:14b")
+	t.Add(Entry{Kind: KindUser, Content: "main.go を読んで <script> を説明して"})
+	t.Add(Entry{Kind: KindTool, Tool: "read", Path: "main.go", Content: "package main\n", Success: true})
+	t.Add(Entry{Kind: KindAssistant, Content: "## 概要\n\n`main` 関数です。\n\n```go\nfunc main() {\n\t// entry\n\tfmt.Println(\"hi\")\n}\n```\n"})
+	t.Add(Entry{Kind: KindCommand, Command: "go test ./...", Content: "FAIL", ExitCode: 1})
+	t.Add(Entry{Kind: KindDiff, Content: "--- a/main.go\n+++ b/main.go\n@@ -1 +1,2 @@\n package main\n+// added\n"})
+	return t
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	output := RenderMarkdown(sampleTranscript())
+
+	for _, want := range []string{
+		"# vyb session session-1",
+		"- Model: qwen2.5-coder:14b",
+		"- Turns: 1",
+		"## 👤 User",
+		"### 🔧 read `main.go` ✓",
+		"```go\nfunc main() {",
+		"### ⚡ `go test ./...` ✗ (exit 1)",
+		"```console\n$ go test ./...\nFAIL\n```",
+		"### 📝 Changes",
+		"```diff\n--- a/main.go",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("Markdownに %q が含まれていない:\n%s", want, output)
+		}
+	}
+}
+
+func TestRenderMarkdownFenceLongerThanContent(t *testing.T) {
+	tr := New("s", "")
+	tr.Add(Entry{Kind: KindTool, Tool: "read", Path: "README.md", Content: "```go\nx\n```", Success: true})
+
+	output := RenderMarkdown(tr)
+	if !strings.Contains(output, "````text\n```go\nx\n```\n````") {
+		t.Errorf("本文のフェンスより長いフェンスで囲まれていない:\n%s", output)
+	}
+}
+
+func TestRenderHTML(t *testing.T) {
+	output := RenderHTML(sampleTranscript())
+
+	for _, want := range []string{
+		"<!DOCTYPE html>",
+		"&lt;script&gt;",
+		"<h5>概要</h5>",
+		"<p><code>main</code> 関数です。</p>",
+		"<code class=\"language-go\">",
+		"<span class=\"kw\">func</span>",
+		"<span class=\"com\">// entry</span>",
+		"<span class=\"str\">&#34;hi&#34;</span>",
+		"<span class=\"add\">+// added</span>",
+		"<span class=\"ng\">✗ (exit 1)</span>",
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("HTMLに %q が含まれていない", want)
+		}
+	}
+	if strings.Contains(output, "<script>") {
+		t.Error("ユーザー入力がエスケープされていない")
+	}
+}
+
+func TestFromAuditEvents(t *testing.T) {
+	base := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	events := []logger.AuditEvent{
+		{Timestamp: base, Type: logger.AuditUserInput, Content: "テストを実行して", Success: true},
+		{Timestamp: base.Add(time.Second), Type: logger.AuditLLMRequest, Model: "m1", Content: "prompt"},
+		{Timestamp: base.Add(2 * time.Second), Type: logger.AuditLLMResponse, Model: "m1", Content: "raw"},
+		{Timestamp: base.Add(3 * time.Second), Type: logger.AuditTask, Tool: "test", Command: "go test ./...", ExitCode: 1},
+		{Timestamp: base.Add(4 * time.Second), Type: logger.AuditAssistant, Content: "1件失敗しました", Success: true},
+		{Timestamp: base.Add(5 * time.Second), Type: logger.AuditDiff, Content: "+x"},
+	}
+
+	tr := FromAuditEvents("s1", events)
+	if tr.Model != "m1" || !tr.CreatedAt.Equal(base) {
+		t.Errorf("モデル・開始時刻: %q %v", tr.Model, tr.CreatedAt)
+	}
+	var kinds []Kind
+	for _, entry := range tr.Entries {
+		kinds = append(kinds, entry.Kind)
+	}
+	want := []Kind{KindUser, KindCommand, KindAssistant, KindDiff}
+	if strings.Join(kindStrings(kinds), ",") != strings.Join(kindStrings(want), ",") {
+		t.Errorf("項目の種類: %v, 期待値: %v", kinds, want)
+	}
+
+	// 入力が記録されていない古いログはLLM応答を使う
+	legacy := FromAuditEvents("s2", events[1:3])
+	if len(legacy.Entries) != 1 || legacy.Entries[0].Kind != KindAssistant || legacy.Entries[0].Content != "raw" {
+		t.Errorf("古いログの再構成: %+v", legacy.Entries)
+	}
+}
+
+func kindStrings(kinds []Kind) []string {
+	out := make([]string, len(kinds))
+	for i, kind := range kinds {
+		out[i] = string(kind)
+	}
+	return out
+}
+
+func TestWriteFileFormatByExtension(t *testing.T) {
+	dir := t.TempDir()
+	tr := sampleTranscript()
+
+	for name, want := range map[string]Format{"out/session.md": FormatMarkdown, "session.HTML": FormatHTML} {
+		path := filepath.Join(dir, name)
+		format, err := WriteFile(tr, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if format != want {
+			t.Errorf("%s: 形式 %s, 期待値 %s", name, format, want)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if isHTML := strings.HasPrefix(string(data), "<!DOCTYPE html>"); isHTML != (want == FormatHTML) {
+			t.Errorf("%s: 出力内容が形式と一致しない", name)
+		}
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"": FormatMarkdown, "md": FormatMarkdown, "HTML": FormatHTML} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %s, %v", name, got, err)
+		}
+	}
+	if _, err := ParseFormat("pdf"); err == nil {
+		t.Error("未対応の形式がエラーにならない")
+	}
+}
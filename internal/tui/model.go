@@ -0,0 +1,486 @@
+package tui
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/jobs"
+	"github.com/glkt/vyb-code/internal/streaming"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// Pane はTUIの表示ペイン
+type Pane int
+
+const (
+	PaneConversation Pane = iota // 会話と入力
+	PanePlan                     // 現在のターンの実行ステップ・TODO
+	PaneFiles                    // 変更したファイルと差分
+	PaneJobs                     // バックグラウンドジョブと出力
+	paneCount
+)
+
+const (
+	refreshInterval = 500 * time.Millisecond // 会話記録・ジョブの再取得間隔
+	maxNotes        = 500                    // 保持するコマンド出力等の件数
+	maxOutputLines  = 20000                  // 1つの出力で保持する行数（超えた分は先頭から捨てる）
+	maxInputHistory = 100                    // 入力履歴の件数
+)
+
+// noteKind は会話記録にない表示行の種類
+type noteKind int
+
+const (
+	noteInput  noteKind = iota // スラッシュコマンド等の入力
+	noteOutput                 // コマンド・ログの出力
+	noteError                  // エラー
+)
+
+// note は会話記録にない表示行（コマンドの出力・エラー等）
+// 続けて取り込んだ出力は1つの note にまとめ、長ければ折り畳む
+type note struct {
+	time    time.Time
+	text    string
+	kind    noteKind
+	lines   []string // 出力の行（noteOutput）
+	dropped int      // maxOutputLines を超えて捨てた行数
+	view    int      // 折り畳んだ出力の表示（0: 折り畳み、n: n ページ目を展開）
+}
+
+type (
+	outputMsg     string // 取り込んだ標準出力の1行
+	tickMsg       time.Time
+	submitDoneMsg struct {
+		err         error
+		interrupted bool
+	}
+)
+
+// Model はペイン構成のチャット画面
+type Model struct {
+	backend Backend
+	title   string
+	width   int
+	height  int
+	pane    Pane
+
+	entries   []transcript.Entry
+	notes     []note
+	files     []touchedFile
+	tree      []string // ワークスペースのディレクトリツリー（TreeReporter、表示中のみ取得）
+	showTree  bool     // ファイルペインに変更したファイルの代わりにツリーを表示
+	jobs      []jobs.Info
+	jobOutput string
+	status    string // バックエンドの状態（StatusReporter、固定中のファイル等）
+	session   string // セッションの題名（TitleReporter）
+
+	fold       streaming.FoldConfig // 長い出力の折り畳み・ページ分割
+	outputOpen bool                 // 最後の note に続けて出力を追加する
+
+	input        []rune
+	cursor       int
+	history      []string
+	historyIndex int
+
+	busy      bool
+	busySince time.Time
+	pending   string // 処理中の入力（会話記録に載るまで表示）
+	cancel    context.CancelFunc
+
+	offset   [paneCount]int // スクロール位置（会話は下端から、他は上端からの行数）
+	selected [paneCount]int // ファイル・ジョブの選択位置
+}
+
+// NewModel はTUIのモデルを作成
+func NewModel(backend Backend, title string) *Model {
+	m := &Model{backend: backend, title: title, width: 80, height: 24, fold: streaming.DefaultFoldConfig()}
+	m.refresh()
+	return m
+}
+
+// Init は定期的な再取得を開始
+func (m *Model) Init() tea.Cmd {
+	return tick()
+}
+
+func tick() tea.Cmd {
+	return tea.Tick(refreshInterval, func(t time.Time) tea.Msg { return tickMsg(t) })
+}
+
+// Update はキー入力・処理結果を反映
+func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+	case tickMsg:
+		m.refresh()
+		return m, tick()
+	case outputMsg:
+		m.appendOutput(string(msg))
+	case submitDoneMsg:
+		m.busy, m.pending, m.cancel = false, "", nil
+		m.outputOpen = false
+		if msg.interrupted {
+			m.addNote(i18n.T("chat.interrupted"), noteOutput)
+		} else if msg.err != nil {
+			m.addNote(msg.err.Error(), noteError)
+		}
+		m.offset[PaneConversation] = 0
+		m.refresh()
+		if m.showTree {
+			m.loadTree()
+		}
+	case tea.KeyMsg:
+		return m.handleKey(msg)
+	}
+	return m, nil
+}
+
+// refresh はバックエンドから会話記録・ジョブを取得し直す
+func (m *Model) refresh() {
+	if record := m.backend.Transcript(); record != nil {
+		m.entries = record.Entries
+	}
+	m.files = touchedFiles(m.entries)
+	m.jobs = m.backend.Jobs()
+	if reporter, ok := m.backend.(StatusReporter); ok {
+		m.status = reporter.Status()
+	}
+	if reporter, ok := m.backend.(TitleReporter); ok {
+		m.session = reporter.Title()
+	}
+	m.selected[PaneFiles] = clamp(m.selected[PaneFiles], 0, len(m.files)-1)
+	m.selected[PaneJobs] = clamp(m.selected[PaneJobs], 0, len(m.jobs)-1)
+	if job, ok := m.selectedJob(); ok {
+		m.jobOutput = m.backend.JobOutput(job.ID)
+	} else {
+		m.jobOutput = ""
+	}
+}
+
+func (m *Model) addNote(text string, kind noteKind) {
+	m.outputOpen = false
+	n := note{time: time.Now(), text: text, kind: kind}
+	if kind == noteOutput {
+		n.lines = strings.Split(text, "\n")
+	}
+	m.notes = append(m.notes, n)
+	if len(m.notes) > maxNotes {
+		m.notes = m.notes[len(m.notes)-maxNotes:]
+	}
+}
+
+// appendOutput は取り込んだ出力の1行を、続けて届いた出力にまとめて追加する
+func (m *Model) appendOutput(text string) {
+	if n := len(m.notes); m.outputOpen && n > 0 && m.notes[n-1].kind == noteOutput {
+		last := &m.notes[n-1]
+		last.lines = append(last.lines, text)
+		if over := len(last.lines) - maxOutputLines; over > 0 {
+			last.lines = append([]string(nil), last.lines[over:]...)
+			last.dropped += over
+		}
+		return
+	}
+	m.addNote(text, noteOutput)
+	m.outputOpen = true
+}
+
+// toggleFold は最新の折り畳んだ出力を1ページずつ展開し、最後のページの次で折り畳みに戻す
+func (m *Model) toggleFold() {
+	for i := len(m.notes) - 1; i >= 0; i-- {
+		n := &m.notes[i]
+		if n.kind != noteOutput || !m.fold.Folds(len(n.lines)) {
+			continue
+		}
+		if n.view < m.fold.Pages(len(n.lines)) {
+			n.view++
+		} else {
+			n.view = 0
+		}
+		return
+	}
+	m.addNote(i18n.T("tui.fold_none"), noteOutput)
+}
+
+func (m *Model) handleKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	key := msg.String()
+	switch key {
+	case "ctrl+c":
+		// 処理中はターンの中断、それ以外は終了
+		if m.busy {
+			if m.cancel != nil {
+				m.cancel()
+			}
+			return m, nil
+		}
+		return m, tea.Quit
+	case "tab":
+		// 会話ペインで入力中なら補完、それ以外は次のペイン
+		if completer, ok := m.backend.(Completer); ok && m.pane == PaneConversation && len(m.input) > 0 {
+			m.complete(completer)
+			return m, nil
+		}
+		m.switchPane((m.pane + 1) % paneCount)
+		return m, nil
+	case "shift+tab":
+		m.switchPane((m.pane + paneCount - 1) % paneCount)
+		return m, nil
+	case "alt+1", "alt+2", "alt+3", "alt+4":
+		m.switchPane(Pane(key[len(key)-1] - '1'))
+		return m, nil
+	case "ctrl+o":
+		// 最新の折り畳んだ出力を展開（大きな出力は1ページずつ）
+		m.toggleFold()
+		return m, nil
+	case "ctrl+y":
+		// 直前の応答の最後のコードブロックをコピー（n 番目は /copy n）
+		if copier, ok := m.backend.(Copier); ok {
+			m.addNote(copier.CopyCodeBlock(0), noteOutput)
+		}
+		return m, nil
+	case "pgup":
+		m.scroll(-m.pageSize())
+		return m, nil
+	case "pgdown":
+		m.scroll(m.pageSize())
+		return m, nil
+	}
+
+	if m.pane == PaneConversation {
+		return m.handleInputKey(msg)
+	}
+
+	switch key {
+	case "1", "2", "3", "4":
+		m.switchPane(Pane(key[0] - '1'))
+	case "q", "esc":
+		m.switchPane(PaneConversation)
+	case "j", "down":
+		m.move(1)
+	case "k", "up":
+		m.move(-1)
+	case "g", "home":
+		m.offset[m.pane] = 0
+	case "G", "end":
+		m.scroll(len(m.paneLines(m.pane)))
+	case "t":
+		if m.pane == PaneFiles {
+			m.toggleTree()
+		}
+	case "x":
+		if job, ok := m.selectedJob(); m.pane == PaneJobs && ok && job.Status == jobs.StatusRunning {
+			if err := m.backend.KillJob(job.ID); err != nil {
+				m.addNote(err.Error(), noteError)
+			}
+			m.refresh()
+		}
+	}
+	return m, nil
+}
+
+// handleInputKey は会話ペインの入力行の編集
+func (m *Model) handleInputKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.Type {
+	case tea.KeyEnter:
+		return m.submit()
+	case tea.KeyRunes, tea.KeySpace:
+		runes := msg.Runes
+		if msg.Type == tea.KeySpace {
+			runes = []rune{' '}
+		}
+		m.input = append(m.input[:m.cursor], append(runes, m.input[m.cursor:]...)...)
+		m.cursor += len(runes)
+	case tea.KeyBackspace:
+		if m.cursor > 0 {
+			m.input = append(m.input[:m.cursor-1], m.input[m.cursor:]...)
+			m.cursor--
+		}
+	case tea.KeyDelete:
+		if m.cursor < len(m.input) {
+			m.input = append(m.input[:m.cursor], m.input[m.cursor+1:]...)
+		}
+	case tea.KeyLeft:
+		m.cursor = clamp(m.cursor-1, 0, len(m.input))
+	case tea.KeyRight:
+		m.cursor = clamp(m.cursor+1, 0, len(m.input))
+	case tea.KeyHome, tea.KeyCtrlA:
+		m.cursor = 0
+	case tea.KeyEnd, tea.KeyCtrlE:
+		m.cursor = len(m.input)
+	case tea.KeyCtrlU:
+		m.input, m.cursor = nil, 0
+	case tea.KeyUp:
+		m.recallHistory(-1)
+	case tea.KeyDown:
+		m.recallHistory(1)
+	case tea.KeyCtrlD:
+		if len(m.input) == 0 && !m.busy {
+			return m, tea.Quit
+		}
+	}
+	return m, nil
+}
+
+// complete はカーソル位置の単語を補完し、補完が進まなければ候補を表示
+func (m *Model) complete(completer Completer) {
+	line, cursor, candidates := completer.Complete(m.input, m.cursor)
+	if len(candidates) > 0 {
+		m.addNote(strings.Join(candidates, "  "), noteOutput)
+		return
+	}
+	m.input, m.cursor = line, cursor
+}
+
+// submit は入力行を送信し、処理をバックグラウンドで開始
+func (m *Model) submit() (tea.Model, tea.Cmd) {
+	input := strings.TrimSpace(string(m.input))
+	if input == "" {
+		return m, nil
+	}
+	if input == "exit" || input == "quit" {
+		return m, tea.Quit
+	}
+	if m.busy {
+		m.addNote(i18n.T("tui.busy"), noteError)
+		return m, nil
+	}
+
+	m.input, m.cursor = nil, 0
+	m.outputOpen = false
+	if len(m.history) == 0 || m.history[len(m.history)-1] != input {
+		m.history = append(m.history, input)
+		if len(m.history) > maxInputHistory {
+			m.history = m.history[1:]
+		}
+	}
+	m.historyIndex = len(m.history)
+
+	// コマンドは会話記録に残らないため入力を表示しておく
+	if isCommand(input) {
+		m.addNote(input, noteInput)
+	} else {
+		m.pending = input
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.busy, m.busySince, m.cancel = true, time.Now(), cancel
+	m.offset[PaneConversation] = 0
+	backend := m.backend
+	return m, func() tea.Msg {
+		err := backend.Submit(ctx, input)
+		interrupted := ctx.Err() != nil
+		cancel()
+		return submitDoneMsg{err: err, interrupted: interrupted}
+	}
+}
+
+// isCommand はモデルに送らずに処理される入力か
+func isCommand(input string) bool {
+	return strings.HasPrefix(input, "/") || strings.HasPrefix(input, "!") || input == "show" || input == "more" || input == "full"
+}
+
+func (m *Model) recallHistory(delta int) {
+	if len(m.history) == 0 {
+		return
+	}
+	m.historyIndex = clamp(m.historyIndex+delta, 0, len(m.history))
+	if m.historyIndex == len(m.history) {
+		m.input = nil
+	} else {
+		m.input = []rune(m.history[m.historyIndex])
+	}
+	m.cursor = len(m.input)
+}
+
+func (m *Model) switchPane(pane Pane) {
+	if pane < 0 || pane >= paneCount {
+		return
+	}
+	m.pane = pane
+	m.refresh()
+}
+
+// toggleTree はファイルペインの表示を変更したファイルとディレクトリツリーで切り替える
+func (m *Model) toggleTree() {
+	if _, ok := m.backend.(TreeReporter); !ok {
+		return
+	}
+	m.showTree = !m.showTree
+	m.offset[PaneFiles] = 0
+	if m.showTree {
+		m.loadTree()
+	}
+}
+
+// loadTree はディレクトリツリーを取得し直す（ターンの終了後にファイルの変更を反映）
+func (m *Model) loadTree() {
+	if reporter, ok := m.backend.(TreeReporter); ok {
+		m.tree = reporter.Tree()
+	}
+}
+
+// move はファイル・ジョブの選択を移動（計画ペイン・ツリーはスクロール）
+func (m *Model) move(delta int) {
+	switch {
+	case m.pane == PaneFiles && m.showTree:
+		m.scroll(delta)
+	case m.pane == PaneFiles:
+		m.selected[PaneFiles] = clamp(m.selected[PaneFiles]+delta, 0, len(m.files)-1)
+		m.offset[PaneFiles] = 0
+	case m.pane == PaneJobs:
+		m.selected[PaneJobs] = clamp(m.selected[PaneJobs]+delta, 0, len(m.jobs)-1)
+		m.offset[PaneJobs] = 0
+		m.refresh()
+	default:
+		m.scroll(delta)
+	}
+}
+
+// scroll は表示位置を delta 行下に動かす（負なら上）
+func (m *Model) scroll(delta int) {
+	limit := len(m.paneLines(m.pane)) - m.windowHeight(m.pane)
+	if m.pane == PaneConversation {
+		// 会話ペインは最新の行を下端に表示し、上に遡る
+		m.offset[m.pane] = clamp(m.offset[m.pane]-delta, 0, limit)
+		return
+	}
+	m.offset[m.pane] = clamp(m.offset[m.pane]+delta, 0, limit)
+}
+
+// windowHeight はペインのスクロール対象の表示行数
+func (m *Model) windowHeight(pane Pane) int {
+	if pane == PaneJobs {
+		return m.bodyHeight() - m.jobListHeight() - 1
+	}
+	return m.bodyHeight()
+}
+
+func (m *Model) pageSize() int {
+	if size := m.bodyHeight() - 2; size > 1 {
+		return size
+	}
+	return 1
+}
+
+func (m *Model) selectedJob() (jobs.Info, bool) {
+	index := m.selected[PaneJobs]
+	if index < 0 || index >= len(m.jobs) {
+		return jobs.Info{}, false
+	}
+	return m.jobs[index], true
+}
+
+// clamp は value を [low, high] に収める（high < low なら low）
+func clamp(value, low, high int) int {
+	if value > high {
+		value = high
+	}
+	if value < low {
+		value = low
+	}
+	return value
+}
@@ -0,0 +1,367 @@
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/glkt/vyb-code/internal/jobs"
+	"github.com/glkt/vyb-code/internal/streaming"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// fakeBackend は入力を記録し、固定の会話記録・ジョブを返す
+type fakeBackend struct {
+	record  *transcript.Transcript
+	jobs    []jobs.Info
+	inputs  []string
+	killed  []int
+	submitF func(ctx context.Context, input string) error
+}
+
+func (b *fakeBackend) Submit(ctx context.Context, input string) error {
+	b.inputs = append(b.inputs, input)
+	if b.submitF != nil {
+		return b.submitF(ctx, input)
+	}
+	return nil
+}
+
+func (b *fakeBackend) Transcript() *transcript.Transcript { return b.record.Clone() }
+func (b *fakeBackend) Jobs() []jobs.Info                  { return b.jobs }
+func (b *fakeBackend) JobOutput(id int) string            { return "line 1\nline 2\n" }
+func (b *fakeBackend) KillJob(id int) error {
+	b.killed = append(b.killed, id)
+	return nil
+}
+
+const samplePatch = `diff --git a/main.go b/main.go
+index 1111111..2222222 100644
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,3 @@
+ package main
+-var x = 1
++var x = 2
+diff --git a/new.go b/new.go
+new file mode 100644
+index 0000000..3333333
+--- /dev/null
++++ b/new.go
+@@ -0,0 +1 @@
++package main
+`
+
+func sampleBackend() *fakeBackend {
+	record := transcript.New("s1", "qwen")
+	record.Add(transcript.Entry{Kind: transcript.KindUser, Content: "fix x"})
+	record.Add(transcript.Entry{Kind: transcript.KindTool, Tool: "read", Path: "main.go", Success: true})
+	record.Add(transcript.Entry{Kind: transcript.KindTool, Tool: "edit", Path: "main.go", Success: true})
+	record.Add(transcript.Entry{Kind: transcript.KindDiff, Content: samplePatch, Success: true})
+	record.Add(transcript.Entry{Kind: transcript.KindAssistant, Content: "Done.\n\n- [x] update x\n- [ ] add test", Success: true})
+	return &fakeBackend{record: record}
+}
+
+func press(m *Model, keys ...tea.KeyMsg) tea.Cmd {
+	var cmd tea.Cmd
+	for _, key := range keys {
+		_, cmd = m.Update(key)
+	}
+	return cmd
+}
+
+func runes(text string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(text)}
+}
+
+func TestPaneSwitching(t *testing.T) {
+	m := NewModel(sampleBackend(), "vyb")
+
+	press(m, tea.KeyMsg{Type: tea.KeyTab})
+	if m.pane != PanePlan {
+		t.Fatalf("Tab で次のペインに移るはず: %d", m.pane)
+	}
+	press(m, runes("3"))
+	if m.pane != PaneFiles {
+		t.Fatalf("数字キーでペインを選べるはず: %d", m.pane)
+	}
+	press(m, tea.KeyMsg{Type: tea.KeyShiftTab}, tea.KeyMsg{Type: tea.KeyShiftTab}, tea.KeyMsg{Type: tea.KeyShiftTab})
+	if m.pane != PaneJobs {
+		t.Fatalf("Shift+Tab で前のペインに戻り、先頭から末尾へ回るはず: %d", m.pane)
+	}
+	press(m, tea.KeyMsg{Type: tea.KeyEsc})
+	if m.pane != PaneConversation {
+		t.Fatalf("Esc で会話ペインに戻るはず: %d", m.pane)
+	}
+
+	// 会話ペインでは数字は入力として扱う
+	press(m, runes("2"))
+	if m.pane != PaneConversation || string(m.input) != "2" {
+		t.Errorf("会話ペインの数字は入力になるはず: pane=%d input=%q", m.pane, string(m.input))
+	}
+	press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4"), Alt: true})
+	if m.pane != PaneJobs {
+		t.Errorf("Alt+数字はどのペインからも切り替えられるはず: %d", m.pane)
+	}
+}
+
+// completingBackend は入力の末尾の単語を固定の候補で補完する
+type completingBackend struct {
+	*fakeBackend
+}
+
+func (b completingBackend) Complete(line []rune, cursor int) ([]rune, int, []string) {
+	if strings.HasSuffix(string(line[:cursor]), "ma") {
+		return append(line[:cursor:cursor], []rune("in.go ")...), cursor + 6, nil
+	}
+	return line, cursor, []string{"main.go", "model.go"}
+}
+
+func TestTabCompletesInput(t *testing.T) {
+	m := NewModel(completingBackend{sampleBackend()}, "")
+
+	press(m, runes("open ma"), tea.KeyMsg{Type: tea.KeyTab})
+	if m.pane != PaneConversation || string(m.input) != "open main.go " || m.cursor != len(m.input) {
+		t.Fatalf("入力中の Tab は補完するはず: pane=%d input=%q", m.pane, string(m.input))
+	}
+
+	press(m, runes("m"), tea.KeyMsg{Type: tea.KeyTab})
+	if !strings.Contains(m.View(), "main.go  model.go") {
+		t.Error("補完が進まなければ候補を表示するはず")
+	}
+
+	press(m, tea.KeyMsg{Type: tea.KeyCtrlU}, tea.KeyMsg{Type: tea.KeyTab})
+	if m.pane != PanePlan {
+		t.Errorf("入力が空なら Tab でペインを切り替えるはず: %d", m.pane)
+	}
+}
+
+func TestSubmitRunsInBackground(t *testing.T) {
+	backend := sampleBackend()
+	m := NewModel(backend, "")
+
+	cmd := press(m, runes("hello"), tea.KeyMsg{Type: tea.KeySpace}, runes("world"), tea.KeyMsg{Type: tea.KeyEnter})
+	if !m.busy || cmd == nil {
+		t.Fatal("送信すると処理中になるはず")
+	}
+	if len(m.input) != 0 {
+		t.Error("送信後は入力行を空にするはず")
+	}
+	if !strings.Contains(m.View(), "hello world") {
+		t.Error("会話記録に載るまで処理中の入力を表示するはず")
+	}
+
+	m.Update(cmd())
+	if m.busy {
+		t.Error("処理が終わったら処理中を解除するはず")
+	}
+	if len(backend.inputs) != 1 || backend.inputs[0] != "hello world" {
+		t.Errorf("入力をバックエンドに渡すはず: %v", backend.inputs)
+	}
+
+	// 入力履歴を上キーで呼び出す
+	press(m, tea.KeyMsg{Type: tea.KeyUp})
+	if string(m.input) != "hello world" {
+		t.Errorf("上キーで前の入力を呼び出すはず: %q", string(m.input))
+	}
+}
+
+func TestCtrlCInterruptsThenQuits(t *testing.T) {
+	backend := sampleBackend()
+	backend.submitF = func(ctx context.Context, input string) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+	m := NewModel(backend, "")
+
+	cmd := press(m, runes("/test"), tea.KeyMsg{Type: tea.KeyEnter})
+	done := make(chan tea.Msg, 1)
+	go func() { done <- cmd() }()
+
+	if quit := press(m, tea.KeyMsg{Type: tea.KeyCtrlC}); quit != nil {
+		t.Fatal("処理中の Ctrl+C は終了せず中断するはず")
+	}
+	select {
+	case msg := <-done:
+		m.Update(msg)
+	case <-time.After(time.Second):
+		t.Fatal("Ctrl+C で処理のコンテキストを取り消すはず")
+	}
+	if m.busy || !strings.Contains(m.View(), "/test") {
+		t.Error("中断後は処理中を解除し、コマンドの入力を表示に残すはず")
+	}
+
+	quit := press(m, tea.KeyMsg{Type: tea.KeyCtrlC})
+	if quit == nil {
+		t.Fatal("処理中でなければ Ctrl+C で終了するはず")
+	}
+	if _, ok := quit().(tea.QuitMsg); !ok {
+		t.Error("終了コマンドを返すはず")
+	}
+}
+
+func TestFilesPane(t *testing.T) {
+	m := NewModel(sampleBackend(), "")
+	if len(m.files) != 2 {
+		t.Fatalf("差分の2ファイルを一覧にするはず（edit ツールの main.go と重複しない）: %+v", m.files)
+	}
+	// 最近変更した順
+	if m.files[0].path != "new.go" || m.files[0].marker() != "A" || m.files[1].path != "main.go" {
+		t.Errorf("ファイルの並び・種類が不正: %+v", m.files)
+	}
+
+	press(m, tea.KeyMsg{Type: tea.KeyTab}, tea.KeyMsg{Type: tea.KeyTab}, runes("j"))
+	view := m.View()
+	for _, want := range []string{"main.go  +1 -1", "-var x = 1", "+var x = 2"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("選択したファイルの差分に %q が含まれるはず:\n%s", want, view)
+		}
+	}
+}
+
+// treeBackend はディレクトリツリーを返す
+type treeBackend struct {
+	*fakeBackend
+	calls int
+}
+
+func (b *treeBackend) Tree() []string {
+	b.calls++
+	lines := []string{"/work/", "├── cmd/ (+2)  2026-01-02 10:00"}
+	for i := 0; i < 40; i++ {
+		lines = append(lines, fmt.Sprintf("├── file%02d.go  1.0KB  2026-01-02 10:00", i))
+	}
+	return append(lines, "1 directories, 40 files (depth 3)")
+}
+
+func TestFilesPaneTree(t *testing.T) {
+	backend := &treeBackend{fakeBackend: sampleBackend()}
+	m := NewModel(backend, "")
+	press(m, runes("t"))
+	if m.showTree || backend.calls != 0 {
+		t.Fatal("会話ペインの t は入力になり、ツリーを取得しないはず")
+	}
+
+	press(m, tea.KeyMsg{Type: tea.KeyEsc}, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3"), Alt: true}, runes("t"))
+	view := m.View()
+	if !m.showTree || backend.calls != 1 || !strings.Contains(view, "├── cmd/ (+2)") || strings.Contains(view, "-var x = 1") {
+		t.Fatalf("t でファイルペインをツリーに切り替えるはず:\n%s", view)
+	}
+	press(m, runes("G"))
+	if view := m.View(); !strings.Contains(view, "40 files") || strings.Contains(view, "cmd/") {
+		t.Errorf("ツリーはスクロールできるはず:\n%s", view)
+	}
+
+	m.Update(submitDoneMsg{})
+	if backend.calls != 2 {
+		t.Errorf("ターンの終了後にツリーを取得し直すはず: %d", backend.calls)
+	}
+	press(m, runes("t"))
+	if view := m.View(); m.showTree || !strings.Contains(view, "new.go") {
+		t.Errorf("もう一度 t で変更したファイルに戻るはず:\n%s", view)
+	}
+
+	// ツリーを返せない Backend では切り替えない
+	plain := NewModel(sampleBackend(), "")
+	press(plain, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("3"), Alt: true}, runes("t"))
+	if plain.showTree {
+		t.Error("TreeReporter でなければツリーに切り替えないはず")
+	}
+}
+
+func TestPlanPane(t *testing.T) {
+	m := NewModel(sampleBackend(), "")
+	press(m, tea.KeyMsg{Type: tea.KeyTab})
+	view := m.View()
+	for _, want := range []string{"fix x", "✓ read main.go", "✓ edit main.go", "☑ update x", "☐ add test"} {
+		if !strings.Contains(view, want) {
+			t.Errorf("計画ペインに %q が含まれるはず:\n%s", want, view)
+		}
+	}
+}
+
+func TestJobsPaneKill(t *testing.T) {
+	backend := sampleBackend()
+	backend.jobs = []jobs.Info{
+		{ID: 1, Command: "npm run dev", Status: jobs.StatusRunning, StartedAt: time.Now()},
+		{ID: 2, Command: "go test ./...", Status: jobs.StatusExited, StartedAt: time.Now()},
+	}
+	m := NewModel(backend, "")
+
+	press(m, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("4"), Alt: true})
+	if view := m.View(); !strings.Contains(view, "npm run dev") || !strings.Contains(view, "line 2") {
+		t.Errorf("ジョブの一覧と選択中のジョブの出力を表示するはず:\n%s", view)
+	}
+	press(m, runes("x"))
+	press(m, runes("j"), runes("x"))
+	if len(backend.killed) != 1 || backend.killed[0] != 1 {
+		t.Errorf("実行中のジョブだけを停止するはず: %v", backend.killed)
+	}
+}
+
+func TestParseTodos(t *testing.T) {
+	numbered := parseTodos("Plan:\n1. read the file\n2) edit it\n```\n3. not a step\n```")
+	if len(numbered) != 2 || numbered[1].text != "edit it" {
+		t.Errorf("コードブロック外の番号付きリストを取り出すはず: %+v", numbered)
+	}
+
+	// チェックボックスがあればそちらを優先
+	checkboxes := parseTodos("1. intro\n- [ ] first\n* [X] second")
+	if len(checkboxes) != 2 || checkboxes[0].done || !checkboxes[1].done {
+		t.Errorf("チェックボックスを取り出すはず: %+v", checkboxes)
+	}
+}
+
+func TestSplitLines(t *testing.T) {
+	var lines []string
+	splitLines(strings.NewReader("\n⠋ Generating\r\033[2K⠙ Generating\r\033[2K\033[32m✓ done\033[0m\n\nplain\npartial"), func(line string) {
+		lines = append(lines, line)
+	})
+	want := []string{"✓ done", "plain", "partial"}
+	if strings.Join(lines, "|") != strings.Join(want, "|") {
+		t.Errorf("上書きされた進捗表示・空行・エスケープシーケンスを除くはず: %q", lines)
+	}
+}
+
+func TestOutputFolding(t *testing.T) {
+	m := NewModel(sampleBackend(), "vyb")
+	m.fold = streaming.FoldConfig{Threshold: 10, Keep: 2, PageLines: 20}
+	for i := 1; i <= 30; i++ {
+		m.Update(outputMsg(fmt.Sprintf("line %d", i)))
+	}
+	if len(m.notes) != 1 || len(m.notes[0].lines) != 30 {
+		t.Fatalf("captured lines should form one section: %+v", m.notes)
+	}
+
+	folded := m.outputLines(m.notes[0])
+	if len(folded) != 5 || folded[0].text != "line 1" || folded[4].text != "line 30" {
+		t.Fatalf("folded view should keep head and tail: %+v", folded)
+	}
+
+	// ctrl+o で1ページずつ展開し、最後のページの次で折り畳みに戻る
+	m.toggleFold()
+	page := m.outputLines(m.notes[0])
+	if page[1].text != "line 1" || page[len(page)-1].text != "line 20" {
+		t.Fatalf("first page: %+v", page)
+	}
+	m.toggleFold()
+	page = m.outputLines(m.notes[0])
+	if page[1].text != "line 21" || !strings.Contains(page[len(page)-1].text, "──") {
+		t.Fatalf("last page should end with the collapse hint: %+v", page)
+	}
+	m.toggleFold()
+	if m.notes[0].view != 0 {
+		t.Fatalf("expected folded again, got view %d", m.notes[0].view)
+	}
+
+	// 送信後の出力は新しいセクションになる
+	m.Update(submitDoneMsg{})
+	m.Update(outputMsg("next"))
+	if got := m.notes[len(m.notes)-1].lines; len(got) != 1 || got[0] != "next" {
+		t.Fatalf("output after a turn should start a new section: %v", got)
+	}
+}
@@ -0,0 +1,174 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/glkt/vyb-code/internal/diff"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// touchedFile はセッション中に変更したファイル
+type touchedFile struct {
+	path     string
+	patches  []string // ターン毎の差分（古い順、差分が記録されていなければ空）
+	added    int
+	deleted  int
+	isNew    bool
+	isDelete bool
+}
+
+func (f touchedFile) marker() string {
+	switch {
+	case f.isNew:
+		return "A"
+	case f.isDelete:
+		return "D"
+	}
+	return "M"
+}
+
+func (f touchedFile) style() string {
+	switch {
+	case f.isNew:
+		return styleAdded
+	case f.isDelete:
+		return styleError
+	}
+	return ""
+}
+
+// fileEditTools はファイルを書き換えるツール（差分がない場合も一覧に載せる）
+var fileEditTools = map[string]bool{"write": true, "edit": true, "multiedit": true}
+
+// touchedFiles は会話記録の差分・ツール実行から変更したファイルを集める（最近変更した順）
+func touchedFiles(entries []transcript.Entry) []touchedFile {
+	var files []touchedFile
+	touch := func(path string) *touchedFile {
+		for i, file := range files {
+			if file.path == path {
+				// 最後に変更したものを末尾へ
+				files = append(append(files[:i:i], files[i+1:]...), file)
+				return &files[len(files)-1]
+			}
+		}
+		files = append(files, touchedFile{path: path})
+		return &files[len(files)-1]
+	}
+
+	for _, entry := range entries {
+		switch entry.Kind {
+		case transcript.KindDiff:
+			for _, fileDiff := range diff.ParseFiles(entry.Content) {
+				file := touch(fileDiff.Path)
+				file.patches = append(file.patches, fileDiff.Patch())
+				file.added += fileDiff.Added
+				file.deleted += fileDiff.Deleted
+				file.isNew = file.isNew || fileDiff.IsNew
+				file.isDelete = fileDiff.IsDelete
+			}
+		case transcript.KindTool:
+			if entry.Success && entry.Path != "" && fileEditTools[entry.Tool] {
+				touch(relativePath(entry.Path))
+			}
+		}
+	}
+
+	for i, j := 0, len(files)-1; i < j; i, j = i+1, j-1 {
+		files[i], files[j] = files[j], files[i]
+	}
+	return files
+}
+
+// relativePath はツールに渡された絶対パスを作業ディレクトリからの相対パスにする（差分のパスと揃える）
+func relativePath(path string) string {
+	if !filepath.IsAbs(path) {
+		return filepath.ToSlash(filepath.Clean(path))
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		return path
+	}
+	if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
+
+// diffStats は差分のファイル数と追加・削除行数
+func diffStats(patch string) (files, added, deleted int) {
+	for _, fileDiff := range diff.ParseFiles(patch) {
+		files++
+		added += fileDiff.Added
+		deleted += fileDiff.Deleted
+	}
+	return files, added, deleted
+}
+
+// latestTurn は直近のユーザー入力と、それ以降のツール・コマンド実行、応答
+func latestTurn(entries []transcript.Entry) (request string, steps []transcript.Entry, answer string) {
+	start := -1
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].Kind == transcript.KindUser {
+			start = i
+			break
+		}
+	}
+	if start < 0 {
+		return "", nil, ""
+	}
+	request = entries[start].Content
+	for _, entry := range entries[start+1:] {
+		switch entry.Kind {
+		case transcript.KindTool, transcript.KindCommand:
+			steps = append(steps, entry)
+		case transcript.KindAssistant:
+			answer = entry.Content
+		}
+	}
+	return request, steps, answer
+}
+
+// todoItem は応答中のTODOリスト・手順の1項目
+type todoItem struct {
+	marker string
+	text   string
+	done   bool
+}
+
+var (
+	checkboxPattern = regexp.MustCompile(`^\s*[-*+]\s+\[([ xX])\]\s+(.+)$`)
+	numberedPattern = regexp.MustCompile(`^\s*(\d+)[.)]\s+(.+)$`)
+)
+
+// parseTodos は応答のチェックボックス（- [ ] / - [x]）を、なければ番号付きリストを取り出す
+// コードブロック内は対象外
+func parseTodos(text string) []todoItem {
+	var checkboxes, numbered []todoItem
+	inFence := false
+	for _, raw := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(raw), "```") {
+			inFence = !inFence
+			continue
+		}
+		if inFence {
+			continue
+		}
+		if match := checkboxPattern.FindStringSubmatch(raw); match != nil {
+			done := match[1] != " "
+			marker := "☐"
+			if done {
+				marker = "☑"
+			}
+			checkboxes = append(checkboxes, todoItem{marker: marker, text: match[2], done: done})
+		} else if match := numberedPattern.FindStringSubmatch(raw); match != nil {
+			numbered = append(numbered, todoItem{marker: match[1] + ".", text: match[2]})
+		}
+	}
+	if len(checkboxes) > 0 {
+		return checkboxes
+	}
+	return numbered
+}
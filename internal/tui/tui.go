@@ -0,0 +1,149 @@
+package tui
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/glkt/vyb-code/internal/jobs"
+	"github.com/glkt/vyb-code/internal/streaming"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// Backend はTUIから操作するチャットセッション
+type Backend interface {
+	// Submit は入力（スラッシュコマンド・!command を含む）を1件処理する。ctx のキャンセルでターンを中断
+	Submit(ctx context.Context, input string) error
+	// Transcript は現在のセッションの会話記録
+	Transcript() *transcript.Transcript
+	// Jobs はバックグラウンドジョブの一覧
+	Jobs() []jobs.Info
+	// JobOutput はジョブの出力の末尾
+	JobOutput(id int) string
+	// KillJob はジョブを停止する
+	KillJob(id int) error
+}
+
+// Completer は入力行を補完できる Backend（会話ペインで入力中の Tab で補完）
+type Completer interface {
+	// Complete はカーソル位置の単語を補完した行とカーソル位置を返す。補完が進まなければ候補を返す
+	Complete(line []rune, cursor int) ([]rune, int, []string)
+}
+
+// Copier は直前の応答のコードブロックをクリップボードにコピーできる Backend（Ctrl+Y）
+type Copier interface {
+	// CopyCodeBlock は n 番目（0 は最後）のコードブロックをコピーし、結果の表示を返す
+	CopyCodeBlock(n int) string
+}
+
+// StatusReporter はタブの横に状態（固定中のファイル等）を表示する Backend
+type StatusReporter interface {
+	Status() string
+}
+
+// TitleReporter はヘッダーにセッションの題名を表示する Backend
+type TitleReporter interface {
+	Title() string
+}
+
+// TreeReporter はファイルペインにワークスペースのディレクトリツリーを表示できる Backend（t で切替）
+type TreeReporter interface {
+	// Tree はディレクトリツリーの行（サイズ・更新日時付き）
+	Tree() []string
+}
+
+// Starter は起動直後に1度だけ処理を行う Backend（出力は会話ペインに取り込む）
+type Starter interface {
+	Start()
+}
+
+// Run はペイン構成のTUIを起動し、終了するまで入力を処理する
+// 実行中の標準出力・標準エラー出力は会話ペインに取り込む（画面を崩さないため）。長い出力は fold に従って折り畳む
+func Run(backend Backend, title string, fold streaming.FoldConfig) error {
+	terminal := os.Stdout
+	capture, err := captureOutput()
+	if err != nil {
+		return err
+	}
+
+	model := NewModel(backend, title)
+	model.fold = fold
+	program := tea.NewProgram(model, tea.WithAltScreen(), tea.WithOutput(terminal))
+	go capture.forward(func(line string) { program.Send(outputMsg(line)) })
+	if starter, ok := backend.(Starter); ok {
+		go starter.Start()
+	}
+
+	_, err = program.Run()
+	capture.restore()
+	return err
+}
+
+// outputCapture は os.Stdout・os.Stderr の差し替え
+type outputCapture struct {
+	stdout, stderr *os.File
+	reader, writer *os.File
+	done           chan struct{}
+}
+
+func captureOutput() (*outputCapture, error) {
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	c := &outputCapture{stdout: os.Stdout, stderr: os.Stderr, reader: reader, writer: writer, done: make(chan struct{})}
+	os.Stdout, os.Stderr = writer, writer
+	return c, nil
+}
+
+// forward は取り込んだ出力を1行ずつ渡す
+func (c *outputCapture) forward(send func(line string)) {
+	defer close(c.done)
+	splitLines(c.reader, send)
+}
+
+func (c *outputCapture) restore() {
+	os.Stdout, os.Stderr = c.stdout, c.stderr
+	c.writer.Close()
+	<-c.done
+	c.reader.Close()
+}
+
+// ansiPattern は色・カーソル移動等のエスケープシーケンス
+var ansiPattern = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+// splitLines は出力を行に分けて渡す
+// 進捗表示のような "\r" による上書きは最後の状態だけを残し、空行は渡さない
+func splitLines(r io.Reader, send func(line string)) {
+	var line bytes.Buffer
+	buf := make([]byte, 4096)
+	flush := func() {
+		text := strings.TrimSpace(ansiPattern.ReplaceAllString(line.String(), ""))
+		line.Reset()
+		if text != "" {
+			send(text)
+		}
+	}
+	for {
+		n, err := r.Read(buf)
+		for _, b := range buf[:n] {
+			switch b {
+			case '\n':
+				flush()
+			case '\r':
+				line.Reset()
+			default:
+				line.WriteByte(b)
+			}
+		}
+		if err != nil {
+			flush()
+			return
+		}
+	}
+}
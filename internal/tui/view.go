@@ -0,0 +1,539 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-runewidth"
+
+	"github.com/glkt/vyb-code/internal/i18n"
+	"github.com/glkt/vyb-code/internal/jobs"
+	"github.com/glkt/vyb-code/internal/textutil"
+	"github.com/glkt/vyb-code/internal/transcript"
+)
+
+// 表示色（チャット画面の配色に合わせる）
+const (
+	styleUser      = "1;38;5;34"
+	styleAssistant = "1;38;5;27"
+	styleMuted     = "38;5;244"
+	styleError     = "38;5;196"
+	styleWarn      = "38;5;214"
+	styleAdded     = "38;5;46"
+	styleHunk      = "38;5;27"
+	styleSelected  = "7"
+)
+
+var spinnerFrames = []rune{'⠋', '⠙', '⠹', '⠸', '⠼', '⠴', '⠦', '⠧', '⠇', '⠏'}
+
+// line は表示1行（style は SGR パラメータ、空なら装飾なし）
+type line struct {
+	text  string
+	style string
+}
+
+func (l line) render(width int) string {
+	text := runewidth.Truncate(l.text, width, "…")
+	if l.style == "" {
+		return text
+	}
+	return "\033[" + l.style + "m" + text + "\033[0m"
+}
+
+// renderPadded は幅 width に揃えて描画（2列表示の左列用）
+func (l line) renderPadded(width int) string {
+	text := runewidth.FillRight(runewidth.Truncate(l.text, width, "…"), width)
+	if l.style == "" {
+		return text
+	}
+	return "\033[" + l.style + "m" + text + "\033[0m"
+}
+
+// View は画面全体を描画
+func (m *Model) View() string {
+	var b strings.Builder
+	b.WriteString(m.renderTabs())
+	b.WriteString("\n")
+
+	var body []string
+	switch m.pane {
+	case PaneFiles:
+		body = m.renderFiles()
+	case PaneJobs:
+		body = m.renderJobs()
+	default:
+		body = m.renderWindow(m.pane, m.bodyHeight())
+	}
+	for i := 0; i < m.bodyHeight(); i++ {
+		if i < len(body) {
+			b.WriteString(body[i])
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(m.renderInput())
+	b.WriteString("\n")
+	b.WriteString(line{text: m.helpText(), style: styleMuted}.render(m.width))
+	return b.String()
+}
+
+// bodyHeight はタブ・入力行・ヘルプを除いた表示行数
+func (m *Model) bodyHeight() int {
+	if height := m.height - 3; height > 1 {
+		return height
+	}
+	return 1
+}
+
+func (m *Model) renderTabs() string {
+	counts := map[Pane]string{}
+	if len(m.files) > 0 {
+		counts[PaneFiles] = fmt.Sprintf(" (%d)", len(m.files))
+	}
+	running := 0
+	for _, job := range m.jobs {
+		if job.Status == jobs.StatusRunning {
+			running++
+		}
+	}
+	if running > 0 {
+		counts[PaneJobs] = fmt.Sprintf(" (%d)", running)
+	}
+
+	var b strings.Builder
+	if m.title != "" {
+		b.WriteString(line{text: m.title + " ", style: styleMuted}.render(m.width))
+	}
+	if m.session != "" {
+		b.WriteString(line{text: "· " + m.session + " ", style: styleMuted}.render(m.width))
+	}
+	for pane := Pane(0); pane < paneCount; pane++ {
+		label := fmt.Sprintf(" %d %s%s ", pane+1, i18n.T(paneTitleKeys[pane]), counts[pane])
+		if pane == m.pane {
+			b.WriteString(line{text: label, style: styleSelected}.render(m.width))
+		} else {
+			b.WriteString(line{text: label, style: styleMuted}.render(m.width))
+		}
+	}
+	if m.status != "" {
+		b.WriteString(line{text: "  " + m.status, style: styleMuted}.render(m.width))
+	}
+	if m.busy {
+		elapsed := time.Since(m.busySince)
+		frame := spinnerFrames[int(elapsed/(100*time.Millisecond))%len(spinnerFrames)]
+		b.WriteString(line{text: fmt.Sprintf("  %c %s %s", frame, i18n.T("tui.working"), elapsed.Round(time.Second)), style: styleWarn}.render(m.width))
+	}
+	return b.String()
+}
+
+var paneTitleKeys = [paneCount]string{"tui.pane_conversation", "tui.pane_plan", "tui.pane_files", "tui.pane_jobs"}
+
+var paneHelpKeys = [paneCount]string{"tui.help_conversation", "tui.help_plan", "tui.help_files", "tui.help_jobs"}
+
+func (m *Model) helpText() string {
+	if m.pane == PaneFiles && m.showTree {
+		return i18n.T("tui.help_tree")
+	}
+	return i18n.T(paneHelpKeys[m.pane])
+}
+
+// renderInput は入力行（カーソル位置を反転表示、入りきらなければ末尾側を表示）
+func (m *Model) renderInput() string {
+	prompt := "› "
+	if m.pane != PaneConversation {
+		return line{text: prompt + string(m.input), style: styleMuted}.render(m.width)
+	}
+
+	before, after := string(m.input[:m.cursor]), " "
+	if m.cursor < len(m.input) {
+		after = string(m.input[m.cursor])
+	}
+	rest := ""
+	if m.cursor+1 < len(m.input) {
+		rest = string(m.input[m.cursor+1:])
+	}
+	available := m.width - runewidth.StringWidth(prompt) - runewidth.StringWidth(after)
+	for available > 0 && runewidth.StringWidth(before) > available {
+		_, size := firstRune(before)
+		before = before[size:]
+	}
+	return prompt + before + "\033[7m" + after + "\033[0m" + rest
+}
+
+func firstRune(s string) (rune, int) {
+	for i, r := range s {
+		if i > 0 {
+			return r, i
+		}
+	}
+	return 0, len(s)
+}
+
+// renderWindow は行の一部をスクロール位置に合わせて描画
+func (m *Model) renderWindow(pane Pane, height int) []string {
+	lines := m.paneLines(pane)
+	start := m.offset[pane]
+	if pane == PaneConversation {
+		start = len(lines) - height - m.offset[pane]
+	}
+	start = clamp(start, 0, len(lines)-height)
+	end := start + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+
+	rendered := make([]string, 0, height)
+	for _, l := range lines[start:end] {
+		rendered = append(rendered, l.render(m.width))
+	}
+	return rendered
+}
+
+// paneLines はペインのスクロール対象の行（ファイル・ジョブは選択中の項目の内容）
+func (m *Model) paneLines(pane Pane) []line {
+	switch pane {
+	case PaneConversation:
+		return m.conversationLines()
+	case PanePlan:
+		return m.planLines()
+	case PaneFiles:
+		if m.showTree {
+			lines := make([]line, 0, len(m.tree))
+			for _, text := range m.tree {
+				lines = append(lines, line{text: text})
+			}
+			return lines
+		}
+		if len(m.files) == 0 {
+			return nil
+		}
+		return diffLines(m.files[m.selected[PaneFiles]])
+	case PaneJobs:
+		if m.jobOutput == "" {
+			return nil
+		}
+		var lines []line
+		for _, text := range strings.Split(strings.TrimRight(m.jobOutput, "\n"), "\n") {
+			lines = append(lines, line{text: expandTabs(text)})
+		}
+		return lines
+	}
+	return nil
+}
+
+// conversationLines は会話記録とコマンドの出力を時刻順に並べた行
+func (m *Model) conversationLines() []line {
+	width := m.width
+	var lines []line
+	add := func(text, style string) {
+		for _, wrapped := range wrap(text, width) {
+			lines = append(lines, line{text: wrapped, style: style})
+		}
+	}
+	addNote := func(n note) {
+		switch n.kind {
+		case noteInput:
+			add("› "+n.text, styleUser)
+		case noteError:
+			add("✗ "+n.text, styleError)
+		default:
+			for _, l := range m.outputLines(n) {
+				add(l.text, l.style)
+			}
+		}
+	}
+
+	notes := m.notes
+	for _, entry := range m.entries {
+		for len(notes) > 0 && notes[0].time.Before(entry.Timestamp) {
+			addNote(notes[0])
+			notes = notes[1:]
+		}
+		switch entry.Kind {
+		case transcript.KindUser:
+			lines = append(lines, line{})
+			add(i18n.T("chat.you"), styleUser)
+			add(entry.Content, "")
+		case transcript.KindAssistant:
+			lines = append(lines, line{})
+			add("🤖 Assistant", styleAssistant)
+			add(entry.Content, "")
+		case transcript.KindTool, transcript.KindCommand:
+			add("  "+stepLabel(entry), stepStyle(entry))
+		case transcript.KindDiff:
+			files, added, deleted := diffStats(entry.Content)
+			add(fmt.Sprintf("  ✎ %s", i18n.T("tui.diff_summary", files, added, deleted)), styleMuted)
+		}
+	}
+	for _, n := range notes {
+		addNote(n)
+	}
+
+	// 処理中の入力は会話記録に載るまでここに表示
+	if m.busy && m.pending != "" && !m.hasUserEntrySince(m.busySince) {
+		lines = append(lines, line{})
+		add(i18n.T("chat.you"), styleUser)
+		add(m.pending, "")
+	}
+	return lines
+}
+
+// outputLines は出力の表示行（長い出力は先頭・末尾だけ残して折り畳み、展開時はページ単位）
+func (m *Model) outputLines(n note) []line {
+	var lines []line
+	if n.dropped > 0 {
+		lines = append(lines, line{text: i18n.T("tui.fold_dropped", n.dropped), style: styleWarn})
+	}
+	if !m.fold.Folds(len(n.lines)) {
+		for _, text := range n.lines {
+			lines = append(lines, line{text: text, style: styleMuted})
+		}
+		return lines
+	}
+
+	if n.view == 0 {
+		head, hidden, tail := m.fold.Fold(n.lines)
+		for _, text := range head {
+			lines = append(lines, line{text: text, style: styleMuted})
+		}
+		lines = append(lines, line{text: "  ⋯ " + i18n.T("tui.fold_hidden", hidden, len(n.lines)), style: styleWarn})
+		for _, text := range tail {
+			lines = append(lines, line{text: text, style: styleMuted})
+		}
+		return lines
+	}
+
+	pages := m.fold.Pages(len(n.lines))
+	start, end := m.fold.Page(len(n.lines), n.view-1)
+	if pages > 1 {
+		lines = append(lines, line{text: "  ── " + i18n.T("tui.fold_page", n.view, pages, start+1, end, len(n.lines)), style: styleWarn})
+	}
+	for _, text := range n.lines[start:end] {
+		lines = append(lines, line{text: text, style: styleMuted})
+	}
+	if n.view == pages {
+		lines = append(lines, line{text: "  ── " + i18n.T("tui.fold_collapse"), style: styleWarn})
+	}
+	return lines
+}
+
+func (m *Model) hasUserEntrySince(since time.Time) bool {
+	for i := len(m.entries) - 1; i >= 0; i-- {
+		entry := m.entries[i]
+		if entry.Kind == transcript.KindUser {
+			return !entry.Timestamp.Before(since)
+		}
+	}
+	return false
+}
+
+// stepLabel はツール・コマンド実行の1行表示
+func stepLabel(entry transcript.Entry) string {
+	mark := "✓"
+	if !entry.Success {
+		mark = "✗"
+	}
+	name := entry.Tool
+	if entry.Kind == transcript.KindCommand && name == "" {
+		name = "$"
+	}
+	target := entry.Path
+	if target == "" {
+		target = entry.Command
+	}
+	label := strings.TrimSpace(fmt.Sprintf("%s %s %s", mark, name, textutil.FirstLineMore(target, " …")))
+	if entry.Error != "" {
+		label += " — " + textutil.FirstLineMore(entry.Error, " …")
+	}
+	return label
+}
+
+func stepStyle(entry transcript.Entry) string {
+	if entry.Success {
+		return styleMuted
+	}
+	return styleError
+}
+
+// planLines は直近のターンの実行ステップと、応答中のTODOリスト
+func (m *Model) planLines() []line {
+	request, steps, answer := latestTurn(m.entries)
+	if m.busy && m.pending != "" && !m.hasUserEntrySince(m.busySince) {
+		request, steps, answer = m.pending, nil, ""
+	}
+
+	var lines []line
+	add := func(text, style string) {
+		for _, wrapped := range wrap(text, m.width) {
+			lines = append(lines, line{text: wrapped, style: style})
+		}
+	}
+	if request == "" && len(steps) == 0 {
+		add(i18n.T("tui.plan_empty"), styleMuted)
+		return lines
+	}
+
+	add(i18n.T("tui.plan_request"), styleUser)
+	add("  "+textutil.FirstLineMore(request, " …"), "")
+	lines = append(lines, line{})
+
+	add(i18n.T("tui.plan_steps"), styleAssistant)
+	for _, step := range steps {
+		add("  "+stepLabel(step), stepStyle(step))
+	}
+	if m.busy {
+		add("  … "+i18n.T("tui.working"), styleWarn)
+	} else if len(steps) == 0 {
+		add("  "+i18n.T("tui.plan_no_steps"), styleMuted)
+	}
+
+	if todos := parseTodos(answer); len(todos) > 0 {
+		lines = append(lines, line{})
+		add(i18n.T("tui.plan_todos"), styleAssistant)
+		for _, item := range todos {
+			style := ""
+			if item.done {
+				style = styleMuted
+			}
+			add("  "+item.marker+" "+item.text, style)
+		}
+	}
+	return lines
+}
+
+// renderFiles は変更したファイルの一覧（左）と選択中のファイルの差分（右）、またはディレクトリツリー
+func (m *Model) renderFiles() []string {
+	height := m.bodyHeight()
+	if m.showTree {
+		return m.renderWindow(PaneFiles, height)
+	}
+	if len(m.files) == 0 {
+		return []string{line{text: i18n.T("tui.files_empty"), style: styleMuted}.render(m.width)}
+	}
+
+	listWidth := clamp(m.width/3, 16, 48)
+	previewWidth := m.width - listWidth - 3
+	preview := m.renderWindowWidth(PaneFiles, height, previewWidth)
+
+	// 選択中のファイルが見えるように一覧をずらす
+	first := clamp(m.selected[PaneFiles]-height+1, 0, len(m.files))
+	rendered := make([]string, height)
+	for i := 0; i < height; i++ {
+		left := line{}
+		if index := first + i; index < len(m.files) {
+			file := m.files[index]
+			left = line{text: fmt.Sprintf("%s %s", file.marker(), file.path), style: file.style()}
+			if index == m.selected[PaneFiles] {
+				left.style = styleSelected
+			}
+		}
+		rendered[i] = left.renderPadded(listWidth) + " " + line{text: "│", style: styleMuted}.render(1) + " "
+		if i < len(preview) {
+			rendered[i] += preview[i]
+		}
+	}
+	return rendered
+}
+
+// renderWindowWidth は幅を指定して renderWindow と同様に描画
+func (m *Model) renderWindowWidth(pane Pane, height, width int) []string {
+	saved := m.width
+	m.width = width
+	defer func() { m.width = saved }()
+	return m.renderWindow(pane, height)
+}
+
+// renderJobs はジョブの一覧（上）と選択中のジョブの出力（下）
+func (m *Model) renderJobs() []string {
+	if len(m.jobs) == 0 {
+		return []string{line{text: i18n.T("jobs.empty"), style: styleMuted}.render(m.width)}
+	}
+
+	listHeight := m.jobListHeight()
+	first := clamp(m.selected[PaneJobs]-listHeight+1, 0, len(m.jobs))
+	var rendered []string
+	for i := 0; i < listHeight && first+i < len(m.jobs); i++ {
+		index := first + i
+		info := m.jobs[index]
+		elapsed := time.Since(info.StartedAt)
+		if !info.EndedAt.IsZero() {
+			elapsed = info.EndedAt.Sub(info.StartedAt)
+		}
+		status := i18n.T("jobs.status_" + string(info.Status))
+		if info.Status == jobs.StatusExited {
+			status = i18n.T("jobs.status_exited", info.ExitCode)
+		}
+		l := line{text: fmt.Sprintf("[%d] %-16s %8s  %s", info.ID, status, elapsed.Round(time.Second), info.Command)}
+		switch {
+		case index == m.selected[PaneJobs]:
+			l.style = styleSelected
+		case info.Status == jobs.StatusRunning:
+			l.style = styleWarn
+		case info.Status == jobs.StatusExited && info.ExitCode == 0:
+			l.style = styleMuted
+		default:
+			l.style = styleError
+		}
+		rendered = append(rendered, l.render(m.width))
+	}
+	rendered = append(rendered, line{text: strings.Repeat("─", m.width), style: styleMuted}.render(m.width))
+
+	output := m.renderWindow(PaneJobs, m.windowHeight(PaneJobs))
+	if len(output) == 0 {
+		output = []string{line{text: i18n.T("tui.jobs_no_output"), style: styleMuted}.render(m.width)}
+	}
+	return append(rendered, output...)
+}
+
+func (m *Model) jobListHeight() int {
+	return clamp(len(m.jobs), 1, m.bodyHeight()/3)
+}
+
+// diffLines は差分を色付けした行
+func diffLines(file touchedFile) []line {
+	if len(file.patches) == 0 {
+		return []line{{text: i18n.T("tui.files_no_diff"), style: styleMuted}}
+	}
+	lines := []line{{text: fmt.Sprintf("%s  +%d -%d", file.path, file.added, file.deleted), style: styleAssistant}}
+	for _, patch := range file.patches {
+		for _, text := range strings.Split(strings.TrimRight(patch, "\n"), "\n") {
+			l := line{text: expandTabs(text)}
+			switch {
+			case strings.HasPrefix(text, "@@"):
+				l.style = styleHunk
+			case strings.HasPrefix(text, "+"):
+				l.style = styleAdded
+			case strings.HasPrefix(text, "-"):
+				l.style = styleError
+			}
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// wrap はテキストを表示幅で折り返す
+func wrap(text string, width int) []string {
+	if width < 1 {
+		width = 1
+	}
+	var lines []string
+	for _, raw := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		raw = expandTabs(raw)
+		for runewidth.StringWidth(raw) > width {
+			head := runewidth.Truncate(raw, width, "")
+			if head == "" {
+				break
+			}
+			lines = append(lines, head)
+			raw = raw[len(head):]
+		}
+		lines = append(lines, raw)
+	}
+	return lines
+}
+
+func expandTabs(text string) string {
+	return strings.ReplaceAll(text, "\t", "    ")
+}
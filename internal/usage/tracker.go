@@ -0,0 +1,280 @@
+package usage
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+// Record は1回のLLMリクエストのトークン使用量
+type Record struct {
+	Timestamp        time.Time `json:"timestamp"`
+	SessionID        string    `json:"session_id,omitempty"`
+	Model            string    `json:"model"`
+	PromptTokens     int       `json:"prompt_tokens"`
+	CompletionTokens int       `json:"completion_tokens"`
+	Estimated        bool      `json:"estimated,omitempty"` // プロバイダーが件数を返さず文字数から推定した場合
+	Cost             float64   `json:"cost"`
+}
+
+// TotalTokens はプロンプトと生成の合計トークン数
+func (r Record) TotalTokens() int {
+	return r.PromptTokens + r.CompletionTokens
+}
+
+// GroupBy は集計単位
+type GroupBy string
+
+const (
+	BySession GroupBy = "session"
+	ByDay     GroupBy = "day"
+	ByModel   GroupBy = "model"
+)
+
+// ValidGroupBy は指定可能な集計単位の一覧
+func ValidGroupBy() []GroupBy {
+	return []GroupBy{BySession, ByDay, ByModel}
+}
+
+// Summary は集計単位毎の使用量
+type Summary struct {
+	Key              string  `json:"key"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	Estimated        int     `json:"estimated"` // 推定値を含むリクエスト数
+	Cost             float64 `json:"cost"`
+}
+
+// TotalTokens はプロンプトと生成の合計トークン数
+func (s Summary) TotalTokens() int {
+	return s.PromptTokens + s.CompletionTokens
+}
+
+func (s *Summary) add(record Record) {
+	s.Requests++
+	s.PromptTokens += record.PromptTokens
+	s.CompletionTokens += record.CompletionTokens
+	s.Cost += record.Cost
+	if record.Estimated {
+		s.Estimated++
+	}
+}
+
+// Tracker はトークン使用量をJSONLファイルに記録する
+type Tracker struct {
+	mu      sync.Mutex
+	path    string
+	pricing map[string]config.ModelPrice
+}
+
+// NewTracker は保存先と単価設定を指定してトラッカーを作成
+func NewTracker(path string, cfg config.UsageConfig) *Tracker {
+	return &Tracker{path: path, pricing: cfg.Pricing}
+}
+
+// DefaultPath は使用量ログの保存先（~/.vyb/usage.jsonl）
+func DefaultPath() string {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), "vyb-usage.jsonl")
+	}
+	return filepath.Join(homeDir, ".vyb", "usage.jsonl")
+}
+
+// Path は保存先ファイルパスを返す
+func (t *Tracker) Path() string {
+	return t.path
+}
+
+// Price はモデルの単価を解決（完全一致 → タグを除いた名前 → 最長プレフィックス → "*"）
+func (t *Tracker) Price(model string) (config.ModelPrice, bool) {
+	if price, ok := t.pricing[model]; ok {
+		return price, true
+	}
+	if base, _, found := strings.Cut(model, ":"); found {
+		if price, ok := t.pricing[base]; ok {
+			return price, true
+		}
+	}
+
+	bestLen := 0
+	var best config.ModelPrice
+	for name, price := range t.pricing {
+		if name != "*" && strings.HasPrefix(model, name) && len(name) > bestLen {
+			best, bestLen = price, len(name)
+		}
+	}
+	if bestLen > 0 {
+		return best, true
+	}
+
+	price, ok := t.pricing["*"]
+	return price, ok
+}
+
+// Cost はトークン数から料金を計算（単価未設定なら0）
+func (t *Tracker) Cost(model string, promptTokens, completionTokens int) float64 {
+	price, ok := t.Price(model)
+	if !ok {
+		return 0
+	}
+	return float64(promptTokens)/1000*price.PromptPer1K + float64(completionTokens)/1000*price.CompletionPer1K
+}
+
+// Record は料金を計算して使用量を追記
+func (t *Tracker) Record(record Record) (Record, error) {
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	record.Cost = t.Cost(record.Model, record.PromptTokens, record.CompletionTokens)
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return record, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(t.path), 0700); err != nil {
+		return record, fmt.Errorf("使用量ログディレクトリ作成失敗: %w", err)
+	}
+	file, err := os.OpenFile(t.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600)
+	if err != nil {
+		return record, fmt.Errorf("使用量ログファイル開封失敗: %w", err)
+	}
+	defer file.Close()
+
+	_, err = file.Write(append(data, '\n'))
+	return record, err
+}
+
+// Load は指定時刻以降の使用量を読み込み（sinceがゼロ値なら全件）
+func Load(path string, since time.Time) ([]Record, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("使用量ログ読み込みエラー: %w", err)
+	}
+	defer file.Close()
+
+	var records []Record
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		var record Record
+		if err := json.Unmarshal([]byte(line), &record); err != nil {
+			continue // 書き込み途中の行は無視
+		}
+		if !since.IsZero() && record.Timestamp.Before(since) {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records, scanner.Err()
+}
+
+// FilterSession は指定セッションの使用量のみを返す
+func FilterSession(records []Record, sessionID string) []Record {
+	var filtered []Record
+	for _, record := range records {
+		if record.SessionID == sessionID {
+			filtered = append(filtered, record)
+		}
+	}
+	return filtered
+}
+
+// Aggregate は集計単位毎に使用量をまとめる（日別は新しい順、それ以外は料金・トークンの多い順）
+func Aggregate(records []Record, by GroupBy) []Summary {
+	summaries := make(map[string]*Summary)
+	for _, record := range records {
+		key := groupKey(record, by)
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &Summary{Key: key}
+			summaries[key] = summary
+		}
+		summary.add(record)
+	}
+
+	result := make([]Summary, 0, len(summaries))
+	for _, summary := range summaries {
+		result = append(result, *summary)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if by == ByDay {
+			return result[i].Key > result[j].Key
+		}
+		if result[i].Cost != result[j].Cost {
+			return result[i].Cost > result[j].Cost
+		}
+		if result[i].TotalTokens() != result[j].TotalTokens() {
+			return result[i].TotalTokens() > result[j].TotalTokens()
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result
+}
+
+// Total は全件の合計
+func Total(records []Record) Summary {
+	total := Summary{Key: "total"}
+	for _, record := range records {
+		total.add(record)
+	}
+	return total
+}
+
+func groupKey(record Record, by GroupBy) string {
+	switch by {
+	case BySession:
+		if record.SessionID == "" {
+			return "(none)"
+		}
+		return record.SessionID
+	case ByModel:
+		return record.Model
+	default:
+		return record.Timestamp.Local().Format("2006-01-02")
+	}
+}
+
+// FormatCost は通貨付きで料金を表示（小額でも桁が潰れないよう4桁まで表示）
+func FormatCost(cost float64, currency string) string {
+	if currency == "" {
+		currency = "USD"
+	}
+	return fmt.Sprintf("%.4f %s", cost, currency)
+}
+
+// EstimateTokens はプロバイダーが件数を返さない場合の概算（英語で約4文字/トークン、日本語等は1文字/トークン）
+func EstimateTokens(text string) int {
+	ascii, other := 0, 0
+	for _, r := range text {
+		if r < 128 {
+			ascii++
+		} else {
+			other++
+		}
+	}
+	tokens := (ascii+3)/4 + other
+	if tokens == 0 && text != "" {
+		tokens = 1
+	}
+	return tokens
+}
@@ -0,0 +1,124 @@
+package usage
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/config"
+)
+
+func testConfig() config.UsageConfig {
+	cfg := config.DefaultUsageConfig()
+	cfg.Pricing = map[string]config.ModelPrice{
+		"gpt-4o":      {PromptPer1K: 0.005, CompletionPer1K: 0.015},
+		"gpt-4o-mini": {PromptPer1K: 0.0002, CompletionPer1K: 0.0006},
+		"qwen2.5":     {PromptPer1K: 0.001, CompletionPer1K: 0.002},
+	}
+	return cfg
+}
+
+func TestPriceResolution(t *testing.T) {
+	tracker := NewTracker(filepath.Join(t.TempDir(), "usage.jsonl"), testConfig())
+
+	tests := []struct {
+		model string
+		want  float64
+		found bool
+	}{
+		{"gpt-4o", 0.005, true},
+		{"gpt-4o-mini-2024", 0.0002, true}, // 最長プレフィックス
+		{"qwen2.5:14b", 0.001, true},       // タグを除いた名前
+		{"llama3", 0, false},
+	}
+	for _, tt := range tests {
+		price, ok := tracker.Price(tt.model)
+		if ok != tt.found || price.PromptPer1K != tt.want {
+			t.Errorf("Price(%q) = %v, %t; want %v, %t", tt.model, price.PromptPer1K, ok, tt.want, tt.found)
+		}
+	}
+
+	cost := tracker.Cost("gpt-4o", 2000, 1000)
+	if math.Abs(cost-0.025) > 1e-9 {
+		t.Errorf("unexpected cost: %f", cost)
+	}
+	if tracker.Cost("llama3", 1000, 1000) != 0 {
+		t.Error("unpriced model should be free")
+	}
+}
+
+func TestRecordLoadAndAggregate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "usage.jsonl")
+	tracker := NewTracker(path, testConfig())
+
+	yesterday := time.Now().AddDate(0, 0, -1)
+	records := []Record{
+		{Timestamp: yesterday, SessionID: "a", Model: "gpt-4o", PromptTokens: 1000, CompletionTokens: 1000},
+		{SessionID: "a", Model: "gpt-4o-mini", PromptTokens: 500, CompletionTokens: 100},
+		{SessionID: "b", Model: "llama3", PromptTokens: 10, CompletionTokens: 20, Estimated: true},
+	}
+	for _, record := range records {
+		if _, err := tracker.Record(record); err != nil {
+			t.Fatalf("Record failed: %v", err)
+		}
+	}
+
+	loaded, err := Load(path, time.Time{})
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(loaded) != 3 {
+		t.Fatalf("expected 3 records, got %d", len(loaded))
+	}
+	if math.Abs(loaded[0].Cost-0.02) > 1e-9 {
+		t.Errorf("cost should be computed on record, got %f", loaded[0].Cost)
+	}
+
+	byModel := Aggregate(loaded, ByModel)
+	if len(byModel) != 3 || byModel[0].Key != "gpt-4o" {
+		t.Errorf("models should be ordered by cost: %+v", byModel)
+	}
+
+	bySession := Aggregate(loaded, BySession)
+	if len(bySession) != 2 || bySession[0].Key != "a" || bySession[0].Requests != 2 {
+		t.Errorf("unexpected session summary: %+v", bySession)
+	}
+
+	byDay := Aggregate(loaded, ByDay)
+	if len(byDay) != 2 || byDay[0].Key <= byDay[1].Key {
+		t.Errorf("days should be newest first: %+v", byDay)
+	}
+
+	total := Total(FilterSession(loaded, "b"))
+	if total.Requests != 1 || total.Estimated != 1 || total.TotalTokens() != 30 {
+		t.Errorf("unexpected total: %+v", total)
+	}
+
+	recent, err := Load(path, time.Now().Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if len(recent) != 2 {
+		t.Errorf("expected 2 recent records, got %d", len(recent))
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	records, err := Load(filepath.Join(t.TempDir(), "none.jsonl"), time.Time{})
+	if err != nil || len(records) != 0 {
+		t.Errorf("missing file should yield no records, got %v, %v", records, err)
+	}
+}
+
+func TestEstimateTokens(t *testing.T) {
+	if got := EstimateTokens(""); got != 0 {
+		t.Errorf("empty text: %d", got)
+	}
+	if got := EstimateTokens("abcdefgh"); got != 2 {
+		t.Errorf("ascii text: %d", got)
+	}
+	if got := EstimateTokens("日本語"); got != 3 {
+		t.Errorf("japanese text: %d", got)
+	}
+}
@@ -0,0 +1,243 @@
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/glkt/vyb-code/internal/config"
+	"github.com/glkt/vyb-code/internal/textutil"
+)
+
+// 1つのループの既定の最大反復回数
+const defaultMaxIterations = 10
+
+// ステップの種類
+const (
+	StepPrompt    = "prompt"
+	StepTool      = "tool"
+	StepCondition = "condition"
+	StepLoop      = "loop"
+)
+
+// Definition は .vyb/workflows/<name>.yaml のワークフロー定義
+type Definition struct {
+	Name        string            `yaml:"name" json:"name"`
+	Description string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Inputs      map[string]string `yaml:"inputs,omitempty" json:"inputs,omitempty"` // 入力名と既定値（空なら必須）
+	Steps       []Step            `yaml:"steps" json:"steps"`
+
+	Path string `yaml:"-" json:"path"`
+}
+
+// Step はワークフローの1ステップ（prompt・tool・condition・loop のいずれか1つを指定）
+// 文字列の値は Go テンプレートとして展開される（{{.inputs.x}}、{{.steps.<id>.output}}、{{.item}}）
+type Step struct {
+	ID   string `yaml:"id,omitempty" json:"id,omitempty"` // 後続ステップから結果を参照する名前
+	Name string `yaml:"name,omitempty" json:"name,omitempty"`
+
+	// prompt: モデルに問い合わせる（同じ実行内の以前のやり取りは引き継ぐ）
+	Prompt string `yaml:"prompt,omitempty" json:"prompt,omitempty"`
+
+	// tool: ツールを実行する（with はツールのパラメーター）
+	Tool string                 `yaml:"tool,omitempty" json:"tool,omitempty"`
+	With map[string]interface{} `yaml:"with,omitempty" json:"with,omitempty"`
+
+	// condition: 条件が真なら then、偽なら else を実行する
+	Condition string `yaml:"condition,omitempty" json:"condition,omitempty"`
+	Then      []Step `yaml:"then,omitempty" json:"then,omitempty"`
+	Else      []Step `yaml:"else,omitempty" json:"else,omitempty"`
+
+	// loop: items の各要素、または until が真になるまで steps を繰り返す
+	Loop  *Loop  `yaml:"loop,omitempty" json:"loop,omitempty"`
+	Steps []Step `yaml:"steps,omitempty" json:"steps,omitempty"`
+
+	// If が偽ならステップを飛ばす（全種類共通）
+	If              string `yaml:"if,omitempty" json:"if,omitempty"`
+	ContinueOnError bool   `yaml:"continue_on_error,omitempty" json:"continue_on_error,omitempty"`
+}
+
+// Loop はループステップの繰り返し条件
+type Loop struct {
+	Items []string `yaml:"items,omitempty" json:"items,omitempty"` // 要素（1要素が複数行に展開されれば各行）
+	Until string   `yaml:"until,omitempty" json:"until,omitempty"` // 各反復の後に評価する終了条件
+	Max   int      `yaml:"max,omitempty" json:"max,omitempty"`
+}
+
+// Kind はステップの種類を返す
+func (s *Step) Kind() string {
+	switch {
+	case s.Prompt != "":
+		return StepPrompt
+	case s.Tool != "":
+		return StepTool
+	case s.Condition != "":
+		return StepCondition
+	case s.Loop != nil:
+		return StepLoop
+	}
+	return ""
+}
+
+// Label は進捗表示用のステップ名
+func (s *Step) Label() string {
+	if s.Name != "" {
+		return s.Name
+	}
+	if s.ID != "" {
+		return s.ID
+	}
+	switch s.Kind() {
+	case StepPrompt:
+		return "prompt: " + truncate(textutil.FirstLine(s.Prompt), 60)
+	case StepTool:
+		return "tool: " + s.Tool
+	case StepCondition:
+		return "if " + s.Condition
+	}
+	return s.Kind()
+}
+
+// Validate は定義の構造を検証する
+func (d *Definition) Validate() error {
+	if len(d.Steps) == 0 {
+		return fmt.Errorf("ワークフロー %s にステップがありません", d.Name)
+	}
+	ids := make(map[string]bool)
+	return validateSteps(d.Steps, "steps", ids)
+}
+
+func validateSteps(steps []Step, path string, ids map[string]bool) error {
+	for i := range steps {
+		step := &steps[i]
+		where := fmt.Sprintf("%s[%d]", path, i)
+
+		kinds := 0
+		for _, set := range []bool{step.Prompt != "", step.Tool != "", step.Condition != "", step.Loop != nil} {
+			if set {
+				kinds++
+			}
+		}
+		if kinds != 1 {
+			return fmt.Errorf("%s: prompt・tool・condition・loop のいずれか1つを指定してください", where)
+		}
+		if step.ID != "" {
+			if ids[step.ID] {
+				return fmt.Errorf("%s: ステップID %q が重複しています", where, step.ID)
+			}
+			ids[step.ID] = true
+		}
+
+		switch step.Kind() {
+		case StepCondition:
+			if len(step.Then) == 0 && len(step.Else) == 0 {
+				return fmt.Errorf("%s: condition には then か else が必要です", where)
+			}
+			if err := validateSteps(step.Then, where+".then", ids); err != nil {
+				return err
+			}
+			if err := validateSteps(step.Else, where+".else", ids); err != nil {
+				return err
+			}
+		case StepLoop:
+			if len(step.Loop.Items) == 0 && step.Loop.Until == "" {
+				return fmt.Errorf("%s: loop には items か until が必要です", where)
+			}
+			if len(step.Steps) == 0 {
+				return fmt.Errorf("%s: loop に steps がありません", where)
+			}
+			if err := validateSteps(step.Steps, where+".steps", ids); err != nil {
+				return err
+			}
+		default:
+			if len(step.Then) > 0 || len(step.Else) > 0 || len(step.Steps) > 0 {
+				return fmt.Errorf("%s: then・else・steps は condition・loop でのみ使えます", where)
+			}
+		}
+	}
+	return nil
+}
+
+// Dir はプロジェクトのワークフロー定義ディレクトリ（.vyb/workflows）
+func Dir(projectDir string) string {
+	return filepath.Join(projectDir, config.ProjectConfigDir, "workflows")
+}
+
+// Parse はYAMLのワークフロー定義を解析・検証する（name がなければ defaultName）
+func Parse(data []byte, defaultName string) (*Definition, error) {
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	var def Definition
+	if err := decoder.Decode(&def); err != nil {
+		return nil, fmt.Errorf("ワークフロー定義の解析エラー: %w", err)
+	}
+	if def.Name == "" {
+		def.Name = defaultName
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+	return &def, nil
+}
+
+// Load は名前（またはYAMLファイルのパス）からワークフロー定義を読み込む
+func Load(dir, name string) (*Definition, error) {
+	var candidates []string
+	if strings.HasSuffix(name, ".yaml") || strings.HasSuffix(name, ".yml") {
+		candidates = []string{name}
+	} else {
+		candidates = []string{filepath.Join(dir, name+".yaml"), filepath.Join(dir, name+".yml")}
+	}
+
+	for _, path := range candidates {
+		data, err := os.ReadFile(path)
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ワークフロー読み込みエラー: %w", err)
+		}
+		def, err := Parse(data, strings.TrimSuffix(filepath.Base(path), filepath.Ext(path)))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		def.Path = path
+		return def, nil
+	}
+	return nil, fmt.Errorf("ワークフロー %s が見つかりません（%s）", name, dir)
+}
+
+// List はディレクトリのワークフロー定義を名前順に返す（解析できないファイルはエラーとして返す）
+func List(dir string) ([]*Definition, map[string]error) {
+	var paths []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, _ := filepath.Glob(filepath.Join(dir, pattern))
+		paths = append(paths, matches...)
+	}
+	sort.Strings(paths)
+
+	var defs []*Definition
+	invalid := make(map[string]error)
+	for _, path := range paths {
+		def, err := Load(dir, path)
+		if err != nil {
+			invalid[path] = err
+			continue
+		}
+		defs = append(defs, def)
+	}
+	return defs, invalid
+}
+
+func truncate(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	return text[:limit] + "..."
+}
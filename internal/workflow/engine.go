@@ -0,0 +1,402 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// ステップの実行状態
+const (
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+	StatusSkipped   = "skipped"
+)
+
+// ToolExecutor はツールステップを実行する（tools.UnifiedToolRegistry が満たす）
+type ToolExecutor interface {
+	ExecuteTool(ctx context.Context, request *tools.ToolRequest) (*tools.ToolResponse, error)
+}
+
+// StepResult は1ステップの実行結果
+type StepResult struct {
+	ID       string        `json:"id,omitempty"`
+	Kind     string        `json:"kind"`
+	Label    string        `json:"label"`
+	Depth    int           `json:"depth"` // condition・loop の内側なら1以上
+	Status   string        `json:"status"`
+	Output   string        `json:"output,omitempty"`
+	Error    string        `json:"error,omitempty"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Result はワークフロー1回分の実行結果
+type Result struct {
+	Workflow string            `json:"workflow"`
+	Inputs   map[string]string `json:"inputs,omitempty"`
+	Success  bool              `json:"success"`
+	Steps    []*StepResult     `json:"steps"`
+	Duration time.Duration     `json:"duration"`
+}
+
+// Engine はワークフローのステップを順に実行する
+type Engine struct {
+	Provider llm.Provider
+	Model    string
+	Memory   string // プロジェクトメモリ（VYB.md）。prompt ステップのシステムメッセージに含める
+	Tools    ToolExecutor
+
+	// Progress はステップの開始（StatusRunning）と終了時に呼ばれる
+	Progress func(result *StepResult)
+}
+
+// run は1回の実行中の状態
+type run struct {
+	engine   *Engine
+	result   *Result
+	data     map[string]interface{}
+	steps    map[string]interface{}
+	messages []llm.ChatMessage
+}
+
+// Run は入力を既定値で補ってワークフローを実行する
+// ステップの失敗（continue_on_error を除く）で中断し、Result.Success=false とともにエラーを返す
+func (e *Engine) Run(ctx context.Context, def *Definition, inputs map[string]string) (*Result, error) {
+	resolved, err := ResolveInputs(def, inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	r := &run{
+		engine: e,
+		result: &Result{Workflow: def.Name, Inputs: resolved},
+		steps:  make(map[string]interface{}),
+	}
+	r.data = map[string]interface{}{"inputs": resolved, "steps": r.steps}
+	if e.Memory != "" {
+		r.messages = append(r.messages, llm.ChatMessage{Role: "system", Content: "Project memory (VYB.md):\n\n" + e.Memory})
+	}
+
+	start := time.Now()
+	err = r.runSteps(ctx, def.Steps, 0)
+	r.result.Duration = time.Since(start)
+	r.result.Success = err == nil
+	return r.result, err
+}
+
+// ResolveInputs は指定された入力と定義の既定値をまとめる（未定義の入力・必須入力の不足はエラー）
+func ResolveInputs(def *Definition, inputs map[string]string) (map[string]string, error) {
+	resolved := make(map[string]string)
+	for name, value := range inputs {
+		if _, ok := def.Inputs[name]; !ok {
+			return nil, fmt.Errorf("ワークフロー %s に入力 %q はありません", def.Name, name)
+		}
+		resolved[name] = value
+	}
+
+	var missing []string
+	for name, fallback := range def.Inputs {
+		if _, ok := resolved[name]; ok {
+			continue
+		}
+		if fallback == "" {
+			missing = append(missing, name)
+			continue
+		}
+		resolved[name] = fallback
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return nil, fmt.Errorf("必須の入力がありません: %s（--input name=value で指定）", strings.Join(missing, ", "))
+	}
+	return resolved, nil
+}
+
+func (r *run) runSteps(ctx context.Context, steps []Step, depth int) error {
+	for i := range steps {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := r.runStep(ctx, &steps[i], depth); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *run) runStep(ctx context.Context, step *Step, depth int) error {
+	result := &StepResult{ID: step.ID, Kind: step.Kind(), Label: step.Label(), Depth: depth}
+	r.result.Steps = append(r.result.Steps, result)
+
+	if step.If != "" {
+		ok, err := r.truthy(step.If)
+		if err != nil {
+			return r.fail(step, result, err)
+		}
+		if !ok {
+			result.Status = StatusSkipped
+			r.record(step, result)
+			r.notify(result)
+			return nil
+		}
+	}
+
+	result.Status = StatusRunning
+	r.notify(result)
+	start := time.Now()
+
+	var err error
+	switch result.Kind {
+	case StepPrompt:
+		result.Output, err = r.prompt(ctx, step)
+	case StepTool:
+		result.Output, err = r.tool(ctx, step)
+	case StepCondition:
+		result.Output, err = r.condition(ctx, step, depth)
+	case StepLoop:
+		result.Output, err = r.loop(ctx, step, depth)
+	}
+	result.Duration = time.Since(start)
+	if err != nil {
+		return r.fail(step, result, err)
+	}
+
+	result.Status = StatusSucceeded
+	r.record(step, result)
+	r.notify(result)
+	return nil
+}
+
+// fail はステップを失敗として記録し、continue_on_error でなければエラーを返す
+func (r *run) fail(step *Step, result *StepResult, err error) error {
+	result.Status = StatusFailed
+	result.Error = err.Error()
+	r.record(step, result)
+	r.notify(result)
+	if step.ContinueOnError {
+		return nil
+	}
+	return fmt.Errorf("ステップ %q が失敗しました: %w", result.Label, err)
+}
+
+// record は後続ステップが {{.steps.<id>.*}} で参照できるよう結果を保存
+func (r *run) record(step *Step, result *StepResult) {
+	if step.ID == "" {
+		return
+	}
+	r.steps[step.ID] = map[string]interface{}{
+		"output":  result.Output,
+		"error":   result.Error,
+		"success": result.Status == StatusSucceeded,
+		"skipped": result.Status == StatusSkipped,
+	}
+}
+
+func (r *run) notify(result *StepResult) {
+	if r.engine.Progress != nil {
+		r.engine.Progress(result)
+	}
+}
+
+// prompt はこれまでのやり取りに続けてモデルに問い合わせる
+func (r *run) prompt(ctx context.Context, step *Step) (string, error) {
+	if r.engine.Provider == nil {
+		return "", fmt.Errorf("prompt ステップにはLLMプロバイダーが必要です")
+	}
+	content, err := r.render(step.Prompt)
+	if err != nil {
+		return "", err
+	}
+
+	messages := append(r.messages, llm.ChatMessage{Role: "user", Content: content})
+	resp, err := r.engine.Provider.Chat(ctx, llm.ChatRequest{Model: r.engine.Model, Messages: messages})
+	if err != nil {
+		return "", fmt.Errorf("LLM呼び出しエラー: %w", err)
+	}
+	r.messages = append(messages, llm.ChatMessage{Role: "assistant", Content: resp.Message.Content})
+	return strings.TrimSpace(resp.Message.Content), nil
+}
+
+// tool はパラメーターを展開してツールを実行（ツールの失敗はステップの失敗）
+func (r *run) tool(ctx context.Context, step *Step) (string, error) {
+	if r.engine.Tools == nil {
+		return "", fmt.Errorf("tool ステップを実行できません（ツールが未設定）")
+	}
+	params, err := r.renderValue(step.With)
+	if err != nil {
+		return "", err
+	}
+	parameters, _ := params.(map[string]interface{})
+	if parameters == nil {
+		parameters = make(map[string]interface{})
+	}
+
+	resp, err := r.engine.Tools.ExecuteTool(ctx, &tools.ToolRequest{
+		ID:         step.ID,
+		ToolName:   step.Tool,
+		Parameters: parameters,
+	})
+	if err != nil {
+		return "", err
+	}
+	output := strings.TrimRight(resp.Content, "\n")
+	if !resp.Success {
+		message := resp.Error
+		if message == "" {
+			message = fmt.Sprintf("%s が失敗しました", step.Tool)
+		}
+		return output, fmt.Errorf("%s", message)
+	}
+	return output, nil
+}
+
+// condition は条件を評価して then・else のどちらかを実行（出力は選んだ分岐名）
+func (r *run) condition(ctx context.Context, step *Step, depth int) (string, error) {
+	ok, err := r.truthy(step.Condition)
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return "then", r.runSteps(ctx, step.Then, depth+1)
+	}
+	return "else", r.runSteps(ctx, step.Else, depth+1)
+}
+
+// loop は items の各要素、または until が真になるまで steps を繰り返す（出力は反復回数）
+func (r *run) loop(ctx context.Context, step *Step, depth int) (string, error) {
+	limit := step.Loop.Max
+	if limit <= 0 {
+		limit = defaultMaxIterations
+	}
+
+	var items []string
+	for _, item := range step.Loop.Items {
+		rendered, err := r.render(item)
+		if err != nil {
+			return "", err
+		}
+		// 1要素が複数行に展開された場合（コマンド出力等）は各行を要素とする
+		for _, line := range strings.Split(rendered, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				items = append(items, line)
+			}
+		}
+	}
+	iterations := limit
+	if len(step.Loop.Items) > 0 && len(items) < iterations {
+		iterations = len(items)
+	}
+
+	defer delete(r.data, "item")
+	defer delete(r.data, "index")
+	for i := 0; i < iterations; i++ {
+		r.data["index"] = i
+		if i < len(items) {
+			r.data["item"] = items[i]
+		}
+		if err := r.runSteps(ctx, step.Steps, depth+1); err != nil {
+			return strconv.Itoa(i + 1), err
+		}
+		if step.Loop.Until != "" {
+			done, err := r.truthy(step.Loop.Until)
+			if err != nil {
+				return strconv.Itoa(i + 1), err
+			}
+			if done {
+				return strconv.Itoa(i + 1), nil
+			}
+		}
+	}
+	if step.Loop.Until != "" && len(step.Loop.Items) == 0 {
+		return strconv.Itoa(iterations), fmt.Errorf("%d 回繰り返しても until の条件を満たしませんでした", iterations)
+	}
+	return strconv.Itoa(iterations), nil
+}
+
+// テンプレートで使える関数
+var templateFuncs = template.FuncMap{
+	"contains":  strings.Contains,
+	"hasPrefix": strings.HasPrefix,
+	"lower":     strings.ToLower,
+	"trim":      strings.TrimSpace,
+	"lines": func(text string) []string {
+		var lines []string
+		for _, line := range strings.Split(text, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				lines = append(lines, line)
+			}
+		}
+		return lines
+	},
+}
+
+// render は文字列を実行中の状態でテンプレート展開する（未定義の値は空文字）
+func (r *run) render(text string) (string, error) {
+	if !strings.Contains(text, "{{") {
+		return text, nil
+	}
+	tmpl, err := template.New("step").Funcs(templateFuncs).Option("missingkey=zero").Parse(text)
+	if err != nil {
+		return "", fmt.Errorf("テンプレート解析エラー: %w", err)
+	}
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, r.data); err != nil {
+		return "", fmt.Errorf("テンプレート展開エラー: %w", err)
+	}
+	return strings.ReplaceAll(sb.String(), "<no value>", ""), nil
+}
+
+// renderValue は with の値を再帰的に展開（整数はツールの数値パラメーターに合わせて float64 にする）
+func (r *run) renderValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return r.render(v)
+	case int:
+		return float64(v), nil
+	case map[string]interface{}:
+		rendered := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			out, err := r.renderValue(item)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = out
+		}
+		return rendered, nil
+	case []interface{}:
+		rendered := make([]interface{}, len(v))
+		for i, item := range v {
+			out, err := r.renderValue(item)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = out
+		}
+		return rendered, nil
+	}
+	return value, nil
+}
+
+// truthy は条件式を展開し、空・false・0・no 以外を真とする
+func (r *run) truthy(expr string) (bool, error) {
+	if !strings.Contains(expr, "{{") {
+		expr = "{{" + expr + "}}"
+	}
+	value, err := r.render(expr)
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "", "false", "0", "no":
+		return false, nil
+	}
+	return true, nil
+}
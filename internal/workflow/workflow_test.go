@@ -0,0 +1,239 @@
+package workflow
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/glkt/vyb-code/internal/llm"
+	"github.com/glkt/vyb-code/internal/tools"
+)
+
+// echoProvider は最後のユーザーメッセージを加工して返し、受け取った履歴を記録するテスト用プロバイダー
+type echoProvider struct {
+	requests [][]llm.ChatMessage
+}
+
+func (p *echoProvider) Chat(ctx context.Context, req llm.ChatRequest) (*llm.ChatResponse, error) {
+	p.requests = append(p.requests, req.Messages)
+	last := req.Messages[len(req.Messages)-1].Content
+	return &llm.ChatResponse{Message: llm.ChatMessage{Role: "assistant", Content: "echo: " + last}}, nil
+}
+
+func (p *echoProvider) SupportsFunctionCalling() bool { return false }
+
+func (p *echoProvider) GetModelInfo(model string) (*llm.ModelInfo, error) { return nil, nil }
+
+func (p *echoProvider) ListModels() ([]llm.ModelInfo, error) { return nil, nil }
+
+// fakeTools はコマンドごとに決まった結果を返すテスト用ツール実行
+type fakeTools struct {
+	results  map[string]*tools.ToolResponse
+	executed []string
+}
+
+func (f *fakeTools) ExecuteTool(ctx context.Context, request *tools.ToolRequest) (*tools.ToolResponse, error) {
+	command, _ := request.Parameters["command"].(string)
+	f.executed = append(f.executed, command)
+	if resp, ok := f.results[command]; ok {
+		return resp, nil
+	}
+	return &tools.ToolResponse{Success: true, Content: "ran " + command}, nil
+}
+
+const releasePrep = `
+name: release-prep
+description: Bump a dependency and test
+inputs:
+  module: ""
+  version: latest
+steps:
+  - id: bump
+    tool: bash
+    with:
+      command: "go get {{.inputs.module}}@{{.inputs.version}}"
+      timeout: 60000
+  - id: test
+    tool: bash
+    with:
+      command: go test ./...
+    continue_on_error: true
+  - condition: "{{not .steps.test.success}}"
+    then:
+      - id: fix
+        prompt: "Fix the failures: {{.steps.test.output}}"
+    else:
+      - prompt: All good
+  - id: packages
+    tool: bash
+    with:
+      command: go list ./...
+  - loop:
+      items: ["{{.steps.packages.output}}"]
+    steps:
+      - tool: bash
+        with:
+          command: "go vet {{.item}}"
+  - prompt: Summarize
+    if: "{{.steps.fix.success}}"
+`
+
+func TestParseAndValidate(t *testing.T) {
+	def, err := Parse([]byte(releasePrep), "fallback")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if def.Name != "release-prep" || len(def.Steps) != 6 || def.Steps[2].Kind() != StepCondition || def.Steps[4].Kind() != StepLoop {
+		t.Errorf("定義: %+v", def)
+	}
+
+	invalid := map[string]string{
+		"empty":      "name: x\nsteps: []\n",
+		"two kinds":  "steps:\n  - prompt: hi\n    tool: bash\n",
+		"no kind":    "steps:\n  - id: a\n",
+		"duplicate":  "steps:\n  - id: a\n    prompt: x\n  - id: a\n    prompt: y\n",
+		"loop empty": "steps:\n  - loop: {max: 3}\n    steps:\n      - prompt: x\n",
+		"nested dup": "steps:\n  - id: a\n    prompt: x\n  - condition: \"true\"\n    then:\n      - id: a\n        prompt: y\n",
+		"unknown":    "steps:\n  - promt: typo\n",
+	}
+	for name, source := range invalid {
+		if _, err := Parse([]byte(source), name); err == nil {
+			t.Errorf("%s: エラーになるはず", name)
+		}
+	}
+}
+
+func TestLoadAndList(t *testing.T) {
+	projectDir := t.TempDir()
+	dir := Dir(projectDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"release-prep.yaml": releasePrep,
+		"changelog.yml":     "steps:\n  - prompt: Write a changelog\n",
+		"broken.yaml":       "steps: [",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	def, err := Load(dir, "changelog")
+	if err != nil || def.Name != "changelog" {
+		t.Fatalf("名前がなければファイル名: %+v, %v", def, err)
+	}
+	if _, err := Load(dir, "missing"); err == nil {
+		t.Error("存在しないワークフローはエラーのはず")
+	}
+
+	defs, invalid := List(dir)
+	if len(defs) != 2 || defs[0].Name != "changelog" || len(invalid) != 1 {
+		t.Errorf("一覧: %+v, %v", defs, invalid)
+	}
+}
+
+func TestRun(t *testing.T) {
+	def, err := Parse([]byte(releasePrep), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	provider := &echoProvider{}
+	executor := &fakeTools{results: map[string]*tools.ToolResponse{
+		"go test ./...": {Success: false, Content: "FAIL pkg/a", Error: "exit status 1"},
+		"go list ./...": {Success: true, Content: "pkg/a\npkg/b\n"},
+	}}
+	var started []string
+	engine := &Engine{Provider: provider, Model: "test", Memory: "use gofmt", Tools: executor, Progress: func(result *StepResult) {
+		if result.Status == StatusRunning {
+			started = append(started, result.Label)
+		}
+	}}
+
+	if _, err := engine.Run(context.Background(), def, nil); err == nil || !strings.Contains(err.Error(), "module") {
+		t.Errorf("必須入力の不足はエラーのはず: %v", err)
+	}
+	if _, err := engine.Run(context.Background(), def, map[string]string{"module": "x", "typo": "y"}); err == nil {
+		t.Error("未定義の入力はエラーのはず")
+	}
+
+	result, err := engine.Run(context.Background(), def, map[string]string{"module": "example.com/lib"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Success || result.Inputs["version"] != "latest" {
+		t.Errorf("結果: %+v", result)
+	}
+
+	wantCommands := []string{"go get example.com/lib@latest", "go test ./...", "go list ./...", "go vet pkg/a", "go vet pkg/b"}
+	if strings.Join(executor.executed, "|") != strings.Join(wantCommands, "|") {
+		t.Errorf("実行したコマンド: %v", executor.executed)
+	}
+
+	// then 分岐のみ実行し、前の出力を参照できる
+	if len(provider.requests) != 2 {
+		t.Fatalf("prompt の呼び出し: %d", len(provider.requests))
+	}
+	first := provider.requests[0]
+	if first[0].Role != "system" || !strings.Contains(first[0].Content, "use gofmt") || first[1].Content != "Fix the failures: FAIL pkg/a" {
+		t.Errorf("最初のプロンプト: %+v", first)
+	}
+	// 以前のやり取りを引き継ぐ
+	if second := provider.requests[1]; len(second) != 4 || second[3].Content != "Summarize" {
+		t.Errorf("2回目のプロンプト: %+v", second)
+	}
+
+	statuses := make(map[string]string)
+	for _, step := range result.Steps {
+		statuses[step.Label] = step.Status
+	}
+	if statuses["test"] != StatusFailed || statuses["fix"] != StatusSucceeded || len(started) == 0 {
+		t.Errorf("ステップの状態: %v", statuses)
+	}
+}
+
+func TestRunStopsOnFailureAndLoopUntil(t *testing.T) {
+	def, err := Parse([]byte(`
+steps:
+  - id: retry
+    loop:
+      until: "{{contains .steps.check.output \"ok\"}}"
+      max: 3
+    steps:
+      - id: check
+        tool: bash
+        with:
+          command: "check {{.index}}"
+  - tool: bash
+    with:
+      command: fail
+  - prompt: never reached
+`), "retry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	executor := &fakeTools{results: map[string]*tools.ToolResponse{
+		"check 1": {Success: true, Content: "ok"},
+		"fail":    {Success: false, Error: "boom"},
+	}}
+	provider := &echoProvider{}
+	result, err := (&Engine{Provider: provider, Tools: executor}).Run(context.Background(), def, nil)
+	if err == nil || !strings.Contains(err.Error(), "boom") || result.Success {
+		t.Fatalf("失敗したステップで中断するはず: %v", err)
+	}
+	if result.Steps[0].Output != "2" {
+		t.Errorf("until を満たした反復で終了するはず: %+v", result.Steps[0])
+	}
+	if len(provider.requests) != 0 {
+		t.Error("失敗後のステップは実行しないはず")
+	}
+
+	// until を満たさなければ失敗
+	executor.results = map[string]*tools.ToolResponse{}
+	if _, err := (&Engine{Tools: executor}).Run(context.Background(), def, nil); err == nil || !strings.Contains(err.Error(), "3 回") {
+		t.Errorf("最大反復回数でエラーのはず: %v", err)
+	}
+}
@@ -0,0 +1,353 @@
+package workspace
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// モジュールの種類
+const (
+	KindGo  = "go"  // go.mod
+	KindNPM = "npm" // package.json の workspaces・pnpm-workspace.yaml
+)
+
+// go.mod を探す深さの上限（ルートからのディレクトリ階層）
+const maxModuleDepth = 6
+
+// モジュールの探索で読み飛ばすディレクトリ
+var skippedDirs = map[string]bool{
+	"node_modules": true, "vendor": true, "testdata": true, "dist": true, "build": true, "target": true,
+}
+
+// Module はモノレポ内のモジュール（Go モジュール・npm ワークスペースのパッケージ）
+type Module struct {
+	Name string `json:"name"` // go.mod の module パス、package.json の name
+	Path string `json:"path"` // ルートからの相対パス（ルート自身は "."）
+	Dir  string `json:"dir"`  // 絶対パス
+	Kind string `json:"kind"`
+}
+
+// Workspace はリポジトリのルートと含まれるモジュール
+type Workspace struct {
+	Root    string
+	Modules []Module
+}
+
+// Detect は startDir を含むワークスペースを検出する
+// ルートは go.work・pnpm-workspace.yaml・workspaces 付きの package.json があるうち最も外側のディレクトリ
+// （git リポジトリのルートを越えない）、いずれも無ければ git リポジトリのルート
+func Detect(startDir string) (*Workspace, error) {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return nil, err
+	}
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	root, marked := "", false
+	for current := dir; ; current = filepath.Dir(current) {
+		if isWorkspaceRoot(current) {
+			root, marked = current, true
+		}
+		if exists(filepath.Join(current, ".git")) {
+			if !marked {
+				root = current
+			}
+			break
+		}
+		if filepath.Dir(current) == current {
+			break
+		}
+	}
+	if root == "" {
+		root = dir
+	}
+
+	ws := &Workspace{Root: root}
+	ws.Modules = append(ws.Modules, goModules(root)...)
+	ws.Modules = append(ws.Modules, npmModules(root)...)
+	sort.Slice(ws.Modules, func(i, j int) bool {
+		if ws.Modules[i].Path != ws.Modules[j].Path {
+			return ws.Modules[i].Path < ws.Modules[j].Path
+		}
+		return ws.Modules[i].Kind < ws.Modules[j].Kind
+	})
+	return ws, nil
+}
+
+// IsMonorepo は複数のモジュールを含むか
+func (w *Workspace) IsMonorepo() bool {
+	return len(w.Modules) > 1
+}
+
+// Find はパス（ルートからの相対）・モジュール名・ディレクトリ名からモジュールを探す
+func (w *Workspace) Find(name string) (*Module, error) {
+	query := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(name)), "/")
+	query = strings.TrimPrefix(query, "./")
+	if query == "" {
+		query = "."
+	}
+
+	matchers := []func(Module) bool{
+		func(m Module) bool { return m.Path == query },
+		func(m Module) bool { return m.Name == query },
+		func(m Module) bool { return filepath.Base(m.Path) == query || filepath.Base(m.Name) == query },
+	}
+	for _, match := range matchers {
+		var found []Module
+		for _, module := range w.Modules {
+			if match(module) {
+				found = append(found, module)
+			}
+		}
+		switch {
+		case len(found) == 1:
+			return &found[0], nil
+		case len(found) > 1 && !sameDir(found):
+			return nil, fmt.Errorf("モジュール %s が複数あります: %s", name, strings.Join(modulePaths(found), ", "))
+		case len(found) > 1:
+			return &found[0], nil
+		}
+	}
+	if len(w.Modules) == 0 {
+		return nil, fmt.Errorf("%s にモジュール（go.mod・npm ワークスペース）が見つかりません", w.Root)
+	}
+	return nil, fmt.Errorf("モジュール %s が見つかりません。利用できるモジュール: %s", name, strings.Join(modulePaths(w.Modules), ", "))
+}
+
+// Active はディレクトリを含むモジュール（最も内側、含まれなければ nil）
+func (w *Workspace) Active(dir string) *Module {
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+	var active *Module
+	for i := range w.Modules {
+		module := &w.Modules[i]
+		if dir == module.Dir || strings.HasPrefix(dir, module.Dir+string(filepath.Separator)) {
+			if active == nil || len(module.Dir) > len(active.Dir) {
+				active = module
+			}
+		}
+	}
+	return active
+}
+
+// Enter は startDir を含むワークスペースのモジュールを探し、作業ディレクトリをそこへ移す
+// 分析・ビルドコマンド・ファイル操作はカレントディレクトリを基準にするため、以降はモジュールに限定される
+func Enter(startDir, name string) (*Module, error) {
+	ws, err := Detect(startDir)
+	if err != nil {
+		return nil, err
+	}
+	module, err := ws.Find(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chdir(module.Dir); err != nil {
+		return nil, fmt.Errorf("モジュール %s に移動できません: %w", module.Path, err)
+	}
+	return module, nil
+}
+
+// isWorkspaceRoot はディレクトリが複数モジュールをまとめる設定を持つか
+func isWorkspaceRoot(dir string) bool {
+	if exists(filepath.Join(dir, "go.work")) || exists(filepath.Join(dir, "pnpm-workspace.yaml")) {
+		return true
+	}
+	return len(packageWorkspaces(dir)) > 0
+}
+
+// goModules は go.work の use、無ければルート以下の go.mod からモジュールを集める
+func goModules(root string) []Module {
+	var dirs []string
+	if uses := goWorkUses(filepath.Join(root, "go.work")); len(uses) > 0 {
+		for _, use := range uses {
+			dirs = append(dirs, filepath.Join(root, filepath.FromSlash(use)))
+		}
+	} else {
+		filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil || !info.IsDir() {
+				return nil
+			}
+			if path != root {
+				rel, _ := filepath.Rel(root, path)
+				if skippedDirs[info.Name()] || strings.HasPrefix(info.Name(), ".") || strings.Count(rel, string(filepath.Separator)) >= maxModuleDepth {
+					return filepath.SkipDir
+				}
+			}
+			if exists(filepath.Join(path, "go.mod")) {
+				dirs = append(dirs, path)
+			}
+			return nil
+		})
+	}
+
+	var modules []Module
+	for _, dir := range dirs {
+		name := goModulePath(filepath.Join(dir, "go.mod"))
+		if name == "" {
+			continue
+		}
+		modules = append(modules, newModule(root, dir, name, KindGo))
+	}
+	return modules
+}
+
+// goWorkUses は go.work の use ディレクティブのディレクトリ
+func goWorkUses(path string) []string {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+
+	var uses []string
+	inBlock := false
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = line[:i]
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			uses = append(uses, strings.Trim(fields[0], `"`))
+		case fields[0] == "use" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "use" && len(fields) > 1:
+			uses = append(uses, strings.Trim(fields[1], `"`))
+		}
+	}
+	return uses
+}
+
+// goModulePath は go.mod の module パス
+func goModulePath(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// npmModules は package.json の workspaces・pnpm-workspace.yaml のパッケージ
+func npmModules(root string) []Module {
+	patterns := packageWorkspaces(root)
+	if data, err := os.ReadFile(filepath.Join(root, "pnpm-workspace.yaml")); err == nil {
+		var pnpm struct {
+			Packages []string `yaml:"packages"`
+		}
+		if yaml.Unmarshal(data, &pnpm) == nil {
+			patterns = append(patterns, pnpm.Packages...)
+		}
+	}
+
+	excluded := make(map[string]bool)
+	var dirs []string
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "./")
+		// ** は1階層として扱う（packages/** → packages/*）
+		pattern = strings.ReplaceAll(pattern, "**", "*")
+		matches, _ := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+		for _, match := range matches {
+			if negate {
+				excluded[match] = true
+			} else if exists(filepath.Join(match, "package.json")) {
+				dirs = append(dirs, match)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var modules []Module
+	for _, dir := range dirs {
+		if excluded[dir] || seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		name := filepath.Base(dir)
+		if data, err := os.ReadFile(filepath.Join(dir, "package.json")); err == nil {
+			var pkg struct {
+				Name string `json:"name"`
+			}
+			if json.Unmarshal(data, &pkg) == nil && pkg.Name != "" {
+				name = pkg.Name
+			}
+		}
+		modules = append(modules, newModule(root, dir, name, KindNPM))
+	}
+	return modules
+}
+
+// packageWorkspaces は package.json の workspaces（配列または {packages: [...]}）
+func packageWorkspaces(dir string) []string {
+	data, err := os.ReadFile(filepath.Join(dir, "package.json"))
+	if err != nil {
+		return nil
+	}
+	var pkg struct {
+		Workspaces json.RawMessage `json:"workspaces"`
+	}
+	if json.Unmarshal(data, &pkg) != nil || len(pkg.Workspaces) == 0 {
+		return nil
+	}
+	var patterns []string
+	if json.Unmarshal(pkg.Workspaces, &patterns) == nil {
+		return patterns
+	}
+	var nested struct {
+		Packages []string `json:"packages"`
+	}
+	json.Unmarshal(pkg.Workspaces, &nested)
+	return nested.Packages
+}
+
+func newModule(root, dir, name, kind string) Module {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		rel = dir
+	}
+	return Module{Name: name, Path: filepath.ToSlash(rel), Dir: filepath.Clean(dir), Kind: kind}
+}
+
+func modulePaths(modules []Module) []string {
+	paths := make([]string, 0, len(modules))
+	for _, module := range modules {
+		paths = append(paths, module.Path)
+	}
+	return paths
+}
+
+// sameDir は同じディレクトリのモジュール（go.mod と package.json の両方を持つ等）か
+func sameDir(modules []Module) bool {
+	for _, module := range modules[1:] {
+		if module.Dir != modules[0].Dir {
+			return false
+		}
+	}
+	return true
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
@@ -0,0 +1,158 @@
+package workspace
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	for name, content := range files {
+		filePath := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func paths(ws *Workspace) []string {
+	return modulePaths(ws.Modules)
+}
+
+func TestDetect_GoModules(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		".git/HEAD":                     "ref: refs/heads/main\n",
+		"go.mod":                        "module example.com/mono\n",
+		"services/api/go.mod":           "module example.com/mono/services/api\n",
+		"services/api/internal/x.go":    "package internal\n",
+		"services/worker/go.mod":        "module \"example.com/worker\"\n",
+		"vendor/example.com/v/go.mod":   "module example.com/v\n",
+		"tools/testdata/fixture/go.mod": "module fixture\n",
+	})
+
+	ws, err := Detect(filepath.Join(root, "services", "api", "internal"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.Root != root {
+		t.Errorf("Root = %s, want %s", ws.Root, root)
+	}
+	if got := paths(ws); !reflect.DeepEqual(got, []string{".", "services/api", "services/worker"}) {
+		t.Fatalf("Modules = %v", got)
+	}
+	if !ws.IsMonorepo() {
+		t.Error("複数モジュールはモノレポのはず")
+	}
+
+	if active := ws.Active(filepath.Join(root, "services", "api", "internal")); active == nil || active.Path != "services/api" {
+		t.Errorf("Active = %+v (最も内側のモジュールのはず)", active)
+	}
+	if active := ws.Active(filepath.Join(root, "docs")); active == nil || active.Path != "." {
+		t.Errorf("Active = %+v", active)
+	}
+
+	for query, want := range map[string]string{
+		"services/api":       "services/api",
+		"./services/worker/": "services/worker",
+		"example.com/worker": "services/worker",
+		"api":                "services/api",
+		".":                  ".",
+	} {
+		module, err := ws.Find(query)
+		if err != nil {
+			t.Errorf("Find(%q): %v", query, err)
+			continue
+		}
+		if module.Path != want {
+			t.Errorf("Find(%q) = %s, want %s", query, module.Path, want)
+		}
+	}
+	if _, err := ws.Find("billing"); err == nil || !strings.Contains(err.Error(), "services/api") {
+		t.Errorf("見つからなければ利用できるモジュールを示すはず: %v", err)
+	}
+}
+
+func TestDetect_GoWorkAndNPM(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		".git/HEAD":                "ref: refs/heads/main\n",
+		"go.work":                  "go 1.21\n\nuse (\n\t./backend // API\n\t./shared\n)\n",
+		"backend/go.mod":           "module example.com/backend\n",
+		"shared/go.mod":            "module example.com/shared\n",
+		"experimental/go.mod":      "module example.com/experimental\n",
+		"package.json":             `{"private": true, "workspaces": {"packages": ["apps/*", "!apps/legacy"]}}`,
+		"apps/web/package.json":    `{"name": "@acme/web"}`,
+		"apps/admin/package.json":  `{"name": "@acme/admin"}`,
+		"apps/legacy/package.json": `{"name": "legacy"}`,
+		"apps/README.md":           "apps\n",
+		"apps/web/src/index.ts":    "export {}\n",
+	})
+
+	ws, err := Detect(filepath.Join(root, "apps", "web", "src"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := paths(ws); !reflect.DeepEqual(got, []string{"apps/admin", "apps/web", "backend", "shared"}) {
+		t.Fatalf("Modules = %v (go.work の use と workspaces のみのはず)", got)
+	}
+	module, err := ws.Find("@acme/web")
+	if err != nil || module.Kind != KindNPM {
+		t.Errorf("Find(@acme/web) = %+v, %v", module, err)
+	}
+}
+
+func TestDetect_PNPMWorkspace(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		"pnpm-workspace.yaml":          "packages:\n  - 'packages/**'\n",
+		"packages/ui/package.json":     `{"name": "ui"}`,
+		"packages/core/package.json":   `{}`,
+		"packages/ui/lib/package.json": `{"name": "nested"}`,
+	})
+
+	ws, err := Detect(filepath.Join(root, "packages", "ui"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.Root != root {
+		t.Errorf("Root = %s, want %s", ws.Root, root)
+	}
+	if got := paths(ws); !reflect.DeepEqual(got, []string{"packages/core", "packages/ui"}) {
+		t.Errorf("Modules = %v", got)
+	}
+	if module, err := ws.Find("core"); err != nil || module.Name != "core" {
+		t.Errorf("name が無ければディレクトリ名のはず: %+v, %v", module, err)
+	}
+}
+
+func TestEnter(t *testing.T) {
+	root := writeFiles(t, map[string]string{
+		".git/HEAD":         "ref: refs/heads/main\n",
+		"svc/a/go.mod":      "module a\n",
+		"svc/b/go.mod":      "module b\n",
+		"svc/b/cmd/main.go": "package main\n",
+	})
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	module, err := Enter(filepath.Join(root, "svc", "a"), "b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, _ := os.Getwd()
+	if current != module.Dir || module.Path != "svc/b" {
+		t.Errorf("cwd = %s, module = %+v", current, module)
+	}
+}